@@ -0,0 +1,385 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"net"
+	"slices"
+	"strings"
+	"sync"
+
+	adl "github.com/mwat56/dnscache/internal/adlist"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tTypeFilter` blocks (some) query types of a hostname pattern
+	// for the clients of a group as given by an AdGuard rule.
+	tTypeFilter struct {
+		group  string   // the clients' group (empty for the default one)
+		match  string   // normalised hostname pattern
+		types  []uint16 // the query types, `nil` meaning all types
+		except bool     // block all types but the given ones
+	}
+
+	// `tAdGuard` holds the AdGuard rules of the loaded blocklists
+	// which don't fit the allow/deny lists.
+	//
+	// The rules are kept per source, i.e. the group whose blocklists
+	// contained them (empty for the default blocklists), so reloading
+	// a group's lists replaces its rules.
+	tAdGuard struct {
+		sync.RWMutex
+		filters  map[string][]tTypeFilter // source → query type filters
+		rewrites map[string][]string      // source → rewrite rules' matches
+	}
+)
+
+var (
+	// `adguardTypes` maps the query type names used by the
+	// `$dnstype` modifier to their numbers.
+	adguardTypes = map[string]uint16{
+		"A":     1,
+		"NS":    2,
+		"CNAME": 5,
+		"SOA":   6,
+		"PTR":   12,
+		"MX":    15,
+		"TXT":   16,
+		"AAAA":  28,
+		"SRV":   33,
+		"NAPTR": 35,
+		"DS":    43,
+		"SVCB":  64,
+		"HTTPS": 65,
+		"CAA":   257,
+	}
+)
+
+// ---------------------------------------------------------------------------
+// `tAdGuard` constructor:
+
+// `newAdGuard()` returns a new, empty set of AdGuard rules.
+//
+// Returns:
+//   - `*tAdGuard`: The new set of rules.
+func newAdGuard() *tAdGuard {
+	return &tAdGuard{
+		filters:  make(map[string][]tTypeFilter),
+		rewrites: make(map[string][]string),
+	}
+} // newAdGuard()
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `adguardFilter()` converts the `$dnstype` values of an AdGuard rule.
+//
+// Parameters:
+//   - `aTypes`: The modifier's values (empty for all types).
+//
+// Returns:
+//   - `rTypes`: The query types (`nil` for all types).
+//   - `rExcept`: Whether all types but the given ones are meant.
+//   - `rOK`: `false` if a value is unknown or in- and exclusions are mixed.
+func adguardFilter(aTypes []string) (rTypes []uint16, rExcept bool, rOK bool) {
+	for idx, value := range aTypes {
+		name, except := strings.CutPrefix(value, "~")
+		if (0 < idx) && (except != rExcept) {
+			return nil, false, false
+		}
+		qType, ok := adguardTypes[name]
+		if !ok {
+			return nil, false, false
+		}
+		rExcept = except
+		rTypes = append(rTypes, qType)
+	}
+	rOK = true
+
+	return
+} // adguardFilter()
+
+// `adguardRewrite()` converts the `$dnsrewrite` value of an AdGuard
+// rule.
+//
+// Supported are the short forms (an IP address or a hostname), the
+// `NOERROR;A;…`, `NOERROR;AAAA;…`, and `NOERROR;CNAME;…` forms, and
+// the `NXDOMAIN` and `REFUSED` response codes, which block the
+// hostname.
+//
+// Parameters:
+//   - `aPattern`: The rule's hostname pattern.
+//   - `aValue`: The modifier's value.
+//
+// Returns:
+//   - `rRule`: The rewrite rule.
+//   - `rBlock`: Whether the hostname is to be blocked instead.
+//   - `rOK`: `false` if the value can't be converted.
+func adguardRewrite(aPattern, aValue string) (rRule TRewriteRule, rBlock, rOK bool) {
+	fields := strings.Split(aValue, ";")
+	switch strings.ToUpper(fields[0]) {
+	case "NXDOMAIN", "REFUSED":
+		return rRule, true, true
+
+	case "NOERROR":
+		if 3 != len(fields) {
+			return
+		}
+		switch strings.ToUpper(fields[1]) {
+		case "A", "AAAA":
+			rRule.Type = RewriteIP
+		case "CNAME":
+			rRule.Type = RewriteCNAME
+		default:
+			return
+		}
+		rRule.Target = fields[2]
+
+	default:
+		if 1 != len(fields) {
+			return
+		}
+		rRule.Target = fields[0]
+		if nil == net.ParseIP(rRule.Target) {
+			rRule.Type = RewriteCNAME
+		}
+	}
+	rRule.Match = aPattern
+	rOK = (nil == rRule.Validate())
+
+	return
+} // adguardRewrite()
+
+// ---------------------------------------------------------------------------
+// `tAdGuard` methods:
+
+// `blocks()` checks whether a query type of a hostname is blocked
+// for the clients of a group.
+//
+// Parameters:
+//   - `aGroup`: The client's group (empty for the default group).
+//   - `aHostname`: The queried hostname.
+//   - `aQType`: The query type (`0` matches the filters of all types only).
+//
+// Returns:
+//   - `bool`: `true` if the query is blocked, `false` otherwise.
+func (ag *tAdGuard) blocks(aGroup, aHostname string, aQType uint16) bool {
+	if nil == ag {
+		return false
+	}
+	ag.RLock()
+	defer ag.RUnlock()
+
+	if 0 == len(ag.filters) {
+		return false
+	}
+	hostname := strings.TrimSuffix(strings.ToLower(aHostname), ".")
+	for _, filters := range ag.filters {
+		for _, filter := range filters {
+			if (filter.group != aGroup) || !matchHostPattern(filter.match, hostname) {
+				continue
+			}
+			if nil == filter.types {
+				return true
+			}
+			if (0 != aQType) && (slices.Contains(filter.types, aQType) != filter.except) {
+				return true
+			}
+		}
+	}
+
+	return false
+} // blocks()
+
+// `replace()` replaces the rules of a source.
+//
+// Parameters:
+//   - `aSource`: The group whose blocklists contained the rules.
+//   - `aFilters`: The source's query type filters.
+//   - `aRewrites`: The matches of the source's rewrite rules.
+//
+// Returns:
+//   - `[]string`: The matches of the source's previous rewrite rules.
+func (ag *tAdGuard) replace(aSource string, aFilters []tTypeFilter, aRewrites []string) []string {
+	if nil == ag {
+		return nil
+	}
+	ag.Lock()
+	defer ag.Unlock()
+
+	previous := ag.rewrites[aSource]
+	if 0 == len(aFilters) {
+		delete(ag.filters, aSource)
+	} else {
+		ag.filters[aSource] = aFilters
+	}
+	if 0 == len(aRewrites) {
+		delete(ag.rewrites, aSource)
+	} else {
+		ag.rewrites[aSource] = aRewrites
+	}
+
+	return previous
+} // replace()
+
+// ---------------------------------------------------------------------------
+// `TResolver` methods:
+
+// `adguardGroups()` returns the groups meant by the `$client` values
+// of an AdGuard rule.
+//
+// A value has to be either a group's name or a client address range
+// assigned to a group; excluded clients (`~…`) aren't supported.
+//
+// Parameters:
+//   - `aSource`: The group whose blocklists contained the rule.
+//   - `aClients`: The modifier's values.
+//
+// Returns:
+//   - `rGroups`: The groups to apply the rule to.
+//   - `rOK`: `false` if a value can't be mapped to a group.
+func (r *TResolver) adguardGroups(aSource string, aClients []string) (rGroups []string, rOK bool) {
+	if 0 == len(aClients) {
+		return []string{aSource}, true
+	}
+	for _, client := range aClients {
+		if strings.HasPrefix(client, "~") {
+			return nil, false
+		}
+		group, ok := r.groups.assigned(client)
+		if !ok {
+			if _, err := r.groups.list(client); nil != err {
+				return nil, false
+			}
+			group = client
+		}
+		if !slices.Contains(rGroups, group) {
+			rGroups = append(rGroups, group)
+		}
+	}
+	rOK = true
+
+	return
+} // adguardGroups()
+
+// `applyAdGuard()` applies the AdGuard rules of a group's blocklists
+// which don't fit its allow/deny list, replacing the group's previous
+// rules:
+//
+//   - `$client` rules block the hostname for the named groups or the
+//     groups of the given client ranges,
+//   - `$dnstype` rules block the given query types of the hostname,
+//   - `$dnsrewrite` rules of the default blocklists without a
+//     `$client` modifier become rewrite rules.
+//
+// Rules which can't be mapped are skipped (and logged at debug level).
+//
+// Parameters:
+//   - `aSource`: The group whose blocklists were loaded (empty for
+//     the default blocklists).
+//   - `aRules`: The lists' AdGuard rules.
+func (r *TResolver) applyAdGuard(aSource string, aRules []adl.TAdGuardRule) {
+	var (
+		filters  []tTypeFilter
+		rewrites []TRewriteRule
+		skipped  int
+	)
+	for _, rule := range aRules {
+		skip := func(aReason string) {
+			skipped++
+			if debugLogging {
+				gLog.Debug("AdGuard rule skipped", "pattern", rule.Pattern, "reason", aReason)
+			}
+		}
+		match, err := normaliseHostPattern(rule.Pattern)
+		if nil != err {
+			skip(err.Error())
+			continue
+		}
+		groups, ok := r.adguardGroups(aSource, rule.Clients)
+		if !ok {
+			skip("unknown client")
+			continue
+		}
+		types, except, ok := adguardFilter(rule.DNSTypes)
+		if !ok {
+			skip("unsupported query type")
+			continue
+		}
+
+		if "" != rule.Rewrite {
+			if 0 < len(rule.DNSTypes) {
+				skip("rewrite of query types")
+				continue
+			}
+			rewrite, block, ok := adguardRewrite(match, rule.Rewrite)
+			if !ok {
+				skip("unsupported rewrite")
+				continue
+			}
+			if !block {
+				// Rewrites apply to all clients
+				if ("" != aSource) || (0 < len(rule.Clients)) {
+					skip("client specific rewrite")
+					continue
+				}
+				rewrites = append(rewrites, rewrite)
+				continue
+			}
+		}
+
+		for _, group := range groups {
+			filters = append(filters, tTypeFilter{
+				group:  group,
+				match:  match,
+				types:  types,
+				except: except,
+			})
+		}
+	}
+
+	matches := make([]string, 0, len(rewrites))
+	for _, rewrite := range rewrites {
+		matches = append(matches, rewrite.Match)
+	}
+	for _, previous := range r.adguard.replace(aSource, filters, matches) {
+		if !slices.Contains(matches, previous) {
+			r.rewrites.delete(previous)
+		}
+	}
+	for _, rewrite := range rewrites {
+		_ = r.rewrites.add(rewrite) // validated by `adguardRewrite()`
+	}
+
+	if 0 < len(aRules) {
+		gLog.Info("AdGuard rules applied", "group", aSource,
+			"filters", len(filters), "rewrites", len(rewrites), "skipped", skipped)
+	}
+} // applyAdGuard()
+
+// `BlockedType()` checks whether the AdGuard rules of the loaded
+// blocklists (i.e. those with a `$client`, `$dnstype`, or blocking
+// `$dnsrewrite` modifier) block a query of the given client.
+//
+// Parameters:
+//   - `aClient`: The requesting client's IP address (may be `nil`).
+//   - `aHostname`: The queried hostname.
+//   - `aQType`: The DNS query type.
+//
+// Returns:
+//   - `bool`: `true` if the query is blocked, `false` otherwise.
+func (r *TResolver) BlockedType(aClient net.IP, aHostname string, aQType uint16) bool {
+	if r.audit.Load() {
+		return false
+	}
+
+	return r.adguard.blocks(r.ClientGroup(aClient), aHostname, aQType)
+} // BlockedType()
+
+/* _EoF_ */
@@ -0,0 +1,165 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"net"
+	"slices"
+	"testing"
+
+	adl "github.com/mwat56/dnscache/internal/adlist"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_adguardFilter(t *testing.T) {
+	tests := []struct {
+		name       string
+		types      []string
+		wantTypes  []uint16
+		wantExcept bool
+		wantOK     bool
+	}{
+		/* */
+		{"01 - all types", nil, nil, false, true},
+		{"02 - included", []string{"A", "HTTPS"}, []uint16{1, 65}, false, true},
+		{"03 - excluded", []string{"~A", "~AAAA"}, []uint16{1, 28}, true, true},
+		{"04 - mixed", []string{"A", "~AAAA"}, nil, false, false},
+		{"05 - unknown", []string{"WKS"}, nil, false, false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			types, except, ok := adguardFilter(tc.types)
+			if ok != tc.wantOK {
+				t.Fatalf("adguardFilter() ok = '%v', want '%v'", ok, tc.wantOK)
+			}
+			if !slices.Equal(types, tc.wantTypes) || (except != tc.wantExcept) {
+				t.Errorf("adguardFilter() = '%v', '%v', want '%v', '%v'",
+					types, except, tc.wantTypes, tc.wantExcept)
+			}
+		})
+	}
+} // Test_adguardFilter()
+
+func Test_adguardRewrite(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		want      TRewriteRule
+		wantBlock bool
+		wantOK    bool
+	}{
+		/* */
+		{"01 - short IP", "192.168.1.1",
+			TRewriteRule{Match: "*.home.tld", Type: RewriteIP, Target: "192.168.1.1"}, false, true},
+		{"02 - short hostname", "nas.home.tld",
+			TRewriteRule{Match: "*.home.tld", Type: RewriteCNAME, Target: "nas.home.tld"}, false, true},
+		{"03 - AAAA record", "NOERROR;AAAA;2001:db8::1",
+			TRewriteRule{Match: "*.home.tld", Type: RewriteIP, Target: "2001:db8::1"}, false, true},
+		{"04 - CNAME record", "noerror;cname;nas.home.tld",
+			TRewriteRule{Match: "*.home.tld", Type: RewriteCNAME, Target: "nas.home.tld"}, false, true},
+		{"05 - NXDOMAIN", "NXDOMAIN;;", TRewriteRule{}, true, true},
+		{"06 - other record", "NOERROR;TXT;hello", TRewriteRule{}, false, false},
+		{"07 - invalid IP", "NOERROR;A;nas.home.tld", TRewriteRule{}, false, false},
+		{"08 - empty", "", TRewriteRule{}, false, false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, block, ok := adguardRewrite("*.home.tld", tc.value)
+			if (ok != tc.wantOK) || (block != tc.wantBlock) {
+				t.Fatalf("adguardRewrite() = '%v', '%v', want '%v', '%v'", block, ok, tc.wantBlock, tc.wantOK)
+			}
+			if ok && !block && (got != tc.want) {
+				t.Errorf("adguardRewrite() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_adguardRewrite()
+
+func Test_TResolver_applyAdGuard(t *testing.T) {
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir()})
+	defer r.StopExpire()
+	_ = r.AddGroup("kids")
+	_ = r.AssignClient("192.168.1.0/24", "kids")
+	kid, other := net.ParseIP("192.168.1.10"), net.ParseIP("192.168.2.10")
+
+	r.applyAdGuard("", []adl.TAdGuardRule{
+		{Pattern: "*.games.tld", Clients: []string{"192.168.1.0/24"}},
+		{Pattern: "*.video.tld", Clients: []string{"kids"}, DNSTypes: []string{"AAAA"}},
+		{Pattern: "*.tracker.tld", DNSTypes: []string{"~A"}},
+		{Pattern: "*.home.tld", Rewrite: "192.168.1.1"},
+		{Pattern: "ads.tld", Rewrite: "REFUSED"},
+		{Pattern: "*.laptop.tld", Clients: []string{"Frank's laptop"}},
+		{Pattern: "*.games.tld", Clients: []string{"~kids"}},
+		{Pattern: "*.kids.tld", Clients: []string{"kids"}, Rewrite: "192.168.1.2"},
+	})
+
+	tests := []struct {
+		name     string
+		client   net.IP
+		hostname string
+		qType    uint16
+		want     bool
+	}{
+		/* */
+		{"01 - client range", kid, "www.games.tld", dnsTypeA, true},
+		{"02 - other client", other, "www.games.tld", dnsTypeA, false},
+		{"03 - group and type", kid, "www.video.tld", dnsTypeAAAA, true},
+		{"04 - other type", kid, "www.video.tld", dnsTypeA, false},
+		{"05 - excluded type", other, "www.tracker.tld", dnsTypeA, false},
+		{"06 - not excluded type", other, "www.tracker.tld", 65, true},
+		{"07 - unknown type", other, "www.tracker.tld", 0, false},
+		{"08 - blocking rewrite", other, "ADS.tld.", dnsTypeA, true},
+		{"09 - default group only", kid, "www.tracker.tld", 65, false},
+		{"10 - skipped client", other, "www.laptop.tld", dnsTypeA, false},
+		{"11 - skipped rewrite", kid, "www.kids.tld", dnsTypeA, false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := r.BlockedType(tc.client, tc.hostname, tc.qType); got != tc.want {
+				t.Errorf("BlockedType() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+
+	if got, _ := r.FetchFor(kid, "www.games.tld"); (1 != len(got)) || !got[0].Equal(net.IPv4zero) {
+		t.Errorf("FetchFor() = '%v', want '%v'", got, net.IPv4zero)
+	}
+	if got, _ := r.FetchFor(other, "nas.home.tld"); (1 != len(got)) || !got[0].Equal(net.ParseIP("192.168.1.1")) {
+		t.Errorf("FetchFor() = '%v', want '192.168.1.1'", got)
+	}
+	if got := len(r.Rewrites()); 1 != got {
+		t.Errorf("Rewrites() = '%d' rules, want '1'", got)
+	}
+
+	// Reloading the lists replaces their rules
+	r.applyAdGuard("", nil)
+	if r.BlockedType(kid, "www.games.tld", dnsTypeA) {
+		t.Error("BlockedType() = 'true' after reload, want 'false'")
+	}
+	if got := len(r.Rewrites()); 0 != got {
+		t.Errorf("Rewrites() = '%d' rules after reload, want '0'", got)
+	}
+
+	// Group lists' rules are removed together with the group
+	r.applyAdGuard("kids", []adl.TAdGuardRule{{Pattern: "*.video.tld", DNSTypes: []string{"HTTPS"}}})
+	if !r.BlockedType(kid, "www.video.tld", 65) {
+		t.Error("BlockedType() = 'false', want 'true'")
+	}
+	r.DeleteGroup("kids")
+	if r.adguard.blocks("kids", "www.video.tld", 65) {
+		t.Error("blocks() = 'true' after DeleteGroup(), want 'false'")
+	}
+} // Test_TResolver_applyAdGuard()
+
+/* _EoF_ */
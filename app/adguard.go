@@ -0,0 +1,50 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"encoding/binary"
+	"net"
+
+	"github.com/mwat56/dnscache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `handleBlockedTypeRequest()` answers a query blocked by the AdGuard
+// rules of the blocklists (e.g. `||example.com^$dnstype=HTTPS`) with
+// an empty response.
+//
+// Parameters:
+//   - `aConn`: The connection to write the response to.
+//   - `aAddr`: The address to send the response to.
+//   - `aRequest`: The DNS request message.
+//   - `aResolver`: The DNS resolver whose rules to check.
+//
+// Returns:
+//   - `bool`: `true` if the request was answered, `false` otherwise.
+func handleBlockedTypeRequest(aConn net.PacketConn, aAddr net.Addr, aRequest []byte,
+	aResolver *dnscache.TResolver) bool {
+	if 1 != binary.BigEndian.Uint16(aRequest[4:6]) {
+		return false
+	}
+	qType, qClass, ok := questionType(aRequest)
+	if !ok || (dnsClassIN != qClass) {
+		return false
+	}
+	if !aResolver.BlockedType(addrIP(aAddr), extractFirstHostname(aRequest), qType) {
+		return false
+	}
+	sendRcodeResponse(aConn, aAddr, aRequest, dnsRcodeNoError)
+
+	return true
+} // handleBlockedTypeRequest()
+
+/* _EoF_ */
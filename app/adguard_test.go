@@ -0,0 +1,72 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"encoding/binary"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mwat56/dnscache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_handleBlockedTypeRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(aWriter http.ResponseWriter, _ *http.Request) {
+		_, _ = aWriter.Write([]byte("[Adblock Plus 2.0]\n||ads.example.com^\n" +
+			"||tracker.example.com^$dnstype=HTTPS\n"))
+	}))
+	defer server.Close()
+
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	if err := resolver.LoadBlocklists([]string{server.URL + "/adguard.txt"}); nil != err {
+		t.Fatalf("LoadBlocklists() error = '%v'", err)
+	}
+
+	tests := []struct {
+		name    string
+		request []byte
+		want    bool
+	}{
+		/* */
+		{"01 - blocked type", createDNSQuery("www.tracker.example.com", 65), true},
+		{"02 - other type", createDNSQuery("www.tracker.example.com", dnsTypeA), false},
+		{"03 - other host", createDNSQuery("www.example.com", 65), false},
+		{"04 - other class", chaosQuery("www.tracker.example.com", 65, dnsClassCH), false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var response []byte
+			conn := &tMockPacketConn{
+				writeTo: func(aBuf []byte, _ net.Addr) (int, error) {
+					response = append([]byte{}, aBuf...)
+					return len(aBuf), nil
+				},
+			}
+			if got := handleBlockedTypeRequest(conn, &tMockAddr{}, tc.request, resolver); got != tc.want {
+				t.Fatalf("handleBlockedTypeRequest() = '%v', want '%v'", got, tc.want)
+			}
+			if !tc.want {
+				return
+			}
+			if got := binary.BigEndian.Uint16(response[2:4]) & 0xF; dnsRcodeNoError != got {
+				t.Errorf("RCODE = '%d', want '%d'", got, dnsRcodeNoError)
+			}
+			if got := binary.BigEndian.Uint16(response[6:8]); 0 != got {
+				t.Errorf("ANCount = '%d', want '0'", got)
+			}
+		})
+	}
+} // Test_handleBlockedTypeRequest()
+
+/* _EoF_ */
@@ -0,0 +1,1128 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mwat56/dnscache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tAdminServer` provides the HTTP admin API of the DNS cache.
+	tAdminServer struct {
+		resolver *dnscache.TResolver
+		mux      *http.ServeMux
+		auth     *tAuth         // authentication of the API's callers
+		config   tConfiguration // used to reload the lists
+		progress tLoadProgress  // progress of the latest blocklist load
+	}
+
+	// `tAuditState` is the admin API's answer about the audit mode.
+	tAuditState struct {
+		Audit bool `json:"audit"`
+	}
+
+	// `tLoadProgress` collects the progress of the latest blocklist
+	// load per URL.
+	tLoadProgress struct {
+		sync.Mutex
+		started time.Time
+		sources map[string]dnscache.TLoadProgress
+	}
+
+	// `tProgressState` is the admin API's answer about the progress
+	// of the latest blocklist load.
+	tProgressState struct {
+		Loading bool                     `json:"loading"`
+		Sources []dnscache.TLoadProgress `json:"sources"`
+	}
+
+	// `tQueryAnswer` is the admin API's answer to a hostname query.
+	tQueryAnswer struct {
+		Name string   `json:"name"`
+		IPs  []string `json:"ips"`
+	}
+
+	// `tMetricsState` is the admin API's answer about the metrics.
+	tMetricsState struct {
+		Time      time.Time `json:"time"`
+		Lookups   uint32    `json:"lookups"`
+		Hits      uint32    `json:"hits"`
+		Misses    uint32    `json:"misses"`
+		Retries   uint32    `json:"retries"`
+		Errors    uint32    `json:"errors"`
+		Blocked   uint32    `json:"blocked"`
+		Peak      uint32    `json:"peak"`
+		Panics    uint64    `json:"panics"`      // recovered request handlers
+		Spoofed   uint64    `json:"spoofed"`     // dropped mismatched answers
+		Limited   uint64    `json:"rateLimited"` // dropped by the rate limits
+		CacheSize int       `json:"cacheSize"`
+		HitRatio  float64   `json:"hitRatio"`
+
+		// cache hits and misses per query type (e.g. "AAAA")
+		Types map[string]dnscache.TTypeMetrics `json:"types,omitempty"`
+
+		// switches and blocked queries of the blocklist categories
+		Categories []dnscache.TCategoryState `json:"categories,omitempty"`
+
+		// circuit breakers of the upstream servers (if enabled)
+		Upstreams []dnscache.TBreakerState `json:"upstreams,omitempty"`
+
+		// response latencies of the upstream servers
+		Latencies []dnscache.TUpstreamLatency `json:"latencies,omitempty"`
+	}
+
+	// `tPage` is the requested part of a pattern list.
+	tPage struct {
+		prefix string // only patterns starting with this
+		offset int    // number of matching patterns to skip
+		limit  int    // max. number of patterns (`0` for all)
+	}
+
+	// `tPauseState` is the admin API's answer about a blocking pause.
+	tPauseState struct {
+		Paused      bool       `json:"paused"`
+		PausedUntil *time.Time `json:"pausedUntil,omitempty"`
+	}
+)
+
+const (
+	// `defTopCount` is the default number of entries of the top lists.
+	defTopCount = 10
+)
+
+var (
+	// `gAdminLog` is the logger used by the admin API.
+	gAdminLog = dnscache.Logger("admin")
+
+	// `gQTypeNames` are the names of the common DNS query types.
+	gQTypeNames = map[uint16]string{
+		dnsTypeA:     "A",
+		dnsTypeNS:    "NS",
+		dnsTypeCNAME: "CNAME",
+		dnsTypeSOA:   "SOA",
+		dnsTypePTR:   "PTR",
+		15:           "MX",
+		16:           "TXT",
+		dnsTypeAAAA:  "AAAA",
+		33:           "SRV",
+		65:           "HTTPS",
+	}
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `parseCount()` parses the `count` form value of a request.
+//
+// Parameters:
+//   - `aRequest`: The HTTP request to read the value from.
+//
+// Returns:
+//   - `int`: The requested number of entries (`defTopCount` if missing).
+//   - `error`: `nil` if the number is valid, the error otherwise.
+func parseCount(aRequest *http.Request) (int, error) {
+	value := aRequest.FormValue("count")
+	if "" == value {
+		return defTopCount, nil
+	}
+	count, err := strconv.Atoi(value)
+	if (nil != err) || (0 > count) {
+		return 0, fmt.Errorf("invalid count: %q", value)
+	}
+
+	return count, nil
+} // parseCount()
+
+// `parsePage()` parses the `offset`, `limit`, and `prefix` form
+// values of a request listing patterns.
+//
+// Parameters:
+//   - `aRequest`: The HTTP request to read the values from.
+//
+// Returns:
+//   - `rPage`: The requested part of the list (all patterns if the
+//     values are missing).
+//   - `rErr`: `nil` if the values are valid, the error otherwise.
+func parsePage(aRequest *http.Request) (rPage tPage, rErr error) {
+	number := func(aName string) int {
+		value := aRequest.FormValue(aName)
+		if "" == value {
+			return 0
+		}
+		result, err := strconv.Atoi(value)
+		if (nil != err) || (0 > result) {
+			rErr = errors.Join(rErr, fmt.Errorf("invalid %s: %q", aName, value))
+			return 0
+		}
+		return result
+	} // number()
+
+	rPage.offset = number("offset")
+	rPage.limit = number("limit")
+	rPage.prefix = strings.ToLower(strings.TrimSpace(aRequest.FormValue("prefix")))
+
+	return
+} // parsePage()
+
+// `parseDumpFormat()` parses the name of a cache dump format.
+//
+// Parameters:
+//   - `aName`: The format's name ("hosts", "json", "csv", or "bind").
+//
+// Returns:
+//   - `dnscache.TDumpFormat`: The dump format.
+//   - `string`: The format's content type.
+//   - `error`: `nil` if the name is valid, the error otherwise.
+func parseDumpFormat(aName string) (dnscache.TDumpFormat, string, error) {
+	switch strings.ToLower(strings.TrimSpace(aName)) {
+	case "hosts":
+		return dnscache.DumpHosts, "text/plain; charset=utf-8", nil
+	case "json":
+		return dnscache.DumpJSON, "application/json", nil
+	case "csv":
+		return dnscache.DumpCSV, "text/csv; charset=utf-8", nil
+	case "bind", "zone":
+		return dnscache.DumpBIND, "text/dns; charset=utf-8", nil
+	}
+
+	return dnscache.DumpHosts, "", fmt.Errorf("invalid dump format: %q", aName)
+} // parseDumpFormat()
+
+// `parseListFormat()` parses the name of a deny list format.
+//
+// Parameters:
+//   - `aName`: The format's name ("simple", "hosts", or "abp").
+//
+// Returns:
+//   - `dnscache.TListFormat`: The list format.
+//   - `error`: `nil` if the name is valid, the error otherwise.
+func parseListFormat(aName string) (dnscache.TListFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(aName)) {
+	case "simple":
+		return dnscache.ListSimple, nil
+	case "hosts":
+		return dnscache.ListHosts, nil
+	case "abp", "adblock":
+		return dnscache.ListABP, nil
+	}
+
+	return dnscache.ListSimple, fmt.Errorf("invalid list format: %q", aName)
+} // parseListFormat()
+
+// `parseQType()` parses a DNS query type given by its name (e.g.
+// `AAAA`) or number.
+//
+// Parameters:
+//   - `aName`: The query type's name or number.
+//
+// Returns:
+//   - `uint16`: The query type.
+//   - `error`: `nil` if the query type is valid, the error otherwise.
+func parseQType(aName string) (uint16, error) {
+	name := strings.ToUpper(strings.TrimSpace(aName))
+	for qType, qName := range gQTypeNames {
+		if qName == name {
+			return qType, nil
+		}
+	}
+	qType, err := strconv.ParseUint(name, 10, 16)
+	if (nil != err) || (0 == qType) {
+		return 0, fmt.Errorf("invalid query type: %q", aName)
+	}
+
+	return uint16(qType), nil
+} // parseQType()
+
+// `qTypeName()` returns the name of a DNS query type.
+//
+// Parameters:
+//   - `aQType`: The query type.
+//
+// Returns:
+//   - `string`: The type's name, or its number if it's unknown.
+func qTypeName(aQType uint16) string {
+	if name, ok := gQTypeNames[aQType]; ok {
+		return name
+	}
+
+	return strconv.Itoa(int(aQType))
+} // qTypeName()
+
+// `parseDuration()` parses the `duration` form value of a request.
+//
+// Parameters:
+//   - `aRequest`: The HTTP request to read the value from.
+//
+// Returns:
+//   - `time.Duration`: The requested duration.
+//   - `error`: `nil` if the duration is valid, the error otherwise.
+func parseDuration(aRequest *http.Request) (time.Duration, error) {
+	value := aRequest.FormValue("duration")
+	if "" == value {
+		return 0, errors.New("missing duration")
+	}
+	duration, err := time.ParseDuration(value)
+	if nil != err {
+		return 0, fmt.Errorf("invalid duration: %q", value)
+	}
+	if 0 > duration {
+		return 0, fmt.Errorf("negative duration: %q", value)
+	}
+
+	return duration, nil
+} // parseDuration()
+
+// `writeError()` sends an error message as JSON object.
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aStatus`: The HTTP status code to send.
+//   - `aErr`: The error to report.
+func writeError(aWriter http.ResponseWriter, aStatus int, aErr error) {
+	writeJSON(aWriter, aStatus, map[string]string{"error": aErr.Error()})
+} // writeError()
+
+// `writeJSON()` sends the given data as JSON.
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aStatus`: The HTTP status code to send.
+//   - `aData`: The data to encode.
+func writeJSON(aWriter http.ResponseWriter, aStatus int, aData any) {
+	aWriter.Header().Set("Content-Type", "application/json; charset=utf-8")
+	aWriter.Header().Set("Cache-Control", "no-store")
+	aWriter.WriteHeader(aStatus)
+	if err := json.NewEncoder(aWriter).Encode(aData); nil != err {
+		gAdminLog.Warn("failed to write answer", "error", err)
+	}
+} // writeJSON()
+
+// ---------------------------------------------------------------------------
+// `tLoadProgress` methods:
+
+// `state()` returns the progress of the latest blocklist load.
+//
+// Returns:
+//   - `tProgressState`: The progress sorted by URL.
+func (lp *tLoadProgress) state() tProgressState {
+	lp.Lock()
+	defer lp.Unlock()
+
+	result := tProgressState{
+		Sources: make([]dnscache.TLoadProgress, 0, len(lp.sources)),
+	}
+	for _, source := range lp.sources {
+		result.Sources = append(result.Sources, source)
+		if !source.Done {
+			result.Loading = true
+		}
+	}
+	slices.SortFunc(result.Sources, func(aA, aB dnscache.TLoadProgress) int {
+		return strings.Compare(aA.Source, aB.Source)
+	})
+
+	return result
+} // state()
+
+// `update()` records the progress of a blocklist; it's used as the
+// resolver's `dnscache.TProgressFunc`.
+//
+// A newer load replaces the progress of all previous ones.
+//
+// Parameters:
+//   - `aProgress`: The progress of a blocklist.
+func (lp *tLoadProgress) update(aProgress dnscache.TLoadProgress) {
+	lp.Lock()
+	defer lp.Unlock()
+
+	if aProgress.Started.Before(lp.started) {
+		return // outdated
+	}
+	if aProgress.Started.After(lp.started) || (nil == lp.sources) {
+		lp.started = aProgress.Started
+		lp.sources = make(map[string]dnscache.TLoadProgress)
+	}
+	lp.sources[aProgress.Source] = aProgress
+} // update()
+
+// ---------------------------------------------------------------------------
+// `tAdminServer` constructor:
+
+// `newAdminServer()` creates the admin API for the given resolver.
+//
+// Parameters:
+//   - `aResolver`: The resolver to administrate.
+//   - `aConfig`: The configuration the resolver was set up with.
+//
+// Returns:
+//   - `*tAdminServer`: The new admin API handler.
+func newAdminServer(aResolver *dnscache.TResolver, aConfig tConfiguration) *tAdminServer {
+	as := &tAdminServer{
+		resolver: aResolver,
+		mux:      http.NewServeMux(),
+		auth:     newAuth(aConfig),
+		config:   aConfig,
+	}
+	aResolver.SetLoadProgress(as.progress.update)
+
+	as.mux.HandleFunc("GET /api/allow", as.handleAllowList)
+	as.mux.HandleFunc("POST /api/allow", as.handleAllowSet)
+	as.mux.HandleFunc("GET /api/allow/suggestions", as.handleAllowSuggestions)
+	as.mux.HandleFunc("GET /api/allowlist", as.handleAllowlistGet)
+	as.mux.HandleFunc("POST /api/allowlist", as.handleAllowlistAdd)
+	as.mux.HandleFunc("GET /api/audit", as.handleAuditGet)
+	as.mux.HandleFunc("POST /api/audit", as.handleAuditSet)
+	as.mux.HandleFunc("GET /api/cache", as.handleCacheDump)
+	as.mux.HandleFunc("POST /api/cache/flush", as.handleCacheFlush)
+	as.mux.HandleFunc("GET /api/categories", as.handleCategories)
+	as.mux.HandleFunc("POST /api/categories", as.handleCategorySet)
+	as.mux.HandleFunc("GET /api/denylist", as.handleDenylist)
+	as.mux.HandleFunc("POST /api/denylist", as.handleDenylistAdd)
+	as.mux.HandleFunc("DELETE /api/denylist", as.handleDenylistDelete)
+	as.mux.HandleFunc("GET /api/lists/find", as.handleListsFind)
+	as.mux.HandleFunc("POST /api/lists/patch", as.handleListsPatch)
+	as.mux.HandleFunc("GET /api/lists/progress", as.handleListsProgress)
+	as.mux.HandleFunc("GET /api/lists/report", as.handleListsReport)
+	as.mux.HandleFunc("POST /api/lists/update", as.handleListsUpdate)
+	as.mux.HandleFunc("GET /api/metrics", as.handleMetrics)
+	as.mux.HandleFunc("GET /api/pause", as.handlePauseGet)
+	as.mux.HandleFunc("POST /api/pause", as.handlePauseSet)
+	as.mux.HandleFunc("GET /api/query", as.handleQuery)
+	as.mux.HandleFunc("GET /api/top/audited", as.handleTopAudited)
+	as.mux.HandleFunc("GET /api/top/blocked", as.handleTopBlocked)
+	as.mux.HandleFunc("GET /api/top/queries", as.handleTopQueries)
+	as.mux.HandleFunc("GET /api/upstreams", as.handleUpstreams)
+	as.mux.HandleFunc("GET /api/version", as.handleVersion)
+
+	// The probes must work without authentication
+	as.mux.HandleFunc("GET /healthz", as.handleHealthz)
+	as.mux.HandleFunc("GET /readyz", as.handleReadyz)
+
+	return as
+} // newAdminServer()
+
+// ---------------------------------------------------------------------------
+// `tPage` methods:
+
+// `patterns()` returns the requested part of a pattern list.
+//
+// The list is read lazily and only up to the page's end, so the first
+// pages of a huge list are cheap.
+//
+// Parameters:
+//   - `aList`: The iterator over the list's patterns.
+//
+// Returns:
+//   - `rList`: The page's patterns.
+//   - `rMore`: Whether further patterns follow the page.
+func (p tPage) patterns(aList iter.Seq[string]) (rList []string, rMore bool) {
+	rList = []string{}
+	skip := p.offset
+	for pattern := range aList {
+		if ("" != p.prefix) && !strings.HasPrefix(pattern, p.prefix) {
+			continue
+		}
+		if 0 < skip {
+			skip--
+			continue
+		}
+		if (0 < p.limit) && (p.limit == len(rList)) {
+			rMore = true
+			break
+		}
+		rList = append(rList, pattern)
+	}
+
+	return
+} // patterns()
+
+// ---------------------------------------------------------------------------
+// `tAdminServer` methods:
+
+// `handleAllowList()` lists the temporarily allowed hostname patterns.
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+func (as *tAdminServer) handleAllowList(aWriter http.ResponseWriter, aRequest *http.Request) {
+	list := as.resolver.TemporaryAllows()
+	if nil == list {
+		list = []dnscache.TTempAllow{}
+	}
+
+	writeJSON(aWriter, http.StatusOK, list)
+} // handleAllowList()
+
+// `handleAllowlistGet()` lists the patterns of the default allow list.
+//
+// The optional `offset`, `limit`, and `prefix` form values select a
+// part of the list (see [writePatterns]).
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+func (as *tAdminServer) handleAllowlistGet(aWriter http.ResponseWriter, aRequest *http.Request) {
+	as.writePatterns(aWriter, aRequest, as.resolver.AllowSeq)
+} // handleAllowlistGet()
+
+// `handleAllowlistAdd()` adds the request's `pattern` form value to
+// the default allow list.
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+func (as *tAdminServer) handleAllowlistAdd(aWriter http.ResponseWriter, aRequest *http.Request) {
+	pattern := aRequest.FormValue("pattern")
+	if err := as.resolver.AddAllow(pattern); nil != err {
+		writeError(aWriter, http.StatusBadRequest, err)
+		return
+	}
+	gAdminLog.Info("allow pattern added", "pattern", pattern)
+
+	writeJSON(aWriter, http.StatusOK, map[string]string{"pattern": pattern})
+} // handleAllowlistAdd()
+
+// `handleAllowSet()` allows a hostname pattern temporarily.
+//
+// The request's `host` form value names the pattern to allow and its
+// `duration` value (e.g. `5m`) how long; a duration of `0` removes
+// the pattern again.
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+func (as *tAdminServer) handleAllowSet(aWriter http.ResponseWriter, aRequest *http.Request) {
+	duration, err := parseDuration(aRequest)
+	if nil != err {
+		writeError(aWriter, http.StatusBadRequest, err)
+		return
+	}
+	host := aRequest.FormValue("host")
+	if !as.resolver.AllowTemporarily(host, duration) {
+		writeError(aWriter, http.StatusBadRequest,
+			fmt.Errorf("invalid host pattern: %q", host))
+		return
+	}
+	gAdminLog.Info("temporary allow", "host", host, "duration", duration)
+
+	as.handleAllowList(aWriter, aRequest)
+} // handleAllowSet()
+
+// `handleAllowSuggestions()` lists the blocked hostnames single
+// clients queried over and over again, i.e. the hostnames which are
+// frequently blocked and might better be allowed.
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+func (as *tAdminServer) handleAllowSuggestions(aWriter http.ResponseWriter, aRequest *http.Request) {
+	list := as.resolver.AllowSuggestions()
+	if nil == list {
+		list = []dnscache.TAllowSuggestion{}
+	}
+
+	writeJSON(aWriter, http.StatusOK, list)
+} // handleAllowSuggestions()
+
+// `handleAuditGet()` reports whether the resolver is in audit mode.
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+func (as *tAdminServer) handleAuditGet(aWriter http.ResponseWriter, aRequest *http.Request) {
+	writeJSON(aWriter, http.StatusOK, tAuditState{Audit: as.resolver.AuditMode()})
+} // handleAuditGet()
+
+// `handleAuditSet()` switches the audit mode on or off.
+//
+// The request's `audit` form value (e.g. `true`) determines whether
+// the deny lists' matches are only logged and counted instead of
+// being blocked (see [dnscache.TResolver.SetAuditMode]).
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+func (as *tAdminServer) handleAuditSet(aWriter http.ResponseWriter, aRequest *http.Request) {
+	value := aRequest.FormValue("audit")
+	audit, err := strconv.ParseBool(value)
+	if nil != err {
+		writeError(aWriter, http.StatusBadRequest, fmt.Errorf("invalid audit mode: %q", value))
+		return
+	}
+	as.resolver.SetAuditMode(audit)
+	gAdminLog.Info("audit mode set", "audit", audit)
+
+	as.handleAuditGet(aWriter, aRequest)
+} // handleAuditSet()
+
+// `handleCacheDump()` lists all cached hostnames with their addresses.
+//
+// With a `format` form value ("hosts", "json", "csv", or "bind") the
+// cache is written in that format instead.
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+func (as *tAdminServer) handleCacheDump(aWriter http.ResponseWriter, aRequest *http.Request) {
+	name := aRequest.FormValue("format")
+	if "" == name {
+		writeJSON(aWriter, http.StatusOK, cacheEntries(aRequest.Context(), as.resolver))
+		return
+	}
+	format, contentType, err := parseDumpFormat(name)
+	if nil != err {
+		writeError(aWriter, http.StatusBadRequest, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err = as.resolver.Dump(&buf, format); nil != err {
+		writeError(aWriter, http.StatusInternalServerError, err)
+		return
+	}
+	aWriter.Header().Set("Content-Type", contentType)
+	aWriter.WriteHeader(http.StatusOK)
+	_, _ = aWriter.Write(buf.Bytes())
+} // handleCacheDump()
+
+// `handleCacheFlush()` removes entries from the cache.
+//
+// With a `suffix` form value only the entries of that domain and its
+// subdomains are removed, with a `pattern` value those matching the
+// wildcard pattern, with a `type` value those of that query type;
+// otherwise the whole cache is flushed.
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+func (as *tAdminServer) handleCacheFlush(aWriter http.ResponseWriter, aRequest *http.Request) {
+	var (
+		err     error
+		flushed int
+	)
+	suffix, pattern := aRequest.FormValue("suffix"), aRequest.FormValue("pattern")
+	qType := aRequest.FormValue("type")
+	switch {
+	case 1 < len(slices.DeleteFunc([]string{suffix, pattern, qType}, func(aValue string) bool {
+		return "" == aValue
+	})):
+		err = errors.New("only one of suffix, pattern, or type expected")
+	case "" != suffix:
+		flushed = as.resolver.FlushSuffix(suffix)
+	case "" != pattern:
+		flushed, err = as.resolver.FlushMatching(pattern)
+	case "" != qType:
+		var code uint16
+		if code, err = parseQType(qType); nil == err {
+			flushed = as.resolver.FlushType(code)
+		}
+	default:
+		flushed = as.resolver.Flush()
+	}
+	if nil != err {
+		writeError(aWriter, http.StatusBadRequest, err)
+		return
+	}
+	gAdminLog.Info("cache flushed", "suffix", suffix, "pattern", pattern,
+		"type", qType, "entries", flushed)
+
+	writeJSON(aWriter, http.StatusOK, map[string]int{"flushed": flushed})
+} // handleCacheFlush()
+
+// `handleCategories()` lists the blocklist categories with their
+// switches and the number of queries each one blocked.
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+func (as *tAdminServer) handleCategories(aWriter http.ResponseWriter, aRequest *http.Request) {
+	writeJSON(aWriter, http.StatusOK, as.resolver.Categories())
+} // handleCategories()
+
+// `handleCategorySet()` switches the blocking of a category on or off.
+//
+// The request's `name` form value selects the category while its
+// `enabled` form value (e.g. `false`) determines whether the
+// category's hostnames are blocked.
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+func (as *tAdminServer) handleCategorySet(aWriter http.ResponseWriter, aRequest *http.Request) {
+	name, value := aRequest.FormValue("name"), aRequest.FormValue("enabled")
+	enabled, err := strconv.ParseBool(value)
+	if nil != err {
+		writeError(aWriter, http.StatusBadRequest, fmt.Errorf("invalid category switch: %q", value))
+		return
+	}
+	if err = as.resolver.SetCategoryEnabled(name, enabled); nil != err {
+		writeError(aWriter, http.StatusNotFound, err)
+		return
+	}
+	gAdminLog.Info("category switched", "category", name, "enabled", enabled)
+
+	as.handleCategories(aWriter, aRequest)
+} // handleCategorySet()
+
+// `handleDenylist()` lists the patterns of the default deny list.
+//
+// The optional `offset`, `limit`, and `prefix` form values select a
+// part of the list (see [writePatterns]). With a `format` form value
+// ("simple", "hosts", or "abp") the whole list is written as text in
+// that format instead.
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+func (as *tAdminServer) handleDenylist(aWriter http.ResponseWriter, aRequest *http.Request) {
+	if name := aRequest.FormValue("format"); "" != name {
+		format, err := parseListFormat(name)
+		if nil != err {
+			writeError(aWriter, http.StatusBadRequest, err)
+			return
+		}
+		var buf bytes.Buffer
+		if err = as.resolver.WriteDenylist(&buf, format); nil != err {
+			writeError(aWriter, http.StatusInternalServerError, err)
+			return
+		}
+		aWriter.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		aWriter.WriteHeader(http.StatusOK)
+		_, _ = aWriter.Write(buf.Bytes())
+		return
+	}
+
+	as.writePatterns(aWriter, aRequest, as.resolver.DenySeq)
+} // handleDenylist()
+
+// `handleDenylistAdd()` adds the request's `pattern` form value to
+// the default deny list.
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+func (as *tAdminServer) handleDenylistAdd(aWriter http.ResponseWriter, aRequest *http.Request) {
+	pattern := aRequest.FormValue("pattern")
+	if err := as.resolver.AddDeny(pattern); nil != err {
+		writeError(aWriter, http.StatusBadRequest, err)
+		return
+	}
+	gAdminLog.Info("deny pattern added", "pattern", pattern)
+
+	writeJSON(aWriter, http.StatusOK, map[string]string{"pattern": pattern})
+} // handleDenylistAdd()
+
+// `handleDenylistDelete()` removes the request's `pattern` form value
+// from the default deny list.
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+func (as *tAdminServer) handleDenylistDelete(aWriter http.ResponseWriter, aRequest *http.Request) {
+	pattern := aRequest.FormValue("pattern")
+	if !as.resolver.DeleteDeny(pattern) {
+		writeError(aWriter, http.StatusNotFound,
+			fmt.Errorf("pattern not found: %q", pattern))
+		return
+	}
+	gAdminLog.Info("deny pattern removed", "pattern", pattern)
+
+	writeJSON(aWriter, http.StatusOK, map[string]string{"pattern": pattern})
+} // handleDenylistDelete()
+
+// `handleListsFind()` lists the allow/deny patterns matching the
+// request's `q` form value (a substring or wildcard).
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+func (as *tAdminServer) handleListsFind(aWriter http.ResponseWriter, aRequest *http.Request) {
+	query := strings.TrimSpace(aRequest.FormValue("q"))
+	if "" == query {
+		writeError(aWriter, http.StatusBadRequest, errors.New("missing query"))
+		return
+	}
+
+	writeJSON(aWriter, http.StatusOK, as.resolver.FindPatterns(query))
+} // handleListsFind()
+
+// `handleListsPatch()` applies the request's `diff` form value to the
+// default allow/deny lists (see [dnscache.TResolver.PatchLists]).
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+func (as *tAdminServer) handleListsPatch(aWriter http.ResponseWriter, aRequest *http.Request) {
+	diff := aRequest.FormValue("diff")
+	if "" == strings.TrimSpace(diff) {
+		writeError(aWriter, http.StatusBadRequest, errors.New("missing diff"))
+		return
+	}
+	if err := as.resolver.PatchLists(strings.NewReader(diff)); nil != err {
+		gAdminLog.Warn("lists patch failed", "error", err)
+		writeError(aWriter, http.StatusBadRequest, err)
+		return
+	}
+	gAdminLog.Info("lists patched")
+
+	writeJSON(aWriter, http.StatusOK, map[string]string{"status": "ok"})
+} // handleListsPatch()
+
+// `handleListsProgress()` reports the progress of the latest (or
+// running) blocklist load, i.e. the lines read and patterns added
+// per URL.
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+func (as *tAdminServer) handleListsProgress(aWriter http.ResponseWriter, aRequest *http.Request) {
+	writeJSON(aWriter, http.StatusOK, as.progress.state())
+} // handleListsProgress()
+
+// `handleListsReport()` reports the statistics of the last blocklist
+// load, i.e. the lines read, patterns added, duplicates, and rejected
+// entries per URL.
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+func (as *tAdminServer) handleListsReport(aWriter http.ResponseWriter, aRequest *http.Request) {
+	writeJSON(aWriter, http.StatusOK, as.resolver.BlocklistReport())
+} // handleListsReport()
+
+// `handleListsUpdate()` reloads all configured allow/deny lists.
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+func (as *tAdminServer) handleListsUpdate(aWriter http.ResponseWriter, aRequest *http.Request) {
+	err := errors.Join(
+		applyLists(as.resolver, as.config),
+		applyGroups(as.resolver, as.config),
+		applyCategories(as.resolver, as.config),
+		importPiHole(as.resolver, as.config))
+	if nil != err {
+		gAdminLog.Warn("lists update failed", "error", err)
+		writeError(aWriter, http.StatusBadGateway, err)
+		return
+	}
+	gAdminLog.Info("lists updated")
+
+	writeJSON(aWriter, http.StatusOK, map[string]string{"status": "ok"})
+} // handleListsUpdate()
+
+// `handleMetrics()` reports the resolver's metrics.
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+func (as *tAdminServer) handleMetrics(aWriter http.ResponseWriter, aRequest *http.Request) {
+	m := as.resolver.Metrics()
+	state := tMetricsState{
+		Time:      time.Now(),
+		Lookups:   m.Lookups,
+		Hits:      m.Hits,
+		Misses:    m.Misses,
+		Retries:   m.Retries,
+		Errors:    m.Errors,
+		Blocked:   m.Blocked,
+		Peak:      m.Peak,
+		Panics:    gPanics.Load(),
+		Spoofed:   gSpoofed.Load(),
+		Limited:   gRateLimited.Load(),
+		CacheSize: as.resolver.Len(),
+	}
+	if 0 < m.Lookups {
+		state.HitRatio = float64(m.Hits) / float64(m.Lookups)
+	}
+	for qType, tm := range as.resolver.TypeMetrics() {
+		if nil == state.Types {
+			state.Types = make(map[string]dnscache.TTypeMetrics)
+		}
+		state.Types[qTypeName(qType)] = tm
+	}
+	state.Categories = as.resolver.Categories()
+	state.Upstreams = as.resolver.Breakers()
+	state.Latencies = as.resolver.Latencies()
+
+	writeJSON(aWriter, http.StatusOK, state)
+} // handleMetrics()
+
+// `handlePauseGet()` reports whether blocking is currently paused.
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+func (as *tAdminServer) handlePauseGet(aWriter http.ResponseWriter, aRequest *http.Request) {
+	var state tPauseState
+	if until := as.resolver.BlockingPausedUntil(); !until.IsZero() {
+		state.Paused = true
+		state.PausedUntil = &until
+	}
+
+	writeJSON(aWriter, http.StatusOK, state)
+} // handlePauseGet()
+
+// `handlePauseSet()` pauses blocking for the requested duration.
+//
+// The request's `duration` form value (e.g. `10m`) determines how long
+// blocking is paused; a duration of `0` re-enables blocking at once.
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+func (as *tAdminServer) handlePauseSet(aWriter http.ResponseWriter, aRequest *http.Request) {
+	duration, err := parseDuration(aRequest)
+	if nil != err {
+		writeError(aWriter, http.StatusBadRequest, err)
+		return
+	}
+	as.resolver.PauseBlocking(duration)
+	gAdminLog.Info("blocking paused", "duration", duration)
+
+	as.handlePauseGet(aWriter, aRequest)
+} // handlePauseSet()
+
+// `handleTopAudited()` lists the hostnames most often matched by a
+// deny list in audit mode.
+//
+// The request's optional `count` form value limits the number of
+// entries (`0` for all).
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+func (as *tAdminServer) handleTopAudited(aWriter http.ResponseWriter, aRequest *http.Request) {
+	count, err := parseCount(aRequest)
+	if nil != err {
+		writeError(aWriter, http.StatusBadRequest, err)
+		return
+	}
+
+	writeJSON(aWriter, http.StatusOK, as.resolver.TopAudited(count))
+} // handleTopAudited()
+
+// `handleTopBlocked()` lists the most often blocked hostnames.
+//
+// The request's optional `count` form value limits the number of
+// entries (`0` for all).
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+func (as *tAdminServer) handleTopBlocked(aWriter http.ResponseWriter, aRequest *http.Request) {
+	count, err := parseCount(aRequest)
+	if nil != err {
+		writeError(aWriter, http.StatusBadRequest, err)
+		return
+	}
+
+	writeJSON(aWriter, http.StatusOK, as.resolver.TopBlocked(count))
+} // handleTopBlocked()
+
+// `handleTopQueries()` lists the most often queried hostnames.
+//
+// The request's optional `count` form value limits the number of
+// entries (`0` for all).
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+func (as *tAdminServer) handleTopQueries(aWriter http.ResponseWriter, aRequest *http.Request) {
+	count, err := parseCount(aRequest)
+	if nil != err {
+		writeError(aWriter, http.StatusBadRequest, err)
+		return
+	}
+
+	writeJSON(aWriter, http.StatusOK, as.resolver.TopQueries(count))
+} // handleTopQueries()
+
+// `handleUpstreams()` lists the states of the upstream servers'
+// circuit breakers.
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+func (as *tAdminServer) handleUpstreams(aWriter http.ResponseWriter, aRequest *http.Request) {
+	upstreams := as.resolver.Breakers()
+	if nil == upstreams {
+		upstreams = []dnscache.TBreakerState{}
+	}
+
+	writeJSON(aWriter, http.StatusOK, upstreams)
+} // handleUpstreams()
+
+// `handleQuery()` resolves the request's `name` form value.
+//
+// If the request's `trace` form value is set the answer is the
+// resolution's trace (see [dnscache.TResolver.Trace]) instead.
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+func (as *tAdminServer) handleQuery(aWriter http.ResponseWriter, aRequest *http.Request) {
+	name := aRequest.FormValue("name")
+	if "" == name {
+		writeError(aWriter, http.StatusBadRequest, errors.New("missing name"))
+		return
+	}
+	if "" != aRequest.FormValue("trace") {
+		writeJSON(aWriter, http.StatusOK, as.resolver.Trace(aRequest.Context(), name))
+		return
+	}
+	ips, err := as.resolver.Fetch(name)
+	if nil != err {
+		writeError(aWriter, http.StatusBadGateway, err)
+		return
+	}
+
+	answer := tQueryAnswer{Name: name, IPs: make([]string, 0, len(ips))}
+	for _, ip := range ips {
+		answer.IPs = append(answer.IPs, ip.String())
+	}
+
+	writeJSON(aWriter, http.StatusOK, answer)
+} // handleQuery()
+
+// `ServeHTTP()` implements the `http.Handler` interface.
+//
+// All API calls require an authenticated caller (if authentication
+// is configured) and every mutating call is written to the audit log.
+// The static dashboard assets and the health probes are served
+// without authentication.
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+func (as *tAdminServer) ServeHTTP(aWriter http.ResponseWriter, aRequest *http.Request) {
+	if !strings.HasPrefix(aRequest.URL.Path, "/api/") {
+		as.mux.ServeHTTP(aWriter, aRequest)
+		return
+	}
+
+	identity, ok := as.auth.identify(aRequest)
+	if !ok {
+		if isMutating(aRequest) {
+			gAuditLog.Warn("unauthorised admin call",
+				"method", aRequest.Method, "path", aRequest.URL.Path,
+				"remote", aRequest.RemoteAddr)
+		}
+		aWriter.Header().Set("WWW-Authenticate", `Bearer realm="dnscache"`)
+		writeError(aWriter, http.StatusUnauthorized, errUnauthorised)
+		return
+	}
+	if !isMutating(aRequest) {
+		as.mux.ServeHTTP(aWriter, aRequest)
+		return
+	}
+
+	recorder := &tStatusRecorder{ResponseWriter: aWriter, status: http.StatusOK}
+	as.mux.ServeHTTP(recorder, aRequest)
+	_ = aRequest.ParseForm() // already parsed by the handler (if any)
+	gAuditLog.Info("admin call",
+		"method", aRequest.Method, "path", aRequest.URL.Path,
+		"params", aRequest.Form.Encode(), "identity", identity,
+		"remote", aRequest.RemoteAddr, "status", recorder.status)
+} // ServeHTTP()
+
+// `writePatterns()` sends the requested part of a pattern list as a
+// JSON array.
+//
+// The request's `prefix` form value selects the patterns starting with
+// it, its `offset` value skips that many of them, and its `limit` value
+// restricts their number. If further patterns follow, the answer's
+// `X-Next-Offset` header gives the offset of the next page.
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+//   - `aList`: The function returning the list's patterns.
+func (as *tAdminServer) writePatterns(aWriter http.ResponseWriter, aRequest *http.Request, aList func(context.Context) iter.Seq[string]) {
+	page, err := parsePage(aRequest)
+	if nil != err {
+		writeError(aWriter, http.StatusBadRequest, err)
+		return
+	}
+
+	list, more := page.patterns(aList(aRequest.Context()))
+	if more {
+		aWriter.Header().Set("X-Next-Offset", strconv.Itoa(page.offset+len(list)))
+	}
+
+	writeJSON(aWriter, http.StatusOK, list)
+} // writePatterns()
+
+// ---------------------------------------------------------------------------
+
+// `startAdminServer()` starts the admin API in the background.
+//
+// If a TLS certificate is configured the API is served via HTTPS
+// (optionally verifying client certificates), otherwise via HTTP.
+//
+// Parameters:
+//   - `aResolver`: The resolver to administrate.
+//   - `aConfig`: The configuration providing the address to listen on.
+//
+// Returns:
+//   - `*http.Server`: The running HTTP server.
+//   - `error`: `nil` if the server was started, the error otherwise.
+func startAdminServer(aResolver *dnscache.TResolver, aConfig tConfiguration) (*http.Server, error) {
+	tlsConfig, err := adminTLSConfig(aConfig)
+	if nil != err {
+		return nil, err
+	}
+	as := newAdminServer(aResolver, aConfig)
+	if aConfig.Dashboard {
+		as.enableDashboard()
+	}
+	if !as.auth.enabled() {
+		gAdminLog.Warn("admin API without authentication", "address", aConfig.AdminAddress)
+	}
+	server := &http.Server{
+		Addr:              aConfig.AdminAddress,
+		Handler:           as,
+		TLSConfig:         tlsConfig,
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		WriteTimeout:      10 * time.Second,
+		IdleTimeout:       time.Minute,
+	}
+
+	go func() {
+		var err error
+		gAdminLog.Info("admin API listening", "address", server.Addr, "tls", nil != tlsConfig)
+		if nil != tlsConfig {
+			// The certificates are part of `server.TLSConfig`
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+		if !errors.Is(err, http.ErrServerClosed) {
+			gAdminLog.Error("admin API failed", "error", err)
+		}
+	}()
+
+	return server, nil
+} // startAdminServer()
+
+/* _EoF_ */
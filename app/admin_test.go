@@ -0,0 +1,964 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mwat56/dnscache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// `adminRequest()` sends a request to the admin API and returns
+// the answer's status code and body.
+func adminRequest(aHandler http.Handler, aMethod, aPath string, aForm url.Values) (int, string) {
+	var req *http.Request
+	if nil == aForm {
+		req = httptest.NewRequest(aMethod, aPath, nil)
+	} else {
+		req = httptest.NewRequest(aMethod, aPath, strings.NewReader(aForm.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	rec := httptest.NewRecorder()
+	aHandler.ServeHTTP(rec, req)
+
+	return rec.Code, rec.Body.String()
+} // adminRequest()
+
+func Test_tAdminServer_allow(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	as := newAdminServer(resolver, tConfiguration{})
+
+	tests := []struct {
+		name       string
+		method     string
+		form       url.Values
+		wantStatus int
+		wantLen    int
+	}{
+		/* */
+		{
+			name:       "01 - empty list",
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+			wantLen:    0,
+		},
+		{
+			name:       "02 - missing duration",
+			method:     http.MethodPost,
+			form:       url.Values{"host": {"ads.domain.tld"}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "03 - invalid host",
+			method:     http.MethodPost,
+			form:       url.Values{"host": {""}, "duration": {"5m"}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "04 - allow host",
+			method:     http.MethodPost,
+			form:       url.Values{"host": {"ads.domain.tld"}, "duration": {"5m"}},
+			wantStatus: http.StatusOK,
+			wantLen:    1,
+		},
+		{
+			name:       "05 - list allowed hosts",
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+			wantLen:    1,
+		},
+		{
+			name:       "06 - remove host",
+			method:     http.MethodPost,
+			form:       url.Values{"host": {"ads.domain.tld"}, "duration": {"0"}},
+			wantStatus: http.StatusOK,
+			wantLen:    0,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, body := adminRequest(as, tc.method, "/api/allow", tc.form)
+			if status != tc.wantStatus {
+				t.Errorf("status = '%d', want '%d'", status, tc.wantStatus)
+				return
+			}
+			if http.StatusOK != status {
+				return
+			}
+			var list []dnscache.TTempAllow
+			if err := json.Unmarshal([]byte(body), &list); nil != err {
+				t.Errorf("json.Unmarshal() error = '%v'", err)
+				return
+			}
+			if len(list) != tc.wantLen {
+				t.Errorf("len(list) = '%d', want '%d'", len(list), tc.wantLen)
+			}
+		})
+	}
+} // Test_tAdminServer_allow()
+
+func Test_tAdminServer_allowSuggestions(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	as := newAdminServer(resolver, tConfiguration{})
+
+	status, body := adminRequest(as, http.MethodGet, "/api/allow/suggestions", nil)
+	if (http.StatusOK != status) || ("[]" != strings.TrimSpace(body)) {
+		t.Errorf("answer = '%d' '%s', want '%d' '[]'", status, body, http.StatusOK)
+	}
+
+	_ = resolver.AddDeny("*.ads.localdomain")
+	client := net.ParseIP("192.168.1.10")
+	for range 10 {
+		_, _ = resolver.FetchFor(client, "api.ads.localdomain")
+	}
+	status, body = adminRequest(as, http.MethodGet, "/api/allow/suggestions", nil)
+	if http.StatusOK != status {
+		t.Fatalf("status = '%d', want '%d'", status, http.StatusOK)
+	}
+	var list []dnscache.TAllowSuggestion
+	if err := json.Unmarshal([]byte(body), &list); nil != err {
+		t.Fatalf("json.Unmarshal() error = '%v'", err)
+	}
+	if (1 != len(list)) || ("api.ads.localdomain" != list[0].Hostname) {
+		t.Errorf("suggestions = '%v', want 'api.ads.localdomain'", list)
+	}
+} // Test_tAdminServer_allowSuggestions()
+
+func Test_tAdminServer_audit(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	as := newAdminServer(resolver, tConfiguration{})
+
+	tests := []struct {
+		name       string
+		method     string
+		form       url.Values
+		wantStatus int
+		wantAudit  bool
+	}{
+		/* */
+		{
+			name:       "01 - enforcing",
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "02 - invalid mode",
+			method:     http.MethodPost,
+			form:       url.Values{"audit": {"maybe"}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "03 - switch on",
+			method:     http.MethodPost,
+			form:       url.Values{"audit": {"true"}},
+			wantStatus: http.StatusOK,
+			wantAudit:  true,
+		},
+		{
+			name:       "04 - auditing",
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+			wantAudit:  true,
+		},
+		{
+			name:       "05 - switch off",
+			method:     http.MethodPost,
+			form:       url.Values{"audit": {"false"}},
+			wantStatus: http.StatusOK,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, body := adminRequest(as, tc.method, "/api/audit", tc.form)
+			if status != tc.wantStatus {
+				t.Errorf("status = '%d', want '%d'", status, tc.wantStatus)
+				return
+			}
+			if http.StatusOK != status {
+				return
+			}
+			var state tAuditState
+			if err := json.Unmarshal([]byte(body), &state); nil != err {
+				t.Errorf("json.Unmarshal() error = '%v'", err)
+				return
+			}
+			if state.Audit != tc.wantAudit {
+				t.Errorf("audit = '%v', want '%v'", state.Audit, tc.wantAudit)
+			}
+			if got := resolver.AuditMode(); got != tc.wantAudit {
+				t.Errorf("AuditMode() = '%v', want '%v'", got, tc.wantAudit)
+			}
+		})
+	}
+} // Test_tAdminServer_audit()
+
+func Test_tAdminServer_cacheDump(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	as := newAdminServer(resolver, tConfiguration{})
+	resolver.Create(context.Background(), "www.example.com",
+		[]net.IP{net.ParseIP("192.0.2.1")}, time.Hour)
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		/* */
+		{"01 - default", "/api/cache", http.StatusOK, `"hostname":"www.example.com"`},
+		{"02 - hosts", "/api/cache?format=hosts", http.StatusOK, "192.0.2.1 www.example.com\n"},
+		{"03 - JSON", "/api/cache?format=JSON", http.StatusOK, `"ttl":`},
+		{"04 - CSV", "/api/cache?format=csv", http.StatusOK, "www.example.com,192.0.2.1,"},
+		{"05 - BIND", "/api/cache?format=bind", http.StatusOK, "www.example.com.\t"},
+		{"06 - invalid format", "/api/cache?format=xml", http.StatusBadRequest, "invalid dump format"},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, body := adminRequest(as, http.MethodGet, tc.path, nil)
+			if status != tc.wantStatus {
+				t.Errorf("status = '%d', want '%d'", status, tc.wantStatus)
+			}
+			if !strings.Contains(body, tc.wantBody) {
+				t.Errorf("body = '%s', want '%s'", body, tc.wantBody)
+			}
+		})
+	}
+} // Test_tAdminServer_cacheDump()
+
+func Test_tAdminServer_cacheFlush(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	as := newAdminServer(resolver, tConfiguration{})
+	ctx := context.Background()
+	ip := net.ParseIP("192.0.2.1")
+	for _, hostname := range []string{"a.example.com", "b.example.com", "host-1.example.org", "example.net"} {
+		resolver.Create(ctx, hostname, []net.IP{ip}, time.Hour)
+	}
+	resolver.Create(ctx, "v6.example.net", []net.IP{net.ParseIP("2001:db8::1")}, time.Hour)
+
+	tests := []struct {
+		name       string
+		form       url.Values
+		wantStatus int
+		want       string
+	}{
+		/* */
+		{
+			name:       "01 - suffix and pattern",
+			form:       url.Values{"suffix": {"example.com"}, "pattern": {"*.example.com"}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "02 - invalid pattern",
+			form:       url.Values{"pattern": {"[example.com"}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "03 - suffix",
+			form:       url.Values{"suffix": {"example.com"}},
+			wantStatus: http.StatusOK,
+			want:       `{"flushed":2}`,
+		},
+		{
+			name:       "04 - pattern",
+			form:       url.Values{"pattern": {"host-?.example.org"}},
+			wantStatus: http.StatusOK,
+			want:       `{"flushed":1}`,
+		},
+		{
+			name:       "05 - type and pattern",
+			form:       url.Values{"type": {"AAAA"}, "pattern": {"*.example.net"}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "06 - invalid type",
+			form:       url.Values{"type": {"BOGUS"}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "07 - type",
+			form:       url.Values{"type": {"aaaa"}},
+			wantStatus: http.StatusOK,
+			want:       `{"flushed":1}`,
+		},
+		{
+			name:       "08 - whole cache",
+			form:       url.Values{},
+			wantStatus: http.StatusOK,
+			want:       `{"flushed":1}`,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, body := adminRequest(as, http.MethodPost, "/api/cache/flush", tc.form)
+			if status != tc.wantStatus {
+				t.Errorf("status = '%d', want '%d'", status, tc.wantStatus)
+				return
+			}
+			if http.StatusOK != status {
+				return
+			}
+			if got := strings.TrimSpace(body); got != tc.want {
+				t.Errorf("body = '%s', want '%s'", got, tc.want)
+			}
+		})
+	}
+} // Test_tAdminServer_cacheFlush()
+
+func Test_tAdminServer_categories(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	as := newAdminServer(resolver, tConfiguration{})
+	_ = resolver.AddCategory("malware")
+	_ = resolver.AddCategory("ads")
+
+	tests := []struct {
+		name        string
+		method      string
+		form        url.Values
+		wantStatus  int
+		wantEnabled []bool
+	}{
+		/* */
+		{
+			name:        "01 - all enabled",
+			method:      http.MethodGet,
+			wantStatus:  http.StatusOK,
+			wantEnabled: []bool{true, true},
+		},
+		{
+			name:       "02 - invalid switch",
+			method:     http.MethodPost,
+			form:       url.Values{"name": {"ads"}, "enabled": {"maybe"}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "03 - unknown category",
+			method:     http.MethodPost,
+			form:       url.Values{"name": {"phishing"}, "enabled": {"false"}},
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:        "04 - switch off",
+			method:      http.MethodPost,
+			form:        url.Values{"name": {"ads"}, "enabled": {"false"}},
+			wantStatus:  http.StatusOK,
+			wantEnabled: []bool{true, false},
+		},
+		{
+			name:        "05 - switch on",
+			method:      http.MethodPost,
+			form:        url.Values{"name": {"ads"}, "enabled": {"true"}},
+			wantStatus:  http.StatusOK,
+			wantEnabled: []bool{true, true},
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, body := adminRequest(as, tc.method, "/api/categories", tc.form)
+			if status != tc.wantStatus {
+				t.Errorf("status = '%d', want '%d'", status, tc.wantStatus)
+				return
+			}
+			if http.StatusOK != status {
+				return
+			}
+			var list []dnscache.TCategoryState
+			if err := json.Unmarshal([]byte(body), &list); nil != err {
+				t.Errorf("json.Unmarshal() error = '%v'", err)
+				return
+			}
+			if len(list) != len(tc.wantEnabled) {
+				t.Errorf("categories = '%v', want '%v'", list, tc.wantEnabled)
+				return
+			}
+			for i, state := range list {
+				if state.Enabled != tc.wantEnabled[i] {
+					t.Errorf("%s.Enabled = '%v', want '%v'", state.Name, state.Enabled, tc.wantEnabled[i])
+				}
+			}
+		})
+	}
+} // Test_tAdminServer_categories()
+
+func Test_parseQType(t *testing.T) {
+	tests := []struct {
+		name    string
+		qType   string
+		want    uint16
+		wantErr bool
+	}{
+		/* */
+		{"01 - name", "AAAA", dnsTypeAAAA, false},
+		{"02 - lower case name", " txt ", 16, false},
+		{"03 - number", "99", 99, false},
+		{"04 - unknown name", "BOGUS", 0, true},
+		{"05 - zero", "0", 0, true},
+		{"06 - too large", "65536", 0, true},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseQType(tc.qType)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("parseQType() error = '%v', wantErr '%v'",
+					err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("parseQType() = '%d', want '%d'", got, tc.want)
+			}
+		})
+	}
+} // Test_parseQType()
+
+func Test_tAdminServer_lists(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	as := newAdminServer(resolver, tConfiguration{})
+
+	tests := []struct {
+		name       string
+		path       string
+		pattern    string
+		wantStatus int
+	}{
+		/* */
+		{
+			name:       "01 - invalid allow pattern",
+			path:       "/api/allowlist",
+			pattern:    "",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "02 - allow pattern",
+			path:       "/api/allowlist",
+			pattern:    "www.domain.tld",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "03 - invalid deny pattern",
+			path:       "/api/denylist",
+			pattern:    "",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "04 - deny pattern",
+			path:       "/api/denylist",
+			pattern:    "*.ads.tld",
+			wantStatus: http.StatusOK,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, _ := adminRequest(as, http.MethodPost, tc.path,
+				url.Values{"pattern": {tc.pattern}})
+			if status != tc.wantStatus {
+				t.Errorf("status = '%d', want '%d'", status, tc.wantStatus)
+			}
+		})
+	}
+
+	ips, err := resolver.Fetch("banner.ads.tld")
+	if (nil != err) || (1 != len(ips)) || !ips[0].IsUnspecified() {
+		t.Errorf("Fetch() = '%v', '%v', want '0.0.0.0'", ips, err)
+	}
+} // Test_tAdminServer_lists()
+
+func Test_tAdminServer_listsFind(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	as := newAdminServer(resolver, tConfiguration{})
+	_ = resolver.AddDeny("*.doubleclick.net")
+	_ = resolver.AddAllow("www.doubleclick.net")
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		want       string
+	}{
+		/* */
+		{"01 - missing query", "", http.StatusBadRequest, ""},
+		{"02 - no match", "example", http.StatusOK, `[]`},
+		{"03 - substring", "doubleclick", http.StatusOK,
+			`[{"pattern":"www.doubleclick.net","allow":true},{"pattern":"*.doubleclick.net","allow":false}]`},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, body := adminRequest(as, http.MethodGet,
+				"/api/lists/find?"+url.Values{"q": {tc.query}}.Encode(), nil)
+			if status != tc.wantStatus {
+				t.Errorf("status = '%d', want '%d'", status, tc.wantStatus)
+				return
+			}
+			if http.StatusOK != status {
+				return
+			}
+			if got := strings.TrimSpace(body); got != tc.want {
+				t.Errorf("body = '%s', want '%s'", got, tc.want)
+			}
+		})
+	}
+} // Test_tAdminServer_listsFind()
+
+func Test_tAdminServer_listsPage(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	as := newAdminServer(resolver, tConfiguration{})
+	for _, pattern := range []string{"ads.example.com", "cdn.example.com", "www.example.com", "ads.example.net"} {
+		_ = resolver.AddDeny(pattern)
+	}
+	_ = resolver.AddAllow("www.doubleclick.net")
+
+	tests := []struct {
+		name       string
+		path       string
+		query      url.Values
+		wantStatus int
+		want       string
+		wantNext   string
+	}{
+		/* */
+		{"01 - whole list", "/api/denylist", nil, http.StatusOK,
+			`["ads.example.com","cdn.example.com","www.example.com","ads.example.net"]`, ""},
+		{"02 - first page", "/api/denylist", url.Values{"limit": {"2"}}, http.StatusOK,
+			`["ads.example.com","cdn.example.com"]`, "2"},
+		{"03 - last page", "/api/denylist", url.Values{"offset": {"2"}, "limit": {"2"}}, http.StatusOK,
+			`["www.example.com","ads.example.net"]`, ""},
+		{"04 - beyond the end", "/api/denylist", url.Values{"offset": {"9"}}, http.StatusOK, `[]`, ""},
+		{"05 - prefix", "/api/denylist", url.Values{"prefix": {"ADS."}, "limit": {"1"}}, http.StatusOK,
+			`["ads.example.com"]`, "1"},
+		{"06 - invalid offset", "/api/denylist", url.Values{"offset": {"-1"}}, http.StatusBadRequest, "", ""},
+		{"07 - invalid limit", "/api/denylist", url.Values{"limit": {"many"}}, http.StatusBadRequest, "", ""},
+		{"08 - allow list", "/api/allowlist", nil, http.StatusOK, `["www.doubleclick.net"]`, ""},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tc.path+"?"+tc.query.Encode(), nil)
+			rec := httptest.NewRecorder()
+			as.ServeHTTP(rec, req)
+			if rec.Code != tc.wantStatus {
+				t.Fatalf("status = '%d', want '%d'", rec.Code, tc.wantStatus)
+			}
+			if http.StatusOK != rec.Code {
+				return
+			}
+			if got := strings.TrimSpace(rec.Body.String()); got != tc.want {
+				t.Errorf("body = '%s', want '%s'", got, tc.want)
+			}
+			if got := rec.Header().Get("X-Next-Offset"); got != tc.wantNext {
+				t.Errorf("X-Next-Offset = '%s', want '%s'", got, tc.wantNext)
+			}
+		})
+	}
+} // Test_tAdminServer_listsPage()
+
+func Test_tAdminServer_listsPatch(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	as := newAdminServer(resolver, tConfiguration{})
+	_ = resolver.AddDeny("old.tld")
+
+	tests := []struct {
+		name       string
+		diff       string
+		wantStatus int
+	}{
+		/* */
+		{"01 - missing diff", "", http.StatusBadRequest},
+		{"02 - malformed diff", "new.tld", http.StatusBadRequest},
+		{"03 - changes", "-old.tld\n+new.tld\n", http.StatusOK},
+		{"04 - unknown pattern", "-old.tld\n", http.StatusBadRequest},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, _ := adminRequest(as, http.MethodPost, "/api/lists/patch",
+				url.Values{"diff": {tc.diff}})
+			if status != tc.wantStatus {
+				t.Errorf("status = '%d', want '%d'", status, tc.wantStatus)
+			}
+		})
+	}
+
+	if got, want := resolver.DenyPatterns(), []string{"new.tld"}; !slices.Equal(got, want) {
+		t.Errorf("DenyPatterns() = '%v', want '%v'", got, want)
+	}
+} // Test_tAdminServer_listsPatch()
+
+func Test_tAdminServer_listsProgress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(aWriter http.ResponseWriter, _ *http.Request) {
+		_, _ = aWriter.Write([]byte("ads.localdomain\ntracker.localdomain\n"))
+	}))
+	defer server.Close()
+
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	as := newAdminServer(resolver, tConfiguration{})
+	uri := server.URL + "/deny.txt"
+	if err := resolver.LoadBlocklists([]string{uri}); nil != err {
+		t.Fatalf("LoadBlocklists() error = '%v'", err)
+	}
+
+	status, body := adminRequest(as, http.MethodGet, "/api/lists/progress", nil)
+	if http.StatusOK != status {
+		t.Fatalf("status = '%d', want '%d'", status, http.StatusOK)
+	}
+	var state tProgressState
+	if err := json.Unmarshal([]byte(body), &state); nil != err {
+		t.Fatalf("json.Unmarshal() error = '%v'", err)
+	}
+	if state.Loading || (1 != len(state.Sources)) {
+		t.Fatalf("state = '%v', want one loaded source", state)
+	}
+	if got := state.Sources[0]; (uri != got.Source) || (2 != got.Lines) || (2 != got.Added) || !got.Done {
+		t.Errorf("source = '%v', want 2 lines, 2 added, done", got)
+	}
+
+	// A newer load replaces the older one's progress
+	started := state.Sources[0].Started
+	as.progress.update(dnscache.TLoadProgress{Started: started.Add(time.Second),
+		Source: "http://lists.localdomain/new.txt", Lines: 10})
+	as.progress.update(dnscache.TLoadProgress{Started: started,
+		Source: uri, Done: true})
+	state = as.progress.state()
+	if !state.Loading || (1 != len(state.Sources)) || (10 != state.Sources[0].Lines) {
+		t.Errorf("state = '%v', want one loading source", state)
+	}
+} // Test_tAdminServer_listsProgress()
+
+func Test_tAdminServer_listsReport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(aWriter http.ResponseWriter, _ *http.Request) {
+		_, _ = aWriter.Write([]byte("ads.localdomain\ntracker.localdomain\nads.localdomain\n"))
+	}))
+	defer server.Close()
+
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	as := newAdminServer(resolver, tConfiguration{})
+	uri := server.URL + "/deny.txt"
+	if err := resolver.LoadBlocklists([]string{uri}); nil != err {
+		t.Fatalf("LoadBlocklists() error = '%v'", err)
+	}
+
+	status, body := adminRequest(as, http.MethodGet, "/api/lists/report", nil)
+	if http.StatusOK != status {
+		t.Fatalf("status = '%d', want '%d'", status, http.StatusOK)
+	}
+	var report dnscache.TLoadReport
+	if err := json.Unmarshal([]byte(body), &report); nil != err {
+		t.Fatalf("json.Unmarshal() error = '%v'", err)
+	}
+	if 1 != len(report.Sources) {
+		t.Fatalf("sources = '%v', want 1 source", report.Sources)
+	}
+	got := report.Sources[0]
+	if (uri != got.Source) || (3 != got.Lines) || (2 != got.Added) || (1 != got.Duplicates) {
+		t.Errorf("source = '%v', want 3 lines, 2 added, 1 duplicate", got)
+	}
+} // Test_tAdminServer_listsReport()
+
+func Test_tAdminServer_metrics(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	as := newAdminServer(resolver, tConfiguration{})
+	resolver.Create(context.Background(), "www.example.com",
+		[]net.IP{net.ParseIP("192.0.2.1")}, time.Hour)
+	if _, err := resolver.FetchType(nil, "www.example.com", dnsTypeA); nil != err {
+		t.Fatalf("FetchType() error = '%v'", err)
+	}
+
+	status, body := adminRequest(as, http.MethodGet, "/api/metrics", nil)
+	if http.StatusOK != status {
+		t.Fatalf("status = '%d', want '%d'", status, http.StatusOK)
+	}
+	var state tMetricsState
+	if err := json.Unmarshal([]byte(body), &state); nil != err {
+		t.Fatalf("json.Unmarshal() error = '%v'", err)
+	}
+	if state.Time.IsZero() {
+		t.Error("time = zero time, want current time")
+	}
+	if (0 > state.HitRatio) || (1 < state.HitRatio) {
+		t.Errorf("hitRatio = '%f', want [0..1]", state.HitRatio)
+	}
+	if got := state.Types["A"]; 1 != got.Hits {
+		t.Errorf("types[A] = '%v', want '1' hit", got)
+	}
+} // Test_tAdminServer_metrics()
+
+func Test_tAdminServer_pause(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	as := newAdminServer(resolver, tConfiguration{})
+
+	tests := []struct {
+		name       string
+		method     string
+		form       url.Values
+		wantStatus int
+		wantPaused bool
+	}{
+		/* */
+		{
+			name:       "01 - not paused",
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+			wantPaused: false,
+		},
+		{
+			name:       "02 - invalid duration",
+			method:     http.MethodPost,
+			form:       url.Values{"duration": {"soon"}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "03 - negative duration",
+			method:     http.MethodPost,
+			form:       url.Values{"duration": {"-5m"}},
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "04 - pause blocking",
+			method:     http.MethodPost,
+			form:       url.Values{"duration": {"10m"}},
+			wantStatus: http.StatusOK,
+			wantPaused: true,
+		},
+		{
+			name:       "05 - paused",
+			method:     http.MethodGet,
+			wantStatus: http.StatusOK,
+			wantPaused: true,
+		},
+		{
+			name:       "06 - resume blocking",
+			method:     http.MethodPost,
+			form:       url.Values{"duration": {"0s"}},
+			wantStatus: http.StatusOK,
+			wantPaused: false,
+		},
+		{
+			name:       "07 - method not allowed",
+			method:     http.MethodDelete,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, body := adminRequest(as, tc.method, "/api/pause", tc.form)
+			if status != tc.wantStatus {
+				t.Errorf("status = '%d', want '%d'", status, tc.wantStatus)
+				return
+			}
+			if http.StatusOK != status {
+				return
+			}
+			var state tPauseState
+			if err := json.Unmarshal([]byte(body), &state); nil != err {
+				t.Errorf("json.Unmarshal() error = '%v'", err)
+				return
+			}
+			if state.Paused != tc.wantPaused {
+				t.Errorf("paused = '%v', want '%v'", state.Paused, tc.wantPaused)
+			}
+			if state.Paused == (nil == state.PausedUntil) {
+				t.Errorf("pausedUntil = '%v', paused '%v'",
+					state.PausedUntil, state.Paused)
+			}
+		})
+	}
+} // Test_tAdminServer_pause()
+
+func Test_tAdminServer_queryTrace(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	as := newAdminServer(resolver, tConfiguration{})
+	_ = resolver.AddDeny("*.ads.localdomain")
+
+	status, body := adminRequest(as, http.MethodGet,
+		"/api/query?"+url.Values{"name": {"banner.ads.localdomain"}, "trace": {"1"}}.Encode(), nil)
+	if http.StatusOK != status {
+		t.Fatalf("status = '%d', want '%d'", status, http.StatusOK)
+	}
+	var trace dnscache.TTrace
+	if err := json.Unmarshal([]byte(body), &trace); nil != err {
+		t.Fatalf("json.Unmarshal() error = '%v'", err)
+	}
+	if ("deny" != trace.Decision) || ("*.ads.localdomain" != trace.Pattern) {
+		t.Errorf("trace = '%v', want deny by '*.ads.localdomain'", trace)
+	}
+	if (1 != len(trace.IPs)) || !net.IPv4zero.Equal(trace.IPs[0]) {
+		t.Errorf("ips = '%v', want '%v'", trace.IPs, net.IPv4zero)
+	}
+} // Test_tAdminServer_queryTrace()
+
+func Test_tAdminServer_top(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	_ = resolver.AddRewrite(dnscache.TRewriteRule{
+		Match: "*.lan", Type: dnscache.RewriteIP, Target: "192.168.1.1"})
+	for _, host := range []string{"nas.lan", "nas.lan", "printer.lan"} {
+		_, _ = resolver.Fetch(host)
+	}
+	as := newAdminServer(resolver, tConfiguration{})
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantLen    int
+	}{
+		/* */
+		{
+			name:       "01 - top queries",
+			path:       "/api/top/queries",
+			wantStatus: http.StatusOK,
+			wantLen:    2,
+		},
+		{
+			name:       "02 - limited top queries",
+			path:       "/api/top/queries?count=1",
+			wantStatus: http.StatusOK,
+			wantLen:    1,
+		},
+		{
+			name:       "03 - invalid count",
+			path:       "/api/top/queries?count=-1",
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "04 - top blocked",
+			path:       "/api/top/blocked",
+			wantStatus: http.StatusOK,
+			wantLen:    0,
+		},
+		{
+			name:       "05 - top audited",
+			path:       "/api/top/audited",
+			wantStatus: http.StatusOK,
+			wantLen:    0,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, body := adminRequest(as, http.MethodGet, tc.path, nil)
+			if status != tc.wantStatus {
+				t.Errorf("status = '%d', want '%d'", status, tc.wantStatus)
+				return
+			}
+			if http.StatusOK != status {
+				return
+			}
+			var list []dnscache.TTopEntry
+			if err := json.Unmarshal([]byte(body), &list); nil != err {
+				t.Errorf("json.Unmarshal() error = '%v'", err)
+				return
+			}
+			if len(list) != tc.wantLen {
+				t.Errorf("len(list) = '%d', want '%d'", len(list), tc.wantLen)
+			}
+		})
+	}
+} // Test_tAdminServer_top()
+
+func Test_tAdminServer_upstreams(t *testing.T) {
+	// A local port nobody listens on refuses the queries
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatal(err)
+	}
+	server := conn.LocalAddr().String()
+	conn.Close()
+
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{
+		DataDir:    t.TempDir(),
+		DNSservers: []string{server},
+		Breaker:    &dnscache.TBreakerOptions{Threshold: 1},
+		Resolver: &net.Resolver{
+			PreferGo: true,
+			Dial: func(context.Context, string, string) (net.Conn, error) {
+				return nil, errors.New("upstream down")
+			},
+		},
+	})
+	defer resolver.StopExpire()
+	as := newAdminServer(resolver, tConfiguration{})
+
+	status, body := adminRequest(as, http.MethodGet, "/api/upstreams", nil)
+	if (http.StatusOK != status) || ("[]" != strings.TrimSpace(body)) {
+		t.Errorf("upstreams = '%d' %q, want '%d' \"[]\"", status, body, http.StatusOK)
+	}
+
+	_, _ = resolver.Fetch("unreachable.test")
+	status, body = adminRequest(as, http.MethodGet, "/api/upstreams", nil)
+	if http.StatusOK != status {
+		t.Fatalf("status = '%d', want '%d'", status, http.StatusOK)
+	}
+	var upstreams []dnscache.TBreakerState
+	if err = json.Unmarshal([]byte(body), &upstreams); nil != err {
+		t.Fatalf("json.Unmarshal() error = '%v'", err)
+	}
+	found := false
+	for _, upstream := range upstreams {
+		if server == upstream.Server {
+			found = ("open" == upstream.State) && (1 == upstream.Trips)
+		}
+	}
+	if !found {
+		t.Errorf("upstreams = '%+v', want %q open", upstreams, server)
+	}
+
+	status, body = adminRequest(as, http.MethodGet, "/api/metrics", nil)
+	var state tMetricsState
+	if err = json.Unmarshal([]byte(body), &state); (http.StatusOK != status) || (nil != err) {
+		t.Fatalf("metrics = '%d' %q, error '%v'", status, body, err)
+	}
+	if len(state.Upstreams) != len(upstreams) {
+		t.Errorf("metrics upstreams = '%v', want '%v'", state.Upstreams, upstreams)
+	}
+} // Test_tAdminServer_upstreams()
+
+/* _EoF_ */
@@ -0,0 +1,182 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"encoding/binary"
+	"net"
+	"sync/atomic"
+
+	"github.com/mwat56/dnscache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tAnyPolicy` is the handling of queries for all records of a
+	// name (QTYPE=ANY) which are popular for amplification attacks.
+	tAnyPolicy uint32
+)
+
+const (
+	// ANY policies
+	anyHINFO   = tAnyPolicy(0) // answer with a synthesized HINFO record (RFC 8482)
+	anySubset  = tAnyPolicy(1) // answer with the cached A and AAAA records
+	anyForward = tAnyPolicy(2) // handle like any other query
+
+	// DNS record types
+	dnsTypeHINFO uint16 = 13  // host information (RFC 8482 answer)
+	dnsTypeANY   uint16 = 255 // all records of a name
+
+	// `anyTTL` is the TTL of the synthesized HINFO record.
+	anyTTL = 3600
+)
+
+var (
+	// `gAnyPolicy` is the handling of ANY queries.
+	gAnyPolicy atomic.Uint32
+
+	// `gMinimalResponses` tells whether to remove the authority and
+	// additional records from forwarded responses.
+	gMinimalResponses atomic.Bool
+
+	// `hinfoData` is the RDATA of the synthesized HINFO record:
+	// CPU "RFC8482" and an empty OS.
+	hinfoData = []byte("\x07RFC8482\x00")
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `handleANYRequest()` answers a query for all records of a name as
+// configured by the ANY policy.
+//
+// Parameters:
+//   - `aConn`: The connection to write the response to.
+//   - `aAddr`: The address to send the response to.
+//   - `aRequest`: The DNS request message.
+//   - `aResolver`: The DNS resolver to use for lookups.
+//
+// Returns:
+//   - `bool`: `true` if the request was answered, `false` otherwise.
+func handleANYRequest(aConn net.PacketConn, aAddr net.Addr, aRequest []byte,
+	aResolver *dnscache.TResolver) bool {
+	policy := tAnyPolicy(gAnyPolicy.Load())
+	if (anyForward == policy) || (1 != binary.BigEndian.Uint16(aRequest[4:6])) {
+		return false
+	}
+	qType, qClass, ok := questionType(aRequest)
+	if !ok || (dnsTypeANY != qType) || (dnsClassIN != qClass) {
+		return false
+	}
+
+	id := binary.BigEndian.Uint16(aRequest[0:2])
+	flags := binary.BigEndian.Uint16(aRequest[2:4])
+	end := questionEnd(aRequest)
+
+	response := make([]byte, 512)
+	binary.BigEndian.PutUint16(response[0:2], id)
+	binary.BigEndian.PutUint16(response[2:4], dnsQR|dnsRA|(flags&dnsRD))
+	binary.BigEndian.PutUint16(response[4:6], 1) // QDCount
+	offset := 12 + copy(response[12:], aRequest[12:end])
+
+	if anySubset == policy {
+		ips, err := fetchFor(aConn, aResolver, addrIP(aAddr),
+			extractFirstHostname(aRequest), dnsTypeANY)
+		if (nil != err) || (0 == len(ips)) {
+			sendNXDOMAINResponse(aConn, aAddr, id, flags, 1, aRequest[12:end])
+			return true
+		}
+		var count uint16
+		offset, count = addAnswersToResponse(response, offset, 0, ips, dnsTypeA, 12)
+		offset, count = addAnswersToResponse(response, offset, count, ips, dnsTypeAAAA, 12)
+		binary.BigEndian.PutUint16(response[6:8], count)
+		_, _ = aConn.WriteTo(response[:offset], aAddr)
+
+		return true
+	}
+
+	// RFC 8482: a single HINFO record instead of all records
+	binary.BigEndian.PutUint16(response[6:8], 1) // ANCount
+	response = binary.BigEndian.AppendUint16(response[:offset], 0xC00C)
+	response = binary.BigEndian.AppendUint16(response, dnsTypeHINFO)
+	response = binary.BigEndian.AppendUint16(response, dnsClassIN)
+	response = binary.BigEndian.AppendUint32(response, anyTTL)
+	response = binary.BigEndian.AppendUint16(response, uint16(len(hinfoData))) //#nosec G115
+	response = append(response, hinfoData...)
+
+	_, _ = aConn.WriteTo(response, aAddr)
+	// Error sending response is not critical, hence we ignore it.
+
+	return true
+} // handleANYRequest()
+
+// `minimalResponse()` removes the authority and additional records
+// from a DNS response.
+//
+// The OPT pseudo record is kept since it's part of the EDNS
+// negotiation. Malformed responses are returned unchanged.
+//
+// Parameters:
+//   - `aResponse`: The DNS response to shrink.
+//
+// Returns:
+//   - `[]byte`: The (possibly) shortened response.
+func minimalResponse(aResponse []byte) []byte {
+	if 12 > len(aResponse) {
+		return aResponse
+	}
+	nsCount := int(binary.BigEndian.Uint16(aResponse[8:10]))
+	arCount := int(binary.BigEndian.Uint16(aResponse[10:12]))
+	if 0 == nsCount+arCount {
+		return aResponse
+	}
+
+	// Skip the questions and answers
+	offset := 12
+	for range binary.BigEndian.Uint16(aResponse[4:6]) {
+		var ok bool
+		if offset, ok = skipName(aResponse, offset); !ok || (offset+4 > len(aResponse)) {
+			return aResponse
+		}
+		offset += 4 // type and class
+	}
+	for range binary.BigEndian.Uint16(aResponse[6:8]) {
+		end, _, ok := skipRecord(aResponse, offset)
+		if !ok {
+			return aResponse
+		}
+		offset = end
+	}
+	answersEnd := offset
+
+	// Keep only the OPT record(s) of the remaining sections
+	var (
+		opts     []byte
+		optCount uint16
+	)
+	for idx := range nsCount + arCount {
+		end, rType, ok := skipRecord(aResponse, offset)
+		if !ok {
+			return aResponse
+		}
+		if (nsCount <= idx) && (dnsTypeOPT == rType) {
+			opts = append(opts, aResponse[offset:end]...)
+			optCount++
+		}
+		offset = end
+	}
+
+	result := make([]byte, 0, answersEnd+len(opts))
+	result = append(result, aResponse[:answersEnd]...)
+	binary.BigEndian.PutUint16(result[8:10], 0)
+	binary.BigEndian.PutUint16(result[10:12], optCount)
+
+	return append(result, opts...)
+} // minimalResponse()
+
+/* _EoF_ */
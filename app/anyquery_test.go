@@ -0,0 +1,166 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/mwat56/dnscache"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_handleANYRequest(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	_ = resolver.AddRewrite(dnscache.TRewriteRule{
+		Match:  "nas.home.lan",
+		Type:   dnscache.RewriteIP,
+		Target: "192.0.2.1,2001:db8::1",
+	})
+	defer gAnyPolicy.Store(uint32(anyHINFO))
+
+	tests := []struct {
+		name      string
+		policy    tAnyPolicy
+		request   []byte
+		want      bool
+		wantType  uint16
+		wantCount uint16
+	}{
+		/* */
+		{
+			name:    "01 - A query",
+			policy:  anyHINFO,
+			request: createDNSQuery("nas.home.lan", dnsTypeA),
+			want:    false,
+		},
+		{
+			name:    "02 - forward",
+			policy:  anyForward,
+			request: createDNSQuery("nas.home.lan", dnsTypeANY),
+			want:    false,
+		},
+		{
+			name:      "03 - HINFO",
+			policy:    anyHINFO,
+			request:   createDNSQuery("nas.home.lan", dnsTypeANY),
+			want:      true,
+			wantType:  dnsTypeHINFO,
+			wantCount: 1,
+		},
+		{
+			name:      "04 - subset",
+			policy:    anySubset,
+			request:   createDNSQuery("nas.home.lan", dnsTypeANY),
+			want:      true,
+			wantType:  dnsTypeA,
+			wantCount: 2,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gAnyPolicy.Store(uint32(tc.policy))
+			var response []byte
+			conn := &tMockPacketConn{
+				writeTo: func(aBuf []byte, _ net.Addr) (int, error) {
+					response = append([]byte{}, aBuf...)
+					return len(aBuf), nil
+				},
+			}
+			if got := handleANYRequest(conn, &tMockAddr{}, tc.request, resolver); got != tc.want {
+				t.Errorf("handleANYRequest() = '%v', want '%v'", got, tc.want)
+			}
+			if !tc.want {
+				return
+			}
+			if got := binary.BigEndian.Uint16(response[6:8]); got != tc.wantCount {
+				t.Errorf("ANCount = '%d', want '%d'", got, tc.wantCount)
+			}
+			end := questionEnd(tc.request)
+			if got := binary.BigEndian.Uint16(response[end+2 : end+4]); got != tc.wantType {
+				t.Errorf("answer type = '%d', want '%d'", got, tc.wantType)
+			}
+			if (dnsTypeHINFO == tc.wantType) && !bytes.HasSuffix(response, hinfoData) {
+				t.Errorf("HINFO data = '%q', want '%q'", response[end+12:], hinfoData)
+			}
+		})
+	}
+} // Test_handleANYRequest()
+
+func Test_minimalResponse(t *testing.T) {
+	name := dnsmessage.MustNewName("example.com.")
+	header := dnsmessage.ResourceHeader{Name: name, Class: dnsmessage.ClassINET, TTL: 300}
+	var opt dnsmessage.ResourceHeader
+	if err := opt.SetEDNS0(int(ednsUDPSize), dnsmessage.RCodeSuccess, false); nil != err {
+		t.Fatal(err)
+	}
+	msg := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: 4711, Response: true, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{
+			Name:  name,
+			Type:  dnsmessage.TypeA,
+			Class: dnsmessage.ClassINET,
+		}},
+		Answers: []dnsmessage.Resource{{
+			Header: header,
+			Body:   &dnsmessage.AResource{A: [4]byte{192, 0, 2, 1}},
+		}},
+		Authorities: []dnsmessage.Resource{{
+			Header: header,
+			Body:   &dnsmessage.NSResource{NS: dnsmessage.MustNewName("ns.example.com.")},
+		}},
+		Additionals: []dnsmessage.Resource{
+			{
+				Header: header,
+				Body:   &dnsmessage.AResource{A: [4]byte{192, 0, 2, 53}},
+			},
+			{
+				Header: opt,
+				Body:   &dnsmessage.OPTResource{},
+			},
+		},
+	}
+	response, err := msg.Pack()
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	got := minimalResponse(response)
+	if len(got) >= len(response) {
+		t.Errorf("minimalResponse() length = '%d', want < '%d'", len(got), len(response))
+	}
+	var result dnsmessage.Message
+	if err := result.Unpack(got); nil != err {
+		t.Fatalf("Unpack() error = '%v'", err)
+	}
+	if 1 != len(result.Answers) {
+		t.Errorf("Answers = '%d', want '1'", len(result.Answers))
+	}
+	if 0 != len(result.Authorities) {
+		t.Errorf("Authorities = '%d', want '0'", len(result.Authorities))
+	}
+	if (1 != len(result.Additionals)) || (dnsmessage.TypeOPT != result.Additionals[0].Header.Type) {
+		t.Errorf("Additionals = '%v', want the OPT record only", result.Additionals)
+	}
+
+	// Malformed or minimal responses are returned unchanged
+	if got := minimalResponse(response[:20]); !bytes.Equal(got, response[:20]) {
+		t.Errorf("minimalResponse() = '%v', want '%v'", got, response[:20])
+	}
+	if got := minimalResponse(got); len(got) != len(minimalResponse(response)) {
+		t.Errorf("minimalResponse() length = '%d', want unchanged", len(got))
+	}
+} // Test_minimalResponse()
+
+/* _EoF_ */
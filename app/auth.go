@@ -0,0 +1,227 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/mwat56/dnscache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tAuth` authenticates the callers of the admin API.
+	//
+	// A caller is accepted if it either presents one of the configured
+	// bearer tokens or a client certificate signed by the configured
+	// client CA (mutual TLS). Without any tokens and without a client
+	// CA the admin API is open to everybody who can reach it.
+	tAuth struct {
+		tokens []tToken
+		mTLS   bool // client certificates are verified
+	}
+
+	// `tToken` is a bearer token together with its log-safe name.
+	tToken struct {
+		secret []byte
+		id     string // hash prefix used in the audit log
+	}
+
+	// `tStatusRecorder` remembers the status code sent to a client.
+	tStatusRecorder struct {
+		http.ResponseWriter
+		status int
+	}
+)
+
+var (
+	// `gAuditLog` logs all mutating admin API calls.
+	gAuditLog = dnscache.Logger("audit")
+
+	// `errUnauthorised` is returned to unauthenticated callers.
+	errUnauthorised = errors.New("unauthorised")
+)
+
+// ---------------------------------------------------------------------------
+// `tAuth` constructor:
+
+// `newAuth()` creates the admin API authentication from the configuration.
+//
+// Parameters:
+//   - `aConfig`: The configuration to use.
+//
+// Returns:
+//   - `*tAuth`: The new authentication.
+func newAuth(aConfig tConfiguration) *tAuth {
+	result := &tAuth{
+		mTLS: ("" != aConfig.AdminClientCA),
+	}
+	for _, token := range aConfig.AdminTokens {
+		if token = strings.TrimSpace(token); "" == token {
+			continue
+		}
+		sum := sha256.Sum256([]byte(token))
+		result.tokens = append(result.tokens, tToken{
+			secret: []byte(token),
+			id:     "token:" + hex.EncodeToString(sum[:4]),
+		})
+	}
+
+	return result
+} // newAuth()
+
+// ---------------------------------------------------------------------------
+// `tAuth` methods:
+
+// `enabled()` checks whether authentication is required at all.
+//
+// Returns:
+//   - `bool`: `true` if callers have to authenticate, `false` otherwise.
+func (a *tAuth) enabled() bool {
+	return (nil != a) && ((0 < len(a.tokens)) || a.mTLS)
+} // enabled()
+
+// `identify()` authenticates the caller of a request.
+//
+// Parameters:
+//   - `aRequest`: The HTTP request to check.
+//
+// Returns:
+//   - `string`: The caller's identity (for the audit log).
+//   - `bool`: `true` if the caller is authenticated, `false` otherwise.
+func (a *tAuth) identify(aRequest *http.Request) (string, bool) {
+	if !a.enabled() {
+		return "anonymous", true
+	}
+
+	// The TLS stack only accepts certificates signed by the client CA.
+	if a.mTLS && (nil != aRequest.TLS) && (0 < len(aRequest.TLS.VerifiedChains)) {
+		return "cert:" + aRequest.TLS.VerifiedChains[0][0].Subject.CommonName, true
+	}
+
+	header := aRequest.Header.Get("Authorization")
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold("Bearer", scheme) {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	for _, t := range a.tokens {
+		if 1 == subtle.ConstantTimeCompare(t.secret, []byte(token)) {
+			return t.id, true
+		}
+	}
+
+	return "", false
+} // identify()
+
+// ---------------------------------------------------------------------------
+// `tStatusRecorder` methods:
+
+// `WriteHeader()` remembers the status code and sends it to the client.
+//
+// Parameters:
+//   - `aStatus`: The HTTP status code to send.
+func (sr *tStatusRecorder) WriteHeader(aStatus int) {
+	sr.status = aStatus
+	sr.ResponseWriter.WriteHeader(aStatus)
+} // WriteHeader()
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `adminTLSConfig()` returns the TLS configuration of the admin API.
+//
+// If a client CA is configured, client certificates signed by that CA
+// are verified; they are required if no bearer tokens are configured.
+//
+// Parameters:
+//   - `aConfig`: The configuration to use.
+//
+// Returns:
+//   - `*tls.Config`: The TLS configuration (`nil` for plain HTTP).
+//   - `error`: `nil` if the configuration is valid, the error otherwise.
+func adminTLSConfig(aConfig tConfiguration) (*tls.Config, error) {
+	if ("" == aConfig.AdminTLSCert) || ("" == aConfig.AdminTLSKey) {
+		if "" != aConfig.AdminClientCA {
+			return nil, errors.New("adminClientCA requires adminTLSCert and adminTLSKey")
+		}
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(aConfig.AdminTLSCert, aConfig.AdminTLSKey)
+	if nil != err {
+		return nil, fmt.Errorf("failed to load admin TLS certificate: %w", err)
+	}
+	result := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if "" != aConfig.AdminClientCA {
+		pool, err := loadCertPool(aConfig.AdminClientCA)
+		if nil != err {
+			return nil, err
+		}
+		result.ClientCAs = pool
+		result.ClientAuth = tls.RequireAndVerifyClientCert
+		if 0 < len(aConfig.AdminTokens) {
+			// Token holders don't need a client certificate
+			result.ClientAuth = tls.VerifyClientCertIfGiven
+		}
+	}
+
+	return result, nil
+} // adminTLSConfig()
+
+// `isMutating()` checks whether a request changes the server's state.
+//
+// Parameters:
+//   - `aRequest`: The HTTP request to check.
+//
+// Returns:
+//   - `bool`: `true` for mutating requests, `false` otherwise.
+func isMutating(aRequest *http.Request) bool {
+	switch aRequest.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	}
+
+	return true
+} // isMutating()
+
+// `loadCertPool()` reads PEM encoded certificates from a file.
+//
+// Parameters:
+//   - `aFilename`: The file to read.
+//
+// Returns:
+//   - `*x509.CertPool`: The certificates read.
+//   - `error`: `nil` if the certificates were read, the error otherwise.
+func loadCertPool(aFilename string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(aFilename) //#nosec G304
+	if nil != err {
+		return nil, fmt.Errorf("failed to read certificates: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %q", aFilename)
+	}
+
+	return pool, nil
+} // loadCertPool()
+
+/* _EoF_ */
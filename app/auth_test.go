@@ -0,0 +1,470 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mwat56/dnscache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tTestCert` is a certificate created for testing.
+	tTestCert struct {
+		cert    *x509.Certificate
+		key     *ecdsa.PrivateKey
+		tlsCert tls.Certificate
+		certPEM []byte
+		keyPEM  []byte
+	}
+)
+
+// `newTestCert()` creates a certificate signed by `aParent`
+// (self-signed if `aParent` is `nil`).
+func newTestCert(t *testing.T, aName string, aIsCA bool, aParent *tTestCert) *tTestCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if nil != err {
+		t.Fatalf("ecdsa.GenerateKey() error = '%v'", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: aName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  aIsCA,
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	parent, parentKey := template, key
+	if nil != aParent {
+		parent, parentKey = aParent.cert, aParent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if nil != err {
+		t.Fatalf("x509.CreateCertificate() error = '%v'", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+
+	result := &tTestCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+	result.tlsCert, err = tls.X509KeyPair(result.certPEM, result.keyPEM)
+	if nil != err {
+		t.Fatalf("tls.X509KeyPair() error = '%v'", err)
+	}
+
+	return result
+} // newTestCert()
+
+// `writeTestFile()` writes data to a file in a temporary directory.
+func writeTestFile(t *testing.T, aName string, aData []byte) string {
+	t.Helper()
+
+	fName := filepath.Join(t.TempDir(), aName)
+	if err := os.WriteFile(fName, aData, 0600); nil != err {
+		t.Fatalf("os.WriteFile() error = '%v'", err)
+	}
+
+	return fName
+} // writeTestFile()
+
+func Test_tAuth_identify(t *testing.T) {
+	auth := newAuth(tConfiguration{AdminTokens: []string{"s3cret", " ", "other"}})
+
+	tests := []struct {
+		name   string
+		auth   *tAuth
+		header string
+		wantOK bool
+	}{
+		/* */
+		{
+			name:   "01 - no authentication configured",
+			auth:   newAuth(tConfiguration{}),
+			header: "",
+			wantOK: true,
+		},
+		{
+			name:   "02 - missing token",
+			auth:   auth,
+			header: "",
+			wantOK: false,
+		},
+		{
+			name:   "03 - wrong scheme",
+			auth:   auth,
+			header: "Basic s3cret",
+			wantOK: false,
+		},
+		{
+			name:   "04 - wrong token",
+			auth:   auth,
+			header: "Bearer guess",
+			wantOK: false,
+		},
+		{
+			name:   "05 - valid token",
+			auth:   auth,
+			header: "Bearer s3cret",
+			wantOK: true,
+		},
+		{
+			name:   "06 - second token",
+			auth:   auth,
+			header: "bearer other",
+			wantOK: true,
+		},
+		{
+			name:   "07 - empty token",
+			auth:   auth,
+			header: "Bearer ",
+			wantOK: false,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/pause", nil)
+			if "" != tc.header {
+				req.Header.Set("Authorization", tc.header)
+			}
+			identity, ok := tc.auth.identify(req)
+			if ok != tc.wantOK {
+				t.Errorf("tAuth.identify() = '%v', want '%v'", ok, tc.wantOK)
+			}
+			if ok && strings.Contains(identity, "s3cret") {
+				t.Errorf("tAuth.identify() = '%s' reveals the token", identity)
+			}
+		})
+	}
+} // Test_tAuth_identify()
+
+func Test_adminTLSConfig(t *testing.T) {
+	server := newTestCert(t, "server", false, nil)
+	certFile := writeTestFile(t, "server.pem", server.certPEM)
+	keyFile := writeTestFile(t, "server.key", server.keyPEM)
+
+	tests := []struct {
+		name     string
+		config   tConfiguration
+		wantNil  bool
+		wantAuth tls.ClientAuthType
+		wantErr  bool
+	}{
+		/* */
+		{
+			name:    "01 - plain HTTP",
+			config:  tConfiguration{},
+			wantNil: true,
+		},
+		{
+			name:    "02 - client CA without certificate",
+			config:  tConfiguration{AdminClientCA: certFile},
+			wantErr: true,
+		},
+		{
+			name: "03 - missing certificate",
+			config: tConfiguration{
+				AdminTLSCert: filepath.Join(t.TempDir(), "missing.pem"),
+				AdminTLSKey:  keyFile,
+			},
+			wantErr: true,
+		},
+		{
+			name:     "04 - HTTPS",
+			config:   tConfiguration{AdminTLSCert: certFile, AdminTLSKey: keyFile},
+			wantAuth: tls.NoClientCert,
+		},
+		{
+			name: "05 - mutual TLS",
+			config: tConfiguration{
+				AdminTLSCert:  certFile,
+				AdminTLSKey:   keyFile,
+				AdminClientCA: certFile,
+			},
+			wantAuth: tls.RequireAndVerifyClientCert,
+		},
+		{
+			name: "06 - mutual TLS or token",
+			config: tConfiguration{
+				AdminTLSCert:  certFile,
+				AdminTLSKey:   keyFile,
+				AdminClientCA: certFile,
+				AdminTokens:   []string{"s3cret"},
+			},
+			wantAuth: tls.VerifyClientCertIfGiven,
+		},
+		{
+			name: "07 - invalid client CA",
+			config: tConfiguration{
+				AdminTLSCert:  certFile,
+				AdminTLSKey:   keyFile,
+				AdminClientCA: keyFile,
+			},
+			wantErr: true,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := adminTLSConfig(tc.config)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("adminTLSConfig() error = '%v', wantErr '%v'", err, tc.wantErr)
+				return
+			}
+			if tc.wantErr {
+				return
+			}
+			if (nil == got) != tc.wantNil {
+				t.Errorf("adminTLSConfig() = '%v', wantNil '%v'", got, tc.wantNil)
+				return
+			}
+			if (nil != got) && (got.ClientAuth != tc.wantAuth) {
+				t.Errorf("adminTLSConfig().ClientAuth = '%v', want '%v'",
+					got.ClientAuth, tc.wantAuth)
+			}
+		})
+	}
+} // Test_adminTLSConfig()
+
+func Test_tAdminServer_auth(t *testing.T) {
+	var buf bytes.Buffer
+	dnscache.SetLogger(dnscache.NewSlogLogger(slog.NewTextHandler(&buf, nil)))
+	defer dnscache.SetLogger(nil)
+
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	as := newAdminServer(resolver, tConfiguration{AdminTokens: []string{"s3cret"}})
+	as.enableDashboard()
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		token      string
+		wantStatus int
+		wantAudit  string
+	}{
+		/* */
+		{
+			name:       "01 - dashboard without token",
+			method:     http.MethodGet,
+			path:       "/",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "02 - API without token",
+			method:     http.MethodGet,
+			path:       "/api/pause",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "03 - API with token",
+			method:     http.MethodGet,
+			path:       "/api/pause",
+			token:      "s3cret",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "04 - mutating call without token",
+			method:     http.MethodPost,
+			path:       "/api/pause?duration=5m",
+			wantStatus: http.StatusUnauthorized,
+			wantAudit:  "unauthorised admin call",
+		},
+		{
+			name:       "05 - mutating call with token",
+			method:     http.MethodPost,
+			path:       "/api/pause?duration=5m",
+			token:      "s3cret",
+			wantStatus: http.StatusOK,
+			wantAudit:  `path=/api/pause params="duration=5m" identity=token:`,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			buf.Reset()
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			if "" != tc.token {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			rec := httptest.NewRecorder()
+			as.ServeHTTP(rec, req)
+
+			if rec.Code != tc.wantStatus {
+				t.Errorf("status = '%d', want '%d'", rec.Code, tc.wantStatus)
+			}
+			if got := buf.String(); !strings.Contains(got, tc.wantAudit) {
+				t.Errorf("audit log = '%s', want '%s'", got, tc.wantAudit)
+			}
+			if strings.Contains(buf.String(), "s3cret") {
+				t.Errorf("audit log = '%s' reveals the token", buf.String())
+			}
+		})
+	}
+} // Test_tAdminServer_auth()
+
+func Test_tAdminServer_mTLS(t *testing.T) {
+	ca := newTestCert(t, "test CA", true, nil)
+	server := newTestCert(t, "server", false, ca)
+	client := newTestCert(t, "admin", false, ca)
+	stranger := newTestCert(t, "stranger", false, nil)
+	caFile := writeTestFile(t, "ca.pem", ca.certPEM)
+
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	config := tConfiguration{
+		AdminTLSCert:  writeTestFile(t, "server.pem", server.certPEM),
+		AdminTLSKey:   writeTestFile(t, "server.key", server.keyPEM),
+		AdminClientCA: caFile,
+		AdminTokens:   []string{"s3cret"},
+	}
+	tlsConfig, err := adminTLSConfig(config)
+	if nil != err {
+		t.Fatalf("adminTLSConfig() error = '%v'", err)
+	}
+	ts := httptest.NewUnstartedServer(newAdminServer(resolver, config))
+	ts.TLS = tlsConfig
+	ts.StartTLS()
+	defer ts.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+
+	tests := []struct {
+		name       string
+		cert       *tTestCert
+		token      string
+		wantStatus int
+	}{
+		/* */
+		{
+			name:       "01 - neither certificate nor token",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "02 - client certificate",
+			cert:       client,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "03 - token only",
+			token:      "s3cret",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "04 - certificate of an unknown CA",
+			cert:       stranger,
+			wantStatus: http.StatusUnauthorized,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tlsClient := &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}
+			if nil != tc.cert {
+				tlsClient.Certificates = []tls.Certificate{tc.cert.tlsCert}
+			}
+			httpClient := &http.Client{
+				Transport: &http.Transport{TLSClientConfig: tlsClient},
+				Timeout:   5 * time.Second,
+			}
+			req, _ := http.NewRequest(http.MethodPost, ts.URL+"/api/pause",
+				strings.NewReader(url.Values{"duration": {"0"}}.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			if "" != tc.token {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			resp, err := httpClient.Do(req)
+			if nil != err {
+				t.Errorf("Do() error = '%v'", err)
+				return
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tc.wantStatus {
+				t.Errorf("status = '%d', want '%d'", resp.StatusCode, tc.wantStatus)
+			}
+		})
+	}
+} // Test_tAdminServer_mTLS()
+
+func Test_tAdminClient_token(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	server := httptest.NewServer(newAdminServer(resolver,
+		tConfiguration{AdminTokens: []string{"s3cret"}}))
+	defer server.Close()
+	address := strings.TrimPrefix(server.URL, "http://")
+
+	tests := []struct {
+		name    string
+		config  tConfiguration
+		wantErr bool
+	}{
+		/* */
+		{
+			name:    "01 - without token",
+			config:  tConfiguration{AdminAddress: address},
+			wantErr: true,
+		},
+		{
+			name:    "02 - wrong token",
+			config:  tConfiguration{AdminAddress: address, AdminTokens: []string{"guess"}},
+			wantErr: true,
+		},
+		{
+			name:   "03 - valid token",
+			config: tConfiguration{AdminAddress: address, AdminTokens: []string{"s3cret"}},
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := cmdBlockList(tc.config, nil, tOutput{Writer: &out})
+			if (nil != err) != tc.wantErr {
+				t.Errorf("cmdBlockList() error = '%v', wantErr '%v'", err, tc.wantErr)
+			}
+		})
+	}
+} // Test_tAdminClient_token()
+
+/* _EoF_ */
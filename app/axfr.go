@@ -0,0 +1,417 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strings"
+	"sync/atomic"
+
+	"github.com/mwat56/dnscache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+const (
+	// Additional DNS record types used by zone transfers
+	dnsTypeNS    uint16 = 2   // Name server
+	dnsTypeCNAME uint16 = 5   // Canonical name
+	dnsTypeSOA   uint16 = 6   // Start of authority
+	dnsTypeAXFR  uint16 = 252 // Zone transfer
+
+	// `zoneTTL` is the TTL (in seconds) of the transferred records.
+	zoneTTL = 300
+
+	// `maxAXFRMessage` is the maximal size of a single message of a
+	// zone transfer (well below the 64 KB TCP limit).
+	maxAXFRMessage = 16 << 10
+)
+
+type (
+	// `tZoneTransfer` holds the local zones that may be transferred
+	// and the clients that are allowed to do so.
+	tZoneTransfer struct {
+		zones   []string     // lower-cased zone names without trailing dot
+		clients []*net.IPNet // networks allowed to request a transfer
+	}
+
+	// `tZoneRecord` is a single resource record of a zone transfer.
+	tZoneRecord struct {
+		name  string
+		rType uint16
+		data  []byte
+	}
+)
+
+var (
+	// `gZoneTransfer` is the active zone transfer configuration
+	// (`nil` means zone transfers are disabled).
+	gZoneTransfer atomic.Pointer[tZoneTransfer]
+)
+
+// ---------------------------------------------------------------------------
+// `tZoneTransfer` constructor:
+
+// `newZoneTransfer()` creates the zone transfer configuration.
+//
+// Parameters:
+//   - `aConfig`: The configuration to use.
+//
+// Returns:
+//   - `*tZoneTransfer`: The zone transfer configuration (`nil` if no local zones are configured).
+//   - `error`: `nil` if the configuration is valid, the error otherwise.
+func newZoneTransfer(aConfig tConfiguration) (*tZoneTransfer, error) {
+	if 0 == len(aConfig.LocalZones) {
+		if 0 < len(aConfig.ZoneTransfers) {
+			return nil, fmt.Errorf("zoneTransfers configured without localZones")
+		}
+		return nil, nil
+	}
+
+	result := &tZoneTransfer{}
+	for _, zone := range aConfig.LocalZones {
+		name := strings.Trim(strings.ToLower(strings.TrimSpace(zone)), ".")
+		if nil == encodeName(name) {
+			return nil, fmt.Errorf("invalid local zone: %q", zone)
+		}
+		result.zones = append(result.zones, name)
+	}
+	for _, client := range aConfig.ZoneTransfers {
+		network := parseClientNetwork(client)
+		if nil == network {
+			return nil, fmt.Errorf("invalid zone transfer client: %q", client)
+		}
+		result.clients = append(result.clients, network)
+	}
+
+	return result, nil
+} // newZoneTransfer()
+
+// ---------------------------------------------------------------------------
+// `tZoneTransfer` methods:
+
+// `allows()` checks whether a client may request a zone transfer.
+//
+// Parameters:
+//   - `aClient`: The client's IP address.
+//
+// Returns:
+//   - `bool`: `true` if the client is allowed, `false` otherwise.
+func (zt *tZoneTransfer) allows(aClient net.IP) bool {
+	if (nil == zt) || (nil == aClient) {
+		return false
+	}
+	for _, network := range zt.clients {
+		if network.Contains(aClient) {
+			return true
+		}
+	}
+
+	return false
+} // allows()
+
+// `zone()` returns the local zone with the given name.
+//
+// Parameters:
+//   - `aName`: The requested zone's name.
+//
+// Returns:
+//   - `string`: The normalised zone name.
+//   - `bool`: `true` if the zone is a local one, `false` otherwise.
+func (zt *tZoneTransfer) zone(aName string) (string, bool) {
+	if nil == zt {
+		return "", false
+	}
+	name := strings.Trim(strings.ToLower(aName), ".")
+	for _, zone := range zt.zones {
+		if zone == name {
+			return zone, true
+		}
+	}
+
+	return "", false
+} // zone()
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `buildZoneMessages()` creates the DNS messages of a zone transfer.
+//
+// The transfer starts and ends with the zone's SOA record as required
+// by RFC 5936; the records in between are the zone's NS record and the
+// records derived from the resolver's rewrite rules.
+//
+// Parameters:
+//   - `aID`: The DNS request ID.
+//   - `aQuestion`: The question section of the request.
+//   - `aZone`: The zone's name.
+//   - `aRules`: The rewrite rules within the zone.
+//   - `aSerial`: The zone's serial number.
+//
+// Returns:
+//   - `[][]byte`: The messages to send (without TCP length prefix).
+func buildZoneMessages(aID uint16, aQuestion []byte, aZone string,
+	aRules []dnscache.TRewriteRule, aSerial uint32) [][]byte {
+	soa := tZoneRecord{
+		name:  aZone,
+		rType: dnsTypeSOA,
+		data:  soaData(aZone, aSerial),
+	}
+	records := []tZoneRecord{soa, {
+		name:  aZone,
+		rType: dnsTypeNS,
+		data:  encodeName("ns." + aZone),
+	}}
+	records = append(records, zoneRecords(aRules)...)
+	records = append(records, soa)
+
+	var (
+		result  [][]byte
+		message []byte
+		count   uint16
+	)
+	newMessage := func(aQuestion []byte) {
+		message = make([]byte, 12, maxAXFRMessage)
+		binary.BigEndian.PutUint16(message[0:2], aID)
+		binary.BigEndian.PutUint16(message[2:4], dnsQR|dnsAA)
+		if 0 < len(aQuestion) {
+			binary.BigEndian.PutUint16(message[4:6], 1)
+			message = append(message, aQuestion...)
+		}
+		count = 0
+	}
+	newMessage(aQuestion)
+
+	for _, rec := range records {
+		owner := encodeName(rec.name)
+		if (nil == owner) || (nil == rec.data) {
+			continue // not representable in the wire format
+		}
+		size := len(owner) + 10 + len(rec.data)
+		if (0 < count) && (maxAXFRMessage < len(message)+size) {
+			binary.BigEndian.PutUint16(message[6:8], count)
+			result = append(result, message)
+			newMessage(nil)
+		}
+		message = append(message, owner...)
+		message = binary.BigEndian.AppendUint16(message, rec.rType)
+		message = binary.BigEndian.AppendUint16(message, dnsClassIN)
+		message = binary.BigEndian.AppendUint32(message, zoneTTL)
+		message = binary.BigEndian.AppendUint16(message, uint16(len(rec.data))) //#nosec G115
+		message = append(message, rec.data...)
+		count++
+	}
+	binary.BigEndian.PutUint16(message[6:8], count)
+
+	return append(result, message)
+} // buildZoneMessages()
+
+// `encodeName()` converts a hostname to the DNS wire format.
+//
+// Parameters:
+//   - `aName`: The hostname to encode.
+//
+// Returns:
+//   - `[]byte`: The encoded name (`nil` if the name is invalid).
+func encodeName(aName string) []byte {
+	name := strings.Trim(aName, ".")
+	if ("" == name) || (253 < len(name)) {
+		return nil
+	}
+
+	result := make([]byte, 0, len(name)+2)
+	for _, label := range strings.Split(name, ".") {
+		if (0 == len(label)) || (63 < len(label)) {
+			return nil
+		}
+		result = append(result, byte(len(label)))
+		result = append(result, label...)
+	}
+
+	return append(result, 0)
+} // encodeName()
+
+// `handleAXFR()` answers a zone transfer request.
+//
+// Parameters:
+//   - `aConn`: The TCP connection to write the response to.
+//   - `aRequest`: The DNS request message.
+//   - `aResolver`: The DNS resolver providing the local records.
+//
+// Returns:
+//   - `error`: `nil` if the response was sent, the error otherwise.
+func handleAXFR(aConn net.Conn, aRequest []byte, aResolver *dnscache.TResolver) error {
+	id := binary.BigEndian.Uint16(aRequest[0:2])
+	question := aRequest[12:]
+	if end := questionEnd(aRequest); 0 < end {
+		question = aRequest[12:end]
+	}
+	zt := gZoneTransfer.Load()
+	client := addrIP(aConn.RemoteAddr())
+
+	zone, ok := zt.zone(extractFirstHostname(aRequest))
+	if !ok || !zt.allows(client) {
+		gServerLog.Info("zone transfer refused",
+			"client", client, "zone", extractFirstHostname(aRequest))
+		response := make([]byte, 12, 12+len(question))
+		binary.BigEndian.PutUint16(response[0:2], id)
+		binary.BigEndian.PutUint16(response[2:4], dnsQR|dnsRcodeRefused)
+		binary.BigEndian.PutUint16(response[4:6], 1)
+		_, err := tTCPConn{aConn}.WriteTo(append(response, question...), nil)
+
+		return err
+	}
+
+	rules, serial := aResolver.ZoneRules(zone)
+	gServerLog.Info("zone transfer", "client", client, "zone", zone,
+		"serial", serial, "rules", len(rules))
+	for _, message := range buildZoneMessages(id, question, zone, rules, serial) {
+		if _, err := (tTCPConn{aConn}).WriteTo(message, nil); nil != err {
+			return err
+		}
+	}
+
+	return nil
+} // handleAXFR()
+
+// `parseClientNetwork()` parses a client's address range.
+//
+// A single IP address (without a prefix length) is accepted as well.
+//
+// Parameters:
+//   - `aClient`: The client's IP address or address range.
+//
+// Returns:
+//   - `*net.IPNet`: The address range (`nil` if invalid).
+func parseClientNetwork(aClient string) *net.IPNet {
+	if !strings.Contains(aClient, "/") {
+		ip := net.ParseIP(aClient)
+		if nil == ip {
+			return nil
+		}
+		bits := 8 * len(ip.To16())
+		if nil != ip.To4() {
+			ip, bits = ip.To4(), 32
+		}
+
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}
+	}
+	_, network, err := net.ParseCIDR(aClient)
+	if nil != err {
+		return nil
+	}
+
+	return network
+} // parseClientNetwork()
+
+// `questionEnd()` returns the offset following the first question
+// of a DNS message.
+//
+// Parameters:
+//   - `aRequest`: The DNS message.
+//
+// Returns:
+//   - `int`: The offset after the question (`0` if malformed).
+func questionEnd(aRequest []byte) int {
+	offset := 12
+	for offset < len(aRequest) {
+		labelLen := int(aRequest[offset])
+		if 0 == labelLen {
+			offset++
+			if offset+4 > len(aRequest) {
+				return 0
+			}
+			return offset + 4
+		}
+		if 0 != labelLen&0xC0 {
+			return 0 // compression isn't allowed in questions
+		}
+		offset += labelLen + 1
+	}
+
+	return 0
+} // questionEnd()
+
+// `questionType()` returns the type and class of the first question
+// of a DNS message.
+//
+// Parameters:
+//   - `aRequest`: The DNS message.
+//
+// Returns:
+//   - `rType`: The question's type.
+//   - `rClass`: The question's class.
+//   - `rOK`: `false` if the message is malformed.
+func questionType(aRequest []byte) (rType, rClass uint16, rOK bool) {
+	end := questionEnd(aRequest)
+	if 0 == end {
+		return
+	}
+
+	return binary.BigEndian.Uint16(aRequest[end-4 : end-2]),
+		binary.BigEndian.Uint16(aRequest[end-2 : end]), true
+} // questionType()
+
+// `soaData()` returns the RDATA of a zone's SOA record.
+//
+// Parameters:
+//   - `aZone`: The zone's name.
+//   - `aSerial`: The zone's serial number.
+//
+// Returns:
+//   - `[]byte`: The SOA record's data.
+func soaData(aZone string, aSerial uint32) []byte {
+	result := encodeName("ns." + aZone)
+	result = append(result, encodeName("hostmaster."+aZone)...)
+	result = binary.BigEndian.AppendUint32(result, aSerial)
+	result = binary.BigEndian.AppendUint32(result, 3600)    // refresh
+	result = binary.BigEndian.AppendUint32(result, 600)     // retry
+	result = binary.BigEndian.AppendUint32(result, 86400)   // expire
+	result = binary.BigEndian.AppendUint32(result, zoneTTL) // minimum
+
+	return result
+} // soaData()
+
+// `zoneRecords()` converts rewrite rules to resource records.
+//
+// Parameters:
+//   - `aRules`: The rewrite rules to convert.
+//
+// Returns:
+//   - `[]tZoneRecord`: The resulting records.
+func zoneRecords(aRules []dnscache.TRewriteRule) []tZoneRecord {
+	result := make([]tZoneRecord, 0, len(aRules))
+	for _, rule := range aRules {
+		if dnscache.RewriteCNAME == rule.Type {
+			result = append(result, tZoneRecord{
+				name:  rule.Match,
+				rType: dnsTypeCNAME,
+				data:  encodeName(rule.Target),
+			})
+			continue
+		}
+
+		for _, part := range strings.Split(rule.Target, ",") {
+			ip := net.ParseIP(strings.TrimSpace(part))
+			if ip4 := ip.To4(); nil != ip4 {
+				result = append(result, tZoneRecord{
+					name: rule.Match, rType: dnsTypeA, data: ip4,
+				})
+			} else if nil != ip {
+				result = append(result, tZoneRecord{
+					name: rule.Match, rType: dnsTypeAAAA, data: ip.To16(),
+				})
+			}
+		}
+	}
+
+	return result
+} // zoneRecords()
+
+/* _EoF_ */
@@ -0,0 +1,385 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/mwat56/dnscache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// `readTCPMessage()` reads a length-prefixed DNS message from `aConn`.
+func readTCPMessage(t *testing.T, aConn net.Conn) []byte {
+	t.Helper()
+
+	_ = aConn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var prefix [2]byte
+	if _, err := io.ReadFull(aConn, prefix[:]); nil != err {
+		t.Fatalf("reading message length: '%v'", err)
+	}
+	message := make([]byte, binary.BigEndian.Uint16(prefix[:]))
+	if _, err := io.ReadFull(aConn, message); nil != err {
+		t.Fatalf("reading message: '%v'", err)
+	}
+
+	return message
+} // readTCPMessage()
+
+// `recordTypes()` returns the types of the answer records of a
+// DNS message.
+func recordTypes(aMessage []byte) []uint16 {
+	offset := 12
+	if 0 < binary.BigEndian.Uint16(aMessage[4:6]) {
+		offset = questionEnd(aMessage)
+	}
+	count := int(binary.BigEndian.Uint16(aMessage[6:8]))
+	result := make([]uint16, 0, count)
+	for range count {
+		for 0 != aMessage[offset] {
+			if 0xC0 == aMessage[offset]&0xC0 {
+				offset++ // compression pointer
+				break
+			}
+			offset += int(aMessage[offset]) + 1
+		}
+		offset++
+		result = append(result, binary.BigEndian.Uint16(aMessage[offset:offset+2]))
+		offset += 10 + int(binary.BigEndian.Uint16(aMessage[offset+8:offset+10]))
+	}
+
+	return result
+} // recordTypes()
+
+func Test_encodeName(t *testing.T) {
+	tests := []struct {
+		name string
+		host string
+		want []byte
+	}{
+		/* */
+		{
+			name: "01 - empty name",
+			host: "",
+			want: nil,
+		},
+		{
+			name: "02 - simple name",
+			host: "nas.lan",
+			want: []byte("\x03nas\x03lan\x00"),
+		},
+		{
+			name: "03 - trailing dot",
+			host: "*.lan.",
+			want: []byte("\x01*\x03lan\x00"),
+		},
+		{
+			name: "04 - empty label",
+			host: "nas..lan",
+			want: nil,
+		},
+		{
+			name: "05 - label too long",
+			host: fmt.Sprintf("%064d.lan", 0),
+			want: nil,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := encodeName(tc.host); !bytes.Equal(got, tc.want) {
+				t.Errorf("encodeName() = '%q', want '%q'", got, tc.want)
+			}
+		})
+	}
+} // Test_encodeName()
+
+func Test_newZoneTransfer(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     tConfiguration
+		wantNil    bool
+		wantErr    bool
+		wantZone   string
+		wantClient string
+	}{
+		/* */
+		{
+			name:    "01 - no local zones",
+			config:  tConfiguration{},
+			wantNil: true,
+		},
+		{
+			name:    "02 - clients without zones",
+			config:  tConfiguration{ZoneTransfers: []string{"192.168.1.2"}},
+			wantErr: true,
+		},
+		{
+			name:    "03 - invalid zone",
+			config:  tConfiguration{LocalZones: []string{"home..lan"}},
+			wantErr: true,
+		},
+		{
+			name: "04 - invalid client",
+			config: tConfiguration{
+				LocalZones:    []string{"home.lan"},
+				ZoneTransfers: []string{"secondary"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "05 - single address",
+			config: tConfiguration{
+				LocalZones:    []string{"Home.LAN."},
+				ZoneTransfers: []string{"192.168.1.2"},
+			},
+			wantZone:   "home.lan",
+			wantClient: "192.168.1.2",
+		},
+		{
+			name: "06 - network",
+			config: tConfiguration{
+				LocalZones:    []string{"home.lan"},
+				ZoneTransfers: []string{"fd00::/8"},
+			},
+			wantZone:   "home.lan",
+			wantClient: "fd00::53",
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := newZoneTransfer(tc.config)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("newZoneTransfer() error = '%v', wantErr '%v'", err, tc.wantErr)
+				return
+			}
+			if tc.wantErr {
+				return
+			}
+			if (nil == got) != tc.wantNil {
+				t.Errorf("newZoneTransfer() = '%v', wantNil '%v'", got, tc.wantNil)
+				return
+			}
+			if tc.wantNil {
+				return
+			}
+			if zone, ok := got.zone("home.lan"); !ok || (zone != tc.wantZone) {
+				t.Errorf("zone() = '%v', want '%v'", zone, tc.wantZone)
+			}
+			if !got.allows(net.ParseIP(tc.wantClient)) {
+				t.Errorf("allows(%s) = 'false', want 'true'", tc.wantClient)
+			}
+			if got.allows(net.ParseIP("198.51.100.1")) {
+				t.Errorf("allows(198.51.100.1) = 'true', want 'false'")
+			}
+		})
+	}
+} // Test_newZoneTransfer()
+
+func Test_buildZoneMessages(t *testing.T) {
+	question := createDNSQuery("home.lan", dnsTypeAXFR)[12:]
+	small := []dnscache.TRewriteRule{
+		{Match: "*.dev.home.lan", Type: dnscache.RewriteCNAME, Target: "nas.home.lan"},
+		{Match: "nas.home.lan", Type: dnscache.RewriteIP, Target: "192.168.1.10, fd00::10"},
+	}
+	var large []dnscache.TRewriteRule
+	for i := range 2000 {
+		large = append(large, dnscache.TRewriteRule{
+			Match:  fmt.Sprintf("host%04d.home.lan", i),
+			Type:   dnscache.RewriteIP,
+			Target: "192.168.1.1",
+		})
+	}
+
+	tests := []struct {
+		name         string
+		rules        []dnscache.TRewriteRule
+		wantMessages int
+		wantRecords  int
+	}{
+		/* */
+		{
+			name:         "01 - empty zone",
+			rules:        nil,
+			wantMessages: 1,
+			wantRecords:  3, // SOA, NS, SOA
+		},
+		{
+			name:         "02 - small zone",
+			rules:        small,
+			wantMessages: 1,
+			wantRecords:  6,
+		},
+		{
+			name:         "03 - large zone",
+			rules:        large,
+			wantMessages: 5,
+			wantRecords:  2003,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			messages := buildZoneMessages(1234, question, "home.lan", tc.rules, 42)
+			if len(messages) != tc.wantMessages {
+				t.Errorf("len(buildZoneMessages()) = '%d', want '%d'",
+					len(messages), tc.wantMessages)
+			}
+			var types []uint16
+			for _, message := range messages {
+				if maxAXFRMessage < len(message) {
+					t.Errorf("message size = '%d', want <= '%d'", len(message), maxAXFRMessage)
+				}
+				if id := binary.BigEndian.Uint16(message[0:2]); 1234 != id {
+					t.Errorf("message ID = '%d', want '1234'", id)
+				}
+				types = append(types, recordTypes(message)...)
+			}
+			if len(types) != tc.wantRecords {
+				t.Errorf("records = '%d', want '%d'", len(types), tc.wantRecords)
+				return
+			}
+			if (dnsTypeSOA != types[0]) || (dnsTypeSOA != types[len(types)-1]) {
+				t.Errorf("first/last record = '%d'/'%d', want SOA",
+					types[0], types[len(types)-1])
+			}
+		})
+	}
+} // Test_buildZoneMessages()
+
+func Test_serveTCP(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	_ = resolver.AddRewrite(dnscache.TRewriteRule{
+		Match: "nas.home.lan", Type: dnscache.RewriteIP, Target: "192.168.1.10"})
+	_ = resolver.AddRewrite(dnscache.TRewriteRule{
+		Match: "www.home.lan", Type: dnscache.RewriteCNAME, Target: "nas.home.lan"})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatalf("net.Listen() error = '%v'", err)
+	}
+	defer listener.Close()
+	go serveTCP(listener, resolver, "", &tStdForwarder{})
+	defer gZoneTransfer.Store(nil)
+
+	tests := []struct {
+		name      string
+		clients   []string
+		query     []byte
+		wantRcode uint16
+		wantTypes []uint16
+	}{
+		/* */
+		{
+			name:      "01 - A query",
+			query:     createDNSQuery("nas.home.lan", dnsTypeA),
+			wantRcode: dnsRcodeNoError,
+			wantTypes: []uint16{dnsTypeA},
+		},
+		{
+			name:      "02 - transfers disabled",
+			query:     createDNSQuery("home.lan", dnsTypeAXFR),
+			wantRcode: dnsRcodeRefused,
+		},
+		{
+			name:      "03 - client not allowed",
+			clients:   []string{"192.168.1.0/24"},
+			query:     createDNSQuery("home.lan", dnsTypeAXFR),
+			wantRcode: dnsRcodeRefused,
+		},
+		{
+			name:      "04 - unknown zone",
+			clients:   []string{"127.0.0.1"},
+			query:     createDNSQuery("office.lan", dnsTypeAXFR),
+			wantRcode: dnsRcodeRefused,
+		},
+		{
+			name:      "05 - zone transfer",
+			clients:   []string{"127.0.0.0/8"},
+			query:     createDNSQuery("home.lan", dnsTypeAXFR),
+			wantRcode: dnsRcodeNoError,
+			wantTypes: []uint16{dnsTypeSOA, dnsTypeNS, dnsTypeA, dnsTypeCNAME, dnsTypeSOA},
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			zt, _ := newZoneTransfer(tConfiguration{
+				LocalZones:    []string{"home.lan"},
+				ZoneTransfers: tc.clients,
+			})
+			if 0 == len(tc.clients) {
+				zt = nil
+			}
+			gZoneTransfer.Store(zt)
+
+			conn, err := net.Dial("tcp", listener.Addr().String())
+			if nil != err {
+				t.Fatalf("net.Dial() error = '%v'", err)
+			}
+			defer conn.Close()
+			if _, err := (tTCPConn{conn}).WriteTo(tc.query, nil); nil != err {
+				t.Fatalf("WriteTo() error = '%v'", err)
+			}
+
+			message := readTCPMessage(t, conn)
+			if rcode := binary.BigEndian.Uint16(message[2:4]) & 0x000F; rcode != tc.wantRcode {
+				t.Errorf("rcode = '%d', want '%d'", rcode, tc.wantRcode)
+				return
+			}
+			if dnsTypeAXFR != binary.BigEndian.Uint16(tc.query[len(tc.query)-4:]) {
+				// a single answer for normal queries
+				if got := recordTypes(message); len(got) != len(tc.wantTypes) {
+					t.Errorf("answers = '%v', want '%v'", got, tc.wantTypes)
+				}
+				return
+			}
+			var got []uint16
+			for 0 < len(tc.wantTypes) {
+				got = append(got, recordTypes(message)...)
+				if (1 < len(got)) && (dnsTypeSOA == got[len(got)-1]) {
+					break
+				}
+				message = readTCPMessage(t, conn)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.wantTypes) {
+				t.Errorf("records = '%v', want '%v'", got, tc.wantTypes)
+			}
+		})
+	}
+} // Test_serveTCP()
+
+func Fuzz_questionType(f *testing.F) {
+	f.Add(createDNSQuery("www.example.com", dnsTypeA))
+	f.Add(createDNSQuery("home.lan", dnsTypeAXFR))
+	f.Add([]byte{0, 1, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 63, 'a'})
+	f.Add([]byte{})
+
+	f.Fuzz(func(t *testing.T, aRequest []byte) {
+		if _, _, ok := questionType(aRequest); !ok {
+			return
+		}
+		if end := questionEnd(aRequest); (12+5 > end) || (end > len(aRequest)) {
+			t.Errorf("questionEnd() = '%d', want 17..%d", end, len(aRequest))
+		}
+	})
+} // Fuzz_questionType()
+
+/* _EoF_ */
@@ -0,0 +1,114 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/mwat56/dnscache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+const (
+	// Backends propagating the cache changes within a cluster
+	cacheSyncGossip = "gossip"
+	cacheSyncRedis  = "redis"
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `checkCacheSync()` validates the cache synchronisation settings.
+//
+// Parameters:
+//   - `aConfig`: The configuration to check.
+//
+// Returns:
+//   - `error`: `nil` if the settings are valid, the joined errors otherwise.
+func checkCacheSync(aConfig tConfiguration) error {
+	cs := aConfig.CacheSync
+	if nil == cs {
+		return nil
+	}
+	var errs []error
+
+	switch cs.Backend {
+	case cacheSyncGossip:
+		if _, _, err := net.SplitHostPort(cs.Address); nil != err {
+			errs = append(errs, fmt.Errorf("invalid cacheSync address %q: %w", cs.Address, err))
+		}
+		if 0 == len(cs.Peers) {
+			errs = append(errs, errors.New("no cacheSync peers given"))
+		}
+		for _, peer := range cs.Peers {
+			if _, _, err := net.SplitHostPort(peer); nil != err {
+				errs = append(errs, fmt.Errorf("invalid cacheSync peer %q: %w", peer, err))
+			}
+		}
+
+	case cacheSyncRedis:
+		if "" == cs.Address {
+			break // the default address
+		}
+		if _, _, err := net.SplitHostPort(cs.Address); nil != err {
+			errs = append(errs, fmt.Errorf("invalid cacheSync address %q: %w", cs.Address, err))
+		}
+
+	default:
+		errs = append(errs, fmt.Errorf("invalid cacheSync backend: %q", cs.Backend))
+	}
+
+	return errors.Join(errs...)
+} // checkCacheSync()
+
+// `startCacheSync()` connects the resolver to the other instances
+// of its cluster if configured.
+//
+// Parameters:
+//   - `aResolver`: The resolver whose cache to synchronise.
+//   - `aConfig`: The configuration to use.
+//
+// Returns:
+//   - `error`: `nil` if the synchronisation was started (or isn't
+//     configured), the error otherwise.
+func startCacheSync(aResolver *dnscache.TResolver, aConfig tConfiguration) error {
+	if err := checkCacheSync(aConfig); (nil != err) || (nil == aConfig.CacheSync) {
+		return err
+	}
+	cs := aConfig.CacheSync
+
+	var (
+		backend dnscache.ICacheSync
+		err     error
+	)
+	if cacheSyncGossip == cs.Backend {
+		backend, err = dnscache.NewGossipSync(dnscache.TGossipOptions{
+			Listen: cs.Address,
+			Peers:  cs.Peers,
+			Key:    cs.Key,
+		})
+	} else {
+		backend, err = dnscache.NewRedisSync(dnscache.TRedisOptions{
+			Address:  cs.Address,
+			Password: cs.Password,
+			Channel:  cs.Channel,
+			Key:      cs.Key,
+		})
+	}
+	if nil != err {
+		return err
+	}
+	aResolver.SetCacheSync(backend)
+	gServerLog.Info("synchronising the cache", "backend", cs.Backend)
+
+	return nil
+} // startCacheSync()
+
+/* _EoF_ */
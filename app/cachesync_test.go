@@ -0,0 +1,68 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"testing"
+
+	"github.com/mwat56/dnscache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_checkCacheSync(t *testing.T) {
+	tests := []struct {
+		name    string
+		sync    *tCacheSyncConfig
+		wantErr bool
+	}{
+		/* */
+		{"01 - not configured", nil, false},
+		{"02 - gossip", &tCacheSyncConfig{Backend: "gossip", Address: ":5300",
+			Peers: []string{"192.0.2.2:5300"}}, false},
+		{"03 - gossip without peers", &tCacheSyncConfig{Backend: "gossip", Address: ":5300"}, true},
+		{"04 - invalid gossip peer", &tCacheSyncConfig{Backend: "gossip", Address: ":5300",
+			Peers: []string{"192.0.2.2"}}, true},
+		{"05 - invalid gossip address", &tCacheSyncConfig{Backend: "gossip",
+			Peers: []string{"192.0.2.2:5300"}}, true},
+		{"06 - redis default", &tCacheSyncConfig{Backend: "redis"}, false},
+		{"07 - redis", &tCacheSyncConfig{Backend: "redis", Address: "redis.localdomain:6379"}, false},
+		{"08 - invalid redis address", &tCacheSyncConfig{Backend: "redis", Address: "redis.localdomain"}, true},
+		{"09 - invalid backend", &tCacheSyncConfig{Backend: "multicast"}, true},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkCacheSync(tConfiguration{CacheSync: tc.sync})
+			if (nil != err) != tc.wantErr {
+				t.Errorf("checkCacheSync() error = '%v', wantErr '%v'", err, tc.wantErr)
+			}
+		})
+	}
+} // Test_checkCacheSync()
+
+func Test_startCacheSync(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	defer resolver.SetCacheSync(nil)
+
+	if err := startCacheSync(resolver, tConfiguration{}); nil != err {
+		t.Errorf("startCacheSync() error = '%v', want 'nil'", err)
+	}
+	config := tConfiguration{CacheSync: &tCacheSyncConfig{Backend: "gossip",
+		Address: "127.0.0.1:0", Peers: []string{"127.0.0.1:5300"}}}
+	if err := startCacheSync(resolver, config); nil != err {
+		t.Errorf("startCacheSync() error = '%v', want 'nil'", err)
+	}
+	config.CacheSync = &tCacheSyncConfig{Backend: "redis", Address: "127.0.0.1:1"}
+	if err := startCacheSync(resolver, config); nil == err {
+		t.Error("startCacheSync() error = 'nil', want connection error")
+	}
+} // Test_startCacheSync()
+
+/* _EoF_ */
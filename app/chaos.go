@@ -0,0 +1,154 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"encoding/binary"
+	"net"
+	"strings"
+	"sync/atomic"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+const (
+	// DNS classes and record types
+	dnsClassCH  uint16 = 3   // Chaos class
+	dnsClassANY uint16 = 255 // any class
+	dnsTypeTXT  uint16 = 16  // text record
+
+	// `dnsOpcodeMask` selects the opcode of the header flags
+	// (`0` means a standard query).
+	dnsOpcodeMask uint16 = 0xF << 11
+)
+
+var (
+	// `gChaosHostname` is the answer to `hostname.bind` and `id.server`
+	// queries (empty means to refuse them).
+	gChaosHostname atomic.Pointer[string]
+
+	// `gChaosVersion` is the answer to `version.bind` and
+	// `version.server` queries (empty means to refuse them).
+	gChaosVersion atomic.Pointer[string]
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `chaosText()` returns the configured answer to a CH class query.
+//
+// Parameters:
+//   - `aName`: The queried name.
+//
+// Returns:
+//   - `string`: The answer's text (empty if the name is unknown or
+//     the answer is not configured).
+func chaosText(aName string) string {
+	var text *string
+	switch strings.ToLower(strings.TrimSuffix(aName, ".")) {
+	case "hostname.bind", "id.server":
+		text = gChaosHostname.Load()
+	case "version.bind", "version.server":
+		text = gChaosVersion.Load()
+	}
+	if nil == text {
+		return ""
+	}
+
+	return *text
+} // chaosText()
+
+// `handleUnsupportedRequest()` answers requests which aren't
+// standard queries of the Internet class.
+//
+// Requests with an opcode other than QUERY are answered with NOTIMP.
+// CH class queries for the server's version or hostname are answered
+// with the configured text, all other queries of classes other than
+// IN with REFUSED.
+//
+// Parameters:
+//   - `aConn`: The connection to write the response to.
+//   - `aAddr`: The address to send the response to.
+//   - `aRequest`: The DNS request message.
+//
+// Returns:
+//   - `bool`: `true` if the request was answered, `false` otherwise.
+func handleUnsupportedRequest(aConn net.PacketConn, aAddr net.Addr, aRequest []byte) bool {
+	if 0 != binary.BigEndian.Uint16(aRequest[2:4])&dnsOpcodeMask {
+		sendRcodeResponse(aConn, aAddr, aRequest, dnsRcodeNotImp)
+		return true
+	}
+	qType, qClass, ok := questionType(aRequest)
+	if !ok || (dnsClassIN == qClass) || (dnsClassANY == qClass) {
+		return false
+	}
+	if dnsClassCH != qClass {
+		sendRcodeResponse(aConn, aAddr, aRequest, dnsRcodeRefused)
+		return true
+	}
+
+	text := chaosText(extractFirstHostname(aRequest))
+	if ("" == text) || ((dnsTypeTXT != qType) && (dnsTypeANY != qType)) {
+		sendRcodeResponse(aConn, aAddr, aRequest, dnsRcodeRefused)
+		return true
+	}
+	if 0xFF < len(text) {
+		text = text[:0xFF]
+	}
+
+	end := questionEnd(aRequest)
+	response := make([]byte, 12, end+14+len(text))
+	binary.BigEndian.PutUint16(response[0:2], binary.BigEndian.Uint16(aRequest[0:2]))
+	binary.BigEndian.PutUint16(response[2:4],
+		dnsQR|dnsAA|(binary.BigEndian.Uint16(aRequest[2:4])&dnsRD))
+	binary.BigEndian.PutUint16(response[4:6], 1) // QDCount
+	binary.BigEndian.PutUint16(response[6:8], 1) // ANCount
+	response = append(response, aRequest[12:end]...)
+
+	response = binary.BigEndian.AppendUint16(response, 0xC00C) // name pointer
+	response = binary.BigEndian.AppendUint16(response, dnsTypeTXT)
+	response = binary.BigEndian.AppendUint16(response, dnsClassCH)
+	response = binary.BigEndian.AppendUint32(response, 0)                   // don't cache
+	response = binary.BigEndian.AppendUint16(response, uint16(1+len(text))) //#nosec G115
+	response = append(response, byte(len(text)))
+	response = append(response, text...)
+
+	_, _ = aConn.WriteTo(response, aAddr)
+	// Error sending response is not critical, hence we ignore it.
+
+	return true
+} // handleUnsupportedRequest()
+
+// `sendRcodeResponse()` sends an empty response with the given
+// response code.
+//
+// The response keeps the request's opcode and (if well-formed) its
+// first question.
+//
+// Parameters:
+//   - `aConn`: The connection to write the response to.
+//   - `aAddr`: The address to send the response to.
+//   - `aRequest`: The DNS request message.
+//   - `aRcode`: The response code to send.
+func sendRcodeResponse(aConn net.PacketConn, aAddr net.Addr, aRequest []byte, aRcode uint16) {
+	flags := binary.BigEndian.Uint16(aRequest[2:4])
+	end := questionEnd(aRequest)
+
+	response := make([]byte, 12, 12+max(end-12, 0))
+	binary.BigEndian.PutUint16(response[0:2], binary.BigEndian.Uint16(aRequest[0:2]))
+	binary.BigEndian.PutUint16(response[2:4],
+		dnsQR|dnsRA|(flags&(dnsOpcodeMask|dnsRD))|aRcode)
+	if 0 < end {
+		binary.BigEndian.PutUint16(response[4:6], 1) // QDCount
+		response = append(response, aRequest[12:end]...)
+	}
+
+	_, _ = aConn.WriteTo(response, aAddr)
+	// Error sending response is not critical, hence we ignore it.
+} // sendRcodeResponse()
+
+/* _EoF_ */
@@ -0,0 +1,132 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// `chaosQuery()` creates a query of the given class.
+func chaosQuery(aName string, aType, aClass uint16) []byte {
+	query := createDNSQuery(aName, aType)
+	end := questionEnd(query)
+	binary.BigEndian.PutUint16(query[end-2:end], aClass)
+
+	return query[:end]
+} // chaosQuery()
+
+func Test_handleUnsupportedRequest(t *testing.T) {
+	version, hostname := "dnscache 1.2.3", ""
+	gChaosVersion.Store(&version)
+	gChaosHostname.Store(&hostname)
+	defer func() {
+		gChaosVersion.Store(nil)
+		gChaosHostname.Store(nil)
+	}()
+
+	notify := createDNSQuery("example.com", dnsTypeSOA)
+	binary.BigEndian.PutUint16(notify[2:4], 4<<11) // opcode NOTIFY
+
+	tests := []struct {
+		name      string
+		request   []byte
+		want      bool
+		wantRcode uint16
+		wantText  string
+	}{
+		/* */
+		{
+			name:    "01 - IN query",
+			request: createDNSQuery("example.com", dnsTypeA),
+			want:    false,
+		},
+		{
+			name:    "02 - ANY class",
+			request: chaosQuery("example.com", dnsTypeA, dnsClassANY),
+			want:    false,
+		},
+		{
+			name:      "03 - opcode NOTIFY",
+			request:   notify,
+			want:      true,
+			wantRcode: dnsRcodeNotImp,
+		},
+		{
+			name:      "04 - version.bind",
+			request:   chaosQuery("version.bind", dnsTypeTXT, dnsClassCH),
+			want:      true,
+			wantRcode: dnsRcodeNoError,
+			wantText:  version,
+		},
+		{
+			name:      "05 - hostname.bind not configured",
+			request:   chaosQuery("hostname.bind", dnsTypeTXT, dnsClassCH),
+			want:      true,
+			wantRcode: dnsRcodeRefused,
+		},
+		{
+			name:      "06 - unknown CH name",
+			request:   chaosQuery("authors.bind", dnsTypeTXT, dnsClassCH),
+			want:      true,
+			wantRcode: dnsRcodeRefused,
+		},
+		{
+			name:      "07 - CH A query",
+			request:   chaosQuery("version.bind", dnsTypeA, dnsClassCH),
+			want:      true,
+			wantRcode: dnsRcodeRefused,
+		},
+		{
+			name:      "08 - HS class",
+			request:   chaosQuery("example.com", dnsTypeA, 4),
+			want:      true,
+			wantRcode: dnsRcodeRefused,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var response []byte
+			conn := &tMockPacketConn{
+				writeTo: func(aBuf []byte, _ net.Addr) (int, error) {
+					response = append([]byte{}, aBuf...)
+					return len(aBuf), nil
+				},
+			}
+			if got := handleUnsupportedRequest(conn, &tMockAddr{}, tc.request); got != tc.want {
+				t.Errorf("handleUnsupportedRequest() = '%v', want '%v'", got, tc.want)
+			}
+			if !tc.want {
+				return
+			}
+			flags := binary.BigEndian.Uint16(response[2:4])
+			if got := flags & 0xF; got != tc.wantRcode {
+				t.Errorf("RCODE = '%d', want '%d'", got, tc.wantRcode)
+			}
+			if got := flags & dnsOpcodeMask; got != binary.BigEndian.Uint16(tc.request[2:4])&dnsOpcodeMask {
+				t.Errorf("opcode = '%d', want the request's", got>>11)
+			}
+			if "" == tc.wantText {
+				return
+			}
+			if got := binary.BigEndian.Uint16(response[6:8]); 1 != got {
+				t.Errorf("ANCount = '%d', want '1'", got)
+			}
+			if !bytes.HasSuffix(response, append([]byte{byte(len(tc.wantText))}, tc.wantText...)) {
+				t.Errorf("TXT data = '%q', want '%q'", response, tc.wantText)
+			}
+		})
+	}
+} // Test_handleUnsupportedRequest()
+
+/* _EoF_ */
@@ -0,0 +1,153 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tAdminClient` talks to the admin API of a running instance.
+	tAdminClient struct {
+		baseURL string
+		token   string // bearer token (if any)
+		client  *http.Client
+	}
+)
+
+var (
+	// `errNoAdminAddress` is returned if no admin API is configured.
+	errNoAdminAddress = errors.New("no admin address configured")
+)
+
+// ---------------------------------------------------------------------------
+// `tAdminClient` constructor:
+
+// `newAdminClient()` creates a client for the configured admin API.
+//
+// If the admin API is served via HTTPS, the configured server
+// certificate is trusted in addition to the system's root CAs
+// (which allows for self-signed certificates). The first configured
+// bearer token is used for authentication.
+//
+// Parameters:
+//   - `aConfig`: The configuration providing the admin address.
+//
+// Returns:
+//   - `*tAdminClient`: The new client.
+//   - `error`: `errNoAdminAddress` if no admin API is configured.
+func newAdminClient(aConfig tConfiguration) (*tAdminClient, error) {
+	if "" == aConfig.AdminAddress {
+		return nil, errNoAdminAddress
+	}
+
+	result := &tAdminClient{
+		baseURL: "http://" + aConfig.AdminAddress,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+	if 0 < len(aConfig.AdminTokens) {
+		result.token = strings.TrimSpace(aConfig.AdminTokens[0])
+	}
+
+	if "" != aConfig.AdminTLSCert {
+		roots, err := x509.SystemCertPool()
+		if nil != err {
+			roots = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(aConfig.AdminTLSCert)
+		if nil != err {
+			return nil, fmt.Errorf("failed to read admin certificate: %w", err)
+		}
+		roots.AppendCertsFromPEM(pem)
+
+		result.baseURL = "https://" + aConfig.AdminAddress
+		result.client.Transport = &http.Transport{
+			TLSClientConfig: &tls.Config{
+				RootCAs:    roots,
+				MinVersion: tls.VersionTLS12,
+			},
+		}
+	}
+
+	return result, nil
+} // newAdminClient()
+
+// ---------------------------------------------------------------------------
+// `tAdminClient` methods:
+
+// `call()` sends a request to the admin API and decodes the answer.
+//
+// Parameters:
+//   - `aMethod`: The HTTP method to use.
+//   - `aPath`: The API path (e.g. `/api/denylist`).
+//   - `aValues`: The request's form values (may be `nil`).
+//   - `aResult`: Pointer to the value to decode the answer into
+//     (an `io.Writer` receives the undecoded answer).
+//
+// Returns:
+//   - `error`: `nil` if the call succeeded, the error otherwise.
+func (ac *tAdminClient) call(aMethod, aPath string, aValues url.Values, aResult any) error {
+	target := ac.baseURL + aPath
+	var body io.Reader
+	if 0 < len(aValues) {
+		if http.MethodPost == aMethod {
+			body = strings.NewReader(aValues.Encode())
+		} else {
+			target += "?" + aValues.Encode()
+		}
+	}
+
+	req, err := http.NewRequest(aMethod, target, body)
+	if nil != err {
+		return err
+	}
+	if nil != body {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if "" != ac.token {
+		req.Header.Set("Authorization", "Bearer "+ac.token)
+	}
+
+	resp, err := ac.client.Do(req)
+	if nil != err {
+		return fmt.Errorf("admin API not reachable: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if http.StatusOK != resp.StatusCode {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&apiErr); (nil == err) && ("" != apiErr.Error) {
+			return errors.New(apiErr.Error)
+		}
+		return fmt.Errorf("admin API: %s", resp.Status)
+	}
+	if nil == aResult {
+		return nil
+	}
+	if out, ok := aResult.(io.Writer); ok {
+		_, err = io.Copy(out, resp.Body)
+		return err
+	}
+
+	return json.NewDecoder(resp.Body).Decode(aResult)
+} // call()
+
+/* _EoF_ */
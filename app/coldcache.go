@@ -0,0 +1,43 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"path/filepath"
+
+	"github.com/mwat56/dnscache/cache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `openColdStore()` opens the persistent store of the colder cache
+// entries if configured.
+//
+// A relative directory is taken relative to the data directory.
+//
+// Parameters:
+//   - `aConfig`: The configuration to use.
+//
+// Returns:
+//   - `*cache.TDiskStore`: The opened store (`nil` if not configured).
+//   - `error`: `nil` if the store was opened, the error otherwise.
+func openColdStore(aConfig tConfiguration) (*cache.TDiskStore, error) {
+	dir := aConfig.ColdCacheDir
+	if "" == dir {
+		return nil, nil
+	}
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(aConfig.DataDir, dir)
+	}
+
+	return cache.NewDiskStore(dir)
+} // openColdStore()
+
+/* _EoF_ */
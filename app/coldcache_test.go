@@ -0,0 +1,55 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_openColdStore(t *testing.T) {
+	dataDir := t.TempDir()
+	absDir := filepath.Join(t.TempDir(), "absolute")
+	tests := []struct {
+		name      string
+		config    tConfiguration
+		wantDir   string
+		wantStore bool
+	}{
+		/* */
+		{"01 - disabled", tConfiguration{DataDir: dataDir}, "", false},
+		{"02 - relative dir", tConfiguration{DataDir: dataDir, ColdCacheDir: "cold"},
+			filepath.Join(dataDir, "cold"), true},
+		{"03 - absolute dir", tConfiguration{DataDir: dataDir, ColdCacheDir: absDir},
+			absDir, true},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := openColdStore(tc.config)
+			if nil != err {
+				t.Fatalf("openColdStore() error = '%v'", err)
+			}
+			if (nil != got) != tc.wantStore {
+				t.Fatalf("openColdStore() = '%v', want a store '%v'", got, tc.wantStore)
+			}
+			if nil == got {
+				return
+			}
+			defer got.Close()
+			if fi, err := os.Stat(tc.wantDir); (nil != err) || !fi.IsDir() {
+				t.Errorf("openColdStore() didn't create '%s'", tc.wantDir)
+			}
+		})
+	}
+} // Test_openColdStore()
+
+/* _EoF_ */
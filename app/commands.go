@@ -0,0 +1,545 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mwat56/dnscache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tCommand` is a subcommand of the program.
+	tCommand struct {
+		name  string // space separated words, e.g. "block add"
+		args  string // description of the arguments
+		help  string // short description of the command
+		nArgs int    // number of required arguments
+		run   tCommandFunc
+	}
+
+	// `tCommandFunc` executes a subcommand.
+	//
+	// A `nil` function (i.e. the `serve` command) lets `main()`
+	// start the server.
+	tCommandFunc func(aConfig tConfiguration, aArgs []string, aOut tOutput) error
+
+	// `tOutput` is the destination of a subcommand's results.
+	//
+	// With the `--json` option the results are written as a single
+	// JSON value with stable field names instead of text lines.
+	tOutput struct {
+		io.Writer      // where to write the results to
+		json      bool // write JSON instead of text
+	}
+
+	// `tPatternResult` is the JSON result of a list change.
+	tPatternResult struct {
+		Action  string `json:"action"` // "added" or "removed"
+		Pattern string `json:"pattern"`
+	}
+
+	// `tStatusResult` is the JSON result of a command without data.
+	tStatusResult struct {
+		Status string `json:"status"`
+	}
+)
+
+var (
+	// `gCommands` lists all subcommands of the program.
+	gCommands = []tCommand{
+		{name: "serve", help: "run the DNS server (default)"},
+		{name: "query", args: "[--trace] <name>", nArgs: 1, run: cmdQuery,
+			help: "resolve a hostname using the running server"},
+		{name: "block add", args: "<pattern>", nArgs: 1, run: cmdBlockAdd,
+			help: "add a pattern to the deny list"},
+		{name: "block remove", args: "<pattern>", nArgs: 1, run: cmdBlockRemove,
+			help: "remove a pattern from the deny list"},
+		{name: "block find", args: "<text>|<pattern>", nArgs: 1, run: cmdBlockFind,
+			help: "list the allow/deny patterns matching a text or wildcard"},
+		{name: "block list", args: "[--format=simple|hosts|abp]", run: cmdBlockList,
+			help: "list all patterns of the deny list"},
+		{name: "cache dump", args: "[--format=hosts|json|csv|bind]", run: cmdCacheDump,
+			help: "list all cached hostnames"},
+		{name: "cache flush", args: "[<domain>|<pattern>]", run: cmdCacheFlush,
+			help: "remove all (or the matching) entries from the cache"},
+		{name: "stats", run: cmdStats,
+			help: "show the running server's metrics"},
+		{name: "lists report", run: cmdListsReport,
+			help: "show the statistics of the last blocklist load"},
+		{name: "lists update", run: cmdListsUpdate,
+			help: "reload the configured allow/deny lists"},
+		{name: "config check", run: cmdConfigCheck,
+			help: "validate the configuration file"},
+	}
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `findCommand()` returns the subcommand given by the command line.
+//
+// Without any arguments the `serve` command is returned.
+//
+// Parameters:
+//   - `aArgs`: The non-flag command line arguments.
+//
+// Returns:
+//   - `*tCommand`: The subcommand to run.
+//   - `[]string`: The subcommand's arguments.
+//   - `error`: `nil` if a valid command was given, the error otherwise.
+func findCommand(aArgs []string) (*tCommand, []string, error) {
+	if 0 == len(aArgs) {
+		return &gCommands[0], nil, nil
+	}
+
+	var (
+		result *tCommand
+		words  int
+	)
+	for idx := range gCommands {
+		cmd := &gCommands[idx]
+		parts := strings.Fields(cmd.name)
+		if (len(parts) <= len(aArgs)) && (len(parts) > words) &&
+			(strings.Join(parts, " ") == strings.Join(aArgs[:len(parts)], " ")) {
+			result, words = cmd, len(parts)
+		}
+	}
+	if nil == result {
+		return nil, nil, fmt.Errorf("unknown command: %q", strings.Join(aArgs, " "))
+	}
+
+	args := aArgs[words:]
+	if len(args) < result.nArgs {
+		return nil, nil, fmt.Errorf("usage: %s %s", result.name, result.args)
+	}
+
+	return result, args, nil
+} // findCommand()
+
+// `formatOption()` parses the `--format` option of a subcommand.
+//
+// Parameters:
+//   - `aName`: The subcommand's name.
+//   - `aArgs`: The subcommand's arguments.
+//
+// Returns:
+//   - `string`: The format's name (empty if not given).
+//   - `error`: `nil` if the arguments are valid, the error otherwise.
+func formatOption(aName string, aArgs []string) (string, error) {
+	var format string
+	fs := flag.NewFlagSet(aName, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&format, "format", "", "output format")
+	if err := fs.Parse(aArgs); nil != err {
+		return "", fmt.Errorf("%s: %w", aName, err)
+	}
+
+	return format, nil
+} // formatOption()
+
+// `jsonOption()` removes the `--json` option from a subcommand's
+// command line.
+//
+// Parameters:
+//   - `aArgs`: The subcommand and its arguments.
+//
+// Returns:
+//   - `rArgs`: The arguments without the option.
+//   - `rJSON`: Whether the option was given.
+func jsonOption(aArgs []string) (rArgs []string, rJSON bool) {
+	for _, arg := range aArgs {
+		switch arg {
+		case "--json", "-json", "--json=true", "-json=true":
+			rJSON = true
+		case "--json=false", "-json=false":
+			rJSON = false
+		default:
+			rArgs = append(rArgs, arg)
+		}
+	}
+
+	return
+} // jsonOption()
+
+// `printCommands()` writes the list of subcommands.
+//
+// Parameters:
+//   - `aOut`: The writer to write the list to.
+func printCommands(aOut io.Writer) {
+	fmt.Fprintln(aOut, "\n\tCommands:")
+	tw := tabwriter.NewWriter(aOut, 0, 4, 2, ' ', 0)
+	for _, cmd := range gCommands {
+		fmt.Fprintf(tw, "\t  %s %s\t%s\n", cmd.name, cmd.args, cmd.help)
+	}
+	_ = tw.Flush()
+	fmt.Fprintln(aOut, "\n\tAll commands accept `--json` for machine-readable output.")
+} // printCommands()
+
+// ---------------------------------------------------------------------------
+// `tOutput` methods:
+
+// `result()` writes a subcommand's result.
+//
+// Parameters:
+//   - `aData`: The result to write as JSON.
+//   - `aText`: The function writing the result as text.
+//
+// Returns:
+//   - `error`: `nil` if the result was written, the error otherwise.
+func (o tOutput) result(aData any, aText func()) error {
+	if !o.json {
+		aText()
+		return nil
+	}
+	enc := json.NewEncoder(o.Writer)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(aData)
+} // result()
+
+// ---------------------------------------------------------------------------
+// Subcommands:
+
+// `cmdBlockAdd()` adds a pattern to the deny list of the running server.
+func cmdBlockAdd(aConfig tConfiguration, aArgs []string, aOut tOutput) error {
+	client, err := newAdminClient(aConfig)
+	if nil != err {
+		return err
+	}
+	if err = client.call(http.MethodPost, "/api/denylist",
+		url.Values{"pattern": {aArgs[0]}}, nil); nil != err {
+		return err
+	}
+
+	return aOut.result(tPatternResult{Action: "added", Pattern: aArgs[0]}, func() {
+		fmt.Fprintf(aOut, "added %s\n", aArgs[0])
+	})
+} // cmdBlockAdd()
+
+// `cmdBlockFind()` lists the allow/deny patterns of the running server
+// containing a text or matching a wildcard, e.g. to find out by which
+// rule a hostname is blocked.
+func cmdBlockFind(aConfig tConfiguration, aArgs []string, aOut tOutput) error {
+	client, err := newAdminClient(aConfig)
+	if nil != err {
+		return err
+	}
+	var found []dnscache.TPatternMatch
+	if err = client.call(http.MethodGet, "/api/lists/find",
+		url.Values{"q": {aArgs[0]}}, &found); nil != err {
+		return err
+	}
+	if nil == found {
+		found = []dnscache.TPatternMatch{}
+	}
+
+	return aOut.result(found, func() {
+		for _, match := range found {
+			list := "deny"
+			if match.Allow {
+				list = "allow"
+			}
+			fmt.Fprintf(aOut, "%s %s\n", list, match.Pattern)
+		}
+	})
+} // cmdBlockFind()
+
+// `cmdBlockList()` lists the deny list of the running server.
+//
+// The `--format` option writes the list in `hosts(5)` or ABP syntax
+// for use by other ad blockers.
+func cmdBlockList(aConfig tConfiguration, aArgs []string, aOut tOutput) error {
+	format, err := formatOption("block list", aArgs)
+	if nil != err {
+		return err
+	}
+	if "" != format {
+		if aOut.json {
+			return errors.New("block list: --format and --json exclude each other")
+		}
+		if _, err = parseListFormat(format); nil != err {
+			return err
+		}
+	}
+
+	client, err := newAdminClient(aConfig)
+	if nil != err {
+		return err
+	}
+	if "" != format {
+		return client.call(http.MethodGet, "/api/denylist",
+			url.Values{"format": {format}}, aOut)
+	}
+	list := []string{}
+	if err = client.call(http.MethodGet, "/api/denylist", nil, &list); nil != err {
+		return err
+	}
+
+	return aOut.result(list, func() {
+		for _, pattern := range list {
+			fmt.Fprintln(aOut, pattern)
+		}
+	})
+} // cmdBlockList()
+
+// `cmdBlockRemove()` removes a pattern from the deny list of the
+// running server.
+func cmdBlockRemove(aConfig tConfiguration, aArgs []string, aOut tOutput) error {
+	client, err := newAdminClient(aConfig)
+	if nil != err {
+		return err
+	}
+	if err = client.call(http.MethodDelete, "/api/denylist",
+		url.Values{"pattern": {aArgs[0]}}, nil); nil != err {
+		return err
+	}
+
+	return aOut.result(tPatternResult{Action: "removed", Pattern: aArgs[0]}, func() {
+		fmt.Fprintf(aOut, "removed %s\n", aArgs[0])
+	})
+} // cmdBlockRemove()
+
+// `cmdCacheDump()` lists the cache entries of the running server.
+//
+// The `--format` option selects one of the dump formats instead of
+// the default `hostname IPs…` lines.
+func cmdCacheDump(aConfig tConfiguration, aArgs []string, aOut tOutput) error {
+	format, err := formatOption("cache dump", aArgs)
+	if nil != err {
+		return err
+	}
+	if "" != format {
+		if aOut.json {
+			return errors.New("cache dump: --format and --json exclude each other")
+		}
+		if _, _, err = parseDumpFormat(format); nil != err {
+			return err
+		}
+	}
+
+	client, err := newAdminClient(aConfig)
+	if nil != err {
+		return err
+	}
+	if "" != format {
+		return client.call(http.MethodGet, "/api/cache",
+			url.Values{"format": {format}}, aOut)
+	}
+	entries := []tCacheEntry{}
+	if err = client.call(http.MethodGet, "/api/cache", nil, &entries); nil != err {
+		return err
+	}
+
+	return aOut.result(entries, func() {
+		for _, entry := range entries {
+			ips := make([]string, len(entry.IPs))
+			for idx, ip := range entry.IPs {
+				ips[idx] = ip.String()
+			}
+			fmt.Fprintf(aOut, "%s %s\n", entry.Hostname, strings.Join(ips, " "))
+		}
+	})
+} // cmdCacheDump()
+
+// `cmdCacheFlush()` empties the cache of the running server.
+//
+// An optional argument restricts the flush to a domain (including its
+// subdomains) or, if it contains wildcards, to the matching hostnames.
+func cmdCacheFlush(aConfig tConfiguration, aArgs []string, aOut tOutput) error {
+	client, err := newAdminClient(aConfig)
+	if nil != err {
+		return err
+	}
+	var (
+		answer struct {
+			Flushed int `json:"flushed"`
+		}
+		values url.Values
+	)
+	if 0 < len(aArgs) {
+		if strings.ContainsAny(aArgs[0], "*?[") {
+			values = url.Values{"pattern": {aArgs[0]}}
+		} else {
+			values = url.Values{"suffix": {aArgs[0]}}
+		}
+	}
+	if err = client.call(http.MethodPost, "/api/cache/flush", values, &answer); nil != err {
+		return err
+	}
+
+	return aOut.result(answer, func() {
+		fmt.Fprintf(aOut, "flushed %d entries\n", answer.Flushed)
+	})
+} // cmdCacheFlush()
+
+// `cmdConfigCheck()` validates the configuration.
+func cmdConfigCheck(aConfig tConfiguration, aArgs []string, aOut tOutput) error {
+	if err := checkConfiguration(aConfig); nil != err {
+		return err
+	}
+
+	return aOut.result(tStatusResult{Status: "valid"}, func() {
+		fmt.Fprintln(aOut, "configuration OK")
+	})
+} // cmdConfigCheck()
+
+// `cmdListsReport()` prints the statistics of the running server's
+// last blocklist load, one URL per line followed by its rejection
+// reasons.
+func cmdListsReport(aConfig tConfiguration, aArgs []string, aOut tOutput) error {
+	client, err := newAdminClient(aConfig)
+	if nil != err {
+		return err
+	}
+	var report dnscache.TLoadReport
+	if err = client.call(http.MethodGet, "/api/lists/report", nil, &report); nil != err {
+		return err
+	}
+
+	return aOut.result(report, func() {
+		for _, source := range report.Sources {
+			if "" != source.Error {
+				fmt.Fprintf(aOut, "%s: %s\n", source.Source, source.Error)
+				continue
+			}
+			fmt.Fprintf(aOut, "%s: %d lines, %d added, %d duplicates, %d rejected (%s)\n",
+				source.Source, source.Lines, source.Added, source.Duplicates,
+				source.Rejected, source.Duration.Round(time.Millisecond))
+			for _, reason := range slices.Sorted(maps.Keys(source.Reasons)) {
+				fmt.Fprintf(aOut, "\t%s: %d\n", reason, source.Reasons[reason])
+			}
+		}
+	})
+} // cmdListsReport()
+
+// `cmdListsUpdate()` makes the running server reload its lists.
+func cmdListsUpdate(aConfig tConfiguration, aArgs []string, aOut tOutput) error {
+	client, err := newAdminClient(aConfig)
+	if nil != err {
+		return err
+	}
+	if err = client.call(http.MethodPost, "/api/lists/update", nil, nil); nil != err {
+		return err
+	}
+
+	return aOut.result(tStatusResult{Status: "updated"}, func() {
+		fmt.Fprintln(aOut, "lists updated")
+	})
+} // cmdListsUpdate()
+
+// `cmdQuery()` resolves a hostname using the running server.
+//
+// With the `--trace` option every step of the resolution is shown.
+func cmdQuery(aConfig tConfiguration, aArgs []string, aOut tOutput) error {
+	var withTrace bool
+	fs := flag.NewFlagSet("query", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.BoolVar(&withTrace, "trace", false, "show the resolution's steps")
+	if err := fs.Parse(aArgs); nil != err {
+		return fmt.Errorf("query: %w", err)
+	}
+	if 1 != fs.NArg() {
+		return errors.New("usage: query [--trace] <name>")
+	}
+
+	client, err := newAdminClient(aConfig)
+	if nil != err {
+		return err
+	}
+	if withTrace {
+		var trace dnscache.TTrace
+		if err = client.call(http.MethodGet, "/api/query",
+			url.Values{"name": {fs.Arg(0)}, "trace": {"1"}}, &trace); nil != err {
+			return err
+		}
+		return aOut.result(trace, func() {
+			for _, step := range trace.Steps {
+				fmt.Fprintf(aOut, "%-8s %-8s %-24s %s\n", step.Step, step.Result,
+					step.Detail, step.Duration.Round(time.Microsecond))
+			}
+			if "" != trace.Error {
+				fmt.Fprintf(aOut, "error: %s\n", trace.Error)
+			}
+			for _, ip := range trace.IPs {
+				fmt.Fprintln(aOut, ip)
+			}
+		})
+	}
+	var answer tQueryAnswer
+	if err = client.call(http.MethodGet, "/api/query",
+		url.Values{"name": {fs.Arg(0)}}, &answer); nil != err {
+		return err
+	}
+
+	return aOut.result(answer, func() {
+		for _, ip := range answer.IPs {
+			fmt.Fprintln(aOut, ip)
+		}
+	})
+} // cmdQuery()
+
+// `cmdStats()` prints the metrics of the running server.
+func cmdStats(aConfig tConfiguration, aArgs []string, aOut tOutput) error {
+	client, err := newAdminClient(aConfig)
+	if nil != err {
+		return err
+	}
+	var state tMetricsState
+	if err = client.call(http.MethodGet, "/api/metrics", nil, &state); nil != err {
+		return err
+	}
+
+	return aOut.result(state, func() {
+		fmt.Fprintf(aOut, "lookups:    %d\n", state.Lookups)
+		fmt.Fprintf(aOut, "hits:       %d (%.1f%%)\n", state.Hits, state.HitRatio*100)
+		fmt.Fprintf(aOut, "misses:     %d\n", state.Misses)
+		fmt.Fprintf(aOut, "retries:    %d\n", state.Retries)
+		fmt.Fprintf(aOut, "errors:     %d\n", state.Errors)
+		fmt.Fprintf(aOut, "blocked:    %d\n", state.Blocked)
+		fmt.Fprintf(aOut, "peak:       %d\n", state.Peak)
+		fmt.Fprintf(aOut, "panics:     %d\n", state.Panics)
+		fmt.Fprintf(aOut, "spoofed:    %d\n", state.Spoofed)
+		fmt.Fprintf(aOut, "limited:    %d\n", state.Limited)
+		fmt.Fprintf(aOut, "cache size: %d\n", state.CacheSize)
+		for _, name := range slices.Sorted(maps.Keys(state.Types)) {
+			fmt.Fprintf(aOut, "\t%s: %d hits, %d misses\n", name,
+				state.Types[name].Hits, state.Types[name].Misses)
+		}
+		for _, category := range state.Categories {
+			switched := "enabled"
+			if !category.Enabled {
+				switched = "disabled"
+			}
+			fmt.Fprintf(aOut, "category %s: %s, %d blocked\n",
+				category.Name, switched, category.Blocked)
+		}
+		for _, upstream := range state.Upstreams {
+			fmt.Fprintf(aOut, "upstream %s: %s, %d failures, %d trips\n",
+				upstream.Server, upstream.State, upstream.Failures, upstream.Trips)
+		}
+		for _, latency := range state.Latencies {
+			fmt.Fprintf(aOut, "upstream %s: %v latency, %d samples\n",
+				latency.Server, latency.Latency.Round(time.Microsecond), latency.Samples)
+		}
+	})
+} // cmdStats()
+
+/* _EoF_ */
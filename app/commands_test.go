@@ -0,0 +1,294 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/mwat56/dnscache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_findCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantName string
+		wantArgs []string
+		wantErr  bool
+	}{
+		/* */
+		{
+			name:     "01 - no command",
+			args:     nil,
+			wantName: "serve",
+		},
+		{
+			name:     "02 - serve",
+			args:     []string{"serve"},
+			wantName: "serve",
+			wantArgs: []string{},
+		},
+		{
+			name:     "03 - query",
+			args:     []string{"query", "host.domain.tld"},
+			wantName: "query",
+			wantArgs: []string{"host.domain.tld"},
+		},
+		{
+			name:    "04 - query without name",
+			args:    []string{"query"},
+			wantErr: true,
+		},
+		{
+			name:     "05 - two words",
+			args:     []string{"block", "add", "*.ads.tld"},
+			wantName: "block add",
+			wantArgs: []string{"*.ads.tld"},
+		},
+		{
+			name:    "06 - incomplete command",
+			args:    []string{"block"},
+			wantErr: true,
+		},
+		{
+			name:    "07 - unknown command",
+			args:    []string{"reboot"},
+			wantErr: true,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, args, err := findCommand(tc.args)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("findCommand() error = '%v', wantErr '%v'", err, tc.wantErr)
+				return
+			}
+			if tc.wantErr {
+				return
+			}
+			if got.name != tc.wantName {
+				t.Errorf("findCommand() = '%s', want '%s'", got.name, tc.wantName)
+			}
+			if strings.Join(args, " ") != strings.Join(tc.wantArgs, " ") {
+				t.Errorf("findCommand() args = '%v', want '%v'", args, tc.wantArgs)
+			}
+		})
+	}
+} // Test_findCommand()
+
+func Test_commands(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	_ = resolver.AddRewrite(dnscache.TRewriteRule{
+		Match: "nas.lan", Type: dnscache.RewriteIP, Target: "192.168.1.2"})
+	server := httptest.NewServer(newAdminServer(resolver, tConfiguration{}))
+	defer server.Close()
+	config := tConfiguration{
+		AdminAddress: strings.TrimPrefix(server.URL, "http://"),
+	}
+
+	tests := []struct {
+		name    string
+		config  tConfiguration
+		args    []string
+		want    string
+		wantErr bool
+	}{
+		/* */
+		{
+			name:    "01 - no admin address",
+			config:  tConfiguration{},
+			args:    []string{"block", "list"},
+			wantErr: true,
+		},
+		{
+			name:   "02 - block add",
+			config: config,
+			args:   []string{"block", "add", "*.ads.tld"},
+			want:   "added *.ads.tld\n",
+		},
+		{
+			name:   "03 - block list",
+			config: config,
+			args:   []string{"block", "list"},
+			want:   "*.ads.tld\n",
+		},
+		{
+			name:   "18 - block list as hosts",
+			config: config,
+			args:   []string{"block", "list", "--format=hosts"},
+			want:   "127.0.0.1 *.ads.tld\n",
+		},
+		{
+			name:    "19 - block list invalid format",
+			config:  config,
+			args:    []string{"block", "list", "--format=pihole"},
+			wantErr: true,
+		},
+		{
+			name:   "15 - block find",
+			config: config,
+			args:   []string{"block", "find", "ADS"},
+			want:   "deny *.ads.tld\n",
+		},
+		{
+			name:   "04 - query blocked name",
+			config: config,
+			args:   []string{"query", "banner.ads.tld"},
+			want:   "0.0.0.0\n",
+		},
+		{
+			name:   "05 - block remove",
+			config: config,
+			args:   []string{"block", "remove", "*.ads.tld"},
+			want:   "removed *.ads.tld\n",
+		},
+		{
+			name:    "06 - block remove unknown",
+			config:  config,
+			args:    []string{"block", "remove", "*.ads.tld"},
+			wantErr: true,
+		},
+		{
+			name:   "07 - query rewritten name",
+			config: config,
+			args:   []string{"query", "nas.lan"},
+			want:   "192.168.1.2\n",
+		},
+		{
+			name:    "21 - query trace without name",
+			config:  config,
+			args:    []string{"query", "--trace"},
+			wantErr: true,
+		},
+		{
+			name:   "08 - cache flush",
+			config: config,
+			args:   []string{"cache", "flush"},
+			want:   "flushed 0 entries\n",
+		},
+		{
+			name:   "09 - cache dump",
+			config: config,
+			args:   []string{"cache", "dump"},
+			want:   "",
+		},
+		{
+			name:   "10 - lists update",
+			config: config,
+			args:   []string{"lists", "update"},
+			want:   "lists updated\n",
+		},
+		{
+			name:   "11 - config check",
+			config: config,
+			args:   []string{"config", "check"},
+			want:   "configuration OK\n",
+		},
+		{
+			name:    "12 - invalid config",
+			config:  tConfiguration{Port: -1},
+			args:    []string{"config", "check"},
+			wantErr: true,
+		},
+		{
+			name:   "13 - cache flush domain",
+			config: config,
+			args:   []string{"cache", "flush", "example.com"},
+			want:   "flushed 0 entries\n",
+		},
+		{
+			name:    "14 - cache flush invalid pattern",
+			config:  config,
+			args:    []string{"cache", "flush", "[example.com"},
+			wantErr: true,
+		},
+		{
+			name:   "16 - cache dump as CSV",
+			config: config,
+			args:   []string{"cache", "dump", "--format=csv"},
+			want:   "hostname,ip,ttl,expires\n",
+		},
+		{
+			name:    "17 - cache dump invalid format",
+			config:  config,
+			args:    []string{"cache", "dump", "--format=xml"},
+			wantErr: true,
+		},
+		{
+			name:   "20 - lists report",
+			config: config,
+			args:   []string{"lists", "report"},
+			want:   "",
+		},
+		{
+			name:   "22 - query as JSON",
+			config: config,
+			args:   []string{"query", "nas.lan", "--json"},
+			want:   "{\n  \"name\": \"nas.lan\",\n  \"ips\": [\n    \"192.168.1.2\"\n  ]\n}\n",
+		},
+		{
+			name:   "23 - cache flush as JSON",
+			config: config,
+			args:   []string{"cache", "flush", "--json"},
+			want:   "{\n  \"flushed\": 0\n}\n",
+		},
+		{
+			name:   "24 - cache dump as JSON",
+			config: config,
+			args:   []string{"--json", "cache", "dump"},
+			want:   "[]\n",
+		},
+		{
+			name:    "25 - cache dump format and JSON",
+			config:  config,
+			args:    []string{"cache", "dump", "--format=csv", "--json"},
+			wantErr: true,
+		},
+		{
+			name:   "26 - config check as JSON",
+			config: config,
+			args:   []string{"config", "check", "--json"},
+			want:   "{\n  \"status\": \"valid\"\n}\n",
+		},
+		{
+			name:    "27 - block list format and JSON",
+			config:  config,
+			args:    []string{"block", "list", "--format=hosts", "--json"},
+			wantErr: true,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			command, asJSON := jsonOption(tc.args)
+			cmd, args, err := findCommand(command)
+			if nil != err {
+				t.Fatalf("findCommand() error = '%v'", err)
+			}
+			var out bytes.Buffer
+			err = cmd.run(tc.config, args, tOutput{Writer: &out, json: asJSON})
+			if (nil != err) != tc.wantErr {
+				t.Errorf("%s error = '%v', wantErr '%v'", cmd.name, err, tc.wantErr)
+				return
+			}
+			if got := out.String(); got != tc.want {
+				t.Errorf("%s = '%s', want '%s'", cmd.name, got, tc.want)
+			}
+		})
+	}
+} // Test_commands()
+
+/* _EoF_ */
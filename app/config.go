@@ -7,12 +7,22 @@ Copyright © 2025  M.Watermann, 10247 Berlin, Germany
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"log/slog"
+	"maps"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
 	"slices"
+	"strings"
+	"time"
+
+	"github.com/mwat56/dnscache"
 )
 
 //lint:file-ignore ST1017 - I prefer Yoda conditions
@@ -56,23 +66,190 @@ var (
 type (
 	// `tCmdLineArgs` represents the possible command line arguments.
 	tCmdLineArgs struct {
-		ConfigPathName string // Path to configuration file
-		Address        string // IP address to bind to for DNS requests
-		Port           int    // Port to listen on for DNS requests
-		ConsoleMode    bool   // Run in console UI mode
-		DaemonMode     bool   // Run as a daemon (Linux only)
+		ConfigPathName string   // Path to configuration file
+		Command        []string // Subcommand and its arguments (if any)
+		Address        string   // IP address to bind to for DNS requests
+		Port           int      // Port to listen on for DNS requests
+		ConsoleMode    bool     // Run in console UI mode
+		ContainerMode  bool     // Run in the foreground as a container's process
+		DaemonMode     bool     // Run as a daemon (Linux only)
+		ResetStats     bool     // Start with zero instead of persisted metrics
+	}
+
+	// `tBindConfig` represents the local interface and/or source
+	// address used to query an upstream server
+	tBindConfig struct {
+		Interface string `json:"interface,omitempty"` // e.g. a VPN's "wg0"
+		Address   string `json:"address,omitempty"`   // source IP address
+	}
+
+	// `tCacheSyncConfig` represents the settings used to synchronise
+	// the caches of a cluster's instances
+	tCacheSyncConfig struct {
+		Backend  string   `json:"backend"`            // "gossip" or "redis"
+		Address  string   `json:"address,omitempty"`  // local (gossip) or server (redis) address
+		Peers    []string `json:"peers,omitempty"`    // other instances (gossip)
+		Key      string   `json:"key,omitempty"`      // shared secret authenticating the events
+		Password string   `json:"password,omitempty"` // server password (redis)
+		Channel  string   `json:"channel,omitempty"`  // publish/subscribe channel (redis)
+	}
+
+	// `tBreakerConfig` represents the settings of the upstream
+	// servers' circuit breakers
+	tBreakerConfig struct {
+		Threshold int    `json:"threshold,omitempty"` // consecutive failures
+		Cooldown  string `json:"cooldown,omitempty"`  // time an open breaker skips its server
+		Policy    string `json:"policy,omitempty"`    // "fail", "nxdomain", or "stale"
+	}
+
+	// `tDownloadConfig` represents the settings used to download
+	// blocklists
+	tDownloadConfig struct {
+		Proxy     string `json:"proxy,omitempty"`     // empty: from environment
+		UserAgent string `json:"userAgent,omitempty"` // `User-Agent` header
+		Timeout   string `json:"timeout,omitempty"`   // per download attempt
+		MaxSize   int64  `json:"maxSize,omitempty"`   // in bytes
+		Retries   int    `json:"retries,omitempty"`   // negative: none
+	}
+
+	// `tCategoryConfig` represents a category-tagged deny list (like
+	// malware or phishing domains)
+	tCategoryConfig struct {
+		Name       string   `json:"name"`
+		BlockLists []string `json:"blockLists,omitempty"`
+		Disabled   bool     `json:"disabled,omitempty"` // don't block the category's hosts
+	}
+
+	// `tGroupConfig` represents the allow/deny lists of a client group
+	tGroupConfig struct {
+		BlockLists      []string `json:"blockLists,omitempty"`
+		AllowList       string   `json:"allowList,omitempty"`
+		DefaultDeny     bool     `json:"defaultDeny,omitempty"`     // block all but allowed hosts
+		SafeSearch      bool     `json:"safeSearch,omitempty"`      // force safe search variants
+		BlockSubdomains bool     `json:"blockSubdomains,omitempty"` // block the listed hosts' subdomains
+	}
+
+	// `tRateConfig` represents a per-client query rate limit
+	tRateConfig struct {
+		Rate  float64 `json:"rate,omitempty"`  // queries per second (`0`: no limit)
+		Burst int     `json:"burst,omitempty"` // queries at once (default: rate)
+	}
+
+	// `tRateLimitConfig` represents the rate limits of diagnostic and
+	// ANY queries
+	tRateLimitConfig struct {
+		Diagnostic tRateConfig `json:"diagnostic,omitempty"` // CH class and self-identification queries
+		ANY        tRateConfig `json:"any,omitempty"`        // queries for all records of a name
+		Exempt     []string    `json:"exempt,omitempty"`     // clients besides the loopback ones
+	}
+
+	// `tRecordConfig` represents a resource record of a zone
+	tRecordConfig struct {
+		Name string `json:"name"`          // "@" for the zone itself, "*.dev" for a wildcard
+		Type string `json:"type"`          // A, AAAA, CNAME, MX, NS, PTR, SOA, SRV, or TXT
+		Data string `json:"data"`          // e.g. "10 mail" for MX
+		TTL  uint32 `json:"ttl,omitempty"` // in seconds (default: the zone's)
+	}
+
+	// `tZoneConfig` represents a zone answered authoritatively
+	tZoneConfig struct {
+		Name    string          `json:"name"`
+		File    string          `json:"file,omitempty"` // RFC 1035 master file
+		Records []tRecordConfig `json:"records,omitempty"`
+		TTL     uint32          `json:"ttl,omitempty"` // default TTL in seconds
+	}
+
+	// `tListenerConfig` represents an additional DNS listener
+	tListenerConfig struct {
+		Address    string `json:"address"`
+		Forwarder  string `json:"forwarder,omitempty"`
+		TLSCert    string `json:"tlsCert,omitempty"`
+		TLSKey     string `json:"tlsKey,omitempty"`
+		Unfiltered bool   `json:"unfiltered,omitempty"`
 	}
 
 	// `tConfiguration` represents the DNS cache configuration
 	tConfiguration struct {
-		DNSServers      []string `json:"dnsServers,omitempty"`
-		Address         string   `json:"address,omitempty"`
-		DataDir         string   `json:"dataDir,omitempty"`
-		Forwarder       string   `json:"forwarder,omitempty"`
-		CacheSize       int      `json:"cacheSize,omitempty"`
-		Port            int      `json:"port,omitempty"`
-		RefreshInterval uint8    `json:"refreshInterval,omitempty"`
-		TTL             uint8    `json:"ttl,omitempty"`
+		BlockLists        []string                `json:"blockLists,omitempty"`
+		BlockedCIDRs      []string                `json:"blockedCIDRs,omitempty"`
+		Categories        []tCategoryConfig       `json:"categories,omitempty"`
+		DNSServers        []string                `json:"dnsServers,omitempty"`
+		LeaseFiles        []string                `json:"leaseFiles,omitempty"`
+		Listeners         []tListenerConfig       `json:"listeners,omitempty"`
+		Breaker           *tBreakerConfig         `json:"breaker,omitempty"`
+		RateLimit         *tRateLimitConfig       `json:"rateLimit,omitempty"`
+		Download          *tDownloadConfig        `json:"download,omitempty"`
+		Outbound          *tBindConfig            `json:"outbound,omitempty"`
+		OutboundServers   map[string]tBindConfig  `json:"outboundServers,omitempty"`
+		CacheSync         *tCacheSyncConfig       `json:"cacheSync,omitempty"`
+		LocalZones        []string                `json:"localZones,omitempty"`
+		NeverCache        []string                `json:"neverCache,omitempty"`
+		RebindExempt      []string                `json:"rebindExempt,omitempty"`
+		Rewrites          []dnscache.TRewriteRule `json:"rewrites,omitempty"`
+		SearchDomains     []string                `json:"searchDomains,omitempty"`
+		SelfIdentifyNets  []string                `json:"selfIdentifyNets,omitempty"`
+		ZoneTransfers     []string                `json:"zoneTransfers,omitempty"`
+		Zones             []tZoneConfig           `json:"zones,omitempty"`
+		Address           string                  `json:"address,omitempty"`
+		AdminAddress      string                  `json:"adminAddress,omitempty"`
+		AdminClientCA     string                  `json:"adminClientCA,omitempty"`
+		AdminTLSCert      string                  `json:"adminTLSCert,omitempty"`
+		AdminTLSKey       string                  `json:"adminTLSKey,omitempty"`
+		AdminTokens       []string                `json:"adminTokens,omitempty"`
+		AllowList         string                  `json:"allowList,omitempty"`
+		AnyPolicy         string                  `json:"anyPolicy,omitempty"`
+		BlockPolicy       string                  `json:"blockPolicy,omitempty"`
+		ChaosHostname     string                  `json:"chaosHostname,omitempty"`
+		ChaosVersion      string                  `json:"chaosVersion,omitempty"`
+		ColdCacheDir      string                  `json:"coldCacheDir,omitempty"`
+		DataDir           string                  `json:"dataDir,omitempty"`
+		ECSPolicy         string                  `json:"ecsPolicy,omitempty"`
+		Forwarder         string                  `json:"forwarder,omitempty"`
+		ForwarderProtocol string                  `json:"forwarderProtocol,omitempty"`
+		LeaseDomain       string                  `json:"leaseDomain,omitempty"`
+		LogLevel          string                  `json:"logLevel,omitempty"`
+		LogLevels         map[string]string       `json:"logLevels,omitempty"`
+		MaxTTL            string                  `json:"maxTTL,omitempty"`
+		MetricsFile       string                  `json:"metricsFile,omitempty"`
+		MetricsInterval   string                  `json:"metricsInterval,omitempty"`
+		MinTTL            string                  `json:"minTTL,omitempty"`
+		PiHoleDB          string                  `json:"piholeDB,omitempty"`
+		Precedence        string                  `json:"precedence,omitempty"`
+		UpstreamSelection string                  `json:"upstreamSelection,omitempty"`
+		TTLOverrides      map[string]string       `json:"ttlOverrides,omitempty"`
+		TLDFile           string                  `json:"tldFile,omitempty"`
+		Validation        string                  `json:"validation,omitempty"`
+		Groups            map[string]tGroupConfig `json:"groups,omitempty"`
+		Clients           map[string]string       `json:"clients,omitempty"`
+		PrivacyMode       string                  `json:"privacyMode,omitempty"`
+		QueryTimeout      string                  `json:"queryTimeout,omitempty"`
+		RebindPolicy      string                  `json:"rebindPolicy,omitempty"`
+		RefreshJitter     string                  `json:"refreshJitter,omitempty"`
+		RefreshWindow     string                  `json:"refreshWindow,omitempty"`
+		PrivacySuffixes   []string                `json:"privacySuffixes,omitempty"`
+		CacheSize         int                     `json:"cacheSize,omitempty"`
+		MaxAnswers        int                     `json:"maxAnswers,omitempty"`
+		MaxHotEntries     int                     `json:"maxHotEntries,omitempty"`
+		MaxResponseSize   int                     `json:"maxResponseSize,omitempty"`
+		Port              int                     `json:"port,omitempty"`
+		PrivacyMaskV4     int                     `json:"privacyMaskV4,omitempty"`
+		PrivacyMaskV6     int                     `json:"privacyMaskV6,omitempty"`
+		RefreshWorkers    int                     `json:"refreshWorkers,omitempty"`
+		UDPSockets        int                     `json:"udpSockets,omitempty"`
+		RefreshInterval   uint8                   `json:"refreshInterval,omitempty"`
+		TTL               uint8                   `json:"ttl,omitempty"`
+		AuditMode         bool                    `json:"auditMode,omitempty"`
+		BlockSubdomains   bool                    `json:"blockSubdomains,omitempty"`
+		Dashboard         bool                    `json:"dashboard,omitempty"`
+		LinkLocalOnly     bool                    `json:"linkLocalOnly,omitempty"`
+		MDNSBridge        bool                    `json:"mdnsBridge,omitempty"`
+		MinimalResponses  bool                    `json:"minimalResponses,omitempty"`
+		Offline           bool                    `json:"offline,omitempty"`
+		QueryLog          bool                    `json:"queryLog,omitempty"`
+		SafeSearch        bool                    `json:"safeSearch,omitempty"`
+		SelfIdentify      bool                    `json:"selfIdentify,omitempty"`
+		Sandbox           bool                    `json:"sandbox,omitempty"`
+		DisableTLDCheck   bool                    `json:"disableTLDCheck,omitempty"`
 	}
 )
 
@@ -97,30 +274,40 @@ func parseCmdLineArgs(aArgList []string) (rArgs tCmdLineArgs) {
 		"Run in console UI mode")
 	fs.BoolVar(&rArgs.DaemonMode, "daemon", false,
 		"Run as a daemon (Linux only)")
+	fs.BoolVar(&rArgs.ContainerMode, "container", envContainerMode(),
+		"Run in the foreground without persistent data, logging JSON to StdOut")
 	fs.StringVar(&rArgs.Address, "address", "",
 		"IP address to bind to (empty for all interfaces)")
 	fs.IntVar(&rArgs.Port, "port", 53,
 		"Port to listen on for DNS requests")
+	fs.BoolVar(&rArgs.ResetStats, "reset-stats", false,
+		"Start with zero instead of the persisted metrics")
 
 	fs.Usage = func() {
-		fmt.Fprintf(os.Stderr, "\n\tUsage: %s [OPTIONS]\n\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\n\tUsage: %s [OPTIONS] [COMMAND [ARGS]]\n\n", os.Args[0])
 		fs.PrintDefaults()
+		printCommands(os.Stderr)
 		fmt.Fprintln(os.Stderr, "\n\tMost options can be set in an JSON config file to keep the command-line short ;-)\n\t")
 		//os.Exit(0)
 	}
 	_ = fs.Parse(aArgList) // an error will result in default values being used
+	rArgs.Command = fs.Args()
 
 	// Some sanity checks:
 	if 0 >= rArgs.Port {
 		rArgs.Port = 53
 	}
-	if !rArgs.ConsoleMode {
+	if rArgs.ContainerMode {
+		// The container's runtime expects the process to stay in the foreground
+		rArgs.ConsoleMode, rArgs.DaemonMode = false, false
+	} else if !rArgs.ConsoleMode {
 		rArgs.DaemonMode = true
 	}
-	if rArgs.DaemonMode {
+	if rArgs.DaemonMode || rArgs.ContainerMode {
 		rArgs.ConsoleMode = false
-		// Check for root privileges
-		if (1024 > rArgs.Port) && (0 < os.Getuid()) {
+		// Check for root privileges (if the server is to be started)
+		serve := (0 == len(rArgs.Command)) || ("serve" == rArgs.Command[0])
+		if serve && (1024 > rArgs.Port) && (0 < os.Getuid()) {
 			fmt.Fprintf(os.Stderr, "\nWarning: Port %d requires root privileges!\n", rArgs.Port)
 		}
 	}
@@ -136,6 +323,734 @@ func parseCmdLineArgs(aArgList []string) (rArgs tCmdLineArgs) {
 	return
 } // parseCmdLineArgs()
 
+// `applyLogLevels()` sets the log levels given by the configuration.
+//
+// The default level is taken from the `LogLevel` field while the
+// `LogLevels` field maps component names (e.g. "server" or "resolver")
+// to their respective level. Levels are given by name, i.e. "debug",
+// "info", "warn", or "error" (case-insensitive).
+//
+// Parameters:
+//   - `aConfig`: The configuration to use.
+//
+// Returns:
+//   - `error`: `nil` if all levels were valid, the first error otherwise.
+func applyLogLevels(aConfig tConfiguration) (rErr error) {
+	var level slog.Level
+
+	if "" != aConfig.LogLevel {
+		if err := level.UnmarshalText([]byte(aConfig.LogLevel)); nil != err {
+			rErr = fmt.Errorf("invalid log level %q: %w", aConfig.LogLevel, err)
+		} else {
+			dnscache.SetLogLevel("", level)
+		}
+	}
+
+	for component, name := range aConfig.LogLevels {
+		if err := level.UnmarshalText([]byte(name)); nil != err {
+			if nil == rErr {
+				rErr = fmt.Errorf("invalid log level %q for %q: %w",
+					name, component, err)
+			}
+			continue
+		}
+		dnscache.SetLogLevel(component, level)
+	}
+
+	return
+} // applyLogLevels()
+
+// `applyCategories()` sets up the blocklist categories given by the
+// configuration.
+//
+// The `Categories` field lists the category-tagged deny lists (like
+// "malware", "phishing", or "cryptomining") which are checked for all
+// clients in the given order. A category's `Disabled` field is only
+// applied when the category is created so that switching it by the
+// admin API survives reloading the lists.
+//
+// Parameters:
+//   - `aResolver`: The resolver to configure.
+//   - `aConfig`: The configuration to use.
+//
+// Returns:
+//   - `error`: `nil` if all categories were set up, the joined errors otherwise.
+func applyCategories(aResolver *dnscache.TResolver, aConfig tConfiguration) error {
+	var errs []error
+
+	known := make(map[string]bool)
+	for _, state := range aResolver.Categories() {
+		known[state.Name] = true
+	}
+	for _, category := range aConfig.Categories {
+		name := category.Name
+		if err := aResolver.AddCategory(name); nil != err {
+			errs = append(errs, err)
+			continue
+		}
+		if !known[name] && category.Disabled {
+			if err := aResolver.SetCategoryEnabled(name, false); nil != err {
+				errs = append(errs, fmt.Errorf("category %q: %w", name, err))
+			}
+		}
+		if 0 < len(category.BlockLists) {
+			if err := aResolver.LoadCategoryBlocklists(name, category.BlockLists); nil != err {
+				errs = append(errs, fmt.Errorf("category %q: %w", name, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+} // applyCategories()
+
+// `applyGroups()` sets up the client groups given by the configuration.
+//
+// The `Groups` field maps group names to their allow/deny lists while
+// the `Clients` field maps client addresses (single IPs or CIDRs) to
+// the group they belong to. Clients without a group use the default
+// allow/deny lists. A group's `BlockSubdomains` field makes the
+// hostnames of its blocklists block their subdomains as well, like
+// the global field of that name does for all groups.
+//
+// Parameters:
+//   - `aResolver`: The resolver to configure.
+//   - `aConfig`: The configuration to use.
+//
+// Returns:
+//   - `error`: `nil` if all groups were set up, the joined errors otherwise.
+func applyGroups(aResolver *dnscache.TResolver, aConfig tConfiguration) error {
+	var errs []error
+
+	for name, group := range aConfig.Groups {
+		if err := aResolver.AddGroup(name); nil != err {
+			errs = append(errs, err)
+			continue
+		}
+		if "" != group.AllowList {
+			if err := aResolver.LoadGroupAllowlist(name, group.AllowList); nil != err {
+				errs = append(errs, fmt.Errorf("group %q: %w", name, err))
+			}
+		}
+		if group.BlockSubdomains {
+			// Has to be set before the lists are loaded
+			if err := aResolver.SetGroupBlockSubdomains(name, true); nil != err {
+				errs = append(errs, fmt.Errorf("group %q: %w", name, err))
+			}
+		}
+		if 0 < len(group.BlockLists) {
+			if err := aResolver.LoadGroupBlocklists(name, group.BlockLists); nil != err {
+				errs = append(errs, fmt.Errorf("group %q: %w", name, err))
+			}
+		}
+		if group.DefaultDeny {
+			if err := aResolver.SetGroupDefaultDeny(name, true); nil != err {
+				errs = append(errs, fmt.Errorf("group %q: %w", name, err))
+			}
+		}
+		if group.SafeSearch {
+			if err := aResolver.SetGroupSafeSearch(name, true); nil != err {
+				errs = append(errs, fmt.Errorf("group %q: %w", name, err))
+			}
+		}
+	}
+
+	for client, name := range aConfig.Clients {
+		if err := aResolver.AssignClient(client, name); nil != err {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+} // applyGroups()
+
+// `applyLeases()` starts watching the DHCP lease files given by the
+// configuration.
+//
+// The `LeaseFiles` field lists dnsmasq or ISC DHCP lease files whose
+// hostnames get resolved (and reverse resolved) by the resolver, the
+// `LeaseDomain` field the domain to qualify these hostnames with.
+//
+// Parameters:
+//   - `aCtx`: The context to stop watching the files.
+//   - `aResolver`: The resolver to configure.
+//   - `aConfig`: The configuration to use.
+//
+// Returns:
+//   - `error`: `nil` if all files were loaded, the joined errors otherwise.
+func applyLeases(aCtx context.Context, aResolver *dnscache.TResolver, aConfig tConfiguration) error {
+	var errs []error
+
+	for _, fName := range aConfig.LeaseFiles {
+		if err := aResolver.WatchLeaseFile(aCtx, fName, aConfig.LeaseDomain); nil != err {
+			errs = append(errs, fmt.Errorf("lease file: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+} // applyLeases()
+
+// `applyLists()` loads the default allow/deny lists given by the
+// configuration.
+//
+// The `AllowList` field names a local file with 'allow' patterns while
+// the `BlockLists` field lists the URLs to download 'deny' patterns from.
+//
+// Parameters:
+//   - `aResolver`: The resolver to configure.
+//   - `aConfig`: The configuration to use.
+//
+// Returns:
+//   - `error`: `nil` if all lists were loaded, the joined errors otherwise.
+func applyLists(aResolver *dnscache.TResolver, aConfig tConfiguration) error {
+	var errs []error
+
+	if "" != aConfig.AllowList {
+		if err := aResolver.LoadAllowlist(aConfig.AllowList); nil != err {
+			errs = append(errs, fmt.Errorf("allowlist: %w", err))
+		}
+	}
+	if 0 < len(aConfig.BlockLists) {
+		if err := aResolver.LoadBlocklists(aConfig.BlockLists); nil != err {
+			errs = append(errs, fmt.Errorf("blocklists: %w", err))
+		}
+	}
+
+	return errors.Join(errs...)
+} // applyLists()
+
+// `anyPolicy()` returns the handling of queries for all records of
+// a name (QTYPE=ANY).
+//
+// Parameters:
+//   - `aPolicy`: The policy's name ("hinfo", "subset", or "forward").
+//
+// Returns:
+//   - `tAnyPolicy`: The policy to apply.
+//   - `error`: `nil` if the name is valid, the error otherwise.
+func anyPolicy(aPolicy string) (tAnyPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(aPolicy)) {
+	case "", "hinfo":
+		return anyHINFO, nil
+	case "subset":
+		return anySubset, nil
+	case "forward":
+		return anyForward, nil
+	}
+
+	return anyHINFO, fmt.Errorf("invalid ANY policy: %q", aPolicy)
+} // anyPolicy()
+
+// `blockPolicy()` returns the resolver's block policy for answers
+// containing blocked IP addresses.
+//
+// Parameters:
+//   - `aPolicy`: The policy's name ("strip", "null", or "nxdomain").
+//
+// Returns:
+//   - `dnscache.TBlockPolicy`: The block policy to use.
+//   - `error`: `nil` if the name is valid, the error otherwise.
+func blockPolicy(aPolicy string) (dnscache.TBlockPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(aPolicy)) {
+	case "", "strip":
+		return dnscache.BlockPolicyStrip, nil
+	case "null", "nullip":
+		return dnscache.BlockPolicyNullIP, nil
+	case "nxdomain":
+		return dnscache.BlockPolicyNXDomain, nil
+	}
+
+	return dnscache.BlockPolicyStrip, fmt.Errorf("invalid block policy: %q", aPolicy)
+} // blockPolicy()
+
+// `forwardProtocol()` returns the protocol to query the forwarder with.
+//
+// Parameters:
+//   - `aProtocol`: The protocol's name ("auto", "udp", or "tcp").
+//
+// Returns:
+//   - `tForwardProtocol`: The protocol to use.
+//   - `error`: `nil` if the name is valid, the error otherwise.
+func forwardProtocol(aProtocol string) (tForwardProtocol, error) {
+	switch strings.ToLower(strings.TrimSpace(aProtocol)) {
+	case "", "auto":
+		return forwardAuto, nil
+	case "udp":
+		return forwardUDP, nil
+	case "tcp":
+		return forwardTCP, nil
+	}
+
+	return forwardAuto, fmt.Errorf("invalid forwarder protocol: %q", aProtocol)
+} // forwardProtocol()
+
+// `ecsPolicy()` returns the handling of the EDNS Client Subnet option
+// of forwarded queries.
+//
+// Parameters:
+//   - `aPolicy`: The policy's name ("forward", "strip", or "synthesize").
+//
+// Returns:
+//   - `tECSPolicy`: The policy to apply.
+//   - `error`: `nil` if the name is valid, the error otherwise.
+func ecsPolicy(aPolicy string) (tECSPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(aPolicy)) {
+	case "", "forward":
+		return ecsForward, nil
+	case "strip":
+		return ecsStrip, nil
+	case "synthesize":
+		return ecsSynthesize, nil
+	}
+
+	return ecsForward, fmt.Errorf("invalid ECS policy: %q", aPolicy)
+} // ecsPolicy()
+
+// `configDuration()` parses a configured duration (e.g. `90s` or `1h`).
+//
+// Parameters:
+//   - `aName`: The setting's name (used in the error message).
+//   - `aValue`: The duration to parse (empty means `0`).
+//
+// Returns:
+//   - `time.Duration`: The parsed duration.
+//   - `error`: `nil` if the duration is valid and not negative, the error otherwise.
+func configDuration(aName, aValue string) (time.Duration, error) {
+	if "" == aValue {
+		return 0, nil
+	}
+	result, err := time.ParseDuration(aValue)
+	if (nil != err) || (0 > result) {
+		return 0, fmt.Errorf("invalid %s: %q", aName, aValue)
+	}
+
+	return result, nil
+} // configDuration()
+
+// `breakerOptions()` returns the configured settings of the upstream
+// servers' circuit breakers.
+//
+// Parameters:
+//   - `aConfig`: The configuration to use.
+//
+// Returns:
+//   - `*dnscache.TBreakerOptions`: The settings (`nil` means disabled).
+//   - `error`: `nil` if the settings are valid, the joined errors otherwise.
+func breakerOptions(aConfig tConfiguration) (*dnscache.TBreakerOptions, error) {
+	if nil == aConfig.Breaker {
+		return nil, nil
+	}
+	var errs []error
+
+	cooldown, err := configDuration("breaker cooldown", aConfig.Breaker.Cooldown)
+	if nil != err {
+		errs = append(errs, err)
+	}
+	if 0 > aConfig.Breaker.Threshold {
+		errs = append(errs, fmt.Errorf("invalid breaker threshold: %d", aConfig.Breaker.Threshold))
+	}
+	policy, err := breakerPolicy(aConfig.Breaker.Policy)
+	if nil != err {
+		errs = append(errs, err)
+	}
+	if 0 < len(errs) {
+		return nil, errors.Join(errs...)
+	}
+
+	return &dnscache.TBreakerOptions{
+		Threshold: aConfig.Breaker.Threshold,
+		Cooldown:  cooldown,
+		Policy:    policy,
+	}, nil
+} // breakerOptions()
+
+// `breakerPolicy()` returns the answer to queries while all upstream
+// servers' circuit breakers are open.
+//
+// Parameters:
+//   - `aPolicy`: The policy's name ("fail", "nxdomain", or "stale").
+//
+// Returns:
+//   - `dnscache.TBreakerPolicy`: The parsed policy.
+//   - `error`: `nil` if the policy is valid, the error otherwise.
+func breakerPolicy(aPolicy string) (dnscache.TBreakerPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(aPolicy)) {
+	case "", "fail", "servfail":
+		return dnscache.BreakerPolicyFail, nil
+	case "nxdomain":
+		return dnscache.BreakerPolicyNXDomain, nil
+	case "stale":
+		return dnscache.BreakerPolicyStale, nil
+	}
+
+	return dnscache.BreakerPolicyFail, fmt.Errorf("invalid breaker policy: %q", aPolicy)
+} // breakerPolicy()
+
+// `downloadOptions()` returns the configured settings used to download
+// blocklists.
+//
+// Parameters:
+//   - `aConfig`: The configuration to use.
+//
+// Returns:
+//   - `*dnscache.TDownloadOptions`: The settings (`nil` means the defaults).
+//   - `error`: `nil` if the settings are valid, the joined errors otherwise.
+func downloadOptions(aConfig tConfiguration) (*dnscache.TDownloadOptions, error) {
+	if nil == aConfig.Download {
+		return nil, nil
+	}
+	var errs []error
+
+	timeout, err := configDuration("download timeout", aConfig.Download.Timeout)
+	if nil != err {
+		errs = append(errs, err)
+	}
+	if 0 > aConfig.Download.MaxSize {
+		errs = append(errs, fmt.Errorf("invalid download maxSize: %d", aConfig.Download.MaxSize))
+	}
+	if "" != aConfig.Download.Proxy {
+		if proxy, err := url.Parse(aConfig.Download.Proxy); (nil != err) || ("" == proxy.Host) {
+			errs = append(errs, fmt.Errorf("invalid download proxy: %q", aConfig.Download.Proxy))
+		}
+	}
+	if 0 < len(errs) {
+		return nil, errors.Join(errs...)
+	}
+
+	return &dnscache.TDownloadOptions{
+		Proxy:     aConfig.Download.Proxy,
+		UserAgent: aConfig.Download.UserAgent,
+		Timeout:   timeout,
+		MaxSize:   aConfig.Download.MaxSize,
+		Retries:   aConfig.Download.Retries,
+	}, nil
+} // downloadOptions()
+
+// `rebindPolicy()` returns the resolver's rebind policy for answers
+// containing private IP addresses.
+//
+// Parameters:
+//   - `aPolicy`: The policy's name ("off", "strip", or "block").
+//
+// Returns:
+//   - `dnscache.TRebindPolicy`: The rebind policy to use.
+//   - `error`: `nil` if the name is valid, the error otherwise.
+func rebindPolicy(aPolicy string) (dnscache.TRebindPolicy, error) {
+	switch strings.ToLower(strings.TrimSpace(aPolicy)) {
+	case "", "off":
+		return dnscache.RebindPolicyOff, nil
+	case "strip":
+		return dnscache.RebindPolicyStrip, nil
+	case "block", "nxdomain":
+		return dnscache.RebindPolicyBlock, nil
+	}
+
+	return dnscache.RebindPolicyOff, fmt.Errorf("invalid rebind policy: %q", aPolicy)
+} // rebindPolicy()
+
+// `refreshOptions()` parses the configured refresh jitter and window.
+//
+// Parameters:
+//   - `aConfig`: The configuration to use.
+//
+// Returns:
+//   - `rJitter`: The maximal random delay before each refresh lookup.
+//   - `rWindow`: Refresh only entries queried within this time (`0` means all).
+//   - `rErr`: `nil` if both durations are valid, the joined errors otherwise.
+func refreshOptions(aConfig tConfiguration) (rJitter, rWindow time.Duration, rErr error) {
+	var err1, err2 error
+	rJitter, err1 = configDuration("refreshJitter", aConfig.RefreshJitter)
+	rWindow, err2 = configDuration("refreshWindow", aConfig.RefreshWindow)
+	if 0 > aConfig.RefreshWorkers {
+		rErr = fmt.Errorf("invalid refreshWorkers: %d", aConfig.RefreshWorkers)
+	}
+	rErr = errors.Join(err1, err2, rErr)
+
+	return
+} // refreshOptions()
+
+// `tldOptions()` returns the settings of the top-level domains used
+// to validate the blocklists' hostnames.
+//
+// The local copy of the IANA list is kept in the data directory
+// unless another file is configured.
+//
+// Parameters:
+//   - `aConfig`: The configuration to use.
+//
+// Returns:
+//   - `dnscache.TTLDOptions`: The settings to use.
+func tldOptions(aConfig tConfiguration) dnscache.TTLDOptions {
+	result := dnscache.TTLDOptions{
+		CacheFile: aConfig.TLDFile,
+		Disabled:  aConfig.DisableTLDCheck,
+		Offline:   aConfig.Offline,
+	}
+	if ("" == result.CacheFile) && ("" != aConfig.DataDir) {
+		result.CacheFile = filepath.Join(aConfig.DataDir, "tlds-alpha-by-domain.txt")
+	}
+
+	return result
+} // tldOptions()
+
+// `ttlOptions()` parses the configured TTL bounds and overrides.
+//
+// Parameters:
+//   - `aConfig`: The configuration to use.
+//
+// Returns:
+//   - `rMin`: The minimal TTL of cache entries (`0` means no lower bound).
+//   - `rMax`: The maximal TTL of cache entries (`0` means no upper bound).
+//   - `rOverrides`: The fixed TTLs by domain.
+//   - `rErr`: `nil` if all durations are valid, the joined errors otherwise.
+func ttlOptions(aConfig tConfiguration) (rMin, rMax time.Duration, rOverrides map[string]time.Duration, rErr error) {
+	var errs []error
+
+	parse := func(aName, aValue string) time.Duration {
+		result, err := configDuration(aName, aValue)
+		if nil != err {
+			errs = append(errs, err)
+		}
+		return result
+	} // parse()
+
+	rMin = parse("minTTL", aConfig.MinTTL)
+	rMax = parse("maxTTL", aConfig.MaxTTL)
+	if (0 < rMin) && (0 < rMax) && (rMin > rMax) {
+		errs = append(errs, fmt.Errorf("minTTL %q exceeds maxTTL %q",
+			aConfig.MinTTL, aConfig.MaxTTL))
+	}
+	for domain, value := range aConfig.TTLOverrides {
+		ttl := parse("TTL override for "+domain, value)
+		if 0 >= ttl {
+			continue
+		}
+		if nil == rOverrides {
+			rOverrides = make(map[string]time.Duration, len(aConfig.TTLOverrides))
+		}
+		rOverrides[domain] = ttl
+	}
+	rErr = errors.Join(errs...)
+
+	return
+} // ttlOptions()
+
+// `precedence()` returns the rule deciding about hostnames matched
+// by both the allow and the deny list.
+//
+// Parameters:
+//   - `aRule`: The rule's name (`allow` or `specific`, empty meaning `allow`).
+//
+// Returns:
+//   - `dnscache.TPrecedence`: The rule to use.
+//   - `error`: `nil` if the name is valid, the error otherwise.
+func precedence(aRule string) (dnscache.TPrecedence, error) {
+	switch strings.ToLower(strings.TrimSpace(aRule)) {
+	case "", "allow":
+		return dnscache.PrecedenceAllow, nil
+	case "specific":
+		return dnscache.PrecedenceSpecific, nil
+	}
+
+	return dnscache.PrecedenceAllow, fmt.Errorf("invalid precedence: %q", aRule)
+} // precedence()
+
+// `upstreamSelection()` returns which of the configured DNS servers
+// are queried for a hostname.
+//
+// Parameters:
+//   - `aStrategy`: The strategy's name ("parallel" or "fastest").
+//
+// Returns:
+//   - `dnscache.TSelection`: The strategy to use.
+//   - `error`: `nil` if the name is valid, the error otherwise.
+func upstreamSelection(aStrategy string) (dnscache.TSelection, error) {
+	switch strings.ToLower(strings.TrimSpace(aStrategy)) {
+	case "", "parallel":
+		return dnscache.SelectParallel, nil
+	case "fastest":
+		return dnscache.SelectFastest, nil
+	}
+
+	return dnscache.SelectParallel, fmt.Errorf("invalid upstream selection: %q", aStrategy)
+} // upstreamSelection()
+
+// `validation()` returns the strictness of the blocklists' hostname
+// checks.
+//
+// Parameters:
+//   - `aLevel`: The strictness' name ("strict", "relaxed", or "off").
+//
+// Returns:
+//   - `dnscache.TValidation`: The strictness to use.
+//   - `error`: `nil` if the name is valid, the error otherwise.
+func validation(aLevel string) (dnscache.TValidation, error) {
+	switch strings.ToLower(strings.TrimSpace(aLevel)) {
+	case "", "strict":
+		return dnscache.ValidateStrict, nil
+	case "relaxed":
+		return dnscache.ValidateRelaxed, nil
+	case "off":
+		return dnscache.ValidateOff, nil
+	}
+
+	return dnscache.ValidateStrict, fmt.Errorf("invalid validation: %q", aLevel)
+} // validation()
+
+// `checkConfiguration()` validates the given configuration.
+//
+// All problems found are reported, not just the first one.
+//
+// Parameters:
+//   - `aConfig`: The configuration to check.
+//
+// Returns:
+//   - `error`: `nil` if the configuration is valid, the joined errors otherwise.
+func checkConfiguration(aConfig tConfiguration) error {
+	var (
+		errs  []error
+		level slog.Level
+	)
+
+	if (0 > aConfig.Port) || (65535 < aConfig.Port) {
+		errs = append(errs, fmt.Errorf("invalid port: %d", aConfig.Port))
+	}
+	if -1 > aConfig.UDPSockets {
+		errs = append(errs, fmt.Errorf("invalid number of UDP sockets: %d", aConfig.UDPSockets))
+	}
+	if 0 > aConfig.MaxHotEntries {
+		errs = append(errs, fmt.Errorf("invalid number of hot cache entries: %d", aConfig.MaxHotEntries))
+	}
+	if (0 > aConfig.MaxAnswers) || (0xFFFF < aConfig.MaxAnswers) {
+		errs = append(errs, fmt.Errorf("invalid max. number of answers: %d", aConfig.MaxAnswers))
+	}
+	if (0 != aConfig.MaxResponseSize) &&
+		((512 > aConfig.MaxResponseSize) || (0xFFFF < aConfig.MaxResponseSize)) {
+		errs = append(errs, fmt.Errorf("invalid max. response size: %d", aConfig.MaxResponseSize))
+	}
+	if err := checkListenHost(aConfig.Address); nil != err {
+		errs = append(errs, err)
+	}
+	for name, address := range map[string]string{
+		"adminAddress": aConfig.AdminAddress,
+		"forwarder":    aConfig.Forwarder,
+	} {
+		if "" == address {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(address); nil != err {
+			errs = append(errs, fmt.Errorf("invalid %s %q: %w", name, address, err))
+		}
+	}
+	for _, server := range aConfig.DNSServers {
+		if nil == net.ParseIP(server) {
+			errs = append(errs, fmt.Errorf("invalid DNS server: %q", server))
+		}
+	}
+	if err := checkListeners(aConfig); nil != err {
+		errs = append(errs, err)
+	}
+	for _, cidr := range aConfig.BlockedCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); (nil != err) && (nil == net.ParseIP(cidr)) {
+			errs = append(errs, fmt.Errorf("invalid blocked CIDR: %q", cidr))
+		}
+	}
+	for _, rule := range aConfig.Rewrites {
+		if err := rule.Validate(); nil != err {
+			errs = append(errs, err)
+		}
+	}
+	if _, err := adminTLSConfig(aConfig); nil != err {
+		errs = append(errs, err)
+	}
+	if _, err := newZoneTransfer(aConfig); nil != err {
+		errs = append(errs, err)
+	}
+	if _, err := newAuthZones(aConfig); nil != err {
+		errs = append(errs, err)
+	}
+	if _, err := newSelfID(aConfig); nil != err {
+		errs = append(errs, err)
+	}
+	if _, err := newRateLimits(aConfig, nil); nil != err {
+		errs = append(errs, err)
+	}
+	if _, err := blockPolicy(aConfig.BlockPolicy); nil != err {
+		errs = append(errs, err)
+	}
+	if _, err := rebindPolicy(aConfig.RebindPolicy); nil != err {
+		errs = append(errs, err)
+	}
+	if _, err := validation(aConfig.Validation); nil != err {
+		errs = append(errs, err)
+	}
+	if _, err := precedence(aConfig.Precedence); nil != err {
+		errs = append(errs, err)
+	}
+	if _, err := upstreamSelection(aConfig.UpstreamSelection); nil != err {
+		errs = append(errs, err)
+	}
+	if _, err := configDuration("queryTimeout", aConfig.QueryTimeout); nil != err {
+		errs = append(errs, err)
+	}
+	if _, err := forwardProtocol(aConfig.ForwarderProtocol); nil != err {
+		errs = append(errs, err)
+	}
+	if _, err := newOutbound(aConfig); nil != err {
+		errs = append(errs, err)
+	}
+	if _, err := anyPolicy(aConfig.AnyPolicy); nil != err {
+		errs = append(errs, err)
+	}
+	if _, err := ecsPolicy(aConfig.ECSPolicy); nil != err {
+		errs = append(errs, err)
+	}
+	if "" != aConfig.LogLevel {
+		if err := level.UnmarshalText([]byte(aConfig.LogLevel)); nil != err {
+			errs = append(errs, fmt.Errorf("invalid log level %q", aConfig.LogLevel))
+		}
+	}
+	for component, name := range aConfig.LogLevels {
+		if err := level.UnmarshalText([]byte(name)); nil != err {
+			errs = append(errs, fmt.Errorf("invalid log level %q for %q", name, component))
+		}
+	}
+	if _, err := newPrivacy(aConfig); nil != err {
+		errs = append(errs, err)
+	}
+	if _, _, err := refreshOptions(aConfig); nil != err {
+		errs = append(errs, err)
+	}
+	if err := checkCacheSync(aConfig); nil != err {
+		errs = append(errs, err)
+	}
+	if _, err := downloadOptions(aConfig); nil != err {
+		errs = append(errs, err)
+	}
+	if _, err := breakerOptions(aConfig); nil != err {
+		errs = append(errs, err)
+	}
+	if _, _, _, err := ttlOptions(aConfig); nil != err {
+		errs = append(errs, err)
+	}
+	if _, _, err := metricsOptions(aConfig); nil != err {
+		errs = append(errs, err)
+	}
+	categories := make(map[string]bool, len(aConfig.Categories))
+	for _, category := range aConfig.Categories {
+		if categories[category.Name] {
+			errs = append(errs, fmt.Errorf("duplicate category: %q", category.Name))
+		}
+		categories[category.Name] = true
+	}
+	for client, group := range aConfig.Clients {
+		if _, ok := aConfig.Groups[group]; !ok {
+			errs = append(errs, fmt.Errorf("client %q: unknown group %q", client, group))
+		}
+	}
+
+	return errors.Join(errs...)
+} // checkConfiguration()
+
 // `loadConfiguration()` reads the configuration from a file.
 //
 // Parameters:
@@ -189,6 +1104,37 @@ func saveConfiguration(aConfig tConfiguration, aFilename string) error {
 	return os.Rename(tmpName, aFilename)
 } // saveConfiguration()
 
+// ---------------------------------------------------------------------------
+// `tCacheSyncConfig` methods:
+
+// `Equal()` checks whether the settings are equal to the given ones.
+//
+// Parameters:
+//   - `aConfig`: The settings to compare with.
+//
+// Returns:
+//   - `bool`: `true` if the settings are equal to the given ones, `false` otherwise.
+func (cs *tCacheSyncConfig) Equal(aConfig *tCacheSyncConfig) bool {
+	return (cs.Backend == aConfig.Backend) && (cs.Address == aConfig.Address) &&
+		slices.Equal(cs.Peers, aConfig.Peers) && (cs.Key == aConfig.Key) &&
+		(cs.Password == aConfig.Password) && (cs.Channel == aConfig.Channel)
+} // Equal()
+
+// ---------------------------------------------------------------------------
+// `tRateLimitConfig` methods:
+
+// `Equal()` checks whether the settings are equal to the given ones.
+//
+// Parameters:
+//   - `aConfig`: The settings to compare with.
+//
+// Returns:
+//   - `bool`: `true` if the settings are equal to the given ones, `false` otherwise.
+func (rl *tRateLimitConfig) Equal(aConfig *tRateLimitConfig) bool {
+	return (rl.Diagnostic == aConfig.Diagnostic) && (rl.ANY == aConfig.ANY) &&
+		slices.Equal(rl.Exempt, aConfig.Exempt)
+} // Equal()
+
 // ---------------------------------------------------------------------------
 // `tCmdLineArgs` methods:
 
@@ -214,6 +1160,15 @@ func (c *tCmdLineArgs) Equal(aCmdLine *tCmdLineArgs) (rOK bool) {
 	if c.DaemonMode != aCmdLine.DaemonMode {
 		return
 	}
+	if c.ContainerMode != aCmdLine.ContainerMode {
+		return
+	}
+	if c.ResetStats != aCmdLine.ResetStats {
+		return
+	}
+	if !slices.Equal(c.Command, aCmdLine.Command) {
+		return
+	}
 	rOK = true
 
 	return
@@ -236,17 +1191,164 @@ func (c *tConfiguration) Equal(aConfig *tConfiguration) bool {
 	if nil == aConfig {
 		return false
 	}
+	if !slices.Equal(c.AdminTokens, aConfig.AdminTokens) {
+		return false
+	}
+	if !slices.Equal(c.BlockLists, aConfig.BlockLists) {
+		return false
+	}
+	if !slices.Equal(c.BlockedCIDRs, aConfig.BlockedCIDRs) {
+		return false
+	}
 	if !slices.Equal(c.DNSServers, aConfig.DNSServers) {
 		return false
 	}
+	if !slices.Equal(c.LeaseFiles, aConfig.LeaseFiles) {
+		return false
+	}
+	if !slices.Equal(c.Listeners, aConfig.Listeners) {
+		return false
+	}
+	if (nil == c.Breaker) != (nil == aConfig.Breaker) {
+		return false
+	}
+	if (nil != c.Breaker) && (*c.Breaker != *aConfig.Breaker) {
+		return false
+	}
+	if (nil == c.RateLimit) != (nil == aConfig.RateLimit) {
+		return false
+	}
+	if (nil != c.RateLimit) && !c.RateLimit.Equal(aConfig.RateLimit) {
+		return false
+	}
+	if (nil == c.Download) != (nil == aConfig.Download) {
+		return false
+	}
+	if (nil != c.Download) && (*c.Download != *aConfig.Download) {
+		return false
+	}
+	if (nil == c.CacheSync) != (nil == aConfig.CacheSync) {
+		return false
+	}
+	if (nil != c.CacheSync) && !c.CacheSync.Equal(aConfig.CacheSync) {
+		return false
+	}
+	if !slices.Equal(c.LocalZones, aConfig.LocalZones) {
+		return false
+	}
+	if !slices.Equal(c.RebindExempt, aConfig.RebindExempt) {
+		return false
+	}
+	if !slices.Equal(c.SearchDomains, aConfig.SearchDomains) {
+		return false
+	}
+	if !slices.Equal(c.NeverCache, aConfig.NeverCache) {
+		return false
+	}
+	if !slices.Equal(c.Rewrites, aConfig.Rewrites) {
+		return false
+	}
+	if !slices.Equal(c.SelfIdentifyNets, aConfig.SelfIdentifyNets) {
+		return false
+	}
+	if !slices.Equal(c.ZoneTransfers, aConfig.ZoneTransfers) {
+		return false
+	}
+	if !slices.EqualFunc(c.Zones, aConfig.Zones, func(a, b tZoneConfig) bool {
+		return (a.Name == b.Name) && (a.File == b.File) && (a.TTL == b.TTL) &&
+			slices.Equal(a.Records, b.Records)
+	}) {
+		return false
+	}
+	if !maps.Equal(c.LogLevels, aConfig.LogLevels) {
+		return false
+	}
+	if !maps.EqualFunc(c.Groups, aConfig.Groups, func(a, b tGroupConfig) bool {
+		return (a.AllowList == b.AllowList) && (a.DefaultDeny == b.DefaultDeny) &&
+			(a.SafeSearch == b.SafeSearch) && (a.BlockSubdomains == b.BlockSubdomains) &&
+			slices.Equal(a.BlockLists, b.BlockLists)
+	}) {
+		return false
+	}
+	if !slices.EqualFunc(c.Categories, aConfig.Categories, func(a, b tCategoryConfig) bool {
+		return (a.Name == b.Name) && (a.Disabled == b.Disabled) &&
+			slices.Equal(a.BlockLists, b.BlockLists)
+	}) {
+		return false
+	}
+	if !maps.Equal(c.Clients, aConfig.Clients) {
+		return false
+	}
+	if (nil == c.Outbound) != (nil == aConfig.Outbound) {
+		return false
+	}
+	if (nil != c.Outbound) && (*c.Outbound != *aConfig.Outbound) {
+		return false
+	}
+	if !maps.Equal(c.OutboundServers, aConfig.OutboundServers) {
+		return false
+	}
+	if !maps.Equal(c.TTLOverrides, aConfig.TTLOverrides) {
+		return false
+	}
+	if !slices.Equal(c.PrivacySuffixes, aConfig.PrivacySuffixes) {
+		return false
+	}
 
 	return (c.Address == aConfig.Address) &&
+		(c.AdminAddress == aConfig.AdminAddress) &&
+		(c.AdminClientCA == aConfig.AdminClientCA) &&
+		(c.AdminTLSCert == aConfig.AdminTLSCert) &&
+		(c.AdminTLSKey == aConfig.AdminTLSKey) &&
+		(c.AllowList == aConfig.AllowList) &&
+		(c.AnyPolicy == aConfig.AnyPolicy) &&
+		(c.BlockPolicy == aConfig.BlockPolicy) &&
+		(c.ChaosHostname == aConfig.ChaosHostname) &&
+		(c.ChaosVersion == aConfig.ChaosVersion) &&
+		(c.ColdCacheDir == aConfig.ColdCacheDir) &&
 		(c.DataDir == aConfig.DataDir) &&
+		(c.AuditMode == aConfig.AuditMode) &&
+		(c.BlockSubdomains == aConfig.BlockSubdomains) &&
+		(c.Precedence == aConfig.Precedence) &&
+		(c.UpstreamSelection == aConfig.UpstreamSelection) &&
+		(c.Dashboard == aConfig.Dashboard) &&
+		(c.ECSPolicy == aConfig.ECSPolicy) &&
 		(c.CacheSize == aConfig.CacheSize) &&
+		(c.MaxAnswers == aConfig.MaxAnswers) &&
+		(c.MaxHotEntries == aConfig.MaxHotEntries) &&
+		(c.MaxResponseSize == aConfig.MaxResponseSize) &&
 		(c.Forwarder == aConfig.Forwarder) &&
+		(c.ForwarderProtocol == aConfig.ForwarderProtocol) &&
+		(c.LeaseDomain == aConfig.LeaseDomain) &&
+		(c.LinkLocalOnly == aConfig.LinkLocalOnly) &&
+		(c.LogLevel == aConfig.LogLevel) &&
+		(c.MaxTTL == aConfig.MaxTTL) &&
+		(c.MetricsFile == aConfig.MetricsFile) &&
+		(c.MetricsInterval == aConfig.MetricsInterval) &&
+		(c.MDNSBridge == aConfig.MDNSBridge) &&
+		(c.PiHoleDB == aConfig.PiHoleDB) &&
+		(c.MinimalResponses == aConfig.MinimalResponses) &&
+		(c.MinTTL == aConfig.MinTTL) &&
+		(c.PrivacyMode == aConfig.PrivacyMode) &&
+		(c.QueryTimeout == aConfig.QueryTimeout) &&
+		(c.PrivacyMaskV4 == aConfig.PrivacyMaskV4) &&
+		(c.PrivacyMaskV6 == aConfig.PrivacyMaskV6) &&
+		(c.RebindPolicy == aConfig.RebindPolicy) &&
+		(c.Offline == aConfig.Offline) &&
+		(c.QueryLog == aConfig.QueryLog) &&
+		(c.SafeSearch == aConfig.SafeSearch) &&
+		(c.SelfIdentify == aConfig.SelfIdentify) &&
+		(c.Sandbox == aConfig.Sandbox) &&
 		(c.Port == aConfig.Port) &&
 		(c.RefreshInterval == aConfig.RefreshInterval) &&
-		(c.TTL == aConfig.TTL)
+		(c.RefreshJitter == aConfig.RefreshJitter) &&
+		(c.RefreshWindow == aConfig.RefreshWindow) &&
+		(c.RefreshWorkers == aConfig.RefreshWorkers) &&
+		(c.TLDFile == aConfig.TLDFile) &&
+		(c.Validation == aConfig.Validation) &&
+		(c.DisableTLDCheck == aConfig.DisableTLDCheck) &&
+		(c.TTL == aConfig.TTL) &&
+		(c.UDPSockets == aConfig.UDPSockets)
 } // Equal()
 
 // `String()` implements the `fmt.Stringer` interface for the
@@ -7,9 +7,15 @@ Copyright © 2025  M.Watermann, 10247 Berlin, Germany
 package main
 
 import (
+	"context"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
+	"time"
+
+	"github.com/mwat56/dnscache"
 )
 
 //lint:file-ignore ST1017 - I prefer Yoda conditions
@@ -23,66 +29,978 @@ func Test_tCmdLineArgs_Equal(t *testing.T) {
 	}{
 		/* */
 		{
-			name:    "01 - nil cmdLine and other",
-			cmdLine: nil,
-			other:   nil,
-			wantOK:  true,
+			name:    "01 - nil cmdLine and other",
+			cmdLine: nil,
+			other:   nil,
+			wantOK:  true,
+		},
+		{
+			name:    "02 - nil cmdLine",
+			cmdLine: nil,
+			other:   &tCmdLineArgs{},
+			wantOK:  false,
+		},
+		{
+			name:    "03 - nil other",
+			cmdLine: &tCmdLineArgs{},
+			other:   nil,
+			wantOK:  false,
+		},
+		{
+			name:    "04 - equal",
+			cmdLine: &tCmdLineArgs{},
+			other:   &tCmdLineArgs{},
+			wantOK:  true,
+		},
+		{
+			name:    "05 - not equal (1)",
+			cmdLine: &tCmdLineArgs{ConfigPathName: "config.json"},
+			other:   &tCmdLineArgs{},
+			wantOK:  false,
+		},
+		{
+			name:    "06 - not equal (2)",
+			cmdLine: &tCmdLineArgs{Port: 53},
+			other:   &tCmdLineArgs{},
+			wantOK:  false,
+		},
+		{
+			name:    "07 - not equal (3)",
+			cmdLine: &tCmdLineArgs{ConsoleMode: true},
+			other:   &tCmdLineArgs{},
+			wantOK:  false,
+		},
+		{
+			name:    "08 - not equal (4)",
+			cmdLine: &tCmdLineArgs{DaemonMode: true},
+			other:   &tCmdLineArgs{},
+			wantOK:  false,
+		},
+		{
+			name:    "09 - not equal (5)",
+			cmdLine: &tCmdLineArgs{ContainerMode: true},
+			other:   &tCmdLineArgs{},
+			wantOK:  false,
+		},
+		{
+			name:    "10 - not equal (6)",
+			cmdLine: &tCmdLineArgs{ResetStats: true},
+			other:   &tCmdLineArgs{},
+			wantOK:  false,
+		},
+		/* */
+		// TODO: Add test cases.
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if gotOK := tc.cmdLine.Equal(tc.other); gotOK != tc.wantOK {
+				t.Errorf("tCmdLineArgs.Equal() = '%v', want '%v'",
+					gotOK, tc.wantOK)
+			}
+		})
+	}
+} // Test_tCmdLineArgs_Equal()
+
+func Test_applyLogLevels(t *testing.T) {
+	defer dnscache.SetLogLevel("", slog.LevelInfo)
+
+	tests := []struct {
+		name      string
+		config    tConfiguration
+		component string
+		want      slog.Level
+		wantErr   bool
+	}{
+		/* */
+		{
+			name:      "01 - empty configuration",
+			config:    tConfiguration{},
+			component: "",
+			want:      slog.LevelInfo,
+			wantErr:   false,
+		},
+		{
+			name:      "02 - default level",
+			config:    tConfiguration{LogLevel: "WARN"},
+			component: "server",
+			want:      slog.LevelWarn,
+			wantErr:   false,
+		},
+		{
+			name: "03 - component level",
+			config: tConfiguration{
+				LogLevel:  "error",
+				LogLevels: map[string]string{"test03": "debug"},
+			},
+			component: "test03",
+			want:      slog.LevelDebug,
+			wantErr:   false,
+		},
+		{
+			name:      "04 - invalid level",
+			config:    tConfiguration{LogLevel: "chatty"},
+			component: "",
+			want:      slog.LevelError, // unchanged from previous case
+			wantErr:   true,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := applyLogLevels(tc.config)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("applyLogLevels() error = '%v', wantErr '%v'",
+					err, tc.wantErr)
+				return
+			}
+			if got := dnscache.LogLevel(tc.component); got != tc.want {
+				t.Errorf("LogLevel() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_applyLogLevels()
+
+func Test_applyGroups(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     tConfiguration
+		wantErr    bool
+		wantGroups []string
+	}{
+		/* */
+		{
+			name:       "01 - no groups",
+			config:     tConfiguration{},
+			wantErr:    false,
+			wantGroups: []string{},
+		},
+		{
+			name: "02 - group with client",
+			config: tConfiguration{
+				Groups:  map[string]tGroupConfig{"kids": {}},
+				Clients: map[string]string{"192.168.1.0/24": "kids"},
+			},
+			wantErr:    false,
+			wantGroups: []string{"kids"},
+		},
+		{
+			name: "03 - unknown group",
+			config: tConfiguration{
+				Groups:  map[string]tGroupConfig{"kids": {}},
+				Clients: map[string]string{"192.168.1.0/24": "admin"},
+			},
+			wantErr:    true,
+			wantGroups: []string{"kids"},
+		},
+		{
+			name: "04 - default-deny group",
+			config: tConfiguration{
+				Groups:  map[string]tGroupConfig{"kiosk": {DefaultDeny: true}},
+				Clients: map[string]string{"192.168.1.0/24": "kiosk"},
+			},
+			wantErr:    false,
+			wantGroups: []string{"kiosk"},
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{
+				DataDir: t.TempDir(),
+			})
+			defer resolver.StopExpire()
+
+			err := applyGroups(resolver, tc.config)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("applyGroups() error = '%v', wantErr '%v'",
+					err, tc.wantErr)
+			}
+			if got := resolver.Groups(); !slices.Equal(got, tc.wantGroups) {
+				t.Errorf("Groups() = '%v', want '%v'", got, tc.wantGroups)
+			}
+		})
+	}
+} // Test_applyGroups()
+
+func Test_applyCategories(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{
+		DataDir: t.TempDir(),
+	})
+	defer resolver.StopExpire()
+
+	tests := []struct {
+		name    string
+		config  tConfiguration
+		wantErr bool
+		want    []dnscache.TCategoryState
+	}{
+		/* */
+		{
+			name:   "01 - no categories",
+			config: tConfiguration{},
+			want:   []dnscache.TCategoryState{},
+		},
+		{
+			name: "02 - enabled and disabled categories",
+			config: tConfiguration{Categories: []tCategoryConfig{
+				{Name: "malware"}, {Name: "ads", Disabled: true},
+			}},
+			want: []dnscache.TCategoryState{
+				{Name: "malware", Enabled: true}, {Name: "ads"},
+			},
+		},
+		{
+			name: "03 - switch kept on reload",
+			config: tConfiguration{Categories: []tCategoryConfig{
+				{Name: "malware", Disabled: true}, {Name: "ads"},
+			}},
+			want: []dnscache.TCategoryState{
+				{Name: "malware", Enabled: true}, {Name: "ads"},
+			},
+		},
+		{
+			name: "04 - invalid name",
+			config: tConfiguration{Categories: []tCategoryConfig{
+				{Name: "../phishing"},
+			}},
+			wantErr: true,
+			want: []dnscache.TCategoryState{
+				{Name: "malware", Enabled: true}, {Name: "ads"},
+			},
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := applyCategories(resolver, tc.config)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("applyCategories() error = '%v', wantErr '%v'",
+					err, tc.wantErr)
+			}
+			if got := resolver.Categories(); !slices.Equal(got, tc.want) {
+				t.Errorf("Categories() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_applyCategories()
+
+func Test_applyLeases(t *testing.T) {
+	leaseFile := filepath.Join(t.TempDir(), "dnsmasq.leases")
+	if err := os.WriteFile(leaseFile, []byte("0 aa:bb:cc:dd:ee:01 192.168.1.10 laptop *\n"), 0600); nil != err {
+		t.Fatalf("os.WriteFile() error = '%v'", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tests := []struct {
+		name       string
+		config     tConfiguration
+		wantErr    bool
+		wantLeases int
+	}{
+		/* */
+		{
+			name:       "01 - no lease files",
+			config:     tConfiguration{},
+			wantLeases: 0,
+		},
+		{
+			name:       "02 - lease file",
+			config:     tConfiguration{LeaseFiles: []string{leaseFile}, LeaseDomain: "home.lan"},
+			wantLeases: 1,
+		},
+		{
+			name: "03 - missing lease file",
+			config: tConfiguration{LeaseFiles: []string{
+				filepath.Join(t.TempDir(), "missing.leases"), leaseFile,
+			}},
+			wantErr:    true,
+			wantLeases: 1,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{
+				DataDir: t.TempDir(),
+			})
+			defer resolver.StopExpire()
+
+			err := applyLeases(ctx, resolver, tc.config)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("applyLeases() error = '%v', wantErr '%v'", err, tc.wantErr)
+			}
+			if got := len(resolver.Leases()); got != tc.wantLeases {
+				t.Errorf("len(Leases()) = '%d', want '%d'", got, tc.wantLeases)
+			}
+		})
+	}
+} // Test_applyLeases()
+
+func Test_applyLists(t *testing.T) {
+	allowFile := filepath.Join(t.TempDir(), "allow.txt")
+	if err := os.WriteFile(allowFile, []byte("www.domain.tld\n"), 0600); nil != err {
+		t.Fatalf("os.WriteFile() error = '%v'", err)
+	}
+
+	tests := []struct {
+		name      string
+		config    tConfiguration
+		wantErr   bool
+		wantAllow []string
+	}{
+		/* */
+		{
+			name:      "01 - no lists",
+			config:    tConfiguration{},
+			wantErr:   false,
+			wantAllow: nil,
+		},
+		{
+			name:      "02 - allowlist",
+			config:    tConfiguration{AllowList: allowFile},
+			wantErr:   false,
+			wantAllow: []string{"www.domain.tld"},
+		},
+		{
+			name:    "03 - missing allowlist",
+			config:  tConfiguration{AllowList: filepath.Join(t.TempDir(), "missing.txt")},
+			wantErr: true,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{
+				DataDir: t.TempDir(),
+			})
+			defer resolver.StopExpire()
+
+			err := applyLists(resolver, tc.config)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("applyLists() error = '%v', wantErr '%v'",
+					err, tc.wantErr)
+				return
+			}
+			if tc.wantErr {
+				return
+			}
+			if got := resolver.AllowPatterns(); !slices.Equal(got, tc.wantAllow) {
+				t.Errorf("AllowPatterns() = '%v', want '%v'", got, tc.wantAllow)
+			}
+		})
+	}
+} // Test_applyLists()
+
+func Test_anyPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		want    tAnyPolicy
+		wantErr bool
+	}{
+		/* */
+		{"01 - default", "", anyHINFO, false},
+		{"02 - HINFO", "HINFO", anyHINFO, false},
+		{"03 - subset", " subset ", anySubset, false},
+		{"04 - forward", "forward", anyForward, false},
+		{"05 - invalid", "refuse", anyHINFO, true},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := anyPolicy(tc.policy)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("anyPolicy() error = '%v', wantErr '%v'",
+					err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("anyPolicy() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_anyPolicy()
+
+func Test_blockPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		want    dnscache.TBlockPolicy
+		wantErr bool
+	}{
+		/* */
+		{
+			name:   "01 - default",
+			policy: "",
+			want:   dnscache.BlockPolicyStrip,
+		},
+		{
+			name:   "02 - null IP",
+			policy: "Null",
+			want:   dnscache.BlockPolicyNullIP,
+		},
+		{
+			name:   "03 - NXDOMAIN",
+			policy: " nxdomain ",
+			want:   dnscache.BlockPolicyNXDomain,
+		},
+		{
+			name:    "04 - invalid",
+			policy:  "drop",
+			want:    dnscache.BlockPolicyStrip,
+			wantErr: true,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := blockPolicy(tc.policy)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("blockPolicy() error = '%v', wantErr '%v'",
+					err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("blockPolicy() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_blockPolicy()
+
+func Test_rebindPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		want    dnscache.TRebindPolicy
+		wantErr bool
+	}{
+		/* */
+		{
+			name:   "01 - default",
+			policy: "",
+			want:   dnscache.RebindPolicyOff,
+		},
+		{
+			name:   "02 - strip",
+			policy: "Strip",
+			want:   dnscache.RebindPolicyStrip,
+		},
+		{
+			name:   "03 - block",
+			policy: " block ",
+			want:   dnscache.RebindPolicyBlock,
+		},
+		{
+			name:   "04 - NXDOMAIN",
+			policy: "nxdomain",
+			want:   dnscache.RebindPolicyBlock,
+		},
+		{
+			name:    "05 - invalid",
+			policy:  "drop",
+			want:    dnscache.RebindPolicyOff,
+			wantErr: true,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := rebindPolicy(tc.policy)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("rebindPolicy() error = '%v', wantErr '%v'",
+					err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("rebindPolicy() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_rebindPolicy()
+
+func Test_breakerOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  tConfiguration
+		want    *dnscache.TBreakerOptions
+		wantErr bool
+	}{
+		/* */
+		{
+			name:   "01 - disabled",
+			config: tConfiguration{},
+			want:   nil,
+		},
+		{
+			name:   "02 - defaults",
+			config: tConfiguration{Breaker: &tBreakerConfig{}},
+			want:   &dnscache.TBreakerOptions{},
+		},
+		{
+			name:   "03 - stale",
+			config: tConfiguration{Breaker: &tBreakerConfig{Threshold: 3, Cooldown: "2m", Policy: "Stale"}},
+			want: &dnscache.TBreakerOptions{
+				Threshold: 3,
+				Cooldown:  2 * time.Minute,
+				Policy:    dnscache.BreakerPolicyStale,
+			},
+		},
+		{
+			name:   "04 - NXDOMAIN",
+			config: tConfiguration{Breaker: &tBreakerConfig{Policy: " nxdomain "}},
+			want:   &dnscache.TBreakerOptions{Policy: dnscache.BreakerPolicyNXDomain},
+		},
+		{
+			name:   "05 - SERVFAIL",
+			config: tConfiguration{Breaker: &tBreakerConfig{Policy: "servfail"}},
+			want:   &dnscache.TBreakerOptions{Policy: dnscache.BreakerPolicyFail},
+		},
+		{
+			name:    "06 - invalid policy",
+			config:  tConfiguration{Breaker: &tBreakerConfig{Policy: "drop"}},
+			wantErr: true,
+		},
+		{
+			name:    "07 - invalid cooldown",
+			config:  tConfiguration{Breaker: &tBreakerConfig{Cooldown: "-1s"}},
+			wantErr: true,
+		},
+		{
+			name:    "08 - invalid threshold",
+			config:  tConfiguration{Breaker: &tBreakerConfig{Threshold: -2}},
+			wantErr: true,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := breakerOptions(tc.config)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("breakerOptions() error = '%v', wantErr '%v'",
+					err, tc.wantErr)
+			}
+			if (nil == got) != (nil == tc.want) {
+				t.Fatalf("breakerOptions() = '%v', want '%v'", got, tc.want)
+			}
+			if (nil != got) && (*got != *tc.want) {
+				t.Errorf("breakerOptions() = '%v', want '%v'", *got, *tc.want)
+			}
+		})
+	}
+} // Test_breakerOptions()
+
+func Test_precedence(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    string
+		want    dnscache.TPrecedence
+		wantErr bool
+	}{
+		/* */
+		{
+			name: "01 - default",
+			rule: "",
+			want: dnscache.PrecedenceAllow,
+		},
+		{
+			name: "02 - allow",
+			rule: "Allow",
+			want: dnscache.PrecedenceAllow,
+		},
+		{
+			name: "03 - specific",
+			rule: " specific ",
+			want: dnscache.PrecedenceSpecific,
+		},
+		{
+			name:    "04 - invalid",
+			rule:    "deny",
+			want:    dnscache.PrecedenceAllow,
+			wantErr: true,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := precedence(tc.rule)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("precedence() error = '%v', wantErr '%v'",
+					err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("precedence() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_precedence()
+
+func Test_upstreamSelection(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		want     dnscache.TSelection
+		wantErr  bool
+	}{
+		/* */
+		{"01 - default", "", dnscache.SelectParallel, false},
+		{"02 - parallel", "parallel", dnscache.SelectParallel, false},
+		{"03 - fastest", " Fastest ", dnscache.SelectFastest, false},
+		{"04 - invalid", "random", dnscache.SelectParallel, true},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := upstreamSelection(tc.strategy)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("upstreamSelection() error = '%v', wantErr '%v'",
+					err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("upstreamSelection() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_upstreamSelection()
+
+func Test_validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		level   string
+		want    dnscache.TValidation
+		wantErr bool
+	}{
+		/* */
+		{
+			name:  "01 - default",
+			level: "",
+			want:  dnscache.ValidateStrict,
+		},
+		{
+			name:  "02 - strict",
+			level: "Strict",
+			want:  dnscache.ValidateStrict,
+		},
+		{
+			name:  "03 - relaxed",
+			level: " relaxed ",
+			want:  dnscache.ValidateRelaxed,
+		},
+		{
+			name:  "04 - off",
+			level: "off",
+			want:  dnscache.ValidateOff,
+		},
+		{
+			name:    "05 - invalid",
+			level:   "lenient",
+			want:    dnscache.ValidateStrict,
+			wantErr: true,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := validation(tc.level)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("validation() error = '%v', wantErr '%v'",
+					err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("validation() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_validation()
+
+func Test_forwardProtocol(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol string
+		want     tForwardProtocol
+		wantErr  bool
+	}{
+		/* */
+		{"01 - default", "", forwardAuto, false},
+		{"02 - auto", "Auto", forwardAuto, false},
+		{"03 - UDP", " udp ", forwardUDP, false},
+		{"04 - TCP", "TCP", forwardTCP, false},
+		{"05 - invalid", "dot", forwardAuto, true},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := forwardProtocol(tc.protocol)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("forwardProtocol() error = '%v', wantErr '%v'",
+					err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("forwardProtocol() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_forwardProtocol()
+
+func Test_ecsPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		want    tECSPolicy
+		wantErr bool
+	}{
+		/* */
+		{"01 - default", "", ecsForward, false},
+		{"02 - forward", "Forward", ecsForward, false},
+		{"03 - strip", " strip ", ecsStrip, false},
+		{"04 - synthesize", "SYNTHESIZE", ecsSynthesize, false},
+		{"05 - invalid", "scramble", ecsForward, true},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ecsPolicy(tc.policy)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("ecsPolicy() error = '%v', wantErr '%v'",
+					err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("ecsPolicy() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_ecsPolicy()
+
+func Test_checkConfiguration(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  tConfiguration
+		wantErr bool
+	}{
+		/* */
+		{
+			name:    "01 - empty configuration",
+			config:  tConfiguration{},
+			wantErr: false,
+		},
+		{
+			name: "02 - valid configuration",
+			config: tConfiguration{
+				AdminAddress: "127.0.0.1:8053",
+				BlockedCIDRs: []string{"192.0.2.0/24", "198.51.100.7"},
+				BlockPolicy:  "nxdomain",
+				DNSServers:   []string{"9.9.9.9"},
+				Forwarder:    "9.9.9.9:53",
+				LogLevel:     "debug",
+				Groups:       map[string]tGroupConfig{"kids": {}},
+				Clients:      map[string]string{"192.168.1.0/24": "kids"},
+				Port:         53,
+				Rewrites: []dnscache.TRewriteRule{
+					{Match: "nas.lan", Type: dnscache.RewriteIP, Target: "192.168.1.2"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "03 - invalid port",
+			config:  tConfiguration{Port: 65536},
+			wantErr: true,
+		},
+		{
+			name:    "04 - invalid admin address",
+			config:  tConfiguration{AdminAddress: "localhost"},
+			wantErr: true,
+		},
+		{
+			name:    "05 - invalid DNS server",
+			config:  tConfiguration{DNSServers: []string{"dns.example"}},
+			wantErr: true,
+		},
+		{
+			name:    "06 - invalid blocked CIDR",
+			config:  tConfiguration{BlockedCIDRs: []string{"192.0.2.0/33"}},
+			wantErr: true,
+		},
+		{
+			name: "07 - invalid rewrite",
+			config: tConfiguration{Rewrites: []dnscache.TRewriteRule{
+				{Match: "nas.lan", Type: dnscache.RewriteIP, Target: "nas"},
+			}},
+			wantErr: true,
+		},
+		{
+			name:    "08 - invalid log level",
+			config:  tConfiguration{LogLevels: map[string]string{"server": "loud"}},
+			wantErr: true,
+		},
+		{
+			name:    "09 - invalid privacy mode",
+			config:  tConfiguration{PrivacyMode: "secret"},
+			wantErr: true,
+		},
+		{
+			name:    "10 - client of unknown group",
+			config:  tConfiguration{Clients: map[string]string{"192.168.1.0/24": "kids"}},
+			wantErr: true,
+		},
+		{
+			name:    "11 - client CA without certificate",
+			config:  tConfiguration{AdminClientCA: "ca.pem"},
+			wantErr: true,
+		},
+		{
+			name:    "12 - zone transfers without local zones",
+			config:  tConfiguration{ZoneTransfers: []string{"192.168.1.2"}},
+			wantErr: true,
+		},
+		{
+			name:    "13 - minimal TTL exceeding maximal TTL",
+			config:  tConfiguration{MinTTL: "2h", MaxTTL: "1h"},
+			wantErr: true,
+		},
+		{
+			name:    "14 - invalid TTL override",
+			config:  tConfiguration{TTLOverrides: map[string]string{"example.com": "soon"}},
+			wantErr: true,
 		},
 		{
-			name:    "02 - nil cmdLine",
-			cmdLine: nil,
-			other:   &tCmdLineArgs{},
-			wantOK:  false,
+			name: "15 - valid TTL options",
+			config: tConfiguration{MinTTL: "1m", MaxTTL: "24h",
+				TTLOverrides: map[string]string{"*.example.com": "5s"}},
+			wantErr: false,
 		},
 		{
-			name:    "03 - nil other",
-			cmdLine: &tCmdLineArgs{},
-			other:   nil,
-			wantOK:  false,
+			name:    "16 - invalid refresh options",
+			config:  tConfiguration{RefreshJitter: "-5s", RefreshWindow: "1x", RefreshWorkers: -1},
+			wantErr: true,
 		},
 		{
-			name:    "04 - equal",
-			cmdLine: &tCmdLineArgs{},
-			other:   &tCmdLineArgs{},
-			wantOK:  true,
+			name:    "17 - link-local address without zone",
+			config:  tConfiguration{Address: "fe80::1"},
+			wantErr: true,
 		},
 		{
-			name:    "05 - not equal (1)",
-			cmdLine: &tCmdLineArgs{ConfigPathName: "config.json"},
-			other:   &tCmdLineArgs{},
-			wantOK:  false,
+			name:    "18 - invalid number of UDP sockets",
+			config:  tConfiguration{UDPSockets: -2},
+			wantErr: true,
 		},
 		{
-			name:    "06 - not equal (2)",
-			cmdLine: &tCmdLineArgs{Port: 53},
-			other:   &tCmdLineArgs{},
-			wantOK:  false,
+			name:    "19 - invalid forwarder protocol",
+			config:  tConfiguration{ForwarderProtocol: "quic"},
+			wantErr: true,
 		},
 		{
-			name:    "07 - not equal (3)",
-			cmdLine: &tCmdLineArgs{ConsoleMode: true},
-			other:   &tCmdLineArgs{},
-			wantOK:  false,
+			name:    "20 - invalid ECS policy",
+			config:  tConfiguration{ECSPolicy: "scramble"},
+			wantErr: true,
 		},
 		{
-			name:    "08 - not equal (4)",
-			cmdLine: &tCmdLineArgs{DaemonMode: true},
-			other:   &tCmdLineArgs{},
-			wantOK:  false,
+			name:    "21 - invalid metrics interval",
+			config:  tConfiguration{MetricsInterval: "often"},
+			wantErr: true,
+		},
+		{
+			name: "22 - valid download settings",
+			config: tConfiguration{Download: &tDownloadConfig{
+				Proxy:   "http://proxy.example.com:3128",
+				Timeout: "30s",
+				MaxSize: 1 << 20,
+				Retries: -1,
+			}},
+			wantErr: false,
+		},
+		{
+			name:    "23 - invalid download settings",
+			config:  tConfiguration{Download: &tDownloadConfig{Proxy: "proxy", Timeout: "soon", MaxSize: -1}},
+			wantErr: true,
+		},
+		{
+			name:    "24 - invalid validation",
+			config:  tConfiguration{Validation: "lenient"},
+			wantErr: true,
+		},
+		{
+			name:    "25 - valid breaker settings",
+			config:  tConfiguration{Breaker: &tBreakerConfig{Threshold: 3, Cooldown: "1m", Policy: "stale"}},
+			wantErr: false,
+		},
+		{
+			name:    "26 - invalid breaker settings",
+			config:  tConfiguration{Breaker: &tBreakerConfig{Threshold: -1, Cooldown: "later", Policy: "drop"}},
+			wantErr: true,
+		},
+		{
+			name:    "27 - invalid upstream selection",
+			config:  tConfiguration{UpstreamSelection: "random"},
+			wantErr: true,
+		},
+		{
+			name:    "28 - invalid query timeout",
+			config:  tConfiguration{QueryTimeout: "-2s"},
+			wantErr: true,
+		},
+		{
+			name:    "29 - invalid max. answers",
+			config:  tConfiguration{MaxAnswers: -1},
+			wantErr: true,
+		},
+		{
+			name:    "30 - too small max. response size",
+			config:  tConfiguration{MaxResponseSize: 100},
+			wantErr: true,
+		},
+		{
+			name:    "31 - valid response limits",
+			config:  tConfiguration{MaxAnswers: 20, MaxResponseSize: 1232},
+			wantErr: false,
+		},
+		{
+			name: "32 - invalid rate limit",
+			config: tConfiguration{RateLimit: &tRateLimitConfig{
+				Diagnostic: tRateConfig{Rate: -1},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "33 - duplicate category",
+			config: tConfiguration{Categories: []tCategoryConfig{
+				{Name: "malware"}, {Name: "phishing"}, {Name: "malware"},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "34 - invalid zone record",
+			config: tConfiguration{Zones: []tZoneConfig{{
+				Name:    "lan",
+				Records: []tRecordConfig{{Name: "nas", Type: "A", Data: "nas.lan"}},
+			}}},
+			wantErr: true,
 		},
 		/* */
-		// TODO: Add test cases.
 	}
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			if gotOK := tc.cmdLine.Equal(tc.other); gotOK != tc.wantOK {
-				t.Errorf("tCmdLineArgs.Equal() = '%v', want '%v'",
-					gotOK, tc.wantOK)
+			err := checkConfiguration(tc.config)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("checkConfiguration() error = '%v', wantErr '%v'",
+					err, tc.wantErr)
 			}
 		})
 	}
-} // Test_tCmdLineArgs_Equal()
+} // Test_checkConfiguration()
 
 func Test_parseCmdLineArgs(t *testing.T) {
 	tests := []struct {
@@ -171,6 +1089,38 @@ func Test_parseCmdLineArgs(t *testing.T) {
 				DaemonMode:     true, // set by sanity check
 			},
 		},
+		{
+			name: "10 - subcommand",
+			args: []string{"-port", "5353", "block", "add", "*.ads.tld"},
+			want: tCmdLineArgs{
+				ConfigPathName: gConfigFile,
+				Command:        []string{"block", "add", "*.ads.tld"},
+				Port:           5353,
+				ConsoleMode:    false,
+				DaemonMode:     true, // set by sanity check
+			},
+		},
+		{
+			name: "11 - container mode",
+			args: []string{"-container", "-console", "-daemon"},
+			want: tCmdLineArgs{
+				ConfigPathName: gConfigFile,
+				Port:           53,
+				ConsoleMode:    false, // disabled by sanity check
+				ContainerMode:  true,
+				DaemonMode:     false, // disabled by sanity check
+			},
+		},
+		{
+			name: "12 - reset stats",
+			args: []string{"--reset-stats"},
+			want: tCmdLineArgs{
+				ConfigPathName: gConfigFile,
+				Port:           53,
+				DaemonMode:     true, // set by sanity check
+				ResetStats:     true,
+			},
+		},
 		/* */
 		{
 			name: "09 - help request",
@@ -362,6 +1312,234 @@ func Test_tConfiguration_Equal(t *testing.T) {
 			other:  &tConfiguration{},
 			want:   false,
 		},
+		{
+			name:   "09 - not equal (5)",
+			config: &tConfiguration{LogLevel: "debug"},
+			other:  &tConfiguration{},
+			want:   false,
+		},
+		{
+			name:   "10 - not equal (6)",
+			config: &tConfiguration{LogLevels: map[string]string{"server": "debug"}},
+			other:  &tConfiguration{LogLevels: map[string]string{"server": "info"}},
+			want:   false,
+		},
+		{
+			name:   "11 - not equal (7)",
+			config: &tConfiguration{AdminAddress: "127.0.0.1:8053"},
+			other:  &tConfiguration{AdminAddress: "127.0.0.1:8054"},
+			want:   false,
+		},
+		{
+			name:   "12 - not equal (8)",
+			config: &tConfiguration{Dashboard: true},
+			other:  &tConfiguration{Dashboard: false},
+			want:   false,
+		},
+		{
+			name:   "13 - not equal (9)",
+			config: &tConfiguration{BlockLists: []string{"https://lists.example/a.txt"}},
+			other:  &tConfiguration{},
+			want:   false,
+		},
+		{
+			name:   "14 - not equal (10)",
+			config: &tConfiguration{AllowList: "allow.txt"},
+			other:  &tConfiguration{AllowList: "allow2.txt"},
+			want:   false,
+		},
+		{
+			name:   "15 - not equal (11)",
+			config: &tConfiguration{AdminTokens: []string{"s3cret"}},
+			other:  &tConfiguration{AdminTokens: []string{"other"}},
+			want:   false,
+		},
+		{
+			name:   "16 - not equal (12)",
+			config: &tConfiguration{AdminTLSCert: "cert.pem", AdminTLSKey: "key.pem"},
+			other:  &tConfiguration{AdminTLSCert: "cert.pem"},
+			want:   false,
+		},
+		{
+			name:   "17 - not equal (13)",
+			config: &tConfiguration{LocalZones: []string{"home.lan"}, ZoneTransfers: []string{"192.168.1.2"}},
+			other:  &tConfiguration{LocalZones: []string{"home.lan"}},
+			want:   false,
+		},
+		{
+			name:   "18 - not equal (14)",
+			config: &tConfiguration{MDNSBridge: true},
+			other:  &tConfiguration{MDNSBridge: false},
+			want:   false,
+		},
+		{
+			name:   "19 - not equal (15)",
+			config: &tConfiguration{LeaseFiles: []string{"dnsmasq.leases"}, LeaseDomain: "home.lan"},
+			other:  &tConfiguration{LeaseFiles: []string{"dnsmasq.leases"}},
+			want:   false,
+		},
+		{
+			name:   "20 - not equal (16)",
+			config: &tConfiguration{MinTTL: "1m", TTLOverrides: map[string]string{"example.com": "5s"}},
+			other:  &tConfiguration{MinTTL: "1m", TTLOverrides: map[string]string{"example.com": "1h"}},
+			want:   false,
+		},
+		{
+			name:   "21 - not equal (17)",
+			config: &tConfiguration{NeverCache: []string{"*.dyn.example.com"}},
+			other:  &tConfiguration{},
+			want:   false,
+		},
+		{
+			name:   "22 - not equal (18)",
+			config: &tConfiguration{RefreshWorkers: 4, RefreshJitter: "5s", RefreshWindow: "30m"},
+			other:  &tConfiguration{RefreshWorkers: 4, RefreshJitter: "5s", RefreshWindow: "1h"},
+			want:   false,
+		},
+		{
+			name:   "23 - not equal (19)",
+			config: &tConfiguration{Listeners: []tListenerConfig{{Address: "127.0.0.1:53", Unfiltered: true}}},
+			other:  &tConfiguration{Listeners: []tListenerConfig{{Address: "127.0.0.1:53"}}},
+			want:   false,
+		},
+		{
+			name:   "24 - not equal (20)",
+			config: &tConfiguration{Address: "::", LinkLocalOnly: true},
+			other:  &tConfiguration{Address: "::"},
+			want:   false,
+		},
+		{
+			name:   "25 - not equal (21)",
+			config: &tConfiguration{UDPSockets: 4},
+			other:  &tConfiguration{UDPSockets: -1},
+			want:   false,
+		},
+		{
+			name:   "26 - not equal (22)",
+			config: &tConfiguration{ForwarderProtocol: "tcp"},
+			other:  &tConfiguration{},
+			want:   false,
+		},
+		{
+			name:   "27 - not equal (23)",
+			config: &tConfiguration{ECSPolicy: "strip"},
+			other:  &tConfiguration{ECSPolicy: "synthesize"},
+			want:   false,
+		},
+		{
+			name:   "28 - not equal (24)",
+			config: &tConfiguration{MetricsFile: "metrics.json"},
+			other:  &tConfiguration{},
+			want:   false,
+		},
+		{
+			name:   "29 - not equal (25)",
+			config: &tConfiguration{Download: &tDownloadConfig{Retries: 3}},
+			other:  &tConfiguration{},
+			want:   false,
+		},
+		{
+			name:   "30 - not equal (26)",
+			config: &tConfiguration{Download: &tDownloadConfig{Retries: 3}},
+			other:  &tConfiguration{Download: &tDownloadConfig{Retries: 4}},
+			want:   false,
+		},
+		{
+			name:   "31 - not equal (27)",
+			config: &tConfiguration{DisableTLDCheck: true},
+			other:  &tConfiguration{},
+			want:   false,
+		},
+		{
+			name:   "32 - not equal (28)",
+			config: &tConfiguration{Offline: true},
+			other:  &tConfiguration{},
+			want:   false,
+		},
+		{
+			name:   "33 - not equal (29)",
+			config: &tConfiguration{Validation: "relaxed"},
+			other:  &tConfiguration{},
+			want:   false,
+		},
+		{
+			name:   "34 - equal download settings",
+			config: &tConfiguration{Download: &tDownloadConfig{Timeout: "1m"}},
+			other:  &tConfiguration{Download: &tDownloadConfig{Timeout: "1m"}},
+			want:   true,
+		},
+		{
+			name:   "35 - not equal breaker settings",
+			config: &tConfiguration{Breaker: &tBreakerConfig{Policy: "stale"}},
+			other:  &tConfiguration{Breaker: &tBreakerConfig{Policy: "fail"}},
+			want:   false,
+		},
+		{
+			name:   "36 - not equal upstream selection",
+			config: &tConfiguration{UpstreamSelection: "fastest"},
+			other:  &tConfiguration{},
+			want:   false,
+		},
+		{
+			name:   "37 - not equal query timeout",
+			config: &tConfiguration{QueryTimeout: "2s"},
+			other:  &tConfiguration{QueryTimeout: "3s"},
+			want:   false,
+		},
+		{
+			name:   "38 - not equal max. answers",
+			config: &tConfiguration{MaxAnswers: 20},
+			other:  &tConfiguration{MaxAnswers: 30},
+			want:   false,
+		},
+		{
+			name:   "39 - not equal max. response size",
+			config: &tConfiguration{MaxResponseSize: 1232},
+			other:  &tConfiguration{MaxResponseSize: 4096},
+			want:   false,
+		},
+		{
+			name:   "40 - not equal rate limits",
+			config: &tConfiguration{RateLimit: &tRateLimitConfig{Diagnostic: tRateConfig{Rate: 1}}},
+			other:  &tConfiguration{RateLimit: &tRateLimitConfig{Diagnostic: tRateConfig{Rate: 2}}},
+			want:   false,
+		},
+		{
+			name:   "41 - equal rate limits",
+			config: &tConfiguration{RateLimit: &tRateLimitConfig{Exempt: []string{"192.0.2.1"}}},
+			other:  &tConfiguration{RateLimit: &tRateLimitConfig{Exempt: []string{"192.0.2.1"}}},
+			want:   true,
+		},
+		{
+			name:   "42 - not equal categories",
+			config: &tConfiguration{Categories: []tCategoryConfig{{Name: "malware"}}},
+			other:  &tConfiguration{Categories: []tCategoryConfig{{Name: "malware", Disabled: true}}},
+			want:   false,
+		},
+		{
+			name:   "43 - equal categories",
+			config: &tConfiguration{Categories: []tCategoryConfig{{Name: "ads", BlockLists: []string{"ads.txt"}}}},
+			other:  &tConfiguration{Categories: []tCategoryConfig{{Name: "ads", BlockLists: []string{"ads.txt"}}}},
+			want:   true,
+		},
+		{
+			name:   "44 - not equal search domains",
+			config: &tConfiguration{SearchDomains: []string{"lan"}},
+			other:  &tConfiguration{SearchDomains: []string{"home.arpa", "lan"}},
+			want:   false,
+		},
+		{
+			name:   "45 - not equal zones",
+			config: &tConfiguration{Zones: []tZoneConfig{{Name: "lan", Records: []tRecordConfig{{Name: "nas", Type: "A", Data: "192.168.1.2"}}}}},
+			other:  &tConfiguration{Zones: []tZoneConfig{{Name: "lan", Records: []tRecordConfig{{Name: "nas", Type: "A", Data: "192.168.1.3"}}}}},
+			want:   false,
+		},
+		{
+			name:   "46 - not equal zone files",
+			config: &tConfiguration{Zones: []tZoneConfig{{Name: "lan", File: "lan.zone"}}},
+			other:  &tConfiguration{Zones: []tZoneConfig{{Name: "lan", File: "home.zone"}}},
+			want:   false,
+		},
 		/* */
 		// TODO: Add test cases.
 	}
@@ -377,6 +1555,50 @@ func Test_tConfiguration_Equal(t *testing.T) {
 	}
 } // Test_tConfiguration_Equal()
 
+func Test_tldOptions(t *testing.T) {
+	tests := []struct {
+		name   string
+		config tConfiguration
+		want   dnscache.TTLDOptions
+	}{
+		/* */
+		{
+			name:   "01 - defaults",
+			config: tConfiguration{},
+			want:   dnscache.TTLDOptions{},
+		},
+		{
+			name:   "02 - data directory",
+			config: tConfiguration{DataDir: "/var/lib/dnscache"},
+			want:   dnscache.TTLDOptions{CacheFile: "/var/lib/dnscache/tlds-alpha-by-domain.txt"},
+		},
+		{
+			name:   "03 - configured file",
+			config: tConfiguration{DataDir: "/var/lib/dnscache", TLDFile: "/etc/tlds.txt"},
+			want:   dnscache.TTLDOptions{CacheFile: "/etc/tlds.txt"},
+		},
+		{
+			name:   "04 - disabled",
+			config: tConfiguration{DisableTLDCheck: true},
+			want:   dnscache.TTLDOptions{Disabled: true},
+		},
+		{
+			name:   "05 - offline",
+			config: tConfiguration{Offline: true},
+			want:   dnscache.TTLDOptions{Offline: true},
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tldOptions(tc.config); got != tc.want {
+				t.Errorf("tldOptions() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_tldOptions()
+
 func Test_tConfiguration_String(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -0,0 +1,156 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/mwat56/dnscache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+const (
+	// `envPrefix` is the prefix of the environment variables
+	// holding configuration values (e.g. `DNSCACHE_FORWARDER`).
+	envPrefix = "DNSCACHE_"
+
+	// `envContainer` is the environment variable enabling the
+	// container mode if the `-container` option isn't given.
+	envContainer = envPrefix + "CONTAINER"
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `applyEnvironment()` overrides the configuration by environment variables.
+//
+// Each configuration field can be set by a variable named like its
+// JSON key in upper case with the `DNSCACHE_` prefix, e.g. the
+// `blockLists` field by `DNSCACHE_BLOCKLISTS`. Lists of strings are
+// given as comma separated values, booleans as e.g. `true` or `1`,
+// and all other values (numbers, maps, rewrite rules) in JSON notation.
+//
+// Parameters:
+//   - `aConfig`: The configuration to update.
+//   - `aLookup`: The function returning an environment variable's value.
+//
+// Returns:
+//   - `error`: `nil` if all values were valid, the joined errors otherwise.
+func applyEnvironment(aConfig *tConfiguration, aLookup func(string) (string, bool)) error {
+	var errs []error
+	config := reflect.ValueOf(aConfig).Elem()
+
+	for idx := range config.NumField() {
+		key, _, _ := strings.Cut(config.Type().Field(idx).Tag.Get("json"), ",")
+		if ("" == key) || ("-" == key) {
+			continue
+		}
+		name := envPrefix + strings.ToUpper(key)
+		value, ok := aLookup(name)
+		if !ok {
+			continue
+		}
+		if err := setEnvValue(config.Field(idx), strings.TrimSpace(value)); nil != err {
+			errs = append(errs, fmt.Errorf("invalid %s: %w", name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+} // applyEnvironment()
+
+// `envContainerMode()` checks whether the container mode is enabled
+// by the environment.
+//
+// Returns:
+//   - `bool`: `true` if `DNSCACHE_CONTAINER` is set to a true value.
+func envContainerMode() bool {
+	result, _ := strconv.ParseBool(os.Getenv(envContainer))
+
+	return result
+} // envContainerMode()
+
+// `setEnvValue()` sets a configuration field from an environment
+// variable's value.
+//
+// Parameters:
+//   - `aField`: The configuration field to set.
+//   - `aValue`: The environment variable's value.
+//
+// Returns:
+//   - `error`: `nil` if the value was valid, the error otherwise.
+func setEnvValue(aField reflect.Value, aValue string) error {
+	switch aField.Kind() {
+	case reflect.String:
+		aField.SetString(aValue)
+
+	case reflect.Bool:
+		flag, err := strconv.ParseBool(aValue)
+		if nil != err {
+			return err
+		}
+		aField.SetBool(flag)
+
+	default:
+		if (reflect.Slice == aField.Kind()) && (reflect.String == aField.Type().Elem().Kind()) {
+			list := reflect.MakeSlice(aField.Type(), 0, strings.Count(aValue, ",")+1)
+			for _, item := range strings.Split(aValue, ",") {
+				if item = strings.TrimSpace(item); "" != item {
+					list = reflect.Append(list, reflect.ValueOf(item))
+				}
+			}
+			aField.Set(list)
+			return nil
+		}
+
+		value := reflect.New(aField.Type())
+		if err := json.Unmarshal([]byte(aValue), value.Interface()); nil != err {
+			return err
+		}
+		aField.Set(value.Elem())
+	}
+
+	return nil
+} // setEnvValue()
+
+// `setupContainer()` prepares the application to run in a container.
+//
+// All messages are logged as JSON to `StdOut` and the data directory
+// is replaced by a temporary one, so nothing persists between two runs
+// of the container.
+//
+// Parameters:
+//   - `aConfig`: The configuration to update.
+//
+// Returns:
+//   - `rCleanup`: The function removing the temporary data directory.
+//   - `rErr`: `nil` if the container mode was set up, the error otherwise.
+func setupContainer(aConfig *tConfiguration) (rCleanup func(), rErr error) {
+	dnscache.SetLogger(dnscache.NewSlogLogger(slog.NewJSONHandler(os.Stdout,
+		&slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	dataDir, err := os.MkdirTemp("", "dnscache-")
+	if nil != err {
+		rErr = fmt.Errorf("failed to create data directory: %w", err)
+		return
+	}
+	aConfig.DataDir = dataDir
+	rCleanup = func() {
+		_ = os.RemoveAll(dataDir)
+	}
+
+	return
+} // setupContainer()
+
+/* _EoF_ */
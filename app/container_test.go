@@ -0,0 +1,120 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/mwat56/dnscache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_applyEnvironment(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		config  tConfiguration
+		want    tConfiguration
+		wantErr bool
+	}{
+		/* */
+		{
+			name:   "01 - no variables",
+			config: tConfiguration{Forwarder: "192.0.2.1:53"},
+			want:   tConfiguration{Forwarder: "192.0.2.1:53"},
+		},
+		{
+			name:   "02 - strings",
+			env:    map[string]string{"DNSCACHE_FORWARDER": " 192.0.2.2:53 ", "DNSCACHE_LOGLEVEL": "debug"},
+			config: tConfiguration{Forwarder: "192.0.2.1:53"},
+			want:   tConfiguration{Forwarder: "192.0.2.2:53", LogLevel: "debug"},
+		},
+		{
+			name: "03 - lists",
+			env: map[string]string{
+				"DNSCACHE_BLOCKLISTS": "https://one.tld/list, https://two.tld/list,",
+				"DNSCACHE_DNSSERVERS": "",
+			},
+			config: tConfiguration{DNSServers: []string{"192.0.2.1"}},
+			want: tConfiguration{
+				BlockLists: []string{"https://one.tld/list", "https://two.tld/list"},
+				DNSServers: []string{},
+			},
+		},
+		{
+			name: "04 - numbers and flags",
+			env: map[string]string{
+				"DNSCACHE_PORT":      "5353",
+				"DNSCACHE_TTL":       "30",
+				"DNSCACHE_QUERYLOG":  "1",
+				"DNSCACHE_DASHBOARD": "false",
+			},
+			config: tConfiguration{Dashboard: true},
+			want:   tConfiguration{Port: 5353, TTL: 30, QueryLog: true},
+		},
+		{
+			name: "05 - maps and rules",
+			env: map[string]string{
+				"DNSCACHE_CLIENTS":  `{"10.0.0.0/8":"kids"}`,
+				"DNSCACHE_REWRITES": `[{"match":"nas.lan","target":"192.168.1.10"}]`,
+			},
+			want: tConfiguration{
+				Clients:  map[string]string{"10.0.0.0/8": "kids"},
+				Rewrites: []dnscache.TRewriteRule{{Match: "nas.lan", Target: "192.168.1.10"}},
+			},
+		},
+		{
+			name: "06 - invalid values",
+			env: map[string]string{
+				"DNSCACHE_PORT":     "many",
+				"DNSCACHE_QUERYLOG": "maybe",
+				"DNSCACHE_ADDRESS":  "127.0.0.1",
+			},
+			want:    tConfiguration{Address: "127.0.0.1"},
+			wantErr: true,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			lookup := func(aName string) (string, bool) {
+				value, ok := tc.env[aName]
+				return value, ok
+			}
+			err := applyEnvironment(&tc.config, lookup)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("applyEnvironment() error = '%v', wantErr '%v'", err, tc.wantErr)
+			}
+			if !tc.config.Equal(&tc.want) {
+				t.Errorf("applyEnvironment() =\n%v\nwant\n%v", &tc.config, &tc.want)
+			}
+		})
+	}
+} // Test_applyEnvironment()
+
+func Test_setupContainer(t *testing.T) {
+	defer dnscache.SetLogger(nil)
+
+	config := tConfiguration{DataDir: t.TempDir()}
+	cleanup, err := setupContainer(&config)
+	if nil != err {
+		t.Fatalf("setupContainer() error = '%v'", err)
+	}
+	if info, err := os.Stat(config.DataDir); (nil != err) || !info.IsDir() {
+		t.Fatalf("setupContainer() data directory = '%v', error = '%v'", config.DataDir, err)
+	}
+
+	cleanup()
+	if _, err := os.Stat(config.DataDir); !os.IsNotExist(err) {
+		t.Errorf("cleanup() didn't remove '%v'", config.DataDir)
+	}
+} // Test_setupContainer()
+
+/* _EoF_ */
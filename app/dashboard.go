@@ -0,0 +1,43 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+var (
+	// `gWebFS` holds the static assets of the web dashboard.
+	//
+	//go:embed web
+	gWebFS embed.FS
+)
+
+// ---------------------------------------------------------------------------
+// `tAdminServer` methods:
+
+// `enableDashboard()` serves the built-in web dashboard.
+//
+// The dashboard is a self-contained page using the admin API to show
+// the query rate, the cache hit ratio, and the top blocked domains,
+// and to add allow/deny entries.
+func (as *tAdminServer) enableDashboard() {
+	web, err := fs.Sub(gWebFS, "web")
+	if nil != err {
+		// Can't happen with a valid embedded directory
+		gAdminLog.Error("dashboard assets missing", "error", err)
+		return
+	}
+
+	as.mux.Handle("GET /", http.FileServerFS(web))
+} // enableDashboard()
+
+/* _EoF_ */
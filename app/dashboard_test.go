@@ -0,0 +1,84 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/mwat56/dnscache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_tAdminServer_enableDashboard(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	plain := newAdminServer(resolver, tConfiguration{})
+	dashboard := newAdminServer(resolver, tConfiguration{})
+	dashboard.enableDashboard()
+
+	tests := []struct {
+		name       string
+		server     *tAdminServer
+		path       string
+		wantStatus int
+		wantText   string
+	}{
+		/* */
+		{
+			name:       "01 - dashboard disabled",
+			server:     plain,
+			path:       "/",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "02 - index page",
+			server:     dashboard,
+			path:       "/",
+			wantStatus: http.StatusOK,
+			wantText:   "<title>DNScache dashboard</title>",
+		},
+		{
+			name:       "03 - script",
+			server:     dashboard,
+			path:       "/dashboard.js",
+			wantStatus: http.StatusOK,
+			wantText:   "api/metrics",
+		},
+		{
+			name:       "04 - missing file",
+			server:     dashboard,
+			path:       "/missing.html",
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "05 - API still available",
+			server:     dashboard,
+			path:       "/api/pause",
+			wantStatus: http.StatusOK,
+			wantText:   `"paused":false`,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			status, body := adminRequest(tc.server, http.MethodGet, tc.path, nil)
+			if status != tc.wantStatus {
+				t.Errorf("status = '%d', want '%d'", status, tc.wantStatus)
+				return
+			}
+			if !strings.Contains(body, tc.wantText) {
+				t.Errorf("body = '%s', want '%s'", body, tc.wantText)
+			}
+		})
+	}
+} // Test_tAdminServer_enableDashboard()
+
+/* _EoF_ */
@@ -0,0 +1,166 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"encoding/binary"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/mwat56/dnscache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tWaitingQuery` is a duplicate of a query being answered.
+	tWaitingQuery struct {
+		conn net.PacketConn // connection to send the answer through
+		addr net.Addr       // client to send the answer to
+	}
+
+	// `tPendingQueries` are the queries currently being answered,
+	// together with their duplicates waiting for the answer.
+	tPendingQueries struct {
+		sync.Mutex
+		queries map[string][]tWaitingQuery // duplicates by query key
+	}
+
+	// `tCaptureConn` records the answer sent to a query so it can be
+	// sent to the query's duplicates as well.
+	tCaptureConn struct {
+		net.PacketConn
+		response []byte // the last message sent
+	}
+)
+
+const (
+	// `maxDuplicates` is the max. number of duplicates waiting for
+	// the answer of a query; further ones are dropped.
+	maxDuplicates = 1 << 4
+)
+
+var (
+	// `gPending` are the queries currently being answered.
+	gPending = &tPendingQueries{queries: make(map[string][]tWaitingQuery)}
+)
+
+// ---------------------------------------------------------------------------
+// `tCaptureConn` methods:
+
+// `WriteTo()` sends a message keeping a copy of it.
+//
+// Parameters:
+//   - `aMessage`: The DNS message to send.
+//   - `aAddr`: The address to send the message to.
+//
+// Returns:
+//   - `int`: The number of bytes sent.
+//   - `error`: `nil` if the message was sent, the error otherwise.
+func (cc *tCaptureConn) WriteTo(aMessage []byte, aAddr net.Addr) (int, error) {
+	cc.response = append(cc.response[:0], aMessage...)
+
+	return cc.PacketConn.WriteTo(aMessage, aAddr)
+} // WriteTo()
+
+// ---------------------------------------------------------------------------
+// `tPendingQueries` methods:
+
+// `done()` sends the answer of a query to its waiting duplicates.
+//
+// Parameters:
+//   - `aKey`: The query's key (see `pendingKey()`).
+//   - `aResponse`: The query's answer (`nil` if it wasn't answered).
+func (pq *tPendingQueries) done(aKey string, aResponse []byte) {
+	pq.Lock()
+	waiting := pq.queries[aKey]
+	delete(pq.queries, aKey)
+	pq.Unlock()
+
+	if (2 > len(aResponse)) || (0 == len(waiting)) {
+		return
+	}
+	for _, query := range waiting {
+		_, _ = query.conn.WriteTo(aResponse, query.addr)
+		// Error sending response is not critical, hence we ignore it.
+	}
+	if dnscache.DebugEnabled(serverLogComponent) {
+		gServerLog.Debug("answered duplicate queries", "duplicates", len(waiting))
+	}
+} // done()
+
+// `join()` registers a query as being answered or, if it's already
+// being answered, as waiting for that answer.
+//
+// Parameters:
+//   - `aKey`: The query's key (see `pendingKey()`).
+//   - `aConn`: The connection the query was received on.
+//   - `aAddr`: The client's address.
+//
+// Returns:
+//   - `bool`: `true` if the query is a duplicate (which must not be
+//     handled), `false` if it has to be answered.
+func (pq *tPendingQueries) join(aKey string, aConn net.PacketConn, aAddr net.Addr) bool {
+	pq.Lock()
+	defer pq.Unlock()
+
+	waiting, ok := pq.queries[aKey]
+	if !ok {
+		pq.queries[aKey] = nil
+		return false
+	}
+	if maxDuplicates > len(waiting) {
+		pq.queries[aKey] = append(waiting, tWaitingQuery{
+			conn: aConn,
+			addr: aAddr,
+		})
+	}
+
+	return true
+} // join()
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `pendingKey()` returns the key identifying duplicates of a query.
+//
+// A retransmission re-uses the query's message ID, while queries of
+// the same question with different IDs are answered independently.
+// Queries received via TCP aren't retransmitted by their clients,
+// hence they get no key.
+//
+// Parameters:
+//   - `aConn`: The connection the query was received on.
+//   - `aAddr`: The client's address.
+//   - `aRequest`: The DNS request message.
+//
+// Returns:
+//   - `string`: The listener, client, message ID, and (lower-cased)
+//     question of the query, or an empty string if duplicates aren't
+//     detected.
+func pendingKey(aConn net.PacketConn, aAddr net.Addr, aRequest []byte) string {
+	if _, ok := aConn.(tTCPConn); ok {
+		return ""
+	}
+	if 1 != binary.BigEndian.Uint16(aRequest[4:6]) {
+		return ""
+	}
+	end := questionEnd(aRequest)
+	if 0 == end {
+		return ""
+	}
+	var listener string
+	if local := aConn.LocalAddr(); nil != local {
+		listener = local.String()
+	}
+
+	return listener + "|" + addrIP(aAddr).String() + "|" + string(aRequest[0:2]) +
+		strings.ToLower(string(aRequest[12:end-4])) + string(aRequest[end-4:end])
+} // pendingKey()
+
+/* _EoF_ */
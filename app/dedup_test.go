@@ -0,0 +1,149 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mwat56/dnscache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// `tBlockingForwarder` answers forwarded requests once released.
+type tBlockingForwarder struct {
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (bf *tBlockingForwarder) ForwardDNSRequest(aCtx context.Context, aForwarder string, aRequest []byte) ([]byte, error) {
+	bf.calls.Add(1)
+	select {
+	case <-bf.release:
+	case <-aCtx.Done():
+		return nil, aCtx.Err()
+	}
+	response := append([]byte{}, aRequest...)
+	binary.BigEndian.PutUint16(response[2:4], dnsQR|dnsRD|dnsRA)
+
+	return response, nil
+} // ForwardDNSRequest()
+
+// `txtRequest()` creates a TXT query for the given hostname.
+func txtRequest(aID uint16, aHostname string) []byte {
+	request := createDNSRequest(aID, aHostname)
+	binary.BigEndian.PutUint16(request[len(request)-4:], 16)
+
+	return request
+} // txtRequest()
+
+func Test_pendingKey(t *testing.T) {
+	conn := &tMockPacketConn{}
+	addr := &tMockAddr{}
+	request := createDNSRequest(1, "example.com")
+	key := pendingKey(conn, addr, request)
+	if "" == key {
+		t.Fatal("pendingKey() = '', want key")
+	}
+	twoQuestions := createDNSRequest(1, "example.com")
+	binary.BigEndian.PutUint16(twoQuestions[4:6], 2)
+
+	tests := []struct {
+		name     string
+		conn     net.PacketConn
+		addr     net.Addr
+		request  []byte
+		wantSame bool
+		wantNone bool
+	}{
+		/* */
+		{"01 - other ID", conn, addr, createDNSRequest(2, "example.com"), false, false},
+		{"02 - other case", conn, addr, createDNSRequest(1, "Example.COM"), true, false},
+		{"03 - other name", conn, addr, createDNSRequest(1, "example.org"), false, false},
+		{"04 - other type", conn, addr, txtRequest(1, "example.com"), false, false},
+		{"05 - other client", conn, &net.UDPAddr{IP: net.ParseIP("192.0.2.7"), Port: 53},
+			request, false, false},
+		{"06 - TCP", tTCPConn{}, addr, request, false, true},
+		{"07 - two questions", conn, addr, twoQuestions, false, true},
+		{"08 - malformed", conn, addr, request[:14], false, true},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := pendingKey(tc.conn, tc.addr, tc.request)
+			if tc.wantNone != ("" == got) {
+				t.Errorf("pendingKey() = %q, wantNone '%v'", got, tc.wantNone)
+			}
+			if tc.wantSame != (key == got) {
+				t.Errorf("pendingKey() = %q, wantSame '%v' as %q", got, tc.wantSame, key)
+			}
+		})
+	}
+} // Test_pendingKey()
+
+func Test_handleDNSRequest_duplicates(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	forwarder := &tBlockingForwarder{release: make(chan struct{})}
+	addr := &tMockAddr{}
+	first := make(chan []byte, 1)
+	second := make(chan []byte, 1)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handleDNSRequestWithForwarder(&tMockPacketConn{respChan: first}, addr,
+			txtRequest(1, "retry.example.com"), resolver, "192.0.2.53:53", forwarder)
+	}()
+	for deadline := time.Now().Add(time.Second); 0 == forwarder.calls.Load(); {
+		if time.Now().After(deadline) {
+			t.Fatal("request not forwarded")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	// The retransmission returns at once and gets answered later
+	handleDNSRequestWithForwarder(&tMockPacketConn{respChan: second}, addr,
+		txtRequest(1, "retry.example.com"), resolver, "192.0.2.53:53", forwarder)
+	close(forwarder.release)
+	<-done
+
+	for i, ch := range []chan []byte{first, second} {
+		select {
+		case response := <-ch:
+			if got := binary.BigEndian.Uint16(response[0:2]); 1 != got {
+				t.Errorf("response ID = '%d', want '1'", got)
+			}
+		case <-time.After(time.Second):
+			t.Errorf("no response to query #%d", i+1)
+		}
+	}
+	if got := forwarder.calls.Load(); 1 != got {
+		t.Errorf("forwarded '%d' times, want '1'", got)
+	}
+
+	// Once answered the query gets forwarded again
+	handleDNSRequestWithForwarder(&tMockPacketConn{respChan: first}, addr,
+		txtRequest(1, "retry.example.com"), resolver, "192.0.2.53:53", forwarder)
+	if got := forwarder.calls.Load(); 2 != got {
+		t.Errorf("forwarded '%d' times, want '2'", got)
+	}
+	gPending.Lock()
+	pending := len(gPending.queries)
+	gPending.Unlock()
+	if 0 != pending {
+		t.Errorf("pending queries = '%d', want '0'", pending)
+	}
+} // Test_handleDNSRequest_duplicates()
+
+/* _EoF_ */
@@ -9,12 +9,16 @@ package main
 import (
 	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
-	"log"
+	"io"
 	"net"
 	"os"
 	"os/signal"
+	"slices"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
@@ -33,19 +37,30 @@ const (
 	dnsRA uint16 = 1 << 7  // Recursion Available
 
 	// DNS response codes
-	dnsRcodeNoError uint16 = 0 // No error
-	dnsRcodeFormErr uint16 = 1 // Format error
-	// dnsRcodeServFail uint16 = 2 // Server failure
+	dnsRcodeNoError  uint16 = 0 // No error
+	dnsRcodeFormErr  uint16 = 1 // Format error
+	dnsRcodeServFail uint16 = 2 // Server failure
 	dnsRcodeNXDomain uint16 = 3 // Non-existent domain
-	// dnsRcodeNotImp   uint16 = 4 // Not implemented
-	// dnsRcodeRefused  uint16 = 5 // Query refused
+	dnsRcodeNotImp   uint16 = 4 // Not implemented
+	dnsRcodeRefused  uint16 = 5 // Query refused
 
 	// DNS record types
 	dnsTypeA    uint16 = 1  // A record (IPv4)
+	dnsTypePTR  uint16 = 12 // PTR record (reverse lookup)
 	dnsTypeAAAA uint16 = 28 // AAAA record (IPv6)
 	dnsClassIN  uint16 = 1  // Internet class
 )
 
+const (
+	// `serverLogComponent` is the log component name of the DNS server.
+	serverLogComponent = "server"
+)
+
+var (
+	// `gServerLog` is the logger used by the DNS server.
+	gServerLog = dnscache.Logger(serverLogComponent)
+)
+
 type (
 	// `iForwarderClient` defines an interface for forwarding DNS requests.
 	// It is used to decouple the DNS server from the forwarding mechanism
@@ -54,8 +69,52 @@ type (
 		ForwardDNSRequest(aCtx context.Context, aForwarder string, aRequest []byte) ([]byte, error)
 	}
 
-	// tStdForwarder implements the DNSForwarderClient interface using UDP.
-	tStdForwarder struct{}
+	// `tForwardProtocol` is the protocol used to query the forwarder.
+	tForwardProtocol uint32
+
+	// tStdForwarder implements the DNSForwarderClient interface using
+	// UDP and/or TCP.
+	tStdForwarder struct {
+		protocol tForwardProtocol
+	}
+
+	// `tTCPConn` adapts a TCP connection to the `net.PacketConn`
+	// interface used by the request handlers, taking care of the
+	// two-byte length prefix of DNS messages sent over TCP.
+	tTCPConn struct {
+		net.Conn
+	}
+)
+
+const (
+	// `tcpIdleTimeout` is the time a TCP connection may stay idle
+	// before it gets closed.
+	tcpIdleTimeout = 10 * time.Second
+
+	// `shutdownTimeout` is the time the server may take to shut down
+	// after receiving a termination signal.
+	shutdownTimeout = 5 * time.Second
+
+	// `defQueryTimeout` is the default max. time to answer a forwarded
+	// query.
+	defQueryTimeout = time.Second << 3
+
+	// `forwardBufferSize` is the max. size of a forwarder's UDP response.
+	forwardBufferSize = 1 << 12
+
+	// Protocols to query the forwarder with
+	forwardAuto = tForwardProtocol(0) // UDP, retried over TCP if truncated
+	forwardUDP  = tForwardProtocol(1) // UDP only
+	forwardTCP  = tForwardProtocol(2) // TCP only
+)
+
+var (
+	// `gForwardProtocol` is the protocol used to query the forwarders.
+	gForwardProtocol atomic.Uint32
+
+	// `gQueryTimeout` is the max. total time of a query in
+	// nanoseconds (`0` means the default).
+	gQueryTimeout atomic.Int64
 )
 
 // `addAnswersToResponse()` adds DNS answers to a response.
@@ -149,6 +208,50 @@ func addAnswersToResponse(aResponse []byte, aOffset int, aAnswerCount uint16,
 	return offset, answerCount
 } // addAnswersToResponse()
 
+// `addrIP()` returns the IP address of a network address.
+//
+// Parameters:
+//   - `aAddr`: The network address (usually a `*net.UDPAddr`).
+//
+// Returns:
+//   - `net.IP`: The address's IP (`nil` if there is none).
+func addrIP(aAddr net.Addr) net.IP {
+	switch addr := aAddr.(type) {
+	case nil:
+		return nil
+	case *net.UDPAddr:
+		return addr.IP
+	case *net.TCPAddr:
+		return addr.IP
+	}
+
+	host, _, err := net.SplitHostPort(aAddr.String())
+	if nil != err {
+		host = aAddr.String()
+	}
+
+	return net.ParseIP(host)
+} // addrIP()
+
+// `checkQuery()` checks whether the given message is a DNS query.
+//
+// Parameters:
+//   - `aRequest`: The DNS request.
+//
+// Returns:
+//   - `error`: `nil` if the request is a query, an error matching `dnscache.ErrMalformedQuery` otherwise.
+func checkQuery(aRequest []byte) error {
+	if 12 > len(aRequest) {
+		return fmt.Errorf("%w: %d bytes only", dnscache.ErrMalformedQuery, len(aRequest))
+	}
+	if 0 != (binary.BigEndian.Uint16(aRequest[2:4]) & dnsQR) {
+		// Answering responses might cause loops between servers
+		return fmt.Errorf("%w: response instead of query", dnscache.ErrMalformedQuery)
+	}
+
+	return nil
+} // checkQuery()
+
 // `extractFirstHostname()` extracts the first hostname from a DNS request
 // message.
 //
@@ -241,9 +344,48 @@ func extractHostname(aQuestion []byte) string {
 	return hostname.String()
 } // extractHostname()
 
+// `dialForwarder()` connects to the forwarder.
+//
+// Parameters:
+//   - `aCtx`: The context whose deadline to use for the connection.
+//   - `aNetwork`: The network to use ("udp" or "tcp").
+//   - `aForwarder`: The DNS forwarder to connect to.
+//
+// Returns:
+//   - `net.Conn`: The connection to the forwarder.
+//   - `error`: `nil` if the connection was established, the error otherwise.
+func dialForwarder(aCtx context.Context, aNetwork, aForwarder string) (net.Conn, error) {
+	dialer, err := gOutbound.Load().dialer(aNetwork, aForwarder)
+	if nil != err {
+		return nil, fmt.Errorf("failed to connect to forwarder: %w", err)
+	}
+	conn, err := dialer.DialContext(aCtx, aNetwork, aForwarder)
+	if nil != err {
+		return nil, fmt.Errorf("failed to connect to forwarder: %w", err)
+	}
+
+	// Set deadline based on context
+	deadline, ok := aCtx.Deadline()
+	if !ok {
+		// Default timeout of 8 seconds if no deadline in context
+		deadline = time.Now().Add(time.Second << 3)
+	}
+	if err := conn.SetDeadline(deadline); nil != err {
+		conn.Close()
+		return nil, fmt.Errorf("failed to set connection deadline: %w", err)
+	}
+
+	return conn, nil
+} // dialForwarder()
+
 // `ForwardDNSRequest()` forwards a DNS request to the specified forwarder
 // and returns the response.
 //
+// By default the request is sent via UDP and, if the response comes
+// back truncated, sent again via TCP to get the full answer. Hence the
+// response may be larger than a UDP client accepts and has to be
+// limited by the caller (see `limitUDPResponse()`).
+//
 // Parameters:
 //   - `aCtx`: The context to use for the operation.
 //   - `aForwarder`: The DNS forwarder to use.
@@ -253,43 +395,97 @@ func extractHostname(aQuestion []byte) string {
 //   - `[]byte`: The DNS response.
 //   - `error`: `nil` if the request was forwarded successfully, the error otherwise.
 func (f *tStdForwarder) ForwardDNSRequest(aCtx context.Context, aForwarder string, aRequest []byte) ([]byte, error) {
-	// Create a UDP connection to the forwarder
-	conn, err := net.Dial("udp", aForwarder)
+	if forwardTCP == f.protocol {
+		return f.forwardTCP(aCtx, aForwarder, aRequest)
+	}
+
+	response, err := f.forwardUDP(aCtx, aForwarder, aRequest)
+	if (nil != err) || (forwardUDP == f.protocol) ||
+		(12 > len(response)) || (0 == binary.BigEndian.Uint16(response[2:4])&dnsTC) {
+		return response, err
+	}
+	gServerLog.Debug("truncated response, retrying over TCP", "forwarder", aForwarder)
+
+	return f.forwardTCP(aCtx, aForwarder, aRequest)
+} // ForwardDNSRequest()
+
+// `forwardTCP()` sends a DNS request to the forwarder via TCP.
+//
+// Parameters:
+//   - `aCtx`: The context to use for the operation.
+//   - `aForwarder`: The DNS forwarder to use.
+//   - `aRequest`: The DNS request to forward.
+//
+// Returns:
+//   - `[]byte`: The DNS response.
+//   - `error`: `nil` if the request was forwarded successfully, the error otherwise.
+func (f *tStdForwarder) forwardTCP(aCtx context.Context, aForwarder string, aRequest []byte) ([]byte, error) {
+	conn, err := dialForwarder(aCtx, "tcp", aForwarder)
 	if nil != err {
-		return nil, fmt.Errorf("failed to connect to forwarder: %w", err)
+		return nil, err
 	}
 	defer conn.Close()
+	tcpConn := tTCPConn{conn}
 
-	// Set deadline based on context
-	deadline, ok := aCtx.Deadline()
-	if ok {
-		if err := conn.SetDeadline(deadline); nil != err {
-			return nil, fmt.Errorf("failed to set connection deadline: %w", err)
-		}
-	} else {
-		// Default timeout of 8 seconds if no deadline in context
-		if err := conn.SetDeadline(time.Now().Add(time.Second << 3)); nil != err {
-			return nil, fmt.Errorf("failed to set connection deadline: %w", err)
-		}
+	if _, err := tcpConn.WriteTo(aRequest, nil); nil != err {
+		return nil, fmt.Errorf("failed to send request to forwarder: %w", err)
+	}
+	response := make([]byte, 0xFFFF)
+	n, _, err := tcpConn.ReadFrom(response)
+	if nil != err {
+		return nil, fmt.Errorf("failed to read response from forwarder: %w", err)
+	}
+
+	return response[:n], nil
+} // forwardTCP()
+
+// `forwardUDP()` sends a DNS request to the forwarder via UDP.
+//
+// Datagrams not answering the request (see `validResponse()`) are
+// dropped and counted while waiting for the real answer.
+//
+// Parameters:
+//   - `aCtx`: The context to use for the operation.
+//   - `aForwarder`: The DNS forwarder to use.
+//   - `aRequest`: The DNS request to forward.
+//
+// Returns:
+//   - `[]byte`: The DNS response.
+//   - `error`: `nil` if the request was forwarded successfully, the error otherwise.
+func (f *tStdForwarder) forwardUDP(aCtx context.Context, aForwarder string, aRequest []byte) ([]byte, error) {
+	conn, err := dialForwarder(aCtx, "udp", aForwarder)
+	if nil != err {
+		return nil, err
 	}
+	defer conn.Close()
 
 	// Send the request
 	if _, err := conn.Write(aRequest); nil != err {
 		return nil, fmt.Errorf("failed to send request to forwarder: %w", err)
 	}
 
-	// Read the response
-	response := make([]byte, 512)
-	n, err := conn.Read(response)
-	if nil != err {
-		return nil, fmt.Errorf("failed to read response from forwarder: %w", err)
+	// Read the response, dropping datagrams not answering the request
+	response := make([]byte, forwardBufferSize)
+	for {
+		n, err := conn.Read(response)
+		if nil != err {
+			return nil, fmt.Errorf("failed to read response from forwarder: %w", err)
+		}
+		if validResponse(aRequest, response[:n]) {
+			return response[:n], nil
+		}
+		gSpoofed.Add(1)
+		gServerLog.Warn("dropping mismatched response", "forwarder", aForwarder)
 	}
-
-	return response[:n], nil
-} // ForwardDNSRequest()
+} // forwardUDP()
 
 // `forwardRequest()` forwards a DNS request to the specified forwarder.
 //
+// An answer not matching the forwarded request (see `validResponse()`)
+// isn't relayed but counted, and the client gets a SERVFAIL response.
+// An answer too large for a UDP client is truncated with the TC bit
+// set, so the client may retry via TCP.
+//
 // Parameters:
 //   - `aConn`: The UDP connection to write response to.
 //   - `aAddr`: The address to send response to.
@@ -302,18 +498,34 @@ func (f *tStdForwarder) ForwardDNSRequest(aCtx context.Context, aForwarder strin
 func forwardRequest(aConn net.PacketConn, aAddr net.Addr, aRequest []byte,
 	aID, aFlags, aQDCount uint16, aForwarder string, aForwarderClient iForwarderClient) {
 	// Forward the request
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second<<3)
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout())
 	defer cancel()
 
-	// Forward the request
-	response, err := aForwarderClient.ForwardDNSRequest(ctx, aForwarder, aRequest)
+	// Forward the request with the client subnet as configured
+	request := applyECS(aRequest, addrIP(aAddr), tECSPolicy(gECSPolicy.Load()))
+	response, err := aForwarderClient.ForwardDNSRequest(ctx, aForwarder, request)
 	if nil != err {
+		gServerLog.Debug("forwarding DNS request failed",
+			"forwarder", aForwarder, "error", err)
 		// Send NXDOMAIN response
 		sendNXDOMAINResponse(aConn, aAddr, aID, aFlags, aQDCount, aRequest[12:])
 		return
 	}
 
-	// Send the response from the forwarder
+	// Don't relay an answer to some other request
+	if !validResponse(request, response) {
+		gSpoofed.Add(1)
+		gServerLog.Warn("dropping mismatched response", "forwarder", aForwarder)
+		sendRcodeResponse(aConn, aAddr, aRequest, dnsRcodeServFail)
+		return
+	}
+
+	// Send the response from the forwarder, fitting a UDP client's
+	// payload size since the forwarder may have answered via TCP
+	if gMinimalResponses.Load() {
+		response = minimalResponse(response)
+	}
+	response = limitUDPResponse(aConn, aRequest, limitResponse(response))
 	_, _ = aConn.WriteTo(response, aAddr)
 	// Error sending response is not critical, hence we ignore it.
 } // forwardRequest()
@@ -333,6 +545,9 @@ func handleDNSRequest(aConn net.PacketConn, aAddr net.Addr, aRequest []byte, aRe
 // `handleDNSRequestWithForwarder()` processes a DNS request and sends a response,
 // forwarding non-A/AAAA requests to the specified forwarder if provided.
 //
+// A panic while handling the request is recovered, logged, and
+// counted (see `recoverRequest()`) without affecting other requests.
+//
 // Parameters:
 //   - `aConn`: The UDP connection to write response to.
 //   - `aAddr`: The address to send response to.
@@ -342,24 +557,114 @@ func handleDNSRequest(aConn net.PacketConn, aAddr net.Addr, aRequest []byte, aRe
 //   - `aForwarderClient`: The client to use for forwarding requests.
 func handleDNSRequestWithForwarder(aConn net.PacketConn, aAddr net.Addr, aRequest []byte,
 	aResolver *dnscache.TResolver, aForwarder string, aForwarderClient iForwarderClient) {
+	gInFlight.Add(1)
+	defer gInFlight.Add(-1)
+	defer recoverRequest(aAddr, aRequest)
+
+	// Drop anything that isn't a query
+	if err := checkQuery(aRequest); nil != err {
+		if dnscache.DebugEnabled(serverLogComponent) {
+			gServerLog.Debug("ignoring request", "client", aAddr.String(), "error", err)
+		}
+		return
+	}
 
-	// Check if request is too short
-	if 12 > len(aRequest) {
+	// Ignore clients outside the local link if requested
+	if gLinkLocalOnly.Load() && !isLinkLocalClient(addrIP(aAddr)) {
+		if dnscache.DebugEnabled(serverLogComponent) {
+			gServerLog.Debug("ignoring non-link-local client", "client", aAddr.String())
+		}
 		return
 	}
 
+	// Let the client policy (if any) drop or refuse the query
+	switch aResolver.CheckClient(addrIP(aAddr)) {
+	case dnscache.ClientDrop:
+		if dnscache.DebugEnabled(serverLogComponent) {
+			gServerLog.Debug("dropping query by client policy", "client", aAddr.String())
+		}
+		return
+	case dnscache.ClientRefuse:
+		if dnscache.DebugEnabled(serverLogComponent) {
+			gServerLog.Debug("refusing query by client policy", "client", aAddr.String())
+		}
+		sendRcodeResponse(aConn, aAddr, aRequest, dnsRcodeRefused)
+		return
+	}
+
+	// Drop diagnostic and ANY queries exceeding their rate limits
+	if !gRateLimits.Load().allows(addrIP(aAddr), aRequest) {
+		gRateLimited.Add(1)
+		if dnscache.DebugEnabled(serverLogComponent) {
+			gServerLog.Debug("rate limiting query", "client", aAddr.String())
+		}
+		return
+	}
+
+	// Retransmissions of a query still being answered get its answer
+	if key := pendingKey(aConn, aAddr, aRequest); "" != key {
+		if gPending.join(key, aConn, aAddr) {
+			return
+		}
+		capture := &tCaptureConn{PacketConn: aConn}
+		aConn = capture
+		defer func() { gPending.done(key, capture.response) }()
+	}
+
 	// Parse DNS request header
 	requestID := binary.BigEndian.Uint16(aRequest[0:2])
 	requestFlags := binary.BigEndian.Uint16(aRequest[2:4])
 	requestQDCount := binary.BigEndian.Uint16(aRequest[4:6])
 
+	// Names of the authoritative zones are answered from their records
+	if handleAuthRequest(aConn, aAddr, aRequest) {
+		gQueryLog.Load().Log(aAddr, aRequest, "local")
+		return
+	}
+
+	// Reverse lookups of DHCP leases are answered locally
+	if handlePTRRequest(aConn, aAddr, aRequest, aResolver) {
+		gQueryLog.Load().Log(aAddr, aRequest, "local")
+		return
+	}
+
+	// Only standard queries of the Internet class are resolved
+	if handleUnsupportedRequest(aConn, aAddr, aRequest) {
+		gQueryLog.Load().Log(aAddr, aRequest, "local")
+		return
+	}
+	if handleSelfIDRequest(aConn, aAddr, aRequest, aResolver) {
+		gQueryLog.Load().Log(aAddr, aRequest, "local")
+		return
+	}
+
+	// Query types blocked by the blocklists get an empty answer
+	if handleBlockedTypeRequest(aConn, aAddr, aRequest, aResolver) {
+		gQueryLog.Load().Log(aAddr, aRequest, "local")
+		return
+	}
+
+	// Queries for all records of a name aren't forwarded
+	if handleANYRequest(aConn, aAddr, aRequest, aResolver) {
+		gQueryLog.Load().Log(aAddr, aRequest, "local")
+		return
+	}
+
 	// First pass: check if we need to forward any questions
 	if shouldForwardRequest(aRequest, requestQDCount, aForwarder) {
+		if aResolver.Offline() {
+			// No upstream queries in offline mode
+			gQueryLog.Load().Log(aAddr, aRequest, "local")
+			sendRcodeResponse(aConn, aAddr, aRequest, dnsRcodeServFail)
+			return
+		}
+		gQueryLog.Load().Log(aAddr, aRequest, "forward")
 		forwardRequest(aConn, aAddr, aRequest, requestID, requestFlags, requestQDCount, aForwarder, aForwarderClient)
 		return
 	}
 
 	// Second pass: handle A/AAAA records locally
+	gQueryLog.Load().Log(aAddr, aRequest, "local")
 	handleLocalRequest(aConn, aAddr, aRequest, requestID, requestFlags, requestQDCount, aResolver)
 } // handleDNSRequestWithForwarder()
 
@@ -375,13 +680,24 @@ func handleDNSRequestWithForwarder(aConn net.PacketConn, aAddr net.Addr, aReques
 //   - `aResolver`: The DNS resolver to use for lookups.
 func handleLocalRequest(aConn net.PacketConn, aAddr net.Addr, aRequest []byte,
 	aID, aFlags, aQDCount uint16, aResolver *dnscache.TResolver) {
+	// The client's group determines the allow/deny list to use
+	client := addrIP(aAddr)
 
 	// For non-existent domains, send NXDOMAIN response immediately
 	if 0 < aQDCount {
 		// Extract the first hostname
 		if hostname := extractFirstHostname(aRequest); "" != hostname {
 			// Try to lookup the hostname
-			ips, err := aResolver.Fetch(hostname)
+			ips, err := fetchFor(aConn, aResolver, client, hostname,
+				extractFirstQType(aRequest))
+
+			// Names neither cached nor local can't be resolved offline,
+			// while all upstream servers are failing, or in time
+			if errors.Is(err, dnscache.ErrOffline) || errors.Is(err, dnscache.ErrCircuitOpen) ||
+				errors.Is(err, dnscache.ErrUpstreamTimeout) {
+				sendRcodeResponse(aConn, aAddr, aRequest, dnsRcodeServFail)
+				return
+			}
 
 			// If lookup fails, send NXDOMAIN immediately
 			if (nil != err) || (0 == len(ips)) {
@@ -482,8 +798,13 @@ func handleLocalRequest(aConn net.PacketConn, aAddr net.Addr, aRequest []byte,
 			}
 
 			if "" != hostname {
-				// Lookup IP addresses
-				ips, err := aResolver.Fetch(hostname)
+				// Lookup IP addresses (the first question was
+				// counted already when checking for NXDOMAIN)
+				countType := qType
+				if 0 == i {
+					countType = 0
+				}
+				ips, err := fetchFor(aConn, aResolver, client, hostname, countType)
 				if (nil != err) || (0 == len(ips)) {
 					// Set NXDOMAIN if lookup fails
 					binary.BigEndian.PutUint16(response[2:4], dnsQR|dnsAA|dnsRA|(aFlags&dnsRD)|dnsRcodeNXDomain)
@@ -504,7 +825,7 @@ func handleLocalRequest(aConn net.PacketConn, aAddr net.Addr, aRequest []byte,
 
 	// Always send a response
 	if questionProcessed {
-		_, _ = aConn.WriteTo(response[:responseOffset], aAddr)
+		_, _ = aConn.WriteTo(limitResponse(response[:responseOffset]), aAddr)
 	} else if 0 < aQDCount {
 		// If we have questions but couldn't process any, send FORMERR
 		binary.BigEndian.PutUint16(response[2:4], dnsQR|dnsAA|dnsRA|(aFlags&dnsRD)|dnsRcodeFormErr)
@@ -561,6 +882,116 @@ func processARecord(aRequest, aResponse []byte, aOffset int, aAnswerCount uint16
 } // processARecord()
 /* */
 
+// `handlePTRRequest()` answers a reverse lookup for the address of
+// a DHCP lease.
+//
+// Parameters:
+//   - `aConn`: The UDP connection to write response to.
+//   - `aAddr`: The address to send response to.
+//   - `aRequest`: The DNS request message.
+//   - `aResolver`: The DNS resolver to use for lookups.
+//
+// Returns:
+//   - `bool`: `true` if the request was answered, `false` otherwise.
+func handlePTRRequest(aConn net.PacketConn, aAddr net.Addr, aRequest []byte,
+	aResolver *dnscache.TResolver) bool {
+	if 1 != binary.BigEndian.Uint16(aRequest[4:6]) {
+		return false
+	}
+	qType, qClass, ok := questionType(aRequest)
+	if !ok || (dnsTypePTR != qType) || (dnsClassIN != qClass) {
+		return false
+	}
+	ip := reverseIP(extractFirstHostname(aRequest))
+	if nil == ip {
+		return false
+	}
+	hostname, ok := aResolver.LookupAddr(ip)
+	if !ok {
+		return false
+	}
+	target := encodeName(hostname)
+	if nil == target {
+		return false
+	}
+
+	end := questionEnd(aRequest)
+	response := make([]byte, 12, end+12+len(target))
+	binary.BigEndian.PutUint16(response[0:2], binary.BigEndian.Uint16(aRequest[0:2]))
+	binary.BigEndian.PutUint16(response[2:4],
+		dnsQR|dnsAA|dnsRA|(binary.BigEndian.Uint16(aRequest[2:4])&dnsRD))
+	binary.BigEndian.PutUint16(response[4:6], 1) // QDCount
+	binary.BigEndian.PutUint16(response[6:8], 1) // ANCount
+	response = append(response, aRequest[12:end]...)
+
+	response = binary.BigEndian.AppendUint16(response, 0xC00C) // name pointer
+	response = binary.BigEndian.AppendUint16(response, dnsTypePTR)
+	response = binary.BigEndian.AppendUint16(response, dnsClassIN)
+	response = binary.BigEndian.AppendUint32(response, 300)                 // 5 minutes TTL
+	response = binary.BigEndian.AppendUint16(response, uint16(len(target))) //#nosec G115
+	response = append(response, target...)
+
+	_, _ = aConn.WriteTo(response, aAddr)
+	// Error sending response is not critical, hence we ignore it.
+
+	return true
+} // handlePTRRequest()
+
+// `queryTimeout()` returns the max. total time of a query.
+//
+// Returns:
+//   - `time.Duration`: The configured timeout, or `defQueryTimeout`.
+func queryTimeout() time.Duration {
+	if result := time.Duration(gQueryTimeout.Load()); 0 < result {
+		return result
+	}
+
+	return defQueryTimeout
+} // queryTimeout()
+
+// `reverseIP()` returns the address of a reverse lookup name.
+//
+// Parameters:
+//   - `aName`: The name below `in-addr.arpa` or `ip6.arpa`.
+//
+// Returns:
+//   - `net.IP`: The address (`nil` if the name is no reverse name).
+func reverseIP(aName string) net.IP {
+	name := strings.ToLower(strings.TrimSuffix(aName, "."))
+
+	if labels, ok := strings.CutSuffix(name, ".in-addr.arpa"); ok {
+		parts := strings.Split(labels, ".")
+		if 4 != len(parts) {
+			return nil
+		}
+		slices.Reverse(parts)
+
+		return net.ParseIP(strings.Join(parts, ".")).To4()
+	}
+
+	if nibbles, ok := strings.CutSuffix(name, ".ip6.arpa"); ok {
+		parts := strings.Split(nibbles, ".")
+		if 32 != len(parts) {
+			return nil
+		}
+		slices.Reverse(parts)
+		var hexIP strings.Builder
+		for i, nibble := range parts {
+			if 1 != len(nibble) {
+				return nil
+			}
+			if (0 < i) && (0 == i%4) {
+				hexIP.WriteByte(':')
+			}
+			hexIP.WriteString(nibble)
+		}
+
+		return net.ParseIP(hexIP.String())
+	}
+
+	return nil
+} // reverseIP()
+
 // `sendNXDOMAINResponse()` sends a DNS response with NXDOMAIN status.
 //
 // Parameters:
@@ -649,6 +1080,73 @@ func shouldForwardRequest(aRequest []byte, aQDCount uint16, aForwarder string) b
 	return false
 } // shouldForwardRequest()
 
+// `serveTCP()` accepts DNS connections over TCP until the listener
+// gets closed.
+//
+// Parameters:
+//   - `aListener`: The TCP listener to accept connections from.
+//   - `aResolver`: The DNS resolver to use for lookups.
+//   - `aForwarder`: The DNS forwarder to use for non-A/AAAA requests (empty string means no forwarding).
+//   - `aForwarderClient`: The client to use for forwarding requests.
+func serveTCP(aListener net.Listener, aResolver *dnscache.TResolver,
+	aForwarder string, aForwarderClient iForwarderClient) {
+	for {
+		conn, err := aListener.Accept()
+		if nil != err {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			gServerLog.Warn("error accepting TCP connection", "error", err)
+			continue
+		}
+		go handleTCPConn(conn, aResolver, aForwarder, aForwarderClient)
+	}
+} // serveTCP()
+
+// `handleTCPConn()` answers the DNS requests sent over a TCP connection.
+//
+// Zone transfer (AXFR) requests are answered by `handleAXFR()`, all
+// other requests by the same handler used for UDP requests.
+//
+// Parameters:
+//   - `aConn`: The TCP connection to serve.
+//   - `aResolver`: The DNS resolver to use for lookups.
+//   - `aForwarder`: The DNS forwarder to use for non-A/AAAA requests (empty string means no forwarding).
+//   - `aForwarderClient`: The client to use for forwarding requests.
+func handleTCPConn(aConn net.Conn, aResolver *dnscache.TResolver,
+	aForwarder string, aForwarderClient iForwarderClient) {
+	defer aConn.Close()
+	defer recoverRequest(aConn.RemoteAddr(), nil) // e.g. zone transfers
+	conn := tTCPConn{aConn}
+	buffer := make([]byte, 0xFFFF)
+
+	for {
+		if err := aConn.SetReadDeadline(time.Now().Add(tcpIdleTimeout)); nil != err {
+			return
+		}
+		n, addr, err := conn.ReadFrom(buffer)
+		if nil != err {
+			return // client closed the connection or timed out
+		}
+		request := buffer[:n]
+		if dnscache.DebugEnabled(serverLogComponent) {
+			gServerLog.Debug("received DNS request",
+				"client", addr.String(), "size", n, "network", "tcp")
+		}
+
+		if qType, _, ok := questionType(request); ok && (dnsTypeAXFR == qType) {
+			gQueryLog.Load().Log(addr, request, "axfr")
+			if err := handleAXFR(aConn, request, aResolver); nil != err {
+				gServerLog.Warn("error sending zone transfer", "error", err)
+				return
+			}
+			continue
+		}
+		handleDNSRequestWithForwarder(conn, addr, request, aResolver,
+			aForwarder, aForwarderClient)
+	}
+} // handleTCPConn()
+
 // `startDNSserver()` starts a DNS server on the specified address and port.
 //
 // Parameters:
@@ -656,10 +1154,12 @@ func shouldForwardRequest(aRequest []byte, aQDCount uint16, aForwarder string) b
 //   - `aAddress`: The IP address to bind to (empty string means all addresses).
 //   - `aPort`: The port to listen on.
 //   - `aForwarder`: The DNS forwarder to use for non-A/AAAA requests (empty string means no forwarding).
+//   - `aListeners`: The additional listeners to start (sharing the resolver).
 //
 // Returns:
 //   - `error`: `nil` if the server started successfully, otherwise the error that occurred.
-func startDNSserver(aResolver *dnscache.TResolver, aAddress string, aPort int, aForwarder string) error {
+func startDNSserver(aResolver *dnscache.TResolver, aAddress string, aPort int,
+	aForwarder string, aListeners []tListenerConfig) error {
 	if nil == aResolver {
 		return fmt.Errorf("nil resolver provided")
 	}
@@ -669,75 +1169,187 @@ func startDNSserver(aResolver *dnscache.TResolver, aAddress string, aPort int, a
 	}
 
 	// Create UDP listener
-	listenAddr := fmt.Sprintf("%s:%d", aAddress, aPort)
-	conn, err := net.ListenPacket("udp", listenAddr)
+	host := strings.Trim(aAddress, "[]")
+	listenAddr := net.JoinHostPort(host, strconv.Itoa(aPort))
+	conns, err := listenUDP(listenNetwork("udp", host), listenAddr)
 	if nil != err {
 		//TODO: implement retry logic
 		return fmt.Errorf("failed to start DNS server: %w", err)
 	}
 
+	// Create TCP listener (used by large responses and zone transfers)
+	tcpListener, err := listenTCP(listenNetwork("tcp", host), listenAddr)
+	if nil != err {
+		gServerLog.Warn("failed to start TCP listener", "address", listenAddr, "error", err)
+	}
+
+	// Create a forwarder client
+	forwarderClient := &tStdForwarder{
+		protocol: tForwardProtocol(gForwardProtocol.Load()),
+	}
+
+	// Start the additional listeners
+	closers := make([]io.Closer, 0, len(conns)+1)
+	for _, conn := range conns {
+		closers = append(closers, conn)
+	}
+	if nil != tcpListener {
+		closers = append(closers, tcpListener)
+	}
+	for _, listener := range aListeners {
+		started, err := startListener(aResolver, listener, aForwarder, forwarderClient)
+		if nil != err {
+			for _, closer := range closers {
+				_ = closer.Close()
+			}
+			return err
+		}
+		closers = append(closers, started...)
+	}
+
+	// All sockets are open, so lock the process down
+	if err := gSandbox.Load().apply(); nil != err {
+		gServerLog.Warn("failed to apply the sandbox", "error", err)
+	}
+
 	// Setup signal handling for graceful shutdown
 	sig := make(chan os.Signal, 2)
 	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
 
-	// Create a channel to signal shutdown to the handler goroutine
-	done := make(chan struct{})
-
-	// Create a forwarder client
-	forwarderClient := &tStdForwarder{}
+	if nil != tcpListener {
+		go serveTCP(tcpListener, aResolver, aForwarder, forwarderClient)
+	}
 
 	// Start handler in a goroutine
 	go func() {
-		log.Printf("Starting DNS server on %s", listenAddr)
+		gDNSRunning.Store(true)
+		defer gDNSRunning.Store(false)
+
+		gServerLog.Info("starting DNS server",
+			"address", listenAddr, "sockets", len(conns))
 		if "" != aForwarder {
-			log.Printf("Using DNS forwarder: %s", aForwarder)
+			gServerLog.Info("using DNS forwarder", "forwarder", aForwarder)
 		}
+		// One read loop per socket, the first one in this goroutine
+		for _, conn := range conns[1:] {
+			go serveUDP(conn, aResolver, aForwarder, forwarderClient)
+		}
+		serveUDP(conns[0], aResolver, aForwarder, forwarderClient)
+	}() // go func()
 
-		buffer := make([]byte, 512) // Standard DNS message size
-		for {
-			select {
-			case <-done:
-				return
-
-			default:
-				// Set read deadline to allow checking for shutdown signal
-				if err := conn.SetReadDeadline(time.Now().Add(time.Second)); nil != err {
-					log.Printf("Error setting read deadline: %v", err)
-				}
-
-				// Read incoming DNS request
-				n, addr, err := conn.ReadFrom(buffer)
-				if nil != err {
-					if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
-						// This is just a timeout, continue to check for shutdown
-						continue
-					}
-					log.Printf("Error reading DNS request: %v", err)
-					continue
-				}
+	// Tell a replaced process to stop serving
+	reportReady()
 
-				// Handle the DNS request in a separate goroutine
-				go handleDNSRequestWithForwarder(conn, addr, buffer[:n], aResolver, aForwarder, forwarderClient)
-			} // select
-		} // for
-	}() // go func()
+	// Wait for termination signal (or an upgrade)
+	upgrade := make(chan os.Signal, 1)
+	if nil != upgradeSignal {
+		signal.Notify(upgrade, upgradeSignal)
+		defer signal.Stop(upgrade)
+	}
+	for waiting := true; waiting; {
+		select {
+		case <-sig:
+			waiting = false
+		case <-upgrade:
+			gServerLog.Info("upgrading DNS server")
+			if err := startUpgrade(closers); nil != err {
+				gServerLog.Error("upgrade failed", "error", err)
+				continue
+			}
+			waiting = false
+		}
+	}
+	gServerLog.Info("shutting down DNS server")
 
-	// Wait for termination signal
-	<-sig
-	log.Println("Shutting down DNS server ...")
-	// Signal handler goroutine to stop
-	close(done)
+	// A second signal or a hanging cleanup terminates immediately
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-done:
+			return
+		case <-sig:
+		case <-time.After(shutdownTimeout):
+		}
+		gServerLog.Warn("forced DNS server shutdown")
+		os.Exit(1)
+	}()
+
+	// Let the upgraded process take the new requests
+	if gHandedOver.Load() {
+		stopReading(closers)
+		if !drainRequests(drainTimeout) {
+			gServerLog.Warn("dropping requests in flight", "requests", gInFlight.Load())
+		}
+	}
 
 	// Stop background refresh and expire
 	aResolver.StopRefresh().StopExpire()
 
-	// Close the connection
-	if err := conn.Close(); nil != err {
+	// Close the connections (which ends the handler goroutines)
+	var errs []error
+	for _, closer := range closers {
+		// The listeners are closed already after an upgrade
+		if err := closer.Close(); (nil != err) && !errors.Is(err, net.ErrClosed) {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); nil != err {
 		return fmt.Errorf("error closing connection: %w", err)
 	}
 
-	log.Println("DNS server shutdown complete")
+	gServerLog.Info("DNS server shutdown complete")
 	return nil
 } // startDNSserver()
 
+// ---------------------------------------------------------------------------
+// `tTCPConn` methods:
+
+// `ReadFrom()` reads a single length-prefixed DNS message.
+//
+// Parameters:
+//   - `aBuffer`: The buffer to read the message into.
+//
+// Returns:
+//   - `int`: The message's length.
+//   - `net.Addr`: The address of the connection's peer.
+//   - `error`: `nil` if a message was read, the error otherwise.
+func (tc tTCPConn) ReadFrom(aBuffer []byte) (int, net.Addr, error) {
+	var prefix [2]byte
+	if _, err := io.ReadFull(tc.Conn, prefix[:]); nil != err {
+		return 0, nil, err
+	}
+	size := int(binary.BigEndian.Uint16(prefix[:]))
+	if size > len(aBuffer) {
+		return 0, nil, io.ErrShortBuffer
+	}
+	if _, err := io.ReadFull(tc.Conn, aBuffer[:size]); nil != err {
+		return 0, nil, err
+	}
+
+	return size, tc.RemoteAddr(), nil
+} // ReadFrom()
+
+// `WriteTo()` writes a single DNS message with its length prefix.
+//
+// Parameters:
+//   - `aMessage`: The DNS message to write.
+//   - `aAddr`: Ignored since the connection's peer is the receiver.
+//
+// Returns:
+//   - `int`: The number of message bytes written.
+//   - `error`: `nil` if the message was written, the error otherwise.
+func (tc tTCPConn) WriteTo(aMessage []byte, aAddr net.Addr) (int, error) {
+	if 0xFFFF < len(aMessage) {
+		return 0, fmt.Errorf("DNS message too large: %d bytes", len(aMessage))
+	}
+	data := make([]byte, 2, 2+len(aMessage))
+	binary.BigEndian.PutUint16(data, uint16(len(aMessage))) //#nosec G115
+	if _, err := tc.Write(append(data, aMessage...)); nil != err {
+		return 0, err
+	}
+
+	return len(aMessage), nil
+} // WriteTo()
+
 /* _EoF_ */
@@ -7,8 +7,10 @@ Copyright © 2025  M.Watermann, 10247 Berlin, Germany
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -19,6 +21,8 @@ import (
 	"time"
 
 	"github.com/mwat56/dnscache"
+	"github.com/mwat56/dnscache/dnscachetest"
+	"github.com/mwat56/dnscache/internal/workload"
 )
 
 //lint:file-ignore ST1017 - I prefer Yoda conditions
@@ -402,6 +406,185 @@ func createDNSRequest(aID uint16, aHostname string) []byte {
 	return request[:offset]
 } // createDNSRequest()
 
+func Test_addrIP(t *testing.T) {
+	tests := []struct {
+		name string
+		addr net.Addr
+		want net.IP
+	}{
+		/* */
+		{
+			name: "01 - nil address",
+			addr: nil,
+			want: nil,
+		},
+		{
+			name: "02 - UDP address",
+			addr: &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 53},
+			want: net.ParseIP("192.0.2.1"),
+		},
+		{
+			name: "03 - TCP address",
+			addr: &net.TCPAddr{IP: net.ParseIP("2001:db8::1"), Port: 53},
+			want: net.ParseIP("2001:db8::1"),
+		},
+		{
+			name: "04 - other address",
+			addr: &tMockAddr{},
+			want: net.ParseIP("127.0.0.1"),
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := addrIP(tc.addr); !got.Equal(tc.want) {
+				t.Errorf("addrIP() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_addrIP()
+
+func Test_checkQuery(t *testing.T) {
+	query := createDNSRequest(0x1234, "example.com")
+	response := bytes.Clone(query)
+	binary.BigEndian.PutUint16(response[2:4], dnsQR|dnsRD)
+
+	tests := []struct {
+		name    string
+		request []byte
+		wantErr bool
+	}{
+		/* */
+		{"01 - nil request", nil, true},
+		{"02 - short request", query[:11], true},
+		{"03 - query", query, false},
+		{"04 - response", response, true},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkQuery(tc.request)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("checkQuery() error = '%v', wantErr '%v'", err, tc.wantErr)
+				return
+			}
+			if tc.wantErr && !errors.Is(err, dnscache.ErrMalformedQuery) {
+				t.Errorf("checkQuery() error = '%v', want '%v'",
+					err, dnscache.ErrMalformedQuery)
+			}
+		})
+	}
+} // Test_checkQuery()
+
+func Test_queryTimeout(t *testing.T) {
+	defer gQueryTimeout.Store(0)
+
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		want    time.Duration
+	}{
+		/* */
+		{"01 - default", 0, defQueryTimeout},
+		{"02 - configured", 2 * time.Second, 2 * time.Second},
+		{"03 - negative", -time.Second, defQueryTimeout},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gQueryTimeout.Store(int64(tc.timeout))
+			if got := queryTimeout(); got != tc.want {
+				t.Errorf("queryTimeout() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_queryTimeout()
+
+func Test_reverseIP(t *testing.T) {
+	tests := []struct {
+		name string
+		host string
+		want net.IP
+	}{
+		/* */
+		{"01 - no reverse name", "www.example.com", nil},
+		{"02 - IPv4", "10.1.168.192.in-addr.arpa.", net.ParseIP("192.168.1.10")},
+		{"03 - IPv4 network", "1.168.192.in-addr.arpa", nil},
+		{"04 - IPv6",
+			"0.1.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.d.f.IP6.ARPA",
+			net.ParseIP("fd00::10")},
+		{"05 - invalid IPv6", "10.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.d.f.ip6.arpa", nil},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := reverseIP(tc.host); !got.Equal(tc.want) {
+				t.Errorf("reverseIP() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_reverseIP()
+
+func Test_handlePTRRequest(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	resolver.SetLeases("test", "home.lan", []dnscache.TLease{
+		{Hostname: "laptop", IP: net.ParseIP("192.168.1.10")},
+	})
+
+	tests := []struct {
+		name    string
+		request []byte
+		want    bool
+	}{
+		/* */
+		{
+			name:    "01 - A query",
+			request: createDNSQuery("laptop.home.lan", dnsTypeA),
+			want:    false,
+		},
+		{
+			name:    "02 - unknown address",
+			request: createDNSQuery("11.1.168.192.in-addr.arpa", dnsTypePTR),
+			want:    false,
+		},
+		{
+			name:    "03 - leased address",
+			request: createDNSQuery("10.1.168.192.in-addr.arpa", dnsTypePTR),
+			want:    true,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var response []byte
+			conn := &tMockPacketConn{
+				writeTo: func(aBuf []byte, _ net.Addr) (int, error) {
+					response = append([]byte{}, aBuf...)
+					return len(aBuf), nil
+				},
+			}
+			if got := handlePTRRequest(conn, &tMockAddr{}, tc.request, resolver); got != tc.want {
+				t.Errorf("handlePTRRequest() = '%v', want '%v'", got, tc.want)
+			}
+			if !tc.want {
+				return
+			}
+			if got := binary.BigEndian.Uint16(response[6:8]); 1 != got {
+				t.Errorf("ANCount = '%d', want '1'", got)
+			}
+			if target := response[len(tc.request)+12:]; !bytes.Equal(target, encodeName("laptop.home.lan")) {
+				t.Errorf("PTR target = '%q', want 'laptop.home.lan'", target)
+			}
+		})
+	}
+} // Test_handlePTRRequest()
+
 func Test_extractHostname(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -451,8 +634,10 @@ func Test_extractHostname(t *testing.T) {
 } // Test_extractHostname()
 
 func Test_handleDNSRequest(t *testing.T) {
-	// Create a mock resolver
-	resolver := dnscache.New(0)
+	// Create a resolver whose lookups never leave the test
+	upstream := dnscachetest.StartUpstream(t, nil).
+		Add("example.com", time.Hour, net.ParseIP("192.168.2.2"))
+	resolver := dnscachetest.NewResolver(t, upstream)
 
 	// Add a test entry to the resolver
 	testHost := "example.org"
@@ -848,6 +1033,300 @@ func Test_handleDNSRequestWithForwarding(t *testing.T) {
 	}
 } // Test_handleDNSRequestWithForwarding()
 
+func Test_handleDNSRequest_offline(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{Offline: true})
+	_ = resolver.Create(context.TODO(), "cached.localdomain",
+		[]net.IP{net.ParseIP("192.168.2.1")}, time.Minute)
+
+	tests := []struct {
+		name        string
+		request     []byte
+		wantRcode   uint16
+		wantAnswers uint16
+	}{
+		/* */
+		{
+			name:        "01 - cached name",
+			request:     createDNSQuery("cached.localdomain", dnsTypeA),
+			wantRcode:   dnsRcodeNoError,
+			wantAnswers: 1,
+		},
+		{
+			name:      "02 - uncached name",
+			request:   createDNSQuery("uncached.localdomain", dnsTypeA),
+			wantRcode: dnsRcodeServFail,
+		},
+		{
+			name:      "03 - forwarded type",
+			request:   createDNSQuery("mx.localdomain", 15), // 15 = MX record
+			wantRcode: dnsRcodeServFail,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			responseCh := make(chan []byte, 1)
+			mockConn := &tMockPacketConn{respChan: responseCh}
+			mockClient := &tMockForwarderClient{mockForwarder: &tMockForwarder{}}
+
+			handleDNSRequestWithForwarder(mockConn, &tMockAddr{}, tc.request, resolver, "8.8.8.8:53", mockClient)
+
+			var resp []byte
+			select {
+			case resp = <-responseCh:
+			case <-time.After(100 * time.Millisecond):
+				t.Fatal("handleDNSRequestWithForwarder() sent no response")
+			}
+			if mockClient.forwardCalled {
+				t.Error("handleDNSRequestWithForwarder() forwarding = 'true', want 'false'")
+			}
+			if got := binary.BigEndian.Uint16(resp[2:4]) & 0x000F; got != tc.wantRcode {
+				t.Errorf("handleDNSRequestWithForwarder() rcode = '%d', want '%d'", got, tc.wantRcode)
+			}
+			if got := binary.BigEndian.Uint16(resp[6:8]); got != tc.wantAnswers {
+				t.Errorf("handleDNSRequestWithForwarder() answers = '%d', want '%d'", got, tc.wantAnswers)
+			}
+		})
+	}
+} // Test_handleDNSRequest_offline()
+
+func Test_handleDNSRequest_clientPolicy(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{Offline: true})
+	_ = resolver.Create(context.TODO(), "cached.localdomain",
+		[]net.IP{net.ParseIP("192.168.2.1")}, time.Minute)
+	resolver.SetClientPolicy(dnscache.TClientPolicyFunc(func(aClient net.IP) dnscache.TClientAction {
+		switch aClient.String() {
+		case "192.0.2.66":
+			return dnscache.ClientDrop
+		case "192.0.2.99":
+			return dnscache.ClientRefuse
+		}
+		return dnscache.ClientAllow
+	}))
+
+	tests := []struct {
+		name      string
+		client    string
+		wantResp  bool
+		wantRcode uint16
+	}{
+		/* */
+		{"01 - allowed client", "192.0.2.1", true, dnsRcodeNoError},
+		{"02 - dropped client", "192.0.2.66", false, 0},
+		{"03 - refused client", "192.0.2.99", true, dnsRcodeRefused},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			responseCh := make(chan []byte, 1)
+			addr := &net.UDPAddr{IP: net.ParseIP(tc.client), Port: 5353}
+
+			handleDNSRequest(&tMockPacketConn{respChan: responseCh}, addr,
+				createDNSQuery("cached.localdomain", dnsTypeA), resolver)
+
+			select {
+			case resp := <-responseCh:
+				if !tc.wantResp {
+					t.Fatal("handleDNSRequest() sent a response, want none")
+				}
+				if got := binary.BigEndian.Uint16(resp[2:4]) & 0x000F; got != tc.wantRcode {
+					t.Errorf("handleDNSRequest() rcode = '%d', want '%d'", got, tc.wantRcode)
+				}
+			case <-time.After(100 * time.Millisecond):
+				if tc.wantResp {
+					t.Fatal("handleDNSRequest() sent no response")
+				}
+			}
+		})
+	}
+} // Test_handleDNSRequest_clientPolicy()
+
+// `startTruncatingUpstream()` starts a forwarder answering every UDP
+// request truncated and every TCP request with a large answer.
+func startTruncatingUpstream(t *testing.T) string {
+	t.Helper()
+
+	var (
+		err      error
+		listener net.Listener
+		udpConn  net.PacketConn
+	)
+	for range 8 { // the UDP port may be in use for TCP
+		if udpConn, err = net.ListenPacket("udp", "127.0.0.1:0"); nil != err {
+			t.Fatalf("ListenPacket() error = '%v'", err)
+		}
+		if listener, err = net.Listen("tcp", udpConn.LocalAddr().String()); nil == err {
+			break
+		}
+		udpConn.Close()
+	}
+	if nil != err {
+		t.Fatalf("Listen() error = '%v'", err)
+	}
+	t.Cleanup(func() {
+		udpConn.Close()
+		listener.Close()
+	})
+
+	respond := func(aRequest []byte, aFlags uint16, aPadding int) []byte {
+		response := append([]byte{}, aRequest...)
+		binary.BigEndian.PutUint16(response[2:4], dnsQR|aFlags)
+		return append(response, make([]byte, aPadding)...)
+	}
+	go func() {
+		buffer := make([]byte, 512)
+		for {
+			n, addr, err := udpConn.ReadFrom(buffer)
+			if nil != err {
+				return
+			}
+			_, _ = udpConn.WriteTo(respond(buffer[:n], dnsTC, 0), addr)
+		}
+	}()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if nil != err {
+				return
+			}
+			tcpConn := tTCPConn{conn}
+			buffer := make([]byte, 512)
+			if n, _, err := tcpConn.ReadFrom(buffer); nil == err {
+				_, _ = tcpConn.WriteTo(respond(buffer[:n], 0, 1024), nil)
+			}
+			conn.Close()
+		}
+	}()
+
+	return udpConn.LocalAddr().String()
+} // startTruncatingUpstream()
+
+func Test_tStdForwarder_ForwardDNSRequest(t *testing.T) {
+	upstream := startTruncatingUpstream(t)
+	request := workload.Query(0x1234, "large.example.com", dnsTypeA)
+
+	tests := []struct {
+		name          string
+		protocol      tForwardProtocol
+		wantTruncated bool
+	}{
+		/* */
+		{"01 - auto", forwardAuto, false},
+		{"02 - UDP only", forwardUDP, true},
+		{"03 - TCP only", forwardTCP, false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			forwarder := &tStdForwarder{protocol: tc.protocol}
+			got, err := forwarder.ForwardDNSRequest(ctx, upstream, request)
+			if nil != err {
+				t.Fatalf("ForwardDNSRequest() error = '%v'", err)
+			}
+			if 0x1234 != binary.BigEndian.Uint16(got[0:2]) {
+				t.Errorf("ForwardDNSRequest() ID = '%#x', want '%#x'",
+					binary.BigEndian.Uint16(got[0:2]), 0x1234)
+			}
+			truncated := 0 != binary.BigEndian.Uint16(got[2:4])&dnsTC
+			if truncated != tc.wantTruncated {
+				t.Errorf("ForwardDNSRequest() truncated = '%v', want '%v'",
+					truncated, tc.wantTruncated)
+			}
+			if !tc.wantTruncated && (512 >= len(got)) {
+				t.Errorf("ForwardDNSRequest() size = '%d', want > '512'", len(got))
+			}
+		})
+	}
+} // Test_tStdForwarder_ForwardDNSRequest()
+
+func Test_forwardRequest_udpSize(t *testing.T) {
+	upstream := startTruncatingUpstream(t)
+	request := workload.Query(0x1234, "large.example.com", dnsTypeTXT)
+
+	// The same request announcing the given payload size
+	ednsRequest := func(aSize uint16) []byte {
+		result := append([]byte{}, request...)
+		binary.BigEndian.PutUint16(result[10:12], 1)
+		result = append(result, 0) // root name
+		result = binary.BigEndian.AppendUint16(result, dnsTypeOPT)
+		result = binary.BigEndian.AppendUint16(result, aSize)
+		return append(result, make([]byte, 6)...) // TTL, RDLENGTH
+	}
+
+	tests := []struct {
+		name          string
+		request       []byte
+		tcp           bool
+		wantTruncated bool
+	}{
+		/* */
+		{"01 - UDP client without EDNS", request, false, true},
+		{"02 - UDP client with EDNS", ednsRequest(4096), false, false},
+		{"03 - TCP client", request, true, false},
+		{"04 - UDP client with small EDNS size", ednsRequest(512), false, true},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				conn net.PacketConn
+				got  []byte
+			)
+			responses := make(chan []byte, 1)
+			if tc.tcp {
+				client, server := net.Pipe()
+				defer client.Close()
+				defer server.Close()
+				conn = tTCPConn{server}
+				go func() {
+					buffer := make([]byte, 0xFFFF)
+					if n, _, err := (tTCPConn{client}).ReadFrom(buffer); nil == err {
+						responses <- buffer[:n]
+					}
+				}()
+			} else {
+				conn = &tMockPacketConn{respChan: responses}
+			}
+
+			forwardRequest(conn, &tMockAddr{}, tc.request,
+				0x1234, dnsRD, 1, upstream, &tStdForwarder{})
+			select {
+			case got = <-responses:
+			case <-time.After(time.Second):
+				t.Fatal("forwardRequest() sent no response")
+			}
+
+			if !validResponse(tc.request, got) {
+				t.Errorf("forwardRequest() sent '%v', want answer to request", got)
+			}
+			truncated := 0 != binary.BigEndian.Uint16(got[2:4])&dnsTC
+			if truncated != tc.wantTruncated {
+				t.Errorf("forwardRequest() truncated = '%v', want '%v'",
+					truncated, tc.wantTruncated)
+			}
+			if tc.wantTruncated && (512 < len(got)) {
+				t.Errorf("forwardRequest() size = '%d', want <= '512'", len(got))
+			}
+			if !tc.wantTruncated && (512 >= len(got)) {
+				t.Errorf("forwardRequest() size = '%d', want > '512'", len(got))
+			}
+
+			// The client's EDNS support is confirmed even if truncated
+			wantOPT := 0 < binary.BigEndian.Uint16(tc.request[10:12])
+			if gotOPT := (nil != optRecord(got, questionEnd(got))); gotOPT != wantOPT {
+				t.Errorf("forwardRequest() OPT = '%v', want '%v'", gotOPT, wantOPT)
+			}
+		})
+	}
+} // Test_forwardRequest_udpSize()
+
 func Test_startDNSserver(t *testing.T) {
 	// Create a test resolver
 	resolver := dnscache.New(0)
@@ -858,6 +1337,7 @@ func Test_startDNSserver(t *testing.T) {
 		address   string
 		port      int
 		forwarder string
+		listeners []tListenerConfig
 		wantErr   bool
 		setupFunc func()                                            // Optional setup function
 		checkFunc func(t *testing.T, address string, port int) bool // Optional validation function
@@ -912,6 +1392,14 @@ func Test_startDNSserver(t *testing.T) {
 				return true
 			},
 		},
+		{
+			name:      "05 - invalid listener",
+			resolver:  resolver,
+			address:   "127.0.0.1",
+			port:      5355,
+			listeners: []tListenerConfig{{Address: "127.0.0.1:5356", TLSCert: "cert.pem"}},
+			wantErr:   true,
+		},
 	}
 
 	for _, tc := range tests {
@@ -929,7 +1417,7 @@ func Test_startDNSserver(t *testing.T) {
 			go func() {
 				// Signal that we're about to start the server
 				close(serverStarted)
-				err = startDNSserver(tc.resolver, tc.address, tc.port, tc.forwarder)
+				err = startDNSserver(tc.resolver, tc.address, tc.port, tc.forwarder, tc.listeners)
 			}()
 
 			// Wait for server to start
@@ -963,4 +1451,121 @@ func Test_startDNSserver(t *testing.T) {
 	}
 } // Test_startDNSserver()
 
+// `benchResolver()` creates a resolver prepared for the given workload.
+//
+// Since the misses would go to the network, the server benchmarks
+// only use cached and blocked names.
+func benchResolver(b *testing.B, aOptions workload.TOptions) (*dnscache.TResolver, *workload.TWorkload) {
+	b.Helper()
+
+	aOptions.HitRatio, aOptions.Seed = 1, 1
+	w, err := workload.New(aOptions)
+	if nil != err {
+		b.Fatalf("workload.New() error = '%v'", err)
+	}
+
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: b.TempDir()})
+	b.Cleanup(func() { resolver.StopExpire() })
+	ip := []net.IP{net.ParseIP("192.0.2.2")}
+	for _, name := range w.CachedNames() {
+		resolver.ICacheList.Create(context.TODO(), name, ip, time.Hour)
+	}
+	if err := resolver.AddDeny(workload.BlockedPattern); nil != err {
+		b.Fatalf("AddDeny() error = '%v'", err)
+	}
+
+	return resolver, w
+} // benchResolver()
+
+func Benchmark_handleDNSRequest(b *testing.B) {
+	benchmarks := []struct {
+		name    string
+		blocked float64
+	}{
+		/* */
+		{"01 - cached", 0},
+		{"02 - 20% blocked", 0.2},
+		/* */
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			resolver, w := benchResolver(b, workload.TOptions{Blocked: bm.blocked})
+			conn := &tMockPacketConn{
+				writeTo: func(aBuf []byte, aAddr net.Addr) (int, error) {
+					return len(aBuf), nil
+				},
+			}
+			addr := &tMockAddr{}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for idx := range b.N {
+				handleDNSRequest(conn, addr,
+					workload.Query(uint16(idx), w.Next(), workload.TypeA), resolver) //#nosec G115
+			}
+		})
+	}
+} // Benchmark_handleDNSRequest()
+
+func Fuzz_extractHostname(f *testing.F) {
+	f.Add(createDNSQuery("www.example.com", dnsTypeA))
+	f.Add(createDNSQuery("a", dnsTypeAAAA))
+	f.Add([]byte{0, 1, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 63, 'a', 0})
+	f.Add([]byte{0, 1, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0xC0, 12})
+
+	f.Fuzz(func(t *testing.T, aRequest []byte) {
+		hostname := extractFirstHostname(aRequest)
+		if "" == hostname {
+			return
+		}
+		// Every label costs at least its length byte
+		if len(hostname) >= len(aRequest)-12 {
+			t.Errorf("extractFirstHostname() = %q, longer than the question", hostname)
+		}
+	})
+} // Fuzz_extractHostname()
+
+func Fuzz_shouldForwardRequest(f *testing.F) {
+	f.Add(createDNSQuery("www.example.com", dnsTypeA), uint16(1))
+	f.Add(createDNSQuery("www.example.com", 15), uint16(1)) // MX
+	f.Add(createDNSQuery("www.example.com", dnsTypeA), uint16(0xFFFF))
+	f.Add([]byte{0, 1, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 1}, uint16(2))
+
+	f.Fuzz(func(t *testing.T, aRequest []byte, aQDCount uint16) {
+		if shouldForwardRequest(aRequest, aQDCount, "") {
+			t.Errorf("shouldForwardRequest() = 'true' without forwarder")
+		}
+		_ = shouldForwardRequest(aRequest, aQDCount, "192.0.2.1:53")
+	})
+} // Fuzz_shouldForwardRequest()
+
+func Fuzz_tTCPConn_ReadFrom(f *testing.F) {
+	f.Add([]byte{0, 3, 'a', 'b', 'c'})
+	f.Add([]byte{0xFF, 0xFF, 1})
+	f.Add([]byte{0})
+
+	f.Fuzz(func(t *testing.T, aStream []byte) {
+		client, server := net.Pipe()
+		defer server.Close()
+		go func() {
+			_, _ = client.Write(aStream)
+			_ = client.Close()
+		}()
+
+		conn := tTCPConn{server}
+		buffer := make([]byte, 512)
+		for read := 0; read < len(aStream); {
+			n, _, err := conn.ReadFrom(buffer)
+			if nil != err {
+				return
+			}
+			if n > len(buffer) {
+				t.Fatalf("ReadFrom() = '%d', want max. '%d'", n, len(buffer))
+			}
+			read += 2 + n
+		}
+	})
+} // Fuzz_tTCPConn_ReadFrom()
+
 /* _EoF_ */
@@ -7,12 +7,16 @@ Copyright © 2025  M.Watermann, 10247 Berlin, Germany
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net"
 	"os"
 	"runtime"
+	"time"
 
 	"github.com/mwat56/dnscache"
+	"github.com/mwat56/dnscache/cache"
 	"github.com/rivo/tview"
 )
 
@@ -118,8 +122,8 @@ func main() {
 	cmdLineConf := parseCmdLineArgs(os.Args[1:])
 
 	// Load configuration file
-	config, err := loadConfiguration(cmdLineConf.ConfigPathName)
-	if nil != err {
+	config, loadErr := loadConfiguration(cmdLineConf.ConfigPathName)
+	if nil != loadErr {
 		// Use default values if config file doesn't exist
 		config = tConfiguration{
 			DataDir:         os.TempDir(),
@@ -137,9 +141,156 @@ func main() {
 	if 0 != cmdLineConf.Port {
 		config.Port = cmdLineConf.Port
 	}
+	// Containers are configured by their environment
+	if cmdLineConf.ContainerMode {
+		if err := applyEnvironment(&config, os.LookupEnv); nil != err {
+			fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+			os.Exit(1)
+		}
+		loadErr = nil // the configuration file is optional
+	}
+
+	// Run the given subcommand (if it's not the server itself)
+	command, asJSON := jsonOption(cmdLineConf.Command)
+	cmd, cmdArgs, err := findCommand(command)
+	if nil != err {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		printCommands(os.Stderr)
+		os.Exit(2)
+	}
+	if nil != cmd.run {
+		if nil != loadErr {
+			fmt.Fprintf(os.Stderr, "%s: failed to load configuration %q: %v\n",
+				cmd.name, cmdLineConf.ConfigPathName, loadErr)
+			os.Exit(1)
+		}
+		if err := cmd.run(config, cmdArgs, tOutput{Writer: os.Stdout, json: asJSON}); nil != err {
+			if asJSON {
+				_ = json.NewEncoder(os.Stderr).Encode(map[string]string{
+					"command": cmd.name, "error": err.Error()})
+			} else {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", cmd.name, err)
+			}
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Containers don't keep any data and log to StdOut
+	if cmdLineConf.ContainerMode {
+		cleanup, err := setupContainer(&config)
+		if nil != err {
+			fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+			os.Exit(1)
+		}
+		defer cleanup()
+	}
+
+	// Set the configured log levels
+	if err := applyLogLevels(config); nil != err {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+	}
+
+	// Take over the groups, clients, and blocklists of a Pi-hole
+	if err := mergePiHole(&config); nil != err {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+	}
+
+	// Enable the query log if requested
+	if config.QueryLog {
+		privacy, err := newPrivacy(config)
+		if nil != err {
+			fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+			os.Exit(1)
+		}
+		gQueryLog.Store(newQueryLog(privacy))
+	}
+
+	// Enable zone transfers of the local zones if configured
+	zoneTransfer, err := newZoneTransfer(config)
+	if nil != err {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		os.Exit(1)
+	}
+	gZoneTransfer.Store(zoneTransfer)
+
+	// Answer the names of the configured zones authoritatively
+	authZones, err := newAuthZones(config)
+	if nil != err {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		os.Exit(1)
+	}
+	gAuthZones.Store(authZones)
+
+	// Answer `version.dnscache` and `stats.dnscache` if requested
+	selfID, err := newSelfID(config)
+	if nil != err {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		os.Exit(1)
+	}
+	gSelfID.Store(selfID)
+
+	// Limit the rate of diagnostic and ANY queries if configured
+	rateLimits, err := newRateLimits(config, nil)
+	if nil != err {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		os.Exit(1)
+	}
+	gRateLimits.Store(rateLimits)
+
+	// Restrict the server process once it's initialised if requested
+	gSandbox.Store(newSandbox(config, cmdLineConf.ConfigPathName))
+
+	// Answer only clients on the local link if requested
+	gLinkLocalOnly.Store(config.LinkLocalOnly)
+
+	// Spread the UDP requests over several sockets if requested
+	gUDPSockets.Store(int32(config.UDPSockets))
+
+	// Query the forwarder via UDP and/or TCP
+	protocol, err := forwardProtocol(config.ForwarderProtocol)
+	if nil != err {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		os.Exit(1)
+	}
+	gForwardProtocol.Store(uint32(protocol))
+
+	// Send the forwarded queries through the configured interfaces
+	outbound, err := newOutbound(config)
+	if nil != err {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		os.Exit(1)
+	}
+	gOutbound.Store(outbound)
+
+	// Strip, pass on, or synthesize the client subnet of forwarded queries
+	ecs, err := ecsPolicy(config.ECSPolicy)
+	if nil != err {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		os.Exit(1)
+	}
+	gECSPolicy.Store(uint32(ecs))
+
+	// Answer ANY queries locally and shrink forwarded responses
+	anyQueries, err := anyPolicy(config.AnyPolicy)
+	if nil != err {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		os.Exit(1)
+	}
+	gAnyPolicy.Store(uint32(anyQueries))
+	gMinimalResponses.Store(config.MinimalResponses)
+
+	// Truncate responses with too many answers or bytes
+	gMaxAnswers.Store(int32(config.MaxAnswers))
+	gMaxResponseSize.Store(int32(config.MaxResponseSize))
 
-	// Check for existing instance
-	if isInstanceRunning() {
+	// Answer `version.bind` and `hostname.bind` if configured
+	gChaosHostname.Store(&config.ChaosHostname)
+	gChaosVersion.Store(&config.ChaosVersion)
+
+	// Check for existing instance (a container is isolated anyway,
+	// an upgrade replaces the running instance)
+	if !cmdLineConf.ContainerMode && !isUpgrade() && isInstanceRunning() {
 		if cmdLineConf.ConsoleMode {
 			// Connect to existing instance in remote control mode
 			connectToExistingInstance(config)
@@ -150,8 +301,8 @@ func main() {
 		}
 	}
 
-	// Run as daemon if requested (Linux only)
-	if cmdLineConf.DaemonMode && ("linux" == runtime.GOOS) {
+	// Run as daemon if requested (Linux only) unless replacing one
+	if cmdLineConf.DaemonMode && !isUpgrade() && ("linux" == runtime.GOOS) {
 		if err := runAsDaemon(); nil != err {
 			fmt.Printf("Failed to start daemon: %v\n", err)
 			os.Exit(1)
@@ -159,18 +310,148 @@ func main() {
 		return
 	}
 
+	// Get the top-level domains used to validate the blocklists
+	tldCtx, tldCancel := context.WithTimeout(context.Background(), time.Minute)
+	if err := dnscache.InitTLDs(tldCtx, tldOptions(config)); nil != err {
+		gServerLog.Warn("using the last known top-level domains", "error", err)
+	}
+	tldCancel()
+
+	policy, err := blockPolicy(config.BlockPolicy)
+	if nil != err {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+	}
+
+	rebind, err := rebindPolicy(config.RebindPolicy)
+	if nil != err {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+	}
+
+	validate, err := validation(config.Validation)
+	if nil != err {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+	}
+
+	prefer, err := precedence(config.Precedence)
+	if nil != err {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+	}
+
+	selection, err := upstreamSelection(config.UpstreamSelection)
+	if nil != err {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+	}
+
+	timeout, err := configDuration("queryTimeout", config.QueryTimeout)
+	if nil != err {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+	}
+	gQueryTimeout.Store(int64(timeout))
+
+	minTTL, maxTTL, ttlOverrides, err := ttlOptions(config)
+	if nil != err {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+	}
+	refreshJitter, refreshWindow, err := refreshOptions(config)
+	if nil != err {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+	}
+	download, err := downloadOptions(config)
+	if nil != err {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+	}
+	breaker, err := breakerOptions(config)
+	if nil != err {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+	}
+
+	// Keep the colder cache entries on disk if configured
+	var coldStore cache.IColdStore
+	if store, err := openColdStore(config); nil != err {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+	} else if nil != store {
+		coldStore = store
+		defer store.Close()
+	}
+
 	// Create myResolver with configuration
 	myResolver := dnscache.NewWithOptions(dnscache.TResolverOptions{
+		BlockedCIDRs:    config.BlockedCIDRs,
+		BlockPolicy:     policy,
+		MDNS:            config.MDNSBridge,
 		DNSservers:      config.DNSServers,
+		NeverCache:      config.NeverCache,
+		Offline:         config.Offline,
+		AuditMode:       config.AuditMode,
+		RebindExempt:    config.RebindExempt,
+		SearchDomains:   config.SearchDomains,
+		RebindPolicy:    rebind,
+		Validation:      validate,
+		Precedence:      prefer,
+		Selection:       selection,
+		BlockSubdomains: config.BlockSubdomains,
+		Rewrites:        config.Rewrites,
+		SafeSearch:      config.SafeSearch,
 		DataDir:         config.DataDir,
+		Download:        download,
+		Breaker:         breaker,
 		CacheSize:       config.CacheSize,
+		ColdStore:       coldStore,
+		MaxHotEntries:   config.MaxHotEntries,
 		RefreshInterval: config.RefreshInterval,
+		RefreshJitter:   refreshJitter,
+		RefreshWindow:   refreshWindow,
+		RefreshWorkers:  config.RefreshWorkers,
 		TTL:             config.TTL,
+		MinTTL:          minTTL,
+		MaxTTL:          maxTTL,
+		QueryTimeout:    queryTimeout(),
+		TTLOverrides:    ttlOverrides,
 	})
 
+	if err := applyLists(myResolver, config); nil != err {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+	}
+	if err := applyGroups(myResolver, config); nil != err {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+	}
+	if err := applyCategories(myResolver, config); nil != err {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+	}
+	if err := importPiHole(myResolver, config); nil != err {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+	}
+	if err := applyLeases(context.Background(), myResolver, config); nil != err {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+	}
+
+	// Share the cache with the other instances of a cluster
+	if err := startCacheSync(myResolver, config); nil != err {
+		fmt.Fprintf(os.Stderr, "Failed to synchronise the cache: %v\n", err)
+	}
+	defer myResolver.SetCacheSync(nil)
+
+	// Keep the metrics across restarts if configured
+	metricsFile, metricsInterval, err := metricsOptions(config)
+	if nil != err {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+	}
+	stopMetrics := startMetricsPersistence(myResolver, metricsFile,
+		metricsInterval, cmdLineConf.ResetStats)
+	defer stopMetrics()
+
+	// Start the admin API if configured
+	if "" != config.AdminAddress {
+		if _, err := startAdminServer(myResolver, config); nil != err {
+			fmt.Fprintf(os.Stderr, "Failed to start admin API: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	// Start DNS server if not in console mode
 	if !cmdLineConf.ConsoleMode {
-		if err := startDNSserver(myResolver, config.Address, config.Port, config.Forwarder); nil != err {
+		if err := startDNSserver(myResolver, config.Address, config.Port,
+			config.Forwarder, config.Listeners); nil != err {
 			fmt.Printf("Failed to start DNS server: %v\n", err)
 			os.Exit(1)
 		}
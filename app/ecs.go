@@ -0,0 +1,224 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"encoding/binary"
+	"net"
+	"sync/atomic"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tECSPolicy` is the handling of the EDNS Client Subnet option
+	// (RFC 7871) of forwarded queries.
+	//
+	// The A and AAAA queries answered from the cache are resolved
+	// without the option, i.e. their answers are the same for all
+	// clients; hence the cache doesn't need to be keyed by subnet.
+	tECSPolicy uint32
+)
+
+const (
+	// ECS policies
+	ecsForward    = tECSPolicy(0) // pass the client's option unchanged
+	ecsStrip      = tECSPolicy(1) // remove the option
+	ecsSynthesize = tECSPolicy(2) // replace it by the client's subnet
+
+	// `ecsPrefixV4` is the source prefix length of synthesized IPv4 subnets.
+	ecsPrefixV4 = 24
+
+	// `ecsPrefixV6` is the source prefix length of synthesized IPv6 subnets.
+	ecsPrefixV6 = 56
+
+	// EDNS constants
+	dnsTypeOPT    uint16 = 41   // OPT pseudo record
+	ednsOptionECS uint16 = 8    // Client Subnet option code
+	ednsUDPSize   uint16 = 1232 // advertised UDP payload size
+)
+
+var (
+	// `gECSPolicy` is the handling of the ECS option of forwarded queries.
+	gECSPolicy atomic.Uint32
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `applyECS()` applies the ECS policy to a query to forward.
+//
+// Malformed queries are returned unchanged.
+//
+// Parameters:
+//   - `aRequest`: The DNS query to forward.
+//   - `aClient`: The address of the query's client.
+//   - `aPolicy`: The ECS policy to apply.
+//
+// Returns:
+//   - `[]byte`: The query to forward.
+func applyECS(aRequest []byte, aClient net.IP, aPolicy tECSPolicy) []byte {
+	if (ecsForward == aPolicy) || (12 > len(aRequest)) {
+		return aRequest
+	}
+	var option []byte
+	if ecsSynthesize == aPolicy {
+		if option = ecsOption(aClient); nil == option {
+			aPolicy = ecsStrip // don't pass on a foreign subnet
+		}
+	}
+
+	// Skip the questions and all records before the OPT record
+	offset := 12
+	for range binary.BigEndian.Uint16(aRequest[4:6]) {
+		var ok bool
+		if offset, ok = skipName(aRequest, offset); !ok || (offset+4 > len(aRequest)) {
+			return aRequest
+		}
+		offset += 4 // type and class
+	}
+	records := int(binary.BigEndian.Uint16(aRequest[6:8])) +
+		int(binary.BigEndian.Uint16(aRequest[8:10])) +
+		int(binary.BigEndian.Uint16(aRequest[10:12]))
+	for range records {
+		var ok bool
+		if offset, ok = skipName(aRequest, offset); !ok || (offset+10 > len(aRequest)) {
+			return aRequest
+		}
+		rType := binary.BigEndian.Uint16(aRequest[offset : offset+2])
+		rdStart := offset + 10
+		rdEnd := rdStart + int(binary.BigEndian.Uint16(aRequest[offset+8:offset+10]))
+		if rdEnd > len(aRequest) {
+			return aRequest
+		}
+		if dnsTypeOPT != rType {
+			offset = rdEnd
+			continue
+		}
+
+		// Copy all options but the client's subnet
+		rdata, ok := filterOptions(aRequest[rdStart:rdEnd], ednsOptionECS)
+		if !ok {
+			return aRequest
+		}
+		rdata = append(rdata, option...)
+		if 0xFFFF < len(rdata) {
+			return aRequest
+		}
+		result := make([]byte, 0, len(aRequest)-(rdEnd-rdStart)+len(rdata))
+		result = append(result, aRequest[:rdStart-2]...)
+		result = binary.BigEndian.AppendUint16(result, uint16(len(rdata))) //#nosec G115
+		result = append(result, rdata...)
+
+		return append(result, aRequest[rdEnd:]...)
+	}
+	if (ecsSynthesize != aPolicy) || (offset != len(aRequest)) {
+		return aRequest
+	}
+
+	// Add an OPT record carrying the client's subnet
+	result := make([]byte, 0, len(aRequest)+11+len(option))
+	result = append(result, aRequest...)
+	binary.BigEndian.PutUint16(result[10:12], binary.BigEndian.Uint16(result[10:12])+1)
+	result = append(result, 0) // root name
+	result = binary.BigEndian.AppendUint16(result, dnsTypeOPT)
+	result = binary.BigEndian.AppendUint16(result, ednsUDPSize)
+	// extended RCODE, version, and flags
+	result = binary.BigEndian.AppendUint32(result, 0)
+	result = binary.BigEndian.AppendUint16(result, uint16(len(option))) //#nosec G115
+
+	return append(result, option...)
+} // applyECS()
+
+// `ecsOption()` creates the ECS option announcing a client's subnet.
+//
+// Parameters:
+//   - `aClient`: The client's address.
+//
+// Returns:
+//   - `[]byte`: The ECS option, `nil` if the address is invalid.
+func ecsOption(aClient net.IP) []byte {
+	var (
+		address []byte
+		family  uint16
+		prefix  int
+	)
+	if ip4 := aClient.To4(); nil != ip4 {
+		address, family, prefix = ip4.Mask(net.CIDRMask(ecsPrefixV4, 32)), 1, ecsPrefixV4
+	} else if ip6 := aClient.To16(); nil != ip6 {
+		address, family, prefix = ip6.Mask(net.CIDRMask(ecsPrefixV6, 128)), 2, ecsPrefixV6
+	} else {
+		return nil
+	}
+	address = address[:(prefix+7)/8]
+
+	result := binary.BigEndian.AppendUint16(nil, ednsOptionECS)
+	result = binary.BigEndian.AppendUint16(result, uint16(4+len(address))) //#nosec G115
+	result = binary.BigEndian.AppendUint16(result, family)
+	result = append(result, byte(prefix), 0) // source and scope prefix length
+
+	return append(result, address...)
+} // ecsOption()
+
+// `filterOptions()` returns the EDNS options without the given one.
+//
+// Parameters:
+//   - `aOptions`: The OPT record's data.
+//   - `aCode`: The code of the option to remove.
+//
+// Returns:
+//   - `[]byte`: The remaining options.
+//   - `bool`: `true` if the options are well-formed, `false` otherwise.
+func filterOptions(aOptions []byte, aCode uint16) ([]byte, bool) {
+	result := make([]byte, 0, len(aOptions))
+	for offset := 0; offset < len(aOptions); {
+		if offset+4 > len(aOptions) {
+			return nil, false
+		}
+		end := offset + 4 + int(binary.BigEndian.Uint16(aOptions[offset+2:offset+4]))
+		if end > len(aOptions) {
+			return nil, false
+		}
+		if aCode != binary.BigEndian.Uint16(aOptions[offset:offset+2]) {
+			result = append(result, aOptions[offset:end]...)
+		}
+		offset = end
+	}
+
+	return result, true
+} // filterOptions()
+
+// `skipName()` returns the offset behind an encoded domain name.
+//
+// Parameters:
+//   - `aMessage`: The DNS message.
+//   - `aOffset`: The offset of the name.
+//
+// Returns:
+//   - `int`: The offset behind the name.
+//   - `bool`: `true` if the name is well-formed, `false` otherwise.
+func skipName(aMessage []byte, aOffset int) (int, bool) {
+	for aOffset < len(aMessage) {
+		length := int(aMessage[aOffset])
+		switch {
+		case 0 == length:
+			return aOffset + 1, true
+		case 0xC0 == length&0xC0: // compression pointer
+			if aOffset+2 > len(aMessage) {
+				return 0, false
+			}
+			return aOffset + 2, true
+		case 0 != length&0xC0: // reserved label types
+			return 0, false
+		}
+		aOffset += 1 + length
+	}
+
+	return 0, false
+} // skipName()
+
+/* _EoF_ */
@@ -0,0 +1,146 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// `ecsQuery()` creates a query with the given EDNS options
+// (`nil` means no OPT record at all).
+func ecsQuery(t *testing.T, aOptions []dnsmessage.Option) []byte {
+	t.Helper()
+	msg := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: 4711, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{
+			Name:  dnsmessage.MustNewName("example.com."),
+			Type:  dnsmessage.TypeMX,
+			Class: dnsmessage.ClassINET,
+		}},
+	}
+	if nil != aOptions {
+		var opt dnsmessage.ResourceHeader
+		if err := opt.SetEDNS0(int(ednsUDPSize), dnsmessage.RCodeSuccess, false); nil != err {
+			t.Fatal(err)
+		}
+		msg.Additionals = []dnsmessage.Resource{{
+			Header: opt,
+			Body:   &dnsmessage.OPTResource{Options: aOptions},
+		}}
+	}
+	result, err := msg.Pack()
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	return result
+} // ecsQuery()
+
+// `ecsOptions()` returns the EDNS options of a query
+// (`nil` if it has no OPT record).
+func ecsOptions(t *testing.T, aQuery []byte) []dnsmessage.Option {
+	t.Helper()
+	var msg dnsmessage.Message
+	if err := msg.Unpack(aQuery); nil != err {
+		t.Fatalf("Unpack() error = '%v'", err)
+	}
+	for _, rr := range msg.Additionals {
+		if opt, ok := rr.Body.(*dnsmessage.OPTResource); ok {
+			return append([]dnsmessage.Option{}, opt.Options...)
+		}
+	}
+
+	return nil
+} // ecsOptions()
+
+func Test_applyECS(t *testing.T) {
+	cookie := dnsmessage.Option{Code: 10, Data: []byte{1, 2, 3, 4, 5, 6, 7, 8}}
+	foreign := dnsmessage.Option{Code: ednsOptionECS,
+		Data: []byte{0, 1, 24, 0, 192, 0, 2}}
+	local4 := dnsmessage.Option{Code: ednsOptionECS,
+		Data: []byte{0, 1, 24, 0, 10, 1, 2}}
+	local6 := dnsmessage.Option{Code: ednsOptionECS,
+		Data: []byte{0, 2, 56, 0, 0x20, 0x01, 0x0d, 0xb8, 0x12, 0x34, 0x56}}
+	client4 := net.ParseIP("10.1.2.3")
+	client6 := net.ParseIP("2001:db8:1234:5678::1")
+
+	tests := []struct {
+		name    string
+		options []dnsmessage.Option
+		client  net.IP
+		policy  tECSPolicy
+		want    []dnsmessage.Option
+	}{
+		/* */
+		{"01 - forward", []dnsmessage.Option{foreign}, client4, ecsForward,
+			[]dnsmessage.Option{foreign}},
+		{"02 - strip", []dnsmessage.Option{cookie, foreign}, client4, ecsStrip,
+			[]dnsmessage.Option{cookie}},
+		{"03 - strip without OPT", nil, client4, ecsStrip, nil},
+		{"04 - synthesize IPv4", []dnsmessage.Option{foreign, cookie}, client4, ecsSynthesize,
+			[]dnsmessage.Option{cookie, local4}},
+		{"05 - synthesize IPv6", []dnsmessage.Option{}, client6, ecsSynthesize,
+			[]dnsmessage.Option{local6}},
+		{"06 - synthesize without OPT", nil, client4, ecsSynthesize,
+			[]dnsmessage.Option{local4}},
+		{"07 - synthesize without client", []dnsmessage.Option{foreign}, nil, ecsSynthesize,
+			[]dnsmessage.Option{}},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			query := ecsQuery(t, tc.options)
+			got := ecsOptions(t, applyECS(query, tc.client, tc.policy))
+			if (nil == got) != (nil == tc.want) {
+				t.Fatalf("applyECS() = '%v', want '%v'", got, tc.want)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("applyECS() = '%v', want '%v'", got, tc.want)
+			}
+			for idx, opt := range got {
+				if (opt.Code != tc.want[idx].Code) ||
+					!bytes.Equal(opt.Data, tc.want[idx].Data) {
+					t.Errorf("applyECS()[%d] = '%v', want '%v'",
+						idx, opt, tc.want[idx])
+				}
+			}
+		})
+	}
+} // Test_applyECS()
+
+func Test_applyECS_malformed(t *testing.T) {
+	query := ecsQuery(t, []dnsmessage.Option{{Code: ednsOptionECS,
+		Data: []byte{0, 1, 24, 0, 192, 0, 2}}})
+
+	tests := []struct {
+		name    string
+		request []byte
+	}{
+		/* */
+		{"01 - empty", nil},
+		{"02 - header only", query[:12]},
+		{"03 - truncated", query[:len(query)-3]},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := applyECS(tc.request, net.ParseIP("10.1.2.3"), ecsStrip); !bytes.Equal(got, tc.request) {
+				t.Errorf("applyECS() = '%v', want '%v'", got, tc.request)
+			}
+		})
+	}
+} // Test_applyECS_malformed()
+
+/* _EoF_ */
@@ -0,0 +1,216 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tReadyState` is the admin API's answer to a readiness probe.
+	tReadyState struct {
+		Ready    bool   `json:"ready"`
+		Listener string `json:"listener"`
+		Upstream string `json:"upstream"`
+	}
+)
+
+const (
+	// `healthTimeout` is the time a readiness check may take.
+	healthTimeout = 2 * time.Second
+
+	// `healthOK` is the state reported for a successful check.
+	healthOK = "ok"
+)
+
+var (
+	// `errNotRunning` is reported while the DNS server isn't running.
+	errNotRunning = errors.New("DNS server not running")
+
+	// `gDNSRunning` tells whether the DNS server's listener loop is running.
+	gDNSRunning atomic.Bool
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `checkListener()` sends a loopback query to the running DNS server.
+//
+// The query consists of a header without questions which the server
+// answers right away (without any lookup), hence the check neither
+// depends on nor changes the cache.
+//
+// Parameters:
+//   - `aCtx`: The context limiting the check's duration.
+//   - `aAddress`: The `host:port` address of the DNS server.
+//
+// Returns:
+//   - `error`: `nil` if the server answered, the error otherwise.
+func checkListener(aCtx context.Context, aAddress string) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(aCtx, "udp", aAddress)
+	if nil != err {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := aCtx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	id := uint16(time.Now().UnixNano()) //#nosec G115 - any value will do
+	query := make([]byte, 12)
+	binary.BigEndian.PutUint16(query[0:2], id)
+	if _, err = conn.Write(query); nil != err {
+		return err
+	}
+
+	answer := make([]byte, 512)
+	n, err := conn.Read(answer)
+	if nil != err {
+		return err
+	}
+	if (12 > n) || (id != binary.BigEndian.Uint16(answer[0:2])) ||
+		(0 == binary.BigEndian.Uint16(answer[2:4])&dnsQR) {
+		return errors.New("invalid answer")
+	}
+
+	return nil
+} // checkListener()
+
+// `checkUpstream()` checks whether at least one of the upstream DNS
+// servers answers a query for the root zone's name servers.
+//
+// Parameters:
+//   - `aCtx`: The context limiting the check's duration.
+//   - `aServers`: The `host[:port]` addresses of the upstream servers.
+//
+// Returns:
+//   - `error`: `nil` if an upstream server answered, the error otherwise.
+func checkUpstream(aCtx context.Context, aServers []string) error {
+	query := make([]byte, 12, 17)
+	binary.BigEndian.PutUint16(query[2:4], dnsRD)
+	binary.BigEndian.PutUint16(query[4:6], 1) // QDCount
+	query = append(query, 0)                  // root name
+	query = binary.BigEndian.AppendUint16(query, dnsTypeNS)
+	query = binary.BigEndian.AppendUint16(query, dnsClassIN)
+
+	var (
+		forwarder tStdForwarder
+		errs      []error
+	)
+	for _, server := range aServers {
+		if _, _, err := net.SplitHostPort(server); nil != err {
+			server = net.JoinHostPort(server, "53")
+		}
+		if _, err := forwarder.ForwardDNSRequest(aCtx, server, query); nil != err {
+			errs = append(errs, err)
+			continue
+		}
+		return nil
+	}
+
+	return errors.Join(errs...)
+} // checkUpstream()
+
+// `dnsServerAddress()` returns the address to send loopback queries to.
+//
+// Parameters:
+//   - `aConfig`: The configuration providing the server's address.
+//
+// Returns:
+//   - `string`: The `host:port` address of the DNS server.
+func dnsServerAddress(aConfig tConfiguration) string {
+	host := aConfig.Address
+	if ip := net.ParseIP(host); ("" == host) || ((nil != ip) && ip.IsUnspecified()) {
+		host = "127.0.0.1"
+	}
+
+	return net.JoinHostPort(host, strconv.Itoa(aConfig.Port))
+} // dnsServerAddress()
+
+// `healthState()` converts a check's result to its reported state.
+//
+// Parameters:
+//   - `aErr`: The check's result.
+//
+// Returns:
+//   - `string`: Either `healthOK` or the error message.
+func healthState(aErr error) string {
+	if nil == aErr {
+		return healthOK
+	}
+
+	return aErr.Error()
+} // healthState()
+
+// ---------------------------------------------------------------------------
+// `tAdminServer` methods:
+
+// `handleHealthz()` answers a liveness probe.
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+func (as *tAdminServer) handleHealthz(aWriter http.ResponseWriter, aRequest *http.Request) {
+	if !gDNSRunning.Load() {
+		writeError(aWriter, http.StatusServiceUnavailable, errNotRunning)
+		return
+	}
+
+	writeJSON(aWriter, http.StatusOK, map[string]string{"status": healthOK})
+} // handleHealthz()
+
+// `handleReadyz()` answers a readiness probe by querying the running
+// DNS server and checking the upstream servers' reachability.
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+func (as *tAdminServer) handleReadyz(aWriter http.ResponseWriter, aRequest *http.Request) {
+	ctx, cancel := context.WithTimeout(aRequest.Context(), healthTimeout)
+	defer cancel()
+
+	upstream := as.config.DNSServers
+	if "" != as.config.Forwarder {
+		upstream = append(upstream[:len(upstream):len(upstream)], as.config.Forwarder)
+	}
+
+	var listenerErr, upstreamErr error
+	if !gDNSRunning.Load() {
+		listenerErr = errNotRunning
+	} else {
+		listenerErr = checkListener(ctx, dnsServerAddress(as.config))
+	}
+	if 0 < len(upstream) {
+		upstreamErr = checkUpstream(ctx, upstream)
+	} // else the system's resolver is used
+
+	state := tReadyState{
+		Ready:    (nil == listenerErr) && (nil == upstreamErr),
+		Listener: healthState(listenerErr),
+		Upstream: healthState(upstreamErr),
+	}
+	if !state.Ready {
+		gAdminLog.Warn("readiness check failed",
+			"listener", state.Listener, "upstream", state.Upstream)
+		writeJSON(aWriter, http.StatusServiceUnavailable, state)
+		return
+	}
+
+	writeJSON(aWriter, http.StatusOK, state)
+} // handleReadyz()
+
+/* _EoF_ */
@@ -0,0 +1,170 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/mwat56/dnscache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// `startEchoServer()` starts a UDP server answering every request
+// by sending it back with the QR bit set.
+func startEchoServer(t *testing.T) *net.UDPAddr {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatalf("ListenPacket() error = '%v'", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	go func() {
+		buffer := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buffer)
+			if nil != err {
+				return
+			}
+			if 12 > n {
+				continue
+			}
+			flags := binary.BigEndian.Uint16(buffer[2:4])
+			binary.BigEndian.PutUint16(buffer[2:4], flags|dnsQR)
+			_, _ = conn.WriteTo(buffer[:n], addr)
+		}
+	}()
+
+	return conn.LocalAddr().(*net.UDPAddr)
+} // startEchoServer()
+
+// `closedUDPAddress()` returns a local UDP address nobody listens on.
+func closedUDPAddress(t *testing.T) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatalf("ListenPacket() error = '%v'", err)
+	}
+	result := conn.LocalAddr().String()
+	_ = conn.Close()
+
+	return result
+} // closedUDPAddress()
+
+func Test_dnsServerAddress(t *testing.T) {
+	tests := []struct {
+		name   string
+		config tConfiguration
+		want   string
+	}{
+		/* */
+		{"01 - all addresses", tConfiguration{Port: 53}, "127.0.0.1:53"},
+		{"02 - unspecified IPv4", tConfiguration{Address: "0.0.0.0", Port: 5353}, "127.0.0.1:5353"},
+		{"03 - unspecified IPv6", tConfiguration{Address: "::", Port: 53}, "127.0.0.1:53"},
+		{"04 - given address", tConfiguration{Address: "192.0.2.1", Port: 53}, "192.0.2.1:53"},
+		{"05 - IPv6 address", tConfiguration{Address: "::1", Port: 53}, "[::1]:53"},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := dnsServerAddress(tc.config); got != tc.want {
+				t.Errorf("dnsServerAddress() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_dnsServerAddress()
+
+func Test_tAdminServer_health(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	echo := startEchoServer(t)
+	closed := closedUDPAddress(t)
+	defer gDNSRunning.Store(false)
+
+	tests := []struct {
+		name       string
+		path       string
+		running    bool
+		config     tConfiguration
+		wantStatus int
+	}{
+		/* */
+		{
+			name:       "01 - liveness, not running",
+			path:       "/healthz",
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "02 - liveness, running",
+			path:       "/healthz",
+			running:    true,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "03 - readiness, not running",
+			path:       "/readyz",
+			config:     tConfiguration{Address: "127.0.0.1", Port: echo.Port},
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "04 - readiness, system resolver",
+			path:       "/readyz",
+			running:    true,
+			config:     tConfiguration{Address: "127.0.0.1", Port: echo.Port},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:    "05 - readiness, upstream reachable",
+			path:    "/readyz",
+			running: true,
+			config: tConfiguration{Address: "127.0.0.1", Port: echo.Port,
+				DNSServers: []string{closed, echo.String()}},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:    "06 - readiness, upstream unreachable",
+			path:    "/readyz",
+			running: true,
+			config: tConfiguration{Address: "127.0.0.1", Port: echo.Port,
+				Forwarder: closed},
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gDNSRunning.Store(tc.running)
+			// The probes don't require authentication
+			tc.config.AdminTokens = []string{"secret"}
+			as := newAdminServer(resolver, tc.config)
+
+			status, body := adminRequest(as, http.MethodGet, tc.path, nil)
+			if status != tc.wantStatus {
+				t.Fatalf("%s status = '%d', want '%d' (%s)", tc.path, status, tc.wantStatus, body)
+			}
+			if "/readyz" != tc.path {
+				return
+			}
+			var state tReadyState
+			if err := json.Unmarshal([]byte(body), &state); nil != err {
+				t.Fatalf("Unmarshal() error = '%v'", err)
+			}
+			if state.Ready != (http.StatusOK == tc.wantStatus) {
+				t.Errorf("%s ready = '%v', want '%v'", tc.path, state.Ready, !state.Ready)
+			}
+		})
+	}
+} // Test_tAdminServer_health()
+
+/* _EoF_ */
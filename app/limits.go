@@ -0,0 +1,188 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"encoding/binary"
+	"net"
+	"sync/atomic"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+var (
+	// `gMaxAnswers` is the max. number of answer records per
+	// response (`0` for no limit).
+	gMaxAnswers atomic.Int32
+
+	// `gMaxResponseSize` is the max. size of a response in bytes
+	// (`0` for no limit).
+	gMaxResponseSize atomic.Int32
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `limitResponse()` truncates a response exceeding the configured
+// answer count or size (see `truncateResponse()`).
+//
+// Parameters:
+//   - `aResponse`: The DNS response to limit.
+//
+// Returns:
+//   - `[]byte`: The (possibly) truncated response.
+func limitResponse(aResponse []byte) []byte {
+	return truncateResponse(aResponse,
+		int(gMaxAnswers.Load()), int(gMaxResponseSize.Load()))
+} // limitResponse()
+
+// `limitUDPResponse()` truncates a response exceeding the UDP payload
+// size accepted by the client (see `udpPayloadSize()`).
+//
+// Responses sent over TCP are returned unchanged.
+//
+// Parameters:
+//   - `aConn`: The connection to write the response to.
+//   - `aRequest`: The DNS request message answered.
+//   - `aResponse`: The DNS response to limit.
+//
+// Returns:
+//   - `[]byte`: The (possibly) truncated response.
+func limitUDPResponse(aConn net.PacketConn, aRequest, aResponse []byte) []byte {
+	if _, ok := aConn.(tTCPConn); ok {
+		return aResponse
+	}
+
+	return truncateResponse(aResponse, 0, udpPayloadSize(aRequest))
+} // limitUDPResponse()
+
+// `truncateResponse()` truncates a response exceeding the given
+// answer count or size.
+//
+// The truncated response keeps the questions and as many (complete)
+// answer records as allowed, drops the authority and additional
+// records except for an EDNS OPT record (RFC 6891), and has the TC
+// bit set so the client may retry via TCP.
+//
+// Parameters:
+//   - `aResponse`: The DNS response to limit.
+//   - `aMaxAnswers`: The max. number of answers (`0` for no limit).
+//   - `aMaxSize`: The max. size in bytes (`0` for no limit).
+//
+// Returns:
+//   - `[]byte`: The (possibly) truncated response.
+func truncateResponse(aResponse []byte, aMaxAnswers, aMaxSize int) []byte {
+	if (12 > len(aResponse)) || ((0 == aMaxAnswers) && (0 == aMaxSize)) {
+		return aResponse
+	}
+	anCount := int(binary.BigEndian.Uint16(aResponse[6:8]))
+	if ((0 == aMaxAnswers) || (aMaxAnswers >= anCount)) &&
+		((0 == aMaxSize) || (aMaxSize >= len(aResponse))) {
+		return aResponse
+	}
+
+	// Skip the questions (keeping only the header if they're
+	// malformed or too large)
+	offset := 12
+	qdCount := binary.BigEndian.Uint16(aResponse[4:6])
+	ok := true
+	for range qdCount {
+		if offset, ok = skipName(aResponse, offset); !ok || (offset+4 > len(aResponse)) {
+			ok = false
+			break
+		}
+		offset += 4 // type and class
+	}
+	// Keep the OPT record, reserving its size
+	var opt []byte
+	if ok {
+		opt = optRecord(aResponse, offset)
+	}
+	maxSize := aMaxSize
+	if 0 < maxSize {
+		maxSize = max(maxSize-len(opt), 12)
+	}
+	if !ok || ((0 < maxSize) && (maxSize < offset)) {
+		offset, qdCount, anCount, opt = 12, 0, 0, nil
+	}
+
+	// Keep the answers fitting into the limits
+	var answers uint16
+	for int(answers) < anCount {
+		if (0 < aMaxAnswers) && (aMaxAnswers <= int(answers)) {
+			break
+		}
+		end, _, ok := skipRecord(aResponse, offset)
+		if !ok || ((0 < maxSize) && (maxSize < end)) {
+			break
+		}
+		offset = end
+		answers++
+	}
+
+	result := append(append([]byte{}, aResponse[:offset]...), opt...)
+	binary.BigEndian.PutUint16(result[2:4], binary.BigEndian.Uint16(result[2:4])|dnsTC)
+	binary.BigEndian.PutUint16(result[4:6], qdCount)
+	binary.BigEndian.PutUint16(result[6:8], answers)
+	binary.BigEndian.PutUint16(result[8:10], 0)
+	binary.BigEndian.PutUint16(result[10:12], 0)
+	if 0 < len(opt) {
+		binary.BigEndian.PutUint16(result[10:12], 1)
+	}
+
+	return result
+} // truncateResponse()
+
+// `optRecord()` returns the EDNS OPT record of a message.
+//
+// Parameters:
+//   - `aMessage`: The DNS message to search.
+//   - `aOffset`: The offset following the message's questions.
+//
+// Returns:
+//   - `[]byte`: The OPT record, or `nil` if there's none.
+func optRecord(aMessage []byte, aOffset int) []byte {
+	others := int(binary.BigEndian.Uint16(aMessage[6:8])) +
+		int(binary.BigEndian.Uint16(aMessage[8:10]))
+	additional := int(binary.BigEndian.Uint16(aMessage[10:12]))
+
+	for idx := range others + additional {
+		end, rType, ok := skipRecord(aMessage, aOffset)
+		if !ok {
+			return nil
+		}
+		if (others <= idx) && (dnsTypeOPT == rType) {
+			return aMessage[aOffset:end]
+		}
+		aOffset = end
+	}
+
+	return nil
+} // optRecord()
+
+// `skipRecord()` returns the end of a resource record.
+//
+// Parameters:
+//   - `aMessage`: The DNS message containing the record.
+//   - `aOffset`: The offset of the record's owner name.
+//
+// Returns:
+//   - `rEnd`: The offset following the record.
+//   - `rType`: The record's type.
+//   - `rOK`: `false` if the record is malformed.
+func skipRecord(aMessage []byte, aOffset int) (rEnd int, rType uint16, rOK bool) {
+	end, ok := skipName(aMessage, aOffset)
+	if !ok || (end+10 > len(aMessage)) {
+		return
+	}
+	rType = binary.BigEndian.Uint16(aMessage[end : end+2])
+	rEnd = end + 10 + int(binary.BigEndian.Uint16(aMessage[end+8:end+10]))
+
+	return rEnd, rType, rEnd <= len(aMessage)
+} // skipRecord()
+
+/* _EoF_ */
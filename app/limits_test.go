@@ -0,0 +1,119 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_limitResponse(t *testing.T) {
+	name := dnsmessage.MustNewName("example.com.")
+	header := dnsmessage.ResourceHeader{Name: name, Class: dnsmessage.ClassINET, TTL: 300}
+	msg := dnsmessage.Message{
+		Header: dnsmessage.Header{ID: 4711, Response: true, RecursionDesired: true},
+		Questions: []dnsmessage.Question{{
+			Name:  name,
+			Type:  dnsmessage.TypeA,
+			Class: dnsmessage.ClassINET,
+		}},
+		Authorities: []dnsmessage.Resource{{
+			Header: header,
+			Body:   &dnsmessage.NSResource{NS: dnsmessage.MustNewName("ns.example.com.")},
+		}},
+	}
+	for i := range 100 {
+		msg.Answers = append(msg.Answers, dnsmessage.Resource{
+			Header: header,
+			Body:   &dnsmessage.AResource{A: [4]byte{192, 0, 2, byte(i)}},
+		})
+	}
+	response, err := msg.Pack()
+	if nil != err {
+		t.Fatal(err)
+	}
+	msg.Additionals = append(msg.Additionals, dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName("."),
+			Type: dnsmessage.TypeOPT, Class: 4096},
+		Body: &dnsmessage.OPTResource{},
+	})
+	ednsResponse, err := msg.Pack()
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer gMaxAnswers.Store(0)
+	defer gMaxResponseSize.Store(0)
+
+	tests := []struct {
+		name        string
+		maxAnswers  int32
+		maxSize     int32
+		response    []byte
+		wantAnswers uint16
+		wantSame    bool
+		wantOPT     bool
+	}{
+		/* */
+		{"01 - no limits", 0, 0, response, 100, true, false},
+		{"02 - below max. answers", 100, 0, response, 100, true, false},
+		{"03 - below max. size", 0, 4096, response, 100, true, false},
+		{"04 - max. answers", 10, 0, response, 10, false, false},
+		{"05 - max. size", 0, 512, response, 30, false, false},
+		{"06 - both limits", 10, 512, response, 10, false, false},
+		{"07 - malformed answers", 10, 0, response[:40], 0, false, false},
+		{"08 - short response", 10, 0, response[:8], 0, true, false},
+		{"09 - max. size keeping OPT", 0, 512, ednsResponse, 29, false, true},
+		{"10 - max. answers keeping OPT", 10, 0, ednsResponse, 10, false, true},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gMaxAnswers.Store(tc.maxAnswers)
+			gMaxResponseSize.Store(tc.maxSize)
+
+			got := limitResponse(tc.response)
+			if tc.wantSame {
+				if !bytes.Equal(got, tc.response) {
+					t.Errorf("limitResponse() changed the response")
+				}
+				return
+			}
+			if (0 < tc.maxSize) && (int(tc.maxSize) < len(got)) {
+				t.Errorf("limitResponse() length = '%d', want <= '%d'", len(got), tc.maxSize)
+			}
+			if 0 == binary.BigEndian.Uint16(got[2:4])&dnsTC {
+				t.Error("limitResponse() TC bit not set")
+			}
+			var result dnsmessage.Message
+			if err := result.Unpack(got); nil != err {
+				t.Fatalf("Unpack() error = '%v'", err)
+			}
+			if got := uint16(len(result.Answers)); got != tc.wantAnswers {
+				t.Errorf("Answers = '%d', want '%d'", got, tc.wantAnswers)
+			}
+			wantAdditionals := 0
+			if tc.wantOPT {
+				wantAdditionals = 1
+			}
+			if (0 != len(result.Authorities)) || (wantAdditionals != len(result.Additionals)) {
+				t.Errorf("Authorities/Additionals = '%d/%d', want '0/%d'",
+					len(result.Authorities), len(result.Additionals), wantAdditionals)
+			}
+			if tc.wantOPT && (dnsmessage.TypeOPT != result.Additionals[0].Header.Type) {
+				t.Errorf("Additionals[0] = '%v', want OPT record", result.Additionals[0])
+			}
+		})
+	}
+} // Test_limitResponse()
+
+/* _EoF_ */
@@ -0,0 +1,389 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/mwat56/dnscache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `iUnfiltered` is implemented by the connections of listeners
+	// which answer requests without checking the allow/deny lists.
+	iUnfiltered interface {
+		unfiltered()
+	}
+
+	// `tUnfilteredConn` marks a UDP connection as unfiltered.
+	tUnfilteredConn struct {
+		net.PacketConn
+	}
+
+	// `tUnfilteredListener` marks all TCP connections accepted
+	// by a listener as unfiltered.
+	tUnfilteredListener struct {
+		net.Listener
+	}
+
+	// `tUnfilteredStream` marks a TCP connection as unfiltered.
+	tUnfilteredStream struct {
+		net.Conn
+	}
+)
+
+var (
+	// `gLinkLocalOnly` tells whether only link-local (and loopback)
+	// clients are answered.
+	gLinkLocalOnly atomic.Bool
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `checkListenHost()` checks the host part of an address to listen on.
+//
+// A link-local IPv6 address can only be bound together with its
+// scope, i.e. the network interface (e.g. `fe80::1%eth0`).
+//
+// Parameters:
+//   - `aHost`: The host (usually an IP address) to check.
+//
+// Returns:
+//   - `error`: `nil` if the host is valid, the error otherwise.
+func checkListenHost(aHost string) error {
+	addr, err := netip.ParseAddr(strings.Trim(aHost, "[]"))
+	if nil != err {
+		return nil // a hostname or empty (i.e. all addresses)
+	}
+	if addr.Is6() && addr.IsLinkLocalUnicast() && ("" == addr.Zone()) {
+		return fmt.Errorf("link-local address %q requires a zone (e.g. %s%%eth0)",
+			aHost, addr)
+	}
+
+	return nil
+} // checkListenHost()
+
+// `checkListeners()` checks the additional listeners of a configuration.
+//
+// Parameters:
+//   - `aConfig`: The configuration to check.
+//
+// Returns:
+//   - `error`: `nil` if all listeners are valid, the joined errors otherwise.
+func checkListeners(aConfig tConfiguration) error {
+	var (
+		errs []error
+		seen []string
+	)
+
+	for _, listener := range aConfig.Listeners {
+		host, port, err := net.SplitHostPort(listener.Address)
+		if nil != err {
+			errs = append(errs, fmt.Errorf("invalid listener address %q: %w", listener.Address, err))
+			continue
+		}
+		if num, err := strconv.Atoi(port); (nil != err) || (0 >= num) || (65535 < num) {
+			errs = append(errs, fmt.Errorf("invalid listener port: %q", listener.Address))
+		}
+		if err := checkListenHost(host); nil != err {
+			errs = append(errs, err)
+		}
+		if slices.Contains(seen, listener.Address) {
+			errs = append(errs, fmt.Errorf("duplicate listener address: %q", listener.Address))
+		}
+		seen = append(seen, listener.Address)
+
+		if "" != listener.Forwarder {
+			if _, _, err := net.SplitHostPort(listener.Forwarder); nil != err {
+				errs = append(errs, fmt.Errorf("invalid forwarder %q of listener %q: %w",
+					listener.Forwarder, listener.Address, err))
+			}
+		}
+		if _, err := listenerTLSConfig(listener); nil != err {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+} // checkListeners()
+
+// `fetchFor()` resolves a hostname requested via the given connection.
+//
+// Requests received by an unfiltered listener are answered without
+// checking the allow/deny lists, all others by using the lists of
+// the client's group.
+//
+// Parameters:
+//   - `aConn`: The connection the request was received by.
+//   - `aResolver`: The DNS resolver to use for lookups.
+//   - `aClient`: The requesting client's IP address (may be `nil`).
+//   - `aHostname`: The hostname to resolve.
+//   - `aQType`: The query type to count (`0` means not to count it).
+//
+// Returns:
+//   - `[]net.IP`: List of IP addresses for the given hostname.
+//   - `error`: `nil` if the hostname was resolved successfully, the error otherwise.
+func fetchFor(aConn net.PacketConn, aResolver *dnscache.TResolver,
+	aClient net.IP, aHostname string, aQType uint16) ([]net.IP, error) {
+	if isUnfiltered(aConn) {
+		return aResolver.FetchUnfiltered(aHostname)
+	}
+
+	return aResolver.FetchType(aClient, aHostname, aQType)
+} // fetchFor()
+
+// `isUnfiltered()` checks whether a connection belongs to an
+// unfiltered listener.
+//
+// Parameters:
+//   - `aConn`: The connection to check.
+//
+// Returns:
+//   - `bool`: `true` if the lists are to be ignored, `false` otherwise.
+func isUnfiltered(aConn net.PacketConn) bool {
+	var conn any = aConn
+	if cc, ok := aConn.(*tCaptureConn); ok {
+		conn = cc.PacketConn
+	}
+	if tc, ok := conn.(tTCPConn); ok {
+		conn = tc.Conn
+	}
+	_, ok := conn.(iUnfiltered)
+
+	return ok
+} // isUnfiltered()
+
+// `isLinkLocalClient()` checks whether a client is allowed if only
+// link-local clients are to be answered.
+//
+// Parameters:
+//   - `aClient`: The client's IP address.
+//
+// Returns:
+//   - `bool`: `true` for link-local and loopback addresses, `false` otherwise.
+func isLinkLocalClient(aClient net.IP) bool {
+	return (nil != aClient) && (aClient.IsLinkLocalUnicast() || aClient.IsLoopback())
+} // isLinkLocalClient()
+
+// `listenNetwork()` returns the network to use for listening on
+// the given host.
+//
+// IPv4 addresses are bound via e.g. `udp4`, IPv6 addresses via
+// `udp6`, while an empty host or the IPv6 wildcard `::` result in
+// a dual-stack socket accepting both, IPv4 and IPv6 requests.
+//
+// Parameters:
+//   - `aProtocol`: The protocol to use (`udp` or `tcp`).
+//   - `aHost`: The host (usually an IP address) to listen on.
+//
+// Returns:
+//   - `string`: The network name to pass to e.g. `net.Listen()`.
+func listenNetwork(aProtocol, aHost string) string {
+	addr, err := netip.ParseAddr(strings.Trim(aHost, "[]"))
+	switch {
+	case nil != err:
+		return aProtocol // a hostname or empty (i.e. all addresses)
+	case addr.Is4() || addr.Is4In6():
+		return aProtocol + "4"
+	case addr.IsUnspecified():
+		return aProtocol // dual-stack wildcard
+	}
+
+	return aProtocol + "6"
+} // listenNetwork()
+
+// `listenerTLSConfig()` returns the TLS configuration of a
+// DNS-over-TLS listener.
+//
+// Parameters:
+//   - `aListener`: The listener's configuration.
+//
+// Returns:
+//   - `*tls.Config`: The TLS configuration (`nil` for plain DNS).
+//   - `error`: `nil` if the configuration is valid, the error otherwise.
+func listenerTLSConfig(aListener tListenerConfig) (*tls.Config, error) {
+	if ("" == aListener.TLSCert) && ("" == aListener.TLSKey) {
+		return nil, nil
+	}
+	if ("" == aListener.TLSCert) || ("" == aListener.TLSKey) {
+		return nil, fmt.Errorf("listener %q requires both, tlsCert and tlsKey",
+			aListener.Address)
+	}
+
+	cert, err := tls.LoadX509KeyPair(aListener.TLSCert, aListener.TLSKey)
+	if nil != err {
+		return nil, fmt.Errorf("failed to load TLS certificate of listener %q: %w",
+			aListener.Address, err)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+} // listenerTLSConfig()
+
+// `serveUDP()` answers DNS requests received via UDP until the
+// connection gets closed.
+//
+// Where available (see `serveUDPBatch()`) the requests are read and
+// answered in batches to reduce the number of syscalls.
+//
+// Parameters:
+//   - `aConn`: The UDP connection to read the requests from.
+//   - `aResolver`: The DNS resolver to use for lookups.
+//   - `aForwarder`: The DNS forwarder to use for non-A/AAAA requests (empty string means no forwarding).
+//   - `aForwarderClient`: The client to use for forwarding requests.
+func serveUDP(aConn net.PacketConn, aResolver *dnscache.TResolver,
+	aForwarder string, aForwarderClient iForwarderClient) {
+	if serveUDPBatch(aConn, aResolver, aForwarder, aForwarderClient) {
+		return // served by the platform's fast path
+	}
+
+	buffer := make([]byte, 512) // Standard DNS message size
+	for {
+		n, addr, err := aConn.ReadFrom(buffer)
+		if nil != err {
+			if errors.Is(err, net.ErrClosed) || errors.Is(err, os.ErrDeadlineExceeded) {
+				return // closed or handed over (see `stopReading()`)
+			}
+			gServerLog.Warn("error reading DNS request", "error", err)
+			continue
+		}
+		if dnscache.DebugEnabled(serverLogComponent) {
+			gServerLog.Debug("received DNS request",
+				"client", addr.String(), "size", n)
+		}
+
+		// The buffer is reused by the next request
+		go handleDNSRequestWithForwarder(aConn, addr, slices.Clone(buffer[:n]),
+			aResolver, aForwarder, aForwarderClient)
+	}
+} // serveUDP()
+
+// `startListener()` starts an additional DNS listener in the background.
+//
+// A listener with a TLS certificate serves DNS-over-TLS (TCP only),
+// all others serve plain DNS via UDP and TCP.
+//
+// Parameters:
+//   - `aResolver`: The DNS resolver to use for lookups.
+//   - `aListener`: The listener's configuration.
+//   - `aForwarder`: The default DNS forwarder (used if the listener has none).
+//   - `aForwarderClient`: The client to use for forwarding requests.
+//
+// Returns:
+//   - `[]io.Closer`: The connections to close when shutting down.
+//   - `error`: `nil` if the listener was started, the error otherwise.
+func startListener(aResolver *dnscache.TResolver, aListener tListenerConfig,
+	aForwarder string, aForwarderClient iForwarderClient) ([]io.Closer, error) {
+	forwarder := aListener.Forwarder
+	if "" == forwarder {
+		forwarder = aForwarder
+	}
+	tlsConfig, err := listenerTLSConfig(aListener)
+	if nil != err {
+		return nil, err
+	}
+	host, _, err := net.SplitHostPort(aListener.Address)
+	if nil != err {
+		return nil, fmt.Errorf("invalid listener address %q: %w", aListener.Address, err)
+	}
+
+	if nil != tlsConfig {
+		listener, err := listenTCP(listenNetwork("tcp", host), aListener.Address)
+		if nil != err {
+			return nil, fmt.Errorf("failed to start DNS-over-TLS listener: %w", err)
+		}
+		gServerLog.Info("starting DNS-over-TLS listener",
+			"address", aListener.Address, "unfiltered", aListener.Unfiltered)
+		go serveTCP(unfilteredListener(tls.NewListener(listener, tlsConfig), aListener.Unfiltered),
+			aResolver, forwarder, aForwarderClient)
+
+		return []io.Closer{listener}, nil
+	}
+
+	conns, err := listenUDP(listenNetwork("udp", host), aListener.Address)
+	if nil != err {
+		return nil, fmt.Errorf("failed to start DNS listener: %w", err)
+	}
+	result := make([]io.Closer, 0, len(conns)+1)
+	gServerLog.Info("starting DNS listener", "address", aListener.Address,
+		"unfiltered", aListener.Unfiltered, "sockets", len(conns))
+
+	for _, conn := range conns {
+		result = append(result, conn)
+		var packetConn net.PacketConn = conn
+		if aListener.Unfiltered {
+			packetConn = tUnfilteredConn{conn}
+		}
+		go serveUDP(packetConn, aResolver, forwarder, aForwarderClient)
+	}
+
+	tcpListener, err := listenTCP(listenNetwork("tcp", host), aListener.Address)
+	if nil != err {
+		gServerLog.Warn("failed to start TCP listener", "address", aListener.Address, "error", err)
+		return result, nil
+	}
+	go serveTCP(unfilteredListener(tcpListener, aListener.Unfiltered),
+		aResolver, forwarder, aForwarderClient)
+
+	return append(result, tcpListener), nil
+} // startListener()
+
+// `unfilteredListener()` marks a TCP listener as unfiltered if requested.
+//
+// Parameters:
+//   - `aListener`: The TCP listener to mark.
+//   - `aUnfiltered`: Whether the listener is to ignore the allow/deny lists.
+//
+// Returns:
+//   - `net.Listener`: The (possibly marked) listener.
+func unfilteredListener(aListener net.Listener, aUnfiltered bool) net.Listener {
+	if aUnfiltered {
+		return tUnfilteredListener{aListener}
+	}
+
+	return aListener
+} // unfilteredListener()
+
+// ---------------------------------------------------------------------------
+// `tUnfiltered*` methods:
+
+// `unfiltered()` implements the `iUnfiltered` interface.
+func (tUnfilteredConn) unfiltered() {}
+
+// `unfiltered()` implements the `iUnfiltered` interface.
+func (tUnfilteredStream) unfiltered() {}
+
+// `Accept()` waits for and returns the next (unfiltered) connection.
+//
+// Returns:
+//   - `net.Conn`: The accepted connection.
+//   - `error`: `nil` if a connection was accepted, the error otherwise.
+func (ul tUnfilteredListener) Accept() (net.Conn, error) {
+	conn, err := ul.Listener.Accept()
+	if nil != err {
+		return nil, err
+	}
+
+	return tUnfilteredStream{conn}, nil
+} // Accept()
+
+/* _EoF_ */
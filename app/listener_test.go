@@ -0,0 +1,288 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/mwat56/dnscache"
+	"github.com/mwat56/dnscache/internal/workload"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_checkListeners(t *testing.T) {
+	tests := []struct {
+		name      string
+		listeners []tListenerConfig
+		wantErr   bool
+	}{
+		/* */
+		{"01 - no listeners", nil, false},
+		{"02 - valid listeners", []tListenerConfig{
+			{Address: "127.0.0.1:53", Unfiltered: true},
+			{Address: "192.168.1.1:53", Forwarder: "192.0.2.1:53"},
+		}, false},
+		{"03 - missing port", []tListenerConfig{{Address: "127.0.0.1"}}, true},
+		{"04 - invalid port", []tListenerConfig{{Address: "127.0.0.1:65536"}}, true},
+		{"05 - duplicate address", []tListenerConfig{
+			{Address: "127.0.0.1:53"}, {Address: "127.0.0.1:53"},
+		}, true},
+		{"06 - invalid forwarder", []tListenerConfig{
+			{Address: "127.0.0.1:53", Forwarder: "192.0.2.1"},
+		}, true},
+		{"07 - TLS key missing", []tListenerConfig{
+			{Address: ":853", TLSCert: "cert.pem"},
+		}, true},
+		{"08 - TLS files missing", []tListenerConfig{
+			{Address: ":853", TLSCert: "/no/cert.pem", TLSKey: "/no/key.pem"},
+		}, true},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkListeners(tConfiguration{Listeners: tc.listeners})
+			if (nil != err) != tc.wantErr {
+				t.Errorf("checkListeners() error = '%v', wantErr '%v'", err, tc.wantErr)
+			}
+		})
+	}
+} // Test_checkListeners()
+
+func Test_checkListenHost(t *testing.T) {
+	tests := []struct {
+		name    string
+		host    string
+		wantErr bool
+	}{
+		/* */
+		{"01 - all addresses", "", false},
+		{"02 - hostname", "localhost", false},
+		{"03 - IPv4 address", "192.168.1.1", false},
+		{"04 - global IPv6 address", "2001:db8::1", false},
+		{"05 - link-local with zone", "fe80::1%eth0", false},
+		{"06 - link-local without zone", "fe80::1", true},
+		{"07 - bracketed link-local", "[fe80::1]", true},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := checkListenHost(tc.host); (nil != err) != tc.wantErr {
+				t.Errorf("checkListenHost() error = '%v', wantErr '%v'", err, tc.wantErr)
+			}
+		})
+	}
+} // Test_checkListenHost()
+
+func Test_isLinkLocalClient(t *testing.T) {
+	tests := []struct {
+		name   string
+		client net.IP
+		want   bool
+	}{
+		/* */
+		{"01 - nil", nil, false},
+		{"02 - IPv6 link-local", net.ParseIP("fe80::1"), true},
+		{"03 - IPv4 link-local", net.ParseIP("169.254.1.2"), true},
+		{"04 - loopback", net.ParseIP("::1"), true},
+		{"05 - private IPv4", net.ParseIP("192.168.1.10"), false},
+		{"06 - global IPv6", net.ParseIP("2001:db8::1"), false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isLinkLocalClient(tc.client); got != tc.want {
+				t.Errorf("isLinkLocalClient() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_isLinkLocalClient()
+
+func Test_handleDNSRequest_linkLocalOnly(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	resolver.ICacheList.Create(context.TODO(), "nas.lan",
+		[]net.IP{net.ParseIP("192.168.1.2")}, time.Hour)
+	gLinkLocalOnly.Store(true)
+	defer gLinkLocalOnly.Store(false)
+
+	tests := []struct {
+		name   string
+		client net.IP
+		want   bool
+	}{
+		/* */
+		{"01 - link-local client", net.ParseIP("fe80::1234"), true},
+		{"02 - loopback client", net.ParseIP("127.0.0.1"), true},
+		{"03 - remote client", net.ParseIP("192.168.1.10"), false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			answered := false
+			conn := &tMockPacketConn{
+				writeTo: func(aBuf []byte, aAddr net.Addr) (int, error) {
+					answered = true
+					return len(aBuf), nil
+				},
+			}
+			handleDNSRequest(conn, &net.UDPAddr{IP: tc.client, Port: 5353},
+				createDNSQuery("nas.lan", dnsTypeA), resolver)
+			if answered != tc.want {
+				t.Errorf("handleDNSRequest() answered = '%v', want '%v'", answered, tc.want)
+			}
+		})
+	}
+} // Test_handleDNSRequest_linkLocalOnly()
+
+func Test_isUnfiltered(t *testing.T) {
+	tests := []struct {
+		name string
+		conn net.PacketConn
+		want bool
+	}{
+		/* */
+		{"01 - plain UDP", &tMockPacketConn{}, false},
+		{"02 - unfiltered UDP", tUnfilteredConn{&tMockPacketConn{}}, true},
+		{"03 - plain TCP", tTCPConn{&net.TCPConn{}}, false},
+		{"04 - unfiltered TCP", tTCPConn{tUnfilteredStream{&net.TCPConn{}}}, true},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isUnfiltered(tc.conn); got != tc.want {
+				t.Errorf("isUnfiltered() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_isUnfiltered()
+
+func Test_listenNetwork(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol string
+		host     string
+		want     string
+	}{
+		/* */
+		{"01 - all addresses", "udp", "", "udp"},
+		{"02 - IPv6 wildcard", "tcp", "::", "tcp"},
+		{"03 - IPv4 wildcard", "udp", "0.0.0.0", "udp4"},
+		{"04 - IPv4 address", "tcp", "192.168.1.1", "tcp4"},
+		{"05 - IPv6 address", "udp", "2001:db8::1", "udp6"},
+		{"06 - bracketed IPv6", "udp", "[::1]", "udp6"},
+		{"07 - link-local with zone", "tcp", "fe80::1%eth0", "tcp6"},
+		{"08 - hostname", "udp", "localhost", "udp"},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := listenNetwork(tc.protocol, tc.host); got != tc.want {
+				t.Errorf("listenNetwork() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_listenNetwork()
+
+func Test_startListener(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	_ = resolver.AddDeny("ads.example.com")
+	ip := net.ParseIP("192.0.2.10").To4()
+	resolver.ICacheList.Create(context.TODO(), "ads.example.com", []net.IP{ip}, time.Hour)
+
+	tests := []struct {
+		name       string
+		unfiltered bool
+		want       net.IP
+	}{
+		/* */
+		{"01 - filtered", false, net.IPv4zero.To4()},
+		{"02 - unfiltered", true, ip},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			closers, err := startListener(resolver, tListenerConfig{
+				Address:    "127.0.0.1:0",
+				Unfiltered: tc.unfiltered,
+			}, "", &tStdForwarder{})
+			if nil != err {
+				t.Fatalf("startListener() error = '%v'", err)
+			}
+			defer func() {
+				for _, closer := range closers {
+					_ = closer.Close()
+				}
+			}()
+
+			conn, err := net.Dial("udp", closers[0].(net.PacketConn).LocalAddr().String())
+			if nil != err {
+				t.Fatalf("Dial() error = '%v'", err)
+			}
+			defer conn.Close()
+			_ = conn.SetDeadline(time.Now().Add(2 * time.Second))
+
+			if _, err = conn.Write(createDNSQuery("ads.example.com", dnsTypeA)); nil != err {
+				t.Fatalf("Write() error = '%v'", err)
+			}
+			answer := make([]byte, 512)
+			n, err := conn.Read(answer)
+			if nil != err {
+				t.Fatalf("Read() error = '%v'", err)
+			}
+			if got := net.IP(answer[n-4 : n]); !got.Equal(tc.want) {
+				t.Errorf("startListener() answer = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_startListener()
+
+func Benchmark_serveUDP(b *testing.B) {
+	resolver, w := benchResolver(b, workload.TOptions{Blocked: 0.2})
+	closers, err := startListener(resolver, tListenerConfig{Address: "127.0.0.1:0"},
+		"", &tStdForwarder{})
+	if nil != err {
+		b.Fatalf("startListener() error = '%v'", err)
+	}
+	defer func() {
+		for _, closer := range closers {
+			_ = closer.Close()
+		}
+	}()
+
+	conn, err := net.Dial("udp", closers[0].(net.PacketConn).LocalAddr().String())
+	if nil != err {
+		b.Fatalf("Dial() error = '%v'", err)
+	}
+	defer conn.Close()
+	answer := make([]byte, 512)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for idx := range b.N {
+		_ = conn.SetDeadline(time.Now().Add(time.Second))
+		if _, err = conn.Write(workload.Query(uint16(idx), w.Next(), workload.TypeA)); nil != err { //#nosec G115
+			b.Fatalf("Write() error = '%v'", err)
+		}
+		if _, err = conn.Read(answer); nil != err {
+			b.Fatalf("Read() error = '%v'", err)
+		}
+	}
+} // Benchmark_serveUDP()
+
+/* _EoF_ */
@@ -0,0 +1,252 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync/atomic"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tBinding` is the local end of the queries sent to an upstream
+	// server.
+	tBinding struct {
+		device string // interface to send the queries through
+		source net.IP // source address (`nil` means the device's or any)
+	}
+
+	// `tOutbound` holds the bindings of the queries sent upstream.
+	tOutbound struct {
+		fallback tBinding            // binding of all other servers
+		servers  map[string]tBinding // bindings by server (`host:port` or `host`)
+	}
+)
+
+var (
+	// `gOutbound` are the bindings of the forwarded queries
+	// (`nil` means the system picks interface and address).
+	gOutbound atomic.Pointer[tOutbound]
+)
+
+// ---------------------------------------------------------------------------
+// `tBinding` constructor:
+
+// `newBinding()` parses the binding of an upstream server.
+//
+// The interface doesn't need to exist (yet): a VPN may come up later,
+// and until it does the queries bound to it fail instead of taking
+// another route.
+//
+// Parameters:
+//   - `aConfig`: The interface and/or source address to use.
+//
+// Returns:
+//   - `tBinding`: The parsed binding.
+//   - `error`: `nil` if the binding is valid, the error otherwise.
+func newBinding(aConfig tBindConfig) (tBinding, error) {
+	result := tBinding{device: strings.TrimSpace(aConfig.Interface)}
+	if "" != aConfig.Address {
+		if result.source = net.ParseIP(strings.TrimSpace(aConfig.Address)); nil == result.source {
+			return result, fmt.Errorf("invalid source address %q", aConfig.Address)
+		}
+	}
+
+	return result, nil
+} // newBinding()
+
+// ---------------------------------------------------------------------------
+// `tOutbound` constructor:
+
+// `newOutbound()` creates the bindings of the forwarded queries.
+//
+// Parameters:
+//   - `aConfig`: The configuration to use.
+//
+// Returns:
+//   - `*tOutbound`: The bindings (`nil` if none are configured).
+//   - `error`: `nil` if the bindings are valid, the joined errors otherwise.
+func newOutbound(aConfig tConfiguration) (*tOutbound, error) {
+	if (nil == aConfig.Outbound) && (0 == len(aConfig.OutboundServers)) {
+		return nil, nil
+	}
+
+	var (
+		errs   []error
+		err    error
+		result = &tOutbound{servers: make(map[string]tBinding, len(aConfig.OutboundServers))}
+	)
+	if nil != aConfig.Outbound {
+		if result.fallback, err = newBinding(*aConfig.Outbound); nil != err {
+			errs = append(errs, fmt.Errorf("outbound: %w", err))
+		}
+	}
+	for server, bind := range aConfig.OutboundServers {
+		binding, err := newBinding(bind)
+		if nil != err {
+			errs = append(errs, fmt.Errorf("outbound server %q: %w", server, err))
+			continue
+		}
+		result.servers[outboundKey(server)] = binding
+	}
+	if 0 < len(errs) {
+		return nil, errors.Join(errs...)
+	}
+
+	return result, nil
+} // newOutbound()
+
+// ---------------------------------------------------------------------------
+// `tBinding` methods:
+
+// `dialer()` returns a dialer sending from the binding's interface
+// and/or address.
+//
+// Parameters:
+//   - `aNetwork`: The network to dial ("udp" or "tcp").
+//   - `aServer`: The upstream server to dial (`host:port`).
+//
+// Returns:
+//   - `*net.Dialer`: The dialer to use.
+//   - `error`: `nil` if the binding is usable, the error otherwise.
+func (b tBinding) dialer(aNetwork, aServer string) (*net.Dialer, error) {
+	result := &net.Dialer{}
+	source := b.source
+	if "" != b.device {
+		if bindToDeviceSupported {
+			result.Control = bindToDeviceControl(b.device)
+		} else if nil == source {
+			var err error
+			if source, err = interfaceAddr(b.device, serverIsIPv6(aServer)); nil != err {
+				return nil, err
+			}
+		}
+	}
+	if nil != source {
+		if strings.HasPrefix(aNetwork, "tcp") {
+			result.LocalAddr = &net.TCPAddr{IP: source}
+		} else {
+			result.LocalAddr = &net.UDPAddr{IP: source}
+		}
+	}
+
+	return result, nil
+} // dialer()
+
+// ---------------------------------------------------------------------------
+// `tOutbound` methods:
+
+// `binding()` returns the binding of an upstream server.
+//
+// Parameters:
+//   - `aServer`: The upstream server (`host:port`).
+//
+// Returns:
+//   - `tBinding`: The server's own binding, or the default one.
+func (o *tOutbound) binding(aServer string) tBinding {
+	if nil == o {
+		return tBinding{}
+	}
+	server := outboundKey(aServer)
+	if result, ok := o.servers[server]; ok {
+		return result
+	}
+	if host, _, err := net.SplitHostPort(server); nil == err {
+		if result, ok := o.servers[host]; ok {
+			return result
+		}
+	}
+
+	return o.fallback
+} // binding()
+
+// `dialer()` returns a dialer for the given upstream server.
+//
+// Parameters:
+//   - `aNetwork`: The network to dial ("udp" or "tcp").
+//   - `aServer`: The upstream server to dial (`host:port`).
+//
+// Returns:
+//   - `*net.Dialer`: The dialer to use.
+//   - `error`: `nil` if the server's binding is usable, the error otherwise.
+func (o *tOutbound) dialer(aNetwork, aServer string) (*net.Dialer, error) {
+	return o.binding(aServer).dialer(aNetwork, aServer)
+} // dialer()
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `interfaceAddr()` returns an address of a network interface.
+//
+// Parameters:
+//   - `aName`: The interface's name.
+//   - `aIPv6`: Whether an IPv6 (instead of an IPv4) address is needed.
+//
+// Returns:
+//   - `net.IP`: The interface's first address of the requested family.
+//   - `error`: `nil` if an address was found, the error otherwise.
+func interfaceAddr(aName string, aIPv6 bool) (net.IP, error) {
+	iface, err := net.InterfaceByName(aName)
+	if nil != err {
+		return nil, fmt.Errorf("outbound interface %q: %w", aName, err)
+	}
+	addrs, err := iface.Addrs()
+	if nil != err {
+		return nil, fmt.Errorf("outbound interface %q: %w", aName, err)
+	}
+	for _, addr := range addrs {
+		network, ok := addr.(*net.IPNet)
+		if !ok || network.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		if (nil == network.IP.To4()) == aIPv6 {
+			return network.IP, nil
+		}
+	}
+
+	return nil, fmt.Errorf("outbound interface %q has no usable address", aName)
+} // interfaceAddr()
+
+// `outboundKey()` normalises an upstream server's name.
+//
+// Parameters:
+//   - `aServer`: The upstream server (`host:port` or `host`).
+//
+// Returns:
+//   - `string`: The lower-cased server without the brackets of an
+//     IPv6 address lacking a port.
+func outboundKey(aServer string) string {
+	result := strings.ToLower(strings.TrimSpace(aServer))
+	if _, _, err := net.SplitHostPort(result); nil != err {
+		result = strings.Trim(result, "[]")
+	}
+
+	return result
+} // outboundKey()
+
+// `serverIsIPv6()` tells whether an upstream server is an IPv6 address.
+//
+// Parameters:
+//   - `aServer`: The upstream server (`host:port`).
+//
+// Returns:
+//   - `bool`: `true` for IPv6 addresses, `false` otherwise.
+func serverIsIPv6(aServer string) bool {
+	host, _, err := net.SplitHostPort(aServer)
+	if nil != err {
+		host = aServer
+	}
+	ip := net.ParseIP(strings.Trim(host, "[]"))
+
+	return (nil != ip) && (nil == ip.To4())
+} // serverIsIPv6()
+
+/* _EoF_ */
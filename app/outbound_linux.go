@@ -0,0 +1,50 @@
+//go:build linux
+
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+const (
+	// `bindToDeviceSupported` tells whether `SO_BINDTODEVICE` is available.
+	bindToDeviceSupported = true
+)
+
+// `bindToDeviceControl()` returns a function binding a socket to a
+// network interface before it gets connected.
+//
+// Unlike a mere source address this forces the packets out through
+// the interface regardless of the routing table (which usually needs
+// the `CAP_NET_RAW` capability).
+//
+// Parameters:
+//   - `aDevice`: The name of the interface to bind to.
+//
+// Returns:
+//   - `func(string, string, syscall.RawConn) error`: The dialer's control function.
+func bindToDeviceControl(aDevice string) func(string, string, syscall.RawConn) error {
+	return func(aNetwork, aAddress string, aConn syscall.RawConn) error {
+		var sockErr error
+		err := aConn.Control(func(aFD uintptr) {
+			sockErr = unix.BindToDevice(int(aFD), aDevice)
+		})
+		if nil != err {
+			return err
+		}
+
+		return sockErr
+	}
+} // bindToDeviceControl()
+
+/* _EoF_ */
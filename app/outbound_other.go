@@ -0,0 +1,32 @@
+//go:build !linux
+
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"syscall"
+)
+
+const (
+	// `bindToDeviceSupported` tells whether `SO_BINDTODEVICE` is available.
+	bindToDeviceSupported = false
+)
+
+// `bindToDeviceControl()` is never used on systems without
+// `SO_BINDTODEVICE` (the interface's address is used as source there).
+//
+// Parameters:
+//   - `aDevice`: The name of the interface (unused).
+//
+// Returns:
+//   - `func(string, string, syscall.RawConn) error`: Always `nil`.
+func bindToDeviceControl(aDevice string) func(string, string, syscall.RawConn) error {
+	return nil
+} // bindToDeviceControl()
+
+/* _EoF_ */
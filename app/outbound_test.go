@@ -0,0 +1,149 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_newOutbound(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  tConfiguration
+		wantNil bool
+		wantErr bool
+	}{
+		/* */
+		{"01 - not configured", tConfiguration{}, true, false},
+		{"02 - interface", tConfiguration{Outbound: &tBindConfig{Interface: "wg0"}}, false, false},
+		{"03 - source address", tConfiguration{Outbound: &tBindConfig{Address: "10.8.0.2"}}, false, false},
+		{"04 - invalid address", tConfiguration{Outbound: &tBindConfig{Address: "10.8.0"}}, true, true},
+		{"05 - server override", tConfiguration{OutboundServers: map[string]tBindConfig{
+			"9.9.9.9:53": {Interface: "wg0"},
+		}}, false, false},
+		{"06 - invalid override", tConfiguration{OutboundServers: map[string]tBindConfig{
+			"9.9.9.9:53": {Address: "wg0"},
+		}}, true, true},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := newOutbound(tc.config)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("newOutbound() error = '%v', wantErr '%v'", err, tc.wantErr)
+			}
+			if (nil == got) != tc.wantNil {
+				t.Errorf("newOutbound() = '%v', wantNil '%v'", got, tc.wantNil)
+			}
+		})
+	}
+} // Test_newOutbound()
+
+func Test_tOutbound_binding(t *testing.T) {
+	outbound, err := newOutbound(tConfiguration{
+		Outbound: &tBindConfig{Interface: "wg0"},
+		OutboundServers: map[string]tBindConfig{
+			"9.9.9.9:53":        {Address: "10.8.0.2"},
+			"DNS.Example.com":   {Interface: "tun1"},
+			"[2620:fe::fe]":     {Interface: "tun6"},
+			"[2620:fe::9]:5353": {Interface: "eth0"},
+		},
+	})
+	if nil != err {
+		t.Fatalf("newOutbound() error = '%v'", err)
+	}
+
+	tests := []struct {
+		name       string
+		outbound   *tOutbound
+		server     string
+		wantDevice string
+		wantSource string
+	}{
+		/* */
+		{"01 - server and port", outbound, "9.9.9.9:53", "", "10.8.0.2"},
+		{"02 - other port", outbound, "9.9.9.9:853", "wg0", ""},
+		{"03 - host only", outbound, "dns.example.com:53", "tun1", ""},
+		{"04 - IPv6 host only", outbound, "[2620:fe::fe]:53", "tun6", ""},
+		{"05 - IPv6 and port", outbound, "[2620:fe::9]:5353", "eth0", ""},
+		{"06 - default", outbound, "1.1.1.1:53", "wg0", ""},
+		{"07 - none configured", nil, "1.1.1.1:53", "", ""},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.outbound.binding(tc.server)
+			if got.device != tc.wantDevice {
+				t.Errorf("tOutbound.binding(%q).device = '%s', want '%s'",
+					tc.server, got.device, tc.wantDevice)
+			}
+			if source := got.source.String(); ("" != tc.wantSource) && (source != tc.wantSource) {
+				t.Errorf("tOutbound.binding(%q).source = '%s', want '%s'",
+					tc.server, source, tc.wantSource)
+			}
+			if ("" == tc.wantSource) && (nil != got.source) {
+				t.Errorf("tOutbound.binding(%q).source = '%v', want 'nil'", tc.server, got.source)
+			}
+		})
+	}
+} // Test_tOutbound_binding()
+
+func Test_tBinding_dialer(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	binding := tBinding{source: net.ParseIP("127.0.0.1")}
+	if dialer, err := binding.dialer("tcp", server.LocalAddr().String()); nil != err {
+		t.Errorf("tBinding.dialer() error = '%v'", err)
+	} else if _, ok := dialer.LocalAddr.(*net.TCPAddr); !ok {
+		t.Errorf("tBinding.dialer().LocalAddr = '%T', want '*net.TCPAddr'", dialer.LocalAddr)
+	}
+
+	dialer, err := binding.dialer("udp", server.LocalAddr().String())
+	if nil != err {
+		t.Fatalf("tBinding.dialer() error = '%v'", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	conn, err := dialer.DialContext(ctx, "udp", server.LocalAddr().String())
+	if nil != err {
+		t.Fatalf("DialContext() error = '%v'", err)
+	}
+	defer conn.Close()
+	if _, err = conn.Write([]byte("ping")); nil != err {
+		t.Fatal(err)
+	}
+	_ = server.SetReadDeadline(time.Now().Add(time.Second))
+	_, from, err := server.ReadFrom(make([]byte, 16))
+	if nil != err {
+		t.Fatal(err)
+	}
+	if got := from.(*net.UDPAddr).IP.String(); "127.0.0.1" != got {
+		t.Errorf("tBinding.dialer() source = '%s', want '127.0.0.1'", got)
+	}
+
+	// An unknown interface makes the queries fail instead of leaking
+	binding = tBinding{device: "no-such-if0"}
+	if dialer, err = binding.dialer("udp", "127.0.0.1:53"); nil == err {
+		_, err = dialer.DialContext(ctx, "udp", "127.0.0.1:53")
+	}
+	if nil == err {
+		t.Error("tBinding.dialer() with unknown interface: error = 'nil', want error")
+	}
+} // Test_tBinding_dialer()
+
+/* _EoF_ */
@@ -44,37 +44,33 @@ type (
 
 	// `tCacheEntry` represents a DNS cache entry with hostname and IPs
 	tCacheEntry struct {
-		Hostname string
-		IPs      []net.IP
+		Hostname string   `json:"hostname"`
+		IPs      []net.IP `json:"ips"`
 	}
 )
 
 // ---------------------------------------------------------------------------
 // Helper functions:
 
-// `getCacheEntries()` returns all entries in the DNS cache.
+// `cacheEntries()` returns all entries in the resolver's DNS cache.
 //
 // Parameters:
-//   - `aState`: The application state.
+//   - `aCtx`: The context to use for the operation.
+//   - `aResolver`: The resolver whose cache to read.
 //
 // Returns:
-//   - `[]CacheEntry`: List of cache entries.
-//   - `error`: Error if any occurred.
-func getCacheEntries(aState *tAppState) ([]tCacheEntry, error) {
-	if (nil == aState) || (nil == aState.resolver) {
-		return nil, fmt.Errorf("app or resolver not initialised")
-	}
-
+//   - `[]tCacheEntry`: List of cache entries.
+func cacheEntries(aCtx context.Context, aResolver *dnscache.TResolver) []tCacheEntry {
 	entries := []tCacheEntry{}
 
 	// Create a context with timeout for the operation
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second<<2)
+	ctx, cancel := context.WithTimeout(aCtx, time.Second<<2)
 	defer cancel()
 
 	// Get all hostnames from the cache
-	for hostname := range aState.resolver.ICacheList.Range(ctx) {
+	for hostname := range aResolver.ICacheList.Range(ctx) {
 		// Get IPs for this hostname
-		ips, ok := aState.resolver.ICacheList.IPs(ctx, hostname)
+		ips, ok := aResolver.ICacheList.IPs(ctx, hostname)
 		if !ok || (0 == len(ips)) {
 			continue
 		}
@@ -91,7 +87,23 @@ func getCacheEntries(aState *tAppState) ([]tCacheEntry, error) {
 		})
 	}
 
-	return entries, nil
+	return entries
+} // cacheEntries()
+
+// `getCacheEntries()` returns all entries in the DNS cache.
+//
+// Parameters:
+//   - `aState`: The application state.
+//
+// Returns:
+//   - `[]CacheEntry`: List of cache entries.
+//   - `error`: Error if any occurred.
+func getCacheEntries(aState *tAppState) ([]tCacheEntry, error) {
+	if (nil == aState) || (nil == aState.resolver) {
+		return nil, fmt.Errorf("app or resolver not initialised")
+	}
+
+	return cacheEntries(context.Background(), aState.resolver), nil
 } // getCacheEntries()
 
 // ---------------------------------------------------------------------------
@@ -0,0 +1,122 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"path/filepath"
+	"slices"
+
+	"github.com/mwat56/dnscache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `importPiHole()` inserts the allow/deny patterns of the configured
+// Pi-hole database.
+//
+// Since loading the blocklists replaces the deny lists, it has to be
+// called after [applyLists] and [applyGroups].
+//
+// Parameters:
+//   - `aResolver`: The resolver to configure.
+//   - `aConfig`: The configuration merged by [mergePiHole].
+//
+// Returns:
+//   - `error`: `nil` if the patterns were inserted, the error otherwise.
+func importPiHole(aResolver *dnscache.TResolver, aConfig tConfiguration) error {
+	if "" == aConfig.PiHoleDB {
+		return nil
+	}
+	data, err := dnscache.ReadPiHole(piholeFilename(aConfig))
+	if nil != err {
+		return err
+	}
+
+	// The groups' lists and clients are part of the merged configuration
+	return aResolver.ImportPiHole(&dnscache.TPiHoleImport{
+		Groups: data.Groups,
+		Allow:  data.Allow,
+		Deny:   data.Deny,
+	})
+} // importPiHole()
+
+// `mergePiHole()` adds the groups, clients, and blocklists of the
+// configured Pi-hole database to the configuration.
+//
+// The configuration's own settings take precedence.
+//
+// Parameters:
+//   - `aConfig`: The configuration to extend.
+//
+// Returns:
+//   - `error`: `nil` if the database was read, the error otherwise.
+func mergePiHole(aConfig *tConfiguration) error {
+	if "" == aConfig.PiHoleDB {
+		return nil
+	}
+	data, err := dnscache.ReadPiHole(piholeFilename(*aConfig))
+	if nil != err {
+		return err
+	}
+	for _, entry := range data.Skipped {
+		gServerLog.Warn("can't import Pi-hole entry", "entry", entry)
+	}
+
+	for _, url := range data.BlockLists[""] {
+		if !slices.Contains(aConfig.BlockLists, url) {
+			aConfig.BlockLists = append(aConfig.BlockLists, url)
+		}
+	}
+	if (0 < len(data.Groups)) && (nil == aConfig.Groups) {
+		aConfig.Groups = make(map[string]tGroupConfig, len(data.Groups))
+	}
+	for _, name := range data.Groups {
+		group := aConfig.Groups[name]
+		for _, url := range data.BlockLists[name] {
+			if !slices.Contains(group.BlockLists, url) {
+				group.BlockLists = append(group.BlockLists, url)
+			}
+		}
+		aConfig.Groups[name] = group
+	}
+	if (0 < len(data.Clients)) && (nil == aConfig.Clients) {
+		aConfig.Clients = make(map[string]string, len(data.Clients))
+	}
+	for client, name := range data.Clients {
+		if _, ok := aConfig.Clients[client]; !ok {
+			aConfig.Clients[client] = name
+		}
+	}
+	gServerLog.Info("imported Pi-hole database", "file", aConfig.PiHoleDB,
+		"groups", len(data.Groups), "clients", len(data.Clients),
+		"skipped", len(data.Skipped))
+
+	return nil
+} // mergePiHole()
+
+// `piholeFilename()` returns the path of the configured Pi-hole
+// database.
+//
+// A relative path is taken relative to the data directory.
+//
+// Parameters:
+//   - `aConfig`: The configuration to use.
+//
+// Returns:
+//   - `string`: The database's path/file name.
+func piholeFilename(aConfig tConfiguration) string {
+	if fName := aConfig.PiHoleDB; filepath.IsAbs(fName) {
+		return fName
+	}
+
+	return filepath.Join(aConfig.DataDir, aConfig.PiHoleDB)
+} // piholeFilename()
+
+/* _EoF_ */
@@ -0,0 +1,99 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/mwat56/dnscache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_piholeFilename(t *testing.T) {
+	tests := []struct {
+		name   string
+		config tConfiguration
+		want   string
+	}{
+		/* */
+		{"01 - relative", tConfiguration{DataDir: "/var/lib/dnscache", PiHoleDB: "gravity.db"},
+			"/var/lib/dnscache/gravity.db"},
+		{"02 - absolute", tConfiguration{DataDir: "/var/lib/dnscache", PiHoleDB: "/etc/pihole/gravity.db"},
+			"/etc/pihole/gravity.db"},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := piholeFilename(tc.config); got != tc.want {
+				t.Errorf("piholeFilename() = '%s', want '%s'", got, tc.want)
+			}
+		})
+	}
+} // Test_piholeFilename()
+
+func Test_mergePiHole(t *testing.T) {
+	config := tConfiguration{}
+	if err := mergePiHole(&config); nil != err {
+		t.Errorf("mergePiHole() error = '%v'", err)
+	}
+	if nil != config.Groups {
+		t.Errorf("Groups = '%v', want 'nil'", config.Groups)
+	}
+
+	config = tConfiguration{PiHoleDB: "missing.db", DataDir: t.TempDir()}
+	if err := mergePiHole(&config); nil == err {
+		t.Error("mergePiHole() error = 'nil', want an error")
+	}
+
+	gravity, _ := filepath.Abs(filepath.Join("..", "testdata", "gravity.db"))
+	config = tConfiguration{
+		PiHoleDB:   gravity,
+		BlockLists: []string{"https://lists.example.com/hosts.txt"},
+		Clients:    map[string]string{"192.168.1.10": "guests"},
+	}
+	if err := mergePiHole(&config); nil != err {
+		t.Fatalf("mergePiHole() error = '%v'", err)
+	}
+	if want := []string{"https://lists.example.com/hosts.txt"}; !slices.Equal(config.BlockLists, want) {
+		t.Errorf("BlockLists = '%v', want '%v'", config.BlockLists, want)
+	}
+	want := []string{"https://lists.example.com/hosts.txt", "https://lists.example.com/kids.txt"}
+	if got := config.Groups["Kids_Devices"].BlockLists; !slices.Equal(got, want) {
+		t.Errorf("Groups[Kids_Devices].BlockLists = '%v', want '%v'", got, want)
+	}
+	if got := config.Clients["192.168.1.10"]; "guests" != got {
+		t.Errorf("Clients[192.168.1.10] = '%s', want 'guests'", got)
+	}
+	if got := config.Clients["10.0.0.0/24"]; "Kids_Devices" != got {
+		t.Errorf("Clients[10.0.0.0/24] = '%s', want 'Kids_Devices'", got)
+	}
+} // Test_mergePiHole()
+
+func Test_importPiHole(t *testing.T) {
+	r := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer r.StopExpire()
+
+	if err := importPiHole(r, tConfiguration{}); nil != err {
+		t.Errorf("importPiHole() error = '%v'", err)
+	}
+	gravity, _ := filepath.Abs(filepath.Join("..", "testdata", "gravity.db"))
+	if err := importPiHole(r, tConfiguration{PiHoleDB: gravity}); nil != err {
+		t.Fatalf("importPiHole() error = '%v'", err)
+	}
+	if got := r.DenyPatterns(); !slices.Contains(got, "ads.example.com") {
+		t.Errorf("DenyPatterns() = '%v', want 'ads.example.com'", got)
+	}
+	if got := r.Groups(); !slices.Equal(got, []string{"Kids_Devices"}) {
+		t.Errorf("Groups() = '%v', want '[Kids_Devices]'", got)
+	}
+} // Test_importPiHole()
+
+/* _EoF_ */
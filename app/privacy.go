@@ -0,0 +1,190 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strings"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+const (
+	// Privacy modes for client addresses:
+
+	privacyNone     = ""         // log client addresses as is
+	privacyTruncate = "truncate" // mask the host part of addresses
+	privacyHash     = "hash"     // replace addresses by a salted hash
+
+	// Default prefix lengths used when truncating client addresses:
+
+	defPrivacyMaskV4 = 24
+	defPrivacyMaskV6 = 56
+)
+
+type (
+	// `tPrivacy` anonymises client addresses and query names
+	// before they get written to the query log.
+	tPrivacy struct {
+		mode     string
+		v4Mask   net.IPMask
+		v6Mask   net.IPMask
+		salt     []byte
+		suffixes []string // lower-cased sensitive suffixes
+	}
+)
+
+// ---------------------------------------------------------------------------
+// `tPrivacy` constructor:
+
+// `newPrivacy()` creates a new privacy filter from the configuration.
+//
+// For the hash mode a random salt is generated which is kept in memory
+// only, hence the hashes of the same address differ between program
+// runs and can't be reversed by a simple dictionary attack.
+//
+// Parameters:
+//   - `aConfig`: The configuration to use.
+//
+// Returns:
+//   - `*tPrivacy`: The new privacy filter.
+//   - `error`: `nil` if the configuration is valid, the error otherwise.
+func newPrivacy(aConfig tConfiguration) (*tPrivacy, error) {
+	result := &tPrivacy{
+		mode: strings.ToLower(strings.TrimSpace(aConfig.PrivacyMode)),
+	}
+
+	switch result.mode {
+	case privacyNone:
+
+	case privacyTruncate:
+		v4Bits := aConfig.PrivacyMaskV4
+		if 0 == v4Bits {
+			v4Bits = defPrivacyMaskV4
+		}
+		v6Bits := aConfig.PrivacyMaskV6
+		if 0 == v6Bits {
+			v6Bits = defPrivacyMaskV6
+		}
+		if (0 > v4Bits) || (32 < v4Bits) {
+			return nil, fmt.Errorf("invalid IPv4 privacy mask: %d", v4Bits)
+		}
+		if (0 > v6Bits) || (128 < v6Bits) {
+			return nil, fmt.Errorf("invalid IPv6 privacy mask: %d", v6Bits)
+		}
+		result.v4Mask = net.CIDRMask(v4Bits, 32)
+		result.v6Mask = net.CIDRMask(v6Bits, 128)
+
+	case privacyHash:
+		result.salt = make([]byte, 16)
+		if _, err := rand.Read(result.salt); nil != err {
+			return nil, fmt.Errorf("failed to create privacy salt: %w", err)
+		}
+
+	default:
+		return nil, fmt.Errorf("invalid privacy mode: %q", aConfig.PrivacyMode)
+	}
+
+	for _, suffix := range aConfig.PrivacySuffixes {
+		suffix = strings.Trim(strings.ToLower(strings.TrimSpace(suffix)), ".")
+		if "" != suffix {
+			result.suffixes = append(result.suffixes, suffix)
+		}
+	}
+
+	return result, nil
+} // newPrivacy()
+
+// ---------------------------------------------------------------------------
+// `tPrivacy` methods:
+
+// `Client()` returns the anonymised representation of a client address.
+//
+// Depending on the configured mode the address is returned unchanged,
+// with its host part masked (e.g. `192.168.1.0`), or as a salted hash.
+//
+// Parameters:
+//   - `aAddr`: The client's network address.
+//
+// Returns:
+//   - `string`: The (anonymised) client address.
+func (p *tPrivacy) Client(aAddr net.Addr) string {
+	if nil == aAddr {
+		return ""
+	}
+
+	ip := addrIP(aAddr)
+	if nil == ip {
+		if (nil == p) || (privacyNone == p.mode) {
+			return aAddr.String()
+		}
+		// Not an IP address, so we can't mask it; hash it instead
+		return p.hash([]byte(aAddr.String()))
+	}
+	if nil == p {
+		return ip.String()
+	}
+
+	switch p.mode {
+	case privacyTruncate:
+		if ip4 := ip.To4(); nil != ip4 {
+			return ip4.Mask(p.v4Mask).String()
+		}
+		return ip.Mask(p.v6Mask).String()
+
+	case privacyHash:
+		return p.hash(ip)
+	}
+
+	return ip.String()
+} // Client()
+
+// `hash()` returns the salted hash of the given data.
+//
+// Parameters:
+//   - `aData`: The data to hash.
+//
+// Returns:
+//   - `string`: The hex encoded (shortened) hash value.
+func (p *tPrivacy) hash(aData []byte) string {
+	h := sha256.New()
+	_, _ = h.Write(p.salt)
+	_, _ = h.Write(aData)
+
+	return hex.EncodeToString(h.Sum(nil)[:8])
+} // hash()
+
+// `IsSensitive()` checks whether a query name matches one of the
+// configured sensitive suffixes.
+//
+// Queries for sensitive names are not to be logged at all.
+//
+// Parameters:
+//   - `aName`: The query name to check.
+//
+// Returns:
+//   - `bool`: `true` if the name is sensitive, `false` otherwise.
+func (p *tPrivacy) IsSensitive(aName string) bool {
+	if (nil == p) || (0 == len(p.suffixes)) {
+		return false
+	}
+
+	name := strings.Trim(strings.ToLower(aName), ".")
+	for _, suffix := range p.suffixes {
+		if (name == suffix) || strings.HasSuffix(name, "."+suffix) {
+			return true
+		}
+	}
+
+	return false
+} // IsSensitive()
+
+/* _EoF_ */
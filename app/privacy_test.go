@@ -0,0 +1,205 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"net"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_newPrivacy(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  tConfiguration
+		wantErr bool
+	}{
+		/* */
+		{
+			name:    "01 - no privacy",
+			config:  tConfiguration{},
+			wantErr: false,
+		},
+		{
+			name:    "02 - truncate with defaults",
+			config:  tConfiguration{PrivacyMode: "truncate"},
+			wantErr: false,
+		},
+		{
+			name:    "03 - hash",
+			config:  tConfiguration{PrivacyMode: "Hash"},
+			wantErr: false,
+		},
+		{
+			name:    "04 - invalid mode",
+			config:  tConfiguration{PrivacyMode: "scramble"},
+			wantErr: true,
+		},
+		{
+			name:    "05 - invalid IPv4 mask",
+			config:  tConfiguration{PrivacyMode: "truncate", PrivacyMaskV4: 33},
+			wantErr: true,
+		},
+		{
+			name:    "06 - invalid IPv6 mask",
+			config:  tConfiguration{PrivacyMode: "truncate", PrivacyMaskV6: -1},
+			wantErr: true,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := newPrivacy(tc.config)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("newPrivacy() error = '%v', wantErr '%v'",
+					err, tc.wantErr)
+				return
+			}
+			if !tc.wantErr && (nil == got) {
+				t.Error("newPrivacy() = 'nil', want non-nil")
+			}
+		})
+	}
+} // Test_newPrivacy()
+
+func Test_tPrivacy_Client(t *testing.T) {
+	truncate, _ := newPrivacy(tConfiguration{PrivacyMode: "truncate"})
+	truncate16, _ := newPrivacy(tConfiguration{
+		PrivacyMode:   "truncate",
+		PrivacyMaskV4: 16,
+		PrivacyMaskV6: 32,
+	})
+	hash, _ := newPrivacy(tConfiguration{PrivacyMode: "hash"})
+	v4 := &net.UDPAddr{IP: net.ParseIP("192.168.17.42"), Port: 5353}
+	v6 := &net.UDPAddr{IP: net.ParseIP("2001:db8:1234:5678::1"), Port: 5353}
+
+	tests := []struct {
+		name    string
+		privacy *tPrivacy
+		addr    net.Addr
+		want    string
+	}{
+		/* */
+		{
+			name:    "01 - nil privacy",
+			privacy: nil,
+			addr:    v4,
+			want:    "192.168.17.42",
+		},
+		{
+			name:    "02 - nil address",
+			privacy: truncate,
+			addr:    nil,
+			want:    "",
+		},
+		{
+			name:    "03 - truncate IPv4",
+			privacy: truncate,
+			addr:    v4,
+			want:    "192.168.17.0",
+		},
+		{
+			name:    "04 - truncate IPv6",
+			privacy: truncate,
+			addr:    v6,
+			want:    "2001:db8:1234:5600::",
+		},
+		{
+			name:    "05 - truncate IPv4 /16",
+			privacy: truncate16,
+			addr:    v4,
+			want:    "192.168.0.0",
+		},
+		{
+			name:    "06 - truncate IPv6 /32",
+			privacy: truncate16,
+			addr:    v6,
+			want:    "2001:db8::",
+		},
+		{
+			name:    "07 - hash",
+			privacy: hash,
+			addr:    v4,
+			want:    hash.hash(v4.IP),
+		},
+		{
+			name:    "08 - mock address",
+			privacy: truncate,
+			addr:    &tMockAddr{},
+			want:    "127.0.0.0",
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.privacy.Client(tc.addr); got != tc.want {
+				t.Errorf("tPrivacy.Client() = '%v', want '%v'",
+					got, tc.want)
+			}
+		})
+	}
+} // Test_tPrivacy_Client()
+
+func Test_tPrivacy_IsSensitive(t *testing.T) {
+	privacy, _ := newPrivacy(tConfiguration{
+		PrivacySuffixes: []string{"Health.example", ".bank.tld."},
+	})
+
+	tests := []struct {
+		name    string
+		privacy *tPrivacy
+		qname   string
+		want    bool
+	}{
+		/* */
+		{
+			name:    "01 - nil privacy",
+			privacy: nil,
+			qname:   "health.example",
+			want:    false,
+		},
+		{
+			name:    "02 - exact match",
+			privacy: privacy,
+			qname:   "health.example",
+			want:    true,
+		},
+		{
+			name:    "03 - subdomain match",
+			privacy: privacy,
+			qname:   "WWW.Bank.TLD.",
+			want:    true,
+		},
+		{
+			name:    "04 - no label boundary",
+			privacy: privacy,
+			qname:   "mybank.tld",
+			want:    false,
+		},
+		{
+			name:    "05 - unrelated name",
+			privacy: privacy,
+			qname:   "example.com",
+			want:    false,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.privacy.IsSensitive(tc.qname); got != tc.want {
+				t.Errorf("tPrivacy.IsSensitive() = '%v', want '%v'",
+					got, tc.want)
+			}
+		})
+	}
+} // Test_tPrivacy_IsSensitive()
+
+/* _EoF_ */
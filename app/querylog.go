@@ -0,0 +1,105 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"encoding/binary"
+	"net"
+	"sync/atomic"
+
+	"github.com/mwat56/dnscache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tQueryLog` writes the received DNS queries to the log.
+	//
+	// All entries are passed through the privacy filter first.
+	tQueryLog struct {
+		privacy *tPrivacy
+		log     dnscache.ILogger
+	}
+)
+
+var (
+	// `gQueryLog` is the active query log (`nil` means disabled).
+	gQueryLog atomic.Pointer[tQueryLog]
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `extractFirstQType()` extracts the type of the first question of a
+// DNS request message.
+//
+// Parameters:
+//   - `aRequest`: The DNS request.
+//
+// Returns:
+//   - `uint16`: The question's type (`0` if the request is malformed).
+func extractFirstQType(aRequest []byte) uint16 {
+	offset := 12
+	for offset < len(aRequest) {
+		labelLen := int(aRequest[offset])
+		if 0 == labelLen {
+			offset++
+			if offset+2 > len(aRequest) {
+				return 0
+			}
+			return binary.BigEndian.Uint16(aRequest[offset : offset+2])
+		}
+		offset += labelLen + 1
+	}
+
+	return 0
+} // extractFirstQType()
+
+// `newQueryLog()` creates a new query log.
+//
+// Parameters:
+//   - `aPrivacy`: The privacy filter to use (`nil` means none).
+//
+// Returns:
+//   - `*tQueryLog`: The new query log.
+func newQueryLog(aPrivacy *tPrivacy) *tQueryLog {
+	return &tQueryLog{
+		privacy: aPrivacy,
+		log:     dnscache.Logger("query"),
+	}
+} // newQueryLog()
+
+// ---------------------------------------------------------------------------
+// `tQueryLog` methods:
+
+// `Log()` writes a DNS query to the log.
+//
+// Queries for sensitive names (see `tPrivacy.IsSensitive()`) are
+// dropped silently.
+//
+// Parameters:
+//   - `aAddr`: The client's network address.
+//   - `aRequest`: The DNS request message.
+//   - `aAction`: How the request is handled (e.g. "local" or "forward").
+func (ql *tQueryLog) Log(aAddr net.Addr, aRequest []byte, aAction string) {
+	if nil == ql {
+		return
+	}
+
+	hostname := extractFirstHostname(aRequest)
+	if ql.privacy.IsSensitive(hostname) {
+		return
+	}
+
+	ql.log.Info("query",
+		"client", ql.privacy.Client(aAddr),
+		"qname", hostname,
+		"qtype", extractFirstQType(aRequest),
+		"action", aAction)
+} // Log()
+
+/* _EoF_ */
@@ -0,0 +1,125 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/mwat56/dnscache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_extractFirstQType(t *testing.T) {
+	tests := []struct {
+		name    string
+		request []byte
+		want    uint16
+	}{
+		/* */
+		{
+			name:    "01 - nil request",
+			request: nil,
+			want:    0,
+		},
+		{
+			name:    "02 - A query",
+			request: createDNSQuery("example.com", dnsTypeA),
+			want:    dnsTypeA,
+		},
+		{
+			name:    "03 - AAAA query",
+			request: createDNSQuery("example.com", dnsTypeAAAA),
+			want:    dnsTypeAAAA,
+		},
+		{
+			name:    "04 - truncated query",
+			request: createDNSQuery("example.com", dnsTypeA)[:20],
+			want:    0,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := extractFirstQType(tc.request); got != tc.want {
+				t.Errorf("extractFirstQType() = '%v', want '%v'",
+					got, tc.want)
+			}
+		})
+	}
+} // Test_extractFirstQType()
+
+func Test_tQueryLog_Log(t *testing.T) {
+	var buf bytes.Buffer
+	dnscache.SetLogger(dnscache.NewSlogLogger(slog.NewTextHandler(&buf,
+		&slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer dnscache.SetLogger(nil)
+
+	privacy, _ := newPrivacy(tConfiguration{
+		PrivacyMode:     "truncate",
+		PrivacySuffixes: []string{"secret.tld"},
+	})
+
+	tests := []struct {
+		name     string
+		queryLog *tQueryLog
+		request  []byte
+		want     string
+	}{
+		/* */
+		{
+			name:     "01 - nil query log",
+			queryLog: nil,
+			request:  createDNSQuery("example.com", dnsTypeA),
+			want:     "",
+		},
+		{
+			name:     "02 - truncated client",
+			queryLog: newQueryLog(privacy),
+			request:  createDNSQuery("example.com", dnsTypeA),
+			want:     "client=127.0.0.0 qname=example.com qtype=1 action=local",
+		},
+		{
+			name:     "03 - sensitive name",
+			queryLog: newQueryLog(privacy),
+			request:  createDNSQuery("www.secret.tld", dnsTypeA),
+			want:     "",
+		},
+		{
+			name:     "04 - no privacy",
+			queryLog: newQueryLog(nil),
+			request:  createDNSQuery("www.secret.tld", dnsTypeAAAA),
+			want:     "client=127.0.0.1 qname=www.secret.tld qtype=28",
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			buf.Reset()
+			tc.queryLog.Log(&tMockAddr{}, tc.request, "local")
+
+			got := buf.String()
+			if "" == tc.want {
+				if "" != got {
+					t.Errorf("tQueryLog.Log() wrote '%s', want ''", got)
+				}
+				return
+			}
+			if !strings.Contains(got, tc.want) {
+				t.Errorf("tQueryLog.Log() wrote '%s', want '%s'",
+					got, tc.want)
+			}
+		})
+	}
+} // Test_tQueryLog_Log()
+
+/* _EoF_ */
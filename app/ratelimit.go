@@ -0,0 +1,262 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mwat56/dnscache/clock"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tBucket` is the token bucket of a single client.
+	tBucket struct {
+		tokens float64   // queries the client may still send
+		filled time.Time // time the tokens were last refilled
+	}
+
+	// `tRateLimiter` limits the rate of a kind of queries per client.
+	tRateLimiter struct {
+		sync.Mutex
+		buckets map[string]*tBucket // token buckets by client
+		clock   clock.IClock        // source of the current time
+		rate    float64             // tokens added per second
+		burst   float64             // max. number of tokens
+	}
+
+	// `tRateLimits` are the rate limits of diagnostic and ANY queries.
+	tRateLimits struct {
+		diagnostic *tRateLimiter // CH class and self-identification queries
+		any        *tRateLimiter // queries for all records of a name
+		exempt     []*net.IPNet  // networks besides the loopback ones
+	}
+)
+
+const (
+	// `maxRateBuckets` is the max. number of clients tracked by a
+	// rate limiter.
+	maxRateBuckets = 1 << 14
+)
+
+var (
+	// `gRateLimits` are the active query rate limits (`nil` means
+	// no limits).
+	gRateLimits atomic.Pointer[tRateLimits]
+
+	// `gRateLimited` counts the queries dropped by the rate limits.
+	gRateLimited atomic.Uint64
+)
+
+// ---------------------------------------------------------------------------
+// `tRateLimiter` constructor:
+
+// `newRateLimiter()` creates a per-client rate limiter.
+//
+// Parameters:
+//   - `aRate`: The configured rate limit.
+//   - `aClock`: The source of the current time.
+//
+// Returns:
+//   - `*tRateLimiter`: The rate limiter (`nil` if there's no limit).
+func newRateLimiter(aRate tRateConfig, aClock clock.IClock) *tRateLimiter {
+	if 0 >= aRate.Rate {
+		return nil
+	}
+	burst := float64(aRate.Burst)
+	if 0 >= burst {
+		burst = math.Max(1, math.Ceil(aRate.Rate))
+	}
+
+	return &tRateLimiter{
+		buckets: make(map[string]*tBucket),
+		clock:   aClock,
+		rate:    aRate.Rate,
+		burst:   burst,
+	}
+} // newRateLimiter()
+
+// ---------------------------------------------------------------------------
+// `tRateLimits` constructor:
+
+// `newRateLimits()` creates the rate limits of diagnostic and ANY
+// queries.
+//
+// Parameters:
+//   - `aConfig`: The configuration to use.
+//   - `aClock`: The source of the current time (`nil` for the system's).
+//
+// Returns:
+//   - `*tRateLimits`: The rate limits (`nil` if not configured).
+//   - `error`: `nil` if the configuration is valid, the error otherwise.
+func newRateLimits(aConfig tConfiguration, aClock clock.IClock) (*tRateLimits, error) {
+	limits := aConfig.RateLimit
+	if nil == limits {
+		return nil, nil
+	}
+
+	var errs []error
+	for _, kind := range []struct {
+		name string
+		rate tRateConfig
+	}{
+		{"diagnostic", limits.Diagnostic},
+		{"ANY", limits.ANY},
+	} {
+		name, rate := kind.name, kind.rate
+		if (0 > rate.Rate) || math.IsNaN(rate.Rate) || math.IsInf(rate.Rate, 0) {
+			errs = append(errs, fmt.Errorf("invalid %s query rate: %v", name, rate.Rate))
+		}
+		if 0 > rate.Burst {
+			errs = append(errs, fmt.Errorf("invalid %s query burst: %d", name, rate.Burst))
+		}
+	}
+	result := &tRateLimits{}
+	for _, client := range limits.Exempt {
+		network := parseClientNetwork(client)
+		if nil == network {
+			errs = append(errs, fmt.Errorf("invalid rate limit exempt client: %q", client))
+			continue
+		}
+		result.exempt = append(result.exempt, network)
+	}
+	if 0 < len(errs) {
+		return nil, errors.Join(errs...)
+	}
+
+	aClock = clock.OrSystem(aClock)
+	result.diagnostic = newRateLimiter(limits.Diagnostic, aClock)
+	result.any = newRateLimiter(limits.ANY, aClock)
+
+	return result, nil
+} // newRateLimits()
+
+// ---------------------------------------------------------------------------
+// `tRateLimiter` methods:
+
+// `allow()` checks whether a client may send another query.
+//
+// Parameters:
+//   - `aClient`: The client's address.
+//
+// Returns:
+//   - `bool`: `true` if the query is within the limit, `false` otherwise.
+func (rl *tRateLimiter) allow(aClient string) bool {
+	if nil == rl {
+		return true
+	}
+	now := rl.clock.Now()
+
+	rl.Lock()
+	defer rl.Unlock()
+
+	bucket, ok := rl.buckets[aClient]
+	if !ok {
+		if maxRateBuckets <= len(rl.buckets) {
+			rl.prune(now)
+		}
+		bucket = &tBucket{tokens: rl.burst, filled: now}
+		rl.buckets[aClient] = bucket
+	} else {
+		bucket.tokens = math.Min(rl.burst,
+			bucket.tokens+now.Sub(bucket.filled).Seconds()*rl.rate)
+		bucket.filled = now
+	}
+	if 1 > bucket.tokens {
+		return false
+	}
+	bucket.tokens--
+
+	return true
+} // allow()
+
+// `prune()` removes the buckets of clients which could send a full
+// burst again, or all of them if that's not enough.
+//
+// The caller must hold the limiter's lock.
+//
+// Parameters:
+//   - `aNow`: The current time.
+func (rl *tRateLimiter) prune(aNow time.Time) {
+	refill := time.Duration(rl.burst / rl.rate * float64(time.Second))
+	for client, bucket := range rl.buckets {
+		if refill <= aNow.Sub(bucket.filled) {
+			delete(rl.buckets, client)
+		}
+	}
+	if maxRateBuckets <= len(rl.buckets) {
+		clear(rl.buckets)
+	}
+} // prune()
+
+// ---------------------------------------------------------------------------
+// `tRateLimits` methods:
+
+// `allows()` checks whether a client may send the given query.
+//
+// Only CH class queries, the self-identification queries, and
+// queries for all records of a name are limited; the loopback and
+// the exempt clients aren't limited at all.
+//
+// Parameters:
+//   - `aClient`: The client's IP address.
+//   - `aRequest`: The DNS request message.
+//
+// Returns:
+//   - `bool`: `true` if the query may be answered, `false` otherwise.
+func (rls *tRateLimits) allows(aClient net.IP, aRequest []byte) bool {
+	if (nil == rls) || (nil == aClient) {
+		return true
+	}
+	limiter := rls.limiter(aRequest)
+	if (nil == limiter) || aClient.IsLoopback() {
+		return true
+	}
+	for _, network := range rls.exempt {
+		if network.Contains(aClient) {
+			return true
+		}
+	}
+
+	return limiter.allow(aClient.String())
+} // allows()
+
+// `limiter()` returns the rate limiter responsible for a query.
+//
+// Parameters:
+//   - `aRequest`: The DNS request message.
+//
+// Returns:
+//   - `*tRateLimiter`: The query's limiter (`nil` if not limited).
+func (rls *tRateLimits) limiter(aRequest []byte) *tRateLimiter {
+	qType, qClass, ok := questionType(aRequest)
+	switch {
+	case !ok:
+		return nil
+	case dnsClassCH == qClass:
+		return rls.diagnostic
+	case dnsTypeANY == qType:
+		return rls.any
+	case (dnsClassIN == qClass) && (dnsTypeTXT == qType):
+		switch strings.ToLower(strings.TrimSuffix(extractFirstHostname(aRequest), ".")) {
+		case selfIDStats, selfIDVersion:
+			return rls.diagnostic
+		}
+	}
+
+	return nil
+} // limiter()
+
+/* _EoF_ */
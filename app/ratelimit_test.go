@@ -0,0 +1,210 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/mwat56/dnscache"
+	"github.com/mwat56/dnscache/clock"
+	"github.com/mwat56/dnscache/internal/workload"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_newRateLimits(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   *tRateLimitConfig
+		wantNil  bool
+		wantDiag bool
+		wantANY  bool
+		wantErr  bool
+	}{
+		/* */
+		{"01 - not configured", nil, true, false, false, false},
+		{"02 - diagnostic only", &tRateLimitConfig{Diagnostic: tRateConfig{Rate: 1}},
+			false, true, false, false},
+		{"03 - both", &tRateLimitConfig{Diagnostic: tRateConfig{Rate: 1}, ANY: tRateConfig{Rate: 0.5, Burst: 2}},
+			false, true, true, false},
+		{"04 - negative rate", &tRateLimitConfig{ANY: tRateConfig{Rate: -1}}, true, false, false, true},
+		{"05 - negative burst", &tRateLimitConfig{Diagnostic: tRateConfig{Rate: 1, Burst: -1}},
+			true, false, false, true},
+		{"06 - invalid exempt client", &tRateLimitConfig{Exempt: []string{"not-an-ip"}},
+			true, false, false, true},
+		{"07 - exempt clients", &tRateLimitConfig{Exempt: []string{"192.0.2.1", "2001:db8::/32"}},
+			false, false, false, false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := newRateLimits(tConfiguration{RateLimit: tc.config}, nil)
+			if (nil != err) != tc.wantErr {
+				t.Fatalf("newRateLimits() error = '%v', wantErr '%v'", err, tc.wantErr)
+			}
+			if (nil == got) != tc.wantNil {
+				t.Fatalf("newRateLimits() = '%v', wantNil '%v'", got, tc.wantNil)
+			}
+			if nil == got {
+				return
+			}
+			if (nil != got.diagnostic) != tc.wantDiag {
+				t.Errorf("newRateLimits().diagnostic = '%v', want '%v'", got.diagnostic, tc.wantDiag)
+			}
+			if (nil != got.any) != tc.wantANY {
+				t.Errorf("newRateLimits().any = '%v', want '%v'", got.any, tc.wantANY)
+			}
+		})
+	}
+} // Test_newRateLimits()
+
+func Test_tRateLimiter_allow(t *testing.T) {
+	clk := clock.NewManual(time.Now())
+	rl := newRateLimiter(tRateConfig{Rate: 1, Burst: 2}, clk)
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		client  string
+		want    bool
+	}{
+		/* */
+		{"01 - first of burst", 0, "192.0.2.1", true},
+		{"02 - second of burst", 0, "192.0.2.1", true},
+		{"03 - burst exhausted", 0, "192.0.2.1", false},
+		{"04 - other client", 0, "192.0.2.2", true},
+		{"05 - half a token", 500 * time.Millisecond, "192.0.2.1", false},
+		{"06 - refilled token", 500 * time.Millisecond, "192.0.2.1", true},
+		{"07 - token used", 0, "192.0.2.1", false},
+		{"08 - refilled burst", time.Hour, "192.0.2.1", true},
+		{"09 - refilled burst", 0, "192.0.2.1", true},
+		{"10 - burst exhausted", 0, "192.0.2.1", false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			clk.Advance(tc.advance)
+			if got := rl.allow(tc.client); got != tc.want {
+				t.Errorf("allow() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+
+	var unlimited *tRateLimiter
+	if !unlimited.allow("192.0.2.1") {
+		t.Error("allow() without limit = 'false', want 'true'")
+	}
+} // Test_tRateLimiter_allow()
+
+func Test_tRateLimiter_prune(t *testing.T) {
+	clk := clock.NewManual(time.Now())
+	rl := newRateLimiter(tRateConfig{Rate: 1}, clk)
+
+	for i := range maxRateBuckets {
+		rl.allow(fmt.Sprintf("client-%d", i))
+	}
+	if got := len(rl.buckets); maxRateBuckets != got {
+		t.Fatalf("buckets = '%d', want '%d'", got, maxRateBuckets)
+	}
+
+	// Clients which could send a full burst again are forgotten
+	clk.Advance(time.Second)
+	rl.allow("192.0.2.1")
+	if got := len(rl.buckets); 1 != got {
+		t.Errorf("buckets after prune = '%d', want '1'", got)
+	}
+} // Test_tRateLimiter_prune()
+
+func Test_tRateLimits_allows(t *testing.T) {
+	limits, err := newRateLimits(tConfiguration{RateLimit: &tRateLimitConfig{
+		Diagnostic: tRateConfig{Rate: 1},
+		ANY:        tRateConfig{Rate: 1},
+		Exempt:     []string{"192.0.2.128/25"},
+	}}, clock.NewManual(time.Now()))
+	if nil != err {
+		t.Fatalf("newRateLimits() error = '%v'", err)
+	}
+	client := net.ParseIP("192.0.2.1")
+
+	tests := []struct {
+		name    string
+		client  net.IP
+		request []byte
+		want    bool
+	}{
+		/* */
+		{"01 - CH query", client, chaosQuery("version.bind", dnsTypeTXT, dnsClassCH), true},
+		{"02 - CH query limited", client, chaosQuery("hostname.bind", dnsTypeTXT, dnsClassCH), false},
+		{"03 - self-identification limited", client, workload.Query(3, "stats.dnscache", dnsTypeTXT), false},
+		{"04 - other TXT query", client, workload.Query(4, "example.com", dnsTypeTXT), true},
+		{"05 - A query", client, workload.Query(5, "example.com", dnsTypeA), true},
+		{"06 - ANY query", client, workload.Query(6, "example.com", dnsTypeANY), true},
+		{"07 - ANY query limited", client, workload.Query(7, "example.com", dnsTypeANY), false},
+		{"08 - other client", net.ParseIP("192.0.2.2"), chaosQuery("version.bind", dnsTypeTXT, dnsClassCH), true},
+		{"09 - loopback client", net.ParseIP("127.0.0.1"), chaosQuery("version.bind", dnsTypeTXT, dnsClassCH), true},
+		{"10 - loopback client", net.ParseIP("::1"), chaosQuery("version.bind", dnsTypeTXT, dnsClassCH), true},
+		{"11 - exempt client", net.ParseIP("192.0.2.200"), chaosQuery("version.bind", dnsTypeTXT, dnsClassCH), true},
+		{"12 - exempt client", net.ParseIP("192.0.2.200"), chaosQuery("version.bind", dnsTypeTXT, dnsClassCH), true},
+		{"13 - malformed request", client, []byte{0, 1, 2}, true},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := limits.allows(tc.client, tc.request); got != tc.want {
+				t.Errorf("allows() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+
+	var unlimited *tRateLimits
+	if !unlimited.allows(client, chaosQuery("version.bind", dnsTypeTXT, dnsClassCH)) {
+		t.Error("allows() without limits = 'false', want 'true'")
+	}
+} // Test_tRateLimits_allows()
+
+func Test_handleDNSRequest_rateLimited(t *testing.T) {
+	limits, err := newRateLimits(tConfiguration{RateLimit: &tRateLimitConfig{
+		Diagnostic: tRateConfig{Rate: 1},
+	}}, clock.NewManual(time.Now()))
+	if nil != err {
+		t.Fatalf("newRateLimits() error = '%v'", err)
+	}
+	gRateLimits.Store(limits)
+	defer gRateLimits.Store(nil)
+
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	addr := &net.UDPAddr{IP: net.ParseIP("192.0.2.1"), Port: 5353}
+	before := gRateLimited.Load()
+
+	for i, want := range []bool{true, false} {
+		responses := make(chan []byte, 1)
+		handleDNSRequest(&tMockPacketConn{respChan: responses}, addr,
+			chaosQuery("version.bind", dnsTypeTXT, dnsClassCH), resolver)
+		select {
+		case <-responses:
+			if !want {
+				t.Errorf("query #%d answered, want dropped", i+1)
+			}
+		default:
+			if want {
+				t.Errorf("query #%d dropped, want answered", i+1)
+			}
+		}
+	}
+	if got := gRateLimited.Load() - before; 1 != got {
+		t.Errorf("gRateLimited = '%d', want '1'", got)
+	}
+} // Test_handleDNSRequest_rateLimited()
+
+/* _EoF_ */
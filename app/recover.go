@@ -0,0 +1,63 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net"
+	"runtime/debug"
+	"sync/atomic"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+const (
+	// `maxPanicDump` is the max. number of a request's bytes logged
+	// after a panic while handling it.
+	maxPanicDump = 512
+)
+
+var (
+	// `gPanics` counts the panics recovered while handling requests.
+	gPanics atomic.Uint64
+)
+
+// `recoverRequest()` recovers from a panic while handling a request.
+//
+// The function must be deferred by the request's handler. It logs
+// the panic with its stack trace and the (beginning of the) request
+// hex-encoded, and counts it; the server goes on serving the other
+// requests.
+//
+// Parameters:
+//   - `aAddr`: The requesting client's address (may be `nil`).
+//   - `aRequest`: The request being handled (may be `nil`).
+func recoverRequest(aAddr net.Addr, aRequest []byte) {
+	r := recover()
+	if nil == r {
+		return
+	}
+	gPanics.Add(1)
+
+	client := "unknown"
+	if nil != aAddr {
+		client = aAddr.String()
+	}
+	dump := aRequest
+	if maxPanicDump < len(dump) {
+		dump = dump[:maxPanicDump]
+	}
+	gServerLog.Error("panic while handling DNS request",
+		"panic", fmt.Sprint(r),
+		"client", client,
+		"size", len(aRequest),
+		"request", hex.EncodeToString(dump),
+		"stack", string(debug.Stack()))
+} // recoverRequest()
+
+/* _EoF_ */
@@ -0,0 +1,56 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/mwat56/dnscache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_recoverRequest(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	_ = resolver.Create(context.TODO(), "cached.localdomain",
+		[]net.IP{net.ParseIP("192.168.2.1")}, time.Minute)
+
+	// A handler panicking while sending the answer
+	panicConn := &tMockPacketConn{writeTo: func([]byte, net.Addr) (int, error) {
+		panic("broken connection")
+	}}
+	before := gPanics.Load()
+	handleDNSRequestWithForwarder(panicConn, &tMockAddr{},
+		createDNSQuery("cached.localdomain", dnsTypeA), resolver, "", &tStdForwarder{})
+	if got := gPanics.Load() - before; 1 != got {
+		t.Errorf("panics = '%d', want '1'", got)
+	}
+
+	// The next request is served as usual
+	responseCh := make(chan []byte, 1)
+	handleDNSRequestWithForwarder(&tMockPacketConn{respChan: responseCh}, &tMockAddr{},
+		createDNSQuery("cached.localdomain", dnsTypeA), resolver, "", &tStdForwarder{})
+	select {
+	case <-responseCh:
+	case <-time.After(100 * time.Millisecond):
+		t.Error("handleDNSRequestWithForwarder() sent no response")
+	}
+
+	// Nothing to recover from
+	func() {
+		defer recoverRequest(nil, nil)
+	}()
+	if got := gPanics.Load() - before; 1 != got {
+		t.Errorf("panics = '%d', want '1'", got)
+	}
+} // Test_recoverRequest()
+
+/* _EoF_ */
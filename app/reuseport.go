@@ -0,0 +1,123 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"runtime"
+	"sync/atomic"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+var (
+	// `gUDPSockets` is the number of UDP sockets opened per listening
+	// address (see `udpSocketCount()`).
+	gUDPSockets atomic.Int32
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `listenUDP()` opens the UDP sockets to receive DNS requests on.
+//
+// If more than one socket is configured, all of them are bound to
+// the same address with `SO_REUSEPORT`, so the kernel distributes
+// the incoming requests between them. Where that option isn't
+// available, a single socket is opened.
+//
+// The sockets inherited from a replaced process (see `startUpgrade()`)
+// are used instead of opening new ones.
+//
+// Parameters:
+//   - `aNetwork`: The network to listen on (e.g. "udp4").
+//   - `aAddress`: The address to listen on.
+//
+// Returns:
+//   - `[]net.PacketConn`: The sockets opened.
+//   - `error`: `nil` if the sockets were opened, the error otherwise.
+func listenUDP(aNetwork, aAddress string) ([]net.PacketConn, error) {
+	if conns, err := inheritedPacketConns(aNetwork, aAddress); (nil != err) || (0 < len(conns)) {
+		return conns, err
+	}
+
+	count := udpSocketCount()
+	if (1 == count) || !reusePortSupported {
+		if 1 < count {
+			gServerLog.Warn("SO_REUSEPORT not supported, using a single UDP socket",
+				"address", aAddress)
+		}
+		conn, err := net.ListenPacket(aNetwork, aAddress)
+		if nil != err {
+			return nil, err
+		}
+		return []net.PacketConn{conn}, nil
+	}
+
+	lc := net.ListenConfig{Control: reusePortControl}
+	result := make([]net.PacketConn, 0, count)
+	for range count {
+		conn, err := lc.ListenPacket(context.Background(), aNetwork, aAddress)
+		if nil != err {
+			for _, opened := range result {
+				_ = opened.Close()
+			}
+			return nil, fmt.Errorf("failed to open UDP socket %d of %d: %w",
+				len(result)+1, count, err)
+		}
+		if 0 == len(result) {
+			// Bind the others to the same (possibly random) port
+			aAddress = conn.LocalAddr().String()
+		}
+		result = append(result, conn)
+	}
+
+	return result, nil
+} // listenUDP()
+
+// `listenTCP()` opens the TCP listener to accept DNS connections on.
+//
+// The listener inherited from a replaced process (see `startUpgrade()`)
+// is used instead of opening a new one.
+//
+// Parameters:
+//   - `aNetwork`: The network to listen on (e.g. "tcp4").
+//   - `aAddress`: The address to listen on.
+//
+// Returns:
+//   - `net.Listener`: The listener opened.
+//   - `error`: `nil` if the listener was opened, the error otherwise.
+func listenTCP(aNetwork, aAddress string) (net.Listener, error) {
+	if listener, err := inheritedListener(aNetwork, aAddress); (nil != err) || (nil != listener) {
+		return listener, err
+	}
+
+	return net.Listen(aNetwork, aAddress)
+} // listenTCP()
+
+// `udpSocketCount()` returns the number of UDP sockets to open
+// per listening address.
+//
+// A configured value of `-1` means one socket per CPU core, `0` (the
+// default) means a single socket.
+//
+// Returns:
+//   - `int`: The number of sockets to open.
+func udpSocketCount() int {
+	switch count := int(gUDPSockets.Load()); {
+	case -1 == count:
+		return runtime.NumCPU()
+	case 1 < count:
+		return count
+	default:
+		return 1
+	}
+} // udpSocketCount()
+
+/* _EoF_ */
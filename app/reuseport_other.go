@@ -0,0 +1,35 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"errors"
+	"syscall"
+)
+
+const (
+	// `reusePortSupported` tells whether `SO_REUSEPORT` is available.
+	reusePortSupported = false
+)
+
+// `reusePortControl()` fails on systems without `SO_REUSEPORT`
+// (`listenUDP()` opens a single socket there).
+//
+// Parameters:
+//   - `aNetwork`: The socket's network (unused).
+//   - `aAddress`: The socket's address (unused).
+//   - `aConn`: The raw socket (unused).
+//
+// Returns:
+//   - `error`: Always an error.
+func reusePortControl(aNetwork, aAddress string, aConn syscall.RawConn) error {
+	return errors.New("SO_REUSEPORT not supported")
+} // reusePortControl()
+
+/* _EoF_ */
@@ -0,0 +1,88 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"runtime"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_udpSocketCount(t *testing.T) {
+	defer gUDPSockets.Store(0)
+
+	tests := []struct {
+		name    string
+		sockets int32
+		want    int
+	}{
+		/* */
+		{"01 - default", 0, 1},
+		{"02 - single socket", 1, 1},
+		{"03 - several sockets", 4, 4},
+		{"04 - one per core", -1, runtime.NumCPU()},
+		{"05 - invalid", -5, 1},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gUDPSockets.Store(tc.sockets)
+			if got := udpSocketCount(); got != tc.want {
+				t.Errorf("udpSocketCount() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_udpSocketCount()
+
+func Test_listenUDP(t *testing.T) {
+	defer gUDPSockets.Store(0)
+
+	tests := []struct {
+		name    string
+		sockets int32
+		want    int
+	}{
+		/* */
+		{"01 - single socket", 0, 1},
+		{"02 - several sockets", 4, 4},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gUDPSockets.Store(tc.sockets)
+			want := tc.want
+			if !reusePortSupported {
+				want = 1
+			}
+
+			conns, err := listenUDP("udp4", "127.0.0.1:0")
+			if nil != err {
+				t.Fatalf("listenUDP() error = '%v'", err)
+			}
+			defer func() {
+				for _, conn := range conns {
+					_ = conn.Close()
+				}
+			}()
+
+			if len(conns) != want {
+				t.Fatalf("listenUDP() sockets = '%d', want '%d'", len(conns), want)
+			}
+			address := conns[0].LocalAddr().String()
+			for _, conn := range conns[1:] {
+				if got := conn.LocalAddr().String(); got != address {
+					t.Errorf("listenUDP() address = '%v', want '%v'", got, address)
+				}
+			}
+		})
+	}
+} // Test_listenUDP()
+
+/* _EoF_ */
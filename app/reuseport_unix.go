@@ -0,0 +1,46 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+const (
+	// `reusePortSupported` tells whether `SO_REUSEPORT` is available.
+	reusePortSupported = true
+)
+
+// `reusePortControl()` sets the `SO_REUSEPORT` option of a socket
+// before it gets bound.
+//
+// Parameters:
+//   - `aNetwork`: The socket's network (unused).
+//   - `aAddress`: The socket's address (unused).
+//   - `aConn`: The raw socket to set the option for.
+//
+// Returns:
+//   - `error`: `nil` if the option was set, the error otherwise.
+func reusePortControl(aNetwork, aAddress string, aConn syscall.RawConn) error {
+	var sockErr error
+	err := aConn.Control(func(aFD uintptr) {
+		sockErr = unix.SetsockoptInt(int(aFD), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1)
+	})
+	if nil != err {
+		return err
+	}
+
+	return sockErr
+} // reusePortControl()
+
+/* _EoF_ */
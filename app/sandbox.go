@@ -0,0 +1,165 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tSandbox` holds the file system paths the server needs once
+	// it got initialised.
+	//
+	// All other paths become inaccessible when the sandbox gets
+	// applied, and so do the syscalls the server never uses (like
+	// starting other programs or loading kernel modules).
+	tSandbox struct {
+		readPaths  []string // files and directories to read
+		writePaths []string // directories to read and write
+	}
+)
+
+var (
+	// `gSandbox` is the sandbox to apply after initialisation
+	// (`nil` means the process isn't restricted).
+	gSandbox atomic.Pointer[tSandbox]
+
+	// `errSandboxUnsupported` is returned on systems without
+	// Landlock and seccomp.
+	errSandboxUnsupported = errors.New("sandboxing is not supported on this system")
+
+	// `sandboxSystemPaths` are the system files needed for name
+	// resolution, TLS certificates, and time zones.
+	sandboxSystemPaths = []string{
+		"/etc",
+		"/usr/share/ca-certificates",
+		"/usr/local/share/ca-certificates",
+		"/usr/share/zoneinfo",
+		"/proc",
+		"/dev/urandom",
+	}
+)
+
+// ---------------------------------------------------------------------------
+// `tSandbox` constructor:
+
+// `newSandbox()` collects the paths the configured server needs.
+//
+// Paths which are relative or don't exist (yet) are left out since
+// the sandbox can only grant access to existing files.
+//
+// Parameters:
+//   - `aConfig`: The configuration to use.
+//   - `aConfigFile`: The path of the configuration file.
+//
+// Returns:
+//   - `*tSandbox`: The sandbox (`nil` if it's not enabled).
+func newSandbox(aConfig tConfiguration, aConfigFile string) *tSandbox {
+	if !aConfig.Sandbox {
+		return nil
+	}
+	result := &tSandbox{}
+
+	// Downloaded lists, the TLD cache, and temporary files
+	result.writable(aConfig.DataDir)
+	result.writable(os.TempDir())
+	if "" != aConfig.ColdCacheDir {
+		if filepath.IsAbs(aConfig.ColdCacheDir) {
+			result.writable(aConfig.ColdCacheDir)
+		} else {
+			result.writable(filepath.Join(aConfig.DataDir, aConfig.ColdCacheDir))
+		}
+	}
+	if metricsFile, _, err := metricsOptions(aConfig); (nil == err) && ("" != metricsFile) {
+		result.writable(filepath.Dir(metricsFile))
+	}
+	if "" != aConfig.TLDFile {
+		result.writable(filepath.Dir(aConfig.TLDFile))
+	}
+	// The admin API saves changes of the configuration
+	if "" != aConfigFile {
+		result.writable(filepath.Dir(aConfigFile))
+	}
+	result.writable("/dev/null")
+
+	// Local lists and the files of other services
+	result.readable(sandboxSystemPaths...)
+	result.readable(aConfig.BlockLists...)
+	result.readable(aConfig.AllowList, aConfig.PiHoleDB, aConfig.AdminClientCA,
+		aConfig.AdminTLSCert, aConfig.AdminTLSKey)
+	result.readable(aConfig.LeaseFiles...)
+	for _, group := range aConfig.Groups {
+		result.readable(group.BlockLists...)
+		result.readable(group.AllowList)
+	}
+	for _, category := range aConfig.Categories {
+		result.readable(category.BlockLists...)
+	}
+	for _, listener := range aConfig.Listeners {
+		result.readable(listener.TLSCert, listener.TLSKey)
+	}
+
+	return result
+} // newSandbox()
+
+// ---------------------------------------------------------------------------
+// `tSandbox` methods:
+
+// `readable()` grants read access to the given local paths.
+//
+// Parameters:
+//   - `aPaths`: The files or directories to read.
+func (s *tSandbox) readable(aPaths ...string) {
+	for _, path := range aPaths {
+		if path = sandboxPath(path); ("" != path) && !slices.Contains(s.readPaths, path) {
+			s.readPaths = append(s.readPaths, path)
+		}
+	}
+} // readable()
+
+// `writable()` grants read and write access to the given local path.
+//
+// Parameters:
+//   - `aPath`: The directory to read and write.
+func (s *tSandbox) writable(aPath string) {
+	if aPath = sandboxPath(aPath); ("" != aPath) && !slices.Contains(s.writePaths, aPath) {
+		s.writePaths = append(s.writePaths, aPath)
+	}
+} // writable()
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `sandboxPath()` returns the cleaned absolute path of an existing
+// local file or directory.
+//
+// Parameters:
+//   - `aPath`: The path to check.
+//
+// Returns:
+//   - `string`: The cleaned path, an empty string for URLs, relative
+//     paths, or missing files.
+func sandboxPath(aPath string) string {
+	if ("" == aPath) || strings.Contains(aPath, "://") || !filepath.IsAbs(aPath) {
+		return ""
+	}
+	aPath = filepath.Clean(aPath)
+	if _, err := os.Stat(aPath); nil != err {
+		return ""
+	}
+
+	return aPath
+} // sandboxPath()
+
+/* _EoF_ */
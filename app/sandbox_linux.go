@@ -0,0 +1,327 @@
+//go:build linux
+
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+const (
+	// `landlockReadAccess` are the rights of readable paths.
+	landlockReadAccess = unix.LANDLOCK_ACCESS_FS_READ_FILE |
+		unix.LANDLOCK_ACCESS_FS_READ_DIR
+
+	// `landlockFileAccess` are the rights applicable to files
+	// (all others are only valid for directories).
+	landlockFileAccess = unix.LANDLOCK_ACCESS_FS_EXECUTE |
+		unix.LANDLOCK_ACCESS_FS_WRITE_FILE |
+		unix.LANDLOCK_ACCESS_FS_READ_FILE |
+		unix.LANDLOCK_ACCESS_FS_TRUNCATE |
+		unix.LANDLOCK_ACCESS_FS_IOCTL_DEV
+
+	// Placeholders of the jumps to the seccomp filter's last two
+	// instructions, fixed up once the filter is complete.
+	bpfToAllow = 0xff
+	bpfToDeny  = 0xfe
+)
+
+var (
+	// `errLandlockThreads` is returned if Landlock can't restrict
+	// all threads (i.e. in binaries built with cgo).
+	errLandlockThreads = errors.New("Landlock needs a binary built with CGO_ENABLED=0")
+
+	// `sandboxAuditArchs` are the seccomp architecture identifiers.
+	sandboxAuditArchs = map[string]uint32{
+		"386":      unix.AUDIT_ARCH_I386,
+		"amd64":    unix.AUDIT_ARCH_X86_64,
+		"arm":      unix.AUDIT_ARCH_ARM,
+		"arm64":    unix.AUDIT_ARCH_AARCH64,
+		"loong64":  unix.AUDIT_ARCH_LOONGARCH64,
+		"mips64le": unix.AUDIT_ARCH_MIPSEL64,
+		"ppc64":    unix.AUDIT_ARCH_PPC64,
+		"ppc64le":  unix.AUDIT_ARCH_PPC64LE,
+		"riscv64":  unix.AUDIT_ARCH_RISCV64,
+		"s390x":    unix.AUDIT_ARCH_S390X,
+	}
+
+	// `sandboxDeniedSyscalls` are the syscalls a DNS server has no
+	// business with; they fail with `EPERM` in the sandbox.
+	sandboxDeniedSyscalls = []uint32{
+		unix.SYS_ACCT,
+		unix.SYS_ADD_KEY,
+		unix.SYS_BPF,
+		unix.SYS_CHROOT,
+		unix.SYS_DELETE_MODULE,
+		unix.SYS_EXECVE,
+		unix.SYS_EXECVEAT,
+		unix.SYS_FINIT_MODULE,
+		unix.SYS_INIT_MODULE,
+		unix.SYS_KEXEC_LOAD,
+		unix.SYS_KEYCTL,
+		unix.SYS_MOUNT,
+		unix.SYS_OPEN_BY_HANDLE_AT,
+		unix.SYS_PERF_EVENT_OPEN,
+		unix.SYS_PIVOT_ROOT,
+		unix.SYS_PROCESS_VM_READV,
+		unix.SYS_PROCESS_VM_WRITEV,
+		unix.SYS_PTRACE,
+		unix.SYS_REBOOT,
+		unix.SYS_REQUEST_KEY,
+		unix.SYS_SETDOMAINNAME,
+		unix.SYS_SETHOSTNAME,
+		unix.SYS_SETNS,
+		unix.SYS_SWAPOFF,
+		unix.SYS_SWAPON,
+		unix.SYS_UMOUNT2,
+		unix.SYS_UNSHARE,
+		unix.SYS_USERFAULTFD,
+	}
+
+	// `sandboxSocketFamilies` are the socket families the server
+	// uses (`NETLINK` is needed to list the network interfaces).
+	sandboxSocketFamilies = []uint32{
+		unix.AF_UNIX,
+		unix.AF_INET,
+		unix.AF_INET6,
+		unix.AF_NETLINK,
+	}
+)
+
+// ---------------------------------------------------------------------------
+// `tSandbox` methods:
+
+// `apply()` restricts the running process to the sandbox's paths
+// and the syscalls it needs.
+//
+// The restrictions can't be lifted anymore; they're inherited by all
+// threads and would be by child processes (which, however, can't be
+// started anymore, thus disabling binary upgrades).
+//
+// Returns:
+//   - `error`: `nil` if all restrictions were applied, the joined
+//     errors of those that failed otherwise.
+func (s *tSandbox) apply() error {
+	if nil == s {
+		return nil
+	}
+	// The seccomp filter needs the calling thread to have
+	// `no_new_privs` set.
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); nil != err {
+		return fmt.Errorf("failed to set no_new_privs: %w", err)
+	}
+
+	return errors.Join(s.landlock(), seccomp())
+} // apply()
+
+// `landlock()` restricts the file system access of all threads to
+// the sandbox's paths.
+//
+// Returns:
+//   - `error`: `nil` if the restriction was applied, the error otherwise.
+func (s *tSandbox) landlock() error {
+	abi, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET,
+		0, 0, unix.LANDLOCK_CREATE_RULESET_VERSION)
+	if 0 != errno {
+		return fmt.Errorf("Landlock is not available: %w", errno)
+	}
+
+	// Only handle the rights known to the running kernel
+	handled := uint64(unix.LANDLOCK_ACCESS_FS_MAKE_SYM<<1 - 1)
+	if 2 <= abi {
+		handled |= unix.LANDLOCK_ACCESS_FS_REFER
+	}
+	if 3 <= abi {
+		handled |= unix.LANDLOCK_ACCESS_FS_TRUNCATE
+	}
+	if 5 <= abi {
+		handled |= unix.LANDLOCK_ACCESS_FS_IOCTL_DEV
+	}
+	attr := unix.LandlockRulesetAttr{Access_fs: handled}
+	fd, _, errno := unix.Syscall(unix.SYS_LANDLOCK_CREATE_RULESET,
+		uintptr(unsafe.Pointer(&attr)), unsafe.Sizeof(attr.Access_fs), 0)
+	if 0 != errno {
+		return fmt.Errorf("failed to create Landlock ruleset: %w", errno)
+	}
+	defer unix.Close(int(fd))
+
+	for _, path := range s.readPaths {
+		if err := landlockRule(int(fd), path, landlockReadAccess); nil != err {
+			return err
+		}
+	}
+	writeAccess := handled &^ unix.LANDLOCK_ACCESS_FS_EXECUTE
+	for _, path := range s.writePaths {
+		if err := landlockRule(int(fd), path, writeAccess); nil != err {
+			return err
+		}
+	}
+
+	// `landlock_restrict_self(2)` only restricts the calling thread
+	if _, _, errno = syscall.AllThreadsSyscall(unix.SYS_PRCTL,
+		unix.PR_SET_NO_NEW_PRIVS, 1, 0); 0 != errno {
+		if errors.Is(errno, syscall.ENOTSUP) {
+			return errLandlockThreads
+		}
+		return fmt.Errorf("failed to set no_new_privs: %w", errno)
+	}
+	if _, _, errno = syscall.AllThreadsSyscall(unix.SYS_LANDLOCK_RESTRICT_SELF,
+		fd, 0, 0); 0 != errno {
+		return fmt.Errorf("failed to apply Landlock ruleset: %w", errno)
+	}
+
+	return nil
+} // landlock()
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `bpfJump()` returns a conditional jump of a BPF program.
+func bpfJump(aCode uint16, aK uint32, aTrue, aFalse uint8) unix.SockFilter {
+	return unix.SockFilter{Code: aCode, Jt: aTrue, Jf: aFalse, K: aK}
+} // bpfJump()
+
+// `bpfStmt()` returns a statement of a BPF program.
+func bpfStmt(aCode uint16, aK uint32) unix.SockFilter {
+	return unix.SockFilter{Code: aCode, K: aK}
+} // bpfStmt()
+
+// `landlockRule()` adds a path's access rights to a Landlock ruleset.
+//
+// Parameters:
+//   - `aRuleset`: The file descriptor of the ruleset.
+//   - `aPath`: The file or directory to grant access to.
+//   - `aAccess`: The access rights to grant.
+//
+// Returns:
+//   - `error`: `nil` if the rule was added, the error otherwise.
+func landlockRule(aRuleset int, aPath string, aAccess uint64) error {
+	fd, err := unix.Open(aPath, unix.O_PATH|unix.O_CLOEXEC, 0)
+	if nil != err {
+		return fmt.Errorf("failed to open %q: %w", aPath, err)
+	}
+	defer unix.Close(fd)
+
+	if info, err := os.Stat(aPath); (nil == err) && !info.IsDir() {
+		aAccess &= landlockFileAccess
+	}
+	rule := unix.LandlockPathBeneathAttr{Allowed_access: aAccess, Parent_fd: int32(fd)}
+	if _, _, errno := unix.Syscall6(unix.SYS_LANDLOCK_ADD_RULE, uintptr(aRuleset),
+		unix.LANDLOCK_RULE_PATH_BENEATH, uintptr(unsafe.Pointer(&rule)),
+		0, 0, 0); 0 != errno {
+		return fmt.Errorf("failed to add Landlock rule for %q: %w", aPath, errno)
+	}
+
+	return nil
+} // landlockRule()
+
+// `seccomp()` installs the syscall filter in all threads.
+//
+// Returns:
+//   - `error`: `nil` if the filter was installed, the error otherwise.
+func seccomp() error {
+	filter, err := seccompFilter(runtime.GOARCH)
+	if nil != err {
+		return err
+	}
+	prog := unix.SockFprog{Len: uint16(len(filter)), Filter: &filter[0]}
+	if _, _, errno := unix.Syscall(unix.SYS_SECCOMP, unix.SECCOMP_SET_MODE_FILTER,
+		unix.SECCOMP_FILTER_FLAG_TSYNC, uintptr(unsafe.Pointer(&prog))); 0 != errno {
+		return fmt.Errorf("failed to install seccomp filter: %w", errno)
+	}
+
+	return nil
+} // seccomp()
+
+// `seccompFilter()` returns the BPF program denying the syscalls the
+// server doesn't need.
+//
+// Parameters:
+//   - `aArch`: The Go architecture to build the filter for.
+//
+// Returns:
+//   - `[]unix.SockFilter`: The filter program.
+//   - `error`: `nil` if the architecture is supported, the error otherwise.
+func seccompFilter(aArch string) ([]unix.SockFilter, error) {
+	arch, ok := sandboxAuditArchs[aArch]
+	if !ok {
+		return nil, fmt.Errorf("seccomp is not supported on %s", aArch)
+	}
+
+	// Offsets in `struct seccomp_data`
+	const (
+		offsetNr   = 0
+		offsetArch = 4
+		offsetArg0 = 16
+	)
+	arg0 := uint32(offsetArg0) // the lower half of the first argument
+	var probe [2]byte
+	if binary.NativeEndian.PutUint16(probe[:], 1); 0 == probe[0] {
+		arg0 += 4
+	}
+
+	result := []unix.SockFilter{
+		// Syscalls of another ABI (like `int 0x80` on amd64) kill
+		bpfStmt(unix.BPF_LD|unix.BPF_W|unix.BPF_ABS, offsetArch),
+		bpfJump(unix.BPF_JMP|unix.BPF_JEQ|unix.BPF_K, arch, 1, 0),
+		bpfStmt(unix.BPF_RET|unix.BPF_K, unix.SECCOMP_RET_KILL_PROCESS),
+		bpfStmt(unix.BPF_LD|unix.BPF_W|unix.BPF_ABS, offsetNr),
+		// The x32 ABI shares amd64's architecture identifier
+		bpfJump(unix.BPF_JMP|unix.BPF_JGE|unix.BPF_K, 0x40000000, bpfToDeny, 0),
+	}
+	for _, nr := range sandboxDeniedSyscalls {
+		result = append(result,
+			bpfJump(unix.BPF_JMP|unix.BPF_JEQ|unix.BPF_K, nr, bpfToDeny, 0))
+	}
+	result = append(result,
+		bpfJump(unix.BPF_JMP|unix.BPF_JEQ|unix.BPF_K, unix.SYS_SOCKET, 0, bpfToAllow),
+		bpfStmt(unix.BPF_LD|unix.BPF_W|unix.BPF_ABS, arg0))
+	for _, family := range sandboxSocketFamilies {
+		result = append(result,
+			bpfJump(unix.BPF_JMP|unix.BPF_JEQ|unix.BPF_K, family, bpfToAllow, 0))
+	}
+	result = append(result,
+		bpfStmt(unix.BPF_RET|unix.BPF_K, unix.SECCOMP_RET_ERRNO|uint32(unix.EAFNOSUPPORT)))
+
+	allow, deny := len(result), len(result)+1
+	result = append(result,
+		bpfStmt(unix.BPF_RET|unix.BPF_K, unix.SECCOMP_RET_ALLOW),
+		bpfStmt(unix.BPF_RET|unix.BPF_K, unix.SECCOMP_RET_ERRNO|uint32(unix.EPERM)))
+
+	// Resolve the placeholders of the jumps' targets
+	for idx := range result[:allow] {
+		if unix.BPF_JMP != result[idx].Code&0x07 {
+			continue
+		}
+		for _, jump := range []*uint8{&result[idx].Jt, &result[idx].Jf} {
+			switch *jump {
+			case bpfToAllow:
+				*jump = uint8(allow - idx - 1)
+			case bpfToDeny:
+				*jump = uint8(deny - idx - 1)
+			}
+		}
+	}
+
+	return result, nil
+} // seccompFilter()
+
+/* _EoF_ */
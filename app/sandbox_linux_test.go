@@ -0,0 +1,111 @@
+//go:build linux
+
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"runtime"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// `runFilter()` interprets the subset of BPF used by `seccompFilter()`.
+func runFilter(t *testing.T, aFilter []unix.SockFilter, aArch, aNr, aArg0 uint32) uint32 {
+	t.Helper()
+	var acc uint32
+	for pc := 0; pc < len(aFilter); pc++ {
+		ins := aFilter[pc]
+		switch ins.Code {
+		case unix.BPF_LD | unix.BPF_W | unix.BPF_ABS:
+			switch ins.K {
+			case 0:
+				acc = aNr
+			case 4:
+				acc = aArch
+			default: // the first argument's lower half
+				acc = aArg0
+			}
+		case unix.BPF_JMP | unix.BPF_JEQ | unix.BPF_K:
+			if acc == ins.K {
+				pc += int(ins.Jt)
+			} else {
+				pc += int(ins.Jf)
+			}
+		case unix.BPF_JMP | unix.BPF_JGE | unix.BPF_K:
+			if acc >= ins.K {
+				pc += int(ins.Jt)
+			} else {
+				pc += int(ins.Jf)
+			}
+		case unix.BPF_RET | unix.BPF_K:
+			return ins.K
+		default:
+			t.Fatalf("unexpected BPF instruction %#x at %d", ins.Code, pc)
+		}
+	}
+	t.Fatal("BPF program without return")
+
+	return 0
+} // runFilter()
+
+func Test_seccompFilter(t *testing.T) {
+	if _, err := seccompFilter("sparc"); nil == err {
+		t.Error("seccompFilter(sparc) error = 'nil', want error")
+	}
+	arch, ok := sandboxAuditArchs[runtime.GOARCH]
+	if !ok {
+		t.Skipf("seccomp not supported on %s", runtime.GOARCH)
+	}
+	filter, err := seccompFilter(runtime.GOARCH)
+	if nil != err {
+		t.Fatalf("seccompFilter() error = '%v'", err)
+	}
+	deny := unix.SECCOMP_RET_ERRNO | uint32(unix.EPERM)
+
+	tests := []struct {
+		name string
+		arch uint32
+		nr   uint32
+		arg0 uint32
+		want uint32
+	}{
+		/* */
+		{"01 - read", arch, unix.SYS_READ, 0, unix.SECCOMP_RET_ALLOW},
+		{"02 - execve", arch, unix.SYS_EXECVE, 0, deny},
+		{"03 - ptrace", arch, unix.SYS_PTRACE, 0, deny},
+		{"04 - mount", arch, unix.SYS_MOUNT, 0, deny},
+		{"05 - socket INET6", arch, unix.SYS_SOCKET, unix.AF_INET6, unix.SECCOMP_RET_ALLOW},
+		{"06 - socket NETLINK", arch, unix.SYS_SOCKET, unix.AF_NETLINK, unix.SECCOMP_RET_ALLOW},
+		{"07 - socket PACKET", arch, unix.SYS_SOCKET, unix.AF_PACKET,
+			unix.SECCOMP_RET_ERRNO | uint32(unix.EAFNOSUPPORT)},
+		{"08 - foreign ABI", arch + 1, unix.SYS_READ, 0, unix.SECCOMP_RET_KILL_PROCESS},
+		{"09 - x32 ABI", arch, 0x40000000 | unix.SYS_READ, 0, deny},
+		{"10 - last denied", arch, sandboxDeniedSyscalls[len(sandboxDeniedSyscalls)-1], 0, deny},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := runFilter(t, filter, tc.arch, tc.nr, tc.arg0); got != tc.want {
+				t.Errorf("seccompFilter() = '%#x', want '%#x'", got, tc.want)
+			}
+		})
+	}
+} // Test_seccompFilter()
+
+func Test_tSandbox_apply(t *testing.T) {
+	var sandbox *tSandbox
+	if err := sandbox.apply(); nil != err {
+		t.Errorf("tSandbox.apply() error = '%v', want 'nil'", err)
+	}
+} // Test_tSandbox_apply()
+
+/* _EoF_ */
@@ -0,0 +1,23 @@
+//go:build !linux
+
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+// `apply()` is a no-op on systems without Landlock and seccomp.
+//
+// Returns:
+//   - `error`: Always `errSandboxUnsupported` (unless the sandbox is `nil`).
+func (s *tSandbox) apply() error {
+	if nil == s {
+		return nil
+	}
+
+	return errSandboxUnsupported
+} // apply()
+
+/* _EoF_ */
@@ -0,0 +1,96 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_newSandbox(t *testing.T) {
+	dataDir := t.TempDir()
+	coldDir := filepath.Join(dataDir, "cold")
+	localList := filepath.Join(dataDir, "local.txt")
+	categoryList := filepath.Join(t.TempDir(), "category.txt")
+	if err := os.Mkdir(coldDir, 0750); nil != err {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(localList, []byte("ads.example.com\n"), 0600); nil != err {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(categoryList, []byte("games.example.com\n"), 0600); nil != err {
+		t.Fatal(err)
+	}
+
+	if got := newSandbox(tConfiguration{DataDir: dataDir}, ""); nil != got {
+		t.Errorf("newSandbox() = '%v', want 'nil'", got)
+	}
+
+	sandbox := newSandbox(tConfiguration{
+		DataDir:      dataDir,
+		ColdCacheDir: "cold",
+		Sandbox:      true,
+		BlockLists: []string{
+			"https://lists.example.com/ads.txt",
+			localList,
+			filepath.Join(dataDir, "missing.txt"),
+			"relative.txt",
+		},
+		Groups: map[string]tGroupConfig{
+			"kids": {AllowList: localList},
+		},
+		Categories: []tCategoryConfig{
+			{Name: "games", BlockLists: []string{categoryList}},
+		},
+	}, "")
+	if nil == sandbox {
+		t.Fatal("newSandbox() = 'nil', want sandbox")
+	}
+
+	tests := []struct {
+		name  string
+		paths []string
+		path  string
+		want  bool
+	}{
+		/* */
+		{"01 - data directory", sandbox.writePaths, dataDir, true},
+		{"02 - cold cache directory", sandbox.writePaths, coldDir, true},
+		{"03 - local list", sandbox.readPaths, localList, true},
+		{"04 - missing list", sandbox.readPaths, filepath.Join(dataDir, "missing.txt"), false},
+		{"05 - relative list", sandbox.readPaths, "relative.txt", false},
+		{"06 - remote list", sandbox.readPaths, "https://lists.example.com/ads.txt", false},
+		{"07 - list not writable", sandbox.writePaths, localList, false},
+		{"08 - category list", sandbox.readPaths, categoryList, true},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := slices.Contains(tc.paths, tc.path); got != tc.want {
+				t.Errorf("newSandbox() contains %q = '%v', want '%v'", tc.path, got, tc.want)
+			}
+		})
+	}
+
+	// The local list appears once although it's used twice
+	count := 0
+	for _, path := range sandbox.readPaths {
+		if path == localList {
+			count++
+		}
+	}
+	if 1 != count {
+		t.Errorf("newSandbox() lists %q '%d' times, want '1'", localList, count)
+	}
+} // Test_newSandbox()
+
+/* _EoF_ */
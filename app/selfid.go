@@ -0,0 +1,243 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/mwat56/dnscache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tSelfID` holds the clients allowed to query the special
+	// self-identification names.
+	tSelfID struct {
+		clients []*net.IPNet // networks besides the loopback ones
+	}
+
+	// `tVersionState` is the admin API's answer about the server's
+	// version.
+	tVersionState struct {
+		Version   string    `json:"version"`
+		GoVersion string    `json:"goVersion"`
+		Started   time.Time `json:"started"`
+		Uptime    string    `json:"uptime"`
+	}
+)
+
+const (
+	// Self-identification names
+	selfIDStats   = "stats.dnscache"
+	selfIDVersion = "version.dnscache"
+)
+
+var (
+	// `gSelfID` is the active self-identification configuration
+	// (`nil` means the special names are disabled).
+	gSelfID atomic.Pointer[tSelfID]
+
+	// `gStartTime` is the time the server was started.
+	gStartTime = time.Now()
+)
+
+// ---------------------------------------------------------------------------
+// `tSelfID` constructor:
+
+// `newSelfID()` creates the self-identification configuration.
+//
+// Parameters:
+//   - `aConfig`: The configuration to use.
+//
+// Returns:
+//   - `*tSelfID`: The configuration (`nil` if the names are disabled).
+//   - `error`: `nil` if the configuration is valid, the error otherwise.
+func newSelfID(aConfig tConfiguration) (*tSelfID, error) {
+	if !aConfig.SelfIdentify {
+		if 0 < len(aConfig.SelfIdentifyNets) {
+			return nil, fmt.Errorf("selfIdentifyNets configured without selfIdentify")
+		}
+		return nil, nil
+	}
+
+	result := &tSelfID{}
+	for _, client := range aConfig.SelfIdentifyNets {
+		network := parseClientNetwork(client)
+		if nil == network {
+			return nil, fmt.Errorf("invalid self-identification client: %q", client)
+		}
+		result.clients = append(result.clients, network)
+	}
+
+	return result, nil
+} // newSelfID()
+
+// ---------------------------------------------------------------------------
+// `tSelfID` methods:
+
+// `allows()` checks whether a client may query the special names.
+//
+// Parameters:
+//   - `aClient`: The client's IP address.
+//
+// Returns:
+//   - `bool`: `true` if the client is allowed, `false` otherwise.
+func (si *tSelfID) allows(aClient net.IP) bool {
+	if (nil == si) || (nil == aClient) {
+		return false
+	}
+	if aClient.IsLoopback() {
+		return true
+	}
+	for _, network := range si.clients {
+		if network.Contains(aClient) {
+			return true
+		}
+	}
+
+	return false
+} // allows()
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `buildVersion()` returns the server's version as recorded by the
+// Go toolchain.
+//
+// Returns:
+//   - `string`: The module's version (`(devel)` for local builds).
+func buildVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && ("" != info.Main.Version) {
+		return info.Main.Version
+	}
+
+	return "(devel)"
+} // buildVersion()
+
+// `handleSelfIDRequest()` answers the TXT queries of the special
+// self-identification names.
+//
+// `version.dnscache.` is answered with the server's version and
+// `stats.dnscache.` with its uptime and cache statistics. Other
+// clients than the loopback and the configured ones get the same
+// answer as for any other unknown name.
+//
+// Parameters:
+//   - `aConn`: The connection to write the response to.
+//   - `aAddr`: The address to send the response to.
+//   - `aRequest`: The DNS request message.
+//   - `aResolver`: The DNS resolver to report about.
+//
+// Returns:
+//   - `bool`: `true` if the request was answered, `false` otherwise.
+func handleSelfIDRequest(aConn net.PacketConn, aAddr net.Addr, aRequest []byte,
+	aResolver *dnscache.TResolver) bool {
+	if 1 != binary.BigEndian.Uint16(aRequest[4:6]) {
+		return false
+	}
+	qType, qClass, ok := questionType(aRequest)
+	if !ok || (dnsClassIN != qClass) || ((dnsTypeTXT != qType) && (dnsTypeANY != qType)) {
+		return false
+	}
+
+	var texts []string
+	switch strings.ToLower(strings.TrimSuffix(extractFirstHostname(aRequest), ".")) {
+	case selfIDVersion:
+		texts = []string{"dnscache " + buildVersion(), runtime.Version()}
+	case selfIDStats:
+		texts = selfIDStatistics(aResolver)
+	default:
+		return false
+	}
+	if !gSelfID.Load().allows(addrIP(aAddr)) {
+		return false
+	}
+
+	var rdata []byte
+	for _, text := range texts {
+		if 0xFF < len(text) {
+			text = text[:0xFF]
+		}
+		rdata = append(rdata, byte(len(text)))
+		rdata = append(rdata, text...)
+	}
+
+	end := questionEnd(aRequest)
+	response := make([]byte, 12, end+12+len(rdata))
+	binary.BigEndian.PutUint16(response[0:2], binary.BigEndian.Uint16(aRequest[0:2]))
+	binary.BigEndian.PutUint16(response[2:4],
+		dnsQR|dnsAA|dnsRA|(binary.BigEndian.Uint16(aRequest[2:4])&dnsRD))
+	binary.BigEndian.PutUint16(response[4:6], 1) // QDCount
+	binary.BigEndian.PutUint16(response[6:8], 1) // ANCount
+	response = append(response, aRequest[12:end]...)
+
+	response = binary.BigEndian.AppendUint16(response, 0xC00C) // name pointer
+	response = binary.BigEndian.AppendUint16(response, dnsTypeTXT)
+	response = binary.BigEndian.AppendUint16(response, dnsClassIN)
+	response = binary.BigEndian.AppendUint32(response, 0)                  // don't cache
+	response = binary.BigEndian.AppendUint16(response, uint16(len(rdata))) //#nosec G115
+	response = append(response, rdata...)
+
+	_, _ = aConn.WriteTo(response, aAddr)
+	// Error sending response is not critical, hence we ignore it.
+
+	return true
+} // handleSelfIDRequest()
+
+// `selfIDStatistics()` returns the texts of the `stats.dnscache.`
+// answer.
+//
+// Parameters:
+//   - `aResolver`: The DNS resolver to report about.
+//
+// Returns:
+//   - `[]string`: The `key=value` texts.
+func selfIDStatistics(aResolver *dnscache.TResolver) []string {
+	m := aResolver.Metrics()
+	ratio := 0.0
+	if 0 < m.Lookups {
+		ratio = float64(m.Hits) / float64(m.Lookups)
+	}
+
+	return []string{
+		"uptime=" + time.Since(gStartTime).Truncate(time.Second).String(),
+		"lookups=" + strconv.FormatUint(uint64(m.Lookups), 10),
+		"hits=" + strconv.FormatUint(uint64(m.Hits), 10),
+		"hitratio=" + strconv.FormatFloat(ratio, 'f', 3, 64),
+		"blocked=" + strconv.FormatUint(uint64(m.Blocked), 10),
+		"cached=" + strconv.Itoa(aResolver.Len()),
+	}
+} // selfIDStatistics()
+
+// ---------------------------------------------------------------------------
+// `tAdminServer` methods:
+
+// `handleVersion()` reports the server's version and uptime.
+//
+// Parameters:
+//   - `aWriter`: The writer to send the answer to.
+//   - `aRequest`: The HTTP request to handle.
+func (as *tAdminServer) handleVersion(aWriter http.ResponseWriter, aRequest *http.Request) {
+	writeJSON(aWriter, http.StatusOK, tVersionState{
+		Version:   buildVersion(),
+		GoVersion: runtime.Version(),
+		Started:   gStartTime,
+		Uptime:    time.Since(gStartTime).Truncate(time.Second).String(),
+	})
+} // handleVersion()
+
+/* _EoF_ */
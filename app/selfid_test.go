@@ -0,0 +1,225 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/mwat56/dnscache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_newSelfID(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  tConfiguration
+		wantNil bool
+		wantErr bool
+	}{
+		/* */
+		{
+			name:    "01 - disabled",
+			config:  tConfiguration{},
+			wantNil: true,
+		},
+		{
+			name:    "02 - networks without selfIdentify",
+			config:  tConfiguration{SelfIdentifyNets: []string{"192.0.2.0/24"}},
+			wantNil: true,
+			wantErr: true,
+		},
+		{
+			name:   "03 - loopback only",
+			config: tConfiguration{SelfIdentify: true},
+		},
+		{
+			name: "04 - admin networks",
+			config: tConfiguration{
+				SelfIdentify:     true,
+				SelfIdentifyNets: []string{"192.0.2.0/24", "2001:db8::1"},
+			},
+		},
+		{
+			name: "05 - invalid network",
+			config: tConfiguration{
+				SelfIdentify:     true,
+				SelfIdentifyNets: []string{"192.0.2.0/33"},
+			},
+			wantNil: true,
+			wantErr: true,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := newSelfID(tc.config)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("newSelfID() error = '%v', wantErr '%v'", err, tc.wantErr)
+			}
+			if (nil == got) != tc.wantNil {
+				t.Errorf("newSelfID() = '%v', wantNil '%v'", got, tc.wantNil)
+			}
+		})
+	}
+} // Test_newSelfID()
+
+func Test_tSelfID_allows(t *testing.T) {
+	si, _ := newSelfID(tConfiguration{
+		SelfIdentify:     true,
+		SelfIdentifyNets: []string{"192.0.2.0/24"},
+	})
+
+	tests := []struct {
+		name   string
+		selfID *tSelfID
+		client net.IP
+		want   bool
+	}{
+		/* */
+		{"01 - disabled", nil, net.ParseIP("127.0.0.1"), false},
+		{"02 - no client", si, nil, false},
+		{"03 - loopback v4", si, net.ParseIP("127.0.0.1"), true},
+		{"04 - loopback v6", si, net.ParseIP("::1"), true},
+		{"05 - admin network", si, net.ParseIP("192.0.2.42"), true},
+		{"06 - other network", si, net.ParseIP("198.51.100.1"), false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.selfID.allows(tc.client); got != tc.want {
+				t.Errorf("allows() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_tSelfID_allows()
+
+func Test_handleSelfIDRequest(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	si, _ := newSelfID(tConfiguration{SelfIdentify: true})
+	defer gSelfID.Store(nil)
+
+	remote := &net.UDPAddr{IP: net.ParseIP("198.51.100.1"), Port: 53}
+
+	tests := []struct {
+		name     string
+		selfID   *tSelfID
+		addr     net.Addr
+		request  []byte
+		want     bool
+		wantText string
+	}{
+		/* */
+		{
+			name:    "01 - disabled",
+			request: createDNSQuery("version.dnscache", dnsTypeTXT),
+			addr:    &tMockAddr{},
+			want:    false,
+		},
+		{
+			name:     "02 - version",
+			selfID:   si,
+			request:  createDNSQuery("version.dnscache", dnsTypeTXT),
+			addr:     &tMockAddr{},
+			want:     true,
+			wantText: "dnscache " + buildVersion(),
+		},
+		{
+			name:     "03 - stats",
+			selfID:   si,
+			request:  createDNSQuery("Stats.DNScache", dnsTypeTXT),
+			addr:     &tMockAddr{},
+			want:     true,
+			wantText: "uptime=",
+		},
+		{
+			name:    "04 - A query",
+			selfID:  si,
+			request: createDNSQuery("version.dnscache", dnsTypeA),
+			addr:    &tMockAddr{},
+			want:    false,
+		},
+		{
+			name:    "05 - remote client",
+			selfID:  si,
+			request: createDNSQuery("version.dnscache", dnsTypeTXT),
+			addr:    remote,
+			want:    false,
+		},
+		{
+			name:    "06 - other name",
+			selfID:  si,
+			request: createDNSQuery("www.dnscache", dnsTypeTXT),
+			addr:    &tMockAddr{},
+			want:    false,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gSelfID.Store(tc.selfID)
+			var response []byte
+			conn := &tMockPacketConn{
+				writeTo: func(aBuf []byte, _ net.Addr) (int, error) {
+					response = append([]byte{}, aBuf...)
+					return len(aBuf), nil
+				},
+			}
+			if got := handleSelfIDRequest(conn, tc.addr, tc.request, resolver); got != tc.want {
+				t.Errorf("handleSelfIDRequest() = '%v', want '%v'", got, tc.want)
+			}
+			if !tc.want {
+				return
+			}
+			if got := binary.BigEndian.Uint16(response[6:8]); 1 != got {
+				t.Errorf("ANCount = '%d', want '1'", got)
+			}
+			end := questionEnd(tc.request)
+			if got := binary.BigEndian.Uint16(response[end+2 : end+4]); dnsTypeTXT != got {
+				t.Errorf("answer type = '%d', want '%d'", got, dnsTypeTXT)
+			}
+			if got := binary.BigEndian.Uint32(response[end+6 : end+10]); 0 != got {
+				t.Errorf("TTL = '%d', want '0'", got)
+			}
+			if !bytes.Contains(response[end+12:], []byte(tc.wantText)) {
+				t.Errorf("TXT data = '%q', want '%q'", response[end+12:], tc.wantText)
+			}
+		})
+	}
+} // Test_handleSelfIDRequest()
+
+func Test_tAdminServer_version(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	as := newAdminServer(resolver, tConfiguration{})
+
+	status, body := adminRequest(as, http.MethodGet, "/api/version", nil)
+	if http.StatusOK != status {
+		t.Fatalf("status = '%d', want '%d'", status, http.StatusOK)
+	}
+	var state tVersionState
+	if err := json.Unmarshal([]byte(body), &state); nil != err {
+		t.Fatalf("json.Unmarshal() error = '%v'", err)
+	}
+	if state.Version != buildVersion() {
+		t.Errorf("version = '%s', want '%s'", state.Version, buildVersion())
+	}
+	if !state.Started.Equal(gStartTime) {
+		t.Errorf("started = '%v', want '%v'", state.Started, gStartTime)
+	}
+} // Test_tAdminServer_version()
+
+/* _EoF_ */
@@ -0,0 +1,126 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/mwat56/dnscache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+const (
+	// `defMetricsInterval` is the default time between two saves of
+	// the persisted metrics.
+	defMetricsInterval = 5 * time.Minute
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `metricsOptions()` returns the configured persistence of the metrics.
+//
+// A relative `metricsFile` is taken to be inside the data directory.
+//
+// Parameters:
+//   - `aConfig`: The configuration to use.
+//
+// Returns:
+//   - `rFilename`: The file to persist the metrics in (empty if disabled).
+//   - `rInterval`: The time between two saves of the metrics.
+//   - `rErr`: `nil` if the interval is valid, the error otherwise.
+func metricsOptions(aConfig tConfiguration) (rFilename string, rInterval time.Duration, rErr error) {
+	if rInterval, rErr = configDuration("metricsInterval", aConfig.MetricsInterval); nil != rErr {
+		return
+	}
+	if 0 == rInterval {
+		rInterval = defMetricsInterval
+	}
+	if rFilename = aConfig.MetricsFile; ("" != rFilename) && !filepath.IsAbs(rFilename) {
+		rFilename = filepath.Join(aConfig.DataDir, rFilename)
+	}
+
+	return
+} // metricsOptions()
+
+// `startMetricsPersistence()` restores the resolver's metrics from a
+// file and saves them there periodically.
+//
+// Parameters:
+//   - `aResolver`: The resolver whose metrics to persist.
+//   - `aFilename`: The file to persist the metrics in (empty to disable).
+//   - `aInterval`: The time between two saves of the metrics.
+//   - `aReset`: Whether to start from zero instead of the saved metrics.
+//
+// Returns:
+//   - `func()`: The function to stop the persistence (saving the metrics
+//     a last time unless handed over to an upgraded process).
+func startMetricsPersistence(aResolver *dnscache.TResolver, aFilename string,
+	aInterval time.Duration, aReset bool) func() {
+	if "" == aFilename {
+		return func() {}
+	}
+
+	if aReset {
+		if err := os.Remove(aFilename); (nil != err) && !errors.Is(err, fs.ErrNotExist) {
+			gServerLog.Warn("resetting metrics failed", "file", aFilename, "error", err)
+		}
+	} else if err := aResolver.LoadMetrics(aFilename); nil != err {
+		gServerLog.Warn("restoring metrics failed", "file", aFilename, "error", err)
+		if errors.Is(err, dnscache.ErrFileVersion) {
+			// Keep the newer file instead of overwriting it
+			_ = os.Rename(aFilename, aFilename+".newer")
+		}
+	}
+
+	save := func() {
+		if err := aResolver.SaveMetrics(aFilename); nil != err {
+			gServerLog.Warn("saving metrics failed", "file", aFilename, "error", err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(aInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				save()
+			}
+		}
+	}()
+
+	// An upgraded process continues with the current metrics …
+	onUpgrade(save)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(stop)
+			wg.Wait()
+			if !gHandedOver.Load() {
+				// … which mustn't be overwritten when this one ends
+				save()
+			}
+		})
+	}
+} // startMetricsPersistence()
+
+/* _EoF_ */
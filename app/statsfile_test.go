@@ -0,0 +1,102 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mwat56/dnscache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_metricsOptions(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       tConfiguration
+		wantFilename string
+		wantInterval time.Duration
+		wantErr      bool
+	}{
+		/* */
+		{"01 - disabled", tConfiguration{}, "", defMetricsInterval, false},
+		{"02 - relative file", tConfiguration{DataDir: "/var/lib/dnscache", MetricsFile: "metrics.json"},
+			"/var/lib/dnscache/metrics.json", defMetricsInterval, false},
+		{"03 - absolute file", tConfiguration{DataDir: "/var/lib/dnscache", MetricsFile: "/tmp/metrics.json"},
+			"/tmp/metrics.json", defMetricsInterval, false},
+		{"04 - interval", tConfiguration{MetricsInterval: "1m"}, "", time.Minute, false},
+		{"05 - invalid interval", tConfiguration{MetricsInterval: "-1m"}, "", 0, true},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gotFilename, gotInterval, err := metricsOptions(tc.config)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("metricsOptions() error = '%v', wantErr '%v'",
+					err, tc.wantErr)
+				return
+			}
+			if tc.wantErr {
+				return
+			}
+			if gotFilename != tc.wantFilename {
+				t.Errorf("metricsOptions() filename = '%s', want '%s'",
+					gotFilename, tc.wantFilename)
+			}
+			if gotInterval != tc.wantInterval {
+				t.Errorf("metricsOptions() interval = '%v', want '%v'",
+					gotInterval, tc.wantInterval)
+			}
+		})
+	}
+} // Test_metricsOptions()
+
+func Test_startMetricsPersistence(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	fName := filepath.Join(t.TempDir(), "metrics.json")
+
+	// Nothing to do without a file
+	startMetricsPersistence(resolver, "", time.Hour, false)()
+
+	resolver.Create(context.Background(), "www.example.com",
+		[]net.IP{net.ParseIP("192.0.2.1")}, time.Hour)
+	resolver.ResetMetrics()
+	if _, err := resolver.FetchUnfiltered("www.example.com"); nil != err {
+		t.Fatalf("FetchUnfiltered() error = '%v'", err)
+	}
+	startMetricsPersistence(resolver, fName, time.Hour, false)()
+	if _, err := os.Stat(fName); nil != err {
+		t.Fatalf("metrics not saved: '%v'", err)
+	}
+
+	// Restoring adds the saved counters
+	stop := startMetricsPersistence(resolver, fName, time.Hour, false)
+	if got := resolver.Metrics().Lookups; 2 != got {
+		t.Errorf("restored lookups = '%d', want '%d'", got, 2)
+	}
+	stop()
+	stop() // must be harmless
+
+	// Resetting removes the saved counters
+	resolver.ResetMetrics()
+	startMetricsPersistence(resolver, fName, time.Hour, true)()
+	if err := resolver.LoadMetrics(fName); nil != err {
+		t.Fatalf("LoadMetrics() error = '%v'", err)
+	}
+	if got := resolver.Metrics().Lookups; 0 != got {
+		t.Errorf("reset lookups = '%d', want '%d'", got, 0)
+	}
+} // Test_startMetricsPersistence()
+
+/* _EoF_ */
@@ -0,0 +1,203 @@
+//go:build linux
+
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"errors"
+	"net"
+	"os"
+	"slices"
+
+	"github.com/mwat56/dnscache"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `iBatchConn` reads and writes several UDP messages per syscall
+	// (i.e. `recvmmsg(2)` and `sendmmsg(2)`).
+	iBatchConn interface {
+		ReadBatch(aMessages []ipv4.Message, aFlags int) (int, error)
+		WriteBatch(aMessages []ipv4.Message, aFlags int) (int, error)
+	}
+
+	// `tBatchConn` collects the answers written by the request
+	// handlers and sends them in batches.
+	tBatchConn struct {
+		*net.UDPConn
+		batch iBatchConn
+		queue chan ipv4.Message
+		done  chan struct{}
+	}
+)
+
+const (
+	// `udpBatchSize` is the max. number of messages read or written
+	// by a single syscall.
+	udpBatchSize = 32
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `newBatchConn()` creates a batching wrapper of a UDP connection.
+//
+// Parameters:
+//   - `aConn`: The UDP connection to wrap.
+//
+// Returns:
+//   - `*tBatchConn`: The batching connection.
+func newBatchConn(aConn *net.UDPConn) *tBatchConn {
+	result := &tBatchConn{
+		UDPConn: aConn,
+		queue:   make(chan ipv4.Message, udpBatchSize),
+		done:    make(chan struct{}),
+	}
+	if addr, ok := aConn.LocalAddr().(*net.UDPAddr); ok && (nil == addr.IP.To4()) {
+		result.batch = ipv6.NewPacketConn(aConn)
+	} else {
+		result.batch = ipv4.NewPacketConn(aConn)
+	}
+
+	return result
+} // newBatchConn()
+
+// `serveUDPBatch()` answers DNS requests read in batches until the
+// connection gets closed.
+//
+// Only plain UDP connections (optionally marked as unfiltered) are
+// served, all others are left to the portable `serveUDP()` loop.
+//
+// Parameters:
+//   - `aConn`: The UDP connection to read the requests from.
+//   - `aResolver`: The DNS resolver to use for lookups.
+//   - `aForwarder`: The DNS forwarder to use for non-A/AAAA requests (empty string means no forwarding).
+//   - `aForwarderClient`: The client to use for forwarding requests.
+//
+// Returns:
+//   - `bool`: `true` if the connection was served, `false` otherwise.
+func serveUDPBatch(aConn net.PacketConn, aResolver *dnscache.TResolver,
+	aForwarder string, aForwarderClient iForwarderClient) bool {
+	var (
+		udpConn    *net.UDPConn
+		unfiltered bool
+	)
+	switch conn := aConn.(type) {
+	case *net.UDPConn:
+		udpConn = conn
+	case tUnfilteredConn:
+		udpConn, _ = conn.PacketConn.(*net.UDPConn)
+		unfiltered = true
+	}
+	if nil == udpConn {
+		return false
+	}
+
+	bc := newBatchConn(udpConn)
+	var replyConn net.PacketConn = bc
+	if unfiltered {
+		replyConn = tUnfilteredConn{bc}
+	}
+	go bc.sendAnswers()
+	defer close(bc.done)
+
+	messages := make([]ipv4.Message, udpBatchSize)
+	for idx := range messages {
+		messages[idx].Buffers = [][]byte{make([]byte, 512)} // Standard DNS message size
+	}
+	for {
+		n, err := bc.batch.ReadBatch(messages, 0)
+		if nil != err {
+			if errors.Is(err, os.ErrDeadlineExceeded) {
+				// Handed over: send the answers still in flight
+				drainRequests(drainTimeout)
+				return true
+			}
+			if errors.Is(err, net.ErrClosed) {
+				return true
+			}
+			gServerLog.Warn("error reading DNS requests", "error", err)
+			continue
+		}
+		for _, msg := range messages[:n] {
+			if dnscache.DebugEnabled(serverLogComponent) {
+				gServerLog.Debug("received DNS request",
+					"client", msg.Addr.String(), "size", msg.N)
+			}
+
+			// The buffers are reused by the next batch
+			go handleDNSRequestWithForwarder(replyConn, msg.Addr,
+				slices.Clone(msg.Buffers[0][:msg.N]),
+				aResolver, aForwarder, aForwarderClient)
+		}
+	}
+} // serveUDPBatch()
+
+// ---------------------------------------------------------------------------
+// `tBatchConn` methods:
+
+// `sendAnswers()` sends the queued answers until the connection
+// isn't served anymore.
+func (bc *tBatchConn) sendAnswers() {
+	messages := make([]ipv4.Message, 0, udpBatchSize)
+	for {
+		select {
+		case <-bc.done:
+			return
+		case msg := <-bc.queue:
+			messages = append(messages[:0], msg)
+		}
+
+		// Add whatever else is waiting already
+	collect:
+		for len(messages) < udpBatchSize {
+			select {
+			case msg := <-bc.queue:
+				messages = append(messages, msg)
+			default:
+				break collect
+			}
+		}
+
+		for pending := messages; 0 < len(pending); {
+			n, err := bc.batch.WriteBatch(pending, 0)
+			if nil != err {
+				gServerLog.Warn("error sending DNS answers", "error", err)
+				break
+			}
+			pending = pending[n:]
+		}
+	}
+} // sendAnswers()
+
+// `WriteTo()` queues an answer to be sent with the next batch.
+//
+// Parameters:
+//   - `aMessage`: The answer to send.
+//   - `aAddr`: The client to send the answer to.
+//
+// Returns:
+//   - `int`: The message's length.
+//   - `error`: `nil` if the answer was queued, the error otherwise.
+func (bc *tBatchConn) WriteTo(aMessage []byte, aAddr net.Addr) (int, error) {
+	msg := ipv4.Message{
+		Buffers: [][]byte{slices.Clone(aMessage)},
+		Addr:    aAddr,
+	}
+	select {
+	case bc.queue <- msg:
+		return len(aMessage), nil
+	case <-bc.done:
+		return 0, net.ErrClosed
+	}
+} // WriteTo()
+
+/* _EoF_ */
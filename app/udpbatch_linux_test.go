@@ -0,0 +1,81 @@
+//go:build linux
+
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/mwat56/dnscache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_serveUDPBatch(t *testing.T) {
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{DataDir: t.TempDir()})
+	defer resolver.StopExpire()
+	resolver.ICacheList.Create(context.TODO(), "nas.lan",
+		[]net.IP{net.ParseIP("192.168.1.2")}, time.Hour)
+
+	if serveUDPBatch(&tMockPacketConn{}, resolver, "", &tStdForwarder{}) {
+		t.Fatalf("serveUDPBatch() served a non-UDP connection")
+	}
+
+	server, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if nil != err {
+		t.Fatalf("ListenUDP() error = '%v'", err)
+	}
+	served := make(chan bool)
+	go func() {
+		served <- serveUDPBatch(server, resolver, "", &tStdForwarder{})
+	}()
+
+	client, err := net.Dial("udp", server.LocalAddr().String())
+	if nil != err {
+		t.Fatalf("Dial() error = '%v'", err)
+	}
+	defer client.Close()
+	_ = client.SetDeadline(time.Now().Add(5 * time.Second))
+
+	// Send a burst of requests to be read in batches
+	const count = 3 * udpBatchSize
+	query := createDNSQuery("nas.lan", dnsTypeA)
+	for id := range uint16(count) {
+		binary.BigEndian.PutUint16(query[0:2], id)
+		if _, err = client.Write(query); nil != err {
+			t.Fatalf("Write() error = '%v'", err)
+		}
+	}
+
+	seen := make(map[uint16]bool, count)
+	answer := make([]byte, 512)
+	for range count {
+		n, err := client.Read(answer)
+		if nil != err {
+			t.Fatalf("Read() error = '%v' after %d answers", err, len(seen))
+		}
+		seen[binary.BigEndian.Uint16(answer[0:2])] = true
+		if got := net.IP(answer[n-4 : n]); !got.Equal(net.ParseIP("192.168.1.2")) {
+			t.Errorf("serveUDPBatch() answer = '%v', want '192.168.1.2'", got)
+		}
+	}
+	if count != len(seen) {
+		t.Errorf("serveUDPBatch() answered %d requests, want %d", len(seen), count)
+	}
+
+	_ = server.Close()
+	if !<-served {
+		t.Errorf("serveUDPBatch() = 'false', want 'true'")
+	}
+} // Test_serveUDPBatch()
+
+/* _EoF_ */
@@ -0,0 +1,33 @@
+//go:build !linux
+
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"net"
+
+	"github.com/mwat56/dnscache"
+)
+
+// `serveUDPBatch()` is a no-op on systems without batched UDP I/O,
+// leaving the connection to the portable `serveUDP()` loop.
+//
+// Parameters:
+//   - `aConn`: The UDP connection to read the requests from.
+//   - `aResolver`: The DNS resolver to use for lookups.
+//   - `aForwarder`: The DNS forwarder to use for non-A/AAAA requests (empty string means no forwarding).
+//   - `aForwarderClient`: The client to use for forwarding requests.
+//
+// Returns:
+//   - `bool`: Always `false`.
+func serveUDPBatch(aConn net.PacketConn, aResolver *dnscache.TResolver,
+	aForwarder string, aForwarderClient iForwarderClient) bool {
+	return false
+} // serveUDPBatch()
+
+/* _EoF_ */
@@ -0,0 +1,433 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+const (
+	// `envListenFDs` is the environment variable telling an upgraded
+	// process about the sockets it inherited (starting with file
+	// descriptor 3, see `startUpgrade()`).
+	envListenFDs = envPrefix + "LISTEN_FDS"
+
+	// `upgradeReadyKey` marks the inherited pipe the upgraded process
+	// reports its readiness with.
+	upgradeReadyKey = "ready"
+
+	// `upgradeTimeout` is the time the upgraded process may take to
+	// start serving.
+	upgradeTimeout = 10 * time.Second
+
+	// `drainTimeout` is the time the requests in flight may take to be
+	// answered after handing over the sockets.
+	drainTimeout = 2 * time.Second
+)
+
+type (
+	// `tInheritedSockets` holds the sockets inherited from the process
+	// replaced by this one.
+	tInheritedSockets struct {
+		sync.Mutex
+		once  sync.Once
+		files map[string][]*os.File // socket key → sockets
+		ready *os.File              // pipe to report the readiness
+	}
+)
+
+var (
+	// `gHandedOver` tells whether the sockets were handed over to an
+	// upgraded process.
+	gHandedOver atomic.Bool
+
+	// `gInFlight` is the number of requests currently being handled.
+	gInFlight atomic.Int64
+
+	// `gInherited` are the sockets inherited from the replaced process.
+	gInherited tInheritedSockets
+
+	// `gUpgradeSavers` are the functions saving the persistence files
+	// before an upgraded process is started.
+	gUpgradeSavers struct {
+		sync.Mutex
+		list []func()
+	}
+)
+
+// ---------------------------------------------------------------------------
+// `tInheritedSockets` methods:
+
+// `add()` stores inherited sockets.
+//
+// Parameters:
+//   - `aKeys`: The sockets' keys (see `socketKey()`).
+//   - `aFiles`: The sockets in the same order as `aKeys`.
+func (is *tInheritedSockets) add(aKeys []string, aFiles []*os.File) {
+	is.Lock()
+	defer is.Unlock()
+
+	for idx, key := range aKeys {
+		if len(aFiles) <= idx {
+			break
+		}
+		if upgradeReadyKey == key {
+			is.ready = aFiles[idx]
+			continue
+		}
+		if nil == is.files {
+			is.files = make(map[string][]*os.File)
+		}
+		is.files[key] = append(is.files[key], aFiles[idx])
+	}
+} // add()
+
+// `finish()` closes the inherited sockets not used and reports the
+// readiness to the replaced process.
+func (is *tInheritedSockets) finish() {
+	is.Lock()
+	defer is.Unlock()
+
+	for key, files := range is.files {
+		gServerLog.Warn("closing unused inherited socket", "socket", key)
+		for _, file := range files {
+			_ = file.Close()
+		}
+	}
+	is.files = nil
+
+	if nil != is.ready {
+		if _, err := is.ready.Write([]byte(upgradeReadyKey)); nil != err {
+			gServerLog.Warn("failed to report readiness", "error", err)
+		}
+		_ = is.ready.Close()
+		is.ready = nil
+	}
+} // finish()
+
+// `load()` reads the inherited sockets from the environment (once).
+func (is *tInheritedSockets) load() {
+	is.once.Do(func() {
+		list, ok := os.LookupEnv(envListenFDs)
+		if !ok {
+			return
+		}
+		// Don't pass the sockets on to any other process
+		_ = os.Unsetenv(envListenFDs)
+
+		keys := strings.Split(list, ",")
+		files := make([]*os.File, len(keys))
+		for idx, key := range keys {
+			files[idx] = os.NewFile(uintptr(3+idx), key)
+		}
+		is.add(keys, files)
+	})
+} // load()
+
+// `take()` removes and returns the inherited sockets of an address.
+//
+// Parameters:
+//   - `aNetwork`: The sockets' network (e.g. "udp4").
+//   - `aAddress`: The sockets' address.
+//
+// Returns:
+//   - `[]*os.File`: The inherited sockets (`nil` if there are none).
+func (is *tInheritedSockets) take(aNetwork, aAddress string) []*os.File {
+	is.Lock()
+	defer is.Unlock()
+
+	key := socketKey(aNetwork, aAddress)
+	result := is.files[key]
+	delete(is.files, key)
+
+	return result
+} // take()
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `drainRequests()` waits for the requests in flight to be answered.
+//
+// Parameters:
+//   - `aTimeout`: The max. time to wait.
+//
+// Returns:
+//   - `bool`: `true` if all requests were answered, `false` otherwise.
+func drainRequests(aTimeout time.Duration) bool {
+	deadline := time.Now().Add(aTimeout)
+	for 0 < gInFlight.Load() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return true
+} // drainRequests()
+
+// `inheritedListener()` returns the TCP listener inherited for an address.
+//
+// Parameters:
+//   - `aNetwork`: The listener's network (e.g. "tcp4").
+//   - `aAddress`: The listener's address.
+//
+// Returns:
+//   - `net.Listener`: The inherited listener (`nil` if there is none).
+//   - `error`: `nil` if the listener could be used, the error otherwise.
+func inheritedListener(aNetwork, aAddress string) (net.Listener, error) {
+	gInherited.load()
+	files := gInherited.take(aNetwork, aAddress)
+	if 0 == len(files) {
+		return nil, nil
+	}
+	for _, file := range files[1:] {
+		_ = file.Close()
+	}
+	defer files[0].Close()
+
+	return net.FileListener(files[0])
+} // inheritedListener()
+
+// `inheritedPacketConns()` returns the UDP sockets inherited for an
+// address.
+//
+// Parameters:
+//   - `aNetwork`: The sockets' network (e.g. "udp4").
+//   - `aAddress`: The sockets' address.
+//
+// Returns:
+//   - `[]net.PacketConn`: The inherited sockets (`nil` if there are none).
+//   - `error`: `nil` if the sockets could be used, the error otherwise.
+func inheritedPacketConns(aNetwork, aAddress string) ([]net.PacketConn, error) {
+	gInherited.load()
+	files := gInherited.take(aNetwork, aAddress)
+	if 0 == len(files) {
+		return nil, nil
+	}
+
+	result := make([]net.PacketConn, 0, len(files))
+	var errs []error
+	for _, file := range files {
+		conn, err := net.FilePacketConn(file)
+		_ = file.Close()
+		if nil != err {
+			errs = append(errs, err)
+			continue
+		}
+		result = append(result, conn)
+	}
+	if err := errors.Join(errs...); nil != err {
+		for _, conn := range result {
+			_ = conn.Close()
+		}
+		return nil, fmt.Errorf("failed to use inherited socket: %w", err)
+	}
+
+	return result, nil
+} // inheritedPacketConns()
+
+// `isUpgrade()` tells whether this process was started to replace
+// a running one (see `startUpgrade()`).
+//
+// Returns:
+//   - `bool`: `true` if the process inherits the sockets, `false` otherwise.
+func isUpgrade() bool {
+	_, ok := os.LookupEnv(envListenFDs)
+
+	return ok
+} // isUpgrade()
+
+// `onUpgrade()` registers a function saving a persistence file before
+// an upgraded process gets started (which will read that file).
+//
+// Parameters:
+//   - `aSave`: The function to call.
+func onUpgrade(aSave func()) {
+	gUpgradeSavers.Lock()
+	gUpgradeSavers.list = append(gUpgradeSavers.list, aSave)
+	gUpgradeSavers.Unlock()
+} // onUpgrade()
+
+// `reportReady()` tells the replaced process (if any) that this
+// process is serving now.
+func reportReady() {
+	gInherited.load()
+	gInherited.finish()
+} // reportReady()
+
+// `socketKey()` returns the key identifying a listening socket
+// across processes.
+//
+// Parameters:
+//   - `aNetwork`: The socket's network (e.g. "udp4").
+//   - `aAddress`: The socket's address.
+//
+// Returns:
+//   - `string`: The socket's key.
+func socketKey(aNetwork, aAddress string) string {
+	network := strings.TrimRight(aNetwork, "46")
+	host, port, err := net.SplitHostPort(aAddress)
+	if nil != err {
+		return network + "/" + aAddress
+	}
+	if ip := net.ParseIP(host); nil != ip {
+		if ip.IsUnspecified() {
+			host = ""
+		} else {
+			host = ip.String()
+		}
+	}
+
+	return network + "/" + net.JoinHostPort(host, port)
+} // socketKey()
+
+// `startUpgrade()` starts the (possibly replaced) executable passing
+// it the listening sockets.
+//
+// The persistence files are saved before, so the new process starts
+// with the current state. This function returns once the new process
+// reported to serve the requests; if it doesn't in time it's killed.
+//
+// Parameters:
+//   - `aSockets`: The listening sockets to hand over.
+//
+// Returns:
+//   - `error`: `nil` if the new process took over, the error otherwise.
+func startUpgrade(aSockets []io.Closer) error {
+	// The executable's path, not its (possibly deleted) file
+	executable, err := exec.LookPath(os.Args[0])
+	if nil != err {
+		return fmt.Errorf("failed to find executable: %w", err)
+	}
+	keys, files, err := upgradeFiles(aSockets)
+	defer func() {
+		for _, file := range files {
+			_ = file.Close()
+		}
+	}()
+	if nil != err {
+		return err
+	}
+	readyR, readyW, err := os.Pipe()
+	if nil != err {
+		return fmt.Errorf("failed to create pipe: %w", err)
+	}
+	defer readyR.Close()
+
+	gUpgradeSavers.Lock()
+	for _, save := range gUpgradeSavers.list {
+		save()
+	}
+	gUpgradeSavers.Unlock()
+
+	cmd := exec.Command(executable, os.Args[1:]...) //#nosec G204
+	cmd.Env = append(os.Environ(),
+		envListenFDs+"="+strings.Join(append(keys, upgradeReadyKey), ","))
+	cmd.ExtraFiles = append(files, readyW)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	err = cmd.Start()
+	_ = readyW.Close()
+	if nil != err {
+		return fmt.Errorf("failed to start %q: %w", executable, err)
+	}
+
+	// Wait for the new process to serve the requests
+	_ = readyR.SetReadDeadline(time.Now().Add(upgradeTimeout))
+	buffer := make([]byte, len(upgradeReadyKey))
+	if _, err := io.ReadFull(readyR, buffer); nil != err {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return fmt.Errorf("upgraded process not ready: %w", err)
+	}
+
+	gHandedOver.Store(true)
+	updatePidFile(os.Getpid(), cmd.Process.Pid)
+	gServerLog.Info("handed over to upgraded process", "pid", cmd.Process.Pid)
+
+	return nil
+} // startUpgrade()
+
+// `stopReading()` stops reading requests from the handed over sockets
+// while still sending the answers to the requests in flight.
+//
+// Parameters:
+//   - `aSockets`: The handed over sockets.
+func stopReading(aSockets []io.Closer) {
+	for _, socket := range aSockets {
+		switch conn := socket.(type) {
+		case net.PacketConn:
+			_ = conn.SetReadDeadline(time.Now())
+		case net.Listener:
+			_ = conn.Close()
+		}
+	}
+} // stopReading()
+
+// `updatePidFile()` replaces the PID written by `runAsDaemon()` by
+// the upgraded process's PID.
+//
+// Parameters:
+//   - `aOld`: The PID of the replaced process.
+//   - `aNew`: The PID of the upgraded process.
+func updatePidFile(aOld, aNew int) {
+	data, err := os.ReadFile(gPidFile) //#nosec G304
+	if (nil != err) || (strconv.Itoa(aOld) != strings.TrimSpace(string(data))) {
+		return // not running as a daemon
+	}
+	if err := os.WriteFile(gPidFile, fmt.Appendf(nil, "%d", aNew), 0600); nil != err {
+		gServerLog.Warn("failed to update PID file", "file", gPidFile, "error", err)
+	}
+} // updatePidFile()
+
+// `upgradeFiles()` returns the files of the sockets to hand over.
+//
+// Parameters:
+//   - `aSockets`: The listening sockets.
+//
+// Returns:
+//   - `[]string`: The sockets' keys (see `socketKey()`).
+//   - `[]*os.File`: The sockets' (duplicated) files.
+//   - `error`: `nil` if all files were returned, the error otherwise.
+func upgradeFiles(aSockets []io.Closer) (rKeys []string, rFiles []*os.File, rErr error) {
+	for _, socket := range aSockets {
+		var addr net.Addr
+		switch conn := socket.(type) {
+		case net.PacketConn:
+			addr = conn.LocalAddr()
+		case net.Listener:
+			addr = conn.Addr()
+		}
+		filer, ok := socket.(interface{ File() (*os.File, error) })
+		if (nil == addr) || !ok {
+			rErr = fmt.Errorf("can't hand over socket %T", socket)
+			return
+		}
+		file, err := filer.File()
+		if nil != err {
+			rErr = fmt.Errorf("can't hand over socket %s: %w", addr, err)
+			return
+		}
+		rKeys = append(rKeys, socketKey(addr.Network(), addr.String()))
+		rFiles = append(rFiles, file)
+	}
+
+	return
+} // upgradeFiles()
+
+/* _EoF_ */
@@ -0,0 +1,21 @@
+//go:build !unix
+
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"os"
+)
+
+var (
+	// `upgradeSignal` is the signal starting a graceful upgrade
+	// (not available on this system).
+	upgradeSignal os.Signal
+)
+
+/* _EoF_ */
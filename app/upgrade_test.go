@@ -0,0 +1,145 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_drainRequests(t *testing.T) {
+	if !drainRequests(0) {
+		t.Error("drainRequests() = 'false', want 'true'")
+	}
+
+	gInFlight.Add(1)
+	if drainRequests(20 * time.Millisecond) {
+		t.Error("drainRequests() = 'true', want 'false'")
+	}
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		gInFlight.Add(-1)
+	}()
+	if !drainRequests(time.Second) {
+		t.Error("drainRequests() = 'false', want 'true'")
+	}
+} // Test_drainRequests()
+
+func Test_socketKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		network string
+		address string
+		want    string
+	}{
+		/* */
+		{"01 - IPv4", "udp4", "127.0.0.1:53", "udp/127.0.0.1:53"},
+		{"02 - IPv6", "tcp6", "[::1]:53", "tcp/[::1]:53"},
+		{"03 - any address", "udp", ":53", "udp/:53"},
+		{"04 - unspecified IPv4", "udp4", "0.0.0.0:53", "udp/:53"},
+		{"05 - unspecified IPv6", "tcp", "[::]:853", "tcp/:853"},
+		{"06 - hostname", "udp", "dns.localdomain:53", "udp/dns.localdomain:53"},
+		{"07 - invalid address", "udp", "localdomain", "udp/localdomain"},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := socketKey(tc.network, tc.address); got != tc.want {
+				t.Errorf("socketKey() = '%s', want '%s'", got, tc.want)
+			}
+		})
+	}
+} // Test_socketKey()
+
+func Test_tInheritedSockets(t *testing.T) {
+	udpConn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	if nil != err {
+		t.Fatalf("ListenPacket() error = '%v'", err)
+	}
+	defer udpConn.Close()
+	tcpListener, err := net.Listen("tcp4", "127.0.0.1:0")
+	if nil != err {
+		t.Fatalf("Listen() error = '%v'", err)
+	}
+	defer tcpListener.Close()
+	readyR, readyW, err := os.Pipe()
+	if nil != err {
+		t.Fatalf("Pipe() error = '%v'", err)
+	}
+	defer readyR.Close()
+
+	keys, files, err := upgradeFiles([]io.Closer{udpConn, tcpListener})
+	if nil != err {
+		t.Fatalf("upgradeFiles() error = '%v'", err)
+	}
+	want := []string{
+		socketKey("udp", udpConn.LocalAddr().String()),
+		socketKey("tcp", tcpListener.Addr().String()),
+	}
+	if (2 != len(keys)) || (want[0] != keys[0]) || (want[1] != keys[1]) {
+		t.Fatalf("upgradeFiles() = '%v', want '%v'", keys, want)
+	}
+
+	var is tInheritedSockets
+	is.add(append(keys, "tcp/127.0.0.1:1", upgradeReadyKey),
+		append(files, files[1], readyW))
+	if got := is.take("udp4", "127.0.0.1:1"); nil != got {
+		t.Errorf("take() = '%v', want 'nil'", got)
+	}
+	got := is.take("udp4", udpConn.LocalAddr().String())
+	if 1 != len(got) {
+		t.Fatalf("take() = '%v', want one socket", got)
+	}
+	inherited, err := net.FilePacketConn(got[0])
+	_ = got[0].Close()
+	if nil != err {
+		t.Fatalf("FilePacketConn() error = '%v'", err)
+	}
+	defer inherited.Close()
+	if inherited.LocalAddr().String() != udpConn.LocalAddr().String() {
+		t.Errorf("LocalAddr() = '%v', want '%v'", inherited.LocalAddr(), udpConn.LocalAddr())
+	}
+	if got := is.take("udp4", udpConn.LocalAddr().String()); nil != got {
+		t.Errorf("take() = '%v', want 'nil' (taken already)", got)
+	}
+
+	is.finish()
+	if nil != is.files {
+		t.Errorf("finish() left '%v'", is.files)
+	}
+	buffer := make([]byte, len(upgradeReadyKey))
+	if _, err := io.ReadFull(readyR, buffer); (nil != err) || (upgradeReadyKey != string(buffer)) {
+		t.Errorf("finish() reported '%s', '%v', want '%s'", buffer, err, upgradeReadyKey)
+	}
+} // Test_tInheritedSockets()
+
+func Test_updatePidFile(t *testing.T) {
+	defer func(aFile string) { gPidFile = aFile }(gPidFile)
+	gPidFile = filepath.Join(t.TempDir(), "dnscache.pid")
+
+	// No PID file: nothing to update
+	updatePidFile(1, 2)
+	if _, err := os.Stat(gPidFile); nil == err {
+		t.Error("updatePidFile() created the PID file")
+	}
+
+	_ = os.WriteFile(gPidFile, []byte("1\n"), 0600)
+	updatePidFile(3, 4) // another process's PID file
+	updatePidFile(1, 2)
+	if data, _ := os.ReadFile(gPidFile); "2" != string(data) {
+		t.Errorf("updatePidFile() wrote '%s', want '2'", data)
+	}
+} // Test_updatePidFile()
+
+/* _EoF_ */
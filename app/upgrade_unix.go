@@ -0,0 +1,22 @@
+//go:build unix
+
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+var (
+	// `upgradeSignal` is the signal starting a graceful upgrade
+	// (see `startUpgrade()`).
+	upgradeSignal os.Signal = syscall.SIGUSR2
+)
+
+/* _EoF_ */
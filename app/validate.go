@@ -0,0 +1,85 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sync/atomic"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+var (
+	// `gSpoofed` counts the forwarders' answers dropped because they
+	// didn't match the forwarded request.
+	gSpoofed atomic.Uint64
+)
+
+// `validResponse()` checks whether a response answers a request.
+//
+// The response has to carry the request's message ID and opcode,
+// have the QR bit set, and repeat the request's question with the
+// same type and class. The question's name is compared ignoring
+// case, since upstream servers may answer with a different case
+// (e.g. when using 0x20 randomisation).
+//
+// Parameters:
+//   - `aRequest`: The forwarded DNS request.
+//   - `aResponse`: The answer received.
+//
+// Returns:
+//   - `bool`: `true` if the response answers the request.
+func validResponse(aRequest, aResponse []byte) bool {
+	if (12 > len(aRequest)) || (12 > len(aResponse)) {
+		return false
+	}
+	if !bytes.Equal(aRequest[0:2], aResponse[0:2]) {
+		return false
+	}
+	requestFlags := binary.BigEndian.Uint16(aRequest[2:4])
+	responseFlags := binary.BigEndian.Uint16(aResponse[2:4])
+	if (0 == responseFlags&dnsQR) || (requestFlags&dnsOpcodeMask != responseFlags&dnsOpcodeMask) {
+		return false
+	}
+	if !bytes.Equal(aRequest[4:6], aResponse[4:6]) {
+		return false
+	}
+	if 0 == binary.BigEndian.Uint16(aRequest[4:6]) {
+		return true
+	}
+
+	requestEnd, responseEnd := questionEnd(aRequest), questionEnd(aResponse)
+	if (0 == requestEnd) || (requestEnd != responseEnd) {
+		return false
+	}
+
+	for i := 12; i < requestEnd-4; i++ {
+		if lowerASCII(aRequest[i]) != lowerASCII(aResponse[i]) {
+			return false
+		}
+	}
+
+	return bytes.Equal(aRequest[requestEnd-4:requestEnd], aResponse[responseEnd-4:responseEnd])
+} // validResponse()
+
+// `lowerASCII()` returns the lower-case version of an ASCII letter.
+//
+// Parameters:
+//   - `aByte`: The byte to convert.
+//
+// Returns:
+//   - `byte`: The lower-case letter, or `aByte` if it's no upper-case letter.
+func lowerASCII(aByte byte) byte {
+	if ('A' <= aByte) && ('Z' >= aByte) {
+		return aByte + ('a' - 'A')
+	}
+
+	return aByte
+} // lowerASCII()
+
+/* _EoF_ */
@@ -0,0 +1,135 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/mwat56/dnscache/internal/workload"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// `answer()` returns a response to the request changed by `aChange`.
+func answer(aRequest []byte, aChange func([]byte)) []byte {
+	result := append([]byte{}, aRequest...)
+	binary.BigEndian.PutUint16(result[2:4], dnsQR|dnsRD|dnsRA)
+	if nil != aChange {
+		aChange(result)
+	}
+
+	return result
+} // answer()
+
+func Test_validResponse(t *testing.T) {
+	request := workload.Query(0x1234, "www.example.com", dnsTypeA)
+	noQuestion := append([]byte{}, request[:12]...)
+	binary.BigEndian.PutUint16(noQuestion[4:6], 0)
+
+	tests := []struct {
+		name     string
+		request  []byte
+		response []byte
+		want     bool
+	}{
+		/* */
+		{"01 - matching", request, answer(request, nil), true},
+		{"02 - other case", request, answer(request, func(r []byte) { r[13] = 'W' }), true},
+		{"03 - other ID", request, answer(request, func(r []byte) { r[1]++ }), false},
+		{"04 - no QR bit", request, answer(request, func(r []byte) { r[2] &^= 0x80 }), false},
+		{"05 - other opcode", request, answer(request, func(r []byte) { r[2] |= 0x10 }), false},
+		{"06 - other name", request, answer(request, func(r []byte) { r[13] = 'x' }), false},
+		{"07 - other type", request, answer(request, func(r []byte) { r[len(r)-3] = byte(dnsTypeAAAA) }), false},
+		{"08 - other class", request, answer(request, func(r []byte) { r[len(r)-1] = 3 }), false},
+		{"09 - no question", request, answer(request, func(r []byte) { r[5] = 0 }), false},
+		{"10 - truncated question", request, answer(request, nil)[:20], false},
+		{"11 - short response", request, request[:8], false},
+		{"12 - request without question", noQuestion, answer(noQuestion, nil), true},
+		{"13 - other name length", request,
+			answer(workload.Query(0x1234, "www.example.org.uk", dnsTypeA), nil), false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := validResponse(tc.request, tc.response); got != tc.want {
+				t.Errorf("validResponse() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_validResponse()
+
+func Test_tStdForwarder_forwardUDP(t *testing.T) {
+	// The upstream sends a forged answer ahead of the real one
+	upstream, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatalf("ListenPacket() error = '%v'", err)
+	}
+	defer upstream.Close()
+	go func() {
+		buffer := make([]byte, 512)
+		n, addr, err := upstream.ReadFrom(buffer)
+		if nil != err {
+			return
+		}
+		_, _ = upstream.WriteTo(answer(buffer[:n], func(r []byte) { r[0]++ }), addr)
+		_, _ = upstream.WriteTo(answer(buffer[:n], nil), addr)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	before := gSpoofed.Load()
+	request := workload.Query(0x1234, "www.example.com", dnsTypeA)
+
+	got, err := (&tStdForwarder{}).forwardUDP(ctx, upstream.LocalAddr().String(), request)
+	if nil != err {
+		t.Fatalf("forwardUDP() error = '%v'", err)
+	}
+	if !validResponse(request, got) {
+		t.Errorf("forwardUDP() = '%v', want matching answer", got)
+	}
+	if spoofed := gSpoofed.Load() - before; 1 != spoofed {
+		t.Errorf("gSpoofed = '%d', want '1'", spoofed)
+	}
+} // Test_tStdForwarder_forwardUDP()
+
+// `tSpoofingForwarder` answers every request with another ID.
+type tSpoofingForwarder struct{}
+
+func (tSpoofingForwarder) ForwardDNSRequest(aCtx context.Context, aForwarder string, aRequest []byte) ([]byte, error) {
+	return answer(aRequest, func(r []byte) { r[0]++ }), nil
+} // ForwardDNSRequest()
+
+func Test_forwardRequest_spoofed(t *testing.T) {
+	responses := make(chan []byte, 1)
+	request := workload.Query(0x1234, "www.example.com", dnsTypeTXT)
+	before := gSpoofed.Load()
+
+	forwardRequest(&tMockPacketConn{respChan: responses}, &tMockAddr{}, request,
+		0x1234, dnsRD, 1, "192.0.2.53:53", tSpoofingForwarder{})
+
+	select {
+	case got := <-responses:
+		if !validResponse(request, got) {
+			t.Errorf("forwardRequest() sent '%v', want answer to request", got)
+		}
+		if rcode := binary.BigEndian.Uint16(got[2:4]) & 0xF; dnsRcodeServFail != rcode {
+			t.Errorf("forwardRequest() rcode = '%d', want '%d'", rcode, dnsRcodeServFail)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("forwardRequest() sent no response")
+	}
+	if spoofed := gSpoofed.Load() - before; 1 != spoofed {
+		t.Errorf("gSpoofed = '%d', want '1'", spoofed)
+	}
+} // Test_forwardRequest_spoofed()
+
+/* _EoF_ */
@@ -0,0 +1,146 @@
+// DNScache dashboard: polls the admin API and updates the page.
+"use strict";
+
+const POLL_INTERVAL = 5000; // milliseconds
+const GRAPH_POINTS = 60;    // number of rate samples shown
+
+let lastMetrics = null;
+const rates = [];
+
+function $(id) {
+	return document.getElementById(id);
+}
+
+async function api(path, form) {
+	const options = form ? { method: "POST", body: new URLSearchParams(form) } : {};
+	const token = sessionStorage.getItem("token");
+	if (token) {
+		options.headers = { "Authorization": "Bearer " + token };
+	}
+	const response = await fetch(path, options);
+	if (401 === response.status) {
+		// Another request may have asked for the token meanwhile
+		let newToken = sessionStorage.getItem("token");
+		if (newToken === token) {
+			newToken = prompt("Admin token:");
+		}
+		if (newToken && (newToken !== token)) {
+			sessionStorage.setItem("token", newToken);
+			return api(path, form);
+		}
+	}
+	const data = await response.json();
+	if (!response.ok) {
+		throw new Error(data.error || response.statusText);
+	}
+	return data;
+}
+
+function showMessage(text, isError) {
+	const msg = $("message");
+	msg.textContent = text;
+	msg.className = isError ? "error" : "";
+}
+
+function drawGraph() {
+	const canvas = $("rate-graph");
+	const ctx = canvas.getContext("2d");
+	const w = canvas.width, h = canvas.height;
+	ctx.clearRect(0, 0, w, h);
+	if (2 > rates.length) {
+		return;
+	}
+	const top = Math.max(1, ...rates);
+	const step = w / (GRAPH_POINTS - 1);
+	ctx.strokeStyle = "#00797a";
+	ctx.lineWidth = 2;
+	ctx.beginPath();
+	rates.forEach((rate, i) => {
+		const x = (GRAPH_POINTS - rates.length + i) * step;
+		const y = h - (rate / top) * (h - 10);
+		if (0 === i) {
+			ctx.moveTo(x, y);
+		} else {
+			ctx.lineTo(x, y);
+		}
+	});
+	ctx.stroke();
+	ctx.fillStyle = "#666";
+	ctx.fillText(top.toFixed(1) + "/s", 4, 12);
+}
+
+function fillTable(id, entries) {
+	const body = $(id).querySelector("tbody");
+	body.replaceChildren();
+	for (const entry of entries) {
+		const row = body.insertRow();
+		row.insertCell().textContent = entry.name;
+		row.insertCell().textContent = entry.count;
+	}
+}
+
+async function updateMetrics() {
+	const m = await api("api/metrics");
+	if (lastMetrics) {
+		const seconds = (Date.parse(m.time) - Date.parse(lastMetrics.time)) / 1000;
+		if (0 < seconds) {
+			rates.push(Math.max(0, m.lookups - lastMetrics.lookups) / seconds);
+			if (GRAPH_POINTS < rates.length) {
+				rates.shift();
+			}
+			$("rate").textContent = rates[rates.length - 1].toFixed(1);
+		}
+	}
+	lastMetrics = m;
+	$("lookups").textContent = m.lookups;
+	$("hit-ratio").textContent = (100 * m.hitRatio).toFixed(1) + " %";
+	$("cache-size").textContent = m.cacheSize;
+	$("errors").textContent = m.errors;
+	drawGraph();
+}
+
+async function updatePause() {
+	const state = await api("api/pause");
+	$("pause-state").textContent = state.paused
+		? "blocking paused until " + new Date(state.pausedUntil).toLocaleTimeString()
+		: "";
+}
+
+async function update() {
+	try {
+		await Promise.all([
+			updateMetrics(),
+			updatePause(),
+			api("api/top/blocked").then((list) => fillTable("top-blocked", list)),
+			api("api/top/queries").then((list) => fillTable("top-queries", list)),
+		]);
+	} catch (err) {
+		showMessage(err.message, true);
+	}
+}
+
+$("list-form").addEventListener("submit", async (event) => {
+	event.preventDefault();
+	const form = new FormData(event.target);
+	const list = event.submitter.value;
+	try {
+		await api("api/" + list, form);
+		showMessage("Added " + form.get("pattern") + " to the " + list, false);
+		event.target.reset();
+	} catch (err) {
+		showMessage(err.message, true);
+	}
+});
+
+$("pause-form").addEventListener("submit", async (event) => {
+	event.preventDefault();
+	try {
+		await api("api/pause", new FormData(event.target));
+		await updatePause();
+	} catch (err) {
+		showMessage(err.message, true);
+	}
+});
+
+update();
+setInterval(update, POLL_INTERVAL);
@@ -0,0 +1,401 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tZoneLine` is a logical line of a master file, i.e. the
+	// physical lines joined by parentheses.
+	tZoneLine struct {
+		fields   []string // without quotes and comments
+		number   int      // of the first physical line
+		indented bool     // starts with white space (same owner)
+	}
+)
+
+var (
+	// `zoneFileArity` is the number of data fields of the record
+	// types (TXT records have one or more).
+	zoneFileArity = map[string]int{
+		"A":     1,
+		"AAAA":  1,
+		"CNAME": 1,
+		"MX":    2,
+		"NS":    1,
+		"PTR":   1,
+		"SOA":   7,
+		"SRV":   4,
+	}
+
+	// `zoneTTLUnits` are the units allowed in TTL values (like `1h30m`).
+	zoneTTLUnits = map[rune]uint64{
+		's': 1,
+		'm': 60,
+		'h': 3600,
+		'd': 86400,
+		'w': 604800,
+	}
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `absoluteName()` returns the absolute form of a name of a master file.
+//
+// Other than with `qualifyName()` a name not ending with a dot is
+// always relative to the origin.
+//
+// Parameters:
+//   - `aName`: The name to qualify.
+//   - `aOrigin`: The current origin.
+//
+// Returns:
+//   - `string`: The lower-cased name without trailing dot.
+func absoluteName(aName, aOrigin string) string {
+	name := strings.ToLower(aName)
+	switch {
+	case "@" == name:
+		return aOrigin
+	case strings.HasSuffix(name, "."):
+		return strings.TrimSuffix(name, ".")
+	}
+
+	return name + "." + aOrigin
+} // absoluteName()
+
+// `loadZoneFile()` reads the records of a zone from a master file.
+//
+// Parameters:
+//   - `aFilename`: The master file to read.
+//   - `aOrigin`: The zone's name.
+//
+// Returns:
+//   - `[]tRecordConfig`: The records read.
+//   - `error`: `nil` if the file was read, the error otherwise.
+func loadZoneFile(aFilename, aOrigin string) ([]tRecordConfig, error) {
+	file, err := os.Open(aFilename) //#nosec G304
+	if nil != err {
+		return nil, err
+	}
+	defer file.Close()
+
+	result, err := parseZoneFile(file, aOrigin)
+	if nil != err {
+		return nil, fmt.Errorf("%s: %w", aFilename, err)
+	}
+
+	return result, nil
+} // loadZoneFile()
+
+// `parseZoneFile()` reads the records of an RFC 1035 master file.
+//
+// The `$ORIGIN` and `$TTL` directives are supported as well as the
+// record types the authoritative zones can answer. Records without
+// a TTL get the `$TTL` value or, lacking that, the last TTL given
+// (`0` meaning the zone's default). The strings of a TXT record are
+// joined into one text.
+//
+// Parameters:
+//   - `aReader`: The reader to read the master file from.
+//   - `aOrigin`: The zone's name (the initial origin).
+//
+// Returns:
+//   - `[]tRecordConfig`: The records read (with absolute names).
+//   - `error`: `nil` if the file was read, the error otherwise.
+func parseZoneFile(aReader io.Reader, aOrigin string) ([]tRecordConfig, error) {
+	lines, err := zoneFileLines(aReader)
+	if nil != err {
+		return nil, err
+	}
+
+	var (
+		result     []tRecordConfig
+		owner      string
+		ttl        uint32
+		defaultTTL bool // `$TTL` was given
+	)
+	origin := strings.ToLower(strings.Trim(aOrigin, "."))
+	for _, line := range lines {
+		fields := line.fields
+		switch directive := strings.ToUpper(fields[0]); {
+		case "$ORIGIN" == directive:
+			if 2 != len(fields) {
+				return nil, fmt.Errorf("line %d: invalid $ORIGIN", line.number)
+			}
+			origin = absoluteName(fields[1], origin)
+			continue
+
+		case "$TTL" == directive:
+			value, ok := uint32(0), (2 == len(fields))
+			if ok {
+				value, ok = parseZoneTTL(fields[1])
+			}
+			if !ok {
+				return nil, fmt.Errorf("line %d: invalid $TTL", line.number)
+			}
+			ttl, defaultTTL = value, true
+			continue
+
+		case strings.HasPrefix(directive, "$"):
+			return nil, fmt.Errorf("line %d: unsupported directive: %q", line.number, fields[0])
+		}
+
+		if !line.indented {
+			owner = absoluteName(fields[0], origin)
+			fields = fields[1:]
+		} else if "" == owner {
+			return nil, fmt.Errorf("line %d: missing owner name", line.number)
+		}
+
+		// TTL and class may come in either order
+		rec := tRecordConfig{Name: owner + ".", TTL: ttl}
+		for 0 < len(fields) {
+			if value, ok := parseZoneTTL(fields[0]); ok {
+				rec.TTL = value
+				if !defaultTTL {
+					ttl = value
+				}
+			} else if "IN" != strings.ToUpper(fields[0]) {
+				break
+			}
+			fields = fields[1:]
+		}
+		if 0 == len(fields) {
+			return nil, fmt.Errorf("line %d: missing record type", line.number)
+		}
+		rec.Type = strings.ToUpper(fields[0])
+		if rec.Data, err = zoneFileData(rec.Type, fields[1:], origin); nil != err {
+			return nil, fmt.Errorf("line %d: %w", line.number, err)
+		}
+		result = append(result, rec)
+	}
+
+	return result, nil
+} // parseZoneFile()
+
+// `parseZoneTTL()` parses a TTL value of a master file.
+//
+// Parameters:
+//   - `aValue`: The number of seconds, or a value with units like `1h30m`.
+//
+// Returns:
+//   - `uint32`: The TTL in seconds.
+//   - `bool`: `true` if the value is valid, `false` otherwise.
+func parseZoneTTL(aValue string) (uint32, bool) {
+	if value, err := strconv.ParseUint(aValue, 10, 32); nil == err {
+		return uint32(value), true
+	}
+
+	var result, number uint64
+	digits := false
+	for _, c := range strings.ToLower(aValue) {
+		if ('0' <= c) && ('9' >= c) {
+			number, digits = number*10+uint64(c-'0'), true
+			if math.MaxUint32 < number {
+				return 0, false
+			}
+			continue
+		}
+		unit, ok := zoneTTLUnits[c]
+		if !ok || !digits {
+			return 0, false
+		}
+		result, number, digits = result+number*unit, 0, false
+		if math.MaxUint32 < result {
+			return 0, false
+		}
+	}
+	if digits || (0 == result) {
+		return 0, false
+	}
+
+	return uint32(result), true
+} // parseZoneTTL()
+
+// `zoneFields()` splits a physical line of a master file into fields.
+//
+// Parameters:
+//   - `aText`: The line to split.
+//
+// Returns:
+//   - `[]string`: The line's fields (without quotes and comments).
+//   - `int`: The change of the parentheses' nesting.
+//   - `error`: `nil` if the line is valid, the error otherwise.
+func zoneFields(aText string) ([]string, int, error) {
+	var (
+		result  []string
+		field   strings.Builder
+		depth   int
+		inField bool
+		quoted  bool
+	)
+	flush := func() {
+		if inField {
+			result = append(result, field.String())
+			field.Reset()
+			inField = false
+		}
+	}
+
+	for i := 0; i < len(aText); i++ {
+		c := aText[i]
+		switch {
+		case ('\\' == c) && (i+1 < len(aText)):
+			// `\DDD` is a decimal byte value, `\X` the character itself
+			value, err := uint64(0), strconv.ErrSyntax
+			if i+4 <= len(aText) {
+				value, err = strconv.ParseUint(aText[i+1:i+4], 10, 8)
+			}
+			if nil == err {
+				field.WriteByte(byte(value))
+				i += 3
+			} else {
+				field.WriteByte(aText[i+1])
+				i++
+			}
+			inField = true
+
+		case quoted:
+			if '"' == c {
+				quoted = false
+				flush()
+			} else {
+				field.WriteByte(c)
+			}
+
+		case '"' == c:
+			flush()
+			quoted, inField = true, true
+
+		case ';' == c:
+			flush()
+			return result, depth, nil
+
+		case ('(' == c) || (')' == c):
+			flush()
+			if '(' == c {
+				depth++
+			} else {
+				depth--
+			}
+
+		case (' ' == c) || ('\t' == c) || ('\r' == c):
+			flush()
+
+		default:
+			field.WriteByte(c)
+			inField = true
+		}
+	}
+	if quoted {
+		return nil, 0, errors.New("unterminated quoted string")
+	}
+	flush()
+
+	return result, depth, nil
+} // zoneFields()
+
+// `zoneFileData()` converts the data fields of a master file's record
+// to the configuration's format.
+//
+// Parameters:
+//   - `aType`: The upper-cased record type.
+//   - `aFields`: The record's data fields.
+//   - `aOrigin`: The current origin.
+//
+// Returns:
+//   - `string`: The record's data (with absolute names).
+//   - `error`: `nil` if the fields are valid, the error otherwise.
+func zoneFileData(aType string, aFields []string, aOrigin string) (string, error) {
+	if _, ok := zoneRecordTypes[aType]; !ok {
+		return "", fmt.Errorf("unsupported record type: %q", aType)
+	}
+	if n, ok := zoneFileArity[aType]; (ok && (n != len(aFields))) || (0 == len(aFields)) {
+		return "", fmt.Errorf("invalid %s record data: %q", aType, strings.Join(aFields, " "))
+	}
+
+	switch aType {
+	case "CNAME", "NS", "PTR":
+		return absoluteName(aFields[0], aOrigin) + ".", nil
+
+	case "MX":
+		return aFields[0] + " " + absoluteName(aFields[1], aOrigin) + ".", nil
+
+	case "SOA":
+		return absoluteName(aFields[0], aOrigin) + ". " +
+			absoluteName(aFields[1], aOrigin) + ". " +
+			strings.Join(aFields[2:], " "), nil
+
+	case "SRV":
+		if "." == aFields[3] {
+			return strings.Join(aFields, " "), nil
+		}
+		return strings.Join(aFields[:3], " ") + " " + absoluteName(aFields[3], aOrigin) + ".", nil
+
+	case "TXT":
+		return strings.Join(aFields, ""), nil
+	}
+
+	return aFields[0], nil
+} // zoneFileData()
+
+// `zoneFileLines()` reads the logical lines of a master file.
+//
+// Parameters:
+//   - `aReader`: The reader to read the master file from.
+//
+// Returns:
+//   - `[]tZoneLine`: The non-empty logical lines.
+//   - `error`: `nil` if the file was read, the error otherwise.
+func zoneFileLines(aReader io.Reader) ([]tZoneLine, error) {
+	var (
+		result  []tZoneLine
+		current tZoneLine
+		depth   int // of the parentheses
+	)
+
+	scanner := bufio.NewScanner(aReader)
+	for number := 1; scanner.Scan(); number++ {
+		text := scanner.Text()
+		if 0 == depth {
+			current = tZoneLine{
+				number:   number,
+				indented: strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t"),
+			}
+		}
+		fields, delta, err := zoneFields(text)
+		if nil != err {
+			return nil, fmt.Errorf("line %d: %w", number, err)
+		}
+		current.fields = append(current.fields, fields...)
+		if depth += delta; 0 > depth {
+			return nil, fmt.Errorf("line %d: unbalanced parentheses", number)
+		}
+		if (0 == depth) && (0 < len(current.fields)) {
+			result = append(result, current)
+		}
+	}
+	if 0 != depth {
+		return nil, fmt.Errorf("line %d: unbalanced parentheses", current.number)
+	}
+
+	return result, scanner.Err()
+} // zoneFileLines()
+
+/* _EoF_ */
@@ -0,0 +1,205 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mwat56/dnscache/internal/workload"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+const (
+	// `testZoneFile` is a master file of the `example.com` zone.
+	testZoneFile = `$TTL 1h
+@	IN	SOA	ns1 hostmaster (
+		2024061501 ; serial
+		2h         ; refresh
+		15m        ; retry
+		1w         ; expire
+		300 )      ; minimum
+	IN	NS	ns1
+	IN	MX	10 mail.example.com.
+	IN	TXT	"v=spf1 " "mx -all"
+ns1	IN	A	192.0.2.53
+mail	600	IN	A	192.0.2.25
+www	IN	A	192.0.2.1
+	IN	AAAA	2001:db8::1
+ftp	CNAME	www
+_sip._tcp	SRV	10 60 5060 sip
+$ORIGIN lab.example.com.
+nas	IN	3600	A	192.0.2.99
+`
+)
+
+func Test_parseZoneFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    []tRecordConfig
+		wantErr bool
+	}{
+		/* */
+		{"01 - empty file", "; nothing here\n\n", nil, false},
+		{"02 - relative names", "www A 192.0.2.1\n@ MX 10 mail\n", []tRecordConfig{
+			{Name: "www.example.com.", Type: "A", Data: "192.0.2.1"},
+			{Name: "example.com.", Type: "MX", Data: "10 mail.example.com."},
+		}, false},
+		{"03 - last TTL", "a 60 IN A 192.0.2.1\nb IN A 192.0.2.2\n", []tRecordConfig{
+			{Name: "a.example.com.", Type: "A", Data: "192.0.2.1", TTL: 60},
+			{Name: "b.example.com.", Type: "A", Data: "192.0.2.2", TTL: 60},
+		}, false},
+		{"04 - $TTL", "$TTL 1d\na 60 A 192.0.2.1\nb A 192.0.2.2\n", []tRecordConfig{
+			{Name: "a.example.com.", Type: "A", Data: "192.0.2.1", TTL: 60},
+			{Name: "b.example.com.", Type: "A", Data: "192.0.2.2", TTL: 86400},
+		}, false},
+		{"05 - quoted text", `@ TXT "a \"b\" c" "; d"` + "\n", []tRecordConfig{
+			{Name: "example.com.", Type: "TXT", Data: `a "b" c; d`},
+		}, false},
+		{"06 - $ORIGIN", "$ORIGIN lan.\nnas A 192.0.2.9\n", []tRecordConfig{
+			{Name: "nas.lan.", Type: "A", Data: "192.0.2.9"},
+		}, false},
+		{"07 - missing owner", " A 192.0.2.1\n", nil, true},
+		{"08 - unsupported type", "@ CAA 0 issue \"ca.example\"\n", nil, true},
+		{"09 - unsupported directive", "$INCLUDE other.zone\n", nil, true},
+		{"10 - unbalanced parentheses", "@ SOA ns hostmaster ( 1 2 3 4 5\n", nil, true},
+		{"11 - unterminated string", "@ TXT \"text\n", nil, true},
+		{"12 - missing data", "www A\n", nil, true},
+		{"13 - invalid $TTL", "$TTL 1x\n", nil, true},
+		{"14 - wildcard owner", "*.dev A 127.0.0.1\n", []tRecordConfig{
+			{Name: "*.dev.example.com.", Type: "A", Data: "127.0.0.1"},
+		}, false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseZoneFile(strings.NewReader(tc.text), "Example.com.")
+			if (nil != err) != tc.wantErr {
+				t.Fatalf("parseZoneFile() error = '%v', wantErr '%v'", err, tc.wantErr)
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("parseZoneFile() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_parseZoneFile()
+
+func Test_parseZoneTTL(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		want   uint32
+		wantOK bool
+	}{
+		/* */
+		{"01 - seconds", "3600", 3600, true},
+		{"02 - units", "1h30m", 5400, true},
+		{"03 - upper case", "2D", 172800, true},
+		{"04 - week", "1w", 604800, true},
+		{"05 - missing unit", "1h30", 0, false},
+		{"06 - unknown unit", "5y", 0, false},
+		{"07 - no number", "h", 0, false},
+		{"08 - overflow", "9999999w", 0, false},
+		{"09 - class", "IN", 0, false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := parseZoneTTL(tc.value)
+			if (got != tc.want) || (ok != tc.wantOK) {
+				t.Errorf("parseZoneTTL() = '%d', '%v', want '%d', '%v'", got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+} // Test_parseZoneTTL()
+
+func Test_newAuthZone_file(t *testing.T) {
+	fName := filepath.Join(t.TempDir(), "example.com.zone")
+	if err := os.WriteFile(fName, []byte(testZoneFile), 0600); nil != err {
+		t.Fatalf("WriteFile() error = '%v'", err)
+	}
+	zones, err := newAuthZones(tConfiguration{Zones: []tZoneConfig{{
+		Name:    "example.com",
+		File:    fName,
+		Records: []tRecordConfig{{Name: "extra", Type: "A", Data: "192.0.2.77"}},
+	}}})
+	if nil != err {
+		t.Fatalf("newAuthZones() error = '%v'", err)
+	}
+
+	tests := []struct {
+		name    string
+		qName   string
+		qType   uint16
+		wantTTL uint32
+		want    []dnsmessage.Type
+	}{
+		/* */
+		{"01 - SOA record", "example.com", dnsTypeSOA, 3600, []dnsmessage.Type{dnsmessage.TypeSOA}},
+		{"02 - NS record", "example.com", dnsTypeNS, 3600, []dnsmessage.Type{dnsmessage.TypeNS}},
+		{"03 - explicit TTL", "mail.example.com", dnsTypeA, 600, []dnsmessage.Type{dnsmessage.TypeA}},
+		{"04 - same owner", "www.example.com", dnsTypeAAAA, 3600, []dnsmessage.Type{dnsmessage.TypeAAAA}},
+		{"05 - alias", "ftp.example.com", dnsTypeA, 3600, []dnsmessage.Type{dnsmessage.TypeCNAME, dnsmessage.TypeA}},
+		{"06 - service", "_sip._tcp.example.com", dnsTypeSRV, 3600, []dnsmessage.Type{dnsmessage.TypeSRV}},
+		{"07 - changed origin", "nas.lab.example.com", dnsTypeA, 3600, []dnsmessage.Type{dnsmessage.TypeA}},
+		{"08 - configured record", "extra.example.com", dnsTypeA, zoneTTL, []dnsmessage.Type{dnsmessage.TypeA}},
+		{"09 - joined text", "example.com", dnsTypeTXT, 3600, []dnsmessage.Type{dnsmessage.TypeTXT}},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var msg dnsmessage.Message
+			if err := msg.Unpack(zones.answer(workload.Query(1, tc.qName, tc.qType), false)); nil != err {
+				t.Fatalf("Unpack() error = '%v'", err)
+			}
+			if len(msg.Answers) != len(tc.want) {
+				t.Fatalf("Answers = '%v', want '%v'", msg.Answers, tc.want)
+			}
+			for i, rr := range msg.Answers {
+				if rr.Header.Type != tc.want[i] {
+					t.Errorf("Answers[%d] = '%v', want '%v'", i, rr.Header.Type, tc.want[i])
+				}
+			}
+			if got := msg.Answers[0].Header.TTL; got != tc.wantTTL {
+				t.Errorf("TTL = '%d', want '%d'", got, tc.wantTTL)
+			}
+
+			switch body := msg.Answers[0].Body.(type) {
+			case *dnsmessage.SOAResource:
+				if (2024061501 != body.Serial) || (7200 != body.Refresh) || (604800 != body.Expire) {
+					t.Errorf("SOA = '%v', want the file's values", body)
+				}
+			case *dnsmessage.SRVResource:
+				if (5060 != body.Port) || ("sip.example.com." != body.Target.String()) {
+					t.Errorf("SRV = '%v', want 'sip.example.com.:5060'", body)
+				}
+			case *dnsmessage.TXTResource:
+				if !slices.Equal(body.TXT, []string{"v=spf1 mx -all"}) {
+					t.Errorf("TXT = '%v', want 'v=spf1 mx -all'", body.TXT)
+				}
+			}
+		})
+	}
+
+	// The file's SOA record replaces the generated one
+	soa := zones.zone("example.com").soa
+	if got := binary.BigEndian.Uint32(soa.data[len(soa.data)-20:]); 2024061501 != got {
+		t.Errorf("serial = '%d', want '2024061501'", got)
+	}
+} // Test_newAuthZone_file()
+
+/* _EoF_ */
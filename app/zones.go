@@ -0,0 +1,670 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+const (
+	// Additional DNS record types of the authoritative zones
+	dnsTypeMX  uint16 = 15 // Mail exchange
+	dnsTypeSRV uint16 = 33 // Service locator
+
+	// `maxCNAMEChain` is the max. number of CNAME records followed
+	// within an authoritative zone.
+	maxCNAMEChain = 8
+
+	// `maxUDPResponse` is the max. size of a UDP response to clients
+	// not announcing a larger one by EDNS.
+	maxUDPResponse = 512
+)
+
+type (
+	// `tAuthRecord` is a resource record of an authoritative zone.
+	tAuthRecord struct {
+		name   string // lower-cased owner name without trailing dot
+		target string // canonical name of a CNAME record
+		data   []byte // RDATA in wire format
+		ttl    uint32
+		rType  uint16
+	}
+
+	// `tAuthZone` is a zone answered authoritatively from its records.
+	tAuthZone struct {
+		name    string                   // lower-cased zone name without trailing dot
+		soa     tAuthRecord              // the zone's SOA record
+		records map[string][]tAuthRecord // owner name → records (empty for intermediate names)
+	}
+
+	// `tAuthZones` are the zones answered authoritatively.
+	tAuthZones struct {
+		zones []*tAuthZone // sorted by decreasing name length
+	}
+)
+
+var (
+	// `gAuthZones` are the active authoritative zones (`nil` means
+	// none are configured).
+	gAuthZones atomic.Pointer[tAuthZones]
+
+	// `zoneRecordTypes` are the record types allowed in the zones'
+	// configuration.
+	zoneRecordTypes = map[string]uint16{
+		"A":     dnsTypeA,
+		"AAAA":  dnsTypeAAAA,
+		"CNAME": dnsTypeCNAME,
+		"MX":    dnsTypeMX,
+		"NS":    dnsTypeNS,
+		"PTR":   dnsTypePTR,
+		"SOA":   dnsTypeSOA,
+		"SRV":   dnsTypeSRV,
+		"TXT":   dnsTypeTXT,
+	}
+)
+
+// ---------------------------------------------------------------------------
+// `tAuthZones` constructor:
+
+// `newAuthZones()` creates the authoritative zones.
+//
+// Each zone gets, unless configured, a SOA record and an NS record
+// naming `ns.<zone>` as its name server.
+//
+// Parameters:
+//   - `aConfig`: The configuration to use.
+//
+// Returns:
+//   - `*tAuthZones`: The authoritative zones (`nil` if not configured).
+//   - `error`: `nil` if the configuration is valid, the error otherwise.
+func newAuthZones(aConfig tConfiguration) (*tAuthZones, error) {
+	if 0 == len(aConfig.Zones) {
+		return nil, nil
+	}
+
+	var errs []error
+	serial := uint32(time.Now().Unix()) //#nosec G115
+	result := &tAuthZones{}
+	for _, zc := range aConfig.Zones {
+		zone, err := newAuthZone(zc, serial)
+		if nil != err {
+			errs = append(errs, err)
+			continue
+		}
+		if slices.ContainsFunc(result.zones, func(aZone *tAuthZone) bool {
+			return aZone.name == zone.name
+		}) {
+			errs = append(errs, fmt.Errorf("duplicate zone: %q", zc.Name))
+			continue
+		}
+		result.zones = append(result.zones, zone)
+	}
+	if 0 < len(errs) {
+		return nil, errors.Join(errs...)
+	}
+	slices.SortStableFunc(result.zones, func(a, b *tAuthZone) int {
+		return len(b.name) - len(a.name)
+	})
+
+	return result, nil
+} // newAuthZones()
+
+// ---------------------------------------------------------------------------
+// `tAuthZone` constructor:
+
+// `newAuthZone()` creates an authoritative zone from its configuration.
+//
+// The records of the zone's master file (if any) come first, followed
+// by the configured ones.
+//
+// Parameters:
+//   - `aConfig`: The zone's configuration.
+//   - `aSerial`: The serial number of the zone's SOA record.
+//
+// Returns:
+//   - `*tAuthZone`: The zone.
+//   - `error`: `nil` if the configuration is valid, the error otherwise.
+func newAuthZone(aConfig tZoneConfig, aSerial uint32) (*tAuthZone, error) {
+	name := strings.Trim(strings.ToLower(strings.TrimSpace(aConfig.Name)), ".")
+	if nil == encodeName(name) {
+		return nil, fmt.Errorf("invalid zone: %q", aConfig.Name)
+	}
+	ttl := aConfig.TTL
+	if 0 == ttl {
+		ttl = zoneTTL
+	}
+
+	result := &tAuthZone{
+		name: name,
+		soa: tAuthRecord{
+			name:  name,
+			data:  soaData(name, aSerial),
+			ttl:   ttl,
+			rType: dnsTypeSOA,
+		},
+		records: make(map[string][]tAuthRecord),
+	}
+
+	var errs []error
+	configs := aConfig.Records
+	if "" != aConfig.File {
+		records, err := loadZoneFile(aConfig.File, name)
+		if nil != err {
+			return nil, fmt.Errorf("zone %q: %w", name, err)
+		}
+		configs = append(records, configs...)
+	}
+	soaCount := 0
+	for _, rc := range configs {
+		rec, err := zoneRecord(rc, name, ttl)
+		if nil != err {
+			errs = append(errs, fmt.Errorf("zone %q: %w", name, err))
+			continue
+		}
+		if dnsTypeSOA == rec.rType {
+			if soaCount++; 1 < soaCount {
+				errs = append(errs, fmt.Errorf("zone %q: more than one SOA record", name))
+			}
+			result.soa = rec
+			continue
+		}
+		result.add(rec)
+	}
+	result.add(result.soa)
+	if !slices.ContainsFunc(result.records[name], func(aRec tAuthRecord) bool {
+		return dnsTypeNS == aRec.rType
+	}) {
+		result.add(tAuthRecord{
+			name:  name,
+			data:  encodeName("ns." + name),
+			ttl:   ttl,
+			rType: dnsTypeNS,
+		})
+	}
+	for owner, records := range result.records {
+		if (1 < len(records)) && slices.ContainsFunc(records, func(aRec tAuthRecord) bool {
+			return dnsTypeCNAME == aRec.rType
+		}) {
+			errs = append(errs, fmt.Errorf("zone %q: CNAME and other records at %q", name, owner))
+		}
+	}
+	if 0 < len(errs) {
+		return nil, errors.Join(errs...)
+	}
+
+	return result, nil
+} // newAuthZone()
+
+// ---------------------------------------------------------------------------
+// `tAuthZone` methods:
+
+// `add()` inserts a record into the zone.
+//
+// The names between the record's owner and the zone get an empty
+// list of records so that queries for them aren't answered NXDOMAIN.
+//
+// Parameters:
+//   - `aRecord`: The record to add.
+func (az *tAuthZone) add(aRecord tAuthRecord) {
+	az.records[aRecord.name] = append(az.records[aRecord.name], aRecord)
+
+	for name := aRecord.name; name != az.name; {
+		_, parent, ok := strings.Cut(name, ".")
+		if !ok || (len(parent) < len(az.name)) {
+			break
+		}
+		if _, ok = az.records[parent]; !ok {
+			az.records[parent] = nil
+		}
+		name = parent
+	}
+} // add()
+
+// `contains()` checks whether a name belongs to the zone.
+//
+// Parameters:
+//   - `aName`: The lower-cased name without trailing dot.
+//
+// Returns:
+//   - `bool`: `true` if the name is the zone or below it.
+func (az *tAuthZone) contains(aName string) bool {
+	return (aName == az.name) || strings.HasSuffix(aName, "."+az.name)
+} // contains()
+
+// `resolve()` collects the records answering a query.
+//
+// CNAME records are followed as long as their targets are within the
+// zone; other targets are left to the client to resolve. Names
+// without records of their own are answered from a matching
+// wildcard record (see `wildcard()`).
+//
+// Parameters:
+//   - `aName`: The lower-cased query name without trailing dot.
+//   - `aType`: The query type.
+//
+// Returns:
+//   - `[]tAuthRecord`: The answer records (empty for no data).
+//   - `bool`: `false` if the name doesn't exist, `true` otherwise.
+func (az *tAuthZone) resolve(aName string, aType uint16) ([]tAuthRecord, bool) {
+	var result []tAuthRecord
+
+	name := aName
+	for range maxCNAMEChain {
+		records, ok := az.records[name]
+		if !ok {
+			if records, ok = az.wildcard(name); !ok {
+				return result, (name != aName)
+			}
+		}
+		matched := false
+		for _, rec := range records {
+			if (aType == rec.rType) || (dnsTypeANY == aType) {
+				result = append(result, rec)
+				matched = true
+			}
+		}
+		if matched || (1 != len(records)) || (dnsTypeCNAME != records[0].rType) {
+			break
+		}
+
+		// Follow the alias (if it's ours)
+		result = append(result, records[0])
+		if name = records[0].target; !az.contains(name) {
+			break
+		}
+	}
+
+	return result, true
+} // resolve()
+
+// `wildcard()` synthesizes the records of a name from the zone's
+// wildcard records (RFC 4592).
+//
+// A record like `*.dev.example.com` answers all names below
+// `dev.example.com` (e.g. `a.b.dev.example.com`) except those at or
+// below another existing name.
+//
+// Parameters:
+//   - `aName`: The lower-cased name without records of its own.
+//
+// Returns:
+//   - `[]tAuthRecord`: The records owned by `aName`.
+//   - `bool`: `true` if a wildcard matched, `false` otherwise.
+func (az *tAuthZone) wildcard(aName string) ([]tAuthRecord, bool) {
+	for name := aName; name != az.name; {
+		_, parent, ok := strings.Cut(name, ".")
+		if !ok {
+			break
+		}
+		if _, ok = az.records[parent]; !ok {
+			name = parent
+			continue
+		}
+
+		// `parent` is the closest existing ancestor
+		records, ok := az.records["*."+parent]
+		if !ok {
+			break
+		}
+		result := make([]tAuthRecord, len(records))
+		for i, rec := range records {
+			rec.name = aName
+			result[i] = rec
+		}
+		return result, true
+	}
+
+	return nil, false
+} // wildcard()
+
+// ---------------------------------------------------------------------------
+// `tAuthZones` methods:
+
+// `answer()` creates the authoritative response to a query.
+//
+// Parameters:
+//   - `aRequest`: The DNS request message.
+//   - `aRecursion`: Whether to announce recursion to the client.
+//
+// Returns:
+//   - `[]byte`: The response (`nil` if the query isn't for a zone).
+func (azs *tAuthZones) answer(aRequest []byte, aRecursion bool) []byte {
+	if (nil == azs) || (1 != binary.BigEndian.Uint16(aRequest[4:6])) ||
+		(0 != binary.BigEndian.Uint16(aRequest[2:4])&dnsOpcodeMask) {
+		return nil
+	}
+	qType, qClass, ok := questionType(aRequest)
+	if !ok || ((dnsClassIN != qClass) && (dnsClassANY != qClass)) || (dnsTypeAXFR == qType) {
+		return nil
+	}
+	qName := strings.ToLower(strings.TrimSuffix(extractFirstHostname(aRequest), "."))
+	zone := azs.zone(qName)
+	if nil == zone {
+		return nil
+	}
+
+	flags := dnsQR | dnsAA | (binary.BigEndian.Uint16(aRequest[2:4]) & dnsRD)
+	if aRecursion {
+		flags |= dnsRA
+	}
+	answers, found := zone.resolve(qName, qType)
+	if !found {
+		flags |= dnsRcodeNXDomain
+	}
+
+	end := questionEnd(aRequest)
+	response := make([]byte, 12, maxUDPResponse)
+	binary.BigEndian.PutUint16(response[0:2], binary.BigEndian.Uint16(aRequest[0:2]))
+	binary.BigEndian.PutUint16(response[2:4], flags)
+	binary.BigEndian.PutUint16(response[4:6], 1) // QDCount
+	response = append(response, aRequest[12:end]...)
+
+	if 0 == len(answers) {
+		// Negative answers carry the zone's SOA record
+		binary.BigEndian.PutUint16(response[8:10], 1) // NSCount
+		return appendAuthRecord(response, zone.soa, qName)
+	}
+	binary.BigEndian.PutUint16(response[6:8], uint16(len(answers))) //#nosec G115
+	for _, rec := range answers {
+		response = appendAuthRecord(response, rec, qName)
+	}
+
+	return response
+} // answer()
+
+// `zone()` returns the zone responsible for a name.
+//
+// Parameters:
+//   - `aName`: The lower-cased name without trailing dot.
+//
+// Returns:
+//   - `*tAuthZone`: The most specific zone (`nil` if none).
+func (azs *tAuthZones) zone(aName string) *tAuthZone {
+	if nil == azs {
+		return nil
+	}
+	for _, zone := range azs.zones {
+		if zone.contains(aName) {
+			return zone
+		}
+	}
+
+	return nil
+} // zone()
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `appendAuthRecord()` appends a resource record to a DNS message.
+//
+// Parameters:
+//   - `aMessage`: The message to extend.
+//   - `aRecord`: The record to append.
+//   - `aQName`: The query name (compressed by a pointer to the question).
+//
+// Returns:
+//   - `[]byte`: The extended message.
+func appendAuthRecord(aMessage []byte, aRecord tAuthRecord, aQName string) []byte {
+	if aRecord.name == aQName {
+		aMessage = binary.BigEndian.AppendUint16(aMessage, 0xC00C) // name pointer
+	} else {
+		aMessage = append(aMessage, encodeName(aRecord.name)...)
+	}
+	aMessage = binary.BigEndian.AppendUint16(aMessage, aRecord.rType)
+	aMessage = binary.BigEndian.AppendUint16(aMessage, dnsClassIN)
+	aMessage = binary.BigEndian.AppendUint32(aMessage, aRecord.ttl)
+	aMessage = binary.BigEndian.AppendUint16(aMessage, uint16(len(aRecord.data))) //#nosec G115
+
+	return append(aMessage, aRecord.data...)
+} // appendAuthRecord()
+
+// `handleAuthRequest()` answers a query for a name of the
+// authoritative zones.
+//
+// The answers are taken from the zones' records only, i.e. the names
+// are never resolved recursively. Recursion is only announced to
+// local (loopback, private, and link-local) clients.
+//
+// Parameters:
+//   - `aConn`: The connection to write the response to.
+//   - `aAddr`: The address to send the response to.
+//   - `aRequest`: The DNS request message.
+//
+// Returns:
+//   - `bool`: `true` if the request was answered, `false` otherwise.
+func handleAuthRequest(aConn net.PacketConn, aAddr net.Addr, aRequest []byte) bool {
+	client := addrIP(aAddr)
+	local := (nil != client) &&
+		(client.IsLoopback() || client.IsPrivate() || client.IsLinkLocalUnicast())
+
+	response := gAuthZones.Load().answer(aRequest, local)
+	if nil == response {
+		return false
+	}
+	response = limitResponse(response)
+	if _, ok := aConn.(tTCPConn); !ok && (udpPayloadSize(aRequest) < len(response)) {
+		// Let the client retry by TCP
+		response = response[:questionEnd(response)]
+		binary.BigEndian.PutUint16(response[2:4], binary.BigEndian.Uint16(response[2:4])|dnsTC)
+		binary.BigEndian.PutUint16(response[6:8], 0)
+		binary.BigEndian.PutUint16(response[8:10], 0)
+		binary.BigEndian.PutUint16(response[10:12], 0)
+	}
+
+	_, _ = aConn.WriteTo(response, aAddr)
+	// Error sending response is not critical, hence we ignore it.
+
+	return true
+} // handleAuthRequest()
+
+// `qualifyName()` returns the absolute form of a name of a zone.
+//
+// Names ending with a dot are absolute, `@` (or an empty name) is
+// the zone itself, names ending with the zone's name are taken as
+// they are, and all other names are relative to the zone.
+//
+// Parameters:
+//   - `aName`: The name to qualify.
+//   - `aZone`: The zone's name.
+//
+// Returns:
+//   - `string`: The lower-cased name without trailing dot.
+func qualifyName(aName, aZone string) string {
+	name := strings.ToLower(strings.TrimSpace(aName))
+	switch {
+	case ("" == name) || ("@" == name):
+		return aZone
+	case strings.HasSuffix(name, "."):
+		return strings.TrimSuffix(name, ".")
+	case (name == aZone) || strings.HasSuffix(name, "."+aZone):
+		return name
+	}
+
+	return name + "." + aZone
+} // qualifyName()
+
+// `soaRecordData()` converts the fields of a SOA record to its RDATA.
+//
+// The fields are the primary name server, the responsible mailbox,
+// the serial number, and the refresh, retry, expire, and minimum
+// times (which may use units like `1h`).
+//
+// Parameters:
+//   - `aFields`: The record's data fields.
+//   - `aZone`: The zone's name.
+//
+// Returns:
+//   - `[]byte`: The SOA record's data (`nil` if invalid).
+func soaRecordData(aFields []string, aZone string) []byte {
+	if 7 != len(aFields) {
+		return nil
+	}
+	mname := encodeName(qualifyName(aFields[0], aZone))
+	rname := encodeName(qualifyName(aFields[1], aZone))
+	serial, err := strconv.ParseUint(aFields[2], 10, 32)
+	if (nil == mname) || (nil == rname) || (nil != err) {
+		return nil
+	}
+
+	result := append(mname, rname...)
+	result = binary.BigEndian.AppendUint32(result, uint32(serial))
+	for _, field := range aFields[3:] {
+		value, ok := parseZoneTTL(field)
+		if !ok {
+			return nil
+		}
+		result = binary.BigEndian.AppendUint32(result, value)
+	}
+
+	return result
+} // soaRecordData()
+
+// `udpPayloadSize()` returns the max. size of a UDP response the
+// client accepts.
+//
+// Parameters:
+//   - `aRequest`: The DNS request message.
+//
+// Returns:
+//   - `int`: The EDNS payload size, or `maxUDPResponse` without EDNS.
+func udpPayloadSize(aRequest []byte) int {
+	offset := questionEnd(aRequest)
+	if 0 == offset {
+		return maxUDPResponse
+	}
+	count := int(binary.BigEndian.Uint16(aRequest[6:8])) +
+		int(binary.BigEndian.Uint16(aRequest[8:10])) +
+		int(binary.BigEndian.Uint16(aRequest[10:12]))
+
+	for range count {
+		end, rType, ok := skipRecord(aRequest, offset)
+		if !ok {
+			break
+		}
+		if dnsTypeOPT == rType {
+			// The OPT record's class is the payload size
+			nameEnd, _ := skipName(aRequest, offset)
+			return max(maxUDPResponse, int(binary.BigEndian.Uint16(aRequest[nameEnd+2:nameEnd+4])))
+		}
+		offset = end
+	}
+
+	return maxUDPResponse
+} // udpPayloadSize()
+
+// `zoneRecord()` converts a configured record to the wire format.
+//
+// Parameters:
+//   - `aConfig`: The record's configuration.
+//   - `aZone`: The zone's name.
+//   - `aTTL`: The zone's default TTL.
+//
+// Returns:
+//   - `tAuthRecord`: The record.
+//   - `error`: `nil` if the record is valid, the error otherwise.
+func zoneRecord(aConfig tRecordConfig, aZone string, aTTL uint32) (tAuthRecord, error) {
+	result := tAuthRecord{
+		name: qualifyName(aConfig.Name, aZone),
+		ttl:  aConfig.TTL,
+	}
+	if 0 == result.ttl {
+		result.ttl = aTTL
+	}
+	if (nil == encodeName(result.name)) ||
+		((result.name != aZone) && !strings.HasSuffix(result.name, "."+aZone)) {
+		return result, fmt.Errorf("invalid record name: %q", aConfig.Name)
+	}
+	rType, ok := zoneRecordTypes[strings.ToUpper(strings.TrimSpace(aConfig.Type))]
+	if !ok {
+		return result, fmt.Errorf("unsupported record type: %q", aConfig.Type)
+	}
+	result.rType = rType
+
+	data := strings.TrimSpace(aConfig.Data)
+	switch rType {
+	case dnsTypeA:
+		result.data = net.ParseIP(data).To4()
+
+	case dnsTypeAAAA:
+		if ip := net.ParseIP(data); (nil != ip) && (nil == ip.To4()) {
+			result.data = ip.To16()
+		}
+
+	case dnsTypeCNAME, dnsTypeNS, dnsTypePTR:
+		result.target = qualifyName(data, aZone)
+		result.data = encodeName(result.target)
+
+	case dnsTypeMX:
+		fields := strings.Fields(data)
+		if 2 != len(fields) {
+			break
+		}
+		preference, err := strconv.ParseUint(fields[0], 10, 16)
+		if target := encodeName(qualifyName(fields[1], aZone)); (nil == err) && (nil != target) {
+			result.data = binary.BigEndian.AppendUint16(nil, uint16(preference))
+			result.data = append(result.data, target...)
+		}
+
+	case dnsTypeSOA:
+		if result.name == aZone {
+			result.data = soaRecordData(strings.Fields(data), aZone)
+		}
+
+	case dnsTypeSRV:
+		// priority, weight, port, and target
+		fields := strings.Fields(data)
+		if 4 != len(fields) {
+			break
+		}
+		var rdata []byte
+		for _, field := range fields[:3] {
+			value, err := strconv.ParseUint(field, 10, 16)
+			if nil != err {
+				break
+			}
+			rdata = binary.BigEndian.AppendUint16(rdata, uint16(value))
+		}
+		target := encodeName(qualifyName(fields[3], aZone))
+		if "." == fields[3] {
+			target = []byte{0} // service not available
+		}
+		if (6 == len(rdata)) && (nil != target) {
+			result.data = append(rdata, target...)
+		}
+
+	case dnsTypeTXT:
+		// Long texts are split into strings of max. 255 bytes
+		result.data = []byte{}
+		for text := aConfig.Data; ; text = text[0xFF:] {
+			if 0xFF >= len(text) {
+				result.data = append(append(result.data, byte(len(text))), text...)
+				break
+			}
+			result.data = append(append(result.data, 0xFF), text[:0xFF]...)
+		}
+		if 0xFFFF < len(result.data) {
+			result.data = nil
+		}
+	}
+	if nil == result.data {
+		return result, fmt.Errorf("invalid %s record data: %q", aConfig.Type, aConfig.Data)
+	}
+
+	return result, nil
+} // zoneRecord()
+
+/* _EoF_ */
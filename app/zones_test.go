@@ -0,0 +1,270 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/mwat56/dnscache"
+	"github.com/mwat56/dnscache/internal/workload"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// `testZones()` returns the configuration of the zones used by the tests.
+func testZones() tConfiguration {
+	return tConfiguration{Zones: []tZoneConfig{{
+		Name: "Example.com.",
+		Records: []tRecordConfig{
+			{Name: "www", Type: "A", Data: "192.0.2.1"},
+			{Name: "www.example.com", Type: "aaaa", Data: "2001:db8::1", TTL: 60},
+			{Name: "mail", Type: "A", Data: "192.0.2.25"},
+			{Name: "@", Type: "MX", Data: "10 mail"},
+			{Name: "@", Type: "TXT", Data: "v=spf1 mx -all"},
+			{Name: "alias", Type: "CNAME", Data: "www"},
+			{Name: "cdn", Type: "CNAME", Data: "cdn.example.net."},
+			{Name: "host.lab", Type: "A", Data: "192.0.2.99"},
+			{Name: "long", Type: "TXT", Data: strings.Repeat("x", 600)},
+			{Name: "*.dev", Type: "A", Data: "127.0.0.1"},
+			{Name: "api.dev", Type: "TXT", Data: "no wildcard"},
+			{Name: "svc.team.dev", Type: "A", Data: "192.0.2.81"},
+			{Name: "*.pages", Type: "CNAME", Data: "www"},
+		},
+	}, {
+		Name: "sub.example.com",
+		TTL:  30,
+		Records: []tRecordConfig{
+			{Name: "@", Type: "NS", Data: "ns1.example.com."},
+			{Name: "@", Type: "A", Data: "192.0.2.50"},
+		},
+	}}}
+} // testZones()
+
+func Test_newAuthZones(t *testing.T) {
+	tests := []struct {
+		name    string
+		zones   []tZoneConfig
+		wantNil bool
+		wantErr bool
+	}{
+		/* */
+		{"01 - not configured", nil, true, false},
+		{"02 - valid zones", testZones().Zones, false, false},
+		{"03 - invalid zone name", []tZoneConfig{{Name: ""}}, true, true},
+		{"04 - duplicate zone", []tZoneConfig{{Name: "lan"}, {Name: "LAN."}}, true, true},
+		{"05 - unsupported type", []tZoneConfig{{Name: "lan", Records: []tRecordConfig{
+			{Name: "x", Type: "CAA", Data: "0 issue ca.example"}}}}, true, true},
+		{"06 - invalid address", []tZoneConfig{{Name: "lan", Records: []tRecordConfig{
+			{Name: "x", Type: "A", Data: "2001:db8::1"}}}}, true, true},
+		{"07 - invalid IPv6 address", []tZoneConfig{{Name: "lan", Records: []tRecordConfig{
+			{Name: "x", Type: "AAAA", Data: "192.0.2.1"}}}}, true, true},
+		{"08 - name outside zone", []tZoneConfig{{Name: "lan", Records: []tRecordConfig{
+			{Name: "x.example.com.", Type: "A", Data: "192.0.2.1"}}}}, true, true},
+		{"09 - CNAME and other data", []tZoneConfig{{Name: "lan", Records: []tRecordConfig{
+			{Name: "x", Type: "CNAME", Data: "y"}, {Name: "x", Type: "A", Data: "192.0.2.1"}}}}, true, true},
+		{"10 - invalid MX", []tZoneConfig{{Name: "lan", Records: []tRecordConfig{
+			{Name: "@", Type: "MX", Data: "mail"}}}}, true, true},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := newAuthZones(tConfiguration{Zones: tc.zones})
+			if (nil != err) != tc.wantErr {
+				t.Errorf("newAuthZones() error = '%v', wantErr '%v'", err, tc.wantErr)
+			}
+			if (nil == got) != tc.wantNil {
+				t.Errorf("newAuthZones() = '%v', wantNil '%v'", got, tc.wantNil)
+			}
+		})
+	}
+} // Test_newAuthZones()
+
+func Test_tAuthZones_answer(t *testing.T) {
+	zones, err := newAuthZones(testZones())
+	if nil != err {
+		t.Fatalf("newAuthZones() error = '%v'", err)
+	}
+
+	tests := []struct {
+		name        string
+		qName       string
+		qType       uint16
+		wantNil     bool
+		wantRcode   dnsmessage.RCode
+		wantAnswers []dnsmessage.Type
+		wantNS      int
+	}{
+		/* */
+		{"01 - A record", "www.example.com", dnsTypeA, false,
+			dnsmessage.RCodeSuccess, []dnsmessage.Type{dnsmessage.TypeA}, 0},
+		{"02 - AAAA record", "www.example.com", dnsTypeAAAA, false,
+			dnsmessage.RCodeSuccess, []dnsmessage.Type{dnsmessage.TypeAAAA}, 0},
+		{"03 - MX record", "example.com", dnsTypeMX, false,
+			dnsmessage.RCodeSuccess, []dnsmessage.Type{dnsmessage.TypeMX}, 0},
+		{"04 - TXT record", "example.com", dnsTypeTXT, false,
+			dnsmessage.RCodeSuccess, []dnsmessage.Type{dnsmessage.TypeTXT}, 0},
+		{"05 - SOA record", "example.com", dnsTypeSOA, false,
+			dnsmessage.RCodeSuccess, []dnsmessage.Type{dnsmessage.TypeSOA}, 0},
+		{"06 - default NS record", "example.com", dnsTypeNS, false,
+			dnsmessage.RCodeSuccess, []dnsmessage.Type{dnsmessage.TypeNS}, 0},
+		{"07 - CNAME within zone", "alias.example.com", dnsTypeA, false,
+			dnsmessage.RCodeSuccess, []dnsmessage.Type{dnsmessage.TypeCNAME, dnsmessage.TypeA}, 0},
+		{"08 - CNAME outside zone", "cdn.example.com", dnsTypeA, false,
+			dnsmessage.RCodeSuccess, []dnsmessage.Type{dnsmessage.TypeCNAME}, 0},
+		{"09 - no data", "www.example.com", dnsTypeMX, false,
+			dnsmessage.RCodeSuccess, nil, 1},
+		{"10 - non-existent name", "nope.example.com", dnsTypeA, false,
+			dnsmessage.RCodeNameError, nil, 1},
+		{"11 - intermediate name", "lab.example.com", dnsTypeA, false,
+			dnsmessage.RCodeSuccess, nil, 1},
+		{"12 - case insensitive", "WWW.Example.COM", dnsTypeA, false,
+			dnsmessage.RCodeSuccess, []dnsmessage.Type{dnsmessage.TypeA}, 0},
+		{"13 - all records", "www.example.com", dnsTypeANY, false,
+			dnsmessage.RCodeSuccess, []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeAAAA}, 0},
+		{"14 - more specific zone", "sub.example.com", dnsTypeNS, false,
+			dnsmessage.RCodeSuccess, []dnsmessage.Type{dnsmessage.TypeNS}, 0},
+		{"15 - other domain", "www.example.org", dnsTypeA, true, 0, nil, 0},
+		{"16 - zone transfer", "example.com", dnsTypeAXFR, true, 0, nil, 0},
+		{"17 - wildcard", "app1.dev.example.com", dnsTypeA, false,
+			dnsmessage.RCodeSuccess, []dnsmessage.Type{dnsmessage.TypeA}, 0},
+		{"18 - wildcard of several labels", "a.b.dev.example.com", dnsTypeA, false,
+			dnsmessage.RCodeSuccess, []dnsmessage.Type{dnsmessage.TypeA}, 0},
+		{"19 - existing name beats wildcard", "api.dev.example.com", dnsTypeA, false,
+			dnsmessage.RCodeSuccess, nil, 1},
+		{"20 - wildcard without data", "app1.dev.example.com", dnsTypeMX, false,
+			dnsmessage.RCodeSuccess, nil, 1},
+		{"21 - wildcard's base", "dev.example.com", dnsTypeA, false,
+			dnsmessage.RCodeSuccess, nil, 1},
+		{"22 - below existing name", "x.team.dev.example.com", dnsTypeA, false,
+			dnsmessage.RCodeNameError, nil, 1},
+		{"23 - wildcard CNAME", "blog.pages.example.com", dnsTypeA, false,
+			dnsmessage.RCodeSuccess, []dnsmessage.Type{dnsmessage.TypeCNAME, dnsmessage.TypeA}, 0},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := zones.answer(workload.Query(4711, tc.qName, tc.qType), false)
+			if nil == got {
+				if !tc.wantNil {
+					t.Error("answer() = 'nil', want a response")
+				}
+				return
+			}
+			if tc.wantNil {
+				t.Fatalf("answer() = '%v', want 'nil'", got)
+			}
+			var msg dnsmessage.Message
+			if err := msg.Unpack(got); nil != err {
+				t.Fatalf("Unpack() error = '%v'", err)
+			}
+			if !msg.Authoritative || msg.RecursionAvailable || (4711 != msg.ID) {
+				t.Errorf("header = '%+v', want authoritative without recursion", msg.Header)
+			}
+			if msg.RCode != tc.wantRcode {
+				t.Errorf("RCode = '%v', want '%v'", msg.RCode, tc.wantRcode)
+			}
+			if len(msg.Answers) != len(tc.wantAnswers) {
+				t.Fatalf("Answers = '%v', want '%v'", msg.Answers, tc.wantAnswers)
+			}
+			for i, rr := range msg.Answers {
+				if rr.Header.Type != tc.wantAnswers[i] {
+					t.Errorf("Answers[%d] = '%v', want '%v'", i, rr.Header.Type, tc.wantAnswers[i])
+				}
+			}
+			if (0 < len(msg.Answers)) && !strings.EqualFold(msg.Answers[0].Header.Name.String(), tc.qName+".") {
+				t.Errorf("Answers[0].Name = '%v', want '%s.'", msg.Answers[0].Header.Name, tc.qName)
+			}
+			if len(msg.Authorities) != tc.wantNS {
+				t.Errorf("Authorities = '%d', want '%d'", len(msg.Authorities), tc.wantNS)
+			}
+		})
+	}
+} // Test_tAuthZones_answer()
+
+func Test_handleDNSRequest_authoritative(t *testing.T) {
+	zones, err := newAuthZones(testZones())
+	if nil != err {
+		t.Fatalf("newAuthZones() error = '%v'", err)
+	}
+	gAuthZones.Store(zones)
+	defer gAuthZones.Store(nil)
+
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{
+		DataDir: t.TempDir(),
+		Offline: true,
+	})
+	defer resolver.StopExpire()
+	external := &net.UDPAddr{IP: net.ParseIP("198.51.100.7"), Port: 5353}
+
+	// `ednsQuery()` returns a query announcing the given payload size.
+	ednsQuery := func(aName string, aType dnsmessage.Type, aSize int) []byte {
+		var opt dnsmessage.ResourceHeader
+		_ = opt.SetEDNS0(aSize, dnsmessage.RCodeSuccess, false)
+		msg := dnsmessage.Message{
+			Header: dnsmessage.Header{ID: 42, RecursionDesired: true},
+			Questions: []dnsmessage.Question{{
+				Name:  dnsmessage.MustNewName(aName),
+				Type:  aType,
+				Class: dnsmessage.ClassINET,
+			}},
+			Additionals: []dnsmessage.Resource{{Header: opt, Body: &dnsmessage.OPTResource{}}},
+		}
+		result, _ := msg.Pack()
+		return result
+	}
+
+	tests := []struct {
+		name      string
+		addr      net.Addr
+		request   []byte
+		wantRA    bool
+		wantTC    bool
+		wantCount uint16
+	}{
+		/* */
+		{"01 - local client", &tMockAddr{}, workload.Query(1, "www.example.com", dnsTypeA), true, false, 1},
+		{"02 - external client", external, workload.Query(2, "www.example.com", dnsTypeA), false, false, 1},
+		{"03 - truncated UDP answer", external, workload.Query(3, "long.example.com", dnsTypeTXT), false, true, 0},
+		{"04 - EDNS payload size", external, ednsQuery("long.example.com.", dnsmessage.TypeTXT, 1232), false, false, 1},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			responses := make(chan []byte, 1)
+			handleDNSRequest(&tMockPacketConn{respChan: responses}, tc.addr, tc.request, resolver)
+
+			var response []byte
+			select {
+			case response = <-responses:
+			default:
+				t.Fatal("no response")
+			}
+			flags := binary.BigEndian.Uint16(response[2:4])
+			if 0 == flags&dnsAA {
+				t.Error("AA bit not set")
+			}
+			if got := 0 != flags&dnsRA; got != tc.wantRA {
+				t.Errorf("RA = '%v', want '%v'", got, tc.wantRA)
+			}
+			if got := 0 != flags&dnsTC; got != tc.wantTC {
+				t.Errorf("TC = '%v', want '%v'", got, tc.wantTC)
+			}
+			if got := binary.BigEndian.Uint16(response[6:8]); got != tc.wantCount {
+				t.Errorf("ANCount = '%d', want '%d'", got, tc.wantCount)
+			}
+		})
+	}
+} // Test_handleDNSRequest_authoritative()
+
+/* _EoF_ */
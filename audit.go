@@ -0,0 +1,87 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"net"
+
+	adl "github.com/mwat56/dnscache/internal/adlist"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// ---------------------------------------------------------------------------
+// `TResolver` methods:
+
+// `AuditMode()` returns whether the resolver is in audit mode.
+//
+// Returns:
+//   - `bool`: `true` if the deny lists aren't enforced, `false` otherwise.
+func (r *TResolver) AuditMode() bool {
+	return r.audit.Load()
+} // AuditMode()
+
+// `auditMatch()` checks the hostname against the allow/deny list
+// without enforcing its decision.
+//
+// A hostname which would have been blocked is logged together with
+// the matching pattern and counted for [TopAudited].
+//
+// Parameters:
+//   - `aCtx`: The query's context.
+//   - `aList`: The allow/deny list to check the hostname against.
+//   - `aClient`: The requesting client's IP address (may be `nil`).
+//   - `aHostname`: The hostname to check.
+func (r *TResolver) auditMatch(aCtx context.Context, aList *adl.TADlist, aClient net.IP, aHostname string) {
+	match := aList.Explain(aCtx, aHostname)
+	if adl.ADdeny != match.Result {
+		return
+	}
+	r.audited.Add(aHostname)
+
+	if nil == aClient {
+		gLog.Info("audit: hostname would be blocked",
+			"hostname", aHostname, "pattern", match.Pattern, "source", match.Source)
+		return
+	}
+	gLog.Info("audit: hostname would be blocked",
+		"hostname", aHostname, "pattern", match.Pattern, "source", match.Source,
+		"client", aClient.String())
+} // auditMatch()
+
+// `SetAuditMode()` switches the audit mode on or off.
+//
+// In audit mode the allow/deny lists are still checked but their
+// decisions aren't enforced: hostnames which would have been blocked
+// are logged (with the matching pattern) and counted (see
+// [TopAudited]) while the queries are answered normally. This allows
+// to evaluate the false positives of a new blocklist before actually
+// using it.
+//
+// Parameters:
+//   - `aAudit`: Whether to only audit the deny lists' matches.
+func (r *TResolver) SetAuditMode(aAudit bool) {
+	r.audit.Store(aAudit)
+} // SetAuditMode()
+
+// `TopAudited()` returns the hostnames most often matched by a deny
+// list while in audit mode (see [SetAuditMode]).
+//
+// The counts are estimated using a bounded number of counters and
+// cover the current and the previous hour.
+//
+// Parameters:
+//   - `aCount`: The max. number of hostnames to return (`0` for all).
+//
+// Returns:
+//   - `[]TTopEntry`: The hostnames sorted by decreasing count.
+func (r *TResolver) TopAudited(aCount int) []TTopEntry {
+	return r.audited.Top(aCount)
+} // TopAudited()
+
+/* _EoF_ */
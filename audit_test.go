@@ -0,0 +1,59 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_TResolver_SetAuditMode(t *testing.T) {
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir(), AuditMode: true})
+	defer r.StopExpire()
+	if !r.AuditMode() {
+		t.Fatal("AuditMode() = 'false', want 'true'")
+	}
+
+	cachedIP := net.ParseIP("192.0.2.1")
+	_ = r.AddDeny("*.ads.localdomain")
+	r.ICacheList.Create(context.TODO(), "banner.ads.localdomain", []net.IP{cachedIP}, time.Minute)
+
+	// Audit mode: the hostname is answered normally but counted
+	ips, err := r.Fetch("banner.ads.localdomain")
+	if (nil != err) || (1 != len(ips)) || !cachedIP.Equal(ips[0]) {
+		t.Errorf("Fetch() = '%v', '%v', want '%v'", ips, err, cachedIP)
+	}
+	if got := r.TopAudited(0); (1 != len(got)) || ("banner.ads.localdomain" != got[0].Name) {
+		t.Errorf("TopAudited() = '%v', want 'banner.ads.localdomain'", got)
+	}
+	if got := r.TopBlocked(0); 0 != len(got) {
+		t.Errorf("TopBlocked() = '%v', want none", got)
+	}
+	trace := r.Trace(context.TODO(), "banner.ads.localdomain")
+	if (3 > len(trace.Steps)) || ("audit" != trace.Steps[2].Result) || !trace.Cached {
+		t.Errorf("Trace() = '%v', want audited and cached", trace)
+	}
+
+	// Enforcing mode: the hostname is blocked
+	r.SetAuditMode(false)
+	if r.AuditMode() {
+		t.Error("AuditMode() = 'true', want 'false'")
+	}
+	ips, err = r.Fetch("banner.ads.localdomain")
+	if (nil != err) || (1 != len(ips)) || !net.IPv4zero.Equal(ips[0]) {
+		t.Errorf("Fetch() = '%v', '%v', want '%v'", ips, err, net.IPv4zero)
+	}
+	if got := r.TopAudited(0); (1 != len(got)) || (1 != got[0].Count) {
+		t.Errorf("TopAudited() = '%v', want one count", got)
+	}
+} // Test_TResolver_SetAuditMode()
+
+/* _EoF_ */
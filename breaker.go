@@ -0,0 +1,403 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"errors"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mwat56/dnscache/clock"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `TBreakerPolicy` determines the answer to lookups while the
+	// circuit breakers of all upstream servers are open.
+	TBreakerPolicy uint8
+
+	// `TBreakerOptions` contains the settings of the upstream
+	// servers' circuit breakers (see [TResolverOptions]).
+	//
+	//   - `Threshold`: Number of consecutive failures opening a server's breaker, `0` means use default (`5`).
+	//   - `Cooldown`: Time an open breaker skips its server, `0` means use default (`30s`).
+	//   - `Policy`: The answer while all breakers are open (default: `BreakerPolicyFail`).
+	TBreakerOptions struct {
+		Threshold int
+		Cooldown  time.Duration
+		Policy    TBreakerPolicy
+	}
+
+	// `TBreakerState` is the state of an upstream server's circuit
+	// breaker as returned by [TResolver.Breakers].
+	TBreakerState struct {
+		OpenUntil *time.Time `json:"openUntil,omitempty"` // end of the cool-down
+		Server    string     `json:"server"`
+		State     string     `json:"state"`    // "closed", "open", or "half-open"
+		Failures  uint32     `json:"failures"` // consecutive failures
+		Trips     uint32     `json:"trips"`    // number of times the breaker opened
+	}
+
+	// `tBreaker` is the circuit breaker of a single upstream server.
+	tBreaker struct {
+		openUntil atomic.Int64  // end of the cool-down (Unix nanoseconds, `0` if closed)
+		failures  atomic.Uint32 // consecutive failures
+		trips     atomic.Uint32 // number of times the breaker opened
+		probing   atomic.Bool   // a trial lookup of a half-open breaker is running
+	}
+
+	// `tStaleEntry` is an expired cache entry kept for
+	// `BreakerPolicyStale`.
+	tStaleEntry struct {
+		expired time.Time
+		ips     []net.IP
+	}
+
+	// `tBreakers` are the circuit breakers of the upstream servers.
+	tBreakers struct {
+		sync.RWMutex
+		servers   map[string]*tBreaker   // breakers by upstream server
+		stale     map[string]tStaleEntry // expired entries by hostname
+		clock     clock.IClock           // source of the current time
+		cooldown  time.Duration          // time an open breaker skips its server
+		threshold uint32                 // consecutive failures opening a breaker
+		policy    TBreakerPolicy         // answer while all breakers are open
+	}
+)
+
+const (
+	// `BreakerPolicyFail` fails the lookups with an error matching
+	// `ErrCircuitOpen` (i.e. SERVFAIL).
+	BreakerPolicyFail = TBreakerPolicy(iota)
+
+	// `BreakerPolicyNXDomain` fails the lookups with a "not found"
+	// error (i.e. NXDOMAIN).
+	BreakerPolicyNXDomain
+
+	// `BreakerPolicyStale` answers with the addresses of expired
+	// cache entries, failing like `BreakerPolicyFail` for hostnames
+	// without one.
+	BreakerPolicyStale
+)
+
+const (
+	// `defBreakerThreshold` is the default number of consecutive
+	// failures opening a breaker.
+	defBreakerThreshold = 5
+
+	// `defBreakerCooldown` is the default time an open breaker skips
+	// its server.
+	defBreakerCooldown = 30 * time.Second
+
+	// `maxStaleEntries` is the max. number of expired cache entries
+	// kept for `BreakerPolicyStale`.
+	maxStaleEntries = 1 << 12
+
+	// `maxStaleAge` is the max. time an expired entry gets served
+	// (see RFC 8767).
+	maxStaleAge = 72 * time.Hour
+)
+
+// ---------------------------------------------------------------------------
+// `tBreakers` constructor:
+
+// `newBreakers()` creates the circuit breakers of the upstream servers.
+//
+// Parameters:
+//   - `aOptions`: The breakers' settings (`nil` disables them).
+//   - `aClock`: The source of the current time.
+//
+// Returns:
+//   - `*tBreakers`: The circuit breakers (`nil` if disabled).
+func newBreakers(aOptions *TBreakerOptions, aClock clock.IClock) *tBreakers {
+	if nil == aOptions {
+		return nil
+	}
+	result := &tBreakers{
+		servers:   make(map[string]*tBreaker),
+		clock:     aClock,
+		cooldown:  aOptions.Cooldown,
+		threshold: uint32(max(aOptions.Threshold, 0)), //#nosec G115
+		policy:    aOptions.Policy,
+	}
+	if 0 >= result.cooldown {
+		result.cooldown = defBreakerCooldown
+	}
+	if 0 == result.threshold {
+		result.threshold = defBreakerThreshold
+	}
+	if BreakerPolicyStale == result.policy {
+		result.stale = make(map[string]tStaleEntry)
+	}
+
+	return result
+} // newBreakers()
+
+// ---------------------------------------------------------------------------
+// `TBreakerPolicy` methods:
+
+// `String()` returns the policy's name.
+//
+// Returns:
+//   - `string`: "fail", "nxdomain", or "stale".
+func (bp TBreakerPolicy) String() string {
+	switch bp {
+	case BreakerPolicyNXDomain:
+		return "nxdomain"
+	case BreakerPolicyStale:
+		return "stale"
+	default:
+		return "fail"
+	}
+} // String()
+
+// ---------------------------------------------------------------------------
+// `tBreakers` methods:
+
+// `allow()` checks whether a server may be queried.
+//
+// An open breaker lets a single trial lookup pass once its cool-down
+// is over (i.e. it's half-open); depending on that lookup's outcome
+// it gets closed or opened again.
+//
+// Parameters:
+//   - `aServer`: The upstream server to query.
+//
+// Returns:
+//   - `bool`: `true` if the server may be queried, `false` otherwise.
+func (b *tBreakers) allow(aServer string) bool {
+	if nil == b {
+		return true
+	}
+	breaker := b.breaker(aServer)
+	until := breaker.openUntil.Load()
+	if 0 == until {
+		return true
+	}
+	if b.clock.Now().UnixNano() < until {
+		return false
+	}
+
+	return breaker.probing.CompareAndSwap(false, true)
+} // allow()
+
+// `breaker()` returns the breaker of a server, creating it if needed.
+//
+// Parameters:
+//   - `aServer`: The upstream server.
+//
+// Returns:
+//   - `*tBreaker`: The server's breaker.
+func (b *tBreakers) breaker(aServer string) *tBreaker {
+	b.RLock()
+	result, ok := b.servers[aServer]
+	b.RUnlock()
+	if ok {
+		return result
+	}
+
+	b.Lock()
+	defer b.Unlock()
+	if result, ok = b.servers[aServer]; !ok {
+		result = &tBreaker{}
+		b.servers[aServer] = result
+	}
+
+	return result
+} // breaker()
+
+// `failure()` counts a failed lookup of a server, opening its
+// breaker if the threshold is reached or a trial lookup failed.
+//
+// Parameters:
+//   - `aServer`: The upstream server that failed.
+func (b *tBreakers) failure(aServer string) {
+	if nil == b {
+		return
+	}
+	breaker := b.breaker(aServer)
+	failures := breaker.failures.Add(1)
+	probing := breaker.probing.Swap(false)
+	if !probing && (failures < b.threshold) {
+		return
+	}
+	until := b.clock.Now().Add(b.cooldown).UnixNano()
+	if 0 == breaker.openUntil.Swap(until) {
+		breaker.trips.Add(1)
+		gLog.Warn("upstream server unavailable",
+			"server", aServer, "failures", failures, "cooldown", b.cooldown)
+	}
+} // failure()
+
+// `fallback()` applies the breakers' policy to a lookup that found
+// all breakers open.
+//
+// Parameters:
+//   - `aHostname`: The hostname to resolve.
+//   - `aErr`: The lookup's error (matching `ErrCircuitOpen`).
+//
+// Returns:
+//   - `[]net.IP`: The stale addresses of the hostname (if any).
+//   - `error`: `nil` if stale addresses are returned, the error otherwise.
+func (b *tBreakers) fallback(aHostname string, aErr error) ([]net.IP, error) {
+	if nil == b {
+		return nil, aErr
+	}
+
+	switch b.policy {
+	case BreakerPolicyNXDomain:
+		return nil, &TLookupError{Hostname: aHostname, Err: &net.DNSError{
+			Err:        "no such host",
+			Name:       aHostname,
+			IsNotFound: true,
+		}}
+
+	case BreakerPolicyStale:
+		b.RLock()
+		entry, ok := b.stale[strings.ToLower(aHostname)]
+		b.RUnlock()
+		if ok && (b.clock.Now().Sub(entry.expired) < maxStaleAge) {
+			return slices.Clone(entry.ips), nil
+		}
+	}
+
+	return nil, aErr
+} // fallback()
+
+// `release()` ends a trial lookup whose outcome is unknown (e.g.
+// because another server answered first).
+//
+// Parameters:
+//   - `aServer`: The upstream server queried.
+func (b *tBreakers) release(aServer string) {
+	if nil == b {
+		return
+	}
+	b.breaker(aServer).probing.Store(false)
+} // release()
+
+// `remember()` keeps an expired cache entry for `BreakerPolicyStale`.
+//
+// It's registered as an expire hook of the resolver.
+//
+// Parameters:
+//   - `aHostname`: The expired hostname.
+//   - `aIPs`: The hostname's addresses.
+func (b *tBreakers) remember(aHostname string, aIPs []net.IP) {
+	if (nil == b) || (0 == len(aIPs)) {
+		return
+	}
+	aHostname = strings.ToLower(aHostname)
+
+	b.Lock()
+	defer b.Unlock()
+	if _, ok := b.stale[aHostname]; !ok && (maxStaleEntries <= len(b.stale)) {
+		// Make room by dropping an arbitrary entry
+		for hostname := range b.stale {
+			delete(b.stale, hostname)
+			break
+		}
+	}
+	b.stale[aHostname] = tStaleEntry{expired: b.clock.Now(), ips: slices.Clone(aIPs)}
+} // remember()
+
+// `states()` returns the states of all breakers.
+//
+// Returns:
+//   - `[]TBreakerState`: The breakers' states sorted by server.
+func (b *tBreakers) states() []TBreakerState {
+	if nil == b {
+		return nil
+	}
+	now := b.clock.Now()
+
+	b.RLock()
+	result := make([]TBreakerState, 0, len(b.servers))
+	for server, breaker := range b.servers {
+		state := TBreakerState{
+			Server:   server,
+			State:    "closed",
+			Failures: breaker.failures.Load(),
+			Trips:    breaker.trips.Load(),
+		}
+		if until := breaker.openUntil.Load(); 0 != until {
+			openUntil := time.Unix(0, until)
+			state.OpenUntil = &openUntil
+			if now.Before(openUntil) {
+				state.State = "open"
+			} else {
+				state.State = "half-open"
+			}
+		}
+		result = append(result, state)
+	}
+	b.RUnlock()
+	slices.SortFunc(result, func(a, b TBreakerState) int {
+		return strings.Compare(a.Server, b.Server)
+	})
+
+	return result
+} // states()
+
+// `success()` closes the breaker of a server that answered.
+//
+// Parameters:
+//   - `aServer`: The upstream server that answered.
+func (b *tBreakers) success(aServer string) {
+	if nil == b {
+		return
+	}
+	breaker := b.breaker(aServer)
+	breaker.failures.Store(0)
+	breaker.probing.Store(false)
+	if 0 != breaker.openUntil.Swap(0) {
+		gLog.Info("upstream server available again", "server", aServer)
+	}
+} // success()
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `isServerFailure()` checks whether a lookup error is the server's
+// fault (rather than a non-existing hostname).
+//
+// Parameters:
+//   - `aErr`: The lookup's error.
+//
+// Returns:
+//   - `bool`: `true` if the server failed, `false` otherwise.
+func isServerFailure(aErr error) bool {
+	if nil == aErr {
+		return false
+	}
+	var dnsErr *net.DNSError
+	if errors.As(aErr, &dnsErr) && dnsErr.IsNotFound {
+		return false
+	}
+
+	return true
+} // isServerFailure()
+
+// ---------------------------------------------------------------------------
+// `TResolver` methods:
+
+// `Breakers()` returns the states of the upstream servers' circuit
+// breakers.
+//
+// Returns:
+//   - `[]TBreakerState`: The breakers' states sorted by server (`nil`
+//     if the breakers are disabled).
+func (r *TResolver) Breakers() []TBreakerState {
+	return r.breakers.states()
+} // Breakers()
+
+/* _EoF_ */
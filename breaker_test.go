@@ -0,0 +1,277 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/mwat56/dnscache/clock"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_newBreakers(t *testing.T) {
+	clk := clock.NewManual(time.Now())
+
+	tests := []struct {
+		name          string
+		options       *TBreakerOptions
+		wantNil       bool
+		wantThreshold uint32
+		wantCooldown  time.Duration
+	}{
+		/* */
+		{"01 - disabled", nil, true, 0, 0},
+		{"02 - defaults", &TBreakerOptions{}, false, defBreakerThreshold, defBreakerCooldown},
+		{"03 - negative values", &TBreakerOptions{Threshold: -1, Cooldown: -time.Second},
+			false, defBreakerThreshold, defBreakerCooldown},
+		{"04 - custom", &TBreakerOptions{Threshold: 2, Cooldown: time.Minute}, false, 2, time.Minute},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := newBreakers(tc.options, clk)
+			if (nil == got) != tc.wantNil {
+				t.Fatalf("newBreakers() = '%v', wantNil '%v'", got, tc.wantNil)
+			}
+			if nil == got {
+				return
+			}
+			if got.threshold != tc.wantThreshold {
+				t.Errorf("newBreakers().threshold = '%d', want '%d'", got.threshold, tc.wantThreshold)
+			}
+			if got.cooldown != tc.wantCooldown {
+				t.Errorf("newBreakers().cooldown = '%v', want '%v'", got.cooldown, tc.wantCooldown)
+			}
+		})
+	}
+} // Test_newBreakers()
+
+func Test_TBreakerPolicy_String(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy TBreakerPolicy
+		want   string
+	}{
+		/* */
+		{"01 - fail", BreakerPolicyFail, "fail"},
+		{"02 - nxdomain", BreakerPolicyNXDomain, "nxdomain"},
+		{"03 - stale", BreakerPolicyStale, "stale"},
+		{"04 - unknown", TBreakerPolicy(99), "fail"},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.policy.String(); got != tc.want {
+				t.Errorf("TBreakerPolicy.String() = '%s', want '%s'", got, tc.want)
+			}
+		})
+	}
+} // Test_TBreakerPolicy_String()
+
+func Test_tBreakers_allow(t *testing.T) {
+	const server = "192.0.2.53:53"
+	clk := clock.NewManual(time.Now())
+	b := newBreakers(&TBreakerOptions{Threshold: 2, Cooldown: time.Minute}, clk)
+
+	state := func() string {
+		for _, s := range b.states() {
+			if server == s.Server {
+				return s.State
+			}
+		}
+		return ""
+	}
+
+	// A single failure keeps the breaker closed
+	b.failure(server)
+	if !b.allow(server) {
+		t.Fatal("allow() after 1 failure = 'false', want 'true'")
+	}
+
+	// Reaching the threshold opens it
+	b.failure(server)
+	if b.allow(server) {
+		t.Error("allow() after 2 failures = 'true', want 'false'")
+	}
+	if got := state(); "open" != got {
+		t.Errorf("states() = '%s', want 'open'", got)
+	}
+
+	// After the cool-down a single trial lookup passes
+	clk.Advance(time.Minute)
+	if got := state(); "half-open" != got {
+		t.Errorf("states() = '%s', want 'half-open'", got)
+	}
+	if !b.allow(server) {
+		t.Fatal("allow() after cool-down = 'false', want 'true'")
+	}
+	if b.allow(server) {
+		t.Error("allow() during trial lookup = 'true', want 'false'")
+	}
+
+	// A failed trial lookup opens the breaker again
+	b.failure(server)
+	if b.allow(server) {
+		t.Error("allow() after failed trial = 'true', want 'false'")
+	}
+
+	// A released trial lookup lets the next one pass
+	clk.Advance(time.Minute)
+	if !b.allow(server) {
+		t.Fatal("allow() after 2nd cool-down = 'false', want 'true'")
+	}
+	b.release(server)
+	if !b.allow(server) {
+		t.Fatal("allow() after release = 'false', want 'true'")
+	}
+
+	// A successful trial lookup closes the breaker
+	b.success(server)
+	if !b.allow(server) || !b.allow(server) {
+		t.Error("allow() after success = 'false', want 'true'")
+	}
+	states := b.states()
+	if 1 != len(states) {
+		t.Fatalf("states() = '%v', want 1 entry", states)
+	}
+	if got := states[0]; ("closed" != got.State) || (0 != got.Failures) || (1 != got.Trips) || (nil != got.OpenUntil) {
+		t.Errorf("states() = '%+v', want closed with 0 failures and 1 trip", got)
+	}
+
+	// Disabled breakers allow everything
+	var disabled *tBreakers
+	disabled.failure(server)
+	if !disabled.allow(server) {
+		t.Error("allow() of disabled breakers = 'false', want 'true'")
+	}
+	if got := disabled.states(); nil != got {
+		t.Errorf("states() of disabled breakers = '%v', want 'nil'", got)
+	}
+} // Test_tBreakers_allow()
+
+func Test_tBreakers_fallback(t *testing.T) {
+	clk := clock.NewManual(time.Now())
+	staleIP := net.ParseIP("192.0.2.1")
+	stale := newBreakers(&TBreakerOptions{Policy: BreakerPolicyStale}, clk)
+	stale.remember("Stale.Example.com", []net.IP{staleIP})
+	stale.remember("old.example.com", []net.IP{staleIP})
+	clk.Advance(maxStaleAge)
+	stale.remember("empty.example.com", nil)
+	stale.remember("fresh.example.com", []net.IP{staleIP})
+
+	tests := []struct {
+		name         string
+		breakers     *tBreakers
+		hostname     string
+		want         net.IP
+		wantErr      error
+		wantNotFound bool
+	}{
+		/* */
+		{"01 - disabled", nil, "example.com", nil, ErrCircuitOpen, false},
+		{"02 - fail", newBreakers(&TBreakerOptions{}, clk), "example.com", nil, ErrCircuitOpen, false},
+		{"03 - nxdomain", newBreakers(&TBreakerOptions{Policy: BreakerPolicyNXDomain}, clk),
+			"example.com", nil, nil, true},
+		{"04 - stale entry", stale, "fresh.example.com", staleIP, nil, false},
+		{"05 - stale entry too old", stale, "old.example.com", nil, ErrCircuitOpen, false},
+		{"06 - no stale entry", stale, "empty.example.com", nil, ErrCircuitOpen, false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.breakers.fallback(tc.hostname, ErrCircuitOpen)
+			if (nil != tc.wantErr) && !errors.Is(err, tc.wantErr) {
+				t.Errorf("fallback() error = '%v', want '%v'", err, tc.wantErr)
+			}
+			var dnsErr *net.DNSError
+			if isNotFound := errors.As(err, &dnsErr) && dnsErr.IsNotFound; isNotFound != tc.wantNotFound {
+				t.Errorf("fallback() error = '%v', wantNotFound '%v'", err, tc.wantNotFound)
+			}
+			if (nil == tc.wantErr) && !tc.wantNotFound && (nil != err) {
+				t.Errorf("fallback() error = '%v', want 'nil'", err)
+			}
+			if (nil == tc.want) != (0 == len(got)) || ((nil != tc.want) && !got[0].Equal(tc.want)) {
+				t.Errorf("fallback() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_tBreakers_fallback()
+
+func Test_tBreakers_remember(t *testing.T) {
+	b := newBreakers(&TBreakerOptions{Policy: BreakerPolicyStale}, clock.NewManual(time.Now()))
+	ips := []net.IP{net.ParseIP("192.0.2.1")}
+
+	for i := range maxStaleEntries + 10 {
+		b.remember(fmt.Sprintf("host%d.test", i), ips)
+	}
+	if got := len(b.stale); maxStaleEntries != got {
+		t.Errorf("remember() kept '%d' entries, want '%d'", got, maxStaleEntries)
+	}
+
+	// The stored addresses are a copy of the given ones
+	b.remember("copy.test", ips)
+	ips[0] = net.ParseIP("192.0.2.99")
+	if got := b.stale["copy.test"].ips[0]; !got.Equal(net.ParseIP("192.0.2.1")) {
+		t.Errorf("remember() stored '%v', want '192.0.2.1'", got)
+	}
+} // Test_tBreakers_remember()
+
+func Test_TResolver_Breakers(t *testing.T) {
+	// A local port nobody listens on refuses the queries
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatal(err)
+	}
+	server := conn.LocalAddr().String()
+	conn.Close()
+
+	r := NewWithOptions(TResolverOptions{
+		DataDir:    t.TempDir(),
+		DNSservers: []string{server},
+		Clock:      clock.NewManual(time.Now()),
+		Breaker:    &TBreakerOptions{Threshold: 1, Cooldown: time.Minute},
+		Resolver: &net.Resolver{
+			PreferGo: true,
+			Dial: func(context.Context, string, string) (net.Conn, error) {
+				return nil, errors.New("upstream down")
+			},
+		},
+	})
+	defer r.StopExpire()
+
+	if _, err = r.Fetch("first.test"); nil == err {
+		t.Fatal("Fetch() error = 'nil', want lookup error")
+	}
+	states := r.Breakers()
+	if 2 != len(states) {
+		t.Fatalf("Breakers() = '%+v', want 2 breakers", states)
+	}
+	for _, state := range states {
+		if "open" != state.State {
+			t.Errorf("Breakers() = '%+v', want state 'open'", state)
+		}
+	}
+
+	if _, err = r.Fetch("second.test"); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Fetch() error = '%v', want '%v'", err, ErrCircuitOpen)
+	}
+
+	if got := New(0).Breakers(); nil != got {
+		t.Errorf("Breakers() without breakers = '%v', want 'nil'", got)
+	}
+} // Test_TResolver_Breakers()
+
+/* _EoF_ */
@@ -0,0 +1,61 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/mwat56/dnscache/clock"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tClockKey` is the context key of a cache list's clock.
+	tClockKey struct{}
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `now()` returns the current time of the clock passed down by the
+// cache list (or the system's time if there's none).
+//
+// The cache nodes don't know their list, hence the list's clock is
+// handed to them along with the operation's context.
+//
+// Parameters:
+//   - `aCtx`: The operation's context.
+//
+// Returns:
+//   - `time.Time`: The current time.
+func now(aCtx context.Context) time.Time {
+	if c, ok := aCtx.Value(tClockKey{}).(clock.IClock); ok {
+		return c.Now()
+	}
+
+	return time.Now()
+} // now()
+
+// `withClock()` returns a context handing the clock to the cache nodes.
+//
+// Parameters:
+//   - `aCtx`: The operation's context.
+//   - `aClock`: The cache list's clock (`nil` means the system's clock).
+//
+// Returns:
+//   - `context.Context`: The context to pass to the cache nodes.
+func withClock(aCtx context.Context, aClock clock.IClock) context.Context {
+	if nil == aClock {
+		return aCtx
+	}
+
+	return context.WithValue(aCtx, tClockKey{}, aClock)
+} // withClock()
+
+/* _EoF_ */
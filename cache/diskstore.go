@@ -0,0 +1,396 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package cache
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `TDiskStore` is an [IColdStore] keeping the entries in the files
+	// of a directory.
+	//
+	// The hostnames are spread by their hash over a fixed number of
+	// bucket files, each holding one line per entry. A bucket is
+	// rewritten (atomically) with each change of one of its entries.
+	TDiskStore struct {
+		sync.Mutex
+		dir    string
+		counts [diskBuckets]int32 // number of entries per bucket
+		total  int                // number of all entries
+	}
+)
+
+const (
+	// `diskBuckets` is the number of bucket files of a [TDiskStore].
+	diskBuckets = 1 << 12
+
+	// `diskSuffix` is the file name extension of the bucket files.
+	diskSuffix = ".cache"
+)
+
+var (
+	// `errStoreClosed` is returned by a closed [TDiskStore].
+	errStoreClosed = errors.New("cache store closed")
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `diskBucket()` returns the bucket of the given hostname.
+//
+// Parameters:
+//   - `aHostname`: The hostname to find the bucket for.
+//
+// Returns:
+//   - `int`: The bucket's index.
+func diskBucket(aHostname string) int {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(aHostname))
+
+	return int(hash.Sum32() % diskBuckets)
+} // diskBucket()
+
+// `parseDiskLine()` parses a line of a bucket file.
+//
+// Parameters:
+//   - `aLine`: The line to parse.
+//
+// Returns:
+//   - `string`: The entry's hostname.
+//   - `TColdEntry`: The parsed entry.
+//   - `bool`: `true` if the line is a valid entry, `false` otherwise.
+func parseDiskLine(aLine string) (string, TColdEntry, bool) {
+	var entry TColdEntry
+
+	fields := strings.Fields(aLine)
+	if 3 != len(fields) {
+		return "", entry, false
+	}
+	expires, err := strconv.ParseInt(fields[1], 10, 64)
+	if nil != err {
+		return "", entry, false
+	}
+	entry.Expires = time.Unix(0, expires)
+
+	for _, addr := range strings.Split(fields[2], ",") {
+		if ip := net.ParseIP(addr); nil != ip {
+			entry.IPs = append(entry.IPs, ip)
+		}
+	}
+
+	return fields[0], entry, (0 < len(entry.IPs))
+} // parseDiskLine()
+
+// ---------------------------------------------------------------------------
+// `TDiskStore` constructor:
+
+// `NewDiskStore()` returns a store keeping the entries in the given
+// directory.
+//
+// The directory is created if necessary; entries stored there by
+// a former instance are available again.
+//
+// Parameters:
+//   - `aDir`: The directory to keep the entries in.
+//
+// Returns:
+//   - `*TDiskStore`: The new store.
+//   - `error`: `nil` if the directory is usable, the error otherwise.
+func NewDiskStore(aDir string) (*TDiskStore, error) {
+	if aDir = strings.TrimSpace(aDir); "" == aDir {
+		return nil, errors.New("no cache store directory given")
+	}
+	if err := os.MkdirAll(aDir, 0750); nil != err {
+		return nil, fmt.Errorf("failed to create cache store: %w", err)
+	}
+
+	result := &TDiskStore{dir: aDir}
+	for bucket := range diskBuckets {
+		entries, err := result.read(bucket)
+		if nil != err {
+			return nil, fmt.Errorf("failed to read cache store: %w", err)
+		}
+		result.counts[bucket] = int32(len(entries)) //#nosec G115
+		result.total += len(entries)
+	}
+
+	return result, nil
+} // NewDiskStore()
+
+// ---------------------------------------------------------------------------
+
+// `init()` ensures proper interface implementation.
+func init() {
+	var (
+		_ IColdStore = (*TDiskStore)(nil)
+	)
+} // init()
+
+// ---------------------------------------------------------------------------
+// `TDiskStore` methods:
+
+// `Close()` releases the store's resources.
+//
+// The stored entries remain in the directory.
+//
+// Returns:
+//   - `error`: Always `nil`.
+func (ds *TDiskStore) Close() error {
+	ds.Lock()
+	ds.dir = ""
+	ds.Unlock()
+
+	return nil
+} // Close()
+
+// `Delete()` removes the entry of the given hostname.
+//
+// Parameters:
+//   - `aHostname`: The hostname to remove the entry for.
+//
+// Returns:
+//   - `bool`: `true` if the entry was found and deleted, `false` otherwise.
+func (ds *TDiskStore) Delete(aHostname string) bool {
+	bucket := diskBucket(aHostname)
+
+	ds.Lock()
+	defer ds.Unlock()
+
+	if ("" == ds.dir) || (0 == ds.counts[bucket]) {
+		return false
+	}
+	entries, err := ds.read(bucket)
+	if nil != err {
+		return false
+	}
+	if _, ok := entries[aHostname]; !ok {
+		return false
+	}
+	delete(entries, aHostname)
+	if nil != ds.write(bucket, entries) {
+		return false
+	}
+	ds.counts[bucket]--
+	ds.total--
+
+	return true
+} // Delete()
+
+// `ForEach()` calls the given function for all stored entries
+// until it returns `false`.
+//
+// The store is locked meanwhile, so the function must not use it.
+//
+// Parameters:
+//   - `aFunc`: The function to call.
+//
+// Returns:
+//   - `error`: `nil` if the entries were read, the error otherwise.
+func (ds *TDiskStore) ForEach(aFunc func(string, TColdEntry) bool) error {
+	ds.Lock()
+	defer ds.Unlock()
+
+	if "" == ds.dir {
+		return errStoreClosed
+	}
+	for bucket := range diskBuckets {
+		if 0 == ds.counts[bucket] {
+			continue
+		}
+		entries, err := ds.read(bucket)
+		if nil != err {
+			return err
+		}
+		for hostname, entry := range entries {
+			if !aFunc(hostname, entry) {
+				return nil
+			}
+		}
+	}
+
+	return nil
+} // ForEach()
+
+// `Get()` returns the entry of the given hostname.
+//
+// Parameters:
+//   - `aHostname`: The hostname to lookup.
+//
+// Returns:
+//   - `TColdEntry`: The hostname's entry.
+//   - `bool`: `true` if the hostname was found, `false` otherwise.
+func (ds *TDiskStore) Get(aHostname string) (TColdEntry, bool) {
+	bucket := diskBucket(aHostname)
+
+	ds.Lock()
+	defer ds.Unlock()
+
+	if ("" == ds.dir) || (0 == ds.counts[bucket]) {
+		return TColdEntry{}, false
+	}
+	entries, err := ds.read(bucket)
+	if nil != err {
+		return TColdEntry{}, false
+	}
+	entry, ok := entries[aHostname]
+
+	return entry, ok
+} // Get()
+
+// `Len()` returns the number of stored entries.
+//
+// Returns:
+//   - `int`: Number of stored entries.
+func (ds *TDiskStore) Len() int {
+	ds.Lock()
+	defer ds.Unlock()
+
+	return ds.total
+} // Len()
+
+// `path()` returns the file name of the given bucket.
+//
+// Parameters:
+//   - `aBucket`: The bucket's index.
+//
+// Returns:
+//   - `string`: The bucket's file name.
+func (ds *TDiskStore) path(aBucket int) string {
+	return filepath.Join(ds.dir, fmt.Sprintf("%03x%s", aBucket, diskSuffix))
+} // path()
+
+// `Put()` stores the entry of the given hostname, replacing a former one.
+//
+// Parameters:
+//   - `aHostname`: The hostname to store the entry for.
+//   - `aEntry`: The entry to store.
+//
+// Returns:
+//   - `error`: `nil` if the entry was stored, the error otherwise.
+func (ds *TDiskStore) Put(aHostname string, aEntry TColdEntry) error {
+	if ("" == aHostname) || strings.ContainsAny(aHostname, " \t\r\n") {
+		return fmt.Errorf("invalid hostname %q", aHostname)
+	}
+	if 0 == len(aEntry.IPs) {
+		return fmt.Errorf("no IP addresses for %q", aHostname)
+	}
+	bucket := diskBucket(aHostname)
+
+	ds.Lock()
+	defer ds.Unlock()
+
+	if "" == ds.dir {
+		return errStoreClosed
+	}
+	entries, err := ds.read(bucket)
+	if nil != err {
+		return err
+	}
+	_, existed := entries[aHostname]
+	entries[aHostname] = TColdEntry{
+		IPs:     slices.Clone(aEntry.IPs),
+		Expires: aEntry.Expires,
+	}
+	if err = ds.write(bucket, entries); nil != err {
+		return err
+	}
+	if !existed {
+		ds.counts[bucket]++
+		ds.total++
+	}
+
+	return nil
+} // Put()
+
+// `read()` reads the entries of the given bucket.
+//
+// Invalid lines are skipped.
+//
+// Parameters:
+//   - `aBucket`: The bucket's index.
+//
+// Returns:
+//   - `map[string]TColdEntry`: The bucket's entries.
+//   - `error`: `nil` if the bucket was read, the error otherwise.
+func (ds *TDiskStore) read(aBucket int) (map[string]TColdEntry, error) {
+	result := make(map[string]TColdEntry)
+
+	file, err := os.Open(ds.path(aBucket))
+	if nil != err {
+		if errors.Is(err, os.ErrNotExist) {
+			return result, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if hostname, entry, ok := parseDiskLine(scanner.Text()); ok {
+			result[hostname] = entry
+		}
+	}
+
+	return result, scanner.Err()
+} // read()
+
+// `write()` replaces the given bucket's file.
+//
+// Parameters:
+//   - `aBucket`: The bucket's index.
+//   - `aEntries`: The bucket's entries.
+//
+// Returns:
+//   - `error`: `nil` if the bucket was written, the error otherwise.
+func (ds *TDiskStore) write(aBucket int, aEntries map[string]TColdEntry) error {
+	fName := ds.path(aBucket)
+	if 0 == len(aEntries) {
+		if err := os.Remove(fName); (nil != err) && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	var builder strings.Builder
+	for hostname, entry := range aEntries {
+		builder.WriteString(hostname)
+		builder.WriteByte(' ')
+		builder.WriteString(strconv.FormatInt(entry.Expires.UnixNano(), 10))
+		for idx, ip := range entry.IPs {
+			if 0 == idx {
+				builder.WriteByte(' ')
+			} else {
+				builder.WriteByte(',')
+			}
+			builder.WriteString(ip.String())
+		}
+		builder.WriteByte('\n')
+	}
+
+	tmpName := fName + ".tmp"
+	if err := os.WriteFile(tmpName, []byte(builder.String()), 0600); nil != err {
+		return err
+	}
+
+	return os.Rename(tmpName, fName)
+} // write()
+
+/* _EoF_ */
@@ -0,0 +1,129 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package cache
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_parseDiskLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		wantHost string
+		wantIPs  int
+		wantOK   bool
+	}{
+		/* */
+		{"01 - empty line", "", "", 0, false},
+		{"02 - one IP", "tld.localdomain 1 127.0.0.1", "tld.localdomain", 1, true},
+		{"03 - two IPs", "tld.localdomain 1 127.0.0.1,::1", "tld.localdomain", 2, true},
+		{"04 - invalid time", "tld.localdomain x 127.0.0.1", "", 0, false},
+		{"05 - invalid IPs", "tld.localdomain 1 localhost", "tld.localdomain", 0, false},
+		{"06 - missing field", "tld.localdomain 1", "", 0, false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			host, entry, ok := parseDiskLine(tc.line)
+			if ok != tc.wantOK {
+				t.Errorf("parseDiskLine() ok = '%v', want '%v'", ok, tc.wantOK)
+			}
+			if host != tc.wantHost {
+				t.Errorf("parseDiskLine() host = '%s', want '%s'", host, tc.wantHost)
+			}
+			if len(entry.IPs) != tc.wantIPs {
+				t.Errorf("parseDiskLine() IPs = '%v', want '%d'", entry.IPs, tc.wantIPs)
+			}
+		})
+	}
+} // Test_parseDiskLine()
+
+func Test_TDiskStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "cold")
+	if _, err := NewDiskStore(" "); nil == err {
+		t.Error("NewDiskStore() error = 'nil', want an error")
+	}
+	ds, err := NewDiskStore(dir)
+	if nil != err {
+		t.Fatalf("NewDiskStore() error = '%v'", err)
+	}
+
+	expires := time.Now().Add(time.Hour).Round(0)
+	entry := TColdEntry{
+		IPs:     []net.IP{net.ParseIP("127.0.0.1"), net.ParseIP("::1")},
+		Expires: expires,
+	}
+	if err := ds.Put("invalid host", entry); nil == err {
+		t.Error("Put() error = 'nil', want an error")
+	}
+	if err := ds.Put("tld.localdomain", TColdEntry{}); nil == err {
+		t.Error("Put() error = 'nil', want an error")
+	}
+	for _, host := range []string{"tld.localdomain", "sub.tld.localdomain", "tld.localdomain"} {
+		if err := ds.Put(host, entry); nil != err {
+			t.Fatalf("Put() error = '%v'", err)
+		}
+	}
+	if got := ds.Len(); 2 != got {
+		t.Errorf("Len() = '%d', want '2'", got)
+	}
+
+	got, ok := ds.Get("tld.localdomain")
+	if !ok || (2 != len(got.IPs)) || !got.IPs[1].Equal(entry.IPs[1]) ||
+		!got.Expires.Equal(expires) {
+		t.Errorf("Get() = '%v', '%v', want '%v'", got, ok, entry)
+	}
+	if _, ok := ds.Get("other.localdomain"); ok {
+		t.Error("Get() = 'true', want 'false'")
+	}
+
+	// The entries survive a restart
+	_ = ds.Close()
+	if _, ok := ds.Get("tld.localdomain"); ok {
+		t.Error("Get() after Close() = 'true', want 'false'")
+	}
+	if ds, err = NewDiskStore(dir); nil != err {
+		t.Fatalf("NewDiskStore() error = '%v'", err)
+	}
+	if got := ds.Len(); 2 != got {
+		t.Errorf("Len() after reopening = '%d', want '2'", got)
+	}
+	var hosts []string
+	_ = ds.ForEach(func(aHostname string, _ TColdEntry) bool {
+		hosts = append(hosts, aHostname)
+		return true
+	})
+	if 2 != len(hosts) {
+		t.Errorf("ForEach() = '%v', want two hostnames", hosts)
+	}
+
+	if !ds.Delete("tld.localdomain") {
+		t.Error("Delete() = 'false', want 'true'")
+	}
+	if ds.Delete("tld.localdomain") {
+		t.Error("Delete() = 'true', want 'false' (deleted already)")
+	}
+	if !ds.Delete("sub.tld.localdomain") {
+		t.Error("Delete() = 'false', want 'true'")
+	}
+	if got := ds.Len(); 0 != got {
+		t.Errorf("Len() = '%d', want '0'", got)
+	}
+	if files, _ := os.ReadDir(dir); 0 != len(files) {
+		t.Errorf("Delete() left '%d' files", len(files))
+	}
+} // Test_TDiskStore()
+
+/* _EoF_ */
@@ -8,13 +8,29 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"net"
 	"time"
+
+	"github.com/mwat56/dnscache/clock"
 )
 
 //lint:file-ignore ST1017 - I prefer Yoda conditions
 
+var (
+	// `ErrCacheMiss` is returned if a hostname is not in the cache.
+	ErrCacheMiss = errors.New("hostname not in cache")
+)
+
 type (
+	// `TExpireFunc` is called for each cache entry removed because
+	// its time to live has passed.
+	//
+	// Parameters:
+	//   - `aHostname`: The expired entry's hostname.
+	//   - `aIPs`: The expired entry's IP addresses.
+	TExpireFunc func(aHostname string, aIPs []net.IP)
+
 	// `ICacheList` is the basic interface for a cache list.
 	// It provides a CRUD interface for caching hostname's IP addresses:
 	//
@@ -72,6 +88,17 @@ type (
 		//   - `bool`: `true` if the hostname was found in the cache, `false` otherwise.
 		Exists(context.Context, string) bool
 
+		// `Expiry()` returns the time the given hostname's entry expires.
+		//
+		// Parameters:
+		//   - `context.Context`: Timeout context to use for the operation.
+		//   - `string`: The hostname to lookup in the cache.
+		//
+		// Returns:
+		//   - `time.Time`: The time after which the entry is invalid.
+		//   - `bool`: `true` if the hostname was found in the cache, `false` otherwise.
+		Expiry(context.Context, string) (time.Time, bool)
+
 		// `IPs()` returns the IP addresses for the given hostname.
 		//
 		// Parameters:
@@ -102,6 +129,21 @@ type (
 		//   - `chan string`: Channel that yields all FQDNs in sorted order.
 		Range(context.Context) <-chan string
 
+		// `SetClock()` sets the clock deciding about the entries' expiry.
+		//
+		// Parameters:
+		//   - `clock.IClock`: The clock to use (`nil` means the system's clock).
+		SetClock(clock.IClock)
+
+		// `SetExpireFunc()` sets the function to call for expired entries.
+		//
+		// The function is called after the expired entries have been
+		// removed, i.e. without holding any of the list's locks.
+		//
+		// Parameters:
+		//   - `TExpireFunc`: The function to call (`nil` to remove it).
+		SetExpireFunc(TExpireFunc)
+
 		// `Update()` updates the cache entry with the given IP addresses.
 		//
 		// Parameters:
@@ -158,14 +158,17 @@ func (ce *tMapEntry) First() net.IP {
 
 // `isExpired()` returns `true` if the cache entry is expired.
 //
+// Parameters:
+//   - `aNow`: The current time.
+//
 // Returns:
 //   - `bool`: `true` if the cache entry is expired, `false` otherwise.
-func (ce *tMapEntry) isExpired() bool {
+func (ce *tMapEntry) isExpired(aNow time.Time) bool {
 	if (nil == ce) || (0 == len(ce.ips)) {
 		return true
 	}
 
-	return ce.bestBefore.Before(time.Now())
+	return ce.bestBefore.Before(aNow)
 } // isExpired()
 
 // `Len()` returns the number of IP addresses in the cache entry.
@@ -246,7 +249,7 @@ func (ce *tMapEntry) Update(aCtx context.Context, aIPs tIpList, aTTL time.Durati
 		copy(ce.ips, aIPs)
 
 		// Update expiration time
-		ce.bestBefore = time.Now().Add(aTTL)
+		ce.bestBefore = now(aCtx).Add(aTTL)
 	} else {
 		ce.ips = tIpList{}
 		ce.bestBefore = time.Time{}
@@ -355,7 +355,7 @@ func Test_tMapEntry_isExpired(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			if got := tc.ce.isExpired(); got != tc.wantExpired {
+			if got := tc.ce.isExpired(time.Now()); got != tc.wantExpired {
 				t.Errorf("tMapEntry.isExpired() = '%v', want '%v'",
 					got, tc.wantExpired)
 			}
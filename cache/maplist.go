@@ -12,9 +12,12 @@ import (
 	"maps"
 	"net"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/mwat56/dnscache/clock"
 )
 
 //lint:file-ignore ST1017 - I prefer Yoda conditions
@@ -25,7 +28,9 @@ type (
 	// indexed by lowercased hostnames.
 	tMapList struct {
 		sync.RWMutex
-		Cache map[string]*tMapEntry
+		Cache    map[string]*tMapEntry
+		clock    clock.IClock // deciding about the entries' expiry
+		onExpire TExpireFunc  // called for expired entries
 	}
 )
 
@@ -69,12 +74,15 @@ func init() {
 //   - `aRate`: Time interval to refresh the cache.
 //   - `aAbort`: Channel to receive a signal to abort.
 func (cl *tMapList) AutoExpire(aRate time.Duration, aAbort chan struct{}) {
-	ticker := time.NewTicker(aRate)
-	defer ticker.Stop()
+	cl.RLock()
+	timer := clock.OrSystem(cl.clock).NewTimer(aRate)
+	cl.RUnlock()
+	defer timer.Stop()
 
 	for {
 		select {
-		case <-ticker.C:
+		case <-timer.C():
+			timer.Reset(aRate)
 			cl.expireEntries()
 
 		case <-aAbort:
@@ -99,6 +107,7 @@ func (cl *tMapList) Clone() ICacheList {
 		Cache: make(map[string]*tMapEntry, len(cl.Cache)),
 	}
 	cl.RLock()
+	clone.clock = cl.clock
 	for host, ce := range cl.Cache {
 		clone.Cache[host] = ce.clone()
 	}
@@ -228,18 +237,56 @@ func (cl *tMapList) expireEntries() {
 		return
 	}
 
+	cl.RLock()
 	clone := maps.Clone(cl.Cache)
+	deadline := clock.OrSystem(cl.clock).Now()
+	onExpire := cl.onExpire
+	cl.RUnlock()
 	for hostname, ce := range clone {
-		if ce.isExpired() {
-			putEntry(ce)
+		if ce.isExpired(deadline) {
+			var ips []net.IP
+			if nil != onExpire {
+				ips = slices.Clone(ce.ips)
+			}
 			cl.Lock()
 			delete(cl.Cache, hostname)
 			cl.Unlock()
+			putEntry(ce)
+			if nil != onExpire {
+				onExpire(hostname, ips)
+			}
 		}
 	}
 	clone = nil
 } // expireEntries()
 
+// `Expiry()` returns the time the given hostname's entry expires.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//   - `aHostname`: The hostname to lookup in the cache.
+//
+// Returns:
+//   - `rExpiry`: The time after which the entry is invalid.
+//   - `rOK`: `true` if the hostname was found in the cache, `false` otherwise.
+func (cl *tMapList) Expiry(aCtx context.Context, aHostname string) (rExpiry time.Time, rOK bool) {
+	if nil == cl {
+		return
+	}
+	if aHostname = strings.TrimSpace(aHostname); 0 == len(aHostname) {
+		return
+	}
+	aHostname = strings.ToLower(aHostname)
+
+	cl.RLock()
+	if ce, ok := cl.Cache[aHostname]; ok && (0 < len(ce.ips)) {
+		rExpiry, rOK = ce.bestBefore, true
+	}
+	cl.RUnlock()
+
+	return
+} // Expiry()
+
 // `IPs()` returns the IP addresses for the given hostname.
 //
 // Parameters:
@@ -330,6 +377,36 @@ func (cl *tMapList) Range(aCtx context.Context) <-chan string {
 	return ch
 } // Range()
 
+// `SetClock()` sets the clock deciding about the entries' expiry.
+//
+// A running `AutoExpire()` keeps using its former clock.
+//
+// Parameters:
+//   - `aClock`: The clock to use (`nil` means the system's clock).
+func (cl *tMapList) SetClock(aClock clock.IClock) {
+	if nil == cl {
+		return
+	}
+
+	cl.Lock()
+	cl.clock = aClock
+	cl.Unlock()
+} // SetClock()
+
+// `SetExpireFunc()` sets the function to call for expired entries.
+//
+// Parameters:
+//   - `aFunc`: The function to call (`nil` to remove it).
+func (cl *tMapList) SetExpireFunc(aFunc TExpireFunc) {
+	if nil == cl {
+		return
+	}
+
+	cl.Lock()
+	cl.onExpire = aFunc
+	cl.Unlock()
+} // SetExpireFunc()
+
 // `String()` implements the `fmt.Stringer` interface for a string
 // representation of the cache list.
 //
@@ -380,7 +457,7 @@ func (cl *tMapList) Update(aCtx context.Context, aHostname string, aIPs []net.IP
 
 	ce := newMapEntry()
 	cl.Lock()
-	cl.Cache[aHostname] = ce.Update(aCtx, aIPs, aTTL).(*tMapEntry)
+	cl.Cache[aHostname] = ce.Update(withClock(aCtx, cl.clock), aIPs, aTTL).(*tMapEntry)
 	cl.Unlock()
 
 	return cl
@@ -616,6 +616,42 @@ func Test_tCacheList_Exists(t *testing.T) {
 	}
 } // Test_tCacheList_Exists()
 
+func Test_tCacheList_Expiry(t *testing.T) {
+	start := time.Now()
+	cl := newMap(4)
+	cl.Create(context.TODO(), "Example.com", []net.IP{net.ParseIP("192.168.1.1")}, time.Hour)
+	end := time.Now()
+
+	tests := []struct {
+		name   string
+		cl     *tMapList
+		host   string
+		wantOK bool
+	}{
+		/* */
+		{"01 - found", cl, "example.com", true},
+		{"02 - not found", cl, "example.org", false},
+		{"03 - nil cl", nil, "example.com", false},
+		{"04 - empty hostname", cl, " ", false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, gotOK := tc.cl.Expiry(context.TODO(), tc.host)
+			if gotOK != tc.wantOK {
+				t.Errorf("tMapList.Expiry() gotOK = '%v', want '%v'",
+					gotOK, tc.wantOK)
+				return
+			}
+			if gotOK && (got.Before(start.Add(time.Hour)) || got.After(end.Add(time.Hour))) {
+				t.Errorf("tMapList.Expiry() = '%v', want about '%v'",
+					got, start.Add(time.Hour))
+			}
+		})
+	}
+} // Test_tCacheList_Expiry()
+
 func Test_tCacheList_IPs(t *testing.T) {
 	h1 := "example.com"
 	h2 := "example.org"
@@ -7,7 +7,13 @@ Copyright © 2025  M.Watermann, 10247 Berlin, Germany
 package cache
 
 import (
+	"context"
+	"net"
+	"slices"
 	"testing"
+	"time"
+
+	"github.com/mwat56/dnscache/clock"
 )
 
 //lint:file-ignore ST1017 - I prefer Yoda conditions
@@ -106,4 +112,106 @@ func Test_New(t *testing.T) {
 	}
 } // Test_New()
 
+func Test_ICacheList_SetExpireFunc(t *testing.T) {
+	tests := []struct {
+		name  string
+		cType TCacheType
+	}{
+		/* */
+		{"01 - map", CacheTypeMap},
+		{"02 - trie", CacheTypeTrie},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.TODO()
+			ip := net.ParseIP("192.168.1.1")
+			cl := New(tc.cType, 0)
+			cl.Create(ctx, "old.example.com", []net.IP{ip}, -time.Hour)
+			cl.Create(ctx, "example.com", []net.IP{ip}, time.Hour)
+
+			var got []string
+			cl.SetExpireFunc(func(aHostname string, aIPs []net.IP) {
+				if !slices.EqualFunc(aIPs, []net.IP{ip}, net.IP.Equal) {
+					t.Errorf("expired IPs = '%v', want '%v'", aIPs, ip)
+				}
+				got = append(got, aHostname)
+			})
+			switch list := cl.(type) {
+			case *tMapList:
+				list.expireEntries()
+			case *tTrieList:
+				list.expireEntries()
+			}
+			if !slices.Equal(got, []string{"old.example.com"}) {
+				t.Errorf("expired = '%v', want '[old.example.com]'", got)
+			}
+
+			// Without function nothing must be reported
+			got = nil
+			cl.SetExpireFunc(nil)
+			cl.Create(ctx, "gone.example.com", []net.IP{ip}, -time.Hour)
+			switch list := cl.(type) {
+			case *tMapList:
+				list.expireEntries()
+			case *tTrieList:
+				list.expireEntries()
+			}
+			if (0 != len(got)) || cl.Exists(ctx, "gone.example.com") {
+				t.Errorf("expired = '%v', want none reported but removed", got)
+			}
+		})
+	}
+} // Test_ICacheList_SetExpireFunc()
+
+func Test_ICacheList_SetClock(t *testing.T) {
+	tests := []struct {
+		name  string
+		cType TCacheType
+	}{
+		/* */
+		{"01 - map", CacheTypeMap},
+		{"02 - trie", CacheTypeTrie},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.TODO()
+			ip := net.ParseIP("192.168.1.1")
+			clk := clock.NewManual(time.Now().Add(-24 * time.Hour))
+			cl := New(tc.cType, 0)
+			cl.SetClock(clk)
+
+			// A TTL already passed on the system's clock
+			cl.Create(ctx, "example.com", []net.IP{ip}, time.Hour)
+			if _, ok := cl.IPs(ctx, "example.com"); !ok {
+				t.Fatal("IPs() = 'false', want 'true'")
+			}
+
+			abort := make(chan struct{})
+			defer close(abort)
+			go cl.AutoExpire(time.Minute, abort)
+			for 0 == clk.Waiters() {
+				time.Sleep(time.Millisecond)
+			}
+
+			clk.Advance(time.Minute)
+			if !cl.Exists(ctx, "example.com") {
+				t.Fatal("Exists() = 'false', want 'true'")
+			}
+
+			clk.Advance(time.Hour)
+			deadline := time.Now().Add(time.Second)
+			for cl.Exists(ctx, "example.com") && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+			}
+			if cl.Exists(ctx, "example.com") {
+				t.Error("Exists() = 'true', want 'false'")
+			}
+		})
+	}
+} // Test_ICacheList_SetClock()
+
 /* _EoF_ */
@@ -0,0 +1,86 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package cache
+
+import (
+	"net"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `TColdEntry` is a cache entry kept in an [IColdStore].
+	//
+	//   - `IPs`: The hostname's IP addresses.
+	//   - `Expires`: The time after which the entry is invalid.
+	TColdEntry struct {
+		IPs     []net.IP
+		Expires time.Time
+	}
+
+	// `IColdStore` is the persistent storage of a tiered cache's
+	// colder entries (see [NewTiered]).
+	//
+	// The hostnames handed to the store are trimmed and lowercased.
+	// Implementations have to be safe for concurrent use.
+	IColdStore interface {
+		// `Close()` releases the store's resources.
+		//
+		// Returns:
+		//   - `error`: `nil` if the store was closed, the error otherwise.
+		Close() error
+
+		// `Delete()` removes the entry of the given hostname.
+		//
+		// Parameters:
+		//   - `string`: The hostname to remove the entry for.
+		//
+		// Returns:
+		//   - `bool`: `true` if the entry was found and deleted, `false` otherwise.
+		Delete(string) bool
+
+		// `ForEach()` calls the given function for all stored entries
+		// until it returns `false`.
+		//
+		// Parameters:
+		//   - `func(string, TColdEntry) bool`: The function to call.
+		//
+		// Returns:
+		//   - `error`: `nil` if the entries were read, the error otherwise.
+		ForEach(func(string, TColdEntry) bool) error
+
+		// `Get()` returns the entry of the given hostname.
+		//
+		// Parameters:
+		//   - `string`: The hostname to lookup.
+		//
+		// Returns:
+		//   - `TColdEntry`: The hostname's entry.
+		//   - `bool`: `true` if the hostname was found, `false` otherwise.
+		Get(string) (TColdEntry, bool)
+
+		// `Len()` returns the number of stored entries.
+		//
+		// Returns:
+		//   - `int`: Number of stored entries.
+		Len() int
+
+		// `Put()` stores the entry of the given hostname, replacing
+		// a former one.
+		//
+		// Parameters:
+		//   - `string`: The hostname to store the entry for.
+		//   - `TColdEntry`: The entry to store.
+		//
+		// Returns:
+		//   - `error`: `nil` if the entry was stored, the error otherwise.
+		Put(string, TColdEntry) error
+	}
+)
+
+/* _EoF_ */
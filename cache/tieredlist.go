@@ -0,0 +1,565 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package cache
+
+import (
+	"container/list"
+	"context"
+	"net"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mwat56/dnscache/clock"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tTieredList` is a two-tier cache list: the recently used
+	// entries are kept in an in-memory cache list, the others in
+	// a (persistent) [IColdStore].
+	tTieredList struct {
+		sync.Mutex                          // guards the fields below
+		hot        ICacheList               // recently used entries
+		cold       IColdStore               // demoted entries
+		clock      clock.IClock             // deciding about the entries' expiry
+		onExpire   TExpireFunc              // called for expired entries
+		lru        *list.List               // hot hostnames, most recent first
+		index      map[string]*list.Element // hot hostnames' LRU elements
+		maxHot     int                      // max. number of hot entries
+	}
+
+	// `tDemoted` is an entry moved from the hot list to be put
+	// into the cold store.
+	tDemoted struct {
+		hostname string
+		entry    TColdEntry
+	}
+)
+
+const (
+	// `DefaultMaxHot` is the default number of entries a tiered cache
+	// keeps in memory (see [NewTiered]).
+	DefaultMaxHot = 1 << 16 // 65536
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `tierKey()` returns the normalised hostname used by both tiers.
+//
+// Parameters:
+//   - `aHostname`: The hostname to normalise.
+//
+// Returns:
+//   - `string`: The trimmed and lowercased hostname.
+func tierKey(aHostname string) string {
+	return strings.ToLower(strings.TrimSpace(aHostname))
+} // tierKey()
+
+// ---------------------------------------------------------------------------
+// `tTieredList` constructor:
+
+// `NewTiered()` returns a two-tier cache list.
+//
+// The most recently used `aMaxHot` entries are kept in the `aHot`
+// list, the least recently used others are moved to the `aCold`
+// store. Cold entries are moved back to the hot list when accessed.
+//
+// The cold store isn't closed by the cache list.
+//
+// Parameters:
+//   - `aHot`: The in-memory cache list (usually from [New]).
+//   - `aCold`: The persistent store of the colder entries.
+//   - `aMaxHot`: Max. number of in-memory entries, `0` means use default (`65536`).
+//
+// Returns:
+//   - `ICacheList`: A new two-tier cache list.
+func NewTiered(aHot ICacheList, aCold IColdStore, aMaxHot int) ICacheList {
+	if 0 >= aMaxHot {
+		aMaxHot = DefaultMaxHot
+	}
+	result := &tTieredList{
+		hot:    aHot,
+		cold:   aCold,
+		lru:    list.New(),
+		index:  make(map[string]*list.Element),
+		maxHot: aMaxHot,
+	}
+	aHot.SetExpireFunc(result.hotExpired)
+
+	// Entries cached already are hot
+	for hostname := range aHot.Range(context.Background()) {
+		result.index[hostname] = result.lru.PushBack(hostname)
+	}
+	result.store(result.demote(context.Background()))
+
+	return result
+} // NewTiered()
+
+// ---------------------------------------------------------------------------
+
+// `init()` ensures proper interface implementation.
+func init() {
+	var (
+		_ ICacheList = (*tTieredList)(nil)
+	)
+} // init()
+
+// ---------------------------------------------------------------------------
+// `tTieredList` methods:
+
+// `AutoExpire()` removes expired cache entries at a given interval.
+//
+// Parameters:
+//   - `aRate`: Time interval to refresh the cache.
+//   - `aAbort`: Channel to receive a signal to abort.
+func (tl *tTieredList) AutoExpire(aRate time.Duration, aAbort chan struct{}) {
+	tl.Lock()
+	timer := clock.OrSystem(tl.clock).NewTimer(aRate)
+	tl.Unlock()
+	defer timer.Stop()
+
+	abortHot := make(chan struct{})
+	defer close(abortHot)
+	go tl.hot.AutoExpire(aRate, abortHot)
+
+	for {
+		select {
+		case <-timer.C():
+			timer.Reset(aRate)
+			tl.expireCold()
+
+		case <-aAbort:
+			return
+		}
+	}
+} // AutoExpire()
+
+// `Clone()` creates a copy of the cache list.
+//
+// The hot entries are copied while the cold store is shared.
+//
+// Returns:
+//   - `ICacheList`: A copy of the cache list.
+func (tl *tTieredList) Clone() ICacheList {
+	if nil == tl {
+		return nil
+	}
+
+	tl.Lock()
+	defer tl.Unlock()
+
+	clone := &tTieredList{
+		hot:    tl.hot.Clone(),
+		cold:   tl.cold,
+		clock:  tl.clock,
+		lru:    list.New(),
+		index:  make(map[string]*list.Element, len(tl.index)),
+		maxHot: tl.maxHot,
+	}
+	clone.hot.SetExpireFunc(clone.hotExpired)
+	for elem := tl.lru.Front(); nil != elem; elem = elem.Next() {
+		hostname := elem.Value.(string)
+		clone.index[hostname] = clone.lru.PushBack(hostname)
+	}
+
+	return clone
+} // Clone()
+
+// `Create()` adds a new cache entry for the given hostname.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//   - `aHostname`: The hostname to add a cache entry for.
+//   - `aIPs`: List of IP addresses to add to the cache entry.
+//   - `aTTL`: Time to live for the cache entry.
+//
+// Returns:
+//   - `ICacheList`: The updated cache list.
+func (tl *tTieredList) Create(aCtx context.Context, aHostname string, aIPs []net.IP, aTTL time.Duration) ICacheList {
+	if nil == tl {
+		return nil
+	}
+
+	return tl.Update(aCtx, aHostname, aIPs, aTTL)
+} // Create()
+
+// `Delete()` removes the cache entry for the given hostname.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//   - `aHostname`: The hostname to remove the cache entry for.
+//
+// Returns:
+//   - `bool`: `true` if the cache entry was found and deleted, `false` otherwise.
+func (tl *tTieredList) Delete(aCtx context.Context, aHostname string) bool {
+	if nil == tl {
+		return false
+	}
+	if aHostname = tierKey(aHostname); "" == aHostname {
+		return false
+	}
+
+	tl.Lock()
+	tl.forget(aHostname)
+	tl.Unlock()
+	inHot := tl.hot.Delete(aCtx, aHostname)
+	inCold := tl.cold.Delete(aHostname)
+
+	return inHot || inCold
+} // Delete()
+
+// `demote()` removes the least recently used entries exceeding the
+// hot list's size from the hot list.
+//
+// The caller has to hold the list's lock and to pass the returned
+// entries to `store()` after releasing it.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//
+// Returns:
+//   - `[]tDemoted`: The entries to put into the cold store.
+func (tl *tTieredList) demote(aCtx context.Context) (rDemoted []tDemoted) {
+	for tl.maxHot < tl.lru.Len() {
+		hostname := tl.lru.Remove(tl.lru.Back()).(string)
+		delete(tl.index, hostname)
+
+		ips, ok := tl.hot.IPs(aCtx, hostname)
+		expires, _ := tl.hot.Expiry(aCtx, hostname)
+		tl.hot.Delete(aCtx, hostname)
+		if ok {
+			rDemoted = append(rDemoted, tDemoted{
+				hostname: hostname,
+				entry:    TColdEntry{IPs: ips, Expires: expires},
+			})
+		}
+	}
+
+	return
+} // demote()
+
+// `Exists()` checks whether the given hostname is cached.
+//
+// Parameters:
+//   - `aCtx`: Timeout context to use for the operation.
+//   - `aHostname`: The hostname to check for.
+//
+// Returns:
+//   - `bool`: `true` if the hostname was found in the cache, `false` otherwise.
+func (tl *tTieredList) Exists(aCtx context.Context, aHostname string) bool {
+	if nil == tl {
+		return false
+	}
+	if aHostname = tierKey(aHostname); "" == aHostname {
+		return false
+	}
+	if tl.hot.Exists(aCtx, aHostname) {
+		return true
+	}
+	_, ok := tl.cold.Get(aHostname)
+
+	return ok
+} // Exists()
+
+// `expireCold()` removes all expired entries of the cold store.
+//
+// This method is called automatically by the `AutoExpire()` method.
+func (tl *tTieredList) expireCold() {
+	tl.Lock()
+	deadline := clock.OrSystem(tl.clock).Now()
+	onExpire := tl.onExpire
+	tl.Unlock()
+
+	expired := make(map[string][]net.IP)
+	_ = tl.cold.ForEach(func(aHostname string, aEntry TColdEntry) bool {
+		if aEntry.Expires.Before(deadline) {
+			expired[aHostname] = aEntry.IPs
+		}
+		return true
+	})
+	for hostname, ips := range expired {
+		if tl.cold.Delete(hostname) && (nil != onExpire) {
+			onExpire(hostname, ips)
+		}
+	}
+} // expireCold()
+
+// `Expiry()` returns the time the given hostname's entry expires.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//   - `aHostname`: The hostname to lookup in the cache.
+//
+// Returns:
+//   - `time.Time`: The time after which the entry is invalid.
+//   - `bool`: `true` if the hostname was found in the cache, `false` otherwise.
+func (tl *tTieredList) Expiry(aCtx context.Context, aHostname string) (time.Time, bool) {
+	if nil == tl {
+		return time.Time{}, false
+	}
+	if aHostname = tierKey(aHostname); "" == aHostname {
+		return time.Time{}, false
+	}
+	if expires, ok := tl.hot.Expiry(aCtx, aHostname); ok {
+		return expires, true
+	}
+	entry, ok := tl.cold.Get(aHostname)
+
+	return entry.Expires, ok
+} // Expiry()
+
+// `forget()` removes the given hostname from the LRU list.
+//
+// The caller has to hold the list's lock.
+//
+// Parameters:
+//   - `aHostname`: The hostname to remove.
+func (tl *tTieredList) forget(aHostname string) {
+	if elem, ok := tl.index[aHostname]; ok {
+		tl.lru.Remove(elem)
+		delete(tl.index, aHostname)
+	}
+} // forget()
+
+// `hotExpired()` is called by the hot list for its expired entries.
+//
+// Parameters:
+//   - `aHostname`: The expired entry's hostname.
+//   - `aIPs`: The expired entry's IP addresses.
+func (tl *tTieredList) hotExpired(aHostname string, aIPs []net.IP) {
+	tl.Lock()
+	tl.forget(aHostname)
+	onExpire := tl.onExpire
+	tl.Unlock()
+
+	if nil != onExpire {
+		onExpire(aHostname, aIPs)
+	}
+} // hotExpired()
+
+// `IPs()` returns the IP addresses for the given hostname.
+//
+// A cold entry is moved to the hot list, an expired one is removed.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//   - `aHostname`: The hostname to resolve.
+//
+// Returns:
+//   - `[]net.IP`: List of IP addresses for the given hostname.
+//   - `bool`: `true` if the hostname was found in the cache, `false` otherwise.
+func (tl *tTieredList) IPs(aCtx context.Context, aHostname string) ([]net.IP, bool) {
+	if nil == tl {
+		return nil, false
+	}
+	if aHostname = tierKey(aHostname); "" == aHostname {
+		return nil, false
+	}
+	if ips, ok := tl.hot.IPs(aCtx, aHostname); ok {
+		tl.Lock()
+		if elem, ok := tl.index[aHostname]; ok {
+			tl.lru.MoveToFront(elem)
+		}
+		tl.Unlock()
+		return ips, true
+	}
+
+	entry, ok := tl.cold.Get(aHostname)
+	if !ok {
+		return nil, false
+	}
+	tl.Lock()
+	ttl := entry.Expires.Sub(clock.OrSystem(tl.clock).Now())
+	tl.Unlock()
+
+	// The cold store is accessed without holding the lock
+	if 0 >= ttl {
+		tl.cold.Delete(aHostname)
+		return nil, false
+	}
+	if !tl.cold.Delete(aHostname) {
+		// Promoted by a concurrent lookup meanwhile
+		return tl.hot.IPs(aCtx, aHostname)
+	}
+	tl.Lock()
+	demoted := tl.promote(aCtx, aHostname, entry.IPs, ttl)
+	tl.Unlock()
+	tl.store(demoted)
+
+	return slices.Clone(entry.IPs), true
+} // IPs()
+
+// `Len()` returns the number of cached hostnames.
+//
+// Returns:
+//   - `int`: Number of cached hostnames.
+func (tl *tTieredList) Len() int {
+	if nil == tl {
+		return 0
+	}
+
+	return tl.hot.Len() + tl.cold.Len()
+} // Len()
+
+// `promote()` adds an entry to the hot list, demoting the least
+// recently used entries if necessary.
+//
+// The caller has to hold the list's lock and to pass the returned
+// entries to `store()` after releasing it.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//   - `aHostname`: The entry's hostname.
+//   - `aIPs`: The entry's IP addresses.
+//   - `aTTL`: The entry's time to live.
+//
+// Returns:
+//   - `[]tDemoted`: The entries to put into the cold store.
+func (tl *tTieredList) promote(aCtx context.Context, aHostname string, aIPs []net.IP, aTTL time.Duration) []tDemoted {
+	tl.hot.Update(aCtx, aHostname, aIPs, aTTL)
+	if elem, ok := tl.index[aHostname]; ok {
+		tl.lru.MoveToFront(elem)
+	} else {
+		tl.index[aHostname] = tl.lru.PushFront(aHostname)
+	}
+
+	return tl.demote(aCtx)
+} // promote()
+
+// `Range()` returns a channel that yields all FQDNs in sorted order.
+//
+// Usage: for fqdn := range ICacheList.Range() { ... }
+//
+// The channel is closed automatically when all entries have been yielded.
+//
+// Parameters:
+//   - `aCtx`: Timeout context to use for the operation.
+//
+// Returns:
+//   - `chan string`: Channel that yields all FQDNs in sorted order.
+func (tl *tTieredList) Range(aCtx context.Context) <-chan string {
+	ch := make(chan string)
+	if nil == tl {
+		close(ch)
+		return ch
+	}
+
+	// Collect the hostnames of both tiers
+	hostnames := make([]string, 0, tl.Len())
+	for fqdn := range tl.hot.Range(aCtx) {
+		hostnames = append(hostnames, fqdn)
+	}
+	_ = tl.cold.ForEach(func(aHostname string, _ TColdEntry) bool {
+		hostnames = append(hostnames, aHostname)
+		return true
+	})
+	sortHostnames(hostnames)
+	hostnames = slices.Compact(hostnames)
+
+	go func(aHostList []string) {
+		defer close(ch)
+
+		for _, fqdn := range aHostList {
+			select {
+			case ch <- fqdn:
+				runtime.Gosched()
+			case <-aCtx.Done():
+				return
+			}
+		}
+	}(hostnames)
+
+	return ch
+} // Range()
+
+// `SetClock()` sets the clock deciding about the entries' expiry.
+//
+// A running `AutoExpire()` keeps using its former clock.
+//
+// Parameters:
+//   - `aClock`: The clock to use (`nil` means the system's clock).
+func (tl *tTieredList) SetClock(aClock clock.IClock) {
+	if nil == tl {
+		return
+	}
+
+	tl.Lock()
+	tl.clock = aClock
+	tl.Unlock()
+	tl.hot.SetClock(aClock)
+} // SetClock()
+
+// `SetExpireFunc()` sets the function to call for expired entries.
+//
+// Parameters:
+//   - `aFunc`: The function to call (`nil` to remove it).
+func (tl *tTieredList) SetExpireFunc(aFunc TExpireFunc) {
+	if nil == tl {
+		return
+	}
+
+	tl.Lock()
+	tl.onExpire = aFunc
+	tl.Unlock()
+} // SetExpireFunc()
+
+// `store()` puts the demoted entries into the cold store.
+//
+// Since the cold store may have to access the disk, the caller must
+// not hold the list's lock.
+//
+// Parameters:
+//   - `aDemoted`: The entries returned by `demote()` or `promote()`.
+func (tl *tTieredList) store(aDemoted []tDemoted) {
+	for _, demoted := range aDemoted {
+		_ = tl.cold.Put(demoted.hostname, demoted.entry)
+	}
+} // store()
+
+// `Update()` updates the cache entry of the given hostname.
+//
+// The entry becomes the most recently used one; a former cold
+// entry is removed from the cold store.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//   - `aHostname`: The hostname to update the cache entry for.
+//   - `aIPs`: List of IP addresses to update the cache entry with.
+//   - `aTTL`: Time to live for the cache entry.
+//
+// Returns:
+//   - `ICacheList`: The updated cache list.
+func (tl *tTieredList) Update(aCtx context.Context, aHostname string, aIPs []net.IP, aTTL time.Duration) ICacheList {
+	if nil == tl {
+		return nil
+	}
+	if aHostname = tierKey(aHostname); "" == aHostname {
+		return tl
+	}
+
+	tl.Lock()
+	_, isHot := tl.index[aHostname]
+	tl.Unlock()
+
+	// The cold store is accessed without holding the lock
+	if !isHot {
+		tl.cold.Delete(aHostname)
+	}
+	tl.Lock()
+	demoted := tl.promote(aCtx, aHostname, aIPs, aTTL)
+	tl.Unlock()
+	tl.store(demoted)
+
+	return tl
+} // Update()
+
+/* _EoF_ */
@@ -0,0 +1,231 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package cache
+
+import (
+	"context"
+	"net"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mwat56/dnscache/clock"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// `newTestTiered()` returns a tiered list keeping two hot entries.
+func newTestTiered(t *testing.T) (*tTieredList, *TDiskStore) {
+	t.Helper()
+	ds, err := NewDiskStore(t.TempDir())
+	if nil != err {
+		t.Fatalf("NewDiskStore() error = '%v'", err)
+	}
+
+	return NewTiered(newTrie(), ds, 2).(*tTieredList), ds
+} // newTestTiered()
+
+func Test_NewTiered(t *testing.T) {
+	ctx := context.Background()
+	ips := []net.IP{net.ParseIP("127.0.0.1")}
+	hot := newMap(0)
+	for _, host := range []string{"a.localdomain", "b.localdomain", "c.localdomain"} {
+		hot.Create(ctx, host, ips, time.Hour)
+	}
+	ds, _ := NewDiskStore(t.TempDir())
+
+	tl := NewTiered(hot, ds, 2).(*tTieredList)
+	if got := hot.Len(); 2 != got {
+		t.Errorf("hot.Len() = '%d', want '2'", got)
+	}
+	if got := ds.Len(); 1 != got {
+		t.Errorf("cold.Len() = '%d', want '1'", got)
+	}
+	if got := tl.Len(); 3 != got {
+		t.Errorf("Len() = '%d', want '3'", got)
+	}
+	if 0 >= NewTiered(newTrie(), ds, 0).(*tTieredList).maxHot {
+		t.Error("NewTiered() didn't use the default size")
+	}
+} // Test_NewTiered()
+
+func Test_tTieredList_demotion(t *testing.T) {
+	ctx := context.Background()
+	tl, ds := newTestTiered(t)
+	ips := []net.IP{net.ParseIP("127.0.0.1")}
+
+	tl.Create(ctx, "a.localdomain", ips, time.Hour)
+	tl.Create(ctx, "b.localdomain", ips, time.Hour)
+	tl.IPs(ctx, "a.localdomain") // `b` is the least recently used now
+	tl.Create(ctx, "C.localdomain", ips, time.Hour)
+
+	if tl.hot.Exists(ctx, "b.localdomain") {
+		t.Error("hot.Exists() = 'true', want 'false'")
+	}
+	if _, ok := ds.Get("b.localdomain"); !ok {
+		t.Error("cold.Get() = 'false', want 'true'")
+	}
+	if !tl.Exists(ctx, "B.localdomain") {
+		t.Error("Exists() = 'false', want 'true'")
+	}
+	if expires, ok := tl.Expiry(ctx, "b.localdomain"); !ok || expires.Before(time.Now()) {
+		t.Errorf("Expiry() = '%v', '%v', want a future time", expires, ok)
+	}
+
+	// Accessing `b` promotes it and demotes `a`
+	got, ok := tl.IPs(ctx, "b.localdomain")
+	if !ok || !slices.EqualFunc(got, ips, net.IP.Equal) {
+		t.Errorf("IPs() = '%v', '%v', want '%v'", got, ok, ips)
+	}
+	if !tl.hot.Exists(ctx, "b.localdomain") {
+		t.Error("hot.Exists() = 'false', want 'true'")
+	}
+	if _, ok := ds.Get("a.localdomain"); !ok {
+		t.Error("cold.Get() = 'false', want 'true'")
+	}
+	if got := tl.Len(); 3 != got {
+		t.Errorf("Len() = '%d', want '3'", got)
+	}
+
+	var hosts []string
+	for host := range tl.Range(ctx) {
+		hosts = append(hosts, host)
+	}
+	if 3 != len(hosts) {
+		t.Errorf("Range() = '%v', want three hostnames", hosts)
+	}
+
+	// Deleting works in both tiers
+	if !tl.Delete(ctx, "a.localdomain") || !tl.Delete(ctx, "b.localdomain") {
+		t.Error("Delete() = 'false', want 'true'")
+	}
+	if tl.Delete(ctx, "a.localdomain") {
+		t.Error("Delete() = 'true', want 'false' (deleted already)")
+	}
+	if got := tl.Len(); 1 != got {
+		t.Errorf("Len() = '%d', want '1'", got)
+	}
+} // Test_tTieredList_demotion()
+
+// `tLockCheckStore` records whether the cold store is used while
+// the tiered list's lock is held.
+type tLockCheckStore struct {
+	*TDiskStore
+	tl     *tTieredList
+	locked atomic.Bool
+}
+
+func (lcs *tLockCheckStore) check() {
+	if lcs.tl.TryLock() {
+		lcs.tl.Unlock()
+	} else {
+		lcs.locked.Store(true)
+	}
+} // check()
+
+func (lcs *tLockCheckStore) Delete(aHostname string) bool {
+	lcs.check()
+	return lcs.TDiskStore.Delete(aHostname)
+} // Delete()
+
+func (lcs *tLockCheckStore) Put(aHostname string, aEntry TColdEntry) error {
+	lcs.check()
+	return lcs.TDiskStore.Put(aHostname, aEntry)
+} // Put()
+
+func Test_tTieredList_coldUnlocked(t *testing.T) {
+	ctx := context.Background()
+	ips := []net.IP{net.ParseIP("127.0.0.1")}
+	ds, err := NewDiskStore(t.TempDir())
+	if nil != err {
+		t.Fatalf("NewDiskStore() error = '%v'", err)
+	}
+	cold := &tLockCheckStore{TDiskStore: ds}
+	tl := NewTiered(newTrie(), cold, 1).(*tTieredList)
+	cold.tl = tl
+
+	tl.Create(ctx, "a.localdomain", ips, time.Hour)
+	tl.Create(ctx, "b.localdomain", ips, time.Hour) // demotes `a`
+	tl.IPs(ctx, "a.localdomain")                    // promotes `a`, demotes `b`
+	tl.Update(ctx, "b.localdomain", ips, time.Hour) // promotes `b`, demotes `a`
+
+	if _, ok := ds.Get("a.localdomain"); !ok {
+		t.Error("cold.Get() = 'false', want 'true'")
+	}
+	if cold.locked.Load() {
+		t.Error("cold store used while holding the list's lock")
+	}
+} // Test_tTieredList_coldUnlocked()
+
+func Test_tTieredList_expireCold(t *testing.T) {
+	ctx := context.Background()
+	tl, ds := newTestTiered(t)
+	clk := clock.NewManual(time.Now())
+	tl.SetClock(clk)
+
+	var (
+		mtx     sync.Mutex
+		expired []string
+	)
+	tl.SetExpireFunc(func(aHostname string, _ []net.IP) {
+		mtx.Lock()
+		expired = append(expired, aHostname)
+		mtx.Unlock()
+	})
+
+	ips := []net.IP{net.ParseIP("127.0.0.1")}
+	_ = ds.Put("old.localdomain", TColdEntry{IPs: ips, Expires: clk.Now().Add(time.Minute)})
+	_ = ds.Put("new.localdomain", TColdEntry{IPs: ips, Expires: clk.Now().Add(time.Hour)})
+	clk.Advance(2 * time.Minute)
+
+	// An expired cold entry isn't promoted
+	if _, ok := tl.IPs(ctx, "old.localdomain"); ok {
+		t.Error("IPs() = 'true', want 'false'")
+	}
+	_ = ds.Put("old.localdomain", TColdEntry{IPs: ips, Expires: clk.Now().Add(-time.Second)})
+
+	tl.expireCold()
+	if got := ds.Len(); 1 != got {
+		t.Errorf("cold.Len() = '%d', want '1'", got)
+	}
+	mtx.Lock()
+	if (1 != len(expired)) || ("old.localdomain" != expired[0]) {
+		t.Errorf("expireCold() expired '%v', want 'old.localdomain'", expired)
+	}
+	mtx.Unlock()
+
+	// The promoted entry keeps its remaining time to live
+	if _, ok := tl.IPs(ctx, "new.localdomain"); !ok {
+		t.Fatal("IPs() = 'false', want 'true'")
+	}
+	expires, _ := tl.hot.Expiry(ctx, "new.localdomain")
+	if want := clk.Now().Add(58 * time.Minute); !expires.Equal(want) {
+		t.Errorf("Expiry() = '%v', want '%v'", expires, want)
+	}
+} // Test_tTieredList_expireCold()
+
+func Test_tTieredList_Clone(t *testing.T) {
+	ctx := context.Background()
+	tl, _ := newTestTiered(t)
+	ips := []net.IP{net.ParseIP("127.0.0.1")}
+	for _, host := range []string{"a.localdomain", "b.localdomain", "c.localdomain"} {
+		tl.Create(ctx, host, ips, time.Hour)
+	}
+
+	clone := tl.Clone()
+	if got := clone.Len(); 3 != got {
+		t.Errorf("Clone().Len() = '%d', want '3'", got)
+	}
+	clone.Delete(ctx, "c.localdomain")
+	if !tl.hot.Exists(ctx, "c.localdomain") {
+		t.Error("Delete() of the clone changed the original's hot list")
+	}
+} // Test_tTieredList_Clone()
+
+/* _EoF_ */
@@ -10,9 +10,10 @@ import (
 	"context"
 	"net"
 	"runtime"
-	"sort"
 	"sync"
 	"time"
+
+	"github.com/mwat56/dnscache/clock"
 )
 
 //lint:file-ignore ST1017 - I prefer Yoda conditions
@@ -38,8 +39,10 @@ type (
 	//   - `U`: Update a pattern [Update],
 	//   - `D`: Delete a pattern [Delete].
 	tTrieList struct {
-		_     struct{} // placeholder for embedding
-		tRoot          // embedded root node of the Trie
+		_        struct{}     // placeholder for embedding
+		tRoot                 // embedded root node of the Trie
+		clock    clock.IClock // deciding about the entries' expiry
+		onExpire TExpireFunc  // called for expired entries
 	}
 )
 
@@ -76,12 +79,15 @@ func init() {
 //   - `aRate`: Time interval to refresh the cache.
 //   - `aAbort`: Channel to receive a signal to abort.
 func (tl *tTrieList) AutoExpire(aRate time.Duration, aAbort chan struct{}) {
-	ticker := time.NewTicker(aRate)
-	defer ticker.Stop()
+	tl.RLock()
+	timer := clock.OrSystem(tl.clock).NewTimer(aRate)
+	tl.RUnlock()
+	defer timer.Stop()
 
 	for {
 		select {
-		case <-ticker.C:
+		case <-timer.C():
+			timer.Reset(aRate)
 			go tl.expireEntries()
 			runtime.Gosched() // yield to other goroutines
 
@@ -102,6 +108,7 @@ func (tl *tTrieList) Clone() ICacheList {
 
 	tl.RLock()
 	root := tl.tRoot.node.clone()
+	clk := tl.clock
 	tl.RUnlock()
 	if nil == root {
 		return nil
@@ -111,6 +118,7 @@ func (tl *tTrieList) Clone() ICacheList {
 		tRoot: tRoot{
 			node: root,
 		},
+		clock: clk,
 	}
 } // Clone()
 
@@ -131,7 +139,7 @@ func (tl *tTrieList) Create(aCtx context.Context, aHostname string, aIPs []net.I
 
 	parts := pattern2parts(aHostname)
 	tl.Lock()
-	tl.node.Create(aCtx, parts, aIPs, aTTL)
+	tl.node.Create(withClock(aCtx, tl.clock), parts, aIPs, aTTL)
 	tl.Unlock()
 
 	return tl
@@ -196,7 +204,7 @@ func (tl *tTrieList) Exists(aCtx context.Context, aHostname string) (rOK bool) {
 
 	parts := pattern2parts(aHostname)
 	tl.RLock()
-	_, rOK = tl.node.finalNode(aCtx, parts)
+	_, rOK = tl.node.finalNode(withClock(aCtx, tl.clock), parts)
 	tl.RUnlock()
 
 	return
@@ -210,11 +218,52 @@ func (tl *tTrieList) expireEntries() {
 		return
 	}
 
+	type tExpired struct {
+		hostname string
+		ips      []net.IP
+	}
+	var expired []tExpired
+
 	tl.Lock()
-	tl.node.expire(context.TODO())
+	ctx := withClock(context.TODO(), tl.clock)
+	onExpire := tl.onExpire
+	if nil == onExpire {
+		tl.node.expire(ctx, nil)
+	} else {
+		tl.node.expire(ctx, func(aHostname string, aIPs []net.IP) {
+			expired = append(expired, tExpired{aHostname, aIPs})
+		})
+	}
 	tl.Unlock()
+
+	for _, entry := range expired {
+		onExpire(entry.hostname, entry.ips)
+	}
 } // expireEntries()
 
+// `Expiry()` returns the time the given hostname's entry expires.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//   - `aHostname`: The hostname to lookup in the cache.
+//
+// Returns:
+//   - `rExpiry`: The time after which the entry is invalid.
+//   - `rOK`: `true` if the hostname was found in the cache, `false` otherwise.
+func (tl *tTrieList) Expiry(aCtx context.Context, aHostname string) (rExpiry time.Time, rOK bool) {
+	if nil == tl {
+		return
+	}
+
+	tl.RLock()
+	if node, ok := tl.node.finalNode(withClock(aCtx, tl.clock), pattern2parts(aHostname)); ok {
+		rExpiry, rOK = node.tCachedIP.bestBefore, true
+	}
+	tl.RUnlock()
+
+	return
+} // Expiry()
+
 // `IPs()` returns the IP addresses for the given hostname.
 //
 // Parameters:
@@ -230,7 +279,7 @@ func (tl *tTrieList) IPs(aCtx context.Context, aHostname string) (rIPs []net.IP,
 	}
 
 	tl.RLock()
-	ips := tl.node.Retrieve(aCtx, pattern2parts(aHostname))
+	ips := tl.node.Retrieve(withClock(aCtx, tl.clock), pattern2parts(aHostname))
 	rOK = (0 < len(ips))
 	tl.RUnlock()
 
@@ -289,7 +338,7 @@ func (tl *tTrieList) Range(aCtx context.Context) <-chan string {
 			{node: tl.tRoot.node, path: []string{}},
 		}
 		var ( // avoid repeated allocations during loop
-			cLen, idx          int
+			idx                int
 			entry              tStackEntry
 			kidNames, newParts tPartsList
 			label              string
@@ -321,18 +370,12 @@ func (tl *tTrieList) Range(aCtx context.Context) <-chan string {
 				}
 			}
 
-			if cLen = len(entry.node.tChildren); 0 == cLen {
+			if 0 == entry.node.tChildren.Size() {
 				continue
 			}
 
 			// Process children in sorted order
-			kidNames = make(tPartsList, 0, cLen)
-			for label = range entry.node.tChildren {
-				kidNames = append(kidNames, label)
-			}
-			if 1 < len(kidNames) {
-				sort.Strings(kidNames)
-			}
+			kidNames = entry.node.tChildren.Labels()
 
 			// Push children to stack in reverse-sorted order
 			// (to process them in forward order when popped)
@@ -343,8 +386,9 @@ func (tl *tTrieList) Range(aCtx context.Context) <-chan string {
 				copy(newParts, entry.path)
 				newParts[len(entry.path)] = label
 
+				child, _ := entry.node.tChildren.Get(label)
 				stack = append(stack, tStackEntry{
-					node: entry.node.tChildren[label],
+					node: child,
 					path: newParts,
 				})
 			}
@@ -354,6 +398,36 @@ func (tl *tTrieList) Range(aCtx context.Context) <-chan string {
 	return ch
 } // Range()
 
+// `SetClock()` sets the clock deciding about the entries' expiry.
+//
+// A running `AutoExpire()` keeps using its former clock.
+//
+// Parameters:
+//   - `aClock`: The clock to use (`nil` means the system's clock).
+func (tl *tTrieList) SetClock(aClock clock.IClock) {
+	if nil == tl {
+		return
+	}
+
+	tl.Lock()
+	tl.clock = aClock
+	tl.Unlock()
+} // SetClock()
+
+// `SetExpireFunc()` sets the function to call for expired entries.
+//
+// Parameters:
+//   - `aFunc`: The function to call (`nil` to remove it).
+func (tl *tTrieList) SetExpireFunc(aFunc TExpireFunc) {
+	if nil == tl {
+		return
+	}
+
+	tl.Lock()
+	tl.onExpire = aFunc
+	tl.Unlock()
+} // SetExpireFunc()
+
 // `String()` implements the `fmt.Stringer` interface for a string
 // representation of the cache list.
 //
@@ -388,6 +462,7 @@ func (tl *tTrieList) Update(aCtx context.Context, aHostname string, aIPs []net.I
 
 	parts := pattern2parts(aHostname)
 	tl.Lock()
+	aCtx = withClock(aCtx, tl.clock)
 	if cn, ok := tl.node.finalNode(aCtx, parts); ok {
 		// There's actually a matching cache entry
 		cn.Update(aCtx, aIPs, aTTL)
@@ -508,6 +508,42 @@ func Test_TTrieList_expireEntries(t *testing.T) {
 	}
 } // Test_TTrieList_expireEntries()
 
+func Test_TTrieList_Expiry(t *testing.T) {
+	start := time.Now()
+	tl := newTrie()
+	tl.Create(context.TODO(), "domain.tld", tIpList{net.ParseIP("192.168.1.1")}, time.Hour)
+	end := time.Now()
+
+	tests := []struct {
+		name   string
+		tl     *tTrieList
+		host   string
+		wantOK bool
+	}{
+		/* */
+		{"01 - nil list", nil, "domain.tld", false},
+		{"02 - empty list", newTrie(), "domain.tld", false},
+		{"03 - found", tl, "domain.tld", true},
+		{"04 - intermediate node", tl, "tld", false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, gotOK := tc.tl.Expiry(context.TODO(), tc.host)
+			if gotOK != tc.wantOK {
+				t.Errorf("tTrieList.Expiry() gotOK = '%v', want '%v'",
+					gotOK, tc.wantOK)
+				return
+			}
+			if gotOK && (got.Before(start.Add(time.Hour)) || got.After(end.Add(time.Hour))) {
+				t.Errorf("tTrieList.Expiry() = '%v', want about '%v'",
+					got, start.Add(time.Hour))
+			}
+		})
+	}
+} // Test_TTrieList_Expiry()
+
 func Test_TTrieList_IPs(t *testing.T) {
 	tests := []struct {
 		name string
@@ -12,14 +12,18 @@ import (
 	"fmt"
 	"io"
 	"net"
-	"sort"
 	"strings"
 	"time"
+
+	ch "github.com/mwat56/dnscache/internal/children"
 )
 
 //lint:file-ignore ST1017 - I prefer Yoda conditions
 
 type (
+	// `tChildren` holds the children nodes of a node.
+	tChildren = ch.TChildren[*tTrieNode]
+
 	//
 	// `tCachedIP` is a DNS cache node.
 	tCachedIP struct {
@@ -27,10 +31,6 @@ type (
 		bestBefore time.Time // time after which the node is invalid
 	}
 
-	//
-	// `tChildren` is a map of children nodes.
-	tChildren map[string]*tTrieNode
-
 	//
 	// `tTrieNode` represents a node in the Trie.
 	//
@@ -84,7 +84,7 @@ func (cn *tTrieNode) allPatterns(aCtx context.Context) (rList tPatternList) {
 		node  *tTrieNode // respective node to process
 	}
 	var (
-		cLen, idx, pLen    int
+		idx, pLen          int
 		child              *tTrieNode
 		current            tStackEntry
 		kidNames, newParts tPartsList
@@ -119,24 +119,18 @@ func (cn *tTrieNode) allPatterns(aCtx context.Context) (rList tPatternList) {
 			}
 		}
 
-		if cLen = len(current.node.tChildren); 0 == cLen {
+		if 0 == current.node.tChildren.Size() {
 			continue
 		}
 
-		// Collect and sort children keys for deterministic order
-		kidNames = make(tPartsList, 0, cLen)
-		for label = range current.node.tChildren {
-			kidNames = append(kidNames, label)
-		}
-		if 1 < len(kidNames) {
-			sort.Strings(kidNames)
-		}
+		// Collect the sorted children keys for deterministic order
+		kidNames = current.node.tChildren.Labels()
 
 		// Push children to stack in reverse-sorted order
 		// (to process them in forward order when popped)
 		for idx = len(kidNames) - 1; 0 <= idx; idx-- {
 			label = kidNames[idx]
-			child = current.node.tChildren[label]
+			child, _ = current.node.tChildren.Get(label)
 
 			newParts = make(tPartsList, len(current.parts)+1)
 			copy(newParts, current.parts)
@@ -179,7 +173,7 @@ func (cn *tTrieNode) clone() *tTrieNode {
 		stack = stack[:len(stack)-1]
 
 		// Copy all children
-		for label, child = range entry.src.tChildren {
+		for label, child = range entry.src.tChildren.All() {
 			if nil == child {
 				continue
 			}
@@ -189,9 +183,8 @@ func (cn *tTrieNode) clone() *tTrieNode {
 					tIpList:    child.tCachedIP.tIpList,
 					bestBefore: child.tCachedIP.bestBefore,
 				},
-				tChildren: make(tChildren, len(child.tChildren)),
 			}
-			entry.dst.tChildren[label] = clonedChild
+			entry.dst.tChildren.Put(label, clonedChild)
 			stack = append(stack, stackEntry{child, clonedChild})
 		}
 	}
@@ -235,7 +228,7 @@ func (cn *tTrieNode) count(aCtx context.Context) (rNodes, rPatterns int) {
 			// With IPs it's a complete pattern
 			rPatterns++
 		}
-		if 0 == len(node.tChildren) {
+		if 0 == node.tChildren.Size() {
 			if (0 < rNodes) && (0 == dec) {
 				// Un-count the node without children
 				rNodes--
@@ -244,7 +237,7 @@ func (cn *tTrieNode) count(aCtx context.Context) (rNodes, rPatterns int) {
 			continue
 		}
 
-		for _, child = range node.tChildren {
+		for _, child = range node.tChildren.All() {
 			stack = append(stack, child)
 		}
 	}
@@ -274,7 +267,11 @@ func (cn *tTrieNode) Create(aCtx context.Context, aPartsList tPartsList, aIPs tI
 		aTTL = DefaultTTL
 	}
 
-	node, ok := cn, false
+	var (
+		child *tTrieNode
+		ok    bool
+	)
+	node := cn
 	for depth, label := range aPartsList {
 		// Check for timeout or cancellation
 		if nil != aCtx.Err() {
@@ -282,19 +279,16 @@ func (cn *tTrieNode) Create(aCtx context.Context, aPartsList tPartsList, aIPs tI
 		}
 
 		// Create a new child node if it doesn't exist
-		if nil == node.tChildren {
-			node.tChildren = make(tChildren)
-			node.tChildren[label] = newTrieNode()
-		} else if _, ok = node.tChildren[label]; !ok {
-			node.tChildren[label] = newTrieNode()
+		if child, ok = node.tChildren.Get(label); !ok {
+			child = newTrieNode()
+			node.tChildren.Put(label, child)
 		}
 
 		// Descend into the child node
-		if node, ok = node.tChildren[label]; ok {
-			if (len(aPartsList) - 1) == depth {
-				node.Update(aCtx, aIPs, aTTL)
-				rOK = true
-			}
+		node = child
+		if (len(aPartsList) - 1) == depth {
+			node.Update(aCtx, aIPs, aTTL)
+			rOK = true
 		}
 	}
 
@@ -329,7 +323,7 @@ func (cn *tTrieNode) Delete(aCtx context.Context, aPartsList tPartsList) (rOK bo
 	current = cn
 	// Traverse and build up the stack
 	for _, label := range aPartsList {
-		if child, ok = current.tChildren[label]; !ok {
+		if child, ok = current.tChildren.Get(label); !ok {
 			// Pattern does not exist: nothing to delete
 			return
 		}
@@ -339,7 +333,7 @@ func (cn *tTrieNode) Delete(aCtx context.Context, aPartsList tPartsList) (rOK bo
 
 	// The target node (the one specified by `aPartsList`).
 	// If it has children, just clear its IPs and return.
-	if 0 < len(current.tChildren) {
+	if 0 < current.tChildren.Size() {
 		current.tCachedIP = tCachedIP{}
 		return
 	}
@@ -357,14 +351,15 @@ func (cn *tTrieNode) Delete(aCtx context.Context, aPartsList tPartsList) (rOK bo
 
 		// Safe to delete the child node.
 		// Return the node to the pool:
-		putNode(parent.tChildren[label])
+		child, _ = parent.tChildren.Get(label)
+		putNode(child)
 
 		// Delete the node from its parent:
-		delete(parent.tChildren, label)
+		parent.tChildren.Remove(label)
 		rOK = true
 
 		// If parent has other children or has its own IPs, stop pruning
-		if 0 < len(parent.tChildren) || 0 < len(parent.tCachedIP.tIpList) {
+		if 0 < parent.tChildren.Size() || 0 < len(parent.tCachedIP.tIpList) {
 			return
 		}
 	}
@@ -396,12 +391,12 @@ func (cn *tTrieNode) Equal(aNode *tTrieNode) (rOK bool) {
 	// We're only interested in the node structure so we ignore
 	// the cached IPs and expiration times while comparing.
 
-	if len(cn.tChildren) != len(aNode.tChildren) {
+	if cn.tChildren.Size() != aNode.tChildren.Size() {
 		return
 	}
 
-	for label, myChild := range cn.tChildren {
-		otherChild, ok := aNode.tChildren[label]
+	for label, myChild := range cn.tChildren.All() {
+		otherChild, ok := aNode.tChildren.Get(label)
 		if !ok {
 			return
 		}
@@ -418,22 +413,25 @@ func (cn *tTrieNode) Equal(aNode *tTrieNode) (rOK bool) {
 //
 // Parameters:
 //   - `aCtx`: The timeout context to use for the operation.
+//   - `aExpired`: Optional function to call for each expired entry.
 //
 // Returns:
 //   - `rOK`: `true` if at least one cache node was removed, `false` otherwise.
-func (cn *tTrieNode) expire(aCtx context.Context) (rOK bool) {
+func (cn *tTrieNode) expire(aCtx context.Context, aExpired TExpireFunc) (rOK bool) {
 	if nil == cn {
 		return
 	}
 
 	type tStackEntry struct {
 		name   string
+		fqdn   string // the node's hostname
 		node   *tTrieNode
 		parent *tTrieNode
 	}
 
 	// Start with root node (no parent)
 	stack := []tStackEntry{{node: cn}}
+	deadline := now(aCtx)
 	nodes2Delete := []tStackEntry{}
 
 	// First pass: identify expired nodes and mark for deletion
@@ -449,22 +447,30 @@ func (cn *tTrieNode) expire(aCtx context.Context) (rOK bool) {
 
 		// Check if this node is expired
 		if (0 < len(entry.node.tCachedIP.tIpList)) &&
-			entry.node.tCachedIP.bestBefore.Before(time.Now()) {
+			entry.node.tCachedIP.bestBefore.Before(deadline) {
+			if nil != aExpired {
+				aExpired(entry.fqdn, entry.node.tCachedIP.tIpList)
+			}
 			// Clear the expired data first
 			entry.node.tCachedIP = tCachedIP{}
 
 			// Mark for deletion if it has no children and has a parent,
 			// i.e. it's not the root node
-			if 0 == len(entry.node.tChildren) && entry.parent != nil {
+			if 0 == entry.node.tChildren.Size() && entry.parent != nil {
 				nodes2Delete = append(nodes2Delete, entry)
 			}
 			rOK = true
 		}
 
 		// Add children to stack
-		for label, child := range entry.node.tChildren {
+		for label, child := range entry.node.tChildren.All() {
+			fqdn := label
+			if "" != entry.fqdn {
+				fqdn = label + "." + entry.fqdn
+			}
 			stack = append(stack, tStackEntry{
 				name:   label,
+				fqdn:   fqdn,
 				node:   child,
 				parent: entry.node,
 			})
@@ -478,10 +484,11 @@ func (cn *tTrieNode) expire(aCtx context.Context) (rOK bool) {
 		}
 
 		// Return the child to the pool:
-		putNode(entry.parent.tChildren[entry.name])
+		child, _ := entry.parent.tChildren.Get(entry.name)
+		putNode(child)
 
 		// Delete the node from its parent:
-		delete(entry.parent.tChildren, entry.name)
+		entry.parent.tChildren.Remove(entry.name)
 	}
 
 	return
@@ -516,7 +523,7 @@ func (cn *tTrieNode) finalNode(aCtx context.Context, aPartsList tPartsList) (rNo
 		}
 
 		// Check for a child with the next label
-		if child, ok = current.tChildren[label]; !ok {
+		if child, ok = current.tChildren.Get(label); !ok {
 			return
 		}
 
@@ -526,7 +533,7 @@ func (cn *tTrieNode) finalNode(aCtx context.Context, aPartsList tPartsList) (rNo
 			// We're at the last label of the pattern
 			// hence check for a terminal match:
 			if rOK = (0 < len(current.tCachedIP.tIpList)); rOK {
-				if current.isExpired() {
+				if current.isExpired(now(aCtx)) {
 					rOK = false
 				} else {
 					rNode = current
@@ -553,14 +560,17 @@ func (cn *tTrieNode) First() net.IP {
 
 // `isExpired()` returns `true` if the cache node is expired.
 //
+// Parameters:
+//   - `aNow`: The current time.
+//
 // Returns:
 //   - `bool`: `true` if the cache node is expired, `false` otherwise.
-func (cn *tTrieNode) isExpired() bool {
+func (cn *tTrieNode) isExpired(aNow time.Time) bool {
 	if nil == cn {
 		return true
 	}
 
-	return cn.tCachedIP.bestBefore.Before(time.Now())
+	return cn.tCachedIP.bestBefore.Before(aNow)
 } // isExpired()
 
 // `Len()` returns the number of IP addresses in the cache node.
@@ -589,7 +599,7 @@ func (cn *tTrieNode) match(aCtx context.Context, aPartsList tPartsList) (rNode *
 	if (nil == cn) || (0 == len(aPartsList)) {
 		return
 	}
-	if 0 == len(cn.tChildren) {
+	if 0 == cn.tChildren.Size() {
 		// No children, thus no match
 		return
 	}
@@ -607,7 +617,7 @@ func (cn *tTrieNode) match(aCtx context.Context, aPartsList tPartsList) (rNode *
 // Returns:
 //   - `rIPs`: The list of IP addresses for the given pattern.
 func (cn *tTrieNode) Retrieve(aCtx context.Context, aPartsList tPartsList) (rIPs tIpList) {
-	if (nil == cn) || (0 == len(aPartsList)) || (0 == len(cn.tChildren)) {
+	if (nil == cn) || (0 == len(aPartsList)) || (0 == cn.tChildren.Size()) {
 		return
 	}
 
@@ -651,7 +661,7 @@ func (cn *tTrieNode) store(aCtx context.Context, aWriter io.Writer) error {
 		}
 	)
 	var (
-		cLen, idx, pLen              int
+		idx, pLen                    int
 		entry                        tStackEntry
 		err                          error
 		fqdn, label                  string
@@ -690,18 +700,12 @@ func (cn *tTrieNode) store(aCtx context.Context, aWriter io.Writer) error {
 			}
 		}
 
-		if cLen = len(entry.node.tChildren); 0 == cLen {
+		if 0 == entry.node.tChildren.Size() {
 			continue
 		}
 
-		// Collect and sort children kidNames
-		kidNames = make(tPartsList, 0, cLen)
-		for label = range entry.node.tChildren {
-			kidNames = append(kidNames, label)
-		}
-		if 1 < len(kidNames) {
-			sort.Strings(kidNames)
-		}
+		// Collect the sorted children kidNames
+		kidNames = entry.node.tChildren.Labels()
 
 		// Check for timeout or cancellation
 		if err = aCtx.Err(); nil != err {
@@ -716,9 +720,10 @@ func (cn *tTrieNode) store(aCtx context.Context, aWriter io.Writer) error {
 			copy(newParts, entry.parts)
 			newParts[len(entry.parts)] = label
 
+			child, _ := entry.node.tChildren.Get(label)
 			stack = append(stack, tStackEntry{
 				parts: newParts,
-				node:  entry.node.tChildren[label],
+				node:  child,
 			})
 		}
 	}
@@ -769,7 +774,7 @@ func (cn *tTrieNode) Update(aCtx context.Context, aIPs tIpList, aTTL time.Durati
 		copy(cn.tCachedIP.tIpList, aIPs)
 
 		// Update expiration time
-		cn.tCachedIP.bestBefore = time.Now().Add(aTTL)
+		cn.tCachedIP.bestBefore = now(aCtx).Add(aTTL)
 	} else {
 		// Clear cache data
 		cn.tCachedIP = tCachedIP{}
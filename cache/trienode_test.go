@@ -12,6 +12,8 @@ import (
 	"net"
 	"testing"
 	"time"
+
+	ch "github.com/mwat56/dnscache/internal/children"
 )
 
 //lint:file-ignore ST1017 - I prefer Yoda conditions
@@ -594,20 +596,20 @@ func Test_tCacheNode_Equal(t *testing.T) {
 		{
 			name:   "08 - different children",
 			node:   newTrieNode(),
-			other:  &tTrieNode{tChildren: tChildren{"tld": newTrieNode()}},
+			other:  &tTrieNode{tChildren: ch.New(map[string]*tTrieNode{"tld": newTrieNode()})},
 			wantOK: false,
 		},
 		{
 			name: "09 - different children values",
 			node: newTrieNode(),
 			other: &tTrieNode{
-				tChildren: tChildren{
+				tChildren: ch.New(map[string]*tTrieNode{
 					"tld": &tTrieNode{
 						tCachedIP: tCachedIP{
 							tIpList: tIpList{net.ParseIP("1.2.3.4")},
 						},
 					},
-				},
+				}),
 			},
 			wantOK: false,
 		},
@@ -749,7 +751,7 @@ func Test_tCacheNode_expire(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			gotOK := tc.node.expire(context.TODO())
+			gotOK := tc.node.expire(context.TODO(), nil)
 
 			if gotOK != tc.wantOK {
 				t.Errorf("tTrieNode.expire() = '%v,' want '%v'",
@@ -811,7 +813,7 @@ func Test_tCacheNode_finalNode(t *testing.T) {
 					tIpList{net.ParseIP("1.2.3.4")}, 0)
 				child := n
 				for _, part := range []string{"tld", "domain", "sub"} {
-					child = child.tChildren[part]
+					child, _ = child.tChildren.Get(part)
 				}
 				return child
 			}(),
@@ -880,7 +882,7 @@ func Test_tCacheNode_finalNode(t *testing.T) {
 					tIpList{net.ParseIP("1.2.3.4"), net.ParseIP("5.6.7.8")}, 0)
 				child := n
 				for _, part := range []string{"tld", "domain", "sub"} {
-					child = child.tChildren[part]
+					child, _ = child.tChildren.Get(part)
 				}
 				return child
 			}(),
@@ -989,7 +991,7 @@ func Test_tCacheNode_match(t *testing.T) {
 
 				child := n
 				for _, part := range []string{"tld", "domain"} {
-					child = child.tChildren[part]
+					child, _ = child.tChildren.Get(part)
 				}
 				return child
 			}(),
@@ -14,6 +14,16 @@ import (
 
 //lint:file-ignore ST1017 - I prefer Yoda conditions
 
+type (
+	// `TPoolOptions` contains the settings of the trie's node pool
+	// (see [SetPoolOptions]).
+	TPoolOptions = np.TPoolOptions
+
+	// `TPoolMetrics` contains the metrics data of a node pool
+	// (see [PoolMetrics]).
+	TPoolMetrics = np.TPoolMetrics
+)
+
 var (
 	// `trieNodePool` is the active pool of `tTrieNode` instances.
 	trieNodePool *np.TPool
@@ -37,11 +47,26 @@ func init() {
 func initTriePool() {
 	trieNodePoolInit.Do(func() {
 		trieNodePool = np.Init(func() any {
-			return &tTrieNode{tChildren: make(tChildren)}
+			return &tTrieNode{}
 		}, 0)
 	})
 } // initTriePool()
 
+// `SetPoolOptions()` replaces the node pool shared by all tries with a
+// new one using the given settings.
+//
+// This function should be called before any trie is created since the
+// items of the replaced pool are left to the garbage collector.
+//
+// Parameters:
+//   - `aOptions`: The pool's settings.
+func SetPoolOptions(aOptions TPoolOptions) {
+	initTriePool() // make sure, the default pool isn't created later
+	trieNodePool = np.InitWithOptions(func() any {
+		return &tTrieNode{}
+	}, aOptions)
+} // SetPoolOptions()
+
 // ---------------------------------------------------------------------------
 // `tTrieNode` constructor:
 
@@ -56,19 +81,19 @@ func newTrieNode() (rNode *tTrieNode) {
 
 	item, err := trieNodePool.Get()
 	if nil != err {
-		rNode = &tTrieNode{tChildren: make(tChildren)}
+		rNode = &tTrieNode{}
 	} else {
 		var ok bool
 		if rNode, ok = item.(*tTrieNode); ok {
 			if nil == rNode {
 				// Uninitialised pool during testing
-				rNode = &tTrieNode{tChildren: make(tChildren)}
+				rNode = &tTrieNode{}
 				return
 			}
 			// Clear/reset the old field values
 			rNode.tCachedIP = tCachedIP{}
-			if 0 < len(rNode.tChildren) {
-				rNode.tChildren = make(tChildren)
+			if 0 < rNode.tChildren.Size() {
+				rNode.tChildren = tChildren{}
 			}
 		}
 	}
@@ -90,4 +115,21 @@ func putNode(aNode *tTrieNode) {
 	_ = trieNodePool.Put(aNode) // ignore the (here impossible) error
 } // putNode()
 
+// ---------------------------------------------------------------------------
+// Helper function:
+
+// `PoolMetrics()` returns the current metrics of the node pool shared
+// by all tries.
+//
+// Returns:
+//   - `*TPoolMetrics`: Current pool metrics.
+func PoolMetrics() (rMetrics *TPoolMetrics) {
+	if nil == trieNodePool {
+		initTriePool() // lazy initialisation
+	}
+	rMetrics, _ = trieNodePool.Metrics()
+
+	return
+} // PoolMetrics()
+
 /* _EoF_ */
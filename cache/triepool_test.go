@@ -46,7 +46,7 @@ func Test_newTrieNode(t *testing.T) {
 					gotNode)
 				return
 			}
-			if 0 != len(gotNode.tChildren) {
+			if 0 != gotNode.tChildren.Size() {
 				t.Errorf("newTrieNode() = %v, want empty children",
 					gotNode.tChildren)
 			}
@@ -69,7 +69,7 @@ func Test_putNode(t *testing.T) {
 		},
 		{
 			name: "02 - empty node",
-			node: &tTrieNode{tChildren: make(tChildren)},
+			node: &tTrieNode{tChildren: tChildren{}},
 		},
 		{
 			name: "03 - node with child",
@@ -90,4 +90,25 @@ func Test_putNode(t *testing.T) {
 	}
 } // Test_putNode()
 
+func Test_SetPoolOptions(t *testing.T) {
+	defer SetPoolOptions(TPoolOptions{}) // restore the default pool
+
+	SetPoolOptions(TPoolOptions{InitSize: 8, MaxSize: 8})
+	if got := PoolMetrics(); 7 != got.Size {
+		t.Errorf("PoolMetrics().Size = %d, want %d", got.Size, 7)
+	}
+	putNode(newTrieNode())
+	if got := PoolMetrics(); 1 != got.Hits {
+		t.Errorf("PoolMetrics().Hits = %d, want %d", got.Hits, 1)
+	}
+
+	SetPoolOptions(TPoolOptions{Disabled: true})
+	putNode(newTrieNode())
+	_ = newTrieNode()
+	got := PoolMetrics()
+	if (0 != got.Size) || (0 != got.Hits) || (2 != got.Created) {
+		t.Errorf("PoolMetrics() = %+v, want 2 created nodes", *got)
+	}
+} // Test_SetPoolOptions()
+
 /* _EoF_ */
@@ -0,0 +1,312 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `TSyncKind` is the kind of a [TSyncEvent].
+	TSyncKind uint8
+
+	// `TSyncEvent` is a change of a resolver's cache propagated to
+	// the other instances of a cluster (see [ICacheSync]).
+	//
+	//   - `Origin`: The identifier of the resolver the event comes from.
+	//   - `Kind`: The kind of change.
+	//   - `Hostname`: The cached hostname (`SyncEntry`), the flushed
+	//     pattern (`SyncFlushMatching`) or domain (`SyncFlushSuffix`).
+	//   - `IPs`: The cached IP addresses (`SyncEntry`).
+	//   - `TTL`: The cached entry's time to live (`SyncEntry`).
+	TSyncEvent struct {
+		Origin   string        `json:"origin"`
+		Kind     TSyncKind     `json:"kind"`
+		Hostname string        `json:"hostname,omitempty"`
+		IPs      []net.IP      `json:"ips,omitempty"`
+		TTL      time.Duration `json:"ttl,omitempty"`
+	}
+
+	// `ICacheSync` is a backend propagating cache changes between
+	// the resolvers of a cluster (see [TResolver.SetCacheSync]).
+	//
+	// There are two implementations available: [TGossipSync] sending
+	// the events via UDP to a fixed list of peers, and [TRedisSync]
+	// using a Redis server's publish/subscribe channel.
+	ICacheSync interface {
+		// `Close()` stops the propagation and closes the channel
+		// returned by [Events].
+		//
+		// Returns:
+		//   - `error`: `nil` if the backend was closed, the error otherwise.
+		Close() error
+
+		// `Events()` returns the channel delivering the events
+		// received from the other instances.
+		//
+		// Returns:
+		//   - `<-chan TSyncEvent`: The channel of received events.
+		Events() <-chan TSyncEvent
+
+		// `Publish()` sends an event to the other instances.
+		//
+		// Parameters:
+		//   - `TSyncEvent`: The event to send.
+		//
+		// Returns:
+		//   - `error`: `nil` if the event was sent, the error otherwise.
+		Publish(TSyncEvent) error
+	}
+
+	// `tSyncLink` holds a resolver's current synchronisation.
+	tSyncLink struct {
+		atomic.Pointer[tCacheSync]
+	}
+
+	// `tCacheSync` connects a resolver to a synchronisation backend.
+	tCacheSync struct {
+		backend ICacheSync
+		origin  string        // the resolver's identifier
+		done    chan struct{} // closed when the receiver ended
+	}
+)
+
+const (
+	// `SyncEntry` propagates a newly resolved cache entry.
+	SyncEntry = TSyncKind(iota + 1)
+
+	// `SyncFlush` propagates flushing the whole cache.
+	SyncFlush
+
+	// `SyncFlushMatching` propagates flushing the entries matching
+	// a wildcard pattern (see [TResolver.FlushMatching]).
+	SyncFlushMatching
+
+	// `SyncFlushSuffix` propagates flushing a domain with all its
+	// subdomains (see [TResolver.FlushSuffix]).
+	SyncFlushSuffix
+)
+
+const (
+	// `syncEventBuffer` is the number of received events the backends
+	// buffer before dropping further ones.
+	syncEventBuffer = 1 << 8
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `openSync()` checks and removes the signature of a message signed
+// by `sealSync()`.
+//
+// Parameters:
+//   - `aKey`: The shared secret (empty: messages aren't signed).
+//   - `aMessage`: The received message.
+//
+// Returns:
+//   - `[]byte`: The message without its signature.
+//   - `bool`: `true` if the signature is valid, `false` otherwise.
+func openSync(aKey, aMessage []byte) ([]byte, bool) {
+	if 0 == len(aKey) {
+		return aMessage, true
+	}
+	if sha256.Size > len(aMessage) {
+		return nil, false
+	}
+	mac, message := aMessage[:sha256.Size], aMessage[sha256.Size:]
+	if !hmac.Equal(mac, signSync(aKey, message)) {
+		return nil, false
+	}
+
+	return message, true
+} // openSync()
+
+// `newSyncOrigin()` returns a random identifier of a resolver.
+//
+// Returns:
+//   - `string`: The resolver's identifier.
+func newSyncOrigin() string {
+	var idBytes [8]byte
+	_, _ = rand.Read(idBytes[:])
+
+	return hex.EncodeToString(idBytes[:])
+} // newSyncOrigin()
+
+// `sealSync()` prefixes a message with its signature if a key is
+// given (see `openSync()`).
+//
+// Parameters:
+//   - `aKey`: The shared secret (empty: messages aren't signed).
+//   - `aMessage`: The message to sign.
+//
+// Returns:
+//   - `[]byte`: The signed message.
+func sealSync(aKey, aMessage []byte) []byte {
+	if 0 == len(aKey) {
+		return aMessage
+	}
+
+	return append(signSync(aKey, aMessage), aMessage...)
+} // sealSync()
+
+// `signSync()` returns the signature of a message.
+//
+// Parameters:
+//   - `aKey`: The shared secret.
+//   - `aMessage`: The message to sign.
+//
+// Returns:
+//   - `[]byte`: The message's HMAC-SHA256.
+func signSync(aKey, aMessage []byte) []byte {
+	mac := hmac.New(sha256.New, aKey)
+	mac.Write(aMessage)
+
+	return mac.Sum(nil)
+} // signSync()
+
+// ---------------------------------------------------------------------------
+// `TSyncKind` methods:
+
+// `String()` implements the `fmt.Stringer` interface.
+//
+// Returns:
+//   - `string`: The kind's name.
+func (sk TSyncKind) String() string {
+	switch sk {
+	case SyncEntry:
+		return "entry"
+	case SyncFlush:
+		return "flush"
+	case SyncFlushMatching:
+		return "flushMatching"
+	case SyncFlushSuffix:
+		return "flushSuffix"
+	default:
+		return "unknown"
+	}
+} // String()
+
+// ---------------------------------------------------------------------------
+// `TResolver` methods:
+
+// `applySync()` applies an event received from another instance.
+//
+// The changes aren't propagated again.
+//
+// Parameters:
+//   - `aEvent`: The received event.
+//   - `aOrigin`: The resolver's own identifier.
+func (r *TResolver) applySync(aEvent TSyncEvent, aOrigin string) {
+	if aOrigin == aEvent.Origin {
+		return // an echo of our own event
+	}
+	hostname := strings.Trim(strings.ToLower(strings.TrimSpace(aEvent.Hostname)), ".")
+
+	switch aEvent.Kind {
+	case SyncEntry:
+		if ("" == hostname) || (0 == len(aEvent.IPs)) || (0 >= aEvent.TTL) ||
+			r.neverCache.match(hostname) {
+			return
+		}
+		// The local policies apply to the other instances' answers
+		ips, err := r.filterRebindIPs(hostname, aEvent.IPs)
+		if nil == err {
+			ips, err = r.filterBlockedIPs(hostname, ips)
+		}
+		if (nil != err) || (0 == len(ips)) {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), defLookupTimeout)
+		defer cancel()
+
+		r.Lock()
+		r.ICacheList.Create(ctx, hostname, ips, r.ttlPolicy.ttl(hostname, aEvent.TTL))
+		setMetricsFieldMax(&gMetrics.Peak, uint32(r.ICacheList.Len())) //#nosec G115
+		r.Unlock()
+
+	case SyncFlush:
+		r.flushAll()
+
+	case SyncFlushMatching:
+		_, _ = r.flushMatching(hostname)
+
+	case SyncFlushSuffix:
+		r.flushSuffix(hostname)
+	}
+} // applySync()
+
+// `publishSync()` sends a cache change to the other instances.
+//
+// Parameters:
+//   - `aEvent`: The change to propagate.
+func (r *TResolver) publishSync(aEvent TSyncEvent) {
+	cs := r.cacheSync.Load()
+	if nil == cs {
+		return
+	}
+	aEvent.Origin = cs.origin
+	if err := cs.backend.Publish(aEvent); nil != err {
+		gLog.Warn("failed to propagate cache change",
+			"kind", aEvent.Kind.String(), "hostname", aEvent.Hostname, "error", err)
+	}
+} // publishSync()
+
+// `receiveSync()` applies the events received from the other instances
+// until the backend gets closed.
+//
+// Parameters:
+//   - `aSync`: The synchronisation to serve.
+func (r *TResolver) receiveSync(aSync *tCacheSync) {
+	defer close(aSync.done)
+
+	for event := range aSync.backend.Events() {
+		r.applySync(event, aSync.origin)
+	}
+} // receiveSync()
+
+// `SetCacheSync()` propagates the resolver's newly resolved cache
+// entries and cache flushes to the other instances of a cluster, and
+// applies theirs to this resolver's cache.
+//
+// The resolver takes over the backend: it gets closed when replaced
+// by another one (or `nil` to stop the synchronisation).
+//
+// The received entries are subject to this resolver's policies, i.e.
+// its TTL bounds, cache bypass patterns and IP filters.
+//
+// Parameters:
+//   - `aSync`: The backend to use (`nil` to stop the synchronisation).
+func (r *TResolver) SetCacheSync(aSync ICacheSync) {
+	var cs *tCacheSync
+	if nil != aSync {
+		cs = &tCacheSync{
+			backend: aSync,
+			origin:  newSyncOrigin(),
+			done:    make(chan struct{}),
+		}
+		go r.receiveSync(cs)
+	}
+
+	if old := r.cacheSync.Swap(cs); nil != old {
+		if err := old.backend.Close(); nil != err {
+			gLog.Warn("failed to close cache sync", "error", err)
+		}
+		<-old.done
+	}
+} // SetCacheSync()
+
+/* _EoF_ */
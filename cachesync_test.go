@@ -0,0 +1,156 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tMockSync` records the published events and delivers
+	// the events sent to its channel.
+	tMockSync struct {
+		sync.Mutex
+		events    chan TSyncEvent
+		published []TSyncEvent
+		closed    bool
+	}
+)
+
+func (ms *tMockSync) Close() error {
+	ms.Lock()
+	defer ms.Unlock()
+	if !ms.closed {
+		ms.closed = true
+		close(ms.events)
+	}
+	return nil
+} // Close()
+
+func (ms *tMockSync) Events() <-chan TSyncEvent {
+	return ms.events
+} // Events()
+
+func (ms *tMockSync) Publish(aEvent TSyncEvent) error {
+	ms.Lock()
+	defer ms.Unlock()
+	ms.published = append(ms.published, aEvent)
+	return nil
+} // Publish()
+
+// `waitFor()` waits until a condition is met (or a second passed).
+func waitFor(aCondition func() bool) bool {
+	for range 100 {
+		if aCondition() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return aCondition()
+} // waitFor()
+
+func Test_TResolver_SetCacheSync(t *testing.T) {
+	ctx := context.TODO()
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir(),
+		BlockedCIDRs: []string{"198.51.100.0/24"}, NeverCache: []string{"*.live.localdomain"}})
+	defer r.StopExpire()
+	ms := &tMockSync{events: make(chan TSyncEvent, 8)}
+	r.SetCacheSync(ms)
+	origin := r.cacheSync.Load().origin
+
+	// Other instances' entries are cached
+	ip := net.ParseIP("192.0.2.1")
+	ms.events <- TSyncEvent{Origin: "other", Kind: SyncEntry,
+		Hostname: "Host.LocalDomain.", IPs: []net.IP{ip}, TTL: time.Minute}
+	ms.events <- TSyncEvent{Origin: "other", Kind: SyncEntry,
+		Hostname: "stream.live.localdomain", IPs: []net.IP{ip}, TTL: time.Minute}
+	ms.events <- TSyncEvent{Origin: "other", Kind: SyncEntry,
+		Hostname: "blocked.localdomain", IPs: []net.IP{net.ParseIP("198.51.100.1")}, TTL: time.Minute}
+	ms.events <- TSyncEvent{Origin: origin, Kind: SyncEntry,
+		Hostname: "echo.localdomain", IPs: []net.IP{ip}, TTL: time.Minute}
+	if !waitFor(func() bool { return r.ICacheList.Exists(ctx, "host.localdomain") }) {
+		t.Fatal("SetCacheSync() didn't cache 'host.localdomain'")
+	}
+	time.Sleep(20 * time.Millisecond)
+	for _, hostname := range []string{"stream.live.localdomain", "echo.localdomain"} {
+		if r.ICacheList.Exists(ctx, hostname) {
+			t.Errorf("SetCacheSync() cached '%s'", hostname)
+		}
+	}
+	if ips, _ := r.ICacheList.IPs(ctx, "blocked.localdomain"); (1 != len(ips)) || !net.IPv4zero.Equal(ips[0]) {
+		t.Errorf("IPs() = '%v', want '%v'", ips, net.IPv4zero)
+	}
+
+	// Other instances' flushes are applied but not propagated again
+	ms.events <- TSyncEvent{Origin: "other", Kind: SyncFlushSuffix, Hostname: "localdomain"}
+	if !waitFor(func() bool { return !r.ICacheList.Exists(ctx, "host.localdomain") }) {
+		t.Error("SetCacheSync() didn't flush 'localdomain'")
+	}
+
+	// Own flushes are propagated
+	r.Flush()
+	r.FlushSuffix("Example.COM")
+	_, _ = r.FlushMatching("*.localdomain")
+	_, _ = r.FlushMatching("[invalid")
+	ms.Lock()
+	published := ms.published
+	ms.Unlock()
+	want := []TSyncEvent{
+		{Origin: origin, Kind: SyncFlush},
+		{Origin: origin, Kind: SyncFlushSuffix, Hostname: "example.com"},
+		{Origin: origin, Kind: SyncFlushMatching, Hostname: "*.localdomain"},
+	}
+	if len(published) != len(want) {
+		t.Fatalf("published = '%v', want '%v'", published, want)
+	}
+	for idx, event := range published {
+		if (event.Origin != want[idx].Origin) || (event.Kind != want[idx].Kind) ||
+			(event.Hostname != want[idx].Hostname) {
+			t.Errorf("published[%d] = '%v', want '%v'", idx, event, want[idx])
+		}
+	}
+
+	r.SetCacheSync(nil)
+	if !ms.closed {
+		t.Error("SetCacheSync(nil) didn't close the backend")
+	}
+	r.Flush()
+	if len(ms.published) != len(want) {
+		t.Error("Flush() propagated without backend")
+	}
+} // Test_TResolver_SetCacheSync()
+
+func Test_TSyncKind_String(t *testing.T) {
+	tests := []struct {
+		kind TSyncKind
+		want string
+	}{
+		/* */
+		{SyncEntry, "entry"},
+		{SyncFlush, "flush"},
+		{SyncFlushMatching, "flushMatching"},
+		{SyncFlushSuffix, "flushSuffix"},
+		{TSyncKind(0), "unknown"},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.want, func(t *testing.T) {
+			if got := tc.kind.String(); got != tc.want {
+				t.Errorf("String() = '%s', want '%s'", got, tc.want)
+			}
+		})
+	}
+} // Test_TSyncKind_String()
+
+/* _EoF_ */
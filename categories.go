@@ -0,0 +1,269 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mwat56/dnscache/clock"
+	adl "github.com/mwat56/dnscache/internal/adlist"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `TCategoryState` contains the state of a blocklist category.
+	TCategoryState struct {
+		Name    string `json:"name"`
+		Enabled bool   `json:"enabled"`
+		Blocked uint32 `json:"blocked"` // queries blocked by the category
+	}
+
+	// `tCategory` is the deny list of a category of hostnames (like
+	// malware or phishing domains).
+	tCategory struct {
+		list    *adl.TADlist
+		enabled atomic.Bool
+		blocked uint32 // updated atomically
+	}
+
+	// `tCategories` holds the category-tagged deny lists.
+	//
+	// The categories are checked for all clients in the order they
+	// were added, after the client's own allow/deny list.
+	tCategories struct {
+		sync.RWMutex
+		clock      clock.IClock          // `nil` means the system's clock
+		datadir    string                // base directory of the categories' lists
+		lists      map[string]*tCategory // category name → deny list
+		names      []string              // categories in the order added
+		valid      TValidation           // strictness of the lists' hostname checks
+		subdomains bool                  // the lists' hostnames block their subdomains
+	}
+)
+
+var (
+	// `ErrInvalidCategory` is returned if a category name is invalid.
+	ErrInvalidCategory = errors.New("invalid category name")
+
+	// `ErrUnknownCategory` is returned if a category doesn't exist.
+	ErrUnknownCategory = errors.New("unknown category")
+)
+
+// ---------------------------------------------------------------------------
+// `tCategories` constructor:
+
+// `newCategories()` returns a new, empty list of categories.
+//
+// Parameters:
+//   - `aDataDir`: The directory to store the categories' lists in.
+//
+// Returns:
+//   - `*tCategories`: The new list of categories.
+func newCategories(aDataDir string) *tCategories {
+	return &tCategories{
+		datadir: filepath.Join(aDataDir, "categories"),
+		lists:   make(map[string]*tCategory),
+	}
+} // newCategories()
+
+// ---------------------------------------------------------------------------
+// `tCategories` methods:
+
+// `category()` returns the given category.
+//
+// Parameters:
+//   - `aName`: The category's name.
+//
+// Returns:
+//   - `*tCategory`: The category.
+//   - `error`: `ErrUnknownCategory` if the category doesn't exist, `nil` otherwise.
+func (c *tCategories) category(aName string) (*tCategory, error) {
+	if nil == c {
+		return nil, ErrUnknownCategory
+	}
+	c.RLock()
+	defer c.RUnlock()
+
+	if cat, ok := c.lists[aName]; ok {
+		return cat, nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrUnknownCategory, aName)
+} // category()
+
+// `match()` checks a hostname against the enabled categories.
+//
+// The blocked queries of the first matching category are counted.
+//
+// Parameters:
+//   - `aCtx`: The context of the lookup.
+//   - `aHostname`: The hostname to check.
+//
+// Returns:
+//   - `string`: The matching category's name (empty if none).
+func (c *tCategories) match(aCtx context.Context, aHostname string) string {
+	if nil == c {
+		return ""
+	}
+	c.RLock()
+	defer c.RUnlock()
+
+	for _, name := range c.names {
+		cat := c.lists[name]
+		if cat.enabled.Load() && (adl.ADdeny == cat.list.Match(aCtx, aHostname)) {
+			incMetricsFields(&cat.blocked)
+			return name
+		}
+	}
+
+	return ""
+} // match()
+
+// ---------------------------------------------------------------------------
+// `TResolver` methods:
+
+// `AddCategory()` creates a new, empty and enabled blocklist category.
+//
+// A category (like "malware", "phishing", or "cryptomining") has its
+// own deny list which blocks its hostnames for all clients unless a
+// client's allow list allows them. Each category can be disabled on
+// its own (see [SetCategoryEnabled]) and counts the queries it blocked
+// (see [Categories]).
+//
+// The category's list is stored in a sub-directory of the resolver's
+// data directory. If the category already exists, nothing is changed.
+//
+// Parameters:
+//   - `aName`: The category's name (letters, digits, `-`, and `_` only).
+//
+// Returns:
+//   - `error`: `nil` if the category was created, the error otherwise.
+func (r *TResolver) AddCategory(aName string) error {
+	if !groupNameRE.MatchString(aName) {
+		return fmt.Errorf("%w: %q", ErrInvalidCategory, aName)
+	}
+	c := r.categories
+	if nil == c {
+		return ErrUnknownCategory
+	}
+	c.Lock()
+	defer c.Unlock()
+
+	if _, ok := c.lists[aName]; !ok {
+		list := adl.New(filepath.Join(c.datadir, aName))
+		list.SetClock(c.clock)
+		list.SetValidation(c.valid)
+		list.SetImplicitSubdomains(c.subdomains)
+		cat := &tCategory{list: list}
+		cat.enabled.Store(true)
+		c.lists[aName] = cat
+		c.names = append(c.names, aName)
+	}
+
+	return nil
+} // AddCategory()
+
+// `Categories()` returns the state of all blocklist categories.
+//
+// Returns:
+//   - `[]TCategoryState`: The categories in the order they were added.
+func (r *TResolver) Categories() []TCategoryState {
+	c := r.categories
+	if nil == c {
+		return nil
+	}
+	c.RLock()
+	defer c.RUnlock()
+
+	result := make([]TCategoryState, 0, len(c.names))
+	for _, name := range c.names {
+		cat := c.lists[name]
+		result = append(result, TCategoryState{
+			Name:    name,
+			Enabled: cat.enabled.Load(),
+			Blocked: atomic.LoadUint32(&cat.blocked),
+		})
+	}
+
+	return result
+} // Categories()
+
+// `DeleteCategory()` removes a blocklist category.
+//
+// Parameters:
+//   - `aName`: The category's name.
+//
+// Returns:
+//   - `bool`: `true` if the category was removed, `false` otherwise.
+func (r *TResolver) DeleteCategory(aName string) bool {
+	c := r.categories
+	if nil == c {
+		return false
+	}
+	c.Lock()
+	defer c.Unlock()
+
+	if _, ok := c.lists[aName]; !ok {
+		return false
+	}
+	delete(c.lists, aName)
+	c.names = slices.DeleteFunc(c.names, func(aCategory string) bool {
+		return aCategory == aName
+	})
+
+	return true
+} // DeleteCategory()
+
+// `LoadCategoryBlocklists()` loads a category's blocklists from the
+// given URLs.
+//
+// Parameters:
+//   - `aName`: The category's name.
+//   - `aURLs`: The URLs to download the blocklists from.
+//
+// Returns:
+//   - `error`: An error in case of problems, or `nil` otherwise.
+func (r *TResolver) LoadCategoryBlocklists(aName string, aURLs []string) error {
+	cat, err := r.categories.category(aName)
+	if nil != err {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second<<2)
+	defer cancel()
+
+	_, err = cat.list.LoadDeny(ctx, aURLs)
+
+	return err
+} // LoadCategoryBlocklists()
+
+// `SetCategoryEnabled()` switches the blocking of a category on or off.
+//
+// Parameters:
+//   - `aName`: The category's name.
+//   - `aEnabled`: Whether the category's hostnames are to be blocked.
+//
+// Returns:
+//   - `error`: `nil` if the category was switched, the error otherwise.
+func (r *TResolver) SetCategoryEnabled(aName string, aEnabled bool) error {
+	cat, err := r.categories.category(aName)
+	if nil != err {
+		return err
+	}
+	cat.enabled.Store(aEnabled)
+
+	return nil
+} // SetCategoryEnabled()
+
+/* _EoF_ */
@@ -0,0 +1,126 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_TResolver_AddCategory(t *testing.T) {
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir()})
+	defer r.StopExpire()
+
+	tests := []struct {
+		name      string
+		category  string
+		wantErr   error
+		wantNames []string
+	}{
+		/* */
+		{"01 - empty name", "", ErrInvalidCategory, nil},
+		{"02 - invalid name", "../malware", ErrInvalidCategory, nil},
+		{"03 - valid name", "malware", nil, []string{"malware"}},
+		{"04 - second category", "phishing", nil, []string{"malware", "phishing"}},
+		{"05 - existing category", "malware", nil, []string{"malware", "phishing"}},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := r.AddCategory(tc.category); !errors.Is(err, tc.wantErr) {
+				t.Errorf("AddCategory() error = '%v', want '%v'", err, tc.wantErr)
+			}
+			got := r.Categories()
+			if len(got) != len(tc.wantNames) {
+				t.Fatalf("Categories() = '%v', want '%v'", got, tc.wantNames)
+			}
+			for i, state := range got {
+				if (state.Name != tc.wantNames[i]) || !state.Enabled {
+					t.Errorf("Categories()[%d] = '%v', want enabled '%s'", i, state, tc.wantNames[i])
+				}
+			}
+		})
+	}
+
+	if !r.DeleteCategory("malware") {
+		t.Error("DeleteCategory() = 'false', want 'true'")
+	}
+	if r.DeleteCategory("malware") {
+		t.Error("DeleteCategory() again = 'true', want 'false'")
+	}
+	if got := r.Categories(); (1 != len(got)) || ("phishing" != got[0].Name) {
+		t.Errorf("Categories() = '%v', want 'phishing'", got)
+	}
+	if err := r.SetCategoryEnabled("malware", false); !errors.Is(err, ErrUnknownCategory) {
+		t.Errorf("SetCategoryEnabled() error = '%v', want '%v'", err, ErrUnknownCategory)
+	}
+	if err := r.LoadCategoryBlocklists("malware", nil); !errors.Is(err, ErrUnknownCategory) {
+		t.Errorf("LoadCategoryBlocklists() error = '%v', want '%v'", err, ErrUnknownCategory)
+	}
+} // Test_TResolver_AddCategory()
+
+func Test_TResolver_fetchCategories(t *testing.T) {
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir(), Offline: true})
+	defer r.StopExpire()
+	_ = r.AddCategory("malware")
+	_ = r.AddCategory("ads")
+	ctx := context.TODO()
+	r.categories.lists["malware"].list.AddDeny(ctx, "*.evil.tld")
+	r.categories.lists["ads"].list.AddDeny(ctx, "*.evil.tld")
+	r.categories.lists["ads"].list.AddDeny(ctx, "*.ads.tld")
+	r.adlist.AddAllow(ctx, "good.ads.tld")
+
+	tests := []struct {
+		name     string
+		disable  string
+		hostname string
+		want     bool
+	}{
+		/* */
+		{"01 - malware blocked", "", "www.evil.tld", true},
+		{"02 - ads blocked", "", "banner.ads.tld", true},
+		{"03 - allow list beats category", "", "good.ads.tld", false},
+		{"04 - not listed", "", "www.example.tld", false},
+		{"05 - ads disabled", "ads", "banner.ads.tld", false},
+		{"06 - malware still blocked", "ads", "www.evil.tld", true},
+		{"07 - malware disabled", "malware", "www.evil.tld", false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if "" != tc.disable {
+				if err := r.SetCategoryEnabled(tc.disable, false); nil != err {
+					t.Fatalf("SetCategoryEnabled() error = '%v'", err)
+				}
+			}
+			ips, err := r.FetchFor(net.ParseIP("192.0.2.1"), tc.hostname)
+			got := (nil == err) && (1 == len(ips)) && ips[0].Equal(net.IPv4zero)
+			if got != tc.want {
+				t.Errorf("FetchFor() = '%v' (%v), want blocked '%v'", ips, err, tc.want)
+			}
+		})
+	}
+
+	// The first enabled category counts the blocked query
+	want := map[string]uint32{"malware": 2, "ads": 1}
+	for _, state := range r.Categories() {
+		if want[state.Name] != state.Blocked {
+			t.Errorf("%s.Blocked = '%d', want '%d'", state.Name, state.Blocked, want[state.Name])
+		}
+		if state.Enabled {
+			t.Errorf("%s.Enabled = 'true', want 'false'", state.Name)
+		}
+	}
+} // Test_TResolver_fetchCategories()
+
+/* _EoF_ */
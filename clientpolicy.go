@@ -0,0 +1,134 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"net"
+	"sync/atomic"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `TClientAction` is the decision of an [IClientPolicy] about a
+	// client's query.
+	TClientAction uint8
+
+	// `IClientPolicy` decides about the queries of a client before
+	// they get processed (see [TResolver.SetClientPolicy]).
+	//
+	// It allows to plug in e.g. dynamic blocking of abusive clients
+	// (like fail2ban) or external IP reputation services without
+	// modifying the server.
+	IClientPolicy interface {
+		// `Check()` returns the action to take for a client's query.
+		//
+		// The method is called synchronously for each query, hence
+		// it should return quickly (e.g. by consulting a local copy
+		// of the reputation data).
+		//
+		// Parameters:
+		//   - `aClient`: The querying client's IP address.
+		//
+		// Returns:
+		//   - `TClientAction`: The action to take.
+		Check(aClient net.IP) TClientAction
+	}
+
+	// `TClientPolicyFunc` lets an ordinary function be used as an
+	// [IClientPolicy].
+	TClientPolicyFunc func(aClient net.IP) TClientAction
+
+	// `tPolicyLink` holds a resolver's current client policy.
+	tPolicyLink struct {
+		atomic.Pointer[tClientPolicy]
+	}
+
+	// `tClientPolicy` wraps the policy to store it atomically.
+	tClientPolicy struct {
+		policy IClientPolicy
+	}
+)
+
+const (
+	// `ClientAllow` processes the client's query as usual.
+	ClientAllow = TClientAction(iota)
+
+	// `ClientDrop` ignores the client's query without an answer.
+	ClientDrop
+
+	// `ClientRefuse` answers the client's query with REFUSED.
+	ClientRefuse
+)
+
+// ---------------------------------------------------------------------------
+// `TClientAction` methods:
+
+// `String()` returns the action's name.
+//
+// Returns:
+//   - `string`: "allow", "drop", or "refuse".
+func (ca TClientAction) String() string {
+	switch ca {
+	case ClientDrop:
+		return "drop"
+	case ClientRefuse:
+		return "refuse"
+	}
+
+	return "allow"
+} // String()
+
+// ---------------------------------------------------------------------------
+// `TClientPolicyFunc` methods:
+
+// `Check()` calls the function.
+//
+// Parameters:
+//   - `aClient`: The querying client's IP address.
+//
+// Returns:
+//   - `TClientAction`: The function's result.
+func (f TClientPolicyFunc) Check(aClient net.IP) TClientAction {
+	return f(aClient)
+} // Check()
+
+// ---------------------------------------------------------------------------
+// `TResolver` methods:
+
+// `CheckClient()` asks the client policy (if any) how to handle a
+// client's query.
+//
+// Parameters:
+//   - `aClient`: The querying client's IP address.
+//
+// Returns:
+//   - `TClientAction`: The policy's decision (`ClientAllow` if there's
+//     no policy).
+func (r *TResolver) CheckClient(aClient net.IP) TClientAction {
+	cp := r.clientCheck.Load()
+	if nil == cp {
+		return ClientAllow
+	}
+
+	return cp.policy.Check(aClient)
+} // CheckClient()
+
+// `SetClientPolicy()` sets the policy deciding about each client's
+// queries before they get processed.
+//
+// Parameters:
+//   - `aPolicy`: The policy to use (`nil` to allow all clients).
+func (r *TResolver) SetClientPolicy(aPolicy IClientPolicy) {
+	if nil == aPolicy {
+		r.clientCheck.Store(nil)
+		return
+	}
+	r.clientCheck.Store(&tClientPolicy{policy: aPolicy})
+} // SetClientPolicy()
+
+/* _EoF_ */
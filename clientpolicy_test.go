@@ -0,0 +1,85 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"net"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_TClientAction_String(t *testing.T) {
+	tests := []struct {
+		name   string
+		action TClientAction
+		want   string
+	}{
+		/* */
+		{"01 - allow", ClientAllow, "allow"},
+		{"02 - drop", ClientDrop, "drop"},
+		{"03 - refuse", ClientRefuse, "refuse"},
+		{"04 - unknown", TClientAction(99), "allow"},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.action.String(); got != tc.want {
+				t.Errorf("TClientAction.String() = '%s', want '%s'", got, tc.want)
+			}
+		})
+	}
+} // Test_TClientAction_String()
+
+func Test_TResolver_CheckClient(t *testing.T) {
+	r := New(0)
+	banned := net.ParseIP("192.0.2.66")
+	suspect := net.ParseIP("192.0.2.99")
+
+	if got := r.CheckClient(banned); ClientAllow != got {
+		t.Errorf("CheckClient() without policy = '%v', want '%v'", got, ClientAllow)
+	}
+
+	r.SetClientPolicy(TClientPolicyFunc(func(aClient net.IP) TClientAction {
+		switch {
+		case banned.Equal(aClient):
+			return ClientDrop
+		case suspect.Equal(aClient):
+			return ClientRefuse
+		}
+		return ClientAllow
+	}))
+
+	tests := []struct {
+		name   string
+		client net.IP
+		want   TClientAction
+	}{
+		/* */
+		{"01 - banned", banned, ClientDrop},
+		{"02 - suspect", suspect, ClientRefuse},
+		{"03 - other", net.ParseIP("192.0.2.1"), ClientAllow},
+		{"04 - unknown", nil, ClientAllow},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := r.CheckClient(tc.client); got != tc.want {
+				t.Errorf("CheckClient() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+
+	r.SetClientPolicy(nil)
+	if got := r.CheckClient(banned); ClientAllow != got {
+		t.Errorf("CheckClient() after reset = '%v', want '%v'", got, ClientAllow)
+	}
+} // Test_TResolver_CheckClient()
+
+/* _EoF_ */
@@ -0,0 +1,149 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+
+// Package `clock` provides the source of the current time used by
+// the resolver, its cache and its allow/deny lists.
+//
+// In production code that's always `System`, while tests can inject
+// a `TManual` clock to fast-forward time instead of sleeping.
+package clock
+
+import "time"
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `IClock` is the source of the current time and of timers.
+	IClock interface {
+		// `After()` waits for the duration to elapse and then sends
+		// the current time on the returned channel.
+		//
+		// Parameters:
+		//   - `time.Duration`: The time to wait.
+		//
+		// Returns:
+		//   - `<-chan time.Time`: The channel to receive the time from.
+		After(time.Duration) <-chan time.Time
+
+		// `NewTimer()` creates a timer that sends the current time on
+		// its channel after the duration elapsed.
+		//
+		// Parameters:
+		//   - `time.Duration`: The time to wait.
+		//
+		// Returns:
+		//   - `ITimer`: The new timer.
+		NewTimer(time.Duration) ITimer
+
+		// `Now()` returns the current time.
+		//
+		// Returns:
+		//   - `time.Time`: The current time.
+		Now() time.Time
+	}
+
+	// `ITimer` is a single event timer created by an `IClock`.
+	ITimer interface {
+		// `C()` returns the channel the time is sent on.
+		//
+		// Returns:
+		//   - `<-chan time.Time`: The timer's channel.
+		C() <-chan time.Time
+
+		// `Reset()` changes the timer to expire after the duration.
+		//
+		// Parameters:
+		//   - `time.Duration`: The time to wait.
+		//
+		// Returns:
+		//   - `bool`: `true` if the timer had been active, `false` otherwise.
+		Reset(time.Duration) bool
+
+		// `Stop()` prevents the timer from firing.
+		//
+		// Returns:
+		//   - `bool`: `true` if the timer had been active, `false` otherwise.
+		Stop() bool
+	}
+
+	// `tSystem` is the clock of the operating system.
+	tSystem struct{}
+
+	// `tSystemTimer` is a timer of the operating system's clock.
+	tSystemTimer struct {
+		*time.Timer
+	}
+)
+
+var (
+	// `System` is the clock of the operating system.
+	System IClock = tSystem{}
+)
+
+// `OrSystem()` returns the given clock or, if that's `nil`, the
+// operating system's clock.
+//
+// Parameters:
+//   - `aClock`: The clock to use.
+//
+// Returns:
+//   - `IClock`: The clock to use.
+func OrSystem(aClock IClock) IClock {
+	if nil == aClock {
+		return System
+	}
+
+	return aClock
+} // OrSystem()
+
+// ---------------------------------------------------------------------------
+// `tSystem` methods:
+
+// `After()` waits for the duration to elapse and then sends the
+// current time on the returned channel.
+//
+// Parameters:
+//   - `aDuration`: The time to wait.
+//
+// Returns:
+//   - `<-chan time.Time`: The channel to receive the time from.
+func (tSystem) After(aDuration time.Duration) <-chan time.Time {
+	return time.After(aDuration)
+} // After()
+
+// `NewTimer()` creates a timer that sends the current time on its
+// channel after the duration elapsed.
+//
+// Parameters:
+//   - `aDuration`: The time to wait.
+//
+// Returns:
+//   - `ITimer`: The new timer.
+func (tSystem) NewTimer(aDuration time.Duration) ITimer {
+	return tSystemTimer{time.NewTimer(aDuration)}
+} // NewTimer()
+
+// `Now()` returns the current time.
+//
+// Returns:
+//   - `time.Time`: The current time.
+func (tSystem) Now() time.Time {
+	return time.Now()
+} // Now()
+
+// ---------------------------------------------------------------------------
+// `tSystemTimer` methods:
+
+// `C()` returns the channel the time is sent on.
+//
+// Returns:
+//   - `<-chan time.Time`: The timer's channel.
+func (st tSystemTimer) C() <-chan time.Time {
+	return st.Timer.C
+} // C()
+
+/* _EoF_ */
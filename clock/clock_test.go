@@ -0,0 +1,51 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_OrSystem(t *testing.T) {
+	manual := NewManual(time.Time{})
+
+	if got := OrSystem(nil); got != System {
+		t.Errorf("OrSystem(nil) = '%v', want '%v'", got, System)
+	}
+	if got := OrSystem(manual); got != manual {
+		t.Errorf("OrSystem() = '%v', want '%v'", got, manual)
+	}
+} // Test_OrSystem()
+
+func Test_tSystem(t *testing.T) {
+	before := time.Now()
+	if got := System.Now(); got.Before(before) {
+		t.Errorf("Now() = '%v', want >= '%v'", got, before)
+	}
+
+	select {
+	case <-System.After(time.Millisecond):
+	case <-time.After(time.Second):
+		t.Error("After() didn't fire")
+	}
+
+	timer := System.NewTimer(time.Hour)
+	if !timer.Stop() {
+		t.Error("Stop() = 'false', want 'true'")
+	}
+	timer.Reset(time.Millisecond)
+	select {
+	case <-timer.C():
+	case <-time.After(time.Second):
+		t.Error("NewTimer() didn't fire")
+	}
+} // Test_tSystem()
+
+/* _EoF_ */
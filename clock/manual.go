@@ -0,0 +1,218 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `TManual` is a clock that only moves when told so.
+	//
+	// Its timers fire when the clock is advanced beyond their expiry.
+	TManual struct {
+		mtx    sync.Mutex
+		now    time.Time
+		timers []*tManualTimer // the pending timers
+	}
+
+	// `tManualTimer` is a timer of a `TManual` clock.
+	tManualTimer struct {
+		clock *TManual
+		ch    chan time.Time
+		when  time.Time
+	}
+)
+
+// ---------------------------------------------------------------------------
+// `TManual` constructor:
+
+// `NewManual()` returns a clock set to the given time.
+//
+// Parameters:
+//   - `aStart`: The clock's current time.
+//
+// Returns:
+//   - `*TManual`: The new clock.
+func NewManual(aStart time.Time) *TManual {
+	return &TManual{now: aStart}
+} // NewManual()
+
+// ---------------------------------------------------------------------------
+// `TManual` methods:
+
+// `Advance()` moves the clock forward, firing all timers expiring
+// meanwhile.
+//
+// Parameters:
+//   - `aDuration`: The time to move the clock by.
+func (m *TManual) Advance(aDuration time.Duration) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.now = m.now.Add(aDuration)
+	m.fire()
+} // Advance()
+
+// `After()` waits for the clock to be advanced by the duration and
+// then sends the clock's time on the returned channel.
+//
+// Parameters:
+//   - `aDuration`: The time to wait.
+//
+// Returns:
+//   - `<-chan time.Time`: The channel to receive the time from.
+func (m *TManual) After(aDuration time.Duration) <-chan time.Time {
+	return m.NewTimer(aDuration).C()
+} // After()
+
+// `fire()` sends the current time to all expired timers and removes
+// them from the list of pending timers.
+//
+// The caller must hold the clock's lock.
+func (m *TManual) fire() {
+	pending := m.timers[:0]
+	for _, timer := range m.timers {
+		if timer.when.After(m.now) {
+			pending = append(pending, timer)
+			continue
+		}
+		select {
+		case timer.ch <- m.now:
+		default: // the previous value wasn't received
+		}
+	}
+	clear(m.timers[len(pending):])
+	m.timers = pending
+} // fire()
+
+// `NewTimer()` creates a timer that sends the clock's time on its
+// channel after the clock was advanced by the duration.
+//
+// Parameters:
+//   - `aDuration`: The time to wait.
+//
+// Returns:
+//   - `ITimer`: The new timer.
+func (m *TManual) NewTimer(aDuration time.Duration) ITimer {
+	result := &tManualTimer{
+		clock: m,
+		ch:    make(chan time.Time, 1),
+	}
+	result.Reset(aDuration)
+
+	return result
+} // NewTimer()
+
+// `Now()` returns the clock's current time.
+//
+// Returns:
+//   - `time.Time`: The current time.
+func (m *TManual) Now() time.Time {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	return m.now
+} // Now()
+
+// `remove()` removes a timer from the list of pending timers.
+//
+// The caller must hold the clock's lock.
+//
+// Parameters:
+//   - `aTimer`: The timer to remove.
+//
+// Returns:
+//   - `bool`: `true` if the timer was pending, `false` otherwise.
+func (m *TManual) remove(aTimer *tManualTimer) bool {
+	for idx, timer := range m.timers {
+		if timer == aTimer {
+			m.timers = append(m.timers[:idx], m.timers[idx+1:]...)
+			return true
+		}
+	}
+
+	return false
+} // remove()
+
+// `Set()` sets the clock to the given time, firing all timers
+// expiring until then.
+//
+// Parameters:
+//   - `aTime`: The new current time.
+func (m *TManual) Set(aTime time.Time) {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	m.now = aTime
+	m.fire()
+} // Set()
+
+// `Waiters()` returns the number of pending timers.
+//
+// Tests can use it to wait for a goroutine to start waiting before
+// advancing the clock.
+//
+// Returns:
+//   - `int`: The number of pending timers.
+func (m *TManual) Waiters() int {
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	return len(m.timers)
+} // Waiters()
+
+// ---------------------------------------------------------------------------
+// `tManualTimer` methods:
+
+// `C()` returns the channel the time is sent on.
+//
+// Returns:
+//   - `<-chan time.Time`: The timer's channel.
+func (mt *tManualTimer) C() <-chan time.Time {
+	return mt.ch
+} // C()
+
+// `Reset()` changes the timer to expire after the clock was advanced
+// by the duration.
+//
+// Parameters:
+//   - `aDuration`: The time to wait.
+//
+// Returns:
+//   - `bool`: `true` if the timer had been active, `false` otherwise.
+func (mt *tManualTimer) Reset(aDuration time.Duration) bool {
+	m := mt.clock
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	result := m.remove(mt)
+	mt.when = m.now.Add(aDuration)
+	m.timers = append(m.timers, mt)
+	if 0 >= aDuration {
+		m.fire()
+	}
+
+	return result
+} // Reset()
+
+// `Stop()` prevents the timer from firing.
+//
+// Returns:
+//   - `bool`: `true` if the timer had been active, `false` otherwise.
+func (mt *tManualTimer) Stop() bool {
+	m := mt.clock
+	m.mtx.Lock()
+	defer m.mtx.Unlock()
+
+	return m.remove(mt)
+} // Stop()
+
+/* _EoF_ */
@@ -0,0 +1,117 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// `fired()` checks whether a timer's channel has a value.
+func fired(aCh <-chan time.Time) bool {
+	select {
+	case <-aCh:
+		return true
+	default:
+		return false
+	}
+} // fired()
+
+func Test_TManual_Now(t *testing.T) {
+	start := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	m := NewManual(start)
+	if got := m.Now(); !got.Equal(start) {
+		t.Errorf("NewManual() = '%v', want '%v'", got, start)
+	}
+
+	m.Advance(time.Hour)
+	if got, want := m.Now(), start.Add(time.Hour); !got.Equal(want) {
+		t.Errorf("Advance() = '%v', want '%v'", got, want)
+	}
+
+	want := start.AddDate(1, 0, 0)
+	m.Set(want)
+	if got := m.Now(); !got.Equal(want) {
+		t.Errorf("Set() = '%v', want '%v'", got, want)
+	}
+} // Test_TManual_Now()
+
+func Test_TManual_NewTimer(t *testing.T) {
+	m := NewManual(time.Time{})
+	short, long := m.NewTimer(time.Minute), m.NewTimer(time.Hour)
+	after := m.After(2 * time.Minute)
+	if got := m.Waiters(); 3 != got {
+		t.Errorf("Waiters() = '%d', want '%d'", got, 3)
+	}
+
+	tests := []struct {
+		name      string
+		advance   time.Duration
+		wantShort bool
+		wantLong  bool
+		wantAfter bool
+	}{
+		/* */
+		{"01 - nothing due", 30 * time.Second, false, false, false},
+		{"02 - short due", 30 * time.Second, true, false, false},
+		{"03 - after due", 5 * time.Minute, false, false, true},
+		{"04 - long due", time.Hour, false, true, false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			m.Advance(tc.advance)
+			if got := fired(short.C()); got != tc.wantShort {
+				t.Errorf("short fired = '%v', want '%v'", got, tc.wantShort)
+			}
+			if got := fired(long.C()); got != tc.wantLong {
+				t.Errorf("long fired = '%v', want '%v'", got, tc.wantLong)
+			}
+			if got := fired(after); got != tc.wantAfter {
+				t.Errorf("after fired = '%v', want '%v'", got, tc.wantAfter)
+			}
+		})
+	}
+
+	if got := m.Waiters(); 0 != got {
+		t.Errorf("Waiters() = '%d', want '%d'", got, 0)
+	}
+} // Test_TManual_NewTimer()
+
+func Test_tManualTimer_Stop(t *testing.T) {
+	m := NewManual(time.Time{})
+	timer := m.NewTimer(time.Minute)
+
+	if !timer.Stop() {
+		t.Error("Stop() = 'false', want 'true'")
+	}
+	if timer.Stop() {
+		t.Error("Stop() = 'true', want 'false'")
+	}
+	m.Advance(time.Hour)
+	if fired(timer.C()) {
+		t.Error("stopped timer fired")
+	}
+
+	if timer.Reset(time.Minute) {
+		t.Error("Reset() = 'true', want 'false'")
+	}
+	m.Advance(time.Minute)
+	if !fired(timer.C()) {
+		t.Error("reset timer didn't fire")
+	}
+
+	timer.Reset(0)
+	if !fired(timer.C()) {
+		t.Error("Reset(0) didn't fire")
+	}
+} // Test_tManualTimer_Stop()
+
+/* _EoF_ */
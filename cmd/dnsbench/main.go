@@ -0,0 +1,358 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+
+// `dnsbench` runs a synthetic query workload against either an
+// in-process `dnscache.TResolver` or a running DNS server and reports
+// the queries per second, latency percentiles and (in-process only)
+// the allocations per query.
+//
+// Usage:
+//
+//	dnsbench [-mode resolver|server] [-server 127.0.0.1:53] [-duration 10s]
+//	         [-workers N] [-names 10000] [-skew 1.1] [-hits 0.9]
+//	         [-blocked 0.1] [-seed N] [-dns 192.0.2.1,…]
+//
+// In "resolver" mode the cached names are put into the cache and the
+// blocked names get denied before the run; misses are resolved by the
+// system's (or the given `-dns`) servers. In "server" mode the cache
+// warms up during the run and the names in `blocked.test` are blocked
+// only if the server denies `*.blocked.test`.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mwat56/dnscache"
+	"github.com/mwat56/dnscache/internal/workload"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tBenchArgs` contains the command line arguments.
+	tBenchArgs struct {
+		workload.TOptions
+		DNSServers []string
+		Mode       string
+		Server     string
+		Duration   time.Duration
+		Workers    int
+	}
+
+	// `tBenchResult` contains the measurements of a run.
+	tBenchResult struct {
+		Latencies []time.Duration
+		Elapsed   time.Duration
+		Errors    uint64
+		Allocs    uint64 // `0` unless measured in-process
+		Bytes     uint64 // `0` unless measured in-process
+	}
+
+	// `tQueryFunc` sends a single query, it's created per worker.
+	tQueryFunc func(aHostname string) error
+)
+
+const (
+	// Benchmark modes
+	modeResolver = "resolver"
+	modeServer   = "server"
+
+	// `queryTimeout` is the max. time to wait for a server's answer.
+	queryTimeout = time.Second << 1
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `parseArgs()` parses the command line arguments.
+//
+// Parameters:
+//   - `aArgList`: The command line arguments to parse.
+//
+// Returns:
+//   - `tBenchArgs`: The parsed arguments.
+//   - `error`: `nil` if the arguments are valid, the error otherwise.
+func parseArgs(aArgList []string) (tBenchArgs, error) {
+	var (
+		args    tBenchArgs
+		servers string
+	)
+
+	fs := flag.NewFlagSet("dnsbench", flag.ContinueOnError)
+	fs.StringVar(&args.Mode, "mode", modeResolver,
+		"Benchmark target: 'resolver' (in-process) or 'server'")
+	fs.StringVar(&args.Server, "server", "127.0.0.1:53",
+		"Address of the DNS server to query in 'server' mode")
+	fs.DurationVar(&args.Duration, "duration", 10*time.Second,
+		"Duration of the benchmark run")
+	fs.IntVar(&args.Workers, "workers", runtime.GOMAXPROCS(0),
+		"Number of concurrent workers")
+	fs.IntVar(&args.Names, "names", 10_000,
+		"Number of distinct cached (and blocked) names")
+	fs.Float64Var(&args.Skew, "skew", 1.1,
+		"Exponent of the Zipf distribution of the names' popularity (> 1)")
+	fs.Float64Var(&args.HitRatio, "hits", 0.9,
+		"Share of the non-blocked queries for cached names (0..1)")
+	fs.Float64Var(&args.Blocked, "blocked", 0.1,
+		"Share of the queries for blocked names (0..1)")
+	fs.Int64Var(&args.Seed, "seed", 0,
+		"Seed of the random generator (0 means the current time)")
+	fs.StringVar(&servers, "dns", "",
+		"Comma-separated upstream DNS servers in 'resolver' mode")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "\n\tUsage: %s [OPTIONS]\n\n", fs.Name())
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(aArgList); nil != err {
+		return args, err
+	}
+
+	for _, server := range strings.Split(servers, ",") {
+		if server = strings.TrimSpace(server); "" != server {
+			args.DNSServers = append(args.DNSServers, server)
+		}
+	}
+	switch {
+	case (modeResolver != args.Mode) && (modeServer != args.Mode):
+		return args, fmt.Errorf("invalid mode %q", args.Mode)
+	case 0 >= args.Duration:
+		return args, fmt.Errorf("invalid duration: %v", args.Duration)
+	case 0 >= args.Workers:
+		return args, fmt.Errorf("invalid number of workers: %d", args.Workers)
+	}
+
+	return args, nil
+} // parseArgs()
+
+// `newResolverQuery()` prepares an in-process resolver for the
+// workload and returns the function to query it.
+//
+// Parameters:
+//   - `aArgs`: The benchmark arguments.
+//   - `aWorkload`: The workload to prepare the resolver for.
+//
+// Returns:
+//   - `func() tQueryFunc`: The factory of the workers' query functions.
+//   - `func()`: The function to release the resolver.
+//   - `error`: `nil` if the resolver was prepared, the error otherwise.
+func newResolverQuery(aArgs tBenchArgs, aWorkload *workload.TWorkload) (func() tQueryFunc, func(), error) {
+	dataDir, err := os.MkdirTemp("", "dnsbench-")
+	if nil != err {
+		return nil, nil, err
+	}
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{
+		DataDir:    dataDir,
+		DNSservers: aArgs.DNSServers,
+		MaxRetries: 1,
+	})
+	release := func() {
+		resolver.StopExpire()
+		_ = os.RemoveAll(dataDir)
+	}
+
+	ip := []net.IP{net.ParseIP("192.0.2.2")}
+	for _, name := range aWorkload.CachedNames() {
+		resolver.ICacheList.Create(context.TODO(), name, ip, time.Hour)
+	}
+	if err = resolver.AddDeny(workload.BlockedPattern); nil != err {
+		release()
+		return nil, nil, err
+	}
+
+	return func() tQueryFunc {
+		return func(aHostname string) error {
+			_, err := resolver.Fetch(aHostname)
+			return err
+		}
+	}, release, nil
+} // newResolverQuery()
+
+// `newServerQuery()` returns the function to query a DNS server.
+//
+// Parameters:
+//   - `aServer`: The DNS server's address.
+//
+// Returns:
+//   - `func() tQueryFunc`: The factory of the workers' query functions.
+func newServerQuery(aServer string) func() tQueryFunc {
+	return func() tQueryFunc {
+		var (
+			conn net.Conn
+			id   uint16
+		)
+		answer := make([]byte, 4096)
+
+		return func(aHostname string) error {
+			if nil == conn {
+				var err error
+				if conn, err = net.Dial("udp", aServer); nil != err {
+					return err
+				}
+			}
+			id++
+			_ = conn.SetDeadline(time.Now().Add(queryTimeout))
+			if _, err := conn.Write(workload.Query(id, aHostname, workload.TypeA)); nil != err {
+				return err
+			}
+			for {
+				n, err := conn.Read(answer)
+				if nil != err {
+					// Start over with a new socket (dropping late answers)
+					_ = conn.Close()
+					conn = nil
+					return err
+				}
+				if (12 <= n) && (id == uint16(answer[0])<<8|uint16(answer[1])) {
+					return nil
+				}
+			}
+		}
+	}
+} // newServerQuery()
+
+// `printResult()` prints the measurements of a run.
+//
+// Parameters:
+//   - `aWriter`: The writer to print to.
+//   - `aArgs`: The benchmark arguments.
+//   - `aResult`: The measurements to print.
+func printResult(aWriter io.Writer, aArgs tBenchArgs, aResult tBenchResult) {
+	queries := uint64(len(aResult.Latencies)) //#nosec G115
+	fmt.Fprintf(aWriter, "mode:        %s (%d workers)\n", aArgs.Mode, aArgs.Workers)
+	fmt.Fprintf(aWriter, "workload:    %d names, skew %.2f, %.0f%% hits, %.0f%% blocked\n",
+		aArgs.Names, aArgs.Skew, aArgs.HitRatio*100, aArgs.Blocked*100)
+	fmt.Fprintf(aWriter, "queries:     %d (%d errors) in %v\n",
+		queries, aResult.Errors, aResult.Elapsed.Round(time.Millisecond))
+	if 0 == queries {
+		return
+	}
+	fmt.Fprintf(aWriter, "throughput:  %.0f QPS\n",
+		float64(queries)/aResult.Elapsed.Seconds())
+	fmt.Fprintf(aWriter, "latency:     p50 %v, p99 %v, max %v\n",
+		workload.Percentile(aResult.Latencies, 50),
+		workload.Percentile(aResult.Latencies, 99),
+		workload.Percentile(aResult.Latencies, 100))
+	if 0 < aResult.Allocs {
+		fmt.Fprintf(aWriter, "allocations: %d allocs/query, %d B/query\n",
+			aResult.Allocs/queries, aResult.Bytes/queries)
+	}
+} // printResult()
+
+// `run()` runs the workload with the given query function.
+//
+// Parameters:
+//   - `aArgs`: The benchmark arguments.
+//   - `aWorkload`: The workload to run.
+//   - `aNewQuery`: The factory of the workers' query functions.
+//
+// Returns:
+//   - `tBenchResult`: The measurements.
+func run(aArgs tBenchArgs, aWorkload *workload.TWorkload, aNewQuery func() tQueryFunc) tBenchResult {
+	var (
+		errCount  atomic.Uint64
+		memBefore runtime.MemStats
+		memAfter  runtime.MemStats
+		mtx       sync.Mutex
+		result    tBenchResult
+		wg        sync.WaitGroup
+	)
+	deadline := time.Now().Add(aArgs.Duration)
+
+	runtime.GC()
+	runtime.ReadMemStats(&memBefore)
+	start := time.Now()
+	for range aArgs.Workers {
+		wg.Add(1)
+		worker, query := aWorkload.Clone(), aNewQuery()
+		go func() {
+			defer wg.Done()
+
+			latencies := make([]time.Duration, 0, 1<<12)
+			for time.Now().Before(deadline) {
+				begin := time.Now()
+				if err := query(worker.Next()); nil != err {
+					errCount.Add(1)
+					continue
+				}
+				latencies = append(latencies, time.Since(begin))
+			}
+
+			mtx.Lock()
+			result.Latencies = append(result.Latencies, latencies...)
+			mtx.Unlock()
+		}()
+	}
+	wg.Wait()
+	result.Elapsed = time.Since(start)
+	runtime.ReadMemStats(&memAfter)
+
+	result.Errors = errCount.Load()
+	if modeResolver == aArgs.Mode {
+		result.Allocs = memAfter.Mallocs - memBefore.Mallocs
+		result.Bytes = memAfter.TotalAlloc - memBefore.TotalAlloc
+	}
+
+	return result
+} // run()
+
+// `runBenchmark()` prepares and runs the benchmark.
+//
+// Parameters:
+//   - `aArgs`: The benchmark arguments.
+//
+// Returns:
+//   - `tBenchResult`: The measurements.
+//   - `error`: `nil` if the benchmark was run, the error otherwise.
+func runBenchmark(aArgs tBenchArgs) (tBenchResult, error) {
+	w, err := workload.New(aArgs.TOptions)
+	if nil != err {
+		return tBenchResult{}, err
+	}
+
+	if modeServer == aArgs.Mode {
+		return run(aArgs, w, newServerQuery(aArgs.Server)), nil
+	}
+
+	newQuery, release, err := newResolverQuery(aArgs, w)
+	if nil != err {
+		return tBenchResult{}, err
+	}
+	defer release()
+
+	return run(aArgs, w, newQuery), nil
+} // runBenchmark()
+
+func main() {
+	args, err := parseArgs(os.Args[1:])
+	if nil != err {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
+		}
+		fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[0], err)
+		os.Exit(2)
+	}
+
+	result, err := runBenchmark(args)
+	if nil != err {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", os.Args[0], err)
+		os.Exit(1)
+	}
+	printResult(os.Stdout, args, result)
+} // main()
+
+/* _EoF_ */
@@ -0,0 +1,122 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mwat56/dnscache/internal/workload"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_parseArgs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    tBenchArgs
+		wantErr bool
+	}{
+		/* */
+		{
+			name: "01 - server mode",
+			args: []string{"-mode", "server", "-server", "192.0.2.1:53", "-duration", "1s", "-workers", "4"},
+			want: tBenchArgs{Mode: modeServer, Server: "192.0.2.1:53", Duration: time.Second, Workers: 4},
+		},
+		{
+			name: "02 - DNS servers",
+			args: []string{"-dns", " 192.0.2.1, ,192.0.2.2", "-workers", "1"},
+			want: tBenchArgs{Mode: modeResolver, DNSServers: []string{"192.0.2.1", "192.0.2.2"}, Workers: 1},
+		},
+		{"03 - invalid mode", []string{"-mode", "proxy"}, tBenchArgs{}, true},
+		{"04 - invalid duration", []string{"-duration", "0s"}, tBenchArgs{}, true},
+		{"05 - invalid workers", []string{"-workers", "0"}, tBenchArgs{}, true},
+		{"06 - unknown flag", []string{"-unknown"}, tBenchArgs{}, true},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseArgs(tc.args)
+			if (nil != err) != tc.wantErr {
+				t.Fatalf("parseArgs() error = '%v', wantErr '%v'", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if got.Mode != tc.want.Mode {
+				t.Errorf("parseArgs() Mode = '%v', want '%v'", got.Mode, tc.want.Mode)
+			}
+			if ("" != tc.want.Server) && (got.Server != tc.want.Server) {
+				t.Errorf("parseArgs() Server = '%v', want '%v'", got.Server, tc.want.Server)
+			}
+			if (0 != tc.want.Duration) && (got.Duration != tc.want.Duration) {
+				t.Errorf("parseArgs() Duration = '%v', want '%v'", got.Duration, tc.want.Duration)
+			}
+			if got.Workers != tc.want.Workers {
+				t.Errorf("parseArgs() Workers = '%v', want '%v'", got.Workers, tc.want.Workers)
+			}
+			if strings.Join(got.DNSServers, ",") != strings.Join(tc.want.DNSServers, ",") {
+				t.Errorf("parseArgs() DNSServers = '%v', want '%v'", got.DNSServers, tc.want.DNSServers)
+			}
+		})
+	}
+} // Test_parseArgs()
+
+func Test_runBenchmark(t *testing.T) {
+	upstream, err := workload.StartUpstream()
+	if nil != err {
+		t.Fatalf("StartUpstream() error = '%v'", err)
+	}
+	defer upstream.Close()
+
+	tests := []struct {
+		name       string
+		args       tBenchArgs
+		wantAllocs bool
+	}{
+		/* */
+		{"01 - resolver", tBenchArgs{
+			TOptions: workload.TOptions{Names: 100, HitRatio: 1, Blocked: 0.2},
+			Mode:     modeResolver,
+		}, true},
+		{"02 - server", tBenchArgs{
+			TOptions: workload.TOptions{Names: 100, HitRatio: 1},
+			Mode:     modeServer,
+			Server:   upstream.LocalAddr().String(),
+		}, false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.args.Duration, tc.args.Workers = 100*time.Millisecond, 2
+			got, err := runBenchmark(tc.args)
+			if nil != err {
+				t.Fatalf("runBenchmark() error = '%v'", err)
+			}
+			if (0 == len(got.Latencies)) || (0 != got.Errors) {
+				t.Errorf("runBenchmark() queries = '%d', errors = '%d'",
+					len(got.Latencies), got.Errors)
+			}
+			if (0 < got.Allocs) != tc.wantAllocs {
+				t.Errorf("runBenchmark() allocs = '%d', want allocs '%v'", got.Allocs, tc.wantAllocs)
+			}
+
+			var buf bytes.Buffer
+			printResult(&buf, tc.args, got)
+			if !strings.Contains(buf.String(), "QPS") {
+				t.Errorf("printResult() = '%s', want QPS", buf.String())
+			}
+		})
+	}
+} // Test_runBenchmark()
+
+/* _EoF_ */
@@ -9,16 +9,21 @@ package dnscache
 import (
 	"context"
 	"errors"
-	"log"
+	"fmt"
+	"io"
+	"iter"
 	"math/rand"
 	"net"
 	"os"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/mwat56/dnscache/cache"
+	"github.com/mwat56/dnscache/clock"
 	adl "github.com/mwat56/dnscache/internal/adlist"
 )
 
@@ -37,6 +42,12 @@ const (
 	//
 	// `defLookupTimeout` is the default timeout for DNS lookups.
 	defLookupTimeout = time.Minute << 1
+
+	//
+	// `upstreamMDNS` and `upstreamSystem` name the answering servers
+	// of lookups not sent to the configured DNS servers.
+	upstreamMDNS   = "mdns"
+	upstreamSystem = "system"
 )
 
 type (
@@ -46,28 +57,107 @@ type (
 	// This are the public fields to configure a new `TResolver` instance:
 	//
 	//   - `BlockLists`: List of URLs to download blocklists from.
-	//   - `DNSservers`: List of DNS servers to use, `nil` means use system default.
+	//   - `BlockedCIDRs`: List of IP ranges whose addresses are not to be returned.
+	//   - `DNSservers`: List of DNS servers (IPs, optionally with port) to use, `nil` means use system default.
+	//   - `NeverCache`: List of hostname patterns whose answers are never cached.
+	//   - `RebindExempt`: List of hostname patterns whose answers may contain private addresses.
+	//   - `Rewrites`: List of rewrite rules to apply before any lookup.
+	//   - `SearchDomains`: List of domains to retry unresolvable single-label hostnames with (like `lan`).
 	//   - `AllowList`: Path/file name to read the 'allow' patterns from.
 	//   - `DataDir`: Directory to store local allow and deny lists.
 	//   - `CacheSize`: Initial cache size, `0` means use default (`512`).
 	//   - `Resolver`: Custom resolver, `nil` means use default.
+	//   - `Clock`: Source of the current time and of timers, `nil` means the system's clock.
+	//   - `ColdStore`: Optional persistent store of the least recently used cache entries, `nil` means keep all in memory.
+	//   - `NodePool`: Optional settings of the node pools shared by all resolvers' tries, `nil` means use default.
+	//   - `Download`: Optional settings used to download blocklists, `nil` means use default.
+	//   - `Breaker`: Optional circuit breakers skipping failing upstream servers, `nil` means none.
+	//   - `BlockPolicy`: How to handle answers with blocked IPs (default: `BlockPolicyStrip`).
+	//   - `RebindPolicy`: How to handle answers with private IPs (default: `RebindPolicyOff`).
+	//   - `Validation`: Strictness of the blocklists' hostname checks (default: `ValidateStrict`).
+	//   - `Precedence`: Rule for hostnames matched by both the allow and deny list (default: `PrecedenceAllow`).
+	//   - `Selection`: Which of the `DNSservers` to query (default: `SelectParallel`).
+	//   - `CompileDenyList`: Compile the deny list after each reload for faster, lock-free matching.
+	//   - `FilterDenyList`: Check hostnames against a Bloom filter of the deny list before matching.
+	//   - `BlockSubdomains`: Let the blocklists' hostnames block their subdomains as well (like Pi-hole).
+	//   - `MDNS`: Resolve `.local` hostnames via multicast DNS on the LAN.
+	//   - `Offline`: Neither download blocklists nor query upstream servers (see [TResolver.SetOffline]).
+	//   - `AuditMode`: Only log and count the deny lists' matches instead of blocking (see [TResolver.SetAuditMode]).
+	//   - `SafeSearch`: Enforce the search engines' safe search for clients without a group.
 	//   - `ExpireInterval`: Optional interval (in minutes) to remove expired cache entries.
+	//   - `MaxHotEntries`: Max. number of in-memory cache entries if a `ColdStore` is used, `0` means use default (`65536`).
 	//   - `MaxRetries`: Maximum number of retries for DNS lookup, `0` means use default (`3`).
 	//   - `RefreshInterval`: Optional interval (in minutes) to refresh the cache.
-	//   - `TTL`: Optional time to live (in minutes) for cache entries.
+	//   - `RefreshWorkers`: Number of parallel refresh lookups, `0` means use default (`1`).
+	//   - `RefreshJitter`: Optional maximal random delay added before each refresh lookup.
+	//   - `RefreshWindow`: Optionally refresh only the entries queried within this time.
+	//   - `TTL`: Optional time to live (in minutes) for cache entries whose upstream TTL is unknown.
+	//   - `MinTTL`: Optional lower bound of the cache entries' TTL.
+	//   - `MaxTTL`: Optional upper bound of the cache entries' TTL.
+	//   - `QueryTimeout`: Max. total time of a query including list matching, cache, upstream lookups, and retries, `0` means use default (`2m`).
+	//   - `TTLOverrides`: Optional fixed TTLs by domain (including its subdomains).
 	TResolverOptions struct {
 		BlockLists      []string
+		BlockedCIDRs    []string
 		DNSservers      []string
+		NeverCache      []string
+		RebindExempt    []string
+		Rewrites        []TRewriteRule
+		SearchDomains   []string
+		TTLOverrides    map[string]time.Duration
 		AllowList       string
 		DataDir         string
 		CacheSize       int
+		RefreshWorkers  int
+		MaxHotEntries   int
 		Resolver        *net.Resolver
+		Clock           clock.IClock
+		ColdStore       cache.IColdStore
+		NodePool        *TPoolOptions
+		Download        *TDownloadOptions
+		Breaker         *TBreakerOptions
+		MinTTL          time.Duration
+		MaxTTL          time.Duration
+		QueryTimeout    time.Duration
+		RefreshJitter   time.Duration
+		RefreshWindow   time.Duration
+		BlockPolicy     TBlockPolicy
+		RebindPolicy    TRebindPolicy
+		Validation      TValidation
+		Precedence      TPrecedence
+		Selection       TSelection
+		CompileDenyList bool
+		FilterDenyList  bool
+		BlockSubdomains bool
+		MDNS            bool
+		Offline         bool
+		AuditMode       bool
+		SafeSearch      bool
 		ExpireInterval  uint8
 		MaxRetries      uint8
 		RefreshInterval uint8
 		TTL             uint8
 	}
 
+	// `TDownloadOptions` contains the settings used to download
+	// blocklists (see [TResolverOptions]).
+	//
+	//   - `Proxy`: URL of the HTTP proxy to use (default: the environment's).
+	//   - `UserAgent`: The `User-Agent` header to send.
+	//   - `ConnectTimeout`: Maximal time to establish a connection (default: 10s).
+	//   - `Timeout`: Maximal time of a single download attempt (default: 2m).
+	//   - `RetryDelay`: Delay before the first retry, doubled for each further one (default: 1s).
+	//   - `MaxSize`: Maximal size of a downloaded list in bytes (default: 64 MiB).
+	//   - `Retries`: Number of retries after failed attempts (default: 2, negative: none).
+	TDownloadOptions = adl.TDownloadOptions
+
+	// `TPatternMatch` is a pattern of the default allow or deny list
+	// as returned by [TResolver.FindPatterns].
+	TPatternMatch struct {
+		Pattern string `json:"pattern"`
+		Allow   bool   `json:"allow"` // `false` for the deny list
+	}
+
 	//
 	// `TResolver` is a DNS resolver with an optional background refresh.
 	//
@@ -76,13 +166,51 @@ type (
 	TResolver struct {
 		sync.RWMutex
 		dnsServers       []string
-		cache.ICacheList               //list of DNS cache entries
-		abortExpire      chan struct{} // signal to abort `autoExpire()`
-		abortRefresh     chan struct{} // signal to abort `autoRefresh()`
-		adlist           *adl.TADlist  // allow/deny list to check before DNS
-		resolver         *net.Resolver // DNS resolver to use
-		ttl              time.Duration // TTL for cache entries
-		retries          uint8         // max. number of retries for DNS lookups
+		cache.ICacheList                //list of DNS cache entries
+		abortExpire      chan struct{}  // signal to abort `autoExpire()`
+		abortRefresh     chan struct{}  // signal to abort `autoRefresh()`
+		accessed         *tAccessLog    // last queries of hostnames
+		adguard          *tAdGuard      // AdGuard rules not fitting the lists
+		adlist           *adl.TADlist   // allow/deny list to check before DNS
+		audited          *adl.TTopK     // hostnames matched in audit mode
+		breakers         *tBreakers     // circuit breakers of the upstream servers
+		cacheSync        tSyncLink      // propagation of cache changes
+		categories       *tCategories   // category-tagged deny lists
+		clientCheck      tPolicyLink    // decision about clients' queries
+		clock            clock.IClock   // source of the current time
+		groups           *tGroups       // named allow/deny lists for clients
+		hooks            *tHooks        // lifecycle callbacks
+		ipBlocklist      *adl.TCIDRlist // IP ranges to filter from answers
+		latencies        *tLatencies    // response times of the upstream servers
+		leases           *tLeases       // hostnames of DHCP leases
+		neverCache       *tHostPatterns // hostnames to bypass the cache for
+		queries          *adl.TTopK     // most often queried hostnames
+		rebindExempt     *tHostPatterns // hostnames allowed private answers
+		refresh          tRefreshPolicy // background refresh settings
+		rewrites         *tRewriter     // rewrite rules for queried names
+		search           *tSearchList   // suffixes for single-label names
+		storms           *tBlockStorms  // clients repeatedly querying blocked names
+		types            *tTypeMetrics  // cache hits/misses per query type
+		resolver         *net.Resolver  // DNS resolver to use
+		queryTimeout     time.Duration  // max. total time of a query
+		ttl              time.Duration  // TTL for cache entries
+		ttlPolicy        *tTTLPolicy    // clamping and overrides of TTLs
+		watchers         *tWatchers     // channels of watched hostnames
+		retries          uint8          // max. number of retries for DNS lookups
+		blockPolicy      TBlockPolicy   // handling of answers with blocked IPs
+		rebindPolicy     TRebindPolicy  // handling of answers with private IPs
+		selection        TSelection     // which upstream servers to query
+		mdns             bool           // resolve `.local` names via mDNS
+		safeSearch       atomic.Bool    // enforce safe search for default clients
+		offline          atomic.Bool    // don't query upstream servers
+		audit            atomic.Bool    // don't enforce the deny lists
+	}
+
+	// `tLookupResult` is the answer of an upstream DNS server.
+	tLookupResult struct {
+		ips    []net.IP
+		ttl    time.Duration
+		server string // the answering server (see `lookupUpstream()`)
 	}
 )
 
@@ -91,6 +219,9 @@ type (
 
 // `validateDNSServers()` validates the given list of DNS server IPs.
 //
+// A server may be given with a port (e.g. `127.0.0.1:5353`) to use
+// instead of the standard port `53`.
+//
 // Parameters:
 //   - `aServerList`: List of DNS server IPs to validate.
 //
@@ -105,6 +236,14 @@ func validateDNSServers(aServerList []string) []string {
 	for _, server := range aServerList {
 		if nil != net.ParseIP(server) {
 			validIPs = append(validIPs, server)
+			continue
+		}
+		host, port, err := net.SplitHostPort(server)
+		if (nil != err) || (nil == net.ParseIP(host)) {
+			continue
+		}
+		if num, err := strconv.Atoi(port); (nil == err) && (0 < num) && (65536 > num) {
+			validIPs = append(validIPs, server)
 		}
 	}
 
@@ -162,19 +301,125 @@ func NewWithOptions(aOptions TResolverOptions) *TResolver {
 		optResolver = net.DefaultResolver
 	}
 
+	optClock := clock.OrSystem(aOptions.Clock)
+
 	optRetries := aOptions.MaxRetries
 	if 0 == optRetries {
 		optRetries = defRetries
 	}
+	optQueryTimeout := aOptions.QueryTimeout
+	if 0 >= optQueryTimeout {
+		optQueryTimeout = defLookupTimeout
+	}
+
+	if nil != aOptions.NodePool {
+		// Must be done before the first tries are created
+		adl.SetPoolOptions(*aOptions.NodePool)
+		cache.SetPoolOptions(*aOptions.NodePool)
+	}
+	if nil != aOptions.Download {
+		if err := adl.SetDownloadOptions(*aOptions.Download); nil != err {
+			// Log the error, but don't fail because of that
+			gLog.Error("invalid download options", "error", err)
+		}
+	}
 
 	result := &TResolver{
 		dnsServers:   optServers,
 		abortExpire:  make(chan struct{}),
 		abortRefresh: make(chan struct{}),
+		accessed:     &tAccessLog{clock: optClock},
+		adguard:      newAdGuard(),
 		adlist:       adl.New(optDataDir),
+		audited:      adl.NewTopK(adl.DefaultTopKCapacity, adl.DefaultTopKWindow),
+		breakers:     newBreakers(aOptions.Breaker, optClock),
+		categories:   newCategories(optDataDir),
+		clock:        optClock,
+		groups:       newGroups(optDataDir),
+		hooks:        &tHooks{},
+		ipBlocklist:  adl.NewCIDRlist(),
+		latencies:    newLatencies(optClock),
+		leases:       newLeases(),
+		neverCache:   &tHostPatterns{},
+		queries:      adl.NewTopK(adl.DefaultTopKCapacity, adl.DefaultTopKWindow),
+		rebindExempt: &tHostPatterns{},
+		rewrites:     newRewriter(),
+		search:       &tSearchList{},
+		storms:       &tBlockStorms{clock: optClock},
+		types:        newTypeMetrics(),
 		resolver:     optResolver,
 		ICacheList:   cache.New(cache.CacheTypeTrie, optCacheSize),
 		retries:      optRetries,
+		queryTimeout: optQueryTimeout,
+		blockPolicy:  aOptions.BlockPolicy,
+		rebindPolicy: aOptions.RebindPolicy,
+		selection:    aOptions.Selection,
+		mdns:         aOptions.MDNS,
+		watchers:     &tWatchers{},
+	}
+
+	if nil != aOptions.ColdStore {
+		result.ICacheList = cache.NewTiered(result.ICacheList,
+			aOptions.ColdStore, aOptions.MaxHotEntries)
+	}
+	result.ICacheList.SetExpireFunc(result.hooks.onExpire)
+	if (nil != result.breakers) && (BreakerPolicyStale == result.breakers.policy) {
+		result.OnExpire(result.breakers.remember)
+	}
+	result.safeSearch.Store(aOptions.SafeSearch)
+	result.audit.Store(aOptions.AuditMode)
+	result.adlist.SetValidation(aOptions.Validation)
+	result.groups.valid = aOptions.Validation
+	result.categories.valid = aOptions.Validation
+	result.adlist.SetImplicitSubdomains(aOptions.BlockSubdomains)
+	result.groups.subdomains = aOptions.BlockSubdomains
+	result.categories.subdomains = aOptions.BlockSubdomains
+	result.adlist.SetPrecedence(aOptions.Precedence)
+	result.groups.prefer = aOptions.Precedence
+	if aOptions.Offline {
+		result.SetOffline(true)
+	}
+	if nil != aOptions.Clock {
+		result.ICacheList.SetClock(optClock)
+		result.adlist.SetClock(optClock)
+		result.groups.clock = optClock
+		result.categories.clock = optClock
+		result.leases.clock = optClock
+	}
+
+	for _, rule := range aOptions.Rewrites {
+		if err := result.AddRewrite(rule); nil != err {
+			// Log the error, but don't fail because of that
+			gLog.Error("invalid rewrite rule", "match", rule.Match, "error", err)
+		}
+	}
+
+	for _, pattern := range aOptions.NeverCache {
+		if err := result.neverCache.add(pattern); nil != err {
+			// Log the error, but don't fail because of that
+			gLog.Error("invalid cache bypass pattern", "pattern", pattern, "error", err)
+		}
+	}
+
+	for _, pattern := range aOptions.RebindExempt {
+		if err := result.RebindExempt(pattern); nil != err {
+			// Log the error, but don't fail because of that
+			gLog.Error("invalid rebind exemption", "pattern", pattern, "error", err)
+		}
+	}
+
+	for _, domain := range aOptions.SearchDomains {
+		if err := result.AddSearchDomain(domain); nil != err {
+			// Log the error, but don't fail because of that
+			gLog.Error("invalid search domain", "domain", domain, "error", err)
+		}
+	}
+
+	for _, cidr := range aOptions.BlockedCIDRs {
+		if err := result.BlockCIDR(cidr); nil != err {
+			// Log the error, but don't fail because of that
+			gLog.Error("invalid blocked IP range", "cidr", cidr, "error", err)
+		}
 	}
 
 	if optTTL := aOptions.TTL; 0 == optTTL {
@@ -182,6 +427,15 @@ func NewWithOptions(aOptions TResolverOptions) *TResolver {
 	} else {
 		result.ttl = time.Minute * time.Duration(optTTL)
 	}
+	result.ttlPolicy = newTTLPolicy(result.ttl,
+		aOptions.MinTTL, aOptions.MaxTTL, aOptions.TTLOverrides)
+
+	result.refresh = tRefreshPolicy{
+		concurrency: max(aOptions.RefreshWorkers, 1),
+		jitter:      max(aOptions.RefreshJitter, 0),
+		pace:        defRefreshPace,
+		window:      max(aOptions.RefreshWindow, 0),
+	}
 
 	if 0 < aOptions.RefreshInterval {
 		// Start the auto-refresh goroutine.
@@ -204,15 +458,21 @@ func NewWithOptions(aOptions TResolverOptions) *TResolver {
 	if 0 < len(optAllowList) {
 		if err := result.LoadAllowlist(optAllowList); nil != err {
 			// Log the error, but don't fail because of that
-			log.Printf("Failed to load allowlist: %v", err)
+			gLog.Error("failed to load allowlist", "error", err)
 		}
 	}
 
 	// Load the deny list
+	if aOptions.CompileDenyList {
+		result.adlist.SetCompiled(context.Background(), true)
+	}
+	if aOptions.FilterDenyList {
+		result.adlist.SetFiltered(context.Background(), true)
+	}
 	if 0 < len(aOptions.BlockLists) {
 		if err := result.LoadBlocklists(aOptions.BlockLists); nil != err {
 			// Log the error, but don't fail because of that
-			log.Printf("Failed to load blocklists: %v", err)
+			gLog.Error("failed to load blocklists", "error", err)
 		}
 	}
 
@@ -222,31 +482,192 @@ func NewWithOptions(aOptions TResolverOptions) *TResolver {
 // ---------------------------------------------------------------------------
 // `TResolver` methods:
 
+// `AddAllow()` inserts a hostname pattern into the default allow list.
+//
+// Parameters:
+//   - `aPattern`: The FQDN name/pattern to insert.
+//
+// Returns:
+//   - `error`: `nil` if the pattern was added, the error otherwise.
+func (r *TResolver) AddAllow(aPattern string) error {
+	if !r.adlist.AddAllow(context.Background(), aPattern) {
+		return fmt.Errorf("invalid pattern: %q", aPattern)
+	}
+
+	return nil
+} // AddAllow()
+
+// `AddDeny()` inserts a hostname pattern into the default deny list.
+//
+// Parameters:
+//   - `aPattern`: The FQDN name/pattern to insert.
+//
+// Returns:
+//   - `error`: `nil` if the pattern was added, the error otherwise.
+func (r *TResolver) AddDeny(aPattern string) error {
+	if !r.adlist.AddDeny(context.Background(), aPattern) {
+		return fmt.Errorf("invalid pattern: %q", aPattern)
+	}
+
+	return nil
+} // AddDeny()
+
+// `AllowPatterns()` returns all patterns of the default allow list.
+//
+// Returns:
+//   - `[]string`: The sorted list of allowed patterns.
+func (r *TResolver) AllowPatterns() []string {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second<<2)
+	defer cancel()
+
+	return r.adlist.AllowPatterns(ctx)
+} // AllowPatterns()
+
+// `AllowSeq()` returns an iterator over the patterns of the default
+// allow list.
+//
+// Other than [AllowPatterns] the patterns are produced lazily in sorted
+// order, so a part of a huge list can be read without building a list
+// of all patterns. The loop body mustn't change the allow list.
+//
+// Parameters:
+//   - `aCtx`: The context to use for the operation.
+//
+// Returns:
+//   - `iter.Seq[string]`: The iterator over the allowed patterns.
+func (r *TResolver) AllowSeq(aCtx context.Context) iter.Seq[string] {
+	return r.adlist.AllowSeq(aCtx)
+} // AllowSeq()
+
 // `autoRefresh()` refreshes the cache at a given interval.
 //
 // Parameters:
 //   - `aRate`: Time interval to refresh the cache.
 //   - `aAbort`: Channel to receive a signal to abort.
 func (r *TResolver) autoRefresh(aRate time.Duration, aAbort chan struct{}) {
-	ticker := time.NewTicker(aRate)
-	defer ticker.Stop()
+	timer := r.clock.NewTimer(aRate)
+	defer timer.Stop()
 
 	for {
 		select {
-		case <-ticker.C:
+		case <-timer.C():
 			r.Refresh()
+			timer.Reset(aRate)
 
 		case <-aAbort:
 			return
-
-		default:
-			runtime.Gosched() // yield to other goroutines
 		}
 	}
 } // autoRefresh()
 
+// `Cached()` returns the cached IP addresses of the given hostname
+// without resolving it.
+//
+// Parameters:
+//   - `aHostname`: The hostname to look up.
+//
+// Returns:
+//   - `[]net.IP`: The cached IP addresses.
+//   - `error`: `nil` if the hostname is cached, an error matching `ErrCacheMiss` otherwise.
+func (r *TResolver) Cached(aHostname string) ([]net.IP, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second<<2)
+	defer cancel()
+
+	r.RLock()
+	ips, ok := r.ICacheList.IPs(ctx, aHostname)
+	r.RUnlock()
+	if !ok || (0 == len(ips)) {
+		return nil, fmt.Errorf("%w: %q", ErrCacheMiss, aHostname)
+	}
+
+	return ips, nil
+} // Cached()
+
+// `DeleteAllow()` removes a hostname pattern from the default allow list.
+//
+// Parameters:
+//   - `aPattern`: The FQDN name/pattern to remove.
+//
+// Returns:
+//   - `bool`: `true` if the pattern was found and removed, `false` otherwise.
+func (r *TResolver) DeleteAllow(aPattern string) bool {
+	return r.adlist.DeleteAllow(context.Background(), aPattern)
+} // DeleteAllow()
+
+// `DeleteDeny()` removes a hostname pattern from the default deny list.
+//
+// Parameters:
+//   - `aPattern`: The FQDN name/pattern to remove.
+//
+// Returns:
+//   - `bool`: `true` if the pattern was found and removed, `false` otherwise.
+func (r *TResolver) DeleteDeny(aPattern string) bool {
+	return r.adlist.DeleteDeny(context.Background(), aPattern)
+} // DeleteDeny()
+
+// `DenyPatterns()` returns all patterns of the default deny list.
+//
+// Returns:
+//   - `[]string`: The sorted list of denied patterns.
+func (r *TResolver) DenyPatterns() []string {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second<<2)
+	defer cancel()
+
+	return r.adlist.DenyPatterns(ctx)
+} // DenyPatterns()
+
+// `DenySeq()` returns an iterator over the patterns of the default
+// deny list.
+//
+// Other than [DenyPatterns] the patterns are produced lazily in sorted
+// order, so a part of a huge list can be read without building a list
+// of all patterns. The loop body mustn't change the deny list.
+//
+// Parameters:
+//   - `aCtx`: The context to use for the operation.
+//
+// Returns:
+//   - `iter.Seq[string]`: The iterator over the denied patterns.
+func (r *TResolver) DenySeq(aCtx context.Context) iter.Seq[string] {
+	return r.adlist.DenySeq(aCtx)
+} // DenySeq()
+
+// `FindPatterns()` returns the patterns of the default allow and deny
+// lists matching the given query, e.g. to find out whether and by what
+// rule a hostname is blocked.
+//
+// A query containing wildcards (`*`, `?`, or `[…]`) is matched against
+// the whole pattern, otherwise all patterns containing the query are
+// returned.
+//
+// Parameters:
+//   - `aQuery`: The substring or wildcard to look for.
+//
+// Returns:
+//   - `[]TPatternMatch`: The matching allow patterns followed by the
+//     matching deny patterns.
+func (r *TResolver) FindPatterns(aQuery string) []TPatternMatch {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second<<2)
+	defer cancel()
+
+	found := r.adlist.Find(ctx, aQuery)
+	result := make([]TPatternMatch, 0, len(found))
+	for _, pattern := range found {
+		result = append(result, TPatternMatch{
+			Pattern: pattern.Pattern,
+			Allow:   adl.ADallow == pattern.Origin,
+		})
+	}
+
+	return result
+} // FindPatterns()
+
 // `Fetch()` returns the IP addresses for a given hostname.
 //
+// The resolver's rewrite rules (see [AddRewrite]) are applied first;
+// a CNAME rewrite returns the target's addresses (i.e. the alias
+// chain is flattened).
+//
 // Parameters:
 //   - `aHostname`: The hostname to resolve.
 //
@@ -254,31 +675,94 @@ func (r *TResolver) autoRefresh(aRate time.Duration, aAbort chan struct{}) {
 //   - `[]net.IP`: List of IP addresses for the given hostname.
 //   - `error`: `nil` if the hostname was resolved successfully, the error otherwise.
 func (r *TResolver) Fetch(aHostname string) ([]net.IP, error) {
-	if adl.ADdeny == r.adlist.Match(context.Background(), aHostname) {
-		incMetricsFields(&gMetrics.Lookups, &gMetrics.Hits)
+	return r.fetch(r.adlist, r.safeSearch.Load(), nil, aHostname, 0)
+} // Fetch()
 
-		return append([]net.IP{}, net.IPv4zero), nil
-	}
+// `fetch()` returns the IP addresses for a given hostname checking it
+// against the given allow/deny list.
+//
+// Parameters:
+//   - `aList`: The allow/deny list to check the hostname against.
+//   - `aSafe`: Whether to enforce safe search (see [SetSafeSearch]).
+//   - `aClient`: The requesting client's IP address (may be `nil`).
+//   - `aHostname`: The hostname to resolve.
+//   - `aQType`: The DNS query type to count (`0` means none).
+//
+// Returns:
+//   - `[]net.IP`: List of IP addresses for the given hostname.
+//   - `error`: `nil` if the hostname was resolved successfully, the error otherwise.
+func (r *TResolver) fetch(aList *adl.TADlist, aSafe bool, aClient net.IP, aHostname string, aQType uint16) ([]net.IP, error) {
+	r.queries.Add(aHostname)
 
-	// Use a context with timeout for the entire lookup operation
-	ctx, cancel := context.WithTimeout(context.Background(), defLookupTimeout)
+	// The query's deadline bounds all of the steps below
+	ctx, cancel := context.WithTimeout(context.Background(), r.queryTimeout)
 	defer cancel()
 
-	// Check the local cache
-	r.RLock()
-	ips, ok := r.ICacheList.IPs(ctx, aHostname)
-	r.RUnlock()
+	ips, aHostname, err := r.rewrite(aHostname, aSafe)
+	if nil != err {
+		return nil, err
+	}
+	if 0 < len(ips) {
+		incMetricsFields(&gMetrics.Lookups, &gMetrics.Hits)
+		r.types.count(aQType, true)
 
-	if ok && (0 < len(ips)) {
+		return ips, nil
+	}
+	if ips = r.leases.lookup(aHostname); 0 < len(ips) {
 		incMetricsFields(&gMetrics.Lookups, &gMetrics.Hits)
+		r.types.count(aQType, true)
 
-		// fast path: we've already resolved this hostname
 		return ips, nil
 	}
+
+	if r.audit.Load() {
+		r.auditMatch(ctx, aList, aClient, aHostname)
+	} else if match := aList.Match(ctx, aHostname); (adl.ADdeny == match) ||
+		((nil != aList) && r.adguard.blocks(r.ClientGroup(aClient), aHostname, 0)) ||
+		((nil != aList) && (adl.ADallow != match) && ("" != r.categories.match(ctx, aHostname))) {
+		incMetricsFields(&gMetrics.Lookups, &gMetrics.Hits, &gMetrics.Blocked)
+		r.types.count(aQType, true)
+		r.hooks.onBlocked(aHostname, aClient)
+		r.storms.record(aHostname, aClient)
+
+		return append([]net.IP{}, net.IPv4zero), nil
+	}
+
+	if 0 < r.refresh.window {
+		r.accessed.touch(aHostname)
+	}
+
+	// Check the local cache (unless the hostname must not be cached)
+	if !r.neverCache.match(aHostname) {
+		r.RLock()
+		ips, ok := r.ICacheList.IPs(ctx, aHostname)
+		r.RUnlock()
+
+		if ok && (0 < len(ips)) {
+			incMetricsFields(&gMetrics.Lookups, &gMetrics.Hits)
+			r.types.count(aQType, true)
+
+			// fast path: we've already resolved this hostname
+			return ips, nil
+		}
+	}
 	incMetricsFields(&gMetrics.Misses)
+	r.types.count(aQType, false)
 
-	return r.LookupHost(ctx, aHostname)
-} // Fetch()
+	ips, err = r.LookupHost(ctx, aHostname)
+	if errors.Is(err, ErrCircuitOpen) {
+		// Answer as configured while no upstream server is available
+		return r.breakers.fallback(aHostname, err)
+	}
+	if isNotFound(err) || ((nil == err) && (0 == len(ips))) {
+		// Single-label hostnames may be known within a search domain
+		if found, ok := r.fetchSearch(aList, aSafe, aClient, aHostname); ok {
+			return found, nil
+		}
+	}
+
+	return ips, err
+} // fetch()
 
 // `FetchFirst()` returns the first IP address for a given hostname.
 //
@@ -364,6 +848,21 @@ func (r *TResolver) FetchRandomString(aHostname string) (string, error) {
 	return ip.String(), nil
 } // FetchRandomString()
 
+// `FetchUnfiltered()` returns the IP addresses for a given hostname
+// without checking it against any allow/deny list.
+//
+// Rewrite rules, DHCP leases and the cache are used as with [Fetch].
+//
+// Parameters:
+//   - `aHostname`: The hostname to resolve.
+//
+// Returns:
+//   - `[]net.IP`: List of IP addresses for the given hostname.
+//   - `error`: `nil` if the hostname was resolved successfully, the error otherwise.
+func (r *TResolver) FetchUnfiltered(aHostname string) ([]net.IP, error) {
+	return r.fetch(nil, false, nil, aHostname, 0)
+} // FetchUnfiltered()
+
 // `LoadAllowlist()` loads the allowlist from the given file.
 //
 // Parameters:
@@ -380,6 +879,12 @@ func (r *TResolver) LoadAllowlist(aFilename string) error {
 
 // `LoadBlocklists()` loads the blocklists from the given URLs.
 //
+// The AdGuard rules with `$client`, `$dnstype`, or `$dnsrewrite`
+// modifiers are applied as far as possible (see [BlockedType]).
+//
+// The statistics of each URL's list are logged and available by
+// [BlocklistReport] afterwards.
+//
 // Parameters:
 //   - `aURLs`: The URLs to download the blocklists from.
 //
@@ -389,7 +894,19 @@ func (r *TResolver) LoadBlocklists(aURLs []string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second<<2)
 	defer cancel()
 
-	return r.adlist.LoadDeny(ctx, aURLs)
+	report, err := r.adlist.LoadDeny(ctx, aURLs)
+	r.applyAdGuard("", r.adlist.AdGuardRules())
+	for _, source := range report.Sources {
+		if "" != source.Error {
+			continue // part of the returned error
+		}
+		gLog.Info("blocklist loaded", "source", source.Source,
+			"lines", source.Lines, "added", source.Added,
+			"duplicates", source.Duplicates, "converted", source.Converted,
+			"rejected", source.Rejected, "duration", source.Duration)
+	}
+
+	return err
 } // LoadBlocklists()
 
 // `lookup()` resolves `aHostname` with the given context.
@@ -400,51 +917,76 @@ func (r *TResolver) LoadBlocklists(aURLs []string) error {
 //
 // Returns:
 //   - `[]net.IP`: List of IP addresses for the given hostname.
+//   - `time.Duration`: The upstream TTL of the answer (`0` if unknown).
 //   - `error`: `nil` if the hostname was resolved successfully, the error otherwise.
-func (r *TResolver) lookup(aCtx context.Context, aHostname string) ([]net.IP, error) {
+func (r *TResolver) lookup(aCtx context.Context, aHostname string) ([]net.IP, time.Duration, error) {
+	result, err := r.lookupUpstream(aCtx, aHostname)
+
+	return result.ips, result.ttl, err
+} // lookup()
+
+// `lookupUpstream()` resolves `aHostname` with the given context
+// using the mDNS responders, the configured DNS servers, or the
+// system's resolver.
+//
+// Parameters:
+//   - `aCtx`: Context for the lookup operation.
+//   - `aHostname`: The hostname to resolve.
+//
+// Returns:
+//   - `tLookupResult`: The answer including the answering server.
+//   - `error`: `nil` if the hostname was resolved successfully, the error otherwise.
+func (r *TResolver) lookupUpstream(aCtx context.Context, aHostname string) (tLookupResult, error) {
+	if r.mdns && isMDNSName(aHostname) {
+		// `.local` names must not leak to the unicast DNS servers
+		ips, ttl, err := lookupMDNS(aCtx, aHostname)
+		return tLookupResult{ips: ips, ttl: ttl, server: upstreamMDNS}, err
+	}
+
+	if r.offline.Load() {
+		return tLookupResult{}, ErrOffline
+	}
+
+	tried := false
 	if nil != r.dnsServers {
-		// Resolve the hostname with multiple DNS servers in parallel
-		results := make(chan []net.IP, len(r.dnsServers))
-
-		// Create child context with cancellation control
-		ctx, cancel := context.WithCancel(aCtx)
-		defer cancel() // Always release resources
-
-		var wg sync.WaitGroup
-		for _, server := range r.dnsServers {
-			wg.Add(1)
-			go func(aServer, aHostname string) {
-				defer wg.Done()
-
-				if ips, err := lookupDNS(ctx, aServer, aHostname); nil == err {
-					if 0 < len(ips) {
-						select {
-						case results <- ips:
-							// Successfully sent result
-						case <-ctx.Done():
-							// Context is already canceled, discard result
-							return
-						}
-						// We have a valid result, hence
-						// cancel all other lookups
-						cancel()
-					}
-				}
-			}(server, aHostname)
+		var (
+			result tLookupResult
+			ok     bool
+		)
+		if SelectFastest == r.selection {
+			result, ok, tried = r.lookupFastest(aCtx, aHostname)
+		} else {
+			result, ok, tried = r.lookupParallel(aCtx, aHostname)
 		}
-		wg.Wait()
-		close(results)
-		if ips, ok := <-results; ok {
-			return ips, nil
+		if ok {
+			return result, nil
 		}
 	}
 
 	// Reaching this point of execution means that we have no DNS
 	// servers configured, or that all of them failed. Hence we
 	// fallback to the default resolver.
+	if err := aCtx.Err(); nil != err {
+		// The query's deadline passed already
+		return tLookupResult{server: upstreamSystem}, err
+	}
+	if !r.breakers.allow(upstreamSystem) {
+		if !tried {
+			return tLookupResult{server: upstreamSystem}, ErrCircuitOpen
+		}
+		return tLookupResult{server: upstreamSystem}, errors.New("no DNS server answered")
+	}
 	ips, err := r.resolver.LookupIP(aCtx, "ip", aHostname)
+	switch {
+	case isServerFailure(err) && errors.Is(aCtx.Err(), context.Canceled):
+		r.breakers.release(upstreamSystem)
+	case isServerFailure(err):
+		r.breakers.failure(upstreamSystem)
+	default:
+		r.breakers.success(upstreamSystem)
+	}
 	if nil == err {
-		return ips, nil
+		return tLookupResult{ips: ips, server: upstreamSystem}, nil
 	}
 
 	// Check if it's a "not found" DNS error
@@ -454,8 +996,134 @@ func (r *TResolver) lookup(aCtx context.Context, aHostname string) ([]net.IP, er
 		ips = nil
 	}
 
-	return ips, err
-} // lookup()
+	return tLookupResult{ips: ips, server: upstreamSystem}, err
+} // lookupUpstream()
+
+// `lookupFastest()` resolves a hostname with the DNS server of the
+// lowest latency, trying the slower ones in turn if it fails.
+//
+// Every `latencyProbeInterval` one of the slower servers is queried in
+// the background as well so the choice adapts to network changes.
+//
+// Parameters:
+//   - `aCtx`: Context for the lookup operation.
+//   - `aHostname`: The hostname to resolve.
+//
+// Returns:
+//   - `tLookupResult`: The first non-empty answer.
+//   - `bool`: `true` if a server answered, `false` otherwise.
+//   - `bool`: `true` if any server was queried, `false` otherwise.
+func (r *TResolver) lookupFastest(aCtx context.Context, aHostname string) (tLookupResult, bool, bool) {
+	servers := r.latencies.order(r.dnsServers)
+	if probe := r.latencies.probe(servers); ("" != probe) && r.breakers.allow(probe) {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), latencyPenalty)
+			defer cancel()
+			_, _, _ = r.queryServer(ctx, probe, aHostname)
+		}()
+	}
+
+	tried := false
+	for _, server := range servers {
+		// Skip the servers failing recently
+		if !r.breakers.allow(server) {
+			continue
+		}
+		tried = true
+		ips, ttl, err := r.queryServer(aCtx, server, aHostname)
+		if (nil == err) && (0 < len(ips)) {
+			return tLookupResult{ips: ips, ttl: ttl, server: server}, true, tried
+		}
+		if nil != aCtx.Err() {
+			break
+		}
+	}
+
+	return tLookupResult{}, false, tried
+} // lookupFastest()
+
+// `lookupParallel()` resolves a hostname with all DNS servers in
+// parallel.
+//
+// Parameters:
+//   - `aCtx`: Context for the lookup operation.
+//   - `aHostname`: The hostname to resolve.
+//
+// Returns:
+//   - `tLookupResult`: The first non-empty answer.
+//   - `bool`: `true` if a server answered, `false` otherwise.
+//   - `bool`: `true` if any server was queried, `false` otherwise.
+func (r *TResolver) lookupParallel(aCtx context.Context, aHostname string) (tLookupResult, bool, bool) {
+	results := make(chan tLookupResult, len(r.dnsServers))
+
+	// Create child context with cancellation control
+	ctx, cancel := context.WithCancel(aCtx)
+	defer cancel() // Always release resources
+
+	tried := false
+	var wg sync.WaitGroup
+	for _, server := range r.dnsServers {
+		// Skip the servers failing recently
+		if !r.breakers.allow(server) {
+			continue
+		}
+		tried = true
+		wg.Add(1)
+		go func(aServer, aHostname string) {
+			defer wg.Done()
+
+			if ips, ttl, err := r.queryServer(ctx, aServer, aHostname); nil == err {
+				if 0 < len(ips) {
+					select {
+					case results <- tLookupResult{ips: ips, ttl: ttl, server: aServer}:
+						// Successfully sent result
+					case <-ctx.Done():
+						// Context is already canceled, discard result
+						return
+					}
+					// We have a valid result, hence
+					// cancel all other lookups
+					cancel()
+				}
+			}
+		}(server, aHostname)
+	}
+	wg.Wait()
+	close(results)
+	result, ok := <-results
+
+	return result, ok, tried
+} // lookupParallel()
+
+// `queryServer()` resolves a hostname with a single DNS server,
+// updating the server's circuit breaker and latency.
+//
+// Parameters:
+//   - `aCtx`: Context for the lookup operation.
+//   - `aServer`: The DNS server to query.
+//   - `aHostname`: The hostname to resolve.
+//
+// Returns:
+//   - `[]net.IP`: List of IP addresses for the given hostname.
+//   - `time.Duration`: The answer's TTL.
+//   - `error`: `nil` if the hostname was resolved, the error otherwise.
+func (r *TResolver) queryServer(aCtx context.Context, aServer, aHostname string) ([]net.IP, time.Duration, error) {
+	start := r.clock.Now()
+	ips, ttl, err := lookupDNS(aCtx, aServer, aHostname)
+	switch {
+	case isServerFailure(err) && errors.Is(aCtx.Err(), context.Canceled):
+		// Cancelled since another server answered first
+		r.breakers.release(aServer)
+	case isServerFailure(err):
+		r.breakers.failure(aServer)
+		r.latencies.record(aServer, latencyPenalty)
+	default:
+		r.breakers.success(aServer)
+		r.latencies.record(aServer, r.clock.Now().Sub(start))
+	}
+
+	return ips, ttl, err
+} // queryServer()
 
 // `LookupHost()` resolves a hostname with the given context and
 // caches the result.
@@ -470,11 +1138,12 @@ func (r *TResolver) lookup(aCtx context.Context, aHostname string) ([]net.IP, er
 //
 // Returns:
 //   - `[]net.IP`: List of IP addresses for the given hostname.
-//   - `error`: `nil` if the hostname was resolved successfully, the error otherwise.
+//   - `error`: `nil` if the hostname was resolved successfully, a `TLookupError` or `TBlockedError` otherwise.
 func (r *TResolver) LookupHost(aCtx context.Context, aHostname string) ([]net.IP, error) {
 	var (
 		err error
 		ips []net.IP
+		ttl time.Duration
 	)
 
 	// Try to resolve the hostname several times
@@ -483,32 +1152,42 @@ func (r *TResolver) LookupHost(aCtx context.Context, aHostname string) ([]net.IP
 		select {
 		case <-aCtx.Done():
 			// No metrics data to update yet
-			return nil, aCtx.Err()
+			return nil, &TLookupError{Hostname: aHostname, Err: aCtx.Err()}
 
 		default:
 			// Continue with lookup
 		}
 
-		if ips, err = r.lookup(aCtx, aHostname); nil == err {
+		if ips, ttl, err = r.lookup(aCtx, aHostname); nil == err {
 			// Update metrics
 			if 0 < loop {
 				incMetricsFields(&gMetrics.Retries)
 			}
 			break // lookup succeeded
 		}
+		if errors.Is(err, ErrCircuitOpen) {
+			break // no server left to retry
+		}
 
 		select {
 		case <-aCtx.Done():
 			if 0 < loop {
 				incMetricsFields(&gMetrics.Retries)
 			}
-			return nil, aCtx.Err()
+			return nil, &TLookupError{Hostname: aHostname, Err: aCtx.Err()}
 
 		default:
 			runtime.Gosched() // yield to other goroutines
 		}
 	} // for loop
 
+	if nil == err {
+		if ips, err = r.filterRebindIPs(aHostname, ips); nil == err {
+			ips, err = r.filterBlockedIPs(aHostname, ips)
+		}
+	} else {
+		err = &TLookupError{Hostname: aHostname, Err: err}
+	}
 	if nil != err {
 		incMetricsFields(&gMetrics.Lookups, &gMetrics.Errors)
 		return nil, err
@@ -517,11 +1196,17 @@ func (r *TResolver) LookupHost(aCtx context.Context, aHostname string) ([]net.IP
 	// Update metrics
 	incMetricsFields(&gMetrics.Lookups)
 
+	if r.neverCache.match(aHostname) {
+		return ips, nil
+	}
+
 	// Cache the result
+	ttl = r.ttlPolicy.ttl(aHostname, ttl)
 	r.Lock()
-	r.ICacheList.Create(aCtx, aHostname, ips, r.ttl)
+	r.ICacheList.Create(aCtx, aHostname, ips, ttl)
 	setMetricsFieldMax(&gMetrics.Peak, uint32(r.ICacheList.Len())) //#nosec G115
 	r.Unlock()
+	r.publishSync(TSyncEvent{Kind: SyncEntry, Hostname: aHostname, IPs: ips, TTL: ttl})
 
 	return ips, nil
 } // LookupHost()
@@ -534,54 +1219,58 @@ func (r *TResolver) Metrics() (rMetrics *TMetrics) {
 	return gMetrics.clone()
 } // Metrics()
 
-// `Refresh()` resolves all cached hostnames and updates the cache.
+// `PatchLists()` applies a list diff to the default allow and deny
+// lists instead of reloading them completely.
 //
-// This method is called automatically if a refresh interval was
-// specified in the `New()` constructor.
-func (r *TResolver) Refresh() {
-	var dnsErr *net.DNSError
-
-	// Use a context with timeout for the entire refresh operation
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute<<2)
+// Each line of the diff holds a pattern to add (prefixed by `+`) or
+// to remove (prefixed by `-`); allow patterns are additionally
+// prefixed by `@@`. Empty lines and comments (starting with `#`) are
+// ignored.
+//
+// Parameters:
+//   - `aReader`: The reader to read the diff from.
+//
+// Returns:
+//   - `error`: `nil` if all changes were applied, the error otherwise.
+func (r *TResolver) PatchLists(aReader io.Reader) error {
+	added, removed, err := adl.ReadDiff(aReader)
+	if nil != err {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second<<2)
 	defer cancel()
 
-	// Use a rate limiter to avoid overwhelming DNS servers
-	limiter := time.NewTicker(time.Second << 1)
-	defer limiter.Stop()
+	return r.adlist.Patch(ctx, added, removed)
+} // PatchLists()
 
-	r.RLock()
-	// This is a shallow clone, the new keys and values
-	// are set using ordinary assignment:
-	cacheList := r.ICacheList.Clone()
-	r.RUnlock()
+// `PruneDenyPatterns()` removes the patterns of the default deny list
+// which didn't block any hostname during the given time (see
+// [DenyHitCounts]).
+//
+// Parameters:
+//   - `aOlderThan`: The time since the patterns' last match.
+//
+// Returns:
+//   - `int`: The number of removed patterns.
+func (r *TResolver) PruneDenyPatterns(aOlderThan time.Duration) int {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second<<4)
+	defer cancel()
 
-	for hostname := range cacheList.Range(ctx) {
-		select {
-		case <-ctx.Done():
-			return // Context timeout or cancellation
-		case <-limiter.C:
-			// Lookup the hostname:
-			_, err := r.LookupHost(ctx, hostname)
-			if nil != err {
-				if errors.As(err, &dnsErr) {
-					if dnsErr.IsNotFound {
-						// We'e working on a (possibly outdated) copy
-						// of the cache, but we delete the non-existing
-						// host from our original cache:
-						r.Lock()
-						r.ICacheList.Delete(ctx, hostname)
-						r.Unlock()
-					}
-				}
-			}
-			runtime.Gosched() // yield to other goroutines
-		}
-	}
+	return r.adlist.PruneUnused(ctx, aOlderThan)
+} // PruneDenyPatterns()
 
-	//
-	//TODO: Reload allow and deny lists
-	//
-} // Refresh()
+// `SetLoadProgress()` sets the function called while
+// [LoadBlocklists] loads the blocklists.
+//
+// The function is called when a blocklist's loading starts, every
+// 10,000 lines read, and when the blocklist is done; it must be safe
+// for concurrent use.
+//
+// Parameters:
+//   - `aFunc`: The function to call, `nil` to remove it.
+func (r *TResolver) SetLoadProgress(aFunc TProgressFunc) {
+	r.adlist.SetProgress(aFunc)
+} // SetLoadProgress()
 
 // `StopExpire()` stops the background expiration goroutine if it's running.
 //
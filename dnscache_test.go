@@ -9,10 +9,18 @@ package dnscache
 import (
 	"bytes"
 	"context"
+	"errors"
 	"net"
 	"slices"
+	"strings"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/mwat56/dnscache/cache"
+	"github.com/mwat56/dnscache/clock"
+	adl "github.com/mwat56/dnscache/internal/adlist"
+	"github.com/mwat56/dnscache/internal/workload"
 )
 
 //lint:file-ignore ST1017 - I prefer Yoda conditions
@@ -190,6 +198,215 @@ func Test_NewWithOptions(t *testing.T) {
 	}
 } // Test_NewWithOptions()
 
+func Test_NewWithOptions_Clock(t *testing.T) {
+	ctx := context.TODO()
+	clk := clock.NewManual(time.Now())
+	r := NewWithOptions(TResolverOptions{
+		Clock:   clk,
+		DataDir: t.TempDir(),
+	})
+	defer r.StopExpire()
+
+	r.ICacheList.Create(ctx, "cached.test", []net.IP{net.ParseIP("192.0.2.1")}, time.Minute)
+	r.accessed.touch("cached.test")
+	r.PauseBlocking(time.Minute)
+
+	clk.Advance(30 * time.Second)
+	if _, ok := r.ICacheList.IPs(ctx, "cached.test"); !ok {
+		t.Error("IPs() = 'false', want 'true'")
+	}
+	if got := r.BlockingPausedUntil(); got.IsZero() {
+		t.Error("BlockingPausedUntil() = zero time, want end of pause")
+	}
+
+	clk.Advance(time.Minute)
+	if _, ok := r.ICacheList.IPs(ctx, "cached.test"); ok {
+		t.Error("IPs() = 'true', want 'false'")
+	}
+	if got := r.BlockingPausedUntil(); !got.IsZero() {
+		t.Errorf("BlockingPausedUntil() = '%v', want zero time", got)
+	}
+	if got := r.accessed.active(clk.Now().Add(-time.Minute)); 0 != len(got) {
+		t.Errorf("active() = '%v', want none", got)
+	}
+} // Test_NewWithOptions_Clock()
+
+func Test_NewWithOptions_ColdStore(t *testing.T) {
+	ctx := context.TODO()
+	store, err := cache.NewDiskStore(t.TempDir())
+	if nil != err {
+		t.Fatalf("NewDiskStore() error = '%v'", err)
+	}
+	defer store.Close()
+
+	r := NewWithOptions(TResolverOptions{
+		ColdStore:     store,
+		DataDir:       t.TempDir(),
+		MaxHotEntries: 1,
+	})
+	defer r.StopExpire()
+
+	ips := []net.IP{net.ParseIP("192.0.2.1")}
+	r.ICacheList.Create(ctx, "first.test", ips, time.Hour)
+	r.ICacheList.Create(ctx, "second.test", ips, time.Hour)
+	if got := store.Len(); 1 != got {
+		t.Errorf("store.Len() = '%d', want '1'", got)
+	}
+	if got := r.ICacheList.Len(); 2 != got {
+		t.Errorf("Len() = '%d', want '2'", got)
+	}
+	if got, err := r.Cached("first.test"); (nil != err) || !ips[0].Equal(got[0]) {
+		t.Errorf("Cached() = '%v', '%v', want '%v'", got, err, ips)
+	}
+} // Test_NewWithOptions_ColdStore()
+
+func Test_TResolver_AddDeny(t *testing.T) {
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir()})
+	defer r.StopExpire()
+
+	tests := []struct {
+		name     string
+		allow    string
+		deny     string
+		hostname string
+		wantErr  bool
+		want     net.IP
+	}{
+		/* */
+		{
+			name:    "01 - invalid pattern",
+			deny:    "",
+			wantErr: true,
+		},
+		{
+			name:     "02 - deny pattern",
+			deny:     "*.ads.tld",
+			hostname: "banner.ads.tld",
+			want:     net.IPv4zero,
+		},
+		{
+			name:     "03 - allow pattern",
+			allow:    "banner.ads.tld",
+			hostname: "popup.ads.tld",
+			want:     net.IPv4zero,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var err error
+			if "" != tc.allow {
+				err = r.AddAllow(tc.allow)
+			} else {
+				err = r.AddDeny(tc.deny)
+			}
+			if (nil != err) != tc.wantErr {
+				t.Errorf("AddDeny() error = '%v', wantErr '%v'", err, tc.wantErr)
+				return
+			}
+			if tc.wantErr {
+				return
+			}
+			got, err := r.Fetch(tc.hostname)
+			if nil != err {
+				t.Errorf("Fetch() error = '%v'", err)
+				return
+			}
+			if (1 != len(got)) || !got[0].Equal(tc.want) {
+				t.Errorf("Fetch() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+
+	if got := r.adlist.Match(context.TODO(), "banner.ads.tld"); adl.ADallow != got {
+		t.Errorf("TADlist.Match() = '%v', want '%v'", got, adl.ADallow)
+	}
+} // Test_TResolver_AddDeny()
+
+func Test_TResolver_DeleteDeny(t *testing.T) {
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir()})
+	defer r.StopExpire()
+	_ = r.AddDeny("*.ads.tld")
+	_ = r.AddDeny("tracker.tld")
+	_ = r.AddAllow("www.ads.tld")
+
+	if got, want := r.DenyPatterns(), []string{"*.ads.tld", "tracker.tld"}; !slices.Equal(got, want) {
+		t.Errorf("DenyPatterns() = '%v', want '%v'", got, want)
+	}
+	if got, want := r.AllowPatterns(), []string{"www.ads.tld"}; !slices.Equal(got, want) {
+		t.Errorf("AllowPatterns() = '%v', want '%v'", got, want)
+	}
+
+	tests := []struct {
+		name    string
+		allow   bool
+		pattern string
+		want    bool
+	}{
+		/* */
+		{
+			name:    "01 - unknown pattern",
+			pattern: "unknown.tld",
+			want:    false,
+		},
+		{
+			name:    "02 - deny pattern",
+			pattern: "*.ads.tld",
+			want:    true,
+		},
+		{
+			name:    "03 - deleted pattern",
+			pattern: "*.ads.tld",
+			want:    false,
+		},
+		{
+			name:    "04 - allow pattern",
+			allow:   true,
+			pattern: "www.ads.tld",
+			want:    true,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got bool
+			if tc.allow {
+				got = r.DeleteAllow(tc.pattern)
+			} else {
+				got = r.DeleteDeny(tc.pattern)
+			}
+			if got != tc.want {
+				t.Errorf("DeleteDeny() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+
+	if got, want := r.DenyPatterns(), []string{"tracker.tld"}; !slices.Equal(got, want) {
+		t.Errorf("DenyPatterns() = '%v', want '%v'", got, want)
+	}
+} // Test_TResolver_DeleteDeny()
+
+func Test_TResolver_FindPatterns(t *testing.T) {
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir()})
+	defer r.StopExpire()
+	_ = r.AddDeny("*.ads.tld")
+	_ = r.AddDeny("tracker.tld")
+	_ = r.AddAllow("www.ads.tld")
+
+	want := []TPatternMatch{
+		{Pattern: "www.ads.tld", Allow: true},
+		{Pattern: "*.ads.tld", Allow: false},
+	}
+	if got := r.FindPatterns("ads"); !slices.Equal(got, want) {
+		t.Errorf("FindPatterns() = '%v', want '%v'", got, want)
+	}
+	if got := r.FindPatterns("unknown"); 0 != len(got) {
+		t.Errorf("FindPatterns() = '%v', want '[]'", got)
+	}
+} // Test_TResolver_FindPatterns()
+
 func Test_TResolver_Fetch(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -393,6 +610,60 @@ func Test_TResolver_FetchRandomString(t *testing.T) {
 	}
 } // Test_TResolver_FetchRandomString()
 
+func Test_TResolver_FetchUnfiltered(t *testing.T) {
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir()})
+	defer r.StopExpire()
+	_ = r.AddDeny("ads.example.com")
+	ips := []net.IP{net.ParseIP("192.0.2.1")}
+	r.ICacheList.Create(context.TODO(), "ads.example.com", ips, time.Hour)
+
+	if got, _ := r.Fetch("ads.example.com"); !slices.EqualFunc(got, []net.IP{net.IPv4zero}, net.IP.Equal) {
+		t.Errorf("Fetch() = '%v', want '%v'", got, net.IPv4zero)
+	}
+	got, err := r.FetchUnfiltered("ads.example.com")
+	if nil != err {
+		t.Fatalf("FetchUnfiltered() error = '%v'", err)
+	}
+	if !slices.EqualFunc(got, ips, net.IP.Equal) {
+		t.Errorf("FetchUnfiltered() = '%v', want '%v'", got, ips)
+	}
+} // Test_TResolver_FetchUnfiltered()
+
+func Test_TResolver_FetchTimeout(t *testing.T) {
+	const budget = 200 * time.Millisecond
+
+	// An upstream server swallowing all queries
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	r := NewWithOptions(TResolverOptions{
+		DataDir:      t.TempDir(),
+		DNSservers:   []string{conn.LocalAddr().String()},
+		QueryTimeout: budget,
+		Resolver: &net.Resolver{
+			PreferGo: true,
+			Dial: func(aCtx context.Context, _, _ string) (net.Conn, error) {
+				<-aCtx.Done()
+				return nil, aCtx.Err()
+			},
+		},
+	})
+	defer r.StopExpire()
+
+	start := time.Now()
+	_, err = r.Fetch("slow.test")
+	elapsed := time.Since(start)
+	if !errors.Is(err, ErrUpstreamTimeout) {
+		t.Errorf("Fetch() error = '%v', want '%v'", err, ErrUpstreamTimeout)
+	}
+	if budget<<2 < elapsed {
+		t.Errorf("Fetch() took '%v', want about '%v'", elapsed, budget)
+	}
+} // Test_TResolver_FetchTimeout()
+
 func Test_TResolver_lookup(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -456,7 +727,7 @@ func Test_TResolver_lookup(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.setup(tc.resolver)
-			gotIPs, err := tc.resolver.lookup(context.TODO(), tc.hostname)
+			gotIPs, _, err := tc.resolver.lookup(context.TODO(), tc.hostname)
 
 			if (err != nil) != tc.wantErr {
 				t.Errorf("TResolver.lookup() error = '%v', wantErr '%v'",
@@ -491,6 +762,43 @@ func Test_TResolver_lookup(t *testing.T) {
 	}
 } // Test_TResolver_lookup()
 
+func Test_TResolver_PatchLists(t *testing.T) {
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir()})
+	defer r.StopExpire()
+	_ = r.AddDeny("old.tld")
+
+	tests := []struct {
+		name      string
+		diff      string
+		wantAllow []string
+		wantDeny  []string
+		wantErr   bool
+	}{
+		/* */
+		{"01 - empty", "# nothing\n", nil, []string{"old.tld"}, false},
+		{"02 - changes", "-old.tld\n+new.tld\n+@@www.new.tld\n",
+			[]string{"www.new.tld"}, []string{"new.tld"}, false},
+		{"03 - malformed", "new.tld\n", []string{"www.new.tld"}, []string{"new.tld"}, true},
+		{"04 - unknown pattern", "-old.tld\n", []string{"www.new.tld"}, []string{"new.tld"}, true},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := r.PatchLists(strings.NewReader(tc.diff))
+			if (nil != err) != tc.wantErr {
+				t.Errorf("PatchLists() error = '%v', wantErr '%v'", err, tc.wantErr)
+			}
+			if got := r.AllowPatterns(); !slices.Equal(got, tc.wantAllow) {
+				t.Errorf("AllowPatterns() = '%v', want '%v'", got, tc.wantAllow)
+			}
+			if got := r.DenyPatterns(); !slices.Equal(got, tc.wantDeny) {
+				t.Errorf("DenyPatterns() = '%v', want '%v'", got, tc.wantDeny)
+			}
+		})
+	}
+} // Test_TResolver_PatchLists()
+
 func Test_TResolver_Refresh(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -600,6 +908,11 @@ func Test_validateDNSServers(t *testing.T) {
 			servers:  []string{"invalid1", "invalid2"},
 			expected: []string{},
 		},
+		{
+			name:     "05 - with ports",
+			servers:  []string{"127.0.0.1:5353", "[::1]:53", "::1", "host:53", "127.0.0.1:0"},
+			expected: []string{"127.0.0.1:5353", "[::1]:53", "::1"},
+		},
 	}
 
 	for _, tc := range tests {
@@ -613,4 +926,77 @@ func Test_validateDNSServers(t *testing.T) {
 	}
 } // Test_validateDNSServers()
 
+// `benchResolver()` creates a resolver prepared for the given workload.
+//
+// The workload's cached names are put into the cache, its blocked
+// names get denied, and all misses go to a local fake upstream.
+func benchResolver(b *testing.B, aWorkload *workload.TWorkload) *TResolver {
+	b.Helper()
+
+	upstream, err := workload.StartUpstream()
+	if nil != err {
+		b.Fatalf("StartUpstream() error = '%v'", err)
+	}
+	b.Cleanup(func() { _ = upstream.Close() })
+
+	r := NewWithOptions(TResolverOptions{
+		DataDir:  b.TempDir(),
+		Resolver: workload.Resolver(upstream.LocalAddr().String()),
+	})
+	b.Cleanup(func() { r.StopExpire() })
+	r.dnsServers = nil // use the fake upstream only
+	r.retries = 1
+
+	ip := []net.IP{net.ParseIP("192.0.2.2")}
+	for _, name := range aWorkload.CachedNames() {
+		r.ICacheList.Create(context.TODO(), name, ip, time.Hour)
+	}
+	if err := r.AddDeny(workload.BlockedPattern); nil != err {
+		b.Fatalf("AddDeny() error = '%v'", err)
+	}
+
+	return r
+} // benchResolver()
+
+func Benchmark_TResolver_Fetch(b *testing.B) {
+	benchmarks := []struct {
+		name    string
+		options workload.TOptions
+	}{
+		/* */
+		{"01 - all hits", workload.TOptions{HitRatio: 1}},
+		{"02 - 90% hits", workload.TOptions{HitRatio: 0.9}},
+		{"03 - 20% blocked", workload.TOptions{HitRatio: 0.9, Blocked: 0.2}},
+		{"04 - all misses", workload.TOptions{HitRatio: 0}},
+		/* */
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			bm.options.Seed = 1
+			w, err := workload.New(bm.options)
+			if nil != err {
+				b.Fatalf("workload.New() error = '%v'", err)
+			}
+			r := benchResolver(b, w)
+
+			var mtx sync.Mutex
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				mtx.Lock()
+				worker := w.Clone()
+				mtx.Unlock()
+
+				for pb.Next() {
+					if _, err := r.Fetch(worker.Next()); nil != err {
+						b.Errorf("Fetch() error = '%v'", err)
+						return
+					}
+				}
+			})
+		})
+	}
+} // Benchmark_TResolver_Fetch()
+
 /* _EoF_ */
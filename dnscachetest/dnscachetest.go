@@ -0,0 +1,410 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+
+// Package `dnscachetest` provides helpers to write tests using a
+// `dnscache.TResolver` without depending on real DNS servers:
+//
+//   - `TClock` is a manually advanced clock (see `clock.TManual`),
+//   - `TUpstream` is an in-memory DNS server answering seeded records,
+//   - `NewResolver()` returns a resolver using only such an upstream,
+//   - `StartTestServer()` starts an ephemeral DNS server in front of
+//     such a resolver.
+//
+// Example:
+//
+//	func TestMyClient(t *testing.T) {
+//		srv := dnscachetest.StartTestServer(t)
+//		srv.Upstream.Add("api.example.com", time.Minute, net.ParseIP("192.0.2.10"))
+//
+//		client := newMyClient(srv.Address()) // sends its queries to the test server
+//		…
+//		srv.Clock.Advance(2 * time.Minute) // the record has expired now
+//	}
+package dnscachetest
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mwat56/dnscache"
+	"github.com/mwat56/dnscache/clock"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `TClock` is a clock that only moves when told so.
+	TClock = clock.TManual
+
+	// `tRecord` is a seeded record of the upstream server.
+	tRecord struct {
+		ips     []net.IP
+		expires time.Time // zero means never
+	}
+
+	// `TUpstream` is an in-memory DNS server answering A and AAAA
+	// queries for the seeded records (and NXDOMAIN for all others).
+	TUpstream struct {
+		mtx     sync.RWMutex
+		clock   *TClock
+		conn    net.PacketConn
+		records map[string]tRecord
+		queries atomic.Uint64
+	}
+
+	// `TTestServer` is an ephemeral DNS server answering queries
+	// with a resolver that uses an in-memory upstream.
+	TTestServer struct {
+		Clock    *TClock             // the resolver's and upstream's clock
+		Resolver *dnscache.TResolver // the resolver answering the queries
+		Upstream *TUpstream          // the resolver's upstream server
+		conn     net.PacketConn
+	}
+)
+
+const (
+	// `answerTTL` is the TTL of the test server's answers.
+	answerTTL = 60
+)
+
+var (
+	// `defStart` is the default start time of a `TClock`.
+	defStart = time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `answer()` creates the response to a DNS query.
+//
+// Parameters:
+//   - `aQuery`: The DNS query.
+//   - `aLookup`: The function returning the addresses and the TTL of
+//     a hostname, or the response code to use instead.
+//
+// Returns:
+//   - `[]byte`: The response, `nil` if the query is malformed.
+func answer(aQuery []byte,
+	aLookup func(aHostname string) ([]net.IP, uint32, dnsmessage.RCode)) []byte {
+	var parser dnsmessage.Parser
+	header, err := parser.Start(aQuery)
+	if nil != err {
+		return nil
+	}
+	question, err := parser.Question()
+	if nil != err {
+		return nil
+	}
+
+	header.Response, header.RecursionAvailable = true, true
+	header.Authoritative = false
+	var (
+		answers []net.IP
+		ttl     uint32
+	)
+	switch question.Type {
+	case dnsmessage.TypeA, dnsmessage.TypeAAAA:
+		hostname := strings.TrimSuffix(question.Name.String(), ".")
+		var ips []net.IP
+		ips, ttl, header.RCode = aLookup(strings.ToLower(hostname))
+		for _, ip := range ips {
+			if (nil != ip.To4()) == (dnsmessage.TypeA == question.Type) {
+				answers = append(answers, ip)
+			}
+		}
+	default:
+		header.RCode = dnsmessage.RCodeNotImplemented
+	}
+
+	builder := dnsmessage.NewBuilder(nil, header)
+	builder.EnableCompression()
+	_ = builder.StartQuestions()
+	_ = builder.Question(question)
+	_ = builder.StartAnswers()
+	rh := dnsmessage.ResourceHeader{Name: question.Name, Class: dnsmessage.ClassINET, TTL: ttl}
+	for _, ip := range answers {
+		if ip4 := ip.To4(); nil != ip4 {
+			_ = builder.AResource(rh, dnsmessage.AResource{A: [4]byte(ip4)})
+		} else {
+			_ = builder.AAAAResource(rh, dnsmessage.AAAAResource{AAAA: [16]byte(ip.To16())})
+		}
+	}
+	result, err := builder.Finish()
+	if nil != err {
+		return nil
+	}
+
+	return result
+} // answer()
+
+// `listen()` opens a UDP socket on the loopback interface and answers
+// the queries received until the test is finished.
+//
+// Parameters:
+//   - `aTB`: The current test.
+//   - `aHandler`: The function creating the response to a query.
+//
+// Returns:
+//   - `net.PacketConn`: The socket opened.
+func listen(aTB testing.TB, aHandler func(aQuery []byte) []byte) net.PacketConn {
+	aTB.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if nil != err {
+		aTB.Fatalf("dnscachetest: failed to listen: %v", err)
+	}
+	aTB.Cleanup(func() { _ = conn.Close() })
+
+	go func() {
+		buffer := make([]byte, 1232)
+		for {
+			n, addr, err := conn.ReadFrom(buffer)
+			if nil != err {
+				if errors.Is(err, net.ErrClosed) {
+					return
+				}
+				continue
+			}
+			if response := aHandler(buffer[:n]); nil != response {
+				_, _ = conn.WriteTo(response, addr)
+			}
+		}
+	}()
+
+	return conn
+} // listen()
+
+// `NewClock()` returns a clock set to the given time.
+//
+// Parameters:
+//   - `aStart`: The clock's time, zero means 2025-01-01 00:00 UTC.
+//
+// Returns:
+//   - `*TClock`: The new clock.
+func NewClock(aStart time.Time) *TClock {
+	if aStart.IsZero() {
+		aStart = defStart
+	}
+
+	return clock.NewManual(aStart)
+} // NewClock()
+
+// `NewResolver()` returns a resolver that sends all its lookups to
+// the given upstream server and uses the upstream's clock.
+//
+// The resolver's background goroutines are stopped when the test
+// is finished.
+//
+// Parameters:
+//   - `aTB`: The current test.
+//   - `aUpstream`: The upstream server to use.
+//
+// Returns:
+//   - `*dnscache.TResolver`: The new resolver.
+func NewResolver(aTB testing.TB, aUpstream *TUpstream) *dnscache.TResolver {
+	aTB.Helper()
+
+	address := aUpstream.Address()
+	resolver := dnscache.NewWithOptions(dnscache.TResolverOptions{
+		Clock:      aUpstream.clock,
+		DNSservers: []string{address},
+		DataDir:    aTB.TempDir(),
+		MaxRetries: 1,
+		// The fallback must not leave the test either
+		Resolver: &net.Resolver{
+			PreferGo: true,
+			Dial: func(aCtx context.Context, aNetwork, _ string) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(aCtx, "udp", address)
+			},
+		},
+	})
+	aTB.Cleanup(func() { resolver.StopRefresh().StopExpire() })
+
+	return resolver
+} // NewResolver()
+
+// `StartTestServer()` starts an ephemeral DNS server (on a random
+// loopback port) answering A and AAAA queries with a resolver that
+// uses an in-memory upstream server.
+//
+// The records to answer are added to the server's `Upstream`, and
+// the server is stopped when the test is finished.
+//
+// Parameters:
+//   - `aTB`: The current test.
+//
+// Returns:
+//   - `*TTestServer`: The running server.
+func StartTestServer(aTB testing.TB) *TTestServer {
+	aTB.Helper()
+
+	clock := NewClock(time.Time{})
+	upstream := StartUpstream(aTB, clock)
+	result := &TTestServer{
+		Clock:    clock,
+		Resolver: NewResolver(aTB, upstream),
+		Upstream: upstream,
+	}
+	result.conn = listen(aTB, func(aQuery []byte) []byte {
+		return answer(aQuery, result.lookup)
+	})
+
+	return result
+} // StartTestServer()
+
+// `StartUpstream()` starts an in-memory DNS server (on a random
+// loopback port) that is stopped when the test is finished.
+//
+// Parameters:
+//   - `aTB`: The current test.
+//   - `aClock`: The clock deciding about the records' expiry (`nil` means a new one).
+//
+// Returns:
+//   - `*TUpstream`: The running server.
+func StartUpstream(aTB testing.TB, aClock *TClock) *TUpstream {
+	aTB.Helper()
+
+	if nil == aClock {
+		aClock = NewClock(time.Time{})
+	}
+	result := &TUpstream{
+		clock:   aClock,
+		records: make(map[string]tRecord),
+	}
+	result.conn = listen(aTB, func(aQuery []byte) []byte {
+		result.queries.Add(1)
+		return answer(aQuery, result.lookup)
+	})
+
+	return result
+} // StartUpstream()
+
+// ---------------------------------------------------------------------------
+// `TTestServer` methods:
+
+// `Address()` returns the server's UDP address (`host:port`).
+//
+// Returns:
+//   - `string`: The address to send queries to.
+func (s *TTestServer) Address() string {
+	return s.conn.LocalAddr().String()
+} // Address()
+
+// `lookup()` resolves a hostname with the server's resolver.
+//
+// Parameters:
+//   - `aHostname`: The hostname to resolve.
+//
+// Returns:
+//   - `[]net.IP`: The hostname's addresses.
+//   - `uint32`: The answer's TTL (in seconds).
+//   - `dnsmessage.RCode`: The response code.
+func (s *TTestServer) lookup(aHostname string) ([]net.IP, uint32, dnsmessage.RCode) {
+	ips, err := s.Resolver.Fetch(aHostname)
+	if nil == err {
+		return ips, answerTTL, dnsmessage.RCodeSuccess
+	}
+	if dnsErr := (*net.DNSError)(nil); errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return nil, 0, dnsmessage.RCodeNameError
+	}
+
+	return nil, 0, dnsmessage.RCodeServerFailure
+} // lookup()
+
+// ---------------------------------------------------------------------------
+// `TUpstream` methods:
+
+// `Add()` adds (or replaces) the addresses of a hostname.
+//
+// Parameters:
+//   - `aHostname`: The hostname to answer.
+//   - `aTTL`: The record's time to live, `0` means forever.
+//   - `aIPs`: The hostname's addresses.
+//
+// Returns:
+//   - `*TUpstream`: The upstream server itself (for chaining).
+func (u *TUpstream) Add(aHostname string, aTTL time.Duration, aIPs ...net.IP) *TUpstream {
+	record := tRecord{ips: aIPs}
+	if 0 < aTTL {
+		record.expires = u.clock.Now().Add(aTTL)
+	}
+
+	u.mtx.Lock()
+	u.records[strings.ToLower(strings.TrimSuffix(aHostname, "."))] = record
+	u.mtx.Unlock()
+
+	return u
+} // Add()
+
+// `Address()` returns the server's UDP address (`host:port`).
+//
+// Returns:
+//   - `string`: The address to send queries to.
+func (u *TUpstream) Address() string {
+	return u.conn.LocalAddr().String()
+} // Address()
+
+// `Delete()` removes a hostname.
+//
+// Parameters:
+//   - `aHostname`: The hostname to remove.
+//
+// Returns:
+//   - `*TUpstream`: The upstream server itself (for chaining).
+func (u *TUpstream) Delete(aHostname string) *TUpstream {
+	u.mtx.Lock()
+	delete(u.records, strings.ToLower(strings.TrimSuffix(aHostname, ".")))
+	u.mtx.Unlock()
+
+	return u
+} // Delete()
+
+// `lookup()` returns the seeded addresses of a hostname.
+//
+// Parameters:
+//   - `aHostname`: The (lower-case) hostname to look up.
+//
+// Returns:
+//   - `[]net.IP`: The hostname's addresses.
+//   - `uint32`: The record's remaining TTL (in seconds).
+//   - `dnsmessage.RCode`: The response code.
+func (u *TUpstream) lookup(aHostname string) ([]net.IP, uint32, dnsmessage.RCode) {
+	u.mtx.RLock()
+	record, ok := u.records[aHostname]
+	u.mtx.RUnlock()
+	if !ok {
+		return nil, 0, dnsmessage.RCodeNameError
+	}
+	if record.expires.IsZero() {
+		return record.ips, answerTTL, dnsmessage.RCodeSuccess
+	}
+
+	remaining := record.expires.Sub(u.clock.Now())
+	if 0 >= remaining {
+		return nil, 0, dnsmessage.RCodeNameError
+	}
+
+	return record.ips, uint32(max(remaining/time.Second, 1)), dnsmessage.RCodeSuccess //#nosec G115
+} // lookup()
+
+// `Queries()` returns the number of queries received so far.
+//
+// Returns:
+//   - `uint64`: The number of queries.
+func (u *TUpstream) Queries() uint64 {
+	return u.queries.Load()
+} // Queries()
+
+/* _EoF_ */
@@ -0,0 +1,144 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscachetest
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// `clientResolver()` returns a resolver sending all lookups to the
+// given address.
+func clientResolver(aAddress string) *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(aCtx context.Context, aNetwork, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(aCtx, "udp", aAddress)
+		},
+	}
+} // clientResolver()
+
+func Test_TClock(t *testing.T) {
+	clock := NewClock(time.Time{})
+	if got := clock.Now(); !got.Equal(defStart) {
+		t.Errorf("NewClock() = '%v', want '%v'", got, defStart)
+	}
+
+	clock.Advance(time.Hour)
+	if got, want := clock.Now(), defStart.Add(time.Hour); !got.Equal(want) {
+		t.Errorf("Advance() = '%v', want '%v'", got, want)
+	}
+
+	want := time.Date(2030, time.June, 1, 12, 0, 0, 0, time.UTC)
+	clock.Set(want)
+	if got := clock.Now(); !got.Equal(want) {
+		t.Errorf("Set() = '%v', want '%v'", got, want)
+	}
+} // Test_TClock()
+
+func Test_StartUpstream(t *testing.T) {
+	clock := NewClock(time.Time{})
+	upstream := StartUpstream(t, clock).
+		Add("forever.test", 0, net.ParseIP("192.0.2.1"), net.ParseIP("2001:db8::1")).
+		Add("Short.Test.", time.Minute, net.ParseIP("192.0.2.2")).
+		Add("deleted.test", 0, net.ParseIP("192.0.2.3")).
+		Delete("deleted.test")
+	resolver := clientResolver(upstream.Address())
+
+	tests := []struct {
+		name    string
+		host    string
+		advance time.Duration
+		want    int
+		wantErr bool
+	}{
+		/* */
+		{"01 - A and AAAA", "forever.test", 0, 2, false},
+		{"02 - case-insensitive", "short.test", 0, 1, false},
+		{"03 - unknown", "unknown.test", 0, 0, true},
+		{"04 - deleted", "deleted.test", 0, 0, true},
+		{"05 - expired", "short.test", 2 * time.Minute, 0, true},
+		{"06 - never expires", "forever.test", 0, 2, false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			clock.Advance(tc.advance)
+			got, err := resolver.LookupIP(context.Background(), "ip", tc.host)
+			if (nil != err) != tc.wantErr {
+				t.Fatalf("LookupIP() error = '%v', wantErr '%v'", err, tc.wantErr)
+			}
+			if len(got) != tc.want {
+				t.Errorf("LookupIP() = '%v', want '%d' addresses", got, tc.want)
+			}
+		})
+	}
+
+	if 0 == upstream.Queries() {
+		t.Error("Queries() = '0', want > '0'")
+	}
+} // Test_StartUpstream()
+
+func Test_NewResolver(t *testing.T) {
+	upstream := StartUpstream(t, nil).
+		Add("seeded.test", 0, net.ParseIP("192.0.2.4"))
+	resolver := NewResolver(t, upstream)
+
+	got, err := resolver.FetchFirstString("seeded.test")
+	if nil != err {
+		t.Fatalf("FetchFirstString() error = '%v'", err)
+	}
+	if "192.0.2.4" != got {
+		t.Errorf("FetchFirstString() = '%s', want '%s'", got, "192.0.2.4")
+	}
+
+	// The cached answer doesn't need the upstream anymore
+	queries := upstream.Queries()
+	upstream.Delete("seeded.test")
+	if _, err = resolver.Fetch("seeded.test"); nil != err {
+		t.Errorf("Fetch() error = '%v', want cached answer", err)
+	}
+	if got := upstream.Queries(); got != queries {
+		t.Errorf("Queries() = '%d', want '%d'", got, queries)
+	}
+
+	if _, err = resolver.Fetch("unknown.test"); nil == err {
+		t.Error("Fetch() error = 'nil', want an error")
+	}
+
+	// The resolver's cache uses the upstream's clock
+	upstream.clock.Advance(2 * answerTTL * time.Second)
+	if _, err = resolver.Fetch("seeded.test"); nil == err {
+		t.Error("Fetch() error = 'nil', want expired cache entry")
+	}
+} // Test_NewResolver()
+
+func Test_StartTestServer(t *testing.T) {
+	srv := StartTestServer(t)
+	srv.Upstream.Add("api.example.test", time.Minute, net.ParseIP("192.0.2.10"))
+	resolver := clientResolver(srv.Address())
+
+	got, err := resolver.LookupHost(context.Background(), "api.example.test")
+	if nil != err {
+		t.Fatalf("LookupHost() error = '%v'", err)
+	}
+	if (1 != len(got)) || ("192.0.2.10" != got[0]) {
+		t.Errorf("LookupHost() = '%v', want '%v'", got, []string{"192.0.2.10"})
+	}
+
+	if _, err = resolver.LookupHost(context.Background(), "unknown.example.test"); nil == err {
+		t.Error("LookupHost() error = 'nil', want NXDOMAIN")
+	}
+} // Test_StartTestServer()
+
+/* _EoF_ */
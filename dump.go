@@ -0,0 +1,185 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	adl "github.com/mwat56/dnscache/internal/adlist"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `TDumpFormat` selects the output format of `Dump()`.
+	TDumpFormat uint8
+
+	// `TListFormat` selects the output format of `WriteDenylist()`.
+	TListFormat = adl.TListFormat
+
+	// `tDumpEntry` is a cached hostname as written by `Dump()`.
+	tDumpEntry struct {
+		Hostname string    `json:"hostname"`
+		IPs      []net.IP  `json:"ips"`
+		TTL      uint32    `json:"ttl"`
+		Expires  time.Time `json:"expires"`
+	}
+)
+
+const (
+	// `DumpHosts` writes hosts(5) style lines (`IP hostname`).
+	DumpHosts = TDumpFormat(iota)
+
+	// `DumpJSON` writes a JSON array of the entries including their
+	// remaining TTL and expiry time.
+	DumpJSON
+
+	// `DumpCSV` writes one `hostname,ip,ttl,expires` row per address.
+	DumpCSV
+
+	// `DumpBIND` writes the entries as A/AAAA records of a BIND zone
+	// file.
+	DumpBIND
+
+	// `ListSimple` writes one hostname pattern per line.
+	ListSimple = adl.ListSimple
+
+	// `ListHosts` writes `hosts(5)` lines mapping the patterns to
+	// `127.0.0.1`.
+	ListHosts = adl.ListHosts
+
+	// `ListABP` writes an ABP filter list.
+	ListABP = adl.ListABP
+)
+
+// ---------------------------------------------------------------------------
+// `TResolver` methods:
+
+// `dumpEntries()` collects all cached hostnames with their addresses
+// and expiry times.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//
+// Returns:
+//   - `[]tDumpEntry`: The cache entries in sorted order.
+func (r *TResolver) dumpEntries(aCtx context.Context) []tDumpEntry {
+	entries := []tDumpEntry{}
+	now := r.clock.Now()
+
+	for hostname := range r.ICacheList.Range(aCtx) {
+		ips, ok := r.ICacheList.IPs(aCtx, hostname)
+		if !ok || (0 == len(ips)) {
+			continue
+		}
+		expires, _ := r.ICacheList.Expiry(aCtx, hostname)
+		entry := tDumpEntry{
+			Hostname: hostname,
+			IPs:      ips,
+			Expires:  expires,
+		}
+		if ttl := expires.Sub(now); 0 < ttl {
+			entry.TTL = uint32(ttl / time.Second) //#nosec G115
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries
+} // dumpEntries()
+
+// `Dump()` writes all cached hostnames in the given format.
+//
+// Parameters:
+//   - `aWriter`: The writer to write the entries to.
+//   - `aFormat`: The output format to use.
+//
+// Returns:
+//   - `error`: `nil` if all entries were written, the error otherwise.
+func (r *TResolver) Dump(aWriter io.Writer, aFormat TDumpFormat) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second<<2)
+	defer cancel()
+
+	entries := r.dumpEntries(ctx)
+	if err := ctx.Err(); nil != err {
+		return err
+	}
+
+	switch aFormat {
+	case DumpHosts:
+		for _, entry := range entries {
+			for _, ip := range entry.IPs {
+				if _, err := fmt.Fprintf(aWriter, "%s %s\n", ip, entry.Hostname); nil != err {
+					return err
+				}
+			}
+		}
+
+	case DumpJSON:
+		return json.NewEncoder(aWriter).Encode(entries)
+
+	case DumpCSV:
+		cw := csv.NewWriter(aWriter)
+		_ = cw.Write([]string{"hostname", "ip", "ttl", "expires"})
+		for _, entry := range entries {
+			ttl := strconv.FormatUint(uint64(entry.TTL), 10)
+			expires := entry.Expires.UTC().Format(time.RFC3339)
+			for _, ip := range entry.IPs {
+				_ = cw.Write([]string{entry.Hostname, ip.String(), ttl, expires})
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+
+	case DumpBIND:
+		if _, err := fmt.Fprintf(aWriter, "; dnscache cache dump %s\n",
+			r.clock.Now().UTC().Format(time.RFC3339)); nil != err {
+			return err
+		}
+		for _, entry := range entries {
+			for _, ip := range entry.IPs {
+				rType := "AAAA"
+				if nil != ip.To4() {
+					rType = "A"
+				}
+				if _, err := fmt.Fprintf(aWriter, "%s.\t%d\tIN\t%s\t%s\n",
+					entry.Hostname, entry.TTL, rType, ip); nil != err {
+					return err
+				}
+			}
+		}
+
+	default:
+		return fmt.Errorf("invalid dump format: %d", aFormat)
+	}
+
+	return nil
+} // Dump()
+
+// `WriteDenylist()` writes all patterns of the default deny list in
+// the given format, e.g. for use by other ad blockers.
+//
+// Parameters:
+//   - `aWriter`: The writer to write the patterns to.
+//   - `aFormat`: The output format to use.
+//
+// Returns:
+//   - `error`: `nil` if all patterns were written, the error otherwise.
+func (r *TResolver) WriteDenylist(aWriter io.Writer, aFormat TListFormat) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second<<2)
+	defer cancel()
+
+	return r.adlist.WriteDeny(ctx, aWriter, aFormat)
+} // WriteDenylist()
+
+/* _EoF_ */
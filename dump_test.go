@@ -0,0 +1,131 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/mwat56/dnscache/clock"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_TResolver_Dump(t *testing.T) {
+	start := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	clk := clock.NewManual(start)
+	r := NewWithOptions(TResolverOptions{
+		Clock:   clk,
+		DataDir: t.TempDir(),
+	})
+	defer r.StopExpire()
+	ctx := context.Background()
+	r.ICacheList.Create(ctx, "example.com",
+		[]net.IP{net.ParseIP("192.0.2.1"), net.ParseIP("2001:db8::1")}, time.Hour)
+	r.ICacheList.Create(ctx, "www.example.org",
+		[]net.IP{net.ParseIP("192.0.2.2")}, time.Minute)
+	clk.Advance(30 * time.Second)
+
+	tests := []struct {
+		name    string
+		format  TDumpFormat
+		want    string
+		wantErr bool
+	}{
+		/* */
+		{
+			name:   "01 - hosts",
+			format: DumpHosts,
+			want: "192.0.2.1 example.com\n" +
+				"2001:db8::1 example.com\n" +
+				"192.0.2.2 www.example.org\n",
+		},
+		{
+			name:   "02 - JSON",
+			format: DumpJSON,
+			want: `[{"hostname":"example.com","ips":["192.0.2.1","2001:db8::1"],"ttl":3570,"expires":"2025-01-02T04:04:05Z"},` +
+				`{"hostname":"www.example.org","ips":["192.0.2.2"],"ttl":30,"expires":"2025-01-02T03:05:05Z"}]` + "\n",
+		},
+		{
+			name:   "03 - CSV",
+			format: DumpCSV,
+			want: "hostname,ip,ttl,expires\n" +
+				"example.com,192.0.2.1,3570,2025-01-02T04:04:05Z\n" +
+				"example.com,2001:db8::1,3570,2025-01-02T04:04:05Z\n" +
+				"www.example.org,192.0.2.2,30,2025-01-02T03:05:05Z\n",
+		},
+		{
+			name:   "04 - BIND",
+			format: DumpBIND,
+			want: "; dnscache cache dump 2025-01-02T03:04:35Z\n" +
+				"example.com.\t3570\tIN\tA\t192.0.2.1\n" +
+				"example.com.\t3570\tIN\tAAAA\t2001:db8::1\n" +
+				"www.example.org.\t30\tIN\tA\t192.0.2.2\n",
+		},
+		{
+			name:    "05 - invalid format",
+			format:  TDumpFormat(42),
+			wantErr: true,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := r.Dump(&buf, tc.format)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("Dump() error = '%v', wantErr '%v'", err, tc.wantErr)
+				return
+			}
+			if got := buf.String(); got != tc.want {
+				t.Errorf("Dump() =\n%s\nwant\n%s", got, tc.want)
+			}
+		})
+	}
+} // Test_TResolver_Dump()
+
+func Test_TResolver_WriteDenylist(t *testing.T) {
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir()})
+	defer r.StopExpire()
+	if err := r.AddDeny("*.ads.localdomain"); nil != err {
+		t.Fatalf("AddDeny() error = '%v'", err)
+	}
+
+	tests := []struct {
+		name    string
+		format  TListFormat
+		want    string
+		wantErr bool
+	}{
+		/* */
+		{"01 - simple", ListSimple, "*.ads.localdomain\n", false},
+		{"02 - hosts", ListHosts, "127.0.0.1 *.ads.localdomain\n", false},
+		{"03 - ABP", ListABP, "[Adblock Plus 2.0]\n||ads.localdomain^\n", false},
+		{"04 - invalid format", TListFormat(42), "", true},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := r.WriteDenylist(&buf, tc.format)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("WriteDenylist() error = '%v', wantErr '%v'", err, tc.wantErr)
+				return
+			}
+			if got := buf.String(); got != tc.want {
+				t.Errorf("WriteDenylist() = '%q', want '%q'", got, tc.want)
+			}
+		})
+	}
+} // Test_TResolver_WriteDenylist()
+
+/* _EoF_ */
@@ -0,0 +1,135 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/mwat56/dnscache/cache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `TBlockedError` is returned if a hostname or its answer got
+	// blocked.
+	//
+	// The error matches `ErrBlocked` and – if `IP` is set – also
+	// `ErrBlockedIP` when checked with `errors.Is()`.
+	TBlockedError struct {
+		Hostname string // the blocked hostname
+		IP       net.IP // the blocked address of the answer (if any)
+	}
+
+	// `TLookupError` is returned if a hostname couldn't be resolved.
+	//
+	// The error wraps the upstream error (e.g. a `*net.DNSError`) for
+	// `errors.As()` and matches `ErrUpstreamTimeout` when checked
+	// with `errors.Is()` if the lookup timed out.
+	TLookupError struct {
+		Hostname string // the hostname to resolve
+		Err      error  // the upstream error
+	}
+)
+
+var (
+	// `ErrBlocked` is matched by all errors caused by blocking a
+	// hostname or its answer.
+	ErrBlocked = errors.New("hostname blocked")
+
+	// `ErrCacheMiss` is returned if a hostname is not in the cache.
+	ErrCacheMiss = cache.ErrCacheMiss
+
+	// `ErrCircuitOpen` is matched by lookup errors while the circuit
+	// breakers of all upstream servers are open (see [TBreakerOptions]).
+	ErrCircuitOpen = errors.New("all upstream servers unavailable")
+
+	// `ErrFileVersion` is matched by errors caused by reading a file
+	// written by a newer version of this package.
+	ErrFileVersion = errors.New("file written by a newer version")
+
+	// `ErrMalformedQuery` is returned for invalid DNS queries.
+	ErrMalformedQuery = errors.New("malformed DNS query")
+
+	// `ErrOffline` is matched by lookup errors in offline mode (see
+	// [TResolver.SetOffline]).
+	ErrOffline = errors.New("upstream lookups disabled in offline mode")
+
+	// `ErrUpstreamTimeout` is matched by lookup errors caused by an
+	// upstream server not answering in time.
+	ErrUpstreamTimeout = errors.New("upstream DNS timeout")
+)
+
+// ---------------------------------------------------------------------------
+// `TBlockedError` methods:
+
+// `Error()` returns the error's message.
+//
+// Returns:
+//   - `string`: The error message.
+func (e *TBlockedError) Error() string {
+	if nil != e.IP {
+		return fmt.Sprintf("answer for %q contains blocked IP address %s",
+			e.Hostname, e.IP)
+	}
+
+	return fmt.Sprintf("hostname %q blocked", e.Hostname)
+} // Error()
+
+// `Is()` checks whether the error matches the given target.
+//
+// Parameters:
+//   - `aTarget`: The error to compare with.
+//
+// Returns:
+//   - `bool`: `true` for `ErrBlocked` (and `ErrBlockedIP`), `false` otherwise.
+func (e *TBlockedError) Is(aTarget error) bool {
+	return (ErrBlocked == aTarget) || ((nil != e.IP) && (ErrBlockedIP == aTarget))
+} // Is()
+
+// ---------------------------------------------------------------------------
+// `TLookupError` methods:
+
+// `Error()` returns the error's message.
+//
+// Returns:
+//   - `string`: The error message.
+func (e *TLookupError) Error() string {
+	return fmt.Sprintf("lookup %q: %v", e.Hostname, e.Err)
+} // Error()
+
+// `Is()` checks whether the error matches the given target.
+//
+// Parameters:
+//   - `aTarget`: The error to compare with.
+//
+// Returns:
+//   - `bool`: `true` for `ErrUpstreamTimeout` if the lookup timed out, `false` otherwise.
+func (e *TLookupError) Is(aTarget error) bool {
+	if ErrUpstreamTimeout != aTarget {
+		return false
+	}
+	if errors.Is(e.Err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+
+	return errors.As(e.Err, &netErr) && netErr.Timeout()
+} // Is()
+
+// `Unwrap()` returns the upstream error.
+//
+// Returns:
+//   - `error`: The wrapped error.
+func (e *TLookupError) Unwrap() error {
+	return e.Err
+} // Unwrap()
+
+/* _EoF_ */
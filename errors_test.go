@@ -0,0 +1,97 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_TBlockedError_Is(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		target error
+		want   bool
+	}{
+		/* */
+		{"01 - hostname blocked", &TBlockedError{Hostname: "ads.tld"}, ErrBlocked, true},
+		{"02 - hostname not IP", &TBlockedError{Hostname: "ads.tld"}, ErrBlockedIP, false},
+		{"03 - IP blocked", &TBlockedError{Hostname: "ads.tld", IP: net.IPv4(192, 0, 2, 1)}, ErrBlocked, true},
+		{"04 - IP blocked IP", &TBlockedError{Hostname: "ads.tld", IP: net.IPv4(192, 0, 2, 1)}, ErrBlockedIP, true},
+		{"05 - other", &TBlockedError{Hostname: "ads.tld"}, ErrCacheMiss, false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := errors.Is(tc.err, tc.target); got != tc.want {
+				t.Errorf("errors.Is(%v, %v) = '%v', want '%v'",
+					tc.err, tc.target, got, tc.want)
+			}
+			var blocked *TBlockedError
+			if !errors.As(tc.err, &blocked) || ("ads.tld" != blocked.Hostname) {
+				t.Errorf("errors.As() = '%v', want a TBlockedError", blocked)
+			}
+		})
+	}
+} // Test_TBlockedError_Is()
+
+func Test_TLookupError_Is(t *testing.T) {
+	notFound := &net.DNSError{Err: "no such host", Name: "host.tld", IsNotFound: true}
+	timeout := &net.DNSError{Err: "i/o timeout", Name: "host.tld", IsTimeout: true}
+
+	tests := []struct {
+		name   string
+		err    error
+		target error
+		want   bool
+	}{
+		/* */
+		{"01 - not found", &TLookupError{"host.tld", notFound}, ErrUpstreamTimeout, false},
+		{"02 - DNS timeout", &TLookupError{"host.tld", timeout}, ErrUpstreamTimeout, true},
+		{"03 - deadline", &TLookupError{"host.tld", context.DeadlineExceeded}, ErrUpstreamTimeout, true},
+		{"04 - canceled", &TLookupError{"host.tld", context.Canceled}, ErrUpstreamTimeout, false},
+		{"05 - wrapped", &TLookupError{"host.tld", context.Canceled}, context.Canceled, true},
+		{"06 - other", &TLookupError{"host.tld", timeout}, ErrBlocked, false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := errors.Is(tc.err, tc.target); got != tc.want {
+				t.Errorf("errors.Is(%v, %v) = '%v', want '%v'",
+					tc.err, tc.target, got, tc.want)
+			}
+		})
+	}
+
+	// The upstream error is still available
+	var dnsErr *net.DNSError
+	if err := error(&TLookupError{"host.tld", notFound}); !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+		t.Errorf("errors.As() = '%v', want '%v'", dnsErr, notFound)
+	}
+} // Test_TLookupError_Is()
+
+func Test_TResolver_Cached(t *testing.T) {
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir()})
+	defer r.StopExpire()
+	r.ICacheList.Create(context.TODO(), "cached.tld", []net.IP{net.IPv4(192, 0, 2, 1)}, 0)
+
+	if ips, err := r.Cached("cached.tld"); (nil != err) || (1 != len(ips)) {
+		t.Errorf("TResolver.Cached() = '%v', '%v', want one IP", ips, err)
+	}
+	if _, err := r.Cached("unknown.tld"); !errors.Is(err, ErrCacheMiss) {
+		t.Errorf("TResolver.Cached() error = '%v', want '%v'", err, ErrCacheMiss)
+	}
+} // Test_TResolver_Cached()
+
+/* _EoF_ */
@@ -0,0 +1,136 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"expvar"
+	"sync"
+	"sync/atomic"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+const (
+	// `expvarPrefix` is the common prefix of all published variables.
+	expvarPrefix = "dnscache."
+)
+
+type (
+	// `tPoolGauges` are the published metrics of a node pool.
+	tPoolGauges struct {
+		*TPoolMetrics
+		HitRate float64
+	}
+)
+
+var (
+	// `gExpvarResolver` is the resolver whose data are published.
+	gExpvarResolver atomic.Pointer[TResolver]
+
+	// `gExpvarOnce` makes sure, the variables are published only once
+	// since `expvar.Publish()` panics on duplicate names.
+	gExpvarOnce sync.Once
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `publishExpvars()` registers all variables with the `expvar` package.
+//
+// The registered functions are evaluated lazily, i.e. only when the
+// variables are requested (e.g. by calling the `/debug/vars` URL).
+func publishExpvars() {
+	expvar.Publish(expvarPrefix+"resolver", expvar.Func(func() any {
+		return gMetrics.clone()
+	}))
+
+	expvar.Publish(expvarPrefix+"cache", expvar.Func(func() any {
+		r := gExpvarResolver.Load()
+		if (nil == r) || (nil == r.ICacheList) {
+			return 0
+		}
+		r.RLock()
+		defer r.RUnlock()
+
+		return r.ICacheList.Len()
+	}))
+
+	expvar.Publish(expvarPrefix+"allowlist", expvar.Func(func() any {
+		r := gExpvarResolver.Load()
+		if nil == r {
+			return nil
+		}
+		allow, _ := r.adlist.Counters()
+
+		return allow
+	}))
+
+	expvar.Publish(expvarPrefix+"denylist", expvar.Func(func() any {
+		r := gExpvarResolver.Load()
+		if nil == r {
+			return nil
+		}
+		_, deny := r.adlist.Counters()
+
+		return deny
+	}))
+
+	expvar.Publish(expvarPrefix+"nodepool", expvar.Func(func() any {
+		r := gExpvarResolver.Load()
+		if nil == r {
+			return nil
+		}
+		adlist, cache := r.PoolMetrics()
+
+		return map[string]tPoolGauges{
+			"adlist": {adlist, adlist.HitRate()},
+			"cache":  {cache, cache.HitRate()},
+		}
+	}))
+
+	expvar.Publish(expvarPrefix+"upstreams", expvar.Func(func() any {
+		r := gExpvarResolver.Load()
+		if nil == r {
+			return nil
+		}
+
+		return r.Breakers()
+	}))
+} // publishExpvars()
+
+// ---------------------------------------------------------------------------
+// `TResolver` methods:
+
+// `EnableExpvar()` publishes the resolver's metrics and those of its
+// allow/deny lists via the standard `expvar` package.
+//
+// The data are published with a `dnscache.` prefix:
+//
+//   - `dnscache.resolver`: The resolver's metrics (see [TMetrics]),
+//   - `dnscache.cache`: The number of currently cached hostnames,
+//   - `dnscache.allowlist`: The node, pattern and match counters of the allow list,
+//   - `dnscache.denylist`: The node, pattern and match counters of the deny list,
+//   - `dnscache.nodepool`: The metrics of the node pools (see [TResolver.PoolMetrics]),
+//   - `dnscache.upstreams`: The states of the upstream servers' circuit breakers (see [TResolver.Breakers]).
+//
+// Since the `expvar` package uses global names, only one resolver can
+// be published at a time; calling this method on another resolver
+// replaces the previously published one.
+//
+// Returns:
+//   - `*TResolver`: The current resolver.
+func (r *TResolver) EnableExpvar() *TResolver {
+	if nil == r {
+		return r
+	}
+	gExpvarResolver.Store(r)
+	gExpvarOnce.Do(publishExpvars)
+
+	return r
+} // EnableExpvar()
+
+/* _EoF_ */
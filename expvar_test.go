@@ -0,0 +1,70 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"expvar"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_TResolver_EnableExpvar(t *testing.T) {
+	tests := []struct {
+		name     string
+		resolver *TResolver
+		wantNil  bool
+	}{
+		/* */
+		{
+			name:     "01 - nil resolver",
+			resolver: nil,
+			wantNil:  true,
+		},
+		{
+			name:     "02 - empty resolver",
+			resolver: &TResolver{},
+			wantNil:  false,
+		},
+		{
+			name:     "03 - enable twice",
+			resolver: &TResolver{},
+			wantNil:  false,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.resolver.EnableExpvar()
+			if tc.wantNil {
+				if nil != got {
+					t.Errorf("EnableExpvar() = '%v', want 'nil'", got)
+				}
+				return
+			}
+			if got != tc.resolver {
+				t.Errorf("EnableExpvar() = '%p', want '%p'", got, tc.resolver)
+			}
+
+			for _, name := range []string{"resolver", "cache", "allowlist", "denylist", "nodepool", "upstreams"} {
+				v := expvar.Get(expvarPrefix + name)
+				if nil == v {
+					t.Errorf("expvar.Get(%q) = 'nil', want non-nil",
+						expvarPrefix+name)
+					continue
+				}
+				if "" == v.String() {
+					t.Errorf("expvar.Get(%q).String() = '', want JSON",
+						expvarPrefix+name)
+				}
+			}
+		})
+	}
+} // Test_TResolver_EnableExpvar()
+
+/* _EoF_ */
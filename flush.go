@@ -0,0 +1,164 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"strings"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// ---------------------------------------------------------------------------
+// `TResolver` methods:
+
+// `deleteCached()` removes all cache entries whose hostname satisfies
+// the given condition.
+//
+// Parameters:
+//   - `aMatch`: The condition for the hostnames to remove.
+//
+// Returns:
+//   - `int`: The number of removed cache entries.
+func (r *TResolver) deleteCached(aMatch func(aHostname string) bool) (rCount int) {
+	ctx, cancel := context.WithTimeout(context.Background(), defLookupTimeout)
+	defer cancel()
+
+	var hostnames []string
+	r.RLock()
+	for hostname := range r.ICacheList.Range(ctx) {
+		if aMatch(hostname) {
+			hostnames = append(hostnames, hostname)
+		}
+	}
+	r.RUnlock()
+
+	r.Lock()
+	defer r.Unlock()
+	for _, hostname := range hostnames {
+		// A trie node keeping subdomains reports `false` although
+		// its own entry is gone, hence the existence check.
+		r.ICacheList.Delete(ctx, hostname)
+		if !r.ICacheList.Exists(ctx, hostname) {
+			rCount++
+		}
+	}
+
+	return
+} // deleteCached()
+
+// `Flush()` removes all entries from the cache.
+//
+// Returns:
+//   - `int`: The number of removed cache entries.
+func (r *TResolver) Flush() int {
+	count := r.flushAll()
+	r.publishSync(TSyncEvent{Kind: SyncFlush})
+
+	return count
+} // Flush()
+
+// `flushAll()` removes all entries from the cache.
+//
+// Returns:
+//   - `int`: The number of removed cache entries.
+func (r *TResolver) flushAll() int {
+	count := r.deleteCached(func(string) bool {
+		return true
+	})
+	gLog.Info("cache flushed", "entries", count)
+
+	return count
+} // flushAll()
+
+// `FlushMatching()` removes all cache entries whose hostname matches
+// the given wildcard pattern.
+//
+// The pattern uses the same syntax as [TResolver.NeverCache], e.g.
+// `*.cdn.example.com` or `host-?.example.com`.
+//
+// Parameters:
+//   - `aPattern`: The hostname or wildcard pattern to flush.
+//
+// Returns:
+//   - `int`: The number of removed cache entries.
+//   - `error`: `nil` if the pattern is valid, the error otherwise.
+func (r *TResolver) FlushMatching(aPattern string) (int, error) {
+	count, err := r.flushMatching(aPattern)
+	if nil == err {
+		r.publishSync(TSyncEvent{Kind: SyncFlushMatching, Hostname: aPattern})
+	}
+
+	return count, err
+} // FlushMatching()
+
+// `flushMatching()` removes all cache entries whose hostname matches
+// the given wildcard pattern.
+//
+// Parameters:
+//   - `aPattern`: The hostname or wildcard pattern to flush.
+//
+// Returns:
+//   - `int`: The number of removed cache entries.
+//   - `error`: `nil` if the pattern is valid, the error otherwise.
+func (r *TResolver) flushMatching(aPattern string) (int, error) {
+	pattern, err := normaliseHostPattern(aPattern)
+	if nil != err {
+		return 0, err
+	}
+	count := r.deleteCached(func(aHostname string) bool {
+		return matchHostPattern(pattern, strings.Trim(strings.ToLower(aHostname), "."))
+	})
+	gLog.Info("cache flushed", "pattern", pattern, "entries", count)
+
+	return count, nil
+} // flushMatching()
+
+// `FlushSuffix()` removes the cache entries of a domain and all its
+// subdomains.
+//
+// For example, flushing `example.com` removes `example.com` as well as
+// `www.example.com`, but not `myexample.com`.
+//
+// Parameters:
+//   - `aDomain`: The domain whose subtree to flush.
+//
+// Returns:
+//   - `int`: The number of removed cache entries.
+func (r *TResolver) FlushSuffix(aDomain string) int {
+	domain := strings.Trim(strings.ToLower(strings.TrimSpace(aDomain)), ".")
+	if "" == domain {
+		return 0
+	}
+	count := r.flushSuffix(domain)
+	r.publishSync(TSyncEvent{Kind: SyncFlushSuffix, Hostname: domain})
+
+	return count
+} // FlushSuffix()
+
+// `flushSuffix()` removes the cache entries of a domain and all its
+// subdomains.
+//
+// Parameters:
+//   - `aDomain`: The normalised domain whose subtree to flush.
+//
+// Returns:
+//   - `int`: The number of removed cache entries.
+func (r *TResolver) flushSuffix(aDomain string) int {
+	if "" == aDomain {
+		return 0
+	}
+	count := r.deleteCached(func(aHostname string) bool {
+		hostname := strings.Trim(strings.ToLower(aHostname), ".")
+		return (hostname == aDomain) || strings.HasSuffix(hostname, "."+aDomain)
+	})
+	gLog.Info("cache flushed", "suffix", aDomain, "entries", count)
+
+	return count
+} // flushSuffix()
+
+/* _EoF_ */
@@ -0,0 +1,121 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"net"
+	"slices"
+	"testing"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_TResolver_Flush(t *testing.T) {
+	hostnames := []string{
+		"example.com",
+		"www.example.com",
+		"img.cdn.example.com",
+		"myexample.com",
+		"host-1.example.org",
+		"host-12.example.org",
+	}
+	ip := net.ParseIP("192.0.2.1")
+
+	tests := []struct {
+		name      string
+		flush     func(*TResolver) (int, error)
+		want      int
+		wantLeft  []string
+		wantError bool
+	}{
+		/* */
+		{
+			name: "01 - whole cache",
+			flush: func(aR *TResolver) (int, error) {
+				return aR.Flush(), nil
+			},
+			want: len(hostnames),
+		},
+		{
+			name: "02 - suffix",
+			flush: func(aR *TResolver) (int, error) {
+				return aR.FlushSuffix("Example.COM."), nil
+			},
+			want:     3,
+			wantLeft: []string{"host-1.example.org", "host-12.example.org", "myexample.com"},
+		},
+		{
+			name: "03 - empty suffix",
+			flush: func(aR *TResolver) (int, error) {
+				return aR.FlushSuffix(" . "), nil
+			},
+			want:     0,
+			wantLeft: hostnames,
+		},
+		{
+			name: "04 - wildcard pattern",
+			flush: func(aR *TResolver) (int, error) {
+				return aR.FlushMatching("host-?.example.org")
+			},
+			want: 1,
+			wantLeft: []string{"example.com", "host-12.example.org",
+				"img.cdn.example.com", "myexample.com", "www.example.com"},
+		},
+		{
+			name: "05 - subdomain pattern",
+			flush: func(aR *TResolver) (int, error) {
+				return aR.FlushMatching("*.example.com")
+			},
+			want: 2,
+			wantLeft: []string{"example.com", "host-1.example.org",
+				"host-12.example.org", "myexample.com"},
+		},
+		{
+			name: "06 - invalid pattern",
+			flush: func(aR *TResolver) (int, error) {
+				return aR.FlushMatching("[example.com")
+			},
+			want:      0,
+			wantLeft:  hostnames,
+			wantError: true,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewWithOptions(TResolverOptions{DataDir: t.TempDir()})
+			defer r.StopExpire()
+			ctx := context.Background()
+			for _, hostname := range hostnames {
+				r.ICacheList.Create(ctx, hostname, []net.IP{ip}, time.Hour)
+			}
+
+			got, err := tc.flush(r)
+			if (nil != err) != tc.wantError {
+				t.Errorf("flush error = '%v', wantError '%v'", err, tc.wantError)
+			}
+			if got != tc.want {
+				t.Errorf("flush = '%d', want '%d'", got, tc.want)
+			}
+
+			var left []string
+			for hostname := range r.ICacheList.Range(ctx) {
+				left = append(left, hostname)
+			}
+			slices.Sort(left)
+			wantLeft := slices.Sorted(slices.Values(tc.wantLeft))
+			if !slices.Equal(left, wantLeft) {
+				t.Errorf("cached = '%v', want '%v'", left, wantLeft)
+			}
+		})
+	}
+} // Test_TResolver_Flush()
+
+/* _EoF_ */
@@ -0,0 +1,225 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `TGossipOptions` contains the settings of a [TGossipSync].
+	//
+	//   - `Listen`: The local UDP address to receive the events on.
+	//   - `Peers`: The UDP addresses of the other instances.
+	//   - `Key`: The shared secret authenticating the events (recommended).
+	TGossipOptions struct {
+		Listen string
+		Peers  []string
+		Key    string
+	}
+
+	// `TGossipSync` propagates cache changes by sending them via UDP
+	// to a fixed list of peers (see [ICacheSync]).
+	//
+	// Only events sent from the peers' IP addresses are accepted; if
+	// a key is configured, each event is additionally signed with it.
+	TGossipSync struct {
+		conn     net.PacketConn
+		events   chan TSyncEvent
+		done     chan struct{}
+		key      []byte
+		peers    []*net.UDPAddr
+		rejected atomic.Uint64 // number of invalid messages received
+		once     sync.Once
+	}
+)
+
+const (
+	// `gossipMaxMessage` is the max. size of a gossip message.
+	gossipMaxMessage = 1 << 16
+)
+
+// ---------------------------------------------------------------------------
+// `TGossipSync` constructor:
+
+// `NewGossipSync()` returns a new UDP gossip backend.
+//
+// Parameters:
+//   - `aOptions`: The backend's settings.
+//
+// Returns:
+//   - `*TGossipSync`: The new backend.
+//   - `error`: `nil` if the backend was created, the error otherwise.
+func NewGossipSync(aOptions TGossipOptions) (*TGossipSync, error) {
+	peers := make([]*net.UDPAddr, 0, len(aOptions.Peers))
+	for _, peer := range aOptions.Peers {
+		addr, err := net.ResolveUDPAddr("udp", peer)
+		if nil != err {
+			return nil, fmt.Errorf("invalid gossip peer %q: %w", peer, err)
+		}
+		peers = append(peers, addr)
+	}
+	if 0 == len(peers) {
+		return nil, errors.New("no gossip peers given")
+	}
+
+	conn, err := net.ListenPacket("udp", aOptions.Listen)
+	if nil != err {
+		return nil, fmt.Errorf("failed to listen for gossip: %w", err)
+	}
+
+	result := &TGossipSync{
+		conn:   conn,
+		events: make(chan TSyncEvent, syncEventBuffer),
+		done:   make(chan struct{}),
+		peers:  peers,
+	}
+	if "" != aOptions.Key {
+		result.key = []byte(aOptions.Key)
+	}
+	go result.receive()
+
+	return result, nil
+} // NewGossipSync()
+
+// ---------------------------------------------------------------------------
+// `TGossipSync` methods:
+
+// `Addr()` returns the local address the events are received on.
+//
+// Returns:
+//   - `net.Addr`: The local address.
+func (gs *TGossipSync) Addr() net.Addr {
+	return gs.conn.LocalAddr()
+} // Addr()
+
+// `Close()` stops the propagation and closes the channel returned
+// by [Events].
+//
+// Returns:
+//   - `error`: `nil` if the backend was closed, the error otherwise.
+func (gs *TGossipSync) Close() (rErr error) {
+	gs.once.Do(func() {
+		rErr = gs.conn.Close()
+		<-gs.done
+	})
+
+	return
+} // Close()
+
+// `decode()` checks and decodes a received message.
+//
+// Parameters:
+//   - `aMessage`: The received message.
+//   - `aAddr`: The sender's address.
+//
+// Returns:
+//   - `TSyncEvent`: The decoded event.
+//   - `bool`: `true` if the message is a valid event, `false` otherwise.
+func (gs *TGossipSync) decode(aMessage []byte, aAddr net.Addr) (rEvent TSyncEvent, rOK bool) {
+	sender, ok := aAddr.(*net.UDPAddr)
+	if !ok {
+		return
+	}
+	for _, peer := range gs.peers {
+		if rOK = peer.IP.Equal(sender.IP); rOK {
+			break
+		}
+	}
+	if !rOK {
+		return // not sent by a peer
+	}
+
+	if aMessage, rOK = openSync(gs.key, aMessage); rOK {
+		rOK = (nil == json.Unmarshal(aMessage, &rEvent))
+	}
+
+	return
+} // decode()
+
+// `Events()` returns the channel delivering the events received from
+// the peers.
+//
+// Returns:
+//   - `<-chan TSyncEvent`: The channel of received events.
+func (gs *TGossipSync) Events() <-chan TSyncEvent {
+	return gs.events
+} // Events()
+
+// `Publish()` sends an event to all peers.
+//
+// Parameters:
+//   - `aEvent`: The event to send.
+//
+// Returns:
+//   - `error`: `nil` if the event was sent to all peers, the error otherwise.
+func (gs *TGossipSync) Publish(aEvent TSyncEvent) error {
+	data, err := json.Marshal(aEvent)
+	if nil != err {
+		return err
+	}
+	data = sealSync(gs.key, data)
+	if gossipMaxMessage < len(data) {
+		return fmt.Errorf("gossip message too large: %d bytes", len(data))
+	}
+
+	var errs []error
+	for _, peer := range gs.peers {
+		if _, err := gs.conn.WriteTo(data, peer); nil != err {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+} // Publish()
+
+// `Rejected()` returns the number of invalid messages received, e.g.
+// not sent by a peer or with a wrong signature.
+//
+// Returns:
+//   - `uint64`: The number of ignored messages.
+func (gs *TGossipSync) Rejected() uint64 {
+	return gs.rejected.Load()
+} // Rejected()
+
+// `receive()` reads the peers' events until the connection gets closed.
+func (gs *TGossipSync) receive() {
+	defer close(gs.done)
+	defer close(gs.events)
+
+	buffer := make([]byte, gossipMaxMessage)
+	for {
+		n, addr, err := gs.conn.ReadFrom(buffer)
+		if nil != err {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			continue
+		}
+		event, ok := gs.decode(buffer[:n], addr)
+		if !ok {
+			// Anybody may send junk, hence it's just counted
+			gs.rejected.Add(1)
+			gLog.Debug("ignoring invalid gossip message", "sender", addr.String())
+			continue
+		}
+		select {
+		case gs.events <- event:
+		default:
+			gLog.Warn("dropping gossip event", "kind", event.Kind.String())
+		}
+	}
+} // receive()
+
+/* _EoF_ */
@@ -0,0 +1,92 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_NewGossipSync(t *testing.T) {
+	tests := []struct {
+		name    string
+		options TGossipOptions
+		wantErr bool
+	}{
+		/* */
+		{"01 - no peers", TGossipOptions{Listen: "127.0.0.1:0"}, true},
+		{"02 - invalid peer", TGossipOptions{Listen: "127.0.0.1:0", Peers: []string{"127.0.0.1"}}, true},
+		{"03 - invalid address", TGossipOptions{Listen: "127.0.0.1:-1", Peers: []string{"127.0.0.1:5300"}}, true},
+		{"04 - valid", TGossipOptions{Listen: "127.0.0.1:0", Peers: []string{"127.0.0.1:5300"}}, false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gs, err := NewGossipSync(tc.options)
+			if (nil != err) != tc.wantErr {
+				t.Fatalf("NewGossipSync() error = '%v', wantErr '%v'", err, tc.wantErr)
+			}
+			if nil != gs {
+				if err := gs.Close(); nil != err {
+					t.Errorf("Close() error = '%v'", err)
+				}
+				if _, ok := <-gs.Events(); ok {
+					t.Error("Events() not closed")
+				}
+			}
+		})
+	}
+} // Test_NewGossipSync()
+
+func Test_TGossipSync_Publish(t *testing.T) {
+	receiver, err := NewGossipSync(TGossipOptions{Listen: "127.0.0.1:0",
+		Peers: []string{"127.0.0.1:9"}, Key: "secret"})
+	if nil != err {
+		t.Fatalf("NewGossipSync() error = '%v'", err)
+	}
+	defer receiver.Close()
+	peers := []string{receiver.Addr().String()}
+
+	sender, _ := NewGossipSync(TGossipOptions{Listen: "127.0.0.1:0", Peers: peers, Key: "secret"})
+	defer sender.Close()
+	unsigned, _ := NewGossipSync(TGossipOptions{Listen: "127.0.0.1:0", Peers: peers})
+	defer unsigned.Close()
+	forger, _ := NewGossipSync(TGossipOptions{Listen: "127.0.0.1:0", Peers: peers, Key: "guessed"})
+	defer forger.Close()
+
+	for _, gs := range []*TGossipSync{unsigned, forger, sender} {
+		event := TSyncEvent{Origin: gs.Addr().String(), Kind: SyncEntry, Hostname: "host.localdomain",
+			IPs: []net.IP{net.ParseIP("192.0.2.1")}, TTL: time.Minute}
+		if err := gs.Publish(event); nil != err {
+			t.Fatalf("Publish() error = '%v'", err)
+		}
+	}
+
+	select {
+	case got := <-receiver.Events():
+		if (sender.Addr().String() != got.Origin) || ("host.localdomain" != got.Hostname) ||
+			(1 != len(got.IPs)) || (time.Minute != got.TTL) {
+			t.Errorf("Events() = '%v', want the signed event", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Events() received nothing")
+	}
+	select {
+	case got := <-receiver.Events():
+		t.Errorf("Events() = '%v', want nothing", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if got := receiver.Rejected(); 2 != got {
+		t.Errorf("Rejected() = '%d', want '2'", got)
+	}
+} // Test_TGossipSync_Publish()
+
+/* _EoF_ */
@@ -0,0 +1,592 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mwat56/dnscache/clock"
+	adl "github.com/mwat56/dnscache/internal/adlist"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tClientGroup` assigns a range of client addresses to a group.
+	tClientGroup struct {
+		network *net.IPNet
+		group   string
+	}
+
+	// `tGroups` holds the named allow/deny lists and the assignment
+	// of client addresses to those groups.
+	//
+	// Clients without an assigned group use the resolver's default
+	// allow/deny list.
+	tGroups struct {
+		sync.RWMutex
+		clock      clock.IClock            // `nil` means the system's clock
+		datadir    string                  // base directory of the groups' lists
+		lists      map[string]*adl.TADlist // group name → allow/deny list
+		safe       map[string]bool         // groups enforcing safe search
+		clients    []tClientGroup          // sorted by decreasing prefix length
+		valid      TValidation             // strictness of the lists' hostname checks
+		subdomains bool                    // the lists' hostnames block their subdomains
+		prefer     TPrecedence             // rule for hostnames matched by both lists
+	}
+)
+
+var (
+	// `ErrInvalidGroup` is returned if a group name is invalid.
+	ErrInvalidGroup = errors.New("invalid group name")
+
+	// `ErrUnknownGroup` is returned if a group doesn't exist.
+	ErrUnknownGroup = errors.New("unknown group")
+
+	// `groupNameRE` matches valid group names (used as directory names).
+	groupNameRE = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+)
+
+// ---------------------------------------------------------------------------
+// `tGroups` constructor:
+
+// `newGroups()` returns a new, empty list of groups.
+//
+// Parameters:
+//   - `aDataDir`: The directory to store the groups' lists in.
+//
+// Returns:
+//   - `*tGroups`: The new list of groups.
+func newGroups(aDataDir string) *tGroups {
+	return &tGroups{
+		datadir: filepath.Join(aDataDir, "groups"),
+		lists:   make(map[string]*adl.TADlist),
+		safe:    make(map[string]bool),
+	}
+} // newGroups()
+
+// ---------------------------------------------------------------------------
+// `tGroups` methods:
+
+// `assigned()` returns the group a client address range is assigned to.
+//
+// Parameters:
+//   - `aCIDR`: The client's IP address or address range.
+//
+// Returns:
+//   - `string`: The group's name.
+//   - `bool`: `true` if exactly that range is assigned, `false` otherwise.
+func (g *tGroups) assigned(aCIDR string) (string, bool) {
+	network, err := parseClientCIDR(aCIDR)
+	if (nil == g) || (nil != err) {
+		return "", false
+	}
+	cidr := network.String()
+	g.RLock()
+	defer g.RUnlock()
+
+	for _, cg := range g.clients {
+		if cg.network.String() == cidr {
+			return cg.group, true
+		}
+	}
+
+	return "", false
+} // assigned()
+
+// `list()` returns the allow/deny list of the given group.
+//
+// Parameters:
+//   - `aGroup`: The group's name.
+//
+// Returns:
+//   - `*adl.TADlist`: The group's list.
+//   - `error`: `ErrUnknownGroup` if the group doesn't exist, `nil` otherwise.
+func (g *tGroups) list(aGroup string) (*adl.TADlist, error) {
+	if nil == g {
+		return nil, ErrUnknownGroup
+	}
+	g.RLock()
+	defer g.RUnlock()
+
+	if list, ok := g.lists[aGroup]; ok {
+		return list, nil
+	}
+
+	return nil, fmt.Errorf("%w: %q", ErrUnknownGroup, aGroup)
+} // list()
+
+// `lookup()` returns the group assigned to the given client.
+//
+// Parameters:
+//   - `aClient`: The client's IP address.
+//
+// Returns:
+//   - `string`: The group's name (empty if none is assigned).
+//   - `*adl.TADlist`: The group's list (`nil` if none is assigned).
+func (g *tGroups) lookup(aClient net.IP) (string, *adl.TADlist) {
+	if (nil == g) || (nil == aClient) {
+		return "", nil
+	}
+	g.RLock()
+	defer g.RUnlock()
+
+	for _, cg := range g.clients {
+		if cg.network.Contains(aClient) {
+			return cg.group, g.lists[cg.group]
+		}
+	}
+
+	return "", nil
+} // lookup()
+
+// `safeSearch()` reports whether the given group enforces safe search.
+//
+// Parameters:
+//   - `aGroup`: The group's name.
+//
+// Returns:
+//   - `bool`: `true` if safe search is enforced, `false` otherwise.
+func (g *tGroups) safeSearch(aGroup string) bool {
+	if nil == g {
+		return false
+	}
+	g.RLock()
+	defer g.RUnlock()
+
+	return g.safe[aGroup]
+} // safeSearch()
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `parseClientCIDR()` parses a client's address range.
+//
+// A single IP address (without a prefix length) is accepted as well.
+//
+// Parameters:
+//   - `aCIDR`: The address range or IP to parse.
+//
+// Returns:
+//   - `*net.IPNet`: The parsed address range.
+//   - `error`: `nil` if the range is valid, the error otherwise.
+func parseClientCIDR(aCIDR string) (*net.IPNet, error) {
+	aCIDR = strings.TrimSpace(aCIDR)
+	if !strings.Contains(aCIDR, "/") {
+		ip := net.ParseIP(aCIDR)
+		if nil == ip {
+			return nil, fmt.Errorf("invalid client address: %q", aCIDR)
+		}
+		if ip4 := ip.To4(); nil != ip4 {
+			return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+	}
+
+	_, network, err := net.ParseCIDR(aCIDR)
+	if nil != err {
+		return nil, fmt.Errorf("invalid client address: %w", err)
+	}
+
+	return network, nil
+} // parseClientCIDR()
+
+// ---------------------------------------------------------------------------
+// `TResolver` methods:
+
+// `AddGroup()` creates a new, empty allow/deny list group.
+//
+// The group's lists are stored in a sub-directory of the resolver's
+// data directory. If the group already exists, nothing is changed.
+//
+// Parameters:
+//   - `aGroup`: The group's name (letters, digits, `-`, and `_` only).
+//
+// Returns:
+//   - `error`: `nil` if the group was created, the error otherwise.
+func (r *TResolver) AddGroup(aGroup string) error {
+	if !groupNameRE.MatchString(aGroup) {
+		return fmt.Errorf("%w: %q", ErrInvalidGroup, aGroup)
+	}
+	g := r.groups
+	if nil == g {
+		return ErrUnknownGroup
+	}
+	g.Lock()
+	defer g.Unlock()
+
+	if _, ok := g.lists[aGroup]; !ok {
+		list := adl.New(filepath.Join(g.datadir, aGroup))
+		list.SetClock(g.clock)
+		list.SetValidation(g.valid)
+		list.SetImplicitSubdomains(g.subdomains)
+		list.SetPrecedence(g.prefer)
+		g.lists[aGroup] = list
+	}
+
+	return nil
+} // AddGroup()
+
+// `AddGroupAllow()` inserts a hostname pattern into a group's allow list.
+//
+// Parameters:
+//   - `aGroup`: The group's name.
+//   - `aPattern`: The FQDN name/pattern to insert.
+//
+// Returns:
+//   - `error`: `nil` if the pattern was added, the error otherwise.
+func (r *TResolver) AddGroupAllow(aGroup, aPattern string) error {
+	list, err := r.groups.list(aGroup)
+	if nil != err {
+		return err
+	}
+	if !list.AddAllow(context.Background(), aPattern) {
+		return fmt.Errorf("invalid pattern: %q", aPattern)
+	}
+
+	return nil
+} // AddGroupAllow()
+
+// `AddGroupDeny()` inserts a hostname pattern into a group's deny list.
+//
+// Parameters:
+//   - `aGroup`: The group's name.
+//   - `aPattern`: The FQDN name/pattern to insert.
+//
+// Returns:
+//   - `error`: `nil` if the pattern was added, the error otherwise.
+func (r *TResolver) AddGroupDeny(aGroup, aPattern string) error {
+	list, err := r.groups.list(aGroup)
+	if nil != err {
+		return err
+	}
+	if !list.AddDeny(context.Background(), aPattern) {
+		return fmt.Errorf("invalid pattern: %q", aPattern)
+	}
+
+	return nil
+} // AddGroupDeny()
+
+// `AssignClient()` assigns a client address range to a group.
+//
+// If the ranges of several assignments overlap, the most specific
+// (i.e. longest) prefix wins. Assigning an already assigned range
+// again replaces the previous assignment.
+//
+// Parameters:
+//   - `aCIDR`: The client's IP address or address range.
+//   - `aGroup`: The name of an existing group.
+//
+// Returns:
+//   - `error`: `nil` if the range was assigned, the error otherwise.
+func (r *TResolver) AssignClient(aCIDR, aGroup string) error {
+	network, err := parseClientCIDR(aCIDR)
+	if nil != err {
+		return err
+	}
+	g := r.groups
+	if nil == g {
+		return ErrUnknownGroup
+	}
+	g.Lock()
+	defer g.Unlock()
+
+	if _, ok := g.lists[aGroup]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownGroup, aGroup)
+	}
+
+	cidr := network.String()
+	g.clients = slices.DeleteFunc(g.clients, func(aCG tClientGroup) bool {
+		return aCG.network.String() == cidr
+	})
+	g.clients = append(g.clients, tClientGroup{network: network, group: aGroup})
+	slices.SortStableFunc(g.clients, func(a, b tClientGroup) int {
+		aOnes, _ := a.network.Mask.Size()
+		bOnes, _ := b.network.Mask.Size()
+		return bOnes - aOnes
+	})
+
+	return nil
+} // AssignClient()
+
+// `ClientGroup()` returns the name of the group assigned to a client.
+//
+// Parameters:
+//   - `aClient`: The client's IP address.
+//
+// Returns:
+//   - `string`: The group's name (empty for the default group).
+func (r *TResolver) ClientGroup(aClient net.IP) string {
+	name, _ := r.groups.lookup(aClient)
+
+	return name
+} // ClientGroup()
+
+// `clientPolicy()` returns the allow/deny list and the safe search
+// setting to use for the given client.
+//
+// Parameters:
+//   - `aClient`: The client's IP address (may be `nil`).
+//
+// Returns:
+//   - `*adl.TADlist`: The client's group list or the default list.
+//   - `bool`: Whether to enforce safe search for the client.
+func (r *TResolver) clientPolicy(aClient net.IP) (*adl.TADlist, bool) {
+	name, list := r.groups.lookup(aClient)
+	if nil == list {
+		return r.adlist, r.safeSearch.Load()
+	}
+
+	return list, r.groups.safeSearch(name)
+} // clientPolicy()
+
+// `DeleteGroup()` removes a group and all its client assignments.
+//
+// The group's files on disk are left untouched.
+//
+// Parameters:
+//   - `aGroup`: The group's name.
+//
+// Returns:
+//   - `bool`: `true` if the group was removed, `false` otherwise.
+func (r *TResolver) DeleteGroup(aGroup string) bool {
+	g := r.groups
+	if nil == g {
+		return false
+	}
+	g.Lock()
+	defer g.Unlock()
+
+	if _, ok := g.lists[aGroup]; !ok {
+		return false
+	}
+	delete(g.lists, aGroup)
+	delete(g.safe, aGroup)
+	g.clients = slices.DeleteFunc(g.clients, func(aCG tClientGroup) bool {
+		return aCG.group == aGroup
+	})
+	r.adguard.replace(aGroup, nil, nil)
+
+	return true
+} // DeleteGroup()
+
+// `FetchFor()` returns the IP addresses for a given hostname as
+// requested by the given client.
+//
+// The hostname is checked against the allow/deny list of the group
+// assigned to the client (see [AssignClient]); clients without a
+// group use the resolver's default list.
+//
+// Parameters:
+//   - `aClient`: The requesting client's IP address (may be `nil`).
+//   - `aHostname`: The hostname to resolve.
+//
+// Returns:
+//   - `[]net.IP`: List of IP addresses for the given hostname.
+//   - `error`: `nil` if the hostname was resolved successfully, the error otherwise.
+func (r *TResolver) FetchFor(aClient net.IP, aHostname string) ([]net.IP, error) {
+	list, safe := r.clientPolicy(aClient)
+
+	return r.fetch(list, safe, aClient, aHostname, 0)
+} // FetchFor()
+
+// `Groups()` returns the names of all groups.
+//
+// Returns:
+//   - `[]string`: The sorted list of group names.
+func (r *TResolver) Groups() []string {
+	g := r.groups
+	if nil == g {
+		return nil
+	}
+	g.RLock()
+	defer g.RUnlock()
+
+	result := make([]string, 0, len(g.lists))
+	for name := range g.lists {
+		result = append(result, name)
+	}
+	slices.Sort(result)
+
+	return result
+} // Groups()
+
+// `LoadGroupAllowlist()` loads a group's allowlist from the given file.
+//
+// Parameters:
+//   - `aGroup`: The group's name.
+//   - `aFilename`: The path/file name to read the 'allow' patterns from.
+//
+// Returns:
+//   - `error`: An error in case of problems, or `nil` otherwise.
+func (r *TResolver) LoadGroupAllowlist(aGroup, aFilename string) error {
+	list, err := r.groups.list(aGroup)
+	if nil != err {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second<<2)
+	defer cancel()
+
+	return list.LoadAllow(ctx, aFilename)
+} // LoadGroupAllowlist()
+
+// `LoadGroupBlocklists()` loads a group's blocklists from the given URLs.
+//
+// The AdGuard rules with `$client`, `$dnstype`, or `$dnsrewrite`
+// modifiers are applied as far as possible (see [BlockedType]).
+//
+// Parameters:
+//   - `aGroup`: The group's name.
+//   - `aURLs`: List of URLs to download blocklists from.
+//
+// Returns:
+//   - `error`: An error in case of problems, or `nil` otherwise.
+func (r *TResolver) LoadGroupBlocklists(aGroup string, aURLs []string) error {
+	list, err := r.groups.list(aGroup)
+	if nil != err {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second<<2)
+	defer cancel()
+
+	_, err = list.LoadDeny(ctx, aURLs)
+	r.applyAdGuard(aGroup, list.AdGuardRules())
+
+	return err
+} // LoadGroupBlocklists()
+
+// `SetGroupDefaultDeny()` switches a group's default-deny mode on or
+// off.
+//
+// In default-deny mode the group's clients can only resolve the
+// hostnames matched by the group's allow list; all other hostnames
+// are blocked.
+//
+// Parameters:
+//   - `aGroup`: The group's name.
+//   - `aDeny`: Whether to deny hostnames not in the group's allow list.
+//
+// Returns:
+//   - `error`: An error in case of problems, or `nil` otherwise.
+func (r *TResolver) SetGroupDefaultDeny(aGroup string, aDeny bool) error {
+	list, err := r.groups.list(aGroup)
+	if nil != err {
+		return err
+	}
+	list.SetDefaultDeny(aDeny)
+
+	return nil
+} // SetGroupDefaultDeny()
+
+// `SetGroupBlockSubdomains()` switches the implicit subdomain blocking
+// of a group's blocklists on or off (see [TResolverOptions]).
+//
+// The new setting applies to the next [LoadGroupBlocklists] call.
+//
+// Parameters:
+//   - `aGroup`: The group's name.
+//   - `aSubdomains`: Whether the lists' hostnames block their subdomains.
+//
+// Returns:
+//   - `error`: An error in case of problems, or `nil` otherwise.
+func (r *TResolver) SetGroupBlockSubdomains(aGroup string, aSubdomains bool) error {
+	list, err := r.groups.list(aGroup)
+	if nil != err {
+		return err
+	}
+	list.SetImplicitSubdomains(aSubdomains)
+
+	return nil
+} // SetGroupBlockSubdomains()
+
+// `SetGroupPrecedence()` sets the rule deciding about hostnames
+// matched by both the allow and the deny list of a group (see
+// [TResolverOptions]).
+//
+// Parameters:
+//   - `aGroup`: The group's name.
+//   - `aRule`: The rule to use (`PrecedenceNone` meaning the default).
+//
+// Returns:
+//   - `error`: An error in case of problems, or `nil` otherwise.
+func (r *TResolver) SetGroupPrecedence(aGroup string, aRule TPrecedence) error {
+	list, err := r.groups.list(aGroup)
+	if nil != err {
+		return err
+	}
+	list.SetPrecedence(aRule)
+
+	return nil
+} // SetGroupPrecedence()
+
+// `SetGroupSafeSearch()` switches a group's safe search enforcement
+// on or off (see [SetSafeSearch]).
+//
+// Parameters:
+//   - `aGroup`: The group's name.
+//   - `aSafe`: Whether to enforce safe search for the group's clients.
+//
+// Returns:
+//   - `error`: An error in case of problems, or `nil` otherwise.
+func (r *TResolver) SetGroupSafeSearch(aGroup string, aSafe bool) error {
+	g := r.groups
+	if nil == g {
+		return ErrUnknownGroup
+	}
+	g.Lock()
+	defer g.Unlock()
+
+	if _, ok := g.lists[aGroup]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownGroup, aGroup)
+	}
+	if aSafe {
+		g.safe[aGroup] = true
+	} else {
+		delete(g.safe, aGroup)
+	}
+
+	return nil
+} // SetGroupSafeSearch()
+
+// `UnassignClient()` removes a client address range assignment.
+//
+// Parameters:
+//   - `aCIDR`: The client's IP address or address range.
+//
+// Returns:
+//   - `bool`: `true` if the assignment was removed, `false` otherwise.
+func (r *TResolver) UnassignClient(aCIDR string) bool {
+	network, err := parseClientCIDR(aCIDR)
+	if nil != err {
+		return false
+	}
+	g := r.groups
+	if nil == g {
+		return false
+	}
+	g.Lock()
+	defer g.Unlock()
+
+	oldLen := len(g.clients)
+	cidr := network.String()
+	g.clients = slices.DeleteFunc(g.clients, func(aCG tClientGroup) bool {
+		return aCG.network.String() == cidr
+	})
+
+	return oldLen != len(g.clients)
+} // UnassignClient()
+
+/* _EoF_ */
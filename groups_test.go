@@ -0,0 +1,284 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"errors"
+	"net"
+	"slices"
+	"testing"
+
+	adl "github.com/mwat56/dnscache/internal/adlist"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_TResolver_AddGroup(t *testing.T) {
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir()})
+	defer r.StopExpire()
+
+	tests := []struct {
+		name      string
+		group     string
+		wantErr   error
+		wantNames []string
+	}{
+		/* */
+		{
+			name:      "01 - empty name",
+			group:     "",
+			wantErr:   ErrInvalidGroup,
+			wantNames: []string{},
+		},
+		{
+			name:      "02 - invalid name",
+			group:     "../kids",
+			wantErr:   ErrInvalidGroup,
+			wantNames: []string{},
+		},
+		{
+			name:      "03 - valid name",
+			group:     "kids",
+			wantNames: []string{"kids"},
+		},
+		{
+			name:      "04 - second group",
+			group:     "admin_1",
+			wantNames: []string{"admin_1", "kids"},
+		},
+		{
+			name:      "05 - existing group",
+			group:     "kids",
+			wantNames: []string{"admin_1", "kids"},
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := r.AddGroup(tc.group); !errors.Is(err, tc.wantErr) {
+				t.Errorf("AddGroup() error = '%v', want '%v'", err, tc.wantErr)
+			}
+			if got := r.Groups(); !slices.Equal(got, tc.wantNames) {
+				t.Errorf("Groups() = '%v', want '%v'", got, tc.wantNames)
+			}
+		})
+	}
+} // Test_TResolver_AddGroup()
+
+func Test_TResolver_ClientGroup(t *testing.T) {
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir()})
+	defer r.StopExpire()
+	_ = r.AddGroup("kids")
+	_ = r.AddGroup("tablet")
+	_ = r.AddGroup("admin")
+
+	for cidr, group := range map[string]string{
+		"192.168.1.0/24":  "kids",
+		"192.168.1.42":    "tablet",
+		"fd00:1::/64":     "admin",
+		"10.0.0.0/8":      "admin",
+		"172.16.0.0/12":   "kids",
+		"192.168.2.0/124": "kids", // invalid
+	} {
+		_ = r.AssignClient(cidr, group)
+	}
+	_ = r.AssignClient("10.0.0.0/8 ", "kids") // replaces the previous assignment
+	if err := r.AssignClient("192.168.3.0/24", "unknown"); !errors.Is(err, ErrUnknownGroup) {
+		t.Errorf("AssignClient() error = '%v', want '%v'", err, ErrUnknownGroup)
+	}
+	r.UnassignClient("172.16.0.0/12")
+
+	tests := []struct {
+		name   string
+		client net.IP
+		want   string
+	}{
+		/* */
+		{
+			name:   "01 - nil client",
+			client: nil,
+			want:   "",
+		},
+		{
+			name:   "02 - unassigned client",
+			client: net.ParseIP("192.168.2.1"),
+			want:   "",
+		},
+		{
+			name:   "03 - range",
+			client: net.ParseIP("192.168.1.1"),
+			want:   "kids",
+		},
+		{
+			name:   "04 - more specific address",
+			client: net.ParseIP("192.168.1.42"),
+			want:   "tablet",
+		},
+		{
+			name:   "05 - IPv6 range",
+			client: net.ParseIP("fd00:1::17"),
+			want:   "admin",
+		},
+		{
+			name:   "06 - replaced assignment",
+			client: net.ParseIP("10.1.2.3"),
+			want:   "kids",
+		},
+		{
+			name:   "07 - removed assignment",
+			client: net.ParseIP("172.16.1.1"),
+			want:   "",
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := r.ClientGroup(tc.client); got != tc.want {
+				t.Errorf("ClientGroup() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_TResolver_ClientGroup()
+
+func Test_TResolver_FetchFor(t *testing.T) {
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir()})
+	defer r.StopExpire()
+	_ = r.AddGroup("kids")
+	_ = r.AssignClient("192.168.1.0/24", "kids")
+	if err := r.AddGroupDeny("kids", "*.games.tld"); nil != err {
+		t.Fatalf("AddGroupDeny() error = '%v'", err)
+	}
+	if err := r.AddGroupDeny("nogroup", "*.games.tld"); !errors.Is(err, ErrUnknownGroup) {
+		t.Errorf("AddGroupDeny() error = '%v', want '%v'", err, ErrUnknownGroup)
+	}
+	r.adlist.AddDeny(context.TODO(), "*.ads.tld")
+	// Fixed answer to avoid network access for non-blocked clients
+	_ = r.AddRewrite(TRewriteRule{Match: "www.games.tld", Type: RewriteIP, Target: "198.51.100.1"})
+
+	tests := []struct {
+		name     string
+		client   net.IP
+		hostname string
+		want     net.IP
+	}{
+		/* */
+		{
+			name:     "01 - group client blocked",
+			client:   net.ParseIP("192.168.1.10"),
+			hostname: "play.games.tld",
+			want:     net.IPv4zero,
+		},
+		{
+			name:     "02 - rewrite beats group",
+			client:   net.ParseIP("192.168.1.10"),
+			hostname: "www.games.tld",
+			want:     net.ParseIP("198.51.100.1"),
+		},
+		{
+			name:     "03 - default client uses default list",
+			client:   net.ParseIP("192.168.2.10"),
+			hostname: "banner.ads.tld",
+			want:     net.IPv4zero,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := r.FetchFor(tc.client, tc.hostname)
+			if nil != err {
+				t.Errorf("FetchFor() error = '%v'", err)
+				return
+			}
+			if (1 != len(got)) || !got[0].Equal(tc.want) {
+				t.Errorf("FetchFor() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_TResolver_FetchFor()
+
+func Test_TResolver_SetGroupBlockSubdomains(t *testing.T) {
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir(), BlockSubdomains: true})
+	defer r.StopExpire()
+	if !r.adlist.ImplicitSubdomains() {
+		t.Error("ImplicitSubdomains() = 'false', want 'true' for the default list")
+	}
+	_ = r.AddGroup("kids")
+
+	if err := r.SetGroupBlockSubdomains("nogroup", true); !errors.Is(err, ErrUnknownGroup) {
+		t.Errorf("SetGroupBlockSubdomains() error = '%v', want '%v'", err, ErrUnknownGroup)
+	}
+	list, _ := r.groups.list("kids")
+	if !list.ImplicitSubdomains() {
+		t.Error("ImplicitSubdomains() = 'false', want 'true' for a new group")
+	}
+	if err := r.SetGroupBlockSubdomains("kids", false); nil != err {
+		t.Fatalf("SetGroupBlockSubdomains() error = '%v'", err)
+	}
+	if list.ImplicitSubdomains() {
+		t.Error("ImplicitSubdomains() = 'true', want 'false'")
+	}
+} // Test_TResolver_SetGroupBlockSubdomains()
+
+func Test_TResolver_SetGroupPrecedence(t *testing.T) {
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir(), Precedence: PrecedenceSpecific})
+	defer r.StopExpire()
+	if got := r.adlist.Precedence(); PrecedenceSpecific != got {
+		t.Errorf("Precedence() = '%v', want '%v' for the default list", got, PrecedenceSpecific)
+	}
+	_ = r.AddGroup("kids")
+
+	if err := r.SetGroupPrecedence("nogroup", PrecedenceAllow); !errors.Is(err, ErrUnknownGroup) {
+		t.Errorf("SetGroupPrecedence() error = '%v', want '%v'", err, ErrUnknownGroup)
+	}
+	list, _ := r.groups.list("kids")
+	if got := list.Precedence(); PrecedenceSpecific != got {
+		t.Errorf("Precedence() = '%v', want '%v' for a new group", got, PrecedenceSpecific)
+	}
+	if err := r.SetGroupPrecedence("kids", PrecedenceAllow); nil != err {
+		t.Fatalf("SetGroupPrecedence() error = '%v'", err)
+	}
+	if got := list.Precedence(); PrecedenceAllow != got {
+		t.Errorf("Precedence() = '%v', want '%v'", got, PrecedenceAllow)
+	}
+} // Test_TResolver_SetGroupPrecedence()
+
+func Test_TResolver_SetGroupDefaultDeny(t *testing.T) {
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir()})
+	defer r.StopExpire()
+	_ = r.AddGroup("kiosk")
+	_ = r.AssignClient("192.168.1.0/24", "kiosk")
+	_ = r.AddGroupAllow("kiosk", "*.school.tld")
+
+	if err := r.SetGroupDefaultDeny("nogroup", true); !errors.Is(err, ErrUnknownGroup) {
+		t.Errorf("SetGroupDefaultDeny() error = '%v', want '%v'", err, ErrUnknownGroup)
+	}
+	if err := r.SetGroupDefaultDeny("kiosk", true); nil != err {
+		t.Fatalf("SetGroupDefaultDeny() error = '%v'", err)
+	}
+
+	got, err := r.FetchFor(net.ParseIP("192.168.1.10"), "www.games.tld")
+	if nil != err {
+		t.Fatalf("FetchFor() error = '%v'", err)
+	}
+	if (1 != len(got)) || !got[0].Equal(net.IPv4zero) {
+		t.Errorf("FetchFor() = '%v', want '%v'", got, net.IPv4zero)
+	}
+
+	list, _ := r.groups.list("kiosk")
+	if got := list.Match(context.TODO(), "www.school.tld"); adl.ADallow != got {
+		t.Errorf("TADlist.Match() = '%v', want '%v'", got, adl.ADallow)
+	}
+	if r.adlist.DefaultDeny() {
+		t.Error("DefaultDeny() = 'true', want 'false' for the default list")
+	}
+} // Test_TResolver_SetGroupDefaultDeny()
+
+/* _EoF_ */
@@ -0,0 +1,157 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"net"
+	"sync"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `TBlockedHook` is called whenever a query is denied by an allow/deny list.
+	//
+	// Parameters:
+	//   - `aHostname`: The blocked hostname.
+	//   - `aClient`: The requesting client (`nil` if unknown).
+	TBlockedHook func(aHostname string, aClient net.IP)
+
+	// `TExpireHook` is called whenever a cache entry expired.
+	//
+	// Parameters:
+	//   - `aHostname`: The expired entry's hostname.
+	//   - `aIPs`: The expired entry's IP addresses.
+	TExpireHook func(aHostname string, aIPs []net.IP)
+
+	// `TRefreshHook` is called whenever a cache entry was refreshed.
+	//
+	// Parameters:
+	//   - `aHostname`: The refreshed hostname.
+	//   - `aOld`: The previously cached IP addresses.
+	//   - `aNew`: The new IP addresses (`nil` if the host doesn't exist anymore).
+	TRefreshHook func(aHostname string, aOld, aNew []net.IP)
+
+	// `tHooks` holds the registered lifecycle callbacks of a resolver.
+	tHooks struct {
+		sync.RWMutex
+		blocked []TBlockedHook
+		expire  []TExpireHook
+		refresh []TRefreshHook
+	}
+)
+
+// ---------------------------------------------------------------------------
+// `tHooks` methods:
+
+// `onBlocked()` calls all registered `TBlockedHook` functions.
+//
+// Parameters:
+//   - `aHostname`: The blocked hostname.
+//   - `aClient`: The requesting client (`nil` if unknown).
+func (h *tHooks) onBlocked(aHostname string, aClient net.IP) {
+	if nil == h {
+		return
+	}
+	h.RLock()
+	hooks := h.blocked
+	h.RUnlock()
+
+	for _, hook := range hooks {
+		hook(aHostname, aClient)
+	}
+} // onBlocked()
+
+// `onExpire()` calls all registered `TExpireHook` functions.
+//
+// Parameters:
+//   - `aHostname`: The expired entry's hostname.
+//   - `aIPs`: The expired entry's IP addresses.
+func (h *tHooks) onExpire(aHostname string, aIPs []net.IP) {
+	if nil == h {
+		return
+	}
+	h.RLock()
+	hooks := h.expire
+	h.RUnlock()
+
+	for _, hook := range hooks {
+		hook(aHostname, aIPs)
+	}
+} // onExpire()
+
+// `onRefresh()` calls all registered `TRefreshHook` functions.
+//
+// Parameters:
+//   - `aHostname`: The refreshed hostname.
+//   - `aOld`: The previously cached IP addresses.
+//   - `aNew`: The new IP addresses.
+func (h *tHooks) onRefresh(aHostname string, aOld, aNew []net.IP) {
+	if nil == h {
+		return
+	}
+	h.RLock()
+	hooks := h.refresh
+	h.RUnlock()
+
+	for _, hook := range hooks {
+		hook(aHostname, aOld, aNew)
+	}
+} // onRefresh()
+
+// ---------------------------------------------------------------------------
+// `TResolver` methods:
+
+// `OnBlocked()` registers a function to call whenever a query is
+// denied by an allow/deny list.
+//
+// The hooks are called synchronously while answering the query,
+// hence they should return quickly.
+//
+// Parameters:
+//   - `aHook`: The function to call.
+func (r *TResolver) OnBlocked(aHook TBlockedHook) {
+	if (nil == aHook) || (nil == r.hooks) {
+		return
+	}
+	r.hooks.Lock()
+	r.hooks.blocked = append(r.hooks.blocked, aHook)
+	r.hooks.Unlock()
+} // OnBlocked()
+
+// `OnExpire()` registers a function to call whenever a cache entry
+// was removed because its time to live has passed.
+//
+// Parameters:
+//   - `aHook`: The function to call.
+func (r *TResolver) OnExpire(aHook TExpireHook) {
+	if (nil == aHook) || (nil == r.hooks) {
+		return
+	}
+	r.hooks.Lock()
+	r.hooks.expire = append(r.hooks.expire, aHook)
+	r.hooks.Unlock()
+} // OnExpire()
+
+// `OnRefresh()` registers a function to call whenever a cache entry
+// was refreshed (see [TResolver.Refresh]).
+//
+// The function receives both, the old and the new IP addresses, so
+// it can e.g. drop connections to addresses no longer in use.
+//
+// Parameters:
+//   - `aHook`: The function to call.
+func (r *TResolver) OnRefresh(aHook TRefreshHook) {
+	if (nil == aHook) || (nil == r.hooks) {
+		return
+	}
+	r.hooks.Lock()
+	r.hooks.refresh = append(r.hooks.refresh, aHook)
+	r.hooks.Unlock()
+} // OnRefresh()
+
+/* _EoF_ */
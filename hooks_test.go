@@ -0,0 +1,126 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"net"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_TResolver_OnBlocked(t *testing.T) {
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir()})
+	defer r.StopExpire()
+	_ = r.AddDeny("ads.example.com")
+
+	var (
+		gotHost   string
+		gotClient net.IP
+	)
+	r.OnBlocked(nil) // must be ignored
+	r.OnBlocked(func(aHostname string, aClient net.IP) {
+		gotHost, gotClient = aHostname, aClient
+	})
+
+	client := net.ParseIP("192.168.1.10")
+	if _, err := r.FetchFor(client, "ads.example.com"); nil != err {
+		t.Fatalf("FetchFor() error = '%v'", err)
+	}
+	if ("ads.example.com" != gotHost) || !client.Equal(gotClient) {
+		t.Errorf("OnBlocked() got '%v', '%v', want 'ads.example.com', '%v'",
+			gotHost, gotClient, client)
+	}
+
+	if _, err := r.Fetch("ads.example.com"); nil != err {
+		t.Fatalf("Fetch() error = '%v'", err)
+	}
+	if nil != gotClient {
+		t.Errorf("OnBlocked() client = '%v', want 'nil'", gotClient)
+	}
+} // Test_TResolver_OnBlocked()
+
+func Test_TResolver_OnExpire(t *testing.T) {
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir()})
+	defer r.StopExpire()
+
+	var got []string
+	r.OnExpire(func(aHostname string, aIPs []net.IP) {
+		got = append(got, aHostname)
+	})
+	r.OnExpire(func(aHostname string, aIPs []net.IP) {
+		got = append(got, aHostname)
+	})
+	r.hooks.onExpire("example.com", []net.IP{net.ParseIP("192.0.2.1")})
+
+	if want := []string{"example.com", "example.com"}; !slices.Equal(got, want) {
+		t.Errorf("OnExpire() got '%v', want '%v'", got, want)
+	}
+} // Test_TResolver_OnExpire()
+
+func Test_TResolver_OnRefresh(t *testing.T) {
+	ip := net.ParseIP("192.168.1.20")
+	startMDNSResponder(t, "printer.local", ip)
+
+	r := NewWithOptions(TResolverOptions{
+		DataDir:    t.TempDir(),
+		DNSservers: []string{"192.0.2.1"}, // must not be asked
+		MDNS:       true,
+	})
+	defer r.StopExpire()
+	r.refresh.pace = 0
+	r.retries = 1 // don't wait for the vanished host repeatedly
+
+	var (
+		mtx sync.Mutex
+		got = map[string][2][]net.IP{}
+	)
+	r.OnRefresh(func(aHostname string, aOld, aNew []net.IP) {
+		mtx.Lock()
+		got[aHostname] = [2][]net.IP{aOld, aNew}
+		mtx.Unlock()
+	})
+
+	ctx := context.Background()
+	oldIPs := []net.IP{net.ParseIP("10.0.0.1")}
+	for _, hostname := range []string{"printer.local", "scanner.local"} {
+		r.ICacheList.Create(ctx, hostname, oldIPs, time.Hour)
+	}
+	r.Refresh()
+
+	tests := []struct {
+		name     string
+		hostname string
+		wantNew  []net.IP
+	}{
+		/* */
+		{"01 - changed addresses", "printer.local", []net.IP{ip}},
+		{"02 - vanished host", "scanner.local", nil},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ips, ok := got[tc.hostname]
+			if !ok {
+				t.Fatalf("OnRefresh() not called for %q", tc.hostname)
+			}
+			if !slices.EqualFunc(ips[0], oldIPs, net.IP.Equal) {
+				t.Errorf("OnRefresh() old = '%v', want '%v'", ips[0], oldIPs)
+			}
+			if !slices.EqualFunc(ips[1], tc.wantNew, net.IP.Equal) {
+				t.Errorf("OnRefresh() new = '%v', want '%v'", ips[1], tc.wantNew)
+			}
+		})
+	}
+} // Test_TResolver_OnRefresh()
+
+/* _EoF_ */
@@ -0,0 +1,200 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `TAdGuardRule` is a blocking rule of an AdGuard filter list
+	// whose modifiers can't be expressed by the deny list itself.
+	//
+	// The rules are collected while loading the lists and are
+	// available by [TADlist.AdGuardRules] afterwards.
+	//
+	//   - `Pattern`: The hostname pattern (e.g. `*.example.com`).
+	//   - `Clients`: The values of the `$client` modifier; a leading
+	//     `~` excludes a client.
+	//   - `DNSTypes`: The values of the `$dnstype` modifier; a leading
+	//     `~` excludes a query type.
+	//   - `Rewrite`: The value of the `$dnsrewrite` modifier.
+	//   - `Important`: Whether the `$important` modifier was given.
+	TAdGuardRule struct {
+		Pattern   string   `json:"pattern"`
+		Clients   []string `json:"clients,omitempty"`
+		DNSTypes  []string `json:"dnsTypes,omitempty"`
+		Rewrite   string   `json:"rewrite,omitempty"`
+		Important bool     `json:"important,omitempty"`
+	}
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `adguardValues()` splits the `|` separated values of an AdGuard
+// modifier, removing the quotes of client names.
+//
+// Parameters:
+//   - `aValue`: The modifier's value.
+//
+// Returns:
+//   - `rValues`: The non-empty values.
+func adguardValues(aValue string) (rValues []string) {
+	for _, value := range strings.Split(aValue, "|") {
+		exclude := strings.HasPrefix(value, "~")
+		value = strings.Trim(strings.TrimPrefix(value, "~"), `'"`)
+		if value = strings.ReplaceAll(value, `\'`, "'"); "" == value {
+			continue
+		}
+		if exclude {
+			value = "~" + value
+		}
+		rValues = append(rValues, value)
+	}
+
+	return
+} // adguardValues()
+
+// `parseAdGuardRule()` processes a line of an AdGuard filter list
+// having modifiers (i.e. `rule$modifier,modifier=value`).
+//
+// Supported are the DNS related modifiers `$important`, `$client`,
+// `$dnstype`, and `$dnsrewrite`; lines with other modifiers (e.g.
+// `$third-party` or `$badfilter`) are rejected.
+//
+// Parameters:
+//   - `aLine`: The line to process.
+//
+// Returns:
+//   - `rRule`: The rule's hostname pattern and modifiers.
+//   - `rReason`: Why the line was rejected, empty if it's valid.
+func parseAdGuardRule(aLine string) (rRule TAdGuardRule, rReason string) {
+	// A regular expression might contain a `$` as well
+	idx := strings.LastIndex(aLine, "$")
+	if (0 >= idx) || strings.HasPrefix(aLine, "/") {
+		rReason = reasonABPRule
+		return
+	}
+	pattern, ok := processABPLine(aLine[:idx])
+	if !ok || strings.Contains(pattern, ",") {
+		rReason = reasonABPRule
+		return
+	}
+	rRule.Pattern = pattern
+
+	for _, modifier := range strings.Split(aLine[idx+1:], ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(modifier), "=")
+		switch strings.ToLower(name) {
+		case "important":
+			rRule.Important = true
+
+		case "client":
+			if rRule.Clients = adguardValues(value); 0 == len(rRule.Clients) {
+				rReason = reasonAdGuard
+			}
+
+		case "dnstype":
+			if rRule.DNSTypes = adguardValues(strings.ToUpper(value)); 0 == len(rRule.DNSTypes) {
+				rReason = reasonAdGuard
+			}
+
+		case "dnsrewrite":
+			rRule.Rewrite = strings.TrimSpace(value)
+
+		default:
+			rReason = reasonAdGuard
+		}
+		if "" != rReason {
+			return
+		}
+	}
+
+	return
+} // parseAdGuardRule()
+
+// ---------------------------------------------------------------------------
+// `tABPLoader` methods:
+
+// `loadAdGuardRule()` processes a line of an AdGuard filter list
+// having modifiers.
+//
+// A rule with just the `$important` modifier is added to the node
+// like any other ABP rule since the exception rules (`@@`) aren't
+// loaded anyway; the other rules are collected by the loader's
+// statistics.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//   - `aLine`: The line to process.
+//   - `aNode`: The node to add the pattern to.
+//
+// Returns:
+//   - `bool`: `true` if the rule was accepted, `false` otherwise.
+func (al *tABPLoader) loadAdGuardRule(aCtx context.Context, aLine string, aNode *tNode) bool {
+	rule, reason := parseAdGuardRule(aLine)
+	if "" != reason {
+		al.stats.reject(reason)
+		return false
+	}
+	pattern, ok := toASCII(rule.Pattern, al.stats)
+	if !ok {
+		return false
+	}
+	if !al.validation.isValid(pattern) {
+		al.stats.reject(reasonHostname)
+		return false
+	}
+	rule.Pattern = pattern
+
+	if (0 == len(rule.Clients)) && (0 == len(rule.DNSTypes)) && ("" == rule.Rewrite) {
+		if parts := pattern2parts(pattern); 0 < len(parts) {
+			aNode.add(aCtx, parts)
+			al.stats.accept()
+			return true
+		}
+		return false
+	}
+	al.stats.rule(rule)
+
+	return true
+} // loadAdGuardRule()
+
+// ---------------------------------------------------------------------------
+// `TADlist` methods:
+
+// `AdGuardRules()` returns the AdGuard rules of the last [LoadDeny]
+// call which couldn't be added to the deny list because of their
+// modifiers.
+//
+// It's up to the caller to apply them, e.g. by client specific
+// lists, query type filters, or rewrites.
+//
+// Returns:
+//   - `[]TAdGuardRule`: The rules in the order of the lists.
+func (adl *TADlist) AdGuardRules() []TAdGuardRule {
+	if nil == adl {
+		return nil
+	}
+	rules := adl.rules.Load()
+	if nil == rules {
+		return nil
+	}
+	result := slices.Clone(*rules)
+	for idx := range result {
+		result[idx].Clients = slices.Clone(result[idx].Clients)
+		result[idx].DNSTypes = slices.Clone(result[idx].DNSTypes)
+	}
+
+	return result
+} // AdGuardRules()
+
+/* _EoF_ */
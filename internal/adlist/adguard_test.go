@@ -0,0 +1,165 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_adguardValues(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		/* */
+		{"01 - empty", "", nil},
+		{"02 - single", "192.168.1.5", []string{"192.168.1.5"}},
+		{"03 - several", "A|~AAAA||HTTPS", []string{"A", "~AAAA", "HTTPS"}},
+		{"04 - quoted names", `'Frank\'s laptop'|~"kids"`, []string{"Frank's laptop", "~kids"}},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := adguardValues(tc.value); !slices.Equal(got, tc.want) {
+				t.Errorf("adguardValues() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_adguardValues()
+
+func Test_parseAdGuardRule(t *testing.T) {
+	tests := []struct {
+		name       string
+		line       string
+		want       TAdGuardRule
+		wantReason string
+	}{
+		/* */
+		{"01 - important", "||ads.example.com^$important",
+			TAdGuardRule{Pattern: "*.ads.example.com", Important: true}, ""},
+		{"02 - client", "||ads.example.com^$client=192.168.1.0/24|kids",
+			TAdGuardRule{Pattern: "*.ads.example.com", Clients: []string{"192.168.1.0/24", "kids"}}, ""},
+		{"03 - dnstype", "|tracker.example.com^$dnstype=aaaa|~https",
+			TAdGuardRule{Pattern: "tracker.example.com", DNSTypes: []string{"AAAA", "~HTTPS"}}, ""},
+		{"04 - dnsrewrite", "||home.example.com^$dnsrewrite=NOERROR;A;192.168.1.1",
+			TAdGuardRule{Pattern: "*.home.example.com", Rewrite: "NOERROR;A;192.168.1.1"}, ""},
+		{"05 - combined", "||ads.example.com^$important,client=kids,dnstype=A",
+			TAdGuardRule{Pattern: "*.ads.example.com", Clients: []string{"kids"},
+				DNSTypes: []string{"A"}, Important: true}, ""},
+		{"06 - browser modifier", "||ads.example.com^$third-party", TAdGuardRule{}, reasonAdGuard},
+		{"07 - empty client", "||ads.example.com^$client=", TAdGuardRule{}, reasonAdGuard},
+		{"08 - regular expression", `/^ad[0-9]+\.example\.com$/$important`, TAdGuardRule{}, reasonABPRule},
+		{"09 - path", "||example.com/ads/$important", TAdGuardRule{}, reasonABPRule},
+		{"10 - no rule", "$important", TAdGuardRule{}, reasonABPRule},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, reason := parseAdGuardRule(tc.line)
+			if reason != tc.wantReason {
+				t.Fatalf("parseAdGuardRule() reason = '%s', want '%s'", reason, tc.wantReason)
+			}
+			if ("" == reason) && !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("parseAdGuardRule() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_parseAdGuardRule()
+
+func Test_tABPLoader_loadAdGuardRule(t *testing.T) {
+	var stats tLoadStats
+	loader := &tABPLoader{tLoadOptions{stats: &stats}}
+	node := newNode()
+	list := strings.Join([]string{
+		"||ads.example.com^$important",
+		"||tracker.example.com^$client=kids",
+		"||ads.example.com^$script,third-party",
+		"||invalid^$important",
+	}, "\n")
+
+	if err := loader.LoadFrom(context.TODO(), strings.NewReader(list), node); nil != err {
+		t.Fatalf("tABPLoader.LoadFrom() error = '%v'", err)
+	}
+	if got := node.allPatterns(context.TODO()); !slices.Equal(got, []string{"*.ads.example.com"}) {
+		t.Errorf("patterns = '%v', want '[*.ads.example.com]'", got)
+	}
+	want := []TAdGuardRule{{Pattern: "*.tracker.example.com", Clients: []string{"kids"}}}
+	if !reflect.DeepEqual(stats.rules, want) {
+		t.Errorf("rules = '%v', want '%v'", stats.rules, want)
+	}
+	if (1 != stats.reasons[reasonAdGuard]) || (1 != stats.reasons[reasonHostname]) {
+		t.Errorf("reasons = '%v', want one of each", stats.reasons)
+	}
+
+	// A list of rules not fitting the trie isn't empty
+	list = "||tracker.example.com^$dnstype=HTTPS"
+	if err := loader.LoadFrom(context.TODO(), strings.NewReader(list), newNode()); nil != err {
+		t.Errorf("tABPLoader.LoadFrom() error = '%v'", err)
+	}
+} // Test_tABPLoader_loadAdGuardRule()
+
+func Test_TADlist_AdGuardRules(t *testing.T) {
+	if err := SetDownloadOptions(TDownloadOptions{Retries: -1}); nil != err {
+		t.Fatal(err)
+	}
+	defer adDownloader.Store(nil)
+
+	server := httptest.NewServer(http.HandlerFunc(func(aWriter http.ResponseWriter, aRequest *http.Request) {
+		switch aRequest.URL.Path {
+		case "/first":
+			_, _ = aWriter.Write([]byte("[Adblock Plus 2.0]\n||ads.example.com^\n" +
+				"||tracker.example.com^$dnstype=HTTPS\n"))
+		case "/second":
+			_, _ = aWriter.Write([]byte("[Adblock Plus 2.0]\n||ads.example.net^\n" +
+				"||home.example.net^$dnsrewrite=192.168.1.1\n"))
+		default:
+			http.NotFound(aWriter, aRequest)
+		}
+	}))
+	defer server.Close()
+
+	var adl *TADlist
+	if got := adl.AdGuardRules(); nil != got {
+		t.Errorf("TADlist.AdGuardRules() = '%v', want 'nil'", got)
+	}
+	adl = newTestList(t)
+	if got := adl.AdGuardRules(); nil != got {
+		t.Errorf("TADlist.AdGuardRules() = '%v', want 'nil'", got)
+	}
+	if _, err := adl.LoadDeny(context.TODO(), []string{server.URL + "/first", server.URL + "/second"}); nil != err {
+		t.Fatalf("TADlist.LoadDeny() error = '%v'", err)
+	}
+
+	want := []TAdGuardRule{
+		{Pattern: "*.tracker.example.com", DNSTypes: []string{"HTTPS"}},
+		{Pattern: "*.home.example.net", Rewrite: "192.168.1.1"},
+	}
+	got := adl.AdGuardRules()
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("TADlist.AdGuardRules() = '%v', want '%v'", got, want)
+	}
+	got[0].DNSTypes[0] = "A"
+	if again := adl.AdGuardRules(); "HTTPS" != again[0].DNSTypes[0] {
+		t.Error("TADlist.AdGuardRules() returned the list's own rules")
+	}
+	if r := adl.Match(context.TODO(), "www.tracker.example.com"); ADneutral != r {
+		t.Errorf("TADlist.Match() = '%v', want '%v'", r, ADneutral)
+	}
+} // Test_TADlist_AdGuardRules()
+
+/* _EoF_ */
@@ -10,11 +10,15 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"iter"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -27,14 +31,59 @@ type (
 	// `TADlist` is a list of allow and deny patterns for FQDN hosts
 	// and wildcards.
 	TADlist struct {
-		datadir string // directory for local storage
-		allow   *tTrie
-		deny    *tTrie
+		datadir   string // directory for local storage
+		allow     *tTrie
+		deny      *tTrie
+		decisions *tDecisionCache             // recent `Match()` results
+		blocked   *TTopK                      // most often denied hostnames
+		hits      tHitCounter                 // matches per deny pattern
+		pause     tPause                      // temporary exceptions
+		compile   atomic.Bool                 // compile the deny list after reloads
+		filter    atomic.Bool                 // Bloom filter the deny list after reloads
+		defDeny   atomic.Bool                 // deny hostnames not in the allow list
+		validate  atomic.Uint32               // `TValidation` of downloaded lists
+		subdomain atomic.Bool                 // block the subdomains of downloaded lists' hostnames
+		prefer    atomic.Uint32               // `TPrecedence` of matching allow and deny patterns
+		report    atomic.Pointer[TLoadReport] // last `LoadDeny()`'s statistics
+		sources   atomic.Pointer[tSources]    // last `LoadDeny()`'s blocklists
+		progress  atomic.Pointer[TProgressFunc]
+		rules     atomic.Pointer[[]TAdGuardRule] // last `LoadDeny()`'s AdGuard rules
+	}
+
+	// `TADpattern` is a pattern of the allow or deny list as
+	// returned by [TADlist.Find].
+	TADpattern struct {
+		Pattern string    `json:"pattern"`
+		Origin  TADresult `json:"origin"` // `ADallow` or `ADdeny`
+	}
+
+	// `TADmatch` explains a decision of the allow and deny lists as
+	// returned by [TADlist.MatchEx] and [TADlist.Explain].
+	//
+	//   - `Pattern`: The allow or deny pattern causing the decision,
+	//     empty for a temporary exception or default-deny decision.
+	//   - `Source`: The URL of the blocklist providing a deny pattern,
+	//     empty for allow patterns and those added by [TADlist.AddDeny]
+	//     or loaded from the local copy.
+	//   - `Result`: The decision itself.
+	//   - `Origin`: The list the pattern belongs to (`ADallow` or
+	//     `ADdeny`), `ADneutral` if no pattern was involved.
+	//   - `Rule`: The precedence rule applied if both lists matched
+	//     (see [TADlist.SetPrecedence]).
+	TADmatch struct {
+		Pattern string      `json:"pattern,omitempty"`
+		Source  string      `json:"source,omitempty"`
+		Result  TADresult   `json:"result"`
+		Origin  TADresult   `json:"origin"`
+		Rule    TPrecedence `json:"rule,omitempty"`
 	}
 
 	// `TADresult` is the result type of a test by [TADlist.Match].
 	TADresult int8
 
+	// `TListFormat` is the output format of [TADlist.WriteDeny].
+	TListFormat uint8
+
 	// `ADlistError` is a special error type for `TADlist` errors.
 	ADlistError struct {
 		error
@@ -51,6 +100,16 @@ const (
 	// `ADneutral` is the result of a test by [TADlist.Match].
 	ADneutral = TADresult(0)
 
+	// `ListSimple` writes one hostname pattern per line.
+	ListSimple = TListFormat(0)
+
+	// `ListHosts` writes `hosts(5)` lines mapping the patterns to
+	// `127.0.0.1`.
+	ListHosts = TListFormat(1)
+
+	// `ListABP` writes an ABP filter list.
+	ListABP = TListFormat(2)
+
 	// `adAllowFile` is the default filename for the allow list.
 	adAllowFile = "allow.txt"
 
@@ -96,18 +155,27 @@ func New(aDataDir string) *TADlist {
 	}
 
 	adl := TADlist{
-		datadir: aDataDir,
-		allow:   newTrie(),
-		deny:    newTrie(),
+		datadir:   aDataDir,
+		allow:     newTrie(),
+		deny:      newTrie(),
+		decisions: newDecisionCache(adDecisionCacheSize),
+		blocked:   NewTopK(DefaultTopKCapacity, DefaultTopKWindow),
 	}
+	adl.hits.since = time.Now()
 
+	// Lists written by a newer version are set aside instead of
+	// being overwritten by the next `StoreAllow()`/`StoreDeny()`.
+	// The lists are stored in the data directory even if there's no
+	// file to load yet.
 	fName := filepath.Join(adl.datadir, adAllowFile)
 	fName, _ = filepath.Abs(fName)
-	_ = adl.allow.loadLocal(context.Background(), fName)
+	adl.allow.filename = fName
+	preserveNewerList(fName, adl.allow.loadLocal(context.Background(), fName))
 
 	fName = filepath.Join(adl.datadir, adDenyFile)
 	fName, _ = filepath.Abs(fName)
-	_ = adl.deny.loadLocal(context.Background(), fName)
+	adl.deny.filename = fName
+	preserveNewerList(fName, adl.deny.loadLocal(context.Background(), fName))
 
 	return &adl
 } // New()
@@ -206,7 +274,9 @@ func addPattern(aCtx context.Context, aHostname string, aList *tTrie) bool {
 		return false
 	}
 
+	aList.saving.Add(1)
 	go func() {
+		defer aList.saving.Done()
 		defer cancel() // Ensure cancel is called after work
 		_ = aList.storeFile(ctx, aList.filename)
 	}()
@@ -229,7 +299,12 @@ func (adl *TADlist) AddAllow(aCtx context.Context, aHostname string) bool {
 		return false
 	}
 
-	return addPattern(aCtx, aHostname, adl.allow)
+	if !addPattern(aCtx, aHostname, adl.allow) {
+		return false
+	}
+	adl.decisions.clear()
+
+	return true
 } // AddAllow()
 
 // `AddDeny()` inserts a FQDN name/pattern (with optional wildcard) into
@@ -246,9 +321,79 @@ func (adl *TADlist) AddDeny(aCtx context.Context, aHostname string) bool {
 		return false
 	}
 
-	return addPattern(aCtx, aHostname, adl.deny)
+	if !addPattern(aCtx, aHostname, adl.deny) {
+		return false
+	}
+	adl.decisions.clear()
+
+	return true
 } // AddDeny()
 
+// `AllowPatterns()` returns all patterns of the allow list.
+//
+// Parameters:
+//   - `aCtx`: The context to use for the operation.
+//
+// Returns:
+//   - `[]string`: The sorted list of allowed patterns.
+func (adl *TADlist) AllowPatterns(aCtx context.Context) []string {
+	if nil == adl {
+		return nil
+	}
+
+	return adl.allow.AllPatterns(aCtx)
+} // AllowPatterns()
+
+// `AllowSeq()` returns an iterator over the patterns of the allow list.
+//
+// Other than [AllowPatterns] the patterns are produced lazily in sorted
+// order. The allow list is read-locked during the loop, so the loop body
+// mustn't change it.
+//
+// Parameters:
+//   - `aCtx`: The context to use for the operation.
+//
+// Returns:
+//   - `iter.Seq[string]`: The iterator over the allowed patterns.
+func (adl *TADlist) AllowSeq(aCtx context.Context) iter.Seq[string] {
+	if nil == adl {
+		return func(func(string) bool) {}
+	}
+
+	return adl.allow.Patterns(aCtx)
+} // AllowSeq()
+
+// `Counters()` returns the counters of the allow and deny lists.
+//
+// Other than [Metrics] this method doesn't force a garbage collection
+// cycle, so it's suited to be called frequently (e.g. by monitoring).
+//
+// Returns:
+//   - `rAllow`: Current counters of the allow list.
+//   - `rDeny`: Current counters of the deny list.
+func (adl *TADlist) Counters() (rAllow, rDeny *TCounters) {
+	if nil == adl {
+		return
+	}
+	rAllow = adl.allow.counters()
+	rDeny = adl.deny.counters()
+
+	return
+} // Counters()
+
+// `DefaultDeny()` reports whether hostnames not in the allow list
+// are denied (see [SetDefaultDeny]).
+//
+// Returns:
+//   - `bool`: `true` if the list denies by default, `false` otherwise.
+func (adl *TADlist) DefaultDeny() bool {
+	if nil == adl {
+		return false
+	}
+
+	return adl.defDeny.Load()
+} // DefaultDeny()
+
 // `deletePattern()` removes a FQDN name/pattern (with optional wildcard)
 // from the given list.
 //
@@ -278,7 +423,9 @@ func deletePattern(aCtx context.Context, aHostname string, aList *tTrie) bool {
 		return false
 	}
 
+	aList.saving.Add(1)
 	go func() {
+		defer aList.saving.Done()
 		defer cancel() // Ensure cancel is called after work
 		_ = aList.storeFile(ctx, aList.filename)
 	}()
@@ -301,7 +448,12 @@ func (adl *TADlist) DeleteAllow(aCtx context.Context, aHostname string) bool {
 		return false
 	}
 
-	return deletePattern(aCtx, aHostname, adl.allow)
+	if !deletePattern(aCtx, aHostname, adl.allow) {
+		return false
+	}
+	adl.decisions.clear()
+
+	return true
 } // DeleteAllow()
 
 // `DeleteDeny()` removes a FQDN name/pattern (with optional wildcard)
@@ -318,9 +470,48 @@ func (adl *TADlist) DeleteDeny(aCtx context.Context, aHostname string) bool {
 		return false
 	}
 
-	return deletePattern(aCtx, aHostname, adl.deny)
+	if !deletePattern(aCtx, aHostname, adl.deny) {
+		return false
+	}
+	adl.decisions.clear()
+
+	return true
 } // DeleteDeny()
 
+// `DenyPatterns()` returns all patterns of the deny list.
+//
+// Parameters:
+//   - `aCtx`: The context to use for the operation.
+//
+// Returns:
+//   - `[]string`: The sorted list of denied patterns.
+func (adl *TADlist) DenyPatterns(aCtx context.Context) []string {
+	if nil == adl {
+		return nil
+	}
+
+	return adl.deny.AllPatterns(aCtx)
+} // DenyPatterns()
+
+// `DenySeq()` returns an iterator over the patterns of the deny list.
+//
+// Other than [DenyPatterns] the patterns are produced lazily in sorted
+// order. The deny list is read-locked during the loop, so the loop body
+// mustn't change it.
+//
+// Parameters:
+//   - `aCtx`: The context to use for the operation.
+//
+// Returns:
+//   - `iter.Seq[string]`: The iterator over the denied patterns.
+func (adl *TADlist) DenySeq(aCtx context.Context) iter.Seq[string] {
+	if nil == adl {
+		return func(func(string) bool) {}
+	}
+
+	return adl.deny.Patterns(aCtx)
+} // DenySeq()
+
 // `Equal()` checks whether the two lists are equal.
 //
 // NOTE: This method is of nor practical use apart from unit-testing.
@@ -348,6 +539,134 @@ func (adl *TADlist) Equal(aList *TADlist) (rOK bool) {
 	return
 } // Equal()
 
+// `Explain()` checks whether the given hostname should be allowed or
+// blocked like [Match] and additionally returns the pattern which
+// caused the decision, the list it belongs to, and – for a deny
+// pattern – the blocklist providing it.
+//
+// If the hostname is matched by both the allow and the deny list, the
+// precedence rule deciding between them is returned as well (see
+// [SetPrecedence]).
+//
+// Other than [Match] and [MatchEx] the hostname isn't counted for the
+// statistics returned by [TopBlocked].
+//
+// Parameters:
+//   - `aCtx`: The context to use for the operation.
+//   - `aHostname`: The hostname to check.
+//
+// Returns:
+//   - `TADmatch`: The decision and its reasons.
+func (adl *TADlist) Explain(aCtx context.Context, aHostname string) TADmatch {
+	if nil == adl {
+		return TADmatch{}
+	}
+	result, _ := adl.match(aCtx, aHostname)
+
+	return adl.explain(aCtx, aHostname, result)
+} // Explain()
+
+// `explain()` returns the reasons of the given decision.
+//
+// Parameters:
+//   - `aCtx`: The context to use for the operation.
+//   - `aHostname`: The hostname checked.
+//   - `aResult`: The decision about the hostname.
+//
+// Returns:
+//   - `rMatch`: The decision and its reasons.
+func (adl *TADlist) explain(aCtx context.Context, aHostname string, aResult TADresult) (rMatch TADmatch) {
+	rMatch.Result = aResult
+
+	var other string
+	switch aResult {
+	case ADallow:
+		rMatch.Pattern = adl.allow.matchingPattern(aCtx, aHostname)
+		other = adl.deny.matchingPattern(aCtx, aHostname)
+	case ADdeny:
+		rMatch.Pattern = adl.deny.matchingPattern(aCtx, aHostname)
+		other = adl.allow.matchingPattern(aCtx, aHostname)
+		if sources := adl.sources.Load(); nil != sources {
+			rMatch.Source = sources.find(rMatch.Pattern)
+		}
+	default:
+		// no pattern involved
+	}
+	if "" == rMatch.Pattern {
+		rMatch.Source = ""
+		return
+	}
+	rMatch.Origin = aResult
+	if ("" != other) && !adl.pause.isDenyPaused() &&
+		!adl.pause.isAllowed(strings.TrimSpace(aHostname)) {
+		rMatch.Rule = adl.Precedence()
+	}
+
+	return
+} // explain()
+
+// `Find()` returns the patterns of the allow and deny lists matching
+// the given query.
+//
+// A query containing wildcards (`*`, `?`, or `[…]`) is matched against
+// the whole pattern, otherwise all patterns containing the query are
+// returned; the comparison is case-insensitive. This helps answering
+// questions like "is doubleclick blocked and by what rule?".
+//
+// Parameters:
+//   - `aCtx`: The context to use for the operation.
+//   - `aQuery`: The substring or wildcard to look for.
+//
+// Returns:
+//   - `[]TADpattern`: The matching allow patterns followed by the
+//     matching deny patterns.
+func (adl *TADlist) Find(aCtx context.Context, aQuery string) (rList []TADpattern) {
+	query := strings.Trim(strings.ToLower(strings.TrimSpace(aQuery)), ".")
+	if (nil == adl) || ("" == query) {
+		return
+	}
+
+	match := func(aPattern string) bool {
+		return strings.Contains(aPattern, query)
+	}
+	if strings.ContainsAny(query, "*?[") {
+		if _, err := path.Match(query, ""); nil != err {
+			return // malformed wildcard
+		}
+		match = func(aPattern string) bool {
+			ok, _ := path.Match(query, aPattern)
+			return ok
+		}
+	}
+
+	for _, list := range []struct {
+		trie   *tTrie
+		origin TADresult
+	}{{adl.allow, ADallow}, {adl.deny, ADdeny}} {
+		for _, pattern := range list.trie.AllPatterns(aCtx) {
+			if match(strings.ToLower(pattern)) {
+				rList = append(rList, TADpattern{Pattern: pattern, Origin: list.origin})
+			}
+		}
+	}
+
+	return
+} // Find()
+
+// `ImplicitSubdomains()` reports whether the bare domains of the
+// downloaded lists block their subdomains as well (see
+// [SetImplicitSubdomains]).
+//
+// Returns:
+//   - `bool`: `true` if subdomains are blocked, `false` otherwise.
+func (adl *TADlist) ImplicitSubdomains() bool {
+	if nil == adl {
+		return false
+	}
+
+	return adl.subdomain.Load()
+} // ImplicitSubdomains()
+
 // `LoadAllow()` reads hostname patterns (FQDN or wildcards) from
 // `aFilename` and inserts them into the allow list.
 //
@@ -379,6 +698,7 @@ func (adl *TADlist) LoadAllow(aCtx context.Context, aFilename string) (rErr erro
 	defer cancel() // Ensure cancel is called
 
 	rErr = adl.allow.loadLocal(ctx, aFilename)
+	adl.decisions.clear()
 
 	return
 } // LoadAllow()
@@ -397,10 +717,11 @@ func (adl *TADlist) LoadAllow(aCtx context.Context, aFilename string) (rErr erro
 //   - `aURL`: The URL to download the host patterns from.
 //   - `aDir`: The directory name to save the file in.
 //   - `aList`: The deny list to add the patterns to.
+//   - `aStats`: Optional counters of the loaded lines and patterns.
 //
 // Returns:
 //   - `error`: An error in case of problems, or `nil` otherwise.
-func loadRemoteDeny(aCtx context.Context, aURL, aDir string, aList *tTrie) (rErr error) {
+func loadRemoteDeny(aCtx context.Context, aURL, aDir string, aList *tTrie, aStats *tLoadStats) (rErr error) {
 	// No need to check arguments as that is done by the calling method.
 	if destUrl, err := url.Parse(aURL); nil == err {
 		// Turn URL string into net.URL and check for validity
@@ -431,7 +752,7 @@ func loadRemoteDeny(aCtx context.Context, aURL, aDir string, aList *tTrie) (rErr
 	ctx, cancel := context.WithTimeout(aCtx, time.Second<<2)
 	defer cancel() // Ensure cancel is called
 
-	rErr = aList.loadRemote(ctx, aURL, filename)
+	rErr = aList.loadRemote(ctx, aURL, filename, aStats)
 
 	return
 } // loadRemoteDeny()
@@ -445,22 +766,37 @@ func loadRemoteDeny(aCtx context.Context, aURL, aDir string, aList *tTrie) (rErr
 // If `aURLs` is empty or the list itself is empty, the method
 // returns an error.
 //
+// The new deny list is built completely while the current one keeps
+// serving queries; it's swapped in under a brief write lock whose
+// duration is available by [Metrics].
+//
+// The returned report lists the lines read, the patterns added, the
+// duplicates, and the rejected entries (with their reasons) per URL;
+// it's available by [LoadReport] afterwards as well.
+// A function set by [SetProgress] is informed about the progress
+// while the lists are loaded.
+//
+// Internationalised hostnames are converted to their punycode form.
+// The hashes of each list's patterns are kept to tell the blocklist a
+// deny pattern came from (see [MatchEx]).
+//
 // Parameters:
 //   - `aCtx`: The context to use for the operation.
 //   - `aURLs`: The URLs to download the host patterns from.
 //
 // Returns:
+//   - `TLoadReport`: The statistics of the URLs' lists.
 //   - `error`: An error in case of problems, or `nil` otherwise.
 //
 // see [LoadAllow], [StoreDeny]
-func (adl *TADlist) LoadDeny(aCtx context.Context, aURLs []string) error {
+func (adl *TADlist) LoadDeny(aCtx context.Context, aURLs []string) (TLoadReport, error) {
 	if nil == adl {
-		return ErrListNil
+		return TLoadReport{}, ErrListNil
 	}
 
 	uLen := len(aURLs)
 	if 0 == uLen {
-		return ErrInvalidUrl
+		return TLoadReport{}, ErrInvalidUrl
 	}
 
 	var (
@@ -470,10 +806,17 @@ func (adl *TADlist) LoadDeny(aCtx context.Context, aURLs []string) error {
 	)
 	// Buffered channel prevents blocking and deadlocks
 	errChan := make(chan error, uLen)
-	newRoot := newTrie()
+	validation := adl.Validation()
+	subdomains := adl.ImplicitSubdomains()
+	lists := make([]*tTrie, uLen) // one trie per URL
+	report := TLoadReport{Started: time.Now()}
+	sources := make([]TSourceStats, uLen)
+	rules := make([][]TAdGuardRule, uLen)
+	origins := make([]tSource, uLen)
+	loaded := make([]bool, uLen)
 
 	// Process all provided URLs
-	for _, uri := range aURLs {
+	for idx, uri := range aURLs {
 		// Avoid closure capturing of loop variables
 		uri := strings.TrimSpace(uri)
 		if 0 == len(uri) {
@@ -481,20 +824,85 @@ func (adl *TADlist) LoadDeny(aCtx context.Context, aURLs []string) error {
 		}
 
 		wg.Add(1)
-		go func(aUrl string) {
+		loaded[idx] = true
+		lists[idx] = newTrie()
+		lists[idx].validation = validation
+		lists[idx].subdomains = subdomains
+		go func(aUrl string, aList *tTrie, aSource *TSourceStats, aRules *[]TAdGuardRule, aOrigin *tSource) {
 			defer wg.Done()
-			if err := loadRemoteDeny(aCtx, aUrl, adl.datadir, newRoot); nil != err {
+			var stats tLoadStats
+			progress := adl.progress.Load()
+			if nil != progress {
+				stats.progress = func(aLines, aAdded uint32) {
+					(*progress)(TLoadProgress{Started: report.Started,
+						Source: aUrl, Lines: aLines, Added: aAdded})
+				}
+				stats.progress(0, 0)
+			}
+			start := time.Now()
+			err := loadRemoteDeny(aCtx, aUrl, adl.datadir, aList, &stats)
+			*aSource = stats.report(aUrl, err, time.Since(start))
+			*aRules = stats.rules
+			*aOrigin = newSource(aCtx, aUrl, aList.root.node)
+			if nil != progress {
+				(*progress)(TLoadProgress{Started: report.Started,
+					Source: aUrl, Lines: stats.lines, Added: stats.added,
+					Done: true})
+			}
+			if nil != err {
 				// Send error to channel
 				errChan <- fmt.Errorf("URL %q: %w", aUrl, err)
 			}
-		}(uri)
+		}(uri, lists[idx], &sources[idx], &rules[idx], &origins[idx])
 	}
 	wg.Wait()
 	close(errChan) // Safe closure after all sends are done
 
+	report.Sources = make([]TSourceStats, 0, uLen)
+	found := make(tSources, 0, uLen)
+	for idx := range sources {
+		if loaded[idx] {
+			report.Sources = append(report.Sources, sources[idx])
+			found = append(found, origins[idx])
+		}
+	}
+
 	for err = range errChan {
 		errs = append(errs, err)
 	}
+
+	// Build the new deny list completely in the background, i.e.
+	// without blocking the running queries, and then swap it in
+	var node *tNode
+	for _, list := range lists {
+		if nil != list {
+			node = node.merge(aCtx, list.root.node)
+		}
+	}
+	if err = aCtx.Err(); nil != err {
+		// Don't replace the deny list by an incomplete one
+		errs = append(errs, err)
+	} else if (nil != node) && (0 < node.tChildren.Size()) {
+		var (
+			compiled *tCompiled
+			filter   *tBloom
+		)
+		if adl.compile.Load() {
+			compiled = compileNode(aCtx, node)
+		}
+		if adl.filter.Load() {
+			filter = newBloom(aCtx, node)
+		}
+		adl.deny.swap(node, compiled, filter)
+		adl.sources.Store(&found)
+		adl.decisions.clear()
+	}
+	if nil == aCtx.Err() {
+		// The AdGuard rules of all lists in the order of the URLs
+		adguard := slices.Concat(rules...)
+		adl.rules.Store(&adguard)
+	}
+
 	if 0 < len(errs) {
 		if 1 < len(errs) {
 			// Join all errors into a single one
@@ -504,21 +912,41 @@ func (adl *TADlist) LoadDeny(aCtx context.Context, aURLs []string) error {
 			err = errs[0]
 		}
 	}
+	report.Duration = time.Since(report.Started)
+	adl.report.Store(&report)
 
-	if 0 < len(newRoot.root.node.tChildren) {
-		// Replace the old deny list with the new one
-		adl.deny.root.Lock()
-		adl.deny.root.node = newRoot.root.node
-		adl.deny.root.Unlock()
+	return report.clone(), err
+} // LoadDeny()
+
+// `LoadReport()` returns the statistics of the last [LoadDeny] call.
+//
+// Returns:
+//   - `TLoadReport`: The report, a zero value if no list was loaded yet.
+func (adl *TADlist) LoadReport() TLoadReport {
+	if nil == adl {
+		return TLoadReport{}
 	}
 
-	return err
-} // LoadDeny()
+	return adl.report.Load().clone()
+} // LoadReport()
 
 // `Match()` checks whether the given hostname should be allowed or blocked.
 //
 // The method returns `ADallow` if the hostname is in the allow list,
 // `ADdeny` if it is in the deny list, and `ADneutral` otherwise.
+// In default-deny mode (see [SetDefaultDeny]) `ADdeny` is returned
+// instead of `ADneutral`.
+//
+// The most recent decisions are cached, so repeated queries for the
+// same hostname don't have to walk the tries again. The cache is
+// invalidated by every change of the allow or deny list.
+//
+// A hostname matched by both lists is allowed or denied according to
+// the list's precedence rule (see [SetPrecedence] and [Explain]).
+// Temporary exceptions (see [AllowTemporarily] and [PauseDeny]) take
+// precedence over both lists. Hostnames denied by a deny pattern are
+// counted for the statistics returned by [TopBlocked], the matching
+// deny patterns for those returned by [HitCounts].
 //
 // Parameters:
 //   - `aCtx`: The context to use for the operation.
@@ -531,26 +959,91 @@ func (adl *TADlist) Match(aCtx context.Context, aHostname string) TADresult {
 		return ADneutral
 	}
 
+	// Only hostnames actually matched by a deny pattern are counted
+	result, pattern := adl.match(aCtx, aHostname)
+	if "" != pattern {
+		adl.blocked.Add(aHostname)
+		adl.hits.add(pattern)
+	}
+
+	return result
+} // Match()
+
+// `MatchEx()` checks whether the given hostname should be allowed or
+// blocked like [Match] but returns the reasons of the decision as
+// well (see [Explain]).
+//
+// Since the patterns have to be looked up after the decision, this
+// method is slower than [Match] and meant for logging and explaining
+// decisions rather than for answering queries.
+//
+// Parameters:
+//   - `aCtx`: The context to use for the operation.
+//   - `aHostname`: The hostname to check.
+//
+// Returns:
+//   - `TADmatch`: The decision and its reasons.
+func (adl *TADlist) MatchEx(aCtx context.Context, aHostname string) TADmatch {
+	if nil == adl {
+		return TADmatch{}
+	}
+
+	return adl.explain(aCtx, aHostname, adl.Match(aCtx, aHostname))
+} // MatchEx()
+
+// `match()` checks whether the given hostname should be allowed or blocked.
+//
+// Parameters:
+//   - `aCtx`: The context to use for the operation.
+//   - `aHostname`: The hostname to check.
+//
+// Returns:
+//   - `rResult`: The result of the lookup.
+//   - `rPattern`: The deny pattern causing an `ADdeny` result, if any.
+func (adl *TADlist) match(aCtx context.Context, aHostname string) (rResult TADresult, rPattern string) {
+
 	if aHostname = strings.TrimSpace(aHostname); 0 == len(aHostname) {
-		return ADdeny
+		return ADdeny, ""
 	}
 
 	if nil != aCtx.Err() {
-		return ADneutral
+		return ADneutral, ""
+	}
+
+	if adl.pause.isAllowed(aHostname) {
+		return ADallow, ""
+	}
+
+	if adl.pause.isDenyPaused() {
+		if adl.allow.Match(aCtx, aHostname) {
+			return ADallow, ""
+		}
+		return ADneutral, ""
+	}
+
+	// Cached decisions don't need a lookup at all
+	rResult, rPattern, gen, ok := adl.decisions.get(aHostname)
+	if ok {
+		return
 	}
 
 	ctx, cancel := context.WithTimeout(aCtx, time.Second<<2)
 	defer cancel() // Ensure cancel is called
 
 	var (
-		// `allowOK` and `denyOK` are used to store the results
+		// `allowOK` and `denyPattern` are used to store the results
 		// of the concurrent lookups in the allow and deny lists.
-		allowOK, denyOK atomic.Bool
-		wg              sync.WaitGroup
+		allowOK     atomic.Bool
+		denyPattern string
+		wg          sync.WaitGroup
 	)
 	wg.Add(2)
 	go func() {
-		denyOK.Store(adl.deny.Match(ctx, aHostname))
+		// Only a matching hostname (which is cached afterwards)
+		// needs its pattern to be looked up.
+		if adl.deny.Match(ctx, aHostname) {
+			denyPattern = adl.deny.matchingPattern(ctx, aHostname)
+		}
 		wg.Done()
 	}()
 
@@ -562,16 +1055,128 @@ func (adl *TADlist) Match(aCtx context.Context, aHostname string) TADresult {
 	wg.Wait()
 
 	// The allow list is usually shorter (and more specific) than the
-	// block list. Hence we give it preference.
-	if allowOK.Load() {
-		return ADallow
+	// block list. Hence we give it preference unless the deny list's
+	// pattern is more specific and that's what counts.
+	allowed, denied := allowOK.Load(), ("" != denyPattern)
+	if allowed && denied && (PrecedenceSpecific == adl.Precedence()) {
+		allowed = !moreSpecific(denyPattern,
+			adl.allow.matchingPattern(ctx, aHostname))
 	}
-	if denyOK.Load() {
-		return ADdeny
+	rPattern = ""
+	if allowed {
+		rResult = ADallow
+	} else if denied {
+		rResult, rPattern = ADdeny, denyPattern
+	} else if adl.defDeny.Load() {
+		rResult = ADdeny
+	} else {
+		rResult = ADneutral
 	}
 
-	return ADneutral
-} // Match()
+	// Don't remember results of interrupted lookups
+	if nil == ctx.Err() {
+		adl.decisions.put(aHostname, rResult, rPattern, gen)
+	}
+
+	return
+} // match()
+
+// `Metrics()` returns the current metrics data of the allow and deny lists.
+//
+// NOTE: Since the trie's metrics include some runtime statistics, each
+// call of this method forces a garbage collection cycle.
+//
+// Returns:
+//   - `rAllow`: Current metrics data of the allow list.
+//   - `rDeny`: Current metrics data of the deny list.
+func (adl *TADlist) Metrics() (rAllow, rDeny *TMetrics) {
+	if nil == adl {
+		return
+	}
+	rAllow = adl.allow.Metrics()
+	rDeny = adl.deny.Metrics()
+
+	return
+} // Metrics()
+
+// `SetDefaultDeny()` switches the default-deny mode on or off.
+//
+// In default-deny mode every hostname not matched by the allow list
+// is denied, turning the allow list into the only source of allowed
+// hostnames (e.g. for kiosk systems). While the deny list is paused
+// (see [PauseDeny]) hostnames not in the allow list stay neutral.
+//
+// Parameters:
+//   - `aDeny`: Whether to deny hostnames not in the allow list.
+func (adl *TADlist) SetDefaultDeny(aDeny bool) {
+	if nil == adl {
+		return
+	}
+
+	if aDeny != adl.defDeny.Swap(aDeny) {
+		adl.decisions.clear()
+	}
+} // SetDefaultDeny()
+
+// `SetImplicitSubdomains()` switches the implicit subdomain blocking
+// of the downloaded lists on or off.
+//
+// By default a hostname like `domain.tld` in a list blocks just that
+// name. With implicit subdomain blocking (as known from Pi-hole) it's
+// treated like `domain.tld` plus `*.domain.tld`, i.e. it blocks all
+// its subdomains as well. Patterns containing a wildcard aren't
+// affected. The implied wildcards are neither listed nor stored (see
+// [StoreDeny]), and their matches count for the hostname's pattern.
+// The new setting applies to the next [LoadDeny] call.
+//
+// Parameters:
+//   - `aSubdomains`: Whether to block the lists' hostnames' subdomains.
+func (adl *TADlist) SetImplicitSubdomains(aSubdomains bool) {
+	if nil == adl {
+		return
+	}
+
+	adl.subdomain.Store(aSubdomains)
+} // SetImplicitSubdomains()
+
+// `SetProgress()` sets the function called while [LoadDeny] loads
+// the lists.
+//
+// The function is called with zero counts when a source's loading
+// starts, every 10,000 lines read, and once more when the source is
+// done.
+//
+// Parameters:
+//   - `aFunc`: The function to call, `nil` to remove it.
+func (adl *TADlist) SetProgress(aFunc TProgressFunc) {
+	if nil == adl {
+		return
+	}
+	if nil == aFunc {
+		adl.progress.Store(nil)
+		return
+	}
+
+	adl.progress.Store(&aFunc)
+} // SetProgress()
+
+// `SetValidation()` sets the strictness of the hostname checks used
+// by [LoadDeny].
+//
+// The default `ValidateStrict` drops hostnames whose top-level domain
+// isn't known, e.g. intranet names like `foo.corp` or `router`; use
+// `ValidateRelaxed` to keep those. The new setting applies to the
+// next download.
+//
+// Parameters:
+//   - `aLevel`: The strictness to use.
+func (adl *TADlist) SetValidation(aLevel TValidation) {
+	if nil == adl {
+		return
+	}
+
+	adl.validate.Store(uint32(aLevel))
+} // SetValidation()
 
 // `Shutdown()` releases all resources used by the list.
 //
@@ -586,6 +1191,9 @@ func (adl *TADlist) Shutdown() (rErr error) {
 	}
 	var errs []error
 
+	// Don't let background stores overwrite the final ones
+	adl.Wait()
+
 	if rErr = adl.StoreAllow(context.Background()); nil != rErr {
 		errs = append(errs, rErr)
 	}
@@ -619,7 +1227,7 @@ func (adl *TADlist) Shutdown() (rErr error) {
 //   - `error`: `nil` if the patterns were written successfully, the error
 //     otherwise.
 func storeList(aCtx context.Context, aList *tTrie) error {
-	if (nil == aList) || (0 == len(aList.root.node.tChildren)) {
+	if (nil == aList) || (0 == aList.root.node.tChildren.Size()) {
 		return ErrListNil
 	}
 	ctx, cancel := context.WithTimeout(aCtx, time.Second<<2)
@@ -701,6 +1309,24 @@ func (adl *TADlist) String() string {
 // `updatePattern()` replaces an old pattern with a new one in the given list.
 //
 // This function is not exported, as it is only used internally by the
+// `TopBlocked()` returns the most often denied hostnames.
+//
+// The counts cover the current and the previous time window of
+// `DefaultTopKWindow` length.
+//
+// Parameters:
+//   - `aCount`: The max. number of hostnames to return (`0` for all).
+//
+// Returns:
+//   - `[]TTopEntry`: The hostnames sorted by decreasing count.
+func (adl *TADlist) TopBlocked(aCount int) []TTopEntry {
+	if nil == adl {
+		return nil
+	}
+
+	return adl.blocked.Top(aCount)
+} // TopBlocked()
+
 // `UpdateAllow()` and `UpdateDeny()` methods.
 //
 // Parameters:
@@ -731,7 +1357,9 @@ func updatePattern(aCtx context.Context, aOldPattern, aNewPattern string, aList
 		return false
 	}
 
+	aList.saving.Add(1)
 	go func() {
+		defer aList.saving.Done()
 		defer cancel() // Ensure cancel is called after work
 		_ = aList.storeFile(ctx, aList.filename)
 	}()
@@ -754,7 +1382,12 @@ func (adl *TADlist) UpdateAllow(aCtx context.Context, aOldPattern, aNewPattern s
 		return false
 	}
 
-	return updatePattern(aCtx, aOldPattern, aNewPattern, adl.allow)
+	if !updatePattern(aCtx, aOldPattern, aNewPattern, adl.allow) {
+		return false
+	}
+	adl.decisions.clear()
+
+	return true
 } // UpdateAllow()
 
 // `UpdateDeny()` replaces an old pattern with a new one in the deny list.
@@ -771,7 +1404,69 @@ func (adl *TADlist) UpdateDeny(aCtx context.Context, aOldPattern, aNewPattern st
 		return false
 	}
 
-	return updatePattern(aCtx, aOldPattern, aNewPattern, adl.deny)
+	if !updatePattern(aCtx, aOldPattern, aNewPattern, adl.deny) {
+		return false
+	}
+	adl.decisions.clear()
+
+	return true
 } // UpdateDeny()
 
+// `Validation()` returns the strictness of the hostname checks used
+// by [LoadDeny] (see [SetValidation]).
+//
+// Returns:
+//   - `TValidation`: The current strictness.
+func (adl *TADlist) Validation() TValidation {
+	if nil == adl {
+		return ValidateStrict
+	}
+
+	return TValidation(adl.validate.Load()) //#nosec G115
+} // Validation()
+
+// `Wait()` waits until the lists' changes made by [AddAllow],
+// [AddDeny], [DeleteAllow], [DeleteDeny], [UpdateAllow], and
+// [UpdateDeny] are written to the local files in the background.
+func (adl *TADlist) Wait() {
+	if nil == adl {
+		return
+	}
+	for _, list := range []*tTrie{adl.allow, adl.deny} {
+		if nil != list {
+			list.saving.Wait()
+		}
+	}
+} // Wait()
+
+// `WriteDeny()` writes all patterns of the (merged) deny list to the
+// writer in the given format, e.g. for use by other ad blockers.
+//
+// Parameters:
+//   - `aCtx`: The context to use for the operation.
+//   - `aWriter`: The writer to write the patterns to.
+//   - `aFormat`: The output format to use.
+//
+// Returns:
+//   - `error`: `nil` if the patterns were written successfully, the error otherwise.
+func (adl *TADlist) WriteDeny(aCtx context.Context, aWriter io.Writer, aFormat TListFormat) error {
+	if nil == adl {
+		return ErrListNil
+	}
+
+	var saver ISaver
+	switch aFormat {
+	case ListSimple:
+		saver = &tSimpleSaver{}
+	case ListHosts:
+		saver = &tHostsSaver{}
+	case ListABP:
+		saver = &tABPSaver{}
+	default:
+		return fmt.Errorf("invalid list format: %d", aFormat)
+	}
+
+	return adl.deny.save(aCtx, aWriter, saver)
+} // WriteDeny()
+
 /* _EoF_ */
@@ -7,14 +7,33 @@ Copyright © 2025  M.Watermann, 10247 Berlin, Germany
 package adlist
 
 import (
+	"bytes"
 	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 //lint:file-ignore ST1017 - I prefer Yoda conditions
 
+// `newTestList()` returns a new list using a temporary directory,
+// waiting for the list's background stores before the directory
+// gets removed.
+func newTestList(tb testing.TB) *TADlist {
+	adl := New(tb.TempDir())
+	tb.Cleanup(adl.Wait)
+
+	return adl
+} // newTestList()
+
 func Test_New(t *testing.T) {
 	tests := []struct {
 		name string
@@ -49,6 +68,11 @@ func Test_New(t *testing.T) {
 				t.Errorf("New() =\n%q\nwant\n%q",
 					got, tc.want)
 			}
+			// Changes are stored in the data directory
+			if dir := filepath.Dir(got.deny.filename); dir != tc.dir {
+				t.Errorf("New() deny list directory = %q, want %q",
+					dir, tc.dir)
+			}
 		})
 	}
 } // Test_New()
@@ -69,13 +93,13 @@ func Test_TADlist_AddAllow(t *testing.T) {
 		},
 		{
 			name:    "02 - empty pattern",
-			adl:     New(t.TempDir()),
+			adl:     newTestList(t),
 			pattern: "",
 			wantOK:  false,
 		},
 		{
 			name:    "03 - add tld",
-			adl:     New(t.TempDir()),
+			adl:     newTestList(t),
 			pattern: "tld",
 			wantOK:  true,
 		},
@@ -111,13 +135,13 @@ func Test_TADlist_AddDeny(t *testing.T) {
 		},
 		{
 			name:    "02 - empty pattern",
-			adl:     New(t.TempDir()),
+			adl:     newTestList(t),
 			pattern: "",
 			wantOK:  false,
 		},
 		{
 			name:    "03 - add tld",
-			adl:     New(t.TempDir()),
+			adl:     newTestList(t),
 			pattern: "tld",
 			wantOK:  true,
 		},
@@ -137,6 +161,49 @@ func Test_TADlist_AddDeny(t *testing.T) {
 	}
 } // Test_TADlist_AddDeny()
 
+func Test_TADlist_Counters(t *testing.T) {
+	ctx := context.TODO()
+	adl := newTestList(t)
+	adl.AddDeny(ctx, "ads.domain.tld")
+	adl.AddDeny(ctx, "*.tracker.tld")
+
+	tests := []struct {
+		name         string
+		change       func()
+		wantPatterns uint32
+	}{
+		/* */
+		{"01 - added patterns", func() {}, 2},
+		{"02 - unchanged list", func() {}, 2},
+		{"03 - deleted pattern", func() { adl.DeleteDeny(ctx, "ads.domain.tld") }, 1},
+		{"04 - added pattern", func() { adl.AddDeny(ctx, "www.domain.tld") }, 2},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.change()
+			gotAllow, gotDeny := adl.Counters()
+			if (nil == gotAllow) || (nil == gotDeny) {
+				t.Fatal("TADlist.Counters() = nil, want non-nil")
+			}
+			if gotDeny.Patterns != tc.wantPatterns {
+				t.Errorf("TADlist.Counters() deny patterns = '%d', want '%d'",
+					gotDeny.Patterns, tc.wantPatterns)
+			}
+			if 0 != gotAllow.Patterns {
+				t.Errorf("TADlist.Counters() allow patterns = '%d', want '0'",
+					gotAllow.Patterns)
+			}
+		})
+	}
+
+	var nilList *TADlist
+	if gotAllow, gotDeny := nilList.Counters(); (nil != gotAllow) || (nil != gotDeny) {
+		t.Error("TADlist.Counters() = non-nil, want 'nil'")
+	}
+} // Test_TADlist_Counters()
+
 func Test_TADlist_DeleteAllow(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -153,20 +220,20 @@ func Test_TADlist_DeleteAllow(t *testing.T) {
 		},
 		{
 			name:    "02 - empty pattern",
-			adl:     New(t.TempDir()),
+			adl:     newTestList(t),
 			pattern: "",
 			wantOK:  false,
 		},
 		{
 			name:    "03 - delete missing tld",
-			adl:     New(t.TempDir()),
+			adl:     newTestList(t),
 			pattern: "tld",
 			wantOK:  false,
 		},
 		{
 			name: "04 - delete added tld",
 			adl: func() *TADlist {
-				a := New(t.TempDir())
+				a := newTestList(t)
 				a.AddAllow(context.TODO(), "tld")
 				return a
 			}(),
@@ -176,7 +243,7 @@ func Test_TADlist_DeleteAllow(t *testing.T) {
 		{
 			name: "05 - delete added domain.tld",
 			adl: func() *TADlist {
-				a := New(t.TempDir())
+				a := newTestList(t)
 				a.AddAllow(context.TODO(), "domain.tld")
 				return a
 			}(),
@@ -186,7 +253,7 @@ func Test_TADlist_DeleteAllow(t *testing.T) {
 		{
 			name: "06 - delete added sub.domain.tld",
 			adl: func() *TADlist {
-				a := New(t.TempDir())
+				a := newTestList(t)
 				a.AddAllow(context.TODO(), "sub.domain.tld")
 				return a
 			}(),
@@ -225,20 +292,20 @@ func Test_TADlist_DeleteDeny(t *testing.T) {
 		},
 		{
 			name:    "02 - empty pattern",
-			adl:     New(t.TempDir()),
+			adl:     newTestList(t),
 			pattern: "",
 			wantOK:  false,
 		},
 		{
 			name:    "03 - delete missing tld",
-			adl:     New(t.TempDir()),
+			adl:     newTestList(t),
 			pattern: "tld",
 			wantOK:  false,
 		},
 		{
 			name: "04 - delete added tld",
 			adl: func() *TADlist {
-				a := New(t.TempDir())
+				a := newTestList(t)
 				a.AddDeny(context.TODO(), "tld")
 				return a
 			}(),
@@ -248,7 +315,7 @@ func Test_TADlist_DeleteDeny(t *testing.T) {
 		{
 			name: "05 - delete added domain.tld",
 			adl: func() *TADlist {
-				a := New(t.TempDir())
+				a := newTestList(t)
 				a.AddDeny(context.TODO(), "domain.tld")
 				return a
 			}(),
@@ -258,7 +325,7 @@ func Test_TADlist_DeleteDeny(t *testing.T) {
 		{
 			name: "06 - delete added sub.domain.tld",
 			adl: func() *TADlist {
-				a := New(t.TempDir())
+				a := newTestList(t)
 				a.AddDeny(context.TODO(), "sub.domain.tld")
 				return a
 			}(),
@@ -282,7 +349,7 @@ func Test_TADlist_DeleteDeny(t *testing.T) {
 } // Test_TADlist_DeleteDeny()
 
 func Test_TADlist_Equal(t *testing.T) {
-	adlist := New(t.TempDir())
+	adlist := newTestList(t)
 	tests := []struct {
 		name   string
 		adl    *TADlist
@@ -311,7 +378,7 @@ func Test_TADlist_Equal(t *testing.T) {
 		{
 			name:   "04 - equal lists",
 			adl:    adlist,
-			other:  New(t.TempDir()),
+			other:  newTestList(t),
 			wantOK: true,
 		},
 		{
@@ -323,14 +390,14 @@ func Test_TADlist_Equal(t *testing.T) {
 		{
 			name: "05 - equal lists with allow patterns",
 			adl: func() *TADlist {
-				a := New(t.TempDir())
+				a := newTestList(t)
 				a.AddAllow(context.TODO(), "tld")
 				a.AddAllow(context.TODO(), "domain.tld")
 				a.AddAllow(context.TODO(), "sub.domain.tld")
 				return a
 			}(),
 			other: func() *TADlist {
-				a := New(t.TempDir())
+				a := newTestList(t)
 				a.AddAllow(context.TODO(), "tld")
 				a.AddAllow(context.TODO(), "domain.tld")
 				a.AddAllow(context.TODO(), "sub.domain.tld")
@@ -341,14 +408,14 @@ func Test_TADlist_Equal(t *testing.T) {
 		{
 			name: "06 - equal lists with deny patterns",
 			adl: func() *TADlist {
-				a := New(t.TempDir())
+				a := newTestList(t)
 				a.AddDeny(context.TODO(), "tld")
 				a.AddDeny(context.TODO(), "domain.tld")
 				a.AddDeny(context.TODO(), "sub.domain.tld")
 				return a
 			}(),
 			other: func() *TADlist {
-				a := New(t.TempDir())
+				a := newTestList(t)
 				a.AddDeny(context.TODO(), "tld")
 				a.AddDeny(context.TODO(), "domain.tld")
 				a.AddDeny(context.TODO(), "sub.domain.tld")
@@ -359,7 +426,7 @@ func Test_TADlist_Equal(t *testing.T) {
 		{
 			name: "07 - lists with different allow and deny patterns",
 			adl: func() *TADlist {
-				a := New(t.TempDir())
+				a := newTestList(t)
 				a.AddAllow(context.TODO(), "tld")
 				a.AddAllow(context.TODO(), "domain.tld")
 				a.AddAllow(context.TODO(), "sub.domain.tld")
@@ -369,7 +436,7 @@ func Test_TADlist_Equal(t *testing.T) {
 				return a
 			}(),
 			other: func() *TADlist {
-				a := New(t.TempDir())
+				a := newTestList(t)
 				a.AddAllow(context.TODO(), "domain.tld")
 				a.AddAllow(context.TODO(), "sub.domain.tld")
 				a.AddDeny(context.TODO(), "domain.tld")
@@ -411,13 +478,13 @@ func Test_TADlist_LoadAllow(t *testing.T) {
 		},
 		{
 			name:    "02 - non-existent file",
-			adl:     New(t.TempDir()),
+			adl:     newTestList(t),
 			fName:   "doesnotexist-02.txt",
 			wantErr: true,
 		},
 		{
 			name: "03 - valid file with empty lines",
-			adl:  New(t.TempDir()),
+			adl:  newTestList(t),
 			fName: func() string {
 				fName := filepath.Join(t.TempDir(), "allow-03.txt")
 				f, _ := os.Create(fName)
@@ -429,7 +496,7 @@ func Test_TADlist_LoadAllow(t *testing.T) {
 		},
 		{
 			name: "04 - valid file with comments",
-			adl:  New(t.TempDir()),
+			adl:  newTestList(t),
 			fName: func() string {
 				fName := filepath.Join(t.TempDir(), "allow-04.txt")
 				f, _ := os.Create(fName)
@@ -471,13 +538,13 @@ func Test_TADlist_LoadDeny(t *testing.T) {
 		},
 		{
 			name:    "02 - non-existent file",
-			adl:     New(t.TempDir()),
+			adl:     newTestList(t),
 			urls:    []string{"http://example.com/deny.txt"},
 			wantErr: true,
 		},
 		{
 			name:    "03 - valid file with empty lines",
-			adl:     New(t.TempDir()),
+			adl:     newTestList(t),
 			urls:    []string{"http://example.com/deny.txt"},
 			wantErr: true,
 		},
@@ -487,7 +554,7 @@ func Test_TADlist_LoadDeny(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			gotErr := tc.adl.LoadDeny(context.TODO(), tc.urls)
+			_, gotErr := tc.adl.LoadDeny(context.TODO(), tc.urls)
 
 			if (nil != gotErr) != tc.wantErr {
 				t.Errorf("TADlist.LoadDeny() error = '%v', wantErr '%v'",
@@ -497,6 +564,135 @@ func Test_TADlist_LoadDeny(t *testing.T) {
 	}
 } // Test_TADlist_LoadDeny()
 
+func Test_TADlist_LoadDeny_swap(t *testing.T) {
+	if err := SetDownloadOptions(TDownloadOptions{Retries: -1}); nil != err {
+		t.Fatal(err)
+	}
+	defer adDownloader.Store(nil)
+
+	server := httptest.NewServer(http.HandlerFunc(func(aWriter http.ResponseWriter, aRequest *http.Request) {
+		switch aRequest.URL.Path {
+		case "/ads":
+			_, _ = aWriter.Write([]byte("ads.localdomain\n*.banner.localdomain\n"))
+		default:
+			_, _ = aWriter.Write([]byte("tracker.localdomain\n"))
+		}
+	}))
+	defer server.Close()
+	ctx := context.TODO()
+
+	adl := newTestList(t)
+	adl.SetCompiled(ctx, true)
+	adl.SetFiltered(ctx, true)
+	urls := []string{server.URL + "/ads", server.URL + "/tracker"}
+	if _, err := adl.LoadDeny(ctx, urls); nil != err {
+		t.Fatalf("TADlist.LoadDeny() error = '%v'", err)
+	}
+
+	// The patterns of all URLs are merged
+	for _, hostname := range []string{"ads.localdomain", "top.banner.localdomain", "tracker.localdomain"} {
+		if got := adl.Match(ctx, hostname); ADdeny != got {
+			t.Errorf("TADlist.Match(%q) = '%v', want '%v'", hostname, got, ADdeny)
+		}
+	}
+	if got := adl.deny.compiled.Load(); nil == got {
+		t.Error("TADlist.LoadDeny() didn't compile the new list")
+	}
+	if got := adl.deny.filter.Load(); nil == got {
+		t.Error("TADlist.LoadDeny() didn't filter the new list")
+	}
+
+	_, deny := adl.Metrics()
+	if (1 != deny.Reloads) || (0 == deny.MaxSwapNs) || (deny.SwapNs > deny.MaxSwapNs) {
+		t.Errorf("TADlist.Metrics() = '%v', want 1 reload with swap times", deny)
+	}
+
+	// A cancelled load keeps the current list
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := adl.LoadDeny(cancelled, urls[1:]); nil == err {
+		t.Error("TADlist.LoadDeny() error = 'nil', want an error")
+	}
+	if got := adl.Match(ctx, "ads.localdomain"); ADdeny != got {
+		t.Errorf("TADlist.Match(%q) = '%v', want '%v'", "ads.localdomain", got, ADdeny)
+	}
+} // Test_TADlist_LoadDeny_swap()
+
+func Test_TADlist_LoadReport(t *testing.T) {
+	if err := SetDownloadOptions(TDownloadOptions{Retries: -1}); nil != err {
+		t.Fatal(err)
+	}
+	defer adDownloader.Store(nil)
+
+	server := httptest.NewServer(http.HandlerFunc(func(aWriter http.ResponseWriter, aRequest *http.Request) {
+		switch aRequest.URL.Path {
+		case "/hosts":
+			_, _ = aWriter.Write([]byte("0.0.0.0 bücher.localdomain\n" +
+				"0.0.0.0 -ü.localdomain\n0.0.0.0 tracker.localdomain\n"))
+		case "/names":
+			_, _ = aWriter.Write([]byte("ads.localdomain\n例え.localdomain\n" +
+				"# comment\nads.localdomain\n"))
+		default:
+			http.NotFound(aWriter, aRequest)
+		}
+	}))
+	defer server.Close()
+	ctx := context.TODO()
+
+	adl := newTestList(t)
+	if got := adl.LoadReport(); nil != got.Sources {
+		t.Errorf("TADlist.LoadReport() = '%v', want 'nil'", got.Sources)
+	}
+	urls := []string{server.URL + "/hosts", " ", server.URL + "/names", server.URL + "/missing"}
+	report, err := adl.LoadDeny(ctx, urls)
+	if nil == err {
+		t.Error("TADlist.LoadDeny() error = 'nil', want an error")
+	}
+
+	want := []TSourceStats{
+		{Source: urls[0], Lines: 3, Added: 2, Converted: 1, Rejected: 1,
+			Reasons: map[string]uint32{reasonIDN: 1}},
+		{Source: urls[2], Lines: 3, Added: 2, Duplicates: 1, Converted: 1},
+		{Source: urls[3]},
+	}
+	if len(report.Sources) != len(want) {
+		t.Fatalf("TADlist.LoadDeny() = '%v', want '%v'", report.Sources, want)
+	}
+	for idx, got := range report.Sources {
+		if (urls[3] == got.Source) && ("" == got.Error) {
+			t.Errorf("TADlist.LoadDeny() error of %q is empty", got.Source)
+		}
+		got.Error, got.Duration = "", 0
+		if !reflect.DeepEqual(got, want[idx]) {
+			t.Errorf("TADlist.LoadDeny() = '%v', want '%v'", got, want[idx])
+		}
+	}
+	if got := adl.LoadReport(); !reflect.DeepEqual(got, report) {
+		t.Errorf("TADlist.LoadReport() = '%v', want '%v'", got, report)
+	}
+
+	// The returned report is a copy
+	report.Sources[0].Reasons[reasonIDN] = 99
+	if got := adl.LoadReport(); 1 != got.Sources[0].Reasons[reasonIDN] {
+		t.Errorf("TADlist.LoadReport() = '%v', want '%v'", got.Sources[0], want[0])
+	}
+
+	// The converted names are matched
+	if _, err = adl.LoadDeny(ctx, urls[:1]); nil != err {
+		t.Fatalf("TADlist.LoadDeny() error = '%v'", err)
+	}
+	for _, hostname := range []string{"xn--bcher-kva.localdomain", "tracker.localdomain"} {
+		if got := adl.Match(ctx, hostname); ADdeny != got {
+			t.Errorf("TADlist.Match(%q) = '%v', want '%v'", hostname, got, ADdeny)
+		}
+	}
+
+	var nilList *TADlist
+	if got := nilList.LoadReport(); nil != got.Sources {
+		t.Errorf("TADlist.LoadReport() = '%v', want 'nil'", got.Sources)
+	}
+} // Test_TADlist_LoadReport()
+
 func Test_TADlist_Match(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -513,20 +709,20 @@ func Test_TADlist_Match(t *testing.T) {
 		},
 		{
 			name:     "02 - empty hostname",
-			adl:      New(t.TempDir()),
+			adl:      newTestList(t),
 			hostname: "",
 			want:     ADdeny,
 		},
 		{
 			name:     "03 - non-matching hostname",
-			adl:      New(t.TempDir()),
+			adl:      newTestList(t),
 			hostname: "nothing.will.be.matched.in.an.empty.tree",
 			want:     ADneutral,
 		},
 		{
 			name: "04 - match allow tld",
 			adl: func() *TADlist {
-				a := New(t.TempDir())
+				a := newTestList(t)
 				a.AddAllow(context.TODO(), "tld")
 				return a
 			}(),
@@ -536,7 +732,7 @@ func Test_TADlist_Match(t *testing.T) {
 		{
 			name: "05 - match allow domain.tld",
 			adl: func() *TADlist {
-				a := New(t.TempDir())
+				a := newTestList(t)
 				a.AddAllow(context.TODO(), "domain.tld")
 				return a
 			}(),
@@ -546,7 +742,7 @@ func Test_TADlist_Match(t *testing.T) {
 		{
 			name: "06 - match allow sub.domain.tld",
 			adl: func() *TADlist {
-				a := New(t.TempDir())
+				a := newTestList(t)
 				a.AddAllow(context.TODO(), "sub.domain.tld")
 				return a
 			}(),
@@ -569,6 +765,375 @@ func Test_TADlist_Match(t *testing.T) {
 	}
 } // Test_TADlist_Match()
 
+func Test_TADlist_MatchEx(t *testing.T) {
+	if err := SetDownloadOptions(TDownloadOptions{Retries: -1}); nil != err {
+		t.Fatal(err)
+	}
+	defer adDownloader.Store(nil)
+
+	server := httptest.NewServer(http.HandlerFunc(func(aWriter http.ResponseWriter, aRequest *http.Request) {
+		switch aRequest.URL.Path {
+		case "/first":
+			_, _ = aWriter.Write([]byte("ads.example.com\n*.tracker.tld\n"))
+		case "/second":
+			_, _ = aWriter.Write([]byte("*.tracker.tld\nads.*.example.net\n"))
+		default:
+			http.NotFound(aWriter, aRequest)
+		}
+	}))
+	defer server.Close()
+	ctx := context.TODO()
+
+	adl := newTestList(t)
+	first, second := server.URL+"/first", server.URL+"/second"
+	if _, err := adl.LoadDeny(ctx, []string{first, second}); nil != err {
+		t.Fatalf("TADlist.LoadDeny() error = '%v'", err)
+	}
+	adl.AddDeny(ctx, "*.local.tld")
+	adl.AddAllow(ctx, "www.tracker.tld")
+
+	tests := []struct {
+		name     string
+		hostname string
+		want     TADmatch
+	}{
+		/* */
+		{"01 - first list", "ads.example.com",
+			TADmatch{Pattern: "ads.example.com", Source: first, Result: ADdeny, Origin: ADdeny}},
+		{"02 - both lists", "cdn.tracker.tld",
+			TADmatch{Pattern: "*.tracker.tld", Source: first, Result: ADdeny, Origin: ADdeny}},
+		{"03 - second list", "ads.eu.example.net",
+			TADmatch{Pattern: "ads.*.example.net", Source: second, Result: ADdeny, Origin: ADdeny}},
+		{"04 - added pattern", "www.local.tld",
+			TADmatch{Pattern: "*.local.tld", Result: ADdeny, Origin: ADdeny}},
+		{"05 - allowed", "www.tracker.tld",
+			TADmatch{Pattern: "www.tracker.tld", Result: ADallow, Origin: ADallow, Rule: PrecedenceAllow}},
+		{"06 - neutral", "example.org", TADmatch{}},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := adl.MatchEx(ctx, tc.hostname); got != tc.want {
+				t.Errorf("TADlist.MatchEx() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+
+	// Other than explanations the decisions are counted
+	if got := adl.TopBlocked(0); 4 != len(got) {
+		t.Errorf("TADlist.TopBlocked() = '%v', want 4 entries", got)
+	}
+
+	var nilList *TADlist
+	if got := nilList.MatchEx(ctx, "example.com"); (TADmatch{}) != got {
+		t.Errorf("TADlist.MatchEx() = '%v', want '%v'", got, TADmatch{})
+	}
+} // Test_TADlist_MatchEx()
+
+func Test_TADlist_Metrics(t *testing.T) {
+	tests := []struct {
+		name         string
+		adl          *TADlist
+		wantNil      bool
+		wantPatterns uint32
+	}{
+		/* */
+		{
+			name:    "01 - nil list",
+			adl:     nil,
+			wantNil: true,
+		},
+		{
+			name:         "02 - empty list",
+			adl:          newTestList(t),
+			wantPatterns: 0,
+		},
+		{
+			name: "03 - deny patterns",
+			adl: func() *TADlist {
+				ad := newTestList(t)
+				ad.AddDeny(context.TODO(), "ads.domain.tld")
+				ad.AddDeny(context.TODO(), "*.tracker.tld")
+				return ad
+			}(),
+			wantPatterns: 2,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gotAllow, gotDeny := tc.adl.Metrics()
+
+			if tc.wantNil {
+				if (nil != gotAllow) || (nil != gotDeny) {
+					t.Error("TADlist.Metrics() = non-nil, want 'nil'")
+				}
+				return
+			}
+			if (nil == gotAllow) || (nil == gotDeny) {
+				t.Error("TADlist.Metrics() = nil, want non-nil")
+				return
+			}
+			if gotDeny.Patterns != tc.wantPatterns {
+				t.Errorf("TADlist.Metrics() deny patterns = '%d', want '%d'",
+					gotDeny.Patterns, tc.wantPatterns)
+			}
+		})
+	}
+} // Test_TADlist_Metrics()
+
+func Test_TADlist_SetDefaultDeny(t *testing.T) {
+	ctx := context.TODO()
+	adl := newTestList(t)
+	adl.AddAllow(ctx, "*.school.localdomain")
+	adl.AddAllow(ctx, "wiki.localdomain")
+	adl.AddDeny(ctx, "ads.school.localdomain")
+
+	if adl.DefaultDeny() {
+		t.Error("TADlist.DefaultDeny() = 'true', want 'false'")
+	}
+	if got := adl.Match(ctx, "news.localdomain"); ADneutral != got {
+		t.Errorf("TADlist.Match() = '%v', want '%v'", got, ADneutral)
+	}
+
+	adl.SetDefaultDeny(true)
+	if !adl.DefaultDeny() {
+		t.Error("TADlist.DefaultDeny() = 'false', want 'true'")
+	}
+
+	tests := []struct {
+		name     string
+		hostname string
+		want     TADresult
+	}{
+		/* */
+		{"01 - not allowed", "news.localdomain", ADdeny},
+		{"02 - allowed", "wiki.localdomain", ADallow},
+		{"03 - allowed wildcard", "www.school.localdomain", ADallow},
+		{"04 - allow over deny", "ads.school.localdomain", ADallow},
+		{"05 - wildcard parent", "school.localdomain", ADdeny},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := adl.Match(ctx, tc.hostname); got != tc.want {
+				t.Errorf("TADlist.Match(%q) = '%v', want '%v'",
+					tc.hostname, got, tc.want)
+			}
+		})
+	}
+
+	// Pausing the deny list pauses the default-deny mode as well
+	adl.PauseDeny(time.Minute)
+	if got := adl.Match(ctx, "news.localdomain"); ADneutral != got {
+		t.Errorf("TADlist.Match() = '%v', want '%v'", got, ADneutral)
+	}
+	adl.PauseDeny(0)
+
+	// Switching the mode off drops the cached decisions
+	adl.SetDefaultDeny(false)
+	if got := adl.Match(ctx, "news.localdomain"); ADneutral != got {
+		t.Errorf("TADlist.Match() = '%v', want '%v'", got, ADneutral)
+	}
+
+	var nilList *TADlist
+	nilList.SetDefaultDeny(true)
+	if nilList.DefaultDeny() {
+		t.Error("TADlist.DefaultDeny() = 'true', want 'false'")
+	}
+} // Test_TADlist_SetDefaultDeny()
+
+func Test_TADlist_SetProgress(t *testing.T) {
+	if err := SetDownloadOptions(TDownloadOptions{Retries: -1}); nil != err {
+		t.Fatal(err)
+	}
+	defer adDownloader.Store(nil)
+
+	var list strings.Builder
+	for idx := range 25_000 {
+		fmt.Fprintf(&list, "host%d.localdomain\n", idx)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(aWriter http.ResponseWriter, _ *http.Request) {
+		_, _ = aWriter.Write([]byte(list.String()))
+	}))
+	defer server.Close()
+
+	var (
+		mtx sync.Mutex
+		got []TLoadProgress
+	)
+	adl := newTestList(t)
+	adl.SetProgress(func(aProgress TLoadProgress) {
+		mtx.Lock()
+		got = append(got, aProgress)
+		mtx.Unlock()
+	})
+	uri := server.URL + "/deny.txt"
+	report, err := adl.LoadDeny(context.TODO(), []string{uri})
+	if nil != err {
+		t.Fatalf("TADlist.LoadDeny() error = '%v'", err)
+	}
+
+	want := []TLoadProgress{
+		{Source: uri},
+		{Source: uri, Lines: 10_000, Added: 10_000},
+		{Source: uri, Lines: 20_000, Added: 20_000},
+		{Source: uri, Lines: 25_000, Added: 25_000, Done: true},
+	}
+	for idx := range want {
+		want[idx].Started = report.Started
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("TADlist.SetProgress() = '%v', want '%v'", got, want)
+	}
+
+	// Removing the function stops the calls
+	got = nil
+	adl.SetProgress(nil)
+	if _, err = adl.LoadDeny(context.TODO(), []string{uri}); nil != err {
+		t.Fatalf("TADlist.LoadDeny() error = '%v'", err)
+	}
+	if nil != got {
+		t.Errorf("TADlist.SetProgress(nil) = '%v', want 'nil'", got)
+	}
+
+	var nilList *TADlist
+	nilList.SetProgress(nil)
+} // Test_TADlist_SetProgress()
+
+func Test_TADlist_SetImplicitSubdomains(t *testing.T) {
+	if err := SetDownloadOptions(TDownloadOptions{Retries: -1}); nil != err {
+		t.Fatal(err)
+	}
+	defer adDownloader.Store(nil)
+
+	server := httptest.NewServer(http.HandlerFunc(func(aWriter http.ResponseWriter, _ *http.Request) {
+		_, _ = aWriter.Write([]byte("tracker.example.com\nads.example.net\n" +
+			"*.cdn.example.org\nads.*.example.org\n"))
+	}))
+	defer server.Close()
+	ctx := context.TODO()
+
+	tests := []struct {
+		name       string
+		subdomains bool
+		hostname   string
+		want       TADresult
+	}{
+		/* */
+		{"01 - exact, hostname", false, "tracker.example.com", ADdeny},
+		{"02 - exact, subdomain", false, "www.tracker.example.com", ADneutral},
+		{"03 - implicit, hostname", true, "tracker.example.com", ADdeny},
+		{"04 - implicit, subdomain", true, "www.tracker.example.com", ADdeny},
+		{"05 - implicit, deep subdomain", true, "a.b.ads.example.net", ADdeny},
+		{"06 - implicit, parent domain", true, "example.com", ADneutral},
+		{"07 - implicit, wildcard", true, "cdn.example.org", ADneutral},
+		{"08 - implicit, mid wildcard", true, "x.ads.eu.example.org", ADneutral},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			adl := newTestList(t)
+			adl.SetImplicitSubdomains(tc.subdomains)
+			if got := adl.ImplicitSubdomains(); got != tc.subdomains {
+				t.Errorf("TADlist.ImplicitSubdomains() = '%v', want '%v'", got, tc.subdomains)
+			}
+			if _, err := adl.LoadDeny(ctx, []string{server.URL + "/deny.txt"}); nil != err {
+				t.Fatalf("TADlist.LoadDeny() error = '%v'", err)
+			}
+			if got := adl.Match(ctx, tc.hostname); got != tc.want {
+				t.Errorf("TADlist.Match(%q) = '%v', want '%v'",
+					tc.hostname, got, tc.want)
+			}
+		})
+	}
+
+	// The implied wildcards are neither listed, counted, nor stored
+	adl := newTestList(t)
+	adl.SetImplicitSubdomains(true)
+	if _, err := adl.LoadDeny(ctx, []string{server.URL + "/deny.txt"}); nil != err {
+		t.Fatalf("TADlist.LoadDeny() error = '%v'", err)
+	}
+	want := []string{"tracker.example.com", "ads.example.net",
+		"ads.*.example.org", "*.cdn.example.org"}
+	if got := adl.DenyPatterns(ctx); !slices.Equal(got, want) {
+		t.Errorf("TADlist.DenyPatterns() = '%v', want '%v'", got, want)
+	}
+	adl.Match(ctx, "www.tracker.example.com")
+	if got := adl.HitCounts(); (1 != len(got)) || ("tracker.example.com" != got[0].Pattern) {
+		t.Errorf("TADlist.HitCounts() = '%v', want 'tracker.example.com'", got)
+	}
+	if err := adl.StoreDeny(ctx); nil != err {
+		t.Fatalf("TADlist.StoreDeny() error = '%v'", err)
+	}
+	stored := New(adl.datadir)
+	if got := stored.Match(ctx, "www.tracker.example.com"); ADneutral != got {
+		t.Errorf("TADlist.Match() = '%v', want '%v' (stored list)", got, ADneutral)
+	}
+
+	var nilList *TADlist
+	nilList.SetImplicitSubdomains(true)
+	if nilList.ImplicitSubdomains() {
+		t.Error("TADlist.ImplicitSubdomains() = 'true', want 'false'")
+	}
+} // Test_TADlist_SetImplicitSubdomains()
+
+func Test_TADlist_SetValidation(t *testing.T) {
+	if err := SetDownloadOptions(TDownloadOptions{Retries: -1}); nil != err {
+		t.Fatal(err)
+	}
+	defer adDownloader.Store(nil)
+
+	server := httptest.NewServer(http.HandlerFunc(func(aWriter http.ResponseWriter, _ *http.Request) {
+		_, _ = aWriter.Write([]byte("address=/tracker.localdomain/0.0.0.0\n" +
+			"address=/ads.corp/0.0.0.0\n"))
+	}))
+	defer server.Close()
+	ctx := context.TODO()
+
+	tests := []struct {
+		name     string
+		level    TValidation
+		hostname string
+		want     TADresult
+	}{
+		/* */
+		{"01 - strict, public name", ValidateStrict, "tracker.localdomain", ADdeny},
+		{"02 - strict, intranet name", ValidateStrict, "ads.corp", ADneutral},
+		{"03 - relaxed, public name", ValidateRelaxed, "tracker.localdomain", ADdeny},
+		{"04 - relaxed, intranet name", ValidateRelaxed, "ads.corp", ADdeny},
+		{"05 - off, intranet name", ValidateOff, "ads.corp", ADdeny},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			adl := newTestList(t)
+			adl.SetValidation(tc.level)
+			if got := adl.Validation(); got != tc.level {
+				t.Errorf("TADlist.Validation() = '%v', want '%v'", got, tc.level)
+			}
+			if _, err := adl.LoadDeny(ctx, []string{server.URL + "/deny.conf"}); nil != err {
+				t.Fatalf("TADlist.LoadDeny() error = '%v'", err)
+			}
+			if got := adl.Match(ctx, tc.hostname); got != tc.want {
+				t.Errorf("TADlist.Match(%q) = '%v', want '%v'",
+					tc.hostname, got, tc.want)
+			}
+		})
+	}
+
+	var nilList *TADlist
+	nilList.SetValidation(ValidateOff)
+	if got := nilList.Validation(); ValidateStrict != got {
+		t.Errorf("TADlist.Validation() = '%v', want '%v'", got, ValidateStrict)
+	}
+} // Test_TADlist_SetValidation()
+
 func Test_TADlist_Shutdown(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -583,13 +1148,13 @@ func Test_TADlist_Shutdown(t *testing.T) {
 		},
 		{
 			name:    "02 - empty list",
-			adl:     New(t.TempDir()),
+			adl:     newTestList(t),
 			wantErr: true,
 		},
 		{
 			name: "03 - valid names",
 			adl: func() *TADlist {
-				ad := New(t.TempDir())
+				ad := newTestList(t)
 				ad.AddAllow(context.TODO(), "host.domain.tld")
 				ad.AddAllow(context.TODO(), "www.domain.tld")
 				ad.AddDeny(context.TODO(), "sub.domain.tld")
@@ -627,13 +1192,13 @@ func Test_TADlist_StoreAllow(t *testing.T) {
 		},
 		{
 			name:    "02 - empty trie",
-			adl:     New(t.TempDir()),
+			adl:     newTestList(t),
 			wantErr: true,
 		},
 		{
 			name: "03 - valid filename",
 			adl: func() *TADlist {
-				ad := New(t.TempDir())
+				ad := newTestList(t)
 				ad.AddAllow(context.TODO(), "domain.tld")
 				return ad
 			}(),
@@ -669,12 +1234,12 @@ func Test_TADlist_StoreDeny(t *testing.T) {
 		},
 		{
 			name:    "02 - empty List",
-			adl:     New(t.TempDir()),
+			adl:     newTestList(t),
 			wantErr: true,
 		},
 		{
 			name:    "03 - valid filename",
-			adl:     New(t.TempDir()),
+			adl:     newTestList(t),
 			wantErr: true,
 		},
 		/* */
@@ -707,13 +1272,13 @@ func Test_TADlist_String(t *testing.T) {
 		},
 		{
 			name: "02 - empty list",
-			adl:  New(t.TempDir()),
+			adl:  newTestList(t),
 			want: "Allow:\n\"Trie\":\n  isEnd: false\n  isWild: false\n\nDeny:\n\"Trie\":\n  isEnd: false\n  isWild: false\n",
 		},
 		{
 			name: "03 - one allow pattern",
 			adl: func() *TADlist {
-				a := New(t.TempDir())
+				a := newTestList(t)
 				a.AddAllow(context.TODO(), "tld")
 				return a
 			}(),
@@ -722,7 +1287,7 @@ func Test_TADlist_String(t *testing.T) {
 		{
 			name: "04 - one deny pattern",
 			adl: func() *TADlist {
-				a := New(t.TempDir())
+				a := newTestList(t)
 				a.AddDeny(context.TODO(), "tld")
 				return a
 			}(),
@@ -731,7 +1296,7 @@ func Test_TADlist_String(t *testing.T) {
 		{
 			name: "05 - two allow patterns",
 			adl: func() *TADlist {
-				a := New(t.TempDir())
+				a := newTestList(t)
 				a.AddAllow(context.TODO(), "tld")
 				a.AddAllow(context.TODO(), "domain.tld")
 				return a
@@ -741,7 +1306,7 @@ func Test_TADlist_String(t *testing.T) {
 		{
 			name: "06 - two deny patterns",
 			adl: func() *TADlist {
-				a := New(t.TempDir())
+				a := newTestList(t)
 				a.AddDeny(context.TODO(), "tld")
 				a.AddDeny(context.TODO(), "domain.tld")
 				return a
@@ -782,21 +1347,21 @@ func Test_TADlist_UpdateAllow(t *testing.T) {
 		},
 		{
 			name:       "02 - empty old pattern",
-			adl:        New(t.TempDir()),
+			adl:        newTestList(t),
 			oldPattern: "",
 			newPattern: "tld",
 			wantOK:     false,
 		},
 		{
 			name:       "03 - empty new pattern",
-			adl:        New(t.TempDir()),
+			adl:        newTestList(t),
 			oldPattern: "tld",
 			newPattern: "",
 			wantOK:     false,
 		},
 		{
 			name:       "04 - equal old and new pattern",
-			adl:        New(t.TempDir()),
+			adl:        newTestList(t),
 			oldPattern: "tld",
 			newPattern: "tld",
 			wantOK:     false,
@@ -804,7 +1369,7 @@ func Test_TADlist_UpdateAllow(t *testing.T) {
 		{
 			name: "05 - update non-existent old pattern",
 			adl: func() *TADlist {
-				a := New(t.TempDir())
+				a := newTestList(t)
 				a.AddAllow(context.TODO(), "tld")
 				return a
 			}(),
@@ -815,7 +1380,7 @@ func Test_TADlist_UpdateAllow(t *testing.T) {
 		{
 			name: "06 - update existing old pattern",
 			adl: func() *TADlist {
-				a := New(t.TempDir())
+				a := newTestList(t)
 				a.AddAllow(context.TODO(), "tld")
 				return a
 			}(),
@@ -857,21 +1422,21 @@ func Test_TADlist_UpdateDeny(t *testing.T) {
 		},
 		{
 			name:       "02 - empty old pattern",
-			adl:        New(t.TempDir()),
+			adl:        newTestList(t),
 			oldPattern: "",
 			newPattern: "tld",
 			wantOK:     false,
 		},
 		{
 			name:       "03 - empty new pattern",
-			adl:        New(t.TempDir()),
+			adl:        newTestList(t),
 			oldPattern: "tld",
 			newPattern: "",
 			wantOK:     false,
 		},
 		{
 			name:       "04 - equal old and new pattern",
-			adl:        New(t.TempDir()),
+			adl:        newTestList(t),
 			oldPattern: "tld",
 			newPattern: "tld",
 			wantOK:     false,
@@ -879,7 +1444,7 @@ func Test_TADlist_UpdateDeny(t *testing.T) {
 		{
 			name: "05 - update non-existent old pattern",
 			adl: func() *TADlist {
-				a := New(t.TempDir())
+				a := newTestList(t)
 				a.AddDeny(context.TODO(), "tld")
 				return a
 			}(),
@@ -890,7 +1455,7 @@ func Test_TADlist_UpdateDeny(t *testing.T) {
 		{
 			name: "06 - update existing old pattern",
 			adl: func() *TADlist {
-				a := New(t.TempDir())
+				a := newTestList(t)
 				a.AddDeny(context.TODO(), "tld")
 				return a
 			}(),
@@ -914,6 +1479,68 @@ func Test_TADlist_UpdateDeny(t *testing.T) {
 	}
 } // Test_TADlist_UpdateDeny()
 
+func Test_TADlist_WriteDeny(t *testing.T) {
+	adl := newTestList(t)
+	adl.AddDeny(context.TODO(), "*.ads.localdomain")
+	adl.AddDeny(context.TODO(), "tracker.localdomain")
+
+	tests := []struct {
+		name    string
+		adl     *TADlist
+		format  TListFormat
+		want    string
+		wantErr bool
+	}{
+		/* */
+		{
+			name:    "01 - nil list",
+			adl:     nil,
+			format:  ListSimple,
+			wantErr: true,
+		},
+		{
+			name:   "02 - simple",
+			adl:    adl,
+			format: ListSimple,
+			want:   "*.ads.localdomain\ntracker.localdomain\n",
+		},
+		{
+			name:   "03 - hosts",
+			adl:    adl,
+			format: ListHosts,
+			want:   "127.0.0.1 *.ads.localdomain\n127.0.0.1 tracker.localdomain\n",
+		},
+		{
+			name:   "04 - ABP",
+			adl:    adl,
+			format: ListABP,
+			want:   "[Adblock Plus 2.0]\n||ads.localdomain^\n|tracker.localdomain^\n",
+		},
+		{
+			name:    "05 - invalid format",
+			adl:     adl,
+			format:  TListFormat(42),
+			wantErr: true,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := tc.adl.WriteDeny(context.TODO(), &buf, tc.format)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("TADlist.WriteDeny() error = '%v', wantErr '%v'",
+					err, tc.wantErr)
+				return
+			}
+			if got := buf.String(); got != tc.want {
+				t.Errorf("TADlist.WriteDeny() =\n%q\nwant\n%q", got, tc.want)
+			}
+		})
+	}
+} // Test_TADlist_WriteDeny()
+
 func Test_urlPath2Filename(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -980,4 +1607,199 @@ func Test_urlPath2Filename(t *testing.T) {
 	}
 } // Test_urlPath2Filename()
 
+func Test_TADlist_Match_decisions(t *testing.T) {
+	ctx := context.TODO()
+	adl := newTestList(t)
+	hostname := "ads.domain.tld"
+
+	if got := adl.Match(ctx, hostname); ADneutral != got {
+		t.Errorf("TADlist.Match() = %v, want %v", got, ADneutral)
+	}
+	if got := adl.decisions.Len(); 1 != got {
+		t.Errorf("decisions.Len() = %d, want %d", got, 1)
+	}
+
+	// Changing the deny list must invalidate the cached decision
+	adl.AddDeny(ctx, "*.domain.tld")
+	if got := adl.Match(ctx, hostname); ADdeny != got {
+		t.Errorf("TADlist.Match() = %v, want %v", got, ADdeny)
+	}
+
+	// Changing the allow list must invalidate the cached decision
+	adl.AddAllow(ctx, hostname)
+	if got := adl.Match(ctx, hostname); ADallow != got {
+		t.Errorf("TADlist.Match() = %v, want %v", got, ADallow)
+	}
+
+	adl.DeleteAllow(ctx, hostname)
+	if got := adl.Match(ctx, hostname); ADdeny != got {
+		t.Errorf("TADlist.Match() = %v, want %v", got, ADdeny)
+	}
+} // Test_TADlist_Match_decisions()
+
+func Test_TADlist_DenyPatterns(t *testing.T) {
+	ctx := context.TODO()
+	adl := newTestList(t)
+	adl.AddDeny(ctx, "host.domain.tld")
+	adl.AddDeny(ctx, "*.ads.tld")
+	adl.AddAllow(ctx, "www.domain.tld")
+
+	if got, want := adl.DenyPatterns(ctx), []string{"*.ads.tld", "host.domain.tld"}; !slices.Equal(got, want) {
+		t.Errorf("TADlist.DenyPatterns() = '%v', want '%v'", got, want)
+	}
+	if got, want := adl.AllowPatterns(ctx), []string{"www.domain.tld"}; !slices.Equal(got, want) {
+		t.Errorf("TADlist.AllowPatterns() = '%v', want '%v'", got, want)
+	}
+
+	adl.DeleteDeny(ctx, "*.ads.tld")
+	if got, want := adl.DenyPatterns(ctx), []string{"host.domain.tld"}; !slices.Equal(got, want) {
+		t.Errorf("TADlist.DenyPatterns() = '%v', want '%v'", got, want)
+	}
+
+	var nilList *TADlist
+	if got := nilList.DenyPatterns(ctx); nil != got {
+		t.Errorf("TADlist.DenyPatterns() = '%v', want 'nil'", got)
+	}
+} // Test_TADlist_DenyPatterns()
+
+func Test_TADlist_Explain(t *testing.T) {
+	ctx := context.TODO()
+	adl := newTestList(t)
+	adl.AddDeny(ctx, "*.doubleclick.net")
+	adl.AddDeny(ctx, "ad.doubleclick.com")
+	adl.AddDeny(ctx, "*.tracker.tld")
+	adl.AddDeny(ctx, "top.ads.tracker.tld")
+	adl.AddAllow(ctx, "www.doubleclick.net")
+
+	tests := []struct {
+		name     string
+		hostname string
+		want     TADmatch
+	}{
+		/* */
+		{"01 - exact deny", "ad.doubleclick.com",
+			TADmatch{Pattern: "ad.doubleclick.com", Result: ADdeny, Origin: ADdeny}},
+		{"02 - wildcard deny", "Stats.DoubleClick.net",
+			TADmatch{Pattern: "*.doubleclick.net", Result: ADdeny, Origin: ADdeny}},
+		{"03 - deep wildcard deny", "a.b.tracker.tld",
+			TADmatch{Pattern: "*.tracker.tld", Result: ADdeny, Origin: ADdeny}},
+		{"04 - most specific pattern", "top.ads.tracker.tld",
+			TADmatch{Pattern: "top.ads.tracker.tld", Result: ADdeny, Origin: ADdeny}},
+		{"05 - allow", "www.doubleclick.net",
+			TADmatch{Pattern: "www.doubleclick.net", Result: ADallow, Origin: ADallow, Rule: PrecedenceAllow}},
+		{"06 - neutral", "example.com", TADmatch{}},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := adl.Explain(ctx, tc.hostname); got != tc.want {
+				t.Errorf("TADlist.Explain() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+
+	// Explanations aren't counted as blocked queries
+	if got := adl.TopBlocked(0); 0 != len(got) {
+		t.Errorf("TADlist.TopBlocked() = '%v', want '[]'", got)
+	}
+
+	// Default-deny decisions have no pattern
+	adl.SetDefaultDeny(true)
+	want := TADmatch{Result: ADdeny}
+	if got := adl.Explain(ctx, "example.com"); got != want {
+		t.Errorf("TADlist.Explain() = '%v', want '%v'", got, want)
+	}
+
+	var nilList *TADlist
+	if got := nilList.Explain(ctx, "example.com"); (TADmatch{}) != got {
+		t.Errorf("TADlist.Explain() = '%v', want '%v'", got, TADmatch{})
+	}
+} // Test_TADlist_Explain()
+
+func Test_TADlist_Find(t *testing.T) {
+	ctx := context.TODO()
+	adl := newTestList(t)
+	adl.AddDeny(ctx, "*.doubleclick.net")
+	adl.AddDeny(ctx, "ad.doubleclick.com")
+	adl.AddDeny(ctx, "tracker.tld")
+	adl.AddAllow(ctx, "www.doubleclick.net")
+
+	tests := []struct {
+		name  string
+		query string
+		want  []TADpattern
+	}{
+		/* */
+		{"01 - empty query", "", nil},
+		{"02 - substring", "DoubleClick", []TADpattern{
+			{"www.doubleclick.net", ADallow},
+			{"ad.doubleclick.com", ADdeny},
+			{"*.doubleclick.net", ADdeny},
+		}},
+		{"03 - wildcard", "*.com", []TADpattern{
+			{"ad.doubleclick.com", ADdeny},
+		}},
+		{"04 - wildcard pattern", "[*]*", []TADpattern{
+			{"*.doubleclick.net", ADdeny},
+		}},
+		{"05 - no match", "example", nil},
+		{"06 - malformed wildcard", "[a", nil},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := adl.Find(ctx, tc.query); !slices.Equal(got, tc.want) {
+				t.Errorf("TADlist.Find() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+
+	var nilList *TADlist
+	if got := nilList.Find(ctx, "doubleclick"); nil != got {
+		t.Errorf("TADlist.Find() = '%v', want 'nil'", got)
+	}
+} // Test_TADlist_Find()
+
+// `prepareMatchBench()` returns a list with some deny patterns and
+// the hostnames to match against it.
+func prepareMatchBench(b *testing.B) (*TADlist, []string) {
+	adl := newTestList(b)
+	ctx := context.TODO()
+	for i := range 1000 {
+		adl.deny.Add(ctx, fmt.Sprintf("*.ads%d.tracker.tld", i))
+		adl.deny.Add(ctx, fmt.Sprintf("host%d.domain.tld", i))
+	}
+	hostnames := make([]string, 64) // hot set of queried names
+	for i := range hostnames {
+		hostnames[i] = fmt.Sprintf("www.ads%d.tracker.tld", i*7)
+	}
+
+	return adl, hostnames
+} // prepareMatchBench()
+
+func Benchmark_TADlist_Match_cached(b *testing.B) {
+	adl, hostnames := prepareMatchBench(b)
+	ctx := context.TODO()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := range b.N {
+		_ = adl.Match(ctx, hostnames[i%len(hostnames)])
+	}
+} // Benchmark_TADlist_Match_cached()
+
+func Benchmark_TADlist_Match_uncached(b *testing.B) {
+	adl, hostnames := prepareMatchBench(b)
+	adl.decisions = nil
+	ctx := context.TODO()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := range b.N {
+		_ = adl.Match(ctx, hostnames[i%len(hostnames)])
+	}
+} // Benchmark_TADlist_Match_uncached()
+
 /* _EoF_ */
@@ -0,0 +1,332 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+//lint:file-ignore ST1005 - I like capitalisation
+
+const (
+	// `maxArchiveDepth` is the maximum nesting of archives
+	// (e.g. a TAR file inside a GZIP file).
+	maxArchiveDepth = 3
+
+	// `maxUnpackedSize` is the maximum size of an unpacked file
+	// to protect against decompression bombs.
+	maxUnpackedSize = 1 << 28 // 256 MB
+)
+
+var (
+	// `ErrUnpackedSize` is returned if an unpacked file exceeds
+	// `maxUnpackedSize`.
+	ErrUnpackedSize = ADlistError{errors.New("Unpacked file is too large")}
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `loaderFor()` returns the loader for the given MIME type.
+//
+// Parameters:
+//   - `aMime`: The MIME type as returned by `detectFileType()`.
+//   - `aOptions`: The loader's settings.
+//
+// Returns:
+//   - `ILoader`: The loader to use, `nil` if there's none.
+func loaderFor(aMime string, aOptions tLoadOptions) ILoader {
+	switch aMime {
+	case "text/x-abp":
+		return &tABPLoader{aOptions}
+	case "text/x-dnsmasq":
+		return &tDnsmasqLoader{aOptions}
+	case "text/x-hosts":
+		return &tHostsLoader{aOptions}
+	case "text/x-hostnames":
+		return &tSimpleLoader{aOptions}
+	}
+
+	return nil
+} // loaderFor()
+
+// `loadArchive()` detects the type of the given file, unpacks it if
+// it's an archive, and loads the patterns using the appropriate loader.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//   - `aFilename`: The file to load.
+//   - `aTmpDir`: The directory to unpack archives to.
+//   - `aNode`: The node to add the patterns to.
+//   - `aOptions`: The loaders' settings.
+//   - `aDepth`: The current nesting level of archives.
+//
+// Returns:
+//   - `error`: `nil` if the patterns were loaded successfully, the error otherwise.
+func loadArchive(aCtx context.Context, aFilename, aTmpDir string, aNode *tNode, aOptions tLoadOptions, aDepth int) error {
+	if err := aCtx.Err(); nil != err {
+		return err
+	}
+	mime, err := detectFileType(aFilename)
+	if nil != err {
+		return err
+	}
+	if loader := loaderFor(mime, aOptions); nil != loader {
+		return loader.Load(aCtx, aFilename, aNode)
+	}
+
+	format, ok := strings.CutPrefix(mime, "application/x-")
+	if !ok {
+		return ErrUnsupportedMime
+	}
+	if maxArchiveDepth <= aDepth {
+		return ErrUnsupportedArchive
+	}
+	files, err := unpack(aCtx, aFilename, tArchiveFormat(format), aTmpDir)
+	if nil != err {
+		return err
+	}
+
+	// Archive members which aren't lists (e.g. a README) are skipped
+	var errs []error
+	loaded := 0
+	for _, file := range files {
+		if err = loadArchive(aCtx, file, aTmpDir, aNode, aOptions, aDepth+1); nil == err {
+			loaded++
+		} else {
+			errs = append(errs, err)
+		}
+	}
+	if 0 < loaded {
+		return nil
+	}
+	if 0 == len(errs) {
+		return ADlistError{fmt.Errorf("no files found in %q", aFilename)}
+	}
+
+	return errors.Join(errs...)
+} // loadArchive()
+
+// `loadFile()` loads the patterns of the given local file using the
+// loader appropriate for the file's type, unpacking archives as
+// needed.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//   - `aFilename`: The file to load.
+//   - `aNode`: The node to add the patterns to.
+//   - `aOptions`: The loaders' settings.
+//
+// Returns:
+//   - `error`: `nil` if the patterns were loaded successfully, the error otherwise.
+func loadFile(aCtx context.Context, aFilename string, aNode *tNode, aOptions tLoadOptions) error {
+	tmpDir, err := os.MkdirTemp("", "adlist-")
+	if nil != err {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	return loadArchive(aCtx, aFilename, tmpDir, aNode, aOptions, 0)
+} // loadFile()
+
+// `unpack()` extracts the files of the given archive.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//   - `aFilename`: The archive to unpack.
+//   - `aFormat`: The archive's format.
+//   - `aTmpDir`: The directory to extract the files to.
+//
+// Returns:
+//   - `rFiles`: The names of the extracted files.
+//   - `rErr`: `nil` if the archive was unpacked successfully, the error otherwise.
+func unpack(aCtx context.Context, aFilename string, aFormat tArchiveFormat, aTmpDir string) (rFiles []string, rErr error) {
+	if ArchiveZIP == aFormat {
+		return unpackZIP(aCtx, aFilename, aTmpDir)
+	}
+
+	inFile, err := os.Open(aFilename) //#nosec G304
+	if nil != err {
+		return nil, err
+	}
+	defer inFile.Close()
+
+	var (
+		file   string
+		reader io.Reader
+	)
+	switch aFormat {
+	case ArchiveBZ2:
+		reader = bzip2.NewReader(inFile)
+
+	case ArchiveGZ:
+		gzReader, err := gzip.NewReader(inFile)
+		if nil != err {
+			return nil, err
+		}
+		defer gzReader.Close()
+		reader = gzReader
+
+	case ArchiveTAR:
+		tarReader := tar.NewReader(inFile)
+		for {
+			if rErr = aCtx.Err(); nil != rErr {
+				return nil, rErr
+			}
+			header, err := tarReader.Next()
+			if io.EOF == err {
+				return
+			}
+			if nil != err {
+				return nil, err
+			}
+			if tar.TypeReg != header.Typeflag {
+				continue
+			}
+			if file, rErr = unpackTo(aTmpDir, tarReader); nil != rErr {
+				return nil, rErr
+			}
+			rFiles = append(rFiles, file)
+		}
+
+	default:
+		return nil, ErrUnsupportedArchive
+	}
+
+	if file, rErr = unpackTo(aTmpDir, reader); nil != rErr {
+		return nil, rErr
+	}
+
+	return []string{file}, nil
+} // unpack()
+
+// `unpackTo()` writes the given reader's data to a new file in the
+// given directory.
+//
+// Parameters:
+//   - `aTmpDir`: The directory to create the file in.
+//   - `aReader`: The unpacked data.
+//
+// Returns:
+//   - `string`: The name of the new file.
+//   - `error`: `nil` if the data was written successfully, the error otherwise.
+func unpackTo(aTmpDir string, aReader io.Reader) (string, error) {
+	outFile, err := os.CreateTemp(aTmpDir, "unpacked-")
+	if nil != err {
+		return "", err
+	}
+	defer outFile.Close()
+
+	n, err := io.CopyN(outFile, aReader, maxUnpackedSize+1)
+	if (nil != err) && (io.EOF != err) {
+		return "", err
+	}
+	if maxUnpackedSize < n {
+		return "", ErrUnpackedSize
+	}
+
+	return outFile.Name(), nil
+} // unpackTo()
+
+// `unpackZIP()` extracts the files of the given ZIP archive.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//   - `aFilename`: The archive to unpack.
+//   - `aTmpDir`: The directory to extract the files to.
+//
+// Returns:
+//   - `rFiles`: The names of the extracted files.
+//   - `rErr`: `nil` if the archive was unpacked successfully, the error otherwise.
+func unpackZIP(aCtx context.Context, aFilename, aTmpDir string) (rFiles []string, rErr error) {
+	zipReader, err := zip.OpenReader(aFilename)
+	if nil != err {
+		return nil, err
+	}
+	defer zipReader.Close()
+
+	var file string
+	for _, member := range zipReader.File {
+		if rErr = aCtx.Err(); nil != rErr {
+			return nil, rErr
+		}
+		if member.FileInfo().IsDir() {
+			continue
+		}
+		reader, err := member.Open()
+		if nil != err {
+			return nil, err
+		}
+		file, rErr = unpackTo(aTmpDir, reader)
+		_ = reader.Close()
+		if nil != rErr {
+			return nil, rErr
+		}
+		rFiles = append(rFiles, file)
+	}
+
+	return
+} // unpackZIP()
+
+// ---------------------------------------------------------------------------
+// Public functions:
+
+// `LoadAuto()` loads the patterns of the given file or URL into the
+// given node, selecting the loader by the file's contents.
+//
+// Remote files (`http://` or `https://`) are downloaded first. Archives
+// (GZIP, BZ2, TAR, and ZIP, nested up to three levels) are unpacked and
+// all their members loaded; members that aren't lists are skipped.
+// The supported list formats are `hosts(5)` files, plain hostname
+// lists, ABP filter lists, and `dnsmasq` configuration files.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//   - `aSource`: The path/name or URL of the file to load.
+//   - `aNode`: The node to add the patterns to.
+//
+// Returns:
+//   - `error`: `nil` if the patterns were loaded successfully, the error otherwise.
+func LoadAuto(aCtx context.Context, aSource string, aNode *tNode) error {
+	if nil == aNode {
+		return ErrLoaderNil
+	}
+	if aSource = strings.TrimSpace(aSource); 0 == len(aSource) {
+		return ErrInvalidFile
+	}
+
+	lower := strings.ToLower(aSource)
+	if !strings.HasPrefix(lower, "http://") && !strings.HasPrefix(lower, "https://") {
+		return loadFile(aCtx, aSource, aNode, tLoadOptions{})
+	}
+
+	tmpDir, err := os.MkdirTemp("", "adlist-")
+	if nil != err {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	filename, err := downloadFile(aCtx, aSource, filepath.Join(tmpDir, "download"))
+	if nil != err {
+		return err
+	}
+
+	return loadArchive(aCtx, filename, tmpDir, aNode, tLoadOptions{}, 0)
+} // LoadAuto()
+
+/* _EoF_ */
@@ -0,0 +1,182 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// `writeTestFile()` writes the given data to a file in the directory.
+func writeTestFile(t *testing.T, aDir, aName string, aData []byte) string {
+	t.Helper()
+	fName := filepath.Join(aDir, aName)
+	if err := os.WriteFile(fName, aData, 0600); nil != err {
+		t.Fatal(err)
+	}
+
+	return fName
+} // writeTestFile()
+
+// `gzipData()` returns the GZIP compressed data.
+func gzipData(aData []byte) []byte {
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	_, _ = gzw.Write(aData)
+	_ = gzw.Close()
+
+	return buf.Bytes()
+} // gzipData()
+
+// `tarData()` returns a TAR archive of the given files.
+func tarData(aFiles map[string]string) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, data := range aFiles {
+		_ = tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0600,
+			Size:     int64(len(data)),
+			Typeflag: tar.TypeReg,
+		})
+		_, _ = tw.Write([]byte(data))
+	}
+	_ = tw.Close()
+
+	return buf.Bytes()
+} // tarData()
+
+// `zipData()` returns a ZIP archive of the given files.
+func zipData(aFiles map[string]string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, data := range aFiles {
+		w, _ := zw.Create(name)
+		_, _ = w.Write([]byte(data))
+	}
+	_ = zw.Close()
+
+	return buf.Bytes()
+} // zipData()
+
+func Test_loaderFor(t *testing.T) {
+	tests := []struct {
+		name  string
+		mime  string
+		level TValidation
+		want  ILoader
+	}{
+		/* */
+		{"01 - ABP", "text/x-abp", ValidateStrict, &tABPLoader{}},
+		{"02 - dnsmasq", "text/x-dnsmasq", ValidateStrict, &tDnsmasqLoader{}},
+		{"03 - hosts", "text/x-hosts", ValidateStrict, &tHostsLoader{}},
+		{"04 - hostnames", "text/x-hostnames", ValidateStrict, &tSimpleLoader{}},
+		{"05 - plain text", "text/plain", ValidateStrict, nil},
+		{"06 - archive", "application/x-zip", ValidateStrict, nil},
+		{"07 - relaxed ABP", "text/x-abp", ValidateRelaxed, &tABPLoader{tLoadOptions{validation: ValidateRelaxed}}},
+		{"08 - relaxed dnsmasq", "text/x-dnsmasq", ValidateRelaxed, &tDnsmasqLoader{tLoadOptions{validation: ValidateRelaxed}}},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := loaderFor(tc.mime, tLoadOptions{validation: tc.level}); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("loaderFor() = '%#v', want '%#v'", got, tc.want)
+			}
+		})
+	}
+} // Test_loaderFor()
+
+func Test_LoadAuto(t *testing.T) {
+	tmpDir := t.TempDir()
+	hosts := "# blocked\n127.0.0.1 ads.localdomain\n"
+	hostnames := "tracker.localdomain\n"
+	dnsmasq := "address=/ads.localdomain/0.0.0.0\n"
+
+	server := httptest.NewServer(http.HandlerFunc(func(aWriter http.ResponseWriter, aRequest *http.Request) {
+		_, _ = aWriter.Write(gzipData([]byte(hostnames)))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		source   string
+		hostname string
+		wantErr  bool
+	}{
+		/* */
+		{"01 - empty source", "", "", true},
+		{"02 - missing file", filepath.Join(tmpDir, "missing.txt"), "", true},
+		{"03 - hosts file",
+			writeTestFile(t, tmpDir, "hosts.txt", []byte(hosts)),
+			"ads.localdomain", false},
+		{"04 - dnsmasq file",
+			writeTestFile(t, tmpDir, "dnsmasq.conf", []byte(dnsmasq)),
+			"www.ads.localdomain", false},
+		{"05 - GZIP file",
+			writeTestFile(t, tmpDir, "hostnames.gz", gzipData([]byte(hostnames))),
+			"tracker.localdomain", false},
+		{"06 - TAR.GZ file",
+			writeTestFile(t, tmpDir, "lists.tar.gz", gzipData(tarData(map[string]string{
+				"README":    "Oh dear, this is just\na plain text\n",
+				"hosts.txt": hosts,
+			}))),
+			"ads.localdomain", false},
+		{"07 - ZIP file",
+			writeTestFile(t, tmpDir, "lists.zip", zipData(map[string]string{
+				"hostnames.txt": hostnames,
+			})),
+			"tracker.localdomain", false},
+		{"08 - ZIP without lists",
+			writeTestFile(t, tmpDir, "readme.zip", zipData(map[string]string{
+				"README": "Oh dear, this is just\na plain text\n",
+			})),
+			"", true},
+		{"09 - unsupported archive",
+			writeTestFile(t, tmpDir, "list.xz", []byte{0xFD, 0x37, 0x7A, 0x58, 0x5A, 0x00}),
+			"", true},
+		{"10 - plain text",
+			writeTestFile(t, tmpDir, "plain.txt", []byte("Oh dear, this is just\na plain text\n")),
+			"", true},
+		{"11 - URL", server.URL + "/hostnames.gz", "tracker.localdomain", false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			node := newNode()
+			err := LoadAuto(context.TODO(), tc.source, node)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("LoadAuto() error = '%v', wantErr '%v'", err, tc.wantErr)
+				return
+			}
+			if "" == tc.hostname {
+				return
+			}
+			if !node.match(context.TODO(), pattern2parts(tc.hostname)) {
+				t.Errorf("LoadAuto() didn't load %q", tc.hostname)
+			}
+		})
+	}
+
+	if err := LoadAuto(context.TODO(), "hosts.txt", nil); nil == err {
+		t.Error("LoadAuto() error = 'nil', want an error")
+	}
+} // Test_LoadAuto()
+
+/* _EoF_ */
@@ -0,0 +1,274 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+const (
+	// `bloomBitsPerKey` is the number of filter bits per key
+	// (giving about 1% false positives).
+	bloomBitsPerKey = 10
+
+	// `bloomHashes` is the number of hash functions per key.
+	bloomHashes = 7
+
+	// FNV-1a constants:
+	fnvOffset = 14695981039346656037
+	fnvPrime  = 1099511628211
+
+	// `bloomWildSalt` distinguishes wildcard keys from exact ones.
+	bloomWildSalt = 0x2a2a2a2a2a2a2a2a
+)
+
+type (
+	// `tBloom` is a Bloom filter of a trie's patterns.
+	//
+	// For each pattern the filter holds either the pattern itself
+	// (exact key) or the part before its first wildcard (wildcard
+	// key). A hostname can only match a pattern if the filter
+	// contains the hostname's exact key or the wildcard key of one
+	// of its parent domains, hence a negative filter check saves the
+	// trie lookup altogether.
+	//
+	// Bits can be added concurrently to lookups but never removed,
+	// so deleted patterns just cause some more false positives.
+	tBloom struct {
+		bits []uint64 // the filter's bit array
+		size uint64   // number of bits
+	}
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `bloomExtend()` adds the given label to the hash of a parts list.
+//
+// Parameters:
+//   - `aHash`: The hash of the preceding labels.
+//   - `aLabel`: The label to add.
+//
+// Returns:
+//   - `uint64`: The hash including the label.
+func bloomExtend(aHash uint64, aLabel string) uint64 {
+	for idx := 0; idx < len(aLabel); idx++ {
+		aHash = (aHash ^ uint64(aLabel[idx])) * fnvPrime
+	}
+
+	// Separate the labels (which never contain a NUL byte)
+	return aHash * fnvPrime
+} // bloomExtend()
+
+// `bloomMix()` scrambles the given hash (splitmix64 finalizer).
+//
+// Parameters:
+//   - `aHash`: The hash to scramble.
+//
+// Returns:
+//   - `uint64`: The scrambled hash.
+func bloomMix(aHash uint64) uint64 {
+	aHash ^= aHash >> 30
+	aHash *= 0xbf58476d1ce4e5b9
+	aHash ^= aHash >> 27
+	aHash *= 0x94d049bb133111eb
+	aHash ^= aHash >> 31
+
+	return aHash
+} // bloomMix()
+
+// ---------------------------------------------------------------------------
+// `tBloom` constructor:
+
+// `newBloom()` creates a Bloom filter of the given node's patterns.
+//
+// The function is not thread-safe in itself but expects to be RLocked
+// by the calling `tTrie` instance.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//   - `aNode`: The root node of the patterns' tree.
+//
+// Returns:
+//   - `*tBloom`: The new filter, `nil` in case of cancellation.
+func newBloom(aCtx context.Context, aNode *tNode) *tBloom {
+	if nil == aNode {
+		return nil
+	}
+	type (
+		tStackEntry struct {
+			node *tNode
+			hash uint64 // hash of the labels leading to `node`
+		}
+	)
+
+	// Collect the keys first to get the filter's size
+	var keys []uint64
+	stack := []tStackEntry{{aNode, fnvOffset}}
+	for 0 < len(stack) {
+		if nil != aCtx.Err() {
+			return nil
+		}
+		entry := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		for label, child := range entry.node.tChildren.All() {
+			if "*" == label {
+				// All patterns below a wildcard share its key
+				keys = append(keys, bloomMix(entry.hash^bloomWildSalt))
+				continue
+			}
+			hash := bloomExtend(entry.hash, label)
+			if 0 != child.terminator {
+				keys = append(keys, bloomMix(hash))
+			}
+			stack = append(stack, tStackEntry{child, hash})
+		}
+	}
+
+	size := uint64(max(64, len(keys)*bloomBitsPerKey))
+	size = (size + 63) &^ 63 // full words only
+	result := &tBloom{
+		bits: make([]uint64, size>>6),
+		size: size,
+	}
+	for _, key := range keys {
+		result.set(key)
+	}
+
+	return result
+} // newBloom()
+
+// ---------------------------------------------------------------------------
+// `tBloom` methods:
+
+// `add()` adds the key of the given pattern to the filter.
+//
+// Parameters:
+//   - `aPartsList`: The list of parts of the pattern to add.
+func (b *tBloom) add(aPartsList tPartsList) {
+	if nil == b {
+		return
+	}
+
+	hash := uint64(fnvOffset)
+	for _, label := range aPartsList {
+		if "*" == label {
+			b.set(bloomMix(hash ^ bloomWildSalt))
+			return
+		}
+		hash = bloomExtend(hash, label)
+	}
+	b.set(bloomMix(hash))
+} // add()
+
+// `has()` checks whether the given key might be in the filter.
+//
+// Parameters:
+//   - `aKey`: The (mixed) hash of the key to check.
+//
+// Returns:
+//   - `bool`: `false` if the key is definitely not in the filter.
+func (b *tBloom) has(aKey uint64) bool {
+	h1, h2 := aKey&0xffffffff, aKey>>32
+	for idx := range uint64(bloomHashes) {
+		bit := (h1 + idx*h2) % b.size
+		if 0 == atomic.LoadUint64(&b.bits[bit>>6])&(1<<(bit&63)) {
+			return false
+		}
+	}
+
+	return true
+} // has()
+
+// `mayMatch()` checks whether the given hostname might match any
+// pattern of the filter.
+//
+// Parameters:
+//   - `aHost`: The lower case hostname to check.
+//
+// Returns:
+//   - `bool`: `false` if the hostname matches no pattern for sure.
+func (b *tBloom) mayMatch(aHost string) bool {
+	if nil == b {
+		return true
+	}
+
+	hash := uint64(fnvOffset)
+	for end := len(aHost); ; {
+		// Is there a wildcard covering the remaining labels?
+		if b.has(bloomMix(hash ^ bloomWildSalt)) {
+			return true
+		}
+		label, start := hostLabel(aHost, end)
+		hash = bloomExtend(hash, label)
+		if 0 == start {
+			break
+		}
+		end = start - 1
+	}
+
+	return b.has(bloomMix(hash))
+} // mayMatch()
+
+// `set()` adds the given key to the filter.
+//
+// Parameters:
+//   - `aKey`: The (mixed) hash of the key to add.
+func (b *tBloom) set(aKey uint64) {
+	h1, h2 := aKey&0xffffffff, aKey>>32
+	for idx := range uint64(bloomHashes) {
+		bit := (h1 + idx*h2) % b.size
+		atomic.OrUint64(&b.bits[bit>>6], 1<<(bit&63))
+	}
+} // set()
+
+// `bytes()` returns the memory used by the filter.
+//
+// Returns:
+//   - `int`: The number of bytes used.
+func (b *tBloom) bytes() int {
+	if nil == b {
+		return 0
+	}
+
+	return len(b.bits) << 3
+} // bytes()
+
+// ---------------------------------------------------------------------------
+// `TADlist` methods:
+
+// `SetFiltered()` switches the Bloom filter of the deny list on or off.
+//
+// The filter answers most lookups of hostnames not in the deny list
+// with a few hash checks without touching (and locking) the deny list.
+// It's rebuilt after each reload of the deny list (see [LoadDeny]).
+//
+// Parameters:
+//   - `aCtx`: The context to use for the operation.
+//   - `aFiltered`: Whether to use a Bloom filter.
+//
+// Returns:
+//   - `int`: The memory used by the filter.
+func (adl *TADlist) SetFiltered(aCtx context.Context, aFiltered bool) int {
+	if nil == adl {
+		return 0
+	}
+
+	adl.filter.Store(aFiltered)
+	if !aFiltered {
+		adl.deny.filter.Store(nil)
+		return 0
+	}
+
+	return adl.deny.Filter(aCtx)
+} // SetFiltered()
+
+/* _EoF_ */
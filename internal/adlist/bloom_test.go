@@ -0,0 +1,202 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_bloomExtend(t *testing.T) {
+	// The label boundaries are part of the hash
+	one := bloomExtend(bloomExtend(fnvOffset, "ab"), "c")
+	two := bloomExtend(bloomExtend(fnvOffset, "a"), "bc")
+	if one == two {
+		t.Errorf("bloomExtend() = '%d', want different hashes", one)
+	}
+	if got := bloomExtend(fnvOffset, "tld"); got != bloomExtend(fnvOffset, "tld") {
+		t.Errorf("bloomExtend() = '%d', want a stable hash", got)
+	}
+} // Test_bloomExtend()
+
+func Test_newBloom(t *testing.T) {
+	trie := prepareCompiledTrie()
+
+	filter := newBloom(context.TODO(), trie.root.node)
+	if nil == filter {
+		t.Fatal("newBloom() = 'nil', want non-nil")
+	}
+	if 0 != filter.size%64 {
+		t.Errorf("newBloom() size = '%d', want a multiple of 64", filter.size)
+	}
+	if got := filter.bytes(); got != int(filter.size>>3) {
+		t.Errorf("tBloom.bytes() = '%d', want '%d'", got, filter.size>>3)
+	}
+
+	if got := newBloom(context.TODO(), nil); nil != got {
+		t.Errorf("newBloom() = '%v', want 'nil'", got)
+	}
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	if got := newBloom(ctx, trie.root.node); nil != got {
+		t.Errorf("newBloom() = '%v', want 'nil'", got)
+	}
+} // Test_newBloom()
+
+func Test_tBloom_mayMatch(t *testing.T) {
+	trie := prepareCompiledTrie()
+	filter := newBloom(context.TODO(), trie.root.node)
+
+	tests := []struct {
+		name     string
+		hostname string
+		want     bool
+	}{
+		/* */
+		{"01 - TLD", "tld", true},
+		{"02 - domain", "domain.tld", true},
+		{"03 - wildcard", "x.ads.tld", true},
+		{"04 - deep wildcard", "a.b.ads.tld", true},
+		{"05 - wildcard and literal", "www.ads.tld", true},
+		{"06 - literal", "tracker.example.com", true},
+		{"07 - wildcard below", "img.cdn.example.net", true},
+		{"08 - wildcard other", "www.example.org", true},
+		{"09 - upper case", "WWW.ADS.TLD", true},
+		{"10 - unknown", "example.net", false},
+		{"11 - unknown TLD", "example.invalid", false},
+		{"12 - literal parent", "example.com", false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := filter.mayMatch(strings.ToLower(tc.hostname)); got != tc.want {
+				t.Errorf("tBloom.mayMatch(%q) = '%v', want '%v'",
+					tc.hostname, got, tc.want)
+			}
+		})
+	}
+
+	var nilFilter *tBloom
+	if !nilFilter.mayMatch("tld") {
+		t.Error("tBloom.mayMatch() = 'false', want 'true'")
+	}
+} // Test_tBloom_mayMatch()
+
+func Test_tBloom_noFalseNegatives(t *testing.T) {
+	trie := prepareCompiledBench()
+	filter := newBloom(context.TODO(), trie.root.node)
+	ctx := context.TODO()
+
+	var hits, positives int
+	for i := range 20_000 {
+		hostname := fmt.Sprintf("www.site%d.%s", i, []string{"com", "net", "org", "de"}[i%4])
+		parts := pattern2parts(hostname)
+		maybe := filter.mayMatch(hostname)
+		if trie.root.node.match(ctx, parts) {
+			hits++
+			if !maybe {
+				t.Fatalf("tBloom.mayMatch(%q) = 'false', want 'true'", hostname)
+			}
+		} else if maybe {
+			positives++
+		}
+	}
+	misses := 20_000 - hits
+	// With 10 bits per key about 1% of the misses pass the filter
+	if rate := float64(positives) / float64(misses); 0.03 < rate {
+		t.Errorf("tBloom false positive rate = '%.3f', want <= '0.03'", rate)
+	}
+} // Test_tBloom_noFalseNegatives()
+
+func Test_tTrie_Filter(t *testing.T) {
+	ctx := context.TODO()
+	trie := prepareCompiledTrie()
+
+	if got := trie.Filter(ctx); 0 >= got {
+		t.Errorf("tTrie.Filter() = '%d', want > 0", got)
+	}
+	if nil == trie.filter.Load() {
+		t.Fatal("tTrie.Filter() didn't store the filter")
+	}
+	if trie.Match(ctx, "example.net") {
+		t.Error("tTrie.Match() = 'true', want 'false'")
+	}
+
+	// Additions are added to the filter
+	trie.Add(ctx, "*.example.net")
+	if !trie.Match(ctx, "www.example.net") {
+		t.Error("tTrie.Match() = 'false', want 'true'")
+	}
+	trie.Update(ctx, "*.example.net", "new.tld")
+	if !trie.Match(ctx, "new.tld") {
+		t.Error("tTrie.Match() = 'false', want 'true'")
+	}
+
+	// Deletions keep the filter
+	trie.Delete(ctx, "new.tld")
+	if nil == trie.filter.Load() {
+		t.Error("tTrie.Delete() dropped the filter")
+	}
+	if trie.Match(ctx, "new.tld") {
+		t.Error("tTrie.Match() = 'true', want 'false'")
+	}
+
+	var nilTrie *tTrie
+	if got := nilTrie.Filter(ctx); 0 != got {
+		t.Errorf("tTrie.Filter() = '%d', want '0'", got)
+	}
+} // Test_tTrie_Filter()
+
+func Test_TADlist_SetFiltered(t *testing.T) {
+	ctx := context.TODO()
+	adl := newTestList(t)
+	adl.deny.Add(ctx, "*.ads.tld")
+
+	if got := adl.SetFiltered(ctx, true); 0 >= got {
+		t.Errorf("TADlist.SetFiltered() = '%d', want > 0", got)
+	}
+	if got := adl.Match(ctx, "www.ads.tld"); ADdeny != got {
+		t.Errorf("TADlist.Match() = '%v', want '%v'", got, ADdeny)
+	}
+	if got := adl.Match(ctx, "www.example.tld"); ADneutral != got {
+		t.Errorf("TADlist.Match() = '%v', want '%v'", got, ADneutral)
+	}
+	if got := adl.SetFiltered(ctx, false); 0 != got {
+		t.Errorf("TADlist.SetFiltered() = '%d', want '0'", got)
+	}
+	if nil != adl.deny.filter.Load() {
+		t.Error("TADlist.SetFiltered() didn't drop the filter")
+	}
+} // Test_TADlist_SetFiltered()
+
+func Benchmark_tTrie_Match_filtered(b *testing.B) {
+	trie := prepareCompiledBench()
+	trie.Filter(context.TODO())
+	ctx := context.TODO()
+	b.ResetTimer()
+
+	for i := range b.N {
+		_ = trie.Match(ctx, fmt.Sprintf("www.host%d.com", i%12_500))
+	}
+} // Benchmark_tTrie_Match_filtered()
+
+func Benchmark_tTrie_Match_unfiltered(b *testing.B) {
+	trie := prepareCompiledBench()
+	ctx := context.TODO()
+	b.ResetTimer()
+
+	for i := range b.N {
+		_ = trie.Match(ctx, fmt.Sprintf("www.host%d.com", i%12_500))
+	}
+} // Benchmark_tTrie_Match_unfiltered()
+
+/* _EoF_ */
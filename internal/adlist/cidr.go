@@ -0,0 +1,256 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"sync"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tCIDRnode` is a node of the binary CIDR trie.
+	//
+	// Each level of the trie represents a single bit of an IP address.
+	tCIDRnode struct {
+		children [2]*tCIDRnode
+		terminal bool // a range ends at this node
+	}
+
+	// `TCIDRlist` is a thread-safe list of IP address ranges (CIDRs).
+	//
+	// The ranges are stored in two binary tries (one for IPv4 and one
+	// for IPv6) so that checking an IP address takes at most 32 or 128
+	// steps respectively regardless of the number of ranges.
+	TCIDRlist struct {
+		sync.RWMutex
+		v4    *tCIDRnode
+		v6    *tCIDRnode
+		count int // number of ranges in the list
+	}
+)
+
+var (
+	// `ErrInvalidCIDR` is returned if a given IP range is invalid.
+	ErrInvalidCIDR = ADlistError{errors.New("CIDR is invalid")}
+)
+
+// ---------------------------------------------------------------------------
+// `TCIDRlist` constructor:
+
+// `NewCIDRlist()` returns a new, empty `TCIDRlist` instance.
+//
+// Returns:
+//   - `*TCIDRlist`: A new `TCIDRlist` instance.
+func NewCIDRlist() *TCIDRlist {
+	return &TCIDRlist{
+		v4: &tCIDRnode{},
+		v6: &tCIDRnode{},
+	}
+} // NewCIDRlist()
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `parseCIDR()` parses an IP range in CIDR notation.
+//
+// A single IP address (without a prefix length) is accepted as well
+// and treated as a range of exactly that address.
+//
+// Parameters:
+//   - `aCIDR`: The IP range to parse.
+//
+// Returns:
+//   - `net.IP`: The range's (4 or 16 byte) network address.
+//   - `int`: The range's prefix length.
+//   - `error`: `nil` if the range is valid, `ErrInvalidCIDR` otherwise.
+func parseCIDR(aCIDR string) (net.IP, int, error) {
+	if aCIDR = strings.TrimSpace(aCIDR); 0 == len(aCIDR) {
+		return nil, 0, ErrInvalidCIDR
+	}
+
+	if !strings.Contains(aCIDR, "/") {
+		ip := net.ParseIP(aCIDR)
+		if nil == ip {
+			return nil, 0, ErrInvalidCIDR
+		}
+		if ip4 := ip.To4(); nil != ip4 {
+			return ip4, 32, nil
+		}
+		return ip, 128, nil
+	}
+
+	_, ipNet, err := net.ParseCIDR(aCIDR)
+	if nil != err {
+		return nil, 0, ErrInvalidCIDR
+	}
+	ones, _ := ipNet.Mask.Size()
+
+	return ipNet.IP, ones, nil
+} // parseCIDR()
+
+// `ipBit()` returns the bit at the given position of an IP address.
+//
+// Parameters:
+//   - `aIP`: The IP address to use.
+//   - `aPos`: The bit's position (starting with `0` for the MSB).
+//
+// Returns:
+//   - `int`: The bit's value (`0` or `1`).
+func ipBit(aIP net.IP, aPos int) int {
+	return int(aIP[aPos>>3]>>(7-uint(aPos&7))) & 1
+} // ipBit()
+
+// ---------------------------------------------------------------------------
+// `TCIDRlist` methods:
+
+// `Add()` inserts an IP range into the list.
+//
+// Parameters:
+//   - `aCIDR`: The IP range (e.g. `192.0.2.0/24`) or single IP to add.
+//
+// Returns:
+//   - `error`: `nil` if the range was added, the error otherwise.
+func (cl *TCIDRlist) Add(aCIDR string) error {
+	if nil == cl {
+		return ErrListNil
+	}
+	ip, ones, err := parseCIDR(aCIDR)
+	if nil != err {
+		return err
+	}
+
+	cl.Lock()
+	defer cl.Unlock()
+
+	node := cl.root(ip)
+	for pos := range ones {
+		bit := ipBit(ip, pos)
+		if nil == node.children[bit] {
+			node.children[bit] = &tCIDRnode{}
+		}
+		node = node.children[bit]
+	}
+	if !node.terminal {
+		node.terminal = true
+		cl.count++
+	}
+
+	return nil
+} // Add()
+
+// `Contains()` checks whether the given IP address falls into one of
+// the list's ranges.
+//
+// Parameters:
+//   - `aIP`: The IP address to check.
+//
+// Returns:
+//   - `bool`: `true` if the address is in the list, `false` otherwise.
+func (cl *TCIDRlist) Contains(aIP net.IP) bool {
+	if (nil == cl) || (nil == aIP) {
+		return false
+	}
+	ip := aIP.To4()
+	if nil == ip {
+		if ip = aIP.To16(); nil == ip {
+			return false
+		}
+	}
+
+	cl.RLock()
+	defer cl.RUnlock()
+
+	node := cl.root(ip)
+	if nil == node {
+		return false
+	}
+	for pos := range len(ip) << 3 {
+		if node.terminal {
+			return true
+		}
+		if node = node.children[ipBit(ip, pos)]; nil == node {
+			return false
+		}
+	}
+
+	return node.terminal
+} // Contains()
+
+// `Delete()` removes an IP range from the list.
+//
+// Only the exact range is removed; ranges which are part of or
+// contain the given range are left untouched.
+//
+// Parameters:
+//   - `aCIDR`: The IP range to remove.
+//
+// Returns:
+//   - `bool`: `true` if the range was found and removed, `false` otherwise.
+func (cl *TCIDRlist) Delete(aCIDR string) bool {
+	if nil == cl {
+		return false
+	}
+	ip, ones, err := parseCIDR(aCIDR)
+	if nil != err {
+		return false
+	}
+
+	cl.Lock()
+	defer cl.Unlock()
+
+	node := cl.root(ip)
+	for pos := range ones {
+		if node = node.children[ipBit(ip, pos)]; nil == node {
+			return false
+		}
+	}
+	if !node.terminal {
+		return false
+	}
+	node.terminal = false
+	cl.count--
+
+	// NOTE: Empty branches are not pruned; they are harmless and
+	// get re-used when the range is added again.
+
+	return true
+} // Delete()
+
+// `Len()` returns the number of IP ranges in the list.
+//
+// Returns:
+//   - `int`: The number of ranges.
+func (cl *TCIDRlist) Len() int {
+	if nil == cl {
+		return 0
+	}
+	cl.RLock()
+	defer cl.RUnlock()
+
+	return cl.count
+} // Len()
+
+// `root()` returns the trie's root node for the given IP address.
+//
+// Parameters:
+//   - `aIP`: The (4 or 16 byte) IP address.
+//
+// Returns:
+//   - `*tCIDRnode`: The IPv4 or IPv6 root node.
+func (cl *TCIDRlist) root(aIP net.IP) *tCIDRnode {
+	if net.IPv4len == len(aIP) {
+		return cl.v4
+	}
+
+	return cl.v6
+} // root()
+
+/* _EoF_ */
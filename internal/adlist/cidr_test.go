@@ -0,0 +1,222 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"net"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_parseCIDR(t *testing.T) {
+	tests := []struct {
+		name     string
+		cidr     string
+		wantIP   string
+		wantOnes int
+		wantErr  bool
+	}{
+		/* */
+		{
+			name:    "01 - empty",
+			cidr:    "",
+			wantErr: true,
+		},
+		{
+			name:    "02 - invalid",
+			cidr:    "192.0.2.0/33",
+			wantErr: true,
+		},
+		{
+			name:     "03 - IPv4 range",
+			cidr:     "192.0.2.17/24",
+			wantIP:   "192.0.2.0",
+			wantOnes: 24,
+		},
+		{
+			name:     "04 - single IPv4",
+			cidr:     " 192.0.2.17 ",
+			wantIP:   "192.0.2.17",
+			wantOnes: 32,
+		},
+		{
+			name:     "05 - IPv6 range",
+			cidr:     "2001:db8::/32",
+			wantIP:   "2001:db8::",
+			wantOnes: 32,
+		},
+		{
+			name:     "06 - single IPv6",
+			cidr:     "2001:db8::1",
+			wantIP:   "2001:db8::1",
+			wantOnes: 128,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ip, ones, err := parseCIDR(tc.cidr)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("parseCIDR() error = '%v', wantErr '%v'",
+					err, tc.wantErr)
+				return
+			}
+			if tc.wantErr {
+				return
+			}
+			if ip.String() != tc.wantIP {
+				t.Errorf("parseCIDR() IP = '%v', want '%v'", ip, tc.wantIP)
+			}
+			if ones != tc.wantOnes {
+				t.Errorf("parseCIDR() ones = '%d', want '%d'",
+					ones, tc.wantOnes)
+			}
+		})
+	}
+} // Test_parseCIDR()
+
+func Test_TCIDRlist_Contains(t *testing.T) {
+	cl := NewCIDRlist()
+	for _, cidr := range []string{
+		"192.0.2.0/24",
+		"198.51.100.7",
+		"2001:db8:bad::/48",
+	} {
+		if err := cl.Add(cidr); nil != err {
+			t.Fatalf("TCIDRlist.Add(%q) error = '%v'", cidr, err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		cl   *TCIDRlist
+		ip   net.IP
+		want bool
+	}{
+		/* */
+		{
+			name: "01 - nil list",
+			cl:   nil,
+			ip:   net.ParseIP("192.0.2.1"),
+			want: false,
+		},
+		{
+			name: "02 - nil IP",
+			cl:   cl,
+			ip:   nil,
+			want: false,
+		},
+		{
+			name: "03 - IPv4 in range",
+			cl:   cl,
+			ip:   net.ParseIP("192.0.2.200"),
+			want: true,
+		},
+		{
+			name: "04 - IPv4 outside range",
+			cl:   cl,
+			ip:   net.ParseIP("192.0.3.1"),
+			want: false,
+		},
+		{
+			name: "05 - single IPv4",
+			cl:   cl,
+			ip:   net.ParseIP("198.51.100.7"),
+			want: true,
+		},
+		{
+			name: "06 - neighbour of single IPv4",
+			cl:   cl,
+			ip:   net.ParseIP("198.51.100.8"),
+			want: false,
+		},
+		{
+			name: "07 - IPv6 in range",
+			cl:   cl,
+			ip:   net.ParseIP("2001:db8:bad:1::42"),
+			want: true,
+		},
+		{
+			name: "08 - IPv6 outside range",
+			cl:   cl,
+			ip:   net.ParseIP("2001:db8:bae::1"),
+			want: false,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.cl.Contains(tc.ip); got != tc.want {
+				t.Errorf("TCIDRlist.Contains() = '%v', want '%v'",
+					got, tc.want)
+			}
+		})
+	}
+} // Test_TCIDRlist_Contains()
+
+func Test_TCIDRlist_Delete(t *testing.T) {
+	tests := []struct {
+		name    string
+		add     []string
+		cidr    string
+		want    bool
+		wantLen int
+	}{
+		/* */
+		{
+			name:    "01 - empty list",
+			add:     nil,
+			cidr:    "192.0.2.0/24",
+			want:    false,
+			wantLen: 0,
+		},
+		{
+			name:    "02 - existing range",
+			add:     []string{"192.0.2.0/24", "10.0.0.0/8"},
+			cidr:    "192.0.2.0/24",
+			want:    true,
+			wantLen: 1,
+		},
+		{
+			name:    "03 - sub-range",
+			add:     []string{"10.0.0.0/8"},
+			cidr:    "10.1.0.0/16",
+			want:    false,
+			wantLen: 1,
+		},
+		{
+			name:    "04 - invalid range",
+			add:     []string{"10.0.0.0/8"},
+			cidr:    "10.0.0.0/99",
+			want:    false,
+			wantLen: 1,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			cl := NewCIDRlist()
+			for _, cidr := range tc.add {
+				_ = cl.Add(cidr)
+			}
+			if got := cl.Delete(tc.cidr); got != tc.want {
+				t.Errorf("TCIDRlist.Delete() = '%v', want '%v'",
+					got, tc.want)
+			}
+			if got := cl.Len(); got != tc.wantLen {
+				t.Errorf("TCIDRlist.Len() = '%d', want '%d'",
+					got, tc.wantLen)
+			}
+		})
+	}
+} // Test_TCIDRlist_Delete()
+
+/* _EoF_ */
@@ -0,0 +1,305 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"context"
+	"encoding/binary"
+	"slices"
+	"sort"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tCEdge` is a labelled edge of the compiled trie.
+	tCEdge struct {
+		label uint32 // index into the label table
+		node  uint32 // index of the target node
+	}
+
+	// `tCNode` is a node of the compiled trie.
+	tCNode struct {
+		first      uint32 // index of the node's first edge
+		count      uint32 // number of the node's edges
+		terminator uint8  // flags for pattern end and wildcard
+	}
+
+	// `tCompiled` is an immutable, compact copy of a trie.
+	//
+	// All labels are stored only once in a sorted table, and all
+	// identical sub-trees (e.g. the countless leaf nodes) are merged
+	// into one, turning the trie into a minimal acyclic automaton
+	// (DAFSA). The edges of a node are stored consecutively, sorted
+	// by label, so a child is found by binary search.
+	tCompiled struct {
+		labels   []string // sorted table of all labels
+		edges    []tCEdge // edges of all nodes
+		nodes    []tCNode // all distinct nodes
+		root     uint32   // index of the root node
+		wildcard int      // index of the `*` label (`-1` if none)
+	}
+)
+
+// ---------------------------------------------------------------------------
+// `tCompiled` constructor:
+
+// `compileNode()` converts the given node's tree into a compiled trie.
+//
+// The method is not thread-safe in itself but expects to be RLocked
+// by the calling `tTrie` instance.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//   - `aNode`: The root node of the tree to compile.
+//
+// Returns:
+//   - `*tCompiled`: The compiled trie, `nil` in case of cancellation.
+func compileNode(aCtx context.Context, aNode *tNode) *tCompiled {
+	if nil == aNode {
+		return nil
+	}
+	type (
+		tStackEntry struct {
+			node     *tNode
+			kidNames tPartsList // sorted child labels
+			kids     []uint32   // compiled child nodes
+		}
+	)
+
+	// First pass: collect the label table
+	seen := make(map[string]struct{})
+	aNode.forEach(aCtx, func(aChild *tNode) {
+		for label := range aChild.tChildren.All() {
+			seen[label] = struct{}{}
+		}
+	})
+	if nil != aCtx.Err() {
+		return nil
+	}
+	labels := make([]string, 0, len(seen))
+	for label := range seen {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	ids := make(map[string]uint32, len(labels))
+	for idx, label := range labels {
+		ids[label] = uint32(idx) //#nosec G115
+	}
+
+	// Second pass: add the nodes bottom-up, merging identical ones
+	result := &tCompiled{
+		labels:   labels,
+		wildcard: -1,
+	}
+	if id, ok := ids["*"]; ok {
+		result.wildcard = int(id)
+	}
+	known := make(map[string]uint32)
+	key := make([]byte, 0, 64)
+
+	stack := []tStackEntry{{node: aNode, kidNames: aNode.tChildren.Labels()}}
+	for 0 < len(stack) {
+		if nil != aCtx.Err() {
+			return nil
+		}
+		entry := &stack[len(stack)-1]
+
+		// Descend into the next child not compiled yet
+		if len(entry.kids) < len(entry.kidNames) {
+			child, _ := entry.node.tChildren.Get(entry.kidNames[len(entry.kids)])
+			stack = append(stack, tStackEntry{
+				node:     child,
+				kidNames: child.tChildren.Labels(),
+			})
+			continue
+		}
+
+		// All children are compiled: look for an identical node
+		key = append(key[:0], entry.node.terminator)
+		for idx, label := range entry.kidNames {
+			key = binary.AppendUvarint(key, uint64(ids[label]))
+			key = binary.AppendUvarint(key, uint64(entry.kids[idx]))
+		}
+		id, ok := known[string(key)]
+		if !ok {
+			id = uint32(len(result.nodes)) //#nosec G115
+			result.nodes = append(result.nodes, tCNode{
+				first:      uint32(len(result.edges)), //#nosec G115
+				count:      uint32(len(entry.kids)),   //#nosec G115
+				terminator: entry.node.terminator,
+			})
+			for idx, label := range entry.kidNames {
+				result.edges = append(result.edges, tCEdge{ids[label], entry.kids[idx]})
+			}
+			known[string(key)] = id
+		}
+
+		stack = stack[:len(stack)-1]
+		if 0 == len(stack) {
+			result.root = id
+		} else {
+			parent := &stack[len(stack)-1]
+			parent.kids = append(parent.kids, id)
+		}
+	}
+	result.edges = slices.Clip(result.edges)
+	result.nodes = slices.Clip(result.nodes)
+
+	return result
+} // compileNode()
+
+// ---------------------------------------------------------------------------
+// `tCompiled` methods:
+
+// `child()` returns the given node's child with the given label.
+//
+// Parameters:
+//   - `aNode`: The index of the parent node.
+//   - `aLabel`: The index of the child's label.
+//
+// Returns:
+//   - `uint32`: The index of the child node.
+//   - `bool`: `true` if the child was found, `false` otherwise.
+func (c *tCompiled) child(aNode uint32, aLabel int) (uint32, bool) {
+	if 0 > aLabel {
+		return 0, false
+	}
+	node := c.nodes[aNode]
+	edges := c.edges[node.first : node.first+node.count]
+	idx, ok := slices.BinarySearchFunc(edges, uint32(aLabel), //#nosec G115
+		func(aEdge tCEdge, aTarget uint32) int {
+			return int(aEdge.label) - int(aTarget)
+		})
+	if !ok {
+		return 0, false
+	}
+
+	return edges[idx].node, true
+} // child()
+
+// `labelID()` returns the index of the given label.
+//
+// Parameters:
+//   - `aLabel`: The label to look up.
+//
+// Returns:
+//   - `int`: The label's index, `-1` if it's unknown.
+func (c *tCompiled) labelID(aLabel string) int {
+	if idx, ok := slices.BinarySearch(c.labels, aLabel); ok {
+		return idx
+	}
+
+	return -1
+} // labelID()
+
+// `match()` checks whether the compiled trie contains the given hostname.
+//
+// The method works exactly like `tNode.matchHost()` but on the compiled
+// data which is immutable, hence no locking is required.
+//
+// Parameters:
+//   - `aHost`: The lower case hostname to check.
+//
+// Returns:
+//   - `bool`: `true` if the hostname is in the compiled trie, `false` otherwise.
+func (c *tCompiled) match(aHost string) bool {
+	if (nil == c) || (0 == len(aHost)) {
+		return false
+	}
+
+	return c.matchFrom(c.root, aHost, len(aHost))
+} // match()
+
+// `matchFrom()` checks whether the given node's sub-trie contains the
+// hostname's labels up to `aEnd`.
+//
+// The method works exactly like `tNode.matchFrom()` but on the
+// compiled data.
+//
+// Parameters:
+//   - `aNode`: The index of the node to start at.
+//   - `aHost`: The lower case hostname to check.
+//   - `aEnd`: The index after the hostname's last label to check.
+//
+// Returns:
+//   - `bool`: `true` if the labels are in the sub-trie, `false` otherwise.
+func (c *tCompiled) matchFrom(aNode uint32, aHost string, aEnd int) bool {
+	label, start := hostLabel(aHost, aEnd)
+	if child, ok := c.child(aNode, c.labelID(label)); ok {
+		if 0 == start {
+			if 0 != c.nodes[child].terminator {
+				return true
+			}
+		} else if c.matchFrom(child, aHost, start-1) {
+			return true
+		}
+	}
+	child, ok := c.child(aNode, c.wildcard)
+	if !ok {
+		return false
+	}
+	if (c.nodes[child].terminator & wildMask) == wildMask {
+		return true
+	}
+
+	// A wildcard inside a pattern stands for one label
+	return (0 != start) && c.matchFrom(child, aHost, start-1)
+} // matchFrom()
+
+// `size()` returns the approximate memory used by the compiled trie.
+//
+// Returns:
+//   - `int`: The number of bytes used.
+func (c *tCompiled) size() (rSize int) {
+	if nil == c {
+		return
+	}
+	rSize = len(c.nodes)*12 + len(c.edges)*8
+	for _, label := range c.labels {
+		rSize += 16 + len(label)
+	}
+
+	return
+} // size()
+
+// ---------------------------------------------------------------------------
+// `TADlist` methods:
+
+// `SetCompiled()` switches the compilation of the deny list on or off.
+//
+// A compiled deny list is an immutable, compact copy of the deny list
+// which is matched without locking. It's rebuilt after each reload of
+// the deny list (see [LoadDeny]) and swapped in atomically. Any other
+// change of the deny list (e.g. by [AddDeny]) drops the compiled copy
+// until the next reload, so matching falls back to the (slower)
+// mutable list in the meantime.
+//
+// This is meant for deployments where the deny list only changes on
+// scheduled reloads.
+//
+// Parameters:
+//   - `aCtx`: The context to use for the operation.
+//   - `aCompiled`: Whether to compile the deny list.
+//
+// Returns:
+//   - `int`: The approximate memory used by the compiled deny list.
+func (adl *TADlist) SetCompiled(aCtx context.Context, aCompiled bool) int {
+	if nil == adl {
+		return 0
+	}
+
+	adl.compile.Store(aCompiled)
+	if !aCompiled {
+		adl.deny.compiled.Store(nil)
+		return 0
+	}
+
+	return adl.deny.Compile(aCtx)
+} // SetCompiled()
+
+/* _EoF_ */
@@ -0,0 +1,217 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// `prepareCompiledTrie()` returns a trie with some patterns.
+func prepareCompiledTrie() *tTrie {
+	trie := newTrie()
+	ctx := context.TODO()
+	for _, pattern := range []string{
+		"tld",
+		"domain.tld",
+		"*.ads.tld",
+		"www.ads.tld",
+		"tracker.example.com",
+		"ads.example.com",
+		"*.cdn.example.net",
+		"host.sub.example.org",
+		"*.example.org",
+	} {
+		trie.Add(ctx, pattern)
+	}
+
+	return trie
+} // prepareCompiledTrie()
+
+func Test_compileNode(t *testing.T) {
+	trie := prepareCompiledTrie()
+	compiled := compileNode(context.TODO(), trie.root.node)
+	if nil == compiled {
+		t.Fatal("compileNode() = 'nil', want non-nil")
+	}
+
+	nodes, _ := trie.Count(context.TODO())
+	// All leaf nodes are identical, hence merged
+	if len(compiled.nodes) >= nodes {
+		t.Errorf("compileNode() nodes = '%d', want less than '%d'",
+			len(compiled.nodes), nodes)
+	}
+	if (0 > compiled.wildcard) || ("*" != compiled.labels[compiled.wildcard]) {
+		t.Errorf("compileNode() wildcard = '%d', want the index of '*'",
+			compiled.wildcard)
+	}
+	if 0 >= compiled.size() {
+		t.Errorf("tCompiled.size() = '%d', want > 0", compiled.size())
+	}
+
+	if got := compileNode(context.TODO(), nil); nil != got {
+		t.Errorf("compileNode() = '%v', want 'nil'", got)
+	}
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	if got := compileNode(ctx, trie.root.node); nil != got {
+		t.Errorf("compileNode() = '%v', want 'nil'", got)
+	}
+} // Test_compileNode()
+
+func Test_tCompiled_match(t *testing.T) {
+	trie := prepareCompiledTrie()
+	compiled := compileNode(context.TODO(), trie.root.node)
+
+	tests := []struct {
+		name     string
+		hostname string
+	}{
+		/* */
+		{"01 - TLD", "tld"},
+		{"02 - domain", "domain.tld"},
+		{"03 - unknown subdomain", "www.domain.tld"},
+		{"04 - wildcard", "x.ads.tld"},
+		{"05 - wildcard and literal", "www.ads.tld"},
+		{"06 - wildcard parent", "ads.tld"},
+		{"07 - deep wildcard", "a.b.ads.tld"},
+		{"08 - literal", "tracker.example.com"},
+		{"09 - literal parent", "example.com"},
+		{"10 - literal child", "www.tracker.example.com"},
+		{"11 - unknown", "example.net"},
+		{"12 - wildcard below", "img.cdn.example.net"},
+		{"13 - wildcard sibling", "host.sub.example.org"},
+		{"14 - wildcard other", "www.example.org"},
+		{"15 - unknown TLD", "example.invalid"},
+		{"16 - upper case", "WWW.ADS.TLD"},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			parts := pattern2parts(tc.hostname)
+			want := trie.root.node.match(context.TODO(), parts)
+			if got := compiled.match(strings.ToLower(tc.hostname)); got != want {
+				t.Errorf("tCompiled.match(%q) = '%v', want '%v'",
+					tc.hostname, got, want)
+			}
+		})
+	}
+
+	var nilCompiled *tCompiled
+	if nilCompiled.match("tld") {
+		t.Error("tCompiled.match() = 'true', want 'false'")
+	}
+} // Test_tCompiled_match()
+
+func Test_tTrie_Compile(t *testing.T) {
+	ctx := context.TODO()
+	trie := prepareCompiledTrie()
+
+	if got := trie.Compile(ctx); 0 >= got {
+		t.Errorf("tTrie.Compile() = '%d', want > 0", got)
+	}
+	if nil == trie.compiled.Load() {
+		t.Fatal("tTrie.Compile() didn't store the compiled trie")
+	}
+	if !trie.Match(ctx, "x.ads.tld") {
+		t.Error("tTrie.Match() = 'false', want 'true'")
+	}
+
+	// Any change drops the compiled trie
+	trie.Add(ctx, "new.tld")
+	if nil != trie.compiled.Load() {
+		t.Error("tTrie.Add() didn't drop the compiled trie")
+	}
+	if !trie.Match(ctx, "new.tld") {
+		t.Error("tTrie.Match() = 'false', want 'true'")
+	}
+
+	var nilTrie *tTrie
+	if got := nilTrie.Compile(ctx); 0 != got {
+		t.Errorf("tTrie.Compile() = '%d', want '0'", got)
+	}
+} // Test_tTrie_Compile()
+
+func Test_TADlist_SetCompiled(t *testing.T) {
+	ctx := context.TODO()
+	adl := newTestList(t)
+	adl.deny.Add(ctx, "*.ads.tld")
+
+	if got := adl.SetCompiled(ctx, true); 0 >= got {
+		t.Errorf("TADlist.SetCompiled() = '%d', want > 0", got)
+	}
+	if got := adl.Match(ctx, "www.ads.tld"); ADdeny != got {
+		t.Errorf("TADlist.Match() = '%v', want '%v'", got, ADdeny)
+	}
+	if got := adl.SetCompiled(ctx, false); 0 != got {
+		t.Errorf("TADlist.SetCompiled() = '%d', want '0'", got)
+	}
+	if nil != adl.deny.compiled.Load() {
+		t.Error("TADlist.SetCompiled() didn't drop the compiled list")
+	}
+} // Test_TADlist_SetCompiled()
+
+// `prepareCompiledBench()` returns a trie with many patterns.
+func prepareCompiledBench() *tTrie {
+	subs := []string{"www", "ads", "ad", "track", "metrics", "cdn", "static", "pixel"}
+	tlds := []string{"com", "net", "org", "de"}
+	trie := newTrie()
+	ctx := context.TODO()
+	for i := range 100_000 {
+		trie.Add(ctx, fmt.Sprintf("%s.site%d.%s",
+			subs[i%len(subs)], i/len(subs), tlds[(i/len(subs))%len(tlds)]))
+	}
+
+	return trie
+} // prepareCompiledBench()
+
+func Benchmark_tTrie_Compile_memory(b *testing.B) {
+	b.ReportAllocs()
+
+	for range b.N {
+		b.StopTimer()
+		before := heapInUse()
+		trie := prepareCompiledBench()
+		trieSize := heapInUse() - before
+		b.StartTimer()
+
+		compiled := compileNode(context.TODO(), trie.root.node)
+
+		b.StopTimer()
+		b.ReportMetric(float64(trieSize), "trieB")
+		b.ReportMetric(float64(compiled.size()), "compiledB")
+		b.StartTimer()
+	}
+} // Benchmark_tTrie_Compile_memory()
+
+func Benchmark_tTrie_Match_compiled(b *testing.B) {
+	trie := prepareCompiledBench()
+	trie.Compile(context.TODO())
+	ctx := context.TODO()
+	b.ResetTimer()
+
+	for i := range b.N {
+		_ = trie.Match(ctx, fmt.Sprintf("www.site%d.com", i%12_500))
+	}
+} // Benchmark_tTrie_Match_compiled()
+
+func Benchmark_tTrie_Match_mutable(b *testing.B) {
+	trie := prepareCompiledBench()
+	ctx := context.TODO()
+	b.ResetTimer()
+
+	for i := range b.N {
+		_ = trie.Match(ctx, fmt.Sprintf("www.site%d.com", i%12_500))
+	}
+} // Benchmark_tTrie_Match_mutable()
+
+/* _EoF_ */
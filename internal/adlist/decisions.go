@@ -0,0 +1,175 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"container/list"
+	"sync"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+const (
+	// `adDecisionCacheSize` is the default number of decisions
+	// remembered by a `TADlist`.
+	adDecisionCacheSize = 1 << 10
+)
+
+type (
+	// `tDecision` is a single entry of the decision cache.
+	tDecision struct {
+		hostname string
+		pattern  string // the matching deny pattern, if any
+		result   TADresult
+	}
+
+	// `tDecisionCache` is a bounded LRU cache of recent
+	// (hostname → `TADresult`) decisions along with the deny
+	// pattern causing them.
+	//
+	// Each change of the allow or deny list has to invalidate the
+	// cache by calling its `clear()` method. To avoid storing stale
+	// results of lookups running concurrently to such a change,
+	// results are only stored if the cache's generation didn't change
+	// since the lookup started.
+	tDecisionCache struct {
+		sync.Mutex
+		entries map[string]*list.Element
+		order   *list.List // front: most recently used
+		gen     uint64     // incremented by each `clear()`
+		size    int        // max. number of entries
+	}
+)
+
+// ---------------------------------------------------------------------------
+// `tDecisionCache` constructor:
+
+// `newDecisionCache()` returns a new decision cache.
+//
+// Parameters:
+//   - `aSize`: The max. number of decisions to remember.
+//
+// Returns:
+//   - `*tDecisionCache`: The new cache (`nil` if `aSize` is not positive).
+func newDecisionCache(aSize int) *tDecisionCache {
+	if 0 >= aSize {
+		return nil
+	}
+
+	return &tDecisionCache{
+		entries: make(map[string]*list.Element, aSize),
+		order:   list.New(),
+		size:    aSize,
+	}
+} // newDecisionCache()
+
+// ---------------------------------------------------------------------------
+// `tDecisionCache` methods:
+
+// `clear()` removes all decisions from the cache.
+func (dc *tDecisionCache) clear() {
+	if nil == dc {
+		return
+	}
+	dc.Lock()
+	defer dc.Unlock()
+
+	dc.gen++
+	clear(dc.entries)
+	dc.order.Init()
+} // clear()
+
+// `get()` returns the cached decision for the given hostname.
+//
+// The returned generation is to be passed to `put()` when storing
+// the result of a lookup done because of a cache miss.
+//
+// Parameters:
+//   - `aHostname`: The hostname to look up.
+//
+// Returns:
+//   - `rResult`: The cached decision.
+//   - `rPattern`: The deny pattern causing the decision, if any.
+//   - `rGen`: The cache's current generation.
+//   - `rOK`: `true` if a decision was found, `false` otherwise.
+func (dc *tDecisionCache) get(aHostname string) (rResult TADresult, rPattern string, rGen uint64, rOK bool) {
+	if nil == dc {
+		return
+	}
+	dc.Lock()
+	defer dc.Unlock()
+
+	rGen = dc.gen
+	elem, ok := dc.entries[aHostname]
+	if !ok {
+		return
+	}
+	dc.order.MoveToFront(elem)
+	decision := elem.Value.(*tDecision)
+	rResult, rPattern, rOK = decision.result, decision.pattern, true
+
+	return
+} // get()
+
+// `Len()` returns the number of cached decisions.
+//
+// Returns:
+//   - `int`: The number of cached decisions.
+func (dc *tDecisionCache) Len() int {
+	if nil == dc {
+		return 0
+	}
+	dc.Lock()
+	defer dc.Unlock()
+
+	return len(dc.entries)
+} // Len()
+
+// `put()` stores a decision for the given hostname.
+//
+// If the cache's generation changed since `aGen` was obtained, the
+// decision is considered stale and not stored. If the cache is full
+// the least recently used decision is evicted.
+//
+// Parameters:
+//   - `aHostname`: The hostname the decision was made for.
+//   - `aResult`: The decision to remember.
+//   - `aPattern`: The deny pattern causing the decision, if any.
+//   - `aGen`: The cache's generation as returned by `get()`.
+func (dc *tDecisionCache) put(aHostname string, aResult TADresult, aPattern string, aGen uint64) {
+	if nil == dc {
+		return
+	}
+	dc.Lock()
+	defer dc.Unlock()
+
+	if aGen != dc.gen {
+		return // the lists changed during the lookup
+	}
+
+	if elem, ok := dc.entries[aHostname]; ok {
+		decision := elem.Value.(*tDecision)
+		decision.result, decision.pattern = aResult, aPattern
+		dc.order.MoveToFront(elem)
+		return
+	}
+
+	if dc.order.Len() >= dc.size {
+		if oldest := dc.order.Back(); nil != oldest {
+			dc.order.Remove(oldest)
+			delete(dc.entries, oldest.Value.(*tDecision).hostname)
+		}
+	}
+
+	dc.entries[aHostname] = dc.order.PushFront(&tDecision{
+		hostname: aHostname,
+		pattern:  aPattern,
+		result:   aResult,
+	})
+} // put()
+
+/* _EoF_ */
@@ -0,0 +1,159 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_newDecisionCache(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		wantNil bool
+	}{
+		/* */
+		{
+			name:    "01 - zero size",
+			size:    0,
+			wantNil: true,
+		},
+		{
+			name:    "02 - negative size",
+			size:    -1,
+			wantNil: true,
+		},
+		{
+			name:    "03 - positive size",
+			size:    8,
+			wantNil: false,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := newDecisionCache(tc.size)
+			if (nil == got) != tc.wantNil {
+				t.Errorf("newDecisionCache() = '%v', wantNil '%v'",
+					got, tc.wantNil)
+			}
+		})
+	}
+} // Test_newDecisionCache()
+
+func Test_tDecisionCache_get(t *testing.T) {
+	dc := newDecisionCache(2)
+	_, _, gen, _ := dc.get("a.tld")
+	dc.put("a.tld", ADallow, "", gen)
+	dc.put("b.tld", ADdeny, "b.tld", gen)
+	_, _, _, _ = dc.get("a.tld") // makes "b.tld" the oldest entry
+	dc.put("c.tld", ADdeny, "*.tld", gen)
+
+	tests := []struct {
+		name     string
+		dc       *tDecisionCache
+		hostname string
+		want     TADresult
+		wantPat  string
+		wantOK   bool
+	}{
+		/* */
+		{
+			name:     "01 - nil cache",
+			dc:       nil,
+			hostname: "a.tld",
+			want:     ADneutral,
+			wantOK:   false,
+		},
+		{
+			name:     "02 - recently used entry",
+			dc:       dc,
+			hostname: "a.tld",
+			want:     ADallow,
+			wantOK:   true,
+		},
+		{
+			name:     "03 - evicted entry",
+			dc:       dc,
+			hostname: "b.tld",
+			want:     ADneutral,
+			wantOK:   false,
+		},
+		{
+			name:     "04 - newest entry",
+			dc:       dc,
+			hostname: "c.tld",
+			want:     ADdeny,
+			wantPat:  "*.tld",
+			wantOK:   true,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, gotPat, _, gotOK := tc.dc.get(tc.hostname)
+			if gotOK != tc.wantOK {
+				t.Errorf("tDecisionCache.get() ok = '%v', want '%v'",
+					gotOK, tc.wantOK)
+			}
+			if got != tc.want {
+				t.Errorf("tDecisionCache.get() = '%v', want '%v'",
+					got, tc.want)
+			}
+			if gotPat != tc.wantPat {
+				t.Errorf("tDecisionCache.get() pattern = '%s', want '%s'",
+					gotPat, tc.wantPat)
+			}
+		})
+	}
+} // Test_tDecisionCache_get()
+
+func Test_tDecisionCache_put(t *testing.T) {
+	tests := []struct {
+		name    string
+		prepare func(*tDecisionCache) uint64
+		wantLen int
+	}{
+		/* */
+		{
+			name: "01 - store decision",
+			prepare: func(dc *tDecisionCache) uint64 {
+				_, _, gen, _ := dc.get("a.tld")
+				return gen
+			},
+			wantLen: 1,
+		},
+		{
+			name: "02 - stale generation",
+			prepare: func(dc *tDecisionCache) uint64 {
+				_, _, gen, _ := dc.get("a.tld")
+				dc.clear()
+				return gen
+			},
+			wantLen: 0,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dc := newDecisionCache(4)
+			gen := tc.prepare(dc)
+			dc.put("a.tld", ADdeny, "a.tld", gen)
+			if got := dc.Len(); got != tc.wantLen {
+				t.Errorf("tDecisionCache.Len() = '%d', want '%d'",
+					got, tc.wantLen)
+			}
+		})
+	}
+} // Test_tDecisionCache_put()
+
+/* _EoF_ */
@@ -0,0 +1,209 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+const (
+	// `adAllowPrefix` marks the allow patterns of a diff (as the
+	// exceptions of the AdBlock Plus syntax).
+	adAllowPrefix = "@@"
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `diffPatterns()` returns the differences of two pattern lists.
+//
+// Parameters:
+//   - `aOld`: The current patterns.
+//   - `aNew`: The wanted patterns.
+//   - `aPrefix`: The prefix to add to all differences.
+//
+// Returns:
+//   - `rAdded`: The patterns only in `aNew`.
+//   - `rRemoved`: The patterns only in `aOld`.
+func diffPatterns(aOld, aNew []string, aPrefix string) (rAdded, rRemoved []string) {
+	old := make(map[string]struct{}, len(aOld))
+	for _, pattern := range aOld {
+		old[pattern] = struct{}{}
+	}
+	for _, pattern := range aNew {
+		if _, ok := old[pattern]; ok {
+			delete(old, pattern)
+		} else {
+			rAdded = append(rAdded, aPrefix+pattern)
+		}
+	}
+	for _, pattern := range aOld {
+		if _, ok := old[pattern]; ok {
+			rRemoved = append(rRemoved, aPrefix+pattern)
+		}
+	}
+
+	return
+} // diffPatterns()
+
+// `ReadDiff()` reads a diff written by [WriteDiff].
+//
+// Each line holds a pattern to add (prefixed by `+`) or to remove
+// (prefixed by `-`); allow patterns are additionally prefixed by `@@`.
+// Empty lines and comments (starting with `#`) are ignored.
+//
+// Parameters:
+//   - `aReader`: The reader to read the diff from.
+//
+// Returns:
+//   - `rAdded`: The patterns to add.
+//   - `rRemoved`: The patterns to remove.
+//   - `rErr`: `nil` if the diff is well-formed, the error otherwise.
+func ReadDiff(aReader io.Reader) (rAdded, rRemoved []string, rErr error) {
+	scanner := bufio.NewScanner(aReader)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if ("" == text) || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		if ('+' != text[0]) && ('-' != text[0]) {
+			return nil, nil, fmt.Errorf("line %d: '+' or '-' expected: %q", line, text)
+		}
+		pattern := strings.TrimSpace(text[1:])
+		if "" == strings.TrimPrefix(pattern, adAllowPrefix) {
+			return nil, nil, fmt.Errorf("line %d: missing pattern", line)
+		}
+		if '+' == text[0] {
+			rAdded = append(rAdded, pattern)
+		} else {
+			rRemoved = append(rRemoved, pattern)
+		}
+	}
+	rErr = scanner.Err()
+
+	return
+} // ReadDiff()
+
+// `WriteDiff()` writes a diff in the format read by [ReadDiff].
+//
+// Parameters:
+//   - `aWriter`: The writer to write the diff to.
+//   - `aAdded`: The patterns to add.
+//   - `aRemoved`: The patterns to remove.
+//
+// Returns:
+//   - `error`: `nil` if the diff was written, the error otherwise.
+func WriteDiff(aWriter io.Writer, aAdded, aRemoved []string) error {
+	writer := bufio.NewWriter(aWriter)
+	for _, pattern := range aRemoved {
+		fmt.Fprintf(writer, "-%s\n", pattern)
+	}
+	for _, pattern := range aAdded {
+		fmt.Fprintf(writer, "+%s\n", pattern)
+	}
+
+	return writer.Flush()
+} // WriteDiff()
+
+// ---------------------------------------------------------------------------
+// `TADlist` methods:
+
+// `Diff()` returns the changes turning the current list into the
+// given one.
+//
+// Allow patterns are prefixed by `@@` (as the exceptions of the
+// AdBlock Plus syntax) while deny patterns are returned as they are.
+//
+// Parameters:
+//   - `aCtx`: The context to use for the operation.
+//   - `aOther`: The wanted list.
+//
+// Returns:
+//   - `rAdded`: The patterns only in the other list.
+//   - `rRemoved`: The patterns only in the current list.
+//
+// see [Patch], [WriteDiff]
+func (adl *TADlist) Diff(aCtx context.Context, aOther *TADlist) (rAdded, rRemoved []string) {
+	added, removed := diffPatterns(adl.AllowPatterns(aCtx),
+		aOther.AllowPatterns(aCtx), adAllowPrefix)
+	rAdded, rRemoved = diffPatterns(adl.DenyPatterns(aCtx),
+		aOther.DenyPatterns(aCtx), "")
+
+	return slices.Concat(added, rAdded), slices.Concat(removed, rRemoved)
+} // Diff()
+
+// `Patch()` applies the changes returned by [Diff] (or read by
+// [ReadDiff]) to the list.
+//
+// The patterns are removed before the new ones are added; afterwards
+// the changed lists are stored once.
+//
+// Parameters:
+//   - `aCtx`: The context to use for the operation.
+//   - `aAdded`: The patterns to add.
+//   - `aRemoved`: The patterns to remove.
+//
+// Returns:
+//   - `error`: `nil` if all changes were applied, the joined errors otherwise.
+func (adl *TADlist) Patch(aCtx context.Context, aAdded, aRemoved []string) error {
+	if (nil == adl) || (nil == adl.allow) || (nil == adl.deny) {
+		return ErrListNil
+	}
+
+	var (
+		errs                  []error
+		allowDirty, denyDirty bool
+	)
+	apply := func(aPatterns []string, aAdd bool) {
+		for _, pattern := range aPatterns {
+			list, dirty := adl.deny, &denyDirty
+			if after, ok := strings.CutPrefix(pattern, adAllowPrefix); ok {
+				pattern, list, dirty = after, adl.allow, &allowDirty
+			}
+
+			if aAdd {
+				if !list.Add(aCtx, pattern) {
+					errs = append(errs, fmt.Errorf("can't add %q", pattern))
+					continue
+				}
+			} else if !list.Delete(aCtx, pattern) {
+				errs = append(errs, fmt.Errorf("can't remove %q", pattern))
+				continue
+			}
+			*dirty = true
+		}
+	}
+	apply(aRemoved, false)
+	apply(aAdded, true)
+
+	if allowDirty || denyDirty {
+		adl.decisions.clear()
+	}
+	if allowDirty {
+		if err := adl.StoreAllow(aCtx); (nil != err) && !errors.Is(err, ErrListNil) {
+			errs = append(errs, err)
+		}
+	}
+	if denyDirty {
+		if err := adl.StoreDeny(aCtx); (nil != err) && !errors.Is(err, ErrListNil) {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+} // Patch()
+
+/* _EoF_ */
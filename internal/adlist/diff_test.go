@@ -0,0 +1,183 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"bytes"
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_diffPatterns(t *testing.T) {
+	tests := []struct {
+		name        string
+		old         []string
+		new         []string
+		prefix      string
+		wantAdded   []string
+		wantRemoved []string
+	}{
+		/* */
+		{"01 - both empty", nil, nil, "", nil, nil},
+		{"02 - equal", []string{"a.tld", "b.tld"}, []string{"b.tld", "a.tld"}, "", nil, nil},
+		{"03 - added", nil, []string{"a.tld"}, "", []string{"a.tld"}, nil},
+		{"04 - removed", []string{"a.tld"}, nil, "", nil, []string{"a.tld"}},
+		{"05 - both", []string{"a.tld", "b.tld"}, []string{"b.tld", "c.tld"}, "@@",
+			[]string{"@@c.tld"}, []string{"@@a.tld"}},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gotAdded, gotRemoved := diffPatterns(tc.old, tc.new, tc.prefix)
+			if !slices.Equal(gotAdded, tc.wantAdded) {
+				t.Errorf("diffPatterns() added = '%v', want '%v'",
+					gotAdded, tc.wantAdded)
+			}
+			if !slices.Equal(gotRemoved, tc.wantRemoved) {
+				t.Errorf("diffPatterns() removed = '%v', want '%v'",
+					gotRemoved, tc.wantRemoved)
+			}
+		})
+	}
+} // Test_diffPatterns()
+
+func Test_ReadDiff(t *testing.T) {
+	tests := []struct {
+		name        string
+		diff        string
+		wantAdded   []string
+		wantRemoved []string
+		wantErr     bool
+	}{
+		/* */
+		{"01 - empty", "", nil, nil, false},
+		{"02 - comments", "# list diff\n\n  # more\n", nil, nil, false},
+		{"03 - changes", "-old.tld\n+new.tld\n+@@www.new.tld\n- *.old.tld \n",
+			[]string{"new.tld", "@@www.new.tld"}, []string{"old.tld", "*.old.tld"}, false},
+		{"04 - missing sign", "+new.tld\nold.tld\n", nil, nil, true},
+		{"05 - missing pattern", "+\n", nil, nil, true},
+		{"06 - missing allow pattern", "-@@\n", nil, nil, true},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gotAdded, gotRemoved, err := ReadDiff(strings.NewReader(tc.diff))
+			if (nil != err) != tc.wantErr {
+				t.Errorf("ReadDiff() error = '%v', wantErr '%v'", err, tc.wantErr)
+				return
+			}
+			if !slices.Equal(gotAdded, tc.wantAdded) {
+				t.Errorf("ReadDiff() added = '%v', want '%v'",
+					gotAdded, tc.wantAdded)
+			}
+			if !slices.Equal(gotRemoved, tc.wantRemoved) {
+				t.Errorf("ReadDiff() removed = '%v', want '%v'",
+					gotRemoved, tc.wantRemoved)
+			}
+		})
+	}
+} // Test_ReadDiff()
+
+func Test_WriteDiff(t *testing.T) {
+	added := []string{"new.tld", "@@www.new.tld"}
+	removed := []string{"old.tld"}
+
+	var buf bytes.Buffer
+	if err := WriteDiff(&buf, added, removed); nil != err {
+		t.Fatalf("WriteDiff() error = '%v'", err)
+	}
+	if want := "-old.tld\n+new.tld\n+@@www.new.tld\n"; buf.String() != want {
+		t.Errorf("WriteDiff() = '%s', want '%s'", buf.String(), want)
+	}
+
+	gotAdded, gotRemoved, err := ReadDiff(&buf)
+	if nil != err {
+		t.Fatalf("ReadDiff() error = '%v'", err)
+	}
+	if !slices.Equal(gotAdded, added) || !slices.Equal(gotRemoved, removed) {
+		t.Errorf("ReadDiff() = '%v', '%v', want '%v', '%v'",
+			gotAdded, gotRemoved, added, removed)
+	}
+} // Test_WriteDiff()
+
+func Test_TADlist_Diff(t *testing.T) {
+	ctx := context.TODO()
+	current := newTestList(t)
+	current.AddDeny(ctx, "old.tld")
+	current.AddDeny(ctx, "*.ads.tld")
+	current.AddAllow(ctx, "www.old.tld")
+	wanted := newTestList(t)
+	wanted.AddDeny(ctx, "*.ads.tld")
+	wanted.AddDeny(ctx, "new.tld")
+	wanted.AddAllow(ctx, "www.new.tld")
+
+	gotAdded, gotRemoved := current.Diff(ctx, wanted)
+	if want := []string{"@@www.new.tld", "new.tld"}; !slices.Equal(gotAdded, want) {
+		t.Errorf("TADlist.Diff() added = '%v', want '%v'", gotAdded, want)
+	}
+	if want := []string{"@@www.old.tld", "old.tld"}; !slices.Equal(gotRemoved, want) {
+		t.Errorf("TADlist.Diff() removed = '%v', want '%v'", gotRemoved, want)
+	}
+
+	// Applying the diff makes both lists equal
+	if err := current.Patch(ctx, gotAdded, gotRemoved); nil != err {
+		t.Fatalf("TADlist.Patch() error = '%v'", err)
+	}
+	if gotAdded, gotRemoved = current.Diff(ctx, wanted); (nil != gotAdded) || (nil != gotRemoved) {
+		t.Errorf("TADlist.Diff() = '%v', '%v', want 'nil', 'nil'",
+			gotAdded, gotRemoved)
+	}
+} // Test_TADlist_Diff()
+
+func Test_TADlist_Patch(t *testing.T) {
+	ctx := context.TODO()
+	adl := newTestList(t)
+	adl.AddDeny(ctx, "*.ads.tld")
+
+	tests := []struct {
+		name     string
+		added    []string
+		removed  []string
+		hostname string
+		want     TADresult
+		wantErr  bool
+	}{
+		/* */
+		{"01 - nothing", nil, nil, "www.ads.tld", ADdeny, false},
+		{"02 - allow", []string{"@@www.ads.tld"}, nil, "www.ads.tld", ADallow, false},
+		{"03 - deny", []string{"tracker.tld"}, nil, "tracker.tld", ADdeny, false},
+		{"04 - remove allow", nil, []string{"@@www.ads.tld"}, "www.ads.tld", ADdeny, false},
+		{"05 - remove deny", nil, []string{"tracker.tld"}, "tracker.tld", ADneutral, false},
+		{"06 - remove unknown", []string{"more.tld"}, []string{"unknown.tld"}, "more.tld", ADdeny, true},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := adl.Patch(ctx, tc.added, tc.removed)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("TADlist.Patch() error = '%v', wantErr '%v'", err, tc.wantErr)
+			}
+			if got := adl.Match(ctx, tc.hostname); got != tc.want {
+				t.Errorf("TADlist.Match() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+
+	var nilList *TADlist
+	if err := nilList.Patch(ctx, []string{"a.tld"}, nil); nil == err {
+		t.Error("TADlist.Patch() error = 'nil', want an error")
+	}
+} // Test_TADlist_Patch()
+
+/* _EoF_ */
@@ -0,0 +1,314 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+//lint:file-ignore ST1005 - I like capitalisation
+
+type (
+	// `TDownloadOptions` contains the settings used to download lists.
+	//
+	//   - `Proxy`: URL of the HTTP proxy to use (default: the one
+	//     given by the `HTTPS_PROXY`/`HTTP_PROXY` environment).
+	//   - `UserAgent`: The `User-Agent` header to send (default:
+	//     `DefaultUserAgent`).
+	//   - `ConnectTimeout`: Maximal time to establish a connection
+	//     (default: 10 seconds).
+	//   - `Timeout`: Maximal time of a single download attempt
+	//     including reading the body (default: 2 minutes).
+	//   - `RetryDelay`: The delay before the first retry, doubled
+	//     for each further retry (default: 1 second).
+	//   - `MaxSize`: Maximal size of a downloaded list in bytes
+	//     (default: 64 MiB).
+	//   - `Retries`: Number of retries after failed attempts
+	//     (default: 2, negative: none).
+	TDownloadOptions struct {
+		Proxy          string
+		UserAgent      string
+		ConnectTimeout time.Duration
+		Timeout        time.Duration
+		RetryDelay     time.Duration
+		MaxSize        int64
+		Retries        int
+	}
+
+	// `tDownloader` downloads lists using its own HTTP client.
+	tDownloader struct {
+		client  *http.Client
+		options TDownloadOptions
+	}
+)
+
+const (
+	// `DefaultUserAgent` is the `User-Agent` header sent by default.
+	DefaultUserAgent = "dnscache (+https://github.com/mwat56/dnscache)"
+
+	// Defaults of the download settings
+	defConnectTimeout   = time.Second * 10
+	defDownloadRetries  = 2
+	defDownloadSize     = int64(64 << 20)
+	defDownloadTimeout  = time.Minute << 1
+	defDownloadRetryGap = time.Second
+)
+
+var (
+	// `ErrDownloadSize` is returned if a download exceeds the
+	// configured maximal size.
+	ErrDownloadSize = ADlistError{errors.New("Download exceeds size limit")}
+
+	// `ErrOffline` is returned by downloads in offline mode.
+	ErrOffline = ADlistError{errors.New("Downloads disabled in offline mode")}
+
+	// `adOffline` disables all downloads (see `SetOffline()`).
+	adOffline atomic.Bool
+
+	// `adDownloader` is the active downloader (`nil` means the
+	// default settings).
+	adDownloader atomic.Pointer[tDownloader]
+)
+
+// ---------------------------------------------------------------------------
+// `tDownloader` constructor:
+
+// `newDownloader()` creates a downloader using the given settings.
+//
+// Zero values of the settings are replaced by their defaults.
+//
+// Parameters:
+//   - `aOptions`: The download settings to use.
+//
+// Returns:
+//   - `*tDownloader`: The new downloader.
+//   - `error`: `nil` if the settings are valid, the error otherwise.
+func newDownloader(aOptions TDownloadOptions) (*tDownloader, error) {
+	if 0 >= aOptions.ConnectTimeout {
+		aOptions.ConnectTimeout = defConnectTimeout
+	}
+	if 0 >= aOptions.Timeout {
+		aOptions.Timeout = defDownloadTimeout
+	}
+	if 0 >= aOptions.RetryDelay {
+		aOptions.RetryDelay = defDownloadRetryGap
+	}
+	if 0 >= aOptions.MaxSize {
+		aOptions.MaxSize = defDownloadSize
+	}
+	switch {
+	case 0 == aOptions.Retries:
+		aOptions.Retries = defDownloadRetries
+	case 0 > aOptions.Retries:
+		aOptions.Retries = 0
+	}
+	if aOptions.UserAgent = strings.TrimSpace(aOptions.UserAgent); 0 == len(aOptions.UserAgent) {
+		aOptions.UserAgent = DefaultUserAgent
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if aOptions.Proxy = strings.TrimSpace(aOptions.Proxy); 0 < len(aOptions.Proxy) {
+		proxy, err := url.Parse(aOptions.Proxy)
+		if (nil != err) || (0 == len(proxy.Host)) {
+			return nil, ADlistError{fmt.Errorf("Invalid proxy URL: %q", aOptions.Proxy)}
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	transport.DialContext = (&net.Dialer{
+		Timeout:   aOptions.ConnectTimeout,
+		KeepAlive: time.Second * 30,
+	}).DialContext
+	transport.TLSHandshakeTimeout = aOptions.ConnectTimeout
+
+	return &tDownloader{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   aOptions.Timeout,
+		},
+		options: aOptions,
+	}, nil
+} // newDownloader()
+
+// ---------------------------------------------------------------------------
+// `tDownloader` methods:
+
+// `download()` writes the body of the given URL to a file, retrying
+// failed attempts with an increasing delay.
+//
+// Only network errors, server errors, and rate limiting are retried.
+//
+// Parameters:
+//   - `aCtx`: The context to use for the operation.
+//   - `aURL`: The URL to download.
+//   - `aMeta`: Optional metadata of a local copy to revalidate (updated
+//     by a successful download).
+//   - `aFile`: The file to write the body to (truncated before each attempt).
+//
+// Returns:
+//   - `error`: `nil` if the body was written, `errNotModified` if the
+//     local copy is up to date, the last error otherwise.
+func (d *tDownloader) download(aCtx context.Context, aURL string, aMeta *tMirrorMeta, aFile *os.File) (rErr error) {
+	delay := d.options.RetryDelay
+
+	for attempt := 0; attempt <= d.options.Retries; attempt++ {
+		if 0 < attempt {
+			timer := time.NewTimer(delay)
+			select {
+			case <-aCtx.Done():
+				timer.Stop()
+				return aCtx.Err()
+			case <-timer.C:
+			}
+			delay <<= 1
+		}
+
+		if rErr = aFile.Truncate(0); nil != rErr {
+			return
+		}
+		if _, rErr = aFile.Seek(0, io.SeekStart); nil != rErr {
+			return
+		}
+
+		var retry bool
+		if retry, rErr = d.get(aCtx, aURL, aMeta, aFile); (nil == rErr) || !retry {
+			return
+		}
+	}
+
+	return
+} // download()
+
+// `get()` writes the body of the given URL.
+//
+// In offline mode `ErrOffline` is returned without any request.
+//
+// If `aMeta` holds the validators of a local copy, the request is
+// conditional and answered with `errNotModified` while the copy is
+// up to date.
+//
+// Parameters:
+//   - `aCtx`: The context to use for the operation.
+//   - `aURL`: The URL to download.
+//   - `aMeta`: Optional metadata of a local copy (updated on success).
+//   - `aWriter`: The writer to write the body to.
+//
+// Returns:
+//   - `bool`: `true` if a failed attempt may be retried, `false` otherwise.
+//   - `error`: `nil` if the body was written, the error otherwise.
+func (d *tDownloader) get(aCtx context.Context, aURL string, aMeta *tMirrorMeta, aWriter io.Writer) (bool, error) {
+	if adOffline.Load() {
+		return false, ErrOffline
+	}
+	request, err := http.NewRequestWithContext(aCtx, http.MethodGet, aURL, nil)
+	if nil != err {
+		return false, ADlistError{fmt.Errorf("Failed to download file: %v", err)}
+	}
+	request.Header.Set("User-Agent", d.options.UserAgent)
+	if nil != aMeta {
+		if "" != aMeta.ETag {
+			request.Header.Set("If-None-Match", aMeta.ETag)
+		}
+		if "" != aMeta.LastModified {
+			request.Header.Set("If-Modified-Since", aMeta.LastModified)
+		}
+	}
+
+	response, err := d.client.Do(request)
+	if nil != err {
+		return nil == aCtx.Err(), ADlistError{fmt.Errorf("Failed to download file: %v", err)}
+	}
+	defer response.Body.Close()
+
+	if (http.StatusNotModified == response.StatusCode) && (nil != aMeta) {
+		return false, errNotModified
+	}
+	if http.StatusOK != response.StatusCode {
+		retry := (http.StatusTooManyRequests == response.StatusCode) ||
+			(http.StatusInternalServerError <= response.StatusCode)
+		return retry, ADlistError{fmt.Errorf("Failed to download file: %s", response.Status)}
+	}
+	if d.options.MaxSize < response.ContentLength {
+		return false, fmt.Errorf("%w: %q (%d bytes)", ErrDownloadSize, aURL, response.ContentLength)
+	}
+
+	// Read one byte more than allowed to detect oversized bodies
+	written, err := io.Copy(aWriter, io.LimitReader(response.Body, d.options.MaxSize+1))
+	if nil != err {
+		return nil == aCtx.Err(), ADlistError{fmt.Errorf("Failed to save file: %v", err)}
+	}
+	if d.options.MaxSize < written {
+		return false, fmt.Errorf("%w: %q", ErrDownloadSize, aURL)
+	}
+	if nil != aMeta {
+		aMeta.ETag = response.Header.Get("ETag")
+		aMeta.LastModified = response.Header.Get("Last-Modified")
+	}
+
+	return false, nil
+} // get()
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `downloader()` returns the active downloader.
+//
+// Returns:
+//   - `*tDownloader`: The downloader set by `SetDownloadOptions()` or
+//     one using the default settings.
+func downloader() *tDownloader {
+	if result := adDownloader.Load(); nil != result {
+		return result
+	}
+	result, _ := newDownloader(TDownloadOptions{})
+	if !adDownloader.CompareAndSwap(nil, result) {
+		result = adDownloader.Load()
+	}
+
+	return result
+} // downloader()
+
+// `SetDownloadOptions()` replaces the settings used by all lists to
+// download their sources.
+//
+// Parameters:
+//   - `aOptions`: The download settings to use.
+//
+// Returns:
+//   - `error`: `nil` if the settings are valid, the error otherwise.
+func SetDownloadOptions(aOptions TDownloadOptions) error {
+	result, err := newDownloader(aOptions)
+	if nil != err {
+		return err
+	}
+	adDownloader.Store(result)
+
+	return nil
+} // SetDownloadOptions()
+
+// `SetOffline()` switches the offline mode on or off.
+//
+// In offline mode no list is downloaded; the lists' local copies (see
+// `downloadFile()`) and the embedded top-level domains are used instead.
+//
+// Parameters:
+//   - `aOffline`: Whether to disable all downloads.
+func SetOffline(aOffline bool) {
+	adOffline.Store(aOffline)
+} // SetOffline()
+
+/* _EoF_ */
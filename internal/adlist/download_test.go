@@ -0,0 +1,249 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_newDownloader(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     TDownloadOptions
+		wantRetries int
+		wantErr     bool
+	}{
+		/* */
+		{
+			name:        "01 - defaults",
+			options:     TDownloadOptions{},
+			wantRetries: defDownloadRetries,
+		},
+		{
+			name:        "02 - no retries",
+			options:     TDownloadOptions{Retries: -1},
+			wantRetries: 0,
+		},
+		{
+			name:        "03 - valid proxy",
+			options:     TDownloadOptions{Proxy: "http://proxy.localdomain:3128", Retries: 5},
+			wantRetries: 5,
+		},
+		{
+			name:    "04 - invalid proxy",
+			options: TDownloadOptions{Proxy: "proxy"},
+			wantErr: true,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := newDownloader(tc.options)
+			if (nil != err) != tc.wantErr {
+				t.Fatalf("newDownloader() error = '%v', wantErr '%v'", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if got.options.Retries != tc.wantRetries {
+				t.Errorf("newDownloader() Retries = '%d', want '%d'",
+					got.options.Retries, tc.wantRetries)
+			}
+			if DefaultUserAgent != got.options.UserAgent {
+				t.Errorf("newDownloader() UserAgent = '%s', want '%s'",
+					got.options.UserAgent, DefaultUserAgent)
+			}
+			if defDownloadSize != got.options.MaxSize {
+				t.Errorf("newDownloader() MaxSize = '%d', want '%d'",
+					got.options.MaxSize, defDownloadSize)
+			}
+		})
+	}
+} // Test_newDownloader()
+
+func Test_tDownloader_download(t *testing.T) {
+	var (
+		requests  atomic.Int32
+		userAgent atomic.Value
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(aWriter http.ResponseWriter, aRequest *http.Request) {
+		count := requests.Add(1)
+		userAgent.Store(aRequest.UserAgent())
+
+		switch aRequest.URL.Path {
+		case "/flaky":
+			if 3 > count {
+				http.Error(aWriter, "busy", http.StatusServiceUnavailable)
+				return
+			}
+		case "/missing":
+			http.NotFound(aWriter, aRequest)
+			return
+		case "/large":
+			_, _ = aWriter.Write([]byte(strings.Repeat("x", 64)))
+			return
+		}
+		_, _ = aWriter.Write([]byte("tracker.localdomain\n"))
+	}))
+	defer server.Close()
+
+	d, err := newDownloader(TDownloadOptions{
+		UserAgent:  "test-agent",
+		RetryDelay: time.Millisecond,
+		MaxSize:    32,
+		Retries:    2,
+	})
+	if nil != err {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		path         string
+		want         string
+		wantRequests int32
+		wantErr      error
+	}{
+		/* */
+		{
+			name:         "01 - success",
+			path:         "/list",
+			want:         "tracker.localdomain\n",
+			wantRequests: 1,
+		},
+		{
+			name:         "02 - retried server error",
+			path:         "/flaky",
+			want:         "tracker.localdomain\n",
+			wantRequests: 3,
+		},
+		{
+			name:         "03 - client error isn't retried",
+			path:         "/missing",
+			wantRequests: 1,
+			wantErr:      errors.New("404"),
+		},
+		{
+			name:         "04 - body too large",
+			path:         "/large",
+			wantRequests: 1,
+			wantErr:      ErrDownloadSize,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			requests.Store(0)
+			file, err := os.Create(filepath.Join(t.TempDir(), "download"))
+			if nil != err {
+				t.Fatal(err)
+			}
+			defer file.Close()
+
+			err = d.download(context.TODO(), server.URL+tc.path, nil, file)
+			switch {
+			case nil == tc.wantErr:
+				if nil != err {
+					t.Fatalf("download() error = '%v', want 'nil'", err)
+				}
+			case errors.Is(tc.wantErr, ErrDownloadSize):
+				if !errors.Is(err, ErrDownloadSize) {
+					t.Fatalf("download() error = '%v', want '%v'", err, tc.wantErr)
+				}
+			case (nil == err) || !strings.Contains(err.Error(), tc.wantErr.Error()):
+				t.Fatalf("download() error = '%v', want '%v'", err, tc.wantErr)
+			}
+			if got := requests.Load(); got != tc.wantRequests {
+				t.Errorf("download() requests = '%d', want '%d'", got, tc.wantRequests)
+			}
+			if got := userAgent.Load(); "test-agent" != got {
+				t.Errorf("download() User-Agent = '%v', want 'test-agent'", got)
+			}
+			if nil != tc.wantErr {
+				return
+			}
+			data, _ := os.ReadFile(file.Name())
+			if got := string(data); got != tc.want {
+				t.Errorf("download() = '%s', want '%s'", got, tc.want)
+			}
+		})
+	}
+
+	// Cancelling the context stops the retries
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	file, err := os.Create(filepath.Join(t.TempDir(), "cancelled"))
+	if nil != err {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err = d.download(ctx, server.URL+"/list", nil, file); nil == err {
+		t.Error("download(cancelled) error = 'nil', want error")
+	}
+} // Test_tDownloader_download()
+
+func Test_SetDownloadOptions(t *testing.T) {
+	defer adDownloader.Store(nil)
+
+	if err := SetDownloadOptions(TDownloadOptions{Proxy: "::"}); nil == err {
+		t.Error("SetDownloadOptions(invalid) error = 'nil', want error")
+	}
+	if err := SetDownloadOptions(TDownloadOptions{UserAgent: "agent/1"}); nil != err {
+		t.Fatalf("SetDownloadOptions() error = '%v'", err)
+	}
+	if got := downloader().options.UserAgent; "agent/1" != got {
+		t.Errorf("downloader() UserAgent = '%s', want 'agent/1'", got)
+	}
+
+	adDownloader.Store(nil)
+	if got := downloader().options.UserAgent; DefaultUserAgent != got {
+		t.Errorf("downloader() UserAgent = '%s', want '%s'", got, DefaultUserAgent)
+	}
+} // Test_SetDownloadOptions()
+
+func Test_SetOffline(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(aWriter http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		_, _ = aWriter.Write([]byte("tracker.localdomain\n"))
+	}))
+	defer server.Close()
+
+	SetOffline(true)
+	defer SetOffline(false)
+
+	var body strings.Builder
+	if _, err := downloader().get(context.TODO(), server.URL, nil, &body); !errors.Is(err, ErrOffline) {
+		t.Errorf("get(offline) error = '%v', want '%v'", err, ErrOffline)
+	}
+	if got := requests.Load(); 0 != got {
+		t.Errorf("get(offline) requests = '%d', want '0'", got)
+	}
+
+	SetOffline(false)
+	if _, err := downloader().get(context.TODO(), server.URL, nil, &body); nil != err {
+		t.Errorf("get(online) error = '%v', want 'nil'", err)
+	}
+	if got := requests.Load(); 1 != got {
+		t.Errorf("get(online) requests = '%d', want '1'", got)
+	}
+} // Test_SetOffline()
+
+/* _EoF_ */
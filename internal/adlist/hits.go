@@ -0,0 +1,198 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mwat56/dnscache/clock"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `THitCount` is a deny pattern with the number of its matches
+	// as returned by [TADlist.HitCounts].
+	THitCount struct {
+		Pattern string    `json:"pattern"`
+		Hits    uint64    `json:"hits"`
+		LastHit time.Time `json:"lastHit"`
+	}
+
+	// `tHit` counts the matches of a single pattern.
+	tHit struct {
+		last  time.Time // time of the latest match
+		count uint64    // number of matches
+	}
+
+	// `tHitCounter` counts the matches of the deny list's patterns.
+	tHitCounter struct {
+		sync.Mutex
+		clock  clock.IClock     // deciding about the times of the hits
+		since  time.Time        // start of the counting
+		counts map[string]*tHit // pattern → hits
+	}
+)
+
+// ---------------------------------------------------------------------------
+// `tHitCounter` methods:
+
+// `add()` counts a match of the given pattern.
+//
+// Parameters:
+//   - `aPattern`: The matching pattern.
+func (hc *tHitCounter) add(aPattern string) {
+	hc.Lock()
+	defer hc.Unlock()
+
+	if nil == hc.counts {
+		hc.counts = make(map[string]*tHit)
+	}
+	hit, ok := hc.counts[aPattern]
+	if !ok {
+		hit = &tHit{}
+		hc.counts[aPattern] = hit
+	}
+	hit.count++
+	hit.last = hc.now()
+} // add()
+
+// `list()` returns the counted patterns.
+//
+// Returns:
+//   - `[]THitCount`: The patterns sorted by decreasing number of hits.
+func (hc *tHitCounter) list() []THitCount {
+	hc.Lock()
+	result := make([]THitCount, 0, len(hc.counts))
+	for pattern, hit := range hc.counts {
+		result = append(result, THitCount{
+			Pattern: pattern,
+			Hits:    hit.count,
+			LastHit: hit.last,
+		})
+	}
+	hc.Unlock()
+
+	slices.SortFunc(result, func(a, b THitCount) int {
+		if a.Hits != b.Hits {
+			if a.Hits > b.Hits {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Pattern, b.Pattern)
+	})
+
+	return result
+} // list()
+
+// `now()` returns the current time of the counter's clock.
+//
+// The caller must hold the lock.
+//
+// Returns:
+//   - `time.Time`: The current time.
+func (hc *tHitCounter) now() time.Time {
+	return clock.OrSystem(hc.clock).Now()
+} // now()
+
+// `unused()` returns the patterns not matched during the given time.
+//
+// Patterns are only considered unused if the counting started before
+// that time; the counts of patterns not in `aPatterns` are dropped.
+//
+// Parameters:
+//   - `aPatterns`: The patterns of the deny list.
+//   - `aOlderThan`: The time since the patterns' last match.
+//
+// Returns:
+//   - `[]string`: The unused patterns.
+func (hc *tHitCounter) unused(aPatterns []string, aOlderThan time.Duration) (rList []string) {
+	hc.Lock()
+	defer hc.Unlock()
+
+	cutoff := hc.now().Add(-aOlderThan)
+	if !hc.since.Before(cutoff) {
+		return // not counted long enough
+	}
+	counts := make(map[string]*tHit, len(hc.counts))
+	for _, pattern := range aPatterns {
+		if hit, ok := hc.counts[pattern]; ok && !hit.last.Before(cutoff) {
+			counts[pattern] = hit
+			continue
+		}
+		rList = append(rList, pattern)
+	}
+	hc.counts = counts
+
+	return
+} // unused()
+
+// ---------------------------------------------------------------------------
+// `TADlist` methods:
+
+// `HitCounts()` returns how often the deny list's patterns matched
+// a hostname checked by [Match].
+//
+// Only patterns which matched at least once are returned.
+//
+// Returns:
+//   - `[]THitCount`: The patterns sorted by decreasing number of hits.
+func (adl *TADlist) HitCounts() []THitCount {
+	if nil == adl {
+		return nil
+	}
+
+	return adl.hits.list()
+} // HitCounts()
+
+// `PruneUnused()` removes the deny patterns which didn't match any
+// hostname during the given time.
+//
+// Patterns are only removed if their matches were counted for (at
+// least) the given duration, i.e. the list has to exist that long.
+// This keeps the deny list lean, e.g. after loading huge blocklists
+// whose majority of entries is never queried.
+//
+// Parameters:
+//   - `aCtx`: The context to use for the operation.
+//   - `aOlderThan`: The time since the patterns' last match.
+//
+// Returns:
+//   - `int`: The number of removed patterns.
+func (adl *TADlist) PruneUnused(aCtx context.Context, aOlderThan time.Duration) int {
+	if (nil == adl) || (nil == adl.deny) || (nil == adl.deny.root.node) || (0 >= aOlderThan) {
+		return 0
+	}
+
+	unused := adl.hits.unused(adl.deny.AllPatterns(aCtx), aOlderThan)
+	if 0 == len(unused) {
+		return 0
+	}
+
+	var count int
+	for _, pattern := range unused {
+		if nil != aCtx.Err() {
+			break
+		}
+		if adl.deny.Delete(aCtx, pattern) {
+			count++
+		}
+	}
+	if 0 < count {
+		adl.decisions.clear()
+		_ = adl.deny.storeFile(aCtx, adl.deny.filename)
+	}
+
+	return count
+} // PruneUnused()
+
+/* _EoF_ */
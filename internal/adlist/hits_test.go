@@ -0,0 +1,100 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mwat56/dnscache/clock"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_TADlist_HitCounts(t *testing.T) {
+	ctx := context.TODO()
+	adl := newTestList(t)
+	adl.AddDeny(ctx, "*.ads.localdomain")
+	adl.AddDeny(ctx, "tracker.localdomain")
+	adl.AddDeny(ctx, "unused.localdomain")
+	adl.AddAllow(ctx, "good.ads.localdomain")
+
+	for _, host := range []string{
+		"banner.ads.localdomain", "popup.ads.localdomain",
+		"banner.ads.localdomain", // cached decision
+		"tracker.localdomain",
+		"good.ads.localdomain", // allowed
+		"www.localdomain",      // neutral
+	} {
+		_ = adl.Match(ctx, host)
+	}
+
+	// Default-deny decisions have no pattern to count
+	adl.SetDefaultDeny(true)
+	if got := adl.Match(ctx, "www.localdomain"); ADdeny != got {
+		t.Fatalf("Match() = '%v', want '%v'", got, ADdeny)
+	}
+
+	got := adl.HitCounts()
+	want := []THitCount{
+		{Pattern: "*.ads.localdomain", Hits: 3},
+		{Pattern: "tracker.localdomain", Hits: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("HitCounts() = '%v', want '%v'", got, want)
+	}
+	for idx, hc := range got {
+		if (hc.Pattern != want[idx].Pattern) || (hc.Hits != want[idx].Hits) || hc.LastHit.IsZero() {
+			t.Errorf("HitCounts()[%d] = '%v', want '%v'", idx, hc, want[idx])
+		}
+	}
+
+	var nilList *TADlist
+	if got := nilList.HitCounts(); nil != got {
+		t.Errorf("HitCounts() = '%v', want 'nil'", got)
+	}
+} // Test_TADlist_HitCounts()
+
+func Test_TADlist_PruneUnused(t *testing.T) {
+	ctx := context.TODO()
+	clk := clock.NewManual(time.Now())
+	adl := newTestList(t)
+	adl.SetClock(clk)
+	adl.AddDeny(ctx, "*.ads.localdomain")
+	adl.AddDeny(ctx, "tracker.localdomain")
+	adl.AddDeny(ctx, "unused.localdomain")
+
+	// Not counted long enough
+	_ = adl.Match(ctx, "banner.ads.localdomain")
+	if got := adl.PruneUnused(ctx, time.Hour); 0 != got {
+		t.Errorf("PruneUnused() = '%d', want '0'", got)
+	}
+
+	clk.Advance(2 * time.Hour)
+	_ = adl.Match(ctx, "tracker.localdomain")
+	if got := adl.PruneUnused(ctx, time.Hour); 2 != got {
+		t.Errorf("PruneUnused() = '%d', want '2'", got)
+	}
+	want := []string{"tracker.localdomain"}
+	if got := adl.DenyPatterns(ctx); !slices.Equal(got, want) {
+		t.Errorf("DenyPatterns() = '%v', want '%v'", got, want)
+	}
+	if ADneutral != adl.Match(ctx, "banner.ads.localdomain") {
+		t.Error("Match() = 'ADdeny', want 'ADneutral'")
+	}
+	if got := adl.HitCounts(); (1 != len(got)) || ("tracker.localdomain" != got[0].Pattern) {
+		t.Errorf("HitCounts() = '%v', want only 'tracker.localdomain'", got)
+	}
+
+	if got := adl.PruneUnused(ctx, 0); 0 != got {
+		t.Errorf("PruneUnused() = '%d', want '0'", got)
+	}
+} // Test_TADlist_PruneUnused()
+
+/* _EoF_ */
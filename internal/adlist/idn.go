@@ -0,0 +1,87 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/idna"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `asciiForm()` returns the punycode form of a hostname pattern
+// without validating it, e.g. to recognise a list's format.
+//
+// Parameters:
+//   - `aPattern`: The hostname pattern to convert.
+//
+// Returns:
+//   - `string`: The ASCII form of the pattern.
+func asciiForm(aPattern string) string {
+	if isASCII(aPattern) {
+		return aPattern
+	}
+	result, _ := idna.Punycode.ToASCII(aPattern)
+
+	return result
+} // asciiForm()
+
+// `isASCII()` checks whether the given string contains ASCII
+// characters only.
+//
+// Parameters:
+//   - `aText`: The string to check.
+//
+// Returns:
+//   - `bool`: `true` if there are no multi-byte characters, `false` otherwise.
+func isASCII(aText string) bool {
+	for idx := 0; idx < len(aText); idx++ {
+		if utf8.RuneSelf <= aText[idx] {
+			return false
+		}
+	}
+
+	return true
+} // isASCII()
+
+// `toASCII()` converts an internationalised hostname pattern (with
+// optional `*.` prefix) to its punycode form.
+//
+// ASCII patterns are returned unchanged and aren't counted.
+//
+// Parameters:
+//   - `aPattern`: The hostname pattern to convert.
+//   - `aStats`: Optional counters of converted and rejected names.
+//
+// Returns:
+//   - `string`: The ASCII form of the pattern.
+//   - `bool`: `true` if the pattern could be converted, `false` otherwise.
+func toASCII(aPattern string, aStats *tLoadStats) (string, bool) {
+	if isASCII(aPattern) {
+		return aPattern, true
+	}
+
+	prefix := ""
+	if strings.HasPrefix(aPattern, "*.") {
+		prefix, aPattern = "*.", aPattern[2:]
+	}
+	ascii, err := idna.Lookup.ToASCII(aPattern)
+	if nil != err {
+		aStats.reject(reasonIDN)
+		return "", false
+	}
+	aStats.convert()
+
+	return prefix + ascii, true
+} // toASCII()
+
+/* _EoF_ */
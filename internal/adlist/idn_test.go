@@ -0,0 +1,106 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_asciiForm(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		want    string
+	}{
+		/* */
+		{"01 - ASCII hostname", "www.example.com", "www.example.com"},
+		{"02 - Unicode hostname", "bücher.localdomain", "xn--bcher-kva.localdomain"},
+		{"03 - invalid label", "-ü.localdomain", "xn----eha.localdomain"},
+		{"04 - Unicode wildcard", "*.ü.localdomain", "*.xn--tda.localdomain"},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := asciiForm(tc.pattern); got != tc.want {
+				t.Errorf("asciiForm() = '%s', want '%s'", got, tc.want)
+			}
+		})
+	}
+} // Test_asciiForm()
+
+func Test_toASCII(t *testing.T) {
+	tests := []struct {
+		name          string
+		pattern       string
+		want          string
+		wantOK        bool
+		wantConverted uint32
+		wantRejected  uint32
+	}{
+		/* */
+		{
+			name:    "01 - ASCII hostname",
+			pattern: "www.example.com",
+			want:    "www.example.com",
+			wantOK:  true,
+		},
+		{
+			name:          "02 - Unicode hostname",
+			pattern:       "bücher.localdomain",
+			want:          "xn--bcher-kva.localdomain",
+			wantOK:        true,
+			wantConverted: 1,
+		},
+		{
+			name:          "03 - Unicode wildcard",
+			pattern:       "*.BÜCHER.localdomain",
+			want:          "*.xn--bcher-kva.localdomain",
+			wantOK:        true,
+			wantConverted: 1,
+		},
+		{
+			name:          "04 - ideographic full stops",
+			pattern:       "例え。テスト",
+			want:          "xn--r8jz45g.xn--zckzah",
+			wantOK:        true,
+			wantConverted: 1,
+		},
+		{
+			name:         "05 - invalid label",
+			pattern:      "-ü.localdomain",
+			wantOK:       false,
+			wantRejected: 1,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var stats tLoadStats
+			got, ok := toASCII(tc.pattern, &stats)
+			if (got != tc.want) || (ok != tc.wantOK) {
+				t.Errorf("toASCII() = '%s', '%v', want '%s', '%v'",
+					got, ok, tc.want, tc.wantOK)
+			}
+			if (stats.converted != tc.wantConverted) || (stats.reasons[reasonIDN] != tc.wantRejected) {
+				t.Errorf("toASCII() stats = '%d', '%d', want '%d', '%d'",
+					stats.converted, stats.reasons[reasonIDN], tc.wantConverted, tc.wantRejected)
+			}
+
+			// The counters are optional
+			if got, ok = toASCII(tc.pattern, nil); (got != tc.want) || (ok != tc.wantOK) {
+				t.Errorf("toASCII(nil) = '%s', '%v', want '%s', '%v'",
+					got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+} // Test_toASCII()
+
+/* _EoF_ */
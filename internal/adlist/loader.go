@@ -13,14 +13,14 @@ import (
 	"fmt"
 	"io"
 	"net"
-	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
 	"slices"
 	"strings"
-	"sync"
 	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 //lint:file-ignore ST1017 - I prefer Yoda conditions
@@ -40,6 +40,19 @@ type (
 		// Returns:
 		//   - `error`: `nil` if the patterns were read successfully, the error otherwise.
 		Load(aCtx context.Context, aFilename string, aNode *tNode) error
+
+		// `LoadFrom()` reads hostname patterns from the reader and
+		// adds them to the trie node, e.g. directly from an HTTP
+		// body or an archive member.
+		//
+		// Parameters:
+		//   - `aCtx`: The timeout context to use for the operation.
+		//   - `aReader`: The reader to read the patterns from.
+		//   - `aNode`: The trie node to add the patterns to.
+		//
+		// Returns:
+		//   - `error`: `nil` if the patterns were read successfully, the error otherwise.
+		LoadFrom(aCtx context.Context, aReader io.Reader, aNode *tNode) error
 	}
 
 	// `ISaver` is the interface for a saver of allow/deny lists.
@@ -64,20 +77,45 @@ type (
 		ISaver
 	}
 
+	// `TValidation` is the strictness of the hostname checks used
+	// when loading lists (see [TADlist.SetValidation]).
+	TValidation uint8
+
+	// `tLoadOptions` contains the settings shared by the loaders.
+	tLoadOptions struct {
+		stats      *tLoadStats // optional counters of the loaded source
+		validation TValidation // strictness of the hostname checks
+	}
+
 	// `tABPLoader` is a loader of ABP filter lists.
-	tABPLoader struct{}
+	tABPLoader struct {
+		tLoadOptions
+	}
+
+	// `tABPSaver` is a saver for ABP filter lists.
+	tABPSaver struct{}
+
+	// `tDnsmasqLoader` is a loader of `dnsmasq` configuration files
+	// (i.e. `address=/domain/IP` lines).
+	tDnsmasqLoader struct {
+		tLoadOptions
+	}
 
 	// `tHostsLoader` is a loader of text files in `hosts(5)` format.
-	tHostsLoader struct{}
+	tHostsLoader struct {
+		tLoadOptions
+	}
 
-	/*
-		// `tHostsSaver` is a saver for text files in `hosts(5)` format.
-		tHostsSaver struct{}
-	*/
+	// `tHostsSaver` is a saver for text files in `hosts(5)` format.
+	tHostsSaver struct {
+		ip net.IP // address to map the patterns to (default `127.0.0.1`)
+	}
 
 	// `tSimpleLoader` is a loader of simple text files with one
 	// hostname per line.
-	tSimpleLoader struct{}
+	tSimpleLoader struct {
+		tLoadOptions
+	}
 
 	// `tSimpleSaver` is a saver for simple text files with one
 	// hostname per line.
@@ -99,83 +137,26 @@ type (
 	*/
 )
 
+const (
+	// `ValidateStrict` accepts RFC 1123 hostnames with a known
+	// top-level domain only (default).
+	ValidateStrict = TValidation(0)
+
+	// `ValidateRelaxed` accepts hostnames with any top-level domain
+	// (e.g. `foo.corp`), single-label names (e.g. `router`), and
+	// underscores.
+	ValidateRelaxed = TValidation(1)
+
+	// `ValidateOff` accepts every pattern without whitespace.
+	ValidateOff = TValidation(2)
+)
+
 var (
 	// `ErrLoaderNil` is returned if a loader or a method's required
 	// arguments is `nil`.
 	ErrLoaderNil = ADlistError{errors.New("Loader, Reader, or Node is nil")}
-
-	toplevelDomains []string
-	toplevelOnce    sync.Once
 )
 
-func init() {
-	toplevelOnce.Do(func() {
-		// TODO: Check whether there's a local file
-		localCopy := filepath.Join(os.TempDir(), "tlds-alpha-by-domain.txt")
-		if fi, err := os.Stat(localCopy); nil == err {
-			if fi.ModTime().After(time.Now().Add(-7 * 24 * time.Hour)) {
-				// Use the local copy to avoid network traffic
-				toplevelDomains = make([]string, 0, 1024)
-
-				inFile, err := os.Open(localCopy) //#nosec G304
-				if nil != err {
-					goto loadFromNet
-				}
-				defer inFile.Close()
-
-				scanner := bufio.NewScanner(inFile)
-				for scanner.Scan() {
-					line := strings.TrimSpace(scanner.Text())
-					if (0 == len(line)) || ("#" == string(line[0])) {
-						// Ignore empty or comment lines
-						continue
-					}
-					toplevelDomains = append(toplevelDomains, line)
-				}
-				toplevelDomains = slices.Clip(toplevelDomains)
-				return
-			}
-		}
-
-	loadFromNet:
-		// Get the list of top-level domains from IANA
-		toplevelDomains = make([]string, 0, 1024)
-		// We need this entries for the hosts file format and unit-tests
-		toplevelDomains = append(append(toplevelDomains, "localdomain"), "localhost")
-
-		resp, err := http.Get("https://data.iana.org/TLD/tlds-alpha-by-domain.txt")
-		if nil != err {
-			return
-		}
-		defer resp.Body.Close()
-
-		scanner := bufio.NewScanner(resp.Body)
-		for scanner.Scan() {
-			line := strings.TrimSpace(scanner.Text())
-			if (0 == len(line)) || ("#" == string(line[0])) {
-				// Ignore empty or comment lines
-				continue
-			}
-
-			// The IANA file contains uppercase entries but
-			// we're only using lowercase entries in this package
-			toplevelDomains = append(toplevelDomains, strings.ToLower(line))
-		}
-		toplevelDomains = slices.Clip(toplevelDomains)
-
-		// Write `topLevelDomains` to `localCopy` for later use.
-		file, err := os.OpenFile(localCopy, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600) //#nosec G304
-		if nil != err {
-			return
-		}
-		defer file.Close()
-
-		for _, tld := range toplevelDomains {
-			_, _ = file.WriteString(tld + "\n")
-		}
-	})
-} // init()
-
 // ---------------------------------------------------------------------------
 // `tABPLoader` method:
 
@@ -220,10 +201,12 @@ func processABPLine(aLine string) (rPattern string, rOK bool) {
 		return
 	}
 
-	// Reject lines with wildcards
-	if ("*." != aLine[:2]) && strings.Contains(aLine, "*") {
-		// Reject `*analytics*.js` or `*.host.*.domain.tld`
-		return
+	// Reject lines with wildcards inside labels
+	for _, label := range strings.Split(aLine, ".") {
+		if ("*" != label) && strings.Contains(label, "*") {
+			// Reject `*analytics*.js` or `ads*.domain.tld`
+			return
+		}
 	}
 
 	// Extract hostname and port
@@ -264,8 +247,26 @@ func (al *tABPLoader) Load(aCtx context.Context, aFilename string, aNode *tNode)
 	}
 	defer inFile.Close()
 
+	return al.LoadFrom(aCtx, inFile, aNode)
+} // Load()
+
+// `LoadFrom()` reads hostname patterns from the reader and adds them
+// to the node's tree.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//   - `aReader`: The reader to read the hostnames from.
+//   - `aNode`: The node to add the patterns to.
+//
+// Returns:
+//   - `error`: `nil` if the patterns were read successfully, the error otherwise.
+func (al *tABPLoader) LoadFrom(aCtx context.Context, aReader io.Reader, aNode *tNode) error {
+	if (nil == al) || (nil == aReader) || (nil == aNode) {
+		return ErrLoaderNil
+	}
+
 	added := 0
-	scanner := bufio.NewScanner(inFile)
+	scanner := bufio.NewScanner(aReader)
 	for scanner.Scan() {
 		// Check for timeout or cancellation
 		if err := aCtx.Err(); nil != err {
@@ -276,23 +277,30 @@ func (al *tABPLoader) Load(aCtx context.Context, aFilename string, aNode *tNode)
 			// Ignore empty lines
 			continue
 		}
-		if "!" == string(line[0]) {
-			// Ignore comment lines
+		if ("!" == string(line[0])) || strings.HasPrefix(line, "[A") {
+			// Ignore comment and header lines
 			continue
 		}
+		al.stats.line()
 
 		if 2 > len(line) {
 			// Ignore ABP specific lines
+			al.stats.reject(reasonABPRule)
 			continue
 		}
 
 		// Ignore ABP specific lines
 		switch string(line[0:2]) {
-		case "@@", "##", "[A":
+		case "@@", "##":
+			al.stats.reject(reasonABPRule)
 			continue
 
 		default:
 			if strings.Contains(line, "$") {
+				// AdGuard rules with modifiers
+				if al.loadAdGuardRule(aCtx, line, aNode) {
+					added++
+				}
 				continue
 			}
 		}
@@ -323,6 +331,7 @@ func (al *tABPLoader) Load(aCtx context.Context, aFilename string, aNode *tNode)
 				".txt",
 				".wicket",
 				".xml":
+				al.stats.reject(reasonABPRule)
 				continue
 
 			default:
@@ -330,29 +339,193 @@ func (al *tABPLoader) Load(aCtx context.Context, aFilename string, aNode *tNode)
 			}
 		}
 
-		if pattern, ok := processABPLine(line); ok {
-			// Split the pattern into multiple patterns if it
-			// contains a `,` or '|` and process them separately
-			entries := strings.Split(pattern, ",")
-			for _, entry := range entries {
-				if !(isValidHostname(entry) || isValidWildcard(entry)) {
-					continue
-				}
-				if parts := pattern2parts(entry); 0 < len(parts) {
-					if aNode.add(aCtx, parts) {
-						added++
-					}
+		pattern, ok := processABPLine(line)
+		if !ok {
+			al.stats.reject(reasonABPRule)
+			continue
+		}
+		// Split the pattern into multiple patterns if it
+		// contains a `,` or '|` and process them separately
+		entries := strings.Split(pattern, ",")
+		for _, entry := range entries {
+			entry, ok := toASCII(entry, al.stats)
+			if !ok {
+				continue
+			}
+			if !al.validation.isValid(entry) {
+				al.stats.reject(reasonHostname)
+				continue
+			}
+			if parts := pattern2parts(entry); 0 < len(parts) {
+				if aNode.add(aCtx, parts) {
+					added++
+					al.stats.accept()
 				}
 			}
 		}
 	}
 	if 0 == added {
-		return ADlistError{fmt.Errorf("no valid patterns found in %q", aFilename)}
+		return ADlistError{fmt.Errorf("no valid patterns found in %q", readerName(aReader))}
 	}
 
 	return scanner.Err()
+} // LoadFrom()
+
+// ---------------------------------------------------------------------------
+// `tABPSaver` methods:
+
+// `Save()` writes all patterns currently in the node to the writer,
+// one ABP filter rule per hostname pattern.
+//
+// Wildcard patterns (`*.domain.tld`) are written as domain rules
+// (`||domain.tld^`) and all other patterns as exact rules
+// (`|host.domain.tld^`) as used by DNS based ad blockers.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//   - `aWriter`: The writer to write the patterns to.
+//   - `aNode`: The node to write the patterns from.
+//
+// Returns:
+//   - `error`: `nil` if the patterns were written successfully, the error otherwise.
+func (as *tABPSaver) Save(aCtx context.Context, aWriter io.Writer, aNode *tNode) error {
+	if (nil == as) || (nil == aWriter) || (nil == aNode) {
+		return ErrLoaderNil
+	}
+	if _, err := fmt.Fprintln(aWriter, "[Adblock Plus 2.0]"); nil != err {
+		return err
+	}
+
+	return aNode.storeFunc(aCtx, aWriter, func(aPattern string) string {
+		if domain, ok := strings.CutPrefix(aPattern, "*."); ok {
+			return "||" + domain + "^"
+		}
+		return "|" + aPattern + "^"
+	})
+} // Save()
+
+// ---------------------------------------------------------------------------
+// `tDnsmasqLoader` methods:
+
+// `dnsmasqDomains()` returns the domains of a `dnsmasq` configuration
+// line like `address=/ads.example.com/tracker.example.net/0.0.0.0`.
+//
+// Only `address`, `local`, and `server` lines are considered.
+//
+// Internationalised domains are converted to their punycode form.
+//
+// Parameters:
+//   - `aLine`: The line to process.
+//   - `aOptions`: The strictness of the domains' checks and optional
+//     counters of the converted domains.
+//
+// Returns:
+//   - `rDomains`: The line's valid domains, `nil` if there are none.
+func dnsmasqDomains(aLine string, aOptions tLoadOptions) (rDomains []string) {
+	key, value, ok := strings.Cut(aLine, "=")
+	if !ok {
+		aOptions.stats.reject(reasonDnsmasq)
+		return
+	}
+	switch strings.TrimSpace(key) {
+	case "address", "local", "server":
+	default:
+		aOptions.stats.reject(reasonDnsmasq)
+		return
+	}
+	if value = strings.TrimSpace(value); !strings.HasPrefix(value, "/") {
+		aOptions.stats.reject(reasonNoHost)
+		return
+	}
+
+	// The last field is the (optional) address or server
+	fields := strings.Split(value[1:], "/")
+	for _, domain := range fields[:len(fields)-1] {
+		domain = strings.TrimPrefix(strings.TrimSpace(domain), ".")
+		if domain, ok = toASCII(domain, aOptions.stats); !ok {
+			continue
+		}
+		if aOptions.validation.isHostname(domain) {
+			rDomains = append(rDomains, domain)
+		} else {
+			aOptions.stats.reject(reasonHostname)
+		}
+	}
+
+	return
+} // dnsmasqDomains()
+
+// `Load()` reads the domains from the file and adds them to the
+// node's tree.
+//
+// `dnsmasq` blocks a domain including all its subdomains, hence both,
+// the domain and a wildcard for its subdomains, are added.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//   - `aFilename`: The path/name to read the domains from.
+//   - `aNode`: The node to add the patterns to.
+//
+// Returns:
+//   - `error`: `nil` if the patterns were read successfully, the error otherwise.
+func (dl *tDnsmasqLoader) Load(aCtx context.Context, aFilename string, aNode *tNode) error {
+	if (nil == dl) || (nil == aNode) {
+		return ErrLoaderNil
+	}
+
+	// Open the downloaded file
+	inFile, err := os.Open(aFilename) //#nosec G304
+	if nil != err {
+		return err
+	}
+	defer inFile.Close()
+
+	return dl.LoadFrom(aCtx, inFile, aNode)
 } // Load()
 
+// `LoadFrom()` reads the domains from the reader and adds them to the
+// node's tree.
+//
+// `dnsmasq` blocks a domain including all its subdomains, hence both,
+// the domain and a wildcard for its subdomains, are added.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//   - `aReader`: The reader to read the domains from.
+//   - `aNode`: The node to add the patterns to.
+//
+// Returns:
+//   - `error`: `nil` if the patterns were read successfully, the error otherwise.
+func (dl *tDnsmasqLoader) LoadFrom(aCtx context.Context, aReader io.Reader, aNode *tNode) error {
+	if (nil == dl) || (nil == aReader) || (nil == aNode) {
+		return ErrLoaderNil
+	}
+
+	scanner := bufio.NewScanner(aReader)
+	for scanner.Scan() {
+		// Check for timeout or cancellation
+		if err := aCtx.Err(); nil != err {
+			return err
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if (0 == len(line)) || ("#" == string(line[0])) {
+			// Ignore empty and comment lines
+			continue
+		}
+
+		dl.stats.line()
+
+		for _, domain := range dnsmasqDomains(line, dl.tLoadOptions) {
+			aNode.add(aCtx, pattern2parts(domain))
+			aNode.add(aCtx, pattern2parts("*."+domain))
+			dl.stats.accept()
+			dl.stats.accept()
+		}
+	}
+
+	return scanner.Err()
+} // LoadFrom()
+
 // ---------------------------------------------------------------------------
 // `tHostsLoader` methods:
 
@@ -377,7 +550,24 @@ func (hl *tHostsLoader) Load(aCtx context.Context, aFilename string, aNode *tNod
 	}
 	defer inFile.Close()
 
-	scanner := bufio.NewScanner(inFile)
+	return hl.LoadFrom(aCtx, inFile, aNode)
+} // Load()
+
+// `LoadFrom()` reads hostnames from the reader and adds them to the node's tree.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//   - `aReader`: The reader to read the hostnames from.
+//   - `aNode`: The node to add the hostnames's patterns to.
+//
+// Returns:
+//   - `error`: `nil` if the patterns were read successfully, the error otherwise.
+func (hl *tHostsLoader) LoadFrom(aCtx context.Context, aReader io.Reader, aNode *tNode) error {
+	if (nil == hl) || (nil == aReader) || (nil == aNode) {
+		return ErrLoaderNil
+	}
+
+	scanner := bufio.NewScanner(aReader)
 	for scanner.Scan() {
 		// Check for timeout or cancellation
 		if err := aCtx.Err(); nil != err {
@@ -397,32 +587,42 @@ func (hl *tHostsLoader) Load(aCtx context.Context, aFilename string, aNode *tNod
 			// Not a comment line
 		}
 
+		hl.stats.line()
+
 		// Split the line into fields: We need at least two
 		// fields (IP address and hostname).
-		if fields := strings.Fields(line); 1 < len(fields) {
-			// Check if the IP is valid
-			if nil == net.ParseIP(fields[0]) {
+		fields := strings.Fields(line)
+		if 2 > len(fields) {
+			hl.stats.reject(reasonNoHost)
+			continue
+		}
+		// Check if the IP is valid
+		if nil == net.ParseIP(fields[0]) {
+			hl.stats.reject(reasonIP)
+			continue
+		}
+		// Check the remaining parts of the line
+		fields = fields[1:]
+		for idx := range fields {
+			pattern, ok := toASCII(fields[idx], hl.stats)
+			if !ok {
 				continue
 			}
-			// Check the remaining parts of the line
-			fields = fields[1:]
-			for idx := range fields {
-				if parts := pattern2parts(fields[idx]); 0 < len(parts) {
-					aNode.add(aCtx, parts)
-				}
+			if parts := pattern2parts(pattern); 0 < len(parts) {
+				aNode.add(aCtx, parts)
+				hl.stats.accept()
 			}
 		}
 	}
 
 	return scanner.Err()
-} // Load()
+} // LoadFrom()
 
-/*
 // ---------------------------------------------------------------------------
 // `tHostsSaver` methods:
 
 // `Save()` writes all patterns currently in the node to the writer,
-// one hostname pattern per line.
+// one `IP pattern` line per hostname pattern.
 //
 // Parameters:
 //   - `aCtx`: The timeout context to use for the operation.
@@ -435,12 +635,15 @@ func (hs *tHostsSaver) Save(aCtx context.Context, aWriter io.Writer, aNode *tNod
 	if (nil == hs) || (nil == aWriter) || (nil == aNode) {
 		return ErrLoaderNil
 	}
+	ip := "127.0.0.1"
+	if nil != hs.ip {
+		ip = hs.ip.String()
+	}
 
-	// We need to prepend the IP address to each pattern line.
-	// This is a bit of a hack, but it works.
-	return aNode.store(aCtx, aWriter, "127.0.0.1")
+	return aNode.storeFunc(aCtx, aWriter, func(aPattern string) string {
+		return ip + " " + aPattern
+	})
 } // Save()
-*/
 
 // ---------------------------------------------------------------------------
 // `tSimpleLoader` methods:
@@ -467,7 +670,25 @@ func (sl *tSimpleLoader) Load(aCtx context.Context, aFilename string, aNode *tNo
 	}
 	defer inFile.Close()
 
-	scanner := bufio.NewScanner(inFile)
+	return sl.LoadFrom(aCtx, inFile, aNode)
+} // Load()
+
+// `LoadFrom()` reads hostname patterns from the reader and adds them
+// to the node's tree.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//   - `aReader`: The reader to read the hostnames from.
+//   - `aNode`: The node to add the patterns to.
+//
+// Returns:
+//   - `error`: `nil` if the patterns were read successfully, the error otherwise.
+func (sl *tSimpleLoader) LoadFrom(aCtx context.Context, aReader io.Reader, aNode *tNode) error {
+	if (nil == sl) || (nil == aReader) || (nil == aNode) {
+		return ErrLoaderNil
+	}
+
+	scanner := bufio.NewScanner(aReader)
 	for scanner.Scan() {
 		// Check for timeout or cancellation
 		if err := aCtx.Err(); nil != err {
@@ -481,19 +702,28 @@ func (sl *tSimpleLoader) Load(aCtx context.Context, aFilename string, aNode *tNo
 
 		switch string(line[0]) {
 		case "#", ";":
-			// Ignore comment lines
+			// Ignore comment lines (but refuse newer file versions)
+			if version, ok := listFileVersion(line); ok && (listVersion < version) {
+				return fmt.Errorf("%w: %q (v%d)",
+					ErrListVersion, readerName(aReader), version)
+			}
 			continue
 		default:
 			// Not a comment line
 		}
 
-		if parts := pattern2parts(line); 0 < len(parts) {
-			aNode.add(aCtx, parts)
+		sl.stats.line()
+
+		if line, ok := toASCII(line, sl.stats); ok {
+			if parts := pattern2parts(line); 0 < len(parts) {
+				aNode.add(aCtx, parts)
+				sl.stats.accept()
+			}
 		}
 	}
 
 	return scanner.Err()
-} // Load()
+} // LoadFrom()
 
 // ---------------------------------------------------------------------------
 // `tSimpleSaver` methods:
@@ -592,6 +822,10 @@ var (
 
 	// `validHostnameRE` is a regular expression for hostname validation per RFC 952/1123.
 	validHostnameRE = regexp.MustCompile(`^(?i:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?(?:\.[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?)*)$`)
+
+	// `relaxedHostnameRE` additionally allows underscores as used
+	// e.g. by SRV records and some intranet names.
+	relaxedHostnameRE = regexp.MustCompile(`^(?i:[a-z0-9_](?:[a-z0-9_-]{0,61}[a-z0-9_])?(?:\.[a-z0-9_](?:[a-z0-9_-]{0,61}[a-z0-9_])?)*)$`)
 )
 
 // `detectFileType()` detects the type of a file based on its magic number.
@@ -630,6 +864,12 @@ func detectFileType(aFilename string) (rMime string, rErr error) {
 			return
 		}
 
+		// `dnsmasq` lines would look like ABP lines as well
+		if isDnsmasqFile(inFile) {
+			rMime = "text/x-dnsmasq"
+			return
+		}
+
 		if isABPfile(inFile) {
 			rMime = "text/x-abp"
 			return
@@ -648,18 +888,28 @@ func detectFileType(aFilename string) (rMime string, rErr error) {
 // `downloadFile()` downloads a file from the given URL and saves it
 // in the specified directory with the given filename.
 //
+// The download uses the settings given by `SetDownloadOptions()`.
+// An existing copy of the same URL is revalidated with a conditional
+// request and kept if it's still up to date. If the download fails,
+// that copy is used instead, so a list remains available offline.
+//
 // Parameters:
+//   - `aCtx`: The context to use for the operation.
 //   - `aURL`: The URL to download the file from.
 //   - `aFilename`: The filename to save the data as.
 //
 // Returns:
 //   - `rFilename`: The absolute path/name of the downloaded file.
 //   - `rErr`: `nil` if the file was downloaded and saved successfully, the error otherwise.
-func downloadFile(aURL, aFilename string) (rFilename string, rErr error) {
+func downloadFile(aCtx context.Context, aURL, aFilename string) (rFilename string, rErr error) {
 	if aURL = strings.TrimSpace(aURL); 0 == len(aURL) {
 		rErr = ErrInvalidUrl
 		return
 	}
+	if aFilename = strings.TrimSpace(aFilename); 0 == len(aFilename) {
+		rErr = ErrInvalidFile
+		return
+	}
 	if aFilename, rErr = filepath.Abs(aFilename); nil != rErr {
 		return
 	}
@@ -675,9 +925,12 @@ func downloadFile(aURL, aFilename string) (rFilename string, rErr error) {
 		return
 	}
 
-	//
-	//TODO: Check whether we have a local cop< already
-	//
+	// Check whether we have a local copy already
+	meta := readMirrorMeta(aFilename, aURL)
+	mirrored := (nil != meta)
+	if !mirrored {
+		meta = &tMirrorMeta{URL: aURL}
+	}
 
 	// Build a tmp. filename
 	tmpName := aFilename + "~"
@@ -685,14 +938,6 @@ func downloadFile(aURL, aFilename string) (rFilename string, rErr error) {
 		_ = os.Remove(tmpName)
 	}
 
-	// Request the file
-	resp, err := http.Get(aURL) //#nosec G107
-	if nil != err {
-		rErr = ADlistError{fmt.Errorf("Failed to download file: %v", err)}
-		return
-	}
-	defer resp.Body.Close()
-
 	// First write to the temporary file and later rename
 	// it to the final name if no errors occurred
 	tmpFile, err := os.OpenFile(tmpName, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600) //#nosec G304
@@ -702,10 +947,17 @@ func downloadFile(aURL, aFilename string) (rFilename string, rErr error) {
 	}
 	defer tmpFile.Close()
 
-	// Copy the content.
-	if _, err = io.Copy(tmpFile, resp.Body); nil != err {
+	// Request the file and copy the content.
+	if rErr = downloader().download(aCtx, aURL, meta, tmpFile); nil != rErr {
 		_ = os.Remove(tmpName)
-		rErr = ADlistError{fmt.Errorf("Failed to save file: %v", err)}
+		if mirrored {
+			if errors.Is(rErr, errNotModified) {
+				meta.Fetched = time.Now()
+				_ = meta.write(aFilename)
+			}
+			// Use the local copy
+			rFilename, rErr = aFilename, nil
+		}
 		return
 	}
 
@@ -715,6 +967,8 @@ func downloadFile(aURL, aFilename string) (rFilename string, rErr error) {
 		rErr = ADlistError{fmt.Errorf("Failed to rename file: %v", rErr)}
 		return
 	}
+	meta.Fetched = time.Now()
+	_ = meta.write(aFilename)
 	rFilename = aFilename
 
 	return
@@ -828,6 +1082,41 @@ func isBinary(aFile io.ReadSeeker) (tArchiveFormat, error) {
 	return ArchiveUnknown, ErrUnknownFileType
 } // isBinary()
 
+// `isDnsmasqFile()` checks whether the given file is a `dnsmasq`
+// configuration file.
+//
+// Parameters:
+//   - `aFile`: The file data to check.
+//
+// Returns:
+//   - `rOK`: `true` if the file is a `dnsmasq` file, `false` otherwise.
+func isDnsmasqFile(aFile io.ReadSeeker) (rOK bool) {
+	if nil == aFile {
+		return
+	}
+	if _, err := aFile.Seek(0, io.SeekStart); nil != err {
+		return
+	}
+
+	loops := 0
+	scanner := bufio.NewScanner(aFile)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		// Ignore empty lines and comment lines
+		if (0 == len(line)) || ("#" == string(line[0])) {
+			continue
+		}
+
+		if 0 == len(dnsmasqDomains(line, tLoadOptions{validation: ValidateRelaxed})) {
+			return
+		}
+		loops++
+	}
+	rOK = (0 < loops)
+
+	return
+} // isDnsmasqFile()
+
 // `isHostnamesOnly()` checks whether the given file contains
 // only hostname patterns or wildcards.
 //
@@ -857,7 +1146,7 @@ func isHostnamesOnly(aFile io.ReadSeeker) (rOK bool) {
 		}
 
 		// Allow a single hostname or wildcard per line
-		if !isValidHostname(line) && !isValidWildcard(line) {
+		if !ValidateRelaxed.isValid(asciiForm(line)) {
 			return
 		}
 		loops++
@@ -906,7 +1195,7 @@ func isHostsFile(aFile io.ReadSeeker) (rOK bool) {
 
 		// Check if the hostname patterns are valid
 		for _, pattern := range fields[1:] {
-			if !isValidHostname(pattern) && !isValidWildcard(pattern) {
+			if !ValidateRelaxed.isValid(asciiForm(pattern)) {
 				return
 			}
 		}
@@ -918,7 +1207,7 @@ func isHostsFile(aFile io.ReadSeeker) (rOK bool) {
 	return
 } // isHostsFile()
 
-// `isText()` checks whether the given data is text.
+// `isText()` checks whether the given data is (UTF-8 encoded) text.
 //
 // Parameters:
 //   - `aFile`: The file data to check.
@@ -946,13 +1235,25 @@ func isText(aFile io.ReadSeeker) (rOK bool) {
 
 	loops := 0
 	for _, b := range header {
-		if (b < 0x09 || b > 0x0D) && (b < 0x20 || b > 0x7E) {
+		// Control characters but no multi-byte (UTF-8) characters
+		if (b < 0x09 || b > 0x0D) && (b < 0x20 || b == 0x7F) {
 			return
 		}
 
 		loops++
 	}
-	rOK = (0 < loops)
+
+	// A full header may end within a multi-byte character
+	if len(header) == cap(header) {
+		last := len(header) - 1
+		for (0 < last) && !utf8.RuneStart(header[last]) {
+			last--
+		}
+		if !utf8.FullRune(header[last:]) {
+			header = header[:last]
+		}
+	}
+	rOK = (0 < loops) && utf8.Valid(header)
 
 	return
 } // isText()
@@ -969,18 +1270,17 @@ func isValidHostname(aPattern string) bool {
 		return false
 	}
 
-	if 0 < len(toplevelDomains) {
-		// Check for valid top-level domain
-		tld := filepath.Ext(aPattern)
-		if 0 < len(tld) {
-			// Remove the leading dot
-			tld = tld[1:]
-		} else {
-			// No top-level domain, use the whole pattern
-			tld = aPattern
-		}
-
-		if ok := slices.Contains(toplevelDomains, tld); ok {
+	// Check for valid top-level domain
+	tld := filepath.Ext(aPattern)
+	if 0 < len(tld) {
+		// Remove the leading dot
+		tld = tld[1:]
+	} else {
+		// No top-level domain, use the whole pattern
+		tld = aPattern
+	}
+	if ok, checked := isKnownTLD(tld); checked {
+		if ok {
 			return validHostnameRE.MatchString(aPattern)
 		}
 
@@ -1014,4 +1314,88 @@ func isValidWildcard(aPattern string) (rOK bool) {
 	return
 } // isValidWildcard()
 
+// `readerName()` returns the name of the given reader for error
+// messages, i.e. the filename of an `os.File`.
+//
+// Parameters:
+//   - `aReader`: The reader to name.
+//
+// Returns:
+//   - `string`: The reader's name, `"stream"` if it has none.
+func readerName(aReader io.Reader) string {
+	if named, ok := aReader.(interface{ Name() string }); ok {
+		return named.Name()
+	}
+
+	return "stream"
+} // readerName()
+
+// ---------------------------------------------------------------------------
+// `TValidation` methods:
+
+// `isHostname()` checks whether the given pattern is a valid hostname
+// at the validation's strictness.
+//
+// Parameters:
+//   - `aPattern`: The hostname pattern to check.
+//
+// Returns:
+//   - `bool`: `true` if the pattern is a valid hostname, `false` otherwise.
+func (v TValidation) isHostname(aPattern string) bool {
+	switch v {
+	case ValidateRelaxed:
+		aPattern = strings.TrimSpace(aPattern)
+		return (0 < len(aPattern)) && (253 >= len(aPattern)) &&
+			relaxedHostnameRE.MatchString(aPattern)
+
+	case ValidateOff:
+		aPattern = strings.TrimSpace(aPattern)
+		return (0 < len(aPattern)) && (253 >= len(aPattern)) &&
+			!strings.ContainsFunc(aPattern, unicode.IsSpace)
+
+	default:
+		return isValidHostname(aPattern)
+	}
+} // isHostname()
+
+// `isValid()` checks whether the given pattern is a valid hostname or
+// wildcard at the validation's strictness.
+//
+// Apart from a leading `*.` a pattern may contain `*` labels standing
+// for a single label (e.g. `ads.*.example.com` or `*.tracking.*`) as
+// long as it has at least one other label. Those wildcards are
+// checked as if they were an ordinary label, or a known top-level
+// domain respectively.
+//
+// Parameters:
+//   - `aPattern`: The hostname or wildcard pattern to check.
+//
+// Returns:
+//   - `bool`: `true` if the pattern is valid, `false` otherwise.
+func (v TValidation) isValid(aPattern string) bool {
+	if v.isHostname(aPattern) {
+		return true
+	}
+	aPattern = strings.TrimSpace(aPattern)
+	hostname, ok := strings.CutPrefix(aPattern, "*.")
+	if !strings.Contains(hostname, "*") {
+		return ok && (0 < len(hostname)) && v.isHostname(hostname)
+	}
+
+	labels := strings.Split(hostname, ".")
+	literal := false
+	for idx, label := range labels {
+		switch {
+		case "*" != label:
+			literal = true
+		case len(labels)-1 == idx:
+			labels[idx] = "com"
+		default:
+			labels[idx] = "x"
+		}
+	}
+
+	return literal && v.isHostname(strings.Join(labels, "."))
+} // isValid()
+
 /* _EoF_ */
@@ -10,8 +10,10 @@ import (
 	"bytes"
 	"context"
 	"io"
+	"net"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -133,6 +135,33 @@ func Test_tABPLoader_Load(t *testing.T) {
 	}
 } // Test_tABPLoader_Load()
 
+func Test_tDnsmasqLoader_Load(t *testing.T) {
+	tmpDir := t.TempDir()
+	fName := filepath.Join(tmpDir, "dnsmasq.conf")
+	_ = os.WriteFile(fName, []byte("# blocked\naddress=/ads.localdomain/tracker.localdomain/0.0.0.0\nlocal=/local.localdomain/\n"), 0600)
+
+	node := newNode()
+	if err := (&tDnsmasqLoader{}).Load(context.TODO(), fName, node); nil != err {
+		t.Fatalf("tDnsmasqLoader.Load() error = '%v'", err)
+	}
+	for _, hostname := range []string{
+		"ads.localdomain", "www.ads.localdomain",
+		"tracker.localdomain", "local.localdomain",
+	} {
+		if !node.match(context.TODO(), pattern2parts(hostname)) {
+			t.Errorf("tDnsmasqLoader.Load() didn't load %q", hostname)
+		}
+	}
+
+	var nilLoader *tDnsmasqLoader
+	if err := nilLoader.Load(context.TODO(), fName, node); nil == err {
+		t.Error("tDnsmasqLoader.Load() error = 'nil', want an error")
+	}
+	if err := (&tDnsmasqLoader{}).Load(context.TODO(), filepath.Join(tmpDir, "missing"), node); nil == err {
+		t.Error("tDnsmasqLoader.Load() error = 'nil', want an error")
+	}
+} // Test_tDnsmasqLoader_Load()
+
 func Test_tHostsLoader_Load(t *testing.T) {
 	loader := &tHostsLoader{}
 	tmpDir := t.TempDir()
@@ -360,7 +389,157 @@ func Test_tSimpleLoader_Load(t *testing.T) {
 	}
 } // Test_tSimpleLoader_Load()
 
-/*
+func Test_ILoader_LoadFrom(t *testing.T) {
+	tests := []struct {
+		name      string
+		loader    ILoader
+		reader    io.Reader
+		wantHosts []string
+		wantErr   string
+	}{
+		/* */
+		{
+			name:    "01 - nil reader",
+			loader:  &tSimpleLoader{},
+			reader:  nil,
+			wantErr: ErrLoaderNil.Error(),
+		},
+		{
+			name:      "02 - ABP rules",
+			loader:    &tABPLoader{},
+			reader:    strings.NewReader("[Adblock Plus 2.0]\n||ads.localdomain^\n"),
+			wantHosts: []string{"www.ads.localdomain"},
+		},
+		{
+			name:    "03 - ABP without patterns",
+			loader:  &tABPLoader{},
+			reader:  strings.NewReader("! comment\n"),
+			wantErr: `no valid patterns found in "stream"`,
+		},
+		{
+			name:      "04 - dnsmasq options",
+			loader:    &tDnsmasqLoader{},
+			reader:    strings.NewReader("address=/ads.localdomain/0.0.0.0\n"),
+			wantHosts: []string{"ads.localdomain", "www.ads.localdomain"},
+		},
+		{
+			name:      "05 - hosts file",
+			loader:    &tHostsLoader{},
+			reader:    strings.NewReader("0.0.0.0 ads.localdomain tracker.localdomain\n"),
+			wantHosts: []string{"ads.localdomain", "tracker.localdomain"},
+		},
+		{
+			name:      "06 - hostnames",
+			loader:    &tSimpleLoader{},
+			reader:    strings.NewReader("ads.localdomain\n*.tracker.localdomain\n"),
+			wantHosts: []string{"ads.localdomain", "www.tracker.localdomain"},
+		},
+		{
+			name:    "07 - newer list version",
+			loader:  &tSimpleLoader{},
+			reader:  strings.NewReader(listHeader + "99\nads.localdomain\n"),
+			wantErr: `"stream" (v99)`,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			node := newNode()
+			err := tc.loader.LoadFrom(context.TODO(), tc.reader, node)
+			if "" != tc.wantErr {
+				if (nil == err) || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Errorf("LoadFrom() error = '%v', want '%s'", err, tc.wantErr)
+				}
+				return
+			}
+			if nil != err {
+				t.Fatalf("LoadFrom() error = '%v'", err)
+			}
+			for _, hostname := range tc.wantHosts {
+				if !node.match(context.TODO(), pattern2parts(hostname)) {
+					t.Errorf("LoadFrom() didn't load %q", hostname)
+				}
+			}
+		})
+	}
+} // Test_ILoader_LoadFrom()
+
+func Test_tABPSaver_Save(t *testing.T) {
+	tests := []struct {
+		name     string
+		as       *tABPSaver
+		node     *tNode
+		wantText string
+		wantErr  bool
+	}{
+		/* */
+		{
+			name:    "01 - nil saver",
+			as:      nil,
+			node:    newNode(),
+			wantErr: true,
+		},
+		{
+			name:    "02 - nil node",
+			as:      &tABPSaver{},
+			node:    nil,
+			wantErr: true,
+		},
+		{
+			name:     "03 - empty node",
+			as:       &tABPSaver{},
+			node:     newNode(),
+			wantText: "[Adblock Plus 2.0]\n",
+		},
+		{
+			name: "04 - wildcard and hostname",
+			as:   &tABPSaver{},
+			node: func() *tNode {
+				n := newNode()
+				n.add(context.TODO(), tPartsList{"localdomain", "ads", "*"})
+				n.add(context.TODO(), tPartsList{"localdomain", "tracker"})
+				return n
+			}(),
+			wantText: "[Adblock Plus 2.0]\n||ads.localdomain^\n|tracker.localdomain^\n",
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			aWriter := &bytes.Buffer{}
+			err := tc.as.Save(context.TODO(), aWriter, tc.node)
+
+			if (nil != err) != tc.wantErr {
+				t.Errorf("tABPSaver.Save() error = '%v', wantErr '%v'",
+					err, tc.wantErr)
+				return
+			}
+			if gotText := aWriter.String(); gotText != tc.wantText {
+				t.Errorf("tABPSaver.Save() =\n%q\nwant\n%q",
+					gotText, tc.wantText)
+			}
+			if tc.wantErr || (0 == len(tc.node.allPatterns(context.TODO()))) {
+				return
+			}
+
+			// The saved list must be readable by the ABP loader
+			fName := filepath.Join(t.TempDir(), "abp.txt")
+			if err := os.WriteFile(fName, aWriter.Bytes(), 0600); nil != err {
+				t.Fatal(err)
+			}
+			loaded := newNode()
+			if err := (&tABPLoader{}).Load(context.TODO(), fName, loaded); nil != err {
+				t.Errorf("tABPLoader.Load() error = '%v'", err)
+			}
+			if got, want := loaded.allPatterns(context.TODO()), tc.node.allPatterns(context.TODO()); !slices.Equal(got, want) {
+				t.Errorf("reloaded patterns = '%v', want '%v'", got, want)
+			}
+		})
+	}
+} // Test_tABPSaver_Save()
+
 func Test_tHostsSaver_Save(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -435,6 +614,17 @@ func Test_tHostsSaver_Save(t *testing.T) {
 			wantText: "127.0.0.1 *.domain.tld\n127.0.0.1 host.domain.tld\n",
 			wantErr:  false,
 		},
+		{
+			name: "08 - custom address",
+			hs:   &tHostsSaver{ip: net.IPv4zero},
+			node: func() *tNode {
+				n := newNode()
+				n.add(context.TODO(), tPartsList{"tld", "domain"})
+				return n
+			}(),
+			wantText: "0.0.0.0 domain.tld\n",
+			wantErr:  false,
+		},
 		// TODO: Add test cases.
 	}
 
@@ -455,7 +645,6 @@ func Test_tHostsSaver_Save(t *testing.T) {
 		})
 	}
 } // Test_tHostsSaver_Save()
-*/
 
 func Test_tSimpleSaver_Save(t *testing.T) {
 	tests := []struct {
@@ -594,7 +783,7 @@ func Test_downloadFile(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			name, err := downloadFile(tc.url, tc.filename)
+			name, err := downloadFile(context.TODO(), tc.url, tc.filename)
 
 			if (nil != err) != tc.wantErr {
 				t.Errorf("DownloadFile() error =\n'%v'\nwantErr '%v'",
@@ -777,6 +966,34 @@ func Test_isBinary(t *testing.T) {
 	}
 } // Test_isBinary()
 
+func Test_isDnsmasqFile(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want bool
+	}{
+		/* */
+		{"01 - empty", "", false},
+		{"02 - comments only", "# nothing\n\n", false},
+		{"03 - dnsmasq", "# list\naddress=/ads.localdomain/0.0.0.0\nserver=/tracker.localdomain/\n", true},
+		{"04 - mixed", "address=/ads.localdomain/0.0.0.0\nads.localdomain\n", false},
+		{"05 - ABP", "||ads.localdomain^\n", false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isDnsmasqFile(strings.NewReader(tc.data)); got != tc.want {
+				t.Errorf("isDnsmasqFile() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+
+	if isDnsmasqFile(nil) {
+		t.Error("isDnsmasqFile() = 'true', want 'false'")
+	}
+} // Test_isDnsmasqFile()
+
 func Test_isHostnamesOnly(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -975,6 +1192,21 @@ func Test_isText(t *testing.T) {
 			file:   bytes.NewReader([]byte("Hello, World!\n# Comment")),
 			wantOK: true,
 		},
+		{
+			name:   "06 - UTF-8 text file",
+			file:   bytes.NewReader([]byte("bücher.localdomain\n例え.テスト\n")),
+			wantOK: true,
+		},
+		{
+			name:   "07 - invalid UTF-8",
+			file:   bytes.NewReader([]byte{'a', 0xFF, 0xFE, '\n'}),
+			wantOK: false,
+		},
+		{
+			name:   "08 - header ending within a character",
+			file:   bytes.NewReader([]byte(strings.Repeat("a", 511) + "ü")),
+			wantOK: true,
+		},
 		/* */
 		// TODO: Add test cases.
 	}
@@ -1104,6 +1336,47 @@ func Test_isValidWildcard(t *testing.T) {
 	}
 } // Test_isValidWildcard()
 
+func Test_TValidation_isValid(t *testing.T) {
+	tests := []struct {
+		name    string
+		level   TValidation
+		pattern string
+		wantOK  bool
+	}{
+		/* */
+		{"01 - strict, known TLD", ValidateStrict, "www.example.com", true},
+		{"02 - strict, unknown TLD", ValidateStrict, "foo.corp", false},
+		{"03 - strict, single label", ValidateStrict, "router", false},
+		{"04 - strict, underscore", ValidateStrict, "_dmarc.example.com", false},
+		{"05 - strict, wildcard", ValidateStrict, "*.example.com", true},
+		{"06 - relaxed, unknown TLD", ValidateRelaxed, "foo.corp", true},
+		{"07 - relaxed, single label", ValidateRelaxed, "router", true},
+		{"08 - relaxed, underscore", ValidateRelaxed, "_dmarc.example.com", true},
+		{"09 - relaxed, wildcard", ValidateRelaxed, "*.corp", true},
+		{"10 - relaxed, invalid character", ValidateRelaxed, "foo!corp", false},
+		{"11 - relaxed, empty", ValidateRelaxed, "", false},
+		{"12 - off, invalid character", ValidateOff, "foo!corp", true},
+		{"13 - off, whitespace", ValidateOff, "foo corp", false},
+		{"14 - off, too long", ValidateOff, strings.Repeat("a", 254), false},
+		{"15 - strict, mid wildcard", ValidateStrict, "ads.*.example.com", true},
+		{"16 - strict, wildcard TLD", ValidateStrict, "*.tracking.*", true},
+		{"17 - strict, wildcards only", ValidateStrict, "*.*", false},
+		{"18 - strict, partial wildcard", ValidateStrict, "ads*.example.com", false},
+		{"19 - relaxed, mid wildcard", ValidateRelaxed, "ads.*.corp", true},
+		{"20 - strict, mid wildcard, unknown TLD", ValidateStrict, "ads.*.corp", false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if gotOK := tc.level.isValid(tc.pattern); gotOK != tc.wantOK {
+				t.Errorf("TValidation(%d).isValid(%q) = '%v', want '%v'",
+					tc.level, tc.pattern, gotOK, tc.wantOK)
+			}
+		})
+	}
+} // Test_TValidation_isValid()
+
 func Test_detectFileType(t *testing.T) {
 	tmpDir := t.TempDir()
 	tests := []struct {
@@ -1183,6 +1456,16 @@ func Test_detectFileType(t *testing.T) {
 			wantMime: "application/x-zip",
 			wantErr:  false,
 		},
+		{
+			name: "08 - dnsmasq file",
+			filename: func() string {
+				fName := filepath.Join(tmpDir, "dnsmasq.conf")
+				_ = os.WriteFile(fName, []byte("address=/localhost.localdomain/0.0.0.0\n"), 0644)
+				return fName
+			}(),
+			wantMime: "text/x-dnsmasq",
+			wantErr:  false,
+		},
 		/* */
 		// TODO: Add test cases.
 	}
@@ -1203,6 +1486,37 @@ func Test_detectFileType(t *testing.T) {
 	}
 } // Test_detectFileType()
 
+func Test_dnsmasqDomains(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		level TValidation
+		want  []string
+	}{
+		/* */
+		{"01 - empty", "", ValidateStrict, nil},
+		{"02 - no option", "ads.localdomain", ValidateStrict, nil},
+		{"03 - other option", "cache-size=1000", ValidateStrict, nil},
+		{"04 - address", "address=/ads.localdomain/0.0.0.0", ValidateStrict, []string{"ads.localdomain"}},
+		{"05 - several domains", "address=/ads.localdomain/.tracker.localdomain/::", ValidateStrict,
+			[]string{"ads.localdomain", "tracker.localdomain"}},
+		{"06 - server", "server=/ads.localdomain/", ValidateStrict, []string{"ads.localdomain"}},
+		{"07 - all domains", "address=/#/0.0.0.0", ValidateStrict, nil},
+		{"08 - no slash", "address=ads.localdomain", ValidateStrict, nil},
+		{"09 - intranet domain", "address=/ads.corp/0.0.0.0", ValidateStrict, nil},
+		{"10 - relaxed intranet domain", "address=/ads.corp/0.0.0.0", ValidateRelaxed, []string{"ads.corp"}},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := dnsmasqDomains(tc.line, tLoadOptions{validation: tc.level}); !slices.Equal(got, tc.want) {
+				t.Errorf("dnsmasqDomains() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_dnsmasqDomains()
+
 func Test_ProcessABPLine(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -1217,9 +1531,10 @@ func Test_ProcessABPLine(t *testing.T) {
 		{"/ad/*", "", false},
 		{"*analytics*.js", "", false},
 		{"malformed/domain", "", false},
-		{"||host.*example.com^", "*.host.*example.com", true},
+		{"||host.*example.com^", "", false},
 		{"host.domain.tld", "host.domain.tld", true},
-		{"host.*.domain.tld", "", false},
+		{"host.*.domain.tld", "host.*.domain.tld", true},
+		{"||tracking.*^", "*.tracking.*", true},
 		{"test@domain.tld", "", false},
 		{"|https://host.domain.tld?redirect=https://example.com", "", false},
 		{"|https:// ", "", false},
@@ -1234,4 +1549,57 @@ func Test_ProcessABPLine(t *testing.T) {
 	}
 } // Test_processABPLine()
 
+func Fuzz_processABPLine(f *testing.F) {
+	for _, seed := range []string{
+		"||example.com^", "|https://example.com/|", "*.example.com",
+		"example.com:8080", "*analytics*.js", "||", "a", "*", "://",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, aLine string) {
+		pattern, ok := processABPLine(aLine)
+		if !ok {
+			return
+		}
+		if 0 == len(pattern) {
+			t.Errorf("processABPLine(%q) = '', want a pattern", aLine)
+		}
+		if strings.ContainsAny(pattern, " /?#@[]") {
+			t.Errorf("processABPLine(%q) = %q, want no invalid characters", aLine, pattern)
+		}
+	})
+} // Fuzz_processABPLine()
+
+// `fuzzLoad()` fuzzes a loader with the given seed files' contents.
+func fuzzLoad(f *testing.F, aLoader ILoader, aSeeds ...string) {
+	for _, seed := range aSeeds {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, aData []byte) {
+		node := newNode()
+		if err := aLoader.LoadFrom(context.TODO(), bytes.NewReader(aData), node); nil != err {
+			return
+		}
+		_, _ = node.count(context.TODO())
+	})
+} // fuzzLoad()
+
+func Fuzz_tABPLoader_Load(f *testing.F) {
+	fuzzLoad(f, &tABPLoader{},
+		"[Adblock Plus 2.0]\n! comment\n||ads.example.com^\n@@||good.example.com^\n",
+		"||a^\n||*.b.c^$third-party\n|http://d.e/f|\n",
+		"||a.b^$client='x'|~y,dnstype=A,dnsrewrite=NOERROR;A;1.2.3.4\n$important\n",
+		"\n\n||\n|\n^\n",
+		"0\n|0") // short lines used to panic
+} // Fuzz_tABPLoader_Load()
+
+func Fuzz_tHostsLoader_Load(f *testing.F) {
+	fuzzLoad(f, &tHostsLoader{},
+		"# comment\n127.0.0.1 localhost\n0.0.0.0 ads.example.com tracker.example.com\n",
+		"::1 ip6-localhost # trailing comment\n0.0.0.0\n\t\n",
+		"0.0.0.0 *.wild.example.com\n0.0.0.0 .\n")
+} // Fuzz_tHostsLoader_Load()
+
 /* _EoF_ */
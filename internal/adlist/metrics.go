@@ -14,11 +14,29 @@ import (
 //lint:file-ignore ST1017 - I prefer Yoda conditions
 
 type (
+	// `TCounters` contains the metrics data of a list that are
+	// available without forcing a garbage collection (see
+	// [TADlist.Counters]):
+	//
+	//   - `Nodes`: Number of nodes in the trie.
+	//   - `Patterns`: Number of patterns in the trie.
+	//   - `Hits`: Number of times a pattern was found.
+	//   - `Misses`: Number of times a pattern was not found.
+	//   - `Reloads`: Number of times the list was reloaded.
+	TCounters struct {
+		Nodes    uint32
+		Patterns uint32
+		Hits     uint32
+		Misses   uint32
+		Reloads  uint32
+	}
+
 	// `TMetrics` contains the metrics data for the node pool and the trie.
 	//
 	// These are the fields to access the metrics data:
 	//
 	//   - `PoolCreations`: Number of nodes created by the pool.
+	//   - `PoolHits`: Number of nodes taken from the pool.
 	//   - `PoolReturns`: Number of nodes returned to the pool.
 	//   - `PoolSize`: Current number of items in the pool.
 	//   - `Nodes`: Number of nodes in the trie.
@@ -27,11 +45,14 @@ type (
 	//   - `Misses`: Number of times a pattern was not found.
 	//   - `Reloads`: Number of times the list was reloaded.
 	//   - `Retries`: Number of times a reload was retried.
+	//   - `SwapNs`: Nanoseconds the last reload locked the trie.
+	//   - `MaxSwapNs`: Max. nanoseconds a reload locked the trie.
 	//   - `HeapAllocs`: Number of heap objects allocated.
 	//   - `HeapFrees`: Number of heap objects freed.
 	//   - `GCPauseTotalNs`: Cumulative nanoseconds in GC stop-the-world pauses.
 	TMetrics struct {
 		PoolCreations  uint32
+		PoolHits       uint32
 		PoolReturns    uint32
 		PoolSize       int
 		Nodes          uint32
@@ -40,6 +61,8 @@ type (
 		Misses         uint32
 		Reloads        uint32
 		Retries        uint32
+		SwapNs         uint64
+		MaxSwapNs      uint64
 		HeapAllocs     uint64
 		HeapFrees      uint64
 		GCPauseTotalNs uint64
@@ -60,6 +83,7 @@ func (m *TMetrics) clone() *TMetrics {
 
 	return &TMetrics{
 		PoolCreations:  m.PoolCreations,
+		PoolHits:       m.PoolHits,
 		PoolReturns:    m.PoolReturns,
 		PoolSize:       m.PoolSize,
 		Nodes:          m.Nodes,
@@ -68,6 +92,8 @@ func (m *TMetrics) clone() *TMetrics {
 		Misses:         m.Misses,
 		Reloads:        m.Reloads,
 		Retries:        m.Retries,
+		SwapNs:         m.SwapNs,
+		MaxSwapNs:      m.MaxSwapNs,
 		HeapAllocs:     m.HeapAllocs,
 		HeapFrees:      m.HeapFrees,
 		GCPauseTotalNs: m.GCPauseTotalNs,
@@ -90,6 +116,7 @@ func (m *TMetrics) Equal(aMetrics *TMetrics) bool {
 	}
 
 	return (m.PoolCreations == aMetrics.PoolCreations) &&
+		(m.PoolHits == aMetrics.PoolHits) &&
 		(m.PoolReturns == aMetrics.PoolReturns) &&
 		(m.PoolSize == aMetrics.PoolSize) &&
 		(m.Nodes == aMetrics.Nodes) &&
@@ -99,6 +126,8 @@ func (m *TMetrics) Equal(aMetrics *TMetrics) bool {
 		(m.Reloads == aMetrics.Reloads) &&
 		(m.Retries == aMetrics.Retries)
 	//NOTE: Ignore the runtime stats because they vary with every run.
+	// (m.SwapNs == aMetrics.SwapNs) &&
+	// (m.MaxSwapNs == aMetrics.MaxSwapNs) &&
 	// (m.HeapAllocs == aMetrics.HeapAllocs) &&
 	// (m.HeapFrees == aMetrics.HeapFrees) &&
 	// (m.GCPauseTotalNs == aMetrics.GCPauseTotalNs)
@@ -115,6 +144,7 @@ func (m *TMetrics) String() string {
 	var builder strings.Builder
 
 	fmt.Fprintf(&builder, "Pool.Creations: %d\n", m.PoolCreations)
+	fmt.Fprintf(&builder, "Pool.Hits: %d\n", m.PoolHits)
 	fmt.Fprintf(&builder, "Pool.Returns: %d\n", m.PoolReturns)
 	fmt.Fprintf(&builder, "Pool.Size: %d\n", m.PoolSize)
 	fmt.Fprintf(&builder, "Trie.Nodes: %d\n", m.Nodes)
@@ -123,6 +153,8 @@ func (m *TMetrics) String() string {
 	fmt.Fprintf(&builder, "Trie.Misses: %d\n", m.Misses)
 	fmt.Fprintf(&builder, "Trie.Reloads: %d\n", m.Reloads)
 	fmt.Fprintf(&builder, "Trie.Retries: %d\n", m.Retries)
+	fmt.Fprintf(&builder, "Trie.SwapNs: %d\n", m.SwapNs)
+	fmt.Fprintf(&builder, "Trie.MaxSwapNs: %d\n", m.MaxSwapNs)
 	fmt.Fprintf(&builder, "Heap.Allocs: %d\n", m.HeapAllocs)
 	fmt.Fprintf(&builder, "Heap.Frees: %d\n", m.HeapFrees)
 	fmt.Fprintf(&builder, "GC.PauseTotalNs: %d\n", m.GCPauseTotalNs)
@@ -71,6 +71,14 @@ func Test_TMetrics_Equal(t *testing.T) {
 			},
 			want: false,
 		},
+		{
+			name: "08 - not equal (4)",
+			m: &TMetrics{
+				PoolHits: 1,
+			},
+			metrics: &TMetrics{},
+			want:    false,
+		},
 		// TODO: Add test cases.
 	}
 
@@ -98,12 +106,13 @@ func Test_TMetrics_String(t *testing.T) {
 		{
 			name: "02 - empty",
 			m:    &TMetrics{},
-			want: "Pool.Creations: 0\nPool.Returns: 0\nPool.Size: 0\nTrie.Nodes: 0\nTrie.Patterns: 0\nTrie.Hits: 0\nTrie.Misses: 0\nTrie.Reloads: 0\nTrie.Retries: 0\nHeap.Allocs: 0\nHeap.Frees: 0\nGC.PauseTotalNs: 0\n",
+			want: "Pool.Creations: 0\nPool.Hits: 0\nPool.Returns: 0\nPool.Size: 0\nTrie.Nodes: 0\nTrie.Patterns: 0\nTrie.Hits: 0\nTrie.Misses: 0\nTrie.Reloads: 0\nTrie.Retries: 0\nTrie.SwapNs: 0\nTrie.MaxSwapNs: 0\nHeap.Allocs: 0\nHeap.Frees: 0\nGC.PauseTotalNs: 0\n",
 		},
 		{
 			name: "03 - non-empty",
 			m: &TMetrics{
 				PoolCreations:  1,
+				PoolHits:       13,
 				PoolReturns:    2,
 				PoolSize:       3,
 				Nodes:          4,
@@ -112,11 +121,13 @@ func Test_TMetrics_String(t *testing.T) {
 				Misses:         7,
 				Reloads:        8,
 				Retries:        9,
+				SwapNs:         14,
+				MaxSwapNs:      15,
 				HeapAllocs:     10,
 				HeapFrees:      11,
 				GCPauseTotalNs: 12,
 			},
-			want: "Pool.Creations: 1\nPool.Returns: 2\nPool.Size: 3\nTrie.Nodes: 4\nTrie.Patterns: 5\nTrie.Hits: 6\nTrie.Misses: 7\nTrie.Reloads: 8\nTrie.Retries: 9\nHeap.Allocs: 10\nHeap.Frees: 11\nGC.PauseTotalNs: 12\n",
+			want: "Pool.Creations: 1\nPool.Hits: 13\nPool.Returns: 2\nPool.Size: 3\nTrie.Nodes: 4\nTrie.Patterns: 5\nTrie.Hits: 6\nTrie.Misses: 7\nTrie.Reloads: 8\nTrie.Retries: 9\nTrie.SwapNs: 14\nTrie.MaxSwapNs: 15\nHeap.Allocs: 10\nHeap.Frees: 11\nGC.PauseTotalNs: 12\n",
 		},
 		// TODO: Add test cases.
 	}
@@ -151,6 +162,7 @@ func Test_TMetrics_clone(t *testing.T) {
 			name: "03 - non-empty",
 			m: &TMetrics{
 				PoolCreations:  1,
+				PoolHits:       13,
 				PoolReturns:    2,
 				PoolSize:       3,
 				Nodes:          4,
@@ -165,6 +177,7 @@ func Test_TMetrics_clone(t *testing.T) {
 			},
 			want: &TMetrics{
 				PoolCreations:  1,
+				PoolHits:       13,
 				PoolReturns:    2,
 				PoolSize:       3,
 				Nodes:          4,
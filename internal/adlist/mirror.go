@@ -0,0 +1,91 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+//lint:file-ignore ST1005 - I like capitalisation
+
+type (
+	// `tMirrorMeta` describes the local copy of a downloaded list.
+	//
+	// It's stored next to the copy and used to revalidate it with
+	// a conditional request instead of downloading it again.
+	tMirrorMeta struct {
+		URL          string    `json:"url"`
+		ETag         string    `json:"etag,omitempty"`
+		LastModified string    `json:"lastModified,omitempty"`
+		Fetched      time.Time `json:"fetched"`
+	}
+)
+
+const (
+	// `mirrorExt` is appended to a local copy's filename to get the
+	// filename of its metadata.
+	mirrorExt = ".meta"
+)
+
+var (
+	// `errNotModified` is returned by conditional requests if the
+	// local copy is still up to date.
+	errNotModified = ADlistError{errors.New("Not modified")}
+)
+
+// ---------------------------------------------------------------------------
+// `tMirrorMeta` constructor:
+
+// `readMirrorMeta()` reads the metadata of a local copy.
+//
+// Parameters:
+//   - `aFilename`: The path/name of the local copy.
+//   - `aURL`: The URL the copy has to be downloaded from.
+//
+// Returns:
+//   - `*tMirrorMeta`: The metadata (`nil` if there's no usable copy of `aURL`).
+func readMirrorMeta(aFilename, aURL string) *tMirrorMeta {
+	if _, err := os.Stat(aFilename); nil != err {
+		return nil
+	}
+	data, err := os.ReadFile(aFilename + mirrorExt) //#nosec G304
+	if nil != err {
+		return nil
+	}
+
+	var result tMirrorMeta
+	if err = json.Unmarshal(data, &result); (nil != err) || (aURL != result.URL) {
+		return nil
+	}
+
+	return &result
+} // readMirrorMeta()
+
+// ---------------------------------------------------------------------------
+// `tMirrorMeta` methods:
+
+// `write()` stores the metadata next to the local copy.
+//
+// Parameters:
+//   - `aFilename`: The path/name of the local copy.
+//
+// Returns:
+//   - `error`: `nil` if the metadata were written, the error otherwise.
+func (m *tMirrorMeta) write(aFilename string) error {
+	data, err := json.Marshal(m)
+	if nil != err {
+		return err
+	}
+
+	return os.WriteFile(aFilename+mirrorExt, data, 0600)
+} // write()
+
+/* _EoF_ */
@@ -0,0 +1,135 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_readMirrorMeta(t *testing.T) {
+	dir := t.TempDir()
+	fName := filepath.Join(dir, "list.down")
+	if err := os.WriteFile(fName, []byte("tracker.localdomain\n"), 0600); nil != err {
+		t.Fatal(err)
+	}
+	meta := &tMirrorMeta{URL: "https://lists.localdomain/deny", ETag: `"v1"`}
+	if err := meta.write(fName); nil != err {
+		t.Fatalf("write() error = '%v'", err)
+	}
+
+	tests := []struct {
+		name     string
+		filename string
+		url      string
+		wantETag string
+		wantNil  bool
+	}{
+		/* */
+		{
+			name:     "01 - matching copy",
+			filename: fName,
+			url:      meta.URL,
+			wantETag: meta.ETag,
+		},
+		{
+			name:     "02 - other URL",
+			filename: fName,
+			url:      "https://lists.localdomain/other",
+			wantNil:  true,
+		},
+		{
+			name:     "03 - missing copy",
+			filename: filepath.Join(dir, "missing.down"),
+			url:      meta.URL,
+			wantNil:  true,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := readMirrorMeta(tc.filename, tc.url)
+			if (nil == got) != tc.wantNil {
+				t.Fatalf("readMirrorMeta() = '%v', wantNil '%v'", got, tc.wantNil)
+			}
+			if (nil != got) && (got.ETag != tc.wantETag) {
+				t.Errorf("readMirrorMeta() ETag = '%s', want '%s'", got.ETag, tc.wantETag)
+			}
+		})
+	}
+} // Test_readMirrorMeta()
+
+func Test_downloadFile_mirror(t *testing.T) {
+	if err := SetDownloadOptions(TDownloadOptions{Retries: -1}); nil != err {
+		t.Fatal(err)
+	}
+	defer adDownloader.Store(nil)
+
+	var downloads, revalidations atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(aWriter http.ResponseWriter, aRequest *http.Request) {
+		if `"v1"` == aRequest.Header.Get("If-None-Match") {
+			revalidations.Add(1)
+			aWriter.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads.Add(1)
+		aWriter.Header().Set("ETag", `"v1"`)
+		_, _ = aWriter.Write([]byte("tracker.localdomain\n"))
+	}))
+	uri := server.URL + "/deny"
+	fName := filepath.Join(t.TempDir(), "deny.down")
+	ctx := context.TODO()
+
+	// The first download stores the copy and its metadata …
+	if got, err := downloadFile(ctx, uri, fName); (nil != err) || (got != fName) {
+		t.Fatalf("downloadFile() = '%s', '%v', want '%s'", got, err, fName)
+	}
+	if meta := readMirrorMeta(fName, uri); (nil == meta) || (`"v1"` != meta.ETag) {
+		t.Fatalf("readMirrorMeta() = '%v', want ETag '\"v1\"'", meta)
+	}
+
+	// … which is revalidated instead of downloaded again …
+	if got, err := downloadFile(ctx, uri, fName); (nil != err) || (got != fName) {
+		t.Fatalf("downloadFile(cached) = '%s', '%v', want '%s'", got, err, fName)
+	}
+	if (1 != downloads.Load()) || (1 != revalidations.Load()) {
+		t.Errorf("downloadFile(cached) downloads = '%d', revalidations = '%d', want '1', '1'",
+			downloads.Load(), revalidations.Load())
+	}
+
+	// … and used while the server is unreachable …
+	server.Close()
+	if got, err := downloadFile(ctx, uri, fName); (nil != err) || (got != fName) {
+		t.Errorf("downloadFile(offline) = '%s', '%v', want '%s'", got, err, fName)
+	}
+	data, err := os.ReadFile(fName) //#nosec G304
+	if (nil != err) || ("tracker.localdomain\n" != string(data)) {
+		t.Errorf("downloadFile(offline) data = '%s', '%v'", data, err)
+	}
+
+	// … as well as in offline mode
+	SetOffline(true)
+	if got, err := downloadFile(ctx, uri, fName); (nil != err) || (got != fName) {
+		t.Errorf("downloadFile(offline mode) = '%s', '%v', want '%s'", got, err, fName)
+	}
+	SetOffline(false)
+
+	// Other URLs don't get the copy
+	if _, err = downloadFile(ctx, server.URL+"/other", fName); nil == err {
+		t.Error("downloadFile(other) error = 'nil', want error")
+	}
+} // Test_downloadFile_mirror()
+
+/* _EoF_ */
@@ -11,9 +11,14 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"slices"
-	"sort"
 	"strings"
+	"unicode/utf8"
+	"unique"
+	"unsafe"
+
+	ch "github.com/mwat56/dnscache/internal/children"
 )
 
 //lint:file-ignore ST1017 - I prefer Yoda conditions
@@ -24,16 +29,25 @@ const (
 
 	// `wildMask` is the bit mask to use for marking a node as a wildcard node.
 	wildMask = 3 // 00000011
+
+	// `subMask` is the bit mask to use for marking a wildcard node
+	// just implied by its parent's hostname (see `addSubdomains()`).
+	subMask = 12 // 00001100
+
+	// `maxHostLen` is the maximum length of a hostname lowercased
+	// on the stack by `lowerHost()`.
+	maxHostLen = 255
 )
 
 type (
-	// `tChildren` is a map of children nodes.
-	tChildren map[string]*tNode
+	// `tChildren` holds the children nodes of a node.
+	tChildren = ch.TChildren[*tNode]
 
 	// `tNode` represents a node in the trie.
 	//
 	// The node is a leaf node if `terminator` has the `endMask` bit set and
 	// it's a wildcard node if `terminator` has the `wildMask` bit set.
+	// An implied wildcard node has the `subMask` bit set as well.
 	tNode struct {
 		tChildren        // children nodes
 		terminator uint8 // flags for pattern end and wildcard
@@ -49,6 +63,84 @@ var (
 // ---------------------------------------------------------------------------
 // Helper function:
 
+// `lowerHost()` returns the lower case version of the given hostname.
+//
+// Other than `strings.ToLower()` this function doesn't allocate any
+// memory for ASCII hostnames: names already in lower case are
+// returned as is, others are lowercased into the given buffer.
+// The returned string may hence share the buffer's memory and must
+// not be used after the buffer gets reused or goes out of scope.
+//
+// Parameters:
+//   - `aHost`: The hostname to lowercase.
+//   - `aBuf`: The buffer to use for the conversion.
+//
+// Returns:
+//   - `string`: The lower case hostname.
+func lowerHost(aHost string, aBuf *[maxHostLen]byte) string {
+	upper := false
+	for idx := 0; idx < len(aHost); idx++ {
+		c := aHost[idx]
+		if utf8.RuneSelf <= c {
+			// Leave non-ASCII names to the standard library
+			return strings.ToLower(aHost)
+		}
+		upper = upper || (('A' <= c) && ('Z' >= c))
+	}
+	if !upper {
+		return aHost
+	}
+	if len(aBuf) < len(aHost) {
+		return strings.ToLower(aHost)
+	}
+
+	for idx := 0; idx < len(aHost); idx++ {
+		c := aHost[idx]
+		if ('A' <= c) && ('Z' >= c) {
+			c += 'a' - 'A'
+		}
+		aBuf[idx] = c
+	}
+
+	return unsafe.String(&aBuf[0], len(aHost)) //#nosec G103
+} // lowerHost()
+
+// `internLabel()` returns the canonical copy of a label.
+//
+// Millions of patterns repeat the same few labels (e.g. "com", "www",
+// or "ads"); storing the canonical copy as child key lets all nodes
+// share its memory. Besides, the key doesn't keep the whole pattern
+// string it was cut from alive.
+//
+// Parameters:
+//   - `aLabel`: The label to intern.
+//
+// Returns:
+//   - `string`: The canonical copy of the label.
+func internLabel(aLabel string) string {
+	return unique.Make(aLabel).Value()
+} // internLabel()
+
+// `joinTerminators()` returns the combined flags of two nodes.
+//
+// An implied wildcard stays implied only if neither node is an
+// ordinary pattern.
+//
+// Parameters:
+//   - `aOld`: The flags of the node to merge into.
+//   - `aNew`: The flags of the node to merge.
+//
+// Returns:
+//   - `uint8`: The combined flags.
+func joinTerminators(aOld, aNew uint8) uint8 {
+	if ((0 != aOld) && (0 == (aOld & subMask))) ||
+		((0 != aNew) && (0 == (aNew & subMask))) {
+		return (aOld | aNew) &^ subMask
+	}
+
+	return aOld | aNew
+} // joinTerminators()
+
 // `pattern2parts()` converts a hostname pattern to a reversed list of parts.
 //
 // The pattern is expected to be a valid FQDN or wildcard pattern, and it's
@@ -71,6 +163,32 @@ func pattern2parts(aPattern string) tPartsList {
 	return parts
 } // pattern2parts()
 
+// `hostLabel()` returns the hostname's last label ending at `aEnd`.
+//
+// Walking a hostname's labels from its end this way is the
+// allocation-free equivalent of iterating over the list returned
+// by `pattern2parts()`:
+//
+//	for end := len(aHost); ; end = start - 1 {
+//		label, start = hostLabel(aHost, end)
+//		…
+//		if 0 == start { break } // this was the first label
+//	}
+//
+// Parameters:
+//   - `aHost`: The (lower case) hostname to split.
+//   - `aEnd`: The index after the label's last character.
+//
+// Returns:
+//   - `rLabel`: The label ending at `aEnd`.
+//   - `rStart`: The index of the label's first character.
+func hostLabel(aHost string, aEnd int) (rLabel string, rStart int) {
+	rStart = strings.LastIndexByte(aHost[:aEnd], '.') + 1
+	rLabel = aHost[rStart:aEnd]
+
+	return
+} // hostLabel()
+
 // ---------------------------------------------------------------------------
 // `tNode` methods:
 
@@ -93,8 +211,9 @@ func (n *tNode) add(aCtx context.Context, aPartsList tPartsList) (rOK bool) {
 	}
 	var (
 		depth, added, ends int
-		isEnd, isWild, ok  bool
+		isWild, ok         bool
 		label              string
+		child              *tNode
 	)
 
 	node := n
@@ -103,22 +222,24 @@ func (n *tNode) add(aCtx context.Context, aPartsList tPartsList) (rOK bool) {
 		if nil != aCtx.Err() {
 			return
 		}
-		if _, ok = node.tChildren[label]; !ok {
-			node.tChildren[label] = newNode()
+		if child, ok = node.tChildren.Get(label); !ok {
+			child = newNode()
+			node.tChildren.Put(internLabel(label), child)
 			added++
 		}
 
 		// Descend into the child node
-		node = node.tChildren[label]
-		if isWild = ("*" == label); isWild {
-			node.terminator = wildMask
-			ends++
-		}
+		node = child
 		if (len(aPartsList) - 1) == depth {
-			if isEnd = (!isWild); isEnd {
+			// Only a leading wildcard marks its node while a
+			// wildcard inside the pattern is just another label.
+			if isWild = ("*" == label); isWild {
+				// An explicit wildcard isn't implied anymore
+				node.terminator = (node.terminator &^ subMask) | wildMask
+			} else {
 				node.terminator |= endMask
-				ends++
 			}
+			ends++
 		}
 	} // for parts
 	rOK = (0 < added) || (0 < ends)
@@ -126,36 +247,24 @@ func (n *tNode) add(aCtx context.Context, aPartsList tPartsList) (rOK bool) {
 	return
 } // add()
 
-// `allPatterns()` collects all hostname patterns in the node's tree.
+// `addSubdomains()` adds a leading wildcard for each hostname of the
+// node's tree, i.e. `*.domain.tld` for `domain.tld`, so that the
+// subdomains of a bare domain match as well.
 //
-// The patterns are returned in sorted order.
-//
-// The method uses a stack to traverse the tree in a depth-first manner,
-// which is more efficient than a recursive approach.
+// Patterns containing a wildcard are left alone. The added wildcards
+// are marked as implied (see `implied()`), so they match like
+// ordinary wildcards but are neither listed, counted, nor stored.
 //
 // Parameters:
 //   - `aCtx`: The timeout context to use for the operation.
 //
 // Returns:
-//   - `rList`: A list of all patterns in the node's tree.
-func (n *tNode) allPatterns(aCtx context.Context) (rList tPartsList) {
+//   - `rAdded`: The number of wildcards added.
+func (n *tNode) addSubdomains(aCtx context.Context) (rAdded int) {
 	if nil == n {
 		return
 	}
-	type (
-		tStackEntry struct {
-			node *tNode     // respective node to process
-			path tPartsList // path in the trie to the node
-		}
-	)
-	var (
-		cLen     int
-		kidNames tPartsList
-	)
-	stack := []tStackEntry{
-		// Push the current node to the stack
-		{node: n, path: tPartsList{}},
-	}
+	stack := []*tNode{n}
 
 	for 0 < len(stack) {
 		// Check for timeout or cancellation
@@ -164,59 +273,71 @@ func (n *tNode) allPatterns(aCtx context.Context) (rList tPartsList) {
 		}
 
 		// Pop the top of the stack
-		current := stack[len(stack)-1]
-		// Remove the top of the stack
+		node := stack[len(stack)-1]
 		stack = stack[:len(stack)-1]
 
-		// Check if current node is a terminal pattern
-		if 0 != current.node.terminator { // either end or wildcard bits
-			// Reverse the path to get the original FQDN
-			// in original order.
-			if pLen := len(current.path); 0 < pLen {
-				reversed := make(tPartsList, len(current.path))
-				for idx, label := range current.path {
-					reversed[len(current.path)-1-idx] = label
-				}
-				rList = append(rList, strings.Join(reversed, "."))
+		for label, child := range node.tChildren.All() {
+			if "*" != label {
+				stack = append(stack, child)
 			}
 		}
-
-		if cLen = len(current.node.tChildren); 0 == cLen {
+		if (node == n) || ((node.terminator & endMask) != endMask) {
 			continue
 		}
-
-		// Collect and sort children keys for deterministic order
-		kidNames = make(tPartsList, 0, cLen)
-		for label := range current.node.tChildren {
-			kidNames = append(kidNames, label)
+		wild, ok := node.tChildren.Get("*")
+		if !ok {
+			wild = newNode()
+			node.tChildren.Put(internLabel("*"), wild)
 		}
-		if 1 < len(kidNames) {
-			sort.Strings(kidNames)
+		if (wild.terminator & wildMask) != wildMask {
+			wild.terminator |= wildMask | subMask
+			rAdded++
 		}
+	}
 
-		// Check for timeout or cancellation
-		if nil != aCtx.Err() {
-			return
-		}
+	return
+} // addSubdomains()
 
-		// Push children to stack in reverse-sorted order
-		// (to process them in forward order when popped)
-		for i := len(kidNames) - 1; 0 <= i; i-- {
-			label := kidNames[i]
-			child := current.node.tChildren[label]
-			newPath := make(tPartsList, len(current.path)+1)
-			copy(newPath, current.path)
-			newPath[len(current.path)] = label
-			stack = append(stack, tStackEntry{
-				node: child,
-				path: newPath,
-			})
-		}
-	} // for stack
+// `allPatterns()` collects all hostname patterns in the node's tree.
+//
+// The patterns are returned in sorted order (see `patterns()`).
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//
+// Returns:
+//   - `rList`: A list of all patterns in the node's tree.
+func (n *tNode) allPatterns(aCtx context.Context) (rList tPartsList) {
+	for pattern := range n.patterns(aCtx) {
+		rList = append(rList, pattern)
+	}
 
 	return
 } // allPatterns()
 
+// `clone()` returns a deep copy of the node's tree.
+//
+// The method is not thread-safe in itself but expects to be RLocked
+// by the calling `tTrie` instance.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//
+// Returns:
+//   - `*tNode`: The copy, `nil` in case of cancellation.
+func (n *tNode) clone(aCtx context.Context) *tNode {
+	if nil == n {
+		return nil
+	}
+
+	result := newNode().merge(aCtx, n)
+	if nil != aCtx.Err() {
+		return nil // incomplete copy
+	}
+
+	return result
+} // clone()
+
 // `count()` returns the number of nodes and patterns in the node's tree.
 //
 // Parameters:
@@ -249,11 +370,11 @@ func (n *tNode) count(aCtx context.Context) (rNodes, rPatterns int) {
 		stack = stack[1:]
 
 		rNodes++
-		if 0 != node.terminator {
+		if (0 != node.terminator) && !node.implied() {
 			// With either end or wildcard bits it's a complete pattern
 			rPatterns++
 		}
-		if 0 == len(node.tChildren) {
+		if 0 == node.tChildren.Size() {
 			if (0 < rNodes) && (0 == dec) {
 				// Un-count the node without children
 				rNodes--
@@ -262,7 +383,7 @@ func (n *tNode) count(aCtx context.Context) (rNodes, rPatterns int) {
 			continue
 		}
 
-		for _, child = range node.tChildren {
+		for _, child = range node.tChildren.All() {
 			stack = append(stack, child)
 		}
 	}
@@ -270,6 +391,43 @@ func (n *tNode) count(aCtx context.Context) (rNodes, rPatterns int) {
 	return
 } // count()
 
+// `contains()` checks whether the given pattern itself is part of the
+// node's tree.
+//
+// Other than `match()` the pattern's labels are compared literally,
+// i.e. `*.example.com` is contained only if that wildcard pattern
+// was added, not if just `example.com` or `a.example.com` were.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//   - `aPartsList`: The list of parts of the pattern to check.
+//
+// Returns:
+//   - `bool`: `true` if the pattern is in the node's tree, `false` otherwise.
+func (n *tNode) contains(aCtx context.Context, aPartsList tPartsList) bool {
+	if (nil == n) || (0 == len(aPartsList)) {
+		return false
+	}
+
+	current := n
+	for _, label := range aPartsList {
+		// Check for timeout or cancellation
+		if nil != aCtx.Err() {
+			return false
+		}
+		child, ok := current.tChildren.Get(label)
+		if !ok {
+			return false
+		}
+		current = child
+	}
+	if "*" == aPartsList[len(aPartsList)-1] {
+		return ((current.terminator & wildMask) == wildMask) && !current.implied()
+	}
+
+	return ((current.terminator & endMask) == endMask)
+} // contains()
+
 // `delete()` removes path patterns from the node's tree.
 //
 // The method returns `true` if at least one node is deleted, `false`
@@ -301,7 +459,7 @@ func (n *tNode) delete(aCtx context.Context, aPartsList tPartsList) (rOK bool) {
 
 	// Traverse and build up the stack
 	for _, part := range aPartsList {
-		child, ok := current.tChildren[part]
+		child, ok := current.tChildren.Get(part)
 		if !ok {
 			// Pattern does not exist; nothing to delete
 			return
@@ -312,15 +470,24 @@ func (n *tNode) delete(aCtx context.Context, aPartsList tPartsList) (rOK bool) {
 
 	// Unset terminal markers at the end node
 	current.terminator = 0
+	if wild, ok := current.tChildren.Get("*"); ok && wild.implied() {
+		// The hostname's implied wildcard goes with it
+		if 0 == wild.tChildren.Size() {
+			putNode(wild)
+			current.tChildren.Remove("*")
+		} else {
+			wild.terminator = 0
+		}
+	}
 
 	// Backtrack and prune
 	for idx := len(stack) - 1; 0 <= idx; idx-- {
 		parent := stack[idx].node
 		label := stack[idx].label
-		child := parent.tChildren[label]
+		child, _ := parent.tChildren.Get(label)
 
 		// If child has children stop pruning
-		if 0 < len(child.tChildren) {
+		if 0 < child.tChildren.Size() {
 			break
 		}
 
@@ -331,8 +498,8 @@ func (n *tNode) delete(aCtx context.Context, aPartsList tPartsList) (rOK bool) {
 
 		// Safe to delete this child
 		putNode(child) // Return the child to the pool
-		delete(parent.tChildren, label)
-		if 0 == len(parent.tChildren) {
+		parent.tChildren.Remove(label)
+		if 0 == parent.tChildren.Size() {
 			if isWild = ("*" == label); isWild {
 				parent.terminator = wildMask
 			}
@@ -370,12 +537,12 @@ func (n *tNode) Equal(aNode *tNode) (rOK bool) {
 	if n.terminator != aNode.terminator {
 		return
 	}
-	if len(n.tChildren) != len(aNode.tChildren) {
+	if n.tChildren.Size() != aNode.tChildren.Size() {
 		return
 	}
 
-	for label, child := range n.tChildren {
-		otherChild, ok := aNode.tChildren[label]
+	for label, child := range n.tChildren.All() {
+		otherChild, ok := aNode.tChildren.Get(label)
 		if !ok {
 			return
 		}
@@ -417,10 +584,10 @@ func (n *tNode) finalNode(aCtx context.Context, aPartsList tPartsList) (rNode *t
 		}
 
 		// Check for a child with the next label
-		if child, ok = current.tChildren[label]; !ok {
+		if child, ok = current.tChildren.Get(label); !ok {
 			// No child with that name, so check for a wildcard
 			// match at the current level
-			if child, ok = current.tChildren["*"]; ok {
+			if child, ok = current.tChildren.Get("*"); ok {
 				if rOK = (0 != child.terminator); rOK {
 					rNode = child
 				}
@@ -432,7 +599,9 @@ func (n *tNode) finalNode(aCtx context.Context, aPartsList tPartsList) (rNode *t
 		// Descend into the child node
 		current = child
 		if depth < len(aPartsList)-1 {
-			if child, ok = current.tChildren["*"]; !ok {
+			if child, ok = current.tChildren.Get("*"); !ok ||
+				((child.terminator & wildMask) != wildMask) {
+				// No leading wildcard at this level
 				continue
 			}
 
@@ -444,7 +613,7 @@ func (n *tNode) finalNode(aCtx context.Context, aPartsList tPartsList) (rNode *t
 			rNode = child
 
 			// Check whether there's also a literal match:
-			if child, ok = current.tChildren[aPartsList[depth+1]]; ok {
+			if child, ok = current.tChildren.Get(aPartsList[depth+1]); ok {
 				// Don't change `rOK` because we already have
 				// a valid (wildcard) match.
 				if ok = ((child.terminator & endMask) == endMask); ok {
@@ -502,19 +671,11 @@ func (n *tNode) forEach(aCtx context.Context, aFunc func(aNode *tNode)) {
 
 		aFunc(entry.node)
 
-		// Collect and sort children kidNames for deterministic order
-		cLen := len(entry.node.tChildren)
-		if 0 == cLen {
+		// Collect the sorted children kidNames for deterministic order
+		if 0 == entry.node.tChildren.Size() {
 			continue
 		}
-
-		kidNames := make(tPartsList, 0, cLen)
-		for label := range entry.node.tChildren {
-			kidNames = append(kidNames, label)
-		}
-		if 1 < len(kidNames) {
-			sort.Strings(kidNames)
-		}
+		kidNames := entry.node.tChildren.Labels()
 
 		// Check for timeout or cancellation
 		if nil != aCtx.Err() {
@@ -524,15 +685,27 @@ func (n *tNode) forEach(aCtx context.Context, aFunc func(aNode *tNode)) {
 		// Push children to stack in reverse-sorted order
 		// (to process them in forward order when popped)
 		for idx := len(kidNames) - 1; 0 <= idx; idx-- {
+			child, _ := entry.node.tChildren.Get(kidNames[idx])
 			stack = append(stack, tStackEntry{
-				node: entry.node.tChildren[kidNames[idx]],
+				node: child,
 			})
 		}
 	}
 } // forEach()
 
+// `implied()` checks whether the node is a wildcard just implied by
+// its parent's hostname (see `addSubdomains()`).
+//
+// Returns:
+//   - `bool`: `true` if the node is an implied wildcard, `false` otherwise.
+func (n *tNode) implied() bool {
+	return (wildMask | subMask) == n.terminator
+} // implied()
+
 // `match()` checks whether the node's tree contains the given pattern.
 //
+// The parts are joined to a hostname which is checked by `matchHost()`.
+//
 // Parameters:
 //   - `aCtx`: The timeout context to use for the operation.
 //   - `aPartsList`: The list of parts of the pattern to check.
@@ -544,10 +717,94 @@ func (n *tNode) match(aCtx context.Context, aPartsList tPartsList) (rOK bool) {
 		return
 	}
 
-	_, rOK = n.finalNode(aCtx, aPartsList)
+	labels := slices.Clone(aPartsList)
+	slices.Reverse(labels)
+	rOK = n.matchHost(aCtx, strings.Join(labels, "."))
+
 	return
 } // match()
 
+// `matchFrom()` checks whether the node's tree contains the hostname's
+// labels up to `aEnd`.
+//
+// The labels are matched from the top-level domain downwards. At
+// each level a literal label is tried first, then a leading wildcard
+// covering all the remaining labels, and finally a wildcard standing
+// for just the current label. If a path fails further down, the next
+// alternative is tried, so the first pattern found is the most
+// specific one.
+//
+// Parameters:
+//   - `aHost`: The lower case hostname to check.
+//   - `aEnd`: The index after the hostname's last label to check.
+//   - `aPath`: If not `nil`, the labels of the matching pattern are
+//     appended to it (in the pattern's order).
+//
+// Returns:
+//   - `bool`: `true` if the labels are in the node's tree, `false` otherwise.
+func (n *tNode) matchFrom(aHost string, aEnd int, aPath *tPartsList) bool {
+	label, start := hostLabel(aHost, aEnd)
+	found := false
+	if child, ok := n.tChildren.Get(label); ok {
+		if 0 == start {
+			found = (0 != child.terminator)
+		} else {
+			found = child.matchFrom(aHost, start-1, aPath)
+		}
+	}
+	if !found {
+		child, ok := n.tChildren.Get("*")
+		if !ok {
+			return false
+		}
+		if (child.terminator & wildMask) != wildMask {
+			// A wildcard inside a pattern stands for one label
+			if (0 == start) || !child.matchFrom(aHost, start-1, aPath) {
+				return false
+			}
+		} else if child.implied() {
+			// The hostname implying the wildcard is the pattern
+			return true
+		}
+		label = "*"
+	}
+	if nil != aPath {
+		// Copy the label lest the hostname escapes to the heap
+		*aPath = append(*aPath, strings.Clone(label))
+	}
+
+	return true
+} // matchFrom()
+
+// `matchHost()` checks whether the node's tree contains the given
+// hostname.
+//
+// The method walks the hostname's labels in place instead of splitting
+// it, hence it doesn't allocate any memory.
+//
+// The method is not thread-safe in itself but expects to be RLocked
+// by the calling `tTrie` instance.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//   - `aHost`: The lower case hostname to check.
+//
+// Returns:
+//   - `rOK`: `true` if the hostname is in the node's tree, `false` otherwise.
+func (n *tNode) matchHost(aCtx context.Context, aHost string) (rOK bool) {
+	if (nil == n) || (0 == len(aHost)) {
+		return
+	}
+
+	// Check for timeout or cancellation
+	if nil != aCtx.Err() {
+		return
+	}
+	rOK = n.matchFrom(aHost, len(aHost), nil)
+
+	return
+} // matchHost()
+
 // `merge()` merges the subtree of `aSrc` into the current node.
 //
 // Parameters:
@@ -583,32 +840,24 @@ func (n *tNode) merge(aCtx context.Context, aSrc *tNode) *tNode {
 
 		// Merge terminal flags using OR
 		if 0 != entry.srcNode.terminator {
-			entry.destNode.terminator |= entry.srcNode.terminator
+			entry.destNode.terminator = joinTerminators(
+				entry.destNode.terminator, entry.srcNode.terminator)
 		}
 
-		// Collect and sort children keys for deterministic order
-		cLen := len(entry.srcNode.tChildren)
-		if 0 == cLen {
+		// Collect the sorted children keys for deterministic order
+		if 0 == entry.srcNode.tChildren.Size() {
 			continue
 		}
 
-		kidNames := make(tPartsList, 0, cLen)
-		for label = range entry.srcNode.tChildren {
-			kidNames = append(kidNames, label)
-		}
-		if 1 < len(kidNames) {
-			sort.Strings(kidNames)
-		}
-
-		for _, label = range kidNames {
-			srcChild := entry.srcNode.tChildren[label]
-			destChild, exists := entry.destNode.tChildren[label]
+		for _, label = range entry.srcNode.tChildren.Labels() {
+			srcChild, _ := entry.srcNode.tChildren.Get(label)
+			destChild, exists := entry.destNode.tChildren.Get(label)
 
 			if !exists {
 				// Create new destination child
 				destChild = newNode()
 				destChild.terminator = srcChild.terminator
-				entry.destNode.tChildren[label] = destChild
+				entry.destNode.tChildren.Put(label, destChild)
 			}
 
 			// Push to stack for deeper merge
@@ -619,6 +868,80 @@ func (n *tNode) merge(aCtx context.Context, aSrc *tNode) *tNode {
 	return n
 } // merge()
 
+// `patterns()` returns an iterator over all hostname patterns in the
+// node's tree.
+//
+// The patterns are produced lazily in sorted order, so walking a huge
+// tree doesn't need memory for all its patterns at once. The walk
+// stops when the loop body breaks or the context is done.
+//
+// The method uses a stack to traverse the tree in a depth-first manner,
+// which is more efficient than a recursive approach. It's not
+// thread-safe in itself but expects the calling `tTrie` instance to
+// keep it RLocked during the iteration.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//
+// Returns:
+//   - `iter.Seq[string]`: The iterator over the patterns.
+func (n *tNode) patterns(aCtx context.Context) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		if nil == n {
+			return
+		}
+		type (
+			tStackEntry struct {
+				node *tNode     // respective node to process
+				path tPartsList // path in the trie to the node
+			}
+		)
+		stack := []tStackEntry{{node: n, path: tPartsList{}}}
+
+		for 0 < len(stack) {
+			// Check for timeout or cancellation
+			if nil != aCtx.Err() {
+				return
+			}
+
+			// Pop the top of the stack
+			current := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			// Check if current node is a terminal pattern
+			if pLen := len(current.path); (0 != current.node.terminator) &&
+				!current.node.implied() && (0 < pLen) {
+				// Reverse the path to get the original FQDN
+				reversed := make(tPartsList, pLen)
+				for idx, label := range current.path {
+					reversed[pLen-1-idx] = label
+				}
+				if !yield(strings.Join(reversed, ".")) {
+					return
+				}
+			}
+			if 0 == current.node.tChildren.Size() {
+				continue
+			}
+
+			// Push children to stack in reverse-sorted order
+			// (to process them in forward order when popped)
+			kidNames := current.node.tChildren.Labels()
+			for idx := len(kidNames) - 1; 0 <= idx; idx-- {
+				label := kidNames[idx]
+				child, _ := current.node.tChildren.Get(label)
+				newPath := make(tPartsList, len(current.path)+1)
+				copy(newPath, current.path)
+				newPath[len(current.path)] = label
+				stack = append(stack, tStackEntry{
+					node: child,
+					path: newPath,
+				})
+			}
+		}
+	}
+} // patterns()
+
 // `store()` writes all patterns currently in the node to the writer,
 // one hostname pattern per line.
 //
@@ -642,84 +965,42 @@ func (n *tNode) merge(aCtx context.Context, aSrc *tNode) *tNode {
 // Returns:
 //   - `error`: `nil` if the patterns were written successfully, the error otherwise.
 func (n *tNode) store(aCtx context.Context, aWriter io.Writer) error {
+	return n.storeFunc(aCtx, aWriter, nil)
+} // store()
+
+// `storeFunc()` writes all patterns currently in the node to the
+// writer, one line per pattern as formatted by `aFormat`.
+//
+// Like `store()` the method expects to be RLocked by the calling
+// `tTrie` instance.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//   - `aWriter`: The writer to write the patterns to.
+//   - `aFormat`: The function returning a pattern's line (`nil`
+//     writes the pattern itself).
+//
+// Returns:
+//   - `error`: `nil` if the patterns were written successfully, the error otherwise.
+func (n *tNode) storeFunc(aCtx context.Context, aWriter io.Writer, aFormat func(string) string) error {
 	if (nil == n) || (nil == aWriter) {
 		return ErrNodeNil
 	}
-	type (
-		tStackEntry struct {
-			path tPartsList
-			node *tNode
-		}
-	)
-
-	stack := []tStackEntry{
-		{
-			path: tPartsList{},
-			node: n,
-		},
-	}
-	for 0 < len(stack) {
-		// Pop from stack
-		entry := stack[len(stack)-1]
-		// Remove current entry from stack
-		stack = stack[:len(stack)-1]
-
-		// Check for timeout or cancellation
-		if err := aCtx.Err(); nil != err {
-			return err
-		}
-
-		// Process current node
-		if 0 != entry.node.terminator { // either end or wildcard bits
-			// Reverse path to original FQDN format
-			pLen := len(entry.path)
-			reversed := make(tPartsList, pLen)
-			for idx, part := range entry.path {
-				reversed[pLen-1-idx] = part
-			}
-			fqdn := strings.Join(reversed, ".")
-
-			// Write to writer with newline
-			if _, err := fmt.Fprintln(aWriter, fqdn); nil != err {
-				return err
-			}
-		}
-		cLen := len(entry.node.tChildren)
-		if 0 == cLen {
-			continue
-		}
 
-		// Collect and sort children kidNames
-		kidNames := make(tPartsList, 0, cLen)
-		for label := range entry.node.tChildren {
-			kidNames = append(kidNames, label)
-		}
-		if 1 < len(kidNames) {
-			sort.Strings(kidNames)
+	for fqdn := range n.patterns(aCtx) {
+		if nil != aFormat {
+			fqdn = aFormat(fqdn)
 		}
 
-		// Check for timeout or cancellation
-		if err := aCtx.Err(); nil != err {
+		// Write to writer with newline
+		if _, err := fmt.Fprintln(aWriter, fqdn); nil != err {
 			return err
 		}
-
-		// Push children in reverse-sorted order for
-		// correct processing sequence
-		for idx := len(kidNames) - 1; 0 <= idx; idx-- {
-			label := kidNames[idx]
-			newPath := make(tPartsList, len(entry.path)+1)
-			copy(newPath, entry.path)
-			newPath[len(entry.path)] = label
-
-			stack = append(stack, tStackEntry{
-				node: entry.node.tChildren[label],
-				path: newPath,
-			})
-		}
 	}
 
-	return nil
-} // store()
+	// Check for timeout or cancellation
+	return aCtx.Err()
+} // storeFunc()
 
 // `string()` returns a string representation of the node.
 //
@@ -772,13 +1053,7 @@ func (n *tNode) string(aLabel string) string {
 			builder.WriteString(line)
 
 			// Prepare sorted child keys
-			entry.kidNames = make(tPartsList, 0, len(entry.node.tChildren))
-			for label := range entry.node.tChildren {
-				entry.kidNames = append(entry.kidNames, label)
-			}
-			if 1 < len(entry.kidNames) {
-				sort.Strings(entry.kidNames)
-			}
+			entry.kidNames = entry.node.tChildren.Labels()
 		}
 
 		// If there are unprocessed children, process the next one
@@ -787,7 +1062,7 @@ func (n *tNode) string(aLabel string) string {
 			builder.WriteString(indent)
 
 			label := entry.kidNames[entry.childIdx]
-			child := entry.node.tChildren[label]
+			child, _ := entry.node.tChildren.Get(label)
 			entry.childIdx++
 
 			// Push the child node to the stack
@@ -9,8 +9,12 @@ package adlist
 import (
 	"bytes"
 	"context"
+	"slices"
 	"strings"
 	"testing"
+	"unsafe"
+
+	ch "github.com/mwat56/dnscache/internal/children"
 )
 
 //lint:file-ignore ST1017 - I prefer Yoda conditions
@@ -106,13 +110,15 @@ func Test_tNode_Equal(t *testing.T) {
 			node: func() *tNode {
 				n := newNode()
 				n.add(context.TODO(), tPartsList{"tld"})
-				n.tChildren["tld"].terminator = endMask
+				child, _ := n.tChildren.Get("tld")
+				child.terminator = endMask
 				return n
 			}(),
 			other: func() *tNode {
 				n := newNode()
 				n.add(context.TODO(), tPartsList{"tld"})
-				n.tChildren["tld"].terminator = 0
+				child, _ := n.tChildren.Get("tld")
+				child.terminator = 0
 				return n
 			}(),
 			want: false,
@@ -180,9 +186,9 @@ func Test_tNode_String(t *testing.T) {
 		{
 			name: "06 - node with multiple levels",
 			node: &tNode{
-				tChildren: tChildren{"tld": &tNode{
-					tChildren: tChildren{"domain": &tNode{terminator: endMask}},
-				}},
+				tChildren: ch.New(map[string]*tNode{"tld": &tNode{
+					tChildren: ch.New(map[string]*tNode{"domain": &tNode{terminator: endMask}}),
+				}}),
 			},
 			want: "\"Node\":\n  isEnd: false\n  isWild: false\n  \"tld\":\n      isEnd: false\n      isWild: false\n      \"domain\":\n          isEnd: true\n          isWild: false\n",
 		},
@@ -258,27 +264,27 @@ func Test_tNode_add(t *testing.T) {
 		},
 		{
 			name:  "07 - add existing part with wildcard",
-			node:  &tNode{tChildren: tChildren{"tld": newNode()}},
+			node:  &tNode{tChildren: ch.New(map[string]*tNode{"tld": newNode()})},
 			parts: tPartsList{"tld", "*"},
 			want:  true,
 		},
 		/* */
 		{
 			name:  "08 - add existing parts",
-			node:  &tNode{tChildren: tChildren{"tld": &tNode{tChildren: tChildren{"domain": newNode()}}}},
+			node:  &tNode{tChildren: ch.New(map[string]*tNode{"tld": &tNode{tChildren: ch.New(map[string]*tNode{"domain": newNode()})}})},
 			parts: tPartsList{"tld", "domain"},
 			want:  true,
 		},
 		{
 			name:  "09 - add existing wildcard",
-			node:  &tNode{tChildren: tChildren{"*": newNode()}},
+			node:  &tNode{tChildren: ch.New(map[string]*tNode{"*": newNode()})},
 			parts: tPartsList{"*"},
 			want:  true,
 		},
 		/* */
 		{
 			name:  "10 - add wildcard after part",
-			node:  &tNode{tChildren: tChildren{"tld": newNode()}},
+			node:  &tNode{tChildren: ch.New(map[string]*tNode{"tld": newNode()})},
 			parts: tPartsList{"*"},
 			want:  true,
 		},
@@ -286,20 +292,20 @@ func Test_tNode_add(t *testing.T) {
 			name: "11 - node with child, grandchild, wildcard, and child",
 			node: func() *tNode {
 				n := newNode()
-				n.tChildren["tld"] = &tNode{
-					tChildren: tChildren{
+				n.tChildren.Put("tld", &tNode{
+					tChildren: ch.New(map[string]*tNode{
 						"domain": &tNode{
-							tChildren: tChildren{
+							tChildren: ch.New(map[string]*tNode{
 								"*": &tNode{
-									tChildren: tChildren{
+									tChildren: ch.New(map[string]*tNode{
 										"sub": newNode(),
-									},
+									}),
 									terminator: wildMask,
 								},
-							},
+							}),
 						},
-					},
-				}
+					}),
+				})
 
 				return n
 			}(),
@@ -310,15 +316,15 @@ func Test_tNode_add(t *testing.T) {
 			name: "12 - node with children, and grandchildren",
 			node: func() *tNode {
 				n := newNode()
-				n.tChildren["tld"] = &tNode{
-					tChildren: tChildren{
+				n.tChildren.Put("tld", &tNode{
+					tChildren: ch.New(map[string]*tNode{
 						"domain": &tNode{
-							tChildren: tChildren{
+							tChildren: ch.New(map[string]*tNode{
 								"sub": newNode(),
-							},
+							}),
 						},
-					},
-				}
+					}),
+				})
 
 				return n
 			}(),
@@ -340,6 +346,83 @@ func Test_tNode_add(t *testing.T) {
 	}
 } // Test_tNode_add()
 
+func Test_tNode_addSubdomains(t *testing.T) {
+	ctx := context.TODO()
+	node := newNode()
+	for _, pattern := range []string{
+		"example.com", "www.example.com", "*.example.net",
+		"ads.*.example.org", "tld",
+	} {
+		node.add(ctx, pattern2parts(pattern))
+	}
+	want := node.allPatterns(ctx)
+	_, wantCount := node.count(ctx)
+
+	if got := node.addSubdomains(ctx); 3 != got {
+		t.Errorf("tNode.addSubdomains() = '%d', want '3'", got)
+	}
+	for _, host := range []string{"a.example.com", "b.www.example.com", "x.tld"} {
+		if !node.matchHost(ctx, host) {
+			t.Errorf("tNode.matchHost(%q) = 'false', want 'true'", host)
+		}
+	}
+
+	// The implied wildcards are neither listed, counted, nor stored
+	if got := node.allPatterns(ctx); !slices.Equal(got, want) {
+		t.Errorf("tNode.allPatterns() = '%v', want '%v'", got, want)
+	}
+	if _, got := node.count(ctx); got != wantCount {
+		t.Errorf("tNode.count() = '%d', want '%d'", got, wantCount)
+	}
+	var buf bytes.Buffer
+	if err := node.store(ctx, &buf); nil != err {
+		t.Fatalf("tNode.store() error = '%v'", err)
+	}
+	if strings.Contains(buf.String(), "*.example.com") {
+		t.Errorf("tNode.store() wrote an implied wildcard:\n%s", buf.String())
+	}
+	if node.contains(ctx, pattern2parts("*.example.com")) {
+		t.Error("tNode.contains() = 'true', want 'false'")
+	}
+
+	// A match is reported for the implying hostname
+	var path tPartsList
+	if !node.matchFrom("a.b.www.example.com", len("a.b.www.example.com"), &path) {
+		t.Fatal("tNode.matchFrom() = 'false', want 'true'")
+	}
+	if got := strings.Join(path, "."); "www.example.com" != got {
+		t.Errorf("tNode.matchFrom() pattern = '%s', want 'www.example.com'", got)
+	}
+
+	// Adding them once more doesn't change anything
+	if got := node.addSubdomains(ctx); 0 != got {
+		t.Errorf("tNode.addSubdomains() = '%d', want '0'", got)
+	}
+
+	// Deleting a hostname removes its implied wildcard
+	node.delete(ctx, pattern2parts("tld"))
+	if node.matchHost(ctx, "x.tld") {
+		t.Error("tNode.matchHost() = 'true', want 'false' (deleted)")
+	}
+
+	// An explicit wildcard isn't implied anymore
+	other := newNode()
+	other.add(ctx, pattern2parts("*.tld"))
+	node.merge(ctx, other)
+	if !node.contains(ctx, pattern2parts("*.tld")) {
+		t.Error("tNode.merge() kept '*.tld' implied")
+	}
+	node.add(ctx, pattern2parts("*.example.com"))
+	if !node.contains(ctx, pattern2parts("*.example.com")) {
+		t.Error("tNode.add() kept '*.example.com' implied")
+	}
+
+	var nilNode *tNode
+	if got := nilNode.addSubdomains(ctx); 0 != got {
+		t.Errorf("tNode.addSubdomains() = '%d', want '0'", got)
+	}
+} // Test_tNode_addSubdomains()
+
 func Test_tNode_allPatterns(t *testing.T) {
 	tests := []struct {
 		name string
@@ -580,6 +663,37 @@ func Test_tNode_count(t *testing.T) {
 	}
 } // Test_tNode_count()
 
+func Test_tNode_contains(t *testing.T) {
+	ctx := context.TODO()
+	node := newNode()
+	node.add(ctx, pattern2parts("*.ads.tld"))
+	node.add(ctx, pattern2parts("www.example.tld"))
+
+	tests := []struct {
+		name    string
+		pattern string
+		want    bool
+	}{
+		/* */
+		{"01 - empty pattern", "", false},
+		{"02 - wildcard", "*.ads.tld", true},
+		{"03 - matched by wildcard", "www.ads.tld", false},
+		{"04 - wildcard's domain", "ads.tld", false},
+		{"05 - hostname", "www.example.tld", true},
+		{"06 - hostname's parent", "example.tld", false},
+		{"07 - missing wildcard", "*.example.tld", false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := node.contains(ctx, pattern2parts(tc.pattern)); got != tc.want {
+				t.Errorf("tNode.contains() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_tNode_contains()
+
 func Test_tNode_delete(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -900,6 +1014,66 @@ func Test_tNode_finalNode(t *testing.T) {
 	}
 } // Test_tNode_finalNode()
 
+func Test_tNode_clone(t *testing.T) {
+	ctx := context.TODO()
+	node := newNode()
+	node.add(ctx, pattern2parts("ads.example.com"))
+	node.add(ctx, pattern2parts("*.tracker.tld"))
+
+	got := node.clone(ctx)
+	if !got.Equal(node) {
+		t.Fatalf("tNode.clone() = '%v', want '%v'", got, node)
+	}
+	got.add(ctx, pattern2parts("www.example.com"))
+	if node.match(ctx, pattern2parts("www.example.com")) {
+		t.Error("tNode.clone() shares nodes with the original")
+	}
+
+	var nilNode *tNode
+	if got := nilNode.clone(ctx); nil != got {
+		t.Errorf("tNode.clone() = '%v', want 'nil'", got)
+	}
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if got := node.clone(cancelled); nil != got {
+		t.Errorf("tNode.clone() = '%v', want 'nil'", got)
+	}
+} // Test_tNode_clone()
+
+func Test_tNode_patterns(t *testing.T) {
+	ctx := context.TODO()
+	node := newNode()
+	for _, pattern := range []string{"www.example.com", "*.tracker.tld", "ads.example.com", "example.com"} {
+		node.add(ctx, pattern2parts(pattern))
+	}
+	want := node.allPatterns(ctx)
+
+	if got := slices.Collect(node.patterns(ctx)); !slices.Equal(got, want) {
+		t.Errorf("tNode.patterns() = '%v', want '%v'", got, want)
+	}
+
+	// Breaking the loop stops the walk
+	var got []string
+	for pattern := range node.patterns(ctx) {
+		if got = append(got, pattern); 2 == len(got) {
+			break
+		}
+	}
+	if !slices.Equal(got, want[:2]) {
+		t.Errorf("tNode.patterns() = '%v', want '%v'", got, want[:2])
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if got := slices.Collect(node.patterns(cancelled)); 0 != len(got) {
+		t.Errorf("tNode.patterns() = '%v', want '[]'", got)
+	}
+	var nilNode *tNode
+	if got := slices.Collect(nilNode.patterns(ctx)); 0 != len(got) {
+		t.Errorf("tNode.patterns() = '%v', want '[]'", got)
+	}
+} // Test_tNode_patterns()
+
 func Test_tNode_forEach(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -1428,6 +1602,134 @@ func Test_tNode_update(t *testing.T) {
 	}
 } // Test_tNode_update()
 
+func Test_hostLabel(t *testing.T) {
+	tests := []struct {
+		name string
+		host string
+		want tPartsList
+	}{
+		/* */
+		{"01 - tld", "tld", tPartsList{"tld"}},
+		{"02 - domain", "domain.tld", tPartsList{"tld", "domain"}},
+		{"03 - host", "host.sub.domain.tld", tPartsList{"tld", "domain", "sub", "host"}},
+		{"04 - empty label", "a..tld", tPartsList{"tld", "", "a"}},
+		{"05 - trailing dot", "domain.tld.", tPartsList{"", "tld", "domain"}},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				got   tPartsList
+				label string
+				start int
+			)
+			for end := len(tc.host); ; end = start - 1 {
+				label, start = hostLabel(tc.host, end)
+				got = append(got, label)
+				if 0 == start {
+					break
+				}
+			}
+			if !tc.want.Equal(got) {
+				t.Errorf("hostLabel() = '%v', want '%v'", got, tc.want)
+			}
+			// Same labels as `pattern2parts()` returns
+			if want := pattern2parts(tc.host); !want.Equal(got) {
+				t.Errorf("hostLabel() = '%v', want '%v'", got, want)
+			}
+		})
+	}
+} // Test_hostLabel()
+
+func Test_tNode_matchHost(t *testing.T) {
+	ctx := context.TODO()
+	node := prepareCompiledTrie().root.node
+
+	for host, want := range map[string]bool{
+		"tld": true, "domain.tld": true, "www.domain.tld": false,
+		"x.ads.tld": true, "www.ads.tld": true, "ads.tld": false,
+		"a.b.ads.tld": true, "tracker.example.com": true,
+		"example.com": false, "www.tracker.example.com": false,
+		"example.net": false, "img.cdn.example.net": true,
+		"host.sub.example.org": true, "www.example.org": true,
+		"example.invalid": false,
+	} {
+		if got := node.matchHost(ctx, host); got != want {
+			t.Errorf("tNode.matchHost(%q) = '%v', want '%v'", host, got, want)
+		}
+	}
+
+	// A wildcard inside a pattern stands for a single label
+	node.add(ctx, pattern2parts("ads.*.example.com"))
+	if !node.matchHost(ctx, "ads.eu.example.com") {
+		t.Error("tNode.matchHost(\"ads.eu.example.com\") = 'false', want 'true'")
+	}
+	if node.matchHost(ctx, "ads.a.b.example.com") {
+		t.Error("tNode.matchHost(\"ads.a.b.example.com\") = 'true', want 'false'")
+	}
+
+	var nilNode *tNode
+	if nilNode.matchHost(ctx, "tld") {
+		t.Error("tNode.matchHost() = 'true', want 'false'")
+	}
+	if node.matchHost(ctx, "") {
+		t.Error("tNode.matchHost() = 'true', want 'false'")
+	}
+} // Test_tNode_matchHost()
+
+func Test_lowerHost(t *testing.T) {
+	tests := []struct {
+		name    string
+		host    string
+		want    string
+		wantBuf bool // result uses the buffer
+	}{
+		/* */
+		{"01 - empty", "", "", false},
+		{"02 - lower case", "www.domain.tld", "www.domain.tld", false},
+		{"03 - upper case", "WWW.Domain.TLD", "www.domain.tld", true},
+		{"04 - digits and dashes", "Host-01.TLD", "host-01.tld", true},
+		{"05 - non-ASCII", "WWW.Bücher.DE", "www.bücher.de", false},
+		{"06 - too long", strings.Repeat("A", maxHostLen+1),
+			strings.Repeat("a", maxHostLen+1), false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf [maxHostLen]byte
+			got := lowerHost(tc.host, &buf)
+			if got != tc.want {
+				t.Errorf("lowerHost() = %q, want %q", got, tc.want)
+			}
+			if 0 == len(got) {
+				return
+			}
+			if usesBuf := (unsafe.StringData(got) == &buf[0]); usesBuf != tc.wantBuf {
+				t.Errorf("lowerHost() uses buffer = '%v', want '%v'",
+					usesBuf, tc.wantBuf)
+			}
+		})
+	}
+} // Test_lowerHost()
+
+func Test_internLabel(t *testing.T) {
+	first := pattern2parts("www.example.com")
+	second := pattern2parts("www.example.org")
+
+	got1, got2 := internLabel(first[2]), internLabel(second[2])
+	if ("www" != got1) || (got1 != got2) {
+		t.Errorf("internLabel() = '%s', '%s', want 'www'", got1, got2)
+	}
+	if unsafe.StringData(got1) != unsafe.StringData(got2) {
+		t.Error("internLabel() returned different copies")
+	}
+	if unsafe.StringData(got1) == unsafe.StringData(first[2]) {
+		t.Error("internLabel() returned the pattern's memory")
+	}
+} // Test_internLabel()
+
 func Test_pattern2parts(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -0,0 +1,233 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mwat56/dnscache/clock"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tTempAllow` is a hostname pattern allowed for a limited time.
+	tTempAllow struct {
+		until time.Time
+	}
+
+	// `tPause` holds the temporary exceptions of a `TADlist`:
+	// a global pause of the deny list and temporarily allowed
+	// hostname patterns.
+	tPause struct {
+		sync.RWMutex
+		clock     clock.IClock           // deciding about the expiry
+		denyUntil time.Time              // deny list paused until then
+		patterns  map[string]*tTempAllow // pattern → expiry
+	}
+
+	// `TTempAllow` describes a temporarily allowed hostname pattern
+	// as returned by [TADlist.TemporaryAllows].
+	TTempAllow struct {
+		Pattern string    `json:"pattern"`
+		Until   time.Time `json:"until"`
+	}
+)
+
+// ---------------------------------------------------------------------------
+// `tPause` methods:
+
+// `isAllowed()` checks whether the hostname matches a temporarily
+// allowed pattern.
+//
+// Parameters:
+//   - `aHostname`: The hostname to check.
+//
+// Returns:
+//   - `bool`: `true` if the hostname is allowed, `false` otherwise.
+func (p *tPause) isAllowed(aHostname string) bool {
+	p.RLock()
+	defer p.RUnlock()
+
+	if 0 == len(p.patterns) {
+		return false
+	}
+	aHostname = strings.Trim(strings.ToLower(aHostname), ".")
+	now := p.now()
+	for pattern, ta := range p.patterns {
+		if now.After(ta.until) {
+			continue // not removed yet
+		}
+		if pattern == aHostname {
+			return true
+		}
+		if strings.HasPrefix(pattern, "*.") && strings.HasSuffix(aHostname, pattern[1:]) {
+			return true
+		}
+	}
+
+	return false
+} // isAllowed()
+
+// `isDenyPaused()` checks whether the deny list is currently paused.
+//
+// Returns:
+//   - `bool`: `true` if blocking is paused, `false` otherwise.
+func (p *tPause) isDenyPaused() bool {
+	p.RLock()
+	defer p.RUnlock()
+
+	return p.now().Before(p.denyUntil)
+} // isDenyPaused()
+
+// `now()` returns the current time of the pause's clock.
+//
+// The caller must hold (at least) the read lock.
+//
+// Returns:
+//   - `time.Time`: The current time.
+func (p *tPause) now() time.Time {
+	return clock.OrSystem(p.clock).Now()
+} // now()
+
+// ---------------------------------------------------------------------------
+// `TADlist` methods:
+
+// `AllowTemporarily()` allows a hostname pattern for the given duration.
+//
+// The pattern is either a FQDN or a wildcard (e.g. `*.domain.tld`).
+// After the duration expired the pattern isn't allowed anymore (and
+// gets removed with the next change of the temporary patterns).
+// Allowing an already allowed pattern again resets its duration; a
+// duration of zero (or less) removes the pattern immediately.
+//
+// Parameters:
+//   - `aPattern`: The hostname pattern to allow.
+//   - `aDuration`: How long the pattern is to be allowed.
+//
+// Returns:
+//   - `bool`: `true` if the pattern was (dis)allowed, `false` otherwise.
+func (adl *TADlist) AllowTemporarily(aPattern string, aDuration time.Duration) bool {
+	if nil == adl {
+		return false
+	}
+	pattern := strings.Trim(strings.ToLower(strings.TrimSpace(aPattern)), ".")
+	if ("" == pattern) || ("*" == pattern) {
+		return false
+	}
+
+	p := &adl.pause
+	p.Lock()
+	defer p.Unlock()
+
+	now := p.now()
+	delete(p.patterns, pattern)
+	maps.DeleteFunc(p.patterns, func(_ string, aTA *tTempAllow) bool {
+		return !now.Before(aTA.until) // expired
+	})
+	if 0 < aDuration {
+		if nil == p.patterns {
+			p.patterns = make(map[string]*tTempAllow)
+		}
+		p.patterns[pattern] = &tTempAllow{until: now.Add(aDuration)}
+	}
+
+	return true
+} // AllowTemporarily()
+
+// `DenyPausedUntil()` returns the time the paused deny list gets
+// active again.
+//
+// Returns:
+//   - `time.Time`: The end of the pause (zero if not paused).
+func (adl *TADlist) DenyPausedUntil() time.Time {
+	if nil == adl {
+		return time.Time{}
+	}
+	adl.pause.RLock()
+	defer adl.pause.RUnlock()
+
+	if adl.pause.now().Before(adl.pause.denyUntil) {
+		return adl.pause.denyUntil
+	}
+
+	return time.Time{}
+} // DenyPausedUntil()
+
+// `PauseDeny()` suspends the deny list for the given duration.
+//
+// While paused, `Match()` never returns `ADdeny`. After the duration
+// expired blocking is re-enabled automatically; a duration of zero
+// (or less) re-enables it immediately.
+//
+// Parameters:
+//   - `aDuration`: How long blocking is to be paused.
+func (adl *TADlist) PauseDeny(aDuration time.Duration) {
+	if nil == adl {
+		return
+	}
+	adl.pause.Lock()
+	defer adl.pause.Unlock()
+
+	if 0 < aDuration {
+		adl.pause.denyUntil = adl.pause.now().Add(aDuration)
+	} else {
+		adl.pause.denyUntil = time.Time{}
+	}
+} // PauseDeny()
+
+// `SetClock()` sets the clock deciding about the end of the deny
+// list's pause and of the temporarily allowed patterns as well as
+// about the times of the deny patterns' hits (see [HitCounts]); the
+// hits' counting period restarts with the new clock.
+//
+// Parameters:
+//   - `aClock`: The clock to use (`nil` means the system's clock).
+func (adl *TADlist) SetClock(aClock clock.IClock) {
+	if nil == adl {
+		return
+	}
+	adl.pause.Lock()
+	adl.pause.clock = aClock
+	adl.pause.Unlock()
+
+	adl.hits.Lock()
+	adl.hits.clock = aClock
+	adl.hits.since = adl.hits.now()
+	adl.hits.Unlock()
+} // SetClock()
+
+// `TemporaryAllows()` returns all currently allowed temporary patterns.
+//
+// Returns:
+//   - `[]TTempAllow`: The patterns sorted by their expiry.
+func (adl *TADlist) TemporaryAllows() []TTempAllow {
+	if nil == adl {
+		return nil
+	}
+	adl.pause.RLock()
+	defer adl.pause.RUnlock()
+
+	now := adl.pause.now()
+	result := make([]TTempAllow, 0, len(adl.pause.patterns))
+	for pattern, ta := range adl.pause.patterns {
+		if now.Before(ta.until) {
+			result = append(result, TTempAllow{Pattern: pattern, Until: ta.until})
+		}
+	}
+	slices.SortFunc(result, func(a, b TTempAllow) int {
+		return a.Until.Compare(b.Until)
+	})
+
+	return result
+} // TemporaryAllows()
+
+/* _EoF_ */
@@ -0,0 +1,209 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/mwat56/dnscache/clock"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_TADlist_AllowTemporarily(t *testing.T) {
+	ctx := context.TODO()
+	adl := newTestList(t)
+	adl.AddDeny(ctx, "*.domain.tld")
+
+	tests := []struct {
+		name     string
+		pattern  string
+		duration time.Duration
+		hostname string
+		wantOK   bool
+		want     TADresult
+	}{
+		/* */
+		{
+			name:     "01 - empty pattern",
+			pattern:  "",
+			duration: time.Minute,
+			hostname: "ads.domain.tld",
+			wantOK:   false,
+			want:     ADdeny,
+		},
+		{
+			name:     "02 - exact pattern",
+			pattern:  "ads.domain.tld",
+			duration: time.Minute,
+			hostname: "ads.domain.tld",
+			wantOK:   true,
+			want:     ADallow,
+		},
+		{
+			name:     "03 - other host still denied",
+			pattern:  "ads.domain.tld",
+			duration: time.Minute,
+			hostname: "www.domain.tld",
+			wantOK:   true,
+			want:     ADdeny,
+		},
+		{
+			name:     "04 - wildcard pattern",
+			pattern:  "*.domain.tld",
+			duration: time.Minute,
+			hostname: "www.domain.tld",
+			wantOK:   true,
+			want:     ADallow,
+		},
+		{
+			name:     "05 - remove wildcard pattern",
+			pattern:  "*.domain.tld",
+			duration: 0,
+			hostname: "www.domain.tld",
+			wantOK:   true,
+			want:     ADdeny,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := adl.AllowTemporarily(tc.pattern, tc.duration); got != tc.wantOK {
+				t.Errorf("TADlist.AllowTemporarily() = '%v', want '%v'",
+					got, tc.wantOK)
+			}
+			if got := adl.Match(ctx, tc.hostname); got != tc.want {
+				t.Errorf("TADlist.Match() = '%v', want '%v'",
+					got, tc.want)
+			}
+		})
+	}
+} // Test_TADlist_AllowTemporarily()
+
+func Test_TADlist_AllowTemporarily_expire(t *testing.T) {
+	ctx := context.TODO()
+	adl := newTestList(t)
+	adl.AddDeny(ctx, "ads.domain.tld")
+
+	adl.AllowTemporarily("ads.domain.tld", 20*time.Millisecond)
+	if got := adl.Match(ctx, "ads.domain.tld"); ADallow != got {
+		t.Errorf("TADlist.Match() = '%v', want '%v'", got, ADallow)
+	}
+	if got := len(adl.TemporaryAllows()); 1 != got {
+		t.Errorf("len(TADlist.TemporaryAllows()) = '%d', want '%d'", got, 1)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if got := adl.Match(ctx, "ads.domain.tld"); ADdeny != got {
+		t.Errorf("TADlist.Match() = '%v', want '%v'", got, ADdeny)
+	}
+	if got := len(adl.TemporaryAllows()); 0 != got {
+		t.Errorf("len(TADlist.TemporaryAllows()) = '%d', want '%d'", got, 0)
+	}
+} // Test_TADlist_AllowTemporarily_expire()
+
+func Test_TADlist_PauseDeny(t *testing.T) {
+	ctx := context.TODO()
+	adl := newTestList(t)
+	adl.AddDeny(ctx, "*.domain.tld")
+	adl.AddAllow(ctx, "www.domain.tld")
+
+	if !adl.DenyPausedUntil().IsZero() {
+		t.Errorf("TADlist.DenyPausedUntil() = '%v', want zero time",
+			adl.DenyPausedUntil())
+	}
+
+	adl.PauseDeny(20 * time.Millisecond)
+	if adl.DenyPausedUntil().IsZero() {
+		t.Error("TADlist.DenyPausedUntil() = zero time, want end of pause")
+	}
+	if got := adl.Match(ctx, "ads.domain.tld"); ADneutral != got {
+		t.Errorf("TADlist.Match() = '%v', want '%v'", got, ADneutral)
+	}
+	if got := adl.Match(ctx, "www.domain.tld"); ADallow != got {
+		t.Errorf("TADlist.Match() = '%v', want '%v'", got, ADallow)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if got := adl.Match(ctx, "ads.domain.tld"); ADdeny != got {
+		t.Errorf("TADlist.Match() = '%v', want '%v'", got, ADdeny)
+	}
+
+	adl.PauseDeny(time.Minute)
+	adl.PauseDeny(0)
+	if got := adl.Match(ctx, "ads.domain.tld"); ADdeny != got {
+		t.Errorf("TADlist.Match() = '%v', want '%v'", got, ADdeny)
+	}
+} // Test_TADlist_PauseDeny()
+
+func Test_TADlist_SetClock(t *testing.T) {
+	ctx := context.TODO()
+	clk := clock.NewManual(time.Now())
+	adl := newTestList(t)
+	adl.SetClock(clk)
+	adl.AddDeny(ctx, "*.domain.tld")
+
+	adl.PauseDeny(time.Hour)
+	adl.AllowTemporarily("ads.domain.tld", 2*time.Hour)
+	if got, want := adl.DenyPausedUntil(), clk.Now().Add(time.Hour); !got.Equal(want) {
+		t.Errorf("TADlist.DenyPausedUntil() = '%v', want '%v'", got, want)
+	}
+
+	tests := []struct {
+		name    string
+		advance time.Duration
+		host    string
+		want    TADresult
+	}{
+		/* */
+		{"01 - paused", 59 * time.Minute, "www.domain.tld", ADneutral},
+		{"02 - pause over", 2 * time.Minute, "www.domain.tld", ADdeny},
+		{"03 - still allowed", 0, "ads.domain.tld", ADallow},
+		{"04 - allowance over", time.Hour, "ads.domain.tld", ADdeny},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			clk.Advance(tc.advance)
+			if got := adl.Match(ctx, tc.host); got != tc.want {
+				t.Errorf("TADlist.Match() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+
+	// Expired patterns are removed with the next change
+	adl.AllowTemporarily("www.domain.tld", time.Minute)
+	if got := len(adl.pause.patterns); 1 != got {
+		t.Errorf("len(patterns) = '%d', want '%d'", got, 1)
+	}
+} // Test_TADlist_SetClock()
+
+func Test_TADlist_TemporaryAllows(t *testing.T) {
+	adl := newTestList(t)
+	adl.AllowTemporarily("b.domain.tld", 2*time.Minute)
+	adl.AllowTemporarily("A.Domain.TLD.", time.Minute)
+
+	got := adl.TemporaryAllows()
+	if 2 != len(got) {
+		t.Fatalf("len(TADlist.TemporaryAllows()) = '%d', want '%d'", len(got), 2)
+	}
+	if "a.domain.tld" != got[0].Pattern {
+		t.Errorf("TADlist.TemporaryAllows()[0] = '%s', want '%s'",
+			got[0].Pattern, "a.domain.tld")
+	}
+
+	var nilList *TADlist
+	if got := nilList.TemporaryAllows(); nil != got {
+		t.Errorf("TADlist.TemporaryAllows() = '%v', want 'nil'", got)
+	}
+} // Test_TADlist_TemporaryAllows()
+
+/* _EoF_ */
@@ -37,11 +37,26 @@ func init() {
 func initADnodePool() {
 	adNodePoolInit.Do(func() {
 		adNodePool = np.Init(func() any {
-			return &tNode{tChildren: make(tChildren)}
+			return &tNode{}
 		}, 0)
 	})
 } // initADnodePool()
 
+// `SetPoolOptions()` replaces the node pool shared by all lists with
+// a new one using the given settings.
+//
+// This function should be called before any list is created since
+// the items of the replaced pool are left to the garbage collector.
+//
+// Parameters:
+//   - `aOptions`: The pool's settings.
+func SetPoolOptions(aOptions np.TPoolOptions) {
+	initADnodePool() // make sure, the default pool isn't created later
+	adNodePool = np.InitWithOptions(func() any {
+		return &tNode{}
+	}, aOptions)
+} // SetPoolOptions()
+
 // ---------------------------------------------------------------------------
 // `tNode` constructor:
 
@@ -56,18 +71,18 @@ func newNode() (rNode *tNode) {
 
 	item, err := adNodePool.Get()
 	if nil != err {
-		rNode = &tNode{tChildren: make(tChildren)}
+		rNode = &tNode{}
 	} else {
 		var ok bool
 		if rNode, ok = item.(*tNode); ok {
 			if nil == rNode {
 				// Uninitialised pool during testing
-				rNode = &tNode{tChildren: make(tChildren)}
+				rNode = &tNode{}
 				return
 			}
 			// Clear/reset the old field values
-			if 0 < len(rNode.tChildren) {
-				rNode.tChildren = make(tChildren)
+			if 0 < rNode.tChildren.Size() {
+				rNode.tChildren = tChildren{}
 			}
 			rNode.terminator = 0
 		}
@@ -106,4 +121,13 @@ func adPoolMetrics() (rMetrics *np.TPoolMetrics) {
 	return
 } // adPoolMetrics()
 
+// `PoolMetrics()` returns the current metrics of the node pool shared
+// by all lists.
+//
+// Returns:
+//   - `*np.TPoolMetrics`: Current pool metrics.
+func PoolMetrics() *np.TPoolMetrics {
+	return adPoolMetrics()
+} // PoolMetrics()
+
 /* _EoF_ */
@@ -8,6 +8,8 @@ package adlist
 
 import (
 	"testing"
+
+	np "github.com/mwat56/dnscache/internal/nodepool"
 )
 
 //lint:file-ignore ST1017 - I prefer Yoda conditions
@@ -19,7 +21,7 @@ func Test_newNode(t *testing.T) {
 	}{
 		{
 			name:     "01 - empty node",
-			wantNode: &tNode{tChildren: make(tChildren)},
+			wantNode: &tNode{tChildren: tChildren{}},
 		},
 		{
 			name:     "02 - new node",
@@ -43,7 +45,7 @@ func Test_newNode(t *testing.T) {
 					gotNode.String())
 				return
 			}
-			if 0 != len(gotNode.tChildren) {
+			if 0 != gotNode.tChildren.Size() {
 				t.Errorf("newNode() = %v, want empty children",
 					gotNode.tChildren)
 			}
@@ -81,7 +83,7 @@ func Test_putNode(t *testing.T) {
 		},
 		{
 			name:  "02 - empty node",
-			aNode: &tNode{tChildren: make(tChildren)},
+			aNode: &tNode{tChildren: tChildren{}},
 		},
 		// TODO: Add test cases.
 	}
@@ -93,4 +95,25 @@ func Test_putNode(t *testing.T) {
 	}
 } // Test_putNode()
 
+func Test_SetPoolOptions(t *testing.T) {
+	defer SetPoolOptions(np.TPoolOptions{}) // restore the default pool
+
+	SetPoolOptions(np.TPoolOptions{InitSize: 8, MaxSize: 8})
+	if got := PoolMetrics(); 7 != got.Size {
+		t.Errorf("PoolMetrics().Size = %d, want %d", got.Size, 7)
+	}
+	putNode(newNode())
+	if got := PoolMetrics(); 1 != got.Hits {
+		t.Errorf("PoolMetrics().Hits = %d, want %d", got.Hits, 1)
+	}
+
+	SetPoolOptions(np.TPoolOptions{Disabled: true})
+	putNode(newNode())
+	_ = newNode()
+	got := PoolMetrics()
+	if (0 != got.Size) || (0 != got.Hits) || (2 != got.Created) {
+		t.Errorf("PoolMetrics() = %+v, want 2 created nodes", *got)
+	}
+} // Test_SetPoolOptions()
+
 /* _EoF_ */
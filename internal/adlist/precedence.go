@@ -0,0 +1,140 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `TPrecedence` is the rule deciding about a hostname matched by
+	// both the allow and the deny list (see [TADlist.SetPrecedence]).
+	TPrecedence uint8
+)
+
+const (
+	// `PrecedenceNone` means that no rule was needed because at most
+	// one of the lists matched (see [TADlist.Explain]).
+	PrecedenceNone = TPrecedence(0)
+
+	// `PrecedenceAllow` lets the allow list always win (default).
+	PrecedenceAllow = TPrecedence(1)
+
+	// `PrecedenceSpecific` lets the more specific of the two matching
+	// patterns win, e.g. an allowed `cdn.example.com` over a denied
+	// `*.example.com` but a denied `ads.cdn.example.com` over an
+	// allowed `*.example.com`. If both are equally specific the allow
+	// list wins.
+	PrecedenceSpecific = TPrecedence(2)
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `labelRank()` returns the specificity of a pattern's label.
+//
+// Parameters:
+//   - `aParts`: The pattern's parts (in reversed order).
+//   - `aIdx`: The index of the label to rank.
+//
+// Returns:
+//   - `int`: `2` for a literal label, `1` for a wildcard inside the
+//     pattern, and `0` for a leading wildcard.
+func labelRank(aParts tPartsList, aIdx int) int {
+	switch {
+	case "*" != aParts[aIdx]:
+		return 2
+	case len(aParts)-1 == aIdx:
+		return 0
+	default:
+		return 1
+	}
+} // labelRank()
+
+// `moreSpecific()` checks whether the first of two patterns matching
+// the same hostname is more specific than the second one.
+//
+// The patterns' labels are compared from the top-level domain
+// downwards: the first differing label decides, a literal label being
+// more specific than a wildcard inside the pattern, which in turn is
+// more specific than a leading wildcard. If all labels are alike,
+// the longer pattern is the more specific one.
+//
+// Parameters:
+//   - `aPattern`: The pattern to check.
+//   - `aOther`: The pattern to compare with.
+//
+// Returns:
+//   - `bool`: `true` if `aPattern` is more specific, `false` otherwise.
+func moreSpecific(aPattern, aOther string) bool {
+	parts, other := pattern2parts(aPattern), pattern2parts(aOther)
+	for idx := range min(len(parts), len(other)) {
+		if rank, otherRank := labelRank(parts, idx), labelRank(other, idx); rank != otherRank {
+			return rank > otherRank
+		}
+	}
+
+	return len(parts) > len(other)
+} // moreSpecific()
+
+// ---------------------------------------------------------------------------
+// `TPrecedence` methods:
+
+// `String()` implements the `fmt.Stringer` interface.
+//
+// Returns:
+//   - `string`: The rule's name.
+func (p TPrecedence) String() string {
+	switch p {
+	case PrecedenceAllow:
+		return "allow"
+	case PrecedenceSpecific:
+		return "specific"
+	default:
+		return "none"
+	}
+} // String()
+
+// ---------------------------------------------------------------------------
+// `TADlist` methods:
+
+// `Precedence()` returns the rule deciding about hostnames matched by
+// both lists (see [SetPrecedence]).
+//
+// Returns:
+//   - `TPrecedence`: The current rule.
+func (adl *TADlist) Precedence() TPrecedence {
+	if nil == adl {
+		return PrecedenceAllow
+	}
+	if rule := TPrecedence(adl.prefer.Load()); PrecedenceSpecific == rule { //#nosec G115
+		return rule
+	}
+
+	return PrecedenceAllow
+} // Precedence()
+
+// `SetPrecedence()` sets the rule deciding about hostnames matched by
+// both the allow and the deny list.
+//
+// With the default `PrecedenceAllow` an allowed pattern always wins,
+// so `cdn.example.com` in the allow list exempts that hostname from a
+// denied `*.example.com` but an allowed `*.example.com` exempts all
+// its subdomains from any deny pattern. With `PrecedenceSpecific` the
+// more specific of both patterns wins instead.
+//
+// Parameters:
+//   - `aRule`: The rule to use (`PrecedenceNone` meaning the default).
+func (adl *TADlist) SetPrecedence(aRule TPrecedence) {
+	if nil == adl {
+		return
+	}
+
+	if uint32(aRule) != adl.prefer.Swap(uint32(aRule)) {
+		adl.decisions.clear()
+	}
+} // SetPrecedence()
+
+/* _EoF_ */
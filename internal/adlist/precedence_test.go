@@ -0,0 +1,114 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"context"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_moreSpecific(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		other   string
+		want    bool
+	}{
+		/* */
+		{"01 - hostname over wildcard", "cdn.example.com", "*.example.com", true},
+		{"02 - wildcard under hostname", "*.example.com", "cdn.example.com", false},
+		{"03 - deeper wildcard", "*.cdn.example.com", "*.example.com", true},
+		{"04 - deeper hostname", "ads.cdn.example.com", "*.example.com", true},
+		{"05 - mid wildcard over leading one", "ads.*.example.com", "*.example.com", true},
+		{"06 - hostname over mid wildcard", "ads.eu.example.com", "ads.*.example.com", true},
+		{"07 - equal patterns", "cdn.example.com", "cdn.example.com", false},
+		{"08 - wildcard TLD", "ads.example.*", "*.example.com", false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := moreSpecific(tc.pattern, tc.other); got != tc.want {
+				t.Errorf("moreSpecific(%q, %q) = '%v', want '%v'",
+					tc.pattern, tc.other, got, tc.want)
+			}
+		})
+	}
+} // Test_moreSpecific()
+
+func Test_TPrecedence_String(t *testing.T) {
+	tests := []struct {
+		name string
+		rule TPrecedence
+		want string
+	}{
+		/* */
+		{"01 - none", PrecedenceNone, "none"},
+		{"02 - allow", PrecedenceAllow, "allow"},
+		{"03 - specific", PrecedenceSpecific, "specific"},
+		{"04 - unknown", TPrecedence(9), "none"},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.rule.String(); got != tc.want {
+				t.Errorf("TPrecedence.String() = '%s', want '%s'", got, tc.want)
+			}
+		})
+	}
+} // Test_TPrecedence_String()
+
+func Test_TADlist_SetPrecedence(t *testing.T) {
+	ctx := context.TODO()
+	adl := newTestList(t)
+	adl.AddDeny(ctx, "*.example.com")
+	adl.AddDeny(ctx, "ads.cdn.example.org")
+	adl.AddAllow(ctx, "cdn.example.com")
+	adl.AddAllow(ctx, "*.example.org")
+
+	tests := []struct {
+		name     string
+		rule     TPrecedence
+		hostname string
+		want     TADresult
+		wantRule TPrecedence
+	}{
+		/* */
+		{"01 - allow, allowed hostname", PrecedenceAllow, "cdn.example.com", ADallow, PrecedenceAllow},
+		{"02 - allow, denied subdomain", PrecedenceAllow, "www.example.com", ADdeny, PrecedenceNone},
+		{"03 - allow, allowed wildcard", PrecedenceAllow, "ads.cdn.example.org", ADallow, PrecedenceAllow},
+		{"04 - specific, allowed hostname", PrecedenceSpecific, "cdn.example.com", ADallow, PrecedenceSpecific},
+		{"05 - specific, denied subdomain", PrecedenceSpecific, "www.example.com", ADdeny, PrecedenceNone},
+		{"06 - specific, denied hostname", PrecedenceSpecific, "ads.cdn.example.org", ADdeny, PrecedenceSpecific},
+		{"07 - specific, allowed subdomain", PrecedenceSpecific, "www.example.org", ADallow, PrecedenceNone},
+		{"08 - default, denied hostname", PrecedenceNone, "ads.cdn.example.org", ADallow, PrecedenceAllow},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			adl.SetPrecedence(tc.rule)
+			if got := adl.Match(ctx, tc.hostname); got != tc.want {
+				t.Errorf("TADlist.Match(%q) = '%v', want '%v'", tc.hostname, got, tc.want)
+			}
+			if got := adl.Explain(ctx, tc.hostname); got.Rule != tc.wantRule {
+				t.Errorf("TADlist.Explain(%q).Rule = '%v', want '%v'", tc.hostname, got.Rule, tc.wantRule)
+			}
+		})
+	}
+
+	var nilList *TADlist
+	nilList.SetPrecedence(PrecedenceSpecific)
+	if got := nilList.Precedence(); PrecedenceAllow != got {
+		t.Errorf("TADlist.Precedence() = '%v', want '%v'", got, PrecedenceAllow)
+	}
+} // Test_TADlist_SetPrecedence()
+
+/* _EoF_ */
@@ -0,0 +1,229 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `TLoadProgress` describes the progress of loading a single
+	// list source as given to a [TProgressFunc].
+	//
+	//   - `Started`: The start time of the [TADlist.LoadDeny] call.
+	//   - `Source`: The list's URL.
+	//   - `Lines`: Number of lines read so far.
+	//   - `Added`: Number of patterns added so far (the distinct
+	//     patterns once `Done` is set).
+	//   - `Done`: Whether the source is loaded (or failed).
+	TLoadProgress struct {
+		Started time.Time `json:"started"`
+		Source  string    `json:"source"`
+		Lines   uint32    `json:"lines"`
+		Added   uint32    `json:"added"`
+		Done    bool      `json:"done"`
+	}
+
+	// `TProgressFunc` is called while lists are loaded, see
+	// [TADlist.SetProgress].
+	//
+	// As the sources are loaded concurrently, the function must be
+	// safe for concurrent use; it should return quickly as it's
+	// called by the loaders.
+	TProgressFunc func(aProgress TLoadProgress)
+
+	// `TLoadReport` describes a [TADlist.LoadDeny] call.
+	TLoadReport struct {
+		Started  time.Time      `json:"started"`
+		Duration time.Duration  `json:"duration"`
+		Sources  []TSourceStats `json:"sources"` // in the order of the URLs
+	}
+
+	// `TSourceStats` describes the loading of a single list source.
+	//
+	//   - `Source`: The list's URL.
+	//   - `Error`: The reason why the list wasn't loaded (if any).
+	//   - `Lines`: Number of lines read (without empty and comment lines).
+	//   - `Added`: Number of distinct patterns added.
+	//   - `Duplicates`: Number of patterns given more than once.
+	//   - `Converted`: Number of Unicode names added as punycode.
+	//   - `Rejected`: Number of rejected entries.
+	//   - `Reasons`: Number of rejected entries per reason.
+	//   - `Duration`: Time to download and load the list.
+	TSourceStats struct {
+		Source     string            `json:"source"`
+		Error      string            `json:"error,omitempty"`
+		Lines      uint32            `json:"lines"`
+		Added      uint32            `json:"added"`
+		Duplicates uint32            `json:"duplicates"`
+		Converted  uint32            `json:"converted"`
+		Rejected   uint32            `json:"rejected"`
+		Reasons    map[string]uint32 `json:"reasons,omitempty"`
+		Duration   time.Duration     `json:"duration"`
+	}
+
+	// `tLoadStats` counts a list's lines and patterns while it's
+	// loaded.
+	//
+	// All methods can be called on a `nil` instance.
+	tLoadStats struct {
+		progress  func(aLines, aAdded uint32) // optional progress callback
+		reasons   map[string]uint32           // rejected entries per reason
+		lines     uint32                      // lines read
+		accepted  uint32                      // patterns given to the trie
+		added     uint32                      // distinct patterns in the trie
+		converted uint32                      // internationalised names
+		rules     []TAdGuardRule              // rules not fitting the trie
+	}
+)
+
+const (
+	// `progressLines` is the number of lines read between two calls
+	// of a [TProgressFunc].
+	progressLines = 10_000
+
+	// Reasons of rejected list entries
+	reasonABPRule  = "unsupported ABP rule"
+	reasonAdGuard  = "unsupported AdGuard modifier"
+	reasonDnsmasq  = "unsupported dnsmasq option"
+	reasonHostname = "invalid hostname"
+	reasonIDN      = "invalid internationalised hostname"
+	reasonIP       = "invalid IP address"
+	reasonNoHost   = "missing hostname"
+)
+
+// ---------------------------------------------------------------------------
+// `tLoadStats` methods:
+
+// `accept()` counts a pattern given to the trie.
+func (s *tLoadStats) accept() {
+	if nil != s {
+		s.accepted++
+	}
+} // accept()
+
+// `convert()` counts an internationalised name converted to punycode.
+func (s *tLoadStats) convert() {
+	if nil != s {
+		s.converted++
+	}
+} // convert()
+
+// `count()` stores the number of distinct patterns in the given node.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//   - `aNode`: The node the source's patterns were added to.
+func (s *tLoadStats) count(aCtx context.Context, aNode *tNode) {
+	if nil == s {
+		return
+	}
+	_, patterns := aNode.count(aCtx)
+	s.added = uint32(patterns) //#nosec G115
+} // count()
+
+// `line()` counts a line read.
+//
+// Every `progressLines` lines the progress is reported before the
+// next line is counted, i.e. once the previous line is processed.
+func (s *tLoadStats) line() {
+	if nil == s {
+		return
+	}
+	if (nil != s.progress) && (0 < s.lines) && (0 == s.lines%progressLines) {
+		s.progress(s.lines, s.accepted)
+	}
+	s.lines++
+} // line()
+
+// `reject()` counts a rejected entry.
+//
+// Parameters:
+//   - `aReason`: Why the entry was rejected.
+func (s *tLoadStats) reject(aReason string) {
+	if nil == s {
+		return
+	}
+	if nil == s.reasons {
+		s.reasons = make(map[string]uint32)
+	}
+	s.reasons[aReason]++
+} // reject()
+
+// `report()` returns the statistics of the given source.
+//
+// Parameters:
+//   - `aSource`: The source's URL.
+//   - `aErr`: The error of loading the source (if any).
+//   - `aDuration`: The time used to load the source.
+//
+// Returns:
+//   - `TSourceStats`: The source's statistics.
+func (s *tLoadStats) report(aSource string, aErr error, aDuration time.Duration) TSourceStats {
+	result := TSourceStats{
+		Source:   aSource,
+		Duration: aDuration,
+	}
+	if nil != aErr {
+		result.Error = aErr.Error()
+	}
+	if nil == s {
+		return result
+	}
+
+	result.Lines = s.lines
+	result.Added = s.added
+	if s.added < s.accepted {
+		result.Duplicates = s.accepted - s.added
+	}
+	result.Converted = s.converted
+	for _, count := range s.reasons {
+		result.Rejected += count
+	}
+	if 0 < len(s.reasons) {
+		result.Reasons = maps.Clone(s.reasons)
+	}
+
+	return result
+} // report()
+
+// `rule()` collects an AdGuard rule which can't be added to the trie.
+//
+// Parameters:
+//   - `aRule`: The rule to collect.
+func (s *tLoadStats) rule(aRule TAdGuardRule) {
+	if nil != s {
+		s.rules = append(s.rules, aRule)
+	}
+} // rule()
+
+// ---------------------------------------------------------------------------
+// `TLoadReport` methods:
+
+// `clone()` returns a deep copy of the report.
+//
+// Returns:
+//   - `TLoadReport`: The copy.
+func (lr *TLoadReport) clone() TLoadReport {
+	if nil == lr {
+		return TLoadReport{}
+	}
+	result := *lr
+	result.Sources = slices.Clone(lr.Sources)
+	for idx := range result.Sources {
+		result.Sources[idx].Reasons = maps.Clone(lr.Sources[idx].Reasons)
+	}
+
+	return result
+} // clone()
+
+/* _EoF_ */
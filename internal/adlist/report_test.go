@@ -0,0 +1,133 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_loaderStats(t *testing.T) {
+	tests := []struct {
+		name   string
+		loader func(tLoadOptions) ILoader
+		text   string
+		want   TSourceStats
+	}{
+		/* */
+		{
+			name:   "01 - ABP rules",
+			loader: func(aOptions tLoadOptions) ILoader { return &tABPLoader{aOptions} },
+			text: "[Adblock Plus 2.0]\n! comment\n||ads.localdomain^\n" +
+				"@@||good.localdomain^\n##.banner\n||ads.localdomain^\n" +
+				"||bad_host.localdomain^\n",
+			want: TSourceStats{Lines: 5, Added: 1, Duplicates: 1, Rejected: 3,
+				Reasons: map[string]uint32{reasonABPRule: 2, reasonHostname: 1}},
+		},
+		{
+			name:   "02 - dnsmasq options",
+			loader: func(aOptions tLoadOptions) ILoader { return &tDnsmasqLoader{aOptions} },
+			text: "# comment\naddress=/ads.localdomain/0.0.0.0\n" +
+				"cache-size=1000\nserver=8.8.8.8\n" +
+				"local=/bad_host.localdomain/\n",
+			want: TSourceStats{Lines: 4, Added: 2, Rejected: 3,
+				Reasons: map[string]uint32{reasonDnsmasq: 1,
+					reasonNoHost: 1, reasonHostname: 1}},
+		},
+		{
+			name:   "03 - hosts file",
+			loader: func(aOptions tLoadOptions) ILoader { return &tHostsLoader{aOptions} },
+			text: "# comment\n0.0.0.0 ads.localdomain tracker.localdomain\n" +
+				"0.0.0.0\nlocalhost ads.localdomain\n0.0.0.0 ads.localdomain\n",
+			want: TSourceStats{Lines: 4, Added: 2, Duplicates: 1, Rejected: 2,
+				Reasons: map[string]uint32{reasonNoHost: 1, reasonIP: 1}},
+		},
+		{
+			name:   "04 - hostnames",
+			loader: func(aOptions tLoadOptions) ILoader { return &tSimpleLoader{aOptions} },
+			text:   "; comment\nads.localdomain\n*.ads.localdomain\nADS.localdomain\n",
+			want:   TSourceStats{Lines: 3, Added: 2, Duplicates: 1},
+		},
+		/* */
+	}
+
+	for idx, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var stats tLoadStats
+			node := newNode()
+			loader := tc.loader(tLoadOptions{stats: &stats})
+			if err := loader.LoadFrom(context.TODO(), strings.NewReader(tc.text), node); nil != err {
+				t.Fatalf("LoadFrom() error = '%v'", err)
+			}
+			stats.count(context.TODO(), node)
+
+			if got := stats.report("", nil, 0); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("%d: report() = '%v', want '%v'", idx, got, tc.want)
+			}
+		})
+	}
+} // Test_loaderStats()
+
+func Test_tLoadStats_report(t *testing.T) {
+	errLoad := errors.New("load failed")
+	tests := []struct {
+		name  string
+		stats *tLoadStats
+		err   error
+		want  TSourceStats
+	}{
+		/* */
+		{
+			name:  "01 - nil stats",
+			stats: nil,
+			err:   errLoad,
+			want:  TSourceStats{Source: "src", Error: "load failed", Duration: time.Second},
+		},
+		{
+			name: "02 - duplicates and rejections",
+			stats: &tLoadStats{
+				reasons:  map[string]uint32{reasonIP: 2, reasonNoHost: 1},
+				lines:    9,
+				accepted: 6,
+				added:    4,
+			},
+			want: TSourceStats{Source: "src", Lines: 9, Added: 4,
+				Duplicates: 2, Rejected: 3, Duration: time.Second,
+				Reasons: map[string]uint32{reasonIP: 2, reasonNoHost: 1}},
+		},
+		{
+			name:  "03 - more added than accepted",
+			stats: &tLoadStats{lines: 1, accepted: 1, added: 2},
+			want:  TSourceStats{Source: "src", Lines: 1, Added: 2, Duration: time.Second},
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.stats.report("src", tc.err, time.Second); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("tLoadStats.report() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+
+	// The methods can be called on a `nil` instance
+	var nilStats *tLoadStats
+	nilStats.accept()
+	nilStats.convert()
+	nilStats.line()
+	nilStats.reject(reasonIP)
+	nilStats.count(context.TODO(), newNode())
+} // Test_tLoadStats_report()
+
+/* _EoF_ */
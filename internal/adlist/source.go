@@ -0,0 +1,124 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"context"
+	"slices"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tSource` remembers the patterns of a downloaded blocklist.
+	//
+	// Instead of the patterns themselves only their (sorted) hashes
+	// are kept, i.e. eight bytes per pattern, which is enough to tell
+	// the list a matching pattern came from.
+	tSource struct {
+		url  string   // the list's URL
+		keys []uint64 // sorted hashes of the list's patterns
+	}
+
+	// `tSources` are the blocklists of the last [TADlist.LoadDeny]
+	// call in the order of their URLs.
+	tSources []tSource
+)
+
+// ---------------------------------------------------------------------------
+// `tSource` constructor:
+
+// `newSource()` collects the pattern hashes of a blocklist.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//   - `aURL`: The list's URL.
+//   - `aNode`: The root node of the list's patterns.
+//
+// Returns:
+//   - `tSource`: The list's pattern hashes, none in case of cancellation.
+func newSource(aCtx context.Context, aURL string, aNode *tNode) tSource {
+	result := tSource{url: aURL}
+	if nil == aNode {
+		return result
+	}
+	type (
+		tStackEntry struct {
+			node *tNode
+			hash uint64 // hash of the labels leading to `node`
+		}
+	)
+
+	stack := []tStackEntry{{aNode, fnvOffset}}
+	for 0 < len(stack) {
+		if nil != aCtx.Err() {
+			result.keys = nil
+			return result
+		}
+		entry := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		for label, child := range entry.node.tChildren.All() {
+			hash := bloomExtend(entry.hash, label)
+			if (0 != child.terminator) && !child.implied() {
+				result.keys = append(result.keys, hash)
+			}
+			stack = append(stack, tStackEntry{child, hash})
+		}
+	}
+	slices.Sort(result.keys)
+
+	return result
+} // newSource()
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `sourceKey()` returns the hash of a pattern as used by `tSource`.
+//
+// Parameters:
+//   - `aPattern`: The pattern to hash.
+//
+// Returns:
+//   - `uint64`: The pattern's hash.
+func sourceKey(aPattern string) uint64 {
+	result := uint64(fnvOffset)
+	for _, label := range pattern2parts(aPattern) {
+		result = bloomExtend(result, label)
+	}
+
+	return result
+} // sourceKey()
+
+// ---------------------------------------------------------------------------
+// `tSources` methods:
+
+// `find()` returns the URL of the first blocklist containing the
+// given pattern.
+//
+// Parameters:
+//   - `aPattern`: The deny pattern to look for.
+//
+// Returns:
+//   - `string`: The list's URL, an empty string if no downloaded
+//     list contains the pattern.
+func (s tSources) find(aPattern string) string {
+	if ("" == aPattern) || (0 == len(s)) {
+		return ""
+	}
+
+	key := sourceKey(aPattern)
+	for _, source := range s {
+		if _, ok := slices.BinarySearch(source.keys, key); ok {
+			return source.url
+		}
+	}
+
+	return ""
+} // find()
+
+/* _EoF_ */
@@ -0,0 +1,61 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"context"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_tSources_find(t *testing.T) {
+	ctx := context.TODO()
+	first, second := newNode(), newNode()
+	_ = first.add(ctx, pattern2parts("ads.example.com"))
+	_ = first.add(ctx, pattern2parts("*.tracker.tld"))
+	_ = second.add(ctx, pattern2parts("*.tracker.tld"))
+	_ = second.add(ctx, pattern2parts("ads.*.example.net"))
+	sources := tSources{
+		newSource(ctx, "https://first.tld/list", first),
+		newSource(ctx, "https://second.tld/list", second),
+	}
+
+	tests := []struct {
+		name    string
+		sources tSources
+		pattern string
+		want    string
+	}{
+		/* */
+		{"01 - hostname", sources, "ads.example.com", "https://first.tld/list"},
+		{"02 - first of both lists", sources, "*.tracker.tld", "https://first.tld/list"},
+		{"03 - mid wildcard", sources, "ads.*.example.net", "https://second.tld/list"},
+		{"04 - inner node", sources, "example.com", ""},
+		{"05 - unknown pattern", sources, "*.example.com", ""},
+		{"06 - case insensitive", sources, "ADS.example.com", "https://first.tld/list"},
+		{"07 - empty pattern", sources, "", ""},
+		{"08 - no sources", nil, "ads.example.com", ""},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.sources.find(tc.pattern); got != tc.want {
+				t.Errorf("tSources.find(%q) = '%s', want '%s'", tc.pattern, got, tc.want)
+			}
+		})
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if got := newSource(cancelled, "https://first.tld/list", first); 0 != len(got.keys) {
+		t.Errorf("newSource() = '%d' keys, want '0'", len(got.keys))
+	}
+} // Test_tSources_find()
+
+/* _EoF_ */
@@ -0,0 +1,193 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	_ "embed"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `TTLDOptions` contains the settings of `InitTLDs()`.
+	//
+	//   - `CacheFile`: Path/name of the local copy of the IANA list
+	//     (default: `tlds-alpha-by-domain.txt` in the system's
+	//     temporary directory).
+	//   - `MaxAge`: Age after which the local copy is downloaded again
+	//     (default: 7 days).
+	//   - `Disabled`: Don't check the hostnames' top-level domains at all.
+	//   - `Offline`: Don't download the list but use the local copy
+	//     (however old) or the embedded snapshot.
+	TTLDOptions struct {
+		CacheFile string
+		MaxAge    time.Duration
+		Disabled  bool
+		Offline   bool
+	}
+
+	// `tTLDs` is the set of known top-level domains.
+	tTLDs map[string]struct{}
+)
+
+const (
+	// `tldURL` is the source of the top-level domains.
+	tldURL = "https://data.iana.org/TLD/tlds-alpha-by-domain.txt"
+
+	// `defTLDMaxAge` is the default age of an outdated local copy.
+	defTLDMaxAge = 7 * 24 * time.Hour
+)
+
+var (
+	// `adTLDs` is the active set of top-level domains (`nil` means
+	// not initialised yet, an empty set disables the check).
+	adTLDs atomic.Pointer[tTLDs]
+
+	// `tldSnapshot` is used as long as `InitTLDs()` wasn't called or
+	// couldn't get a current list.
+	//
+	//go:embed tlds.txt
+	tldSnapshot []byte
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `InitTLDs()` sets up the top-level domains used to validate the
+// hostnames of loaded lists.
+//
+// A current local copy of the IANA list is used as is, otherwise the
+// list is downloaded and the copy updated. If that fails, an outdated
+// copy or the embedded snapshot is used instead.
+//
+// Without calling this function the embedded snapshot is used, i.e.
+// the package never accesses the network by itself.
+//
+// Parameters:
+//   - `aCtx`: The context to use for the download.
+//   - `aOptions`: The settings to use.
+//
+// Returns:
+//   - `error`: `nil` if a current (or offline) list is used, the
+//     download error otherwise.
+func InitTLDs(aCtx context.Context, aOptions TTLDOptions) error {
+	if aOptions.Disabled {
+		adTLDs.Store(&tTLDs{})
+		return nil
+	}
+	if aOptions.CacheFile = strings.TrimSpace(aOptions.CacheFile); 0 == len(aOptions.CacheFile) {
+		aOptions.CacheFile = filepath.Join(os.TempDir(), "tlds-alpha-by-domain.txt")
+	}
+	if 0 >= aOptions.MaxAge {
+		aOptions.MaxAge = defTLDMaxAge
+	}
+
+	cached, cacheErr := os.ReadFile(aOptions.CacheFile) //#nosec G304
+	if nil == cacheErr {
+		if fi, err := os.Stat(aOptions.CacheFile); (nil == err) &&
+			fi.ModTime().After(time.Now().Add(-aOptions.MaxAge)) {
+			if tlds := parseTLDs(bytes.NewReader(cached)); 0 < len(tlds) {
+				adTLDs.Store(&tlds)
+				return nil
+			}
+		}
+	}
+
+	var err error
+	if !aOptions.Offline {
+		var body bytes.Buffer
+		if _, err = downloader().get(aCtx, tldURL, nil, &body); nil == err {
+			if tlds := parseTLDs(bytes.NewReader(body.Bytes())); 0 < len(tlds) {
+				adTLDs.Store(&tlds)
+				// Keep a local copy for later use
+				_ = os.WriteFile(aOptions.CacheFile, body.Bytes(), 0600)
+				return nil
+			}
+		}
+	}
+
+	// Fallback: the outdated copy or the embedded snapshot
+	tlds := parseTLDs(bytes.NewReader(tldSnapshot))
+	if nil == cacheErr {
+		if outdated := parseTLDs(bytes.NewReader(cached)); 0 < len(outdated) {
+			tlds = outdated
+		}
+	}
+	adTLDs.Store(&tlds)
+
+	return err
+} // InitTLDs()
+
+// `isKnownTLD()` checks whether the given name is a top-level domain.
+//
+// Parameters:
+//   - `aTLD`: The (lower case) name to check.
+//
+// Returns:
+//   - `rOK`: `true` if the name is a known top-level domain.
+//   - `rChecked`: `false` if the check is disabled, `true` otherwise.
+func isKnownTLD(aTLD string) (rOK, rChecked bool) {
+	tlds := adTLDs.Load()
+	if nil == tlds {
+		snapshot := parseTLDs(bytes.NewReader(tldSnapshot))
+		if !adTLDs.CompareAndSwap(nil, &snapshot) {
+			tlds = adTLDs.Load()
+		} else {
+			tlds = &snapshot
+		}
+	}
+	if 0 == len(*tlds) {
+		return
+	}
+	_, rOK = (*tlds)[aTLD]
+
+	return rOK, true
+} // isKnownTLD()
+
+// `parseTLDs()` reads the top-level domains in IANA's format, i.e. one
+// (upper case) name per line and `#` comments.
+//
+// Parameters:
+//   - `aReader`: The reader to read the names from.
+//
+// Returns:
+//   - `tTLDs`: The set of (lower case) top-level domains.
+func parseTLDs(aReader io.Reader) tTLDs {
+	result := make(tTLDs, 1536)
+
+	scanner := bufio.NewScanner(aReader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if (0 == len(line)) || ("#" == string(line[0])) {
+			// Ignore empty or comment lines
+			continue
+		}
+		// The IANA file contains uppercase entries but
+		// we're only using lowercase entries in this package
+		result[strings.ToLower(line)] = struct{}{}
+	}
+	if 0 == len(result) {
+		return result
+	}
+
+	// We need this entries for the hosts file format and unit-tests
+	result["localdomain"] = struct{}{}
+	result["localhost"] = struct{}{}
+
+	return result
+} // parseTLDs()
+
+/* _EoF_ */
@@ -0,0 +1,119 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_parseTLDs(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		tld     string
+		wantLen int
+		want    bool
+	}{
+		/* */
+		{
+			name:    "01 - empty list",
+			data:    "# Version 1\n",
+			tld:     "com",
+			wantLen: 0,
+			want:    false,
+		},
+		{
+			name:    "02 - IANA format",
+			data:    "# Version 1\nCOM\nNET\n",
+			tld:     "com",
+			wantLen: 4,
+			want:    true,
+		},
+		{
+			name:    "03 - local names",
+			data:    "COM\n",
+			tld:     "localdomain",
+			wantLen: 3,
+			want:    true,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := parseTLDs(strings.NewReader(tc.data))
+			if len(got) != tc.wantLen {
+				t.Errorf("parseTLDs() len = '%d', want '%d'", len(got), tc.wantLen)
+			}
+			if _, ok := got[tc.tld]; ok != tc.want {
+				t.Errorf("parseTLDs()[%q] = '%v', want '%v'", tc.tld, ok, tc.want)
+			}
+		})
+	}
+} // Test_parseTLDs()
+
+func Test_InitTLDs(t *testing.T) {
+	defer adTLDs.Store(nil)
+	ctx := context.TODO()
+
+	// The embedded snapshot is used by default
+	adTLDs.Store(nil)
+	if ok, checked := isKnownTLD("com"); !ok || !checked {
+		t.Errorf("isKnownTLD(snapshot) = '%v', '%v', want 'true', 'true'", ok, checked)
+	}
+
+	// A current local copy is used without downloading
+	cacheFile := filepath.Join(t.TempDir(), "tlds.txt")
+	if err := os.WriteFile(cacheFile, []byte("# test\nEXAMPLE\n"), 0600); nil != err {
+		t.Fatal(err)
+	}
+	if err := InitTLDs(ctx, TTLDOptions{CacheFile: cacheFile}); nil != err {
+		t.Fatalf("InitTLDs() error = '%v'", err)
+	}
+	if ok, _ := isKnownTLD("example"); !ok {
+		t.Error("isKnownTLD(example) = 'false', want 'true'")
+	}
+	if ok, _ := isKnownTLD("com"); ok {
+		t.Error("isKnownTLD(com) = 'true', want 'false'")
+	}
+	if isValidHostname("www.google.com") {
+		t.Error("isValidHostname(www.google.com) = 'true', want 'false'")
+	}
+
+	// Offline, the local copy or the snapshot is used
+	if err := InitTLDs(ctx, TTLDOptions{CacheFile: cacheFile, Offline: true}); nil != err {
+		t.Fatalf("InitTLDs(offline) error = '%v'", err)
+	}
+	if ok, _ := isKnownTLD("example"); !ok {
+		t.Error("isKnownTLD(offline) = 'false', want 'true'")
+	}
+	if err := InitTLDs(ctx, TTLDOptions{CacheFile: cacheFile + ".missing", Offline: true}); nil != err {
+		t.Fatalf("InitTLDs(offline, no copy) error = '%v'", err)
+	}
+	if ok, _ := isKnownTLD("com"); !ok {
+		t.Error("isKnownTLD(offline, no copy) = 'false', want 'true'")
+	}
+
+	// The check can be disabled
+	if err := InitTLDs(ctx, TTLDOptions{Disabled: true}); nil != err {
+		t.Fatalf("InitTLDs(disabled) error = '%v'", err)
+	}
+	if _, checked := isKnownTLD("com"); checked {
+		t.Error("isKnownTLD(disabled) checked = 'true', want 'false'")
+	}
+	if !isValidHostname("host.unknown-tld") {
+		t.Error("isValidHostname(disabled) = 'false', want 'true'")
+	}
+} // Test_InitTLDs()
+
+/* _EoF_ */
@@ -0,0 +1,296 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"container/heap"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tCounter` is a single counter of the space-saving algorithm.
+	tCounter struct {
+		name  string
+		count uint64
+		index int // position in the heap
+	}
+
+	// `tSpaceSaving` counts the most frequent names using a bounded
+	// number of counters (Metwally et al., "space-saving").
+	//
+	// If all counters are in use, the counter with the smallest count
+	// is reused for a new name. Hence the counts are upper bounds, but
+	// all names occurring more often than `1/capacity` of the time are
+	// guaranteed to be kept.
+	tSpaceSaving struct {
+		counters map[string]*tCounter
+		heap     tCounterHeap // min-heap of the counters
+	}
+
+	// `tCounterHeap` implements `heap.Interface` for the counters.
+	tCounterHeap []*tCounter
+
+	// `TTopEntry` is a name with its (estimated) number of occurrences.
+	TTopEntry struct {
+		Name  string `json:"name"`
+		Count uint64 `json:"count"`
+	}
+
+	// `TTopK` keeps track of the most frequent names within a rolling
+	// time window.
+	//
+	// The counters of the current and the previous window are kept,
+	// so the reported counts cover between one and two windows.
+	TTopK struct {
+		mtx      sync.Mutex
+		capacity int           // max. number of counters per window
+		window   time.Duration // length of a time window
+		start    time.Time     // start of the current window
+		current  *tSpaceSaving
+		previous *tSpaceSaving
+	}
+)
+
+const (
+	// `DefaultTopKCapacity` is the default number of counters per window.
+	DefaultTopKCapacity = 1 << 7
+
+	// `DefaultTopKWindow` is the default length of a time window.
+	DefaultTopKWindow = time.Hour
+)
+
+// ---------------------------------------------------------------------------
+// `tCounterHeap` methods:
+
+// `Len()` implements the `sort.Interface` for `heap.Interface`.
+func (h tCounterHeap) Len() int {
+	return len(h)
+} // Len()
+
+// `Less()` implements the `sort.Interface` for `heap.Interface`.
+func (h tCounterHeap) Less(i, j int) bool {
+	return h[i].count < h[j].count
+} // Less()
+
+// `Pop()` implements the `heap.Interface`.
+func (h *tCounterHeap) Pop() any {
+	old := *h
+	last := len(old) - 1
+	c := old[last]
+	old[last] = nil
+	*h = old[:last]
+
+	return c
+} // Pop()
+
+// `Push()` implements the `heap.Interface`.
+func (h *tCounterHeap) Push(aCounter any) {
+	c := aCounter.(*tCounter)
+	c.index = len(*h)
+	*h = append(*h, c)
+} // Push()
+
+// `Swap()` implements the `sort.Interface` for `heap.Interface`.
+func (h tCounterHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+} // Swap()
+
+// ---------------------------------------------------------------------------
+// `tSpaceSaving` constructor:
+
+// `newSpaceSaving()` returns a new, empty set of counters.
+//
+// Parameters:
+//   - `aCapacity`: The max. number of counters.
+//
+// Returns:
+//   - `*tSpaceSaving`: The new set of counters.
+func newSpaceSaving(aCapacity int) *tSpaceSaving {
+	return &tSpaceSaving{
+		counters: make(map[string]*tCounter, aCapacity),
+		heap:     make(tCounterHeap, 0, aCapacity),
+	}
+} // newSpaceSaving()
+
+// ---------------------------------------------------------------------------
+// `tSpaceSaving` methods:
+
+// `add()` counts an occurrence of the given name.
+//
+// Parameters:
+//   - `aName`: The name to count.
+func (ss *tSpaceSaving) add(aName string) {
+	if c, ok := ss.counters[aName]; ok {
+		c.count++
+		heap.Fix(&ss.heap, c.index)
+		return
+	}
+
+	if len(ss.heap) < cap(ss.heap) {
+		c := &tCounter{name: aName, count: 1}
+		heap.Push(&ss.heap, c)
+		ss.counters[aName] = c
+		return
+	}
+
+	// Replace the least frequent name by the new one
+	c := ss.heap[0]
+	delete(ss.counters, c.name)
+	c.name = aName
+	c.count++
+	ss.counters[aName] = c
+	heap.Fix(&ss.heap, 0)
+} // add()
+
+// ---------------------------------------------------------------------------
+// `TTopK` constructor:
+
+// `NewTopK()` returns a new top-K counter.
+//
+// Parameters:
+//   - `aCapacity`: The max. number of names to track per window.
+//   - `aWindow`: The length of a time window.
+//
+// Returns:
+//   - `*TTopK`: The new top-K counter.
+func NewTopK(aCapacity int, aWindow time.Duration) *TTopK {
+	if 0 >= aCapacity {
+		aCapacity = DefaultTopKCapacity
+	}
+	if 0 >= aWindow {
+		aWindow = DefaultTopKWindow
+	}
+
+	return &TTopK{
+		capacity: aCapacity,
+		window:   aWindow,
+		start:    time.Now(),
+		current:  newSpaceSaving(aCapacity),
+	}
+} // NewTopK()
+
+// ---------------------------------------------------------------------------
+// `TTopK` methods:
+
+// `Add()` counts an occurrence of the given name.
+//
+// Parameters:
+//   - `aName`: The (host) name to count.
+func (tk *TTopK) Add(aName string) {
+	if nil == tk {
+		return
+	}
+	if aName = strings.Trim(strings.ToLower(aName), "."); "" == aName {
+		return
+	}
+	tk.mtx.Lock()
+	defer tk.mtx.Unlock()
+
+	tk.rotate()
+	tk.current.add(aName)
+} // Add()
+
+// `Reset()` removes all counters.
+func (tk *TTopK) Reset() {
+	if nil == tk {
+		return
+	}
+	tk.mtx.Lock()
+	defer tk.mtx.Unlock()
+
+	tk.start = time.Now()
+	tk.current = newSpaceSaving(tk.capacity)
+	tk.previous = nil
+} // Reset()
+
+// `rotate()` starts a new window if the current one has expired.
+//
+// NOTE: The caller must hold the lock.
+func (tk *TTopK) rotate() {
+	elapsed := time.Since(tk.start)
+	if elapsed < tk.window {
+		return
+	}
+
+	if elapsed < (tk.window << 1) {
+		tk.previous = tk.current
+		tk.start = tk.start.Add(tk.window)
+	} else {
+		// No events during the whole last window
+		tk.previous = nil
+		tk.start = time.Now()
+	}
+	tk.current = newSpaceSaving(tk.capacity)
+} // rotate()
+
+// `Top()` returns the most frequent names.
+//
+// Parameters:
+//   - `aCount`: The max. number of names to return (`0` for all).
+//
+// Returns:
+//   - `[]TTopEntry`: The names sorted by decreasing count.
+func (tk *TTopK) Top(aCount int) []TTopEntry {
+	if nil == tk {
+		return nil
+	}
+	tk.mtx.Lock()
+	tk.rotate()
+	counts := make(map[string]uint64, len(tk.current.counters))
+	for name, c := range tk.current.counters {
+		counts[name] = c.count
+	}
+	if nil != tk.previous {
+		for name, c := range tk.previous.counters {
+			counts[name] += c.count
+		}
+	}
+	tk.mtx.Unlock()
+
+	return TopEntries(counts, aCount)
+} // Top()
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `TopEntries()` returns the entries with the highest counts.
+//
+// Parameters:
+//   - `aCounts`: The names and their counts.
+//   - `aCount`: The max. number of entries to return (`0` for all).
+//
+// Returns:
+//   - `[]TTopEntry`: The entries sorted by decreasing count and name.
+func TopEntries(aCounts map[string]uint64, aCount int) []TTopEntry {
+	result := make([]TTopEntry, 0, len(aCounts))
+	for name, count := range aCounts {
+		result = append(result, TTopEntry{Name: name, Count: count})
+	}
+	slices.SortFunc(result, func(a, b TTopEntry) int {
+		if a.Count != b.Count {
+			if a.Count > b.Count {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	if (0 < aCount) && (aCount < len(result)) {
+		result = result[:aCount]
+	}
+
+	return result
+} // TopEntries()
+
+/* _EoF_ */
@@ -0,0 +1,131 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_TTopK_Top(t *testing.T) {
+	tk := NewTopK(8, time.Hour)
+	for i := range 5 {
+		for range 10 - i {
+			tk.Add(fmt.Sprintf("host%d.tld", i))
+		}
+	}
+	tk.Add("HOST0.TLD.")
+	// Names occurring only once may replace each other's counters
+	// but must never push out the most frequent names.
+	for i := range 4 {
+		tk.Add(fmt.Sprintf("rare%d.tld", i))
+	}
+
+	tests := []struct {
+		name  string
+		count int
+		want  []string
+	}{
+		/* */
+		{
+			name:  "01 - top 1",
+			count: 1,
+			want:  []string{"host0.tld"},
+		},
+		{
+			name:  "02 - top 2",
+			count: 2,
+			want:  []string{"host0.tld", "host1.tld"},
+		},
+		{
+			name:  "03 - top 3",
+			count: 3,
+			want:  []string{"host0.tld", "host1.tld", "host2.tld"},
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, entry := range tk.Top(tc.count) {
+				got = append(got, entry.Name)
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("TTopK.Top() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+
+	if got := tk.Top(1)[0].Count; 11 != got {
+		t.Errorf("TTopK.Top()[0].Count = '%d', want '%d'", got, 11)
+	}
+	if got := len(tk.Top(0)); 8 != got {
+		t.Errorf("len(TTopK.Top(0)) = '%d', want '%d'", got, 8)
+	}
+} // Test_TTopK_Top()
+
+func Test_TTopK_window(t *testing.T) {
+	tk := NewTopK(8, 20*time.Millisecond)
+	tk.Add("old.tld")
+
+	time.Sleep(25 * time.Millisecond)
+	tk.Add("new.tld")
+	if got := len(tk.Top(0)); 2 != got {
+		t.Errorf("len(TTopK.Top()) = '%d', want '%d'", got, 2)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if got := len(tk.Top(0)); 0 != got {
+		t.Errorf("len(TTopK.Top()) = '%d', want '%d'", got, 0)
+	}
+
+	tk.Add("new.tld")
+	tk.Reset()
+	if got := len(tk.Top(0)); 0 != got {
+		t.Errorf("len(TTopK.Top()) = '%d', want '%d'", got, 0)
+	}
+
+	var nilTK *TTopK
+	nilTK.Add("host.tld")
+	if got := nilTK.Top(0); nil != got {
+		t.Errorf("TTopK.Top() = '%v', want 'nil'", got)
+	}
+} // Test_TTopK_window()
+
+func Test_TADlist_TopBlocked(t *testing.T) {
+	ctx := context.TODO()
+	adl := newTestList(t)
+	adl.AddDeny(ctx, "*.ads.tld")
+
+	for range 3 {
+		adl.Match(ctx, "banner.ads.tld")
+	}
+	adl.Match(ctx, "popup.ads.tld")
+	adl.Match(ctx, "www.domain.tld")
+
+	// Denials without a matching pattern aren't counted
+	adl.Match(ctx, " ")
+	adl.SetDefaultDeny(true)
+	adl.Match(ctx, "www.domain.tld")
+
+	got := adl.TopBlocked(0)
+	want := []TTopEntry{
+		{Name: "banner.ads.tld", Count: 3},
+		{Name: "popup.ads.tld", Count: 1},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("TADlist.TopBlocked() = '%v', want '%v'", got, want)
+	}
+} // Test_TADlist_TopBlocked()
+
+/* _EoF_ */
@@ -8,8 +8,12 @@ package adlist
 
 import (
 	"context"
+	"errors"
+	"io"
+	"iter"
 	"os"
 	"runtime"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -26,6 +30,9 @@ type (
 		numMisses   atomic.Uint32
 		numReloads  atomic.Uint32
 		numRetries  atomic.Uint32
+		swapNs      atomic.Uint64 // write lock duration of the last swap
+		maxSwapNs   atomic.Uint64 // max. write lock duration of a swap
+		counted     atomic.Bool   // `numNodes` and `numPatterns` are up to date
 	}
 
 	//
@@ -47,13 +54,36 @@ type (
 	//   - `R`: Retrieve a pattern [Match],
 	//   - `U`: Update a pattern [Update],
 	//   - `D`: Delete a pattern [Delete].
+	//
+	// A `*` label of a pattern is a wildcard:
+	//
+	//   - a leading `*` stands for one or more labels, i.e. all
+	//     subdomains (`*.example.com` matches `www.example.com` and
+	//     `a.b.example.com` but not `example.com`),
+	//   - any other `*` stands for exactly one label (`ads.*.example.com`
+	//     matches `ads.eu.example.com`, and `*.tracking.*` matches
+	//     `www.tracking.com` but not `www.tracking.co.uk`).
+	//
+	// If several patterns match a hostname, the most specific one
+	// takes precedence: the labels are compared from the top-level
+	// domain downwards, and at each level a literal label is
+	// preferred to a leading `*`, which in turn is preferred to a
+	// `*` inside the pattern. E.g. for `ads.eu.example.com` the
+	// hostname itself precedes `*.eu.example.com`, which precedes
+	// `*.example.com`, which precedes `ads.*.example.com`, which
+	// precedes `*.com`.
 	tTrie struct {
-		_            struct{}  // placeholder for embedding
-		tTrieMetrics           // embedded metrics for the trie
-		lastLoadTime time.Time // time of the trie's file loading
-		filename     string    // filename for local storage
-		url          string    // URL for the upstream source
-		root         tRoot     // root node of the trie
+		_            struct{}                  // placeholder for embedding
+		tTrieMetrics                           // embedded metrics for the trie
+		compiled     atomic.Pointer[tCompiled] // optional R/O copy for matching
+		filter       atomic.Pointer[tBloom]    // optional pre-check for matching
+		lastLoadTime time.Time                 // time of the trie's file loading
+		filename     string                    // filename for local storage
+		url          string                    // URL for the upstream source
+		root         tRoot                     // root node of the trie
+		validation   TValidation               // strictness of downloaded lists' checks
+		subdomains   bool                      // block the subdomains of downloaded lists' hostnames
+		saving       sync.WaitGroup            // running background stores of the list
 	}
 )
 
@@ -103,6 +133,9 @@ func (t *tTrie) Add(aCtx context.Context, aPattern string) (rOK bool) {
 
 	t.root.Lock()
 	rOK = t.root.node.add(aCtx, parts)
+	t.compiled.Store(nil)
+	t.counted.Store(false)
+	t.filter.Load().add(parts)
 	t.root.Unlock()
 
 	return
@@ -116,7 +149,7 @@ func (t *tTrie) Add(aCtx context.Context, aPattern string) (rOK bool) {
 // Returns:
 //   - `rList`: A list of all patterns in the trie.
 func (t *tTrie) AllPatterns(aCtx context.Context) (rList tPartsList) {
-	if (nil == t) || (nil == t.root.node) || (0 == len(t.root.node.tChildren)) {
+	if (nil == t) || (nil == t.root.node) || (0 == t.root.node.tChildren.Size()) {
 		return
 	}
 	// Check for timeout or cancellation
@@ -131,6 +164,28 @@ func (t *tTrie) AllPatterns(aCtx context.Context) (rList tPartsList) {
 	return
 } // AllPatterns()
 
+// `Compile()` creates an immutable, compact copy of the trie used by
+// [Match] until the trie gets changed the next time.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//
+// Returns:
+//   - `int`: The approximate memory used by the compiled copy.
+func (t *tTrie) Compile(aCtx context.Context) int {
+	if (nil == t) || (nil == t.root.node) {
+		return 0
+	}
+
+	t.root.RLock()
+	compiled := compileNode(aCtx, t.root.node)
+	// Store while locked so no change can slip in between
+	t.compiled.Store(compiled)
+	t.root.RUnlock()
+
+	return compiled.size()
+} // Compile()
+
 // `Count()` returns the number of nodes and patterns in the trie.
 //
 // Parameters:
@@ -155,6 +210,39 @@ func (t *tTrie) Count(aCtx context.Context) (rNodes, rPatterns int) {
 	return
 } // Count()
 
+// `counters()` returns the trie's counters without the runtime
+// statistics of [Metrics], i.e. without forcing a garbage collection.
+//
+// The nodes and patterns are counted only again if the trie was
+// changed since the last call.
+//
+// Returns:
+//   - `*TCounters`: The trie's current counters.
+func (t *tTrie) counters() *TCounters {
+	if nil == t {
+		return nil
+	}
+
+	if !t.counted.Load() {
+		t.root.RLock()
+		if nil != t.root.node {
+			nodes, patterns := t.root.node.count(context.TODO())
+			t.numNodes.Store(uint32(nodes))       //#nosec G115
+			t.numPatterns.Store(uint32(patterns)) //#nosec G115
+		}
+		t.counted.Store(true)
+		t.root.RUnlock()
+	}
+
+	return &TCounters{
+		Nodes:    t.numNodes.Load(),
+		Patterns: t.numPatterns.Load(),
+		Hits:     t.numHits.Load(),
+		Misses:   t.numMisses.Load(),
+		Reloads:  t.numReloads.Load(),
+	}
+} // counters()
+
 // `Delete()` removes a pattern (FQDN or wildcard) from the list.
 //
 // The method returns a boolean value indicating whether the pattern
@@ -191,6 +279,8 @@ func (t *tTrie) Delete(aCtx context.Context, aPattern string) (rOK bool) {
 
 	t.root.Lock()
 	rOK = t.root.node.delete(aCtx, parts)
+	t.compiled.Store(nil)
+	t.counted.Store(false)
 	t.root.Unlock()
 
 	return
@@ -223,6 +313,28 @@ func (t *tTrie) Equal(aTrie *tTrie) (rOK bool) {
 	return
 } // Equal()
 
+// `Filter()` creates a Bloom filter of the trie's patterns used by
+// [Match] to quickly rule out hostnames not in the trie.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//
+// Returns:
+//   - `int`: The memory used by the filter.
+func (t *tTrie) Filter(aCtx context.Context) int {
+	if (nil == t) || (nil == t.root.node) {
+		return 0
+	}
+
+	t.root.RLock()
+	filter := newBloom(aCtx, t.root.node)
+	// Store while locked so no change can slip in between
+	t.filter.Store(filter)
+	t.root.RUnlock()
+
+	return filter.bytes()
+} // Filter()
+
 // `ForEach()` calls the given function for each node in the trie.
 //
 // Since all fields of the nodes in this trie are private, this method
@@ -232,7 +344,8 @@ func (t *tTrie) Equal(aTrie *tTrie) (rOK bool) {
 // The given `aFunc()` is called in a locked R/O context for each node in
 // the trie. That means that `aFunc()` can safely access the node's public
 // `String()` method while all of the node's internal fields remain private
-// (i.e. inaccessible).
+// (i.e. inaccessible). Since the trie is read-locked for the whole walk,
+// long walks should use [ForEachSnapshot] instead.
 //
 // Parameters:
 //   - `aCtx`: The timeout context to use for the operation.
@@ -251,6 +364,25 @@ func (t *tTrie) ForEach(aCtx context.Context, aFunc func(aNode *tNode)) {
 	t.root.RUnlock()
 } // ForEach()
 
+// `ForEachSnapshot()` calls the given function for each node of a
+// snapshot of the trie.
+//
+// Other than [ForEach] the trie is locked only while the snapshot is
+// copied, so a slow `aFunc()` doesn't block the trie's writers; it
+// just doesn't see their changes. The price is the snapshot's memory
+// during the walk.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//   - `aFunc`: The function to call for each node.
+func (t *tTrie) ForEachSnapshot(aCtx context.Context, aFunc func(aNode *tNode)) {
+	if (nil == t) || (nil == aFunc) {
+		return
+	}
+
+	t.snapshot(aCtx).forEach(aCtx, aFunc)
+} // ForEachSnapshot()
+
 // `loadLocal()` reads hostname patterns (FQDN or wildcards) from `aFilename`
 // and inserts them into the current trie.
 //
@@ -275,13 +407,21 @@ func (t *tTrie) loadLocal(aCtx context.Context, aFilename string) (rErr error) {
 		return
 	}
 
-	loader := &tSimpleLoader{}
-	t.root.Lock()
-	if rErr = loader.Load(aCtx, aFilename, t.root.node); nil == rErr {
-		t.lastLoadTime = time.Now()
-		t.filename = aFilename
-		t.url = ""
+	// Read the file without blocking the running queries and lock
+	// the trie for the (much faster) merge only
+	node := newNode()
+	if rErr = (&tSimpleLoader{}).Load(aCtx, aFilename, node); nil != rErr {
+		return
 	}
+
+	t.root.Lock()
+	t.root.node = t.root.node.merge(aCtx, node)
+	t.compiled.Store(nil)
+	t.counted.Store(false)
+	t.filter.Store(nil)
+	t.lastLoadTime = time.Now()
+	t.filename = aFilename
+	t.url = ""
 	t.root.Unlock()
 
 	return
@@ -301,44 +441,27 @@ const (
 //   - `aURL`: The URL to download the file from.
 //   - `aFilename`: The filename to save the data as.
 //   - `aNode`: The root node of the trie to load the patterns into.
+//   - `aOptions`: The loaders' settings.
 //
 // Returns:
 //   - `rErr`: `nil` if the file was downloaded and saved successfully, the error otherwise.
-func downAndSelectLoader(aCtx context.Context, aURL, aFilename string, aNode *tNode) (rErr error) {
+func downAndSelectLoader(aCtx context.Context, aURL, aFilename string, aNode *tNode, aOptions tLoadOptions) (rErr error) {
 
 	//TODO: Check whether there's a local copy of the file to download and
 	// use that instead of downloading it again. Consult the `lastLoadTime`
 	// Trie field and compare it with the file's modification time.
 
-	var (
-		filename string
-		loader   ILoader
-		mime     string
-	)
-	if filename, rErr = downloadFile(aURL, aFilename+downExt); nil != rErr {
+	var filename string
+	if filename, rErr = downloadFile(aCtx, aURL, aFilename+downExt); nil != rErr {
 		return
 	}
 	if rErr = aCtx.Err(); nil != rErr {
 		return
 	}
 	// Check file type and use appropriate loader
-	if mime, rErr = detectFileType(filename); nil != rErr {
-		return
-	}
-
-	switch mime {
-	case "text/x-abp":
-		loader = &tABPLoader{}
-	case "text/x-hosts":
-		loader = &tHostsLoader{}
-	case "text/x-hostnames":
-		loader = &tSimpleLoader{}
-	default:
+	if rErr = loadFile(aCtx, filename, aNode, aOptions); errors.Is(rErr, ErrUnsupportedMime) {
 		_ = os.Remove(filename)
-		rErr = ErrUnsupportedMime
-		return
 	}
-	rErr = loader.Load(aCtx, filename, aNode)
 
 	return
 } // downAndSelectLoader()
@@ -364,6 +487,9 @@ func saveLocally(aCtx context.Context, aFilename string, aNode *tNode) error {
 	if err = aCtx.Err(); nil != err {
 		return err
 	}
+	if err = writeListHeader(localFile); nil != err {
+		return err
+	}
 
 	return saver.Save(aCtx, localFile, aNode)
 } // saveLocally()
@@ -376,14 +502,18 @@ func saveLocally(aCtx context.Context, aFilename string, aNode *tNode) error {
 // wildcard syntax, neither are the patterns checked for invalid characters
 // or invalid endings.
 //
+// If the trie's `subdomains` flag is set, the subdomains of all the
+// list's hostnames are added as well (see `tNode.addSubdomains()`).
+//
 // Parameters:
 //   - `aCtx`: The context to use for the operation.
 //   - `aURL`: The URL to download the file from.
 //   - `aFilename`: The absolute path/name to read the patterns from.
+//   - `aStats`: Optional counters of the loaded lines and patterns.
 //
 // Returns:
 //   - `error`: `nil` if the patterns were read successfully, the error otherwise.
-func (t *tTrie) loadRemote(aCtx context.Context, aURL, aFilename string) (rErr error) {
+func (t *tTrie) loadRemote(aCtx context.Context, aURL, aFilename string, aStats *tLoadStats) (rErr error) {
 	if nil == t {
 		return ErrListNil
 	}
@@ -391,9 +521,14 @@ func (t *tTrie) loadRemote(aCtx context.Context, aURL, aFilename string) (rErr e
 	// so we can skip that here.
 	newRoot := newTrie()
 
-	if rErr = downAndSelectLoader(aCtx, aURL, aFilename, newRoot.root.node); nil != rErr {
+	if rErr = downAndSelectLoader(aCtx, aURL, aFilename, newRoot.root.node,
+		tLoadOptions{stats: aStats, validation: t.validation}); nil != rErr {
 		return
 	}
+	aStats.count(aCtx, newRoot.root.node)
+	if t.subdomains {
+		newRoot.root.node.addSubdomains(aCtx)
+	}
 	if rErr = aCtx.Err(); nil != rErr {
 		return
 	}
@@ -408,6 +543,9 @@ func (t *tTrie) loadRemote(aCtx context.Context, aURL, aFilename string) (rErr e
 
 	t.root.Lock()
 	t.root.node = newRoot.root.node
+	t.compiled.Store(nil)
+	t.counted.Store(false)
+	t.filter.Store(nil)
 	t.lastLoadTime = time.Now()
 	t.filename = aFilename
 	t.url = aURL
@@ -434,8 +572,12 @@ func (t *tTrie) Match(aCtx context.Context, aHostPattern string) (rOK bool) {
 		return
 	}
 
-	parts := pattern2parts(aHostPattern)
-	if 0 == len(parts) {
+	// Walk the hostname's labels in place instead of splitting
+	// it (like `pattern2parts()` does) and lowercase it on the
+	// stack to avoid any allocations.
+	var buf [maxHostLen]byte
+	host := lowerHost(strings.TrimSpace(aHostPattern), &buf)
+	if 0 == len(host) {
 		return
 	}
 
@@ -444,9 +586,16 @@ func (t *tTrie) Match(aCtx context.Context, aHostPattern string) (rOK bool) {
 		return
 	}
 
-	t.root.RLock()
-	rOK = t.root.node.match(aCtx, parts)
-	t.root.RUnlock()
+	if !t.filter.Load().mayMatch(host) {
+		// Definitely not in the trie
+		rOK = false
+	} else if compiled := t.compiled.Load(); nil != compiled {
+		rOK = compiled.match(host)
+	} else {
+		t.root.RLock()
+		rOK = t.root.node.matchHost(aCtx, host)
+		t.root.RUnlock()
+	}
 
 	if rOK {
 		t.numHits.Add(1)
@@ -457,6 +606,42 @@ func (t *tTrie) Match(aCtx context.Context, aHostPattern string) (rOK bool) {
 	return
 } // Match()
 
+// `matchingPattern()` returns the pattern of the trie that matches
+// the given hostname.
+//
+// If several patterns match, the most specific one is returned
+// according to the precedence rules described at `tTrie`.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//   - `aHostname`: The hostname to look for.
+//
+// Returns:
+//   - `string`: The matching pattern, an empty string if there's none.
+func (t *tTrie) matchingPattern(aCtx context.Context, aHostname string) string {
+	if (nil == t) || (nil == t.root.node) {
+		return ""
+	}
+	host := strings.Trim(strings.ToLower(strings.TrimSpace(aHostname)), ".")
+	if "" == host {
+		return ""
+	}
+
+	t.root.RLock()
+	defer t.root.RUnlock()
+
+	// Check for timeout or cancellation
+	if nil != aCtx.Err() {
+		return ""
+	}
+	var labels tPartsList
+	if !t.root.node.matchFrom(host, len(host), &labels) {
+		return ""
+	}
+
+	return strings.Join(labels, ".")
+} // matchingPattern()
+
 // `Merge()` merges the other trie into the current one.
 //
 // Parameters:
@@ -479,6 +664,9 @@ func (t *tTrie) Merge(aCtx context.Context, aTrie *tTrie) (rOK bool) {
 	t.root.Lock()
 	aTrie.root.RLock()
 	rOK = (nil != t.root.node.merge(aCtx, aTrie.root.node))
+	t.compiled.Store(nil)
+	t.counted.Store(false)
+	t.filter.Store(nil)
 	aTrie.root.RUnlock()
 	t.root.Unlock()
 
@@ -511,6 +699,7 @@ func (t *tTrie) Metrics() *TMetrics {
 
 	return &TMetrics{
 		PoolCreations: pm.Created,
+		PoolHits:      pm.Hits,
 		PoolReturns:   pm.Returned,
 		PoolSize:      pm.Size,
 		// ---
@@ -521,12 +710,92 @@ func (t *tTrie) Metrics() *TMetrics {
 		Reloads:  t.numReloads.Load(),
 		Retries:  t.numRetries.Load(),
 		// ---
+		SwapNs:    t.swapNs.Load(),
+		MaxSwapNs: t.maxSwapNs.Load(),
+		// ---
 		HeapAllocs:     m.Mallocs,
 		HeapFrees:      m.Frees,
 		GCPauseTotalNs: m.PauseTotalNs,
 	}
 } // Metrics()
 
+// `Patterns()` returns an iterator over all patterns in the trie.
+//
+// Other than [AllPatterns] the patterns are produced lazily in sorted
+// order, so huge lists can be walked (or a part of them) without
+// building a list of all patterns first.
+//
+// The trie is read-locked while the iteration runs, so the loop body
+// must not change the trie; lookups are not affected by the lock but
+// writers have to wait until the loop ends. Long running loops should
+// therefore break early or use a snapshot (see [ForEachSnapshot]).
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//
+// Returns:
+//   - `iter.Seq[string]`: The iterator over the patterns.
+func (t *tTrie) Patterns(aCtx context.Context) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		if nil == t {
+			return
+		}
+		t.root.RLock()
+		defer t.root.RUnlock()
+
+		for pattern := range t.root.node.patterns(aCtx) {
+			if !yield(pattern) {
+				return
+			}
+		}
+	}
+} // Patterns()
+
+// `save()` writes all patterns currently in the trie to the writer
+// using the given saver.
+//
+// Parameters:
+//   - `aCtx`: The context to use for the operation.
+//   - `aWriter`: The writer to write the patterns to.
+//   - `aSaver`: The saver determining the output format.
+//
+// Returns:
+//   - `error`: `nil` if the patterns were written successfully, the error otherwise.
+func (t *tTrie) save(aCtx context.Context, aWriter io.Writer, aSaver ISaver) error {
+	if (nil == t) || (nil == t.root.node) {
+		return ErrListNil
+	}
+
+	// Don't let a slow writer block the trie's updates
+	node := t.snapshot(aCtx)
+	if nil == node {
+		return aCtx.Err()
+	}
+
+	return aSaver.Save(aCtx, aWriter, node)
+} // save()
+
+// `snapshot()` returns a copy of the trie's patterns.
+//
+// The trie is read-locked just while the copy is made, so walking the
+// copy afterwards doesn't block the trie's writers.
+//
+// Parameters:
+//   - `aCtx`: The timeout context to use for the operation.
+//
+// Returns:
+//   - `*tNode`: The root node of the copy, `nil` in case of cancellation.
+func (t *tTrie) snapshot(aCtx context.Context) *tNode {
+	if (nil == t) || (nil != aCtx.Err()) {
+		return nil
+	}
+
+	t.root.RLock()
+	defer t.root.RUnlock()
+
+	return t.root.node.clone(aCtx)
+} // snapshot()
+
 // `storeFile()` writes all patterns currently in the trie to the file.
 //
 // The function uses a temporary file to write the patterns to, and then
@@ -544,6 +813,9 @@ func (t *tTrie) storeFile(aCtx context.Context, aFilename string) error {
 	if (nil == t) || (nil == t.root.node) {
 		return ErrListNil
 	}
+	if 0 == len(aFilename) {
+		return ErrInvalidFile
+	}
 
 	tmpName := aFilename + "~"
 	if _, err := os.Stat(tmpName); nil == err {
@@ -563,9 +835,14 @@ func (t *tTrie) storeFile(aCtx context.Context, aFilename string) error {
 	}
 	defer file.Close()
 
-	t.root.RLock()
-	err = t.root.node.store(aCtx, file)
-	t.root.RUnlock()
+	if err = writeListHeader(file); nil == err {
+		// Don't let the disk's speed block the trie's updates
+		if node := t.snapshot(aCtx); nil != node {
+			err = node.store(aCtx, file)
+		} else {
+			err = aCtx.Err()
+		}
+	}
 
 	if nil != err {
 		_ = os.Remove(tmpName)
@@ -593,6 +870,42 @@ func (t *tTrie) String() (rStr string) {
 	return
 } // String()
 
+// `swap()` replaces the trie's patterns by the given node.
+//
+// The new node (and its optional compiled copy and Bloom filter)
+// must be complete when calling this method, so the trie's write
+// lock is held just for exchanging the pointers; the lock's duration
+// is available by [Metrics].
+//
+// Parameters:
+//   - `aNode`: The new root node of the trie.
+//   - `aCompiled`: The node's compiled copy (may be `nil`).
+//   - `aFilter`: The node's Bloom filter (may be `nil`).
+func (t *tTrie) swap(aNode *tNode, aCompiled *tCompiled, aFilter *tBloom) {
+	if (nil == t) || (nil == aNode) {
+		return
+	}
+
+	t.root.Lock()
+	start := time.Now()
+	t.root.node = aNode
+	t.compiled.Store(aCompiled)
+	t.counted.Store(false)
+	t.filter.Store(aFilter)
+	t.lastLoadTime = start
+	duration := uint64(time.Since(start)) //#nosec G115
+	t.root.Unlock()
+
+	t.numReloads.Add(1)
+	t.swapNs.Store(duration)
+	for {
+		maxNs := t.maxSwapNs.Load()
+		if (duration <= maxNs) || t.maxSwapNs.CompareAndSwap(maxNs, duration) {
+			break
+		}
+	}
+} // swap()
+
 // `Update()` replaces an old pattern with a new one.
 //
 // The method first adds the new pattern and tries to delete the old one.
@@ -635,6 +948,9 @@ func (t *tTrie) Update(aCtx context.Context, aOldPattern, aNewPattern string) (r
 
 	t.root.Lock()
 	rOK = t.root.node.update(aCtx, oldParts, newParts)
+	t.compiled.Store(nil)
+	t.counted.Store(false)
+	t.filter.Load().add(newParts)
 	t.root.Unlock()
 
 	return
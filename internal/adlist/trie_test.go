@@ -8,8 +8,11 @@ package adlist
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"testing"
 )
 
@@ -458,6 +461,58 @@ func Test_tTrie_ForEach(t *testing.T) {
 	}
 } // Test_tTrie_ForEach()
 
+func Test_tTrie_ForEachSnapshot(t *testing.T) {
+	ctx := context.TODO()
+	trie := newTrie()
+	trie.Add(ctx, "ads.example.com")
+	trie.Add(ctx, "*.tracker.tld")
+	var wantNodes int
+	trie.ForEach(ctx, func(aNode *tNode) { wantNodes++ })
+
+	// Writers aren't blocked (which would deadlock here) and the
+	// walk doesn't see their changes
+	var nodes int
+	trie.ForEachSnapshot(ctx, func(aNode *tNode) {
+		nodes++
+		trie.Add(ctx, fmt.Sprintf("host%d.example.org", nodes))
+	})
+	if nodes != wantNodes {
+		t.Errorf("tTrie.ForEachSnapshot() visited '%d' nodes, want '%d'", nodes, wantNodes)
+	}
+	if !trie.Match(ctx, "host1.example.org") {
+		t.Error("tTrie.Add() during the walk wasn't applied")
+	}
+
+	var nilTrie *tTrie
+	nilTrie.ForEachSnapshot(ctx, func(aNode *tNode) { nodes++ })
+	trie.ForEachSnapshot(ctx, nil)
+} // Test_tTrie_ForEachSnapshot()
+
+func Test_tTrie_Patterns(t *testing.T) {
+	ctx := context.TODO()
+	trie := newTrie()
+	trie.Add(ctx, "ads.example.com")
+	trie.Add(ctx, "*.tracker.tld")
+	want := trie.AllPatterns(ctx)
+
+	if got := slices.Collect(trie.Patterns(ctx)); !slices.Equal(got, want) {
+		t.Errorf("tTrie.Patterns() = '%v', want '%v'", got, want)
+	}
+
+	// The trie is unlocked after breaking the loop
+	for range trie.Patterns(ctx) {
+		break
+	}
+	if !trie.Add(ctx, "www.example.com") {
+		t.Error("tTrie.Add() = 'false', want 'true'")
+	}
+
+	var nilTrie *tTrie
+	if got := slices.Collect(nilTrie.Patterns(ctx)); 0 != len(got) {
+		t.Errorf("tTrie.Patterns() = '%v', want '[]'", got)
+	}
+} // Test_tTrie_Patterns()
+
 func Test_tTrie_loadLocal(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -591,7 +646,7 @@ func Test_tTrie_loadRemote(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			gotErr := tc.trie.loadRemote(context.TODO(), tc.url, tc.fName)
+			gotErr := tc.trie.loadRemote(context.TODO(), tc.url, tc.fName, nil)
 
 			if (nil != gotErr) != tc.wantErr {
 				t.Errorf("tTrie.loadRemote() error = '%v', wantErr '%v'",
@@ -659,6 +714,68 @@ func Test_tTrie_Match(t *testing.T) {
 	}
 } // Test_tTrie_Match()
 
+func Test_tTrie_Match_wildcards(t *testing.T) {
+	ctx := context.TODO()
+	trie := newTrie()
+	for _, pattern := range []string{
+		"ads.*.example.com",
+		"*.tracking.*",
+		"cdn.*.*.example.net",
+		"*.example.org",
+		"ads.*.example.org",
+		"*.eu.example.org",
+		"*.tld",
+		"ads.*.sub.tld",
+	} {
+		trie.Add(ctx, pattern)
+	}
+
+	tests := []struct {
+		name        string
+		hostname    string
+		wantPattern string
+	}{
+		/* */
+		{"01 - mid wildcard", "ads.eu.example.com", "ads.*.example.com"},
+		{"02 - mid wildcard, no label", "ads.example.com", ""},
+		{"03 - mid wildcard, two labels", "ads.a.b.example.com", ""},
+		{"04 - mid wildcard, other label", "www.eu.example.com", ""},
+		{"05 - mid wildcard, subdomain", "x.ads.eu.example.com", ""},
+		{"06 - wildcard TLD", "www.tracking.com", "*.tracking.*"},
+		{"07 - wildcard TLD, two labels", "www.tracking.co.uk", ""},
+		{"08 - wildcard TLD, no subdomain", "tracking.com", ""},
+		{"09 - wildcard TLD, deep subdomain", "a.b.tracking.net", "*.tracking.*"},
+		{"10 - two mid wildcards", "cdn.a.b.example.net", "cdn.*.*.example.net"},
+		{"11 - two mid wildcards, one label", "cdn.a.example.net", ""},
+		{"12 - parent wildcard first", "x.eu.example.org", "*.eu.example.org"},
+		{"13 - leading before mid wildcard", "ads.us.example.org", "*.example.org"},
+		{"14 - literal before leading", "ads.x.sub.tld", "ads.*.sub.tld"},
+		{"15 - backtracking", "www.x.sub.tld", "*.tld"},
+		{"16 - upper case", "ADS.EU.Example.COM", "ads.*.example.com"},
+		/* */
+	}
+
+	check := func(aName string) {
+		for _, tc := range tests {
+			t.Run(aName+" "+tc.name, func(t *testing.T) {
+				want := ("" != tc.wantPattern)
+				if got := trie.Match(ctx, tc.hostname); got != want {
+					t.Errorf("tTrie.Match() = '%v', want '%v'", got, want)
+				}
+				if got := trie.matchingPattern(ctx, tc.hostname); got != tc.wantPattern {
+					t.Errorf("tTrie.matchingPattern() = '%s', want '%s'", got, tc.wantPattern)
+				}
+			})
+		}
+	}
+
+	check("mutable")
+	trie.Filter(ctx)
+	check("filtered")
+	trie.Compile(ctx)
+	check("compiled")
+} // Test_tTrie_Match_wildcards()
+
 func Test_tTrie_Merge(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -749,7 +866,7 @@ func Test_tTrie_Merge(t *testing.T) {
 
 func Test_tTrie_Metrics(t *testing.T) {
 	// np, _ := nodepool.Init(func() any {
-	// 	return &tNode{tChildren: make(tChildren)}
+	// 	return &tNode{tChildren: tChildren{}}
 	// }, 0)
 	tests := []struct {
 		name    string
@@ -991,7 +1108,7 @@ func Test_tTrie_String(t *testing.T) {
 				t.root.node.add(context.TODO(), tPartsList{"*", "domain"})
 				return t
 			}(),
-			want: "\"Trie\":\n  isEnd: false\n  isWild: false\n  \"*\":\n      isEnd: false\n      isWild: true\n      \"domain\":\n          isEnd: true\n          isWild: false\n",
+			want: "\"Trie\":\n  isEnd: false\n  isWild: false\n  \"*\":\n      isEnd: false\n      isWild: false\n      \"domain\":\n          isEnd: true\n          isWild: false\n",
 		},
 		{
 			name: "06 - trie with root and children and wildcard",
@@ -1009,7 +1126,7 @@ func Test_tTrie_String(t *testing.T) {
 				t.root.node.add(context.TODO(), tPartsList{"tld", "domain", "*", "sub"})
 				return t
 			}(),
-			want: "\"Trie\":\n  isEnd: false\n  isWild: false\n  \"tld\":\n      isEnd: false\n      isWild: false\n      \"domain\":\n          isEnd: false\n          isWild: false\n          \"*\":\n              isEnd: false\n              isWild: true\n              \"sub\":\n                  isEnd: true\n                  isWild: false\n",
+			want: "\"Trie\":\n  isEnd: false\n  isWild: false\n  \"tld\":\n      isEnd: false\n      isWild: false\n      \"domain\":\n          isEnd: false\n          isWild: false\n          \"*\":\n              isEnd: false\n              isWild: false\n              \"sub\":\n                  isEnd: true\n                  isWild: false\n",
 		},
 		/* */
 		// More tests are done on the node's method.
@@ -1096,4 +1213,83 @@ func Test_tTrie_Update(t *testing.T) {
 	}
 } // Test_tTrie_Update()
 
+func Test_tTrie_Match_allocs(t *testing.T) {
+	ctx := context.TODO()
+	trie := prepareCompiledTrie()
+	hosts := []string{"www.ads.tld", "tracker.example.com", "example.net",
+		"WWW.Ads.TLD", "Tracker.Example.COM"}
+
+	check := func(aName string) {
+		allocs := testing.AllocsPerRun(100, func() {
+			for _, host := range hosts {
+				_ = trie.Match(ctx, host)
+			}
+		})
+		if 0 != allocs {
+			t.Errorf("tTrie.Match() %s allocs = '%.1f', want '0'", aName, allocs)
+		}
+	}
+
+	check("mutable")
+	trie.Filter(ctx)
+	check("filtered")
+	trie.Compile(ctx)
+	check("compiled")
+} // Test_tTrie_Match_allocs()
+
+// `heapInUse()` returns the heap memory in use after a garbage collection.
+func heapInUse() uint64 {
+	var ms runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&ms)
+
+	return ms.HeapAlloc
+} // heapInUse()
+
+func Benchmark_tTrie_Add_memory(b *testing.B) {
+	const patterns = 100_000
+	subs := []string{"www", "ads", "ad", "track", "metrics", "cdn", "static", "pixel"}
+	tlds := []string{"com", "net", "org", "de"}
+	ctx := context.TODO()
+	b.ReportAllocs()
+
+	for range b.N {
+		b.StopTimer()
+		before := heapInUse()
+		// The patterns are created one by one as if read from a list file
+		lines := make([]string, 0, patterns)
+		for i := range patterns {
+			lines = append(lines, fmt.Sprintf("%s.site%d.%s",
+				subs[i%len(subs)], i/len(subs), tlds[(i/len(subs))%len(tlds)]))
+		}
+		b.StartTimer()
+
+		trie := newTrie()
+		for _, line := range lines {
+			trie.Add(ctx, line)
+		}
+
+		b.StopTimer()
+		lines = nil
+		b.ReportMetric(float64(heapInUse()-before)/patterns, "heapB/pattern")
+		runtime.KeepAlive(trie)
+		b.StartTimer()
+	}
+} // Benchmark_tTrie_Add_memory()
+
+func Benchmark_tTrie_Match(b *testing.B) {
+	trie := prepareCompiledBench()
+	hosts := make([]string, 1024)
+	for idx := range hosts {
+		hosts[idx] = fmt.Sprintf("www.site%d.com", idx*7)
+	}
+	ctx := context.TODO()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := range b.N {
+		_ = trie.Match(ctx, hosts[i&1023])
+	}
+} // Benchmark_tTrie_Match()
+
 /* _EoF_ */
@@ -0,0 +1,90 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+//lint:file-ignore ST1005 - I like capitalisation
+
+const (
+	// `listVersion` is the version of the list files written by
+	// `storeFile()` and `saveLocally()`.
+	//
+	// Version 0 files (i.e. those without a header) contain the same
+	// one-pattern-per-line syntax and are read unchanged; they get the
+	// current header when they are written the next time.
+	listVersion = 1
+
+	// `listHeader` starts the first line of a versioned list file.
+	listHeader = "# dnscache list v"
+)
+
+var (
+	// `ErrListVersion` is returned if a list file was written by a
+	// newer version of this package.
+	ErrListVersion = ADlistError{errors.New("List file written by a newer version")}
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `listFileVersion()` returns the version given by a list file's
+// header line.
+//
+// Parameters:
+//   - `aLine`: The (trimmed) line to check.
+//
+// Returns:
+//   - `int`: The file's version.
+//   - `bool`: `true` if the line is a version header, `false` otherwise.
+func listFileVersion(aLine string) (int, bool) {
+	rest, ok := strings.CutPrefix(aLine, listHeader)
+	if !ok {
+		return 0, false
+	}
+	version, err := strconv.Atoi(strings.TrimSpace(rest))
+	if (nil != err) || (0 > version) {
+		return 0, false
+	}
+
+	return version, true
+} // listFileVersion()
+
+// `preserveNewerList()` renames a list file written by a newer version
+// so it won't be overwritten by the next `storeFile()`.
+//
+// Parameters:
+//   - `aFilename`: The list file that couldn't be loaded.
+//   - `aErr`: The error returned by the loader.
+func preserveNewerList(aFilename string, aErr error) {
+	if errors.Is(aErr, ErrListVersion) {
+		_ = os.Rename(aFilename, aFilename+".newer")
+	}
+} // preserveNewerList()
+
+// `writeListHeader()` writes the version header of a list file.
+//
+// Parameters:
+//   - `aWriter`: The writer to write the header to.
+//
+// Returns:
+//   - `error`: `nil` if the header was written, the error otherwise.
+func writeListHeader(aWriter io.Writer) error {
+	_, err := fmt.Fprintf(aWriter, "%s%d\n", listHeader, listVersion)
+
+	return err
+} // writeListHeader()
+
+/* _EoF_ */
@@ -0,0 +1,135 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package adlist
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_listFileVersion(t *testing.T) {
+	tests := []struct {
+		name   string
+		line   string
+		want   int
+		wantOK bool
+	}{
+		/* */
+		{
+			name:   "01 - current header",
+			line:   "# dnscache list v1",
+			want:   1,
+			wantOK: true,
+		},
+		{
+			name:   "02 - newer header",
+			line:   "# dnscache list v42",
+			want:   42,
+			wantOK: true,
+		},
+		{
+			name:   "03 - plain comment",
+			line:   "# some comment",
+			wantOK: false,
+		},
+		{
+			name:   "04 - invalid version",
+			line:   "# dnscache list vX",
+			wantOK: false,
+		},
+		{
+			name:   "05 - negative version",
+			line:   "# dnscache list v-1",
+			wantOK: false,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := listFileVersion(tc.line)
+			if ok != tc.wantOK {
+				t.Errorf("listFileVersion() ok = '%v', want '%v'", ok, tc.wantOK)
+			}
+			if got != tc.want {
+				t.Errorf("listFileVersion() = '%d', want '%d'", got, tc.want)
+			}
+		})
+	}
+} // Test_listFileVersion()
+
+func Test_writeListHeader(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeListHeader(&buf); nil != err {
+		t.Fatalf("writeListHeader() error = '%v'", err)
+	}
+
+	line := strings.TrimSpace(buf.String())
+	if got, ok := listFileVersion(line); !ok || (listVersion != got) {
+		t.Errorf("writeListHeader() = '%s', want version '%d'", line, listVersion)
+	}
+} // Test_writeListHeader()
+
+func Test_listFileRoundTrip(t *testing.T) {
+	ctx := context.TODO()
+	dir := t.TempDir()
+	deny := filepath.Join(dir, adDenyFile)
+	want := []string{"*.ads.localdomain", "tracker.localdomain"}
+
+	// Files without a header are read as version 0 …
+	if err := os.WriteFile(deny, []byte(strings.Join(want, "\n")+"\n"), 0600); nil != err {
+		t.Fatal(err)
+	}
+	adl := New(dir)
+	got := adl.DenyPatterns(ctx)
+	slices.Sort(got)
+	if !slices.Equal(got, want) {
+		t.Errorf("New(v0) = '%v', want '%v'", got, want)
+	}
+
+	// … and get the version header when they're saved again
+	if err := adl.StoreDeny(ctx); nil != err {
+		t.Fatalf("StoreDeny() error = '%v'", err)
+	}
+	data, err := os.ReadFile(deny) //#nosec G304
+	if nil != err {
+		t.Fatal(err)
+	}
+	first, _, _ := strings.Cut(string(data), "\n")
+	if version, ok := listFileVersion(first); !ok || (listVersion != version) {
+		t.Errorf("StoreDeny() header = '%s', want version '%d'", first, listVersion)
+	}
+	got = New(dir).DenyPatterns(ctx)
+	slices.Sort(got)
+	if !slices.Equal(got, want) {
+		t.Errorf("New(v%d) = '%v', want '%v'", listVersion, got, want)
+	}
+
+	// Files of a newer version are set aside, not overwritten
+	newer := "# dnscache list v99\nfuture.localdomain\n"
+	if err = os.WriteFile(deny, []byte(newer), 0600); nil != err {
+		t.Fatal(err)
+	}
+	if got = New(dir).DenyPatterns(ctx); 0 != len(got) {
+		t.Errorf("New(v99) = '%v', want empty", got)
+	}
+	if _, err = os.Stat(deny); !os.IsNotExist(err) {
+		t.Errorf("New(v99) kept '%s', want it renamed", deny)
+	}
+	if data, err = os.ReadFile(deny + ".newer"); (nil != err) || (newer != string(data)) { //#nosec G304
+		t.Errorf("New(v99) = '%s' (%v), want '%s'", data, err, newer)
+	}
+} // Test_listFileRoundTrip()
+
+/* _EoF_ */
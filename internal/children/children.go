@@ -0,0 +1,209 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package children
+
+import (
+	"iter"
+	"slices"
+	"sort"
+	"strings"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+const (
+	// `maxListChildren` is the number of children a node keeps in a
+	// sorted slice before switching to a map.
+	maxListChildren = 8
+)
+
+type (
+	// `tChild` is a labelled child node.
+	tChild[N any] struct {
+		label string
+		node  N
+	}
+
+	// `TChildren` holds the children nodes of a trie's node.
+	//
+	// Most nodes have only a few children which are kept in a slice
+	// sorted by label (using much less memory than a map). Beyond
+	// `maxListChildren` children a map is used instead.
+	//
+	// The zero value is an empty list of children ready to use.
+	TChildren[N any] struct {
+		list []tChild[N]  // sorted children of small nodes
+		kids map[string]N // children of large nodes
+	}
+)
+
+// ---------------------------------------------------------------------------
+// `TChildren` constructor:
+
+// `New()` returns the given nodes as children.
+//
+// Parameters:
+//   - `aKids`: The children nodes by their labels.
+//
+// Returns:
+//   - `TChildren[N]`: The children.
+func New[N any](aKids map[string]N) (rChildren TChildren[N]) {
+	for label, node := range aKids {
+		rChildren.Put(label, node)
+	}
+
+	return
+} // New()
+
+// ---------------------------------------------------------------------------
+// `TChildren` methods:
+
+// `All()` returns an iterator over all children (in no particular order).
+//
+// Returns:
+//   - `iter.Seq2[string, N]`: The iterator over labels and nodes.
+func (c *TChildren[N]) All() iter.Seq2[string, N] {
+	return func(aYield func(string, N) bool) {
+		if nil != c.kids {
+			for label, node := range c.kids {
+				if !aYield(label, node) {
+					return
+				}
+			}
+			return
+		}
+		for _, child := range c.list {
+			if !aYield(child.label, child.node) {
+				return
+			}
+		}
+	}
+} // All()
+
+// `Get()` returns the child with the given label.
+//
+// Parameters:
+//   - `aLabel`: The label of the child to return.
+//
+// Returns:
+//   - `N`: The child node, the zero value if not found.
+//   - `bool`: `true` if the child was found, `false` otherwise.
+func (c *TChildren[N]) Get(aLabel string) (N, bool) {
+	if nil != c.kids {
+		node, ok := c.kids[aLabel]
+		return node, ok
+	}
+	// For a few children a linear search is fastest
+	for _, child := range c.list {
+		if child.label == aLabel {
+			return child.node, true
+		}
+	}
+
+	var none N
+	return none, false
+} // Get()
+
+// `Labels()` returns the sorted labels of all children.
+//
+// Returns:
+//   - `[]string`: The sorted labels.
+func (c *TChildren[N]) Labels() []string {
+	if nil != c.kids {
+		result := make([]string, 0, len(c.kids))
+		for label := range c.kids {
+			result = append(result, label)
+		}
+		sort.Strings(result)
+
+		return result
+	}
+
+	result := make([]string, len(c.list))
+	for idx, child := range c.list {
+		result[idx] = child.label
+	}
+
+	return result
+} // Labels()
+
+// `Put()` adds or replaces the child with the given label.
+//
+// Parameters:
+//   - `aLabel`: The label of the child.
+//   - `aNode`: The child node.
+func (c *TChildren[N]) Put(aLabel string, aNode N) {
+	if nil != c.kids {
+		c.kids[aLabel] = aNode
+		return
+	}
+
+	idx, found := slices.BinarySearchFunc(c.list, aLabel, func(aChild tChild[N], aTarget string) int {
+		return strings.Compare(aChild.label, aTarget)
+	})
+	if found {
+		c.list[idx].node = aNode
+		return
+	}
+	if maxListChildren > len(c.list) {
+		c.list = slices.Insert(c.list, idx, tChild[N]{aLabel, aNode})
+		return
+	}
+
+	// Too many children: switch to a map
+	c.kids = make(map[string]N, len(c.list)+1)
+	for _, child := range c.list {
+		c.kids[child.label] = child.node
+	}
+	c.kids[aLabel] = aNode
+	c.list = nil
+} // Put()
+
+// `Remove()` deletes the child with the given label.
+//
+// Parameters:
+//   - `aLabel`: The label of the child to remove.
+func (c *TChildren[N]) Remove(aLabel string) {
+	if nil == c.kids {
+		c.list = slices.DeleteFunc(c.list, func(aChild tChild[N]) bool {
+			return aChild.label == aLabel
+		})
+		if 0 == len(c.list) {
+			c.list = nil
+		}
+		return
+	}
+
+	delete(c.kids, aLabel)
+	// Switch back to a slice if the node got small enough again
+	// (only at half the limit to avoid flapping).
+	if (maxListChildren >> 1) < len(c.kids) {
+		return
+	}
+	list := make([]tChild[N], 0, len(c.kids))
+	for label, node := range c.kids {
+		list = append(list, tChild[N]{label, node})
+	}
+	slices.SortFunc(list, func(a, b tChild[N]) int {
+		return strings.Compare(a.label, b.label)
+	})
+	c.list, c.kids = list, nil
+} // Remove()
+
+// `Size()` returns the number of children.
+//
+// Returns:
+//   - `int`: The number of children.
+func (c *TChildren[N]) Size() int {
+	if nil != c.kids {
+		return len(c.kids)
+	}
+
+	return len(c.list)
+} // Size()
+
+/* _EoF_ */
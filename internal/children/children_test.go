@@ -0,0 +1,155 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package children
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tTestNode` is the node type used by the tests.
+	tTestNode struct {
+		id int
+	}
+)
+
+func Test_TChildren_Put(t *testing.T) {
+	tests := []struct {
+		name      string
+		count     int
+		wantMap   bool
+		wantFirst string
+	}{
+		/* */
+		{"01 - empty", 0, false, ""},
+		{"02 - one", 1, false, "label0"},
+		{"03 - limit", maxListChildren, false, "label0"},
+		{"04 - beyond limit", maxListChildren + 1, true, "label0"},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var c TChildren[*tTestNode]
+			// add in reverse order to check the sorting
+			for i := tc.count - 1; 0 <= i; i-- {
+				c.Put(fmt.Sprintf("label%d", i), &tTestNode{i})
+			}
+			if got := c.Size(); got != tc.count {
+				t.Errorf("TChildren.Size() = '%d', want '%d'", got, tc.count)
+			}
+			if got := (nil != c.kids); got != tc.wantMap {
+				t.Errorf("TChildren uses map = '%v', want '%v'", got, tc.wantMap)
+			}
+			labels := c.Labels()
+			if !slices.IsSorted(labels) {
+				t.Errorf("TChildren.Labels() = '%v', want sorted", labels)
+			}
+			if 0 == len(labels) {
+				return
+			}
+			if labels[0] != tc.wantFirst {
+				t.Errorf("TChildren.Labels()[0] = '%s', want '%s'",
+					labels[0], tc.wantFirst)
+			}
+			for _, label := range labels {
+				if _, ok := c.Get(label); !ok {
+					t.Errorf("TChildren.Get(%q) = 'false', want 'true'", label)
+				}
+			}
+		})
+	}
+} // Test_TChildren_Put()
+
+func Test_TChildren_Get(t *testing.T) {
+	node := &tTestNode{1}
+	c := New(map[string]*tTestNode{"tld": node})
+
+	if got, ok := c.Get("tld"); !ok || (got != node) {
+		t.Errorf("TChildren.Get() = '%v', '%v', want '%v', 'true'", got, ok, node)
+	}
+	if got, ok := c.Get("com"); ok || (nil != got) {
+		t.Errorf("TChildren.Get() = '%v', '%v', want 'nil', 'false'", got, ok)
+	}
+
+	// Replacing a child keeps the number of children
+	other := &tTestNode{2}
+	c.Put("tld", other)
+	if got, _ := c.Get("tld"); (got != other) || (1 != c.Size()) {
+		t.Errorf("TChildren.Get() = '%v', want '%v'", got, other)
+	}
+} // Test_TChildren_Get()
+
+func Test_TChildren_All(t *testing.T) {
+	for _, count := range []int{0, maxListChildren, maxListChildren + 1} {
+		kids := make(map[string]*tTestNode, count)
+		for i := range count {
+			kids[fmt.Sprintf("label%d", i)] = &tTestNode{i}
+		}
+		c := New(kids)
+
+		seen := 0
+		for label, node := range c.All() {
+			if kids[label] != node {
+				t.Errorf("TChildren.All() = %q, '%v', want '%v'", label, node, kids[label])
+			}
+			seen++
+		}
+		if seen != count {
+			t.Errorf("TChildren.All() yielded '%d' children, want '%d'", seen, count)
+		}
+	}
+} // Test_TChildren_All()
+
+func Test_TChildren_Remove(t *testing.T) {
+	var c TChildren[*tTestNode]
+	for i := range maxListChildren + 2 {
+		c.Put(fmt.Sprintf("label%d", i), &tTestNode{i})
+	}
+
+	tests := []struct {
+		name     string
+		label    string
+		wantSize int
+		wantMap  bool
+	}{
+		/* */
+		{"01 - unknown", "unknown", maxListChildren + 2, true},
+		{"02 - map", "label0", maxListChildren + 1, true},
+		{"03 - map", "label1", maxListChildren, true},
+		{"04 - map", "label2", maxListChildren - 1, true},
+		{"05 - map", "label3", maxListChildren - 2, true},
+		{"06 - map", "label4", maxListChildren - 3, true},
+		{"07 - back to slice", "label5", maxListChildren >> 1, false},
+		{"08 - slice", "label9", (maxListChildren >> 1) - 1, false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c.Remove(tc.label)
+			if got := c.Size(); got != tc.wantSize {
+				t.Errorf("TChildren.Size() = '%d', want '%d'", got, tc.wantSize)
+			}
+			if got := (nil != c.kids); got != tc.wantMap {
+				t.Errorf("TChildren uses map = '%v', want '%v'", got, tc.wantMap)
+			}
+			if _, ok := c.Get(tc.label); ok {
+				t.Errorf("TChildren.Get(%q) = 'true', want 'false'", tc.label)
+			}
+			if labels := c.Labels(); !slices.IsSorted(labels) {
+				t.Errorf("TChildren.Labels() = '%v', want sorted", labels)
+			}
+		})
+	}
+} // Test_TChildren_Remove()
+
+/* _EoF_ */
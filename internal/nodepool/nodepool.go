@@ -33,13 +33,28 @@ type (
 	//
 	//   - `Size`: Current number of items in the pool.
 	//   - `Created`: Number of items created by the pool.
+	//   - `Hits`: Number of items taken from the pool.
 	//   - `Returned`: Number of items returned to the pool.
 	TPoolMetrics struct {
 		Size     int
 		Created  uint32
+		Hits     uint32
 		Returned uint32
 	}
 
+	// `TPoolOptions` contains the settings of a pool.
+	//
+	//   - `InitSize`: Number of items to pre-allocate (default: 512).
+	//   - `MaxSize`: Maximum number of retained items (default: four
+	//     times `InitSize`).
+	//   - `Disabled`: Whether to bypass the pool, i.e. always create
+	//     new items and drop the returned ones.
+	TPoolOptions struct {
+		InitSize int
+		MaxSize  int
+		Disabled bool
+	}
+
 	// `TPool` is a bounded pool of items.
 	//
 	// The pool is inherently thread-safe. Its size is fixed and can't
@@ -52,7 +67,9 @@ type (
 		nodes    chan any           // Bounded channel for items
 		mCh      chan *TPoolMetrics // Channel for pool metrics
 		created  atomic.Uint32      // Number of items created
+		hits     atomic.Uint32      // Number of items taken from the pool
 		returned atomic.Uint32      // Number of items returned
+		disabled bool               // Whether to bypass the pool
 	}
 
 	// `TPoolError` is returned if the pool is not fully initialised.
@@ -99,11 +116,59 @@ func Init(aNewFunc func() any, aSize int) (rPool *TPool) {
 	if nil != aNewFunc {
 		rPool.New = aNewFunc
 	}
-	rPool.reset(aSize)
+	rPool.reset(aSize, 0)
 
 	return
 } // Init()
 
+// `InitWithOptions()` initialises a new pool with the given settings.
+//
+// It works like [Init] but allows to limit the number of retained items
+// independently of the pre-allocated ones, or to disable pooling
+// altogether (e.g. to compare the memory usage with and without pool).
+//
+// Parameters:
+//   - `aNewFunc`: Factory function for creating new items.
+//   - `aOptions`: The pool's settings.
+//
+// Returns:
+//   - `*TPool`: A new pool.
+func InitWithOptions(aNewFunc func() any, aOptions TPoolOptions) (rPool *TPool) {
+	rPool = &TPool{
+		New:      aNewFunc,
+		disabled: aOptions.Disabled,
+	}
+	if aOptions.Disabled {
+		// Keep the channels valid but don't pre-allocate anything
+		rPool.nodes = make(chan any)
+		rPool.mCh = make(chan *TPoolMetrics, 1)
+		return
+	}
+	rPool.reset(aOptions.InitSize, aOptions.MaxSize)
+
+	return
+} // InitWithOptions()
+
+// ---------------------------------------------------------------------------
+// `TPoolMetrics` methods:
+
+// `HitRate()` returns the share of requested items that were taken
+// from the pool instead of being newly created.
+//
+// A low hit rate means the pool is too small (or too many items are
+// dropped); a hit rate close to `1` while the pool's `Size` stays
+// high means it could be smaller.
+//
+// Returns:
+//   - `float64`: The hit rate between `0` and `1`.
+func (m *TPoolMetrics) HitRate() float64 {
+	if (nil == m) || (0 == m.Hits+m.Created) {
+		return 0
+	}
+
+	return float64(m.Hits) / float64(m.Hits+m.Created)
+} // HitRate()
+
 // ---------------------------------------------------------------------------
 // `TPool` methods:
 
@@ -129,6 +194,7 @@ func (p *TPool) Clear() {
 
 	// Reset counters
 	p.created.Store(0)
+	p.hits.Store(0)
 	p.returned.Store(0)
 } // Clear()
 
@@ -152,13 +218,14 @@ func (p *TPool) Get() (rNode any, rErr error) {
 	}
 	if nil == p.nodes {
 		// Pool not initialised yet
-		p.reset(0)
+		p.reset(0, 0)
 	}
 	var c uint32
 
 	select {
 	case rNode = <-p.nodes:
 		// Item was taken from pool
+		p.hits.Add(1)
 	default:
 		rNode, c = p.newNode()
 	}
@@ -176,6 +243,7 @@ func (p *TPool) Get() (rNode any, rErr error) {
 // The returned metrics show:
 //   - `Size`: Current number of items in the pool.
 //   - `Created`: Number of items created by the pool.
+//   - `Hits`: Number of items taken from the pool.
 //   - `Returned`: Number of items returned to the pool.
 //
 // Returns:
@@ -187,11 +255,12 @@ func (p *TPool) Metrics() (rMetric *TPoolMetrics, rErr error) {
 		return
 	}
 	if nil == p.nodes {
-		p.reset(0)
+		p.reset(0, 0)
 	}
 	rMetric = &TPoolMetrics{
 		Size:     len(p.nodes),
 		Created:  p.created.Load(),
+		Hits:     p.hits.Load(),
 		Returned: p.returned.Load(),
 	}
 
@@ -218,7 +287,7 @@ func (p *TPool) MetricsChannel() (rChan <-chan *TPoolMetrics, rErr error) {
 	}
 	if nil == p.mCh {
 		// Pool not initialised yet
-		p.reset(0)
+		p.reset(0, 0)
 	}
 	rChan = p.mCh
 
@@ -264,14 +333,14 @@ func (p *TPool) Put(aNode any) (rErr error) {
 	}
 	if nil == p.nodes {
 		// Pool not initialised yet
-		p.reset(0)
+		p.reset(0, 0)
 	}
 	if nil == aNode {
 		return
 	}
 
 	r := p.returned.Add(1)
-	if (r & poolDropMask) == poolDropMask {
+	if p.disabled || ((r & poolDropMask) == poolDropMask) {
 		// Drop the item if the drop mask matches.
 		// This leaves the given `aNode` for GC.
 		// With a drop mask of `7` (0111) we drop 1 in 8 items.
@@ -293,11 +362,17 @@ func (p *TPool) Put(aNode any) (rErr error) {
 //
 // Parameters:
 //   - `aSize`: Initial size of the pool.
-func (p *TPool) reset(aSize int) {
+//   - `aMaxSize`: Maximum size of the pool (`0` for four times `aSize`).
+func (p *TPool) reset(aSize, aMaxSize int) {
 	if 0 >= aSize {
 		aSize = poolInitSize
 	}
-	p.nodes = make(chan any, aSize<<2)
+	if 0 >= aMaxSize {
+		aMaxSize = aSize << 2
+	} else if aSize > aMaxSize {
+		aSize = aMaxSize
+	}
+	p.nodes = make(chan any, aMaxSize)
 	p.mCh = make(chan *TPoolMetrics, 1) // pool metrics
 
 	// Pre-allocate some items for the pool:
@@ -249,6 +249,23 @@ func Test_TPool_Metrics(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "09 - after taking a pooled node",
+			pool: np,
+			prepare: func() {
+				clear(np)
+				node, _ := np.Get()
+				np.Put(node)
+				_, _ = np.Get()
+			},
+			want: &TPoolMetrics{
+				Size:     0,
+				Created:  1,
+				Hits:     1,
+				Returned: 1,
+			},
+			wantErr: false,
+		},
 		/* */
 		// TODO: Add test cases.
 	}
@@ -281,6 +298,11 @@ func Test_TPool_Metrics(t *testing.T) {
 					got.Created, tc.want.Created)
 			}
 
+			if got.Hits != tc.want.Hits {
+				t.Errorf("Metrics().Hits = %v, want %v",
+					got.Hits, tc.want.Hits)
+			}
+
 			if got.Returned != tc.want.Returned {
 				t.Errorf("Metrics().Returned = %v, want %v",
 					got.Returned, tc.want.Returned)
@@ -505,4 +527,69 @@ func Test_TPool_Put(t *testing.T) {
 	}
 } // Test_TPool_Put()
 
+func Test_InitWithOptions(t *testing.T) {
+	newFunc := func() any { return "item" }
+	tests := []struct {
+		name     string
+		options  TPoolOptions
+		wantSize int
+		wantCap  int
+	}{
+		/* */
+		{"01 - defaults", TPoolOptions{}, 448, 2048},
+		{"02 - init size", TPoolOptions{InitSize: 8}, 7, 32},
+		{"03 - max size", TPoolOptions{InitSize: 8, MaxSize: 10}, 7, 10},
+		{"04 - init beyond max", TPoolOptions{InitSize: 16, MaxSize: 8}, 7, 8},
+		{"05 - disabled", TPoolOptions{InitSize: 8, Disabled: true}, 0, 0},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pool := InitWithOptions(newFunc, tc.options)
+			m, _ := pool.Metrics()
+			if m.Size != tc.wantSize {
+				t.Errorf("Metrics().Size = %d, want %d", m.Size, tc.wantSize)
+			}
+			if got := cap(pool.nodes); got != tc.wantCap {
+				t.Errorf("cap(nodes) = %d, want %d", got, tc.wantCap)
+			}
+		})
+	}
+
+	// A disabled pool always creates new items
+	pool := InitWithOptions(newFunc, TPoolOptions{Disabled: true})
+	for range 3 {
+		item, _ := pool.Get()
+		pool.Put(item)
+	}
+	if m, _ := pool.Metrics(); (0 != m.Size) || (3 != m.Created) || (0 != m.Hits) {
+		t.Errorf("Metrics() = %+v, want 3 created items", *m)
+	}
+} // Test_InitWithOptions()
+
+func Test_TPoolMetrics_HitRate(t *testing.T) {
+	tests := []struct {
+		name    string
+		metrics *TPoolMetrics
+		want    float64
+	}{
+		/* */
+		{"01 - nil", nil, 0},
+		{"02 - empty", &TPoolMetrics{}, 0},
+		{"03 - no hits", &TPoolMetrics{Created: 4}, 0},
+		{"04 - some hits", &TPoolMetrics{Created: 1, Hits: 3}, 0.75},
+		{"05 - only hits", &TPoolMetrics{Hits: 2}, 1},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.metrics.HitRate(); got != tc.want {
+				t.Errorf("HitRate() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+} // Test_TPoolMetrics_HitRate()
+
 /* _EoF_ */
@@ -0,0 +1,649 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package sqlite
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `TRow` is a table's row mapping the column names to their
+	// values (`nil`, `int64`, `float64`, `string`, or `[]byte`).
+	TRow map[string]any
+
+	// `TDatabase` reads the tables of an SQLite database file.
+	//
+	// Only the file's (checkpointed) content is read, i.e. changes
+	// still kept in a write-ahead log are missing. Tables created
+	// `WITHOUT ROWID` and databases not encoded in UTF-8 aren't
+	// supported.
+	TDatabase struct {
+		file     *os.File
+		pageSize int               // size of a page in bytes
+		usable   int               // usable size of a page in bytes
+		pages    int               // number of pages in the file
+		tables   map[string]tTable // table name → table
+	}
+
+	// `tTable` describes a table of the database.
+	tTable struct {
+		root    int      // the table's root page
+		columns []string // the column names in order of definition
+		rowid   int      // index of the `INTEGER PRIMARY KEY` column (`-1`: none)
+	}
+)
+
+const (
+	// `headerMagic` starts each SQLite database file.
+	headerMagic = "SQLite format 3\x00"
+
+	// `headerSize` is the size of the database file's header.
+	headerSize = 100
+
+	// B-tree page types
+	pageInteriorTable = 0x05
+	pageLeafTable     = 0x0d
+
+	// `maxDepth` limits the depth of a b-tree (guarding against
+	// corrupted files).
+	maxDepth = 64
+)
+
+var (
+	// `ErrFormat` is returned for files not being a supported
+	// SQLite database.
+	ErrFormat = errors.New("invalid or unsupported sqlite database")
+
+	// `ErrNoTable` is returned for a table not in the database.
+	ErrNoTable = errors.New("no such table")
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `decodeRecord()` decodes a record's values.
+//
+// Parameters:
+//   - `aRecord`: The record to decode.
+//
+// Returns:
+//   - `[]any`: The record's values.
+//   - `error`: `nil` if the record was decoded, the error otherwise.
+func decodeRecord(aRecord []byte) ([]any, error) {
+	hdrSize, n := varint(aRecord)
+	if (0 == n) || (uint64(len(aRecord)) < hdrSize) || (uint64(n) > hdrSize) {
+		return nil, ErrFormat
+	}
+	header, body := aRecord[n:hdrSize], aRecord[hdrSize:]
+
+	var result []any
+	for 0 < len(header) {
+		serial, n := varint(header)
+		if 0 == n {
+			return nil, ErrFormat
+		}
+		header = header[n:]
+
+		size := serialSize(serial)
+		if uint64(len(body)) < size {
+			return nil, ErrFormat
+		}
+		data := body[:size]
+		body = body[size:]
+
+		switch {
+		case 0 == serial:
+			result = append(result, nil)
+		case 7 == serial:
+			result = append(result, math.Float64frombits(binary.BigEndian.Uint64(data)))
+		case 8 == serial:
+			result = append(result, int64(0))
+		case 9 == serial:
+			result = append(result, int64(1))
+		case 7 > serial:
+			var value int64
+			if 0 < len(data) && (0 != data[0]&0x80) {
+				value = -1 // sign extension
+			}
+			for _, b := range data {
+				value = (value << 8) | int64(b)
+			}
+			result = append(result, value)
+		case 12 > serial:
+			return nil, ErrFormat // reserved types
+		case 0 == serial&1:
+			result = append(result, append([]byte(nil), data...))
+		default:
+			result = append(result, string(data))
+		}
+	}
+
+	return result, nil
+} // decodeRecord()
+
+// `parseColumns()` returns the column names of a `CREATE TABLE`
+// statement.
+//
+// Parameters:
+//   - `aSQL`: The table's `CREATE TABLE` statement.
+//
+// Returns:
+//   - `[]string`: The column names in order of definition.
+//   - `int`: The index of the `INTEGER PRIMARY KEY` column (`-1`: none).
+//   - `error`: `nil` if the statement was parsed, the error otherwise.
+func parseColumns(aSQL string) ([]string, int, error) {
+	start := strings.IndexByte(aSQL, '(')
+	end := strings.LastIndexByte(aSQL, ')')
+	if (0 > start) || (end < start) {
+		return nil, -1, fmt.Errorf("%w: %q", ErrFormat, aSQL)
+	}
+	if strings.Contains(strings.ToUpper(aSQL[end:]), "WITHOUT ROWID") {
+		return nil, -1, fmt.Errorf("%w: table without rowid", ErrFormat)
+	}
+
+	var (
+		columns []string
+		rowid   = -1
+	)
+	for _, def := range splitDefinitions(aSQL[start+1 : end]) {
+		name, rest := splitName(def)
+		if "" == name {
+			continue
+		}
+		switch strings.ToUpper(name) {
+		case "CONSTRAINT", "PRIMARY", "UNIQUE", "CHECK", "FOREIGN":
+			continue // a table constraint
+		}
+		words := strings.Fields(strings.ToUpper(rest))
+		if (3 <= len(words)) && ("INTEGER" == words[0]) &&
+			("PRIMARY" == words[1]) && ("KEY" == words[2]) {
+			rowid = len(columns)
+		}
+		columns = append(columns, name)
+	}
+
+	return columns, rowid, nil
+} // parseColumns()
+
+// `serialSize()` returns the size of a record's value.
+//
+// Parameters:
+//   - `aSerial`: The value's serial type.
+//
+// Returns:
+//   - `uint64`: The value's size in bytes.
+func serialSize(aSerial uint64) uint64 {
+	switch aSerial {
+	case 1, 2, 3, 4:
+		return aSerial
+	case 5:
+		return 6
+	case 6, 7:
+		return 8
+	}
+	if 12 > aSerial {
+		return 0
+	}
+
+	return (aSerial - 12 - (aSerial & 1)) / 2
+} // serialSize()
+
+// `splitDefinitions()` splits a table's definitions at the commas
+// outside of parentheses and quotes.
+//
+// Parameters:
+//   - `aDefs`: The definitions to split.
+//
+// Returns:
+//   - `[]string`: The single definitions.
+func splitDefinitions(aDefs string) []string {
+	var (
+		result []string
+		depth  int
+		quote  byte
+		start  int
+	)
+	for idx := 0; idx < len(aDefs); idx++ {
+		c := aDefs[idx]
+		switch {
+		case 0 != quote:
+			if c == quote {
+				quote = 0
+			}
+		case ('"' == c) || ('\'' == c) || ('`' == c):
+			quote = c
+		case '[' == c:
+			quote = ']'
+		case '(' == c:
+			depth++
+		case ')' == c:
+			depth--
+		case (',' == c) && (0 == depth):
+			result = append(result, strings.TrimSpace(aDefs[start:idx]))
+			start = idx + 1
+		}
+	}
+
+	return append(result, strings.TrimSpace(aDefs[start:]))
+} // splitDefinitions()
+
+// `splitName()` splits a definition's (possibly quoted) name from
+// the rest.
+//
+// Parameters:
+//   - `aDef`: The definition to split.
+//
+// Returns:
+//   - `string`: The unquoted name.
+//   - `string`: The definition's rest.
+func splitName(aDef string) (string, string) {
+	if "" == aDef {
+		return "", ""
+	}
+	closing := map[byte]byte{'"': '"', '`': '`', '[': ']'}[aDef[0]]
+	if 0 != closing {
+		if end := strings.IndexByte(aDef[1:], closing); 0 <= end {
+			return aDef[1 : end+1], strings.TrimSpace(aDef[end+2:])
+		}
+	}
+	if end := strings.IndexAny(aDef, " \t\r\n("); 0 <= end {
+		return aDef[:end], strings.TrimSpace(aDef[end:])
+	}
+
+	return aDef, ""
+} // splitName()
+
+// `varint()` decodes a variable-length integer.
+//
+// Parameters:
+//   - `aData`: The bytes to decode.
+//
+// Returns:
+//   - `uint64`: The decoded value.
+//   - `int`: The number of bytes read (`0`: invalid).
+func varint(aData []byte) (uint64, int) {
+	var result uint64
+	for idx := 0; idx < len(aData); idx++ {
+		if 8 == idx {
+			return (result << 8) | uint64(aData[idx]), 9
+		}
+		result = (result << 7) | uint64(aData[idx]&0x7f)
+		if 0 == aData[idx]&0x80 {
+			return result, idx + 1
+		}
+	}
+
+	return 0, 0
+} // varint()
+
+// ---------------------------------------------------------------------------
+// `TDatabase` constructor:
+
+// `Open()` opens an SQLite database file for reading.
+//
+// Parameters:
+//   - `aFilename`: The database file to open.
+//
+// Returns:
+//   - `*TDatabase`: The opened database.
+//   - `error`: `nil` if the database was opened, the error otherwise.
+func Open(aFilename string) (*TDatabase, error) {
+	file, err := os.Open(aFilename) //#nosec G304
+	if nil != err {
+		return nil, err
+	}
+	result, err := open(file)
+	if nil != err {
+		_ = file.Close()
+		return nil, err
+	}
+
+	return result, nil
+} // Open()
+
+// `open()` reads the database's header and schema.
+//
+// Parameters:
+//   - `aFile`: The opened database file.
+//
+// Returns:
+//   - `*TDatabase`: The opened database.
+//   - `error`: `nil` if the database is supported, the error otherwise.
+func open(aFile *os.File) (*TDatabase, error) {
+	header := make([]byte, headerSize)
+	if _, err := aFile.ReadAt(header, 0); nil != err {
+		return nil, ErrFormat
+	}
+	if headerMagic != string(header[:len(headerMagic)]) {
+		return nil, ErrFormat
+	}
+	pageSize := int(binary.BigEndian.Uint16(header[16:]))
+	if 1 == pageSize {
+		pageSize = 1 << 16
+	}
+	if (512 > pageSize) || (0 != pageSize&(pageSize-1)) {
+		return nil, ErrFormat
+	}
+	if 1 != binary.BigEndian.Uint32(header[56:]) {
+		return nil, fmt.Errorf("%w: text encoding", ErrFormat)
+	}
+	info, err := aFile.Stat()
+	if nil != err {
+		return nil, err
+	}
+
+	result := &TDatabase{
+		file:     aFile,
+		pageSize: pageSize,
+		usable:   pageSize - int(header[20]),
+		pages:    int(info.Size() / int64(pageSize)),
+		tables:   make(map[string]tTable),
+	}
+	if 480 > result.usable {
+		return nil, ErrFormat
+	}
+
+	// The schema table's columns: type, name, tbl_name, rootpage, sql
+	err = result.walk(1, 0, make(map[int]bool), func(_ int64, aValues []any) error {
+		if 5 > len(aValues) {
+			return nil
+		}
+		kind, _ := aValues[0].(string)
+		name, _ := aValues[1].(string)
+		root, _ := aValues[3].(int64)
+		sql, _ := aValues[4].(string)
+		if ("table" != kind) || (0 >= root) {
+			return nil
+		}
+		columns, rowid, err := parseColumns(sql)
+		if nil == err { // unsupported tables are left out
+			result.tables[strings.ToLower(name)] = tTable{
+				root:    int(root),
+				columns: columns,
+				rowid:   rowid,
+			}
+		}
+		return nil
+	})
+	if nil != err {
+		return nil, err
+	}
+
+	return result, nil
+} // open()
+
+// ---------------------------------------------------------------------------
+// `TDatabase` methods:
+
+// `Close()` closes the database file.
+//
+// Returns:
+//   - `error`: `nil` if the file was closed, the error otherwise.
+func (db *TDatabase) Close() error {
+	return db.file.Close()
+} // Close()
+
+// `HasTable()` reports whether the database contains the given table.
+//
+// Parameters:
+//   - `aTable`: The table's name (case insensitive).
+//
+// Returns:
+//   - `bool`: `true` if the table exists, `false` otherwise.
+func (db *TDatabase) HasTable(aTable string) bool {
+	_, ok := db.tables[strings.ToLower(aTable)]
+
+	return ok
+} // HasTable()
+
+// `page()` reads a page of the database.
+//
+// Parameters:
+//   - `aPage`: The page's number (starting with `1`).
+//
+// Returns:
+//   - `[]byte`: The page's usable content.
+//   - `error`: `nil` if the page was read, the error otherwise.
+func (db *TDatabase) page(aPage int) ([]byte, error) {
+	if (1 > aPage) || (db.pages < aPage) {
+		return nil, fmt.Errorf("%w: page %d out of range", ErrFormat, aPage)
+	}
+	data := make([]byte, db.pageSize)
+	if _, err := db.file.ReadAt(data, int64(aPage-1)*int64(db.pageSize)); nil != err {
+		return nil, err
+	}
+
+	return data[:db.usable], nil
+} // page()
+
+// `payload()` returns a leaf cell's complete payload, following the
+// overflow pages if necessary.
+//
+// Parameters:
+//   - `aCell`: The cell's data starting with its payload.
+//   - `aSize`: The payload's total size.
+//
+// Returns:
+//   - `[]byte`: The payload.
+//   - `error`: `nil` if the payload was read, the error otherwise.
+func (db *TDatabase) payload(aCell []byte, aSize uint64) ([]byte, error) {
+	if uint64(db.pages)*uint64(db.usable) < aSize { //#nosec G115
+		return nil, fmt.Errorf("%w: payload larger than file", ErrFormat)
+	}
+	maxLocal := uint64(db.usable - 35)
+	if aSize <= maxLocal {
+		if uint64(len(aCell)) < aSize {
+			return nil, ErrFormat
+		}
+		return aCell[:aSize], nil
+	}
+
+	minLocal := uint64((db.usable-12)*32/255 - 23)
+	local := minLocal + (aSize-minLocal)%uint64(db.usable-4)
+	if local > maxLocal {
+		local = minLocal
+	}
+	if uint64(len(aCell)) < local+4 {
+		return nil, ErrFormat
+	}
+	result := make([]byte, 0, aSize)
+	result = append(result, aCell[:local]...)
+
+	next := int(binary.BigEndian.Uint32(aCell[local:]))
+	for visited := 0; (uint64(len(result)) < aSize) && (0 != next); visited++ {
+		if db.pages < visited {
+			return nil, ErrFormat // a loop of overflow pages
+		}
+		data, err := db.page(next)
+		if nil != err {
+			return nil, err
+		}
+		next = int(binary.BigEndian.Uint32(data))
+		chunk := data[4:]
+		if rest := aSize - uint64(len(result)); uint64(len(chunk)) > rest {
+			chunk = chunk[:rest]
+		}
+		result = append(result, chunk...)
+	}
+	if uint64(len(result)) != aSize {
+		return nil, ErrFormat
+	}
+
+	return result, nil
+} // payload()
+
+// `Rows()` returns all rows of the given table.
+//
+// Columns added after a row was written have a `nil` value.
+//
+// Parameters:
+//   - `aTable`: The table's name (case insensitive).
+//
+// Returns:
+//   - `[]TRow`: The table's rows.
+//   - `error`: `nil` if the table was read, the error otherwise.
+func (db *TDatabase) Rows(aTable string) ([]TRow, error) {
+	table, ok := db.tables[strings.ToLower(aTable)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrNoTable, aTable)
+	}
+
+	var result []TRow
+	err := db.walk(table.root, 0, make(map[int]bool), func(aRowid int64, aValues []any) error {
+		row := make(TRow, len(table.columns))
+		for idx, column := range table.columns {
+			if idx < len(aValues) {
+				row[column] = aValues[idx]
+			} else {
+				row[column] = nil
+			}
+		}
+		if 0 <= table.rowid {
+			row[table.columns[table.rowid]] = aRowid
+		}
+		result = append(result, row)
+		return nil
+	})
+
+	return result, err
+} // Rows()
+
+// `walk()` calls the given function for all rows of a table b-tree.
+//
+// Each page may be part of the b-tree only once; a page reached
+// again (i.e. a loop in a corrupted file) is rejected.
+//
+// Parameters:
+//   - `aPage`: The b-tree's (sub-)root page.
+//   - `aDepth`: The page's depth within the b-tree.
+//   - `aVisited`: The pages of the b-tree visited so far.
+//   - `aFunc`: The function to call with each row's ID and values.
+//
+// Returns:
+//   - `error`: `nil` if the b-tree was read, the error otherwise.
+func (db *TDatabase) walk(aPage, aDepth int, aVisited map[int]bool, aFunc func(int64, []any) error) error {
+	if maxDepth < aDepth {
+		return fmt.Errorf("%w: b-tree too deep", ErrFormat)
+	}
+	if aVisited[aPage] {
+		return fmt.Errorf("%w: page %d visited twice", ErrFormat, aPage)
+	}
+	aVisited[aPage] = true
+	data, err := db.page(aPage)
+	if nil != err {
+		return err
+	}
+	header := data
+	if 1 == aPage {
+		header = data[headerSize:]
+	}
+	if 8 > len(header) {
+		return ErrFormat
+	}
+
+	kind := header[0]
+	cells := int(binary.BigEndian.Uint16(header[3:]))
+	hdrLen := 8
+	if pageInteriorTable == kind {
+		hdrLen = 12
+	} else if pageLeafTable != kind {
+		return fmt.Errorf("%w: page %d isn't a table page", ErrFormat, aPage)
+	}
+	if len(header) < hdrLen+2*cells {
+		return ErrFormat
+	}
+	pointers := header[hdrLen:]
+
+	for idx := range cells {
+		offset := int(binary.BigEndian.Uint16(pointers[2*idx:]))
+		if (offset >= len(data)) || (4 > offset) {
+			return ErrFormat
+		}
+		cell := data[offset:]
+
+		if pageInteriorTable == kind {
+			if 4 > len(cell) {
+				return ErrFormat
+			}
+			child := int(binary.BigEndian.Uint32(cell))
+			if err := db.walk(child, aDepth+1, aVisited, aFunc); nil != err {
+				return err
+			}
+			continue
+		}
+
+		size, n := varint(cell)
+		if 0 == n {
+			return ErrFormat
+		}
+		cell = cell[n:]
+		rowid, n := varint(cell)
+		if 0 == n {
+			return ErrFormat
+		}
+		record, err := db.payload(cell[n:], size)
+		if nil != err {
+			return err
+		}
+		values, err := decodeRecord(record)
+		if nil != err {
+			return err
+		}
+		if err := aFunc(int64(rowid), values); nil != err { //#nosec G115
+			return err
+		}
+	}
+
+	if pageInteriorTable == kind {
+		return db.walk(int(binary.BigEndian.Uint32(header[8:])), aDepth+1, aVisited, aFunc)
+	}
+
+	return nil
+} // walk()
+
+// ---------------------------------------------------------------------------
+// `TRow` methods:
+
+// `Int()` returns a column's integer value.
+//
+// Parameters:
+//   - `aColumn`: The column's name.
+//
+// Returns:
+//   - `int64`: The column's value (`0` if it's not an integer).
+func (r TRow) Int(aColumn string) int64 {
+	value, _ := r[aColumn].(int64)
+
+	return value
+} // Int()
+
+// `Text()` returns a column's text value.
+//
+// Parameters:
+//   - `aColumn`: The column's name.
+//
+// Returns:
+//   - `string`: The column's value (empty if it's not a text).
+func (r TRow) Text(aColumn string) string {
+	switch value := r[aColumn].(type) {
+	case string:
+		return value
+	case []byte:
+		return string(value)
+	}
+
+	return ""
+} // Text()
+
+/* _EoF_ */
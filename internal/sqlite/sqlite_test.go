@@ -0,0 +1,307 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package sqlite
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// `corruptDB()` writes a database file of the given pages (see
+// `tablePage()`) and returns its name.
+func corruptDB(t testing.TB, aPages ...[]byte) string {
+	t.Helper()
+	data := slices.Concat(aPages...)
+	copy(data, headerMagic)
+	binary.BigEndian.PutUint16(data[16:], 512)
+	binary.BigEndian.PutUint32(data[56:], 1) // UTF-8
+
+	result := filepath.Join(t.TempDir(), "corrupt.db")
+	if err := os.WriteFile(result, data, 0600); nil != err {
+		t.Fatal(err)
+	}
+
+	return result
+} // corruptDB()
+
+// `tablePage()` returns a page of 512 bytes with the given b-tree
+// header and a single cell at the page's offset `300`.
+func tablePage(aFirst bool, aHeader, aCell []byte) []byte {
+	result := make([]byte, 512)
+	offset := 0
+	if aFirst {
+		offset = headerSize
+	}
+	copy(result[offset:], aHeader)
+	binary.BigEndian.PutUint16(result[offset+len(aHeader):], 300)
+	copy(result[300:], aCell)
+
+	return result
+} // tablePage()
+
+// `putVarint()` encodes values below `1<<56` as a SQLite varint.
+func putVarint(aValue uint64) []byte {
+	result := []byte{byte(aValue & 0x7f)}
+	for aValue >>= 7; 0 != aValue; aValue >>= 7 {
+		result = append([]byte{byte(aValue&0x7f) | 0x80}, result...)
+	}
+
+	return result
+} // putVarint()
+
+func Test_decodeRecord(t *testing.T) {
+	tests := []struct {
+		name    string
+		record  []byte
+		want    []any
+		wantErr bool
+	}{
+		/* */
+		{"01 - empty", nil, nil, true},
+		{"02 - NULL and constants", []byte{4, 0, 8, 9}, []any{nil, int64(0), int64(1)}, false},
+		{"03 - negative int8", []byte{2, 1, 0xfe}, []any{int64(-2)}, false},
+		{"04 - int16", []byte{2, 2, 0x01, 0x00}, []any{int64(256)}, false},
+		{"05 - text", []byte{2, 19, 'a', 'b', 'c'}, []any{"abc"}, false},
+		{"06 - blob", []byte{2, 16, 1, 2}, []any{[]byte{1, 2}}, false},
+		{"07 - truncated body", []byte{2, 19, 'a'}, nil, true},
+		{"08 - reserved type", []byte{2, 10}, nil, true},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := decodeRecord(tc.record)
+			if (nil != err) != tc.wantErr {
+				t.Fatalf("decodeRecord() error = '%v', wantErr '%v'", err, tc.wantErr)
+			}
+			if tc.wantErr {
+				return
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("decodeRecord() = '%v', want '%v'", got, tc.want)
+			}
+			for idx := range got {
+				if blob, ok := got[idx].([]byte); ok {
+					if !slices.Equal(blob, tc.want[idx].([]byte)) {
+						t.Errorf("decodeRecord()[%d] = '%v', want '%v'", idx, blob, tc.want[idx])
+					}
+				} else if got[idx] != tc.want[idx] {
+					t.Errorf("decodeRecord()[%d] = '%v', want '%v'", idx, got[idx], tc.want[idx])
+				}
+			}
+		})
+	}
+} // Test_decodeRecord()
+
+func Test_parseColumns(t *testing.T) {
+	tests := []struct {
+		name      string
+		sql       string
+		want      []string
+		wantRowid int
+		wantErr   bool
+	}{
+		/* */
+		{"01 - invalid", "CREATE TABLE t", nil, -1, true},
+		{"02 - rowid alias", "CREATE TABLE t (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT)",
+			[]string{"id", "name"}, 0, false},
+		{"03 - constraints", `CREATE TABLE t (a INTEGER NOT NULL REFERENCES "group" (id), b TEXT DEFAULT (cast(strftime('%s', 'now') as int)), PRIMARY KEY (a, b))`,
+			[]string{"a", "b"}, -1, false},
+		{"04 - quoted names", "CREATE TABLE \"t\" ([a b] TEXT, `c` INT, \"d\")",
+			[]string{"a b", "c", "d"}, -1, false},
+		{"05 - without rowid", "CREATE TABLE t (a TEXT PRIMARY KEY) WITHOUT ROWID", nil, -1, true},
+		{"06 - integer key not primary", "CREATE TABLE t (a INTEGER, b INTEGER PRIMARY KEY)",
+			[]string{"a", "b"}, 1, false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, rowid, err := parseColumns(tc.sql)
+			if (nil != err) != tc.wantErr {
+				t.Fatalf("parseColumns() error = '%v', wantErr '%v'", err, tc.wantErr)
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("parseColumns() = '%v', want '%v'", got, tc.want)
+			}
+			if rowid != tc.wantRowid {
+				t.Errorf("parseColumns() rowid = '%d', want '%d'", rowid, tc.wantRowid)
+			}
+		})
+	}
+} // Test_parseColumns()
+
+func Test_varint(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  []byte
+		want  uint64
+		wantN int
+	}{
+		/* */
+		{"01 - empty", nil, 0, 0},
+		{"02 - one byte", []byte{0x7f}, 0x7f, 1},
+		{"03 - two bytes", []byte{0x81, 0x00}, 0x80, 2},
+		{"04 - truncated", []byte{0x81}, 0, 0},
+		{"05 - nine bytes", []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+			0xffffffffffffffff, 9},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, n := varint(tc.data)
+			if (got != tc.want) || (n != tc.wantN) {
+				t.Errorf("varint() = '%d', '%d', want '%d', '%d'", got, n, tc.want, tc.wantN)
+			}
+		})
+	}
+} // Test_varint()
+
+func Test_TDatabase(t *testing.T) {
+	if _, err := Open(filepath.Join("testdata", "missing.db")); nil == err {
+		t.Error("Open() error = 'nil', want an error")
+	}
+	notDB := filepath.Join(t.TempDir(), "not.db")
+	_ = os.WriteFile(notDB, []byte(strings.Repeat("no database ", 20)), 0600)
+	if _, err := Open(notDB); !errors.Is(err, ErrFormat) {
+		t.Errorf("Open() error = '%v', want '%v'", err, ErrFormat)
+	}
+
+	db, err := Open(filepath.Join("testdata", "test.db"))
+	if nil != err {
+		t.Fatalf("Open() error = '%v'", err)
+	}
+	defer db.Close()
+
+	if db.HasTable("norowid") {
+		t.Error("HasTable(norowid) = 'true', want 'false'")
+	}
+	if _, err := db.Rows("missing"); !errors.Is(err, ErrNoTable) {
+		t.Errorf("Rows() error = '%v', want '%v'", err, ErrNoTable)
+	}
+
+	rows, err := db.Rows("ITEMS")
+	if nil != err {
+		t.Fatalf("Rows() error = '%v'", err)
+	}
+	if 500 != len(rows) {
+		t.Fatalf("Rows() = '%d' rows, want '500'", len(rows))
+	}
+	for idx, row := range rows {
+		if want := int64(idx + 1); row.Int("id") != want {
+			t.Fatalf("Rows()[%d] id = '%d', want '%d'", idx, row.Int("id"), want)
+		}
+	}
+	if got := rows[0].Text("name"); "item-1" != got {
+		t.Errorf("name = '%s', want 'item-1'", got)
+	}
+	if got := rows[0].Int("value"); -249000 != got {
+		t.Errorf("value = '%d', want '-249000'", got)
+	}
+	if got := rows[1].Text("data"); "\x00\xff\x10" != got {
+		t.Errorf("data = '%q', want '\\x00\\xff\\x10'", got)
+	}
+	if got := rows[2].Text("name"); strings.Repeat("x", 3000) != got {
+		t.Errorf("name of overflowing row has '%d' bytes, want '3000'", len(got))
+	}
+	if got := rows[3].Int("value"); -9007199254740993 != got {
+		t.Errorf("value = '%d', want '-9007199254740993'", got)
+	}
+	if got, _ := rows[498]["ratio"].(float64); 124.75 != got {
+		t.Errorf("ratio = '%v', want '124.75'", got)
+	}
+	if got := rows[4].Text("extra"); "added" != got {
+		t.Errorf("extra = '%s', want 'added'", got)
+	}
+	if got := rows[5]["extra"]; nil != got {
+		t.Errorf("extra = '%v', want 'nil'", got)
+	}
+
+	quoted, err := db.Rows("quoted table")
+	if nil != err {
+		t.Fatalf("Rows() error = '%v'", err)
+	}
+	if (2 != len(quoted)) || ("b" != quoted[1].Text("first col")) || (2 != quoted[1].Int("second")) {
+		t.Errorf("Rows() = '%v', want two rows", quoted)
+	}
+} // Test_TDatabase()
+
+func Test_Open_corrupt(t *testing.T) {
+	// A payload that would take all of the local part but claims
+	// to continue on a few hundred terabytes of overflow pages
+	hugeCell := slices.Concat(putVarint(39+508<<45), putVarint(1), make([]byte, 64))
+
+	// Interior pages each referring to the next page twice, so
+	// the leaf would be reached `2^39` times
+	var shared [][]byte
+	for page := 1; 40 > page; page++ {
+		next := binary.BigEndian.AppendUint32(nil, uint32(page+1)) //#nosec G115
+		shared = append(shared, tablePage(1 == page,
+			slices.Concat([]byte{pageInteriorTable, 0, 0, 0, 1, 1, 44, 0}, next),
+			append(next, 1)))
+	}
+	shared = append(shared, tablePage(false, []byte{pageLeafTable, 0, 0, 0, 0, 2, 0, 0}, nil))
+
+	tests := []struct {
+		name  string
+		pages [][]byte
+	}{
+		/* */
+		{"01 - oversized payload", [][]byte{tablePage(true,
+			[]byte{pageLeafTable, 0, 0, 0, 1, 1, 44, 0}, hugeCell)}},
+		{"02 - page pointing to itself", [][]byte{tablePage(true,
+			[]byte{pageInteriorTable, 0, 0, 0, 1, 1, 44, 0, 0, 0, 0, 1},
+			[]byte{0, 0, 0, 1, 1})}},
+		{"03 - pages sharing their child", shared},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			db, err := Open(corruptDB(t, tc.pages...))
+			if nil == err {
+				db.Close()
+			}
+			if !errors.Is(err, ErrFormat) {
+				t.Errorf("Open() error = '%v', want '%v'", err, ErrFormat)
+			}
+		})
+	}
+} // Test_Open_corrupt()
+
+func Fuzz_Open(f *testing.F) {
+	if data, err := os.ReadFile(filepath.Join("testdata", "test.db")); nil == err {
+		f.Add(data)
+	}
+	f.Add([]byte(headerMagic))
+
+	f.Fuzz(func(t *testing.T, aData []byte) {
+		filename := filepath.Join(t.TempDir(), "fuzz.db")
+		if err := os.WriteFile(filename, aData, 0600); nil != err {
+			t.Fatal(err)
+		}
+		db, err := Open(filename)
+		if nil != err {
+			return
+		}
+		defer db.Close()
+
+		for name := range db.tables {
+			_, _ = db.Rows(name)
+		}
+	})
+} // Fuzz_Open()
+
+/* _EoF_ */
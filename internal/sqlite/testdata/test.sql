@@ -0,0 +1,16 @@
+-- Builds `test.db`: sqlite3 test.db < test.sql
+-- The small pages force interior b-tree pages and overflow pages.
+PRAGMA page_size = 512;
+CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT NOT NULL, value INTEGER, ratio REAL, data BLOB);
+WITH RECURSIVE seq(n) AS (SELECT 1 UNION ALL SELECT n + 1 FROM seq WHERE n < 500)
+	INSERT INTO items (id, name, value, ratio, data)
+	SELECT n, 'item-' || n, n * 1000 - 250000, n / 4.0, NULL FROM seq;
+UPDATE items SET data = x'00ff10' WHERE id = 2;
+UPDATE items SET name = printf('%.3000c', 'x') WHERE id = 3;
+UPDATE items SET value = -9007199254740993 WHERE id = 4;
+ALTER TABLE items ADD COLUMN extra TEXT;
+UPDATE items SET extra = 'added' WHERE id = 5;
+CREATE TABLE [quoted table] ("first col" TEXT, `second` INTEGER, CONSTRAINT pk PRIMARY KEY ("first col"));
+INSERT INTO [quoted table] VALUES ('a', 1), ('b', 2);
+CREATE TABLE norowid (key TEXT PRIMARY KEY, value TEXT) WITHOUT ROWID;
+INSERT INTO norowid VALUES ('k', 'v');
@@ -0,0 +1,335 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package workload
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+const (
+	// `Domain` is the domain of the generated cached and missed names.
+	Domain = "bench.test"
+
+	// `BlockedDomain` is the domain of the generated blocked names.
+	BlockedDomain = "blocked.test"
+
+	// `BlockedPattern` is the deny pattern matching all blocked names.
+	BlockedPattern = "*." + BlockedDomain
+
+	// DNS record types used by the generated queries
+	TypeA    uint16 = 1
+	TypeAAAA uint16 = 28
+)
+
+type (
+	// `TOptions` configure a synthetic query workload:
+	//
+	//   - `Names`: Number of distinct cached (and blocked) names, `0` means `10_000`.
+	//   - `Skew`: Exponent of the Zipf distribution (`> 1`), `0` means `1.1`.
+	//   - `HitRatio`: Share of the non-blocked queries answered from cache (`0..1`).
+	//   - `Blocked`: Share of the queries for blocked names (`0..1`).
+	//   - `Seed`: Seed of the random generator, `0` means the current time.
+	TOptions struct {
+		Names    int
+		Skew     float64
+		HitRatio float64
+		Blocked  float64
+		Seed     int64
+	}
+
+	// `TWorkload` generates the hostnames to query.
+	//
+	// The popularity of both, the cached and the blocked names, follows
+	// a Zipf distribution while every missed name is unique (hence
+	// never cached). A `TWorkload` is not safe for concurrent use,
+	// every worker should use its own (see `Clone()`).
+	TWorkload struct {
+		options TOptions
+		rnd     *rand.Rand
+		zipf    *rand.Zipf
+		misses  *atomic.Uint64 // shared by all clones
+	}
+)
+
+var (
+	// `errInvalidOptions` is returned for out-of-range options.
+	errInvalidOptions = errors.New("invalid workload options")
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `hostname()` returns the generated name of the given rank.
+//
+// Parameters:
+//   - `aPrefix`: The name's first label prefix.
+//   - `aRank`: The name's popularity rank.
+//   - `aDomain`: The name's domain.
+//
+// Returns:
+//   - `string`: The hostname.
+func hostname(aPrefix string, aRank uint64, aDomain string) string {
+	return aPrefix + strconv.FormatUint(aRank, 10) + "." + aDomain
+} // hostname()
+
+// `New()` creates a new query workload.
+//
+// Parameters:
+//   - `aOptions`: The workload's options.
+//
+// Returns:
+//   - `*TWorkload`: The new workload.
+//   - `error`: `nil` if the options are valid, the error otherwise.
+func New(aOptions TOptions) (*TWorkload, error) {
+	if 0 == aOptions.Names {
+		aOptions.Names = 10_000
+	}
+	if 0 == aOptions.Skew {
+		aOptions.Skew = 1.1
+	}
+	if 0 == aOptions.Seed {
+		aOptions.Seed = time.Now().UnixNano()
+	}
+	switch {
+	case 0 > aOptions.Names:
+		return nil, fmt.Errorf("%w: names = %d", errInvalidOptions, aOptions.Names)
+	case 1 >= aOptions.Skew:
+		return nil, fmt.Errorf("%w: skew = %v", errInvalidOptions, aOptions.Skew)
+	case (0 > aOptions.HitRatio) || (1 < aOptions.HitRatio):
+		return nil, fmt.Errorf("%w: hit ratio = %v", errInvalidOptions, aOptions.HitRatio)
+	case (0 > aOptions.Blocked) || (1 < aOptions.Blocked):
+		return nil, fmt.Errorf("%w: blocked = %v", errInvalidOptions, aOptions.Blocked)
+	}
+
+	return newWorkload(aOptions, aOptions.Seed, &atomic.Uint64{}), nil
+} // New()
+
+// `newWorkload()` creates a workload with its own random generator.
+//
+// Parameters:
+//   - `aOptions`: The (validated) workload options.
+//   - `aSeed`: The random generator's seed.
+//   - `aMisses`: The counter of the generated unique names.
+//
+// Returns:
+//   - `*TWorkload`: The new workload.
+func newWorkload(aOptions TOptions, aSeed int64, aMisses *atomic.Uint64) *TWorkload {
+	rnd := rand.New(rand.NewSource(aSeed))
+
+	return &TWorkload{
+		options: aOptions,
+		rnd:     rnd,
+		zipf:    rand.NewZipf(rnd, aOptions.Skew, 1, uint64(aOptions.Names-1)),
+		misses:  aMisses,
+	}
+} // newWorkload()
+
+// `Percentile()` returns the given percentile of the latencies.
+//
+// Parameters:
+//   - `aLatencies`: The measured latencies (sorted in place).
+//   - `aPercent`: The percentile to return (`0..100`).
+//
+// Returns:
+//   - `time.Duration`: The percentile, `0` if there are no latencies.
+func Percentile(aLatencies []time.Duration, aPercent float64) time.Duration {
+	if 0 == len(aLatencies) {
+		return 0
+	}
+	slices.Sort(aLatencies)
+	idx := int(float64(len(aLatencies)-1) * aPercent / 100)
+
+	return aLatencies[max(0, min(idx, len(aLatencies)-1))]
+} // Percentile()
+
+// `Query()` creates a DNS query message.
+//
+// Parameters:
+//   - `aID`: The query's ID.
+//   - `aHostname`: The hostname to query.
+//   - `aType`: The record type to query.
+//
+// Returns:
+//   - `[]byte`: The DNS query message.
+func Query(aID uint16, aHostname string, aType uint16) []byte {
+	result := make([]byte, 12, 12+len(aHostname)+6)
+	binary.BigEndian.PutUint16(result[0:2], aID)
+	binary.BigEndian.PutUint16(result[2:4], 0x0100) // RD
+	binary.BigEndian.PutUint16(result[4:6], 1)      // QDCOUNT
+
+	for _, label := range strings.Split(strings.TrimSuffix(aHostname, "."), ".") {
+		result = append(result, byte(len(label)))
+		result = append(result, label...)
+	}
+	result = append(result, 0)
+	result = binary.BigEndian.AppendUint16(result, aType)
+
+	return binary.BigEndian.AppendUint16(result, 1) // IN
+} // Query()
+
+// `Resolver()` returns a resolver sending all lookups to the given
+// DNS server (e.g. the one started by `StartUpstream()`).
+//
+// Parameters:
+//   - `aAddress`: The DNS server's address (`host:port`).
+//
+// Returns:
+//   - `*net.Resolver`: The resolver.
+func Resolver(aAddress string) *net.Resolver {
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(aCtx context.Context, aNetwork, _ string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(aCtx, aNetwork, aAddress)
+		},
+	}
+} // Resolver()
+
+// `StartUpstream()` starts a local DNS server answering every A query
+// with a fixed address (and every other query without records).
+//
+// It stands in for the upstream DNS servers so that cache misses
+// don't depend on the network.
+//
+// Returns:
+//   - `net.PacketConn`: The server's connection (close it to stop the server).
+//   - `error`: `nil` if the server was started, the error otherwise.
+func StartUpstream() (net.PacketConn, error) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if nil != err {
+		return nil, err
+	}
+
+	go func() {
+		buffer := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buffer)
+			if nil != err {
+				if errors.Is(err, net.ErrClosed) {
+					return
+				}
+				continue
+			}
+			if answer := upstreamAnswer(buffer[:n]); nil != answer {
+				_, _ = conn.WriteTo(answer, addr)
+			}
+		}
+	}()
+
+	return conn, nil
+} // StartUpstream()
+
+// `upstreamAnswer()` creates the answer of the fake upstream server.
+//
+// Parameters:
+//   - `aQuery`: The DNS query to answer.
+//
+// Returns:
+//   - `[]byte`: The answer, `nil` for malformed queries.
+func upstreamAnswer(aQuery []byte) []byte {
+	if 12 >= len(aQuery) {
+		return nil
+	}
+	end := 12
+	for (end < len(aQuery)) && (0 != aQuery[end]) {
+		end += int(aQuery[end]) + 1
+	}
+	end += 5 // terminating zero, type and class
+	if end > len(aQuery) {
+		return nil
+	}
+
+	result := slices.Clone(aQuery[:end])
+	binary.BigEndian.PutUint16(result[2:4], 0x8180) // QR, RD, RA
+	binary.BigEndian.PutUint16(result[6:8], 0)      // ANCOUNT
+	binary.BigEndian.PutUint16(result[8:10], 0)     // NSCOUNT
+	binary.BigEndian.PutUint16(result[10:12], 0)    // ARCOUNT
+	if TypeA != binary.BigEndian.Uint16(aQuery[end-4:end-2]) {
+		return result
+	}
+
+	binary.BigEndian.PutUint16(result[6:8], 1)
+	result = append(result,
+		0xC0, 12, // pointer to the question's name
+		0, 1, 0, 1, // type A, class IN
+		0, 0, 0x0E, 0x10, // TTL (one hour)
+		0, 4, 192, 0, 2, 1) // TEST-NET-1 address
+
+	return result
+} // upstreamAnswer()
+
+// ---------------------------------------------------------------------------
+// `TWorkload` methods:
+
+// `BlockedNames()` returns the names whose queries are to be blocked.
+//
+// Instead of adding all of them, adding `BlockedPattern` to the deny
+// list blocks them as well.
+//
+// Returns:
+//   - `[]string`: The blocked names.
+func (w *TWorkload) BlockedNames() []string {
+	result := make([]string, w.options.Names)
+	for idx := range result {
+		result[idx] = hostname("ad", uint64(idx), BlockedDomain)
+	}
+
+	return result
+} // BlockedNames()
+
+// `CachedNames()` returns the names to put into the cache before
+// running the workload.
+//
+// Returns:
+//   - `[]string`: The names to cache.
+func (w *TWorkload) CachedNames() []string {
+	result := make([]string, w.options.Names)
+	for idx := range result {
+		result[idx] = hostname("h", uint64(idx), Domain)
+	}
+
+	return result
+} // CachedNames()
+
+// `Clone()` returns a workload with the same options but its own
+// random generator (for use by another worker).
+//
+// Returns:
+//   - `*TWorkload`: The cloned workload.
+func (w *TWorkload) Clone() *TWorkload {
+	return newWorkload(w.options, w.rnd.Int63(), w.misses)
+} // Clone()
+
+// `Next()` returns the next hostname to query.
+//
+// Returns:
+//   - `string`: The hostname to query.
+func (w *TWorkload) Next() string {
+	choice := w.rnd.Float64()
+	if choice < w.options.Blocked {
+		return hostname("ad", w.zipf.Uint64(), BlockedDomain)
+	}
+	if choice < w.options.Blocked+(1-w.options.Blocked)*w.options.HitRatio {
+		return hostname("h", w.zipf.Uint64(), Domain)
+	}
+
+	return hostname("miss", w.misses.Add(1), Domain)
+} // Next()
+
+/* _EoF_ */
@@ -0,0 +1,144 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package workload
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_New(t *testing.T) {
+	tests := []struct {
+		name    string
+		options TOptions
+		wantErr bool
+	}{
+		/* */
+		{"01 - defaults", TOptions{}, false},
+		{"02 - valid options", TOptions{Names: 100, Skew: 1.5, HitRatio: 0.9, Blocked: 0.2, Seed: 1}, false},
+		{"03 - negative names", TOptions{Names: -1}, true},
+		{"04 - invalid skew", TOptions{Skew: 0.5}, true},
+		{"05 - invalid hit ratio", TOptions{HitRatio: 1.5}, true},
+		{"06 - invalid blocked share", TOptions{Blocked: -0.1}, true},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := New(tc.options)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("New() error = '%v', wantErr '%v'", err, tc.wantErr)
+			}
+			if (nil == got) != tc.wantErr {
+				t.Errorf("New() = '%v', wantErr '%v'", got, tc.wantErr)
+			}
+		})
+	}
+} // Test_New()
+
+func Test_TWorkload_Next(t *testing.T) {
+	tests := []struct {
+		name        string
+		options     TOptions
+		wantBlocked float64
+		wantMissed  float64
+	}{
+		/* */
+		{"01 - all hits", TOptions{HitRatio: 1}, 0, 0},
+		{"02 - all misses", TOptions{HitRatio: 0}, 0, 1},
+		{"03 - all blocked", TOptions{Blocked: 1}, 1, 0},
+		{"04 - mixed", TOptions{HitRatio: 0.5, Blocked: 0.2}, 0.2, 0.4},
+		/* */
+	}
+
+	const count = 10_000
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.options.Seed = 42
+			w, err := New(tc.options)
+			if nil != err {
+				t.Fatalf("New() error = '%v'", err)
+			}
+			w = w.Clone()
+
+			var blocked, missed float64
+			for range count {
+				switch name := w.Next(); {
+				case strings.HasSuffix(name, "."+BlockedDomain):
+					blocked++
+				case strings.HasPrefix(name, "miss"):
+					missed++
+				}
+			}
+			if got := blocked / count; 0.02 < abs(got-tc.wantBlocked) {
+				t.Errorf("Next() blocked = '%v', want '%v'", got, tc.wantBlocked)
+			}
+			if got := missed / count; 0.02 < abs(got-tc.wantMissed) {
+				t.Errorf("Next() missed = '%v', want '%v'", got, tc.wantMissed)
+			}
+		})
+	}
+} // Test_TWorkload_Next()
+
+func Test_Percentile(t *testing.T) {
+	latencies := []time.Duration{5, 1, 4, 2, 3, 10, 9, 8, 7, 6}
+
+	tests := []struct {
+		name    string
+		list    []time.Duration
+		percent float64
+		want    time.Duration
+	}{
+		/* */
+		{"01 - empty", nil, 99, 0},
+		{"02 - minimum", latencies, 0, 1},
+		{"03 - median", latencies, 50, 5},
+		{"04 - p99", latencies, 99, 9},
+		{"05 - maximum", latencies, 100, 10},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Percentile(tc.list, tc.percent); got != tc.want {
+				t.Errorf("Percentile() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_Percentile()
+
+func Test_StartUpstream(t *testing.T) {
+	conn, err := StartUpstream()
+	if nil != err {
+		t.Fatalf("StartUpstream() error = '%v'", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	ips, err := Resolver(conn.LocalAddr().String()).LookupHost(ctx, "h1."+Domain)
+	if nil != err {
+		t.Fatalf("LookupHost() error = '%v'", err)
+	}
+	if (1 != len(ips)) || ("192.0.2.1" != ips[0]) {
+		t.Errorf("LookupHost() = '%v', want '[192.0.2.1]'", ips)
+	}
+} // Test_StartUpstream()
+
+// `abs()` returns the absolute value of `aValue`.
+func abs(aValue float64) float64 {
+	if 0 > aValue {
+		return -aValue
+	}
+	return aValue
+} // abs()
+
+/* _EoF_ */
@@ -0,0 +1,140 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"errors"
+	"net"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `TBlockPolicy` determines how upstream answers containing
+	// blocked IP addresses are handled.
+	TBlockPolicy uint8
+)
+
+const (
+	// `BlockPolicyStrip` removes all blocked addresses from an answer.
+	// If no address remains, the answer is replaced by the null IP.
+	BlockPolicyStrip = TBlockPolicy(iota)
+
+	// `BlockPolicyNullIP` replaces the whole answer by the null IP
+	// (`0.0.0.0`) if it contains any blocked address.
+	BlockPolicyNullIP
+
+	// `BlockPolicyNXDomain` answers with a `TBlockedError` matching
+	// `ErrBlockedIP` (i.e. NXDOMAIN) if the answer contains any
+	// blocked address.
+	BlockPolicyNXDomain
+)
+
+var (
+	// `ErrBlockedIP` is matched by the error returned by the lookup
+	// methods if an answer contained a blocked IP address and the
+	// resolver's block policy is `BlockPolicyNXDomain`.
+	ErrBlockedIP = errors.New("answer contains blocked IP address")
+)
+
+// ---------------------------------------------------------------------------
+// `TResolver` methods:
+
+// `BlockCIDR()` adds an IP range to the resolver's IP blocklist.
+//
+// Upstream answers containing addresses of a blocked range are
+// handled according to the resolver's block policy.
+//
+// Parameters:
+//   - `aCIDR`: The IP range (e.g. `192.0.2.0/24`) or single IP to block.
+//
+// Returns:
+//   - `error`: `nil` if the range was added, the error otherwise.
+func (r *TResolver) BlockCIDR(aCIDR string) error {
+	return r.ipBlocklist.Add(aCIDR)
+} // BlockCIDR()
+
+// `filterBlockedIPs()` applies the resolver's block policy to the
+// given list of IP addresses.
+//
+// Parameters:
+//   - `aHostname`: The hostname the addresses belong to.
+//   - `aIPs`: The IP addresses returned by an upstream server.
+//
+// Returns:
+//   - `[]net.IP`: The (possibly) filtered list of IP addresses.
+//   - `error`: A `TBlockedError` in case of `BlockPolicyNXDomain`, `nil` otherwise.
+func (r *TResolver) filterBlockedIPs(aHostname string, aIPs []net.IP) ([]net.IP, error) {
+	if 0 == r.ipBlocklist.Len() {
+		return aIPs, nil
+	}
+	r.RLock()
+	policy := r.blockPolicy
+	r.RUnlock()
+
+	var result []net.IP
+	for idx, ip := range aIPs {
+		if !r.ipBlocklist.Contains(ip) {
+			if nil != result {
+				result = append(result, ip)
+			}
+			continue
+		}
+
+		switch policy {
+		case BlockPolicyNullIP:
+			return []net.IP{net.IPv4zero}, nil
+
+		case BlockPolicyNXDomain:
+			return nil, &TBlockedError{Hostname: aHostname, IP: ip}
+		}
+
+		// BlockPolicyStrip: copy the allowed addresses found so far
+		if nil == result {
+			result = make([]net.IP, idx, len(aIPs))
+			copy(result, aIPs[:idx])
+		}
+	}
+
+	if nil == result {
+		return aIPs, nil // no blocked address found
+	}
+	if 0 == len(result) {
+		return []net.IP{net.IPv4zero}, nil
+	}
+
+	return result, nil
+} // filterBlockedIPs()
+
+// `SetBlockPolicy()` sets how answers containing blocked IP addresses
+// are handled.
+//
+// Parameters:
+//   - `aPolicy`: The block policy to use.
+//
+// Returns:
+//   - `*TResolver`: The current resolver.
+func (r *TResolver) SetBlockPolicy(aPolicy TBlockPolicy) *TResolver {
+	r.Lock()
+	r.blockPolicy = aPolicy
+	r.Unlock()
+
+	return r
+} // SetBlockPolicy()
+
+// `UnblockCIDR()` removes an IP range from the resolver's IP blocklist.
+//
+// Parameters:
+//   - `aCIDR`: The IP range to remove.
+//
+// Returns:
+//   - `bool`: `true` if the range was found and removed, `false` otherwise.
+func (r *TResolver) UnblockCIDR(aCIDR string) bool {
+	return r.ipBlocklist.Delete(aCIDR)
+} // UnblockCIDR()
+
+/* _EoF_ */
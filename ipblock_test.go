@@ -0,0 +1,100 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"errors"
+	"net"
+	"slices"
+	"testing"
+
+	adl "github.com/mwat56/dnscache/internal/adlist"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_TResolver_filterBlockedIPs(t *testing.T) {
+	newResolver := func(aPolicy TBlockPolicy) *TResolver {
+		r := &TResolver{
+			ipBlocklist: adl.NewCIDRlist(),
+			blockPolicy: aPolicy,
+		}
+		_ = r.BlockCIDR("192.0.2.0/24")
+		return r
+	}
+	good := net.ParseIP("198.51.100.1")
+	bad := net.ParseIP("192.0.2.1")
+
+	tests := []struct {
+		name     string
+		resolver *TResolver
+		ips      []net.IP
+		want     []net.IP
+		wantErr  bool
+	}{
+		/* */
+		{
+			name:     "01 - empty blocklist",
+			resolver: &TResolver{ipBlocklist: adl.NewCIDRlist()},
+			ips:      []net.IP{bad},
+			want:     []net.IP{bad},
+		},
+		{
+			name:     "02 - nothing blocked",
+			resolver: newResolver(BlockPolicyStrip),
+			ips:      []net.IP{good},
+			want:     []net.IP{good},
+		},
+		{
+			name:     "03 - strip blocked",
+			resolver: newResolver(BlockPolicyStrip),
+			ips:      []net.IP{bad, good},
+			want:     []net.IP{good},
+		},
+		{
+			name:     "04 - strip all",
+			resolver: newResolver(BlockPolicyStrip),
+			ips:      []net.IP{bad},
+			want:     []net.IP{net.IPv4zero},
+		},
+		{
+			name:     "05 - null IP",
+			resolver: newResolver(BlockPolicyNullIP),
+			ips:      []net.IP{good, bad},
+			want:     []net.IP{net.IPv4zero},
+		},
+		{
+			name:     "06 - NXDOMAIN",
+			resolver: newResolver(BlockPolicyNXDomain),
+			ips:      []net.IP{good, bad},
+			want:     nil,
+			wantErr:  true,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.resolver.filterBlockedIPs("host.tld", tc.ips)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("filterBlockedIPs() error = '%v', wantErr '%v'",
+					err, tc.wantErr)
+				return
+			}
+			if tc.wantErr && !errors.Is(err, ErrBlockedIP) {
+				t.Errorf("filterBlockedIPs() error = '%v', want '%v'",
+					err, ErrBlockedIP)
+			}
+			if !slices.EqualFunc(got, tc.want, net.IP.Equal) {
+				t.Errorf("filterBlockedIPs() = '%v', want '%v'",
+					got, tc.want)
+			}
+		})
+	}
+} // Test_TResolver_filterBlockedIPs()
+
+/* _EoF_ */
@@ -0,0 +1,495 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mwat56/dnscache/clock"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+const (
+	// `defLeasePoll` is the interval at which lease files are checked
+	// for changes.
+	defLeasePoll = 10 * time.Second
+)
+
+type (
+	// `TLease` is a DHCP lease assigning an IP address to a host.
+	//
+	//   - `Expires`: The lease's end (zero means it never expires).
+	//   - `Hostname`: The host's (unqualified) name.
+	//   - `IP`: The leased address.
+	TLease struct {
+		Expires  time.Time
+		Hostname string
+		IP       net.IP
+	}
+
+	// `tLeaseSource` holds the leases read from a single source.
+	tLeaseSource struct {
+		domain string
+		leases []TLease
+	}
+
+	// `tLeases` maps the hostnames of DHCP leases to their addresses
+	// and vice versa.
+	tLeases struct {
+		sync.RWMutex
+		clock   clock.IClock            // `nil` means the system's clock
+		sources map[string]tLeaseSource // leases by their source
+		byName  map[string][]TLease     // leases by (qualified) hostname
+		byAddr  map[string]string       // FQDN by IP address
+	}
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `leaseHostname()` normalises a lease's hostname.
+//
+// Parameters:
+//   - `aHostname`: The hostname to check.
+//
+// Returns:
+//   - `string`: The lower-cased hostname (empty if invalid).
+func leaseHostname(aHostname string) string {
+	hostname := strings.Trim(strings.ToLower(strings.TrimSpace(aHostname)), ".")
+	if ("" == hostname) || (253 < len(hostname)) {
+		return ""
+	}
+	for _, label := range strings.Split(hostname, ".") {
+		if (0 == len(label)) || (63 < len(label)) ||
+			('-' == label[0]) || ('-' == label[len(label)-1]) {
+			return ""
+		}
+		for _, c := range label {
+			if !(('a' <= c) && ('z' >= c)) && !(('0' <= c) && ('9' >= c)) && ('-' != c) {
+				return ""
+			}
+		}
+	}
+
+	return hostname
+} // leaseHostname()
+
+// `ParseDnsmasqLeases()` reads the leases from a dnsmasq lease file.
+//
+// Each line contains the lease's expiry time (Unix seconds, `0` meaning
+// infinite), the MAC address (or IAID for IPv6), the IP address, the
+// hostname (`*` if unknown), and the client ID. Lines without a valid
+// hostname or address are skipped.
+//
+// Parameters:
+//   - `aReader`: The reader to read the lease file from.
+//
+// Returns:
+//   - `[]TLease`: The leases read.
+//   - `error`: `nil` if the file was read, the error otherwise.
+func ParseDnsmasqLeases(aReader io.Reader) ([]TLease, error) {
+	var result []TLease
+
+	scanner := bufio.NewScanner(aReader)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if (4 > len(fields)) || ("duid" == fields[0]) {
+			continue
+		}
+		expiry, err := strconv.ParseInt(fields[0], 10, 64)
+		if nil != err {
+			continue
+		}
+		lease := TLease{
+			Hostname: leaseHostname(fields[3]),
+			IP:       net.ParseIP(fields[2]),
+		}
+		if ("" == lease.Hostname) || (nil == lease.IP) {
+			continue
+		}
+		if 0 < expiry {
+			lease.Expires = time.Unix(expiry, 0)
+		}
+		result = append(result, lease)
+	}
+
+	return result, scanner.Err()
+} // ParseDnsmasqLeases()
+
+// `ParseISCLeases()` reads the leases from an ISC `dhcpd.leases` file.
+//
+// Since the file is a journal, a later entry for an address replaces
+// an earlier one. Only active leases with a `client-hostname` are
+// returned.
+//
+// Parameters:
+//   - `aReader`: The reader to read the lease file from.
+//
+// Returns:
+//   - `[]TLease`: The leases read.
+//   - `error`: `nil` if the file was read, the error otherwise.
+func ParseISCLeases(aReader io.Reader) ([]TLease, error) {
+	var (
+		current *TLease
+		active  bool
+		order   []string
+	)
+	leases := make(map[string]TLease)
+	states := make(map[string]bool)
+
+	scanner := bufio.NewScanner(aReader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if ("" == line) || ('#' == line[0]) {
+			continue
+		}
+		fields := strings.Fields(strings.TrimSuffix(line, ";"))
+
+		switch {
+		case ("lease" == fields[0]) && (3 == len(fields)) && ("{" == fields[2]):
+			current, active = &TLease{IP: net.ParseIP(fields[1])}, true
+
+		case nil == current:
+			continue
+
+		case "}" == line:
+			if nil != current.IP {
+				key := current.IP.String()
+				if _, ok := leases[key]; !ok {
+					order = append(order, key)
+				}
+				leases[key], states[key] = *current, active
+			}
+			current = nil
+
+		case ("binding" == fields[0]) && (3 <= len(fields)) && ("state" == fields[1]):
+			active = ("active" == fields[2])
+
+		case ("client-hostname" == fields[0]) && (2 <= len(fields)):
+			current.Hostname = leaseHostname(strings.Trim(strings.Join(fields[1:], " "), `"`))
+
+		case ("ends" == fields[0]) && (2 <= len(fields)):
+			current.Expires = parseISCTime(fields[1:])
+		}
+	}
+
+	result := make([]TLease, 0, len(order))
+	for _, key := range order {
+		if lease := leases[key]; states[key] && ("" != lease.Hostname) {
+			result = append(result, lease)
+		}
+	}
+
+	return result, scanner.Err()
+} // ParseISCLeases()
+
+// `parseISCTime()` parses the time of an ISC lease statement.
+//
+// Parameters:
+//   - `aFields`: The statement's fields following its keyword, e.g.
+//     `4 2025/01/02 10:00:00`, `epoch 1735812000`, or `never`.
+//
+// Returns:
+//   - `time.Time`: The parsed time (zero for `never` or invalid values).
+func parseISCTime(aFields []string) time.Time {
+	switch {
+	case ("epoch" == aFields[0]) && (2 <= len(aFields)):
+		if seconds, err := strconv.ParseInt(aFields[1], 10, 64); nil == err {
+			return time.Unix(seconds, 0)
+		}
+
+	case 3 <= len(aFields):
+		if t, err := time.Parse("2006/01/02 15:04:05", aFields[1]+" "+aFields[2]); nil == err {
+			return t
+		}
+	}
+
+	return time.Time{}
+} // parseISCTime()
+
+// `readLeaseFile()` reads a dnsmasq or ISC DHCP lease file.
+//
+// Parameters:
+//   - `aFilename`: The lease file to read.
+//
+// Returns:
+//   - `[]TLease`: The leases read.
+//   - `error`: `nil` if the file was read, the error otherwise.
+func readLeaseFile(aFilename string) ([]TLease, error) {
+	data, err := os.ReadFile(aFilename) //#nosec G304
+	if nil != err {
+		return nil, err
+	}
+
+	// ISC lease files consist of `lease <ip> { … }` blocks
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if bytes.HasPrefix(bytes.TrimSpace(line), []byte("lease ")) {
+			return ParseISCLeases(bytes.NewReader(data))
+		}
+	}
+
+	return ParseDnsmasqLeases(bytes.NewReader(data))
+} // readLeaseFile()
+
+// ---------------------------------------------------------------------------
+// `tLeases` constructor:
+
+// `newLeases()` returns a new, empty `tLeases` instance.
+//
+// Returns:
+//   - `*tLeases`: A new lease table.
+func newLeases() *tLeases {
+	return &tLeases{
+		sources: make(map[string]tLeaseSource),
+		byName:  make(map[string][]TLease),
+		byAddr:  make(map[string]string),
+	}
+} // newLeases()
+
+// ---------------------------------------------------------------------------
+// `tLeases` methods:
+
+// `all()` returns all leases.
+//
+// Returns:
+//   - `[]TLease`: All leases sorted by hostname.
+func (tl *tLeases) all() []TLease {
+	if nil == tl {
+		return nil
+	}
+	tl.RLock()
+	defer tl.RUnlock()
+
+	var result []TLease
+	for _, source := range tl.sources {
+		result = append(result, source.leases...)
+	}
+	slices.SortFunc(result, func(a, b TLease) int {
+		if c := strings.Compare(a.Hostname, b.Hostname); 0 != c {
+			return c
+		}
+		return bytes.Compare(a.IP.To16(), b.IP.To16())
+	})
+
+	return result
+} // all()
+
+// `lookup()` returns the addresses leased to a hostname.
+//
+// Parameters:
+//   - `aHostname`: The hostname to look up.
+//
+// Returns:
+//   - `[]net.IP`: The addresses of the unexpired leases.
+func (tl *tLeases) lookup(aHostname string) (rIPs []net.IP) {
+	if nil == tl {
+		return
+	}
+	hostname := strings.Trim(strings.ToLower(aHostname), ".")
+	now := clock.OrSystem(tl.clock).Now()
+
+	tl.RLock()
+	defer tl.RUnlock()
+
+	for _, lease := range tl.byName[hostname] {
+		if lease.Expires.IsZero() || lease.Expires.After(now) {
+			rIPs = append(rIPs, lease.IP)
+		}
+	}
+
+	return
+} // lookup()
+
+// `lookupAddr()` returns the hostname an address is leased to.
+//
+// Parameters:
+//   - `aIP`: The address to look up.
+//
+// Returns:
+//   - `string`: The lease's fully qualified hostname.
+//   - `bool`: `true` if a lease was found, `false` otherwise.
+func (tl *tLeases) lookupAddr(aIP net.IP) (string, bool) {
+	if (nil == tl) || (nil == aIP) {
+		return "", false
+	}
+	tl.RLock()
+	defer tl.RUnlock()
+
+	hostname, ok := tl.byAddr[aIP.String()]
+
+	return hostname, ok
+} // lookupAddr()
+
+// `set()` replaces the leases of a source.
+//
+// Parameters:
+//   - `aSource`: The leases' source (e.g. the lease file's name).
+//   - `aDomain`: The domain to qualify the hostnames with (may be empty).
+//   - `aLeases`: The source's leases.
+func (tl *tLeases) set(aSource, aDomain string, aLeases []TLease) {
+	if nil == tl {
+		return
+	}
+	domain := strings.Trim(strings.ToLower(strings.TrimSpace(aDomain)), ".")
+	now := clock.OrSystem(tl.clock).Now()
+
+	leases := make([]TLease, 0, len(aLeases))
+	for _, lease := range aLeases {
+		lease.Hostname = leaseHostname(lease.Hostname)
+		if ("" == lease.Hostname) || (nil == lease.IP) {
+			continue
+		}
+		if lease.Expires.IsZero() || lease.Expires.After(now) {
+			leases = append(leases, lease)
+		}
+	}
+
+	tl.Lock()
+	defer tl.Unlock()
+
+	if 0 == len(leases) {
+		delete(tl.sources, aSource)
+	} else {
+		tl.sources[aSource] = tLeaseSource{domain: domain, leases: leases}
+	}
+
+	// Rebuild the indices from all sources
+	clear(tl.byName)
+	clear(tl.byAddr)
+	for _, source := range tl.sources {
+		for _, lease := range source.leases {
+			fqdn := lease.Hostname
+			if ("" != source.domain) && !strings.HasSuffix(fqdn, "."+source.domain) {
+				fqdn += "." + source.domain
+				tl.byName[lease.Hostname] = append(tl.byName[lease.Hostname], lease)
+			}
+			tl.byName[fqdn] = append(tl.byName[fqdn], lease)
+			tl.byAddr[lease.IP.String()] = fqdn
+		}
+	}
+} // set()
+
+// ---------------------------------------------------------------------------
+// `TResolver` methods:
+
+// `Leases()` returns all DHCP leases known to the resolver.
+//
+// Returns:
+//   - `[]TLease`: The leases sorted by hostname.
+func (r *TResolver) Leases() []TLease {
+	return r.leases.all()
+} // Leases()
+
+// `LookupAddr()` returns the hostname of a DHCP lease's address,
+// i.e. the answer to a reverse (PTR) lookup.
+//
+// Parameters:
+//   - `aIP`: The address to look up.
+//
+// Returns:
+//   - `string`: The lease's fully qualified hostname.
+//   - `bool`: `true` if a lease was found, `false` otherwise.
+func (r *TResolver) LookupAddr(aIP net.IP) (string, bool) {
+	return r.leases.lookupAddr(aIP)
+} // LookupAddr()
+
+// `SetLeases()` replaces the DHCP leases of a source.
+//
+// The leases' hostnames are resolved to their addresses by `Fetch()`
+// (after the rewrite rules but before the allow/deny lists) both as
+// given and qualified with `aDomain`.
+//
+// Parameters:
+//   - `aSource`: The leases' source (e.g. the lease file's name).
+//   - `aDomain`: The domain to qualify the hostnames with (may be empty).
+//   - `aLeases`: The source's leases (`nil` removes them all).
+func (r *TResolver) SetLeases(aSource, aDomain string, aLeases []TLease) {
+	r.leases.set(aSource, aDomain, aLeases)
+} // SetLeases()
+
+// `WatchLeaseFile()` loads a dnsmasq or ISC DHCP lease file and
+// reloads it whenever it changes.
+//
+// The file's modification time is checked every ten seconds until
+// the given context is cancelled.
+//
+// Parameters:
+//   - `aCtx`: The context to stop watching the file.
+//   - `aFilename`: The lease file to watch.
+//   - `aDomain`: The domain to qualify the hostnames with (may be empty).
+//
+// Returns:
+//   - `error`: `nil` if the file was loaded, the error otherwise.
+func (r *TResolver) WatchLeaseFile(aCtx context.Context, aFilename, aDomain string) error {
+	return r.watchLeaseFile(aCtx, aFilename, aDomain, defLeasePoll)
+} // WatchLeaseFile()
+
+// `watchLeaseFile()` implements `WatchLeaseFile()` with a configurable
+// polling interval.
+//
+// Parameters:
+//   - `aCtx`: The context to stop watching the file.
+//   - `aFilename`: The lease file to watch.
+//   - `aDomain`: The domain to qualify the hostnames with (may be empty).
+//   - `aInterval`: The interval to check the file for changes.
+//
+// Returns:
+//   - `error`: `nil` if the file was loaded, the error otherwise.
+func (r *TResolver) watchLeaseFile(aCtx context.Context, aFilename, aDomain string, aInterval time.Duration) error {
+	fi, err := os.Stat(aFilename)
+	if nil != err {
+		return err
+	}
+	leases, err := readLeaseFile(aFilename)
+	if nil != err {
+		return fmt.Errorf("reading lease file %q: %w", aFilename, err)
+	}
+	r.SetLeases(aFilename, aDomain, leases)
+	gLog.Info("DHCP leases loaded", "file", aFilename, "leases", len(leases))
+
+	go func(aModTime time.Time, aSize int64) {
+		ticker := time.NewTicker(aInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-aCtx.Done():
+				return
+
+			case <-ticker.C:
+				fi, err := os.Stat(aFilename)
+				if (nil != err) || (fi.ModTime().Equal(aModTime) && (fi.Size() == aSize)) {
+					continue
+				}
+				leases, err := readLeaseFile(aFilename)
+				if nil != err {
+					gLog.Warn("failed to reload DHCP leases", "file", aFilename, "error", err)
+					continue
+				}
+				aModTime, aSize = fi.ModTime(), fi.Size()
+				r.SetLeases(aFilename, aDomain, leases)
+				gLog.Debug("DHCP leases reloaded", "file", aFilename, "leases", len(leases))
+			}
+		}
+	}(fi.ModTime(), fi.Size())
+
+	return nil
+} // watchLeaseFile()
+
+/* _EoF_ */
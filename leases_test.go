@@ -0,0 +1,183 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+const (
+	testDnsmasqLeases = `1893456000 aa:bb:cc:dd:ee:01 192.168.1.10 laptop 01:aa:bb:cc:dd:ee:01
+0 aa:bb:cc:dd:ee:02 192.168.1.11 Printer *
+1893456000 aa:bb:cc:dd:ee:03 192.168.1.12 * *
+1000 aa:bb:cc:dd:ee:04 192.168.1.13 old-phone *
+duid 00:01:00:01:2c:1f:aa:bb:cc:dd:ee:ff
+1893456000 1234 fd00::10 laptop 00:01:00:01
+1893456000 aa:bb:cc:dd:ee:05 192.168.1.14 bad_name *
+`
+	testISCLeases = `# The format of this file is documented in the dhcpd.leases(5) manual page.
+lease 192.168.1.20 {
+  starts 4 2025/01/02 10:00:00;
+  ends 2 2030/01/01 00:00:00;
+  binding state active;
+  hardware ethernet aa:bb:cc:dd:ee:10;
+  client-hostname "desktop";
+}
+lease 192.168.1.21 {
+  ends never;
+  binding state active;
+  client-hostname "tv";
+}
+lease 192.168.1.22 {
+  ends epoch 1893456000;
+  binding state free;
+  client-hostname "gone";
+}
+lease 192.168.1.20 {
+  ends 2 2030/01/01 00:00:00;
+  binding state active;
+  client-hostname "workstation";
+}
+`
+)
+
+func Test_ParseDnsmasqLeases(t *testing.T) {
+	got, err := ParseDnsmasqLeases(strings.NewReader(testDnsmasqLeases))
+	if nil != err {
+		t.Fatalf("ParseDnsmasqLeases() error = '%v'", err)
+	}
+
+	want := []TLease{
+		{Expires: time.Unix(1893456000, 0), Hostname: "laptop", IP: net.ParseIP("192.168.1.10")},
+		{Hostname: "printer", IP: net.ParseIP("192.168.1.11")},
+		{Expires: time.Unix(1000, 0), Hostname: "old-phone", IP: net.ParseIP("192.168.1.13")},
+		{Expires: time.Unix(1893456000, 0), Hostname: "laptop", IP: net.ParseIP("fd00::10")},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("ParseDnsmasqLeases() = '%v',\nwant '%v'", got, want)
+	}
+} // Test_ParseDnsmasqLeases()
+
+func Test_ParseISCLeases(t *testing.T) {
+	got, err := ParseISCLeases(strings.NewReader(testISCLeases))
+	if nil != err {
+		t.Fatalf("ParseISCLeases() error = '%v'", err)
+	}
+
+	want := []TLease{
+		{Expires: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), Hostname: "workstation", IP: net.ParseIP("192.168.1.20")},
+		{Hostname: "tv", IP: net.ParseIP("192.168.1.21")},
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("ParseISCLeases() = '%v',\nwant '%v'", got, want)
+	}
+} // Test_ParseISCLeases()
+
+func Test_TResolver_SetLeases(t *testing.T) {
+	r := &TResolver{leases: newLeases(), rewrites: newRewriter()}
+	leases, _ := ParseDnsmasqLeases(strings.NewReader(testDnsmasqLeases))
+	r.SetLeases("dnsmasq", "home.lan", leases)
+
+	tests := []struct {
+		name     string
+		hostname string
+		want     []net.IP
+	}{
+		/* */
+		{
+			name:     "01 - unknown host",
+			hostname: "tablet",
+			want:     nil,
+		},
+		{
+			name:     "02 - unqualified name",
+			hostname: "Laptop",
+			want:     []net.IP{net.ParseIP("192.168.1.10"), net.ParseIP("fd00::10")},
+		},
+		{
+			name:     "03 - qualified name",
+			hostname: "printer.home.lan.",
+			want:     []net.IP{net.ParseIP("192.168.1.11")},
+		},
+		{
+			name:     "04 - expired lease",
+			hostname: "old-phone",
+			want:     nil,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := r.leases.lookup(tc.hostname)
+			if !slices.EqualFunc(got, tc.want, net.IP.Equal) {
+				t.Errorf("lookup() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+
+	if got, ok := r.LookupAddr(net.ParseIP("192.168.1.11")); !ok || ("printer.home.lan" != got) {
+		t.Errorf("LookupAddr() = '%v', '%v', want 'printer.home.lan', 'true'", got, ok)
+	}
+	if got := len(r.Leases()); 3 != got {
+		t.Errorf("len(Leases()) = '%d', want '3'", got)
+	}
+
+	r.SetLeases("dnsmasq", "", nil)
+	if got, ok := r.LookupAddr(net.ParseIP("192.168.1.11")); ok {
+		t.Errorf("LookupAddr() = '%v', want no lease", got)
+	}
+} // Test_TResolver_SetLeases()
+
+func Test_TResolver_watchLeaseFile(t *testing.T) {
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir()})
+	defer r.StopExpire()
+	fName := filepath.Join(t.TempDir(), "dhcpd.leases")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	if err := r.watchLeaseFile(ctx, fName, "", 10*time.Millisecond); nil == err {
+		t.Errorf("watchLeaseFile() error = 'nil', want an error for a missing file")
+	}
+
+	if err := os.WriteFile(fName, []byte(testISCLeases), 0600); nil != err {
+		t.Fatalf("os.WriteFile() error = '%v'", err)
+	}
+	if err := r.watchLeaseFile(ctx, fName, "", 10*time.Millisecond); nil != err {
+		t.Fatalf("watchLeaseFile() error = '%v'", err)
+	}
+	if ips, err := r.Fetch("tv"); (nil != err) || (1 != len(ips)) {
+		t.Errorf("Fetch() = '%v', '%v', want '192.168.1.21'", ips, err)
+	}
+
+	// The file's changes have to be picked up
+	changed := strings.Replace(testISCLeases, `"tv"`, `"television"`, 1)
+	if err := os.WriteFile(fName, []byte(changed), 0600); nil != err {
+		t.Fatalf("os.WriteFile() error = '%v'", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if name, _ := r.LookupAddr(net.ParseIP("192.168.1.21")); "television" == name {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("LookupAddr() didn't pick up the changed lease file")
+} // Test_TResolver_watchLeaseFile()
+
+/* _EoF_ */
@@ -0,0 +1,17 @@
+//go:build !nodebug
+
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+const (
+	// `debugLogging` enables the DEBUG level messages; build with
+	// the `nodebug` tag to compile them out.
+	debugLogging = true
+)
+
+/* _EoF_ */
@@ -0,0 +1,17 @@
+//go:build nodebug
+
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+const (
+	// `debugLogging` disables the DEBUG level messages as the
+	// program was built with the `nodebug` tag.
+	debugLogging = false
+)
+
+/* _EoF_ */
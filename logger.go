@@ -0,0 +1,319 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `ILogger` is the interface for structured logging.
+	//
+	// The optional `aFields` are alternating key/value pairs
+	// (as used by the standard `log/slog` package), e.g.
+	//
+	//	logger.Info("cache refreshed", "entries", 42, "took", dur)
+	ILogger interface {
+		Debug(aMsg string, aFields ...any)
+		Error(aMsg string, aFields ...any)
+		Info(aMsg string, aFields ...any)
+		Warn(aMsg string, aFields ...any)
+	}
+
+	// `tSlogLogger` is the default `ILogger` implementation
+	// backed by a `slog.Logger`.
+	tSlogLogger struct {
+		*slog.Logger
+	}
+
+	// `tComponentLogger` is the logger handed out by `Logger()`.
+	//
+	// It filters the messages by the component's current log level
+	// and forwards the remaining ones to the active backend logger.
+	tComponentLogger struct {
+		component string
+	}
+)
+
+const (
+	// `LogComponentField` is the field name used to pass a log
+	// message's component name to the backend logger.
+	LogComponentField = "component"
+)
+
+var (
+	// `gLogBackend` is the currently active backend logger.
+	gLogBackend atomic.Pointer[ILogger]
+
+	// `gLogDefaultLevel` is the log level used for all components
+	// without a level of their own.
+	gLogDefaultLevel = func() *slog.LevelVar {
+		lv := new(slog.LevelVar)
+		lv.Set(slog.LevelInfo)
+		return lv
+	}()
+
+	// `gLogLevels` holds the per-component log levels
+	// (`string` → `*slog.LevelVar`).
+	gLogLevels sync.Map
+
+	// `gLog` is the logger used by the resolver itself.
+	gLog = Logger("resolver")
+)
+
+func init() {
+	var (
+		_ ILogger = (*tComponentLogger)(nil)
+		_ ILogger = (*tSlogLogger)(nil)
+	)
+} // init()
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `DebugEnabled()` checks whether the given component logs DEBUG
+// level messages.
+//
+// Code on hot paths (e.g. per query) should check it before calling
+// `Debug()` to avoid preparing the message's fields for nothing.
+// Programs built with the `nodebug` tag never log DEBUG messages, so
+// the check is a constant `false` then and the compiler removes the
+// guarded code completely.
+//
+// Parameters:
+//   - `aComponent`: The name of the component (empty for the default).
+//
+// Returns:
+//   - `bool`: `true` if DEBUG messages are logged, `false` otherwise.
+func DebugEnabled(aComponent string) bool {
+	return debugLogging && (slog.LevelDebug >= LogLevel(aComponent))
+} // DebugEnabled()
+
+// `logBackend()` returns the currently active backend logger.
+//
+// Returns:
+//   - `ILogger`: The backend logger to use.
+func logBackend() ILogger {
+	if l := gLogBackend.Load(); nil != l {
+		return *l
+	}
+
+	// Lazy initialisation with the default logger
+	var l ILogger = NewSlogLogger(nil)
+	if gLogBackend.CompareAndSwap(nil, &l) {
+		return l
+	}
+
+	return *gLogBackend.Load()
+} // logBackend()
+
+// `Logger()` returns a logger for the given component.
+//
+// The returned logger only passes those messages to the backend
+// logger (see `SetLogger()`) whose level is at least the component's
+// current log level (see `SetLogLevel()`). Each message gets an
+// additional field `component` holding the given name.
+//
+// Parameters:
+//   - `aComponent`: The name of the component the logger is used by.
+//
+// Returns:
+//   - `ILogger`: The logger for the given component.
+func Logger(aComponent string) ILogger {
+	return &tComponentLogger{component: aComponent}
+} // Logger()
+
+// `LogLevel()` returns the current log level of the given component.
+//
+// Parameters:
+//   - `aComponent`: The name of the component (empty for the default).
+//
+// Returns:
+//   - `slog.Level`: The component's current log level.
+func LogLevel(aComponent string) slog.Level {
+	if "" != aComponent {
+		if lv, ok := gLogLevels.Load(aComponent); ok {
+			return lv.(*slog.LevelVar).Level()
+		}
+	}
+
+	return gLogDefaultLevel.Level()
+} // LogLevel()
+
+// `NewSlogLogger()` returns an `ILogger` backed by the standard
+// `log/slog` package.
+//
+// If `aHandler` is `nil` a text handler writing to `StdErr` is used.
+// Since the level filtering is done by the component loggers (see
+// `Logger()`) the given handler should accept all levels.
+//
+// Parameters:
+//   - `aHandler`: The `slog` handler to write the messages to.
+//
+// Returns:
+//   - `ILogger`: The new `slog`-backed logger.
+func NewSlogLogger(aHandler slog.Handler) ILogger {
+	if nil == aHandler {
+		aHandler = slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+			Level: slog.LevelDebug,
+		})
+	}
+
+	return &tSlogLogger{slog.New(aHandler)}
+} // NewSlogLogger()
+
+// `SetLogger()` replaces the backend logger used by all components.
+//
+// Parameters:
+//   - `aLogger`: The new backend logger (`nil` restores the default).
+func SetLogger(aLogger ILogger) {
+	if nil == aLogger {
+		aLogger = NewSlogLogger(nil)
+	}
+	gLogBackend.Store(&aLogger)
+} // SetLogger()
+
+// `SetLogLevel()` sets the log level of the given component.
+//
+// The level can be changed at any time while the program is running.
+// An empty component name sets the default level used by all
+// components without a level of their own.
+//
+// Parameters:
+//   - `aComponent`: The name of the component (empty for the default).
+//   - `aLevel`: The new log level.
+func SetLogLevel(aComponent string, aLevel slog.Level) {
+	if "" == aComponent {
+		gLogDefaultLevel.Set(aLevel)
+		return
+	}
+
+	lv, _ := gLogLevels.LoadOrStore(aComponent, new(slog.LevelVar))
+	lv.(*slog.LevelVar).Set(aLevel)
+} // SetLogLevel()
+
+// ---------------------------------------------------------------------------
+// `tComponentLogger` methods:
+
+// `Debug()` logs a message at DEBUG level.
+//
+// Programs built with the `nodebug` tag don't log DEBUG messages at
+// all (see [DebugEnabled]).
+//
+// Parameters:
+//   - `aMsg`: The message to log.
+//   - `aFields`: Optional key/value pairs to add to the message.
+func (cl *tComponentLogger) Debug(aMsg string, aFields ...any) {
+	if debugLogging && cl.enabled(slog.LevelDebug) {
+		logBackend().Debug(aMsg, cl.fields(aFields)...)
+	}
+} // Debug()
+
+// `enabled()` checks whether the component logs at the given level.
+//
+// Parameters:
+//   - `aLevel`: The level to check.
+//
+// Returns:
+//   - `bool`: `true` if messages of the given level are to be logged.
+func (cl *tComponentLogger) enabled(aLevel slog.Level) bool {
+	return aLevel >= LogLevel(cl.component)
+} // enabled()
+
+// `Error()` logs a message at ERROR level.
+//
+// Parameters:
+//   - `aMsg`: The message to log.
+//   - `aFields`: Optional key/value pairs to add to the message.
+func (cl *tComponentLogger) Error(aMsg string, aFields ...any) {
+	if cl.enabled(slog.LevelError) {
+		logBackend().Error(aMsg, cl.fields(aFields)...)
+	}
+} // Error()
+
+// `fields()` prepends the component's name to the given fields.
+//
+// Parameters:
+//   - `aFields`: The key/value pairs to extend.
+//
+// Returns:
+//   - `[]any`: The extended list of fields.
+func (cl *tComponentLogger) fields(aFields []any) []any {
+	result := make([]any, 0, len(aFields)+2)
+	result = append(result, LogComponentField, cl.component)
+
+	return append(result, aFields...)
+} // fields()
+
+// `Info()` logs a message at INFO level.
+//
+// Parameters:
+//   - `aMsg`: The message to log.
+//   - `aFields`: Optional key/value pairs to add to the message.
+func (cl *tComponentLogger) Info(aMsg string, aFields ...any) {
+	if cl.enabled(slog.LevelInfo) {
+		logBackend().Info(aMsg, cl.fields(aFields)...)
+	}
+} // Info()
+
+// `Warn()` logs a message at WARN level.
+//
+// Parameters:
+//   - `aMsg`: The message to log.
+//   - `aFields`: Optional key/value pairs to add to the message.
+func (cl *tComponentLogger) Warn(aMsg string, aFields ...any) {
+	if cl.enabled(slog.LevelWarn) {
+		logBackend().Warn(aMsg, cl.fields(aFields)...)
+	}
+} // Warn()
+
+// ---------------------------------------------------------------------------
+// `tSlogLogger` methods:
+
+// `Debug()` logs a message at DEBUG level.
+//
+// Parameters:
+//   - `aMsg`: The message to log.
+//   - `aFields`: Optional key/value pairs to add to the message.
+func (sl *tSlogLogger) Debug(aMsg string, aFields ...any) {
+	sl.Log(context.Background(), slog.LevelDebug, aMsg, aFields...)
+} // Debug()
+
+// `Error()` logs a message at ERROR level.
+//
+// Parameters:
+//   - `aMsg`: The message to log.
+//   - `aFields`: Optional key/value pairs to add to the message.
+func (sl *tSlogLogger) Error(aMsg string, aFields ...any) {
+	sl.Log(context.Background(), slog.LevelError, aMsg, aFields...)
+} // Error()
+
+// `Info()` logs a message at INFO level.
+//
+// Parameters:
+//   - `aMsg`: The message to log.
+//   - `aFields`: Optional key/value pairs to add to the message.
+func (sl *tSlogLogger) Info(aMsg string, aFields ...any) {
+	sl.Log(context.Background(), slog.LevelInfo, aMsg, aFields...)
+} // Info()
+
+// `Warn()` logs a message at WARN level.
+//
+// Parameters:
+//   - `aMsg`: The message to log.
+//   - `aFields`: Optional key/value pairs to add to the message.
+func (sl *tSlogLogger) Warn(aMsg string, aFields ...any) {
+	sl.Log(context.Background(), slog.LevelWarn, aMsg, aFields...)
+} // Warn()
+
+/* _EoF_ */
@@ -0,0 +1,164 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_DebugEnabled(t *testing.T) {
+	defer SetLogLevel("", slog.LevelInfo)
+
+	SetLogLevel("", slog.LevelInfo)
+	SetLogLevel("debugTest", slog.LevelDebug)
+
+	tests := []struct {
+		name      string
+		component string
+		want      bool
+	}{
+		/* */
+		{
+			name:      "01 - default level",
+			component: "",
+			want:      false,
+		},
+		{
+			name:      "02 - component at debug level",
+			component: "debugTest",
+			want:      debugLogging,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := DebugEnabled(tc.component); got != tc.want {
+				t.Errorf("DebugEnabled() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_DebugEnabled()
+
+func Test_Logger(t *testing.T) {
+	var buf bytes.Buffer
+	SetLogger(NewSlogLogger(slog.NewTextHandler(&buf,
+		&slog.HandlerOptions{Level: slog.LevelDebug})))
+	defer SetLogger(nil)
+	defer SetLogLevel("", slog.LevelInfo)
+
+	tests := []struct {
+		name      string
+		component string
+		level     slog.Level // component level to set
+		log       func(ILogger)
+		want      string
+	}{
+		/* */
+		{
+			name:      "01 - info at info level",
+			component: "test01",
+			level:     slog.LevelInfo,
+			log:       func(l ILogger) { l.Info("hello", "key", 1) },
+			want:      "level=INFO msg=hello component=test01 key=1",
+		},
+		{
+			name:      "02 - debug at info level",
+			component: "test02",
+			level:     slog.LevelInfo,
+			log:       func(l ILogger) { l.Debug("hello") },
+			want:      "",
+		},
+		{
+			name:      "03 - debug at debug level",
+			component: "test03",
+			level:     slog.LevelDebug,
+			log:       func(l ILogger) { l.Debug("hello") },
+			want:      "level=DEBUG msg=hello component=test03",
+		},
+		{
+			name:      "04 - warn at error level",
+			component: "test04",
+			level:     slog.LevelError,
+			log:       func(l ILogger) { l.Warn("hello") },
+			want:      "",
+		},
+		{
+			name:      "05 - error at error level",
+			component: "test05",
+			level:     slog.LevelError,
+			log:       func(l ILogger) { l.Error("hello", "error", "oops") },
+			want:      "level=ERROR msg=hello component=test05 error=oops",
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			buf.Reset()
+			SetLogLevel(tc.component, tc.level)
+			tc.log(Logger(tc.component))
+
+			got := buf.String()
+			if "" == tc.want {
+				if "" != got {
+					t.Errorf("Logger() wrote '%s', want ''", got)
+				}
+				return
+			}
+			if !strings.Contains(got, tc.want) {
+				t.Errorf("Logger() wrote '%s', want '%s'", got, tc.want)
+			}
+		})
+	}
+} // Test_Logger()
+
+func Test_LogLevel(t *testing.T) {
+	defer SetLogLevel("", slog.LevelInfo)
+
+	SetLogLevel("", slog.LevelWarn)
+	SetLogLevel("levelTest", slog.LevelDebug)
+
+	tests := []struct {
+		name      string
+		component string
+		want      slog.Level
+	}{
+		/* */
+		{
+			name:      "01 - default level",
+			component: "",
+			want:      slog.LevelWarn,
+		},
+		{
+			name:      "02 - unknown component",
+			component: "unknown",
+			want:      slog.LevelWarn,
+		},
+		{
+			name:      "03 - component level",
+			component: "levelTest",
+			want:      slog.LevelDebug,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := LogLevel(tc.component); got != tc.want {
+				t.Errorf("LogLevel() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_LogLevel()
+
+/* _EoF_ */
@@ -0,0 +1,119 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+const (
+	// `mdnsSuffix` is the domain resolved via multicast DNS.
+	mdnsSuffix = ".local"
+
+	// `defMDNSTimeout` is the time to wait for mDNS responses.
+	defMDNSTimeout = time.Second
+)
+
+var (
+	// `gMDNSAddr` is the multicast address mDNS queries are sent to.
+	gMDNSAddr = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `isMDNSName()` checks whether a hostname belongs to the `.local`
+// domain reserved for multicast DNS.
+//
+// Parameters:
+//   - `aHostname`: The hostname to check.
+//
+// Returns:
+//   - `bool`: `true` if the name is an mDNS name, `false` otherwise.
+func isMDNSName(aHostname string) bool {
+	hostname := strings.ToLower(strings.TrimSuffix(aHostname, "."))
+
+	return (len(hostname) > len(mdnsSuffix)) && strings.HasSuffix(hostname, mdnsSuffix)
+} // isMDNSName()
+
+// `lookupMDNS()` resolves a `.local` hostname via multicast DNS.
+//
+// The query is sent from an ephemeral port, hence responders answer
+// with a unicast "legacy" response (RFC 6762, section 6.7). The first
+// response containing addresses for the hostname is returned.
+//
+// Parameters:
+//   - `aCtx`: Context for the lookup operation.
+//   - `aHostname`: The hostname to resolve.
+//
+// Returns:
+//   - `[]net.IP`: List of IP addresses for the given hostname.
+//   - `time.Duration`: The TTL of the answer.
+//   - `error`: `nil` if the hostname was resolved successfully, the error otherwise.
+func lookupMDNS(aCtx context.Context, aHostname string) ([]net.IP, time.Duration, error) {
+	var idBytes [2]byte
+	_, _ = rand.Read(idBytes[:])
+	id := binary.BigEndian.Uint16(idBytes[:])
+	query := dnsQuery(id, 0, aHostname, dnsTypeA, dnsTypeAAAA)
+	if nil == query {
+		return nil, 0, &net.DNSError{Err: "invalid hostname", Name: aHostname}
+	}
+
+	conn, err := net.ListenUDP("udp", nil)
+	if nil != err {
+		return nil, 0, err
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(defMDNSTimeout)
+	if ctxDeadline, ok := aCtx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err = conn.SetDeadline(deadline); nil != err {
+		return nil, 0, err
+	}
+	if _, err = conn.WriteTo(query, gMDNSAddr); nil != err {
+		return nil, 0, err
+	}
+
+	buffer := make([]byte, 9000) // mDNS messages may use jumbo frames
+	for {
+		if nil != aCtx.Err() {
+			return nil, 0, aCtx.Err()
+		}
+		n, _, err := conn.ReadFrom(buffer)
+		if nil != err {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				return nil, 0, &net.DNSError{
+					Err:        "no mDNS response",
+					Name:       aHostname,
+					IsNotFound: true,
+				}
+			}
+			return nil, 0, err
+		}
+
+		response := buffer[:n]
+		if (12 > len(response)) || (binary.BigEndian.Uint16(response[0:2]) != id) {
+			continue // not an answer to our query
+		}
+		if ips, ttl, err := parseAnswers(response, aHostname); (nil == err) && (0 < len(ips)) {
+			return ips, ttl, nil
+		}
+	}
+} // lookupMDNS()
+
+/* _EoF_ */
@@ -0,0 +1,181 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"slices"
+	"testing"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// `mdnsResponse()` creates a response to `aQuery` answering its first
+// question with the given addresses (using name compression).
+func mdnsResponse(aQuery []byte, aIPs ...net.IP) []byte {
+	end := 12
+	for 0 != aQuery[end] {
+		end += int(aQuery[end]) + 1
+	}
+	end += 5 // terminating zero, type, and class
+
+	result := append([]byte{}, aQuery[:end]...)
+	binary.BigEndian.PutUint16(result[2:4], 0x8400) // QR, AA
+	binary.BigEndian.PutUint16(result[4:6], 1)
+	binary.BigEndian.PutUint16(result[6:8], uint16(len(aIPs))) //#nosec G115
+	for _, ip := range aIPs {
+		rType, data := dnsTypeAAAA, ip.To16()
+		if ip4 := ip.To4(); nil != ip4 {
+			rType, data = dnsTypeA, ip4
+		}
+		result = binary.BigEndian.AppendUint16(result, 0xC00C) // name pointer
+		result = binary.BigEndian.AppendUint16(result, rType)
+		result = binary.BigEndian.AppendUint16(result, 0x8000|dnsClassIN)
+		result = binary.BigEndian.AppendUint32(result, 120)
+		result = binary.BigEndian.AppendUint16(result, uint16(len(data))) //#nosec G115
+		result = append(result, data...)
+	}
+
+	return result
+} // mdnsResponse()
+
+// `startMDNSResponder()` starts a fake mDNS responder on the loopback
+// interface answering queries for `aHostname` and redirects the mDNS
+// queries to it.
+func startMDNSResponder(t *testing.T, aHostname string, aIPs ...net.IP) {
+	t.Helper()
+
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if nil != err {
+		t.Fatalf("net.ListenUDP() error = '%v'", err)
+	}
+	oldAddr := gMDNSAddr
+	gMDNSAddr = conn.LocalAddr().(*net.UDPAddr)
+	t.Cleanup(func() {
+		gMDNSAddr = oldAddr
+		conn.Close()
+	})
+
+	go func() {
+		buffer := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buffer)
+			if nil != err {
+				return
+			}
+			name, _, err := dnsName(buffer[:n], 12)
+			if (nil != err) || (name != aHostname) {
+				continue
+			}
+			// send some noise first
+			_, _ = conn.WriteTo([]byte{0, 0, 0}, addr)
+			_, _ = conn.WriteTo(mdnsResponse(buffer[:n], aIPs...), addr)
+		}
+	}()
+} // startMDNSResponder()
+
+func Test_isMDNSName(t *testing.T) {
+	tests := []struct {
+		name     string
+		hostname string
+		want     bool
+	}{
+		/* */
+		{"01 - empty name", "", false},
+		{"02 - domain only", "local", false},
+		{"03 - suffix only", ".local", false},
+		{"04 - mDNS name", "printer.local", true},
+		{"05 - mDNS name with dot", "Printer.LOCAL.", true},
+		{"06 - unicast name", "printer.localhost", false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isMDNSName(tc.hostname); got != tc.want {
+				t.Errorf("isMDNSName() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_isMDNSName()
+
+func Test_lookupMDNS(t *testing.T) {
+	ip := net.ParseIP("192.168.1.20")
+	startMDNSResponder(t, "printer.local", ip)
+
+	tests := []struct {
+		name     string
+		hostname string
+		want     []net.IP
+		wantErr  bool
+	}{
+		/* */
+		{
+			name:     "01 - known host",
+			hostname: "Printer.local",
+			want:     []net.IP{ip},
+		},
+		{
+			name:     "02 - unknown host",
+			hostname: "scanner.local",
+			wantErr:  true,
+		},
+		{
+			name:     "03 - invalid hostname",
+			hostname: "printer..local",
+			wantErr:  true,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+			defer cancel()
+
+			got, _, err := lookupMDNS(ctx, tc.hostname)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("lookupMDNS() error = '%v', wantErr '%v'", err, tc.wantErr)
+				return
+			}
+			if !slices.EqualFunc(got, tc.want, net.IP.Equal) {
+				t.Errorf("lookupMDNS() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_lookupMDNS()
+
+func Test_TResolver_mdns(t *testing.T) {
+	ip := net.ParseIP("192.168.1.20")
+	startMDNSResponder(t, "printer.local", ip)
+
+	r := NewWithOptions(TResolverOptions{
+		DataDir:    t.TempDir(),
+		DNSservers: []string{"192.0.2.1"}, // must not be asked
+		MDNS:       true,
+	})
+	defer r.StopExpire()
+
+	got, err := r.Fetch("printer.local")
+	if nil != err {
+		t.Fatalf("Fetch() error = '%v'", err)
+	}
+	if !slices.EqualFunc(got, []net.IP{ip}, net.IP.Equal) {
+		t.Errorf("Fetch() = '%v', want '%v'", got, ip)
+	}
+
+	// The answer has to be cached
+	ctx := context.Background()
+	if ips, ok := r.ICacheList.IPs(ctx, "printer.local"); !ok || (1 != len(ips)) {
+		t.Errorf("IPs() = '%v', '%v', want '%v', 'true'", ips, ok, ip)
+	}
+} // Test_TResolver_mdns()
+
+/* _EoF_ */
@@ -7,7 +7,11 @@ Copyright © 2025  M.Watermann, 10247 Berlin, Germany
 package dnscache
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
+	"os"
 	"strings"
 	"sync/atomic"
 )
@@ -24,6 +28,7 @@ type (
 	//   - `Misses`: Number of cache misses,
 	//   - `Retries`: Number of lookup retries,
 	//   - `Errors`: Number of lookup errors,
+	//   - `Blocked`: Number of blocked lookups,
 	//   - `Peak`: Peak number of cached entries.
 	TMetrics struct {
 		Lookups uint32
@@ -31,8 +36,23 @@ type (
 		Misses  uint32
 		Retries uint32
 		Errors  uint32
+		Blocked uint32
 		Peak    uint32
 	}
+
+	// `tMetricsFile` is the versioned layout of the file written by
+	// [SaveMetrics].
+	tMetricsFile struct {
+		Version int       `json:"version"`
+		Metrics *TMetrics `json:"metrics"`
+	}
+)
+
+const (
+	// `metricsVersion` is the version of the metrics file layout.
+	//
+	// Version 0 files contain the bare `TMetrics` object.
+	metricsVersion = 1
 )
 
 var (
@@ -46,6 +66,25 @@ var (
 // ---------------------------------------------------------------------------
 // `TMetrics` methods:
 
+// `add()` adds the given metrics data to the current one.
+//
+// The counters are summed up while the peak is the larger one of both.
+//
+// Parameters:
+//   - `aMetrics`: Metrics data to add.
+func (m *TMetrics) add(aMetrics *TMetrics) {
+	if (nil == m) || (nil == aMetrics) {
+		return
+	}
+	atomic.AddUint32(&m.Lookups, aMetrics.Lookups)
+	atomic.AddUint32(&m.Hits, aMetrics.Hits)
+	atomic.AddUint32(&m.Misses, aMetrics.Misses)
+	atomic.AddUint32(&m.Retries, aMetrics.Retries)
+	atomic.AddUint32(&m.Errors, aMetrics.Errors)
+	atomic.AddUint32(&m.Blocked, aMetrics.Blocked)
+	setMetricsFieldMax(&m.Peak, aMetrics.Peak)
+} // add()
+
 // `check()` verifies the consistency of the metrics data.
 //
 // Parameters:
@@ -87,6 +126,7 @@ func (m *TMetrics) clone() *TMetrics {
 		Misses:  atomic.LoadUint32(&m.Misses),
 		Retries: atomic.LoadUint32(&m.Retries),
 		Errors:  atomic.LoadUint32(&m.Errors),
+		Blocked: atomic.LoadUint32(&m.Blocked),
 		Peak:    atomic.LoadUint32(&m.Peak),
 	}
 } // clone()
@@ -111,6 +151,7 @@ func (m *TMetrics) Equal(aMetrics *TMetrics) bool {
 		(m.Misses == aMetrics.Misses) &&
 		(m.Retries == aMetrics.Retries) &&
 		(m.Errors == aMetrics.Errors) &&
+		(m.Blocked == aMetrics.Blocked) &&
 		(m.Peak == aMetrics.Peak)
 } // Equal()
 
@@ -133,11 +174,94 @@ func (m *TMetrics) String() string {
 	fmt.Fprintf(&builder, "Misses: %d\n", m.Misses)
 	fmt.Fprintf(&builder, "Retries: %d\n", m.Retries)
 	fmt.Fprintf(&builder, "Errors: %d\n", m.Errors)
+	fmt.Fprintf(&builder, "Blocked: %d\n", m.Blocked)
 	fmt.Fprintf(&builder, "Peak: %d\n", m.Peak)
 
 	return builder.String()
 } // String()
 
+// ---------------------------------------------------------------------------
+// `TResolver` methods:
+
+// `LoadMetrics()` adds the metrics data saved by [SaveMetrics] to the
+// current one.
+//
+// This allows for cumulative metrics across restarts of a program.
+// A missing file is not an error, there's just nothing to restore.
+// Files written by older versions are migrated while those written by
+// a newer version are refused (and left untouched).
+//
+// Parameters:
+//   - `aFilename`: The file to read the metrics data from.
+//
+// Returns:
+//   - `error`: `nil` if the data were restored, the error otherwise.
+func (r *TResolver) LoadMetrics(aFilename string) error {
+	data, err := os.ReadFile(aFilename) //#nosec G304
+	if nil != err {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	var saved tMetricsFile
+	if err = json.Unmarshal(data, &saved); nil != err {
+		return fmt.Errorf("invalid metrics file %q: %w", aFilename, err)
+	}
+	switch {
+	case metricsVersion < saved.Version:
+		return fmt.Errorf("metrics file %q (v%d): %w",
+			aFilename, saved.Version, ErrFileVersion)
+	case (0 == saved.Version) || (nil == saved.Metrics):
+		// Migrate a version 0 file holding the bare metrics
+		saved.Metrics = new(TMetrics)
+		if err = json.Unmarshal(data, saved.Metrics); nil != err {
+			return fmt.Errorf("invalid metrics file %q: %w", aFilename, err)
+		}
+	}
+	gMetrics.add(saved.Metrics)
+
+	return nil
+} // LoadMetrics()
+
+// `ResetMetrics()` sets all metrics data to zero.
+func (r *TResolver) ResetMetrics() {
+	for _, field := range []*uint32{&gMetrics.Lookups, &gMetrics.Hits,
+		&gMetrics.Misses, &gMetrics.Retries, &gMetrics.Errors,
+		&gMetrics.Blocked, &gMetrics.Peak} {
+		atomic.StoreUint32(field, 0)
+	}
+} // ResetMetrics()
+
+// `SaveMetrics()` writes the current metrics data to a file.
+//
+// The file is replaced atomically, i.e. it's either the previous or
+// the new version but never a partially written one.
+//
+// Parameters:
+//   - `aFilename`: The file to write the metrics data to.
+//
+// Returns:
+//   - `error`: `nil` if the data were saved, the error otherwise.
+func (r *TResolver) SaveMetrics(aFilename string) error {
+	data, err := json.Marshal(tMetricsFile{
+		Version: metricsVersion,
+		Metrics: gMetrics.clone(),
+	})
+	if nil != err {
+		return err
+	}
+
+	tmpName := aFilename + "~"
+	if err = os.WriteFile(tmpName, data, 0640); nil != err { //#nosec G306
+		_ = os.Remove(tmpName)
+		return err
+	}
+
+	return os.Rename(tmpName, aFilename)
+} // SaveMetrics()
+
 // ---------------------------------------------------------------------------
 // Helper functions:
 
@@ -7,6 +7,9 @@ Copyright © 2025  M.Watermann, 10247 Berlin, Germany
 package dnscache
 
 import (
+	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -182,7 +185,7 @@ func Test_TMetrics_String(t *testing.T) {
 				Errors:  0,
 				Peak:    0,
 			},
-			want: "Lookups: 0\nHits: 0\nMisses: 0\nRetries: 0\nErrors: 0\nPeak: 0\n",
+			want: "Lookups: 0\nHits: 0\nMisses: 0\nRetries: 0\nErrors: 0\nBlocked: 0\nPeak: 0\n",
 		},
 		{
 			name: "02 - all non-zero",
@@ -194,7 +197,7 @@ func Test_TMetrics_String(t *testing.T) {
 				Errors:  1,
 				Peak:    8,
 			},
-			want: "Lookups: 10\nHits: 7\nMisses: 3\nRetries: 2\nErrors: 1\nPeak: 8\n",
+			want: "Lookups: 10\nHits: 7\nMisses: 3\nRetries: 2\nErrors: 1\nBlocked: 0\nPeak: 8\n",
 		},
 
 		// TODO: Add test cases.
@@ -210,4 +213,92 @@ func Test_TMetrics_String(t *testing.T) {
 	}
 } // Test_TMetrics_String()
 
+func Test_TResolver_SaveMetrics(t *testing.T) {
+	r := &TResolver{}
+	dir := t.TempDir()
+	fName := filepath.Join(dir, "metrics.json")
+	gMetrics = &TMetrics{Lookups: 10, Hits: 7, Misses: 3, Blocked: 2, Peak: 8}
+
+	if err := r.SaveMetrics(fName); nil != err {
+		t.Fatalf("SaveMetrics() error = '%v'", err)
+	}
+	r.ResetMetrics()
+	if got := r.Metrics(); !got.Equal(&TMetrics{}) {
+		t.Errorf("ResetMetrics() = '%v', want zero", got)
+	}
+
+	gMetrics = &TMetrics{Lookups: 1, Hits: 1, Peak: 9}
+	if err := r.LoadMetrics(fName); nil != err {
+		t.Fatalf("LoadMetrics() error = '%v'", err)
+	}
+	want := &TMetrics{Lookups: 11, Hits: 8, Misses: 3, Blocked: 2, Peak: 9}
+	if got := r.Metrics(); !got.Equal(want) {
+		t.Errorf("LoadMetrics() = '%v', want '%v'", got, want)
+	}
+
+	// Missing files are ignored, invalid ones are not
+	if err := r.LoadMetrics(filepath.Join(dir, "missing.json")); nil != err {
+		t.Errorf("LoadMetrics(missing) error = '%v', want 'nil'", err)
+	}
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(invalid, []byte("{"), 0600); nil != err {
+		t.Fatal(err)
+	}
+	if err := r.LoadMetrics(invalid); nil == err {
+		t.Error("LoadMetrics(invalid) error = 'nil', want error")
+	}
+	if got := r.Metrics(); !got.Equal(want) {
+		t.Errorf("LoadMetrics(invalid) = '%v', want '%v'", got, want)
+	}
+} // Test_TResolver_SaveMetrics()
+
+func Test_TResolver_LoadMetrics(t *testing.T) {
+	r := &TResolver{}
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		data    string
+		want    *TMetrics
+		wantErr error
+	}{
+		/* */
+		{
+			name: "01 - current version",
+			data: `{"version":1,"metrics":{"Lookups":5,"Hits":4,"Peak":3}}`,
+			want: &TMetrics{Lookups: 5, Hits: 4, Peak: 3},
+		},
+		{
+			name: "02 - version 0 file",
+			data: `{"Lookups":5,"Hits":4,"Misses":1,"Peak":3}`,
+			want: &TMetrics{Lookups: 5, Hits: 4, Misses: 1, Peak: 3},
+		},
+		{
+			name:    "03 - newer version",
+			data:    `{"version":99,"metrics":{"Lookups":5}}`,
+			want:    &TMetrics{},
+			wantErr: ErrFileVersion,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			fName := filepath.Join(dir, "metrics.json")
+			if err := os.WriteFile(fName, []byte(tc.data), 0600); nil != err {
+				t.Fatal(err)
+			}
+			r.ResetMetrics()
+
+			err := r.LoadMetrics(fName)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("LoadMetrics() error = '%v', want '%v'", err, tc.wantErr)
+			}
+			if got := r.Metrics(); !got.Equal(tc.want) {
+				t.Errorf("LoadMetrics() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_TResolver_LoadMetrics()
+
 /* _EoF_ */
@@ -0,0 +1,208 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tHostPatterns` is a thread-safe list of hostname wildcard patterns.
+	//
+	// The patterns use the shell's glob syntax: `*` matches any sequence
+	// of characters (including dots), `?` a single character, and `[…]`
+	// a character class (e.g. `*.dyn.example.com` or `health-?.example.com`).
+	tHostPatterns struct {
+		sync.RWMutex
+		patterns []string
+	}
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `normaliseHostPattern()` validates a hostname pattern and returns its
+// normalised form.
+//
+// Parameters:
+//   - `aPattern`: The pattern to normalise.
+//
+// Returns:
+//   - `string`: The lower-cased pattern without surrounding dots.
+//   - `error`: `nil` if the pattern is valid, the error otherwise.
+func normaliseHostPattern(aPattern string) (string, error) {
+	pattern := strings.Trim(strings.ToLower(strings.TrimSpace(aPattern)), ".")
+	if ("" == pattern) || strings.ContainsAny(pattern, "/ ") {
+		return "", fmt.Errorf("invalid pattern: %q", aPattern)
+	}
+	if _, err := path.Match(pattern, ""); nil != err {
+		return "", fmt.Errorf("invalid pattern %q: %w", aPattern, err)
+	}
+
+	return pattern, nil
+} // normaliseHostPattern()
+
+// `matchHostPattern()` checks whether a hostname matches a normalised pattern.
+//
+// Parameters:
+//   - `aPattern`: The normalised pattern.
+//   - `aHostname`: The normalised hostname.
+//
+// Returns:
+//   - `bool`: `true` if the hostname matches, `false` otherwise.
+func matchHostPattern(aPattern, aHostname string) bool {
+	ok, _ := path.Match(aPattern, aHostname)
+
+	return ok
+} // matchHostPattern()
+
+// ---------------------------------------------------------------------------
+// `tHostPatterns` methods:
+
+// `add()` inserts a pattern into the list.
+//
+// Parameters:
+//   - `aPattern`: The pattern to add.
+//
+// Returns:
+//   - `error`: `nil` if the pattern was added (or already present), the error otherwise.
+func (hp *tHostPatterns) add(aPattern string) error {
+	pattern, err := normaliseHostPattern(aPattern)
+	if nil != err {
+		return err
+	}
+
+	hp.Lock()
+	defer hp.Unlock()
+
+	if !slices.Contains(hp.patterns, pattern) {
+		hp.patterns = append(hp.patterns, pattern)
+		slices.Sort(hp.patterns)
+	}
+
+	return nil
+} // add()
+
+// `delete()` removes a pattern from the list.
+//
+// Parameters:
+//   - `aPattern`: The pattern to remove.
+//
+// Returns:
+//   - `bool`: `true` if the pattern was removed, `false` otherwise.
+func (hp *tHostPatterns) delete(aPattern string) bool {
+	if nil == hp {
+		return false
+	}
+	pattern := strings.Trim(strings.ToLower(strings.TrimSpace(aPattern)), ".")
+
+	hp.Lock()
+	defer hp.Unlock()
+
+	oldLen := len(hp.patterns)
+	hp.patterns = slices.DeleteFunc(hp.patterns, func(aP string) bool {
+		return aP == pattern
+	})
+
+	return oldLen != len(hp.patterns)
+} // delete()
+
+// `list()` returns a copy of all patterns.
+//
+// Returns:
+//   - `[]string`: The sorted patterns.
+func (hp *tHostPatterns) list() []string {
+	if nil == hp {
+		return nil
+	}
+	hp.RLock()
+	defer hp.RUnlock()
+
+	return slices.Clone(hp.patterns)
+} // list()
+
+// `match()` checks whether a hostname matches any pattern of the list.
+//
+// Parameters:
+//   - `aHostname`: The hostname to check.
+//
+// Returns:
+//   - `bool`: `true` if a pattern matches, `false` otherwise.
+func (hp *tHostPatterns) match(aHostname string) bool {
+	if nil == hp {
+		return false
+	}
+	hostname := strings.Trim(strings.ToLower(aHostname), ".")
+
+	hp.RLock()
+	defer hp.RUnlock()
+
+	for _, pattern := range hp.patterns {
+		if matchHostPattern(pattern, hostname) {
+			return true
+		}
+	}
+
+	return false
+} // match()
+
+// ---------------------------------------------------------------------------
+// `TResolver` methods:
+
+// `DeleteNeverCache()` removes a pattern from the cache bypass list.
+//
+// Parameters:
+//   - `aPattern`: The pattern to remove.
+//
+// Returns:
+//   - `bool`: `true` if the pattern was removed, `false` otherwise.
+func (r *TResolver) DeleteNeverCache(aPattern string) bool {
+	return r.neverCache.delete(aPattern)
+} // DeleteNeverCache()
+
+// `NeverCache()` adds a hostname pattern to the cache bypass list.
+//
+// Queries for matching hostnames always go to the upstream servers and
+// their answers are never stored; entries already cached are removed.
+// The pattern may contain the wildcards `*` (any sequence of characters
+// including dots), `?` (a single character), and `[…]` (a character
+// class), e.g. `*.dyndns.example.com`.
+//
+// Parameters:
+//   - `aPattern`: The hostname or wildcard pattern to bypass the cache for.
+//
+// Returns:
+//   - `error`: `nil` if the pattern was added, the error otherwise.
+func (r *TResolver) NeverCache(aPattern string) error {
+	if nil == r.neverCache {
+		return fmt.Errorf("invalid pattern: %q", aPattern)
+	}
+	if err := r.neverCache.add(aPattern); nil != err {
+		return err
+	}
+	if nil != r.ICacheList {
+		r.deleteCached(r.neverCache.match)
+	}
+
+	return nil
+} // NeverCache()
+
+// `NeverCachePatterns()` returns the patterns of the cache bypass list.
+//
+// Returns:
+//   - `[]string`: The sorted patterns.
+func (r *TResolver) NeverCachePatterns() []string {
+	return r.neverCache.list()
+} // NeverCachePatterns()
+
+/* _EoF_ */
@@ -0,0 +1,116 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"net"
+	"slices"
+	"testing"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_tHostPatterns_match(t *testing.T) {
+	hp := &tHostPatterns{}
+	for _, pattern := range []string{"*.dyn.example.com", "Health-?.Example.COM.", "[ab].test"} {
+		if err := hp.add(pattern); nil != err {
+			t.Fatalf("add(%q) error = '%v'", pattern, err)
+		}
+	}
+	for _, pattern := range []string{"", " . ", "[a.test", "a/b.test"} {
+		if err := hp.add(pattern); nil == err {
+			t.Errorf("add(%q) error = 'nil', want an error", pattern)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		hostname string
+		want     bool
+	}{
+		/* */
+		{"01 - empty hostname", "", false},
+		{"02 - wildcard subdomain", "home.dyn.example.com", true},
+		{"03 - wildcard deep subdomain", "a.b.dyn.example.com.", true},
+		{"04 - wildcard's domain", "dyn.example.com", false},
+		{"05 - single character", "health-1.example.com", true},
+		{"06 - two characters", "health-12.example.com", false},
+		{"07 - character class", "B.test", true},
+		{"08 - unmatched class", "c.test", false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := hp.match(tc.hostname); got != tc.want {
+				t.Errorf("match() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+
+	if !hp.delete("HEALTH-?.example.com") || hp.delete("health-?.example.com") {
+		t.Errorf("delete() didn't remove the pattern exactly once")
+	}
+	if got, want := hp.list(), []string{"*.dyn.example.com", "[ab].test"}; !slices.Equal(got, want) {
+		t.Errorf("list() = '%v', want '%v'", got, want)
+	}
+} // Test_tHostPatterns_match()
+
+func Test_TResolver_NeverCache(t *testing.T) {
+	ip := net.ParseIP("192.168.1.20")
+	startMDNSResponder(t, "printer.local", ip)
+
+	r := NewWithOptions(TResolverOptions{
+		DataDir:    t.TempDir(),
+		DNSservers: []string{"192.0.2.1"}, // must not be asked
+		MDNS:       true,
+		NeverCache: []string{"[", "*.example.org"}, // first one is invalid
+	})
+	defer r.StopExpire()
+
+	ctx := context.Background()
+	r.ICacheList.Create(ctx, "printer.local", []net.IP{ip}, time.Hour)
+	r.ICacheList.Create(ctx, "www.example.com", []net.IP{ip}, time.Hour)
+
+	if err := r.NeverCache("*.local"); nil != err {
+		t.Fatalf("NeverCache() error = '%v'", err)
+	}
+	if r.ICacheList.Exists(ctx, "printer.local") {
+		t.Errorf("NeverCache() didn't remove the cached entry")
+	}
+	if !r.ICacheList.Exists(ctx, "www.example.com") {
+		t.Errorf("NeverCache() removed an unmatched entry")
+	}
+
+	got, err := r.Fetch("printer.local")
+	if nil != err {
+		t.Fatalf("Fetch() error = '%v'", err)
+	}
+	if !slices.EqualFunc(got, []net.IP{ip}, net.IP.Equal) {
+		t.Errorf("Fetch() = '%v', want '%v'", got, ip)
+	}
+	if r.ICacheList.Exists(ctx, "printer.local") {
+		t.Errorf("Fetch() cached a bypassed hostname")
+	}
+
+	if want := []string{"*.example.org", "*.local"}; !slices.Equal(r.NeverCachePatterns(), want) {
+		t.Errorf("NeverCachePatterns() = '%v', want '%v'", r.NeverCachePatterns(), want)
+	}
+	if !r.DeleteNeverCache("*.local") {
+		t.Errorf("DeleteNeverCache() = 'false', want 'true'")
+	}
+	if _, err = r.Fetch("printer.local"); nil != err {
+		t.Fatalf("Fetch() error = '%v'", err)
+	}
+	if !r.ICacheList.Exists(ctx, "printer.local") {
+		t.Errorf("Fetch() didn't cache the no longer bypassed hostname")
+	}
+} // Test_TResolver_NeverCache()
+
+/* _EoF_ */
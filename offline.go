@@ -0,0 +1,42 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	adl "github.com/mwat56/dnscache/internal/adlist"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// ---------------------------------------------------------------------------
+// `TResolver` methods:
+
+// `Offline()` returns whether the resolver is in offline mode.
+//
+// Returns:
+//   - `bool`: `true` if upstream lookups are disabled, `false` otherwise.
+func (r *TResolver) Offline() bool {
+	return r.offline.Load()
+} // Offline()
+
+// `SetOffline()` switches the offline mode on or off.
+//
+// In offline mode only cached and locally defined hostnames (i.e.
+// rewrites, DHCP leases, and – if enabled – `.local` names) are
+// resolved; all other lookups fail with an error matching
+// `ErrOffline`. Blocklists aren't downloaded either but loaded from
+// their local copies; this applies to all resolvers since they share
+// the downloads.
+//
+// Parameters:
+//   - `aOffline`: Whether to disable upstream lookups and downloads.
+func (r *TResolver) SetOffline(aOffline bool) {
+	r.offline.Store(aOffline)
+	adl.SetOffline(aOffline)
+} // SetOffline()
+
+/* _EoF_ */
@@ -0,0 +1,71 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_TResolver_SetOffline(t *testing.T) {
+	r := NewWithOptions(TResolverOptions{
+		DataDir: t.TempDir(),
+		Offline: true,
+	})
+	defer r.StopExpire()
+	defer r.SetOffline(false)
+
+	if !r.Offline() {
+		t.Fatal("Offline() = 'false', want 'true'")
+	}
+	cachedIP := net.ParseIP("192.0.2.1")
+	r.ICacheList.Create(context.TODO(), "cached.test", []net.IP{cachedIP}, time.Minute)
+
+	tests := []struct {
+		name     string
+		hostname string
+		want     net.IP
+		wantErr  error
+	}{
+		/* */
+		{
+			name:     "01 - cached hostname",
+			hostname: "cached.test",
+			want:     cachedIP,
+		},
+		{
+			name:     "02 - uncached hostname",
+			hostname: "uncached.test",
+			wantErr:  ErrOffline,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := r.FetchFirst(tc.hostname)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("FetchFirst() error = '%v', want '%v'", err, tc.wantErr)
+			}
+			if !got.Equal(tc.want) {
+				t.Errorf("FetchFirst() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+
+	r.SetOffline(false)
+	if r.Offline() {
+		t.Error("Offline() = 'true', want 'false'")
+	}
+} // Test_TResolver_SetOffline()
+
+/* _EoF_ */
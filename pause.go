@@ -0,0 +1,101 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"time"
+
+	adl "github.com/mwat56/dnscache/internal/adlist"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `TTempAllow` describes a temporarily allowed hostname pattern
+	// as returned by [TResolver.TemporaryAllows].
+	TTempAllow = adl.TTempAllow
+)
+
+// ---------------------------------------------------------------------------
+// `TResolver` methods:
+
+// `adlists()` returns the default allow/deny list and those of all groups
+// and categories.
+//
+// Returns:
+//   - `[]*adl.TADlist`: All allow/deny lists of the resolver.
+func (r *TResolver) adlists() []*adl.TADlist {
+	result := []*adl.TADlist{r.adlist}
+	if g := r.groups; nil != g {
+		g.RLock()
+		for _, list := range g.lists {
+			result = append(result, list)
+		}
+		g.RUnlock()
+	}
+	if c := r.categories; nil != c {
+		c.RLock()
+		for _, cat := range c.lists {
+			result = append(result, cat.list)
+		}
+		c.RUnlock()
+	}
+
+	return result
+} // adlists()
+
+// `AllowTemporarily()` allows a hostname pattern for the given duration.
+//
+// The pattern is allowed for all clients (i.e. in the default list
+// and in all groups). After the duration expired, the pattern is
+// removed automatically; a duration of zero (or less) removes it
+// immediately.
+//
+// Parameters:
+//   - `aPattern`: The FQDN or wildcard pattern to allow.
+//   - `aDuration`: How long the pattern is to be allowed.
+//
+// Returns:
+//   - `bool`: `true` if the pattern was (dis)allowed, `false` otherwise.
+func (r *TResolver) AllowTemporarily(aPattern string, aDuration time.Duration) (rOK bool) {
+	for _, list := range r.adlists() {
+		rOK = list.AllowTemporarily(aPattern, aDuration)
+	}
+
+	return
+} // AllowTemporarily()
+
+// `BlockingPausedUntil()` returns the time blocking gets re-enabled.
+//
+// Returns:
+//   - `time.Time`: The end of the pause (zero if blocking is active).
+func (r *TResolver) BlockingPausedUntil() time.Time {
+	return r.adlist.DenyPausedUntil()
+} // BlockingPausedUntil()
+
+// `PauseBlocking()` suspends all deny lists for the given duration.
+//
+// After the duration expired, blocking is re-enabled automatically;
+// a duration of zero (or less) re-enables it immediately.
+//
+// Parameters:
+//   - `aDuration`: How long blocking is to be paused.
+func (r *TResolver) PauseBlocking(aDuration time.Duration) {
+	for _, list := range r.adlists() {
+		list.PauseDeny(aDuration)
+	}
+} // PauseBlocking()
+
+// `TemporaryAllows()` returns all temporarily allowed patterns.
+//
+// Returns:
+//   - `[]TTempAllow`: The patterns sorted by their expiry.
+func (r *TResolver) TemporaryAllows() []TTempAllow {
+	return r.adlist.TemporaryAllows()
+} // TemporaryAllows()
+
+/* _EoF_ */
@@ -0,0 +1,82 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	adl "github.com/mwat56/dnscache/internal/adlist"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_TResolver_AllowTemporarily(t *testing.T) {
+	ctx := context.TODO()
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir()})
+	defer r.StopExpire()
+	_ = r.AddGroup("kids")
+	_ = r.AddGroupDeny("kids", "*.games.tld")
+	r.adlist.AddDeny(ctx, "*.games.tld")
+	kids, _ := r.groups.list("kids")
+
+	if !r.AllowTemporarily("www.games.tld", time.Minute) {
+		t.Fatal("AllowTemporarily() = 'false', want 'true'")
+	}
+	for _, list := range []*adl.TADlist{r.adlist, kids} {
+		if got := list.Match(ctx, "www.games.tld"); adl.ADallow != got {
+			t.Errorf("TADlist.Match() = '%v', want '%v'", got, adl.ADallow)
+		}
+	}
+	if got := r.TemporaryAllows(); (1 != len(got)) || ("www.games.tld" != got[0].Pattern) {
+		t.Errorf("TemporaryAllows() = '%v', want '%v'", got, "www.games.tld")
+	}
+
+	r.AllowTemporarily("www.games.tld", 0)
+	for _, list := range []*adl.TADlist{r.adlist, kids} {
+		if got := list.Match(ctx, "www.games.tld"); adl.ADdeny != got {
+			t.Errorf("TADlist.Match() = '%v', want '%v'", got, adl.ADdeny)
+		}
+	}
+} // Test_TResolver_AllowTemporarily()
+
+func Test_TResolver_PauseBlocking(t *testing.T) {
+	ctx := context.TODO()
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir()})
+	defer r.StopExpire()
+	_ = r.AddGroup("kids")
+	_ = r.AddGroupDeny("kids", "*.games.tld")
+	r.adlist.AddDeny(ctx, "*.ads.tld")
+	kids, _ := r.groups.list("kids")
+
+	if !r.BlockingPausedUntil().IsZero() {
+		t.Errorf("BlockingPausedUntil() = '%v', want zero time",
+			r.BlockingPausedUntil())
+	}
+
+	r.PauseBlocking(time.Minute)
+	if r.BlockingPausedUntil().IsZero() {
+		t.Error("BlockingPausedUntil() = zero time, want end of pause")
+	}
+	if got := r.adlist.Match(ctx, "banner.ads.tld"); adl.ADneutral != got {
+		t.Errorf("TADlist.Match() = '%v', want '%v'", got, adl.ADneutral)
+	}
+	if got := kids.Match(ctx, "play.games.tld"); adl.ADneutral != got {
+		t.Errorf("TADlist.Match() = '%v', want '%v'", got, adl.ADneutral)
+	}
+
+	r.PauseBlocking(0)
+	if got := r.adlist.Match(ctx, "banner.ads.tld"); adl.ADdeny != got {
+		t.Errorf("TADlist.Match() = '%v', want '%v'", got, adl.ADdeny)
+	}
+	if got := kids.Match(ctx, "play.games.tld"); adl.ADdeny != got {
+		t.Errorf("TADlist.Match() = '%v', want '%v'", got, adl.ADdeny)
+	}
+} // Test_TResolver_PauseBlocking()
+
+/* _EoF_ */
@@ -0,0 +1,378 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mwat56/dnscache/internal/sqlite"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `TPiHoleImport` contains the settings read from a Pi-hole
+	// `gravity.db` database (see [ReadPiHole]).
+	//
+	// The maps' keys are the names of the groups, an empty name
+	// meaning the resolver's default allow/deny lists.
+	//
+	//   - `Groups`: The groups to create.
+	//   - `Allow`: The allow patterns per group.
+	//   - `Deny`: The deny patterns per group.
+	//   - `BlockLists`: The URLs of the blocklists per group.
+	//   - `Clients`: The client addresses (or ranges) and their group.
+	//   - `Skipped`: The entries which couldn't be converted.
+	TPiHoleImport struct {
+		Groups     []string
+		Allow      map[string][]string
+		Deny       map[string][]string
+		BlockLists map[string][]string
+		Clients    map[string]string
+		Skipped    []string
+	}
+)
+
+const (
+	// Pi-hole's `domainlist` types
+	piholeExactAllow = 0
+	piholeExactDeny  = 1
+	piholeRegexAllow = 2
+	piholeRegexDeny  = 3
+
+	// `piholeAllowList` is Pi-hole's `adlist` type of allowlists.
+	piholeAllowList = 1
+
+	// `piholeDefaultGroup` is the ID of Pi-hole's default group.
+	piholeDefaultGroup = 0
+)
+
+var (
+	// `piholeGroupRE` matches the characters not allowed in group names.
+	piholeGroupRE = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+	// `piholeWildcardRE` matches the regular expression Pi-hole uses
+	// for a domain including all its subdomains.
+	piholeWildcardRE = regexp.MustCompile(`^\(\\\.\|\^\)((?:[a-zA-Z0-9-]+\\\.)+[a-zA-Z0-9-]+)\$$`)
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `piholeByID()` orders the rows of a Pi-hole table by their ID.
+//
+// Parameters:
+//   - `aRow`: The first row to compare.
+//   - `aOther`: The second row to compare.
+//
+// Returns:
+//   - `int`: The rows' order as used by `slices.SortFunc()`.
+func piholeByID(aRow, aOther sqlite.TRow) int {
+	return cmp.Compare(aRow.Int("id"), aOther.Int("id"))
+} // piholeByID()
+
+// `piholeGroupName()` converts a Pi-hole group's name to a valid
+// group name.
+//
+// Parameters:
+//   - `aName`: The Pi-hole group's name.
+//   - `aID`: The Pi-hole group's ID (used if the name is unusable).
+//
+// Returns:
+//   - `string`: The group's name.
+func piholeGroupName(aName string, aID int64) string {
+	name := strings.Trim(piholeGroupRE.ReplaceAllString(strings.TrimSpace(aName), "_"), "_")
+	if "" == name {
+		name = "group_" + strconv.FormatInt(aID, 10)
+	}
+
+	return name
+} // piholeGroupName()
+
+// `piholePatterns()` converts a Pi-hole `domainlist` entry to
+// hostname patterns.
+//
+// Of the regular expressions only Pi-hole's wildcard form matching
+// a domain with all its subdomains can be converted.
+//
+// Parameters:
+//   - `aType`: The entry's type.
+//   - `aDomain`: The entry's domain or regular expression.
+//
+// Returns:
+//   - `[]string`: The hostname patterns (`nil` if not convertible).
+func piholePatterns(aType int64, aDomain string) []string {
+	aDomain = strings.TrimSpace(aDomain)
+	switch aType {
+	case piholeExactAllow, piholeExactDeny:
+		if "" == aDomain {
+			return nil
+		}
+		return []string{aDomain}
+
+	case piholeRegexAllow, piholeRegexDeny:
+		match := piholeWildcardRE.FindStringSubmatch(aDomain)
+		if nil == match {
+			return nil
+		}
+		domain := strings.ReplaceAll(match[1], `\.`, ".")
+		return []string{domain, "*." + domain}
+	}
+
+	return nil
+} // piholePatterns()
+
+// `piholeMembers()` reads a Pi-hole table assigning items to groups.
+//
+// Parameters:
+//   - `aRows`: The table's rows.
+//   - `aColumn`: The name of the column holding the item's ID.
+//
+// Returns:
+//   - `map[int64][]int64`: The items' group IDs in ascending order.
+func piholeMembers(aRows []sqlite.TRow, aColumn string) map[int64][]int64 {
+	result := make(map[int64][]int64)
+	for _, row := range aRows {
+		id := row.Int(aColumn)
+		result[id] = append(result[id], row.Int("group_id"))
+	}
+	for _, groups := range result {
+		slices.Sort(groups)
+	}
+
+	return result
+} // piholeMembers()
+
+// `ReadPiHole()` reads the settings of a Pi-hole `gravity.db`
+// database, easing the migration from Pi-hole.
+//
+// Pi-hole's default group is mapped to the resolver's default
+// allow/deny lists, its other (enabled) groups to the resolver's
+// groups. Disabled entries are left out.
+//
+// As each client can belong to one group only, a client assigned
+// to several Pi-hole groups gets the first one (by ID) besides the
+// default group. Since Pi-hole applies the default group's entries
+// to the members of other groups as well, the default group's
+// entries are copied to each group having a member of the default
+// group.
+//
+// The allowlists, the regular expressions (except for Pi-hole's
+// wildcard form), and the clients given by MAC address, hostname, or
+// interface can't be converted; they're listed as `Skipped`.
+//
+// Parameters:
+//   - `aFilename`: The path/file name of the `gravity.db` database.
+//
+// Returns:
+//   - `*TPiHoleImport`: The converted settings.
+//   - `error`: `nil` if the database was read, the error otherwise.
+func ReadPiHole(aFilename string) (*TPiHoleImport, error) {
+	db, err := sqlite.Open(aFilename)
+	if nil != err {
+		return nil, fmt.Errorf("pi-hole database %q: %w", aFilename, err)
+	}
+	defer db.Close()
+
+	tables := make(map[string][]sqlite.TRow)
+	for _, name := range []string{"group", "domainlist", "domainlist_by_group",
+		"adlist", "adlist_by_group", "client", "client_by_group"} {
+		if tables[name], err = db.Rows(name); nil != err {
+			return nil, fmt.Errorf("pi-hole database %q: %w", aFilename, err)
+		}
+	}
+
+	result := &TPiHoleImport{
+		Allow:      make(map[string][]string),
+		Deny:       make(map[string][]string),
+		BlockLists: make(map[string][]string),
+		Clients:    make(map[string]string),
+	}
+
+	// The enabled groups, the default group mapped to ""
+	groups := make(map[int64]string)
+	slices.SortFunc(tables["group"], piholeByID)
+	for _, row := range tables["group"] {
+		id := row.Int("id")
+		if piholeDefaultGroup == id {
+			groups[id] = ""
+			continue
+		}
+		if 0 == row.Int("enabled") {
+			continue
+		}
+		name := piholeGroupName(row.Text("name"), id)
+		if slices.Contains(result.Groups, name) {
+			name += "_" + strconv.FormatInt(id, 10)
+		}
+		groups[id] = name
+		result.Groups = append(result.Groups, name)
+	}
+
+	// The clients' groups and the groups inheriting the default group
+	inherit := make(map[string]bool)
+	clientGroups := piholeMembers(tables["client_by_group"], "client_id")
+	slices.SortFunc(tables["client"], piholeByID)
+	for _, row := range tables["client"] {
+		var (
+			group     string
+			isDefault bool
+		)
+		for _, id := range clientGroups[row.Int("id")] {
+			name, ok := groups[id]
+			if !ok {
+				continue // disabled group
+			}
+			if piholeDefaultGroup == id {
+				isDefault = true
+			} else if "" == group {
+				group = name
+			}
+		}
+		if "" == group {
+			continue // uses the default lists
+		}
+		client := strings.TrimSpace(row.Text("ip"))
+		if _, err := parseClientCIDR(client); nil != err {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("client %q", client))
+			continue
+		}
+		result.Clients[client] = group
+		if isDefault {
+			inherit[group] = true
+		}
+	}
+
+	// `targets()` returns the names of the given groups' lists
+	targets := func(aGroups []int64) (rNames []string) {
+		for _, id := range aGroups {
+			name, ok := groups[id]
+			if !ok {
+				continue
+			}
+			rNames = append(rNames, name)
+			if piholeDefaultGroup == id {
+				for _, group := range result.Groups {
+					if inherit[group] {
+						rNames = append(rNames, group)
+					}
+				}
+			}
+		}
+		slices.Sort(rNames)
+		return slices.Compact(rNames)
+	}
+
+	domainGroups := piholeMembers(tables["domainlist_by_group"], "domainlist_id")
+	slices.SortFunc(tables["domainlist"], piholeByID)
+	for _, row := range tables["domainlist"] {
+		if 0 == row.Int("enabled") {
+			continue
+		}
+		kind, domain := row.Int("type"), row.Text("domain")
+		patterns := piholePatterns(kind, domain)
+		if nil == patterns {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("domain %q", domain))
+			continue
+		}
+		lists := result.Deny
+		if (piholeExactAllow == kind) || (piholeRegexAllow == kind) {
+			lists = result.Allow
+		}
+		for _, name := range targets(domainGroups[row.Int("id")]) {
+			lists[name] = append(lists[name], patterns...)
+		}
+	}
+
+	adlistGroups := piholeMembers(tables["adlist_by_group"], "adlist_id")
+	slices.SortFunc(tables["adlist"], piholeByID)
+	for _, row := range tables["adlist"] {
+		if 0 == row.Int("enabled") {
+			continue
+		}
+		address := strings.TrimSpace(row.Text("address"))
+		if piholeAllowList == row.Int("type") {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("allowlist %q", address))
+			continue
+		}
+		for _, name := range targets(adlistGroups[row.Int("id")]) {
+			result.BlockLists[name] = append(result.BlockLists[name], address)
+		}
+	}
+
+	return result, nil
+} // ReadPiHole()
+
+// ---------------------------------------------------------------------------
+// `TResolver` methods:
+
+// `ImportPiHole()` applies the settings read from a Pi-hole database
+// (see [ReadPiHole]): it creates the groups, inserts the allow/deny
+// patterns, and assigns the clients to their groups.
+//
+// The blocklists aren't loaded; since loading blocklists replaces
+// a deny list, they have to be loaded (by [LoadBlocklists] and
+// [LoadGroupBlocklists]) before importing the deny patterns.
+//
+// Parameters:
+//   - `aImport`: The settings to apply.
+//
+// Returns:
+//   - `error`: `nil` if all settings were applied, the joined errors otherwise.
+func (r *TResolver) ImportPiHole(aImport *TPiHoleImport) error {
+	if nil == aImport {
+		return nil
+	}
+	var errs []error
+
+	for _, group := range aImport.Groups {
+		if err := r.AddGroup(group); nil != err {
+			errs = append(errs, err)
+		}
+	}
+	for group, patterns := range aImport.Allow {
+		for _, pattern := range patterns {
+			var err error
+			if "" == group {
+				err = r.AddAllow(pattern)
+			} else {
+				err = r.AddGroupAllow(group, pattern)
+			}
+			if nil != err {
+				errs = append(errs, err)
+			}
+		}
+	}
+	for group, patterns := range aImport.Deny {
+		for _, pattern := range patterns {
+			var err error
+			if "" == group {
+				err = r.AddDeny(pattern)
+			} else {
+				err = r.AddGroupDeny(group, pattern)
+			}
+			if nil != err {
+				errs = append(errs, err)
+			}
+		}
+	}
+	for client, group := range aImport.Clients {
+		if err := r.AssignClient(client, group); nil != err {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+} // ImportPiHole()
+
+/* _EoF_ */
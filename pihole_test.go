@@ -0,0 +1,155 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_piholeGroupName(t *testing.T) {
+	tests := []struct {
+		name  string
+		group string
+		id    int64
+		want  string
+	}{
+		/* */
+		{"01 - valid name", "kids", 1, "kids"},
+		{"02 - spaces", " Kids Devices ", 2, "Kids_Devices"},
+		{"03 - special characters", "Büro (2. OG)", 3, "B_ro_2_OG"},
+		{"04 - unusable", "äöü", 4, "group_4"},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := piholeGroupName(tc.group, tc.id); got != tc.want {
+				t.Errorf("piholeGroupName() = '%s', want '%s'", got, tc.want)
+			}
+		})
+	}
+} // Test_piholeGroupName()
+
+func Test_piholePatterns(t *testing.T) {
+	tests := []struct {
+		name   string
+		kind   int64
+		domain string
+		want   []string
+	}{
+		/* */
+		{"01 - exact deny", piholeExactDeny, "ads.example.com", []string{"ads.example.com"}},
+		{"02 - exact allow", piholeExactAllow, " cdn.example.com ", []string{"cdn.example.com"}},
+		{"03 - empty", piholeExactDeny, "", nil},
+		{"04 - wildcard regex", piholeRegexDeny, `(\.|^)example\.com$`,
+			[]string{"example.com", "*.example.com"}},
+		{"05 - other regex", piholeRegexAllow, `^ad[0-9]+\.`, nil},
+		{"06 - unknown type", 9, "example.com", nil},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := piholePatterns(tc.kind, tc.domain); !slices.Equal(got, tc.want) {
+				t.Errorf("piholePatterns() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_piholePatterns()
+
+func Test_ReadPiHole(t *testing.T) {
+	if _, err := ReadPiHole(filepath.Join("testdata", "gravity.sql")); nil == err {
+		t.Error("ReadPiHole() error = 'nil', want an error")
+	}
+	got, err := ReadPiHole(filepath.Join("testdata", "gravity.db"))
+	if nil != err {
+		t.Fatalf("ReadPiHole() error = '%v'", err)
+	}
+
+	if want := []string{"Kids_Devices"}; !slices.Equal(got.Groups, want) {
+		t.Errorf("Groups = '%v', want '%v'", got.Groups, want)
+	}
+	wantClients := map[string]string{
+		"192.168.1.10": "Kids_Devices",
+		"10.0.0.0/24":  "Kids_Devices",
+	}
+	if len(got.Clients) != len(wantClients) {
+		t.Errorf("Clients = '%v', want '%v'", got.Clients, wantClients)
+	}
+	for client, group := range wantClients {
+		if got.Clients[client] != group {
+			t.Errorf("Clients[%s] = '%s', want '%s'", client, got.Clients[client], group)
+		}
+	}
+
+	// The Kids group inherits the default group's entries
+	checks := []struct {
+		what string
+		got  []string
+		want []string
+	}{
+		{"Allow[]", got.Allow[""], []string{"cdn.ads.example.com"}},
+		{"Deny[]", got.Deny[""], []string{"ads.example.com"}},
+		{"Allow[Kids]", got.Allow["Kids_Devices"], []string{"cdn.ads.example.com"}},
+		{"Deny[Kids]", got.Deny["Kids_Devices"], []string{"ads.example.com",
+			"tracker.example.net", "*.tracker.example.net", "games.example.org"}},
+		{"BlockLists[]", got.BlockLists[""], []string{"https://lists.example.com/hosts.txt"}},
+		{"BlockLists[Kids]", got.BlockLists["Kids_Devices"], []string{
+			"https://lists.example.com/hosts.txt", "https://lists.example.com/kids.txt"}},
+		{"Skipped", got.Skipped, []string{`client "00:11:22:33:44:55"`, `client ":eth0"`,
+			`domain "^ad[0-9]+\\."`, `allowlist "https://lists.example.com/allow.txt"`}},
+	}
+	for _, check := range checks {
+		if !slices.Equal(check.got, check.want) {
+			t.Errorf("%s = '%v', want '%v'", check.what, check.got, check.want)
+		}
+	}
+} // Test_ReadPiHole()
+
+func Test_TResolver_ImportPiHole(t *testing.T) {
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir()})
+	defer r.StopExpire()
+
+	if err := r.ImportPiHole(nil); nil != err {
+		t.Errorf("ImportPiHole(nil) error = '%v'", err)
+	}
+	data, err := ReadPiHole(filepath.Join("testdata", "gravity.db"))
+	if nil != err {
+		t.Fatalf("ReadPiHole() error = '%v'", err)
+	}
+	if err := r.ImportPiHole(data); nil != err {
+		t.Fatalf("ImportPiHole() error = '%v'", err)
+	}
+
+	if got := r.Groups(); !slices.Equal(got, []string{"Kids_Devices"}) {
+		t.Errorf("Groups() = '%v', want '[Kids_Devices]'", got)
+	}
+	if got := r.ClientGroup(net.ParseIP("10.0.0.7")); "Kids_Devices" != got {
+		t.Errorf("ClientGroup() = '%s', want 'Kids_Devices'", got)
+	}
+	if got := r.ClientGroup(net.ParseIP("192.168.1.20")); "" != got {
+		t.Errorf("ClientGroup() = '%s', want ''", got)
+	}
+	if got := r.DenyPatterns(); !slices.Contains(got, "ads.example.com") {
+		t.Errorf("DenyPatterns() = '%v', want 'ads.example.com'", got)
+	}
+	if got := r.AllowPatterns(); !slices.Contains(got, "cdn.ads.example.com") {
+		t.Errorf("AllowPatterns() = '%v', want 'cdn.ads.example.com'", got)
+	}
+	list, _ := r.groups.list("Kids_Devices")
+	if got := list.DenyPatterns(context.Background()); !slices.Contains(got, "*.tracker.example.net") {
+		t.Errorf("group DenyPatterns() = '%v', want '*.tracker.example.net'", got)
+	}
+} // Test_TResolver_ImportPiHole()
+
+/* _EoF_ */
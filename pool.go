@@ -0,0 +1,48 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"github.com/mwat56/dnscache/cache"
+	adl "github.com/mwat56/dnscache/internal/adlist"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `TPoolOptions` contains the settings of the node pools used by
+	// the cache and the allow/deny lists (see [TResolverOptions]).
+	//
+	//   - `InitSize`: Number of nodes to pre-allocate (default: 512).
+	//   - `MaxSize`: Maximum number of retained nodes (default: four
+	//     times `InitSize`).
+	//   - `Disabled`: Whether to bypass the pool, i.e. always create
+	//     new nodes.
+	TPoolOptions = cache.TPoolOptions
+
+	// `TPoolMetrics` contains the metrics data of a node pool as
+	// returned by [TResolver.PoolMetrics].
+	TPoolMetrics = cache.TPoolMetrics
+)
+
+// ---------------------------------------------------------------------------
+// `TResolver` methods:
+
+// `PoolMetrics()` returns the current metrics of the node pools.
+//
+// The pools are shared by all resolvers. Their `HitRate()` helps to
+// size them (see [TResolverOptions]): a low rate asks for a larger
+// pool while a high rate with many unused nodes allows a smaller one.
+//
+// Returns:
+//   - `rAdlist`: The metrics of the allow/deny lists' node pool.
+//   - `rCache`: The metrics of the cache's node pool.
+func (r *TResolver) PoolMetrics() (rAdlist, rCache *TPoolMetrics) {
+	return adl.PoolMetrics(), cache.PoolMetrics()
+} // PoolMetrics()
+
+/* _EoF_ */
@@ -0,0 +1,46 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"testing"
+
+	"github.com/mwat56/dnscache/cache"
+	adl "github.com/mwat56/dnscache/internal/adlist"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_TResolver_PoolMetrics(t *testing.T) {
+	defer func() { // restore the default pools
+		adl.SetPoolOptions(TPoolOptions{})
+		cache.SetPoolOptions(TPoolOptions{})
+	}()
+
+	r := NewWithOptions(TResolverOptions{
+		DataDir:  t.TempDir(),
+		NodePool: &TPoolOptions{InitSize: 4, MaxSize: 4},
+	})
+	defer r.StopExpire()
+
+	adlist, cacheMetrics := r.PoolMetrics()
+	for name, m := range map[string]*TPoolMetrics{"adlist": adlist, "cache": cacheMetrics} {
+		if nil == m {
+			t.Errorf("PoolMetrics() %s = 'nil', want non-nil", name)
+			continue
+		}
+		// The new resolver's tries took their root nodes from the pools
+		if (0 == m.Hits) || (3 < m.Size) {
+			t.Errorf("PoolMetrics() %s = '%+v', want a small, used pool", name, *m)
+		}
+		if rate := m.HitRate(); (0 >= rate) || (1 < rate) {
+			t.Errorf("HitRate() %s = '%v', want (0, 1]", name, rate)
+		}
+	}
+} // Test_TResolver_PoolMetrics()
+
+/* _EoF_ */
@@ -0,0 +1,188 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `TTypeMetrics` contains the cache hits and misses of a query type.
+	TTypeMetrics struct {
+		Hits   uint32
+		Misses uint32
+	}
+
+	// `tTypeMetrics` counts the cache hits and misses per query type.
+	tTypeMetrics struct {
+		sync.RWMutex
+		counts map[uint16]*TTypeMetrics
+	}
+)
+
+// ---------------------------------------------------------------------------
+// `tTypeMetrics` methods:
+
+// `count()` adds a lookup of the given query type.
+//
+// Lookups without a query type (i.e. `0`) aren't counted.
+//
+// Parameters:
+//   - `aQType`: The lookup's query type.
+//   - `aHit`: `true` for a cache hit, `false` for a miss.
+func (tm *tTypeMetrics) count(aQType uint16, aHit bool) {
+	if (nil == tm) || (0 == aQType) {
+		return
+	}
+
+	tm.RLock()
+	m, ok := tm.counts[aQType]
+	tm.RUnlock()
+	if !ok {
+		tm.Lock()
+		if m, ok = tm.counts[aQType]; !ok {
+			m = &TTypeMetrics{}
+			tm.counts[aQType] = m
+		}
+		tm.Unlock()
+	}
+
+	if aHit {
+		incMetricsFields(&m.Hits)
+	} else {
+		incMetricsFields(&m.Misses)
+	}
+} // count()
+
+// `snapshot()` returns the current counts of all query types.
+//
+// Returns:
+//   - `map[uint16]TTypeMetrics`: The hits and misses per query type.
+func (tm *tTypeMetrics) snapshot() map[uint16]TTypeMetrics {
+	result := make(map[uint16]TTypeMetrics)
+	if nil == tm {
+		return result
+	}
+	tm.RLock()
+	defer tm.RUnlock()
+
+	for qType, m := range tm.counts {
+		result[qType] = TTypeMetrics{
+			Hits:   atomic.LoadUint32(&m.Hits),
+			Misses: atomic.LoadUint32(&m.Misses),
+		}
+	}
+
+	return result
+} // snapshot()
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `newTypeMetrics()` returns new, empty per-type metrics.
+//
+// Returns:
+//   - `*tTypeMetrics`: The new metrics.
+func newTypeMetrics() *tTypeMetrics {
+	return &tTypeMetrics{
+		counts: make(map[uint16]*TTypeMetrics),
+	}
+} // newTypeMetrics()
+
+// `matchesType()` checks whether an IP address answers the given
+// query type.
+//
+// Parameters:
+//   - `aIP`: The IP address to check.
+//   - `aQType`: The query type (A or AAAA).
+//
+// Returns:
+//   - `bool`: `true` if the address belongs to the query type.
+func matchesType(aIP net.IP, aQType uint16) bool {
+	switch aQType {
+	case dnsTypeA:
+		return nil != aIP.To4()
+	case dnsTypeAAAA:
+		return (nil == aIP.To4()) && (nil != aIP.To16())
+	}
+
+	return false
+} // matchesType()
+
+// ---------------------------------------------------------------------------
+// `TResolver` methods:
+
+// `FetchType()` returns the IP addresses for a given hostname as
+// requested by the given client for the given query type.
+//
+// It works like [FetchFor] but additionally counts the cache hit or
+// miss for the query type (see [TypeMetrics]).
+//
+// Parameters:
+//   - `aClient`: The requesting client's IP address (may be `nil`).
+//   - `aHostname`: The hostname to resolve.
+//   - `aQType`: The DNS query type (`0` means not to count it).
+//
+// Returns:
+//   - `[]net.IP`: List of IP addresses for the given hostname.
+//   - `error`: `nil` if the hostname was resolved successfully, the error otherwise.
+func (r *TResolver) FetchType(aClient net.IP, aHostname string, aQType uint16) ([]net.IP, error) {
+	list, safe := r.clientPolicy(aClient)
+
+	return r.fetch(list, safe, aClient, aHostname, aQType)
+} // FetchType()
+
+// `FlushType()` removes the cache entries holding addresses of the
+// given query type.
+//
+// The cache keeps the IPv4 and IPv6 addresses of a hostname in one
+// entry, hence flushing `A` (1) or `AAAA` (28) removes the whole
+// entry which gets resolved again with the next query. Other record
+// types are passed on to the forwarder and never cached, hence
+// nothing is removed for them.
+//
+// Parameters:
+//   - `aQType`: The DNS query type to flush.
+//
+// Returns:
+//   - `int`: The number of removed cache entries.
+func (r *TResolver) FlushType(aQType uint16) int {
+	if (dnsTypeA != aQType) && (dnsTypeAAAA != aQType) {
+		return 0
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), defLookupTimeout)
+	defer cancel()
+
+	count := r.deleteCached(func(aHostname string) bool {
+		ips, _ := r.ICacheList.IPs(ctx, aHostname)
+		for _, ip := range ips {
+			if matchesType(ip, aQType) {
+				return true
+			}
+		}
+		return false
+	})
+	gLog.Info("cache flushed", "qtype", aQType, "entries", count)
+
+	return count
+} // FlushType()
+
+// `TypeMetrics()` returns the cache hits and misses per query type
+// counted by [FetchType].
+//
+// Returns:
+//   - `map[uint16]TTypeMetrics`: The metrics per query type.
+func (r *TResolver) TypeMetrics() map[uint16]TTypeMetrics {
+	return r.types.snapshot()
+} // TypeMetrics()
+
+/* _EoF_ */
@@ -0,0 +1,115 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_matchesType(t *testing.T) {
+	tests := []struct {
+		name  string
+		ip    net.IP
+		qType uint16
+		want  bool
+	}{
+		/* */
+		{"01 - IPv4 for A", net.ParseIP("192.0.2.1"), dnsTypeA, true},
+		{"02 - IPv4 for AAAA", net.ParseIP("192.0.2.1"), dnsTypeAAAA, false},
+		{"03 - IPv6 for A", net.ParseIP("2001:db8::1"), dnsTypeA, false},
+		{"04 - IPv6 for AAAA", net.ParseIP("2001:db8::1"), dnsTypeAAAA, true},
+		{"05 - IPv4 for TXT", net.ParseIP("192.0.2.1"), 16, false},
+		{"06 - nil", nil, dnsTypeAAAA, false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := matchesType(tc.ip, tc.qType); got != tc.want {
+				t.Errorf("matchesType() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_matchesType()
+
+func Test_TResolver_FetchType(t *testing.T) {
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir()})
+	defer r.StopExpire()
+	r.dnsServers = nil // resolve `localhost` locally
+	r.Create(context.Background(), "www.example.com",
+		[]net.IP{net.ParseIP("192.0.2.1")}, time.Hour)
+
+	tests := []struct {
+		name     string
+		hostname string
+		qType    uint16
+	}{
+		/* */
+		{"01 - miss", "localhost", dnsTypeAAAA},
+		{"02 - hit", "www.example.com", dnsTypeA},
+		{"03 - hit", "localhost", dnsTypeAAAA},
+		{"04 - uncounted", "www.example.com", 0},
+		/* */
+	}
+	for _, tc := range tests {
+		if _, err := r.FetchType(nil, tc.hostname, tc.qType); nil != err {
+			t.Fatalf("%s: FetchType() error = '%v'", tc.name, err)
+		}
+	}
+
+	want := map[uint16]TTypeMetrics{
+		dnsTypeA:    {Hits: 1},
+		dnsTypeAAAA: {Hits: 1, Misses: 1},
+	}
+	got := r.TypeMetrics()
+	if len(got) != len(want) {
+		t.Fatalf("TypeMetrics() = '%v', want '%v'", got, want)
+	}
+	for qType, tm := range want {
+		if got[qType] != tm {
+			t.Errorf("TypeMetrics()[%d] = '%v', want '%v'", qType, got[qType], tm)
+		}
+	}
+} // Test_TResolver_FetchType()
+
+func Test_TResolver_FlushType(t *testing.T) {
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir()})
+	defer r.StopExpire()
+	ctx := context.Background()
+	r.Create(ctx, "v4.example.com", []net.IP{net.ParseIP("192.0.2.1")}, time.Hour)
+	r.Create(ctx, "v6.example.com", []net.IP{net.ParseIP("2001:db8::1")}, time.Hour)
+	r.Create(ctx, "dual.example.com", []net.IP{
+		net.ParseIP("192.0.2.2"), net.ParseIP("2001:db8::2")}, time.Hour)
+
+	tests := []struct {
+		name  string
+		qType uint16
+		want  int
+	}{
+		/* */
+		{"01 - TXT", 16, 0},
+		{"02 - A", dnsTypeA, 2},
+		{"03 - A again", dnsTypeA, 0},
+		{"04 - AAAA", dnsTypeAAAA, 1},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := r.FlushType(tc.qType); got != tc.want {
+				t.Errorf("FlushType() = '%d', want '%d'", got, tc.want)
+			}
+		})
+	}
+} // Test_TResolver_FlushType()
+
+/* _EoF_ */
@@ -0,0 +1,175 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"fmt"
+	"net"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `TRebindPolicy` determines how upstream answers resolving to
+	// private or reserved IP addresses are handled (DNS rebinding
+	// protection).
+	TRebindPolicy uint8
+)
+
+const (
+	// `RebindPolicyOff` accepts all upstream answers.
+	RebindPolicyOff = TRebindPolicy(iota)
+
+	// `RebindPolicyStrip` removes all private addresses from an
+	// answer. If no address remains, the lookup fails with a
+	// `TBlockedError` (i.e. NXDOMAIN).
+	RebindPolicyStrip
+
+	// `RebindPolicyBlock` fails the lookup with a `TBlockedError`
+	// (i.e. NXDOMAIN) if the answer contains any private address.
+	RebindPolicyBlock
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `isRebindIP()` checks whether the given address must not be
+// returned for a public hostname.
+//
+// Those are the private (RFC 1918 and RFC 4193), loopback, link-local,
+// and unspecified addresses.
+//
+// Parameters:
+//   - `aIP`: The address to check.
+//
+// Returns:
+//   - `bool`: `true` if the address is private or reserved, `false` otherwise.
+func isRebindIP(aIP net.IP) bool {
+	return aIP.IsPrivate() || aIP.IsLoopback() || aIP.IsUnspecified() ||
+		aIP.IsLinkLocalUnicast() || aIP.IsLinkLocalMulticast()
+} // isRebindIP()
+
+// ---------------------------------------------------------------------------
+// `TResolver` methods:
+
+// `DeleteRebindExempt()` removes a pattern from the rebind exemptions.
+//
+// Parameters:
+//   - `aPattern`: The pattern to remove.
+//
+// Returns:
+//   - `bool`: `true` if the pattern was removed, `false` otherwise.
+func (r *TResolver) DeleteRebindExempt(aPattern string) bool {
+	return r.rebindExempt.delete(aPattern)
+} // DeleteRebindExempt()
+
+// `filterRebindIPs()` applies the resolver's rebind policy to the
+// given list of IP addresses.
+//
+// Parameters:
+//   - `aHostname`: The hostname the addresses belong to.
+//   - `aIPs`: The IP addresses returned by an upstream server.
+//
+// Returns:
+//   - `[]net.IP`: The (possibly) filtered list of IP addresses.
+//   - `error`: A `TBlockedError` if no address may be returned, `nil` otherwise.
+func (r *TResolver) filterRebindIPs(aHostname string, aIPs []net.IP) ([]net.IP, error) {
+	r.RLock()
+	policy := r.rebindPolicy
+	r.RUnlock()
+
+	if RebindPolicyOff == policy {
+		return aIPs, nil
+	}
+	if r.mdns && isMDNSName(aHostname) {
+		return aIPs, nil // LAN addresses by definition
+	}
+	if r.rebindExempt.match(aHostname) {
+		return aIPs, nil
+	}
+
+	var (
+		blocked net.IP
+		result  []net.IP
+	)
+	for idx, ip := range aIPs {
+		if !isRebindIP(ip) {
+			if nil != result {
+				result = append(result, ip)
+			}
+			continue
+		}
+		if RebindPolicyBlock == policy {
+			return nil, &TBlockedError{Hostname: aHostname, IP: ip}
+		}
+
+		// RebindPolicyStrip: copy the public addresses found so far
+		if nil == result {
+			blocked = ip
+			result = make([]net.IP, idx, len(aIPs))
+			copy(result, aIPs[:idx])
+		}
+	}
+
+	if nil == result {
+		return aIPs, nil // no private address found
+	}
+	if 0 == len(result) {
+		return nil, &TBlockedError{Hostname: aHostname, IP: blocked}
+	}
+
+	return result, nil
+} // filterRebindIPs()
+
+// `RebindExempt()` adds a hostname pattern to the rebind exemptions.
+//
+// Answers for matching hostnames may contain private addresses, e.g.
+// for split-horizon setups where a public domain resolves to hosts
+// of the local network. The pattern uses the same syntax as
+// [NeverCache] (e.g. `*.home.example.com`).
+//
+// Parameters:
+//   - `aPattern`: The hostname or wildcard pattern to exempt.
+//
+// Returns:
+//   - `error`: `nil` if the pattern was added, the error otherwise.
+func (r *TResolver) RebindExempt(aPattern string) error {
+	if nil == r.rebindExempt {
+		return fmt.Errorf("invalid pattern: %q", aPattern)
+	}
+
+	return r.rebindExempt.add(aPattern)
+} // RebindExempt()
+
+// `RebindExemptions()` returns the patterns of the rebind exemptions.
+//
+// Returns:
+//   - `[]string`: The sorted patterns.
+func (r *TResolver) RebindExemptions() []string {
+	return r.rebindExempt.list()
+} // RebindExemptions()
+
+// `SetRebindPolicy()` sets how upstream answers resolving to private
+// or reserved IP addresses are handled.
+//
+// Addresses of DHCP leases, rewrite rules, and mDNS answers are never
+// affected since they don't come from the upstream servers.
+//
+// Parameters:
+//   - `aPolicy`: The rebind policy to use.
+//
+// Returns:
+//   - `*TResolver`: The current resolver.
+func (r *TResolver) SetRebindPolicy(aPolicy TRebindPolicy) *TResolver {
+	r.Lock()
+	r.rebindPolicy = aPolicy
+	r.Unlock()
+
+	return r
+} // SetRebindPolicy()
+
+/* _EoF_ */
@@ -0,0 +1,159 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"errors"
+	"net"
+	"slices"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_isRebindIP(t *testing.T) {
+	tests := []struct {
+		name string
+		ip   string
+		want bool
+	}{
+		/* */
+		{"01 - public IPv4", "198.51.100.1", false},
+		{"02 - RFC 1918", "192.168.1.1", true},
+		{"03 - RFC 1918 class A", "10.1.2.3", true},
+		{"04 - loopback", "127.0.0.1", true},
+		{"05 - link-local", "169.254.1.1", true},
+		{"06 - unspecified", "0.0.0.0", true},
+		{"07 - public IPv6", "2001:db8::1", false},
+		{"08 - ULA", "fd00::1", true},
+		{"09 - IPv6 loopback", "::1", true},
+		{"10 - IPv6 link-local", "fe80::1", true},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := isRebindIP(net.ParseIP(tc.ip)); got != tc.want {
+				t.Errorf("isRebindIP(%s) = '%v', want '%v'", tc.ip, got, tc.want)
+			}
+		})
+	}
+} // Test_isRebindIP()
+
+func Test_TResolver_filterRebindIPs(t *testing.T) {
+	newResolver := func(aPolicy TRebindPolicy) *TResolver {
+		r := &TResolver{rebindExempt: &tHostPatterns{}}
+		_ = r.RebindExempt("*.home.example.com")
+		return r.SetRebindPolicy(aPolicy)
+	}
+	public := net.ParseIP("198.51.100.1")
+	private := net.ParseIP("192.168.1.1")
+
+	tests := []struct {
+		name     string
+		resolver *TResolver
+		hostname string
+		ips      []net.IP
+		want     []net.IP
+		wantErr  bool
+	}{
+		/* */
+		{
+			name:     "01 - off",
+			resolver: newResolver(RebindPolicyOff),
+			hostname: "host.tld",
+			ips:      []net.IP{private},
+			want:     []net.IP{private},
+		},
+		{
+			name:     "02 - public only",
+			resolver: newResolver(RebindPolicyBlock),
+			hostname: "host.tld",
+			ips:      []net.IP{public},
+			want:     []net.IP{public},
+		},
+		{
+			name:     "03 - strip private",
+			resolver: newResolver(RebindPolicyStrip),
+			hostname: "host.tld",
+			ips:      []net.IP{private, public},
+			want:     []net.IP{public},
+		},
+		{
+			name:     "04 - strip all",
+			resolver: newResolver(RebindPolicyStrip),
+			hostname: "host.tld",
+			ips:      []net.IP{private},
+			wantErr:  true,
+		},
+		{
+			name:     "05 - block",
+			resolver: newResolver(RebindPolicyBlock),
+			hostname: "host.tld",
+			ips:      []net.IP{public, private},
+			wantErr:  true,
+		},
+		{
+			name:     "06 - exempt",
+			resolver: newResolver(RebindPolicyBlock),
+			hostname: "nas.home.example.com",
+			ips:      []net.IP{private},
+			want:     []net.IP{private},
+		},
+		{
+			name:     "07 - mDNS",
+			resolver: &TResolver{rebindPolicy: RebindPolicyBlock, mdns: true},
+			hostname: "printer.local",
+			ips:      []net.IP{private},
+			want:     []net.IP{private},
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.resolver.filterRebindIPs(tc.hostname, tc.ips)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("filterRebindIPs() error = '%v', wantErr '%v'",
+					err, tc.wantErr)
+				return
+			}
+			if tc.wantErr && !errors.Is(err, ErrBlockedIP) {
+				t.Errorf("filterRebindIPs() error = '%v', want '%v'",
+					err, ErrBlockedIP)
+			}
+			if !slices.EqualFunc(got, tc.want, net.IP.Equal) {
+				t.Errorf("filterRebindIPs() = '%v', want '%v'",
+					got, tc.want)
+			}
+		})
+	}
+} // Test_TResolver_filterRebindIPs()
+
+func Test_TResolver_RebindExempt(t *testing.T) {
+	r := NewWithOptions(TResolverOptions{
+		DataDir:      t.TempDir(),
+		RebindExempt: []string{"*.home.example.com", "intranet.example.org."},
+	})
+	defer r.StopExpire()
+
+	want := []string{"*.home.example.com", "intranet.example.org"}
+	if got := r.RebindExemptions(); !slices.Equal(got, want) {
+		t.Errorf("RebindExemptions() = '%v', want '%v'", got, want)
+	}
+	if err := r.RebindExempt(""); nil == err {
+		t.Error("RebindExempt() error = 'nil', want non-nil")
+	}
+	if !r.DeleteRebindExempt("Intranet.Example.org") {
+		t.Error("DeleteRebindExempt() = 'false', want 'true'")
+	}
+	if r.DeleteRebindExempt("intranet.example.org") {
+		t.Error("DeleteRebindExempt() = 'true', want 'false'")
+	}
+} // Test_TResolver_RebindExempt()
+
+/* _EoF_ */
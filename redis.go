@@ -0,0 +1,476 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `TRedisOptions` contains the settings of a [TRedisSync].
+	//
+	//   - `Address`: The Redis server's address (default: `localhost:6379`).
+	//   - `Password`: The password to authenticate with (empty: none).
+	//   - `Channel`: The publish/subscribe channel (default: `dnscache`).
+	//   - `Key`: The shared secret authenticating the events (recommended).
+	TRedisOptions struct {
+		Address  string
+		Password string
+		Channel  string
+		Key      string
+	}
+
+	// `TRedisSync` propagates cache changes via a Redis server's
+	// publish/subscribe channel (see [ICacheSync]).
+	//
+	// Lost connections are re-established automatically; the events
+	// published in the meantime are lost. If a key is configured,
+	// each event is signed with it, since anybody allowed to publish
+	// to the channel could send events otherwise.
+	TRedisSync struct {
+		options  TRedisOptions
+		key      []byte
+		events   chan TSyncEvent
+		done     chan struct{} // closed by `Close()`
+		stopped  chan struct{} // closed when the subscriber ended
+		pubMtx   sync.Mutex
+		pub      *tRedisConn // connection to publish with
+		subMtx   sync.Mutex
+		sub      *tRedisConn   // connection subscribed to the channel
+		rejected atomic.Uint64 // number of invalid messages received
+		once     sync.Once
+	}
+
+	// `tRedisConn` is a connection to a Redis server.
+	tRedisConn struct {
+		net.Conn
+		reader *bufio.Reader
+	}
+
+	// `tRedisError` is an error reply of a Redis server.
+	tRedisError string
+)
+
+const (
+	// `redisDefAddress` is the default address of the Redis server.
+	redisDefAddress = "localhost:6379"
+
+	// `redisDefChannel` is the default publish/subscribe channel.
+	redisDefChannel = "dnscache"
+
+	// `redisTimeout` is the max. time to connect and to send a command.
+	redisTimeout = 5 * time.Second
+
+	// `redisMaxRetryDelay` is the max. delay between two reconnects.
+	redisMaxRetryDelay = 30 * time.Second
+
+	// `redisMaxBulk` is the max. size of a reply's string.
+	redisMaxBulk = 1 << 20
+
+	// `redisMaxDepth` is the max. nesting of a reply's arrays.
+	redisMaxDepth = 8
+
+	// `redisMaxElements` is the max. total number of array elements
+	// of a reply.
+	redisMaxElements = 1 << 16
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `dialRedis()` connects to a Redis server.
+//
+// Parameters:
+//   - `aOptions`: The server's settings.
+//
+// Returns:
+//   - `*tRedisConn`: The new connection.
+//   - `error`: `nil` if the connection was established, the error otherwise.
+func dialRedis(aOptions TRedisOptions) (*tRedisConn, error) {
+	conn, err := net.DialTimeout("tcp", aOptions.Address, redisTimeout)
+	if nil != err {
+		return nil, err
+	}
+	result := &tRedisConn{Conn: conn, reader: bufio.NewReader(conn)}
+
+	if "" != aOptions.Password {
+		if _, err := result.call("AUTH", aOptions.Password); nil != err {
+			_ = conn.Close()
+			return nil, fmt.Errorf("redis authentication failed: %w", err)
+		}
+	}
+
+	return result, nil
+} // dialRedis()
+
+// ---------------------------------------------------------------------------
+// `tRedisError` methods:
+
+// `Error()` implements the `error` interface.
+//
+// Returns:
+//   - `string`: The server's error message.
+func (re tRedisError) Error() string {
+	return "redis: " + string(re)
+} // Error()
+
+// ---------------------------------------------------------------------------
+// `tRedisConn` methods:
+
+// `call()` sends a command and reads its reply.
+//
+// Parameters:
+//   - `aArgs`: The command and its arguments.
+//
+// Returns:
+//   - `any`: The server's reply.
+//   - `error`: `nil` if the command succeeded, the error otherwise.
+func (rc *tRedisConn) call(aArgs ...string) (any, error) {
+	if err := rc.send(aArgs...); nil != err {
+		return nil, err
+	}
+	if err := rc.SetReadDeadline(time.Now().Add(redisTimeout)); nil != err {
+		return nil, err
+	}
+	defer rc.SetReadDeadline(time.Time{})
+
+	return rc.read()
+} // call()
+
+// `read()` reads a reply of the server.
+//
+// Returns:
+//   - `any`: The reply (`string`, `int64`, `[]any` or `nil`).
+//   - `error`: `nil` if a reply was read, the error otherwise.
+func (rc *tRedisConn) read() (any, error) {
+	budget := redisMaxElements
+
+	return rc.readValue(0, &budget)
+} // read()
+
+// `readValue()` reads a (nested) value of a reply.
+//
+// Parameters:
+//   - `aDepth`: The nesting level of the value.
+//   - `aBudget`: The number of array elements the reply may still have.
+//
+// Returns:
+//   - `any`: The value (`string`, `int64`, `[]any` or `nil`).
+//   - `error`: `nil` if a value was read, the error otherwise.
+func (rc *tRedisConn) readValue(aDepth int, aBudget *int) (any, error) {
+	line, err := rc.reader.ReadString('\n')
+	if nil != err {
+		return nil, err
+	}
+	if (3 > len(line)) || ('\r' != line[len(line)-2]) {
+		return nil, fmt.Errorf("invalid redis reply: %q", line)
+	}
+	kind, line := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return line, nil
+
+	case '-':
+		return nil, tRedisError(line)
+
+	case ':':
+		return strconv.ParseInt(line, 10, 64)
+
+	case '$':
+		size, err := strconv.Atoi(line)
+		if (nil != err) || (redisMaxBulk < size) {
+			return nil, fmt.Errorf("invalid redis string size: %q", line)
+		}
+		if 0 > size {
+			return nil, nil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(rc.reader, data); nil != err {
+			return nil, err
+		}
+		return string(data[:size]), nil
+
+	case '*':
+		if redisMaxDepth <= aDepth {
+			return nil, errors.New("redis reply nested too deeply")
+		}
+		count, err := strconv.Atoi(line)
+		if (nil != err) || (*aBudget < count) {
+			return nil, fmt.Errorf("invalid redis array size: %q", line)
+		}
+		if 0 > count {
+			return nil, nil
+		}
+		*aBudget -= count
+		result := make([]any, count)
+		for idx := range result {
+			if result[idx], err = rc.readValue(aDepth+1, aBudget); nil != err {
+				return nil, err
+			}
+		}
+		return result, nil
+	}
+
+	return nil, fmt.Errorf("invalid redis reply: %q", line)
+} // readValue()
+
+// `send()` sends a command to the server.
+//
+// Parameters:
+//   - `aArgs`: The command and its arguments.
+//
+// Returns:
+//   - `error`: `nil` if the command was sent, the error otherwise.
+func (rc *tRedisConn) send(aArgs ...string) error {
+	buffer := fmt.Appendf(nil, "*%d\r\n", len(aArgs))
+	for _, arg := range aArgs {
+		buffer = fmt.Appendf(buffer, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if err := rc.SetWriteDeadline(time.Now().Add(redisTimeout)); nil != err {
+		return err
+	}
+	_, err := rc.Write(buffer)
+
+	return err
+} // send()
+
+// ---------------------------------------------------------------------------
+// `TRedisSync` constructor:
+
+// `NewRedisSync()` returns a new Redis publish/subscribe backend.
+//
+// Parameters:
+//   - `aOptions`: The backend's settings.
+//
+// Returns:
+//   - `*TRedisSync`: The new backend.
+//   - `error`: `nil` if the server was reachable, the error otherwise.
+func NewRedisSync(aOptions TRedisOptions) (*TRedisSync, error) {
+	if "" == aOptions.Address {
+		aOptions.Address = redisDefAddress
+	}
+	if "" == aOptions.Channel {
+		aOptions.Channel = redisDefChannel
+	}
+
+	sub, err := dialRedis(aOptions)
+	if nil != err {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	if err := sub.send("SUBSCRIBE", aOptions.Channel); nil != err {
+		_ = sub.Close()
+		return nil, fmt.Errorf("failed to subscribe to redis channel: %w", err)
+	}
+
+	result := &TRedisSync{
+		options: aOptions,
+		events:  make(chan TSyncEvent, syncEventBuffer),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+		sub:     sub,
+	}
+	if "" != aOptions.Key {
+		result.key = []byte(aOptions.Key)
+	}
+	go result.subscribe()
+
+	return result, nil
+} // NewRedisSync()
+
+// ---------------------------------------------------------------------------
+// `TRedisSync` methods:
+
+// `Close()` stops the propagation and closes the channel returned
+// by [Events].
+//
+// Returns:
+//   - `error`: `nil` if the backend was closed, the error otherwise.
+func (rs *TRedisSync) Close() (rErr error) {
+	rs.once.Do(func() {
+		close(rs.done)
+
+		rs.subMtx.Lock()
+		if nil != rs.sub {
+			rErr = rs.sub.Close()
+		}
+		rs.subMtx.Unlock()
+		<-rs.stopped
+
+		rs.pubMtx.Lock()
+		if nil != rs.pub {
+			_ = rs.pub.Close()
+			rs.pub = nil
+		}
+		rs.pubMtx.Unlock()
+	})
+
+	return
+} // Close()
+
+// `Events()` returns the channel delivering the events received from
+// the other instances.
+//
+// Returns:
+//   - `<-chan TSyncEvent`: The channel of received events.
+func (rs *TRedisSync) Events() <-chan TSyncEvent {
+	return rs.events
+} // Events()
+
+// `listen()` reads the subscribed channel's messages until the
+// connection fails.
+//
+// Parameters:
+//   - `aConn`: The subscribed connection.
+//
+// Returns:
+//   - `error`: The connection's error.
+func (rs *TRedisSync) listen(aConn *tRedisConn) error {
+	for {
+		reply, err := aConn.read()
+		if nil != err {
+			return err
+		}
+		// Messages are arrays of "message", the channel, and the payload
+		parts, ok := reply.([]any)
+		if !ok || (3 != len(parts)) || ("message" != parts[0]) {
+			continue // e.g. the subscription's confirmation
+		}
+		payload, _ := parts[2].(string)
+
+		var event TSyncEvent
+		message, ok := openSync(rs.key, []byte(payload))
+		if !ok || (nil != json.Unmarshal(message, &event)) {
+			rs.rejected.Add(1)
+			gLog.Debug("ignoring invalid redis message", "channel", rs.options.Channel)
+			continue
+		}
+		select {
+		case rs.events <- event:
+		default:
+			gLog.Warn("dropping redis event", "kind", event.Kind.String())
+		}
+	}
+} // listen()
+
+// `Publish()` sends an event to the other instances.
+//
+// Parameters:
+//   - `aEvent`: The event to send.
+//
+// Returns:
+//   - `error`: `nil` if the event was sent, the error otherwise.
+func (rs *TRedisSync) Publish(aEvent TSyncEvent) error {
+	data, err := json.Marshal(aEvent)
+	if nil != err {
+		return err
+	}
+
+	rs.pubMtx.Lock()
+	defer rs.pubMtx.Unlock()
+
+	select {
+	case <-rs.done:
+		return net.ErrClosed
+	default:
+	}
+	if nil == rs.pub {
+		if rs.pub, err = dialRedis(rs.options); nil != err {
+			return err
+		}
+	}
+	data = sealSync(rs.key, data)
+	if _, err = rs.pub.call("PUBLISH", rs.options.Channel, string(data)); nil != err {
+		var redisErr tRedisError
+		if !errors.As(err, &redisErr) {
+			// Reconnect with the next event
+			_ = rs.pub.Close()
+			rs.pub = nil
+		}
+	}
+
+	return err
+} // Publish()
+
+// `Rejected()` returns the number of invalid messages received, e.g.
+// with a wrong signature.
+//
+// Returns:
+//   - `uint64`: The number of ignored messages.
+func (rs *TRedisSync) Rejected() uint64 {
+	return rs.rejected.Load()
+} // Rejected()
+
+// `subscribe()` receives the channel's messages until the backend
+// gets closed, reconnecting after errors.
+func (rs *TRedisSync) subscribe() {
+	defer close(rs.stopped)
+	defer close(rs.events)
+
+	delay := time.Second
+	for {
+		rs.subMtx.Lock()
+		conn := rs.sub
+		rs.subMtx.Unlock()
+
+		if nil != conn {
+			err := rs.listen(conn)
+			_ = conn.Close()
+			select {
+			case <-rs.done:
+				return
+			default:
+			}
+			gLog.Warn("lost redis subscription", "error", err)
+			delay = time.Second
+		}
+
+		select {
+		case <-rs.done:
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay<<1, redisMaxRetryDelay)
+
+		conn, err := dialRedis(rs.options)
+		if nil == err {
+			if err = conn.send("SUBSCRIBE", rs.options.Channel); nil != err {
+				_ = conn.Close()
+				conn = nil
+			}
+		}
+		if nil != err {
+			gLog.Warn("failed to reconnect to redis", "address", rs.options.Address, "error", err)
+		}
+
+		rs.subMtx.Lock()
+		select {
+		case <-rs.done:
+			if nil != conn {
+				_ = conn.Close()
+			}
+			rs.subMtx.Unlock()
+			return
+		default:
+			rs.sub = conn
+		}
+		rs.subMtx.Unlock()
+	}
+} // subscribe()
+
+/* _EoF_ */
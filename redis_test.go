@@ -0,0 +1,259 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tFakeRedis` is a minimal Redis server supporting the
+	// commands `AUTH`, `PUBLISH` and `SUBSCRIBE`.
+	tFakeRedis struct {
+		sync.Mutex
+		listener    net.Listener
+		password    string
+		subscribers map[string][]*tRedisConn
+		conns       []net.Conn
+	}
+)
+
+func newFakeRedis(t *testing.T, aPassword string) *tFakeRedis {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatalf("Listen() error = '%v'", err)
+	}
+	result := &tFakeRedis{
+		listener:    listener,
+		password:    aPassword,
+		subscribers: make(map[string][]*tRedisConn),
+	}
+	go result.serve()
+	t.Cleanup(result.close)
+
+	return result
+} // newFakeRedis()
+
+func (fr *tFakeRedis) close() {
+	_ = fr.listener.Close()
+	fr.dropClients()
+} // close()
+
+// `dropClients()` closes all client connections.
+func (fr *tFakeRedis) dropClients() {
+	fr.Lock()
+	defer fr.Unlock()
+	for _, conn := range fr.conns {
+		_ = conn.Close()
+	}
+	fr.conns = nil
+	fr.subscribers = make(map[string][]*tRedisConn)
+} // dropClients()
+
+func (fr *tFakeRedis) handle(aConn *tRedisConn) {
+	authorised := ("" == fr.password)
+	for {
+		reply, err := aConn.read()
+		if nil != err {
+			return
+		}
+		args, _ := reply.([]any)
+		if 0 == len(args) {
+			continue
+		}
+		cmd, _ := args[0].(string)
+		switch {
+		case ("AUTH" == cmd) && (2 == len(args)):
+			if authorised = (fr.password == args[1]); authorised {
+				_, _ = aConn.Write([]byte("+OK\r\n"))
+			} else {
+				_, _ = aConn.Write([]byte("-WRONGPASS invalid password\r\n"))
+			}
+		case !authorised:
+			_, _ = aConn.Write([]byte("-NOAUTH Authentication required.\r\n"))
+		case ("SUBSCRIBE" == cmd) && (2 == len(args)):
+			channel, _ := args[1].(string)
+			fr.Lock()
+			fr.subscribers[channel] = append(fr.subscribers[channel], aConn)
+			fr.Unlock()
+			_ = aConn.send("subscribe", channel)
+		case ("PUBLISH" == cmd) && (3 == len(args)):
+			channel, _ := args[1].(string)
+			payload, _ := args[2].(string)
+			fr.Lock()
+			subscribers := fr.subscribers[channel]
+			fr.Unlock()
+			for _, sub := range subscribers {
+				_ = sub.send("message", channel, payload)
+			}
+			_, _ = aConn.Write([]byte(":1\r\n"))
+		default:
+			_, _ = aConn.Write([]byte("-ERR unknown command\r\n"))
+		}
+	}
+} // handle()
+
+func (fr *tFakeRedis) serve() {
+	for {
+		conn, err := fr.listener.Accept()
+		if nil != err {
+			return
+		}
+		fr.Lock()
+		fr.conns = append(fr.conns, conn)
+		fr.Unlock()
+		go fr.handle(&tRedisConn{Conn: conn, reader: bufio.NewReader(conn)})
+	}
+} // serve()
+
+func Test_tRedisConn_read(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   string
+		wantErr bool
+	}{
+		/* */
+		{"01 - message", "*3\r\n$7\r\nmessage\r\n$1\r\nc\r\n:1\r\n", false},
+		{"02 - max. nesting", strings.Repeat("*1\r\n", redisMaxDepth) + ":1\r\n", false},
+		{"03 - nested too deeply", strings.Repeat("*1\r\n", redisMaxDepth+1) + ":1\r\n", true},
+		{"04 - too many elements", "*1048576\r\n", true},
+		{"05 - too many nested elements", "*2\r\n*1\r\n:1\r\n*65535\r\n", true},
+		{"06 - invalid reply", "?\r\n", true},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rc := &tRedisConn{reader: bufio.NewReader(strings.NewReader(tc.reply))}
+			if _, err := rc.read(); (nil != err) != tc.wantErr {
+				t.Errorf("tRedisConn.read() error = '%v', wantErr '%v'", err, tc.wantErr)
+			}
+		})
+	}
+} // Test_tRedisConn_read()
+
+func Test_NewRedisSync(t *testing.T) {
+	server := newFakeRedis(t, "secret")
+
+	if _, err := NewRedisSync(TRedisOptions{Address: "127.0.0.1:1"}); nil == err {
+		t.Error("NewRedisSync() error = 'nil', want connection error")
+	}
+	_, err := NewRedisSync(TRedisOptions{Address: server.listener.Addr().String(), Password: "wrong"})
+	var redisErr tRedisError
+	if !errors.As(err, &redisErr) {
+		t.Errorf("NewRedisSync() error = '%v', want authentication error", err)
+	}
+
+	rs, err := NewRedisSync(TRedisOptions{Address: server.listener.Addr().String(), Password: "secret"})
+	if nil != err {
+		t.Fatalf("NewRedisSync() error = '%v'", err)
+	}
+	if redisDefChannel != rs.options.Channel {
+		t.Errorf("Channel = '%s', want '%s'", rs.options.Channel, redisDefChannel)
+	}
+	if err := rs.Close(); nil != err {
+		t.Errorf("Close() error = '%v'", err)
+	}
+	if _, ok := <-rs.Events(); ok {
+		t.Error("Events() not closed")
+	}
+	if err := rs.Publish(TSyncEvent{Kind: SyncFlush}); !errors.Is(err, net.ErrClosed) {
+		t.Errorf("Publish() error = '%v', want '%v'", err, net.ErrClosed)
+	}
+} // Test_NewRedisSync()
+
+func Test_TRedisSync_Publish(t *testing.T) {
+	server := newFakeRedis(t, "")
+	options := TRedisOptions{Address: server.listener.Addr().String(), Channel: "cluster"}
+	rs1, err := NewRedisSync(options)
+	if nil != err {
+		t.Fatalf("NewRedisSync() error = '%v'", err)
+	}
+	defer rs1.Close()
+	rs2, _ := NewRedisSync(options)
+	defer rs2.Close()
+
+	receive := func(aSync *TRedisSync) (TSyncEvent, bool) {
+		select {
+		case event := <-aSync.Events():
+			return event, true
+		case <-time.After(2 * time.Second):
+			return TSyncEvent{}, false
+		}
+	}
+
+	// Wait for both subscriptions
+	time.Sleep(50 * time.Millisecond)
+	event := TSyncEvent{Origin: "one", Kind: SyncEntry, Hostname: "host.localdomain",
+		IPs: []net.IP{net.ParseIP("192.0.2.1")}, TTL: time.Minute}
+	if err := rs1.Publish(event); nil != err {
+		t.Fatalf("Publish() error = '%v'", err)
+	}
+	for _, rs := range []*TRedisSync{rs1, rs2} {
+		got, ok := receive(rs)
+		if !ok || ("one" != got.Origin) || ("host.localdomain" != got.Hostname) || (time.Minute != got.TTL) {
+			t.Errorf("Events() = '%v', '%v', want '%v'", got, ok, event)
+		}
+	}
+
+	// Lost connections are re-established
+	server.dropClients()
+	_ = rs2.Publish(TSyncEvent{Origin: "two", Kind: SyncFlush}) // fails with the old connection
+	time.Sleep(1500 * time.Millisecond)
+	if err := rs2.Publish(TSyncEvent{Origin: "two", Kind: SyncFlush}); nil != err {
+		t.Fatalf("Publish() error = '%v'", err)
+	}
+	if got, ok := receive(rs1); !ok || ("two" != got.Origin) || (SyncFlush != got.Kind) {
+		t.Errorf("Events() = '%v', '%v', want a flush", got, ok)
+	}
+} // Test_TRedisSync_Publish()
+
+func Test_TRedisSync_Publish_key(t *testing.T) {
+	server := newFakeRedis(t, "")
+	options := TRedisOptions{Address: server.listener.Addr().String(), Key: "secret"}
+	receiver, err := NewRedisSync(options)
+	if nil != err {
+		t.Fatalf("NewRedisSync() error = '%v'", err)
+	}
+	defer receiver.Close()
+	options.Key = ""
+	unsigned, _ := NewRedisSync(options)
+	defer unsigned.Close()
+	options.Key = "guessed"
+	forger, _ := NewRedisSync(options)
+	defer forger.Close()
+
+	// Wait for the subscriptions
+	time.Sleep(50 * time.Millisecond)
+	for _, rs := range []*TRedisSync{unsigned, forger, receiver} {
+		if err := rs.Publish(TSyncEvent{Origin: rs.options.Key, Kind: SyncFlush}); nil != err {
+			t.Fatalf("Publish() error = '%v'", err)
+		}
+	}
+
+	select {
+	case got := <-receiver.Events():
+		if "secret" != got.Origin {
+			t.Errorf("Events() = '%v', want the signed event", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Events() received nothing")
+	}
+	if got := receiver.Rejected(); 2 != got {
+		t.Errorf("Rejected() = '%d', want '2'", got)
+	}
+} // Test_TRedisSync_Publish_key()
+
+/* _EoF_ */
@@ -0,0 +1,219 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mwat56/dnscache/clock"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+const (
+	// `defRefreshPace` is the minimal delay between two refresh
+	// lookups of the same worker.
+	defRefreshPace = time.Second << 1
+)
+
+type (
+	// `tAccessLog` records when hostnames were last queried.
+	tAccessLog struct {
+		sync.Mutex
+		clock clock.IClock // `nil` means the system's clock
+		last  map[string]time.Time
+	}
+
+	// `tRefreshPolicy` controls the background refresh of the cache.
+	tRefreshPolicy struct {
+		concurrency int           // number of parallel refresh lookups
+		jitter      time.Duration // max. random delay before a lookup
+		pace        time.Duration // min. delay between a worker's lookups
+		window      time.Duration // only refresh recently used entries (`0` means all)
+	}
+)
+
+// ---------------------------------------------------------------------------
+// `tAccessLog` methods:
+
+// `active()` returns the hostnames queried since the given time.
+//
+// Entries older than that are removed from the log.
+//
+// Parameters:
+//   - `aSince`: The time of the oldest access to consider.
+//
+// Returns:
+//   - `map[string]struct{}`: The recently queried hostnames.
+func (al *tAccessLog) active(aSince time.Time) map[string]struct{} {
+	if nil == al {
+		return nil
+	}
+	al.Lock()
+	defer al.Unlock()
+
+	result := make(map[string]struct{}, len(al.last))
+	for hostname, last := range al.last {
+		if last.Before(aSince) {
+			delete(al.last, hostname)
+			continue
+		}
+		result[hostname] = struct{}{}
+	}
+
+	return result
+} // active()
+
+// `touch()` records an access to the given hostname.
+//
+// Parameters:
+//   - `aHostname`: The queried hostname.
+func (al *tAccessLog) touch(aHostname string) {
+	if nil == al {
+		return
+	}
+	al.Lock()
+	if nil == al.last {
+		al.last = make(map[string]time.Time)
+	}
+	al.last[strings.Trim(strings.ToLower(aHostname), ".")] = clock.OrSystem(al.clock).Now()
+	al.Unlock()
+} // touch()
+
+// ---------------------------------------------------------------------------
+// `tRefreshPolicy` methods:
+
+// `delay()` returns the time to wait before a worker's next lookup.
+//
+// Returns:
+//   - `time.Duration`: The pace plus a random jitter.
+func (rp tRefreshPolicy) delay() time.Duration {
+	if 0 >= rp.jitter {
+		return rp.pace
+	}
+
+	return rp.pace + time.Duration(rand.Int63n(int64(rp.jitter))) //#nosec G404
+} // delay()
+
+// ---------------------------------------------------------------------------
+// `TResolver` methods:
+
+// `refreshHost()` resolves a cached hostname again.
+//
+// A hostname which doesn't exist anymore is removed from the cache.
+// The registered `TRefreshHook` functions are called with the old and
+// new addresses.
+//
+// Parameters:
+//   - `aCtx`: Context for the lookup operation.
+//   - `aHostname`: The hostname to refresh.
+func (r *TResolver) refreshHost(aCtx context.Context, aHostname string) {
+	var dnsErr *net.DNSError
+
+	r.RLock()
+	oldIPs, _ := r.ICacheList.IPs(aCtx, aHostname)
+	r.RUnlock()
+
+	newIPs, err := r.LookupHost(aCtx, aHostname)
+	if nil == err {
+		r.hooks.onRefresh(aHostname, oldIPs, newIPs)
+		r.watchers.notify(aHostname, oldIPs, newIPs)
+		return
+	}
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		// We'e working on a (possibly outdated) copy of the cache,
+		// but we delete the non-existing host from our original cache:
+		r.Lock()
+		r.ICacheList.Delete(aCtx, aHostname)
+		r.Unlock()
+		r.hooks.onRefresh(aHostname, oldIPs, nil)
+		r.watchers.notify(aHostname, oldIPs, nil)
+	}
+} // refreshHost()
+
+// `Refresh()` resolves all cached hostnames and updates the cache.
+//
+// This method is called automatically if a refresh interval was
+// specified in the `New()` constructor.
+//
+// The lookups are spread across the configured number of workers,
+// each pausing a randomly jittered delay between its lookups to
+// avoid bursts of queries against the upstream servers. If a refresh
+// window is configured, only the hostnames queried within that
+// window are refreshed; the others are left to expire.
+func (r *TResolver) Refresh() {
+	// Use a context with timeout for the entire refresh operation
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute<<2)
+	defer cancel()
+
+	policy := r.refresh
+	if 0 >= policy.concurrency {
+		policy.concurrency = 1
+	}
+	var active map[string]struct{}
+	if 0 < policy.window {
+		active = r.accessed.active(r.clock.Now().Add(-policy.window))
+	}
+
+	r.RLock()
+	// This is a shallow clone, the new keys and values
+	// are set using ordinary assignment:
+	cacheList := r.ICacheList.Clone()
+	r.RUnlock()
+
+	hostnames := make(chan string)
+	var wg sync.WaitGroup
+	for range policy.concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for hostname := range hostnames {
+				timer := r.clock.NewTimer(policy.delay())
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return // Context timeout or cancellation
+
+				case <-timer.C():
+					r.refreshHost(ctx, hostname)
+					runtime.Gosched() // yield to other goroutines
+				}
+			}
+		}()
+	}
+
+	for hostname := range cacheList.Range(ctx) {
+		if nil != active {
+			if _, ok := active[strings.Trim(strings.ToLower(hostname), ".")]; !ok {
+				continue
+			}
+		}
+		select {
+		case hostnames <- hostname:
+		case <-ctx.Done():
+		}
+		if nil != ctx.Err() {
+			break
+		}
+	}
+	close(hostnames)
+	wg.Wait()
+
+	//
+	//TODO: Reload allow and deny lists
+	//
+} // Refresh()
+
+/* _EoF_ */
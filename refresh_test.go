@@ -0,0 +1,103 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"net"
+	"slices"
+	"testing"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_tAccessLog_active(t *testing.T) {
+	var nilLog *tAccessLog
+	nilLog.touch("example.com")
+	if got := nilLog.active(time.Now()); nil != got {
+		t.Errorf("active() = '%v', want 'nil'", got)
+	}
+
+	al := &tAccessLog{}
+	al.touch("Old.example.com.")
+	al.last["old.example.com"] = time.Now().Add(-time.Hour)
+	al.touch("new.example.com")
+
+	got := al.active(time.Now().Add(-time.Minute))
+	if _, ok := got["new.example.com"]; !ok || (1 != len(got)) {
+		t.Errorf("active() = '%v', want 'new.example.com' only", got)
+	}
+	if _, ok := al.last["old.example.com"]; ok {
+		t.Errorf("active() didn't remove the outdated entry")
+	}
+} // Test_tAccessLog_active()
+
+func Test_tRefreshPolicy_delay(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  tRefreshPolicy
+		wantMin time.Duration
+		wantMax time.Duration
+	}{
+		/* */
+		{"01 - no pace, no jitter", tRefreshPolicy{}, 0, 0},
+		{"02 - pace only", tRefreshPolicy{pace: time.Second}, time.Second, time.Second},
+		{"03 - pace and jitter", tRefreshPolicy{pace: time.Second, jitter: time.Second},
+			time.Second, 2*time.Second - 1},
+		{"04 - negative jitter", tRefreshPolicy{pace: time.Second, jitter: -time.Second},
+			time.Second, time.Second},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			for range 100 {
+				if got := tc.policy.delay(); (got < tc.wantMin) || (got > tc.wantMax) {
+					t.Fatalf("delay() = '%v', want '%v' – '%v'", got, tc.wantMin, tc.wantMax)
+				}
+			}
+		})
+	}
+} // Test_tRefreshPolicy_delay()
+
+func Test_TResolver_Refresh_window(t *testing.T) {
+	ip := net.ParseIP("192.168.1.20")
+	startMDNSResponder(t, "printer.local", ip)
+
+	r := NewWithOptions(TResolverOptions{
+		DataDir:        t.TempDir(),
+		DNSservers:     []string{"192.0.2.1"}, // must not be asked
+		MDNS:           true,
+		RefreshWorkers: 2,
+		RefreshJitter:  time.Millisecond,
+		RefreshWindow:  time.Hour,
+	})
+	defer r.StopExpire()
+	r.refresh.pace = 0
+
+	ctx := context.Background()
+	oldIPs := []net.IP{net.ParseIP("10.0.0.1")}
+	for _, hostname := range []string{"printer.local", "scanner.local"} {
+		r.ICacheList.Create(ctx, hostname, oldIPs, time.Hour)
+	}
+	// Only the queried hostname is to be refreshed
+	if _, err := r.Fetch("printer.local"); nil != err {
+		t.Fatalf("Fetch() error = '%v'", err)
+	}
+
+	r.Refresh()
+
+	if got, _ := r.ICacheList.IPs(ctx, "printer.local"); !slices.EqualFunc(got, []net.IP{ip}, net.IP.Equal) {
+		t.Errorf("IPs(printer.local) = '%v', want '%v'", got, ip)
+	}
+	if got, _ := r.ICacheList.IPs(ctx, "scanner.local"); !slices.EqualFunc(got, oldIPs, net.IP.Equal) {
+		t.Errorf("IPs(scanner.local) = '%v', want '%v'", got, oldIPs)
+	}
+} // Test_TResolver_Refresh_window()
+
+/* _EoF_ */
@@ -9,10 +9,15 @@ package dnscache
 import (
 	"bufio"
 	"context"
+	"crypto/rand"
+	"encoding/binary"
 	"errors"
+	"fmt"
+	"io"
 	"net"
 	"os"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -72,8 +77,94 @@ func getDNSServers() ([]string, error) {
 	return result, nil
 } // getDNSServers()
 
+// `exchangeDNS()` sends a DNS query to a server and returns its response.
+//
+// A truncated UDP response is retried over TCP.
+//
+// Parameters:
+//   - `aCtx`: Context for the lookup operation.
+//   - `aServer`: DNS server to use.
+//   - `aQuery`: The DNS query message.
+//
+// Returns:
+//   - `[]byte`: The DNS response message.
+//   - `error`: `nil` if a response was received, the error otherwise.
+func exchangeDNS(aCtx context.Context, aServer string, aQuery []byte) ([]byte, error) {
+	dialer := net.Dialer{
+		Timeout: time.Second << 2,
+	}
+	address := aServer
+	if _, _, err := net.SplitHostPort(aServer); nil != err {
+		address = net.JoinHostPort(aServer, "53")
+	}
+	id := binary.BigEndian.Uint16(aQuery[0:2])
+
+	for _, network := range []string{"udp", "tcp"} {
+		conn, err := dialer.DialContext(aCtx, network, address)
+		if nil != err {
+			return nil, err
+		}
+		deadline := time.Now().Add(time.Second << 2)
+		if ctxDeadline, ok := aCtx.Deadline(); ok && ctxDeadline.Before(deadline) {
+			deadline = ctxDeadline
+		}
+		_ = conn.SetDeadline(deadline)
+
+		response, err := func() ([]byte, error) {
+			defer conn.Close()
+
+			if "tcp" == network {
+				msg := binary.BigEndian.AppendUint16(nil, uint16(len(aQuery))) //#nosec G115
+				if _, err := conn.Write(append(msg, aQuery...)); nil != err {
+					return nil, err
+				}
+				var prefix [2]byte
+				if _, err := io.ReadFull(conn, prefix[:]); nil != err {
+					return nil, err
+				}
+				response := make([]byte, binary.BigEndian.Uint16(prefix[:]))
+				_, err := io.ReadFull(conn, response)
+
+				return response, err
+			}
+
+			if _, err := conn.Write(aQuery); nil != err {
+				return nil, err
+			}
+			buffer := make([]byte, 1232)
+			for {
+				n, err := conn.Read(buffer)
+				if nil != err {
+					return nil, err
+				}
+				if (12 <= n) && (binary.BigEndian.Uint16(buffer[0:2]) == id) {
+					return buffer[:n], nil
+				}
+				// ignore unrelated or spoofed datagrams
+			}
+		}()
+		if nil != err {
+			return nil, err
+		}
+		if (12 > len(response)) || (binary.BigEndian.Uint16(response[0:2]) != id) {
+			return nil, errMalformedMessage
+		}
+		if 0 == binary.BigEndian.Uint16(response[2:4])&dnsFlagTC {
+			return response, nil
+		}
+	}
+
+	return nil, errMalformedMessage
+} // exchangeDNS()
+
 // `lookupDNS()` resolves a hostname using a specific DNS server.
 //
+// The A and AAAA records are queried concurrently and the smallest
+// TTL of all records used is returned along with the addresses. If
+// only one of the queries fails, the other one's addresses are
+// returned; an error is returned only if neither query produced an
+// address.
+//
 // Parameters:
 //   - `aCtx`: Context for the lookup operation.
 //   - `aServer`: DNS server to use.
@@ -81,40 +172,113 @@ func getDNSServers() ([]string, error) {
 //
 // Returns:
 //   - `[]net.IP`: List of IP addresses for the given hostname.
+//   - `time.Duration`: The TTL of the answer.
 //   - `error`: `nil` if the hostname was resolved successfully, the error otherwise.
-func lookupDNS(aCtx context.Context, aServer, aHostname string) ([]net.IP, error) {
+func lookupDNS(aCtx context.Context, aServer, aHostname string) ([]net.IP, time.Duration, error) {
 	var (
-		addrs []string
-		err   error
-		ip    net.IP
+		results [2]tLookupResult
+		errs    [2]error
+		wg      sync.WaitGroup
 	)
+	for idx, qType := range []uint16{dnsTypeA, dnsTypeAAAA} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[idx].ips, results[idx].ttl, errs[idx] =
+				lookupType(aCtx, aServer, aHostname, qType)
+		}()
+	}
+	wg.Wait()
 
-	resolver := &net.Resolver{
-		PreferGo: true, // Use Go's built-in DNS resolver
-
-		// `Dial` is used to connect to the DNS server
-		Dial: func(aCtx context.Context, aNetType, _ string) (net.Conn, error) {
-			dialer := net.Dialer{
-				Timeout: time.Second << 2,
+	var (
+		ips []net.IP
+		ttl time.Duration
+		err error
+	)
+	for idx, result := range results {
+		if nil != errs[idx] {
+			if nil == err {
+				err = errs[idx]
+			}
+			continue
+		}
+		if 0 < len(result.ips) {
+			ips = append(ips, result.ips...)
+			if (0 == ttl) || (result.ttl < ttl) {
+				ttl = result.ttl
 			}
+		}
+	}
+
+	if 0 == len(ips) {
+		if nil != err {
+			return nil, 0, err
+		}
+		return nil, 0, &net.DNSError{
+			Err:        "no such host",
+			Name:       aHostname,
+			Server:     aServer,
+			IsNotFound: true,
+		}
+	}
 
-			return dialer.DialContext(aCtx, aNetType, aServer+":53")
-		}, // Dial
-	} // resolver
+	return ips, ttl, nil
+} // lookupDNS()
 
-	// Do the DNS lookup
-	if addrs, err = resolver.LookupHost(aCtx, aHostname); nil != err {
-		return nil, err
+// `lookupType()` queries the records of the given type of a hostname.
+//
+// Parameters:
+//   - `aCtx`: Context for the lookup operation.
+//   - `aServer`: DNS server to use.
+//   - `aHostname`: The hostname to resolve.
+//   - `aType`: The record type to query (A or AAAA).
+//
+// Returns:
+//   - `[]net.IP`: List of IP addresses (possibly empty).
+//   - `time.Duration`: The smallest TTL of the records used.
+//   - `error`: `nil` if the server answered successfully, the error otherwise.
+func lookupType(aCtx context.Context, aServer, aHostname string, aType uint16) ([]net.IP, time.Duration, error) {
+	var idBytes [2]byte
+
+	_, _ = rand.Read(idBytes[:])
+	query := dnsQuery(binary.BigEndian.Uint16(idBytes[:]), dnsFlagRD, aHostname, aType)
+	if nil == query {
+		return nil, 0, &net.DNSError{Err: "invalid hostname", Name: aHostname}
+	}
+	response, err := exchangeDNS(aCtx, aServer, query)
+	if nil != err {
+		return nil, 0, &net.DNSError{
+			Err:       err.Error(),
+			Name:      aHostname,
+			Server:    aServer,
+			IsTimeout: errors.Is(err, os.ErrDeadlineExceeded),
+		}
 	}
 
-	ips := make([]net.IP, 0, len(addrs))
-	for _, addr := range addrs {
-		if ip = net.ParseIP(addr); nil != ip {
-			ips = append(ips, ip)
+	switch rcode := binary.BigEndian.Uint16(response[2:4]) & dnsRcodeMask; rcode {
+	case dnsRcodeNoError:
+	case dnsRcodeNXName:
+		return nil, 0, &net.DNSError{
+			Err:        "no such host",
+			Name:       aHostname,
+			Server:     aServer,
+			IsNotFound: true,
+		}
+	default:
+		return nil, 0, &net.DNSError{
+			Err:         fmt.Sprintf("server failure (rcode %d)", rcode),
+			Name:        aHostname,
+			Server:      aServer,
+			IsTemporary: true,
 		}
 	}
 
-	return ips, err
-} // lookupDNS()
+	answers, answerTTL, err := parseAnswers(response, aHostname)
+	if nil != err {
+		return nil, 0, &net.DNSError{Err: err.Error(), Name: aHostname, Server: aServer}
+	}
+
+	return answers, answerTTL, nil
+} // lookupType()
 
 /* _EoF_ */
@@ -8,8 +8,11 @@ package dnscache
 
 import (
 	"context"
+	"encoding/binary"
+	"net"
 	"slices"
 	"testing"
+	"time"
 )
 
 //lint:file-ignore ST1017 - I prefer Yoda conditions
@@ -92,7 +95,7 @@ func Test_lookupDNS(t *testing.T) {
 
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			got, err := lookupDNS(context.TODO(), tc.server, tc.hostname)
+			got, _, err := lookupDNS(context.TODO(), tc.server, tc.hostname)
 
 			// Check error
 			if (nil != err) != tc.wantErr {
@@ -112,6 +115,106 @@ func Test_lookupDNS(t *testing.T) {
 			assertIps(t, got, tc.wantIPs) // defined in `dnscache_test.go`
 		})
 	}
-} // Test_TResolver_Refresh()
+} // Test_lookupDNS()
+
+func Test_lookupDNS_parallel(t *testing.T) {
+	const delay = 200 * time.Millisecond
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatalf("ListenPacket() error = '%v'", err)
+	}
+	defer conn.Close()
+	go func() {
+		for {
+			buffer := make([]byte, 512)
+			n, addr, err := conn.ReadFrom(buffer)
+			if nil != err {
+				return
+			}
+			go func(aQuery []byte) {
+				ip := net.ParseIP("192.0.2.1")
+				if dnsTypeAAAA == binary.BigEndian.Uint16(aQuery[len(aQuery)-4:]) {
+					ip = net.ParseIP("2001:db8::1")
+				}
+				time.Sleep(delay)
+				_, _ = conn.WriteTo(mdnsResponse(aQuery, ip), addr)
+			}(buffer[:n])
+		}
+	}()
+
+	start := time.Now()
+	got, ttl, err := lookupDNS(context.TODO(), conn.LocalAddr().String(), "dual.example.com")
+	elapsed := time.Since(start)
+	if nil != err {
+		t.Fatalf("lookupDNS() error = '%v'", err)
+	}
+	assertIps(t, got, []string{"192.0.2.1", "2001:db8::1"})
+	if 120*time.Second != ttl {
+		t.Errorf("lookupDNS() TTL = '%v', want '%v'", ttl, 120*time.Second)
+	}
+	if 2*delay <= elapsed {
+		t.Errorf("lookupDNS() took '%v', want < '%v'", elapsed, 2*delay)
+	}
+} // Test_lookupDNS_parallel()
+
+func Test_lookupDNS_partial(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatalf("ListenPacket() error = '%v'", err)
+	}
+	defer conn.Close()
+	go func() {
+		for {
+			buffer := make([]byte, 512)
+			n, addr, err := conn.ReadFrom(buffer)
+			if nil != err {
+				return
+			}
+			query := buffer[:n]
+			// The first label names the failing record types
+			label := string(query[13 : 13+query[12]])
+			ip, failed := net.ParseIP("192.0.2.1"), ("a" == label)
+			if dnsTypeAAAA == binary.BigEndian.Uint16(query[len(query)-4:]) {
+				ip, failed = net.ParseIP("2001:db8::1"), ("aaaa" == label)
+			}
+			response := mdnsResponse(query, ip)
+			if failed || ("both" == label) {
+				response[3] |= 2 // SERVFAIL
+			}
+			_, _ = conn.WriteTo(response, addr)
+		}
+	}()
+
+	tests := []struct {
+		name     string
+		hostname string
+		wantIPs  []string
+		wantErr  bool
+	}{
+		/* */
+		{"01 - no failure", "none.example.com", []string{"192.0.2.1", "2001:db8::1"}, false},
+		{"02 - A failure", "a.example.com", []string{"2001:db8::1"}, false},
+		{"03 - AAAA failure", "aaaa.example.com", []string{"192.0.2.1"}, false},
+		{"04 - both failures", "both.example.com", nil, true},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, _, err := lookupDNS(context.TODO(), conn.LocalAddr().String(), tc.hostname)
+			if (nil != err) != tc.wantErr {
+				t.Fatalf("lookupDNS() error = '%v', wantErr '%v'", err, tc.wantErr)
+			}
+			if nil == tc.wantIPs {
+				if nil != got {
+					t.Errorf("lookupDNS() = '%v', want 'nil'", got)
+				}
+				return
+			}
+			assertIps(t, got, tc.wantIPs) // defined in `dnscache_test.go`
+		})
+	}
+} // Test_lookupDNS_partial()
 
 /* _EoF_ */
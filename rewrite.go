@@ -0,0 +1,435 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `TRewriteType` is the kind of a rewrite rule's target.
+	TRewriteType uint8
+
+	// `TRewriteRule` maps a queried hostname to another one or to
+	// fixed IP addresses.
+	//
+	//   - `Match`: The hostname to rewrite; a leading `*.` matches all
+	//     subdomains (e.g. `*.office.example`).
+	//   - `Type`: Whether `Target` is a list of IPs or a hostname.
+	//   - `Target`: Comma separated IP address(es) (`RewriteIP`) or the
+	//     hostname to resolve instead (`RewriteCNAME`).
+	TRewriteRule struct {
+		Match  string       `json:"match"`
+		Type   TRewriteType `json:"type"`
+		Target string       `json:"target"`
+	}
+
+	// `tRewriter` holds the rewrite rules of a resolver.
+	tRewriter struct {
+		sync.RWMutex
+		exact     map[string]TRewriteRule
+		wildcards []TRewriteRule // sorted by decreasing specificity
+		serial    uint32         // changed with every modification
+	}
+)
+
+const (
+	// `RewriteIP` answers a query with fixed IP addresses.
+	RewriteIP = TRewriteType(iota)
+
+	// `RewriteCNAME` answers a query with the addresses of another host.
+	RewriteCNAME
+
+	// `maxRewriteDepth` limits the number of consecutive CNAME rewrites
+	// to avoid endless loops.
+	maxRewriteDepth = 8
+)
+
+var (
+	// `ErrInvalidRewrite` is returned if a rewrite rule is invalid.
+	ErrInvalidRewrite = errors.New("invalid rewrite rule")
+
+	// `ErrRewriteLoop` is returned if CNAME rewrites form a loop.
+	ErrRewriteLoop = errors.New("rewrite loop detected")
+)
+
+// ---------------------------------------------------------------------------
+// `TRewriteType` methods:
+
+// `MarshalText()` implements the `encoding.TextMarshaler` interface.
+//
+// Returns:
+//   - `[]byte`: The type's name.
+//   - `error`: Always `nil`.
+func (rt TRewriteType) MarshalText() ([]byte, error) {
+	return []byte(rt.String()), nil
+} // MarshalText()
+
+// `String()` implements the `fmt.Stringer` interface.
+//
+// Returns:
+//   - `string`: The type's name.
+func (rt TRewriteType) String() string {
+	if RewriteCNAME == rt {
+		return "cname"
+	}
+
+	return "ip"
+} // String()
+
+// `UnmarshalText()` implements the `encoding.TextUnmarshaler` interface.
+//
+// Parameters:
+//   - `aText`: The type's name ("ip", "a", "aaaa", or "cname").
+//
+// Returns:
+//   - `error`: `nil` if the name is valid, `ErrInvalidRewrite` otherwise.
+func (rt *TRewriteType) UnmarshalText(aText []byte) error {
+	switch strings.ToLower(strings.TrimSpace(string(aText))) {
+	case "ip", "a", "aaaa":
+		*rt = RewriteIP
+	case "cname":
+		*rt = RewriteCNAME
+	default:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidRewrite, aText)
+	}
+
+	return nil
+} // UnmarshalText()
+
+// ---------------------------------------------------------------------------
+// `TRewriteRule` methods:
+
+// `ips()` returns the IP addresses of a `RewriteIP` rule's target.
+//
+// Returns:
+//   - `[]net.IP`: The target's IP addresses (`nil` if invalid).
+func (rr TRewriteRule) ips() (rIPs []net.IP) {
+	for _, part := range strings.Split(rr.Target, ",") {
+		ip := net.ParseIP(strings.TrimSpace(part))
+		if nil == ip {
+			return nil
+		}
+		rIPs = append(rIPs, ip)
+	}
+
+	return
+} // ips()
+
+// `normalise()` validates the rule and returns its normalised form.
+//
+// Returns:
+//   - `TRewriteRule`: The normalised rule.
+//   - `error`: `nil` if the rule is valid, `ErrInvalidRewrite` otherwise.
+func (rr TRewriteRule) normalise() (TRewriteRule, error) {
+	rr.Match = strings.Trim(strings.ToLower(strings.TrimSpace(rr.Match)), ".")
+	match := strings.TrimPrefix(rr.Match, "*.")
+	if ("" == match) || strings.Contains(match, "*") {
+		return rr, fmt.Errorf("%w: invalid match %q", ErrInvalidRewrite, rr.Match)
+	}
+
+	switch rr.Type {
+	case RewriteIP:
+		if nil == rr.ips() {
+			return rr, fmt.Errorf("%w: invalid IP target %q",
+				ErrInvalidRewrite, rr.Target)
+		}
+
+	case RewriteCNAME:
+		rr.Target = strings.Trim(strings.ToLower(strings.TrimSpace(rr.Target)), ".")
+		if ("" == rr.Target) || strings.Contains(rr.Target, "*") {
+			return rr, fmt.Errorf("%w: invalid CNAME target %q",
+				ErrInvalidRewrite, rr.Target)
+		}
+
+	default:
+		return rr, fmt.Errorf("%w: unknown type %d", ErrInvalidRewrite, rr.Type)
+	}
+
+	return rr, nil
+} // normalise()
+
+// `Validate()` checks whether the rule is valid.
+//
+// Returns:
+//   - `error`: `nil` if the rule is valid, `ErrInvalidRewrite` otherwise.
+func (rr TRewriteRule) Validate() error {
+	_, err := rr.normalise()
+
+	return err
+} // Validate()
+
+// ---------------------------------------------------------------------------
+// `tRewriter` constructor:
+
+// `newRewriter()` returns a new, empty `tRewriter` instance.
+//
+// Returns:
+//   - `*tRewriter`: A new rewriter.
+func newRewriter() *tRewriter {
+	return &tRewriter{
+		exact:  make(map[string]TRewriteRule),
+		serial: uint32(time.Now().Unix()), //#nosec G115
+	}
+} // newRewriter()
+
+// ---------------------------------------------------------------------------
+// `tRewriter` methods:
+
+// `add()` inserts a rule, replacing an existing one with the same match.
+//
+// Parameters:
+//   - `aRule`: The rule to add.
+//
+// Returns:
+//   - `error`: `nil` if the rule was added, the error otherwise.
+func (rw *tRewriter) add(aRule TRewriteRule) error {
+	if nil == rw {
+		return ErrInvalidRewrite
+	}
+	rule, err := aRule.normalise()
+	if nil != err {
+		return err
+	}
+
+	rw.Lock()
+	defer rw.Unlock()
+	rw.serial++
+
+	if !strings.HasPrefix(rule.Match, "*.") {
+		rw.exact[rule.Match] = rule
+		return nil
+	}
+
+	rw.wildcards = slices.DeleteFunc(rw.wildcards, func(aR TRewriteRule) bool {
+		return aR.Match == rule.Match
+	})
+	rw.wildcards = append(rw.wildcards, rule)
+	// The longest (i.e. most specific) suffix has to be tested first
+	slices.SortStableFunc(rw.wildcards, func(a, b TRewriteRule) int {
+		return len(b.Match) - len(a.Match)
+	})
+
+	return nil
+} // add()
+
+// `delete()` removes the rule with the given match.
+//
+// Parameters:
+//   - `aMatch`: The rule's match pattern.
+//
+// Returns:
+//   - `bool`: `true` if a rule was removed, `false` otherwise.
+func (rw *tRewriter) delete(aMatch string) bool {
+	if nil == rw {
+		return false
+	}
+	aMatch = strings.Trim(strings.ToLower(strings.TrimSpace(aMatch)), ".")
+
+	rw.Lock()
+	defer rw.Unlock()
+
+	if _, ok := rw.exact[aMatch]; ok {
+		delete(rw.exact, aMatch)
+		rw.serial++
+		return true
+	}
+
+	oldLen := len(rw.wildcards)
+	rw.wildcards = slices.DeleteFunc(rw.wildcards, func(aR TRewriteRule) bool {
+		return aR.Match == aMatch
+	})
+	if oldLen == len(rw.wildcards) {
+		return false
+	}
+	rw.serial++
+
+	return true
+} // delete()
+
+// `lookup()` returns the rule matching the given hostname.
+//
+// Exact matches take precedence over wildcard matches.
+//
+// Parameters:
+//   - `aHostname`: The hostname to look up.
+//
+// Returns:
+//   - `TRewriteRule`: The matching rule.
+//   - `bool`: `true` if a rule was found, `false` otherwise.
+func (rw *tRewriter) lookup(aHostname string) (TRewriteRule, bool) {
+	if nil == rw {
+		return TRewriteRule{}, false
+	}
+	hostname := strings.Trim(strings.ToLower(aHostname), ".")
+
+	rw.RLock()
+	defer rw.RUnlock()
+
+	if rule, ok := rw.exact[hostname]; ok {
+		return rule, true
+	}
+	for _, rule := range rw.wildcards {
+		if strings.HasSuffix(hostname, rule.Match[1:]) { // i.e. ".suffix"
+			return rule, true
+		}
+	}
+
+	return TRewriteRule{}, false
+} // lookup()
+
+// `rules()` returns a copy of all rules.
+//
+// Returns:
+//   - `[]TRewriteRule`: All rules sorted by their match pattern.
+func (rw *tRewriter) rules() []TRewriteRule {
+	if nil == rw {
+		return nil
+	}
+	rw.RLock()
+	defer rw.RUnlock()
+
+	result := make([]TRewriteRule, 0, len(rw.exact)+len(rw.wildcards))
+	for _, rule := range rw.exact {
+		result = append(result, rule)
+	}
+	result = append(result, rw.wildcards...)
+	slices.SortFunc(result, func(a, b TRewriteRule) int {
+		return strings.Compare(a.Match, b.Match)
+	})
+
+	return result
+} // rules()
+
+// `zone()` returns the rules within the given zone.
+//
+// Parameters:
+//   - `aZone`: The zone's name (e.g. `home.lan`).
+//
+// Returns:
+//   - `rRules`: The zone's rules sorted by their match pattern.
+//   - `rSerial`: The current serial number of the rules.
+func (rw *tRewriter) zone(aZone string) (rRules []TRewriteRule, rSerial uint32) {
+	if nil == rw {
+		return
+	}
+	zone := strings.Trim(strings.ToLower(strings.TrimSpace(aZone)), ".")
+	if "" == zone {
+		return
+	}
+
+	for _, rule := range rw.rules() {
+		if (rule.Match == zone) || strings.HasSuffix(rule.Match, "."+zone) {
+			rRules = append(rRules, rule)
+		}
+	}
+	rw.RLock()
+	rSerial = rw.serial
+	rw.RUnlock()
+
+	return
+} // zone()
+
+// ---------------------------------------------------------------------------
+// `TResolver` methods:
+
+// `AddRewrite()` adds a rewrite rule to the resolver.
+//
+// Rewrites are applied before the allow/deny lists, the cache, and
+// any upstream lookup. An existing rule with the same match pattern
+// is replaced.
+//
+// Parameters:
+//   - `aRule`: The rule to add.
+//
+// Returns:
+//   - `error`: `nil` if the rule was added, the error otherwise.
+func (r *TResolver) AddRewrite(aRule TRewriteRule) error {
+	return r.rewrites.add(aRule)
+} // AddRewrite()
+
+// `DeleteRewrite()` removes the rewrite rule with the given match pattern.
+//
+// Parameters:
+//   - `aMatch`: The match pattern of the rule to remove.
+//
+// Returns:
+//   - `bool`: `true` if a rule was removed, `false` otherwise.
+func (r *TResolver) DeleteRewrite(aMatch string) bool {
+	return r.rewrites.delete(aMatch)
+} // DeleteRewrite()
+
+// `rewrite()` applies the rewrite rules to the given hostname.
+//
+// CNAME rewrites are followed until either a fixed IP rule or a
+// hostname without a matching rule is found. With `aSafe` set the
+// built-in safe search rules apply to hostnames without a rule of
+// their own.
+//
+// Parameters:
+//   - `aHostname`: The queried hostname.
+//   - `aSafe`: Whether to enforce safe search.
+//
+// Returns:
+//   - `rIPs`: The fixed IP addresses (if an IP rule matched).
+//   - `rHostname`: The hostname to resolve instead.
+//   - `rErr`: `ErrRewriteLoop` if CNAME rewrites form a loop.
+func (r *TResolver) rewrite(aHostname string, aSafe bool) (rIPs []net.IP, rHostname string, rErr error) {
+	rHostname = aHostname
+	for range maxRewriteDepth {
+		rule, ok := r.rewrites.lookup(rHostname)
+		if !ok && aSafe {
+			rule, ok = gSafeSearch.lookup(rHostname)
+		}
+		if !ok {
+			return
+		}
+		if RewriteIP == rule.Type {
+			rIPs = rule.ips()
+			return
+		}
+		rHostname = rule.Target
+	}
+	rErr = ErrRewriteLoop
+
+	return
+} // rewrite()
+
+// `Rewrites()` returns all rewrite rules of the resolver.
+//
+// Returns:
+//   - `[]TRewriteRule`: A copy of the resolver's rewrite rules.
+func (r *TResolver) Rewrites() []TRewriteRule {
+	return r.rewrites.rules()
+} // Rewrites()
+
+// `ZoneRules()` returns the rewrite rules within the given zone.
+//
+// A rule belongs to the zone if its match pattern is either the
+// zone's name itself or a (wildcard) subdomain of it. The returned
+// serial number changes whenever a rule is added or removed, so it
+// can be used as the zone's SOA serial.
+//
+// Parameters:
+//   - `aZone`: The zone's name (e.g. `home.lan`).
+//
+// Returns:
+//   - `[]TRewriteRule`: The zone's rules sorted by their match pattern.
+//   - `uint32`: The current serial number of the rewrite rules.
+func (r *TResolver) ZoneRules(aZone string) ([]TRewriteRule, uint32) {
+	return r.rewrites.zone(aZone)
+} // ZoneRules()
+
+/* _EoF_ */
@@ -0,0 +1,293 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"slices"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_TRewriteRule_normalise(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    TRewriteRule
+		want    TRewriteRule
+		wantErr bool
+	}{
+		/* */
+		{
+			name:    "01 - empty rule",
+			rule:    TRewriteRule{},
+			wantErr: true,
+		},
+		{
+			name: "02 - IP rule",
+			rule: TRewriteRule{Match: " NAS.Home. ", Type: RewriteIP, Target: "192.168.1.10"},
+			want: TRewriteRule{Match: "nas.home", Type: RewriteIP, Target: "192.168.1.10"},
+		},
+		{
+			name:    "03 - invalid IP",
+			rule:    TRewriteRule{Match: "nas.home", Type: RewriteIP, Target: "192.168.1"},
+			wantErr: true,
+		},
+		{
+			name: "04 - CNAME rule",
+			rule: TRewriteRule{Match: "*.Office.Example", Type: RewriteCNAME, Target: "VPN.example."},
+			want: TRewriteRule{Match: "*.office.example", Type: RewriteCNAME, Target: "vpn.example"},
+		},
+		{
+			name:    "05 - inner wildcard",
+			rule:    TRewriteRule{Match: "a.*.example", Type: RewriteCNAME, Target: "vpn.example"},
+			wantErr: true,
+		},
+		{
+			name:    "06 - unknown type",
+			rule:    TRewriteRule{Match: "nas.home", Type: 42, Target: "vpn.example"},
+			wantErr: true,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.rule.normalise()
+			if (nil != err) != tc.wantErr {
+				t.Errorf("normalise() error = '%v', wantErr '%v'",
+					err, tc.wantErr)
+				return
+			}
+			if !tc.wantErr && (got != tc.want) {
+				t.Errorf("normalise() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_TRewriteRule_normalise()
+
+func Test_TRewriteType_JSON(t *testing.T) {
+	var rule TRewriteRule
+	data := []byte(`{"match":"*.office.example","type":"CNAME","target":"vpn.example"}`)
+	if err := json.Unmarshal(data, &rule); nil != err {
+		t.Fatalf("json.Unmarshal() error = '%v'", err)
+	}
+	if RewriteCNAME != rule.Type {
+		t.Errorf("Type = '%v', want '%v'", rule.Type, RewriteCNAME)
+	}
+
+	got, err := json.Marshal(rule)
+	if nil != err {
+		t.Fatalf("json.Marshal() error = '%v'", err)
+	}
+	if want := `{"match":"*.office.example","type":"cname","target":"vpn.example"}`; string(got) != want {
+		t.Errorf("json.Marshal() = '%s', want '%s'", got, want)
+	}
+
+	if err := json.Unmarshal([]byte(`{"type":"mx"}`), &rule); nil == err {
+		t.Error("json.Unmarshal() error = 'nil', want non-nil")
+	}
+} // Test_TRewriteType_JSON()
+
+func Test_TResolver_rewrite(t *testing.T) {
+	r := &TResolver{rewrites: newRewriter()}
+	for _, rule := range []TRewriteRule{
+		{Match: "nas.home", Type: RewriteIP, Target: "192.168.1.10"},
+		{Match: "*.office.example", Type: RewriteCNAME, Target: "vpn.example"},
+		{Match: "*.dev.office.example", Type: RewriteIP, Target: "10.0.0.1, fd00::1"},
+		{Match: "alias.home", Type: RewriteCNAME, Target: "nas.home"},
+		{Match: "loop1.home", Type: RewriteCNAME, Target: "loop2.home"},
+		{Match: "loop2.home", Type: RewriteCNAME, Target: "loop1.home"},
+	} {
+		if err := r.AddRewrite(rule); nil != err {
+			t.Fatalf("AddRewrite(%v) error = '%v'", rule, err)
+		}
+	}
+
+	tests := []struct {
+		name         string
+		hostname     string
+		wantIPs      []net.IP
+		wantHostname string
+		wantErr      error
+	}{
+		/* */
+		{
+			name:         "01 - no rule",
+			hostname:     "example.com",
+			wantHostname: "example.com",
+		},
+		{
+			name:         "02 - fixed IP",
+			hostname:     "NAS.home",
+			wantIPs:      []net.IP{net.ParseIP("192.168.1.10")},
+			wantHostname: "NAS.home",
+		},
+		{
+			name:         "03 - wildcard CNAME",
+			hostname:     "www.office.example",
+			wantHostname: "vpn.example",
+		},
+		{
+			name:         "04 - wildcard doesn't match its base",
+			hostname:     "office.example",
+			wantHostname: "office.example",
+		},
+		{
+			name:         "05 - more specific wildcard",
+			hostname:     "a.dev.office.example",
+			wantIPs:      []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("fd00::1")},
+			wantHostname: "a.dev.office.example",
+		},
+		{
+			name:         "06 - wildcard of several labels",
+			hostname:     "a.b.dev.office.example",
+			wantIPs:      []net.IP{net.ParseIP("10.0.0.1"), net.ParseIP("fd00::1")},
+			wantHostname: "a.b.dev.office.example",
+		},
+		{
+			name:         "07 - CNAME to IP rule",
+			hostname:     "alias.home",
+			wantIPs:      []net.IP{net.ParseIP("192.168.1.10")},
+			wantHostname: "nas.home",
+		},
+		{
+			name:         "08 - loop",
+			hostname:     "loop1.home",
+			wantHostname: "loop1.home",
+			wantErr:      ErrRewriteLoop,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ips, hostname, err := r.rewrite(tc.hostname, false)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("rewrite() error = '%v', want '%v'", err, tc.wantErr)
+				return
+			}
+			if !slices.EqualFunc(ips, tc.wantIPs, net.IP.Equal) {
+				t.Errorf("rewrite() IPs = '%v', want '%v'", ips, tc.wantIPs)
+			}
+			if hostname != tc.wantHostname {
+				t.Errorf("rewrite() hostname = '%v', want '%v'",
+					hostname, tc.wantHostname)
+			}
+		})
+	}
+} // Test_TResolver_rewrite()
+
+func Test_TResolver_DeleteRewrite(t *testing.T) {
+	r := &TResolver{rewrites: newRewriter()}
+	_ = r.AddRewrite(TRewriteRule{Match: "nas.home", Type: RewriteIP, Target: "192.168.1.10"})
+	_ = r.AddRewrite(TRewriteRule{Match: "*.office.example", Type: RewriteCNAME, Target: "vpn.example"})
+
+	tests := []struct {
+		name    string
+		match   string
+		want    bool
+		wantLen int
+	}{
+		/* */
+		{
+			name:    "01 - unknown rule",
+			match:   "nothing.home",
+			want:    false,
+			wantLen: 2,
+		},
+		{
+			name:    "02 - exact rule",
+			match:   "NAS.home",
+			want:    true,
+			wantLen: 1,
+		},
+		{
+			name:    "03 - wildcard rule",
+			match:   "*.office.example",
+			want:    true,
+			wantLen: 0,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := r.DeleteRewrite(tc.match); got != tc.want {
+				t.Errorf("DeleteRewrite() = '%v', want '%v'", got, tc.want)
+			}
+			if got := len(r.Rewrites()); got != tc.wantLen {
+				t.Errorf("len(Rewrites()) = '%d', want '%d'", got, tc.wantLen)
+			}
+		})
+	}
+} // Test_TResolver_DeleteRewrite()
+
+func Test_TResolver_ZoneRules(t *testing.T) {
+	r := &TResolver{rewrites: newRewriter()}
+	_ = r.AddRewrite(TRewriteRule{Match: "home.lan", Type: RewriteIP, Target: "192.168.1.1"})
+	_ = r.AddRewrite(TRewriteRule{Match: "nas.home.lan", Type: RewriteIP, Target: "192.168.1.10"})
+	_ = r.AddRewrite(TRewriteRule{Match: "*.dev.home.lan", Type: RewriteCNAME, Target: "nas.home.lan"})
+	_ = r.AddRewrite(TRewriteRule{Match: "otherhome.lan", Type: RewriteIP, Target: "192.168.2.1"})
+	_ = r.AddRewrite(TRewriteRule{Match: "nas.office.example", Type: RewriteIP, Target: "10.0.0.1"})
+
+	tests := []struct {
+		name string
+		zone string
+		want []string
+	}{
+		/* */
+		{
+			name: "01 - empty zone",
+			zone: "",
+			want: nil,
+		},
+		{
+			name: "02 - unknown zone",
+			zone: "example.org",
+			want: nil,
+		},
+		{
+			name: "03 - zone with apex",
+			zone: "Home.LAN.",
+			want: []string{"*.dev.home.lan", "home.lan", "nas.home.lan"},
+		},
+		{
+			name: "04 - parent zone",
+			zone: "example",
+			want: []string{"nas.office.example"},
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rules, _ := r.ZoneRules(tc.zone)
+			var got []string
+			for _, rule := range rules {
+				got = append(got, rule.Match)
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("ZoneRules() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+
+	_, serial := r.ZoneRules("home.lan")
+	r.DeleteRewrite("nothing.home.lan")
+	if _, got := r.ZoneRules("home.lan"); got != serial {
+		t.Errorf("ZoneRules() serial = '%d', want '%d'", got, serial)
+	}
+	r.DeleteRewrite("nas.home.lan")
+	if _, got := r.ZoneRules("home.lan"); got == serial {
+		t.Errorf("ZoneRules() serial = '%d', want a new one", got)
+	}
+} // Test_TResolver_ZoneRules()
+
+/* _EoF_ */
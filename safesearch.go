@@ -0,0 +1,112 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+const (
+	// `safeBing` is the Bing host serving only strict SafeSearch results.
+	safeBing = "strict.bing.com"
+
+	// `safeDuckDuckGo` is the DuckDuckGo host enforcing safe search.
+	safeDuckDuckGo = "safe.duckduckgo.com"
+
+	// `safeGoogle` is the Google host enforcing SafeSearch.
+	safeGoogle = "forcesafesearch.google.com"
+
+	// `safeYouTube` is the YouTube host enforcing the Restricted Mode.
+	safeYouTube = "restrict.youtube.com"
+)
+
+var (
+	// `gSafeSearch` holds the built-in rewrite rules forcing the
+	// search engines' safe search variants.
+	gSafeSearch = newSafeSearch()
+
+	// `safeSearchGoogleTLDs` are the top level domains of Google's
+	// search sites redirected to `safeGoogle`.
+	safeSearchGoogleTLDs = []string{
+		"ae", "at", "be", "ca", "ch", "cl", "co.id", "co.il", "co.in",
+		"co.jp", "co.kr", "co.nz", "co.uk", "co.za", "com", "com.ar",
+		"com.au", "com.br", "com.co", "com.mx", "com.tr", "com.tw",
+		"com.ua", "cz", "de", "dk", "es", "fi", "fr", "gr", "hu", "ie",
+		"it", "nl", "no", "pl", "pt", "ro", "ru", "se", "sk",
+	}
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `newSafeSearch()` returns a rewriter with the built-in safe search
+// rules.
+//
+// Returns:
+//   - `*tRewriter`: The safe search rewriter.
+func newSafeSearch() *tRewriter {
+	result := newRewriter()
+	add := func(aTarget string, aHosts ...string) {
+		for _, host := range aHosts {
+			_ = result.add(TRewriteRule{
+				Match:  host,
+				Type:   RewriteCNAME,
+				Target: aTarget,
+			})
+		}
+	}
+
+	for _, tld := range safeSearchGoogleTLDs {
+		add(safeGoogle, "google."+tld, "www.google."+tld)
+	}
+	add(safeBing, "bing.com", "www.bing.com")
+	add(safeDuckDuckGo, "duckduckgo.com", "www.duckduckgo.com",
+		"start.duckduckgo.com", "html.duckduckgo.com")
+	add(safeYouTube, "www.youtube.com", "m.youtube.com",
+		"youtube.googleapis.com", "youtubei.googleapis.com",
+		"www.youtube-nocookie.com")
+
+	return result
+} // newSafeSearch()
+
+// ---------------------------------------------------------------------------
+// Public functions:
+
+// `SafeSearchRules()` returns the built-in rewrite rules used to
+// enforce safe search.
+//
+// Returns:
+//   - `[]TRewriteRule`: The safe search rules sorted by their match pattern.
+func SafeSearchRules() []TRewriteRule {
+	return gSafeSearch.rules()
+} // SafeSearchRules()
+
+// ---------------------------------------------------------------------------
+// `TResolver` methods:
+
+// `SafeSearch()` reports whether safe search is enforced for clients
+// without an assigned group.
+//
+// Returns:
+//   - `bool`: `true` if safe search is enforced, `false` otherwise.
+func (r *TResolver) SafeSearch() bool {
+	return r.safeSearch.Load()
+} // SafeSearch()
+
+// `SetSafeSearch()` switches the safe search enforcement for clients
+// without an assigned group on or off.
+//
+// With safe search enforced the hostnames of Google, Bing, DuckDuckGo,
+// and YouTube are rewritten (see [SafeSearchRules]) to the sites'
+// variants filtering explicit content. The resolver's own rewrite
+// rules (see [AddRewrite]) take precedence.
+//
+// Parameters:
+//   - `aSafe`: Whether to enforce safe search.
+func (r *TResolver) SetSafeSearch(aSafe bool) {
+	r.safeSearch.Store(aSafe)
+} // SetSafeSearch()
+
+/* _EoF_ */
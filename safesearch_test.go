@@ -0,0 +1,149 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_newSafeSearch(t *testing.T) {
+	rw := newSafeSearch()
+
+	tests := []struct {
+		name     string
+		hostname string
+		want     string
+		wantOK   bool
+	}{
+		/* */
+		{"01 - Google", "www.google.com", safeGoogle, true},
+		{"02 - Google country", "google.de", safeGoogle, true},
+		{"03 - Google mail", "mail.google.com", "", false},
+		{"04 - Bing", "www.bing.com", safeBing, true},
+		{"05 - DuckDuckGo", "DuckDuckGo.com.", safeDuckDuckGo, true},
+		{"06 - YouTube", "m.youtube.com", safeYouTube, true},
+		{"07 - YouTube API", "youtubei.googleapis.com", safeYouTube, true},
+		{"08 - target", safeGoogle, "", false},
+		{"09 - other", "www.example.com", "", false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			rule, ok := rw.lookup(tc.hostname)
+			if ok != tc.wantOK {
+				t.Errorf("lookup(%q) ok = '%v', want '%v'", tc.hostname, ok, tc.wantOK)
+				return
+			}
+			if ok && ((RewriteCNAME != rule.Type) || (rule.Target != tc.want)) {
+				t.Errorf("lookup(%q) = '%v', want '%v'", tc.hostname, rule, tc.want)
+			}
+		})
+	}
+
+	if got := len(SafeSearchRules()); 2*len(safeSearchGoogleTLDs) >= got {
+		t.Errorf("SafeSearchRules() = '%d' rules, want more than '%d'",
+			got, 2*len(safeSearchGoogleTLDs))
+	}
+} // Test_newSafeSearch()
+
+func Test_TResolver_rewrite_safe(t *testing.T) {
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir()})
+	defer r.StopExpire()
+	_ = r.AddRewrite(TRewriteRule{Match: "www.bing.com", Type: RewriteIP, Target: "198.51.100.1"})
+	_ = r.AddRewrite(TRewriteRule{Match: "search.lan", Type: RewriteCNAME, Target: "www.google.com"})
+
+	tests := []struct {
+		name     string
+		hostname string
+		safe     bool
+		wantHost string
+		wantIPs  int
+	}{
+		/* */
+		{"01 - not enforced", "www.google.com", false, "www.google.com", 0},
+		{"02 - enforced", "www.google.com", true, safeGoogle, 0},
+		{"03 - own rule first", "www.bing.com", true, "www.bing.com", 1},
+		{"04 - own CNAME chained", "search.lan", true, safeGoogle, 0},
+		{"05 - own CNAME only", "search.lan", false, "www.google.com", 0},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ips, hostname, err := r.rewrite(tc.hostname, tc.safe)
+			if nil != err {
+				t.Errorf("rewrite() error = '%v'", err)
+				return
+			}
+			if hostname != tc.wantHost {
+				t.Errorf("rewrite() hostname = '%s', want '%s'", hostname, tc.wantHost)
+			}
+			if len(ips) != tc.wantIPs {
+				t.Errorf("rewrite() IPs = '%v', want '%d'", ips, tc.wantIPs)
+			}
+		})
+	}
+} // Test_TResolver_rewrite_safe()
+
+func Test_TResolver_SetGroupSafeSearch(t *testing.T) {
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir(), SafeSearch: true})
+	defer r.StopExpire()
+	_ = r.AddGroup("kids")
+	_ = r.AddGroup("admin")
+	_ = r.AssignClient("192.168.1.0/24", "kids")
+	_ = r.AssignClient("192.168.2.0/24", "admin")
+
+	if err := r.SetGroupSafeSearch("nogroup", true); !errors.Is(err, ErrUnknownGroup) {
+		t.Errorf("SetGroupSafeSearch() error = '%v', want '%v'", err, ErrUnknownGroup)
+	}
+	if err := r.SetGroupSafeSearch("kids", true); nil != err {
+		t.Fatalf("SetGroupSafeSearch() error = '%v'", err)
+	}
+
+	tests := []struct {
+		name   string
+		client net.IP
+		want   bool
+	}{
+		/* */
+		{"01 - no client", nil, true},
+		{"02 - default client", net.ParseIP("192.168.3.1"), true},
+		{"03 - enforcing group", net.ParseIP("192.168.1.1"), true},
+		{"04 - other group", net.ParseIP("192.168.2.1"), false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, got := r.clientPolicy(tc.client); got != tc.want {
+				t.Errorf("clientPolicy() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+
+	r.SetSafeSearch(false)
+	if r.SafeSearch() {
+		t.Error("SafeSearch() = 'true', want 'false'")
+	}
+	_ = r.SetGroupSafeSearch("kids", false)
+	if _, got := r.clientPolicy(net.ParseIP("192.168.1.1")); got {
+		t.Error("clientPolicy() = 'true', want 'false'")
+	}
+	_ = r.SetGroupSafeSearch("admin", true)
+	r.DeleteGroup("admin")
+	_ = r.AddGroup("admin")
+	if r.groups.safeSearch("admin") {
+		t.Error("safeSearch() = 'true', want 'false' for a new group")
+	}
+} // Test_TResolver_SetGroupSafeSearch()
+
+/* _EoF_ */
@@ -0,0 +1,180 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+
+	adl "github.com/mwat56/dnscache/internal/adlist"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tSearchList` holds the domains to qualify single-label
+	// hostnames with (like a resolver's `search` list).
+	tSearchList struct {
+		sync.RWMutex
+		domains []string // in the order they're tried
+	}
+)
+
+// ---------------------------------------------------------------------------
+// `tSearchList` methods:
+
+// `add()` appends a domain to the search list.
+//
+// Parameters:
+//   - `aDomain`: The domain to add.
+//
+// Returns:
+//   - `error`: `nil` if the domain was added, the error otherwise.
+func (sl *tSearchList) add(aDomain string) error {
+	domain := leaseHostname(aDomain)
+	if "" == domain {
+		return fmt.Errorf("invalid search domain: %q", aDomain)
+	}
+	sl.Lock()
+	defer sl.Unlock()
+
+	if !slices.Contains(sl.domains, domain) {
+		sl.domains = append(sl.domains, domain)
+	}
+
+	return nil
+} // add()
+
+// `delete()` removes a domain from the search list.
+//
+// Parameters:
+//   - `aDomain`: The domain to remove.
+//
+// Returns:
+//   - `bool`: `true` if the domain was removed, `false` otherwise.
+func (sl *tSearchList) delete(aDomain string) bool {
+	domain := leaseHostname(aDomain)
+	sl.Lock()
+	defer sl.Unlock()
+
+	if i := slices.Index(sl.domains, domain); 0 <= i {
+		sl.domains = slices.Delete(sl.domains, i, i+1)
+		return true
+	}
+
+	return false
+} // delete()
+
+// `list()` returns the search list.
+//
+// Returns:
+//   - `[]string`: The domains in the order they're tried.
+func (sl *tSearchList) list() []string {
+	if nil == sl {
+		return nil
+	}
+	sl.RLock()
+	defer sl.RUnlock()
+
+	return slices.Clone(sl.domains)
+} // list()
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `isNotFound()` checks whether a lookup error reports a non-existing
+// hostname.
+//
+// Parameters:
+//   - `aErr`: The lookup's error.
+//
+// Returns:
+//   - `bool`: `true` if the hostname doesn't exist, `false` otherwise.
+func isNotFound(aErr error) bool {
+	var dnsErr *net.DNSError
+
+	return errors.As(aErr, &dnsErr) && dnsErr.IsNotFound
+} // isNotFound()
+
+// ---------------------------------------------------------------------------
+// `TResolver` methods:
+
+// `AddSearchDomain()` appends a domain to the search list.
+//
+// A single-label hostname (like `printer`) which can't be resolved is
+// retried with each domain of the search list in turn (`printer.lan`)
+// before the lookup fails, like many home routers do.
+//
+// Parameters:
+//   - `aDomain`: The domain to qualify single-label hostnames with.
+//
+// Returns:
+//   - `error`: `nil` if the domain was added, the error otherwise.
+func (r *TResolver) AddSearchDomain(aDomain string) error {
+	if nil == r.search {
+		return fmt.Errorf("invalid search domain: %q", aDomain)
+	}
+
+	return r.search.add(aDomain)
+} // AddSearchDomain()
+
+// `DeleteSearchDomain()` removes a domain from the search list.
+//
+// Parameters:
+//   - `aDomain`: The domain to remove.
+//
+// Returns:
+//   - `bool`: `true` if the domain was removed, `false` otherwise.
+func (r *TResolver) DeleteSearchDomain(aDomain string) bool {
+	if nil == r.search {
+		return false
+	}
+
+	return r.search.delete(aDomain)
+} // DeleteSearchDomain()
+
+// `fetchSearch()` retries a single-label hostname with the domains of
+// the search list.
+//
+// Parameters:
+//   - `aList`: The allow/deny list to check the hostnames against.
+//   - `aSafe`: Whether to enforce safe search (see [SetSafeSearch]).
+//   - `aClient`: The requesting client's IP address (may be `nil`).
+//   - `aHostname`: The hostname which couldn't be resolved.
+//
+// Returns:
+//   - `[]net.IP`: The IP addresses of the first qualified hostname found.
+//   - `bool`: `true` if a qualified hostname was resolved, `false` otherwise.
+func (r *TResolver) fetchSearch(aList *adl.TADlist, aSafe bool, aClient net.IP, aHostname string) ([]net.IP, bool) {
+	label := strings.TrimSuffix(aHostname, ".")
+	if ("" == label) || strings.Contains(label, ".") {
+		return nil, false
+	}
+
+	for _, domain := range r.search.list() {
+		ips, err := r.fetch(aList, aSafe, aClient, label+"."+domain, 0)
+		if (nil == err) && (0 < len(ips)) {
+			return ips, true
+		}
+	}
+
+	return nil, false
+} // fetchSearch()
+
+// `SearchDomains()` returns the search list.
+//
+// Returns:
+//   - `[]string`: The domains in the order they're tried.
+func (r *TResolver) SearchDomains() []string {
+	return r.search.list()
+} // SearchDomains()
+
+/* _EoF_ */
@@ -0,0 +1,137 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"slices"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// `startLANUpstream()` starts a fake DNS server on the loopback
+// interface knowing only `printer.lan` and answering NXDOMAIN else.
+func startLANUpstream(t *testing.T, aIP net.IP) string {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatalf("ListenPacket() error = '%v'", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		for {
+			buffer := make([]byte, 512)
+			n, addr, err := conn.ReadFrom(buffer)
+			if nil != err {
+				return
+			}
+			query := buffer[:n]
+			switch {
+			case !bytes.Contains(query, []byte("\x07printer\x03lan\x00")):
+				response := append([]byte{}, query...)
+				binary.BigEndian.PutUint16(response[2:4], 0x8183) // NXDOMAIN
+				_, _ = conn.WriteTo(response, addr)
+			case dnsTypeAAAA == binary.BigEndian.Uint16(query[n-4:]):
+				_, _ = conn.WriteTo(mdnsResponse(query), addr)
+			default:
+				_, _ = conn.WriteTo(mdnsResponse(query, aIP), addr)
+			}
+		}
+	}()
+
+	return conn.LocalAddr().String()
+} // startLANUpstream()
+
+func Test_TResolver_AddSearchDomain(t *testing.T) {
+	r := New(0)
+
+	tests := []struct {
+		name    string
+		domain  string
+		wantErr bool
+		want    []string
+	}{
+		/* */
+		{"01 - empty domain", "", true, nil},
+		{"02 - invalid domain", "-lan", true, nil},
+		{"03 - valid domain", "LAN.", false, []string{"lan"}},
+		{"04 - second domain", "home.arpa", false, []string{"lan", "home.arpa"}},
+		{"05 - existing domain", "lan", false, []string{"lan", "home.arpa"}},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := r.AddSearchDomain(tc.domain); (nil != err) != tc.wantErr {
+				t.Errorf("AddSearchDomain() error = '%v', wantErr '%v'", err, tc.wantErr)
+			}
+			if got := r.SearchDomains(); !slices.Equal(got, tc.want) {
+				t.Errorf("SearchDomains() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+
+	if !r.DeleteSearchDomain("Lan") {
+		t.Error("DeleteSearchDomain() = 'false', want 'true'")
+	}
+	if r.DeleteSearchDomain("lan") {
+		t.Error("DeleteSearchDomain() again = 'true', want 'false'")
+	}
+	if got := r.SearchDomains(); !slices.Equal(got, []string{"home.arpa"}) {
+		t.Errorf("SearchDomains() = '%v', want '[home.arpa]'", got)
+	}
+} // Test_TResolver_AddSearchDomain()
+
+func Test_TResolver_fetchSearch(t *testing.T) {
+	printer := net.ParseIP("192.0.2.10").To4()
+	r := NewWithOptions(TResolverOptions{
+		DataDir:       t.TempDir(),
+		DNSservers:    []string{startLANUpstream(t, printer)},
+		SearchDomains: []string{"home.arpa", "lan", "-invalid"},
+		MaxRetries:    1,
+	})
+	defer r.StopExpire()
+
+	tests := []struct {
+		name     string
+		hostname string
+		want     net.IP
+	}{
+		/* */
+		{"01 - qualified name", "printer.lan", printer},
+		{"02 - single label", "printer", printer},
+		{"03 - single label with root", "printer.", printer},
+		{"04 - unknown single label", "scanner", nil},
+		{"05 - unknown qualified name", "printer.example", nil},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := r.Fetch(tc.hostname)
+			if nil == tc.want {
+				if !isNotFound(err) {
+					t.Errorf("Fetch() = '%v', '%v', want not found", got, err)
+				}
+				return
+			}
+			if nil != err {
+				t.Fatalf("Fetch() error = '%v'", err)
+			}
+			if (1 != len(got)) || !got[0].Equal(tc.want) {
+				t.Errorf("Fetch() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_TResolver_fetchSearch()
+
+/* _EoF_ */
@@ -0,0 +1,98 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	adl "github.com/mwat56/dnscache/internal/adlist"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `TTopEntry` is a hostname with its (estimated) number of
+	// occurrences as returned by [TResolver.TopQueries] and
+	// [TResolver.TopBlocked].
+	TTopEntry = adl.TTopEntry
+
+	// `THitCount` is a deny pattern with the number of hostnames it
+	// blocked as returned by [TResolver.DenyHitCounts].
+	THitCount = adl.THitCount
+
+	// `TLoadProgress` describes the progress of loading a blocklist
+	// as given to a [TProgressFunc].
+	TLoadProgress = adl.TLoadProgress
+
+	// `TProgressFunc` is called while the blocklists are loaded,
+	// see [TResolver.SetLoadProgress].
+	TProgressFunc = adl.TProgressFunc
+
+	// `TLoadReport` describes the last blocklist load as returned
+	// by [TResolver.BlocklistReport].
+	TLoadReport = adl.TLoadReport
+
+	// `TSourceStats` describes the loading of a single blocklist.
+	TSourceStats = adl.TSourceStats
+)
+
+// ---------------------------------------------------------------------------
+// `TResolver` methods:
+
+// `BlocklistReport()` returns the statistics of the last
+// [LoadBlocklists] call, i.e. the lines read, patterns added,
+// duplicates, and rejected entries per URL.
+//
+// Returns:
+//   - `TLoadReport`: The report, a zero value if no list was loaded yet.
+func (r *TResolver) BlocklistReport() TLoadReport {
+	return r.adlist.LoadReport()
+} // BlocklistReport()
+
+// `DenyHitCounts()` returns how often the patterns of the default
+// deny list blocked a hostname.
+//
+// Returns:
+//   - `[]THitCount`: The patterns sorted by decreasing number of hits.
+func (r *TResolver) DenyHitCounts() []THitCount {
+	return r.adlist.HitCounts()
+} // DenyHitCounts()
+
+// `TopBlocked()` returns the most often blocked hostnames.
+//
+// The counts of the default allow/deny list and those of all groups
+// and categories are summed up.
+//
+// Parameters:
+//   - `aCount`: The max. number of hostnames to return (`0` for all).
+//
+// Returns:
+//   - `[]TTopEntry`: The hostnames sorted by decreasing count.
+func (r *TResolver) TopBlocked(aCount int) []TTopEntry {
+	counts := make(map[string]uint64)
+	for _, list := range r.adlists() {
+		for _, entry := range list.TopBlocked(0) {
+			counts[entry.Name] += entry.Count
+		}
+	}
+
+	return adl.TopEntries(counts, aCount)
+} // TopBlocked()
+
+// `TopQueries()` returns the most often queried hostnames.
+//
+// The counts are estimated using a bounded number of counters and
+// cover the current and the previous hour.
+//
+// Parameters:
+//   - `aCount`: The max. number of hostnames to return (`0` for all).
+//
+// Returns:
+//   - `[]TTopEntry`: The hostnames sorted by decreasing count.
+func (r *TResolver) TopQueries(aCount int) []TTopEntry {
+	return r.queries.Top(aCount)
+} // TopQueries()
+
+/* _EoF_ */
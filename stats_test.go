@@ -0,0 +1,118 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_TResolver_BlocklistReport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(aWriter http.ResponseWriter, _ *http.Request) {
+		_, _ = aWriter.Write([]byte("ads.localdomain\nbücher.localdomain\nads.localdomain\n"))
+	}))
+	defer server.Close()
+
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir()})
+	defer r.StopExpire()
+	if got := r.BlocklistReport(); nil != got.Sources {
+		t.Errorf("BlocklistReport() = '%v', want 'nil'", got.Sources)
+	}
+
+	uri := server.URL + "/deny.txt"
+	if err := r.LoadBlocklists([]string{uri}); nil != err {
+		t.Fatalf("LoadBlocklists() error = '%v'", err)
+	}
+	got := r.BlocklistReport()
+	if 1 != len(got.Sources) {
+		t.Fatalf("BlocklistReport() = '%v', want 1 source", got.Sources)
+	}
+	source := got.Sources[0]
+	source.Duration = 0
+	want := TSourceStats{Source: uri, Lines: 3, Added: 2, Duplicates: 1, Converted: 1}
+	if !reflect.DeepEqual(source, want) {
+		t.Errorf("BlocklistReport() = '%v', want '%v'", source, want)
+	}
+	if ips, _ := r.Fetch("xn--bcher-kva.localdomain"); (1 != len(ips)) || !net.IPv4zero.Equal(ips[0]) {
+		t.Errorf("Fetch() = '%v', want '%v'", ips, net.IPv4zero)
+	}
+} // Test_TResolver_BlocklistReport()
+
+func Test_TResolver_DenyHitCounts(t *testing.T) {
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir()})
+	defer r.StopExpire()
+	_ = r.AddDeny("*.ads.localdomain")
+	_ = r.AddDeny("unused.localdomain")
+
+	_, _ = r.Fetch("banner.ads.localdomain")
+	_, _ = r.Fetch("popup.ads.localdomain")
+	got := r.DenyHitCounts()
+	if (1 != len(got)) || ("*.ads.localdomain" != got[0].Pattern) || (2 != got[0].Hits) {
+		t.Errorf("DenyHitCounts() = '%v', want 2 hits of '*.ads.localdomain'", got)
+	}
+
+	// The list didn't exist long enough
+	if got := r.PruneDenyPatterns(time.Hour); 0 != got {
+		t.Errorf("PruneDenyPatterns() = '%d', want '0'", got)
+	}
+} // Test_TResolver_DenyHitCounts()
+
+func Test_TResolver_TopBlocked(t *testing.T) {
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir()})
+	defer r.StopExpire()
+	_ = r.AddGroup("kids")
+	_ = r.AssignClient("192.168.1.0/24", "kids")
+	_ = r.AddGroupDeny("kids", "*.ads.tld")
+	r.adlist.AddDeny(context.TODO(), "*.ads.tld")
+
+	_, _ = r.Fetch("banner.ads.tld")
+	_, _ = r.FetchFor(net.ParseIP("192.168.1.10"), "banner.ads.tld")
+	_, _ = r.Fetch("popup.ads.tld")
+
+	got := r.TopBlocked(0)
+	want := []TTopEntry{
+		{Name: "banner.ads.tld", Count: 2},
+		{Name: "popup.ads.tld", Count: 1},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("TopBlocked() = '%v', want '%v'", got, want)
+	}
+	if got := r.TopBlocked(1); 1 != len(got) {
+		t.Errorf("len(TopBlocked(1)) = '%d', want '%d'", len(got), 1)
+	}
+} // Test_TResolver_TopBlocked()
+
+func Test_TResolver_TopQueries(t *testing.T) {
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir()})
+	defer r.StopExpire()
+	// Fixed answers to avoid network access
+	_ = r.AddRewrite(TRewriteRule{Match: "*.lan", Type: RewriteIP, Target: "192.168.1.1"})
+
+	for range 3 {
+		_, _ = r.Fetch("nas.lan")
+	}
+	_, _ = r.Fetch("printer.lan")
+
+	got := r.TopQueries(0)
+	want := []TTopEntry{
+		{Name: "nas.lan", Count: 3},
+		{Name: "printer.lan", Count: 1},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("TopQueries() = '%v', want '%v'", got, want)
+	}
+} // Test_TResolver_TopQueries()
+
+/* _EoF_ */
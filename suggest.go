@@ -0,0 +1,215 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mwat56/dnscache/clock"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `TAllowSuggestion` is a blocked hostname a single client queried
+	// over and over again as returned by [TResolver.AllowSuggestions].
+	//
+	// Such query storms are typical of applications which break if a
+	// hostname is blocked, so the hostname might better be allowed.
+	//
+	//   - `Hostname`: The blocked hostname.
+	//   - `Client`: The querying client's IP address.
+	//   - `Storms`: Number of query storms detected.
+	//   - `LastSeen`: Time of the latest storm.
+	TAllowSuggestion struct {
+		Hostname string    `json:"hostname"`
+		Client   string    `json:"client"`
+		Storms   uint32    `json:"storms"`
+		LastSeen time.Time `json:"lastSeen"`
+	}
+
+	// `tStormKey` identifies the blocked queries of a client.
+	tStormKey struct {
+		client   string
+		hostname string
+	}
+
+	// `tStormWindow` counts a client's blocked queries of a hostname.
+	tStormWindow struct {
+		start time.Time // start of the current window
+		count uint32    // blocked queries within the window
+	}
+
+	// `tBlockStorms` detects clients repeatedly querying a blocked
+	// hostname in a short time.
+	tBlockStorms struct {
+		sync.Mutex
+		clock   clock.IClock // `nil` means the system's clock
+		windows map[tStormKey]*tStormWindow
+		found   map[tStormKey]*TAllowSuggestion
+	}
+)
+
+const (
+	// `stormThreshold` is the number of blocked queries within
+	// `stormWindow` making up a query storm.
+	stormThreshold = 10
+
+	// `stormWindow` is the time in which `stormThreshold` blocked
+	// queries make up a query storm.
+	stormWindow = time.Minute
+
+	// `maxStormWindows` limits the number of counted (client,
+	// hostname) pairs.
+	maxStormWindows = 1 << 12
+
+	// `maxSuggestions` limits the number of remembered suggestions.
+	maxSuggestions = 1 << 8
+)
+
+// ---------------------------------------------------------------------------
+// `tBlockStorms` methods:
+
+// `expire()` removes the windows ended before the given time.
+//
+// The caller must hold the lock.
+//
+// Parameters:
+//   - `aNow`: The current time.
+//
+// Returns:
+//   - `bool`: `true` if windows were removed, `false` otherwise.
+func (bs *tBlockStorms) expire(aNow time.Time) (rOK bool) {
+	for key, window := range bs.windows {
+		if stormWindow < aNow.Sub(window.start) {
+			delete(bs.windows, key)
+			rOK = true
+		}
+	}
+
+	return
+} // expire()
+
+// `forgetOldest()` removes the least recently seen suggestion.
+//
+// The caller must hold the lock.
+func (bs *tBlockStorms) forgetOldest() {
+	var (
+		oldest tStormKey
+		last   time.Time
+	)
+	for key, suggestion := range bs.found {
+		if last.IsZero() || suggestion.LastSeen.Before(last) {
+			oldest, last = key, suggestion.LastSeen
+		}
+	}
+	delete(bs.found, oldest)
+} // forgetOldest()
+
+// `list()` returns the detected query storms.
+//
+// Returns:
+//   - `[]TAllowSuggestion`: The suggestions sorted by decreasing number
+//     of storms.
+func (bs *tBlockStorms) list() []TAllowSuggestion {
+	if nil == bs {
+		return nil
+	}
+	bs.Lock()
+	result := make([]TAllowSuggestion, 0, len(bs.found))
+	for _, suggestion := range bs.found {
+		result = append(result, *suggestion)
+	}
+	bs.Unlock()
+
+	slices.SortFunc(result, func(a, b TAllowSuggestion) int {
+		if a.Storms != b.Storms {
+			if a.Storms > b.Storms {
+				return -1
+			}
+			return 1
+		}
+		if c := b.LastSeen.Compare(a.LastSeen); 0 != c {
+			return c
+		}
+		return strings.Compare(a.Hostname+a.Client, b.Hostname+b.Client)
+	})
+
+	return result
+} // list()
+
+// `record()` counts a blocked query of the given client.
+//
+// The `stormThreshold`'s blocked query within `stormWindow` is
+// recorded as a query storm.
+//
+// Parameters:
+//   - `aHostname`: The blocked hostname.
+//   - `aClient`: The requesting client (`nil` if unknown).
+func (bs *tBlockStorms) record(aHostname string, aClient net.IP) {
+	if (nil == bs) || (nil == aClient) {
+		return
+	}
+	key := tStormKey{
+		client:   aClient.String(),
+		hostname: strings.Trim(strings.ToLower(aHostname), "."),
+	}
+	bs.Lock()
+	defer bs.Unlock()
+
+	now := clock.OrSystem(bs.clock).Now()
+	window, ok := bs.windows[key]
+	if !ok || (stormWindow < now.Sub(window.start)) {
+		if !ok && (maxStormWindows <= len(bs.windows)) && !bs.expire(now) {
+			return // too many clients querying too many hostnames
+		}
+		if nil == bs.windows {
+			bs.windows = make(map[tStormKey]*tStormWindow)
+		}
+		window = &tStormWindow{start: now}
+		bs.windows[key] = window
+	}
+	if window.count++; stormThreshold != window.count {
+		return
+	}
+
+	suggestion, ok := bs.found[key]
+	if !ok {
+		if maxSuggestions <= len(bs.found) {
+			bs.forgetOldest()
+		}
+		if nil == bs.found {
+			bs.found = make(map[tStormKey]*TAllowSuggestion)
+		}
+		suggestion = &TAllowSuggestion{Hostname: key.hostname, Client: key.client}
+		bs.found[key] = suggestion
+	}
+	suggestion.Storms++
+	suggestion.LastSeen = now
+} // record()
+
+// ---------------------------------------------------------------------------
+// `TResolver` methods:
+
+// `AllowSuggestions()` returns the blocked hostnames which single
+// clients queried over and over again in a short time.
+//
+// Such query storms are typical of broken applications, so these
+// hostnames are candidates for the allow list.
+//
+// Returns:
+//   - `[]TAllowSuggestion`: The suggestions sorted by decreasing number
+//     of storms.
+func (r *TResolver) AllowSuggestions() []TAllowSuggestion {
+	return r.storms.list()
+} // AllowSuggestions()
+
+/* _EoF_ */
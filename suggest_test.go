@@ -0,0 +1,75 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/mwat56/dnscache/clock"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_TResolver_AllowSuggestions(t *testing.T) {
+	clk := clock.NewManual(time.Now())
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir(), Clock: clk})
+	defer r.StopExpire()
+	_ = r.AddDeny("*.ads.localdomain")
+
+	broken := net.ParseIP("192.168.1.10")
+	other := net.ParseIP("192.168.1.20")
+	query := func(aClient net.IP, aCount int) {
+		for range aCount {
+			_, _ = r.FetchFor(aClient, "api.ads.localdomain")
+		}
+	}
+
+	query(broken, stormThreshold-1)
+	query(other, stormThreshold-1)
+	_, _ = r.Fetch("api.ads.localdomain") // unknown client
+	if got := r.AllowSuggestions(); 0 != len(got) {
+		t.Fatalf("AllowSuggestions() = '%v', want none", got)
+	}
+
+	query(broken, 1)
+	got := r.AllowSuggestions()
+	if (1 != len(got)) || ("api.ads.localdomain" != got[0].Hostname) ||
+		(broken.String() != got[0].Client) || (1 != got[0].Storms) {
+		t.Fatalf("AllowSuggestions() = '%v', want one storm of '%s'", got, broken)
+	}
+
+	// The next storm in a later window
+	query(broken, stormThreshold) // still in the first window
+	clk.Advance(stormWindow + time.Second)
+	query(broken, stormThreshold)
+	if got := r.AllowSuggestions(); (1 != len(got)) || (2 != got[0].Storms) {
+		t.Errorf("AllowSuggestions() = '%v', want two storms", got)
+	}
+} // Test_TResolver_AllowSuggestions()
+
+func Test_tBlockStorms_record(t *testing.T) {
+	bs := &tBlockStorms{}
+	for idx := range maxSuggestions + 1 {
+		client := net.IPv4(10, 0, byte(idx>>8), byte(idx))
+		for range stormThreshold {
+			bs.record("ads.localdomain", client)
+		}
+	}
+	if got := len(bs.list()); maxSuggestions != got {
+		t.Errorf("len(list()) = '%d', want '%d'", got, maxSuggestions)
+	}
+
+	var nilStorms *tBlockStorms
+	nilStorms.record("ads.localdomain", net.IPv4(10, 0, 0, 1))
+	if got := nilStorms.list(); nil != got {
+		t.Errorf("list() = '%v', want 'nil'", got)
+	}
+} // Test_tBlockStorms_record()
+
+/* _EoF_ */
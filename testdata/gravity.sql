@@ -0,0 +1,41 @@
+-- Builds `gravity.db`: sqlite3 gravity.db < gravity.sql
+-- The tables follow Pi-hole's schema (version 6).
+PRAGMA page_size = 1024;
+CREATE TABLE "group" (id INTEGER PRIMARY KEY AUTOINCREMENT, enabled BOOLEAN NOT NULL DEFAULT 1, name TEXT UNIQUE NOT NULL, date_added INTEGER NOT NULL DEFAULT (cast(strftime('%s', 'now') as int)), date_modified INTEGER NOT NULL DEFAULT (cast(strftime('%s', 'now') as int)), description TEXT);
+CREATE TABLE domainlist (id INTEGER PRIMARY KEY AUTOINCREMENT, type INTEGER NOT NULL DEFAULT 0, domain TEXT NOT NULL, enabled BOOLEAN NOT NULL DEFAULT 1, date_added INTEGER NOT NULL DEFAULT (cast(strftime('%s', 'now') as int)), date_modified INTEGER NOT NULL DEFAULT (cast(strftime('%s', 'now') as int)), comment TEXT, UNIQUE(domain, type));
+CREATE TABLE adlist (id INTEGER PRIMARY KEY AUTOINCREMENT, address TEXT NOT NULL, enabled BOOLEAN NOT NULL DEFAULT 1, date_added INTEGER NOT NULL DEFAULT (cast(strftime('%s', 'now') as int)), date_modified INTEGER NOT NULL DEFAULT (cast(strftime('%s', 'now') as int)), comment TEXT, date_updated INTEGER, number INTEGER NOT NULL DEFAULT 0, invalid_domains INTEGER NOT NULL DEFAULT 0, status INTEGER NOT NULL DEFAULT 0, abp_entries INTEGER NOT NULL DEFAULT 0, type INTEGER NOT NULL DEFAULT 0, UNIQUE(address, type));
+CREATE TABLE adlist_by_group (adlist_id INTEGER NOT NULL REFERENCES adlist (id) ON DELETE CASCADE, group_id INTEGER NOT NULL REFERENCES "group" (id) ON DELETE CASCADE, PRIMARY KEY (adlist_id, group_id));
+CREATE TABLE gravity (domain TEXT NOT NULL, adlist_id INTEGER NOT NULL REFERENCES adlist (id) ON DELETE CASCADE);
+CREATE TABLE info (property TEXT PRIMARY KEY, value TEXT NOT NULL);
+CREATE TABLE domainlist_by_group (domainlist_id INTEGER NOT NULL REFERENCES domainlist (id) ON DELETE CASCADE, group_id INTEGER NOT NULL REFERENCES "group" (id) ON DELETE CASCADE, PRIMARY KEY (domainlist_id, group_id));
+CREATE TABLE client (id INTEGER PRIMARY KEY AUTOINCREMENT, ip TEXT NOT NULL UNIQUE, date_added INTEGER NOT NULL DEFAULT (cast(strftime('%s', 'now') as int)), date_modified INTEGER NOT NULL DEFAULT (cast(strftime('%s', 'now') as int)), comment TEXT);
+CREATE TABLE client_by_group (client_id INTEGER NOT NULL REFERENCES client (id) ON DELETE CASCADE, group_id INTEGER NOT NULL REFERENCES "group" (id), PRIMARY KEY (client_id, group_id));
+
+INSERT INTO info VALUES ('version', '19');
+INSERT INTO "group" (id, enabled, name, description) VALUES
+	(0, 1, 'Default', 'The default group'),
+	(1, 1, 'Kids Devices', NULL),
+	(2, 0, 'Off', 'a disabled group');
+INSERT INTO domainlist (id, type, domain, enabled) VALUES
+	(1, 1, 'ads.example.com', 1),
+	(2, 0, 'cdn.ads.example.com', 1),
+	(3, 3, '(\.|^)tracker\.example\.net$', 1),
+	(4, 3, '^ad[0-9]+\.', 1),
+	(5, 1, 'games.example.org', 1),
+	(6, 1, 'disabled.example.com', 0),
+	(7, 1, 'off.example.com', 1);
+INSERT INTO domainlist_by_group VALUES (1, 0), (2, 0), (3, 1), (4, 0), (5, 1), (5, 2), (6, 0), (7, 2);
+INSERT INTO adlist (id, address, enabled, type) VALUES
+	(1, 'https://lists.example.com/hosts.txt', 1, 0),
+	(2, 'https://lists.example.com/kids.txt', 1, 0),
+	(3, 'https://lists.example.com/allow.txt', 1, 1),
+	(4, 'https://lists.example.com/disabled.txt', 0, 0);
+INSERT INTO adlist_by_group VALUES (1, 0), (2, 1), (3, 0), (4, 0);
+INSERT INTO gravity VALUES ('banner.example.com', 1), ('games.example.org', 2);
+INSERT INTO client (id, ip) VALUES
+	(1, '192.168.1.10'),
+	(2, '10.0.0.0/24'),
+	(3, '00:11:22:33:44:55'),
+	(4, ':eth0'),
+	(5, '192.168.1.20');
+INSERT INTO client_by_group VALUES (1, 0), (1, 1), (2, 1), (3, 1), (4, 1), (5, 0);
@@ -0,0 +1,80 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+
+	adl "github.com/mwat56/dnscache/internal/adlist"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `TTLDOptions` contains the settings of [InitTLDs].
+	//
+	//   - `CacheFile`: Path/name of the local copy of the IANA list.
+	//   - `MaxAge`: Age after which the local copy is downloaded again (default: 7 days).
+	//   - `Disabled`: Don't check the blocklists' hostnames for valid top-level domains.
+	//   - `Offline`: Don't download the list but use the local copy or the embedded snapshot.
+	TTLDOptions = adl.TTLDOptions
+
+	// `TPrecedence` is the rule deciding about hostnames matched by
+	// both the allow and the deny list (see [TResolverOptions]).
+	TPrecedence = adl.TPrecedence
+
+	// `TValidation` is the strictness of the blocklists' hostname
+	// checks (see [TResolverOptions]).
+	TValidation = adl.TValidation
+)
+
+const (
+	// `ValidateStrict` accepts RFC 1123 hostnames with a known
+	// top-level domain only (default).
+	ValidateStrict = adl.ValidateStrict
+
+	// `ValidateRelaxed` accepts hostnames with any top-level domain
+	// (e.g. `foo.corp`), single-label names, and underscores.
+	ValidateRelaxed = adl.ValidateRelaxed
+
+	// `ValidateOff` accepts every pattern without whitespace.
+	ValidateOff = adl.ValidateOff
+
+	// `PrecedenceNone` marks a decision which needed no rule
+	// (see [TTrace]).
+	PrecedenceNone = adl.PrecedenceNone
+
+	// `PrecedenceAllow` lets the allow list always win (default).
+	PrecedenceAllow = adl.PrecedenceAllow
+
+	// `PrecedenceSpecific` lets the more specific of the matching
+	// allow and deny patterns win.
+	PrecedenceSpecific = adl.PrecedenceSpecific
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `InitTLDs()` sets up the top-level domains used to validate the
+// hostnames of the blocklists.
+//
+// Without calling this function an embedded snapshot of the IANA list
+// is used, i.e. the package never downloads the list by itself. If
+// the download fails, the outdated local copy or the snapshot is used
+// instead, so the returned error is informative only.
+//
+// Parameters:
+//   - `aCtx`: The context to use for the download.
+//   - `aOptions`: The settings to use.
+//
+// Returns:
+//   - `error`: `nil` if a current (or offline) list is used, the download error otherwise.
+func InitTLDs(aCtx context.Context, aOptions TTLDOptions) error {
+	return adl.InitTLDs(aCtx, aOptions)
+} // InitTLDs()
+
+/* _EoF_ */
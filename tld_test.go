@@ -0,0 +1,59 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_InitTLDs(t *testing.T) {
+	// Restore the embedded snapshot
+	defer func() {
+		_ = InitTLDs(context.TODO(), TTLDOptions{Offline: true,
+			CacheFile: filepath.Join(t.TempDir(), "missing.txt")})
+	}()
+
+	cacheFile := filepath.Join(t.TempDir(), "tlds.txt")
+	if err := os.WriteFile(cacheFile, []byte("COM\nNET\n"), 0600); nil != err {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		options TTLDOptions
+	}{
+		/* */
+		{
+			name:    "01 - disabled",
+			options: TTLDOptions{Disabled: true},
+		},
+		{
+			name:    "02 - local copy",
+			options: TTLDOptions{CacheFile: cacheFile},
+		},
+		{
+			name:    "03 - offline",
+			options: TTLDOptions{CacheFile: cacheFile, Offline: true},
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := InitTLDs(context.TODO(), tc.options); nil != err {
+				t.Errorf("InitTLDs() error = '%v', want 'nil'", err)
+			}
+		})
+	}
+} // Test_InitTLDs()
+
+/* _EoF_ */
@@ -0,0 +1,211 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"net"
+	"time"
+
+	adl "github.com/mwat56/dnscache/internal/adlist"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `TTraceStep` is a single step of a hostname's resolution as
+	// returned by [TResolver.Trace].
+	//
+	//   - `Step`: The step's name, e.g. `"adlist"` or `"cache"`.
+	//   - `Result`: The step's outcome, e.g. `"deny"` or `"miss"`.
+	//   - `Detail`: Additional information, e.g. the matching pattern.
+	//   - `Duration`: The time used by the step.
+	TTraceStep struct {
+		Step     string        `json:"step"`
+		Result   string        `json:"result"`
+		Detail   string        `json:"detail,omitempty"`
+		Duration time.Duration `json:"duration"`
+	}
+
+	// `TTrace` describes how a hostname was resolved as returned by
+	// [TResolver.Trace].
+	//
+	//   - `Hostname`: The traced hostname.
+	//   - `Decision`: The allow/deny lists' decision (`"allow"`,
+	//     `"deny"`, or `"neutral"`).
+	//   - `Pattern`: The allow or deny pattern causing the decision.
+	//   - `Source`: The URL of the blocklist providing a deny pattern.
+	//   - `Rule`: The precedence rule deciding between the allow and
+	//     deny lists if both matched (`"allow"` or `"specific"`).
+	//   - `Cached`: Whether the answer was taken from the cache.
+	//   - `Upstream`: The server which answered the lookup, if any.
+	//   - `IPs`: The final record set.
+	//   - `Error`: The reason why the hostname couldn't be resolved.
+	//   - `Steps`: The steps of the resolution in their order.
+	//   - `Duration`: The time used by all steps.
+	TTrace struct {
+		Hostname string        `json:"hostname"`
+		Decision string        `json:"decision,omitempty"`
+		Pattern  string        `json:"pattern,omitempty"`
+		Source   string        `json:"source,omitempty"`
+		Rule     string        `json:"rule,omitempty"`
+		Cached   bool          `json:"cached"`
+		Upstream string        `json:"upstream,omitempty"`
+		IPs      []net.IP      `json:"ips"`
+		Error    string        `json:"error,omitempty"`
+		Steps    []TTraceStep  `json:"steps"`
+		Duration time.Duration `json:"duration"`
+	}
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `decisionName()` returns the name of an allow/deny list decision.
+//
+// Parameters:
+//   - `aResult`: The decision to name.
+//
+// Returns:
+//   - `string`: The decision's name.
+func decisionName(aResult adl.TADresult) string {
+	switch aResult {
+	case adl.ADallow:
+		return "allow"
+	case adl.ADdeny:
+		return "deny"
+	default:
+		return "neutral"
+	}
+} // decisionName()
+
+// ---------------------------------------------------------------------------
+// `TTrace` methods:
+
+// `step()` appends a step to the trace.
+//
+// Parameters:
+//   - `aStep`: The step's name.
+//   - `aResult`: The step's outcome.
+//   - `aDetail`: Additional information (may be empty).
+//   - `aStart`: The step's start time.
+func (t *TTrace) step(aStep, aResult, aDetail string, aStart time.Time) {
+	t.Steps = append(t.Steps, TTraceStep{
+		Step:     aStep,
+		Result:   aResult,
+		Detail:   aDetail,
+		Duration: time.Since(aStart),
+	})
+} // step()
+
+// ---------------------------------------------------------------------------
+// `TResolver` methods:
+
+// `Trace()` resolves a hostname like [Fetch] and reports every step
+// of the resolution: the rewrite rules, the DHCP leases, the
+// allow/deny lists' decision (and the matching pattern), the cache,
+// and the upstream lookup with the answering server.
+//
+// In audit mode (see [SetAuditMode]) a denied hostname's trace goes on
+// with the cache and upstream lookup.
+//
+// Other than [Fetch] the trace doesn't retry failed lookups, and it
+// changes neither the cache nor the metrics and statistics.
+//
+// Parameters:
+//   - `aCtx`: Context for the lookup operation.
+//   - `aHostname`: The hostname to resolve.
+//
+// Returns:
+//   - `*TTrace`: The resolution's trace.
+func (r *TResolver) Trace(aCtx context.Context, aHostname string) *TTrace {
+	started := time.Now()
+	result := &TTrace{Hostname: aHostname}
+	defer func() {
+		result.Duration = time.Since(started)
+	}()
+
+	start := time.Now()
+	ips, hostname, err := r.rewrite(aHostname, r.safeSearch.Load())
+	switch {
+	case nil != err:
+		result.step("rewrite", "error", err.Error(), start)
+		result.Error = err.Error()
+		return result
+	case 0 < len(ips):
+		result.step("rewrite", "answer", "", start)
+		result.IPs = ips
+		return result
+	case hostname != aHostname:
+		result.step("rewrite", "alias", hostname, start)
+	default:
+		result.step("rewrite", "none", "", start)
+	}
+
+	start = time.Now()
+	if ips = r.leases.lookup(hostname); 0 < len(ips) {
+		result.step("leases", "hit", "", start)
+		result.IPs = ips
+		return result
+	}
+	result.step("leases", "miss", "", start)
+
+	start = time.Now()
+	match := r.adlist.Explain(aCtx, hostname)
+	decision, pattern := match.Result, match.Pattern
+	result.Decision, result.Pattern = decisionName(decision), pattern
+	result.Source = match.Source
+	if adl.PrecedenceNone != match.Rule {
+		result.Rule = match.Rule.String()
+	}
+	switch {
+	case adl.ADdeny != decision:
+		result.step("adlist", result.Decision, pattern, start)
+	case r.audit.Load():
+		// In audit mode the hostname is resolved anyway
+		result.step("adlist", "audit", pattern, start)
+	default:
+		result.step("adlist", result.Decision, pattern, start)
+		result.IPs = []net.IP{net.IPv4zero}
+		return result
+	}
+
+	start = time.Now()
+	if r.neverCache.match(hostname) {
+		result.step("cache", "skipped", "never cached", start)
+	} else {
+		r.RLock()
+		ips, ok := r.ICacheList.IPs(aCtx, hostname)
+		r.RUnlock()
+		if ok && (0 < len(ips)) {
+			result.step("cache", "hit", "", start)
+			result.Cached, result.IPs = true, ips
+			return result
+		}
+		result.step("cache", "miss", "", start)
+	}
+
+	start = time.Now()
+	answer, err := r.lookupUpstream(aCtx, hostname)
+	result.Upstream = answer.server
+	if nil == err {
+		if ips, err = r.filterRebindIPs(hostname, answer.ips); nil == err {
+			ips, err = r.filterBlockedIPs(hostname, ips)
+		}
+	}
+	if nil != err {
+		result.step("upstream", "error", err.Error(), start)
+		result.Error = err.Error()
+		return result
+	}
+	result.step("upstream", "answer", answer.server, start)
+	result.IPs = ips
+
+	return result
+} // Trace()
+
+/* _EoF_ */
@@ -0,0 +1,109 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_TResolver_Trace(t *testing.T) {
+	r := NewWithOptions(TResolverOptions{DataDir: t.TempDir()})
+	defer r.StopExpire()
+
+	cachedIP := net.ParseIP("192.0.2.1")
+	rewriteIP := net.ParseIP("198.51.100.1")
+	_ = r.AddRewrite(TRewriteRule{Match: "fixed.localdomain", Type: RewriteIP, Target: rewriteIP.String()})
+	_ = r.AddRewrite(TRewriteRule{Match: "alias.localdomain", Type: RewriteCNAME, Target: "cached.localdomain"})
+	_ = r.AddDeny("*.ads.localdomain")
+	r.ICacheList.Create(context.TODO(), "cached.localdomain", []net.IP{cachedIP}, time.Minute)
+
+	before := r.Metrics()
+
+	tests := []struct {
+		name     string
+		host     string
+		steps    []string
+		decision string
+		pattern  string
+		cached   bool
+		want     net.IP
+	}{
+		/* */
+		{
+			name:  "01 - rewrite answer",
+			host:  "fixed.localdomain",
+			steps: []string{"rewrite"},
+			want:  rewriteIP,
+		},
+		{
+			name:     "02 - denied",
+			host:     "banner.ads.localdomain",
+			steps:    []string{"rewrite", "leases", "adlist"},
+			decision: "deny",
+			pattern:  "*.ads.localdomain",
+			want:     net.IPv4zero,
+		},
+		{
+			name:     "03 - cached",
+			host:     "cached.localdomain",
+			steps:    []string{"rewrite", "leases", "adlist", "cache"},
+			decision: "neutral",
+			cached:   true,
+			want:     cachedIP,
+		},
+		{
+			name:     "04 - CNAME rewrite to cached",
+			host:     "alias.localdomain",
+			steps:    []string{"rewrite", "leases", "adlist", "cache"},
+			decision: "neutral",
+			cached:   true,
+			want:     cachedIP,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := r.Trace(context.TODO(), tc.host)
+			if got.Hostname != tc.host {
+				t.Errorf("Trace().Hostname = '%v', want '%v'", got.Hostname, tc.host)
+			}
+			if len(got.Steps) != len(tc.steps) {
+				t.Fatalf("Trace().Steps = '%v', want '%v'", got.Steps, tc.steps)
+			}
+			for idx, step := range got.Steps {
+				if step.Step != tc.steps[idx] {
+					t.Errorf("Trace().Steps[%d] = '%v', want '%v'", idx, step.Step, tc.steps[idx])
+				}
+			}
+			if got.Decision != tc.decision {
+				t.Errorf("Trace().Decision = '%v', want '%v'", got.Decision, tc.decision)
+			}
+			if got.Pattern != tc.pattern {
+				t.Errorf("Trace().Pattern = '%v', want '%v'", got.Pattern, tc.pattern)
+			}
+			if got.Cached != tc.cached {
+				t.Errorf("Trace().Cached = '%v', want '%v'", got.Cached, tc.cached)
+			}
+			if (1 != len(got.IPs)) || !tc.want.Equal(got.IPs[0]) {
+				t.Errorf("Trace().IPs = '%v', want '%v'", got.IPs, tc.want)
+			}
+		})
+	}
+
+	// A trace doesn't change the metrics
+	if got := r.Metrics(); (before.Lookups != got.Lookups) || (before.Hits != got.Hits) {
+		t.Errorf("Metrics() = '%v', want '%v'", got, before)
+	}
+} // Test_TResolver_Trace()
+
+/* _EoF_ */
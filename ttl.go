@@ -0,0 +1,109 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"strings"
+	"time"
+
+	"github.com/mwat56/dnscache/cache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tTTLPolicy` determines the time to live of cache entries.
+	tTTLPolicy struct {
+		overrides map[string]time.Duration // fixed TTLs by domain
+		def       time.Duration            // TTL if the upstream's is unknown
+		min       time.Duration            // lower bound (`0` means none)
+		max       time.Duration            // upper bound (`0` means none)
+	}
+)
+
+// ---------------------------------------------------------------------------
+// `tTTLPolicy` constructor:
+
+// `newTTLPolicy()` returns a new TTL policy.
+//
+// Parameters:
+//   - `aDefault`: The TTL to use if the upstream TTL is unknown.
+//   - `aMin`: The minimal TTL (`0` means no lower bound).
+//   - `aMax`: The maximal TTL (`0` means no upper bound).
+//   - `aOverrides`: Fixed TTLs by domain (applying to its subdomains as well).
+//
+// Returns:
+//   - `*tTTLPolicy`: The new TTL policy.
+func newTTLPolicy(aDefault, aMin, aMax time.Duration, aOverrides map[string]time.Duration) *tTTLPolicy {
+	result := &tTTLPolicy{
+		def: aDefault,
+		min: max(aMin, 0),
+		max: max(aMax, 0),
+	}
+	for domain, ttl := range aOverrides {
+		domain = strings.TrimPrefix(strings.Trim(strings.ToLower(strings.TrimSpace(domain)), "."), "*.")
+		if ("" == domain) || (0 >= ttl) {
+			gLog.Error("invalid TTL override", "domain", domain, "ttl", ttl)
+			continue
+		}
+		if nil == result.overrides {
+			result.overrides = make(map[string]time.Duration, len(aOverrides))
+		}
+		result.overrides[domain] = ttl
+	}
+
+	return result
+} // newTTLPolicy()
+
+// ---------------------------------------------------------------------------
+// `tTTLPolicy` methods:
+
+// `ttl()` returns the time to live of a hostname's cache entry.
+//
+// An override configured for the hostname (or its closest parent
+// domain) takes precedence. Otherwise the upstream TTL (or the default
+// TTL if unknown) is clamped to the policy's bounds; if the lower bound
+// exceeds the upper one, the upper bound wins.
+//
+// Parameters:
+//   - `aHostname`: The hostname to cache.
+//   - `aUpstream`: The TTL reported by the upstream server (`0` if unknown).
+//
+// Returns:
+//   - `time.Duration`: The TTL to use.
+func (tp *tTTLPolicy) ttl(aHostname string, aUpstream time.Duration) time.Duration {
+	if nil == tp {
+		if 0 < aUpstream {
+			return aUpstream
+		}
+		return cache.DefaultTTL
+	}
+	if 0 < len(tp.overrides) {
+		name := strings.Trim(strings.ToLower(aHostname), ".")
+		for "" != name {
+			if ttl, ok := tp.overrides[name]; ok {
+				return ttl
+			}
+			_, name, _ = strings.Cut(name, ".")
+		}
+	}
+
+	result := aUpstream
+	if 0 >= result {
+		result = tp.def
+	}
+	if (0 < tp.min) && (result < tp.min) {
+		result = tp.min
+	}
+	if (0 < tp.max) && (result > tp.max) {
+		result = tp.max
+	}
+
+	return result
+} // ttl()
+
+/* _EoF_ */
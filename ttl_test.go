@@ -0,0 +1,59 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mwat56/dnscache/cache"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_tTTLPolicy_ttl(t *testing.T) {
+	policy := newTTLPolicy(10*time.Minute, time.Minute, 24*time.Hour,
+		map[string]time.Duration{
+			"*.cdn.example.com": 5 * time.Second,
+			"Example.ORG.":      time.Hour,
+			"":                  time.Hour,  // ignored
+			"example.net":       -time.Hour, // ignored
+		})
+	inverted := newTTLPolicy(10*time.Minute, time.Hour, time.Minute, nil)
+
+	tests := []struct {
+		name     string
+		policy   *tTTLPolicy
+		hostname string
+		upstream time.Duration
+		want     time.Duration
+	}{
+		/* */
+		{"01 - nil policy", nil, "example.com", 30 * time.Second, 30 * time.Second},
+		{"02 - nil policy, unknown TTL", nil, "example.com", 0, cache.DefaultTTL},
+		{"03 - unknown TTL", policy, "example.com", 0, 10 * time.Minute},
+		{"04 - within bounds", policy, "example.com", time.Hour, time.Hour},
+		{"05 - below minimum", policy, "example.com", 5 * time.Second, time.Minute},
+		{"06 - above maximum", policy, "example.com", 48 * time.Hour, 24 * time.Hour},
+		{"07 - override of domain", policy, "example.org", 30 * time.Second, time.Hour},
+		{"08 - override of subdomain", policy, "www.Example.org.", 30 * time.Second, time.Hour},
+		{"09 - wildcard override", policy, "img.cdn.example.com", time.Hour, 5 * time.Second},
+		{"10 - invalid override", policy, "example.net", time.Hour, time.Hour},
+		{"11 - minimum above maximum", inverted, "example.com", 30 * time.Second, time.Minute},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.policy.ttl(tc.hostname, tc.upstream); got != tc.want {
+				t.Errorf("ttl() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_tTTLPolicy_ttl()
+
+/* _EoF_ */
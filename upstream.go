@@ -0,0 +1,269 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mwat56/dnscache/clock"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `TSelection` determines which of the configured DNS servers
+	// are queried for a hostname.
+	TSelection uint8
+
+	// `TUpstreamLatency` is the response latency of an upstream
+	// server as returned by [TResolver.Latencies].
+	TUpstreamLatency struct {
+		Server  string        `json:"server"`
+		Latency time.Duration `json:"latency"` // moving average
+		Samples uint32        `json:"samples"` // number of measured answers
+	}
+
+	// `tLatency` is the response latency of a single upstream server.
+	tLatency struct {
+		average  atomic.Int64  // moving average (nanoseconds)
+		measured atomic.Int64  // time of the last sample (Unix nanoseconds)
+		samples  atomic.Uint32 // number of samples
+	}
+
+	// `tLatencies` are the response latencies of the upstream servers.
+	tLatencies struct {
+		sync.RWMutex
+		servers map[string]*tLatency // latencies by upstream server
+		clock   clock.IClock         // source of the current time
+		probed  atomic.Int64         // time of the last probe (Unix nanoseconds)
+	}
+)
+
+const (
+	// `SelectParallel` queries all DNS servers in parallel and uses
+	// the first answer.
+	SelectParallel = TSelection(iota)
+
+	// `SelectFastest` queries the healthy DNS server with the lowest
+	// latency, trying the slower ones only if it fails.
+	SelectFastest
+)
+
+const (
+	// `latencyPenalty` is the sample recorded for a failed query
+	// (i.e. the timeout of `exchangeDNS()`).
+	latencyPenalty = time.Second << 2
+
+	// `latencyProbeInterval` is the time between two queries sent to
+	// a slower server to track its latency.
+	latencyProbeInterval = 30 * time.Second
+
+	// `latencyWeight` is the inverse weight of a new sample in the
+	// moving average.
+	latencyWeight = 4
+)
+
+// ---------------------------------------------------------------------------
+// `tLatencies` constructor:
+
+// `newLatencies()` creates the latency tracking of the upstream servers.
+//
+// Parameters:
+//   - `aClock`: The source of the current time.
+//
+// Returns:
+//   - `*tLatencies`: The latency tracking.
+func newLatencies(aClock clock.IClock) *tLatencies {
+	return &tLatencies{
+		servers: make(map[string]*tLatency),
+		clock:   aClock,
+	}
+} // newLatencies()
+
+// ---------------------------------------------------------------------------
+// `TSelection` methods:
+
+// `String()` returns the selection's name.
+//
+// Returns:
+//   - `string`: "parallel" or "fastest".
+func (s TSelection) String() string {
+	if SelectFastest == s {
+		return "fastest"
+	}
+
+	return "parallel"
+} // String()
+
+// ---------------------------------------------------------------------------
+// `tLatencies` methods:
+
+// `average()` returns the moving average of a server's latency.
+//
+// Parameters:
+//   - `aServer`: The upstream server.
+//
+// Returns:
+//   - `time.Duration`: The server's latency (`0` if never measured).
+func (l *tLatencies) average(aServer string) time.Duration {
+	l.RLock()
+	latency, ok := l.servers[aServer]
+	l.RUnlock()
+	if !ok {
+		return 0
+	}
+
+	return time.Duration(latency.average.Load())
+} // average()
+
+// `latency()` returns the latency of a server, creating it if needed.
+//
+// Parameters:
+//   - `aServer`: The upstream server.
+//
+// Returns:
+//   - `*tLatency`: The server's latency.
+func (l *tLatencies) latency(aServer string) *tLatency {
+	l.RLock()
+	result, ok := l.servers[aServer]
+	l.RUnlock()
+	if ok {
+		return result
+	}
+
+	l.Lock()
+	defer l.Unlock()
+	if result, ok = l.servers[aServer]; !ok {
+		result = &tLatency{}
+		l.servers[aServer] = result
+	}
+
+	return result
+} // latency()
+
+// `order()` sorts the servers by their latency.
+//
+// Servers never measured come first so they get measured.
+//
+// Parameters:
+//   - `aServers`: The upstream servers.
+//
+// Returns:
+//   - `[]string`: The servers from the fastest to the slowest.
+func (l *tLatencies) order(aServers []string) []string {
+	if nil == l {
+		return aServers
+	}
+	result := slices.Clone(aServers)
+	slices.SortStableFunc(result, func(a, b string) int {
+		return cmp.Compare(l.average(a), l.average(b))
+	})
+
+	return result
+} // order()
+
+// `probe()` returns a slower server to query in the background if
+// it's time to re-measure one.
+//
+// Parameters:
+//   - `aServers`: The upstream servers ordered by latency.
+//
+// Returns:
+//   - `string`: The least recently measured of the slower servers,
+//     or an empty string if there's none or it's not yet time.
+func (l *tLatencies) probe(aServers []string) string {
+	if (nil == l) || (2 > len(aServers)) {
+		return ""
+	}
+	now := l.clock.Now().UnixNano()
+	last := l.probed.Load()
+	if (now-last < int64(latencyProbeInterval)) || !l.probed.CompareAndSwap(last, now) {
+		return ""
+	}
+
+	var (
+		result string
+		oldest int64
+	)
+	for _, server := range aServers[1:] {
+		measured := l.latency(server).measured.Load()
+		if ("" == result) || (measured < oldest) {
+			result, oldest = server, measured
+		}
+	}
+
+	return result
+} // probe()
+
+// `record()` adds a sample to a server's moving average.
+//
+// Parameters:
+//   - `aServer`: The upstream server.
+//   - `aLatency`: The time it took the server to answer.
+func (l *tLatencies) record(aServer string, aLatency time.Duration) {
+	if nil == l {
+		return
+	}
+	latency := l.latency(aServer)
+	sample := int64(aLatency)
+	for {
+		average := latency.average.Load()
+		next := sample
+		if 0 < latency.samples.Load() {
+			next = average + (sample-average)/latencyWeight
+		}
+		if latency.average.CompareAndSwap(average, next) {
+			break
+		}
+	}
+	latency.samples.Add(1)
+	latency.measured.Store(l.clock.Now().UnixNano())
+} // record()
+
+// `states()` returns the latencies of all servers.
+//
+// Returns:
+//   - `[]TUpstreamLatency`: The latencies sorted by server (`nil` if
+//     not tracked).
+func (l *tLatencies) states() []TUpstreamLatency {
+	if nil == l {
+		return nil
+	}
+	l.RLock()
+	result := make([]TUpstreamLatency, 0, len(l.servers))
+	for server, latency := range l.servers {
+		result = append(result, TUpstreamLatency{
+			Server:  server,
+			Latency: time.Duration(latency.average.Load()),
+			Samples: latency.samples.Load(),
+		})
+	}
+	l.RUnlock()
+	slices.SortFunc(result, func(a, b TUpstreamLatency) int {
+		return strings.Compare(a.Server, b.Server)
+	})
+
+	return result
+} // states()
+
+// ---------------------------------------------------------------------------
+// `TResolver` methods:
+
+// `Latencies()` returns the response latencies of the upstream servers.
+//
+// Returns:
+//   - `[]TUpstreamLatency`: The servers' latencies sorted by server.
+func (r *TResolver) Latencies() []TUpstreamLatency {
+	return r.latencies.states()
+} // Latencies()
+
+/* _EoF_ */
@@ -0,0 +1,207 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mwat56/dnscache/clock"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// `startUpstream()` starts a fake DNS server on the loopback interface
+// answering all queries with `aIP` after `aDelay`.
+func startUpstream(t *testing.T, aIP net.IP, aDelay time.Duration) (string, *atomic.Int32) {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if nil != err {
+		t.Fatalf("ListenPacket() error = '%v'", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	queries := &atomic.Int32{}
+	go func() {
+		for {
+			buffer := make([]byte, 512)
+			n, addr, err := conn.ReadFrom(buffer)
+			if nil != err {
+				return
+			}
+			queries.Add(1)
+			go func(aQuery []byte) {
+				time.Sleep(aDelay)
+				if dnsTypeAAAA == binary.BigEndian.Uint16(aQuery[len(aQuery)-4:]) {
+					_, _ = conn.WriteTo(mdnsResponse(aQuery), addr)
+					return
+				}
+				_, _ = conn.WriteTo(mdnsResponse(aQuery, aIP), addr)
+			}(buffer[:n])
+		}
+	}()
+
+	return conn.LocalAddr().String(), queries
+} // startUpstream()
+
+func Test_TSelection_String(t *testing.T) {
+	tests := []struct {
+		name      string
+		selection TSelection
+		want      string
+	}{
+		/* */
+		{"01 - parallel", SelectParallel, "parallel"},
+		{"02 - fastest", SelectFastest, "fastest"},
+		{"03 - unknown", TSelection(99), "parallel"},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.selection.String(); got != tc.want {
+				t.Errorf("TSelection.String() = '%s', want '%s'", got, tc.want)
+			}
+		})
+	}
+} // Test_TSelection_String()
+
+func Test_tLatencies_record(t *testing.T) {
+	l := newLatencies(clock.NewManual(time.Now()))
+
+	tests := []struct {
+		name   string
+		sample time.Duration
+		want   time.Duration
+	}{
+		/* */
+		{"01 - first sample", 100 * time.Millisecond, 100 * time.Millisecond},
+		{"02 - slower sample", 500 * time.Millisecond, 200 * time.Millisecond},
+		{"03 - faster sample", 0, 150 * time.Millisecond},
+		{"04 - failure", latencyPenalty, 150*time.Millisecond + (latencyPenalty-150*time.Millisecond)/latencyWeight},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			l.record("192.0.2.53", tc.sample)
+			if got := l.average("192.0.2.53"); got != tc.want {
+				t.Errorf("tLatencies.average() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+
+	states := l.states()
+	if (1 != len(states)) || (uint32(len(tests)) != states[0].Samples) {
+		t.Errorf("tLatencies.states() = '%v', want 1 server with %d samples", states, len(tests))
+	}
+
+	var disabled *tLatencies
+	disabled.record("192.0.2.53", time.Second)
+	if got := disabled.states(); nil != got {
+		t.Errorf("tLatencies.states() = '%v', want 'nil'", got)
+	}
+} // Test_tLatencies_record()
+
+func Test_tLatencies_order(t *testing.T) {
+	l := newLatencies(clock.NewManual(time.Now()))
+	l.record("slow", 300*time.Millisecond)
+	l.record("fast", 10*time.Millisecond)
+	l.record("failing", latencyPenalty)
+
+	tests := []struct {
+		name    string
+		servers []string
+		want    []string
+	}{
+		/* */
+		{"01 - by latency", []string{"failing", "slow", "fast"}, []string{"fast", "slow", "failing"}},
+		{"02 - unmeasured first", []string{"slow", "new", "fast"}, []string{"new", "fast", "slow"}},
+		{"03 - single", []string{"slow"}, []string{"slow"}},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			servers := slices.Clone(tc.servers)
+			if got := l.order(servers); !slices.Equal(got, tc.want) {
+				t.Errorf("tLatencies.order() = '%v', want '%v'", got, tc.want)
+			}
+			if !slices.Equal(servers, tc.servers) {
+				t.Errorf("tLatencies.order() changed its argument to '%v'", servers)
+			}
+		})
+	}
+} // Test_tLatencies_order()
+
+func Test_tLatencies_probe(t *testing.T) {
+	clk := clock.NewManual(time.Now())
+	l := newLatencies(clk)
+	l.record("fast", 10*time.Millisecond)
+	l.record("slow", 300*time.Millisecond)
+	clk.Advance(time.Second)
+	l.record("slower", 500*time.Millisecond)
+	servers := []string{"fast", "slow", "slower"}
+
+	if got := l.probe(servers[:1]); "" != got {
+		t.Errorf("tLatencies.probe() of a single server = '%s', want ''", got)
+	}
+	if got := l.probe(servers); "slow" != got {
+		t.Errorf("tLatencies.probe() = '%s', want 'slow'", got)
+	}
+	if got := l.probe(servers); "" != got {
+		t.Errorf("tLatencies.probe() before interval = '%s', want ''", got)
+	}
+
+	clk.Advance(latencyProbeInterval)
+	l.record("slow", 300*time.Millisecond)
+	if got := l.probe(servers); "slower" != got {
+		t.Errorf("tLatencies.probe() after interval = '%s', want 'slower'", got)
+	}
+} // Test_tLatencies_probe()
+
+func Test_TResolver_lookupFastest(t *testing.T) {
+	fast, _ := startUpstream(t, net.ParseIP("192.0.2.1"), 0)
+	slow, slowQueries := startUpstream(t, net.ParseIP("192.0.2.2"), 100*time.Millisecond)
+
+	r := NewWithOptions(TResolverOptions{
+		DataDir:    t.TempDir(),
+		DNSservers: []string{slow, fast},
+		Selection:  SelectFastest,
+	})
+	defer r.StopExpire()
+
+	// Neither server is measured yet, so the first one answers while
+	// the other one gets probed in the background
+	ips, err := r.LookupHost(context.TODO(), "first.test")
+	if nil != err {
+		t.Fatalf("LookupHost() error = '%v'", err)
+	}
+	assertIps(t, ips, []string{"192.0.2.2"})
+	if !waitFor(func() bool { return 2 == len(r.Latencies()) }) {
+		t.Fatalf("Latencies() = '%v', want 2 servers", r.Latencies())
+	}
+
+	// Now the faster server gets queried alone
+	slowBefore := slowQueries.Load()
+	ips, err = r.LookupHost(context.TODO(), "second.test")
+	if nil != err {
+		t.Fatalf("LookupHost() error = '%v'", err)
+	}
+	assertIps(t, ips, []string{"192.0.2.1"})
+	if got := slowQueries.Load(); slowBefore != got {
+		t.Errorf("slow server got '%d' queries, want '%d'", got, slowBefore)
+	}
+} // Test_TResolver_lookupFastest()
+
+/* _EoF_ */
@@ -0,0 +1,172 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"net"
+	"slices"
+	"strings"
+	"sync"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+type (
+	// `tWatchers` holds the notification channels of watched hostnames.
+	tWatchers struct {
+		sync.Mutex
+		channels map[string][]chan []net.IP
+	}
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `sameIPs()` checks whether two lists contain the same IP addresses
+// regardless of their order.
+//
+// Parameters:
+//   - `aList1`: The first list to compare.
+//   - `aList2`: The second list to compare.
+//
+// Returns:
+//   - `bool`: `true` if the lists hold the same addresses, `false` otherwise.
+func sameIPs(aList1, aList2 []net.IP) bool {
+	if len(aList1) != len(aList2) {
+		return false
+	}
+	compare := func(a, b net.IP) int {
+		return strings.Compare(string(a.To16()), string(b.To16()))
+	}
+	list1 := slices.SortedFunc(slices.Values(aList1), compare)
+	list2 := slices.SortedFunc(slices.Values(aList2), compare)
+
+	return slices.EqualFunc(list1, list2, net.IP.Equal)
+} // sameIPs()
+
+// ---------------------------------------------------------------------------
+// `tWatchers` methods:
+
+// `notify()` sends the new addresses of a hostname to its watchers
+// if they differ from the old ones.
+//
+// A watcher which didn't yet receive the previous notification gets
+// only the latest one.
+//
+// Parameters:
+//   - `aHostname`: The hostname whose addresses were refreshed.
+//   - `aOld`: The previous addresses.
+//   - `aNew`: The current addresses.
+func (w *tWatchers) notify(aHostname string, aOld, aNew []net.IP) {
+	if (nil == w) || sameIPs(aOld, aNew) {
+		return
+	}
+	hostname := strings.Trim(strings.ToLower(aHostname), ".")
+
+	w.Lock()
+	defer w.Unlock()
+
+	for _, ch := range w.channels[hostname] {
+		select {
+		case <-ch: // drop the outdated notification
+		default:
+		}
+		ch <- slices.Clone(aNew)
+	}
+} // notify()
+
+// `remove()` removes a watcher's channel and closes it.
+//
+// Parameters:
+//   - `aChannel`: The channel returned by `watch()`.
+//
+// Returns:
+//   - `bool`: `true` if the channel was removed, `false` otherwise.
+func (w *tWatchers) remove(aChannel <-chan []net.IP) bool {
+	if nil == w {
+		return false
+	}
+	w.Lock()
+	defer w.Unlock()
+
+	for hostname, channels := range w.channels {
+		idx := slices.IndexFunc(channels, func(aCh chan []net.IP) bool {
+			return aCh == aChannel
+		})
+		if 0 > idx {
+			continue
+		}
+		close(channels[idx])
+		if channels = slices.Delete(channels, idx, idx+1); 0 == len(channels) {
+			delete(w.channels, hostname)
+		} else {
+			w.channels[hostname] = channels
+		}
+		return true
+	}
+
+	return false
+} // remove()
+
+// `watch()` returns a new notification channel for a hostname.
+//
+// Parameters:
+//   - `aHostname`: The hostname to watch.
+//
+// Returns:
+//   - `chan []net.IP`: The channel receiving the hostname's new addresses.
+func (w *tWatchers) watch(aHostname string) chan []net.IP {
+	hostname := strings.Trim(strings.ToLower(strings.TrimSpace(aHostname)), ".")
+	ch := make(chan []net.IP, 1)
+	if nil == w {
+		close(ch) // nothing to watch
+		return ch
+	}
+
+	w.Lock()
+	if nil == w.channels {
+		w.channels = make(map[string][]chan []net.IP)
+	}
+	w.channels[hostname] = append(w.channels[hostname], ch)
+	w.Unlock()
+
+	return ch
+} // watch()
+
+// ---------------------------------------------------------------------------
+// `TResolver` methods:
+
+// `Unwatch()` stops the notifications of a channel returned by
+// [TResolver.Watch] and closes the channel.
+//
+// Parameters:
+//   - `aChannel`: The channel to stop.
+//
+// Returns:
+//   - `bool`: `true` if the channel was found, `false` otherwise.
+func (r *TResolver) Unwatch(aChannel <-chan []net.IP) bool {
+	return r.watchers.remove(aChannel)
+} // Unwatch()
+
+// `Watch()` returns a channel receiving the IP addresses of a hostname
+// whenever they changed during a cache refresh.
+//
+// The channel buffers only the latest change, so a slow reader won't
+// block the refresh but may miss intermediate states; if the hostname
+// doesn't exist anymore, an empty list is sent. Call [TResolver.Unwatch]
+// when the notifications are no longer needed.
+//
+// Parameters:
+//   - `aHostname`: The hostname to watch.
+//
+// Returns:
+//   - `<-chan []net.IP`: The channel receiving the hostname's new addresses.
+func (r *TResolver) Watch(aHostname string) <-chan []net.IP {
+	return r.watchers.watch(aHostname)
+} // Watch()
+
+/* _EoF_ */
@@ -0,0 +1,94 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"context"
+	"net"
+	"slices"
+	"testing"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+func Test_sameIPs(t *testing.T) {
+	ip1 := net.ParseIP("192.0.2.1")
+	ip2 := net.ParseIP("192.0.2.2")
+	ip3 := net.ParseIP("2001:db8::1")
+
+	tests := []struct {
+		name  string
+		list1 []net.IP
+		list2 []net.IP
+		want  bool
+	}{
+		/* */
+		{"01 - both nil", nil, nil, true},
+		{"02 - nil and empty", nil, []net.IP{}, true},
+		{"03 - different length", []net.IP{ip1}, []net.IP{ip1, ip2}, false},
+		{"04 - same order", []net.IP{ip1, ip3}, []net.IP{ip1, ip3}, true},
+		{"05 - other order", []net.IP{ip3, ip1}, []net.IP{ip1, ip3}, true},
+		{"06 - 4in6 form", []net.IP{ip1.To4()}, []net.IP{ip1.To16()}, true},
+		{"07 - different addresses", []net.IP{ip1, ip2}, []net.IP{ip1, ip3}, false},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := sameIPs(tc.list1, tc.list2); got != tc.want {
+				t.Errorf("sameIPs() = '%v', want '%v'", got, tc.want)
+			}
+		})
+	}
+} // Test_sameIPs()
+
+func Test_TResolver_Watch(t *testing.T) {
+	ip := net.ParseIP("192.168.1.20")
+	startMDNSResponder(t, "printer.local", ip)
+
+	r := NewWithOptions(TResolverOptions{
+		DataDir:    t.TempDir(),
+		DNSservers: []string{"192.0.2.1"}, // must not be asked
+		MDNS:       true,
+	})
+	defer r.StopExpire()
+	r.refresh.pace = 0
+
+	changed := r.Watch("Printer.local.")
+	unchanged := r.Watch("printer.local")
+	if !r.Unwatch(unchanged) || r.Unwatch(unchanged) {
+		t.Errorf("Unwatch() didn't remove the channel exactly once")
+	}
+	if _, ok := <-unchanged; ok {
+		t.Errorf("Unwatch() didn't close the channel")
+	}
+
+	ctx := context.Background()
+	r.ICacheList.Create(ctx, "printer.local", []net.IP{net.ParseIP("10.0.0.1")}, time.Hour)
+	r.Refresh()
+
+	select {
+	case got := <-changed:
+		if !slices.EqualFunc(got, []net.IP{ip}, net.IP.Equal) {
+			t.Errorf("Watch() = '%v', want '%v'", got, ip)
+		}
+	default:
+		t.Fatalf("Watch() didn't report the changed addresses")
+	}
+
+	// An unchanged refresh must not be reported
+	r.Refresh()
+	select {
+	case got := <-changed:
+		t.Errorf("Watch() = '%v', want no notification", got)
+	default:
+	}
+	r.Unwatch(changed)
+} // Test_TResolver_Watch()
+
+/* _EoF_ */
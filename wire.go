@@ -0,0 +1,254 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"slices"
+	"strings"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+const (
+	// DNS record types and class used by the lookups
+	dnsTypeA     uint16 = 1
+	dnsTypeCNAME uint16 = 5
+	dnsTypeAAAA  uint16 = 28
+	dnsClassIN   uint16 = 1
+
+	// DNS header flags and response codes used by the lookups
+	dnsFlagRD       uint16 = 1 << 8
+	dnsFlagTC       uint16 = 1 << 9
+	dnsRcodeMask    uint16 = 0x000F
+	dnsRcodeNoError uint16 = 0
+	dnsRcodeNXName  uint16 = 3
+
+	// `maxCNAMEChain` limits the number of CNAMEs followed in an answer.
+	maxCNAMEChain = 8
+)
+
+type (
+	// `tDNSRecord` is a resource record read from a DNS message.
+	tDNSRecord struct {
+		name  string
+		data  []byte
+		msg   []byte // the whole message (to resolve compressed names)
+		ttl   time.Duration
+		rType uint16
+		rData int // offset of `data` within `msg`
+	}
+)
+
+var (
+	// `errMalformedMessage` is returned for invalid DNS messages.
+	errMalformedMessage = errors.New("malformed DNS message")
+)
+
+// ---------------------------------------------------------------------------
+// Helper functions:
+
+// `dnsName()` reads a (possibly compressed) name from a DNS message.
+//
+// Parameters:
+//   - `aMessage`: The DNS message.
+//   - `aOffset`: The name's offset within the message.
+//
+// Returns:
+//   - `string`: The lower-cased name without trailing dot.
+//   - `int`: The offset following the name.
+//   - `error`: `nil` if the name was read, `errMalformedMessage` otherwise.
+func dnsName(aMessage []byte, aOffset int) (string, int, error) {
+	var (
+		labels []string
+		next   = -1 // offset after the first compression pointer
+	)
+	offset := aOffset
+	for jumps := 0; ; {
+		if offset >= len(aMessage) {
+			return "", 0, errMalformedMessage
+		}
+		labelLen := int(aMessage[offset])
+		switch {
+		case 0 == labelLen:
+			if 0 > next {
+				next = offset + 1
+			}
+			return strings.ToLower(strings.Join(labels, ".")), next, nil
+
+		case 0xC0 == labelLen&0xC0:
+			if (offset+2 > len(aMessage)) || (16 < jumps) {
+				return "", 0, errMalformedMessage
+			}
+			if 0 > next {
+				next = offset + 2
+			}
+			offset = int(binary.BigEndian.Uint16(aMessage[offset:offset+2]) & 0x3FFF)
+			jumps++
+
+		default:
+			if offset+1+labelLen > len(aMessage) {
+				return "", 0, errMalformedMessage
+			}
+			labels = append(labels, string(aMessage[offset+1:offset+1+labelLen]))
+			offset += 1 + labelLen
+		}
+	}
+} // dnsName()
+
+// `dnsQuery()` creates a DNS query message.
+//
+// Parameters:
+//   - `aID`: The query's ID.
+//   - `aFlags`: The query's header flags (e.g. `dnsFlagRD`).
+//   - `aHostname`: The hostname to query.
+//   - `aTypes`: The record types to ask for (one question each).
+//
+// Returns:
+//   - `[]byte`: The query message (`nil` if the hostname is invalid).
+func dnsQuery(aID, aFlags uint16, aHostname string, aTypes ...uint16) []byte {
+	var name []byte
+	for _, label := range strings.Split(strings.Trim(aHostname, "."), ".") {
+		if (0 == len(label)) || (63 < len(label)) {
+			return nil
+		}
+		name = append(name, byte(len(label)))
+		name = append(name, label...)
+	}
+	name = append(name, 0)
+
+	result := make([]byte, 12, 12+len(aTypes)*(len(name)+4))
+	binary.BigEndian.PutUint16(result[0:2], aID)
+	binary.BigEndian.PutUint16(result[2:4], aFlags)
+	binary.BigEndian.PutUint16(result[4:6], uint16(len(aTypes))) //#nosec G115
+	for _, qType := range aTypes {
+		result = append(result, name...)
+		result = binary.BigEndian.AppendUint16(result, qType)
+		result = binary.BigEndian.AppendUint16(result, dnsClassIN)
+	}
+
+	return result
+} // dnsQuery()
+
+// `dnsRecords()` reads all resource records of a DNS message.
+//
+// Parameters:
+//   - `aMessage`: The DNS message.
+//
+// Returns:
+//   - `[]tDNSRecord`: The records of all sections (class IN only).
+//   - `error`: `nil` if the message could be parsed, the error otherwise.
+func dnsRecords(aMessage []byte) ([]tDNSRecord, error) {
+	if 12 > len(aMessage) {
+		return nil, errMalformedMessage
+	}
+	qdCount := int(binary.BigEndian.Uint16(aMessage[4:6]))
+	rrCount := int(binary.BigEndian.Uint16(aMessage[6:8])) +
+		int(binary.BigEndian.Uint16(aMessage[8:10])) +
+		int(binary.BigEndian.Uint16(aMessage[10:12]))
+
+	offset := 12
+	for range qdCount {
+		_, next, err := dnsName(aMessage, offset)
+		if nil != err {
+			return nil, err
+		}
+		offset = next + 4
+	}
+
+	var result []tDNSRecord
+	for range rrCount {
+		name, next, err := dnsName(aMessage, offset)
+		if (nil != err) || (next+10 > len(aMessage)) {
+			return result, errMalformedMessage
+		}
+		rType := binary.BigEndian.Uint16(aMessage[next : next+2])
+		// mDNS uses the class's top bit as "cache flush" flag
+		rClass := binary.BigEndian.Uint16(aMessage[next+2:next+4]) & 0x7FFF
+		ttl := binary.BigEndian.Uint32(aMessage[next+4 : next+8])
+		rdLen := int(binary.BigEndian.Uint16(aMessage[next+8 : next+10]))
+		offset = next + 10 + rdLen
+		if offset > len(aMessage) {
+			return result, errMalformedMessage
+		}
+		if dnsClassIN != rClass {
+			continue
+		}
+		result = append(result, tDNSRecord{
+			name:  name,
+			data:  aMessage[next+10 : offset],
+			msg:   aMessage,
+			ttl:   time.Duration(ttl) * time.Second,
+			rType: rType,
+			rData: next + 10,
+		})
+	}
+
+	return result, nil
+} // dnsRecords()
+
+// `parseAnswers()` extracts the addresses of a hostname from a DNS
+// response, following CNAME records.
+//
+// Parameters:
+//   - `aMessage`: The DNS response message.
+//   - `aHostname`: The hostname whose addresses to return.
+//
+// Returns:
+//   - `rIPs`: The hostname's addresses.
+//   - `rTTL`: The smallest TTL of the records used (`0` if there are no addresses).
+//   - `rErr`: `nil` if the message could be parsed, the error otherwise.
+func parseAnswers(aMessage []byte, aHostname string) (rIPs []net.IP, rTTL time.Duration, rErr error) {
+	records, err := dnsRecords(aMessage)
+	if nil != err {
+		return nil, 0, err
+	}
+
+	// Follow the CNAME chain starting with the queried name
+	names := []string{strings.ToLower(strings.TrimSuffix(aHostname, "."))}
+	var chainTTL time.Duration
+	for range maxCNAMEChain {
+		idx := slices.IndexFunc(records, func(aRec tDNSRecord) bool {
+			return (dnsTypeCNAME == aRec.rType) && (aRec.name == names[len(names)-1])
+		})
+		if 0 > idx {
+			break
+		}
+		target, _, err := dnsName(records[idx].msg, records[idx].rData)
+		if (nil != err) || slices.Contains(names, target) {
+			break
+		}
+		names = append(names, target)
+		if (0 == chainTTL) || (records[idx].ttl < chainTTL) {
+			chainTTL = records[idx].ttl
+		}
+	}
+
+	for _, rec := range records {
+		if !slices.Contains(names, rec.name) {
+			continue
+		}
+		switch {
+		case (dnsTypeA == rec.rType) && (net.IPv4len == len(rec.data)),
+			(dnsTypeAAAA == rec.rType) && (net.IPv6len == len(rec.data)):
+			rIPs = append(rIPs, net.IP(slices.Clone(rec.data)))
+			if (0 == rTTL) || (rec.ttl < rTTL) {
+				rTTL = rec.ttl
+			}
+		}
+	}
+	if (0 < len(rIPs)) && (0 < chainTTL) && (chainTTL < rTTL) {
+		rTTL = chainTTL
+	}
+
+	return
+} // parseAnswers()
+
+/* _EoF_ */
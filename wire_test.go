@@ -0,0 +1,197 @@
+/*
+Copyright © 2025  M.Watermann, 10247 Berlin, Germany
+
+	    All rights reserved
+	EMail : <support@mwat.de>
+*/
+package dnscache
+
+import (
+	"encoding/binary"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+//lint:file-ignore ST1017 - I prefer Yoda conditions
+
+// `cnameResponse()` creates a response to `aQuery` answering its first
+// question with a CNAME to `aTarget` (TTL 30s) and the target's address
+// (TTL 300s).
+func cnameResponse(aQuery []byte, aTarget string, aIP net.IP) []byte {
+	result := mdnsResponse(aQuery)
+	binary.BigEndian.PutUint16(result[6:8], 2)
+
+	target := dnsQuery(0, 0, aTarget, dnsTypeA)
+	target = target[12 : len(target)-4] // the encoded name
+	result = binary.BigEndian.AppendUint16(result, 0xC00C)
+	result = binary.BigEndian.AppendUint16(result, dnsTypeCNAME)
+	result = binary.BigEndian.AppendUint16(result, dnsClassIN)
+	result = binary.BigEndian.AppendUint32(result, 30)
+	result = binary.BigEndian.AppendUint16(result, uint16(len(target))) //#nosec G115
+	targetOffset := len(result)
+	result = append(result, target...)
+
+	result = binary.BigEndian.AppendUint16(result, 0xC000|uint16(targetOffset)) //#nosec G115
+	result = binary.BigEndian.AppendUint16(result, dnsTypeA)
+	result = binary.BigEndian.AppendUint16(result, dnsClassIN)
+	result = binary.BigEndian.AppendUint32(result, 300)
+	result = binary.BigEndian.AppendUint16(result, net.IPv4len)
+	result = append(result, aIP.To4()...)
+
+	return result
+} // cnameResponse()
+
+func Test_dnsQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		hostname string
+		wantLen  int
+	}{
+		/* */
+		{"01 - empty name", "", 0},
+		{"02 - empty label", "www..example.com", 0},
+		{"03 - valid name", "www.example.com", 12 + 17 + 4},
+		{"04 - trailing dot", "www.example.com.", 12 + 17 + 4},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := dnsQuery(4711, dnsFlagRD, tc.hostname, dnsTypeA)
+			if len(got) != tc.wantLen {
+				t.Errorf("len(dnsQuery()) = '%d', want '%d'", len(got), tc.wantLen)
+				return
+			}
+			if 0 == tc.wantLen {
+				return
+			}
+			if name, _, err := dnsName(got, 12); (nil != err) || ("www.example.com" != name) {
+				t.Errorf("dnsName() = '%v', '%v', want 'www.example.com'", name, err)
+			}
+		})
+	}
+} // Test_dnsQuery()
+
+func Test_parseAnswers(t *testing.T) {
+	query := dnsQuery(4711, 0, "printer.local", dnsTypeA, dnsTypeAAAA)
+	ip4 := net.ParseIP("192.168.1.20")
+	ip6 := net.ParseIP("fe80::20")
+
+	tests := []struct {
+		name     string
+		message  []byte
+		hostname string
+		want     []net.IP
+		wantTTL  time.Duration
+		wantErr  bool
+	}{
+		/* */
+		{
+			name:     "01 - too short",
+			message:  []byte{0, 1, 2},
+			hostname: "printer.local",
+			wantErr:  true,
+		},
+		{
+			name:     "02 - both address types",
+			message:  mdnsResponse(query, ip4, ip6),
+			hostname: "printer.local",
+			want:     []net.IP{ip4, ip6},
+			wantTTL:  120 * time.Second,
+		},
+		{
+			name:     "03 - other hostname",
+			message:  mdnsResponse(query, ip4),
+			hostname: "scanner.local",
+			want:     nil,
+		},
+		{
+			name:     "04 - truncated answer",
+			message:  mdnsResponse(query, ip4)[:len(mdnsResponse(query, ip4))-2],
+			hostname: "printer.local",
+			wantErr:  true,
+		},
+		{
+			name: "05 - compression loop",
+			message: func() []byte {
+				msg := mdnsResponse(query, ip4)
+				binary.BigEndian.PutUint16(msg[12:14], 0xC00C)
+				return msg
+			}(),
+			hostname: "printer.local",
+			wantErr:  true,
+		},
+		{
+			name:     "06 - CNAME chain",
+			message:  cnameResponse(query, "host.example.com", ip4),
+			hostname: "Printer.local.",
+			want:     []net.IP{ip4},
+			wantTTL:  30 * time.Second,
+		},
+		/* */
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, gotTTL, err := parseAnswers(tc.message, tc.hostname)
+			if (nil != err) != tc.wantErr {
+				t.Errorf("parseAnswers() error = '%v', wantErr '%v'", err, tc.wantErr)
+				return
+			}
+			if !slices.EqualFunc(got, tc.want, net.IP.Equal) {
+				t.Errorf("parseAnswers() = '%v', want '%v'", got, tc.want)
+			}
+			if gotTTL != tc.wantTTL {
+				t.Errorf("parseAnswers() TTL = '%v', want '%v'", gotTTL, tc.wantTTL)
+			}
+		})
+	}
+} // Test_parseAnswers()
+
+func Fuzz_dnsQuery(f *testing.F) {
+	for _, seed := range []string{"www.example.com", "www.example.com.", "", "a..b", "UPPER.case"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, aHostname string) {
+		query := dnsQuery(4711, dnsFlagRD, aHostname, dnsTypeA)
+		if nil == query {
+			return
+		}
+		want := strings.ToLower(strings.Trim(aHostname, "."))
+		if name, _, err := dnsName(query, 12); (nil != err) || (want != name) {
+			t.Errorf("dnsName() = %q, '%v', want %q", name, err, want)
+		}
+		if records, err := dnsRecords(query); (nil != err) || (0 != len(records)) {
+			t.Errorf("dnsRecords() = '%v', '%v', want no records", records, err)
+		}
+	})
+} // Fuzz_dnsQuery()
+
+func Fuzz_parseAnswers(f *testing.F) {
+	query := dnsQuery(4711, 0, "www.example.com", dnsTypeA)
+	f.Add(cnameResponse(query, "cdn.example.net", net.ParseIP("192.0.2.1")), "www.example.com")
+	f.Add(mdnsResponse(query), "www.example.com")
+	f.Add(query, "")
+	f.Add([]byte{0, 0, 0, 0, 0, 1, 0, 1, 0, 0, 0, 0, 0xC0, 12}, "loop")
+
+	f.Fuzz(func(t *testing.T, aMessage []byte, aHostname string) {
+		ips, ttl, err := parseAnswers(aMessage, aHostname)
+		if nil != err {
+			return
+		}
+		for _, ip := range ips {
+			if (net.IPv4len != len(ip)) && (net.IPv6len != len(ip)) {
+				t.Errorf("parseAnswers() IP = '%v', want IPv4 or IPv6", ip)
+			}
+		}
+		if (0 == len(ips)) && (0 != ttl) {
+			t.Errorf("parseAnswers() TTL = '%v' without addresses", ttl)
+		}
+	})
+} // Fuzz_parseAnswers()
+
+/* _EoF_ */